@@ -1,7 +1,7 @@
 #!/usr/bin/env python3
 """Sync class names from catalog DB to ML server and training config.
 
-Fetches SKUs from the Go backend and updates:
+Fetches the class->SKU mapping from the Go backend and updates:
 1. ML server class mapping via gRPC
 2. Training config YAML file
 """
@@ -51,15 +51,15 @@ def parse_args() -> argparse.Namespace:
     return parser.parse_args()
 
 
-def fetch_skus(catalog_url: str) -> list[dict]:
-    """Fetch SKUs from catalog service."""
-    resp = requests.get(f"{catalog_url}/api/v1/skus")
+def fetch_classes(catalog_url: str) -> list[dict]:
+    """Fetch the class mapping from catalog service."""
+    resp = requests.get(f"{catalog_url}/api/v1/admin/ml/classes")
     resp.raise_for_status()
-    return resp.json().get("skus", [])
+    return resp.json().get("classes", [])
 
 
-def update_training_config(config_path: Path, skus: list[dict]) -> None:
-    """Update training config with class names from SKUs."""
+def update_training_config(config_path: Path, classes: list[dict]) -> None:
+    """Update training config with class names from the class mapping."""
     # Load existing config
     if config_path.exists():
         with open(config_path) as f:
@@ -67,19 +67,14 @@ def update_training_config(config_path: Path, skus: list[dict]) -> None:
     else:
         config = {}
 
-    # Build class mapping
-    # SKUs should have a 'class_id' field that maps to YOLO class index
-    # If not, we assign based on order
+    # Build class mapping; class IDs are the YOLO class indices and class
+    # names are SKU codes, as in the training dataset export
     names = {}
     sku_mapping = {}
 
-    for i, sku in enumerate(skus):
-        class_id = sku.get("class_id", i)
-        sku_id = sku.get("id", str(i))
-        name = sku.get("name", f"beverage_{i}")
-
-        names[class_id] = name
-        sku_mapping[class_id] = sku_id
+    for cls in classes:
+        names[cls["class_id"]] = cls["sku_code"]
+        sku_mapping[cls["class_id"]] = cls["sku_id"]
 
     config["nc"] = len(names)
     config["names"] = names
@@ -93,7 +88,7 @@ def update_training_config(config_path: Path, skus: list[dict]) -> None:
     print(f"Updated config with {len(names)} classes")
 
 
-def update_ml_server(ml_server: str, skus: list[dict]) -> None:
+def update_ml_server(ml_server: str, classes: list[dict]) -> None:
     """Update ML server class mapping via gRPC."""
     # Import generated code
     sys.path.insert(0, str(Path(__file__).parent.parent / "server"))
@@ -103,18 +98,16 @@ def update_ml_server(ml_server: str, skus: list[dict]) -> None:
     stub = detection_pb2_grpc.DetectionServiceStub(channel)
 
     # Build class mappings
-    classes = []
-    for i, sku in enumerate(skus):
-        class_id = sku.get("class_id", i)
-        classes.append(
-            detection_pb2.ClassMapping(
-                class_id=class_id,
-                sku_id=sku.get("id", str(i)),
-                class_name=sku.get("name", f"beverage_{i}"),
-            )
+    mappings = [
+        detection_pb2.ClassMapping(
+            class_id=cls["class_id"],
+            sku_id=cls["sku_id"],
+            class_name=cls["sku_code"],
         )
+        for cls in classes
+    ]
 
-    request = detection_pb2.SyncClassesRequest(classes=classes)
+    request = detection_pb2.SyncClassesRequest(classes=mappings)
 
     try:
         response = stub.SyncClasses(request)
@@ -134,30 +127,30 @@ def main() -> int:
         print("Error: Specify --update-config and/or --update-server")
         return 1
 
-    # Fetch SKUs
-    print(f"Fetching SKUs from {args.catalog_url}...")
+    # Fetch the class mapping
+    print(f"Fetching classes from {args.catalog_url}...")
     try:
-        skus = fetch_skus(args.catalog_url)
+        classes = fetch_classes(args.catalog_url)
     except requests.exceptions.RequestException as e:
-        print(f"Failed to fetch SKUs: {e}")
+        print(f"Failed to fetch classes: {e}")
         return 1
 
-    print(f"Found {len(skus)} SKUs")
+    print(f"Found {len(classes)} classes")
 
-    if not skus:
-        print("Warning: No SKUs found")
+    if not classes:
+        print("Warning: No classes mapped")
         return 0
 
     # Update training config
     if args.update_config:
         print(f"\nUpdating training config: {args.config_path}")
-        update_training_config(Path(args.config_path), skus)
+        update_training_config(Path(args.config_path), classes)
 
     # Update ML server
     if args.update_server:
         print(f"\nUpdating ML server: {args.ml_server}")
         try:
-            update_ml_server(args.ml_server, skus)
+            update_ml_server(args.ml_server, classes)
         except Exception as e:
             print(f"Failed to update ML server: {e}")
             return 1
@@ -0,0 +1,697 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SKU mirrors the catalog SKU representation returned by the API
+type SKU struct {
+	ID              string                 `json:"id"`
+	Code            string                 `json:"code"`
+	Name            string                 `json:"name"`
+	PriceCents      int64                  `json:"price_cents"`
+	Currency        string                 `json:"currency"`
+	WeightGrams     float64                `json:"weight_grams"`
+	WeightTolerance float64                `json:"weight_tolerance"`
+	ImageURL        string                 `json:"image_url,omitempty"`
+	Active          bool                   `json:"active"`    // published
+	Lifecycle       string                 `json:"lifecycle"` // draft, published or retired
+	CategoryID      string                 `json:"category_id,omitempty"`
+	Attributes      map[string]any         `json:"attributes,omitempty"`      // brand, size, flavor, allergens, ...
+	Components      []BundleComponent      `json:"components,omitempty"`      // set on bundles only
+	WeightStats     *WeightStats           `json:"weight_stats,omitempty"`    // set once weight samples are recorded
+	ProductInfo     *ProductInfo           `json:"product_info,omitempty"`    // nutrition facts and allergens, when declared
+	Translations    map[string]Translation `json:"translations,omitempty"`    // by language tag, e.g. de or de-CH
+	ShelfLifeDays   int                    `json:"shelf_life_days,omitempty"` // days a unit keeps after restock; 0 when not perishable
+	Restocking      *Restocking            `json:"restocking,omitempty"`      // supplier, case size and reorder point, when set
+	DeletedAt       *time.Time             `json:"deleted_at,omitempty"`      // soft-deleted; hidden from listings
+}
+
+// Translation is a SKU's name and description in one language. Device and
+// session responses use it for clients asking for the language (see
+// WithLanguage).
+type Translation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// WeightStats summarizes the weighed samples of a SKU. Once Calibrated,
+// detection checks basket weights against the mean and spread.
+type WeightStats struct {
+	Samples     int     `json:"samples"`
+	MeanGrams   float64 `json:"mean_grams"`
+	StdDevGrams float64 `json:"stddev_grams"`
+	Calibrated  bool    `json:"calibrated"`
+}
+
+// ProductInfo is the legally required product information of a SKU
+type ProductInfo struct {
+	Nutrition *NutritionFacts `json:"nutrition,omitempty"`
+	Allergens []string        `json:"allergens"` // e.g. gluten, milk, nuts
+}
+
+// NutritionFacts is the nutrition declaration per 100 g or 100 ml
+type NutritionFacts struct {
+	ServingSizeGrams  float64 `json:"serving_size_grams,omitempty"`
+	EnergyKcal        float64 `json:"energy_kcal"`
+	FatGrams          float64 `json:"fat_grams"`
+	SaturatedFatGrams float64 `json:"saturated_fat_grams"`
+	CarbohydrateGrams float64 `json:"carbohydrate_grams"`
+	SugarsGrams       float64 `json:"sugars_grams"`
+	ProteinGrams      float64 `json:"protein_grams"`
+	SaltGrams         float64 `json:"salt_grams"`
+}
+
+// CreateSKURequest is the payload for creating a SKU
+type CreateSKURequest struct {
+	Code            string                 `json:"code"`
+	Name            string                 `json:"name"`
+	PriceCents      int64                  `json:"price_cents"`
+	Currency        string                 `json:"currency,omitempty"`
+	WeightGrams     float64                `json:"weight_grams"`
+	WeightTolerance float64                `json:"weight_tolerance,omitempty"`
+	ImageURL        string                 `json:"image_url,omitempty"`
+	CategoryID      string                 `json:"category_id,omitempty"`
+	Attributes      map[string]any         `json:"attributes,omitempty"`
+	Lifecycle       string                 `json:"lifecycle,omitempty"` // "draft" to prepare the SKU without selling it
+	ProductInfo     *ProductInfo           `json:"product_info,omitempty"`
+	Translations    map[string]Translation `json:"translations,omitempty"`
+	ShelfLifeDays   int                    `json:"shelf_life_days,omitempty"` // perishable SKUs only
+	Restocking      *Restocking            `json:"restocking,omitempty"`
+}
+
+// Restocking is how a SKU is bought in and when a device runs low on it
+type Restocking struct {
+	SupplierRef  string `json:"supplier_ref,omitempty"`  // the supplier's article number
+	CaseSize     int    `json:"case_size,omitempty"`     // units per supplier case
+	ReorderPoint int    `json:"reorder_point,omitempty"` // units left in a device at which it needs restocking
+}
+
+// BundleComponent is one SKU of a bundle and how many units the bundle takes
+type BundleComponent struct {
+	SKUID    string `json:"sku_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// CreateBundleRequest is the payload for creating a bundle SKU. Detected
+// baskets holding every component are charged PriceCents for them.
+type CreateBundleRequest struct {
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	PriceCents int64             `json:"price_cents"`
+	Currency   string            `json:"currency,omitempty"`
+	Components []BundleComponent `json:"components"`
+}
+
+// UpdateSKURequest is the payload for editing a SKU
+type UpdateSKURequest struct {
+	Name            string  `json:"name"`
+	PriceCents      int64   `json:"price_cents"`
+	Currency        string  `json:"currency,omitempty"`
+	WeightGrams     float64 `json:"weight_grams"`
+	WeightTolerance float64 `json:"weight_tolerance,omitempty"`
+	ImageURL        string  `json:"image_url,omitempty"`
+	// Attributes replaces the SKU's attributes; nil keeps them, an empty
+	// map clears them
+	Attributes map[string]any `json:"attributes"`
+	// ProductInfo replaces the nutrition facts and allergens; nil keeps them
+	ProductInfo *ProductInfo `json:"product_info,omitempty"`
+	// Translations replaces the SKU's translations; nil keeps them, an empty
+	// map clears them
+	Translations map[string]Translation `json:"translations"`
+	// ShelfLifeDays sets the shelf life; nil keeps it, 0 marks the SKU as
+	// not perishable
+	ShelfLifeDays *int `json:"shelf_life_days,omitempty"`
+	// Restocking replaces the supplier, case size and reorder point; nil keeps them
+	Restocking *Restocking `json:"restocking,omitempty"`
+}
+
+// PriceChange is one entry of a SKU's price history
+type PriceChange struct {
+	OldPriceCents int64     `json:"old_price_cents"`
+	OldCurrency   string    `json:"old_currency"`
+	NewPriceCents int64     `json:"new_price_cents"`
+	NewCurrency   string    `json:"new_currency"`
+	ChangedBy     string    `json:"changed_by"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// CreateSKUResponse is returned after creating a SKU
+type CreateSKUResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type skuListResponse struct {
+	SKUs  []SKU `json:"skus"`
+	Count int   `json:"count"`
+}
+
+// CreateSKU calls POST /api/v1/skus
+func (c *Client) CreateSKU(ctx context.Context, req CreateSKURequest, opts ...RequestOption) (*CreateSKUResponse, error) {
+	var resp CreateSKUResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateBundle calls POST /api/v1/skus/bundles
+func (c *Client) CreateBundle(ctx context.Context, req CreateBundleRequest, opts ...RequestOption) (*CreateSKUResponse, error) {
+	var resp CreateSKUResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/bundles", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSKU calls GET /api/v1/skus/:id
+func (c *Client) GetSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListSKUs calls GET /api/v1/skus
+func (c *Client) ListSKUs(ctx context.Context, opts ...RequestOption) ([]SKU, error) {
+	var resp skuListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// ListSKUsInCategory calls GET /api/v1/skus?category_id=. Subcategories are
+// included; activeOnly uses /api/v1/skus/active instead.
+func (c *Client) ListSKUsInCategory(ctx context.Context, categoryID string, activeOnly bool, opts ...RequestOption) ([]SKU, error) {
+	path := apiPrefix + "/skus"
+	if activeOnly {
+		path += "/active"
+	}
+	var resp skuListResponse
+	if err := c.do(ctx, http.MethodGet, path+"?category_id="+url.QueryEscape(categoryID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// ListSKUsWithAttributes calls GET /api/v1/skus?attr[name]=value; only SKUs
+// with every given attribute value are returned. activeOnly uses
+// /api/v1/skus/active instead.
+func (c *Client) ListSKUsWithAttributes(ctx context.Context, attrs map[string]string, activeOnly bool, opts ...RequestOption) ([]SKU, error) {
+	path := apiPrefix + "/skus"
+	if activeOnly {
+		path += "/active"
+	}
+	query := url.Values{}
+	for name, value := range attrs {
+		query.Set("attr["+name+"]", value)
+	}
+	var resp skuListResponse
+	if err := c.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// ListSKUsSorted calls GET /api/v1/skus?sort=&order=. field is price, name
+// or created_at; descending reverses the order. activeOnly uses
+// /api/v1/skus/active instead.
+func (c *Client) ListSKUsSorted(ctx context.Context, field string, descending, activeOnly bool, opts ...RequestOption) ([]SKU, error) {
+	path := apiPrefix + "/skus"
+	if activeOnly {
+		path += "/active"
+	}
+	query := url.Values{"sort": {field}, "order": {"asc"}}
+	if descending {
+		query.Set("order", "desc")
+	}
+	var resp skuListResponse
+	if err := c.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// ListActiveSKUs calls GET /api/v1/skus/active
+func (c *Client) ListActiveSKUs(ctx context.Context, opts ...RequestOption) ([]SKU, error) {
+	var resp skuListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus/active", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// PublishSKU calls POST /api/v1/skus/:id/publish, putting a draft or retired
+// SKU on sale
+func (c *Client) PublishSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/publish", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RetireSKU calls POST /api/v1/skus/:id/retire, taking a published SKU off sale
+func (c *Client) RetireSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/retire", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ActivateSKU calls POST /api/v1/skus/:id/activate, an alias of PublishSKU
+func (c *Client) ActivateSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/activate", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeactivateSKU calls POST /api/v1/skus/:id/deactivate, an alias of RetireSKU.
+// The SKU stays in the catalog but is no longer listed as active.
+func (c *Client) DeactivateSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/deactivate", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteSKU calls DELETE /api/v1/skus/:id. The SKU is soft-deleted and can be
+// brought back with RestoreSKU.
+func (c *Client) DeleteSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodDelete, apiPrefix+"/skus/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateSKU calls PUT /api/v1/skus/:id. The staff member making the change
+// is identified with WithActor and recorded in the price history.
+func (c *Client) UpdateSKU(ctx context.Context, id string, req UpdateSKURequest, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/skus/"+url.PathEscape(id), req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SKUPriceHistory calls GET /api/v1/skus/:id/price-history; oldest change first
+func (c *Client) SKUPriceHistory(ctx context.Context, id string, opts ...RequestOption) ([]PriceChange, error) {
+	var resp struct {
+		Changes []PriceChange `json:"changes"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus/"+url.PathEscape(id)+"/price-history", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Changes, nil
+}
+
+// RecordWeightSamples calls POST /api/v1/skus/:id/weight-samples with the
+// weights of single units of the SKU
+func (c *Client) RecordWeightSamples(ctx context.Context, id string, grams []float64, opts ...RequestOption) (*SKU, error) {
+	req := struct {
+		SamplesGrams []float64 `json:"samples_grams"`
+	}{grams}
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/weight-samples", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RestoreSKU calls POST /api/v1/skus/:id/restore
+func (c *Client) RestoreSKU(ctx context.Context, id string, opts ...RequestOption) (*SKU, error) {
+	var resp SKU
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/skus/"+url.PathEscape(id)+"/restore", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AssignSKUCategory calls PUT /api/v1/skus/:id/category. An empty categoryID
+// removes the SKU from its category.
+func (c *Client) AssignSKUCategory(ctx context.Context, id, categoryID string, opts ...RequestOption) (*SKU, error) {
+	req := struct {
+		CategoryID string `json:"category_id"`
+	}{categoryID}
+	var resp SKU
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/skus/"+url.PathEscape(id)+"/category", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ImportedSKU is a SKU created by an import, with the CSV line it came from
+type ImportedSKU struct {
+	Line int    `json:"line"`
+	ID   string `json:"id"`
+	Code string `json:"code"`
+}
+
+// ImportRowError explains why a CSV line was rejected
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportResult is the outcome of a SKU import
+type ImportResult struct {
+	Imported int              `json:"imported"`
+	SKUs     []ImportedSKU    `json:"skus"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportSKUs calls POST /api/v1/skus/import with csvData as the uploaded file.
+// The import is all or nothing: when any row is invalid nothing is created, the
+// error satisfies IsUnprocessable and the returned result lists every bad row.
+func (c *Client) ImportSKUs(ctx context.Context, csvData []byte, opts ...RequestOption) (*ImportResult, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "skus.csv")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(csvData); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var resp ImportResult
+	err = c.doBody(ctx, http.MethodPost, apiPrefix+"/skus/import", form.FormDataContentType(), body.Bytes(), &resp, rc)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+		_ = json.Unmarshal(apiErr.body, &resp)
+		return &resp, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExportSKUs calls GET /api/v1/skus/export and copies the export, "csv" or
+// "json", into w as it arrives. A CSV export can be edited and fed back to
+// ImportSKUs.
+func (c *Client) ExportSKUs(ctx context.Context, w io.Writer, format string, opts ...RequestOption) error {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, apiPrefix+"/skus/export?format="+url.QueryEscape(format), "", nil, rc)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return newAPIError(resp.StatusCode, body)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil
+}
+
+// BoundingBox marks the SKU in a training image, as fractions of the image's
+// width and height from its top-left corner
+type BoundingBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// TrainingImage is a labeled photo of a SKU for training the recognition model
+type TrainingImage struct {
+	ID          string       `json:"id"`
+	SKUID       string       `json:"sku_id"`
+	SKUCode     string       `json:"sku_code,omitempty"` // set in dataset exports
+	ContentType string       `json:"content_type"`
+	SizeBytes   int          `json:"size_bytes"`
+	Checksum    string       `json:"checksum"`
+	BoundingBox *BoundingBox `json:"bounding_box,omitempty"`
+	Source      string       `json:"source,omitempty"`
+	UploadedBy  string       `json:"uploaded_by,omitempty"`
+	ContentURL  string       `json:"content_url"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type trainingImageListResponse struct {
+	Images []TrainingImage `json:"images"`
+	Count  int             `json:"count"`
+}
+
+// AddTrainingImage calls POST /api/v1/skus/{id}/training-images with a JPEG,
+// PNG or WebP image. box and source are optional. Uploading an image the SKU
+// already has fails with a conflict.
+func (c *Client) AddTrainingImage(ctx context.Context, skuID string, image []byte, box *BoundingBox, source string, opts ...RequestOption) (*TrainingImage, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("image", "image")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(image); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if box != nil {
+		value := fmt.Sprintf("%g,%g,%g,%g", box.X, box.Y, box.Width, box.Height)
+		if err := form.WriteField("bounding_box", value); err != nil {
+			return nil, fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	if source != "" {
+		if err := form.WriteField("source", source); err != nil {
+			return nil, fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var resp TrainingImage
+	path := apiPrefix + "/skus/" + url.PathEscape(skuID) + "/training-images"
+	if err := c.doBody(ctx, http.MethodPost, path, form.FormDataContentType(), body.Bytes(), &resp, rc); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListTrainingImages calls GET /api/v1/skus/{id}/training-images
+func (c *Client) ListTrainingImages(ctx context.Context, skuID string, opts ...RequestOption) ([]TrainingImage, error) {
+	var resp trainingImageListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus/"+url.PathEscape(skuID)+"/training-images", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Images, nil
+}
+
+// TrainingImageContent calls GET /api/v1/skus/{id}/training-images/{image}
+// and returns the image with its content type
+func (c *Client) TrainingImageContent(ctx context.Context, skuID, imageID string, opts ...RequestOption) ([]byte, string, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, apiPrefix+"/skus/"+url.PathEscape(skuID)+"/training-images/"+url.PathEscape(imageID), "", nil, rc)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", newAPIError(resp.StatusCode, body)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// DeleteTrainingImage calls DELETE /api/v1/skus/{id}/training-images/{image}
+func (c *Client) DeleteTrainingImage(ctx context.Context, skuID, imageID string, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/skus/"+url.PathEscape(skuID)+"/training-images/"+url.PathEscape(imageID), nil, nil, opts...)
+}
+
+// ExportTrainingDataset calls GET /api/v1/skus/training-images and returns
+// the training images of the whole catalog, each labeled with its SKU code
+func (c *Client) ExportTrainingDataset(ctx context.Context, opts ...RequestOption) ([]TrainingImage, error) {
+	var resp trainingImageListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/skus/training-images", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Images, nil
+}
+
+// SyncMLClasses calls POST /api/v1/admin/ml/sync-classes to push the
+// class→SKU mapping to the ML server now and returns how many classes it
+// holds. Without a configured ML server the error satisfies IsUnavailable.
+func (c *Client) SyncMLClasses(ctx context.Context, opts ...RequestOption) (int, error) {
+	var resp struct {
+		Classes int `json:"classes"`
+	}
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/admin/ml/sync-classes", nil, &resp, opts...); err != nil {
+		return 0, err
+	}
+	return resp.Classes, nil
+}
+
+// Category is a node in the catalog's category tree
+type Category struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parent_id,omitempty"` // empty for top-level categories
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryRequest is the payload for creating or updating a category
+type CategoryRequest struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+type categoryListResponse struct {
+	Categories []Category `json:"categories"`
+	Count      int        `json:"count"`
+}
+
+// CreateCategory calls POST /api/v1/categories
+func (c *Client) CreateCategory(ctx context.Context, req CategoryRequest, opts ...RequestOption) (*Category, error) {
+	var resp Category
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/categories", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListCategories calls GET /api/v1/categories
+func (c *Client) ListCategories(ctx context.Context, opts ...RequestOption) ([]Category, error) {
+	var resp categoryListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/categories", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Categories, nil
+}
+
+// GetCategory calls GET /api/v1/categories/:id
+func (c *Client) GetCategory(ctx context.Context, id string, opts ...RequestOption) (*Category, error) {
+	var resp Category
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/categories/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateCategory calls PUT /api/v1/categories/:id. An empty ParentID moves the
+// category to the top level.
+func (c *Client) UpdateCategory(ctx context.Context, id string, req CategoryRequest, opts ...RequestOption) (*Category, error) {
+	var resp Category
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/categories/"+url.PathEscape(id), req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteCategory calls DELETE /api/v1/categories/:id. Its SKUs become
+// uncategorized; a category with subcategories cannot be deleted.
+func (c *Client) DeleteCategory(ctx context.Context, id string, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/categories/"+url.PathEscape(id), nil, nil, opts...)
+}
+
+// Class ties a detection model class to the SKU it recognizes
+type Class struct {
+	ClassID int    `json:"class_id"`
+	SKUID   string `json:"sku_id"`
+	SKUCode string `json:"sku_code"`
+}
+
+// AssignClassRequest maps a class to a SKU
+type AssignClassRequest struct {
+	SKUID   string `json:"sku_id"`
+	ClassID *int   `json:"class_id,omitempty"` // the next free class ID when nil
+}
+
+// ListClasses calls GET /api/v1/admin/ml/classes
+func (c *Client) ListClasses(ctx context.Context, opts ...RequestOption) ([]Class, error) {
+	var resp struct {
+		Classes []Class `json:"classes"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/admin/ml/classes", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Classes, nil
+}
+
+// GetClass calls GET /api/v1/admin/ml/classes/{class_id}
+func (c *Client) GetClass(ctx context.Context, classID int, opts ...RequestOption) (*Class, error) {
+	var resp Class
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/admin/ml/classes/"+strconv.Itoa(classID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AssignClass calls POST /api/v1/admin/ml/classes
+func (c *Client) AssignClass(ctx context.Context, req AssignClassRequest, opts ...RequestOption) (*Class, error) {
+	var resp Class
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/admin/ml/classes", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReassignClass calls PUT /api/v1/admin/ml/classes/{class_id}, pointing the
+// class at another SKU
+func (c *Client) ReassignClass(ctx context.Context, classID int, skuID string, opts ...RequestOption) (*Class, error) {
+	var resp Class
+	req := struct {
+		SKUID string `json:"sku_id"`
+	}{SKUID: skuID}
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/admin/ml/classes/"+strconv.Itoa(classID), req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RemoveClass calls DELETE /api/v1/admin/ml/classes/{class_id}
+func (c *Client) RemoveClass(ctx context.Context, classID int, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/admin/ml/classes/"+strconv.Itoa(classID), nil, nil, opts...)
+}
@@ -0,0 +1,288 @@
+// Package client provides a typed HTTP client for the vending machine server API.
+//
+// It is used by internal tools and the device agent so they don't have to
+// hand-roll requests against the REST endpoints.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTimeout    = 15 * time.Second
+	defaultMaxRetries = 3
+	defaultRetryWait  = 200 * time.Millisecond
+	maxRetryWait      = 5 * time.Second
+
+	idempotencyKeyHeader = "Idempotency-Key"
+	apiPrefix            = "/api/v1"
+)
+
+// Client is an HTTP client for the server API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       func(req *http.Request)
+	userAgent  string
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithBearerToken authenticates every request with an Authorization bearer token
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+// WithAPIKey authenticates every request with the given header and key
+func WithAPIKey(header, key string) Option {
+	return func(c *Client) {
+		c.auth = func(req *http.Request) {
+			req.Header.Set(header, key)
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRetries sets how many times a retryable request is retried and the
+// base wait between attempts (doubled on each attempt, with jitter)
+func WithRetries(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+// New creates a client for the server at baseURL (e.g. "http://localhost:8080")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "vending-machine-client/1",
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RequestOption configures a single API call
+type RequestOption func(*requestConfig)
+
+type requestConfig struct {
+	idempotencyKey string
+	deduplicated   bool // the server replays a request resent with the same key
+	header         http.Header
+}
+
+// WithIdempotencyKey sends an Idempotency-Key header. Only the detection
+// endpoints deduplicate by it, and SubmitDetection already sends the
+// DetectionID as the key; elsewhere the server ignores it, so a key does not
+// make a request retried.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(rc *requestConfig) {
+		rc.idempotencyKey = key
+	}
+}
+
+// deduplicatedBy sends the key for an endpoint that replays a request resent
+// with the same key, so the request is retried like a GET
+func deduplicatedBy(key string) RequestOption {
+	return func(rc *requestConfig) {
+		rc.idempotencyKey = key
+		rc.deduplicated = true
+	}
+}
+
+// WithNewIdempotencyKey sends a freshly generated Idempotency-Key header
+func WithNewIdempotencyKey() RequestOption {
+	return WithIdempotencyKey(uuid.NewString())
+}
+
+// WithHeader adds an extra header to a single request
+func WithHeader(key, value string) RequestOption {
+	return func(rc *requestConfig) {
+		if rc.header == nil {
+			rc.header = http.Header{}
+		}
+		rc.header.Add(key, value)
+	}
+}
+
+// WithRegion tags a request with the region the caller's data lives in.
+// A server for another region answers with 421 (see IsMisdirected).
+func WithRegion(region string) RequestOption {
+	return WithHeader("X-Region", region)
+}
+
+// WithLanguage asks for SKU names in the given languages, as an
+// Accept-Language value such as "de-CH, de;q=0.9, en;q=0.5"
+func WithLanguage(languages string) RequestOption {
+	return WithHeader("Accept-Language", languages)
+}
+
+// Health calls GET /health and returns nil when the server is up
+func (c *Client) Health(ctx context.Context, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil, opts...)
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil).
+// GET requests and requests the server deduplicates by their idempotency key
+// are retried on network errors, 429 and 5xx responses.
+func (c *Client) do(ctx context.Context, method, path string, in, out any, opts ...RequestOption) error {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+	return c.doBody(ctx, method, path, "application/json", body, out, rc)
+}
+
+// doBody sends an already encoded body of the given content type
+func (c *Client) doBody(ctx context.Context, method, path, contentType string, body []byte, out any, rc requestConfig) error {
+	retryable := method == http.MethodGet || rc.deduplicated
+	attempts := 1
+	if retryable {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, contentType, body, rc)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read response: %w", err)
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			apiErr := newAPIError(resp.StatusCode, respBody)
+			if isRetryableStatus(resp.StatusCode) {
+				lastErr = apiErr
+				continue
+			}
+			return apiErr
+		}
+
+		if out != nil && len(respBody) > 0 {
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return lastErr
+}
+
+func (c *Client) send(ctx context.Context, method, path, contentType string, body []byte, rc requestConfig) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if rc.idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, rc.idempotencyKey)
+	}
+	for key, values := range rc.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if c.auth != nil {
+		c.auth(req)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// sleep waits before the given retry attempt
+func (c *Client) sleep(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(c.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff is the wait before the given retry attempt: the base wait doubled
+// on each attempt up to maxRetryWait, of which the second half is jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retryWait << (attempt - 1)
+	if wait > maxRetryWait || wait <= 0 {
+		wait = maxRetryWait
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer answers the first failures requests with the status and
+// every later one with an empty JSON object, counting the requests
+func failingServer(t *testing.T, status, failures int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestGetRequestsAreRetriedOnServerErrors(t *testing.T) {
+	srv, requests := failingServer(t, http.StatusServiceUnavailable, 2)
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+
+	if err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestRetriesGiveUpWithTheLastError(t *testing.T) {
+	srv, requests := failingServer(t, http.StatusBadGateway, 10)
+	c := New(srv.URL, WithRetries(2, time.Millisecond))
+
+	err := c.Health(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("error = %v, want a 502 APIError", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	srv, requests := failingServer(t, http.StatusNotFound, 10)
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+
+	if err := c.Health(context.Background()); !IsNotFound(err) {
+		t.Fatalf("error = %v, want 404", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestPostRequestsAreNotRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []RequestOption
+	}{
+		{"without an idempotency key", nil},
+		// The server only deduplicates detections by the key
+		{"with an idempotency key", []RequestOption{WithIdempotencyKey("confirm-1")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := failingServer(t, http.StatusServiceUnavailable, 10)
+			c := New(srv.URL, WithRetries(3, time.Millisecond))
+
+			if _, err := c.ConfirmSession(context.Background(), "session-1", "PAY-1", tt.opts...); !IsUnavailable(err) {
+				t.Fatalf("error = %v, want 503", err)
+			}
+			if got := requests.Load(); got != 1 {
+				t.Errorf("requests = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestDetectionsWithADetectionIDAreRetriedUnderTheSameKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"session_id":"session-1"}`))
+	}))
+	defer srv.Close()
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+
+	resp, err := c.SubmitDetection(context.Background(), SubmitDetectionRequest{SessionID: "session-1", DetectionID: "frame-7"})
+	if err != nil {
+		t.Fatalf("SubmitDetection: %v", err)
+	}
+	if resp.SessionID != "session-1" {
+		t.Errorf("session = %q, want session-1", resp.SessionID)
+	}
+	if len(keys) != 2 || keys[0] != "frame-7" || keys[1] != "frame-7" {
+		t.Errorf("idempotency keys = %v, want frame-7 twice", keys)
+	}
+}
+
+func TestDetectionsWithoutADetectionIDAreNotRetried(t *testing.T) {
+	srv, requests := failingServer(t, http.StatusServiceUnavailable, 10)
+	c := New(srv.URL, WithRetries(3, time.Millisecond))
+
+	if _, err := c.SubmitDetection(context.Background(), SubmitDetectionRequest{SessionID: "session-1"}); !IsUnavailable(err) {
+		t.Fatalf("error = %v, want 503", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetriesStopWhenTheContextIsDone(t *testing.T) {
+	srv, requests := failingServer(t, http.StatusServiceUnavailable, 10)
+	c := New(srv.URL, WithRetries(3, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Health(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want the context deadline", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestBackoffDoublesUpToTheMaximumWait(t *testing.T) {
+	c := New("http://example.invalid", WithRetries(10, 100*time.Millisecond))
+	tests := []struct {
+		attempt int
+		wait    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{7, maxRetryWait},
+		{80, maxRetryWait}, // the shift overflows
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := c.backoff(tt.attempt)
+			if got < tt.wait/2 || got > tt.wait {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.wait/2, tt.wait)
+			}
+		}
+	}
+}
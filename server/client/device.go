@@ -0,0 +1,2018 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// EnrollmentTokenRequest is the payload for provisioning a machine
+type EnrollmentTokenRequest struct {
+	MachineID string `json:"machine_id"`
+	Name      string `json:"name,omitempty"`
+	Location  string `json:"location,omitempty"`
+	// Override allows enrolling a decommissioned machine ID again
+	Override bool `json:"override,omitempty"`
+}
+
+// EnrollmentToken is the one-time token a device enrolls with
+type EnrollmentToken struct {
+	MachineID string    `json:"machine_id"`
+	Token     string    `json:"enrollment_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EnrollDeviceRequest is the payload a device enrolls with
+type EnrollDeviceRequest struct {
+	MachineID       string `json:"machine_id"`
+	EnrollmentToken string `json:"enrollment_token"`
+}
+
+// EnrollDeviceResponse is returned after enrolling a device
+type EnrollDeviceResponse struct {
+	ID        string `json:"id"`
+	MachineID string `json:"machine_id"`
+	Region    string `json:"region,omitempty"`
+	Message   string `json:"message"`
+	// DeviceKey authenticates the device's own requests; any earlier key
+	// of the device stops working
+	DeviceKey string `json:"device_key"`
+}
+
+// DeviceSKU is the reduced SKU representation used for device sync
+type DeviceSKU struct {
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`                  // in the requested language when translated
+	Description     string  `json:"description,omitempty"` // translated SKUs only
+	WeightGrams     float64 `json:"weight_grams"`
+	WeightTolerance float64 `json:"weight_tolerance"`
+	ClassID         *int    `json:"class_id"` // the detection model class; nil when the SKU has none
+}
+
+// DetectedItem is a single item reported by a device
+type DetectedItem struct {
+	SKU        string    `json:"sku"`
+	Confidence float64   `json:"confidence"`
+	BBox       []float64 `json:"bbox,omitempty"`
+}
+
+// SubmitDetectionRequest is the payload for submitting detection results
+type SubmitDetectionRequest struct {
+	DeviceID    string         `json:"device_id"`
+	SessionID   string         `json:"session_id"`
+	Items       []DetectedItem `json:"items"`
+	TotalWeight float64        `json:"total_weight"`
+	Image       []byte         `json:"image,omitempty"` // camera frame, kept for nightly reconciliation
+	// ModelVersion is the detection model that produced the items; detections
+	// from another model than the one assigned to the device go to cloud ML
+	ModelVersion string `json:"model_version,omitempty"`
+	// DetectionID identifies the frame; a detection resent with the same ID
+	// gets the original result back instead of being processed again
+	DetectionID string `json:"detection_id,omitempty"`
+	// Sequence orders the frames of a session; a frame no newer than one
+	// already applied is refused with 409
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
+// DetectionChangesRequest is an incremental frame: the items placed on and
+// taken off the scale since the previous frame of the session
+type DetectionChangesRequest struct {
+	DeviceID     string         `json:"device_id"`
+	SessionID    string         `json:"session_id"`
+	Sequence     int64          `json:"sequence"` // positive, increasing with every frame
+	Added        []DetectedItem `json:"added,omitempty"`
+	Removed      []DetectedItem `json:"removed,omitempty"`
+	TotalWeight  float64        `json:"total_weight"` // of the whole basket
+	Image        []byte         `json:"image,omitempty"`
+	ModelVersion string         `json:"model_version,omitempty"`
+	DetectionID  string         `json:"detection_id,omitempty"`
+}
+
+// SessionItem is a priced line of a session; identical units of a SKU make
+// up one line with a quantity
+type SessionItem struct {
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`                  // in the requested language when translated
+	Description    string  `json:"description,omitempty"` // translated SKUs only
+	PriceCents     int64   `json:"price_cents"`           // of one unit
+	Quantity       int     `json:"quantity"`
+	LineTotalCents int64   `json:"line_total_cents"`
+	Currency       string  `json:"currency"`
+	Confidence     float64 `json:"confidence"` // lowest of the line's units
+	// MarkdownPercent is the discount already applied to PriceCents for a
+	// batch close to expiry
+	MarkdownPercent int `json:"markdown_percent,omitempty"`
+	// Bundle is the code of the bundle the item was sold in; PriceCents is
+	// then the item's share of the bundle price
+	Bundle string `json:"bundle,omitempty"`
+	// Suspicious is set on detections of SKUs not assigned to the device
+	Suspicious bool `json:"suspicious,omitempty"`
+	// Review is "pending" on a line detected below the confidence threshold
+	// until an attendant reviews it with ReviewItem, then "accepted"
+	Review string `json:"review,omitempty"`
+	// BBoxes are the [x, y, width, height] boxes of the line's units that
+	// came with one, kept across later detections
+	BBoxes [][]float64 `json:"bboxes,omitempty"`
+}
+
+// SubmitDetectionResponse is returned after submitting detection results
+type SubmitDetectionResponse struct {
+	SessionID     string        `json:"session_id"`
+	Items         []SessionItem `json:"items"`
+	SubtotalCents int64         `json:"subtotal_cents"`
+	TaxCents      int64         `json:"tax_cents"`
+	RoundingCents int64         `json:"rounding_cents"` // cash rounding, TotalCents minus SubtotalCents
+	TotalCents    int64         `json:"total_cents"`
+	Currency      string        `json:"currency"`
+	WeightMatch   bool          `json:"weight_match"`
+	// NeedsCloudML asks the device to upload its image with VerifyImage
+	NeedsCloudML bool `json:"needs_cloud_ml"`
+	// ModelVersionMismatch is set when the device ran another model than
+	// ExpectedModelVersion, the one assigned to it
+	ModelVersionMismatch bool   `json:"model_version_mismatch"`
+	ExpectedModelVersion string `json:"expected_model_version,omitempty"`
+	// UnrecognizedItems are the detected codes the catalog does not sell,
+	// left out of the basket
+	UnrecognizedItems []UnrecognizedItem `json:"unrecognized_items,omitempty"`
+}
+
+// UnrecognizedItem is a detected code the catalog does not sell
+type UnrecognizedItem struct {
+	Code       string    `json:"code"`
+	Confidence float64   `json:"confidence"`
+	BBox       []float64 `json:"bbox,omitempty"` // [x, y, width, height]
+}
+
+// CreateEnrollmentToken calls POST /api/v1/devices/enrollment-tokens. The
+// operator must be identified with WithActor.
+func (c *Client) CreateEnrollmentToken(ctx context.Context, req EnrollmentTokenRequest, opts ...RequestOption) (*EnrollmentToken, error) {
+	var resp EnrollmentToken
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/enrollment-tokens", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// EnrollDevice calls POST /api/v1/device/enroll. Tokens are used up by the
+// first attempt, so it is not retried; a lost response needs a new token.
+func (c *Client) EnrollDevice(ctx context.Context, req EnrollDeviceRequest, opts ...RequestOption) (*EnrollDeviceResponse, error) {
+	var resp EnrollDeviceResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/enroll", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceSKUs calls GET /api/v1/device/skus
+func (c *Client) DeviceSKUs(ctx context.Context, opts ...RequestOption) ([]DeviceSKU, error) {
+	var resp struct {
+		SKUs []DeviceSKU `json:"skus"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device/skus", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// DeviceSKUChange is a SKU created, updated, deactivated or deleted since the
+// last sync. SKUs no longer on sale have Active false and should be dropped.
+type DeviceSKUChange struct {
+	DeviceSKU
+	Active    bool      `json:"active"`
+	Deleted   bool      `json:"deleted"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeviceSKUChanges is a page of the catalog change feed
+type DeviceSKUChanges struct {
+	SKUs []DeviceSKUChange `json:"skus"`
+	// Cursor is the since to pass on the next sync
+	Cursor time.Time `json:"cursor"`
+}
+
+// DeviceSKUChangesSince calls GET /api/v1/device/skus?since=, returning only
+// the SKUs changed after since
+func (c *Client) DeviceSKUChangesSince(ctx context.Context, since time.Time, opts ...RequestOption) (*DeviceSKUChanges, error) {
+	query := url.Values{"since": {since.UTC().Format(time.RFC3339Nano)}}
+	var resp DeviceSKUChanges
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device/skus?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StartToken is a signed session-start token to embed in the device QR code
+type StartToken struct {
+	MachineID string    `json:"machine_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DeviceStartToken calls GET /api/v1/device/start-token, authenticated with
+// the key the device was issued
+func (c *Client) DeviceStartToken(ctx context.Context, deviceKey, machineID string, opts ...RequestOption) (*StartToken, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	var resp StartToken
+	path := apiPrefix + "/device/start-token?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SubmitDetection calls POST /api/v1/device/detection. A request with a
+// DetectionID is sent with it as the idempotency key, so it is retried
+// without being processed twice; one without is not retried.
+func (c *Client) SubmitDetection(ctx context.Context, req SubmitDetectionRequest, opts ...RequestOption) (*SubmitDetectionResponse, error) {
+	if req.DetectionID != "" {
+		opts = append([]RequestOption{deduplicatedBy(req.DetectionID)}, opts...)
+	}
+	var resp SubmitDetectionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/detection", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SubmitDetectionChanges calls POST /api/v1/device/detection/changes and
+// returns the whole basket after the change. Like SubmitDetection, only a
+// request with a DetectionID is retried.
+func (c *Client) SubmitDetectionChanges(ctx context.Context, req DetectionChangesRequest, opts ...RequestOption) (*SubmitDetectionResponse, error) {
+	if req.DetectionID != "" {
+		opts = append([]RequestOption{deduplicatedBy(req.DetectionID)}, opts...)
+	}
+	var resp SubmitDetectionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/detection/changes", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifiedItem compares the units of a SKU the device and the cloud model saw
+type VerifiedItem struct {
+	SKU           string `json:"sku"`
+	EdgeQuantity  int    `json:"edge_quantity"`
+	CloudQuantity int    `json:"cloud_quantity"`
+	Quantity      int    `json:"quantity"` // in the basket after verification
+}
+
+// VerifyImageResponse is the basket after cloud verification
+type VerifyImageResponse struct {
+	SubmitDetectionResponse
+	ModelVersion string         `json:"model_version"`
+	Strategy     string         `json:"strategy"` // how the device's and the cloud model's detections were reconciled
+	Verified     []VerifiedItem `json:"verified"`
+	Corrected    bool           `json:"corrected"` // the basket changed
+}
+
+// VerifyImage calls POST /api/v1/session/:id/verify-image with the captured
+// image, after a detection came back with NeedsCloudML
+func (c *Client) VerifyImage(ctx context.Context, sessionID string, image []byte, opts ...RequestOption) (*VerifyImageResponse, error) {
+	req := struct {
+		Image []byte `json:"image"`
+	}{Image: image}
+	var resp VerifyImageResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/verify-image", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Offline entry types accepted by SyncOfflineEntries
+const (
+	OfflineEntryDetection = "detection"
+	OfflineEntryCancel    = "cancel"
+)
+
+// OfflineEntry is a detection or state transition a device buffered while offline.
+// ClientID must be unique per device; it makes resending an entry safe.
+type OfflineEntry struct {
+	ClientID    string         `json:"client_id"`
+	Type        string         `json:"type"`
+	SessionID   string         `json:"session_id"`
+	RecordedAt  time.Time      `json:"recorded_at"`
+	Items       []DetectedItem `json:"items,omitempty"`
+	TotalWeight float64        `json:"total_weight,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+// SyncOfflineEntriesRequest is the payload for syncing a device's offline buffer
+type SyncOfflineEntriesRequest struct {
+	DeviceID string         `json:"device_id"`
+	Entries  []OfflineEntry `json:"entries"`
+}
+
+// OfflineEntryResult is the outcome of one synced entry: "applied", "duplicate",
+// "conflict", "rejected", or "retry" when the entry should be sent again later
+type OfflineEntryResult struct {
+	ClientID       string `json:"client_id"`
+	SessionID      string `json:"session_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+}
+
+// SyncOfflineEntriesResponse lists the outcomes in the order the entries were sent
+type SyncOfflineEntriesResponse struct {
+	DeviceID string               `json:"device_id"`
+	Results  []OfflineEntryResult `json:"results"`
+	Summary  map[string]int       `json:"summary"`
+}
+
+// SyncOfflineEntries calls POST /api/v1/device/sync. Entries are answered once
+// per client ID, so a batch can be resent until a response arrives.
+func (c *Client) SyncOfflineEntries(ctx context.Context, req SyncOfflineEntriesRequest, opts ...RequestOption) (*SyncOfflineEntriesResponse, error) {
+	var resp SyncOfflineEntriesResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/sync", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceSignalRequest reports that the customer left the device: Signal is
+// "door_closed" or "items_removed"
+type DeviceSignalRequest struct {
+	DeviceID  string `json:"device_id"`
+	SessionID string `json:"session_id"`
+	Signal    string `json:"signal"`
+}
+
+// DeviceSignalResponse names the first signal of the session; it is cancelled
+// at CancelAt if it holds items and is still unpaid
+type DeviceSignalResponse struct {
+	SessionID  string    `json:"session_id"`
+	Signal     string    `json:"signal"`
+	SignaledAt time.Time `json:"signaled_at"`
+	CancelAt   time.Time `json:"cancel_at"`
+}
+
+// SignalDevice calls POST /api/v1/device/signal
+func (c *Client) SignalDevice(ctx context.Context, req DeviceSignalRequest, opts ...RequestOption) (*DeviceSignalResponse, error) {
+	var resp DeviceSignalResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/signal", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ShelfSnapshotRequest is a shelf photo taken outside a session
+type ShelfSnapshotRequest struct {
+	MachineID  string     `json:"machine_id"`
+	Image      []byte     `json:"image"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+}
+
+// StockLevel is the estimated quantity of one SKU on a device
+type StockLevel struct {
+	SKUCode   string `json:"sku_code"`
+	Estimated int    `json:"estimated_quantity"`
+	Low       bool   `json:"low"`
+}
+
+// StockEstimate is a device's vision-based stock estimate
+type StockEstimate struct {
+	MachineID    string       `json:"machine_id"`
+	ModelVersion string       `json:"model_version"`
+	ObservedAt   *time.Time   `json:"observed_at"`
+	LowThreshold int          `json:"low_threshold"`
+	Levels       []StockLevel `json:"levels"`
+}
+
+// SubmitShelfSnapshot calls POST /api/v1/device/snapshot
+func (c *Client) SubmitShelfSnapshot(ctx context.Context, req ShelfSnapshotRequest, opts ...RequestOption) (*StockEstimate, error) {
+	var resp StockEstimate
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/snapshot", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceStock calls GET /api/v1/device/stock
+func (c *Client) DeviceStock(ctx context.Context, machineID string, opts ...RequestOption) (*StockEstimate, error) {
+	var resp StockEstimate
+	path := apiPrefix + "/device/stock?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TelemetryRequest is a periodic device reading
+type TelemetryRequest struct {
+	MachineID          string     `json:"machine_id"`
+	RecordedAt         *time.Time `json:"recorded_at,omitempty"`
+	TemperatureCelsius *float64   `json:"temperature_celsius,omitempty"`
+	DoorOpen           *bool      `json:"door_open,omitempty"`
+}
+
+// DeviceStatus is the device state after telemetry or clearance
+type DeviceStatus struct {
+	MachineID string `json:"machine_id"`
+	Status    string `json:"status"`
+	Incident  string `json:"incident,omitempty"` // security incident raised by this report
+}
+
+// TemperatureExcursion is a recorded over-temperature period that blocked a device
+type TemperatureExcursion struct {
+	ID           string     `json:"id"`
+	StartedAt    time.Time  `json:"started_at"`
+	DetectedAt   time.Time  `json:"detected_at"`
+	LimitCelsius float64    `json:"limit_celsius"`
+	PeakCelsius  float64    `json:"peak_celsius"`
+	ClearedAt    *time.Time `json:"cleared_at,omitempty"`
+	ClearedBy    string     `json:"cleared_by,omitempty"`
+	Note         string     `json:"note,omitempty"`
+}
+
+// SendTelemetry calls POST /api/v1/device/telemetry
+func (c *Client) SendTelemetry(ctx context.Context, req TelemetryRequest, opts ...RequestOption) (*DeviceStatus, error) {
+	var resp DeviceStatus
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/telemetry", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClearDevice calls POST /api/v1/device/clear. The clearing operator must be
+// identified with WithActor.
+func (c *Client) ClearDevice(ctx context.Context, machineID, note string, opts ...RequestOption) (*DeviceStatus, error) {
+	req := struct {
+		MachineID string `json:"machine_id"`
+		Note      string `json:"note,omitempty"`
+	}{MachineID: machineID, Note: note}
+
+	var resp DeviceStatus
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/clear", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DecommissionResult is returned after decommissioning a device
+type DecommissionResult struct {
+	ID                 string    `json:"id"`
+	MachineID          string    `json:"machine_id"`
+	Status             string    `json:"status"`
+	DecommissionedAt   time.Time `json:"decommissioned_at"`
+	ArchivedExcursions int       `json:"archived_excursions"`
+	ArchivedIncidents  int       `json:"archived_incidents"`
+}
+
+// DecommissionDevice calls POST /api/v1/device/decommission. The operator
+// must be identified with WithActor.
+func (c *Client) DecommissionDevice(ctx context.Context, machineID string, opts ...RequestOption) (*DecommissionResult, error) {
+	req := struct {
+		MachineID string `json:"machine_id"`
+	}{MachineID: machineID}
+
+	var resp DecommissionResult
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/decommission", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MaintenanceResult is returned after a device entered or left maintenance
+type MaintenanceResult struct {
+	ID               string     `json:"id"`
+	MachineID        string     `json:"machine_id"`
+	Status           string     `json:"status"`
+	MaintenanceSince *time.Time `json:"maintenance_since,omitempty"`
+}
+
+// EnterMaintenance calls POST /api/v1/device/maintenance/enter. The
+// technician must be identified with WithActor.
+func (c *Client) EnterMaintenance(ctx context.Context, machineID, reason string, opts ...RequestOption) (*MaintenanceResult, error) {
+	req := struct {
+		MachineID string `json:"machine_id"`
+		Reason    string `json:"reason,omitempty"`
+	}{MachineID: machineID, Reason: reason}
+
+	var resp MaintenanceResult
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/maintenance/enter", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExitMaintenance calls POST /api/v1/device/maintenance/exit. The
+// technician must be identified with WithActor.
+func (c *Client) ExitMaintenance(ctx context.Context, machineID string, opts ...RequestOption) (*MaintenanceResult, error) {
+	req := struct {
+		MachineID string `json:"machine_id"`
+	}{MachineID: machineID}
+
+	var resp MaintenanceResult
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/maintenance/exit", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceExcursions calls GET /api/v1/device/excursions
+func (c *Client) DeviceExcursions(ctx context.Context, machineID string, opts ...RequestOption) ([]TemperatureExcursion, error) {
+	var resp []TemperatureExcursion
+	path := apiPrefix + "/device/excursions?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SecurityIncident is a door opening that did not match session state
+type SecurityIncident struct {
+	ID           string    `json:"id"`
+	Kind         string    `json:"kind"`
+	DoorOpenedAt time.Time `json:"door_opened_at"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// DeviceIncidents calls GET /api/v1/device/incidents
+func (c *Client) DeviceIncidents(ctx context.Context, machineID string, opts ...RequestOption) ([]SecurityIncident, error) {
+	var resp []SecurityIncident
+	path := apiPrefix + "/device/incidents?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeviceEvent is one door, tamper or power event in a device's event log.
+// Kind is door_opened, door_closed, tamper, power_lost or power_restored.
+type DeviceEvent struct {
+	ID         string    `json:"id,omitempty"`
+	Kind       string    `json:"kind"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ReceivedAt time.Time `json:"received_at,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// DeviceEventUpload is returned after uploading device events
+type DeviceEventUpload struct {
+	MachineID  string `json:"machine_id"`
+	Received   int    `json:"received"`
+	Duplicates int    `json:"duplicates"` // logged by an earlier upload already
+}
+
+// RecordDeviceEvents calls POST /api/v1/device/events, authenticated with the
+// key the device was issued. Up to 500 events can be uploaded at once.
+func (c *Client) RecordDeviceEvents(ctx context.Context, deviceKey, machineID string, events []DeviceEvent, opts ...RequestOption) (*DeviceEventUpload, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string        `json:"machine_id"`
+		Events    []DeviceEvent `json:"events"`
+	}{MachineID: machineID, Events: events}
+
+	var resp DeviceEventUpload
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/events", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceEventFilter narrows DeviceEvents; zero fields don't filter
+type DeviceEventFilter struct {
+	From  time.Time // occurred at or after
+	To    time.Time // occurred before
+	Limit int       // zero uses the server's default of 100
+}
+
+// DeviceEvents calls GET /api/v1/device/events, newest first
+func (c *Client) DeviceEvents(ctx context.Context, machineID string, filter DeviceEventFilter, opts ...RequestOption) ([]DeviceEvent, error) {
+	query := url.Values{"machine_id": {machineID}}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var resp []DeviceEvent
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device/events?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PlanogramFacing is one planned SKU position on a shelf (shelves are numbered from 1, top down)
+type PlanogramFacing struct {
+	Shelf   int    `json:"shelf"`
+	SKUCode string `json:"sku_code"`
+	Facings int    `json:"facings"`
+}
+
+// Planogram is a device's merchandising layout
+type Planogram struct {
+	MachineID string            `json:"machine_id"`
+	Version   int               `json:"version"`
+	Source    string            `json:"source"` // device, or group when the device follows its group's layout
+	Facings   []PlanogramFacing `json:"facings"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// RestockVisitRequest is the shelf photo field staff take after restocking
+type RestockVisitRequest struct {
+	MachineID  string     `json:"machine_id"`
+	Image      []byte     `json:"image"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+}
+
+// PlanogramDeviation is one line of a misplacement report
+type PlanogramDeviation struct {
+	Shelf    int    `json:"shelf"`
+	SKUCode  string `json:"sku_code"`
+	Kind     string `json:"kind"` // missing, misplaced or unplanned
+	Expected int    `json:"expected_facings"`
+	Found    int    `json:"found_facings"`
+}
+
+// ComplianceReport is the planogram check of one restock visit
+type ComplianceReport struct {
+	ID               string               `json:"id"`
+	PlanogramVersion int                  `json:"planogram_version"`
+	VisitedBy        string               `json:"visited_by"`
+	Score            int                  `json:"score"` // percent
+	Compliant        bool                 `json:"compliant"`
+	Deviations       []PlanogramDeviation `json:"deviations"`
+	CheckedAt        time.Time            `json:"checked_at"`
+}
+
+// AssignPlanogram calls PUT /api/v1/device/planogram
+func (c *Client) AssignPlanogram(ctx context.Context, machineID string, facings []PlanogramFacing, opts ...RequestOption) (*Planogram, error) {
+	req := struct {
+		MachineID string            `json:"machine_id"`
+		Facings   []PlanogramFacing `json:"facings"`
+	}{MachineID: machineID, Facings: facings}
+
+	var resp Planogram
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/device/planogram", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DevicePlanogram calls GET /api/v1/device/planogram
+func (c *Client) DevicePlanogram(ctx context.Context, machineID string, opts ...RequestOption) (*Planogram, error) {
+	var resp Planogram
+	path := apiPrefix + "/device/planogram?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RecordRestockVisit calls POST /api/v1/device/restock-visit. The visiting
+// staff member is identified with WithActor.
+func (c *Client) RecordRestockVisit(ctx context.Context, req RestockVisitRequest, opts ...RequestOption) (*ComplianceReport, error) {
+	var resp ComplianceReport
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/restock-visit", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceComplianceReports calls GET /api/v1/device/compliance
+func (c *Client) DeviceComplianceReports(ctx context.Context, machineID string, opts ...RequestOption) ([]ComplianceReport, error) {
+	var resp []ComplianceReport
+	path := apiPrefix + "/device/compliance?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SalesWindow is a daily time range in the device's local time. An End before
+// Start runs past midnight; "24:00" ends at the end of the day.
+type SalesWindow struct {
+	Days  []string `json:"days,omitempty"` // "mon".."sun"; empty means every day
+	Start string   `json:"start"`          // "HH:MM"
+	End   string   `json:"end"`            // "HH:MM"
+}
+
+// SalesBlackout blocks sales during a window, for the whole device or only the listed SKUs
+type SalesBlackout struct {
+	SalesWindow
+	SKUCodes []string `json:"sku_codes,omitempty"`
+	Reason   string   `json:"reason"`
+}
+
+// SetSalesHoursRequest replaces a device's opening hours and blackout windows
+type SetSalesHoursRequest struct {
+	MachineID    string          `json:"machine_id"`
+	Timezone     string          `json:"timezone,omitempty"` // IANA name; defaults to UTC
+	OpeningHours []SalesWindow   `json:"opening_hours"`      // empty means open around the clock
+	Blackouts    []SalesBlackout `json:"blackouts"`
+}
+
+// RestrictedSKU is a SKU that may not be sold right now
+type RestrictedSKU struct {
+	SKUCode string `json:"sku_code"`
+	Reason  string `json:"reason"`
+}
+
+// SalesHours is a device's sales hours and whether it sells right now
+type SalesHours struct {
+	MachineID      string          `json:"machine_id"`
+	Timezone       string          `json:"timezone"`
+	Configured     bool            `json:"configured"`
+	OpeningHours   []SalesWindow   `json:"opening_hours"`
+	Blackouts      []SalesBlackout `json:"blackouts"`
+	UpdatedAt      *time.Time      `json:"updated_at,omitempty"`
+	OpenNow        bool            `json:"open_now"`
+	ClosedReason   string          `json:"closed_reason,omitempty"`
+	RestrictedSKUs []RestrictedSKU `json:"restricted_skus"`
+}
+
+// DeviceDetails is a device as shown in the customer app
+type DeviceDetails struct {
+	ID         string     `json:"id"`
+	MachineID  string     `json:"machine_id"`
+	Name       string     `json:"name"`
+	Location   string     `json:"location"`
+	Region     string     `json:"region,omitempty"`
+	Status     string     `json:"status"`
+	SalesHours SalesHours `json:"sales_hours"`
+}
+
+// SetSalesHours calls PUT /api/v1/device/sales-hours
+func (c *Client) SetSalesHours(ctx context.Context, req SetSalesHoursRequest, opts ...RequestOption) (*SalesHours, error) {
+	var resp SalesHours
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/device/sales-hours", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceSalesHours calls GET /api/v1/device/sales-hours
+func (c *Client) DeviceSalesHours(ctx context.Context, machineID string, opts ...RequestOption) (*SalesHours, error) {
+	var resp SalesHours
+	path := apiPrefix + "/device/sales-hours?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceDetails calls GET /api/v1/device/details
+func (c *Client) DeviceDetails(ctx context.Context, machineID string, opts ...RequestOption) (*DeviceDetails, error) {
+	var resp DeviceDetails
+	path := apiPrefix + "/device/details?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BatchInput is one batch loaded during a restock
+type BatchInput struct {
+	SKUCode   string `json:"sku_code"`
+	Quantity  int    `json:"quantity"`
+	ExpiresOn string `json:"expires_on,omitempty"` // "YYYY-MM-DD", last day the batch may be sold; empty takes the SKU's shelf life
+}
+
+// RecordBatchesRequest records the batches loaded during a restock
+type RecordBatchesRequest struct {
+	MachineID   string       `json:"machine_id"`
+	RestockedAt *time.Time   `json:"restocked_at,omitempty"`
+	Batches     []BatchInput `json:"batches"`
+}
+
+// StockBatch is a batch of one SKU on a device with its expiry date
+type StockBatch struct {
+	ID              string     `json:"id"`
+	MachineID       string     `json:"machine_id"`
+	SKUCode         string     `json:"sku_code"`
+	Quantity        int        `json:"quantity"`
+	Remaining       int        `json:"remaining"` // estimated from sales, oldest expiry first
+	ExpiresOn       string     `json:"expires_on"`
+	DaysLeft        int        `json:"days_left"`
+	Expired         bool       `json:"expired"`
+	MarkdownPercent int        `json:"markdown_percent"`
+	RestockedBy     string     `json:"restocked_by"`
+	RestockedAt     time.Time  `json:"restocked_at"`
+	WrittenOffAt    *time.Time `json:"written_off_at,omitempty"`
+	WrittenOffBy    string     `json:"written_off_by,omitempty"`
+	WastedUnits     int        `json:"wasted_units,omitempty"`
+}
+
+// WasteLine totals the units of one SKU written off
+type WasteLine struct {
+	SKUCode string `json:"sku_code"`
+	Batches int    `json:"batches"`
+	Units   int    `json:"units"`
+}
+
+// WasteReport is the waste written off over a date range
+type WasteReport struct {
+	MachineID  string       `json:"machine_id,omitempty"` // empty for the whole fleet
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	TotalUnits int          `json:"total_units"`
+	Lines      []WasteLine  `json:"lines"`
+	Batches    []StockBatch `json:"batches"`
+}
+
+// ExpiringBatches lists the batches due to expire, with the units left of them
+type ExpiringBatches struct {
+	WithinDays int          `json:"within_days"`
+	Units      int          `json:"units"`
+	Batches    []StockBatch `json:"batches"` // earliest expiry first, expired ones included
+	Count      int          `json:"count"`
+}
+
+// RecordBatches calls POST /api/v1/device/batches. The restocking staff
+// member is identified with WithActor.
+func (c *Client) RecordBatches(ctx context.Context, req RecordBatchesRequest, opts ...RequestOption) ([]StockBatch, error) {
+	var resp []StockBatch
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/batches", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeviceBatches calls GET /api/v1/device/batches
+func (c *Client) DeviceBatches(ctx context.Context, machineID string, opts ...RequestOption) ([]StockBatch, error) {
+	var resp []StockBatch
+	path := apiPrefix + "/device/batches?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ExpiringBatches calls GET /api/v1/device/batches/expiring. An empty
+// machineID lists the whole fleet; withinDays below zero uses the server's
+// default of 3 days.
+func (c *Client) ExpiringBatches(ctx context.Context, machineID string, withinDays int, opts ...RequestOption) (*ExpiringBatches, error) {
+	query := url.Values{}
+	if machineID != "" {
+		query.Set("machine_id", machineID)
+	}
+	if withinDays >= 0 {
+		query.Set("within_days", strconv.Itoa(withinDays))
+	}
+	path := apiPrefix + "/device/batches/expiring"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp ExpiringBatches
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WriteOffBatch calls POST /api/v1/device/batches/:id/write-off. The staff
+// member is identified with WithActor.
+func (c *Client) WriteOffBatch(ctx context.Context, batchID string, opts ...RequestOption) (*StockBatch, error) {
+	var resp StockBatch
+	path := apiPrefix + "/device/batches/" + url.PathEscape(batchID) + "/write-off"
+	if err := c.do(ctx, http.MethodPost, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WasteReport calls GET /api/v1/device/waste. An empty machineID reports the
+// whole fleet; from and to are "YYYY-MM-DD" dates and default to the last 30 days.
+func (c *Client) WasteReport(ctx context.Context, machineID, from, to string, opts ...RequestOption) (*WasteReport, error) {
+	query := url.Values{}
+	if machineID != "" {
+		query.Set("machine_id", machineID)
+	}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	path := apiPrefix + "/device/waste"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp WasteReport
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RestockLine is the units of one SKU loaded into a device
+type RestockLine struct {
+	SKUCode  string `json:"sku_code"`
+	Quantity int    `json:"quantity"`
+}
+
+// InventoryLevel is the counted units of one SKU in a device
+type InventoryLevel struct {
+	SKUCode  string `json:"sku_code"`
+	Quantity int    `json:"quantity"`
+	Low      bool   `json:"low"` // below the low-stock threshold
+}
+
+// Inventory is the counted stock of a device, kept up to date by restocks
+// and completed sessions
+type Inventory struct {
+	DeviceID     string           `json:"device_id"`
+	MachineID    string           `json:"machine_id"`
+	UpdatedAt    *time.Time       `json:"updated_at,omitempty"`
+	LowThreshold int              `json:"low_threshold"`
+	Levels       []InventoryLevel `json:"levels"`
+}
+
+// RestockInventory calls POST /api/v1/devices/:id/inventory, adding the
+// loaded units to the device's inventory. The restocking staff member is
+// identified with WithActor.
+func (c *Client) RestockInventory(ctx context.Context, deviceID string, items []RestockLine, opts ...RequestOption) (*Inventory, error) {
+	req := struct {
+		Items []RestockLine `json:"items"`
+	}{items}
+	var resp Inventory
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/inventory", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceInventory calls GET /api/v1/devices/:id/inventory
+func (c *Client) DeviceInventory(ctx context.Context, deviceID string, opts ...RequestOption) (*Inventory, error) {
+	var resp Inventory
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/inventory", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// LowInventory calls GET /api/v1/devices/:id/inventory/low, listing only the
+// SKUs below the low-stock threshold
+func (c *Client) LowInventory(ctx context.Context, deviceID string, opts ...RequestOption) (*Inventory, error) {
+	var resp Inventory
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/inventory/low", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RestockSessionLine is the units of one SKU loaded during a restock session
+type RestockSessionLine struct {
+	SKUCode    string `json:"sku_code"`
+	Added      int    `json:"added"`
+	LevelAfter *int   `json:"level_after,omitempty"` // units in the device once completed
+}
+
+// RestockSession is an operator's visit to refill a device. While it is open
+// the device starts no customer sessions; once closed it is the restock report.
+type RestockSession struct {
+	ID         string               `json:"id"`
+	DeviceID   string               `json:"device_id"`
+	MachineID  string               `json:"machine_id"`
+	Status     string               `json:"status"` // open, completed or cancelled
+	StartedBy  string               `json:"started_by"`
+	StartedAt  time.Time            `json:"started_at"`
+	ClosedBy   string               `json:"closed_by,omitempty"`
+	ClosedAt   *time.Time           `json:"closed_at,omitempty"`
+	UnitsAdded int                  `json:"units_added"`
+	Lines      []RestockSessionLine `json:"lines"`
+}
+
+// StartRestockSession calls POST /api/v1/devices/:id/restock-sessions. The
+// operator is identified with WithActor.
+func (c *Client) StartRestockSession(ctx context.Context, deviceID string, opts ...RequestOption) (*RestockSession, error) {
+	var resp RestockSession
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/restock-sessions", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceRestockSessions calls GET /api/v1/devices/:id/restock-sessions,
+// newest first
+func (c *Client) DeviceRestockSessions(ctx context.Context, deviceID string, opts ...RequestOption) ([]RestockSession, error) {
+	var resp []RestockSession
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/restock-sessions", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRestockSession calls GET /api/v1/restock-sessions/:id
+func (c *Client) GetRestockSession(ctx context.Context, sessionID string, opts ...RequestOption) (*RestockSession, error) {
+	var resp RestockSession
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/restock-sessions/"+url.PathEscape(sessionID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RecordRestockUnits calls POST /api/v1/restock-sessions/:id/units, counting
+// units loaded; they reach the inventory when the session completes
+func (c *Client) RecordRestockUnits(ctx context.Context, sessionID string, items []RestockLine, opts ...RequestOption) (*RestockSession, error) {
+	req := struct {
+		Items []RestockLine `json:"items"`
+	}{items}
+	var resp RestockSession
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/restock-sessions/"+url.PathEscape(sessionID)+"/units", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CompleteRestockSession calls POST /api/v1/restock-sessions/:id/complete,
+// adding the units to the inventory and returning the restock report. The
+// operator is identified with WithActor.
+func (c *Client) CompleteRestockSession(ctx context.Context, sessionID string, opts ...RequestOption) (*RestockSession, error) {
+	var resp RestockSession
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/restock-sessions/"+url.PathEscape(sessionID)+"/complete", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelRestockSession calls POST /api/v1/restock-sessions/:id/cancel,
+// discarding the units counted. The operator is identified with WithActor.
+func (c *Client) CancelRestockSession(ctx context.Context, sessionID string, opts ...RequestOption) (*RestockSession, error) {
+	var resp RestockSession
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/restock-sessions/"+url.PathEscape(sessionID)+"/cancel", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Assortment lists the SKUs assigned to a device. A device without assigned
+// SKUs may sell the whole catalog.
+type Assortment struct {
+	DeviceID  string     `json:"device_id"`
+	MachineID string     `json:"machine_id"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	SKUCodes  []string   `json:"sku_codes"`
+}
+
+// AssignDeviceSKUs calls POST /api/v1/devices/:id/skus, adding SKUs to the
+// device's assortment
+func (c *Client) AssignDeviceSKUs(ctx context.Context, deviceID string, skuCodes []string, opts ...RequestOption) (*Assortment, error) {
+	req := struct {
+		SKUCodes []string `json:"sku_codes"`
+	}{skuCodes}
+	var resp Assortment
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/skus", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnassignDeviceSKU calls DELETE /api/v1/devices/:id/skus/:code
+func (c *Client) UnassignDeviceSKU(ctx context.Context, deviceID, skuCode string, opts ...RequestOption) (*Assortment, error) {
+	var resp Assortment
+	if err := c.do(ctx, http.MethodDelete, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/skus/"+url.PathEscape(skuCode), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AssignedSKUs calls GET /api/v1/devices/:id/skus, returning the active SKUs
+// assigned to the device
+func (c *Client) AssignedSKUs(ctx context.Context, deviceID string, opts ...RequestOption) ([]DeviceSKU, error) {
+	var resp struct {
+		SKUs []DeviceSKU `json:"skus"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/skus", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.SKUs, nil
+}
+
+// HeartbeatRequest is sent by a device to report that it is alive
+type HeartbeatRequest struct {
+	MachineID       string         `json:"machine_id"`
+	FirmwareVersion string         `json:"firmware_version,omitempty"`
+	AppVersion      string         `json:"app_version,omitempty"`
+	ModelVersion    string         `json:"model_version,omitempty"` // detection model the device runs
+	Updates         []UpdateReport `json:"updates,omitempty"`
+}
+
+// UpdateReport is how far the device got with a release it was offered
+type UpdateReport struct {
+	RolloutID string `json:"rollout_id"`
+	Status    string `json:"status"` // downloading, downloaded, installing, installed or failed
+	Detail    string `json:"detail,omitempty"`
+}
+
+// OfferedUpdate is a release the device should download and install
+type OfferedUpdate struct {
+	RolloutID   string `json:"rollout_id"`
+	ReleaseID   string `json:"release_id"`
+	Kind        string `json:"kind"` // firmware or app
+	Version     string `json:"version"`
+	SizeBytes   int    `json:"size_bytes"`
+	Checksum    string `json:"checksum"` // SHA-256, hex
+	DownloadURL string `json:"download_url"`
+}
+
+// Heartbeat is the device state after a heartbeat
+type Heartbeat struct {
+	MachineID  string          `json:"machine_id"`
+	Status     string          `json:"status"`
+	LastSeenAt time.Time       `json:"last_seen_at"`
+	Updates    []OfferedUpdate `json:"updates"`
+}
+
+// SendHeartbeat calls POST /api/v1/device/heartbeat, authenticated with the
+// key the device was issued
+func (c *Client) SendHeartbeat(ctx context.Context, deviceKey string, req HeartbeatRequest, opts ...RequestOption) (*Heartbeat, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+
+	var resp Heartbeat
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/heartbeat", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Device is a registered device with its last heartbeat
+type Device struct {
+	ID                string            `json:"id"`
+	MachineID         string            `json:"machine_id"`
+	Name              string            `json:"name,omitempty"`
+	Location          string            `json:"location,omitempty"`
+	Region            string            `json:"region,omitempty"`
+	Status            string            `json:"status"`
+	LastSeenAt        *time.Time        `json:"last_seen_at"` // nil until the first heartbeat
+	FirmwareVersion   string            `json:"firmware_version,omitempty"`
+	AppVersion        string            `json:"app_version,omitempty"`
+	ModelVersion      string            `json:"model_version,omitempty"`
+	Stale             bool              `json:"stale"` // not heard from within the server's staleness window
+	DecommissionedAt  *time.Time        `json:"decommissioned_at,omitempty"`
+	DecommissionedBy  string            `json:"decommissioned_by,omitempty"`
+	MaintenanceSince  *time.Time        `json:"maintenance_since,omitempty"`
+	MaintenanceBy     string            `json:"maintenance_by,omitempty"`
+	MaintenanceReason string            `json:"maintenance_reason,omitempty"`
+	GroupID           string            `json:"group_id,omitempty"` // empty when the device is in no group
+	Tags              []string          `json:"tags"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// DeviceFilter narrows ListDevices; zero fields don't filter
+type DeviceFilter struct {
+	Status     string            // active, inactive, blocked, maintenance or decommissioned
+	Location   string            // part of the location, any case
+	Stale      *bool             // whether the device is stale
+	SeenAfter  *time.Time        // last heartbeat after this time
+	SeenBefore *time.Time        // no heartbeat since this time, never-seen devices included
+	Tags       []string          // carries every one of these tags, any case
+	Metadata   map[string]string // has exactly these metadata values
+	Limit      int               // page size; zero uses the server's default of 50
+	Offset     int
+}
+
+// DeviceList is one page of devices
+type DeviceList struct {
+	Devices    []Device `json:"devices"`
+	Total      int      `json:"total"` // devices matching the filter across all pages
+	Limit      int      `json:"limit"`
+	Offset     int      `json:"offset"`
+	StaleAfter string   `json:"stale_after"` // e.g. "5m0s"
+}
+
+// ListDevices calls GET /api/v1/devices
+func (c *Client) ListDevices(ctx context.Context, filter DeviceFilter, opts ...RequestOption) (*DeviceList, error) {
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if filter.Location != "" {
+		query.Set("location", filter.Location)
+	}
+	if filter.Stale != nil {
+		query.Set("stale", strconv.FormatBool(*filter.Stale))
+	}
+	if filter.SeenAfter != nil {
+		query.Set("seen_after", filter.SeenAfter.UTC().Format(time.RFC3339))
+	}
+	if filter.SeenBefore != nil {
+		query.Set("seen_before", filter.SeenBefore.UTC().Format(time.RFC3339))
+	}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	for key, value := range filter.Metadata {
+		query.Set("metadata["+key+"]", value)
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	path := apiPrefix + "/devices"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp DeviceList
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDevice calls GET /api/v1/devices/:id
+func (c *Client) GetDevice(ctx context.Context, id string, opts ...RequestOption) (*Device, error) {
+	var resp Device
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceLabels are the tags and free-form metadata an operator puts on a device
+type DeviceLabels struct {
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// SetDeviceLabels calls PUT /api/v1/devices/:id/labels, replacing the
+// device's tags and metadata. Tags come back lowercased and sorted.
+func (c *Client) SetDeviceLabels(ctx context.Context, id string, labels DeviceLabels, opts ...RequestOption) (*DeviceLabels, error) {
+	var resp DeviceLabels
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/devices/"+url.PathEscape(id)+"/labels", labels, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceCommand is a command queued for a device. Kind is reboot,
+// resync_catalog, capture_test_image or run_diagnostics; Status is pending,
+// delivered, succeeded or failed.
+type DeviceCommand struct {
+	ID             string     `json:"id"`
+	MachineID      string     `json:"machine_id"`
+	Kind           string     `json:"kind"`
+	Status         string     `json:"status"`
+	IssuedBy       string     `json:"issued_by"`
+	IssuedAt       time.Time  `json:"issued_at"`
+	Deliveries     int        `json:"deliveries"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	Result         string     `json:"result,omitempty"`
+}
+
+// IssueDeviceCommand calls POST /api/v1/devices/:id/commands. The operator
+// must be identified with WithActor.
+func (c *Client) IssueDeviceCommand(ctx context.Context, id, kind string, opts ...RequestOption) (*DeviceCommand, error) {
+	req := struct {
+		Kind string `json:"kind"`
+	}{Kind: kind}
+
+	var resp DeviceCommand
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(id)+"/commands", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceCommands calls GET /api/v1/devices/:id/commands, newest first
+func (c *Client) DeviceCommands(ctx context.Context, id string, opts ...RequestOption) ([]DeviceCommand, error) {
+	var resp []DeviceCommand
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(id)+"/commands", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PollDeviceCommands calls GET /api/v1/device/commands, authenticated with
+// the key the device was issued. The server holds the request for up to
+// wait (whole seconds, at most a minute) when no command is queued, so wait
+// must stay below the HTTP client's timeout; see WithHTTPClient.
+func (c *Client) PollDeviceCommands(ctx context.Context, deviceKey, machineID string, wait time.Duration, opts ...RequestOption) ([]DeviceCommand, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	query := url.Values{
+		"machine_id": {machineID},
+		"wait":       {strconv.Itoa(int(wait / time.Second))},
+	}
+
+	var resp []DeviceCommand
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device/commands?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AcknowledgeDeviceCommand calls POST /api/v1/device/commands/:id/ack,
+// authenticated with the key the device was issued. Status is succeeded or
+// failed.
+func (c *Client) AcknowledgeDeviceCommand(ctx context.Context, deviceKey, machineID, commandID, status, result string, opts ...RequestOption) (*DeviceCommand, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string `json:"machine_id"`
+		Status    string `json:"status"`
+		Result    string `json:"result,omitempty"`
+	}{MachineID: machineID, Status: status, Result: result}
+
+	var resp DeviceCommand
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/commands/"+url.PathEscape(commandID)+"/ack", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DiagnosticChecks is the outcome of a device self-test
+type DiagnosticChecks struct {
+	CameraOK     bool   `json:"camera_ok"`
+	ScaleOK      bool   `json:"scale_ok"`
+	ModelLoaded  bool   `json:"model_loaded"`
+	ModelVersion string `json:"model_version,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// DiagnosticReport is a self-test report a device uploaded
+type DiagnosticReport struct {
+	DiagnosticChecks
+	Passed     bool      `json:"passed"` // every check passed
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// Diagnostics is a run_diagnostics command and, once uploaded, the report
+type Diagnostics struct {
+	CommandID string            `json:"command_id"`
+	MachineID string            `json:"machine_id"`
+	Status    string            `json:"status"`
+	IssuedBy  string            `json:"issued_by"`
+	IssuedAt  time.Time         `json:"issued_at"`
+	Report    *DiagnosticReport `json:"report,omitempty"`
+}
+
+// RequestDiagnostics calls POST /api/v1/devices/:id/diagnostics, which queues
+// a run_diagnostics command. The operator must be identified with WithActor.
+func (c *Client) RequestDiagnostics(ctx context.Context, id string, opts ...RequestOption) (*Diagnostics, error) {
+	var resp Diagnostics
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(id)+"/diagnostics", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDiagnostics calls GET /api/v1/devices/:id/diagnostics/:command_id; the
+// report is nil until the device has uploaded it
+func (c *Client) GetDiagnostics(ctx context.Context, id, commandID string, opts ...RequestOption) (*Diagnostics, error) {
+	var resp Diagnostics
+	path := apiPrefix + "/devices/" + url.PathEscape(id) + "/diagnostics/" + url.PathEscape(commandID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UploadDiagnostics calls POST /api/v1/device/commands/:id/diagnostics,
+// authenticated with the key the device was issued, which also acknowledges
+// the run_diagnostics command
+func (c *Client) UploadDiagnostics(ctx context.Context, deviceKey, machineID, commandID string, checks DiagnosticChecks, opts ...RequestOption) (*Diagnostics, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string `json:"machine_id"`
+		DiagnosticChecks
+	}{machineID, checks}
+
+	var resp Diagnostics
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/commands/"+url.PathEscape(commandID)+"/diagnostics", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDeviceQRCode calls GET /api/v1/devices/:id/qrcode.png and returns the
+// PNG for the device's label, about size pixels square; zero uses the
+// server's default of 256
+func (c *Client) GetDeviceQRCode(ctx context.Context, id string, size int, opts ...RequestOption) ([]byte, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	path := apiPrefix + "/devices/" + url.PathEscape(id) + "/qrcode.png"
+	if size > 0 {
+		path += "?size=" + strconv.Itoa(size)
+	}
+	resp, err := c.send(ctx, http.MethodGet, path, "", nil, rc)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// IssueDeviceKey calls POST /api/v1/devices/:id/key and returns the device's
+// new key; the previous key stops working
+func (c *Client) IssueDeviceKey(ctx context.Context, id string, opts ...RequestOption) (string, error) {
+	var resp struct {
+		DeviceKey string `json:"device_key"`
+	}
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/devices/"+url.PathEscape(id)+"/key", nil, &resp, opts...); err != nil {
+		return "", err
+	}
+	return resp.DeviceKey, nil
+}
+
+// DevicePolicySettings are the settings a device group or a device can
+// configure; unset settings are inherited
+type DevicePolicySettings struct {
+	ConfidenceThreshold      *float64 `json:"confidence_threshold"` // minimum detection confidence, 0-1
+	ModelVersion             string   `json:"model_version,omitempty"`
+	SessionExpirationMinutes *int     `json:"session_expiration_minutes"` // how long sessions on the device last
+}
+
+// DeviceGroup is a fleet of devices configured together
+type DeviceGroup struct {
+	ID                       string            `json:"id"`
+	Name                     string            `json:"name"`
+	ConfidenceThreshold      *float64          `json:"confidence_threshold"`
+	ModelVersion             string            `json:"model_version,omitempty"`
+	SessionExpirationMinutes *int              `json:"session_expiration_minutes"`
+	PlanogramVersion         int               `json:"planogram_version,omitempty"`
+	Planogram                []PlanogramFacing `json:"planogram,omitempty"`
+	DeviceCount              int               `json:"device_count"`
+	MachineIDs               []string          `json:"machine_ids,omitempty"` // not set in lists
+	CreatedAt                time.Time         `json:"created_at"`
+	UpdatedAt                time.Time         `json:"updated_at"`
+}
+
+// GroupPolicy replaces the policy of a device group. Unset settings fall back
+// to the deployment's defaults; a nil planogram removes the group's.
+type GroupPolicy struct {
+	DevicePolicySettings
+	Planogram []PlanogramFacing `json:"planogram,omitempty"`
+}
+
+// DevicePolicy is the policy that applies to a device. Sources are device,
+// group or default.
+type DevicePolicy struct {
+	DeviceID                       string               `json:"device_id"`
+	MachineID                      string               `json:"machine_id"`
+	GroupID                        string               `json:"group_id,omitempty"`
+	ConfidenceThreshold            *float64             `json:"confidence_threshold"` // nil when the server's default applies
+	ConfidenceThresholdSource      string               `json:"confidence_threshold_source"`
+	ModelVersion                   string               `json:"model_version,omitempty"`
+	ModelVersionSource             string               `json:"model_version_source"`
+	SessionExpirationMinutes       *int                 `json:"session_expiration_minutes"` // nil when the server's default applies
+	SessionExpirationMinutesSource string               `json:"session_expiration_minutes_source"`
+	PlanogramVersion               int                  `json:"planogram_version,omitempty"`
+	PlanogramSource                string               `json:"planogram_source,omitempty"` // empty when the device has no planogram
+	Overrides                      DevicePolicySettings `json:"overrides"`
+}
+
+// CreateDeviceGroup calls POST /api/v1/device-groups
+func (c *Client) CreateDeviceGroup(ctx context.Context, name string, opts ...RequestOption) (*DeviceGroup, error) {
+	req := struct {
+		Name string `json:"name"`
+	}{name}
+	var resp DeviceGroup
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device-groups", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListDeviceGroups calls GET /api/v1/device-groups
+func (c *Client) ListDeviceGroups(ctx context.Context, opts ...RequestOption) ([]DeviceGroup, error) {
+	var resp []DeviceGroup
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device-groups", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetDeviceGroup calls GET /api/v1/device-groups/:id
+func (c *Client) GetDeviceGroup(ctx context.Context, id string, opts ...RequestOption) (*DeviceGroup, error) {
+	var resp DeviceGroup
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/device-groups/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetGroupPolicy calls PUT /api/v1/device-groups/:id/policy
+func (c *Client) SetGroupPolicy(ctx context.Context, id string, policy GroupPolicy, opts ...RequestOption) (*DeviceGroup, error) {
+	var resp DeviceGroup
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/device-groups/"+url.PathEscape(id)+"/policy", policy, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddGroupDevices calls POST /api/v1/device-groups/:id/devices, moving the
+// devices out of any group they were in. No device is moved when a machine ID
+// is unknown.
+func (c *Client) AddGroupDevices(ctx context.Context, id string, machineIDs []string, opts ...RequestOption) (*DeviceGroup, error) {
+	req := struct {
+		MachineIDs []string `json:"machine_ids"`
+	}{machineIDs}
+	var resp DeviceGroup
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device-groups/"+url.PathEscape(id)+"/devices", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RemoveGroupDevice calls DELETE /api/v1/device-groups/:id/devices/:machine_id
+func (c *Client) RemoveGroupDevice(ctx context.Context, id, machineID string, opts ...RequestOption) (*DeviceGroup, error) {
+	var resp DeviceGroup
+	path := apiPrefix + "/device-groups/" + url.PathEscape(id) + "/devices/" + url.PathEscape(machineID)
+	if err := c.do(ctx, http.MethodDelete, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetDevicePolicy calls PUT /api/v1/devices/:id/policy, replacing the
+// settings that override the group's policy for the device
+func (c *Client) SetDevicePolicy(ctx context.Context, deviceID string, overrides DevicePolicySettings, opts ...RequestOption) (*DevicePolicy, error) {
+	var resp DevicePolicy
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/policy", overrides, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDevicePolicy calls GET /api/v1/devices/:id/policy
+func (c *Client) GetDevicePolicy(ctx context.Context, deviceID string, opts ...RequestOption) (*DevicePolicy, error) {
+	var resp DevicePolicy
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/policy", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CameraSettings configure a device's shelf camera; zero fields are not managed
+type CameraSettings struct {
+	Resolution     string `json:"resolution,omitempty"` // e.g. "1920x1080"
+	FrameRate      int    `json:"frame_rate,omitempty"`
+	ExposureMicros int    `json:"exposure_micros,omitempty"`
+}
+
+// DeviceConfig is the configuration of a device; zero fields are not managed
+// by the server and keep the device's own defaults
+type DeviceConfig struct {
+	ConfidenceThreshold *float64       `json:"confidence_threshold,omitempty"`
+	SyncIntervalSeconds int            `json:"sync_interval_seconds,omitempty"`
+	Camera              CameraSettings `json:"camera"`
+}
+
+// DesiredConfig is the configuration a device should run
+type DesiredConfig struct {
+	MachineID string       `json:"machine_id"`
+	Version   int          `json:"version"` // 0 until an operator configures the device
+	Config    DeviceConfig `json:"config"`
+}
+
+// ConfigDrift is a setting the device runs with another value than desired
+type ConfigDrift struct {
+	Setting  string `json:"setting"` // e.g. camera.frame_rate
+	Desired  string `json:"desired"`
+	Reported string `json:"reported"` // empty when the device did not report it
+}
+
+// ConfigShadow is the desired and reported configuration of a device
+type ConfigShadow struct {
+	DeviceID        string        `json:"device_id"`
+	MachineID       string        `json:"machine_id"`
+	Status          string        `json:"status"` // in_sync, pending or drifted
+	Version         int           `json:"version"`
+	Desired         DeviceConfig  `json:"desired"`
+	DesiredBy       string        `json:"desired_by,omitempty"`
+	DesiredAt       *time.Time    `json:"desired_at,omitempty"`
+	Reported        *DeviceConfig `json:"reported"` // nil until the device reports
+	ReportedVersion int           `json:"reported_version"`
+	ReportedAt      *time.Time    `json:"reported_at"`
+	Drift           []ConfigDrift `json:"drift"`
+}
+
+// SetDeviceConfig calls PUT /api/v1/devices/:id/config. The operator is
+// identified with WithActor.
+func (c *Client) SetDeviceConfig(ctx context.Context, deviceID string, config DeviceConfig, opts ...RequestOption) (*ConfigShadow, error) {
+	var resp ConfigShadow
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/config", config, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDeviceConfig calls GET /api/v1/devices/:id/config
+func (c *Client) GetDeviceConfig(ctx context.Context, deviceID string, opts ...RequestOption) (*ConfigShadow, error) {
+	var resp ConfigShadow
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/config", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PullDeviceConfig calls GET /api/v1/device/config, authenticated with the
+// key the device was issued
+func (c *Client) PullDeviceConfig(ctx context.Context, deviceKey, machineID string, opts ...RequestOption) (*DesiredConfig, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+
+	var resp DesiredConfig
+	path := apiPrefix + "/device/config?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReportDeviceConfig calls POST /api/v1/device/config/reported with the
+// configuration the device applied and the version it applied it from
+func (c *Client) ReportDeviceConfig(ctx context.Context, deviceKey, machineID string, version int, applied DeviceConfig, opts ...RequestOption) (*ConfigShadow, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string       `json:"machine_id"`
+		Version   int          `json:"version"`
+		Config    DeviceConfig `json:"config"`
+	}{machineID, version, applied}
+
+	var resp ConfigShadow
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/config/reported", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReferenceMarker is a fiducial on the shelf and where the camera saw it, in
+// pixels from the top left of the frame
+type ReferenceMarker struct {
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+// CameraCalibration is a device's shelf camera calibration
+type CameraCalibration struct {
+	DeviceID     string            `json:"device_id"`
+	MachineID    string            `json:"machine_id"`
+	Version      int               `json:"version"`    // bumped on every upload
+	Homography   []float64         `json:"homography"` // row-major 3x3, camera pixels to shelf plane
+	Markers      []ReferenceMarker `json:"markers"`
+	Capture      CameraSettings    `json:"capture"` // settings the calibration frames were taken with
+	CalibratedAt time.Time         `json:"calibrated_at"`
+}
+
+// CameraCalibrationUpload is a calibration the device just made
+type CameraCalibrationUpload struct {
+	Homography []float64         `json:"homography"`
+	Markers    []ReferenceMarker `json:"markers,omitempty"`
+	Capture    CameraSettings    `json:"capture"`
+}
+
+// UploadCameraCalibration calls PUT /api/v1/device/calibration, replacing
+// the device's stored calibration
+func (c *Client) UploadCameraCalibration(ctx context.Context, deviceKey, machineID string, upload CameraCalibrationUpload, opts ...RequestOption) (*CameraCalibration, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string `json:"machine_id"`
+		CameraCalibrationUpload
+	}{machineID, upload}
+
+	var resp CameraCalibration
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/device/calibration", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PullCameraCalibration calls GET /api/v1/device/calibration, which a device
+// does on boot to restore its calibration after being reimaged
+func (c *Client) PullCameraCalibration(ctx context.Context, deviceKey, machineID string, opts ...RequestOption) (*CameraCalibration, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+
+	var resp CameraCalibration
+	path := apiPrefix + "/device/calibration?machine_id=" + url.QueryEscape(machineID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceCameraCalibration calls GET /api/v1/devices/:id/calibration
+func (c *Client) DeviceCameraCalibration(ctx context.Context, deviceID string, opts ...RequestOption) (*CameraCalibration, error) {
+	var resp CameraCalibration
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/calibration", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ScaleReading is what the scale showed with a known weight on it
+type ScaleReading struct {
+	ReferenceGrams float64 `json:"reference_grams"`
+	MeasuredGrams  float64 `json:"measured_grams"`
+}
+
+// ScaleCalibrationRequest is a scale calibration as the device found the
+// scale, before zeroing and correcting it
+type ScaleCalibrationRequest struct {
+	ZeroGrams    float64        `json:"zero_grams"` // reading with nothing on the scale
+	Readings     []ScaleReading `json:"readings"`
+	CalibratedAt *time.Time     `json:"calibrated_at,omitempty"` // defaults to now
+}
+
+// ScaleCalibration is one calibration of a device's scale
+type ScaleCalibration struct {
+	ID           string         `json:"id"`
+	ZeroGrams    float64        `json:"zero_grams"`
+	Readings     []ScaleReading `json:"readings"`
+	SpanFactor   float64        `json:"span_factor"` // 1 is exact
+	ErrorGrams   float64        `json:"error_grams"` // largest error found
+	CalibratedAt time.Time      `json:"calibrated_at"`
+}
+
+// ScaleCalibrationHistory is a device's scale calibrations, newest first,
+// with the drift they show
+type ScaleCalibrationHistory struct {
+	DeviceID         string             `json:"device_id"`
+	MachineID        string             `json:"machine_id"`
+	Calibrations     []ScaleCalibration `json:"calibrations"`
+	DriftGramsPerDay float64            `json:"drift_grams_per_day"`
+	RecalibrateBy    *time.Time         `json:"recalibrate_by,omitempty"`
+	RecalibrationDue bool               `json:"recalibration_due"`
+}
+
+// CalibrateScale calls POST /api/v1/device/scale/calibrate
+func (c *Client) CalibrateScale(ctx context.Context, deviceKey, machineID string, calibration ScaleCalibrationRequest, opts ...RequestOption) (*ScaleCalibration, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	req := struct {
+		MachineID string `json:"machine_id"`
+		ScaleCalibrationRequest
+	}{machineID, calibration}
+
+	var resp ScaleCalibration
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/device/scale/calibrate", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeviceScaleCalibrations calls GET /api/v1/devices/:id/scale/calibrations
+func (c *Client) DeviceScaleCalibrations(ctx context.Context, deviceID string, opts ...RequestOption) (*ScaleCalibrationHistory, error) {
+	var resp ScaleCalibrationHistory
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/devices/"+url.PathEscape(deviceID)+"/scale/calibrations", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Release is a firmware or app build in the release registry
+type Release struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"` // firmware or app
+	Version    string    `json:"version"`
+	SizeBytes  int       `json:"size_bytes"`
+	Checksum   string    `json:"checksum"` // SHA-256, hex
+	Notes      string    `json:"notes,omitempty"`
+	UploadedBy string    `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type releaseListResponse struct {
+	Releases []Release `json:"releases"`
+}
+
+// UploadRelease calls POST /api/v1/releases with artifact as the uploaded
+// build. kind is firmware or app; notes are optional. The operator is
+// identified with WithActor.
+func (c *Client) UploadRelease(ctx context.Context, kind, version string, artifact []byte, notes string, opts ...RequestOption) (*Release, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for field, value := range map[string]string{"kind": kind, "version": version, "notes": notes} {
+		if err := form.WriteField(field, value); err != nil {
+			return nil, fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	part, err := form.CreateFormFile("artifact", "artifact.bin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(artifact); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var resp Release
+	if err := c.doBody(ctx, http.MethodPost, apiPrefix+"/releases", form.FormDataContentType(), body.Bytes(), &resp, rc); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListReleases calls GET /api/v1/releases; an empty kind lists every kind
+func (c *Client) ListReleases(ctx context.Context, kind string, opts ...RequestOption) ([]Release, error) {
+	path := apiPrefix + "/releases"
+	if kind != "" {
+		path += "?kind=" + url.QueryEscape(kind)
+	}
+
+	var resp releaseListResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Releases, nil
+}
+
+// GetRelease calls GET /api/v1/releases/:id
+func (c *Client) GetRelease(ctx context.Context, releaseID string, opts ...RequestOption) (*Release, error) {
+	var resp Release
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/releases/"+url.PathEscape(releaseID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DownloadRelease calls GET /api/v1/device/releases/:id, authenticated with
+// the key the device was issued, and returns the release artifact. Check it
+// against the offered checksum before installing it.
+func (c *Client) DownloadRelease(ctx context.Context, deviceKey, machineID, releaseID string, opts ...RequestOption) ([]byte, error) {
+	opts = append([]RequestOption{WithHeader("X-Device-Key", deviceKey)}, opts...)
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	path := apiPrefix + "/device/releases/" + url.PathEscape(releaseID) + "?machine_id=" + url.QueryEscape(machineID)
+	resp, err := c.send(ctx, http.MethodGet, path, "", nil, rc)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// StartRolloutRequest rolls a release out to device groups. Stages are
+// increasing percentages of the devices ending at 100; none rolls out to
+// every device at once.
+type StartRolloutRequest struct {
+	ReleaseID string   `json:"release_id"`
+	GroupIDs  []string `json:"group_ids"`
+	Stages    []int    `json:"stages,omitempty"`
+}
+
+// RolloutDevice is how far one device got with a rollout's release
+type RolloutDevice struct {
+	DeviceID  string     `json:"device_id"`
+	MachineID string     `json:"machine_id"`
+	Status    string     `json:"status"` // pending, downloading, downloaded, installing, installed or failed
+	Detail    string     `json:"detail,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Rollout is a release being offered to device groups, with its progress
+type Rollout struct {
+	ID          string          `json:"id"`
+	ReleaseID   string          `json:"release_id"`
+	Kind        string          `json:"kind"`
+	Version     string          `json:"version"`
+	GroupIDs    []string        `json:"group_ids"`
+	Stages      []int           `json:"stages"`
+	Stage       int             `json:"stage"`   // index into Stages
+	Percent     int             `json:"percent"` // share of the devices offered the release
+	Status      string          `json:"status"`  // active, completed or aborted
+	CreatedBy   string          `json:"created_by"`
+	AbortedBy   string          `json:"aborted_by,omitempty"`
+	AbortReason string          `json:"abort_reason,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Targeted    int             `json:"targeted"` // devices in the targeted groups
+	Progress    map[string]int  `json:"progress"` // devices by update status
+	Devices     []RolloutDevice `json:"devices"`
+}
+
+type rolloutListResponse struct {
+	Rollouts []Rollout `json:"rollouts"`
+}
+
+// StartRollout calls POST /api/v1/rollouts. The operator is identified with WithActor.
+func (c *Client) StartRollout(ctx context.Context, req StartRolloutRequest, opts ...RequestOption) (*Rollout, error) {
+	var resp Rollout
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/rollouts", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListRollouts calls GET /api/v1/rollouts
+func (c *Client) ListRollouts(ctx context.Context, opts ...RequestOption) ([]Rollout, error) {
+	var resp rolloutListResponse
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/rollouts", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Rollouts, nil
+}
+
+// GetRollout calls GET /api/v1/rollouts/:id
+func (c *Client) GetRollout(ctx context.Context, rolloutID string, opts ...RequestOption) (*Rollout, error) {
+	var resp Rollout
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/rollouts/"+url.PathEscape(rolloutID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AdvanceRollout calls POST /api/v1/rollouts/:id/advance. The operator is
+// identified with WithActor.
+func (c *Client) AdvanceRollout(ctx context.Context, rolloutID string, opts ...RequestOption) (*Rollout, error) {
+	var resp Rollout
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/rollouts/"+url.PathEscape(rolloutID)+"/advance", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AbortRollout calls POST /api/v1/rollouts/:id/abort. The operator is
+// identified with WithActor.
+func (c *Client) AbortRollout(ctx context.Context, rolloutID, reason string, opts ...RequestOption) (*Rollout, error) {
+	req := struct {
+		Reason string `json:"reason,omitempty"`
+	}{reason}
+
+	var resp Rollout
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/rollouts/"+url.PathEscape(rolloutID)+"/abort", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FleetHealth is the state of the whole fleet, for the ops dashboard
+type FleetHealth struct {
+	DevicesByStatus map[string]int     `json:"devices_by_status"`
+	Offline         OfflineDevices     `json:"offline"`
+	StaleModels     StaleModelDevices  `json:"stale_models"`
+	WeightMismatch  WeightMismatchRate `json:"weight_mismatch"`
+}
+
+// OfflineDevices are the devices not heard from within the offline window
+type OfflineDevices struct {
+	AfterMinutes int       `json:"after_minutes"`
+	Since        time.Time `json:"since"`
+	Count        int       `json:"count"`
+	MachineIDs   []string  `json:"machine_ids"` // longest silent first
+}
+
+// StaleModelDevices are the devices running another detection model than
+// their policy pins
+type StaleModelDevices struct {
+	Count      int      `json:"count"`
+	MachineIDs []string `json:"machine_ids"`
+}
+
+// WeightMismatchRate is the share of open sessions whose last detection did
+// not match the measured weight
+type WeightMismatchRate struct {
+	OpenSessions int     `json:"open_sessions"`
+	Mismatched   int     `json:"mismatched"`
+	Rate         float64 `json:"rate"`
+}
+
+// GetFleetHealth calls GET /api/v1/admin/fleet/health. Devices silent for
+// longer than offlineMinutes count as offline; zero uses the server's
+// staleness window.
+func (c *Client) GetFleetHealth(ctx context.Context, offlineMinutes int, opts ...RequestOption) (*FleetHealth, error) {
+	path := apiPrefix + "/admin/fleet/health"
+	if offlineMinutes > 0 {
+		path += "?" + url.Values{"offline_minutes": {strconv.Itoa(offlineMinutes)}}.Encode()
+	}
+
+	var resp FleetHealth
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// OpenDisputeRequest is the payload for recording a chargeback
+type OpenDisputeRequest struct {
+	AmountCents int64  `json:"amount_cents,omitempty"` // zero disputes the whole total
+	Reason      string `json:"reason"`
+	ProviderRef string `json:"provider_ref,omitempty"` // the payment provider's chargeback ID
+}
+
+// DisputeNote is a note staff made while working a dispute
+type DisputeNote struct {
+	ActorID string `json:"actor_id"`
+	Note    string `json:"note"`
+	At      string `json:"at"`
+}
+
+// DisputeEvidence is evidence attached to a dispute
+type DisputeEvidence struct {
+	Kind         string  `json:"kind"`                    // detections or image
+	DetectionIDs []int64 `json:"detection_ids,omitempty"` // detection audit log entries
+	ImageBytes   int     `json:"image_bytes,omitempty"`
+	AttachedBy   string  `json:"attached_by"`
+	Note         string  `json:"note"`
+	At           string  `json:"at"`
+}
+
+// Dispute is a chargeback of a completed session
+type Dispute struct {
+	ID             string            `json:"id"`
+	SessionID      string            `json:"session_id"`
+	TransactionID  string            `json:"transaction_id"`
+	AmountCents    int64             `json:"amount_cents"`
+	Currency       string            `json:"currency"`
+	Reason         string            `json:"reason"`
+	ProviderRef    string            `json:"provider_ref"`
+	Status         string            `json:"status"` // open, won or lost
+	OpenedBy       string            `json:"opened_by"`
+	ResolvedBy     string            `json:"resolved_by"`
+	ResolutionNote string            `json:"resolution_note"`
+	Notes          []DisputeNote     `json:"notes"`
+	Evidence       []DisputeEvidence `json:"evidence"`
+	CreatedAt      string            `json:"created_at"`
+	ResolvedAt     *string           `json:"resolved_at"`
+}
+
+// DisputeFilter narrows ListDisputes; zero fields match all disputes
+type DisputeFilter struct {
+	Status string    // open, won or lost
+	From   time.Time // opened at or after
+	To     time.Time // opened before
+}
+
+// DisputeReportLine counts the disputes of one status in one currency
+type DisputeReportLine struct {
+	Status      string `json:"status"`
+	Currency    string `json:"currency"`
+	Count       int    `json:"count"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// DisputeReport sums up the disputes opened in a period
+type DisputeReport struct {
+	Lines []DisputeReportLine `json:"lines"`
+}
+
+// OpenDispute calls POST /api/v1/session/:id/disputes
+func (c *Client) OpenDispute(ctx context.Context, sessionID string, req OpenDisputeRequest, opts ...RequestOption) (*Dispute, error) {
+	var resp Dispute
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/disputes", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SessionDisputes calls GET /api/v1/session/:id/disputes
+func (c *Client) SessionDisputes(ctx context.Context, sessionID string, opts ...RequestOption) ([]Dispute, error) {
+	var resp struct {
+		Disputes []Dispute `json:"disputes"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/disputes", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Disputes, nil
+}
+
+// ListDisputes calls GET /api/v1/disputes
+func (c *Client) ListDisputes(ctx context.Context, filter DisputeFilter, opts ...RequestOption) ([]Dispute, error) {
+	query := periodQuery(filter.From, filter.To)
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	path := apiPrefix + "/disputes"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp struct {
+		Disputes []Dispute `json:"disputes"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Disputes, nil
+}
+
+// DisputeReport calls GET /api/v1/disputes/report for the disputes opened
+// in [from, to); zero bounds are open-ended
+func (c *Client) DisputeReport(ctx context.Context, from, to time.Time, opts ...RequestOption) (*DisputeReport, error) {
+	path := apiPrefix + "/disputes/report"
+	if query := periodQuery(from, to); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp DisputeReport
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDispute calls GET /api/v1/disputes/:id
+func (c *Client) GetDispute(ctx context.Context, id string, opts ...RequestOption) (*Dispute, error) {
+	var resp Dispute
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/disputes/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AnnotateDispute calls POST /api/v1/disputes/:id/notes
+func (c *Client) AnnotateDispute(ctx context.Context, id, note string, opts ...RequestOption) (*Dispute, error) {
+	req := struct {
+		Note string `json:"note"`
+	}{Note: note}
+
+	var resp Dispute
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/disputes/"+url.PathEscape(id)+"/notes", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AttachDisputeEvidence calls POST /api/v1/disputes/:id/evidence. Kind is
+// detections, for the session's detection audit log, or image, for the last
+// image the device sent.
+func (c *Client) AttachDisputeEvidence(ctx context.Context, id, kind, note string, opts ...RequestOption) (*Dispute, error) {
+	req := struct {
+		Kind string `json:"kind"`
+		Note string `json:"note,omitempty"`
+	}{Kind: kind, Note: note}
+
+	var resp Dispute
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/disputes/"+url.PathEscape(id)+"/evidence", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResolveDispute calls POST /api/v1/disputes/:id/resolve. Outcome is won or
+// lost; the actor needs the finance role.
+func (c *Client) ResolveDispute(ctx context.Context, id, outcome, note string, opts ...RequestOption) (*Dispute, error) {
+	req := struct {
+		Outcome string `json:"outcome"`
+		Note    string `json:"note,omitempty"`
+	}{Outcome: outcome, Note: note}
+
+	var resp Dispute
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/disputes/"+url.PathEscape(id)+"/resolve", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DisputeImage calls GET /api/v1/disputes/:id/image and returns the raw
+// session image attached as evidence
+func (c *Client) DisputeImage(ctx context.Context, id string, opts ...RequestOption) ([]byte, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, apiPrefix+"/disputes/"+url.PathEscape(id)+"/image", "", nil, rc)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// periodQuery encodes the from and to bounds of a listing or report, leaving
+// out zero ones
+func periodQuery(from, to time.Time) url.Values {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format(time.RFC3339))
+	}
+	return query
+}
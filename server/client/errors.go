@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when the server responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+	Code       string // machine-readable reason, e.g. "device_closed" or "sale_restricted"
+	Endpoint   string // on 421, the base URL of the region that serves the request
+
+	body []byte // raw response, for endpoints that return more than a message
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	var payload struct {
+		Error    string `json:"error"`
+		Code     string `json:"code"`
+		Endpoint string `json:"endpoint"`
+	}
+	msg := http.StatusText(status)
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		msg = payload.Error
+	}
+	return &APIError{StatusCode: status, Message: msg, Code: payload.Code, Endpoint: payload.Endpoint, body: body}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnprocessable reports whether err is an APIError with status 422
+func IsUnprocessable(err error) bool {
+	return hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// IsUnavailable reports whether err is an APIError with status 503, i.e. the
+// feature is not configured on the server
+func IsUnavailable(err error) bool {
+	return hasStatus(err, http.StatusServiceUnavailable)
+}
+
+// IsMisdirected reports whether err is an APIError with status 421, i.e. the
+// request belongs to another region; APIError.Endpoint says where to send it
+func IsMisdirected(err error) bool {
+	return hasStatus(err, http.StatusMisdirectedRequest)
+}
+
+// Error codes the server sets on refused sales
+const (
+	CodeDeviceClosed   = "device_closed"
+	CodeSaleRestricted = "sale_restricted"
+	// CodeWeightMismatch is set when checkout waits for an attendant to
+	// resolve the session's weight mismatch with OverrideWeight
+	CodeWeightMismatch = "weight_mismatch"
+	// CodeItemReviewPending is set when checkout waits for an attendant to
+	// review the lines detected below the confidence threshold with ReviewItem
+	CodeItemReviewPending = "item_review_pending"
+	// CodeFraudSuspected is set when a fraud rule blocked the session
+	CodeFraudSuspected = "fraud_suspected"
+	// CodeCouponNotApplicable is set when the session's coupon has expired,
+	// been withdrawn or used up, or the basket does not qualify for it
+	CodeCouponNotApplicable = "coupon_not_applicable"
+)
+
+// CodeCurrencyConversionUnavailable is set when a basket mixes currencies
+// and an item's price could not be converted into the basket's currency
+const CodeCurrencyConversionUnavailable = "currency_conversion_unavailable"
+
+// CodeSessionConflict is set when a session changed while the request was
+// applied to it; the request can be retried as is
+const CodeSessionConflict = "session_conflict"
+
+// CodeSessionExtensionLimit is set when a session cannot be extended any
+// further past its start
+const CodeSessionExtensionLimit = "session_extension_limit"
+
+// HasCode reports whether err is an APIError carrying the given error code
+func HasCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// GenerateInvoiceRequest is the payload for issuing a monthly invoice
+type GenerateInvoiceRequest struct {
+	CustomerID  string `json:"customer_id"`
+	Period      string `json:"period"` // YYYY-MM, must be a closed month
+	CompanyName string `json:"company_name"`
+	VATID       string `json:"vat_id,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Email       string `json:"email,omitempty"`
+	SendEmail   bool   `json:"send_email,omitempty"`
+}
+
+// SendInvoiceRequest overrides the recipient of an invoice email
+type SendInvoiceRequest struct {
+	To string `json:"to,omitempty"`
+}
+
+// InvoiceResponse is returned by invoice commands
+type InvoiceResponse struct {
+	InvoiceID  string `json:"invoice_id"`
+	Number     string `json:"number"`
+	Period     string `json:"period"`
+	NetCents   int64  `json:"net_cents"`
+	VATCents   int64  `json:"vat_cents"`
+	GrossCents int64  `json:"gross_cents"`
+	Currency   string `json:"currency"`
+	LineCount  int    `json:"line_count"`
+}
+
+// InvoiceLine is one transaction on an invoice
+type InvoiceLine struct {
+	SessionID   string `json:"session_id"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	GrossCents  int64  `json:"gross_cents"`
+}
+
+// Invoice is the invoice detail including its lines
+type Invoice struct {
+	ID          string        `json:"id"`
+	Number      string        `json:"number"`
+	CustomerID  string        `json:"customer_id"`
+	CompanyName string        `json:"company_name"`
+	VATID       string        `json:"vat_id"`
+	Address     string        `json:"address"`
+	Email       string        `json:"email"`
+	Period      string        `json:"period"`
+	Lines       []InvoiceLine `json:"lines"`
+	VATRateBP   int           `json:"vat_rate_bp"`
+	NetCents    int64         `json:"net_cents"`
+	VATCents    int64         `json:"vat_cents"`
+	GrossCents  int64         `json:"gross_cents"`
+	Currency    string        `json:"currency"`
+	IssuedAt    string        `json:"issued_at"`
+	EmailedAt   *string       `json:"emailed_at"`
+}
+
+// GenerateInvoice calls POST /api/v1/invoices
+func (c *Client) GenerateInvoice(ctx context.Context, req GenerateInvoiceRequest, opts ...RequestOption) (*InvoiceResponse, error) {
+	var resp InvoiceResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/invoices", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListInvoices calls GET /api/v1/invoices?customer_id=
+func (c *Client) ListInvoices(ctx context.Context, customerID string, opts ...RequestOption) ([]Invoice, error) {
+	var resp []Invoice
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/invoices?customer_id="+url.QueryEscape(customerID), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetInvoice calls GET /api/v1/invoices/:id
+func (c *Client) GetInvoice(ctx context.Context, id string, opts ...RequestOption) (*Invoice, error) {
+	var resp Invoice
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/invoices/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SendInvoice calls POST /api/v1/invoices/:id/send
+func (c *Client) SendInvoice(ctx context.Context, id string, req SendInvoiceRequest, opts ...RequestOption) (*InvoiceResponse, error) {
+	var resp InvoiceResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/invoices/"+url.PathEscape(id)+"/send", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// InvoicePDF calls GET /api/v1/invoices/:id/pdf and returns the raw PDF
+func (c *Client) InvoicePDF(ctx context.Context, id string, opts ...RequestOption) ([]byte, error) {
+	var rc requestConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, apiPrefix+"/invoices/"+url.PathEscape(id)+"/pdf", "", nil, rc)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ExperimentVariant is one arm of a price experiment. SKUs without a price
+// keep their catalog price, so a variant without prices is a control.
+type ExperimentVariant struct {
+	Name   string           `json:"name"`
+	Weight int              `json:"weight,omitempty"`
+	Prices map[string]int64 `json:"prices,omitempty"` // SKU code -> price in cents
+}
+
+// CreateExperimentRequest is the payload for defining a price experiment
+type CreateExperimentRequest struct {
+	Name      string              `json:"name"`
+	Variants  []ExperimentVariant `json:"variants"`
+	DeviceIDs []string            `json:"device_ids,omitempty"` // empty means every device
+	StartsAt  *time.Time          `json:"starts_at,omitempty"`  // defaults to now
+	EndsAt    time.Time           `json:"ends_at"`
+}
+
+// CreateExperimentResponse is returned after creating an experiment
+type CreateExperimentResponse struct {
+	ExperimentID string `json:"experiment_id"`
+	Status       string `json:"status"`
+}
+
+// Experiment is a price experiment definition
+type Experiment struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Status    string              `json:"status"` // scheduled, running or ended
+	Variants  []ExperimentVariant `json:"variants"`
+	DeviceIDs []string            `json:"device_ids"`
+	SKUCodes  []string            `json:"sku_codes"`
+	StartsAt  time.Time           `json:"starts_at"`
+	EndsAt    time.Time           `json:"ends_at"`
+	StoppedAt *time.Time          `json:"stopped_at"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// ExperimentVariantResult holds conversion and revenue metrics for a variant
+type ExperimentVariantResult struct {
+	Variant                string  `json:"variant"`
+	Sessions               int64   `json:"sessions"`
+	CompletedSessions      int64   `json:"completed_sessions"`
+	ConversionRate         float64 `json:"conversion_rate"`
+	RevenueCents           int64   `json:"revenue_cents"`
+	RevenuePerSessionCents float64 `json:"revenue_per_session_cents"`
+	Currency               string  `json:"currency"`
+}
+
+// ExperimentResults compares the variants of an experiment
+type ExperimentResults struct {
+	Experiment Experiment                `json:"experiment"`
+	Variants   []ExperimentVariantResult `json:"variants"`
+}
+
+// CreateExperiment calls POST /api/v1/experiments
+func (c *Client) CreateExperiment(ctx context.Context, req CreateExperimentRequest, opts ...RequestOption) (*CreateExperimentResponse, error) {
+	var resp CreateExperimentResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/experiments", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListExperiments calls GET /api/v1/experiments
+func (c *Client) ListExperiments(ctx context.Context, opts ...RequestOption) ([]Experiment, error) {
+	var resp []Experiment
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/experiments", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetExperiment calls GET /api/v1/experiments/:id
+func (c *Client) GetExperiment(ctx context.Context, id string, opts ...RequestOption) (*Experiment, error) {
+	var resp Experiment
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/experiments/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StopExperiment calls POST /api/v1/experiments/:id/stop
+func (c *Client) StopExperiment(ctx context.Context, id string, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/experiments/"+url.PathEscape(id)+"/stop", nil, nil, opts...)
+}
+
+// ExperimentResults calls GET /api/v1/experiments/:id/results
+func (c *Client) ExperimentResults(ctx context.Context, id string, opts ...RequestOption) (*ExperimentResults, error) {
+	var resp ExperimentResults
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/experiments/"+url.PathEscape(id)+"/results", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateCouponRequest is the payload for defining a coupon. A coupon takes
+// either PercentOff or AmountOffCents off; amounts are in Currency.
+type CreateCouponRequest struct {
+	Code           string     `json:"code"`
+	PercentOff     int        `json:"percent_off,omitempty"`
+	AmountOffCents int64      `json:"amount_off_cents,omitempty"`
+	MinBasketCents int64      `json:"min_basket_cents,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions,omitempty"` // 1 for single-use, 0 for no limit
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`      // nil never expires
+}
+
+// CreateCouponResponse is returned after creating a coupon
+type CreateCouponResponse struct {
+	CouponID string `json:"coupon_id"`
+	Code     string `json:"code"`
+}
+
+// Coupon is a coupon definition and how often it was redeemed
+type Coupon struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Status         string     `json:"status"` // active, expired or disabled
+	PercentOff     int        `json:"percent_off,omitempty"`
+	AmountOffCents int64      `json:"amount_off_cents,omitempty"`
+	MinBasketCents int64      `json:"min_basket_cents,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	Redemptions    int        `json:"redemptions"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreateCoupon calls POST /api/v1/coupons
+func (c *Client) CreateCoupon(ctx context.Context, req CreateCouponRequest, opts ...RequestOption) (*CreateCouponResponse, error) {
+	var resp CreateCouponResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/coupons", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListCoupons calls GET /api/v1/coupons
+func (c *Client) ListCoupons(ctx context.Context, opts ...RequestOption) ([]Coupon, error) {
+	var resp []Coupon
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/coupons", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetCoupon calls GET /api/v1/coupons/:id
+func (c *Client) GetCoupon(ctx context.Context, id string, opts ...RequestOption) (*Coupon, error) {
+	var resp Coupon
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/coupons/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DisableCoupon calls POST /api/v1/coupons/:id/disable
+func (c *Client) DisableCoupon(ctx context.Context, id string, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/coupons/"+url.PathEscape(id)+"/disable", nil, nil, opts...)
+}
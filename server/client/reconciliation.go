@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ReconciliationLine is the revenue impact of one SKU on one device
+type ReconciliationLine struct {
+	DeviceID          string `json:"device_id"`
+	SKUCode           string `json:"sku_code"`
+	Currency          string `json:"currency"`
+	Sessions          int    `json:"sessions"`
+	UnderchargedUnits int    `json:"undercharged_units"`
+	OverchargedUnits  int    `json:"overcharged_units"`
+	UnderchargedCents int64  `json:"undercharged_cents"`
+	OverchargedCents  int64  `json:"overcharged_cents"`
+	NetImpactCents    int64  `json:"net_impact_cents"`
+}
+
+// SessionDiscrepancy is one SKU of one session the cloud model disagrees with.
+// A positive impact was not charged; a negative impact was overcharged.
+type SessionDiscrepancy struct {
+	SessionID   string `json:"session_id"`
+	DeviceID    string `json:"device_id"`
+	SKUCode     string `json:"sku_code"`
+	Charged     int    `json:"charged"`
+	Detected    int    `json:"detected"`
+	ImpactCents int64  `json:"impact_cents"`
+	Currency    string `json:"currency"`
+}
+
+// ReconciliationReport compares a day of charged sessions with the cloud model
+type ReconciliationReport struct {
+	Day                     string               `json:"day"`
+	ModelVersion            string               `json:"model_version"`
+	SessionsChecked         int                  `json:"sessions_checked"`
+	SessionsSkipped         int                  `json:"sessions_skipped"`
+	SessionsWithDiscrepancy int                  `json:"sessions_with_discrepancy"`
+	GeneratedAt             time.Time            `json:"generated_at"`
+	Lines                   []ReconciliationLine `json:"lines"`
+	Discrepancies           []SessionDiscrepancy `json:"discrepancies"`
+}
+
+// RunReconciliation calls POST /api/v1/reconciliation/runs for a past day (UTC).
+// Without rerun an existing report for the day is returned unchanged.
+func (c *Client) RunReconciliation(ctx context.Context, day time.Time, rerun bool, opts ...RequestOption) (*ReconciliationReport, error) {
+	req := struct {
+		Day   string `json:"day"`
+		Rerun bool   `json:"rerun"`
+	}{day.UTC().Format("2006-01-02"), rerun}
+
+	var resp ReconciliationReport
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/reconciliation/runs", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReconciliationReport calls GET /api/v1/reconciliation/reports/:day, narrowed
+// to one device when deviceID is not empty
+func (c *Client) ReconciliationReport(ctx context.Context, day time.Time, deviceID string, opts ...RequestOption) (*ReconciliationReport, error) {
+	path := apiPrefix + "/reconciliation/reports/" + day.UTC().Format("2006-01-02")
+	if deviceID != "" {
+		path += "?device_id=" + url.QueryEscape(deviceID)
+	}
+
+	var resp ReconciliationReport
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
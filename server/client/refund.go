@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithActor identifies the operator staff member making a request. The
+// server trusts these headers, so they must be set by an authenticating proxy
+// or a trusted back-office tool.
+func WithActor(id string, roles ...string) RequestOption {
+	return func(rc *requestConfig) {
+		WithHeader("X-Actor-ID", id)(rc)
+		if len(roles) > 0 {
+			WithHeader("X-Actor-Roles", strings.Join(roles, ","))(rc)
+		}
+	}
+}
+
+// RequestRefundRequest is the payload for requesting a refund
+type RequestRefundRequest struct {
+	AmountCents int64  `json:"amount_cents"`
+	Reason      string `json:"reason"`
+}
+
+// RefundResponse is returned by refund commands
+type RefundResponse struct {
+	RefundID      string `json:"refund_id"`
+	SessionID     string `json:"session_id"`
+	TransactionID string `json:"transaction_id"` // the sale the refund is against
+	AmountCents   int64  `json:"amount_cents"`
+	RoundingCents int64  `json:"rounding_cents"` // AmountCents minus the requested amount
+	Currency      string `json:"currency"`
+	Status        string `json:"status"` // pending_approval, approved or rejected
+}
+
+// RefundAuditEntry records who did what to a refund
+type RefundAuditEntry struct {
+	Action  string   `json:"action"`
+	ActorID string   `json:"actor_id"`
+	Roles   []string `json:"roles"`
+	Note    string   `json:"note"`
+	At      string   `json:"at"`
+}
+
+// Refund is the refund detail including its audit trail
+type Refund struct {
+	ID            string             `json:"id"`
+	SessionID     string             `json:"session_id"`
+	TransactionID string             `json:"transaction_id"`
+	AmountCents   int64              `json:"amount_cents"`
+	RoundingCents int64              `json:"rounding_cents"`
+	Currency      string             `json:"currency"`
+	Reason        string             `json:"reason"`
+	Status        string             `json:"status"`
+	RequestedBy   string             `json:"requested_by"`
+	DecidedBy     string             `json:"decided_by"`
+	CreatedAt     string             `json:"created_at"`
+	DecidedAt     *string            `json:"decided_at"`
+	AuditTrail    []RefundAuditEntry `json:"audit_trail"`
+}
+
+// RequestRefund calls POST /api/v1/session/:id/refunds
+func (c *Client) RequestRefund(ctx context.Context, sessionID string, req RequestRefundRequest, opts ...RequestOption) (*RefundResponse, error) {
+	var resp RefundResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/refunds", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SessionRefunds calls GET /api/v1/session/:id/refunds
+func (c *Client) SessionRefunds(ctx context.Context, sessionID string, opts ...RequestOption) ([]Refund, error) {
+	var resp struct {
+		Refunds []Refund `json:"refunds"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/refunds", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Refunds, nil
+}
+
+// PendingRefunds calls GET /api/v1/refunds/pending
+func (c *Client) PendingRefunds(ctx context.Context, opts ...RequestOption) ([]Refund, error) {
+	var resp struct {
+		Refunds []Refund `json:"refunds"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/refunds/pending", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Refunds, nil
+}
+
+// GetRefund calls GET /api/v1/refunds/:id
+func (c *Client) GetRefund(ctx context.Context, id string, opts ...RequestOption) (*Refund, error) {
+	var resp Refund
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/refunds/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ApproveRefund calls POST /api/v1/refunds/:id/approve
+func (c *Client) ApproveRefund(ctx context.Context, id, note string, opts ...RequestOption) (*RefundResponse, error) {
+	return c.decideRefund(ctx, id, "approve", note, opts...)
+}
+
+// RejectRefund calls POST /api/v1/refunds/:id/reject
+func (c *Client) RejectRefund(ctx context.Context, id, note string, opts ...RequestOption) (*RefundResponse, error) {
+	return c.decideRefund(ctx, id, "reject", note, opts...)
+}
+
+func (c *Client) decideRefund(ctx context.Context, id, decision, note string, opts ...RequestOption) (*RefundResponse, error) {
+	req := struct {
+		Note string `json:"note,omitempty"`
+	}{Note: note}
+
+	var resp RefundResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/refunds/"+url.PathEscape(id)+"/"+decision, req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
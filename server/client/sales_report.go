@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SalesReportFilter selects the completed sales to aggregate. GroupBy is
+// day, device or sku (day when empty); a zero To is now and a zero From is
+// 30 days before To.
+type SalesReportFilter struct {
+	GroupBy string
+	From    time.Time
+	To      time.Time
+}
+
+// SalesReportLine holds the figures of one day, device or SKU in one
+// currency. Grouped by SKU, revenue is the SKU's line totals before tax and
+// discounts, over the transactions that sold it.
+type SalesReportLine struct {
+	Key                string  `json:"key"` // the day (YYYY-MM-DD, UTC), device ID or SKU code
+	Currency           string  `json:"currency"`
+	Transactions       int64   `json:"transactions"`
+	RevenueCents       int64   `json:"revenue_cents"`
+	Units              int64   `json:"units"`
+	AverageBasketCents int64   `json:"average_basket_cents"`
+	AverageBasketUnits float64 `json:"average_basket_units"`
+}
+
+// SalesReport aggregates the sales completed in [From, To)
+type SalesReport struct {
+	GroupBy string            `json:"group_by"`
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Lines   []SalesReportLine `json:"lines"`
+}
+
+// SalesReport calls GET /api/v1/reports/sales
+func (c *Client) SalesReport(ctx context.Context, filter SalesReportFilter, opts ...RequestOption) (*SalesReport, error) {
+	query := periodQuery(filter.From, filter.To)
+	if filter.GroupBy != "" {
+		query.Set("group_by", filter.GroupBy)
+	}
+	path := apiPrefix + "/reports/sales"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp SalesReport
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
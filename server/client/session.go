@@ -0,0 +1,572 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// StartSessionRequest is the payload for starting a session.
+// Token is the signed value scanned from the device QR code.
+type StartSessionRequest struct {
+	Token     string `json:"token,omitempty"`
+	MachineID string `json:"machine_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+}
+
+// StartSessionResponse is returned after starting a session
+type StartSessionResponse struct {
+	SessionID     string         `json:"session_id"`
+	DeviceID      string         `json:"device_id"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	Message       string         `json:"message"`
+	StreamToken   string         `json:"stream_token"`
+	PaymentIntent *PaymentIntent `json:"payment_intent,omitempty"`
+}
+
+// PaymentIntent is returned for guest sessions so the app can collect the card
+type PaymentIntent struct {
+	ID           string `json:"id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// Session is the session detail returned by GET /api/v1/session/:id
+type Session struct {
+	Session struct {
+		ID        string `json:"id"`
+		DeviceID  string `json:"device_id"`
+		Status    string `json:"status"`
+		CreatedAt string `json:"created_at"`
+		ExpiresAt string `json:"expires_at"`
+	} `json:"session"`
+	Items         []SessionItem       `json:"items"`
+	SubtotalCents int64               `json:"subtotal_cents"`
+	DiscountCents int64               `json:"discount_cents,omitempty"`
+	Coupon        *AppliedCoupon      `json:"coupon,omitempty"`
+	TaxCents      int64               `json:"tax_cents"`
+	TaxLines      []TaxLine           `json:"tax_lines,omitempty"`
+	TaxIncluded   bool                `json:"tax_included,omitempty"`
+	RoundingCents int64               `json:"rounding_cents"`
+	TotalCents    int64               `json:"total_cents"`
+	Currency      string              `json:"currency"`
+	PaymentMethod *PaymentMethod      `json:"payment_method,omitempty"`
+	Fiscal        *FiscalRecord       `json:"fiscal,omitempty"`
+	Experiments   []SessionExperiment `json:"experiments,omitempty"`
+
+	// CloudVerificationRequired is set on the first session after a security incident
+	CloudVerificationRequired bool `json:"cloud_verification_required,omitempty"`
+	// WeightMismatch flags a basket that does not match the measured weight;
+	// WeightOverride is set once an attendant resolved it
+	WeightMismatch bool            `json:"weight_mismatch,omitempty"`
+	WeightOverride *WeightOverride `json:"weight_override,omitempty"`
+	// FraudFlags are the fraud rules the session matched; a FraudBlocked
+	// session takes no more detections and cannot be checked out
+	FraudFlags   []FraudFlag `json:"fraud_flags,omitempty"`
+	FraudBlocked bool        `json:"fraud_blocked,omitempty"`
+	// UnrecognizedItems are the codes detected in the session that the
+	// catalog does not sell, each with its most confident sighting
+	UnrecognizedItems []UnrecognizedItem `json:"unrecognized_items,omitempty"`
+	// Notes are what support staff attached to the session, oldest first;
+	// only GetSession returns them
+	Notes []SessionNote `json:"notes,omitempty"`
+}
+
+// SessionNote is a support staff member's annotation on a session
+type SessionNote struct {
+	ID        int64     `json:"id"`
+	AuthorID  string    `json:"author_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AppliedCoupon is the coupon a session is checked out with
+type AppliedCoupon struct {
+	Code          string `json:"code"`
+	DiscountCents int64  `json:"discount_cents"`
+}
+
+// FraudFlag is a fraud rule a session matched
+type FraudFlag struct {
+	Rule       string    `json:"rule"`   // weight_deviation, repeated_cancellations or early_detection
+	Action     string    `json:"action"` // flag or block
+	Reason     string    `json:"reason"`
+	Stage      string    `json:"stage"` // submit or confirm
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// WeightOverride records how an attendant resolved a weight mismatch
+type WeightOverride struct {
+	ActorID      string    `json:"actor_id"`
+	Decision     string    `json:"decision"` // approved or corrected
+	Reason       string    `json:"reason"`
+	OverriddenAt time.Time `json:"overridden_at"`
+}
+
+// WeightOverrideRequest resolves a session's weight mismatch. Items are the
+// basket the attendant counted and replace it when Decision is "corrected".
+type WeightOverrideRequest struct {
+	Decision string              `json:"decision"`
+	Reason   string              `json:"reason"`
+	Items    []CorrectedItemLine `json:"items,omitempty"`
+}
+
+// CorrectedItemLine is a line of the basket counted by an attendant
+type CorrectedItemLine struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// ItemReviewRequest resolves a line awaiting review. Decision is "accepted"
+// or "replaced"; a replaced line becomes Quantity units of ReplacementSKU,
+// or as many as the line held when Quantity is 0.
+type ItemReviewRequest struct {
+	SKU            string `json:"sku"`
+	Decision       string `json:"decision"`
+	ReplacementSKU string `json:"replacement_sku,omitempty"`
+	Quantity       int    `json:"quantity,omitempty"`
+}
+
+// PendingReview is a session in the attendants' review queue with its lines
+// awaiting review
+type PendingReview struct {
+	SessionID string        `json:"session_id"`
+	DeviceID  string        `json:"device_id"`
+	CreatedAt string        `json:"created_at"`
+	ExpiresAt string        `json:"expires_at"`
+	Items     []SessionItem `json:"items"`
+}
+
+// TaxLine is the tax charged at one rate. When TaxIncluded is set on the
+// session the tax is part of the subtotal rather than added to it.
+type TaxLine struct {
+	RatePercent  float64 `json:"rate_percent"`
+	TaxableCents int64   `json:"taxable_cents"`
+	TaxCents     int64   `json:"tax_cents"`
+}
+
+// SessionExperiment is the price experiment variant a session was priced with
+type SessionExperiment struct {
+	ExperimentID string `json:"experiment_id"`
+	Variant      string `json:"variant"`
+}
+
+// FiscalRecord holds the fiscal identifiers that must be printed on the
+// receipt in countries with fiscalization (e.g. German TSE, Italian RT)
+type FiscalRecord struct {
+	Country          string `json:"country"`
+	Provider         string `json:"provider"`
+	DocumentNumber   string `json:"document_number"`
+	SerialNumber     string `json:"serial_number"`
+	Signature        string `json:"signature"`
+	SignatureCounter int64  `json:"signature_counter"`
+	QRCode           string `json:"qr_code"`
+	IssuedAt         string `json:"issued_at"`
+}
+
+// PaymentMethod is the receipt metadata for how a session was paid
+type PaymentMethod struct {
+	Wallet string `json:"wallet"`
+	Brand  string `json:"brand"`
+	Last4  string `json:"last4"`
+}
+
+// ConfirmSessionResponse is returned after confirming a session
+type ConfirmSessionResponse struct {
+	Status        string         `json:"status"`
+	Message       string         `json:"message"`
+	SessionID     string         `json:"session_id"`
+	TransactionID string         `json:"transaction_id,omitempty"` // the recorded sale; empty while awaiting payment
+	SubtotalCents int64          `json:"subtotal_cents"`
+	DiscountCents int64          `json:"discount_cents,omitempty"`
+	Coupon        *AppliedCoupon `json:"coupon,omitempty"`
+	TaxCents      int64          `json:"tax_cents"`
+	TaxLines      []TaxLine      `json:"tax_lines,omitempty"`
+	TaxIncluded   bool           `json:"tax_included,omitempty"`
+	RoundingCents int64          `json:"rounding_cents"`
+	TotalCents    int64          `json:"total_cents"`
+	Currency      string         `json:"currency"`
+	PaymentMethod *PaymentMethod `json:"payment_method,omitempty"`
+	Fiscal        *FiscalRecord  `json:"fiscal,omitempty"`
+}
+
+// WalletPaymentRequest is the payload for paying with Apple Pay or Google Pay.
+// Wallet is "apple_pay" or "google_pay"; Token is the provider token.
+type WalletPaymentRequest struct {
+	Wallet string `json:"wallet"`
+	Token  string `json:"token"`
+}
+
+// WalletPaymentResponse is returned after paying with a wallet
+type WalletPaymentResponse struct {
+	SessionID       string         `json:"session_id"`
+	PaymentIntentID string         `json:"payment_intent_id"`
+	Status          string         `json:"status"`
+	AmountCents     int64          `json:"amount_cents"`
+	Currency        string         `json:"currency"`
+	PaymentMethod   *PaymentMethod `json:"payment_method,omitempty"`
+}
+
+// PaymentIntentResponse is the provider payment intent a session is paid
+// through; the app collects the card with ClientSecret
+type PaymentIntentResponse struct {
+	SessionID       string `json:"session_id"`
+	PaymentIntentID string `json:"payment_intent_id"`
+	ClientSecret    string `json:"client_secret"`
+	Status          string `json:"status"`
+	AmountCents     int64  `json:"amount_cents"`
+	Currency        string `json:"currency"`
+}
+
+// ApplePayMerchantSessionRequest is the payload for Apple Pay merchant validation
+type ApplePayMerchantSessionRequest struct {
+	ValidationURL string `json:"validation_url"`
+	DomainName    string `json:"domain_name"`
+}
+
+// CancelSessionResponse is returned after cancelling a session
+type CancelSessionResponse struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	SessionID string `json:"session_id"`
+}
+
+// ExtendSessionResponse is the expiry of an extended session
+type ExtendSessionResponse struct {
+	SessionID string    `json:"session_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Recommendation is a complementary SKU suggested during a session
+type Recommendation struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	PriceCents int64   `json:"price_cents"`
+	Currency   string  `json:"currency"`
+	Score      float64 `json:"score"`
+}
+
+// StartSession calls POST /api/v1/session/start
+func (c *Client) StartSession(ctx context.Context, req StartSessionRequest, opts ...RequestOption) (*StartSessionResponse, error) {
+	var resp StartSessionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/start", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSession calls GET /api/v1/session/:id
+func (c *Client) GetSession(ctx context.Context, id string, opts ...RequestOption) (*Session, error) {
+	var resp Session
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/session/"+url.PathEscape(id), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddSessionNote calls POST /api/v1/session/:id/notes; the author is the
+// actor set with WithActor
+func (c *Client) AddSessionNote(ctx context.Context, sessionID, note string, opts ...RequestOption) (*SessionNote, error) {
+	req := struct {
+		Note string `json:"note"`
+	}{Note: note}
+
+	var resp SessionNote
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(sessionID)+"/notes", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResumedSession is the active session of a user on a device, with a fresh
+// stream token to follow it live again
+type ResumedSession struct {
+	Session
+	StreamToken string `json:"stream_token,omitempty"`
+}
+
+// ActiveSession calls GET /api/v1/session/active?machine_id=&user_id=, for an
+// app that lost connectivity to pick up the session it already has open on the
+// device instead of starting another one
+func (c *Client) ActiveSession(ctx context.Context, machineID, userID string, opts ...RequestOption) (*ResumedSession, error) {
+	query := url.Values{"machine_id": {machineID}, "user_id": {userID}}
+	var resp ResumedSession
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/session/active?"+query.Encode(), nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ConfirmSession calls POST /api/v1/session/:id/confirm. With a payment
+// provider configured, the session's payment intent must have been paid;
+// paymentRef only counts where there is none. While the provider is still
+// processing the payment the response Status is "awaiting_payment" and the
+// session completes once the provider reports the outcome. Retrying a
+// confirmation that went through, with the same paymentRef, returns the
+// original result, so a timed out call is safe to repeat.
+func (c *Client) ConfirmSession(ctx context.Context, id, paymentRef string, opts ...RequestOption) (*ConfirmSessionResponse, error) {
+	req := struct {
+		PaymentRef string `json:"payment_ref"`
+	}{PaymentRef: paymentRef}
+
+	var resp ConfirmSessionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/confirm", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelSession calls POST /api/v1/session/:id/cancel
+func (c *Client) CancelSession(ctx context.Context, id, reason string, opts ...RequestOption) (*CancelSessionResponse, error) {
+	req := struct {
+		Reason string `json:"reason,omitempty"`
+	}{Reason: reason}
+
+	var resp CancelSessionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/cancel", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExtendSession calls POST /api/v1/session/:id/extend, pushing the session's
+// expiry back by minutes; 0 extends it by its device's session expiration.
+// The server never extends a session past its maximum duration.
+func (c *Client) ExtendSession(ctx context.Context, id string, minutes int, opts ...RequestOption) (*ExtendSessionResponse, error) {
+	req := struct {
+		Minutes int `json:"minutes,omitempty"`
+	}{Minutes: minutes}
+
+	var resp ExtendSessionResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/extend", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Detection is a submission in a session's detection audit log. Payload is
+// the submission without its image; Decision is the result it got, or its
+// error when Outcome is "rejected".
+type Detection struct {
+	ID         int64           `json:"id"`
+	DeviceID   string          `json:"device_id"`
+	Source     string          `json:"source"`  // device, offline_sync or cloud_verification
+	Outcome    string          `json:"outcome"` // applied, replayed or rejected
+	Payload    json.RawMessage `json:"payload"`
+	Decision   json.RawMessage `json:"decision"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// SessionDetections calls GET /api/v1/session/:id/detections and returns
+// the session's detections, oldest first
+func (c *Client) SessionDetections(ctx context.Context, id string, opts ...RequestOption) ([]Detection, error) {
+	var resp struct {
+		Detections []Detection `json:"detections"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/session/"+url.PathEscape(id)+"/detections", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Detections, nil
+}
+
+// OverrideWeight calls POST /api/v1/session/:id/weight-override and returns
+// the session after the override. The attendant must be identified with
+// WithActor.
+func (c *Client) OverrideWeight(ctx context.Context, id string, req WeightOverrideRequest, opts ...RequestOption) (*Session, error) {
+	var resp Session
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/weight-override", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReviewItem calls POST /api/v1/session/:id/item-review and returns the
+// session after the review. The attendant must be identified with WithActor.
+func (c *Client) ReviewItem(ctx context.Context, id string, req ItemReviewRequest, opts ...RequestOption) (*Session, error) {
+	var resp Session
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/item-review", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PendingReviews calls GET /api/v1/reviews/pending and returns the active
+// sessions with lines awaiting review, oldest first
+func (c *Client) PendingReviews(ctx context.Context, opts ...RequestOption) ([]PendingReview, error) {
+	var resp struct {
+		Sessions []PendingReview `json:"sessions"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/reviews/pending", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// ApplyCoupon calls POST /api/v1/session/:id/apply-coupon and returns the
+// session priced with the coupon's discount
+func (c *Client) ApplyCoupon(ctx context.Context, id, code string, opts ...RequestOption) (*Session, error) {
+	req := struct {
+		Code string `json:"code"`
+	}{Code: code}
+	var resp Session
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/apply-coupon", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreatePaymentIntent calls POST /api/v1/session/:id/pay to pay the session
+// total by card
+func (c *Client) CreatePaymentIntent(ctx context.Context, id string, opts ...RequestOption) (*PaymentIntentResponse, error) {
+	var resp PaymentIntentResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/pay", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PayWithWallet calls POST /api/v1/session/:id/pay/wallet
+func (c *Client) PayWithWallet(ctx context.Context, id string, req WalletPaymentRequest, opts ...RequestOption) (*WalletPaymentResponse, error) {
+	var resp WalletPaymentResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/session/"+url.PathEscape(id)+"/pay/wallet", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ApplePayMerchantSession calls POST /api/v1/payments/apple-pay/merchant-session.
+// The returned opaque merchant session is passed to completeMerchantValidation.
+func (c *Client) ApplePayMerchantSession(ctx context.Context, req ApplePayMerchantSessionRequest, opts ...RequestOption) (json.RawMessage, error) {
+	var resp json.RawMessage
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/payments/apple-pay/merchant-session", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SessionRecommendations calls GET /api/v1/sessions/:id/recommendations.
+// A limit of 0 uses the server default.
+func (c *Client) SessionRecommendations(ctx context.Context, id string, limit int, opts ...RequestOption) ([]Recommendation, error) {
+	path := apiPrefix + "/sessions/" + url.PathEscape(id) + "/recommendations"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+
+	var resp struct {
+		Recommendations []Recommendation `json:"recommendations"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp.Recommendations, nil
+}
+
+// SessionSummary is one session of the session history
+type SessionSummary struct {
+	ID          string     `json:"id"`
+	DeviceID    string     `json:"device_id"`
+	UserID      string     `json:"user_id,omitempty"`
+	Status      string     `json:"status"`
+	Items       int        `json:"items"`
+	TotalCents  int64      `json:"total_cents"`
+	Currency    string     `json:"currency"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// SessionHistoryFilter narrows ListSessions; zero fields don't filter
+type SessionHistoryFilter struct {
+	DeviceID string
+	Status   string    // active, completed, cancelled or expired
+	From     time.Time // started at or after
+	To       time.Time // started before
+	Limit    int       // page size; zero uses the server's default of 50
+	Cursor   string    // NextCursor of the previous page
+}
+
+// SessionHistory is one page of sessions, newest first
+type SessionHistory struct {
+	Sessions   []SessionSummary `json:"sessions"`
+	Limit      int              `json:"limit"`
+	NextCursor string           `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// ListSessions calls GET /api/v1/sessions
+func (c *Client) ListSessions(ctx context.Context, filter SessionHistoryFilter, opts ...RequestOption) (*SessionHistory, error) {
+	query := url.Values{}
+	if filter.DeviceID != "" {
+		query.Set("device_id", filter.DeviceID)
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.UTC().Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.UTC().Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Cursor != "" {
+		query.Set("cursor", filter.Cursor)
+	}
+	path := apiPrefix + "/sessions"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp SessionHistory
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Purchase is one completed session of a user's purchase history
+type Purchase struct {
+	SessionID     string         `json:"session_id"`
+	DeviceID      string         `json:"device_id"`
+	Items         []SessionItem  `json:"items"`
+	SubtotalCents int64          `json:"subtotal_cents"`
+	TaxCents      int64          `json:"tax_cents"`
+	RoundingCents int64          `json:"rounding_cents"`
+	TotalCents    int64          `json:"total_cents"`
+	Currency      string         `json:"currency"`
+	CompletedAt   *time.Time     `json:"completed_at"`
+	PaymentMethod *PaymentMethod `json:"payment_method,omitempty"`
+}
+
+// PurchaseHistory is one page of a user's purchases, newest first
+type PurchaseHistory struct {
+	UserID     string     `json:"user_id"`
+	Purchases  []Purchase `json:"sessions"`
+	Limit      int        `json:"limit"`
+	NextCursor string     `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// UserPurchases calls GET /api/v1/users/:id/sessions. A limit of 0 uses the
+// server default; cursor is the NextCursor of the previous page. Item names
+// follow WithLanguage.
+func (c *Client) UserPurchases(ctx context.Context, userID string, limit int, cursor string, opts ...RequestOption) (*PurchaseHistory, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	path := apiPrefix + "/users/" + url.PathEscape(userID) + "/sessions"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp PurchaseHistory
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Status levels of the service and its components
+const (
+	StatusOperational   = "operational"
+	StatusNotConfigured = "not_configured"
+	StatusDegraded      = "degraded"
+	StatusOutage        = "outage"
+)
+
+// ComponentStatus is the availability of one part of the service
+type ComponentStatus struct {
+	Name    string `json:"name"` // api, payments or ml_verification
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Incident is an operator-declared disruption of one component
+type Incident struct {
+	ID         string     `json:"id"`
+	Component  string     `json:"component"`
+	Level      string     `json:"level"` // degraded or outage
+	Message    string     `json:"message"`
+	StartedAt  time.Time  `json:"started_at"`
+	CreatedBy  string     `json:"created_by,omitempty"` // operator endpoints only
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+}
+
+// ServiceStatus is the public status of the service
+type ServiceStatus struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+	Incidents  []Incident        `json:"incidents"` // active only
+}
+
+// Degraded reports whether apps should show a "service degraded" banner
+func (s *ServiceStatus) Degraded() bool {
+	return s.Status == StatusDegraded || s.Status == StatusOutage
+}
+
+// Component returns the status of the named component, if reported
+func (s *ServiceStatus) Component(name string) (ComponentStatus, bool) {
+	for _, c := range s.Components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ComponentStatus{}, false
+}
+
+// RaiseIncidentRequest flags a component as degraded or down
+type RaiseIncidentRequest struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+// Status calls GET /api/v1/status. It needs no credentials.
+func (c *Client) Status(ctx context.Context, opts ...RequestOption) (*ServiceStatus, error) {
+	var resp ServiceStatus
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/status", nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Incidents calls GET /api/v1/status/incidents. A zero since lists active
+// incidents only; otherwise every incident started since then.
+func (c *Client) Incidents(ctx context.Context, since time.Time, opts ...RequestOption) ([]Incident, error) {
+	path := apiPrefix + "/status/incidents"
+	if !since.IsZero() {
+		path += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+
+	var resp []Incident
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RaiseIncident calls POST /api/v1/status/incidents. The operator is
+// identified with WithActor.
+func (c *Client) RaiseIncident(ctx context.Context, req RaiseIncidentRequest, opts ...RequestOption) (*Incident, error) {
+	var resp Incident
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/status/incidents", req, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResolveIncident calls POST /api/v1/status/incidents/:id/resolve. The
+// operator is identified with WithActor.
+func (c *Client) ResolveIncident(ctx context.Context, incidentID string, opts ...RequestOption) (*Incident, error) {
+	var resp Incident
+	path := apiPrefix + "/status/incidents/" + url.PathEscape(incidentID) + "/resolve"
+	if err := c.do(ctx, http.MethodPost, path, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
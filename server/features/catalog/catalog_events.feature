@@ -0,0 +1,49 @@
+@api @catalog
+Feature: Catalog Event Topic
+  As a downstream service such as analytics or ML training
+  I want to read catalog changes from a topic in a documented format
+  So that I stay in sync with the catalog without polling it
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a subscriber has read the "catalog" topic to its end
+
+  Scenario: Creating a SKU publishes its creation
+    When I create a SKU with the following details:
+      | code      | name       | price_cents | weight_grams |
+      | EVT-APPLE | Fuji Apple | 250         | 150          |
+    And the subscriber reads the "catalog" topic
+    Then the response status should be 200
+    And the response field "topic" should be "catalog"
+    And the response field "messages.0.type" should be "catalog.sku.created"
+    And the response field "messages.0.version" should be "1"
+    And the response field "messages.0.payload.code" should be "EVT-APPLE"
+    And the response field "messages.0.payload.name" should be "Fuji Apple"
+
+  Scenario: A price change is published with the old and new price
+    Given the following SKUs exist:
+      | code     | name  | price_cents | weight_grams |
+      | EVT-COLA | Cola  | 200         | 350          |
+    And a subscriber has read the "catalog" topic to its end
+    When "alice" changes the price of the SKU "EVT-COLA" to 250
+    And the subscriber reads the "catalog" topic
+    Then the response field "messages.0.type" should be "catalog.sku.price_changed"
+    And the response field "messages.0.payload.old_price.amount_cents" should be "200"
+    And the response field "messages.0.payload.new_price.amount_cents" should be "250"
+    And the response field "messages.0.payload.new_price.currency" should be "USD"
+    And the response field "messages.1.type" should be "catalog.sku.updated"
+
+  Scenario: A subscriber that has caught up reads nothing new
+    When the subscriber reads the "catalog" topic
+    Then the response status should be 200
+    And the response field "messages" should be "[]"
+
+  Scenario: Unknown topics cannot be read
+    When I send a GET request to "/api/v1/events/payments"
+    Then the response status should be 404
+    And the response should contain error "topic not found"
+
+  Scenario: Offsets must be valid
+    When I send a GET request to "/api/v1/events/catalog?after=-1"
+    Then the response status should be 400
@@ -0,0 +1,92 @@
+@api @catalog
+Feature: Catalog Webhook for the Product-Information System
+  As an operator
+  I want the external product-information system to push product changes to the catalog
+  So that names, prices and weights are maintained in one place
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: The first push of a product creates its SKU
+    When the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1001", "code": "PIM-COLA", "name": "Cola 0.5l", "price_cents": 180, "weight_grams": 530}]}
+      """
+    Then the response status should be 200
+    And the response field "created" should be "1"
+    And the response field "updated" should be "0"
+    And the response field "products.0.external_id" should be "pim-1001"
+    And the response field "products.0.code" should be "PIM-COLA"
+    When I fetch the SKU "PIM-COLA"
+    Then the response field "name" should be "Cola 0.5l"
+    And the response field "price_cents" should be "180"
+
+  Scenario: A later push updates the linked SKU and records the price change
+    Given the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1002", "code": "PIM-WATER", "name": "Water", "price_cents": 120, "weight_grams": 510}]}
+      """
+    When the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1002", "name": "Still Water 0.5l", "price_cents": 140, "weight_grams": 510}]}
+      """
+    Then the response status should be 200
+    And the response field "created" should be "0"
+    And the response field "updated" should be "1"
+    When I fetch the SKU "PIM-WATER"
+    Then the response field "name" should be "Still Water 0.5l"
+    When I request the price history of the SKU "PIM-WATER"
+    Then the response field "count" should be "1"
+    And the response field "changes.0.new_price_cents" should be "140"
+    And the response field "changes.0.changed_by" should be "pim-sync"
+
+  Scenario: A SKU created by hand is linked by its code
+    Given a SKU exists with code "PIM-CHIPS"
+    When the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1003", "code": "PIM-CHIPS", "name": "Paprika Chips", "price_cents": 220, "weight_grams": 75}]}
+      """
+    Then the response status should be 200
+    And the response field "created" should be "0"
+    And the response field "updated" should be "1"
+    When I fetch the SKU "PIM-CHIPS"
+    Then the response field "name" should be "Paprika Chips"
+
+  Scenario: A linked product cannot change its SKU code
+    Given the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1004", "code": "PIM-GUM", "name": "Gum", "price_cents": 90, "weight_grams": 20}]}
+      """
+    When the product-information system pushes:
+      """
+      {"products": [{"external_id": "pim-1004", "code": "PIM-MINTS", "name": "Gum", "price_cents": 90, "weight_grams": 20}]}
+      """
+    Then the response status should be 422
+    And the response field "errors.0.external_id" should be "pim-1004"
+
+  Scenario: A push with an invalid product syncs nothing
+    When the product-information system pushes:
+      """
+      {"products": [
+        {"external_id": "pim-1005", "code": "PIM-TEA", "name": "Iced Tea", "price_cents": 160, "weight_grams": 520},
+        {"external_id": "pim-1006", "code": "PIM-BAR", "name": "", "price_cents": 150, "weight_grams": 50}
+      ]}
+      """
+    Then the response status should be 422
+    And the response should contain error "nothing was synced"
+    And the response field "errors.0.external_id" should be "pim-1006"
+
+  Scenario: A delivery signed with the wrong secret is rejected
+    When the product-information system pushes with the wrong secret:
+      """
+      {"products": [{"external_id": "pim-1007", "code": "PIM-SODA", "name": "Soda", "price_cents": 150, "weight_grams": 520}]}
+      """
+    Then the response status should be 401
+
+  Scenario: A replayed old delivery is rejected
+    When the product-information system pushes a delivery signed 10 minutes ago:
+      """
+      {"products": [{"external_id": "pim-1008", "code": "PIM-JUICE", "name": "Juice", "price_cents": 210, "weight_grams": 330}]}
+      """
+    Then the response status should be 401
@@ -0,0 +1,65 @@
+@api @catalog
+Feature: Product Categories
+  As a catalog manager
+  I want to file SKUs under a tree of categories
+  So that customers and reports can browse the assortment by category
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Create a category below another one
+    Given a category "Fruit" exists
+    When I create the category "Apples" under "Fruit"
+    Then the response status should be 201
+    And the response should contain field "parent_id"
+    When I send a GET request to "/api/v1/categories"
+    Then the response status should be 200
+    And the response field "count" should be "2"
+
+  Scenario: A category needs an existing parent
+    Given a category "Seasonal" exists
+    And I delete the category "Seasonal"
+    When I create the category "Pumpkins" under "Seasonal"
+    Then the response status should be 422
+    And the response should contain error "parent category not found"
+
+  Scenario: Filtering SKUs by category includes subcategories
+    Given the following SKUs exist:
+      | code       | name        | price_cents | weight_grams |
+      | CAT-APPLE  | Fuji Apple  | 250         | 150          |
+      | CAT-PEAR   | Pear        | 230         | 140          |
+      | CAT-WATER  | Still Water | 120         | 500          |
+    And a category "Fruit" exists
+    And a category "Apples" exists under "Fruit"
+    And a category "Drinks" exists
+    When I assign the SKU "CAT-APPLE" to the category "Apples"
+    Then the response status should be 200
+    And the response should contain field "category_id"
+    When I assign the SKU "CAT-PEAR" to the category "Fruit"
+    And I assign the SKU "CAT-WATER" to the category "Drinks"
+    And I list the SKUs in the category "Fruit"
+    Then the response status should be 200
+    And the response should contain 2 SKUs
+    When I deactivate the SKU "CAT-PEAR"
+    And I list the active SKUs in the category "Fruit"
+    Then the response should contain 1 SKUs
+    When I list the SKUs in the category "Apples"
+    Then the response should contain 1 SKUs
+
+  Scenario: A category cannot be moved below its own subcategory
+    Given a category "Fruit" exists
+    And a category "Apples" exists under "Fruit"
+    When I move the category "Fruit" under "Apples"
+    Then the response status should be 422
+    And the response should contain error "category cannot be moved below itself"
+
+  Scenario: A category with subcategories cannot be deleted
+    Given a category "Fruit" exists
+    And a category "Apples" exists under "Fruit"
+    When I delete the category "Fruit"
+    Then the response status should be 409
+    When I delete the category "Apples"
+    Then the response status should be 204
+    When I delete the category "Fruit"
+    Then the response status should be 204
@@ -0,0 +1,86 @@
+@api @catalog
+Feature: ML Class Mapping
+  As an ML engineer
+  I want to say which detection model class recognizes which SKU
+  So that inference results map to SKUs the same way on devices and on the server
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And the following SKUs exist:
+      | code       | name        | price_cents | weight_grams |
+      | CLASS-COLA | Cola 330ml  | 150         | 350          |
+      | CLASS-TEA  | Iced Tea    | 170         | 500          |
+      | CLASS-NEW  | Cola Zero   | 150         | 350          |
+
+  Scenario: Map a class to a SKU
+    When I map the SKU "CLASS-COLA" to class 4
+    Then the response status should be 201
+    And the response field "class_id" should be "4"
+    And the response field "sku_code" should be "CLASS-COLA"
+    When I send a GET request to "/api/v1/admin/ml/classes/4"
+    Then the response status should be 200
+    And the response field "sku_code" should be "CLASS-COLA"
+
+  Scenario: A class without an ID gets the one after the highest in use
+    Given the SKU "CLASS-COLA" is mapped to class 4
+    When I map the SKU "CLASS-TEA" to the next free class
+    Then the response status should be 201
+    And the response field "class_id" should be "5"
+
+  Scenario: The mapping is listed by class ID
+    Given the SKU "CLASS-TEA" is mapped to class 9
+    And the SKU "CLASS-COLA" is mapped to class 2
+    When I send a GET request to "/api/v1/admin/ml/classes"
+    Then the response status should be 200
+    And the response field "count" should be "2"
+    And the response field "classes.0.sku_code" should be "CLASS-COLA"
+    And the response field "classes.1.sku_code" should be "CLASS-TEA"
+
+  Scenario: A class ID names one SKU
+    Given the SKU "CLASS-COLA" is mapped to class 4
+    When I map the SKU "CLASS-TEA" to class 4
+    Then the response status should be 409
+    And the response should contain error "class ID"
+
+  Scenario: A SKU has one class
+    Given the SKU "CLASS-COLA" is mapped to class 4
+    When I map the SKU "CLASS-COLA" to class 5
+    Then the response status should be 409
+    And the response should contain error "another class"
+
+  Scenario: Class IDs stay within the model's range
+    When I map the SKU "CLASS-COLA" to class 10000
+    Then the response status should be 422
+
+  Scenario: Bundles have no class
+    Given a bundle "CLASS-DUO" priced at 300 cents exists with:
+      | sku        | quantity |
+      | CLASS-COLA | 1        |
+      | CLASS-TEA  | 1        |
+    When I map the SKU "CLASS-DUO" to the next free class
+    Then the response status should be 422
+    And the response should contain error "bundles"
+
+  Scenario: Point a class at a successor SKU
+    Given the SKU "CLASS-COLA" is mapped to class 4
+    When I point class 4 at the SKU "CLASS-NEW"
+    Then the response status should be 200
+    And the response field "sku_code" should be "CLASS-NEW"
+
+  Scenario: Remove a class
+    Given the SKU "CLASS-COLA" is mapped to class 4
+    When I send a DELETE request to "/api/v1/admin/ml/classes/4"
+    Then the response status should be 204
+    When I send a GET request to "/api/v1/admin/ml/classes/4"
+    Then the response status should be 404
+
+  Scenario: Devices sync the class of each SKU
+    Given the SKU "CLASS-TEA" is mapped to class 1
+    And the SKU "CLASS-COLA" is mapped to class 3
+    When I send a GET request to "/api/v1/device/skus"
+    Then the response status should be 200
+    And the response field "skus.0.code" should be "CLASS-TEA"
+    And the response field "skus.0.class_id" should be "1"
+    And the response field "skus.1.code" should be "CLASS-COLA"
+    And the response field "skus.1.class_id" should be "3"
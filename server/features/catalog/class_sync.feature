@@ -0,0 +1,16 @@
+@api @catalog
+Feature: ML Class Mapping Sync
+  As an ML engineer
+  I want the ML server to learn which class is which SKU from the catalog
+  So that detections always name the SKUs on sale
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A manual sync needs a configured ML server
+    Given the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | SYNC-COLA | Cola 330ml | 150         | 350          |
+    When I trigger a class mapping sync
+    Then the response status should be 503
@@ -0,0 +1,73 @@
+@api @catalog
+Feature: SKU Import and Export
+  As a catalog manager
+  I want to download the catalog and upload a CSV of SKUs
+  So that I can edit the assortment offline instead of product by product
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Import SKUs from a CSV file
+    When I import SKUs from the CSV:
+      """
+      code,name,price_cents,weight_grams,currency,weight_tolerance
+      IMP-PEAR,Conference Pear,220,170,,
+      IMP-PLUM,Red Plum,90,60,EUR,3
+      """
+    Then the response status should be 201
+    And the response field "imported" should be "2"
+    And the response field "skus.0.line" should be "2"
+    And the response field "skus.1.code" should be "IMP-PLUM"
+    When I send a GET request to "/api/v1/skus"
+    Then the response status should be 200
+    And the response should contain field "skus"
+
+  Scenario: One invalid row rejects the whole file
+    Given a SKU exists with code "IMP-TAKEN"
+    When I import SKUs from the CSV:
+      """
+      code,name,price_cents,weight_grams
+      IMP-KIWI,Kiwi,120,80
+      IMP-FIG,Fig,abc,50
+      IMP-TAKEN,Taken Twice,100,100
+      IMP-KIWI,Kiwi Again,120,80
+      """
+    Then the response status should be 422
+    And the response should contain error "3 of 4 rows are invalid"
+    And the response field "errors.0.line" should be "3"
+    And the response field "errors.0.error" should be "SKU price must be positive"
+    And the response field "errors.1.error" should be "SKU code already exists"
+    And the response field "errors.2.line" should be "5"
+    And no SKU with code "IMP-KIWI" should exist
+
+  Scenario: A file without the required columns is refused
+    When I import SKUs from the CSV:
+      """
+      code,name,price_cents
+      IMP-LIME,Lime,80
+      """
+    Then the response status should be 400
+    And the response should contain error "missing column"
+
+  Scenario: Export the catalog as CSV
+    Given the following SKUs exist:
+      | code     | name          | price_cents | weight_grams | weight_tolerance |
+      | EXP-DATE | Medjool Dates | 450         | 200          | 8                |
+    When I send a GET request to "/api/v1/skus/export?format=csv"
+    Then the response status should be 200
+    And the response header "Content-Type" should be "text/csv; charset=utf-8"
+    And the export should contain the SKU "EXP-DATE"
+
+  Scenario: Export the catalog as JSON
+    Given the following SKUs exist:
+      | code     | name       | price_cents | weight_grams |
+      | EXP-NUTS | Mixed Nuts | 390         | 150          |
+    When I send a GET request to "/api/v1/skus/export?format=json"
+    Then the response status should be 200
+    And the export should contain the SKU "EXP-NUTS"
+
+  Scenario: Unknown export formats are refused
+    When I send a GET request to "/api/v1/skus/export?format=xml"
+    Then the response status should be 400
+    And the response should contain error "format must be csv or json"
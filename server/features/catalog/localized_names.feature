@@ -0,0 +1,58 @@
+@api @catalog
+Feature: Localized SKU Names
+  As an operator running machines in several countries
+  I want SKU names and descriptions translated
+  So that customers read them in their own language
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Translate a SKU
+    When I create the SKU "LOC-COLA" with the translations:
+      | language | name         | description                 |
+      | de       | Cola Dose    | Erfrischungsgetränk, 330 ml |
+      | it-ch    | Cola lattina |                             |
+    Then the response status should be 201
+    When I fetch the SKU "LOC-COLA"
+    Then the response status should be 200
+    And the response field "translations.de.name" should be "Cola Dose"
+    And the response field "translations.it-CH.name" should be "Cola lattina"
+
+  Scenario: Translations need a language tag and a name
+    When I create the SKU "LOC-BAD" with the translations:
+      | language | name | description |
+      | deutsch  | Cola |             |
+    Then the response status should be 422
+
+  Scenario: Devices get SKU names in their language
+    Given I create the SKU "LOC-COLA" with the translations:
+      | language | name      | description                 |
+      | de       | Cola Dose | Erfrischungsgetränk, 330 ml |
+    When the device syncs its SKUs in "de-CH, en;q=0.5"
+    Then the response status should be 200
+    And the response header "Vary" should be "Accept-Language"
+    And the response field "skus.0.name" should be "Cola Dose"
+    And the response field "skus.0.description" should be "Erfrischungsgetränk, 330 ml"
+
+  Scenario: SKUs without a translation keep their own name
+    Given I create the SKU "LOC-COLA" with the translations:
+      | language | name      | description |
+      | de       | Cola Dose |             |
+    When the device syncs its SKUs in "fr"
+    Then the response status should be 200
+    And the response field "skus.0.name" should be "Product LOC-COLA"
+
+  Scenario: Session items are named in the customer's language
+    Given a device exists with machine ID "DEVICE-LOC"
+    And I create the SKU "APPLE-001" with the translations:
+      | language | name       | description |
+      | de       | Fuji-Apfel |             |
+    And I create the SKU "APPLE-002" with the translations:
+      | language | name       | description |
+      | de       | Gala-Apfel |             |
+    And an active session with items exists on device "DEVICE-LOC"
+    When I fetch the current session in "de"
+    Then the response status should be 200
+    And the response field "items.0.name" should be "Fuji-Apfel"
+    And the response field "items.1.name" should be "Gala-Apfel"
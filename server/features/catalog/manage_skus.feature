@@ -43,6 +43,45 @@ Feature: Manage SKUs
     Then the response status should be 200
     And the response should contain 2 SKUs
 
+  Scenario: Deactivate and reactivate a SKU
+    Given a SKU exists with code "APPLE-001"
+    When I send a POST request to "/api/v1/skus/{sku_id}/deactivate"
+    Then the response status should be 200
+    And the response field "active" should be "false"
+    When I send a GET request to "/api/v1/skus/{sku_id}"
+    Then the response field "active" should be "false"
+    When I send a POST request to "/api/v1/skus/{sku_id}/activate"
+    Then the response status should be 200
+    And the response field "active" should be "true"
+
+  Scenario: Deleted SKUs are hidden from listings until restored
+    Given the following SKUs exist:
+      | code      | name        | price_cents | weight_grams |
+      | APPLE-001 | Fuji Apple  | 250         | 150          |
+      | APPLE-002 | Gala Apple  | 230         | 140          |
+      | APPLE-003 | Pink Lady   | 280         | 160          |
+    When I delete the SKU "APPLE-002"
+    Then the response status should be 200
+    And the response should contain field "deleted_at"
+    When I send a GET request to "/api/v1/skus/active"
+    Then the response should contain 2 SKUs
+    When I send a GET request to "/api/v1/skus"
+    Then the response should contain 2 SKUs
+    When I activate the SKU "APPLE-002"
+    Then the response status should be 409
+    And the response should contain error "SKU is deleted"
+    When I restore the SKU "APPLE-002"
+    Then the response status should be 200
+    And the response should not contain field "deleted_at"
+    When I send a GET request to "/api/v1/skus/active"
+    Then the response should contain 3 SKUs
+
+  @error-handling
+  Scenario: Activating an unknown SKU fails
+    When I send a POST request to "/api/v1/skus/00000000-0000-0000-0000-000000000000/activate"
+    Then the response status should be 404
+    And the response should contain error "SKU not found"
+
   @error-handling
   Scenario: Reject duplicate SKU code
     Given a SKU exists with code "APPLE-001"
@@ -0,0 +1,44 @@
+@api @catalog
+Feature: SKU Price History
+  As a finance auditor
+  I want every price change of a SKU to be recorded
+  So that I can reconstruct what a product cost at any point in time
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Price changes are recorded with who made them
+    Given the following SKUs exist:
+      | code       | name           | price_cents | weight_grams |
+      | HIST-MANGO | Alphonso Mango | 400         | 300          |
+    When "finance-anna" changes the price of the SKU "HIST-MANGO" to 450
+    Then the response status should be 200
+    And the response field "price_cents" should be "450"
+    When "finance-ben" changes the price of the SKU "HIST-MANGO" to 380
+    Then the response status should be 200
+    When I request the price history of the SKU "HIST-MANGO"
+    Then the response status should be 200
+    And the response field "count" should be "2"
+    And the response field "changes.0.old_price_cents" should be "400"
+    And the response field "changes.0.new_price_cents" should be "450"
+    And the response field "changes.0.changed_by" should be "finance-anna"
+    And the response field "changes.1.new_price_cents" should be "380"
+    And the response field "changes.1.changed_by" should be "finance-ben"
+
+  Scenario: Updates that keep the price leave the history alone
+    Given the following SKUs exist:
+      | code        | name   | price_cents | weight_grams |
+      | HIST-LYCHEE | Lychee | 350         | 120          |
+    When "finance-anna" changes the price of the SKU "HIST-LYCHEE" to 350
+    Then the response status should be 200
+    When I request the price history of the SKU "HIST-LYCHEE"
+    Then the response field "count" should be "0"
+
+  Scenario: Price changes need to name the staff member
+    Given the following SKUs exist:
+      | code       | name  | price_cents | weight_grams |
+      | HIST-GUAVA | Guava | 280         | 180          |
+    When the price of the SKU "HIST-GUAVA" is changed to 300 anonymously
+    Then the response status should be 401
+    And the response should contain error "staff member making the change is required"
@@ -0,0 +1,48 @@
+@api @catalog
+Feature: Nutrition and Allergen Information
+  As a shopper
+  I want to see the nutrition facts and allergens of the products in my cart
+  So that I know what I am buying before I pay
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  @smoke
+  Scenario: Create a SKU with nutrition facts and allergens
+    When I create the SKU "INFO-BAR-01" with the product info:
+      """
+      {
+        "nutrition": {
+          "serving_size_grams": 40,
+          "energy_kcal": 480,
+          "fat_grams": 24,
+          "saturated_fat_grams": 9,
+          "carbohydrate_grams": 58,
+          "sugars_grams": 31,
+          "protein_grams": 7,
+          "salt_grams": 0.3
+        },
+        "allergens": ["nuts", "milk", "milk"]
+      }
+      """
+    Then the response status should be 201
+    When I fetch the SKU "INFO-BAR-01"
+    Then the response field "product_info.nutrition.energy_kcal" should be "480"
+    And the response field "product_info.nutrition.sugars_grams" should be "31"
+    And the response field "product_info.allergens.0" should be "milk"
+    And the response field "product_info.allergens.1" should be "nuts"
+
+  Scenario: Only allergens that food labels declare are accepted
+    When I create the SKU "INFO-BAR-02" with the product info:
+      """
+      {"allergens": ["caffeine"]}
+      """
+    Then the response status should be 422
+
+  Scenario: Sugars cannot exceed carbohydrate
+    When I create the SKU "INFO-BAR-03" with the product info:
+      """
+      {"nutrition": {"energy_kcal": 200, "carbohydrate_grams": 10, "sugars_grams": 12}, "allergens": []}
+      """
+    Then the response status should be 422
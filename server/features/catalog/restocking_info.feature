@@ -0,0 +1,42 @@
+@api @catalog
+Feature: Supplier and Restocking Information
+  As an operator
+  I want to record who supplies each SKU, how it is packed and when a device runs low on it
+  So that restocking can be planned per device
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Create a SKU with its supplier, case size and reorder point
+    When I create the SKU "RESTOCK-01" with the restocking info:
+      """
+      {"supplier_ref": "ACME-4711", "case_size": 24, "reorder_point": 6}
+      """
+    Then the response status should be 201
+    When I fetch the SKU "RESTOCK-01"
+    Then the response field "restocking.supplier_ref" should be "ACME-4711"
+    And the response field "restocking.case_size" should be "24"
+    And the response field "restocking.reorder_point" should be "6"
+
+  Scenario: Edit the restocking info of a SKU
+    Given I create the SKU "RESTOCK-02" with the restocking info:
+      """
+      {"supplier_ref": "ACME-0815", "case_size": 12, "reorder_point": 4}
+      """
+    When staff "staff-ana" changes the restocking info of the SKU "RESTOCK-02" to:
+      """
+      {"supplier_ref": "FRESHCO-22", "case_size": 6, "reorder_point": 2}
+      """
+    Then the response status should be 200
+    And the response field "restocking.supplier_ref" should be "FRESHCO-22"
+    And the response field "restocking.case_size" should be "6"
+    And the response field "restocking.reorder_point" should be "2"
+
+  Scenario: Case size and reorder point cannot be negative
+    When I create the SKU "RESTOCK-03" with the restocking info:
+      """
+      {"supplier_ref": "ACME-1", "case_size": -6}
+      """
+    Then the response status should be 422
+    And the response should contain error "case size"
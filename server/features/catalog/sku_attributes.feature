@@ -0,0 +1,60 @@
+@api @catalog
+Feature: SKU Attributes
+  As a catalog manager
+  I want to keep brand, size, flavor and allergens on each SKU
+  So that storefronts and apps can show and filter them without another product database
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  @smoke
+  Scenario: Create a SKU with attributes
+    When I create the SKU "ATTR-COLA-1" with the attributes:
+      """
+      {"brand": "Fizz", "size": "330ml", "flavor": "cherry", "allergens": ["caffeine"]}
+      """
+    Then the response status should be 201
+    When I fetch the SKU "ATTR-COLA-1"
+    Then the response field "attributes.brand" should be "Fizz"
+    And the response field "attributes.allergens.0" should be "caffeine"
+
+  Scenario: Filter SKUs by attribute
+    Given I create the SKU "ATTR-NUTS-1" with the attributes:
+      """
+      {"brand": "Crunch", "allergens": ["peanuts", "soy"]}
+      """
+    And I create the SKU "ATTR-BAR-01" with the attributes:
+      """
+      {"brand": "Crunch", "allergens": ["milk"]}
+      """
+    When I list the SKUs with attribute "allergens" set to "peanuts"
+    Then the response status should be 200
+    And the response should contain 1 SKUs
+    And the response field "skus.0.code" should be "ATTR-NUTS-1"
+    When I list the active SKUs with attribute "brand" set to "crunch"
+    Then the response should contain 2 SKUs
+
+  Scenario: Changing the price keeps the attributes
+    Given I create the SKU "ATTR-TEA-01" with the attributes:
+      """
+      {"brand": "Leaf", "flavor": "peach"}
+      """
+    When "manager-1" changes the price of the SKU "ATTR-TEA-01" to 180
+    Then the response status should be 200
+    And the response field "attributes.flavor" should be "peach"
+
+  @error-handling
+  Scenario Outline: Reject invalid attributes
+    When I create the SKU "<code>" with the attributes:
+      """
+      <attributes>
+      """
+    Then the response status should be 422
+    And the response should contain error "invalid SKU attributes"
+
+    Examples:
+      | code        | attributes                   |
+      | ATTR-BAD-01 | {"Brand": "Fizz"}            |
+      | ATTR-BAD-02 | {"allergens": [1, 2]}        |
+      | ATTR-BAD-03 | {"nutrition": {"kcal": 140}} |
@@ -0,0 +1,65 @@
+@api @catalog
+Feature: SKU Lifecycle
+  As a catalog manager
+  I want to prepare new products as drafts before they go on sale
+  So that they can be set up and trained for detection without being sold
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: New SKUs are published unless created as drafts
+    Given the following SKUs exist:
+      | code       | name         | price_cents | weight_grams | lifecycle |
+      | LIFE-COLA  | Cola         | 180         | 350          |           |
+      | LIFE-KOMBU | Kombucha     | 320         | 330          | draft     |
+    When I fetch the SKU "LIFE-COLA"
+    Then the response field "lifecycle" should be "published"
+    And the response field "active" should be "true"
+    When I fetch the SKU "LIFE-KOMBU"
+    Then the response field "lifecycle" should be "draft"
+    And the response field "active" should be "false"
+    When I send a GET request to "/api/v1/skus/active"
+    Then the response should contain 1 SKUs
+    When I send a GET request to "/api/v1/skus?lifecycle=draft"
+    Then the response should contain 1 SKUs
+
+  Scenario: A draft is published, retired and published again
+    Given the following SKUs exist:
+      | code     | name        | price_cents | weight_grams | lifecycle |
+      | LIFE-BAR | Protein Bar | 250         | 60           | draft     |
+    When I publish the SKU "LIFE-BAR"
+    Then the response status should be 200
+    And the response field "lifecycle" should be "published"
+    When I retire the SKU "LIFE-BAR"
+    Then the response status should be 200
+    And the response field "lifecycle" should be "retired"
+    And the response field "active" should be "false"
+    When I publish the SKU "LIFE-BAR"
+    Then the response status should be 200
+    And the response field "lifecycle" should be "published"
+
+  Scenario: Drafts cannot be retired
+    Given the following SKUs exist:
+      | code       | name        | price_cents | weight_grams | lifecycle |
+      | LIFE-SALAD | Green Salad | 450         | 250          | draft     |
+    When I retire the SKU "LIFE-SALAD"
+    Then the response status should be 409
+    And the response should contain error "SKU cannot move to that lifecycle state"
+
+  Scenario: Drafts are not sold
+    Given the following SKUs exist:
+      | code      | name      | price_cents | weight_grams | lifecycle |
+      | LIFE-TEA  | Iced Tea  | 200         | 330          | draft     |
+    When I create a SKU with the following details:
+      | code      | name     | price_cents | weight_grams |
+      | LIFE-SODA | Soda     | 150         | 330          |
+    And I send a GET request to "/api/v1/skus/active"
+    Then the response should contain 1 SKUs
+
+  @error-handling
+  Scenario: SKUs cannot be created as retired
+    When I create a SKU with the following details:
+      | code     | name        | price_cents | weight_grams | lifecycle |
+      | LIFE-OLD | Old Product | 100         | 50           | retired   |
+    Then the response status should be 422
@@ -0,0 +1,33 @@
+@api @catalog
+Feature: Sorted SKU Listings
+  As a catalog manager
+  I want the server to sort SKU listings by price, name or creation time
+  So that the admin UI does not have to download the whole catalog to sort it
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | SORT-WATER | Still Water  | 100         | 500          |
+      | SORT-CHIPS | Potato Chips | 250         | 150          |
+      | SORT-COLA  | Cola         | 180         | 350          |
+
+  @smoke
+  Scenario: Sort by price, most expensive first
+    When I list the SKUs sorted by "price" in "desc" order
+    Then the response status should be 200
+    And the SKUs should be listed in the order "SORT-CHIPS, SORT-COLA, SORT-WATER"
+
+  Scenario: Sort by name
+    When I list the active SKUs sorted by "name" in "asc" order
+    Then the response status should be 200
+    And the SKUs should be listed in the order "SORT-COLA, SORT-CHIPS, SORT-WATER"
+
+  Scenario: Sort by creation time, newest first
+    When I list the SKUs sorted by "created_at" in "desc" order
+    Then the SKUs should be listed in the order "SORT-COLA, SORT-CHIPS, SORT-WATER"
+
+  Scenario: Unknown sort fields are rejected
+    When I list the SKUs sorted by "weight" in "asc" order
+    Then the response status should be 400
@@ -0,0 +1,54 @@
+@api @catalog
+Feature: SKU Training Images
+  As an ML engineer
+  I want labeled training images kept with each SKU
+  So that I can export training datasets straight from the catalog
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And the following SKUs exist:
+      | code       | name       | price_cents | weight_grams |
+      | TRAIN-COLA | Cola 330ml | 150         | 350          |
+      | TRAIN-CHIP | Chips      | 200         | 120          |
+
+  @smoke
+  Scenario: Attach a training image to a SKU
+    When I upload the training image "cola-front.png" of the SKU "TRAIN-COLA" with the bounding box "0.1,0.2,0.5,0.6"
+    Then the response status should be 201
+    And the response field "content_type" should be "image/png"
+    And the response field "bounding_box.width" should be "0.5"
+    When I list the training images of the SKU "TRAIN-COLA"
+    Then the response status should be 200
+    And the response field "count" should be "1"
+    When I fetch the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    Then the response status should be 200
+    And the response header "Content-Type" should be "image/png"
+
+  Scenario: Only images are accepted
+    When I upload a text file as training image of the SKU "TRAIN-COLA"
+    Then the response status should be 422
+
+  Scenario: The bounding box must lie within the image
+    When I upload the training image "cola-side.png" of the SKU "TRAIN-COLA" with the bounding box "0.6,0.2,0.5,0.6"
+    Then the response status should be 422
+
+  Scenario: The same image is only attached once
+    Given I upload the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    When I upload the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    Then the response status should be 409
+
+  Scenario: Export the training dataset labeled with SKU codes
+    Given I upload the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    And I upload the training image "cola-back.png" of the SKU "TRAIN-COLA"
+    And I upload the training image "chips-front.png" of the SKU "TRAIN-CHIP"
+    When I export the training dataset
+    Then the response status should be 200
+    And the response field "count" should be "3"
+
+  Scenario: Remove a training image
+    Given I upload the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    When I delete the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    Then the response status should be 204
+    When I fetch the training image "cola-front.png" of the SKU "TRAIN-COLA"
+    Then the response status should be 404
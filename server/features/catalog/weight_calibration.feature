@@ -0,0 +1,58 @@
+@api @catalog
+Feature: SKU Weight Calibration
+  As a catalog manager
+  I want to record weighed samples of each SKU
+  So that basket weights are checked against what products really weigh
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Weighed samples give the SKU a mean and spread
+    Given the following SKUs exist:
+      | code      | name | price_cents | weight_grams |
+      | CAL-COLA  | Cola | 180         | 350          |
+    When I record the weight samples "330, 332" for the SKU "CAL-COLA"
+    Then the response status should be 200
+    And the response field "weight_stats.samples" should be "2"
+    And the response field "weight_stats.calibrated" should be "false"
+    When I record the weight samples "331" for the SKU "CAL-COLA"
+    Then the response status should be 200
+    And the response field "weight_stats.samples" should be "3"
+    And the response field "weight_stats.mean_grams" should be "331"
+    And the response field "weight_stats.stddev_grams" should be "1"
+    And the response field "weight_stats.calibrated" should be "true"
+
+  Scenario: Weight samples must be positive
+    Given the following SKUs exist:
+      | code     | name  | price_cents | weight_grams |
+      | CAL-TEA  | Tea   | 200         | 330          |
+    When I record the weight samples "330, -1" for the SKU "CAL-TEA"
+    Then the response status should be 422
+
+  Scenario: Calibrated SKUs are checked against their measured weight
+    Given the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | CAL-JUICE  | Orange Juice | 250         | 350          |
+    And I record the weight samples "330, 331, 332" for the SKU "CAL-JUICE"
+    And a device exists with machine ID "CAL-001"
+    When I start a session on device "CAL-001"
+    And I submit the following detections weighing 331.5 grams to the session:
+      | sku       | confidence |
+      | CAL-JUICE | 0.95       |
+    Then the response status should be 200
+    And the response field "weight_match" should be "true"
+
+  Scenario: A calibrated SKU's tight spread catches smaller weight gaps
+    Given the following SKUs exist:
+      | code      | name        | price_cents | weight_grams |
+      | CAL-WATER | Still Water | 120         | 500          |
+    And I record the weight samples "500, 501, 499" for the SKU "CAL-WATER"
+    And a device exists with machine ID "CAL-002"
+    When I start a session on device "CAL-002"
+    And I submit the following detections weighing 508 grams to the session:
+      | sku       | confidence |
+      | CAL-WATER | 0.95       |
+    Then the response status should be 200
+    And the response field "weight_match" should be "false"
+    And the response field "needs_cloud_ml" should be "true"
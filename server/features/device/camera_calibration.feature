@@ -0,0 +1,63 @@
+@api @device
+Feature: Camera Calibration
+  As a field technician
+  I want a device's camera calibration kept on the server
+  So that a reimaged device restores it on boot instead of being recalibrated
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device restores its calibration on boot
+    Given a device exists with machine ID "CALIB-001"
+    When device "CALIB-001" uploads a camera calibration with homography "1.02,0.01,-4.5,0.0,0.98,2.25,0,0,1" and markers:
+      | id   | x      | y     |
+      | TL-1 | 112.5  | 80.0  |
+      | BR-4 | 1804.0 | 996.5 |
+    Then the response status should be 200
+    And the response field "version" should be "1"
+    When device "CALIB-001" pulls its camera calibration
+    Then the response status should be 200
+    And the response field "machine_id" should be "CALIB-001"
+    And the response field "homography.0" should be "1.02"
+    And the response field "homography.5" should be "2.25"
+    And the response field "markers.1.id" should be "BR-4"
+    And the response field "markers.1.y" should be "996.5"
+
+  Scenario: A new calibration replaces the previous one
+    Given a device exists with machine ID "CALIB-002"
+    And device "CALIB-002" uploads a camera calibration with homography "1,0,0,0,1,0,0,0,1" captured at "1280x720"
+    When device "CALIB-002" uploads a camera calibration with homography "2,0,0,0,2,0,0,0,1" captured at "1920x1080"
+    Then the response status should be 200
+    And the response field "version" should be "2"
+    When I request the camera calibration of device "CALIB-002"
+    Then the response status should be 200
+    And the response field "homography.0" should be "2"
+    And the response field "capture.resolution" should be "1920x1080"
+
+  Scenario: A device never calibrated has no calibration to restore
+    Given a device exists with machine ID "CALIB-003"
+    When device "CALIB-003" pulls its camera calibration
+    Then the response status should be 404
+    And the response should contain error "camera calibration not found"
+
+  Scenario: Only the device itself can upload or pull its calibration
+    Given a device exists with machine ID "CALIB-004"
+    When I send a GET request to "/api/v1/device/calibration?machine_id=CALIB-004"
+    Then the response status should be 401
+
+  Scenario: Degenerate calibrations are rejected
+    Given a device exists with machine ID "CALIB-005"
+    When device "CALIB-005" uploads a camera calibration with homography "1,0,0,0,1,0" captured at "1920x1080"
+    Then the response status should be 422
+    And the response should contain error "homography needs 9 finite values forming an invertible matrix"
+    When device "CALIB-005" uploads a camera calibration with homography "1,2,3,2,4,6,0,0,1" captured at "1920x1080"
+    Then the response status should be 422
+    When device "CALIB-005" uploads a camera calibration with homography "1,0,0,0,1,0,0,0,1" captured at "wide"
+    Then the response status should be 422
+    When device "CALIB-005" uploads a camera calibration with homography "1,0,0,0,1,0,0,0,1" and markers:
+      | id   | x  | y  |
+      | TL-1 | 10 | 10 |
+      | TL-1 | 20 | 20 |
+    Then the response status should be 422
+    And the response should contain error "reference marker needs a unique ID and finite coordinates"
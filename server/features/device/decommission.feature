@@ -0,0 +1,60 @@
+Feature: Device Decommissioning
+  As an operator
+  I want to take a device out of service for good
+  So that it stops selling and its machine ID cannot be reused by accident
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Decommissioning cancels the open session and archives the telemetry history
+    Given an active session exists on device "DECOM-001"
+    And device "DECOM-001" reports a cabinet temperature of 12.5 degrees 45 minutes ago
+    And device "DECOM-001" reports a cabinet temperature of 11 degrees 0 minutes ago
+    When operator "ops-1" decommissions device "DECOM-001"
+    Then the response status should be 200
+    And the response field "status" should be "decommissioned"
+    And the response field "archived_excursions" should be "1"
+    And the current session should become "cancelled"
+
+  Scenario: A decommissioned device can no longer sell or report
+    Given a device exists with machine ID "DECOM-002"
+    When operator "ops-1" decommissions device "DECOM-002"
+    Then the response status should be 200
+    When device "DECOM-002" requests a start token
+    Then the response status should be 401
+    And the response should contain error "invalid device credentials"
+    When device "DECOM-002" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    Then the response status should be 401
+    When I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response status should be 200
+    And the response field "status" should be "decommissioned"
+    And the response field "decommissioned_by" should be "ops-1"
+    And the response field "stale" should be "false"
+
+  Scenario: A device cannot be decommissioned twice or anonymously
+    Given a device exists with machine ID "DECOM-003"
+    When operator "" decommissions device "DECOM-003"
+    Then the response status should be 401
+    When operator "ops-1" decommissions device "DECOM-003"
+    Then the response status should be 200
+    When operator "ops-1" decommissions device "DECOM-003"
+    Then the response status should be 409
+    And the response should contain error "device is decommissioned"
+
+  Scenario: Enrolling a decommissioned machine ID again needs an operator override
+    Given a device exists with machine ID "DECOM-004"
+    And operator "ops-1" decommissions device "DECOM-004"
+    When I register a device with the following details:
+      | machine_id | name       | location |
+      | DECOM-004  | New Fridge | Lobby    |
+    Then the response status should be 409
+    And the response should contain error "an operator override is required"
+    When operator "ops-2" re-enrolls device "DECOM-004" with an override
+    Then the response status should be 201
+    And the response should contain field "message" with value "device recommissioned"
+    And the response should contain field "device_key"
+    When device "DECOM-004" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    Then the response status should be 200
+    When device "DECOM-004" requests a start token
+    Then the response status should be 200
@@ -0,0 +1,69 @@
+@api @device
+Feature: Device Commands
+  As an operator
+  I want to send commands to a machine in the field
+  So that I can reboot it or refresh it without sending a technician
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device picks up a queued command and reports back
+    Given a device exists with machine ID "CMD-001"
+    When operator "ops-1" sends a "reboot" command to device "CMD-001"
+    Then the response status should be 201
+    And the response field "status" should be "pending"
+    And the response field "issued_by" should be "ops-1"
+    When device "CMD-001" polls for commands
+    Then the response status should be 200
+    And the commands should be "reboot:delivered"
+    When device "CMD-001" acknowledges the "reboot" command as "succeeded"
+    Then the response status should be 200
+    And the response field "status" should be "succeeded"
+    When device "CMD-001" polls for commands
+    Then the response status should be 200
+    And the commands should be ""
+
+  Scenario: An unacknowledged command is delivered again
+    Given a device exists with machine ID "CMD-002"
+    And operator "ops-1" sends a "resync_catalog" command to device "CMD-002"
+    And device "CMD-002" polls for commands
+    When device "CMD-002" polls for commands
+    Then the commands should be "resync_catalog:delivered"
+
+  Scenario: A command is acknowledged only once
+    Given a device exists with machine ID "CMD-003"
+    And operator "ops-1" sends a "capture_test_image" command to device "CMD-003"
+    And device "CMD-003" acknowledges the "capture_test_image" command as "failed"
+    When device "CMD-003" acknowledges the "capture_test_image" command as "succeeded"
+    Then the response status should be 409
+    And the response should contain error "command already acknowledged"
+
+  Scenario: The command history shows how each command went
+    Given a device exists with machine ID "CMD-004"
+    And operator "ops-1" sends a "reboot" command to device "CMD-004"
+    And operator "ops-1" sends a "resync_catalog" command to device "CMD-004"
+    And device "CMD-004" acknowledges the "reboot" command as "succeeded"
+    When I request the commands of device "CMD-004"
+    Then the response status should be 200
+    And the commands should be "resync_catalog:pending,reboot:succeeded"
+
+  Scenario: A connected device receives commands over the command socket
+    Given a device exists with machine ID "CMD-005"
+    When operator "ops-1" sends a "reboot" command to device "CMD-005"
+    Then device "CMD-005" receives the "reboot" command on the command socket
+
+  Scenario: Unknown command kinds are rejected
+    Given a device exists with machine ID "CMD-006"
+    When operator "ops-1" sends a "self_destruct" command to device "CMD-006"
+    Then the response status should be 422
+
+  Scenario: Commands need an operator
+    Given a device exists with machine ID "CMD-007"
+    When operator "" sends a "reboot" command to device "CMD-007"
+    Then the response status should be 401
+
+  Scenario: Polling with the wrong key is rejected
+    Given a device exists with machine ID "CMD-008"
+    When device "CMD-008" polls for commands with the key "not-the-key"
+    Then the response status should be 401
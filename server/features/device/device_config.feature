@@ -0,0 +1,82 @@
+Feature: Device Configuration Shadow
+  As an operator
+  I want to set the configuration a device should run and see what it applied
+  So that devices running other settings than intended stand out
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device pulls its configuration and reports it applied
+    Given a device exists with machine ID "CONFIG-001"
+    When operator "ops-1" sets the following configuration for device "CONFIG-001":
+      | confidence_threshold | sync_interval_seconds | resolution | frame_rate |
+      | 0.85                 | 300                   | 1920x1080  | 15         |
+    Then the response status should be 200
+    And the response field "version" should be "1"
+    And the response field "status" should be "pending"
+    When device "CONFIG-001" pulls its configuration
+    Then the response status should be 200
+    And the response field "version" should be "1"
+    And the response field "config.sync_interval_seconds" should be "300"
+    And the response field "config.camera.resolution" should be "1920x1080"
+    When device "CONFIG-001" reports applying configuration version 1 with:
+      | confidence_threshold | sync_interval_seconds | resolution | frame_rate |
+      | 0.85                 | 300                   | 1920x1080  | 15         |
+    Then the response status should be 200
+    And the response field "status" should be "in_sync"
+    And the response field "reported_version" should be "1"
+
+  Scenario: Settings the device did not apply show as drift
+    Given a device exists with machine ID "CONFIG-002"
+    And operator "ops-1" sets the following configuration for device "CONFIG-002":
+      | sync_interval_seconds | frame_rate |
+      | 60                    | 30         |
+    And device "CONFIG-002" reports applying configuration version 1 with:
+      | sync_interval_seconds | frame_rate |
+      | 60                    | 15         |
+    When I request the configuration of device "CONFIG-002"
+    Then the response status should be 200
+    And the response field "status" should be "drifted"
+    And the response field "drift.0.setting" should be "camera.frame_rate"
+    And the response field "drift.0.desired" should be "30"
+    And the response field "drift.0.reported" should be "15"
+
+  Scenario: The configuration follows the group's detection threshold unless it sets one
+    Given a device exists with machine ID "CONFIG-003"
+    And I create the device group "Config Fleet"
+    And I add the devices "CONFIG-003" to the group "Config Fleet"
+    And I set the following policy for the group "Config Fleet":
+      | confidence_threshold |
+      | 0.75                 |
+    When device "CONFIG-003" pulls its configuration
+    Then the response status should be 200
+    And the response field "version" should be "0"
+    And the response field "config.confidence_threshold" should be "0.75"
+
+  Scenario: Only the device itself can pull or report its configuration
+    Given a device exists with machine ID "CONFIG-004"
+    And a device exists with machine ID "CONFIG-005"
+    When I send a GET request to "/api/v1/device/config?machine_id=CONFIG-004"
+    Then the response status should be 401
+    When device "CONFIG-005" reports applying configuration version 3 with:
+      | sync_interval_seconds |
+      | 60                    |
+    Then the response status should be 422
+    And the response should contain error "reported configuration version was never desired"
+
+  Scenario: Configurations out of bounds or without an operator are rejected
+    Given a device exists with machine ID "CONFIG-006"
+    When operator "ops-1" sets the following configuration for device "CONFIG-006":
+      | sync_interval_seconds |
+      | 5                     |
+    Then the response status should be 422
+    And the response should contain error "sync interval must be between 10 seconds and 24 hours"
+    When operator "ops-1" sets the following configuration for device "CONFIG-006":
+      | resolution |
+      | huge       |
+    Then the response status should be 422
+    When operator "" sets the following configuration for device "CONFIG-006":
+      | sync_interval_seconds |
+      | 60                    |
+    Then the response status should be 401
@@ -0,0 +1,62 @@
+@api @device
+Feature: Remote Device Diagnostics
+  As an operator
+  I want a machine in the field to run its self-test on request
+  So that I can troubleshoot its camera, scale and model remotely
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device runs the diagnostics it is asked for and reports back
+    Given a device exists with machine ID "DIAG-001"
+    When operator "ops-1" requests diagnostics of device "DIAG-001"
+    Then the response status should be 202
+    And the response field "status" should be "pending"
+    And the response should not contain field "report"
+    When device "DIAG-001" polls for commands
+    Then the commands should be "run_diagnostics:delivered"
+    When device "DIAG-001" uploads its diagnostics:
+      | camera_ok     | true                   |
+      | scale_ok      | false                  |
+      | model_loaded  | true                   |
+      | model_version | shelf-v3               |
+      | notes         | load cell 2 not found  |
+    Then the response status should be 200
+    And the response field "status" should be "succeeded"
+    When I request the diagnostics of device "DIAG-001"
+    Then the response status should be 200
+    And the response field "report.passed" should be "false"
+    And the response field "report.camera_ok" should be "true"
+    And the response field "report.scale_ok" should be "false"
+    And the response field "report.model_version" should be "shelf-v3"
+    And the response field "report.notes" should be "load cell 2 not found"
+    When I request the commands of device "DIAG-001"
+    Then the response field "0.result" should be "camera ok, scale failed, model loaded"
+
+  Scenario: The report is pending until the device uploads it
+    Given a device exists with machine ID "DIAG-002"
+    And operator "ops-1" requests diagnostics of device "DIAG-002"
+    When I request the diagnostics of device "DIAG-002"
+    Then the response status should be 200
+    And the response field "status" should be "pending"
+    And the response should not contain field "report"
+
+  Scenario: A diagnostics run is reported only once
+    Given a device exists with machine ID "DIAG-003"
+    And operator "ops-1" requests diagnostics of device "DIAG-003"
+    And device "DIAG-003" uploads its diagnostics:
+      | camera_ok    | true |
+      | scale_ok     | true |
+      | model_loaded | true |
+    When device "DIAG-003" uploads its diagnostics:
+      | camera_ok    | true |
+      | scale_ok     | true |
+      | model_loaded | true |
+    Then the response status should be 409
+    And the response should contain error "command already acknowledged"
+
+  Scenario: Diagnostics need an operator
+    Given a device exists with machine ID "DIAG-004"
+    When operator "" requests diagnostics of device "DIAG-004"
+    Then the response status should be 401
@@ -0,0 +1,71 @@
+@api @device
+Feature: Device Event Log
+  As a loss-prevention analyst
+  I want every door, tamper and power event a machine reports kept in order
+  So that fraud rules and investigations can replay what happened at a machine
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Uploaded events are listed newest first
+    Given a device exists with machine ID "EVLOG-001"
+    When device "EVLOG-001" uploads the following events:
+      | kind           | minutes_ago | detail          |
+      | power_lost     | 30          | mains dropped   |
+      | power_restored | 20          |                 |
+      | door_opened    | 10          |                 |
+      | door_closed    | 9           |                 |
+    Then the response status should be 200
+    And the response field "received" should be "4"
+    When I request the event log of device "EVLOG-001" from 60 to 0 minutes ago
+    Then the response status should be 200
+    And the device event log should be "door_closed,door_opened,power_restored,power_lost"
+
+  Scenario: The event log is filtered by when the events occurred
+    Given a device exists with machine ID "EVLOG-002"
+    And device "EVLOG-002" uploads the following events:
+      | kind        | minutes_ago |
+      | tamper      | 50          |
+      | door_opened | 40          |
+      | door_closed | 35          |
+      | tamper      | 5           |
+    When I request the event log of device "EVLOG-002" from 45 to 30 minutes ago
+    Then the response status should be 200
+    And the device event log should be "door_closed,door_opened"
+
+  Scenario: Re-uploading a batch after a power loss logs nothing twice
+    Given a device exists with machine ID "EVLOG-003"
+    And device "EVLOG-003" uploads the following events:
+      | kind        | minutes_ago |
+      | door_opened | 12          |
+      | door_closed | 11          |
+    When device "EVLOG-003" uploads the following events:
+      | kind        | minutes_ago |
+      | door_opened | 12          |
+      | door_closed | 11          |
+      | tamper      | 3           |
+    Then the response status should be 200
+    And the response field "received" should be "1"
+    And the response field "duplicates" should be "2"
+    When I request the event log of device "EVLOG-003" from 60 to 0 minutes ago
+    Then the device event log should be "tamper,door_closed,door_opened"
+
+  Scenario: Unknown event kinds are rejected
+    Given a device exists with machine ID "EVLOG-004"
+    When device "EVLOG-004" uploads the following events:
+      | kind        | minutes_ago |
+      | lid_lifted  | 1           |
+    Then the response status should be 422
+
+  Scenario: Only the device itself can upload its events
+    Given a device exists with machine ID "EVLOG-005"
+    When device "EVLOG-005" uploads events with the key "not-the-key"
+    Then the response status should be 401
+
+  Scenario: Invalid filters are rejected
+    Given a device exists with machine ID "EVLOG-006"
+    When I send a GET request to "/api/v1/device/events?machine_id=EVLOG-006&from=yesterday"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/device/events?machine_id=EVLOG-006&limit=5000"
+    Then the response status should be 400
@@ -0,0 +1,98 @@
+Feature: Device Groups
+  As an operator
+  I want to configure devices by site or fleet instead of one by one
+  So that a policy change reaches every device of the group at once
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Devices in a group follow the group's policy
+    Given a device exists with machine ID "GROUP-001"
+    And a device exists with machine ID "GROUP-002"
+    And I create the device group "Airport Terminal 1"
+    When I add the devices "GROUP-001,GROUP-002" to the group "Airport Terminal 1"
+    Then the response status should be 200
+    And the response field "device_count" should be "2"
+    When I set the following policy for the group "Airport Terminal 1":
+      | confidence_threshold | model_version |
+      | 0.9                  | shelf-v2      |
+    Then the response status should be 200
+    When I request the policy of device "GROUP-002"
+    Then the response status should be 200
+    And the response field "confidence_threshold" should be "0.9"
+    And the response field "confidence_threshold_source" should be "group"
+    And the response field "model_version" should be "shelf-v2"
+    And the response field "model_version_source" should be "group"
+
+  Scenario: A device override wins over the group's policy
+    Given a device exists with machine ID "GROUP-003"
+    And I create the device group "Airport Terminal 2"
+    And I add the devices "GROUP-003" to the group "Airport Terminal 2"
+    And I set the following policy for the group "Airport Terminal 2":
+      | confidence_threshold | model_version |
+      | 0.9                  | shelf-v2      |
+    When I set the following policy overrides for device "GROUP-003":
+      | confidence_threshold |
+      | 0.95                 |
+    Then the response status should be 200
+    And the response field "confidence_threshold" should be "0.95"
+    And the response field "confidence_threshold_source" should be "device"
+    And the response field "model_version" should be "shelf-v2"
+    And the response field "model_version_source" should be "group"
+
+  Scenario: A device without its own planogram is stocked with the group's
+    Given a device exists with machine ID "GROUP-004"
+    And I create the device group "Office Park"
+    And I add the devices "GROUP-004" to the group "Office Park"
+    When I set the following planogram for the group "Office Park":
+      | shelf | sku_code  | facings |
+      | 1     | COKE-330  | 4       |
+      | 2     | WATER-500 | 3       |
+    Then the response status should be 200
+    And the response field "planogram_version" should be "1"
+    When I send a GET request to "/api/v1/device/planogram?machine_id=GROUP-004"
+    Then the response status should be 200
+    And the response field "source" should be "group"
+    And the response field "facings.1.sku_code" should be "WATER-500"
+    When I assign the following planogram to device "GROUP-004":
+      | shelf | sku_code | facings |
+      | 1     | COKE-330 | 6       |
+    Then the response status should be 200
+    When I send a GET request to "/api/v1/device/planogram?machine_id=GROUP-004"
+    Then the response field "source" should be "device"
+    And the response field "facings.0.facings" should be "6"
+
+  Scenario: A device taken out of its group falls back to the defaults
+    Given a device exists with machine ID "GROUP-005"
+    And I create the device group "Campus"
+    And I add the devices "GROUP-005" to the group "Campus"
+    And I set the following policy for the group "Campus":
+      | confidence_threshold |
+      | 0.7                  |
+    When I remove the device "GROUP-005" from the group "Campus"
+    Then the response status should be 200
+    And the response field "device_count" should be "0"
+    When I request the policy of device "GROUP-005"
+    Then the response field "confidence_threshold_source" should be "default"
+    When I remove the device "GROUP-005" from the group "Campus"
+    Then the response status should be 409
+    And the response should contain error "device is not in the group"
+
+  Scenario: An unknown machine ID adds none of the devices
+    Given a device exists with machine ID "GROUP-006"
+    And I create the device group "Hospital"
+    When I add the devices "GROUP-006,GROUP-UNKNOWN" to the group "Hospital"
+    Then the response status should be 404
+    And the response should contain error "GROUP-UNKNOWN"
+    When I request the device group "Hospital"
+    Then the response status should be 200
+    And the response field "device_count" should be "0"
+
+  Scenario: Policies outside their bounds are rejected
+    Given I create the device group "Stadium"
+    When I set the following policy for the group "Stadium":
+      | confidence_threshold |
+      | 1.5                  |
+    Then the response status should be 422
+    And the response should contain error "confidence threshold must be between 0 and 1"
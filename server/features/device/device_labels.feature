@@ -0,0 +1,40 @@
+@api @device
+Feature: Device Labels
+  As a field technician
+  I want to print a device's QR code straight from the admin UI
+  So that every machine carries a label linking to it
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device's QR code is rendered as a PNG
+    Given a device exists with machine ID "LABEL-001"
+    When I request the QR code of device "LABEL-001"
+    Then the response status should be 200
+    And the response header "Content-Type" should be "image/png"
+    And the response should be a PNG image at most 256 pixels wide
+    And the QR code should hold the deep link of device "LABEL-001"
+
+  Scenario: The label can be rendered larger for print
+    Given a device exists with machine ID "LABEL-002"
+    When I request the QR code of device "LABEL-002" at 600 pixels
+    Then the response status should be 200
+    And the response should be a PNG image at most 600 pixels wide
+    And the QR code should hold the deep link of device "LABEL-002"
+
+  Scenario: The label size is bounded
+    Given a device exists with machine ID "LABEL-003"
+    When I request the QR code of device "LABEL-003" at 5000 pixels
+    Then the response status should be 400
+    And the response should contain error "size must be between 64 and 1024"
+
+  Scenario: A decommissioned device gets no label
+    Given a device exists with machine ID "LABEL-004"
+    And operator "ops@example.com" decommissions device "LABEL-004"
+    When I request the QR code of device "LABEL-004"
+    Then the response status should be 409
+
+  Scenario: An unknown device has no label
+    When I send a GET request to "/api/v1/devices/00000000-0000-0000-0000-000000000000/qrcode.png"
+    Then the response status should be 404
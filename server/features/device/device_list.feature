@@ -0,0 +1,61 @@
+@api @device
+Feature: Device List
+  As an operator watching the fleet on the dashboard
+  I want to list devices by status, location and when they were last seen
+  So that I can find the machines that need attention
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And I register a device with the following details:
+      | machine_id | name      | location              |
+      | LIST-001   | Lobby     | Harbour Tower Lobby   |
+    And I register a device with the following details:
+      | machine_id | name      | location              |
+      | LIST-002   | Canteen   | Harbour Tower Canteen |
+    And I register a device with the following details:
+      | machine_id | name      | location              |
+      | LIST-003   | Gym       | Harbour Tower Gym     |
+
+  Scenario: Devices are filtered by part of their location
+    When I send a GET request to "/api/v1/devices?location=harbour%20tower"
+    Then the response status should be 200
+    And the response field "total" should be "3"
+    And the response field "devices.0.machine_id" should be "LIST-001"
+    And the response field "devices.2.machine_id" should be "LIST-003"
+
+  Scenario: Devices are paged by machine ID
+    When I send a GET request to "/api/v1/devices?location=harbour%20tower&limit=2&offset=1"
+    Then the response status should be 200
+    And the response field "total" should be "3"
+    And the response field "limit" should be "2"
+    And the response field "offset" should be "1"
+    And the response field "devices.0.machine_id" should be "LIST-002"
+    And the response field "devices.1.machine_id" should be "LIST-003"
+
+  Scenario: Devices are filtered by status
+    When device "LIST-002" reports a cabinet temperature of 12.5 degrees 45 minutes ago
+    And device "LIST-002" reports a cabinet temperature of 11 degrees 0 minutes ago
+    And I send a GET request to "/api/v1/devices?location=harbour%20tower&status=blocked"
+    Then the response status should be 200
+    And the response field "total" should be "1"
+    And the response field "devices.0.machine_id" should be "LIST-002"
+    And the response field "devices.0.status" should be "blocked"
+
+  Scenario: Devices are filtered by when they were last seen
+    When device "LIST-003" sends a heartbeat with firmware "1.0.0" and app "1.0.0"
+    And I send a GET request to "/api/v1/devices?location=harbour%20tower&seen_after=2020-01-01T00:00:00Z"
+    Then the response field "total" should be "1"
+    And the response field "devices.0.machine_id" should be "LIST-003"
+    When I send a GET request to "/api/v1/devices?location=harbour%20tower&seen_before=2020-01-01T00:00:00Z"
+    Then the response field "total" should be "2"
+    When I send a GET request to "/api/v1/devices?location=harbour%20tower&stale=false"
+    Then the response field "total" should be "1"
+
+  Scenario: Invalid filters are rejected
+    When I send a GET request to "/api/v1/devices?status=broken"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/devices?limit=500"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/devices?seen_after=yesterday"
+    Then the response status should be 400
@@ -18,13 +18,14 @@ Feature: Device Management
     And the response should contain field "machine_id" with value "DEVICE-001"
     And the response should contain field "message" with value "device registered"
 
-  Scenario: Register device with same machine ID returns existing device
+  Scenario: Enrolling an enrolled machine ID again gives it a new key
     Given a device exists with machine ID "DEVICE-001"
     When I register a device with the following details:
       | machine_id | name              | location        |
       | DEVICE-001 | Vending Machine 1 | Building A      |
     Then the response status should be 200
-    And the response should contain field "message" with value "device already registered"
+    And the response should contain field "message" with value "device re-enrolled"
+    And the response should contain field "device_key"
 
   Scenario: Device can retrieve active SKUs for ML model
     Given the following SKUs exist:
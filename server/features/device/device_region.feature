@@ -0,0 +1,22 @@
+@api @device
+Feature: Device Regions
+  As an operator bound by data residency rules
+  I want each device served from the region it stands in
+  So that its customers' data stays in that region
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "REGION-001"
+
+  Scenario: Devices are tagged with the region that serves them
+    When I register a device with the following details:
+      | machine_id | name              | location        |
+      | REGION-001 | Vending Machine 1 | Berlin          |
+    Then the response should contain field "region" with value "eu"
+
+  Scenario: Requests for another region get a routing hint
+    When I send a GET request to "/api/v1/device/stock?machine_id=REGION-001" tagged for region "us"
+    Then the response status should be 421
+    And the response should contain field "region" with value "us"
+    And the response should contain field "endpoint" with value "https://us.api.example.com"
@@ -0,0 +1,36 @@
+@api @device
+Feature: Device Start Tokens
+  As a device
+  I want a signed, short-lived session start token for the QR code I show
+  So that customers can only start sessions on a machine they stand in front of
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "TOKEN-001"
+
+  Scenario: Device can fetch a signed session start token for its QR code
+    When device "TOKEN-001" requests a start token
+    Then the response status should be 200
+    And the response should contain field "machine_id" with value "TOKEN-001"
+    And the response should contain field "token"
+    And the response should contain field "expires_at"
+
+  @error-handling
+  Scenario: A device cannot fetch start tokens for another machine
+    Given a device exists with machine ID "TOKEN-002"
+    When device "TOKEN-001" requests a start token with the key of device "TOKEN-002"
+    Then the response status should be 401
+    And the response should contain error "invalid device credentials"
+
+  @error-handling
+  Scenario: Start tokens need the device key
+    When I send a GET request to "/api/v1/device/start-token?machine_id=TOKEN-001"
+    Then the response status should be 401
+    And the response should contain error "invalid device credentials"
+
+  @error-handling
+  Scenario: Unknown machines get no start token
+    When device "TOKEN-404" requests a start token with the key of device "TOKEN-001"
+    Then the response status should be 401
+    And the response should contain error "invalid device credentials"
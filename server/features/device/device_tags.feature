@@ -0,0 +1,67 @@
+@api @device
+Feature: Device Tags and Metadata
+  As an operator running a fleet across many venues
+  I want to tag devices and attach free-form metadata to them
+  So that I can slice the fleet by venue type, customer or anything else
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And I register a device with the following details:
+      | machine_id | name     | location           |
+      | TAGS-001   | Lobby    | Riverside Lobby    |
+    And I register a device with the following details:
+      | machine_id | name     | location           |
+      | TAGS-002   | Canteen  | Riverside Canteen  |
+    And I register a device with the following details:
+      | machine_id | name     | location           |
+      | TAGS-003   | Gym      | Riverside Gym      |
+
+  Scenario: Tags are normalized and shown with the device
+    When I label device "TAGS-001" with tags "Pilot, office,pilot" and metadata:
+      | venue_type | office    |
+      | customer   | Acme Corp |
+    Then the response status should be 200
+    And the response field "tags.0" should be "office"
+    And the response field "tags.1" should be "pilot"
+    And the response field "metadata.customer" should be "Acme Corp"
+    When I send a GET request to "/api/v1/devices?location=riverside"
+    Then the response field "devices.0.tags.1" should be "pilot"
+    And the response field "devices.0.metadata.venue_type" should be "office"
+
+  Scenario: Devices are filtered by tag
+    Given I label device "TAGS-001" with tags "pilot,office"
+    And I label device "TAGS-002" with tags "office"
+    And I label device "TAGS-003" with tags "pilot,gym"
+    When I send a GET request to "/api/v1/devices?tag=pilot"
+    Then the response status should be 200
+    And the response field "total" should be "2"
+    And the response field "devices.0.machine_id" should be "TAGS-001"
+    And the response field "devices.1.machine_id" should be "TAGS-003"
+    When I send a GET request to "/api/v1/devices?tag=PILOT&tag=office"
+    Then the response field "total" should be "1"
+    And the response field "devices.0.machine_id" should be "TAGS-001"
+
+  Scenario: Devices are filtered by metadata
+    Given I label device "TAGS-001" with tags "" and metadata:
+      | venue_type | office |
+    And I label device "TAGS-002" with tags "" and metadata:
+      | venue_type | hospital |
+    When I send a GET request to "/api/v1/devices?metadata%5Bvenue_type%5D=hospital"
+    Then the response status should be 200
+    And the response field "total" should be "1"
+    And the response field "devices.0.machine_id" should be "TAGS-002"
+
+  Scenario: Setting labels replaces the previous ones
+    Given I label device "TAGS-001" with tags "pilot" and metadata:
+      | venue_type | office |
+    When I label device "TAGS-001" with tags "retired"
+    Then the response status should be 200
+    And the response field "tags.0" should be "retired"
+    When I send a GET request to "/api/v1/devices?tag=pilot"
+    Then the response field "total" should be "0"
+
+  Scenario: Oversized labels are rejected
+    When I label device "TAGS-001" with tags "this-tag-is-far-too-long-to-fit-on-any-label-we-print"
+    Then the response status should be 422
+    And the response should contain error "a device takes up to 50 tags of up to 50 characters"
@@ -0,0 +1,30 @@
+@api @device
+Feature: Door Security Incidents
+  As an operator
+  I want door openings without a session raised as security incidents
+  So that tampering is noticed and the next sale is checked in the cloud
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "DOOR-001"
+
+  Scenario: Door opened without an active session raises an incident and flags the next session
+    Given an active session exists on device "DOOR-001"
+    And I cancel the session with reason "customer walked away"
+    When device "DOOR-001" reports the door closed
+    And device "DOOR-001" reports the door open
+    Then the response status should be 200
+    And the response should contain field "incident" with value "door_open_without_session"
+    When device "DOOR-001" reports the door closed
+    And I start a session on device "DOOR-001"
+    And I fetch the current session
+    Then the response status should be 200
+    And the response should contain field "cloud_verification_required" with value "true"
+
+  Scenario: Door opened during a session is not an incident
+    Given an active session exists on device "DOOR-001"
+    When device "DOOR-001" reports the door open
+    Then the response status should be 200
+    And the response should not contain field "incident"
+    And device "DOOR-001" reports the door closed
@@ -0,0 +1,45 @@
+@api @device
+Feature: Device Enrollment
+  As an operator
+  I want devices to enroll with one-time tokens I create for them
+  So that nobody can register a machine ID and act as that machine
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device enrolls with the token created for its machine ID
+    When operator "ops-1" creates an enrollment token for "ENROLL-001"
+    Then the response status should be 201
+    And the response should contain field "enrollment_token"
+    And the response should contain field "expires_at"
+    When device "ENROLL-001" enrolls with its token
+    Then the response status should be 201
+    And the response should contain field "message" with value "device registered"
+    And the response should contain field "device_key"
+    When device "ENROLL-001" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    Then the response status should be 200
+
+  Scenario: An enrollment token can only be used once
+    Given operator "ops-1" creates an enrollment token for "ENROLL-002"
+    And device "ENROLL-002" enrolls with its token
+    When device "ENROLL-002" enrolls with its token
+    Then the response status should be 401
+    And the response should contain error "enrollment token has already been used"
+
+  Scenario: An enrollment token only enrolls the machine ID it was created for
+    Given operator "ops-1" creates an enrollment token for "ENROLL-003"
+    When device "ENROLL-004" enrolls with the token for "ENROLL-003"
+    Then the response status should be 401
+    And the response should contain error "invalid enrollment token"
+    When device "ENROLL-003" enrolls with its token
+    Then the response status should be 201
+
+  Scenario: Only an identified operator can create enrollment tokens
+    When operator "" creates an enrollment token for "ENROLL-005"
+    Then the response status should be 401
+    And the response should contain error "enrolling operator is required"
+
+  Scenario: Devices can no longer register themselves
+    When I send a POST request to "/api/v1/device/register"
+    Then the response status should be 404
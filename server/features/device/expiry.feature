@@ -0,0 +1,118 @@
+@api @device
+Feature: Batch Expiry
+  As an operator
+  I want to track when each restocked batch expires
+  So that short-dated stock is marked down, expired stock is not sold and waste is reported
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Restocked batches show their expiry and markdown
+    Given a device exists with machine ID "EXPIRY-001"
+    When field staff "staff-ana" restocks device "EXPIRY-001" with the following batches:
+      | sku_code   | quantity | expires_in_days |
+      | YOGURT-E01 | 6        | 2               |
+      | SALAD-E01  | 4        | 10              |
+    Then the response status should be 201
+    When I send a GET request to "/api/v1/device/batches?machine_id=EXPIRY-001"
+    Then the response status should be 200
+    And the response field "0.sku_code" should be "SALAD-E01"
+    And the response field "0.markdown_percent" should be "0"
+    And the response field "1.sku_code" should be "YOGURT-E01"
+    And the response field "1.days_left" should be "2"
+    And the response field "1.markdown_percent" should be "25"
+    And the response field "1.remaining" should be "6"
+
+  Scenario: Items from a batch expiring today are sold at a markdown
+    Given the following SKUs exist:
+      | code       | name          | price_cents | weight_grams |
+      | YOGURT-E02 | Berry Yogurt  | 240         | 150          |
+      | SALAD-E02  | Caesar Salad  | 590         | 300          |
+    And a device exists with machine ID "EXPIRY-002"
+    And field staff "staff-ana" restocks device "EXPIRY-002" with the following batches:
+      | sku_code   | quantity | expires_in_days |
+      | YOGURT-E02 | 6        | 0               |
+      | SALAD-E02  | 4        | 10              |
+    When I start a session on device "EXPIRY-002"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | YOGURT-E02 | 0.95       |
+      | SALAD-E02  | 0.93       |
+    Then the response status should be 200
+    And the response field "items.0.markdown_percent" should be "50"
+    And the response field "items.0.price_cents" should be "120"
+    And the response field "items.1.price_cents" should be "590"
+
+  Scenario: Items from an expired batch cannot be sold
+    Given the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | APPLE-001 | Fuji Apple | 250         | 150          |
+      | APPLE-002 | Gala Apple | 230         | 140          |
+    And an active session with items exists on device "EXPIRY-003"
+    And field staff "staff-ana" restocks device "EXPIRY-003" with the following batches:
+      | sku_code  | quantity | expires_in_days |
+      | APPLE-002 | 5        | -1              |
+    When I confirm the session with payment reference "PAY-EXPIRY-3"
+    Then the response status should be 422
+    And the response should contain error "Gala Apple (batch expired on"
+    And the response field "code" should be "sale_restricted"
+
+  Scenario: Written-off batches appear in the waste report
+    Given a device exists with machine ID "EXPIRY-004"
+    And field staff "staff-ana" restocks device "EXPIRY-004" with the following batches:
+      | sku_code   | quantity | expires_in_days |
+      | SANDW-E04  | 3        | -1              |
+    When field staff "staff-ben" writes off the batch of "SANDW-E04" on device "EXPIRY-004"
+    Then the response status should be 200
+    And the response field "written_off_by" should be "staff-ben"
+    And the response field "wasted_units" should be "3"
+    When I send a GET request to "/api/v1/device/waste?machine_id=EXPIRY-004"
+    Then the response status should be 200
+    And the response field "total_units" should be "3"
+    And the response field "lines.0.sku_code" should be "SANDW-E04"
+
+  Scenario: A batch without an expiry date keeps for its SKU's shelf life
+    Given I create the SKU "MILK-E05" with a shelf life of 4 days
+    And a device exists with machine ID "EXPIRY-005"
+    When field staff "staff-ana" restocks device "EXPIRY-005" with the following batches:
+      | sku_code | quantity | expires_in_days |
+      | MILK-E05 | 8        |                 |
+    Then the response status should be 201
+    And the response field "0.days_left" should be "4"
+    When I fetch the SKU "MILK-E05"
+    Then the response field "shelf_life_days" should be "4"
+
+  Scenario: A batch of a SKU without a shelf life needs an expiry date
+    Given I create the SKU "SODA-E06" with a shelf life of 0 days
+    And a device exists with machine ID "EXPIRY-006"
+    When field staff "staff-ana" restocks device "EXPIRY-006" with the following batches:
+      | sku_code | quantity | expires_in_days |
+      | SODA-E06 | 12       |                 |
+    Then the response status should be 422
+    And the response should contain error "SODA-E06 has no shelf life"
+
+  Scenario: A shelf life must be a sensible number of days
+    When I create the SKU "BREAD-E07" with a shelf life of -2 days
+    Then the response status should be 422
+
+  Scenario: Operators list the items due to expire across the fleet
+    Given a device exists with machine ID "EXPIRY-008"
+    And a device exists with machine ID "EXPIRY-009"
+    And field staff "staff-ana" restocks device "EXPIRY-008" with the following batches:
+      | sku_code   | quantity | expires_in_days |
+      | WRAP-E08   | 5        | 1               |
+      | SALAD-E08  | 4        | 10              |
+    And field staff "staff-ana" restocks device "EXPIRY-009" with the following batches:
+      | sku_code   | quantity | expires_in_days |
+      | JUICE-E09  | 3        | -1              |
+    When I send a GET request to "/api/v1/device/batches/expiring?within_days=2"
+    Then the response status should be 200
+    And the response field "count" should be "2"
+    And the response field "units" should be "8"
+    And the response field "batches.0.machine_id" should be "EXPIRY-009"
+    And the response field "batches.0.expired" should be "true"
+    And the response field "batches.1.sku_code" should be "WRAP-E08"
+    And the response field "batches.1.markdown_percent" should be "25"
+    When I send a GET request to "/api/v1/device/batches/expiring?within_days=2&machine_id=EXPIRY-008"
+    Then the response field "count" should be "1"
@@ -0,0 +1,64 @@
+@api @device
+Feature: Fleet Health
+  As an operator
+  I want one summary of the whole fleet's health
+  So that the ops dashboard shows silent machines, outdated models and weighing trouble at a glance
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device without a heartbeat is offline
+    Given a device exists with machine ID "FLEET-001"
+    When I request the fleet health
+    Then the response status should be 200
+    And the response should contain field "devices_by_status"
+    And the response field "offline.after_minutes" should be "5"
+    And the fleet health should list device "FLEET-001" as offline
+
+  Scenario: A device heard from within the window is not offline
+    Given a device exists with machine ID "FLEET-002"
+    And device "FLEET-002" sends a heartbeat with firmware "1.4.2" and app "2.0.0"
+    When I request the fleet health counting devices offline after 10 minutes
+    Then the response status should be 200
+    And the response field "offline.after_minutes" should be "10"
+    And the fleet health should not list device "FLEET-002" as offline
+
+  Scenario: A device running another model than its group pins is stale
+    Given a device exists with machine ID "FLEET-003"
+    And a device exists with machine ID "FLEET-004"
+    And I create the device group "Fleet Health Site"
+    And I add the devices "FLEET-003,FLEET-004" to the group "Fleet Health Site"
+    And I set the following policy for the group "Fleet Health Site":
+      | model_version |
+      | shelf-v3      |
+    And device "FLEET-003" sends a heartbeat running model "shelf-v2"
+    And device "FLEET-004" sends a heartbeat running model "shelf-v3"
+    When I request the fleet health
+    Then the response status should be 200
+    And the fleet health should list device "FLEET-003" as running a stale model
+    And the fleet health should not list device "FLEET-004" as running a stale model
+
+  Scenario: A device without a pinned model is never stale
+    Given a device exists with machine ID "FLEET-005"
+    And device "FLEET-005" sends a heartbeat running model "shelf-v1"
+    When I request the fleet health
+    Then the fleet health should not list device "FLEET-005" as running a stale model
+
+  Scenario: Open sessions with a weight mismatch are counted
+    Given the following SKUs exist:
+      | code        | name        | price_cents | weight_grams | weight_tolerance |
+      | FLEET-WATER | Still Water | 120         | 500          | 10               |
+    And a device exists with machine ID "FLEET-006"
+    And I start a session on device "FLEET-006"
+    And I submit the following detections weighing 650 grams to the session:
+      | sku         | confidence |
+      | FLEET-WATER | 0.95       |
+    When I request the fleet health
+    Then the response status should be 200
+    And the fleet health should count at least 1 open session with a weight mismatch
+
+  Scenario: The offline window must be a positive number of minutes
+    When I request the fleet health counting devices offline after 0 minutes
+    Then the response status should be 400
+    And the response should contain error "offline_minutes must be a positive number"
@@ -0,0 +1,45 @@
+@api @device
+Feature: Device Heartbeat
+  As an operator
+  I want devices to report that they are alive and which versions they run
+  So that I can tell which machines have gone silent
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "HB-001"
+
+  Scenario: Registering a device issues its key
+    When I register a device with the following details:
+      | machine_id | name      | location |
+      | HB-NEW     | Lobby     | Floor 1  |
+    Then the response status should be 201
+    And the response should contain field "device_key"
+
+  Scenario: A device is stale until its first heartbeat
+    Then the device "HB-001" should be listed as stale
+
+  Scenario: A heartbeat records when the device was last seen and its versions
+    When device "HB-001" sends a heartbeat with firmware "1.4.2" and app "2.0.0"
+    Then the response status should be 200
+    And the response should contain field "last_seen_at"
+    And the device "HB-001" should be listed as alive
+    When I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response status should be 200
+    And the response field "firmware_version" should be "1.4.2"
+    And the response field "app_version" should be "2.0.0"
+    And the response field "stale" should be "false"
+
+  Scenario: A device cannot send heartbeats for another device
+    Given a device exists with machine ID "HB-002"
+    When device "HB-002" sends a heartbeat with the key of device "HB-001"
+    Then the response status should be 401
+    And the response should contain error "invalid device credentials"
+    And the device "HB-002" should be listed as stale
+
+  Scenario: A reissued key replaces the old one
+    When I send a POST request to "/api/v1/devices/{device_id}/key"
+    Then the response status should be 200
+    And the response should contain field "device_key"
+    When device "HB-001" sends a heartbeat with firmware "1.4.2" and app "2.0.0"
+    Then the response status should be 401
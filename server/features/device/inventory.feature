@@ -0,0 +1,89 @@
+@api @device
+Feature: Device Inventory
+  As an operator
+  I want to know how many units of each SKU are in every device
+  So that field staff can plan restocking runs
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Restocking adds units to the device inventory
+    Given the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | INV-COLA  | Cola         | 180         | 350          |
+      | INV-CHIPS | Salted Chips | 150         | 60           |
+    And a device exists with machine ID "INV-001"
+    When field staff "staff-ana" restocks the inventory of device "INV-001" with:
+      | sku_code  | quantity |
+      | INV-COLA  | 10       |
+      | INV-CHIPS | 5        |
+    Then the response status should be 200
+    And the response field "machine_id" should be "INV-001"
+    And the response field "levels.0.sku_code" should be "INV-CHIPS"
+    And the response field "levels.0.quantity" should be "5"
+    When field staff "staff-ana" restocks the inventory of device "INV-001" with:
+      | sku_code | quantity |
+      | INV-COLA | 2        |
+    Then the inventory of device "INV-001" should have 12 units of "INV-COLA"
+
+  Scenario: A completed session is taken out of the inventory
+    Given the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | INV-WATER | Still Water  | 120         | 500          |
+      | INV-BAR   | Granola Bar  | 200         | 45           |
+    And a device exists with machine ID "INV-002"
+    And field staff "staff-ana" restocks the inventory of device "INV-002" with:
+      | sku_code  | quantity |
+      | INV-WATER | 6        |
+      | INV-BAR   | 3        |
+    When I start a session on device "INV-002"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | INV-WATER | 0.95       |
+      | INV-WATER | 0.94       |
+      | INV-BAR   | 0.93       |
+    And I confirm the session with payment reference "PAY-INV-2"
+    Then the response status should be 200
+    And the inventory of device "INV-002" should have 4 units of "INV-WATER"
+    And the inventory of device "INV-002" should have 2 units of "INV-BAR"
+
+  Scenario: Only catalog SKUs can be restocked
+    Given a device exists with machine ID "INV-003"
+    When field staff "staff-ana" restocks the inventory of device "INV-003" with:
+      | sku_code    | quantity |
+      | INV-UNKNOWN | 4        |
+    Then the response status should be 422
+    And the response should contain error "SKU is not in the catalog"
+
+  Scenario: Restocking requires the field staff identity
+    Given a device exists with machine ID "INV-004"
+    When field staff "" restocks the inventory of device "INV-004" with:
+      | sku_code | quantity |
+      | INV-COLA | 4        |
+    Then the response status should be 401
+
+  Scenario: SKUs that fall below the threshold are listed for restocking
+    Given the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | LOW-JUICE | Orange Juice | 250         | 330          |
+      | LOW-NUTS  | Mixed Nuts   | 300         | 50           |
+    And a device exists with machine ID "INV-005"
+    And field staff "staff-ana" restocks the inventory of device "INV-005" with:
+      | sku_code  | quantity |
+      | LOW-JUICE | 4        |
+      | LOW-NUTS  | 8        |
+    When I start a session on device "INV-005"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | LOW-JUICE | 0.95       |
+      | LOW-JUICE | 0.94       |
+      | LOW-NUTS  | 0.93       |
+    And I confirm the session with payment reference "PAY-INV-5"
+    Then the inventory of device "INV-005" should have 2 units of "LOW-JUICE"
+    When I request the low inventory of device "INV-005"
+    Then the response status should be 200
+    And the response field "low_threshold" should be "3"
+    And the response field "levels.0.sku_code" should be "LOW-JUICE"
+    And the response field "levels.0.quantity" should be "2"
+    And the response field "levels.0.low" should be "true"
@@ -0,0 +1,74 @@
+@api @device
+Feature: Device Maintenance
+  As a field technician
+  I want to put a machine into maintenance while I work on it
+  So that nobody can shop at it and opening its door raises no alarm
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device under maintenance starts no sessions
+    Given a device exists with machine ID "MAINT-001"
+    When technician "tech-1" puts device "MAINT-001" into maintenance because "replacing the shelf camera"
+    Then the response status should be 200
+    And the response field "status" should be "maintenance"
+    And the response should contain field "maintenance_since"
+    When I start a session on device "MAINT-001"
+    Then the response status should be 422
+    And the response should contain error "device is under maintenance"
+    And the response field "code" should be "device_maintenance"
+    When device "MAINT-001" requests a start token
+    Then the response status should be 422
+    And the response field "code" should be "device_maintenance"
+
+  Scenario: The device shows who is working on it and why
+    Given a device exists with machine ID "MAINT-002"
+    And technician "tech-1" puts device "MAINT-002" into maintenance because "cleaning"
+    When I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response status should be 200
+    And the response field "status" should be "maintenance"
+    And the response field "maintenance_by" should be "tech-1"
+    And the response field "maintenance_reason" should be "cleaning"
+
+  Scenario: Leaving maintenance puts the device back into service
+    Given a device exists with machine ID "MAINT-003"
+    And technician "tech-1" puts device "MAINT-003" into maintenance because "cleaning"
+    When technician "tech-1" takes device "MAINT-003" out of maintenance
+    Then the response status should be 200
+    And the response field "status" should be "active"
+    When I start a session on device "MAINT-003"
+    Then the response status should be 201
+
+  Scenario: Opening the door during maintenance is not an incident
+    Given a device exists with machine ID "MAINT-004"
+    And technician "tech-1" puts device "MAINT-004" into maintenance because "restocking"
+    When device "MAINT-004" reports the door open
+    Then the response status should be 200
+    And the response should not contain field "incident"
+    And device "MAINT-004" reports the door closed
+
+  Scenario: Maintenance needs a technician and a device in service
+    Given a device exists with machine ID "MAINT-005"
+    When technician "" puts device "MAINT-005" into maintenance because "cleaning"
+    Then the response status should be 401
+    When technician "tech-1" takes device "MAINT-005" out of maintenance
+    Then the response status should be 409
+    And the response should contain error "device is not under maintenance"
+    When technician "tech-1" puts device "MAINT-005" into maintenance because "cleaning"
+    And technician "tech-1" puts device "MAINT-005" into maintenance because "cleaning"
+    Then the response status should be 409
+    And the response should contain error "device is under maintenance"
+
+  Scenario: Devices under maintenance are listed by their status
+    Given I register a device with the following details:
+      | machine_id | name    | location           |
+      | MAINT-006  | Atrium  | Maintenance Atrium |
+    And I register a device with the following details:
+      | machine_id | name    | location           |
+      | MAINT-007  | Atrium  | Maintenance Atrium |
+    And technician "tech-1" puts device "MAINT-006" into maintenance because "cleaning"
+    When I send a GET request to "/api/v1/devices?location=maintenance%20atrium&status=maintenance"
+    Then the response status should be 200
+    And the response field "total" should be "1"
+    And the response field "devices.0.machine_id" should be "MAINT-006"
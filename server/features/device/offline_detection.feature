@@ -0,0 +1,55 @@
+@api @device
+Feature: Offline Device Detection
+  As an operator
+  I want devices that stop sending heartbeats to be marked offline
+  So that I hear about dead machines and no shopper is stuck in their sessions
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A silent device is marked offline once
+    Given a device exists with machine ID "OFFLINE-001"
+    And device "OFFLINE-001" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    And 2 seconds pass
+    When the offline sweep runs with a window of 1 second
+    Then the response status should be 200
+    And the offline sweep should list device "OFFLINE-001"
+    When I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response should contain field "offline_since"
+    When the offline sweep runs with a window of 1 second
+    Then the offline sweep should not list device "OFFLINE-001"
+
+  Scenario: A heartbeat brings an offline device back
+    Given a device exists with machine ID "OFFLINE-002"
+    And device "OFFLINE-002" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    And 2 seconds pass
+    And the offline sweep runs with a window of 1 second
+    When device "OFFLINE-002" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    And I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response should not contain field "offline_since"
+
+  Scenario: A device heard from within the window stays online
+    Given a device exists with machine ID "OFFLINE-003"
+    And device "OFFLINE-003" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    When the offline sweep runs with a window of 600 seconds
+    Then the offline sweep should not list device "OFFLINE-003"
+
+  Scenario: A device that never sent a heartbeat is not marked offline
+    Given a device exists with machine ID "OFFLINE-004"
+    And 2 seconds pass
+    When the offline sweep runs with a window of 1 second
+    Then the offline sweep should not list device "OFFLINE-004"
+
+  Scenario: The session open on a device that went offline is cancelled
+    Given a device exists with machine ID "OFFLINE-005"
+    And device "OFFLINE-005" sends a heartbeat with firmware "1.0.0" and app "2.0.0"
+    And I start a session on device "OFFLINE-005"
+    And 2 seconds pass
+    When the offline sweep runs with a window of 1 second
+    Then the current session should become "cancelled"
+
+  Scenario: The offline window must be a positive number of seconds
+    When I send a POST request to "/api/v1/admin/fleet/offline-sweep?offline_seconds=0"
+    Then the response status should be 400
+    And the response should contain error "offline_seconds must be a positive number"
@@ -0,0 +1,36 @@
+@api @device
+Feature: Device Planograms
+  As a merchandiser
+  I want each device to have a planogram of what goes on which shelf
+  So that restock visits can be checked against it
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "PLANO-001"
+
+  Scenario: Assign a planogram to a device
+    When I assign the following planogram to device "PLANO-001":
+      | shelf | sku_code  | facings |
+      | 1     | COKE-330  | 4       |
+      | 2     | WATER-500 | 3       |
+    Then the response status should be 200
+    And the response should contain field "version"
+    When I send a GET request to "/api/v1/device/planogram?machine_id=PLANO-001"
+    Then the response status should be 200
+    And the response should contain field "facings"
+
+  Scenario: Reject a planogram facing without a positive count
+    When I assign the following planogram to device "PLANO-001":
+      | shelf | sku_code | facings |
+      | 1     | COKE-330 | 0       |
+    Then the response status should be 422
+    And the response should contain error "planogram facing needs a shelf, a SKU code and a positive count"
+
+  Scenario: Restock visit is rejected when cloud detection is not configured
+    Given I assign the following planogram to device "PLANO-001":
+      | shelf | sku_code | facings |
+      | 1     | COKE-330 | 4       |
+    When field staff "merch-7" submits a restock snapshot for device "PLANO-001"
+    Then the response status should be 503
+    And the response should contain error "shelf detection is unavailable"
@@ -0,0 +1,108 @@
+@api @device
+Feature: Restock Sessions
+  As an operator
+  I want to restock a machine in a session of its own
+  So that nobody shops at it while I refill it and I get a report of what I loaded
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device being restocked starts no customer sessions
+    Given a device exists with machine ID "RSS-001"
+    When operator "op-rita" starts restocking device "RSS-001"
+    Then the response status should be 201
+    And the response field "status" should be "open"
+    And the response field "started_by" should be "op-rita"
+    When I start a session on device "RSS-001"
+    Then the response status should be 422
+    And the response field "code" should be "device_restocking"
+    When device "RSS-001" requests a start token
+    Then the response status should be 422
+    And the response field "code" should be "device_restocking"
+    When I send a GET request to "/api/v1/devices/{device_id}"
+    Then the response should contain field "restock_session_id"
+
+  Scenario: Completing the session adds the units and reports them
+    Given the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | RSS-COLA  | Cola         | 180         | 350          |
+      | RSS-CHIPS | Salted Chips | 150         | 60           |
+    And a device exists with machine ID "RSS-002"
+    And field staff "staff-ana" restocks the inventory of device "RSS-002" with:
+      | sku_code | quantity |
+      | RSS-COLA | 2        |
+    And operator "op-rita" starts restocking device "RSS-002"
+    When the operator loads the following units in the restock session:
+      | sku_code  | quantity |
+      | RSS-COLA  | 6        |
+      | RSS-CHIPS | 4        |
+    And the operator loads the following units in the restock session:
+      | sku_code | quantity |
+      | RSS-COLA | 2        |
+    Then the response status should be 200
+    And the restock report should be "RSS-CHIPS:4:-,RSS-COLA:8:-"
+    And the inventory of device "RSS-002" should have 2 units of "RSS-COLA"
+    When operator "op-rita" completes the restock session
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+    And the response field "units_added" should be "12"
+    And the restock report should be "RSS-CHIPS:4:4,RSS-COLA:8:10"
+    And the inventory of device "RSS-002" should have 10 units of "RSS-COLA"
+    When I start a session on device "RSS-002"
+    Then the response status should be 201
+
+  Scenario: Cancelling the session leaves the inventory untouched
+    Given the following SKUs exist:
+      | code      | name        | price_cents | weight_grams |
+      | RSS-WATER | Still Water | 120         | 500          |
+    And a device exists with machine ID "RSS-003"
+    And operator "op-rita" starts restocking device "RSS-003"
+    And the operator loads the following units in the restock session:
+      | sku_code  | quantity |
+      | RSS-WATER | 5        |
+    When operator "op-rita" cancels the restock session
+    Then the response status should be 200
+    And the response field "status" should be "cancelled"
+    And the inventory of device "RSS-003" should have 0 units of "RSS-WATER"
+    When I request the restock report
+    Then the restock report should be "RSS-WATER:5:-"
+    When I start a session on device "RSS-003"
+    Then the response status should be 201
+
+  Scenario: Opening the door while restocking is not an incident
+    Given a device exists with machine ID "RSS-004"
+    And operator "op-rita" starts restocking device "RSS-004"
+    When device "RSS-004" reports the door open
+    Then the response status should be 200
+    And the response should not contain field "incident"
+    And device "RSS-004" reports the door closed
+
+  Scenario: A device is restocked by one operator at a time, between customers
+    Given a device exists with machine ID "RSS-005"
+    And an active session exists on device "RSS-005"
+    When operator "op-rita" starts restocking device "RSS-005"
+    Then the response status should be 409
+    And the response should contain error "a customer session is open on the device"
+    Given a device exists with machine ID "RSS-006"
+    And operator "op-rita" starts restocking device "RSS-006"
+    When operator "op-sam" starts restocking device "RSS-006"
+    Then the response status should be 409
+    And the response should contain error "device is being restocked"
+
+  Scenario: Restock sessions need an operator and catalog SKUs
+    Given a device exists with machine ID "RSS-007"
+    When operator "" starts restocking device "RSS-007"
+    Then the response status should be 401
+    Given operator "op-rita" starts restocking device "RSS-007"
+    When the operator loads the following units in the restock session:
+      | sku_code    | quantity |
+      | RSS-UNKNOWN | 3        |
+    Then the response status should be 422
+    And the response should contain error "SKU is not in the catalog"
+    When operator "" completes the restock session
+    Then the response status should be 401
+    When operator "op-rita" completes the restock session
+    And operator "op-rita" completes the restock session
+    Then the response status should be 409
+    And the response should contain error "restock session is already closed"
@@ -0,0 +1,119 @@
+@api @device
+Feature: Firmware and App Rollouts
+  As an operator
+  I want to upload releases and roll them out to device groups in stages
+  So that a bad build reaches few machines before I can stop it
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Uploading a release registers it with its checksum
+    When operator "ops-1" uploads the firmware release "9.0.0-upload"
+    Then the response status should be 201
+    And the response field "kind" should be "firmware"
+    And the response field "version" should be "9.0.0-upload"
+    And the response field "uploaded_by" should be "ops-1"
+    And the response should contain field "checksum"
+    When operator "ops-1" uploads the firmware release "9.0.0-upload"
+    Then the response status should be 409
+    And the response should contain error "a release of this kind and version already exists"
+
+  Scenario: A device of a targeted group is offered the release and reports installing it
+    Given a device exists with machine ID "OTA-001"
+    And I create the device group "OTA Fleet 1"
+    And I add the devices "OTA-001" to the group "OTA Fleet 1"
+    And operator "ops-1" uploads the firmware release "9.1.0"
+    When operator "ops-1" rolls out the release "9.1.0" to the groups "OTA Fleet 1"
+    Then the response status should be 201
+    And the response field "status" should be "completed"
+    And the response field "percent" should be "100"
+    When device "OTA-001" sends a heartbeat with firmware "9.0.0" and app "1.0.0"
+    Then the response status should be 200
+    And the heartbeat should offer 1 update
+    And the response field "updates.0.version" should be "9.1.0"
+    And the response field "updates.0.kind" should be "firmware"
+    When device "OTA-001" downloads the release "9.1.0"
+    Then the response status should be 200
+    When device "OTA-001" reports the update of release "9.1.0" as "installed"
+    Then the response status should be 200
+    And the heartbeat should offer 0 updates
+    When I request the rollout of release "9.1.0"
+    Then the response status should be 200
+    And the response field "targeted" should be "1"
+    And the response field "progress.installed" should be "1"
+    And the response field "devices.0.machine_id" should be "OTA-001"
+    And the response field "devices.0.status" should be "installed"
+
+  Scenario: A staged rollout advances to every device
+    Given a device exists with machine ID "OTA-002"
+    And I create the device group "OTA Fleet 2"
+    And I add the devices "OTA-002" to the group "OTA Fleet 2"
+    And operator "ops-1" uploads the app release "4.2.0"
+    When operator "ops-1" rolls out the release "4.2.0" to the groups "OTA Fleet 2" in stages "1,100"
+    Then the response status should be 201
+    And the response field "status" should be "active"
+    And the response field "percent" should be "1"
+    When operator "ops-1" advances the rollout of release "4.2.0"
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+    And the response field "percent" should be "100"
+    When device "OTA-002" sends a heartbeat with firmware "9.0.0" and app "4.1.0"
+    Then the heartbeat should offer 1 update
+    And the response field "updates.0.version" should be "4.2.0"
+    When operator "ops-1" advances the rollout of release "4.2.0"
+    Then the response status should be 409
+    And the response should contain error "only a staging rollout can advance"
+
+  Scenario: An aborted rollout is no longer offered
+    Given a device exists with machine ID "OTA-003"
+    And I create the device group "OTA Fleet 3"
+    And I add the devices "OTA-003" to the group "OTA Fleet 3"
+    And operator "ops-1" uploads the firmware release "9.3.0"
+    And operator "ops-1" rolls out the release "9.3.0" to the groups "OTA Fleet 3"
+    And device "OTA-003" reports the update of release "9.3.0" as "downloading"
+    When operator "ops-2" aborts the rollout of release "9.3.0" because "boot loop on rev B boards"
+    Then the response status should be 200
+    And the response field "status" should be "aborted"
+    And the response field "aborted_by" should be "ops-2"
+    And the response field "devices.0.status" should be "downloading"
+    When device "OTA-003" sends a heartbeat with firmware "9.0.0" and app "1.0.0"
+    Then the heartbeat should offer 0 updates
+
+  Scenario: A failed install is not offered again
+    Given a device exists with machine ID "OTA-004"
+    And I create the device group "OTA Fleet 4"
+    And I add the devices "OTA-004" to the group "OTA Fleet 4"
+    And operator "ops-1" uploads the firmware release "9.4.0"
+    And operator "ops-1" rolls out the release "9.4.0" to the groups "OTA Fleet 4"
+    When device "OTA-004" reports the update of release "9.4.0" as "rebooting"
+    Then the response status should be 422
+    And the response should contain error "update status must be downloading, downloaded, installing, installed or failed"
+    When device "OTA-004" reports the update of release "9.4.0" as "failed"
+    Then the response status should be 200
+    And the heartbeat should offer 0 updates
+    When I request the rollout of release "9.4.0"
+    Then the response field "progress.failed" should be "1"
+
+  Scenario: A group takes one staging rollout of each kind at a time
+    Given I create the device group "OTA Fleet 5"
+    And operator "ops-1" uploads the firmware release "9.5.0"
+    And operator "ops-1" uploads the firmware release "9.5.1"
+    And operator "ops-1" rolls out the release "9.5.0" to the groups "OTA Fleet 5" in stages "10,50,100"
+    When operator "ops-1" rolls out the release "9.5.1" to the groups "OTA Fleet 5"
+    Then the response status should be 409
+    When operator "ops-1" aborts the rollout of release "9.5.0" because "superseded"
+    And operator "ops-1" rolls out the release "9.5.1" to the groups "OTA Fleet 5"
+    Then the response status should be 201
+
+  Scenario: Rollout stages must end at every device
+    Given I create the device group "OTA Fleet 6"
+    And operator "ops-1" uploads the firmware release "9.6.0"
+    When operator "ops-1" rolls out the release "9.6.0" to the groups "OTA Fleet 6" in stages "10,50"
+    Then the response status should be 422
+    And the response should contain error "rollout stages must be increasing percentages ending at 100"
+
+  Scenario: Unknown rollouts cannot be advanced
+    When I send a POST request to "/api/v1/rollouts/00000000-0000-0000-0000-000000000000/advance"
+    Then the response status should be 404
+    And the response should contain error "rollout not found"
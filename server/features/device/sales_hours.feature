@@ -0,0 +1,67 @@
+@api @device
+Feature: Sales Hours
+  As an operator
+  I want to set opening hours and sales blackouts per device
+  So that machines only sell when and what they are allowed to
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device without sales hours is always open
+    Given a device exists with machine ID "HOURS-001"
+    When I send a GET request to "/api/v1/device/details?machine_id=HOURS-001"
+    Then the response status should be 200
+    And the response field "machine_id" should be "HOURS-001"
+    And the response field "sales_hours.configured" should be "false"
+    And the response field "sales_hours.open_now" should be "true"
+
+  Scenario: Device detail shows the configured sales hours
+    Given a device exists with machine ID "HOURS-002"
+    When I set the following sales hours for device "HOURS-002" in timezone "Europe/Zurich":
+      | kind     | days                        | start | end   | sku_codes | reason                        |
+      | open     | mon,tue,wed,thu,fri,sat,sun | 00:00 | 24:00 |           |                               |
+      | blackout |                             | 22:00 | 06:00 | BEER-H2   | no alcohol sales after 22:00  |
+    Then the response status should be 200
+    When I send a GET request to "/api/v1/device/details?machine_id=HOURS-002"
+    Then the response status should be 200
+    And the response field "sales_hours.timezone" should be "Europe/Zurich"
+    And the response field "sales_hours.configured" should be "true"
+    And the response field "sales_hours.open_now" should be "true"
+    And the response field "sales_hours.blackouts.0.start" should be "22:00"
+    And the response field "sales_hours.blackouts.0.reason" should be "no alcohol sales after 22:00"
+
+  Scenario: A session cannot be started while the device is closed
+    Given a device exists with machine ID "HOURS-003"
+    And I set the following sales hours for device "HOURS-003" in timezone "UTC":
+      | kind     | days | start | end   | sku_codes | reason            |
+      | blackout |      | 00:00 | 24:00 |           | closed for repair |
+    When I start a session on device "HOURS-003"
+    Then the response status should be 422
+    And the response should contain error "device is closed: closed for repair"
+    And the response field "code" should be "device_closed"
+    When I send a GET request to "/api/v1/device/details?machine_id=HOURS-003"
+    Then the response field "sales_hours.open_now" should be "false"
+    And the response field "sales_hours.closed_reason" should be "closed for repair"
+
+  Scenario: A restricted item cannot be confirmed during its blackout
+    Given the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | APPLE-001 | Fuji Apple | 250         | 150          |
+      | APPLE-002 | Gala Apple | 230         | 140          |
+    And an active session with items exists on device "HOURS-004"
+    And I set the following sales hours for device "HOURS-004" in timezone "UTC":
+      | kind     | days | start | end   | sku_codes | reason             |
+      | blackout |      | 00:00 | 24:00 | APPLE-002 | age check required |
+    When I confirm the session with payment reference "PAY-HOURS-4"
+    Then the response status should be 422
+    And the response should contain error "Gala Apple (age check required)"
+    And the response field "code" should be "sale_restricted"
+
+  Scenario: Invalid sales windows are rejected
+    Given a device exists with machine ID "HOURS-005"
+    When I set the following sales hours for device "HOURS-005" in timezone "Mars/Olympus":
+      | kind | days | start | end   | sku_codes | reason |
+      | open |      | 08:00 | 20:00 |           |        |
+    Then the response status should be 422
+    And the response should contain error "unknown timezone"
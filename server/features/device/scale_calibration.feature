@@ -0,0 +1,78 @@
+@api @device
+Feature: Scale Calibration
+  As an operations manager
+  I want devices to report every scale calibration against known weights
+  So that I can schedule recalibration before weight checks go bad
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device reports a calibration as it found the scale
+    Given a device exists with machine ID "SCALE-001"
+    When device "SCALE-001" calibrates its scale with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 501            |
+      | 1000            | 1002           |
+    Then the response status should be 201
+    And the response field "span_factor" should be "1.002"
+    And the response field "error_grams" should be "2"
+    And the response field "readings.1.measured_grams" should be "1002"
+
+  Scenario: The calibration history shows how fast the scale drifts
+    Given a device exists with machine ID "SCALE-002"
+    And device "SCALE-002" calibrated its scale 30 days ago with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 500            |
+    And device "SCALE-002" calibrated its scale 20 days ago with zero reading "0.5" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 500.5          |
+    And device "SCALE-002" calibrated its scale 10 days ago with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 499.5          |
+    When I request the scale calibrations of device "SCALE-002"
+    Then the response status should be 200
+    And the response field "calibrations.2.zero_grams" should be "0"
+    And the response field "calibrations.0.error_grams" should be "0.5"
+    And the response field "calibrations.1.zero_grams" should be "0.5"
+    And the response should contain field "recalibrate_by"
+    And the response field "recalibration_due" should be "false"
+
+  Scenario: A scale that drifted past the tolerance is due for recalibration
+    Given a device exists with machine ID "SCALE-003"
+    And device "SCALE-003" calibrated its scale 10 days ago with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 500            |
+    And device "SCALE-003" calibrated its scale 6 days ago with zero reading "1" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 504            |
+    When I request the scale calibrations of device "SCALE-003"
+    Then the response status should be 200
+    And the response field "recalibration_due" should be "true"
+
+  Scenario: A single calibration sets a baseline but shows no drift yet
+    Given a device exists with machine ID "SCALE-004"
+    And device "SCALE-004" calibrates its scale with zero reading "1.5" and readings:
+      | reference_grams | measured_grams |
+      | 500             | 503            |
+    When I request the scale calibrations of device "SCALE-004"
+    Then the response status should be 200
+    And the response field "drift_grams_per_day" should be "0"
+    And the response should not contain field "recalibrate_by"
+    And the response field "recalibration_due" should be "false"
+
+  Scenario: Calibrations without known weights are rejected
+    Given a device exists with machine ID "SCALE-005"
+    When device "SCALE-005" calibrates its scale with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+    Then the response status should be 422
+    And the response should contain error "at least one known-weight reading"
+    When device "SCALE-005" calibrates its scale with zero reading "0" and readings:
+      | reference_grams | measured_grams |
+      | 0               | 0.4            |
+    Then the response status should be 422
+    And the response should contain error "positive reference weight"
+
+  Scenario: Unknown devices have no calibration history
+    When I send a GET request to "/api/v1/devices/00000000-0000-0000-0000-000000000000/scale/calibrations"
+    Then the response status should be 404
@@ -0,0 +1,21 @@
+@api @device
+Feature: Shelf Stock Estimation
+  As an operator
+  I want stock levels estimated from the device's shelf snapshots
+  So that I know when a device runs low without counting by hand
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "SHELF-001"
+
+  Scenario: Device without shelf snapshots has an empty stock estimate
+    When I send a GET request to "/api/v1/device/stock?machine_id=SHELF-001"
+    Then the response status should be 200
+    And the response should contain field "machine_id" with value "SHELF-001"
+    And the response should contain field "levels"
+
+  Scenario: Shelf snapshot is rejected when cloud detection is not configured
+    When I submit a shelf snapshot for device "SHELF-001"
+    Then the response status should be 503
+    And the response should contain error "shelf detection is unavailable"
@@ -0,0 +1,62 @@
+@api @device
+Feature: Device SKU Assignments
+  As an operator
+  I want to assign each device the part of the catalog it stocks
+  So that devices only sync the SKUs they sell and stray detections stand out
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: A device lists only the SKUs assigned to it
+    Given the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | ASG-COLA   | Cola         | 180         | 350          |
+      | ASG-CHIPS  | Salted Chips | 150         | 60           |
+      | ASG-SALAD  | Green Salad  | 450         | 250          |
+    And a device exists with machine ID "ASG-001"
+    When I assign the SKUs "ASG-COLA, ASG-CHIPS" to device "ASG-001"
+    Then the response status should be 200
+    And the response field "machine_id" should be "ASG-001"
+    And the response field "sku_codes.0" should be "ASG-CHIPS"
+    And the response field "sku_codes.1" should be "ASG-COLA"
+    When I request the SKUs of device "ASG-001"
+    Then the response status should be 200
+    And the response field "count" should be "2"
+
+  Scenario: Unassigning a SKU takes it off the device
+    Given the following SKUs exist:
+      | code      | name        | price_cents | weight_grams |
+      | ASG-WATER | Still Water | 120         | 500          |
+      | ASG-BAR   | Granola Bar | 200         | 45           |
+    And a device exists with machine ID "ASG-002"
+    And I assign the SKUs "ASG-WATER, ASG-BAR" to device "ASG-002"
+    When I unassign the SKU "ASG-BAR" from device "ASG-002"
+    Then the response status should be 200
+    When I request the SKUs of device "ASG-002"
+    Then the response field "count" should be "1"
+    And the response field "skus.0.code" should be "ASG-WATER"
+    When I unassign the SKU "ASG-BAR" from device "ASG-002"
+    Then the response status should be 404
+
+  Scenario: Only catalog SKUs can be assigned
+    Given a device exists with machine ID "ASG-003"
+    When I assign the SKUs "ASG-UNKNOWN" to device "ASG-003"
+    Then the response status should be 422
+    And the response should contain error "SKU is not in the catalog"
+
+  Scenario: Detecting a SKU the device does not stock is suspicious
+    Given the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | ASG-JUICE | Orange Juice | 250         | 330          |
+      | ASG-NUTS  | Mixed Nuts   | 300         | 50           |
+    And a device exists with machine ID "ASG-004"
+    And I assign the SKUs "ASG-JUICE" to device "ASG-004"
+    When I start a session on device "ASG-004"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | ASG-JUICE | 0.95       |
+      | ASG-NUTS  | 0.96       |
+    Then the response status should be 200
+    And the response field "items.1.suspicious" should be "true"
+    And the response field "needs_cloud_ml" should be "true"
@@ -0,0 +1,88 @@
+@api @device
+Feature: Incremental Device SKU Sync
+  As a vending machine
+  I want to download only the SKUs that changed since my last sync
+  So that keeping my local catalog current stays cheap
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Only SKUs changed since the cursor are returned, with tombstones
+    Given the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | SYNC-COLA  | Cola         | 180         | 350          |
+      | SYNC-CHIPS | Salted Chips | 150         | 60           |
+      | SYNC-WATER | Still Water  | 120         | 500          |
+    And the device has synced its SKUs
+    When I deactivate the SKU "SYNC-CHIPS"
+    And I delete the SKU "SYNC-WATER"
+    And the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | SYNC-JUICE | Orange Juice | 250         | 330          |
+    And the device syncs the SKU changes since its last sync
+    Then the response status should be 200
+    And the response field "count" should be "3"
+    And the response field "skus.0.code" should be "SYNC-CHIPS"
+    And the response field "skus.0.active" should be "false"
+    And the response field "skus.0.deleted" should be "false"
+    And the response field "skus.1.code" should be "SYNC-WATER"
+    And the response field "skus.1.active" should be "false"
+    And the response field "skus.1.deleted" should be "true"
+    And the response field "skus.2.code" should be "SYNC-JUICE"
+    And the response field "skus.2.active" should be "true"
+
+  Scenario: A SKU mapped to a class is synced again with its class ID
+    Given the following SKUs exist:
+      | code       | name     | price_cents | weight_grams |
+      | SYNC-LEMON | Lemonade | 190         | 350          |
+    And the device has synced its SKUs
+    When I map the SKU "SYNC-LEMON" to class 12
+    And the device syncs the SKU changes since its last sync
+    Then the response status should be 200
+    And the response field "count" should be "1"
+    And the response field "skus.0.code" should be "SYNC-LEMON"
+    And the response field "skus.0.class_id" should be "12"
+
+  Scenario: Nothing changed since the last sync
+    Given the following SKUs exist:
+      | code      | name | price_cents | weight_grams |
+      | SYNC-TEA  | Tea  | 200         | 330          |
+    And the device has synced its SKUs
+    When the device syncs the SKU changes since its last sync
+    Then the response status should be 200
+    And the response field "count" should be "0"
+
+  Scenario: The cursor must be a timestamp
+    When I send a GET request to "/api/v1/device/skus?since=yesterday"
+    Then the response status should be 400
+
+  Scenario: The sync carries the class mapping the ML server holds
+    Given the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | SYNC-MINT | Mint Gum   | 90          | 30           |
+    And I map the SKU "SYNC-MINT" to class 41
+    When I send a GET request to "/api/v1/device/skus"
+    Then the response status should be 200
+    And the SKU sync should map class 41 to "SYNC-MINT"
+    And the response should not contain field "model_version_source"
+
+  Scenario: A device learns the model version its policy pins
+    Given a device exists with machine ID "SYNC-DEV-001"
+    And I set the following policy overrides for device "SYNC-DEV-001":
+      | model_version |
+      | shelf-v3      |
+    When I send a GET request to "/api/v1/device/skus?machine_id=SYNC-DEV-001"
+    Then the response status should be 200
+    And the response field "model_version" should be "shelf-v3"
+    And the response field "model_version_source" should be "device"
+
+  Scenario: A device without a pinned model is told so
+    Given a device exists with machine ID "SYNC-DEV-002"
+    When I send a GET request to "/api/v1/device/skus?machine_id=SYNC-DEV-002"
+    Then the response status should be 200
+    And the response field "model_version_source" should be "default"
+
+  Scenario: Syncing for an unknown device fails
+    When I send a GET request to "/api/v1/device/skus?machine_id=SYNC-NOBODY"
+    Then the response status should be 404
@@ -0,0 +1,30 @@
+@api @device
+Feature: Temperature Excursions
+  As an operator of fresh-food devices
+  I want a device blocked after its cabinet stays too warm for too long
+  So that no one buys food that was not kept cold enough
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "FRIDGE-001"
+
+  Scenario: Sustained temperature excursion blocks a fresh-food device until cleared
+    When device "FRIDGE-001" reports a cabinet temperature of 12.5 degrees 45 minutes ago
+    And device "FRIDGE-001" reports a cabinet temperature of 11 degrees 0 minutes ago
+    Then the response status should be 200
+    And the response should contain field "status" with value "blocked"
+    When device "FRIDGE-001" requests a start token
+    Then the response status should be 422
+    And the response should contain error "device is blocked pending operator clearance"
+    When operator "ops-1" clears device "FRIDGE-001"
+    Then the response status should be 200
+    When device "FRIDGE-001" requests a start token
+    Then the response status should be 200
+
+  Scenario: Short temperature spike does not block the device
+    When device "FRIDGE-001" reports a cabinet temperature of 4 degrees 20 minutes ago
+    And device "FRIDGE-001" reports a cabinet temperature of 12 degrees 10 minutes ago
+    And device "FRIDGE-001" reports a cabinet temperature of 12 degrees 0 minutes ago
+    Then the response status should be 200
+    And the response should contain field "status" with value "active"
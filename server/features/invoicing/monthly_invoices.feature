@@ -0,0 +1,32 @@
+@api @invoicing
+Feature: Monthly B2B Invoices
+  As an operator's finance team
+  I want to issue consolidated monthly invoices to business customers
+  So that they can reclaim VAT on their purchases
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  @error-handling
+  Scenario: Invoice period must be a calendar month
+    When I generate an invoice for customer "ACME-01" for period "2025-13"
+    Then the response status should be 400
+    And the response should contain error "billing period must be in YYYY-MM format"
+
+  @error-handling
+  Scenario: Invoices are only issued for closed months
+    When I generate an invoice for customer "ACME-01" for the current month
+    Then the response status should be 422
+    And the response should contain error "billing period has not ended yet"
+
+  @error-handling
+  Scenario: No invoice without completed transactions
+    When I generate an invoice for customer "ACME-01" for period "2020-01"
+    Then the response status should be 422
+    And the response should contain error "no completed transactions in billing period"
+
+  Scenario: Listing invoices requires a customer
+    When I send a GET request to "/api/v1/invoices"
+    Then the response status should be 400
+    And the response should contain error "customer_id is required"
@@ -0,0 +1,47 @@
+@api @pricing
+Feature: Coupon Administration
+  As an operator
+  I want to define coupon codes and withdraw them
+  So that I can run promotions customers redeem at checkout
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  @smoke
+  Scenario: Define a percentage coupon
+    When a coupon "WELCOME" takes 10 percent off
+    Then the response status should be 201
+    And the response should contain field "coupon_id"
+    When I fetch the coupon "WELCOME"
+    Then the response status should be 200
+    And the response field "status" should be "active"
+    And the response field "percent_off" should be "10"
+    And the response field "redemptions" should be "0"
+
+  Scenario: Disable a coupon
+    Given a coupon "SPRING" takes 15 percent off
+    When I disable the coupon "SPRING"
+    Then the response status should be 200
+    And the response field "status" should be "disabled"
+    When I fetch the coupon "SPRING"
+    Then the response field "status" should be "disabled"
+    And the response should contain field "disabled_at"
+
+  @error-handling
+  Scenario: A coupon takes either a percentage or an amount off
+    When I create a coupon "BOTH" taking 10 percent and 100 cents off
+    Then the response status should be 400
+    And the response should contain error "coupon takes either a percentage between 1 and 100 or a positive amount off"
+
+  @error-handling
+  Scenario: A coupon cannot be created already expired
+    When I create a coupon "LATE" that expired yesterday
+    Then the response status should be 400
+    And the response should contain error "coupon must expire in the future"
+
+  @error-handling
+  Scenario: Unknown coupon
+    When I send a GET request to "/api/v1/coupons/00000000-0000-0000-0000-000000000000"
+    Then the response status should be 404
+    And the response should contain error "coupon not found"
@@ -0,0 +1,28 @@
+@api @pricing
+Feature: Price Experiments
+  As an operator
+  I want to test alternative SKU prices on a subset of machines
+  So that I can pick the price that converts and earns best
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  @smoke
+  Scenario: Define a running price experiment
+    When I create a price experiment for SKU "APPLE-001" with variant price 199
+    Then the response status should be 201
+    And the response should contain field "experiment_id"
+    And the response should contain field "status" with value "running"
+
+  @error-handling
+  Scenario: Experiments need a control and a variant
+    When I create a price experiment with a single variant for SKU "APPLE-001"
+    Then the response status should be 400
+    And the response should contain error "experiment needs at least two variants"
+
+  @error-handling
+  Scenario: Results for an unknown experiment
+    When I send a GET request to "/api/v1/experiments/00000000-0000-0000-0000-000000000000/results"
+    Then the response status should be 404
+    And the response should contain error "experiment not found"
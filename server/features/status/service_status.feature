@@ -0,0 +1,42 @@
+@api @status
+Feature: Service Status
+  As a customer app or operator dashboard
+  I want a public summary of which parts of the service are available
+  So that I can show a "service degraded" banner when something is wrong
+
+  Background:
+    Given the API server is running
+
+  Scenario: The status is public and cacheable
+    When I send a GET request to "/api/v1/status"
+    Then the response status should be 200
+    And the response header "Cache-Control" should be "public, max-age=30"
+    And the response field "components.0.name" should be "api"
+    And the response field "components.0.status" should be "operational"
+    And the response field "components.2.name" should be "ml_verification"
+    And the response field "components.2.status" should be "not_configured"
+    When I request the status again with the last ETag
+    Then the response status should be 304
+
+  Scenario: An incident degrades its component until it is resolved
+    When operator "ops-mia" raises a "degraded" incident on "payments" saying "Card payments are delayed"
+    Then the response status should be 201
+    And the response field "created_by" should be "ops-mia"
+    When I send a GET request to "/api/v1/status"
+    Then the response field "components.1.name" should be "payments"
+    And the response field "components.1.status" should be "degraded"
+    And the response field "components.1.message" should be "Card payments are delayed"
+    When operator "ops-mia" resolves the incidents on "payments"
+    Then the response status should be 200
+    And the response field "resolved_by" should be "ops-mia"
+    When I send a GET request to "/api/v1/status"
+    Then the response field "components.1.status" should be "operational"
+
+  Scenario: Incidents can only be raised by identified staff for known components
+    When I send a POST request to "/api/v1/status/incidents"
+    Then the response status should be 400
+    When operator "" raises an "outage" incident on "payments" saying "Stripe is down"
+    Then the response status should be 401
+    When operator "ops-mia" raises an "outage" incident on "coffee" saying "No coffee"
+    Then the response status should be 422
+    And the response should contain error "unknown component"
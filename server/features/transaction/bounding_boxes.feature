@@ -0,0 +1,49 @@
+@api @transaction
+Feature: Detection Bounding Boxes
+  As support staff or the ML team
+  I want the boxes the camera drew kept with each basket line
+  So that I can reconstruct what the device saw in a session
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "BBOX-001"
+    And the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | BBX-COLA   | Cola         | 150         | 350          |
+      | BBX-CHIPS  | Potato Chips | 200         | 60           |
+
+  Scenario: Boxes sent with a detection are stored with the basket line
+    Given an active session exists on device "BBOX-001"
+    When I submit the following detections to the session:
+      | sku       | confidence | bbox                |
+      | BBX-COLA  | 0.95       | 0.1,0.2,0.3,0.4     |
+      | BBX-COLA  | 0.92       | 0.5,0.2,0.3,0.4     |
+      | BBX-CHIPS | 0.9        |                     |
+    Then the response status should be 200
+    And the response field "items.0.bboxes" should be "[[0.1 0.2 0.3 0.4] [0.5 0.2 0.3 0.4]]"
+    And the response should not contain field "items.1.bboxes"
+    When I fetch the current session
+    Then the response field "items.0.bboxes" should be "[[0.1 0.2 0.3 0.4] [0.5 0.2 0.3 0.4]]"
+
+  Scenario: Taking an item out drops the box it was seen in
+    Given an active session exists on device "BBOX-001"
+    And I submit frame 1 with the following changes to the session:
+      | change | sku      | confidence | bbox            |
+      | added  | BBX-COLA | 0.95       | 0.1,0.2,0.3,0.4 |
+      | added  | BBX-COLA | 0.92       | 0.5,0.2,0.3,0.4 |
+    When I submit frame 2 with the following changes to the session:
+      | change  | sku      | confidence | bbox            |
+      | removed | BBX-COLA | 0.9        | 0.1,0.2,0.3,0.4 |
+    Then the response status should be 200
+    And the response field "items.0.quantity" should be "1"
+    When I fetch the current session
+    Then the response field "items.0.bboxes" should be "[[0.5 0.2 0.3 0.4]]"
+
+  Scenario: Malformed boxes are not stored
+    Given an active session exists on device "BBOX-001"
+    When I submit the following detections to the session:
+      | sku      | confidence | bbox        |
+      | BBX-COLA | 0.95       | 0.1,0.2,0.3 |
+    Then the response status should be 200
+    And the response should not contain field "items.0.bboxes"
@@ -0,0 +1,78 @@
+@api @transaction
+Feature: Bundle Pricing
+  As an operator
+  I want combo offers such as a snack and a drink for one price
+  So that customers who take the whole combo pay the bundle price automatically
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "BUNDLE-001"
+    And the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | BNDL-CHIPS | Potato Chips | 200         | 60           |
+      | BNDL-SODA1 | Lemon Soda   | 150         | 350          |
+    And a bundle "BNDL-MEAL1" priced at 300 cents exists with:
+      | sku        | quantity |
+      | BNDL-CHIPS | 1        |
+      | BNDL-SODA1 | 1        |
+
+  @smoke
+  Scenario: A basket holding the bundle pays the bundle price
+    Given an active session exists on device "BUNDLE-001"
+    When I submit the following detections to the session:
+      | sku        | confidence |
+      | BNDL-CHIPS | 0.95       |
+      | BNDL-SODA1 | 0.93       |
+    Then the response status should be 200
+    And the total should be 300 cents
+    And the response field "items.0.bundle" should be "BNDL-MEAL1"
+    And the response field "items.0.price_cents" should be "171"
+    And the response field "items.1.price_cents" should be "129"
+
+  Scenario: Extra units are charged at their own price
+    Given an active session exists on device "BUNDLE-001"
+    When I submit the following detections to the session:
+      | sku        | confidence |
+      | BNDL-SODA1 | 0.95       |
+      | BNDL-CHIPS | 0.95       |
+      | BNDL-SODA1 | 0.93       |
+    Then the response status should be 200
+    And the total should be 450 cents
+    And the response field "items.2.price_cents" should be "150"
+
+  Scenario: An incomplete bundle is not discounted
+    Given an active session exists on device "BUNDLE-001"
+    When I submit the following detections to the session:
+      | sku        | confidence |
+      | BNDL-CHIPS | 0.95       |
+    Then the response status should be 200
+    And the total should be 200 cents
+
+  Scenario: The bundle shows on the session after checkout
+    Given an active session exists on device "BUNDLE-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | BNDL-CHIPS | 0.95       |
+      | BNDL-SODA1 | 0.93       |
+    And I confirm the session with payment reference "PAY-BUNDLE-1"
+    When I fetch the current session
+    Then the response field "total_cents" should be "300"
+    And the response field "items.1.bundle" should be "BNDL-MEAL1"
+
+  @error-handling
+  Scenario: A bundle needs at least two units
+    When I create the bundle "BNDL-BAD-01" priced at 100 cents with:
+      | sku        | quantity |
+      | BNDL-CHIPS | 1        |
+    Then the response status should be 422
+    And the response should contain error "at least two units"
+
+  @error-handling
+  Scenario: Bundles cannot contain bundles
+    When I create the bundle "BNDL-BAD-02" priced at 400 cents with:
+      | sku        | quantity |
+      | BNDL-MEAL1 | 1        |
+      | BNDL-CHIPS | 1        |
+    Then the response status should be 422
+    And the response should contain error "not bundles themselves"
@@ -0,0 +1,37 @@
+@api @transaction
+Feature: Cloud Verification
+  As a device that is unsure what the customer took
+  I want to upload the session image for the cloud model to check
+  So that the customer is charged for what is actually in the basket
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "VERIFY-001"
+
+  @error-handling
+  Scenario: Verification needs a cloud detector
+    Given an active session with items exists on device "VERIFY-001"
+    When I upload the image of the current session for cloud verification
+    Then the response status should be 503
+    And the response should contain error "cloud detection is unavailable"
+
+  @error-handling
+  Scenario: Verification needs an image
+    Given an active session exists on device "VERIFY-001"
+    When I upload an empty image of the current session for cloud verification
+    Then the response status should be 400
+    And the response should contain error "image is required"
+
+  @error-handling
+  Scenario: Only active sessions can be verified
+    Given a completed session exists on device "VERIFY-001"
+    When I upload the image of the current session for cloud verification
+    Then the response status should be 422
+    And the response should contain error "session not active"
+
+  @error-handling
+  Scenario: Unknown sessions cannot be verified
+    When I upload an image of session "00000000-0000-0000-0000-000000000000" for cloud verification
+    Then the response status should be 404
+    And the response should contain error "session not found"
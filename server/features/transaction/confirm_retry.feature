@@ -0,0 +1,33 @@
+@api @transaction
+Feature: Retrying a Confirmation
+  As a customer's app
+  I want to retry a confirmation that timed out
+  So that I learn the purchase went through instead of getting an error
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "RETRY-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | RTY-APPLE | Fuji Apple | 250         | 150          |
+    And I start a session on device "RETRY-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RTY-APPLE | 0.95       |
+
+  Scenario: The same payment reference gets the original confirmation back
+    Given I confirm the session with payment reference "PAY-RETRY-1"
+    When I retry the confirmation with payment reference "PAY-RETRY-1"
+    Then the response status should be 200
+    And the response header "Idempotent-Replayed" should be "true"
+    And the response field "status" should be "completed"
+    And the response field "total_cents" should be "250"
+    And the response should repeat the original confirmation
+
+  @error-handling
+  Scenario: Another payment reference finds the session completed
+    Given I confirm the session with payment reference "PAY-RETRY-2"
+    When I retry the confirmation with payment reference "PAY-RETRY-OTHER"
+    Then the response status should be 422
+    And the response should contain error "session not active"
@@ -0,0 +1,83 @@
+@api @transaction
+Feature: Coupons at Checkout
+  As a customer
+  I want to apply a coupon code to my basket
+  So that I pay the discounted price
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "COUPON-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+    And I start a session on device "COUPON-001"
+    And I submit the following detections weighing 300 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+      | APPLE-001 | 0.95       |
+
+  @smoke
+  Scenario: A percentage coupon is taken off the session total
+    Given a coupon "WELCOME" takes 10 percent off
+    When I apply the coupon "WELCOME" to the session
+    Then the response status should be 200
+    And the response field "subtotal_cents" should be "500"
+    And the response field "discount_cents" should be "50"
+    And the response field "total_cents" should be "450"
+    And the response should contain field "coupon"
+    When I confirm the session with payment reference "PAY-COUPON-1"
+    Then the response status should be 200
+    And the response field "discount_cents" should be "50"
+    And the response field "total_cents" should be "450"
+
+  Scenario: A redeemed coupon counts its redemptions
+    Given a coupon "REPEAT" takes 10 percent off
+    And I apply the coupon "REPEAT" to the session
+    When I confirm the session with payment reference "PAY-COUPON-2"
+    And I fetch the coupon "REPEAT"
+    Then the response field "redemptions" should be "1"
+
+  @error-handling
+  Scenario: A single-use coupon cannot be redeemed twice
+    Given a single-use coupon "ONCE" takes 20 percent off
+    And I apply the coupon "ONCE" to the session
+    And I confirm the session with payment reference "PAY-COUPON-3"
+    And I start a session on device "COUPON-001"
+    And I submit the following detections weighing 150 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    When I apply the coupon "ONCE" to the session
+    Then the response status should be 422
+    And the response should contain error "coupon has been used up"
+    And the response field "code" should be "coupon_not_applicable"
+
+  @error-handling
+  Scenario: A basket below the coupon's minimum does not qualify
+    Given a coupon "BIGBASKET" takes 100 cents off baskets of at least 1000 cents
+    When I apply the coupon "BIGBASKET" to the session
+    Then the response status should be 422
+    And the response should contain error "basket does not reach the coupon's minimum"
+
+  @error-handling
+  Scenario: A disabled coupon cannot be applied
+    Given a coupon "WITHDRAWN" takes 10 percent off
+    And I disable the coupon "WITHDRAWN"
+    When I apply the coupon "WITHDRAWN" to the session
+    Then the response status should be 422
+    And the response should contain error "coupon is no longer valid"
+
+  @error-handling
+  Scenario: A coupon disabled after it was applied fails checkout
+    Given a coupon "PULLED" takes 10 percent off
+    And I apply the coupon "PULLED" to the session
+    And I disable the coupon "PULLED"
+    When I confirm the session with payment reference "PAY-COUPON-4"
+    Then the response status should be 422
+    And the response field "code" should be "coupon_not_applicable"
+
+  @error-handling
+  Scenario: Unknown coupon code
+    When I apply the coupon "NO-SUCH-CODE" to the session
+    Then the response status should be 404
+    And the response should contain error "coupon not found"
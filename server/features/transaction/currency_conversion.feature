@@ -0,0 +1,61 @@
+@api @transaction
+Feature: Baskets Mixing Currencies
+  As an operator selling imported products at their supplier's prices
+  I want baskets with items priced in several currencies to be totalled in one
+  So that sessions can be paid instead of failing
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "FX-001"
+    And the following SKUs exist:
+      | code      | name          | price_cents | weight_grams | currency |
+      | FX-COLA   | Cola          | 200         | 350          | USD      |
+      | FX-BRETZL | Pretzel       | 160         | 80           | EUR      |
+      | FX-SCONE  | Scone         | 150         | 90           | GBP      |
+
+  Scenario: Items in another currency are converted into the basket's currency
+    Given an active session exists on device "FX-001"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | FX-COLA   | 0.95       |
+      | FX-BRETZL | 0.93       |
+    Then the response status should be 200
+    And the total should be 400 cents
+    And the response field "currency" should be "USD"
+    And the response field "items.1.price_cents" should be "200"
+    And the response field "items.1.currency" should be "USD"
+
+  Scenario: The basket is priced in the currency of its first item
+    Given an active session exists on device "FX-001"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | FX-BRETZL | 0.93       |
+      | FX-COLA   | 0.95       |
+    Then the response status should be 200
+    And the total should be 320 cents
+    And the response field "currency" should be "EUR"
+
+  Scenario: A currency without an exchange rate cannot be priced
+    Given an active session exists on device "FX-001"
+    When I submit the following detections to the session:
+      | sku      | confidence |
+      | FX-COLA  | 0.95       |
+      | FX-SCONE | 0.90       |
+    Then the response status should be 503
+    And the response should contain error "currency conversion unavailable"
+    And the response field "code" should be "currency_conversion_unavailable"
+
+  Scenario: A basket that cannot be priced is left as it was
+    Given an active session exists on device "FX-001"
+    And I submit the following detections to the session:
+      | sku     | confidence |
+      | FX-COLA | 0.95       |
+    When I submit the following detections to the session:
+      | sku      | confidence |
+      | FX-COLA  | 0.95       |
+      | FX-SCONE | 0.90       |
+    Then the response status should be 503
+    When I fetch the current session
+    Then the total should be 200 cents
+    And the response field "currency" should be "USD"
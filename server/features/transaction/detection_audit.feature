@@ -0,0 +1,33 @@
+@api @transaction
+Feature: Detection Audit Log
+  As support staff handling a dispute
+  I want every detection submitted for a session kept as it was sent
+  So that I can see what the device saw and what the server decided
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "AUDIT-001"
+
+  Scenario: Applied detections are kept with their payload
+    Given an active session with items exists on device "AUDIT-001"
+    When I list the detections of the current session
+    Then the response status should be 200
+    And the response field "detections.0.source" should be "device"
+    And the response field "detections.0.outcome" should be "applied"
+    And the response field "detections.0.payload.items.0.sku" should be "APPLE-001"
+
+  Scenario: Rejected detections are kept too
+    Given a completed session exists on device "AUDIT-001"
+    When I send a detection with an image for the current session on device "AUDIT-001"
+    And I list the detections of the current session
+    Then the response status should be 200
+    And the response field "detections.1.outcome" should be "rejected"
+    And the response field "detections.1.decision.error" should be "session is not active"
+    And the response field "detections.1.payload.image_bytes" should be "15"
+
+  @error-handling
+  Scenario: Unknown sessions have no detections
+    When I send a GET request to "/api/v1/session/00000000-0000-0000-0000-000000000000/detections"
+    Then the response status should be 404
+    And the response should contain error "session not found"
@@ -0,0 +1,83 @@
+@api @transaction
+Feature: Idempotent Detection Submission
+  As an edge device on a flaky network
+  I want to retry a detection without it being processed twice
+  So that a lost response never double-processes the same frame
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "IDEM-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+    And an active session exists on device "IDEM-001"
+
+  Scenario: A retried frame gets the original result back
+    When I submit the following detections with idempotency key "frame-0001" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 200
+    And the response field "total_cents" should be "250"
+    When I submit the following detections with idempotency key "frame-0001" to the session:
+      | sku       | confidence |
+      | APPLE-002 | 0.95       |
+    Then the response status should be 200
+    And the response header "Idempotent-Replayed" should be "true"
+    And the response field "items.0.code" should be "APPLE-001"
+    And the response field "total_cents" should be "250"
+    When I fetch the current session
+    Then the response field "items.0.code" should be "APPLE-001"
+
+  Scenario: The detection ID is the idempotency key when no header is sent
+    When I submit the following detections with detection ID "frame-0002" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I submit the following detections with detection ID "frame-0002" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+      | APPLE-002 | 0.95       |
+    Then the response status should be 200
+    And the response header "Idempotent-Replayed" should be "true"
+    And the response field "total_cents" should be "250"
+
+  Scenario: A new frame is processed
+    Given I submit the following detections with idempotency key "frame-0003" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    When I submit the following detections with idempotency key "frame-0004" to the session:
+      | sku       | confidence |
+      | APPLE-002 | 0.95       |
+    Then the response status should be 200
+    And the response field "total_cents" should be "230"
+
+  Scenario: A retry arriving after checkout still gets its result
+    Given I submit the following detections with idempotency key "frame-0005" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I confirm the session with payment reference "PAY-IDEM-1"
+    When I submit the following detections with idempotency key "frame-0005" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 200
+    And the response header "Idempotent-Replayed" should be "true"
+
+  Scenario: A key cannot be reused for another session
+    Given I submit the following detections with idempotency key "frame-0006" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I cancel the session with reason "changed mind"
+    And an active session exists on device "IDEM-001"
+    When I submit the following detections with idempotency key "frame-0006" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 409
+    And the response should contain error "idempotency key was already used for another session"
+
+  Scenario: Oversized keys are rejected
+    When I submit the following detections with idempotency key "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 400
+    And the response should contain error "idempotency key is limited to 100 characters"
@@ -0,0 +1,74 @@
+@api @transaction
+Feature: Detection Model Version
+  As an operator
+  I want detections checked against the model assigned to each device
+  So that a device running the wrong model does not charge customers on its word alone
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | MODEL-APL | Fuji Apple | 250         | 150          | 10               |
+
+  Scenario: Detections from the assigned model are accepted
+    Given a device exists with machine ID "MODEL-DEV-001"
+    And I set the following policy overrides for device "MODEL-DEV-001":
+      | model_version |
+      | shelf-v3      |
+    And an active session exists on device "MODEL-DEV-001"
+    When I submit the following detections from model "shelf-v3" to the session:
+      | sku       | confidence |
+      | MODEL-APL | 0.95       |
+    Then the response status should be 200
+    And the response field "model_version_mismatch" should be "false"
+    And the response field "expected_model_version" should be "shelf-v3"
+
+  Scenario: Detections from another model are sent to cloud ML
+    Given a device exists with machine ID "MODEL-DEV-002"
+    And I set the following policy overrides for device "MODEL-DEV-002":
+      | model_version |
+      | shelf-v3      |
+    And an active session exists on device "MODEL-DEV-002"
+    When I submit the following detections from model "shelf-v2" to the session:
+      | sku       | confidence |
+      | MODEL-APL | 0.95       |
+    Then the response status should be 200
+    And the response field "model_version_mismatch" should be "true"
+    And the response field "needs_cloud_ml" should be "true"
+    And the response field "expected_model_version" should be "shelf-v3"
+
+  Scenario: Devices follow the model assigned to their group
+    Given a device exists with machine ID "MODEL-DEV-003"
+    And I create the device group "Model Pilot Sites"
+    And I add the devices "MODEL-DEV-003" to the group "Model Pilot Sites"
+    And I set the following policy for the group "Model Pilot Sites":
+      | model_version |
+      | shelf-v4-beta |
+    And an active session exists on device "MODEL-DEV-003"
+    When I submit the following detections from model "shelf-v3" to the session:
+      | sku       | confidence |
+      | MODEL-APL | 0.95       |
+    Then the response status should be 200
+    And the response field "model_version_mismatch" should be "true"
+    And the response field "expected_model_version" should be "shelf-v4-beta"
+
+  Scenario: Devices without an assigned model or not reporting one are not checked
+    Given a device exists with machine ID "MODEL-DEV-004"
+    And an active session exists on device "MODEL-DEV-004"
+    When I submit the following detections from model "shelf-v1" to the session:
+      | sku       | confidence |
+      | MODEL-APL | 0.95       |
+    Then the response status should be 200
+    And the response field "model_version_mismatch" should be "false"
+    And the response should not contain field "expected_model_version"
+    Given a device exists with machine ID "MODEL-DEV-005"
+    And I set the following policy overrides for device "MODEL-DEV-005":
+      | model_version |
+      | shelf-v3      |
+    And an active session exists on device "MODEL-DEV-005"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | MODEL-APL | 0.95       |
+    Then the response status should be 200
+    And the response field "model_version_mismatch" should be "false"
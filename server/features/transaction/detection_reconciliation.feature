@@ -0,0 +1,69 @@
+@api @transaction
+Feature: Detection Reconciliation
+  As an operator
+  I want the device's and the cloud model's detections of a basket merged object by object
+  So that a cloud verification corrects what the device got wrong without losing what it got right
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "RECONCILE-001"
+    And the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | RCN-COLA  | Cola         | 150         | 350          |
+      | RCN-COLA0 | Cola Zero    | 160         | 350          |
+      | RCN-CHIPS | Potato Chips | 200         | 60           |
+
+  Scenario: Overlapping boxes are one object of the more confident SKU
+    Given an active session exists on device "RECONCILE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence | bbox                |
+      | RCN-COLA  | 0.6        | 0.10,0.10,0.20,0.30 |
+      | RCN-CHIPS | 0.9        | 0.50,0.50,0.20,0.20 |
+    And the cloud model finds the following on device "RECONCILE-001":
+      | sku       | confidence | bbox                |
+      | RCN-COLA0 | 0.95       | 0.11,0.10,0.20,0.30 |
+      | RCN-CHIPS | 0.8        | 0.52,0.50,0.20,0.20 |
+    When I upload the image of the current session for cloud verification
+    Then the response status should be 200
+    And the response field "strategy" should be "iou"
+    And the response field "corrected" should be "true"
+    And the total should be 360 cents
+    And the response field "verified.0.sku" should be "RCN-COLA"
+    And the response field "verified.0.quantity" should be "0"
+    And the response field "verified.1.sku" should be "RCN-CHIPS"
+    And the response field "verified.1.quantity" should be "1"
+    And the response field "verified.2.sku" should be "RCN-COLA0"
+    And the response field "verified.2.quantity" should be "1"
+
+  Scenario: Objects only one side sees stay in the basket
+    Given an active session exists on device "RECONCILE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence | bbox                |
+      | RCN-CHIPS | 0.9        | 0.10,0.10,0.20,0.20 |
+    And the cloud model finds the following on device "RECONCILE-001":
+      | sku       | confidence | bbox                |
+      | RCN-CHIPS | 0.85       | 0.10,0.11,0.20,0.20 |
+      | RCN-COLA  | 0.9        | 0.60,0.60,0.20,0.30 |
+    When I upload the image of the current session for cloud verification
+    Then the response status should be 200
+    And the total should be 350 cents
+    And the response field "verified.1.sku" should be "RCN-COLA"
+    And the response field "verified.1.edge_quantity" should be "0"
+    And the response field "verified.1.quantity" should be "1"
+
+  Scenario: Without boxes the cloud model's count of each SKU it sees is taken
+    Given an active session exists on device "RECONCILE-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | RCN-COLA | 0.7        |
+      | RCN-COLA | 0.7        |
+    And the cloud model finds the following on device "RECONCILE-001":
+      | sku      | confidence |
+      | RCN-COLA | 0.9        |
+    When I upload the image of the current session for cloud verification
+    Then the response status should be 200
+    And the response field "corrected" should be "true"
+    And the total should be 150 cents
+    And the response field "verified.0.edge_quantity" should be "2"
+    And the response field "verified.0.quantity" should be "1"
@@ -0,0 +1,84 @@
+@api @transaction
+Feature: Chargeback Disputes
+  As the operator's finance team
+  I want chargebacks tracked against the sale they dispute, with what the device saw
+  So that we can contest them and know how much money is at stake
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "DSP-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+    And I start a session on device "DSP-001"
+    And I send a detection with an image for the current session on device "DSP-001"
+    And I confirm the session with payment reference "PAY-DSP-1"
+
+  Scenario: A dispute is opened against the session's transaction
+    When staff member "agent-7" disputes 250 cents of the session because "customer says the fridge charged twice"
+    Then the response status should be 201
+    And the response should contain field "transaction_id"
+    And the response field "status" should be "open"
+    And the response field "amount_cents" should be "250"
+    And the response field "opened_by" should be "agent-7"
+
+  Scenario: Staff annotate a dispute and attach the device's evidence
+    Given staff member "agent-7" disputes 250 cents of the session because "item not taken"
+    When staff member "agent-7" notes "customer sent a bank statement" on the dispute
+    Then the response status should be 201
+    And the response field "notes.0.note" should be "customer sent a bank statement"
+    When staff member "agent-7" attaches the session's detections to the dispute
+    Then the response status should be 201
+    And the response field "evidence.0.kind" should be "detections"
+    And the response should contain field "evidence.0.detection_ids"
+    When staff member "agent-7" attaches the session's image to the dispute
+    Then the response status should be 201
+    And the response field "evidence.1.kind" should be "image"
+    And the response field "evidence.1.attached_by" should be "agent-7"
+    When I fetch the dispute image
+    Then the response status should be 200
+    And the response header "Content-Type" should be "image/jpeg"
+
+  Scenario: Finance resolves a dispute and it shows in the report
+    Given staff member "agent-7" disputes 250 cents of the session because "item not taken"
+    When staff member "fin-1" with roles "finance" resolves the dispute as "lost"
+    Then the response status should be 200
+    And the response field "status" should be "lost"
+    And the response field "resolved_by" should be "fin-1"
+    When I list the "lost" disputes opened since the dispute
+    Then the response status should be 200
+    And the dispute list should include the dispute
+    When I list the "open" disputes opened since the dispute
+    Then the dispute list should not include the dispute
+    When I request the dispute report since the dispute
+    Then the response status should be 200
+    And the dispute report should count 1 "lost" dispute of 250 cents
+
+  @error-handling
+  Scenario: Only the finance team resolves disputes
+    Given staff member "agent-7" disputes 250 cents of the session because "item not taken"
+    When staff member "agent-7" with roles "support" resolves the dispute as "won"
+    Then the response status should be 403
+    And the response should contain error "resolver lacks the finance role"
+
+  @error-handling
+  Scenario: A session has one open dispute at a time
+    Given staff member "agent-7" disputes 100 cents of the session because "item not taken"
+    When staff member "agent-8" disputes 100 cents of the session because "charged twice"
+    Then the response status should be 409
+    And the response should contain error "the session already has an open dispute"
+
+  @error-handling
+  Scenario: A dispute cannot exceed what was charged
+    When staff member "agent-7" disputes 900 cents of the session because "item not taken"
+    Then the response status should be 422
+    And the response should contain error "dispute exceeds the session total"
+
+  @error-handling
+  Scenario: Evidence is not attached to a resolved dispute
+    Given staff member "agent-7" disputes 250 cents of the session because "item not taken"
+    And staff member "fin-1" with roles "finance" resolves the dispute as "won"
+    When staff member "agent-7" attaches the session's image to the dispute
+    Then the response status should be 422
+    And the response should contain error "dispute is already resolved"
@@ -0,0 +1,47 @@
+@api @transaction
+Feature: Fiscal Receipts
+  As an operator in a country with fiscal receipt laws
+  I want every sale signed by the fiscalization system and kept with the sale
+  So that receipts and invoices can show the fiscal identifiers
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "FISCAL-001"
+    And a device exists with machine ID "PLAIN-001"
+    And device "FISCAL-001" is in a country that requires fiscal receipts
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | FSC-APPLE | Fuji Apple | 250         | 150          |
+
+  Scenario: A confirmed sale gets its fiscal receipt
+    Given I start a session on device "FISCAL-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | FSC-APPLE | 0.95       |
+    When I confirm the session with payment reference "PAY-FISCAL-1"
+    Then the response status should be 200
+    And the response field "fiscal.country" should be "DE"
+    And the response field "fiscal.provider" should be "test-tse"
+    And the response field "fiscal.serial_number" should be "test-serial"
+
+  Scenario: A retried confirmation reads the fiscal receipt stored with the sale
+    Given I start a session on device "FISCAL-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | FSC-APPLE | 0.95       |
+    And I confirm the session with payment reference "PAY-FISCAL-2"
+    When I retry the confirmation with payment reference "PAY-FISCAL-2"
+    Then the response status should be 200
+    And the response header "Idempotent-Replayed" should be "true"
+    And the response should repeat the original confirmation
+    And the response should repeat the original fiscal receipt
+
+  Scenario: A sale where no fiscal receipt is required has none
+    Given I start a session on device "PLAIN-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | FSC-APPLE | 0.95       |
+    When I confirm the session with payment reference "PAY-PLAIN-1"
+    Then the response status should be 200
+    And the response should not contain field "fiscal"
@@ -0,0 +1,70 @@
+@api @transaction
+Feature: Fraud Rules
+  As the operator
+  I want sessions checked against fraud rules as items are detected and at checkout
+  So that suspicious sessions are flagged for review or stopped before they are paid
+
+  # The test server blocks detections more than 5kg off the measured weight
+  # and flags users with two cancellations within the hour
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "FRAUD-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+
+  Scenario: A session matching no rule carries no flags
+    Given I start a session on device "FRAUD-001"
+    And I submit the following detections weighing 150 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    When I fetch the current session
+    Then the response status should be 200
+    And the response should not contain field "fraud_flags"
+
+  Scenario: A user who keeps cancelling sessions is flagged
+    Given user "user-9" starts a session on device "FRAUD-001"
+    And I cancel the session with reason "changed my mind"
+    And user "user-9" starts a session on device "FRAUD-001"
+    And I cancel the session with reason "changed my mind"
+    And user "user-9" starts a session on device "FRAUD-001"
+    When I submit the following detections weighing 150 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 200
+    When I fetch the current session
+    Then the response field "fraud_flags.0.rule" should be "repeated_cancellations"
+    And the response field "fraud_flags.0.action" should be "flag"
+    And the response field "fraud_flags.0.stage" should be "submit"
+    And the response field "fraud_blocked" should be "false"
+
+  @error-handling
+  Scenario: A detection far off the measured weight blocks the session
+    Given I start a session on device "FRAUD-001"
+    When I submit the following detections weighing 6000 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 422
+    And the response should contain error "session is blocked as suspected fraud"
+    And the response field "code" should be "fraud_suspected"
+    When I fetch the current session
+    Then the response field "fraud_flags.0.rule" should be "weight_deviation"
+    And the response field "fraud_flags.0.action" should be "block"
+    And the response field "fraud_blocked" should be "true"
+
+  @error-handling
+  Scenario: A blocked session takes no more detections and cannot be checked out
+    Given I start a session on device "FRAUD-001"
+    And I submit the following detections weighing 6000 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    When I submit the following detections weighing 150 grams to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 422
+    And the response field "code" should be "fraud_suspected"
+    When I confirm the session with payment reference "PAY-FRAUD-1"
+    Then the response status should be 422
+    And the response field "code" should be "fraud_suspected"
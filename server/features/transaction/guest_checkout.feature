@@ -0,0 +1,79 @@
+@api @transaction
+Feature: Guest Checkout
+  As a customer without an account
+  I want to pay in the app through the payment intent of my session
+  So that I can buy without registering first
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "GUEST-001"
+    And the payment provider is available
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams |
+      | GST-APPLE | Fuji Apple | 250         | 150          |
+      | GST-PEAR  | Pear       | 180         | 170          |
+
+  Scenario: A guest session starts with a payment intent for the app
+    When I start a session on device "GUEST-001"
+    Then the response status should be 201
+    And the response should hand over the session's payment intent
+    And the session's payment intent should be for 0 cents
+
+  Scenario: The payment intent follows the basket
+    Given I start a session on device "GUEST-001"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-APPLE | 0.95       |
+      | GST-PEAR  | 0.95       |
+    Then the session's payment intent should be for 430 cents
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-PEAR  | 0.95       |
+    Then the session's payment intent should be for 180 cents
+
+  Scenario: A guest session completes once its payment intent is captured
+    Given I start a session on device "GUEST-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-APPLE | 0.95       |
+    And the customer pays the session's payment intent by card
+    When I confirm the session
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+    And the response field "total_cents" should be "250"
+    And the response field "payment_method.brand" should be "visa"
+    And the response field "payment_method.last4" should be "4242"
+
+  @error-handling
+  Scenario: A guest session is not confirmed before its payment is captured
+    Given I start a session on device "GUEST-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-APPLE | 0.95       |
+    When I confirm the session
+    Then the response status should be 402
+    And the response should contain error "payment has not been captured"
+
+  @error-handling
+  Scenario: A guest session is not confirmed with a payment for another amount
+    Given I start a session on device "GUEST-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-APPLE | 0.95       |
+    And the customer pays 100 cents for the session by card
+    When I confirm the session
+    Then the response status should be 402
+    And the response should contain error "payment has not been captured"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  @error-handling
+  Scenario: A free-form payment reference does not confirm a sale when payments go through the provider
+    Given I start a session on device "GUEST-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | GST-APPLE | 0.95       |
+    When I confirm the session with payment reference "PAY-MADE-UP"
+    Then the response status should be 402
+    And the response should contain error "payment has not been captured"
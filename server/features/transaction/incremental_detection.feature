@@ -0,0 +1,65 @@
+@api @transaction
+Feature: Incremental Detection
+  As a device capturing the basket frame by frame
+  I want to report only the items placed on and taken off the scale
+  So that the cart builds up across captures instead of being replaced
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "INC-001"
+    And the following SKUs exist:
+      | code      | name           | price_cents | weight_grams |
+      | INC-YOGRT | Vanilla Yogurt | 120         | 150          |
+      | INC-JUICE | Orange Juice   | 250         | 330          |
+
+  Scenario: Items placed in later frames add to the cart
+    Given an active session exists on device "INC-001"
+    When I submit frame 1 with the following changes to the session:
+      | change | sku       | confidence |
+      | added  | INC-YOGRT | 0.95       |
+    Then the response status should be 200
+    And the total should be 120 cents
+    When I submit frame 2 with the following changes to the session:
+      | change | sku       | confidence |
+      | added  | INC-JUICE | 0.93       |
+      | added  | INC-YOGRT | 0.94       |
+    Then the response status should be 200
+    And the response should contain 2 items
+    And the response field "items.0.quantity" should be "2"
+    And the total should be 490 cents
+
+  Scenario: Items taken out leave the cart
+    Given an active session exists on device "INC-001"
+    And I submit frame 1 with the following detections to the session:
+      | sku       | confidence |
+      | INC-YOGRT | 0.95       |
+      | INC-JUICE | 0.93       |
+    When I submit frame 2 with the following changes to the session:
+      | change  | sku       | confidence |
+      | removed | INC-JUICE | 0.9        |
+    Then the response status should be 200
+    And the response should contain 1 items
+    And the total should be 120 cents
+
+  Scenario: A frame older than the last one applied is refused
+    Given an active session exists on device "INC-001"
+    And I submit frame 2 with the following changes to the session:
+      | change | sku       | confidence |
+      | added  | INC-YOGRT | 0.95       |
+    When I submit frame 1 with the following changes to the session:
+      | change | sku       | confidence |
+      | added  | INC-JUICE | 0.93       |
+    Then the response status should be 409
+    And the response should contain error "not newer than the last one applied"
+    When I fetch the current session
+    Then the response field "total_cents" should be "120"
+
+  @error-handling
+  Scenario: Only items in the cart can be taken out
+    Given an active session exists on device "INC-001"
+    When I submit frame 1 with the following changes to the session:
+      | change  | sku       | confidence |
+      | removed | INC-JUICE | 0.9        |
+    Then the response status should be 422
+    And the response should contain error "removed item is not in the basket"
@@ -0,0 +1,45 @@
+@api @transaction
+Feature: Item Quantities
+  As a customer
+  I want identical items in my basket shown as one line with a quantity
+  So that my basket and receipt read like a till receipt
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "QTY-001"
+    And the following SKUs exist:
+      | code      | name           | price_cents | weight_grams |
+      | QTY-YOGRT | Vanilla Yogurt | 120         | 150          |
+      | QTY-JUICE | Orange Juice   | 250         | 330          |
+
+  Scenario: Identical items make up one line
+    Given an active session exists on device "QTY-001"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | QTY-YOGRT | 0.95       |
+      | QTY-JUICE | 0.93       |
+      | QTY-YOGRT | 0.9        |
+    Then the response status should be 200
+    And the response should contain 2 items
+    And the response field "items.0.code" should be "QTY-YOGRT"
+    And the response field "items.0.quantity" should be "2"
+    And the response field "items.0.price_cents" should be "120"
+    And the response field "items.0.line_total_cents" should be "240"
+    And the response field "items.0.confidence" should be "0.9"
+    And the response field "items.1.quantity" should be "1"
+    And the total should be 490 cents
+
+  Scenario: The quantity shows on the session after checkout
+    Given an active session exists on device "QTY-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | QTY-YOGRT | 0.95       |
+      | QTY-YOGRT | 0.94       |
+      | QTY-YOGRT | 0.96       |
+    And I confirm the session with payment reference "PAY-QTY-1"
+    When I fetch the current session
+    Then the response status should be 200
+    And the response field "total_cents" should be "360"
+    And the response field "items.0.quantity" should be "3"
+    And the response field "items.0.line_total_cents" should be "360"
@@ -0,0 +1,101 @@
+@api @transaction
+Feature: Low-Confidence Item Review
+  As an attendant
+  I want the items the device is unsure about flagged one by one
+  So that I can accept or replace just those lines before the customer checks out
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "REVIEW-001"
+    And the following SKUs exist:
+      | code      | name         | price_cents | weight_grams |
+      | RVW-COLA  | Cola         | 150         | 350          |
+      | RVW-COLA0 | Cola Zero    | 160         | 350          |
+      | RVW-CHIPS | Potato Chips | 200         | 60           |
+
+  Scenario: Lines detected below the confidence threshold await review
+    Given an active session exists on device "REVIEW-001"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-COLA  | 0.95       |
+      | RVW-CHIPS | 0.6        |
+    Then the response status should be 200
+    And the response field "items.1.review" should be "pending"
+    And the response field "needs_cloud_ml" should be "true"
+    When I list the sessions awaiting review
+    Then the response status should be 200
+    And the review queue should list the current session
+
+  Scenario: An attendant accepts a line awaiting review
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-COLA  | 0.95       |
+      | RVW-CHIPS | 0.6        |
+    When attendant "attendant-7" accepts the "RVW-CHIPS" items of the current session
+    Then the response status should be 200
+    And the response field "items.1.review" should be "accepted"
+    And the response field "awaiting_review" should be "0"
+    When I list the sessions awaiting review
+    Then the review queue should not list the current session
+
+  Scenario: An accepted line stays accepted while no more units are detected
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.6        |
+    And attendant "attendant-7" accepts the "RVW-CHIPS" items of the current session
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.65       |
+    Then the response field "items.0.review" should be "accepted"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.65       |
+      | RVW-CHIPS | 0.6        |
+    Then the response field "items.0.review" should be "pending"
+
+  Scenario: An attendant replaces a line with what is actually in the basket
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.95       |
+      | RVW-COLA  | 0.55       |
+    When attendant "attendant-7" replaces the "RVW-COLA" items of the current session with "RVW-COLA0"
+    Then the response status should be 200
+    And the response field "items.1.code" should be "RVW-COLA0"
+    And the response field "items.1.review" should be "accepted"
+    And the response field "total_cents" should be "360"
+    When I list the detections of the current session
+    Then the response field "detections.1.source" should be "attendant"
+
+  @error-handling
+  Scenario: Only lines awaiting review can be accepted
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | RVW-COLA | 0.95       |
+    When attendant "attendant-7" accepts the "RVW-COLA" items of the current session
+    Then the response status should be 422
+    And the response should contain error "no item of this SKU awaits review"
+
+  @error-handling
+  Scenario: A review needs the acting attendant
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.6        |
+    When attendant "" accepts the "RVW-CHIPS" items of the current session
+    Then the response status should be 401
+    And the response should contain error "acting user is required"
+
+  @error-handling
+  Scenario: A line is only replaced with a SKU from the catalog
+    Given an active session exists on device "REVIEW-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | RVW-CHIPS | 0.6        |
+    When attendant "attendant-7" replaces the "RVW-CHIPS" items of the current session with "RVW-UNKNOWN"
+    Then the response status should be 422
+    And the response should contain error "replacement SKU is not in the catalog"
@@ -0,0 +1,81 @@
+@api @transaction
+Feature: Offline Sync
+  As a device that lost connectivity mid-session
+  I want to sync the detections and state changes I buffered offline
+  So that sessions end up in the state the customer actually left them in
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "SYNC-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 230         | 140          | 10               |
+
+  @smoke
+  Scenario: Offline detections are applied in the order they were recorded
+    Given an active session exists on device "SYNC-001"
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type      | recorded_minutes | skus                |
+      | det-2     | detection | -1               | APPLE-001,APPLE-002 |
+      | det-1     | detection | -3               | APPLE-001           |
+    Then the response status should be 200
+    And the sync result for "det-1" should be "applied"
+    And the sync result for "det-2" should be "applied"
+    When I fetch the current session
+    Then the total should be 480 cents
+
+  Scenario: Resent entries are not applied twice
+    Given an active session exists on device "SYNC-001"
+    And device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type      | recorded_minutes | skus      |
+      | det-1     | detection | -2               | APPLE-001 |
+    When the device resends the last offline batch
+    Then the response status should be 200
+    And the sync result for "det-1" should be "duplicate"
+    And the response should contain field "results"
+
+  Scenario: A buffered cancellation closes the session
+    Given an active session exists on device "SYNC-001"
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type   | recorded_minutes | skus |
+      | cancel-1  | cancel | -1               |      |
+    Then the sync result for "cancel-1" should be "applied"
+    When I fetch the current session
+    Then the response field "session.status" should be "cancelled"
+
+  @error-handling
+  Scenario: An older detection does not replace a newer one
+    Given an active session exists on device "SYNC-001"
+    And device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type      | recorded_minutes | skus                |
+      | det-2     | detection | -1               | APPLE-001,APPLE-002 |
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type      | recorded_minutes | skus      |
+      | det-1     | detection | -5               | APPLE-001 |
+    Then the sync result for "det-1" should be "conflict" with error "a newer detection was already applied"
+
+  @error-handling
+  Scenario: Entries recorded after the session expired conflict
+    Given an active session exists on device "SYNC-001"
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type      | recorded_minutes | skus      |
+      | det-late  | detection | 45               | APPLE-001 |
+    Then the sync result for "det-late" should be "conflict" with error "session has expired"
+
+  @error-handling
+  Scenario: A buffered cancellation conflicts with a completed purchase
+    Given a completed session exists on device "SYNC-001"
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type   | recorded_minutes | skus |
+      | cancel-1  | cancel | -1               |      |
+    Then the sync result for "cancel-1" should be "conflict" with error "session already completed"
+
+  @error-handling
+  Scenario: Unknown entry types are rejected
+    Given an active session exists on device "SYNC-001"
+    When device "SYNC-001" syncs the following offline entries for the current session:
+      | client_id | type     | recorded_minutes | skus |
+      | x-1       | teleport | -1               |      |
+    Then the sync result for "x-1" should be "rejected" with error "unknown offline entry type"
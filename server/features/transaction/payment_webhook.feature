@@ -0,0 +1,74 @@
+@api @transaction
+Feature: Payment Webhook
+  As the operator
+  I want the payment provider to report the outcome of card payments that settle later
+  So that sessions awaiting their payment complete, or return to the customer when it fails
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "PAYHOOK-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 230         | 140          | 10               |
+
+  Scenario: A card payment that settles later completes the session awaiting it
+    Given the payment provider is available
+    And an active session with items exists on device "PAYHOOK-001"
+    And the session's card payment is still processing
+    And I confirm the session
+    And the response status should be 202
+    And the response field "status" should be "awaiting_payment"
+    And the session's card payment is captured
+    When the payment provider reports that the session's payment succeeded
+    Then the response status should be 200
+    And the response field "applied" should be "true"
+    And the response field "status" should be "completed"
+    When I fetch the current session
+    Then the response field "session.status" should be "completed"
+
+  Scenario: A failed card payment returns the session awaiting it to the customer
+    Given the payment provider is available
+    And an active session with items exists on device "PAYHOOK-001"
+    And the session's card payment is still processing
+    And I confirm the session
+    And the response status should be 202
+    And the session's card payment is declined
+    When the payment provider reports that the session's payment failed
+    Then the response status should be 200
+    And the response field "applied" should be "true"
+    And the response field "status" should be "active"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  @error-handling
+  Scenario: Events not signed with the webhook secret are refused
+    Given an active session with items exists on device "PAYHOOK-001"
+    When the payment provider reports that the session's payment succeeded with the wrong secret
+    Then the response status should be 401
+    And the response should contain error "invalid webhook signature"
+
+  Scenario: A payment outcome for a session not awaiting its payment is acknowledged and ignored
+    Given an active session with items exists on device "PAYHOOK-001"
+    When the payment provider reports that the session's payment succeeded
+    Then the response status should be 200
+    And the response field "applied" should be "false"
+    And the response field "status" should be "active"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  Scenario: A failed payment for a session not awaiting its payment leaves it untouched
+    Given an active session with items exists on device "PAYHOOK-001"
+    When the payment provider reports that the session's payment failed
+    Then the response status should be 200
+    And the response field "applied" should be "false"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  Scenario: Event types the server does not act on are acknowledged
+    Given an active session exists on device "PAYHOOK-001"
+    When the payment provider sends a "charge.refunded" event for the session
+    Then the response status should be 200
+    And the response field "received" should be "true"
+    And the response field "applied" should be "false"
@@ -0,0 +1,47 @@
+@api @transaction
+Feature: Purchase History
+  As a customer using the mobile app
+  I want to see what I bought and what I paid
+  So that I can keep track of my purchases
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "PH-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+
+  Scenario: A user's completed sessions are listed with their items and totals
+    Given user "user-42" completed a purchase on device "PH-001"
+    And user "user-7" completed a purchase on device "PH-001"
+    When I request the purchase history of user "user-42"
+    Then the response status should be 200
+    And the response field "user_id" should be "user-42"
+    And the response field "sessions.0.items.0.code" should be "APPLE-001"
+    And the response field "sessions.0.items.1.name" should be "Gala Apple"
+    And the response field "sessions.0.total_cents" should be "480"
+    And the response should not contain field "next_cursor"
+
+  Scenario: Open and cancelled sessions are not purchases
+    Given user "user-42" completed a purchase on device "PH-001"
+    And user "user-42" starts a session on device "PH-001"
+    When I request the purchase history of user "user-42"
+    Then the response status should be 200
+    And the session history should list 1 session ""
+
+  Scenario: The purchase history is paged
+    Given user "user-42" completed a purchase on device "PH-001"
+    And user "user-42" completed a purchase on device "PH-001"
+    When I request the purchase history of user "user-42" 1 at a time
+    Then the response should contain field "next_cursor"
+    When I request the next page of sessions
+    Then the response status should be 200
+    And the response should contain field "sessions"
+    And the response should not contain field "next_cursor"
+
+  Scenario: A user without purchases has an empty history
+    When I request the purchase history of user "nobody"
+    Then the response status should be 200
+    And the session history should list 0 sessions ""
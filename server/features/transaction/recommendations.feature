@@ -0,0 +1,27 @@
+@api @transaction
+Feature: Product Recommendations
+  As the operator
+  I want the customer app to suggest products often bought together
+  So that customers find what else they might want from the fridge
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "RECO-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 230         | 140          | 10               |
+      | BANANA-01 | Banana      | 180         | 120          | 8                |
+
+  Scenario: Recommendations suggest SKUs not already in the basket
+    Given an active session with items exists on device "RECO-001"
+    When I request recommendations for the session
+    Then the response status should be 200
+    And the response should contain field "recommendations"
+
+  @error-handling
+  Scenario: Recommendations for an unknown session
+    When I send a GET request to "/api/v1/sessions/00000000-0000-0000-0000-000000000000/recommendations"
+    Then the response status should be 404
+    And the response should contain error "session not found"
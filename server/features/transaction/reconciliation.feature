@@ -0,0 +1,44 @@
+@api @transaction
+Feature: Session Reconciliation
+  As an operator
+  I want completed sessions re-checked against the current cloud model
+  So that I can quantify revenue lost to detection errors and fraud
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Reconciling a day without sessions produces an empty report
+    When I rerun the session reconciliation for "2020-01-01"
+    Then the response status should be 201
+    And the response field "day" should be "2020-01-01"
+    And the response should contain field "sessions_checked" with value "0"
+    When I send a GET request to "/api/v1/reconciliation/reports/2020-01-01"
+    Then the response status should be 200
+    And the response should contain field "lines"
+    And the response should contain field "discrepancies"
+
+  Scenario: Devices can attach the session image to a detection
+    Given a device exists with machine ID "RECON-001"
+    And a SKU exists with code "APPLE-001"
+    And an active session exists on device "RECON-001"
+    When I send a detection with an image for the current session on device "RECON-001"
+    Then the response status should be 200
+
+  @error-handling
+  Scenario: The current day cannot be reconciled yet
+    When I run the session reconciliation for "today"
+    Then the response status should be 422
+    And the response should contain error "day has not ended yet"
+
+  @error-handling
+  Scenario: Reconciliation days must be calendar dates
+    When I run the session reconciliation for "yesterday"
+    Then the response status should be 400
+    And the response should contain error "day must be formatted as YYYY-MM-DD"
+
+  @error-handling
+  Scenario: Unknown reconciliation report
+    When I send a GET request to "/api/v1/reconciliation/reports/1999-01-01"
+    Then the response status should be 404
+    And the response should contain error "reconciliation report not found"
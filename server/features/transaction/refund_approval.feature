@@ -0,0 +1,95 @@
+@api @transaction
+Feature: Refund Approval
+  As the operator's finance team
+  I want refunds above a threshold to wait for a finance approval
+  So that large refunds are not paid out on one person's say-so
+
+  # The test server approves refunds adding up to at most 2000 cents on its
+  # own; the completed session costs 2800 cents
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "REFUND-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 1500        | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 1300        | 140          | 10               |
+    And a completed session exists on device "REFUND-001"
+
+  Scenario: A refund below the threshold is approved right away
+    When support agent "agent-7" requests a refund of 500 cents for the session
+    Then the response status should be 201
+    And the response field "status" should be "approved"
+    And the pending refunds should not include the refund
+
+  Scenario: A refund above the threshold waits for finance
+    When support agent "agent-7" requests a refund of 2500 cents for the session
+    Then the response status should be 201
+    And the response field "status" should be "pending_approval"
+    And the pending refunds should include the refund
+
+  Scenario: Finance approves a pending refund
+    Given support agent "agent-7" requests a refund of 2500 cents for the session
+    When staff member "finance-1" with roles "finance" approves the refund
+    Then the response status should be 200
+    And the response field "status" should be "approved"
+    And the pending refunds should not include the refund
+
+  Scenario: Finance rejects a pending refund
+    Given support agent "agent-7" requests a refund of 2500 cents for the session
+    When staff member "finance-1" with roles "finance" rejects the refund
+    Then the response status should be 200
+    And the response field "status" should be "rejected"
+    And the pending refunds should not include the refund
+
+  Scenario: A rejected refund leaves the amount to be refunded again
+    Given support agent "agent-7" requests a refund of 2500 cents for the session
+    And staff member "finance-1" with roles "finance" rejects the refund
+    When support agent "agent-7" requests a refund of 2500 cents for the session
+    Then the response status should be 201
+    And the response field "status" should be "pending_approval"
+
+  Scenario: Requests split below the threshold still need approval once they add up past it
+    Given support agent "agent-7" requests a refund of 1500 cents for the session
+    And the response field "status" should be "approved"
+    When support agent "agent-7" requests a refund of 1000 cents for the session
+    Then the response status should be 201
+    And the response field "status" should be "pending_approval"
+    And the pending refunds should include the refund
+
+  @error-handling
+  Scenario: Refunds cannot add up to more than was charged
+    Given support agent "agent-7" requests a refund of 2000 cents for the session
+    When support agent "agent-7" requests a refund of 1000 cents for the session
+    Then the response status should be 422
+    And the response should contain error "refund exceeds remaining session total"
+
+  @error-handling
+  Scenario: Only finance may approve a refund
+    Given support agent "agent-7" requests a refund of 2500 cents for the session
+    When staff member "agent-9" with roles "support" approves the refund
+    Then the response status should be 403
+    And the response should contain error "approver lacks the finance role"
+    And the pending refunds should include the refund
+
+  @error-handling
+  Scenario: Finance cannot approve its own refund request
+    Given staff member "finance-1" with roles "support,finance" requests a refund of 2500 cents for the session
+    When staff member "finance-1" with roles "finance" approves the refund
+    Then the response status should be 403
+    And the response should contain error "refunds cannot be approved by their requester"
+
+  @error-handling
+  Scenario: A decided refund cannot be decided again
+    Given support agent "agent-7" requests a refund of 2500 cents for the session
+    And staff member "finance-1" with roles "finance" approves the refund
+    When staff member "finance-2" with roles "finance" rejects the refund
+    Then the response status should be 422
+    And the response should contain error "refund is not pending approval"
+
+  @error-handling
+  Scenario: Refund requests must identify the requesting user
+    When I request a refund of 100 cents for the session
+    Then the response status should be 401
+    And the response should contain error "acting user is required"
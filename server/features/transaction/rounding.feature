@@ -0,0 +1,61 @@
+@api @transaction
+Feature: Cash Rounding
+  As an operator in a country with cash rounding
+  I want session totals and refunds rounded to the smallest payable amount
+  So that receipts match what the customer pays, with the difference shown
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "ROUND-001"
+    And the following SKUs exist:
+      | code       | name           | price_cents | weight_grams | currency |
+      | CHOC-CH-01 | Milk Chocolate | 248         | 100          | CHF      |
+      | WATER-CH-1 | Mineral Water  | 199         | 500          | CHF      |
+
+  @smoke
+  Scenario: Totals are rounded to 5 rappen
+    Given an active session exists on device "ROUND-001"
+    When I submit the following detections to the session:
+      | sku        | confidence |
+      | CHOC-CH-01 | 0.95       |
+      | WATER-CH-1 | 0.93       |
+    Then the response status should be 200
+    And the total should be 445 cents
+    And the response field "subtotal_cents" should be "447"
+    And the response field "rounding_cents" should be "-2"
+
+  Scenario: The receipt shows the rounding difference
+    Given an active session exists on device "ROUND-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | CHOC-CH-01 | 0.95       |
+    When I confirm the session with payment reference "PAY-ROUND-1"
+    Then the response status should be 200
+    And the total should be 250 cents
+    And the response field "rounding_cents" should be "2"
+    When I fetch the current session
+    Then the response field "subtotal_cents" should be "248"
+    And the response field "rounding_cents" should be "2"
+
+  Scenario: Refunds are rounded like the total
+    Given an active session exists on device "ROUND-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | CHOC-CH-01 | 0.95       |
+    And I confirm the session with payment reference "PAY-ROUND-2"
+    When support agent "agent-7" requests a refund of 123 cents for the session
+    Then the response status should be 201
+    And the response field "amount_cents" should be "125"
+    And the response field "rounding_cents" should be "2"
+
+  @error-handling
+  Scenario: A rounded refund may not exceed the rounded total
+    Given an active session exists on device "ROUND-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | CHOC-CH-01 | 0.95       |
+    And I confirm the session with payment reference "PAY-ROUND-3"
+    When support agent "agent-7" requests a refund of 253 cents for the session
+    Then the response status should be 422
+    And the response should contain error "exceeds"
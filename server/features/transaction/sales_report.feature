@@ -0,0 +1,52 @@
+@api @transaction
+Feature: Sales Report
+  As the operator
+  I want revenue, transaction counts and basket sizes aggregated by the server
+  So that we stop exporting the database into spreadsheets
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "SALES-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+    And a completed session exists on device "SALES-001"
+    And a completed session exists on device "SALES-001"
+    And I start a session on device "SALES-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I confirm the session with payment reference "PAY-SALES-3"
+
+  Scenario: Sales are aggregated per device
+    When I request the sales report grouped by "device"
+    Then the response status should be 200
+    And the response field "group_by" should be "device"
+    And the sales report line for "SALES-001" should show 3 transactions, 5 units and 1210 cents of revenue
+    And the sales report line for "SALES-001" should average 403 cents and 1.67 units per basket
+
+  Scenario: Sales are aggregated per day by default
+    When I send a GET request to "/api/v1/reports/sales"
+    Then the response status should be 200
+    And the response field "group_by" should be "day"
+    And the sales report should have a line for "today"
+
+  Scenario: Sales are aggregated per SKU
+    When I request the sales report grouped by "sku"
+    Then the response status should be 200
+    And the sales report should have a line for "APPLE-001"
+    And the sales report should have a line for "APPLE-002"
+
+  @error-handling
+  Scenario: An unknown grouping is rejected
+    When I request the sales report grouped by "week"
+    Then the response status should be 400
+    And the response should contain error "group_by must be day, device or sku"
+
+  @error-handling
+  Scenario: A period has to end after it starts
+    When I send a GET request to "/api/v1/reports/sales?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z"
+    Then the response status should be 400
+    And the response should contain error "from must be before to"
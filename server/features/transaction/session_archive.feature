@@ -0,0 +1,56 @@
+@api @transaction
+Feature: Session Archival
+  As an operator
+  I want sessions that ended long ago moved out of the hot sessions table
+  So that it stays small while old sessions can still be looked up
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "ARC-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+
+  Scenario: An archived session stays in the history and is still found by ID
+    Given a completed session exists on device "ARC-001"
+    When the session archival runs for sessions older than 0 days
+    Then the response status should be 200
+    And the response field "after_days" should be "0"
+    And the response field "count" should be "1"
+    When I list the sessions of device "ARC-001"
+    Then the session history should list 1 session "completed"
+    When I fetch the current session
+    Then the response status should be 200
+    And the response field "session.status" should be "completed"
+
+  Scenario: Support can still work an archived session
+    Given a completed session exists on device "ARC-001"
+    And the session archival runs for sessions older than 0 days
+    When support agent "agent-7" notes "customer called about an old purchase" on the session
+    Then the response status should be 201
+    When I fetch the current session
+    Then the response field "notes.0.note" should be "customer called about an old purchase"
+
+  Scenario: Active sessions are never archived
+    Given an active session exists on device "ARC-001"
+    When the session archival runs for sessions older than 0 days
+    Then the response status should be 200
+    And the response field "count" should be "0"
+    When I list the sessions of device "ARC-001"
+    Then the session history should list 1 session "active"
+
+  Scenario: Recent sessions stay until they are old enough
+    Given a completed session exists on device "ARC-001"
+    When the session archival runs
+    Then the response status should be 200
+    And the response field "after_days" should be "90"
+    And the response field "count" should be "0"
+    When I list the sessions of device "ARC-001"
+    Then the session history should list 1 session "completed"
+
+  @error-handling
+  Scenario: The archival age cannot be negative
+    When the session archival runs for sessions older than -1 days
+    Then the response status should be 400
+    And the response should contain error "after_days must be a non-negative number"
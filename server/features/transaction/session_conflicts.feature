@@ -0,0 +1,47 @@
+@api @transaction
+Feature: Session Conflicts
+  As a device or customer app
+  I want a change to a session that another request changed meanwhile refused
+  So that neither change is lost and the request can be sent again
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "RACE-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 230         | 140          | 10               |
+
+  Scenario: A detection saved over a newer session is refused and can be sent again
+    Given an active session exists on device "RACE-001"
+    And another request saves the session first
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 409
+    And the response should contain field "code" with value "session_conflict"
+    When I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    Then the response status should be 200
+    And the total should be 250 cents
+
+  Scenario: A confirmation saved over a newer session is refused and can be sent again
+    Given an active session with items exists on device "RACE-001"
+    And another request saves the session first
+    When I confirm the session with payment reference "PAY-RACE-1"
+    Then the response status should be 409
+    And the response should contain field "code" with value "session_conflict"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+    When I confirm the session with payment reference "PAY-RACE-1"
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+
+  Scenario: A cancellation saved over a newer session is refused
+    Given an active session exists on device "RACE-001"
+    And another request saves the session first
+    When I cancel the session with reason "changed my mind"
+    Then the response status should be 409
+    And the response should contain field "code" with value "session_conflict"
@@ -0,0 +1,80 @@
+@api @transaction
+Feature: Session Expiry
+  As an operator
+  I want to set how long sessions last on each device and let customers ask for more time
+  So that a slow shopper is not cut off while an abandoned session does not linger
+
+  Background:
+    Given the API server is running
+    And the database is clean
+
+  Scenario: Sessions last the deployment's default expiration
+    Given a device exists with machine ID "EXPIRY-DEV-001"
+    When I start a session on device "EXPIRY-DEV-001"
+    Then the response status should be 201
+    And the session should expire 30 minutes after it started
+
+  Scenario: A device sets how long its sessions last
+    Given a device exists with machine ID "EXPIRY-DEV-002"
+    And I set the following policy overrides for device "EXPIRY-DEV-002":
+      | session_expiration_minutes |
+      | 10                         |
+    When I start a session on device "EXPIRY-DEV-002"
+    Then the response status should be 201
+    And the session should expire 10 minutes after it started
+
+  Scenario: Devices follow the session expiration of their group
+    Given a device exists with machine ID "EXPIRY-DEV-003"
+    And I create the device group "Express Kiosks"
+    And I add the devices "EXPIRY-DEV-003" to the group "Express Kiosks"
+    And I set the following policy for the group "Express Kiosks":
+      | session_expiration_minutes |
+      | 15                         |
+    When I request the policy of device "EXPIRY-DEV-003"
+    Then the response field "session_expiration_minutes" should be "15"
+    And the response field "session_expiration_minutes_source" should be "group"
+    When I start a session on device "EXPIRY-DEV-003"
+    Then the session should expire 15 minutes after it started
+
+  Scenario: A session expiration has to be between a minute and a day
+    Given a device exists with machine ID "EXPIRY-DEV-004"
+    When I set the following policy overrides for device "EXPIRY-DEV-004":
+      | session_expiration_minutes |
+      | 0                          |
+    Then the response status should be 422
+    And the response should contain error "session expiration must be between 1 minute and 24 hours"
+
+  Scenario: A customer extends a session
+    Given an active session exists on device "EXPIRY-DEV-005"
+    When I extend the session by 20 minutes
+    Then the response status should be 200
+    And the response should contain field "expires_at"
+    And the session should expire 50 minutes after it started
+
+  Scenario: A session is extended by its device's expiration when no time is asked for
+    Given a device exists with machine ID "EXPIRY-DEV-006"
+    And I set the following policy overrides for device "EXPIRY-DEV-006":
+      | session_expiration_minutes |
+      | 10                         |
+    And I start a session on device "EXPIRY-DEV-006"
+    When I extend the session
+    Then the response status should be 200
+    And the session should expire 20 minutes after it started
+
+  Scenario: A session is never extended past its maximum duration
+    Given an active session exists on device "EXPIRY-DEV-007"
+    When I extend the session by 60 minutes
+    And I extend the session by 60 minutes
+    Then the response status should be 200
+    And the session should expire 120 minutes after it started
+    When I extend the session by 5 minutes
+    Then the response status should be 422
+    And the response field "code" should be "session_extension_limit"
+    And the session should expire 120 minutes after it started
+
+  Scenario: Cancelled sessions cannot be extended
+    Given an active session exists on device "EXPIRY-DEV-008"
+    And I cancel the session with reason "changed my mind"
+    When I extend the session by 10 minutes
+    Then the response status should be 422
+    And the response should contain error "session is not active"
@@ -0,0 +1,69 @@
+@api @transaction
+Feature: Session History
+  As an operator or support agent
+  I want to page through past sessions by device, status and time
+  So that I can answer customer questions and watch the fleet's sales
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "HIST-001"
+    And a device exists with machine ID "HIST-002"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+
+  Scenario: A device's sessions are listed newest first
+    Given a completed session exists on device "HIST-001"
+    And an active session exists on device "HIST-001"
+    And an active session exists on device "HIST-002"
+    When I list the sessions of device "HIST-001"
+    Then the response status should be 200
+    And the session history should list 2 sessions "active,completed"
+    And the response field "sessions.1.items" should be "2"
+    And the response should not contain field "next_cursor"
+
+  Scenario: Sessions are filtered by status
+    Given a completed session exists on device "HIST-001"
+    And an active session exists on device "HIST-002"
+    When I send a GET request to "/api/v1/sessions?status=completed"
+    Then the response status should be 200
+    And the session history should list 1 session "completed"
+    When I send a GET request to "/api/v1/sessions?status=cancelled"
+    Then the session history should list 0 sessions ""
+
+  Scenario: Sessions are filtered by when they started
+    Given a completed session exists on device "HIST-001"
+    When I send a GET request to "/api/v1/sessions?from=2020-01-01T00:00:00Z"
+    Then the session history should list 1 session "completed"
+    When I send a GET request to "/api/v1/sessions?to=2020-01-01T00:00:00Z"
+    Then the session history should list 0 sessions ""
+
+  Scenario: The history is paged with a cursor
+    Given an active session exists on device "HIST-001"
+    And I cancel the session with reason "changed mind"
+    And an active session exists on device "HIST-001"
+    And I cancel the session with reason "changed mind"
+    And an active session exists on device "HIST-001"
+    When I list the sessions of device "HIST-001" 2 at a time
+    Then the response status should be 200
+    And the session history should list 2 sessions "active,cancelled"
+    And the response should contain field "next_cursor"
+    When I request the next page of sessions
+    Then the response status should be 200
+    And the session history should list 1 session "cancelled"
+    And the response should not contain field "next_cursor"
+
+  Scenario: Invalid filters are rejected
+    When I send a GET request to "/api/v1/sessions?status=broken"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/sessions?from=yesterday"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/sessions?limit=500"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/sessions?device_id=HIST-001"
+    Then the response status should be 400
+    When I send a GET request to "/api/v1/sessions?cursor=not-a-cursor"
+    Then the response status should be 400
+    And the response should contain error "invalid cursor"
@@ -0,0 +1,54 @@
+@api @transaction
+Feature: Session Notes
+  As a support agent investigating a customer complaint
+  I want to attach notes to the session
+  So that whoever picks up the complaint next sees what was found
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "NOTE-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+    And a completed session exists on device "NOTE-001"
+
+  Scenario: A note is shown on the session detail
+    When support agent "agent-7" notes "customer says two apples were charged, camera shows one" on the session
+    Then the response status should be 201
+    And the response field "author_id" should be "agent-7"
+    When I fetch the current session
+    Then the response status should be 200
+    And the response field "notes.0.note" should be "customer says two apples were charged, camera shows one"
+    And the response field "notes.0.author_id" should be "agent-7"
+
+  Scenario: Notes are listed oldest first
+    Given support agent "agent-7" notes "called the customer" on the session
+    And support agent "agent-9" notes "refund offered" on the session
+    When I fetch the current session
+    Then the response field "notes.0.note" should be "called the customer"
+    And the response field "notes.1.note" should be "refund offered"
+    And the response field "notes.1.author_id" should be "agent-9"
+
+  Scenario: A session without notes has none listed
+    When I fetch the current session
+    Then the response status should be 200
+    And the response should not contain field "notes"
+
+  @error-handling
+  Scenario: A note needs an acting user
+    When support agent "" notes "anonymous note" on the session
+    Then the response status should be 401
+    And the response should contain error "acting user is required"
+
+  @error-handling
+  Scenario: A blank note is rejected
+    When support agent "agent-7" notes "   " on the session
+    Then the response status should be 400
+    And the response should contain error "a session note cannot be empty"
+
+  @error-handling
+  Scenario: Notes need an existing session
+    When support agent "agent-7" notes "wrong session" on session "00000000-0000-0000-0000-000000000000"
+    Then the response status should be 404
+    And the response should contain error "session not found"
@@ -0,0 +1,61 @@
+@api @transaction
+Feature: Session Resumption
+  As a customer whose app lost connectivity
+  I want to find the session I already have open on the device
+  So that I carry on with my basket instead of starting a duplicate session
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "RESUME-001"
+    And the following SKUs exist:
+      | code     | name | price_cents | weight_grams |
+      | RSM-COLA | Cola | 150         | 350          |
+
+  Scenario: A reconnecting app resumes its active session
+    Given user "resume-user-1" starts a session on device "RESUME-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | RSM-COLA | 0.95       |
+    When user "resume-user-1" resumes the active session on device "RESUME-001"
+    Then the response status should be 200
+    And the resumed session should be the current session
+    And the response field "session.status" should be "active"
+    And the response field "total_cents" should be "150"
+
+  Scenario: The resumed session can be followed with the stream token handed back
+    Given user "resume-user-1" starts a session on device "RESUME-001"
+    And user "resume-user-1" resumes the active session on device "RESUME-001"
+    And I cancel the session with reason "customer walked away"
+    When I open the event stream of the current session
+    Then the response status should be 200
+    And the stream should contain a "session" event
+    And the stream should end with a "cancelled" event
+
+  @error-handling
+  Scenario: Another user's session is not resumed
+    Given user "resume-user-1" starts a session on device "RESUME-001"
+    When user "resume-user-2" resumes the active session on device "RESUME-001"
+    Then the response status should be 404
+    And the response should contain error "no active session"
+
+  @error-handling
+  Scenario: There is nothing to resume once the session is cancelled
+    Given user "resume-user-1" starts a session on device "RESUME-001"
+    And I cancel the session with reason "changed my mind"
+    When user "resume-user-1" resumes the active session on device "RESUME-001"
+    Then the response status should be 404
+    And the response should contain error "no active session"
+
+  @error-handling
+  Scenario: Resuming needs the user
+    Given user "resume-user-1" starts a session on device "RESUME-001"
+    When user "" resumes the active session on device "RESUME-001"
+    Then the response status should be 400
+    And the response should contain error "machine_id and user_id are required"
+
+  @error-handling
+  Scenario: Resuming on an unknown device
+    When user "resume-user-1" resumes the active session on device "RESUME-UNKNOWN"
+    Then the response status should be 404
+    And the response should contain error "device not found"
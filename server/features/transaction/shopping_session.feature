@@ -21,6 +21,7 @@ Feature: Shopping Session
     And the response should contain field "session_id"
     And the response should contain field "device_id"
     And the response should contain field "expires_at"
+    And the response should contain field "stream_token"
     And the response should contain field "message" with value "session started, place items on scale"
 
   Scenario: Submit item detections to session
@@ -48,6 +49,35 @@ Feature: Shopping Session
     And the response field "status" should be "completed"
     And the response should contain field "message" with value "purchase confirmed"
 
+  Scenario: Paying by card opens a payment intent for the session total
+    Given the payment provider is available
+    And user "card-user" starts a session on device "DEVICE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+      | BANANA-01 | 0.93       |
+    When I pay for the session by card
+    Then the response status should be 200
+    And the response should contain field "payment_intent_id"
+    And the response should contain field "client_secret"
+    And the response field "amount_cents" should be "430"
+    And the session's payment intent should be for 430 cents
+
+  Scenario: Confirm a session paid by card
+    Given the payment provider is available
+    And user "card-user" starts a session on device "DEVICE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I pay for the session by card
+    And the customer pays the session's payment intent by card
+    When I confirm the session
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+    And the response field "total_cents" should be "250"
+    And the response field "payment_method.brand" should be "visa"
+    And the response field "payment_method.last4" should be "4242"
+
   Scenario: Cancel an active session
     Given an active session exists on device "DEVICE-001"
     When I cancel the session with reason "customer changed mind"
@@ -55,6 +85,42 @@ Feature: Shopping Session
     And the response field "status" should be "cancelled"
     And the response should contain field "message" with value "session cancelled"
 
+  Scenario: Live stream pushes the final session state
+    Given an active session with items exists on device "DEVICE-001"
+    And I confirm the session with payment reference "PAY-STREAM-1"
+    When I open the live stream of the current session
+    Then the response status should be 200
+    And the stream should end with a "final" event
+
+  Scenario: Session events end with the session confirmed
+    Given an active session with items exists on device "DEVICE-001"
+    And I confirm the session with payment reference "PAY-EVENTS-1"
+    When I open the event stream of the current session
+    Then the response status should be 200
+    And the stream should contain a "session" event
+    And the stream should end with a "confirmed" event
+
+  Scenario: Session events end with the session cancelled
+    Given an active session with items exists on device "DEVICE-001"
+    And I cancel the session with reason "changed my mind"
+    When I open the event stream of the current session
+    Then the response status should be 200
+    And the stream should end with a "cancelled" event
+
+  @error-handling
+  Scenario: Session events require the session's stream token
+    Given an active session exists on device "DEVICE-001"
+    When I open the event stream of the current session with token "forged-token"
+    Then the response status should be 401
+    And the response should contain error "invalid or expired session token"
+
+  @error-handling
+  Scenario: Live stream requires the session's stream token
+    Given an active session exists on device "DEVICE-001"
+    When I open the live stream of the current session with token "forged-token"
+    Then the response status should be 401
+    And the response should contain error "invalid or expired session token"
+
   @error-handling
   Scenario: Cannot start session on non-existent device
     When I start a session on device "NONEXISTENT"
@@ -89,3 +155,44 @@ Feature: Shopping Session
     When I cancel the session with reason "test"
     Then the response status should be 422
     And the response should contain error "session already completed"
+
+  @error-handling
+  Scenario: Card payments are unavailable without a payment provider
+    Given an active session with items exists on device "DEVICE-001"
+    When I pay for the session by card
+    Then the response status should be 503
+    And the response should contain error "card payments are not available"
+
+  @error-handling
+  Scenario: A session without items cannot be paid for
+    Given an active session exists on device "DEVICE-001"
+    When I pay for the session by card
+    Then the response status should be 422
+    And the response should contain error "no items detected"
+
+  @error-handling
+  Scenario: A session paid by card is not confirmed before the payment is captured
+    Given the payment provider is available
+    And user "card-user" starts a session on device "DEVICE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I pay for the session by card
+    When I confirm the session
+    Then the response status should be 402
+    And the response should contain error "payment has not been captured"
+
+  @error-handling
+  Scenario: A session paid by card is not confirmed with a payment for another amount
+    Given the payment provider is available
+    And user "card-user" starts a session on device "DEVICE-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | APPLE-001 | 0.95       |
+    And I pay for the session by card
+    And the customer pays 100 cents for the session by card
+    When I confirm the session
+    Then the response status should be 402
+    And the response should contain error "payment has not been captured"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
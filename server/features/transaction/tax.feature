@@ -0,0 +1,52 @@
+@api @transaction
+Feature: Tax at Checkout
+  As an operator
+  I want tax charged by the rules for each SKU category and device region
+  So that receipts show the tax due alongside the subtotal and total
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "TAX-001"
+    And the following SKUs exist:
+      | code       | name         | price_cents | weight_grams |
+      | TAX-SNACK1 | Salted Nuts  | 250         | 80           |
+      | TAX-WATER1 | Still Water  | 120         | 500          |
+    And the taxed category "Snacks" exists
+    And I assign the SKU "TAX-SNACK1" to the category "Snacks"
+
+  @smoke
+  Scenario: Confirming a session returns the tax lines
+    Given an active session exists on device "TAX-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | TAX-SNACK1 | 0.95       |
+      | TAX-WATER1 | 0.93       |
+    When I confirm the session with payment reference "PAY-TAX-1"
+    Then the response status should be 200
+    And the response field "subtotal_cents" should be "370"
+    And the response field "tax_cents" should be "25"
+    And the total should be 395 cents
+    And the response field "tax_lines.0.rate_percent" should be "10"
+    And the response field "tax_lines.0.taxable_cents" should be "250"
+
+  Scenario: Untaxed baskets have no tax lines
+    Given an active session exists on device "TAX-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | TAX-WATER1 | 0.93       |
+    When I confirm the session with payment reference "PAY-TAX-2"
+    Then the response status should be 200
+    And the response field "tax_cents" should be "0"
+    And the total should be 120 cents
+
+  Scenario: The session detail keeps the tax after checkout
+    Given an active session exists on device "TAX-001"
+    And I submit the following detections to the session:
+      | sku        | confidence |
+      | TAX-SNACK1 | 0.95       |
+    And I confirm the session with payment reference "PAY-TAX-3"
+    When I fetch the current session
+    Then the response field "subtotal_cents" should be "250"
+    And the response field "tax_cents" should be "25"
+    And the response field "total_cents" should be "275"
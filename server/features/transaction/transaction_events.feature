@@ -0,0 +1,33 @@
+@api @transaction
+Feature: Transaction Event Topic
+  As a downstream service such as accounting or analytics
+  I want every recorded sale published on a topic
+  So that I never miss a sale the server has committed and never see one it has not
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "TXE-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+    And a subscriber has read the "transaction" topic to its end
+
+  Scenario: Confirming a session publishes its sale
+    Given an active session with items exists on device "TXE-001"
+    When I confirm the session with payment reference "PAY-TXE-1"
+    And the subscriber reads the "transaction" topic
+    Then the response status should be 200
+    And the response field "topic" should be "transaction"
+    And the response field "messages.0.type" should be "transaction.sale.recorded"
+    And the response field "messages.0.version" should be "1"
+    And the response field "messages.0.payload.payment_ref" should be "PAY-TXE-1"
+    And the response field "messages.0.payload.units" should be "2"
+
+  @error-handling
+  Scenario: A session that fails to confirm publishes nothing
+    Given an active session exists on device "TXE-001"
+    When I confirm the session with payment reference "PAY-TXE-2"
+    And the subscriber reads the "transaction" topic
+    Then the response field "messages" should be "[]"
@@ -0,0 +1,33 @@
+@api @transaction
+Feature: Transactions
+  As the operator
+  I want every confirmed session to record an immutable transaction
+  So that refunds and sales reporting rely on what was actually charged
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "TXN-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+
+  Scenario: Confirming a session records its transaction
+    Given an active session with items exists on device "TXN-001"
+    When I confirm the session with payment reference "PAY-TXN-1"
+    Then the response status should be 200
+    And the response should contain field "transaction_id"
+
+  Scenario: Refunds are requested against the session's transaction
+    Given a completed session exists on device "TXN-001"
+    When support agent "agent-7" requests a refund of 50 cents for the session
+    Then the response status should be 201
+    And the response should contain field "transaction_id"
+
+  @error-handling
+  Scenario: A session without a transaction cannot be refunded
+    Given an active session with items exists on device "TXN-001"
+    When support agent "agent-7" requests a refund of 50 cents for the session
+    Then the response status should be 422
+    And the response should contain error "only completed sessions can be refunded"
@@ -0,0 +1,50 @@
+@api @transaction
+Feature: Unrecognized Detected Items
+  As the catalog team
+  I want to see the codes devices detect that the catalog does not sell
+  So that I can decide which products to add
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "UNREC-001"
+    And the following SKUs exist:
+      | code      | name | price_cents | weight_grams |
+      | UNR-COLA  | Cola | 150         | 350          |
+
+  Scenario: Codes outside the catalog are listed instead of dropped silently
+    Given an active session exists on device "UNREC-001"
+    When I submit the following detections to the session:
+      | sku        | confidence | bbox            |
+      | UNR-COLA   | 0.95       |                 |
+      | UNR-KOMBU  | 0.88       | 0.1,0.2,0.3,0.4 |
+    Then the response status should be 200
+    And the response should contain 1 items
+    And the response field "needs_cloud_ml" should be "true"
+    And the response field "unrecognized_items.0.code" should be "UNR-KOMBU"
+    And the response field "unrecognized_items.0.confidence" should be "0.88"
+    And the response field "unrecognized_items.0.bbox" should be "[0.1 0.2 0.3 0.4]"
+
+  Scenario: The session keeps the most confident sighting of each code
+    Given an active session exists on device "UNREC-001"
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | UNR-KOMBU | 0.7        |
+      | UNR-MATE  | 0.9        |
+    And I submit the following detections to the session:
+      | sku       | confidence |
+      | UNR-COLA  | 0.95       |
+      | UNR-KOMBU | 0.85       |
+    When I fetch the current session
+    Then the response status should be 200
+    And the response field "unrecognized_items.0.code" should be "UNR-KOMBU"
+    And the response field "unrecognized_items.0.confidence" should be "0.85"
+    And the response field "unrecognized_items.1.code" should be "UNR-MATE"
+
+  Scenario: A basket of catalog items lists nothing unrecognized
+    Given an active session exists on device "UNREC-001"
+    When I submit the following detections to the session:
+      | sku      | confidence |
+      | UNR-COLA | 0.95       |
+    Then the response status should be 200
+    And the response should not contain field "unrecognized_items"
@@ -0,0 +1,90 @@
+@api @transaction
+Feature: Walkaway Cancellation
+  As an operator
+  I want sessions cancelled when the customer leaves with items and does not pay
+  So that abandoned baskets do not stay open and walkaways reach fraud analytics
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "WALKAWAY-001"
+    And the following SKUs exist:
+      | code     | name         | price_cents | weight_grams |
+      | WLK-COLA | Cola         | 150         | 350          |
+      | WLK-CHIP | Potato Chips | 200         | 60           |
+
+  Scenario: An unpaid basket is cancelled once the grace has passed
+    Given an active session exists on device "WALKAWAY-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | WLK-COLA | 0.95       |
+      | WLK-CHIP | 0.95       |
+    When device "WALKAWAY-001" signals "door_closed" for the current session
+    Then the response status should be 200
+    And the response field "signal" should be "door_closed"
+    When the walkaway sweep runs with a grace of 0 seconds
+    Then the response status should be 200
+    And the walkaway sweep should have cancelled the current session
+    When I fetch the current session
+    Then the response field "session.status" should be "cancelled"
+
+  Scenario: The customer has until the grace runs out to pay
+    Given an active session exists on device "WALKAWAY-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | WLK-COLA | 0.95       |
+    And device "WALKAWAY-001" signals "items_removed" for the current session
+    When the walkaway sweep runs
+    Then the response status should be 200
+    And the response field "cancel_after_seconds" should be "60"
+    And the walkaway sweep should not have cancelled the current session
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  Scenario: A basket paid for after the customer left is not cancelled
+    Given an active session exists on device "WALKAWAY-001"
+    And I submit the following detections to the session:
+      | sku      | confidence |
+      | WLK-COLA | 0.95       |
+    And device "WALKAWAY-001" signals "door_closed" for the current session
+    And I confirm the session with payment reference "PAY-WALKAWAY-1"
+    When the walkaway sweep runs with a grace of 0 seconds
+    Then the walkaway sweep should not have cancelled the current session
+    When I fetch the current session
+    Then the response field "session.status" should be "completed"
+
+  Scenario: A customer who took nothing is not cancelled as a walkaway
+    Given an active session exists on device "WALKAWAY-001"
+    And device "WALKAWAY-001" signals "door_closed" for the current session
+    When the walkaway sweep runs with a grace of 0 seconds
+    Then the walkaway sweep should not have cancelled the current session
+
+  Scenario: The grace runs from the first signal
+    Given an active session exists on device "WALKAWAY-001"
+    And device "WALKAWAY-001" signals "items_removed" for the current session
+    When device "WALKAWAY-001" signals "door_closed" for the current session
+    Then the response status should be 200
+    And the response field "signal" should be "items_removed"
+
+  @error-handling
+  Scenario: Unknown signals are rejected
+    Given an active session exists on device "WALKAWAY-001"
+    When device "WALKAWAY-001" signals "door_opened" for the current session
+    Then the response status should be 400
+    And the response should contain error "unknown walkaway signal"
+
+  @error-handling
+  Scenario: A device only signals for its own sessions
+    Given an active session exists on device "WALKAWAY-001"
+    And a device exists with machine ID "WALKAWAY-002"
+    When device "WALKAWAY-002" signals "door_closed" for the current session
+    Then the response status should be 403
+    And the response should contain error "session belongs to another device"
+
+  @error-handling
+  Scenario: A closed session takes no signal
+    Given an active session exists on device "WALKAWAY-001"
+    And I cancel the session with reason "changed my mind"
+    When device "WALKAWAY-001" signals "door_closed" for the current session
+    Then the response status should be 422
+    And the response should contain error "session not active"
@@ -0,0 +1,66 @@
+@api @transaction
+Feature: Wallet Payments
+  As a customer
+  I want to pay for my session with Apple Pay or Google Pay
+  So that I can check out without typing in a card
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "WALLET-001"
+    And the following SKUs exist:
+      | code      | name        | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple  | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple  | 230         | 140          | 10               |
+
+  Scenario: Pay for a session with Apple Pay
+    Given the payment provider is available
+    And an active session with items exists on device "WALLET-001"
+    When I pay for the session with wallet "apple_pay" and token "tok_test"
+    Then the response status should be 200
+    And the response field "status" should be "succeeded"
+    And the response field "amount_cents" should be "480"
+    And the response field "payment_method.wallet" should be "apple_pay"
+    And the response field "payment_method.brand" should be "visa"
+    And the response field "payment_method.last4" should be "4242"
+
+  Scenario: The receipt of a wallet payment shows the wallet and card
+    Given the payment provider is available
+    And an active session with items exists on device "WALLET-001"
+    And I pay for the session with wallet "google_pay" and token "tok_test"
+    When I confirm the session
+    Then the response status should be 200
+    And the response field "status" should be "completed"
+    And the response field "payment_method.wallet" should be "google_pay"
+    And the response field "payment_method.brand" should be "visa"
+    And the response field "payment_method.last4" should be "4242"
+
+  @error-handling
+  Scenario: A declined wallet payment leaves the session to pay
+    Given the payment provider is available
+    And an active session with items exists on device "WALLET-001"
+    When I pay for the session with wallet "apple_pay" and token "tok_declined"
+    Then the response status should be 502
+    And the response should contain error "payment provider unavailable"
+    When I fetch the current session
+    Then the response field "session.status" should be "active"
+
+  @error-handling
+  Scenario: Cannot pay with an unsupported wallet
+    Given an active session with items exists on device "WALLET-001"
+    When I pay for the session with wallet "paypal" and token "tok_test"
+    Then the response status should be 400
+    And the response should contain error "unsupported wallet type"
+
+  @error-handling
+  Scenario: Wallet payments are unavailable without a payment provider
+    Given an active session with items exists on device "WALLET-001"
+    When I pay for the session with wallet "apple_pay" and token "tok_test"
+    Then the response status should be 503
+    And the response should contain error "wallet payments are not available"
+
+  @error-handling
+  Scenario: Apple Pay merchant validation only calls Apple
+    When I request an Apple Pay merchant session from "https://evil.example.com/paymentSession"
+    Then the response status should be 400
+    And the response should contain error "validation URL is not an Apple Pay endpoint"
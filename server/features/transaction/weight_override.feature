@@ -0,0 +1,54 @@
+@api @transaction
+Feature: Weight Mismatch Override
+  As an attendant
+  I want to approve or correct a basket whose items do not match the measured weight
+  So that the customer can check out with a basket someone has vouched for
+
+  Background:
+    Given the API server is running
+    And the database is clean
+    And a device exists with machine ID "WEIGHT-001"
+    And the following SKUs exist:
+      | code      | name       | price_cents | weight_grams | weight_tolerance |
+      | APPLE-001 | Fuji Apple | 250         | 150          | 10               |
+      | APPLE-002 | Gala Apple | 230         | 140          | 10               |
+
+  Scenario: An attendant approves a basket that does not match its weight
+    Given an active session with items exists on device "WEIGHT-001"
+    When attendant "attendant-3" resolves the weight of the current session as "approved" because "customer's own bag on the shelf"
+    Then the response status should be 200
+    And the response field "weight_mismatch" should be "true"
+    And the response field "weight_override.decision" should be "approved"
+    And the response field "weight_override.actor_id" should be "attendant-3"
+    And the response field "weight_override.reason" should be "customer's own bag on the shelf"
+
+  Scenario: An attendant corrects the basket to what they counted
+    Given an active session with items exists on device "WEIGHT-001"
+    When attendant "attendant-3" corrects the current session to 1 of "APPLE-001" because "second apple was put back"
+    Then the response status should be 200
+    And the response field "items.0.code" should be "APPLE-001"
+    And the response field "items.0.quantity" should be "1"
+    And the response field "weight_override.decision" should be "corrected"
+    When I list the detections of the current session
+    Then the response field "detections.1.source" should be "attendant"
+
+  @error-handling
+  Scenario: An override needs the acting attendant
+    Given an active session with items exists on device "WEIGHT-001"
+    When attendant "" resolves the weight of the current session as "approved" because "looks fine"
+    Then the response status should be 401
+    And the response should contain error "acting user is required"
+
+  @error-handling
+  Scenario: An unknown decision is refused
+    Given an active session with items exists on device "WEIGHT-001"
+    When attendant "attendant-3" resolves the weight of the current session as "ignored" because "looks fine"
+    Then the response status should be 400
+    And the response should contain error "unknown weight override decision"
+
+  @error-handling
+  Scenario: A basket that matches its weight has nothing to approve
+    Given an active session exists on device "WEIGHT-001"
+    When attendant "attendant-3" resolves the weight of the current session as "approved" because "looks fine"
+    Then the response status should be 422
+    And the response should contain error "session has no weight mismatch to resolve"
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/platform/cache"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// CachedSKUReader is a read-through cache for the single-SKU lookups made on
+// every detection submission; listings go straight to the next reader.
+// Entries live for the TTL, and catalog events published through
+// Invalidating drop the SKU right away. With an in-process store other server
+// instances keep serving their entry until it expires.
+type CachedSKUReader struct {
+	next  SKUReader
+	store cache.Store
+	ttl   time.Duration
+}
+
+func NewCachedSKUReader(next SKUReader, store cache.Store, ttl time.Duration) *CachedSKUReader {
+	if next == nil {
+		panic("nil SKUReader")
+	}
+	if store == nil {
+		panic("nil cache Store")
+	}
+	return &CachedSKUReader{next: next, store: store, ttl: ttl}
+}
+
+func skuIDKey(id string) string     { return "catalog:sku:id:" + id }
+func skuCodeKey(code string) string { return "catalog:sku:code:" + code }
+
+func (r *CachedSKUReader) FindByCode(ctx context.Context, code string) (*SKUView, error) {
+	if view, ok := r.get(ctx, skuCodeKey(code)); ok {
+		return view, nil
+	}
+	view, err := r.next.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	r.put(ctx, view)
+	return view, nil
+}
+
+func (r *CachedSKUReader) FindByID(ctx context.Context, id string) (*SKUView, error) {
+	if view, ok := r.get(ctx, skuIDKey(id)); ok {
+		return view, nil
+	}
+	view, err := r.next.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.put(ctx, view)
+	return view, nil
+}
+
+func (r *CachedSKUReader) FindAllActive(ctx context.Context) ([]SKUView, error) {
+	return r.next.FindAllActive(ctx)
+}
+
+func (r *CachedSKUReader) FindAll(ctx context.Context) ([]SKUView, error) {
+	return r.next.FindAll(ctx)
+}
+
+func (r *CachedSKUReader) FindActiveBundles(ctx context.Context) ([]SKUView, error) {
+	return r.next.FindActiveBundles(ctx)
+}
+
+func (r *CachedSKUReader) FindChangedSince(ctx context.Context, since time.Time) ([]SKUView, error) {
+	return r.next.FindChangedSince(ctx, since)
+}
+
+// Invalidate drops the cached SKU. The entry under its ID is written with every
+// entry under its code, and never expires first, so it tells which code to drop.
+func (r *CachedSKUReader) Invalidate(ctx context.Context, id string) error {
+	keys := []string{skuIDKey(id)}
+	if view, ok := r.get(ctx, skuIDKey(id)); ok {
+		keys = append(keys, skuCodeKey(view.Code))
+	}
+	return r.store.Delete(ctx, keys...)
+}
+
+// Invalidating wraps a publisher so every catalog event about a SKU drops it
+// from the cache before the event is passed on
+func (r *CachedSKUReader) Invalidating(next messaging.Publisher) messaging.Publisher {
+	if next == nil {
+		panic("nil Publisher")
+	}
+	return &invalidatingPublisher{cache: r, next: next}
+}
+
+// Store errors are misses: the cache never fails a lookup the reader can answer
+
+func (r *CachedSKUReader) get(ctx context.Context, key string) (*SKUView, bool) {
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logger.Warn("SKU cache read failed", "key", key, "error", err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	var view SKUView
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, false
+	}
+	return &view, true
+}
+
+func (r *CachedSKUReader) put(ctx context.Context, view *SKUView) {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return
+	}
+	// The ID entry goes last so it outlives the code entry
+	for _, key := range []string{skuCodeKey(view.Code), skuIDKey(view.ID)} {
+		if err := r.store.Set(ctx, key, data, r.ttl); err != nil {
+			logger.Warn("SKU cache write failed", "key", key, "error", err)
+			return
+		}
+	}
+}
+
+type invalidatingPublisher struct {
+	cache *CachedSKUReader
+	next  messaging.Publisher
+}
+
+func (p *invalidatingPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	switch e := event.(type) {
+	case domain.SKUCreated:
+		// A SKU reusing the code of a deleted one replaces it
+		if err := p.cache.store.Delete(ctx, skuCodeKey(e.Code)); err != nil {
+			logger.Error("Failed to invalidate cached SKU", "code", e.Code, "error", err)
+		}
+	default:
+		if id, ok := changedSKUID(event); ok {
+			if err := p.cache.Invalidate(ctx, id); err != nil {
+				logger.Error("Failed to invalidate cached SKU", "sku_id", id, "error", err)
+			}
+		}
+	}
+	return p.next.Publish(ctx, event)
+}
+
+// changedSKUID returns the SKU a catalog event changed
+func changedSKUID(event events.DomainEvent) (string, bool) {
+	switch e := event.(type) {
+	case domain.SKUUpdated:
+		return e.SKUID.String(), true
+	case domain.SKUPriceChanged:
+		return e.SKUID.String(), true
+	case domain.SKUPublished:
+		return e.SKUID.String(), true
+	case domain.SKURetired:
+		return e.SKUID.String(), true
+	case domain.SKUDeleted:
+		return e.SKUID.String(), true
+	case domain.SKURestored:
+		return e.SKUID.String(), true
+	case domain.SKUWeightSampled:
+		return e.SKUID.String(), true
+	case domain.SKUCategoryChanged:
+		return e.SKUID.String(), true
+	}
+	return "", false
+}
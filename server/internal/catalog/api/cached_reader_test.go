@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/platform/cache"
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// fakeSKUReader serves SKUs from a map and counts the lookups that reach it
+type fakeSKUReader struct {
+	byID  map[string]SKUView
+	calls int
+}
+
+func newFakeSKUReader(skus ...SKUView) *fakeSKUReader {
+	r := &fakeSKUReader{byID: make(map[string]SKUView)}
+	for _, sku := range skus {
+		r.byID[sku.ID] = sku
+	}
+	return r
+}
+
+func (r *fakeSKUReader) FindByCode(ctx context.Context, code string) (*SKUView, error) {
+	r.calls++
+	for _, sku := range r.byID {
+		if sku.Code == code {
+			return &sku, nil
+		}
+	}
+	return nil, domain.ErrSKUNotFound
+}
+
+func (r *fakeSKUReader) FindByID(ctx context.Context, id string) (*SKUView, error) {
+	r.calls++
+	sku, ok := r.byID[id]
+	if !ok {
+		return nil, domain.ErrSKUNotFound
+	}
+	return &sku, nil
+}
+
+func (r *fakeSKUReader) FindAllActive(ctx context.Context) ([]SKUView, error)     { return nil, nil }
+func (r *fakeSKUReader) FindAll(ctx context.Context) ([]SKUView, error)           { return nil, nil }
+func (r *fakeSKUReader) FindActiveBundles(ctx context.Context) ([]SKUView, error) { return nil, nil }
+func (r *fakeSKUReader) FindChangedSince(ctx context.Context, since time.Time) ([]SKUView, error) {
+	return nil, nil
+}
+
+// failingStore fails every call, as an unreachable Redis does
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+func (failingStore) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("connection refused")
+}
+
+type recordingPublisher struct {
+	published []events.DomainEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+func newSKU(code string, priceCents int64) SKUView {
+	return SKUView{ID: valueobjects.NewSKUID().String(), Code: code, Name: code, PriceCents: priceCents, Currency: "USD", Active: true}
+}
+
+func TestCachedSKUReaderServesHitsFromTheStore(t *testing.T) {
+	ctx := context.Background()
+	sku := newSKU("COLA-330", 150)
+	next := newFakeSKUReader(sku)
+	reader := NewCachedSKUReader(next, cache.NewMemoryStore(), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		view, err := reader.FindByCode(ctx, sku.Code)
+		if err != nil {
+			t.Fatalf("FindByCode: %v", err)
+		}
+		if view.ID != sku.ID {
+			t.Fatalf("FindByCode returned %s, want %s", view.ID, sku.ID)
+		}
+	}
+	if _, err := reader.FindByID(ctx, sku.ID); err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("next reader was called %d times, want 1", next.calls)
+	}
+}
+
+func TestCachedSKUReaderPassesMissesThrough(t *testing.T) {
+	ctx := context.Background()
+	next := newFakeSKUReader()
+	reader := NewCachedSKUReader(next, cache.NewMemoryStore(), time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := reader.FindByCode(ctx, "NOPE"); !errors.Is(err, domain.ErrSKUNotFound) {
+			t.Fatalf("FindByCode error = %v, want ErrSKUNotFound", err)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("next reader was called %d times, want 2: not-found is not cached", next.calls)
+	}
+}
+
+func TestCachedSKUReaderTreatsStoreErrorsAsMisses(t *testing.T) {
+	ctx := context.Background()
+	sku := newSKU("COLA-330", 150)
+	next := newFakeSKUReader(sku)
+	reader := NewCachedSKUReader(next, failingStore{}, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		view, err := reader.FindByCode(ctx, sku.Code)
+		if err != nil {
+			t.Fatalf("FindByCode: %v", err)
+		}
+		if view.PriceCents != 150 {
+			t.Fatalf("PriceCents = %d, want 150", view.PriceCents)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("next reader was called %d times, want 2", next.calls)
+	}
+}
+
+func TestCachedSKUReaderInvalidatesOnCatalogEvents(t *testing.T) {
+	ctx := context.Background()
+	skuID := valueobjects.NewSKUID()
+	price := func(cents int64) valueobjects.Money {
+		m, _ := valueobjects.NewMoney(cents, "USD")
+		return m
+	}
+
+	tests := []struct {
+		name  string
+		event events.DomainEvent
+	}{
+		{"price changed", domain.NewSKUPriceChanged(skuID, price(150), price(175))},
+		{"deleted", domain.NewSKUDeleted(skuID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sku := newSKU("COLA-330", 150)
+			sku.ID = skuID.String()
+			next := newFakeSKUReader(sku)
+			reader := NewCachedSKUReader(next, cache.NewMemoryStore(), time.Minute)
+			downstream := &recordingPublisher{}
+			publisher := reader.Invalidating(downstream)
+
+			if _, err := reader.FindByCode(ctx, sku.Code); err != nil {
+				t.Fatalf("FindByCode: %v", err)
+			}
+			sku.PriceCents = 175
+			next.byID[sku.ID] = sku
+
+			if err := publisher.Publish(ctx, tt.event); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			if len(downstream.published) != 1 {
+				t.Fatalf("%d events passed on, want 1", len(downstream.published))
+			}
+
+			for _, find := range []func() (*SKUView, error){
+				func() (*SKUView, error) { return reader.FindByCode(ctx, sku.Code) },
+				func() (*SKUView, error) { return reader.FindByID(ctx, sku.ID) },
+			} {
+				view, err := find()
+				if err != nil {
+					t.Fatalf("lookup after invalidation: %v", err)
+				}
+				if view.PriceCents != 175 {
+					t.Errorf("PriceCents = %d after invalidation, want 175", view.PriceCents)
+				}
+			}
+		})
+	}
+}
+
+func TestCachedSKUReaderDropsAReusedCodeOnCreation(t *testing.T) {
+	ctx := context.Background()
+	old := newSKU("COLA-330", 150)
+	next := newFakeSKUReader(old)
+	reader := NewCachedSKUReader(next, cache.NewMemoryStore(), time.Minute)
+	publisher := reader.Invalidating(&recordingPublisher{})
+
+	if _, err := reader.FindByCode(ctx, old.Code); err != nil {
+		t.Fatalf("FindByCode: %v", err)
+	}
+
+	// The old SKU is deleted without the cache hearing of it, and a new one
+	// takes over its code
+	delete(next.byID, old.ID)
+	replacement := newSKU(old.Code, 199)
+	next.byID[replacement.ID] = replacement
+	newID, _ := valueobjects.SKUIDFrom(replacement.ID)
+	if err := publisher.Publish(ctx, domain.NewSKUCreated(newID, replacement.Code, replacement.Name)); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	view, err := reader.FindByCode(ctx, old.Code)
+	if err != nil {
+		t.Fatalf("FindByCode: %v", err)
+	}
+	if view.ID != replacement.ID {
+		t.Errorf("FindByCode returned %s, want the new SKU %s", view.ID, replacement.ID)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/locale"
+)
+
+// DeviceSyncView is the slice of a SKU a device needs to recognize it. It is
+// read straight from the device sync read model, without loading the SKU.
+type DeviceSyncView struct {
+	Code            string
+	Name            string
+	Translations    map[string]TranslationView // by language tag
+	WeightGrams     float64
+	WeightTolerance float64
+	ClassID         *int // the detection model class; nil when the SKU has none
+	Active          bool
+	Deleted         bool
+	UpdatedAt       time.Time
+}
+
+// Localized returns the SKU's name and description in the first preferred
+// language it is translated to, or its own name when there is none
+func (v DeviceSyncView) Localized(preferred []string) TranslationView {
+	if t, ok := locale.Pick(v.Translations, preferred); ok {
+		return t
+	}
+	return TranslationView{Name: v.Name}
+}
+
+// DeviceClassView maps a detection model class to the code of the SKU it
+// recognizes
+type DeviceClassView struct {
+	ClassID int
+	Code    string
+}
+
+// DeviceSyncReader is the interface the device context uses to sync SKUs to
+// devices. Class IDs come from the class mapping pushed to the ML server, so
+// devices map inference results the same way the server does.
+type DeviceSyncReader interface {
+	// FindActive lists the SKUs on sale, by class ID with SKUs without one last
+	FindActive(ctx context.Context) ([]DeviceSyncView, error)
+	// FindChangedSince lists the SKUs created, updated, deactivated or deleted
+	// after the given time, oldest change first
+	FindChangedSince(ctx context.Context, since time.Time) ([]DeviceSyncView, error)
+	// FindClasses lists the class mapping the ML server holds: the classes of
+	// the SKUs on sale, by class ID
+	FindClasses(ctx context.Context) ([]DeviceClassView, error)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// EventTopic is the topic catalog events are published on
+const EventTopic = "catalog"
+
+// Catalog message types. A payload only gains optional fields within a
+// version; any other change publishes the type under a new version.
+const (
+	EventSKUCreated         = "catalog.sku.created"
+	EventSKUUpdated         = "catalog.sku.updated"
+	EventSKUPriceChanged    = "catalog.sku.price_changed"
+	EventSKUPublished       = "catalog.sku.published"
+	EventSKURetired         = "catalog.sku.retired"
+	EventSKUDeleted         = "catalog.sku.deleted"
+	EventSKURestored        = "catalog.sku.restored"
+	EventSKUWeightSampled   = "catalog.sku.weight_sampled"
+	EventSKUCategoryChanged = "catalog.sku.category_changed"
+	EventSKUClassAssigned   = "catalog.sku.class_assigned"
+	EventSKUClassRemoved    = "catalog.sku.class_removed"
+	EventCategoryCreated    = "catalog.category.created"
+	EventCategoryUpdated    = "catalog.category.updated"
+	EventCategoryDeleted    = "catalog.category.deleted"
+)
+
+// Payload schemas of the catalog message types, all at version 1
+
+type SKUCreatedPayload struct {
+	SKUID string `json:"sku_id"`
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+}
+
+type SKUUpdatedPayload struct {
+	SKUID string `json:"sku_id"`
+	Name  string `json:"name"`
+}
+
+type MoneyPayload struct {
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+}
+
+type SKUPriceChangedPayload struct {
+	SKUID    string       `json:"sku_id"`
+	OldPrice MoneyPayload `json:"old_price"`
+	NewPrice MoneyPayload `json:"new_price"`
+}
+
+type SKUPublishedPayload struct {
+	SKUID string `json:"sku_id"`
+	From  string `json:"from"` // the lifecycle state it left: draft or retired
+}
+
+// SKUPayload is the payload of the types that only name the SKU: retired,
+// deleted and restored
+type SKUPayload struct {
+	SKUID string `json:"sku_id"`
+}
+
+type SKUWeightSampledPayload struct {
+	SKUID       string  `json:"sku_id"`
+	Samples     int     `json:"samples"`
+	MeanGrams   float64 `json:"mean_grams"`
+	StdDevGrams float64 `json:"std_dev_grams"`
+}
+
+type SKUCategoryChangedPayload struct {
+	SKUID      string `json:"sku_id"`
+	CategoryID string `json:"category_id,omitempty"` // empty when the SKU left its category
+}
+
+// SKUClassPayload is the payload of class_assigned and class_removed
+type SKUClassPayload struct {
+	ClassID int    `json:"class_id"`
+	SKUID   string `json:"sku_id"`
+}
+
+type CategoryPayload struct {
+	CategoryID string `json:"category_id"`
+	Name       string `json:"name"`
+	ParentID   string `json:"parent_id,omitempty"`
+}
+
+type CategoryDeletedPayload struct {
+	CategoryID string `json:"category_id"`
+}
+
+// EncodeEvent is the messaging.Encoder of catalog events. Messages are keyed
+// by the SKU or category they are about.
+func EncodeEvent(event events.DomainEvent) (messaging.Message, bool) {
+	var (
+		typ     string
+		key     string
+		payload any
+	)
+	switch e := event.(type) {
+	case domain.SKUCreated:
+		typ, key, payload = EventSKUCreated, e.SKUID.String(), SKUCreatedPayload{SKUID: e.SKUID.String(), Code: e.Code, Name: e.Name}
+	case domain.SKUUpdated:
+		typ, key, payload = EventSKUUpdated, e.SKUID.String(), SKUUpdatedPayload{SKUID: e.SKUID.String(), Name: e.Name}
+	case domain.SKUPriceChanged:
+		typ, key, payload = EventSKUPriceChanged, e.SKUID.String(), SKUPriceChangedPayload{
+			SKUID:    e.SKUID.String(),
+			OldPrice: MoneyPayload{AmountCents: e.OldPrice.Amount(), Currency: e.OldPrice.Currency()},
+			NewPrice: MoneyPayload{AmountCents: e.NewPrice.Amount(), Currency: e.NewPrice.Currency()},
+		}
+	case domain.SKUPublished:
+		typ, key, payload = EventSKUPublished, e.SKUID.String(), SKUPublishedPayload{SKUID: e.SKUID.String(), From: string(e.From)}
+	case domain.SKURetired:
+		typ, key, payload = EventSKURetired, e.SKUID.String(), SKUPayload{SKUID: e.SKUID.String()}
+	case domain.SKUDeleted:
+		typ, key, payload = EventSKUDeleted, e.SKUID.String(), SKUPayload{SKUID: e.SKUID.String()}
+	case domain.SKURestored:
+		typ, key, payload = EventSKURestored, e.SKUID.String(), SKUPayload{SKUID: e.SKUID.String()}
+	case domain.SKUWeightSampled:
+		typ, key, payload = EventSKUWeightSampled, e.SKUID.String(), SKUWeightSampledPayload{
+			SKUID:       e.SKUID.String(),
+			Samples:     e.Stats.Samples,
+			MeanGrams:   e.Stats.MeanGrams,
+			StdDevGrams: e.Stats.StdDevGrams,
+		}
+	case domain.SKUCategoryChanged:
+		p := SKUCategoryChangedPayload{SKUID: e.SKUID.String()}
+		if !e.CategoryID.IsZero() {
+			p.CategoryID = e.CategoryID.String()
+		}
+		typ, key, payload = EventSKUCategoryChanged, e.SKUID.String(), p
+	case domain.SKUClassAssigned:
+		typ, key, payload = EventSKUClassAssigned, e.SKUID.String(), SKUClassPayload{ClassID: e.ClassID, SKUID: e.SKUID.String()}
+	case domain.SKUClassRemoved:
+		typ, key, payload = EventSKUClassRemoved, e.SKUID.String(), SKUClassPayload{ClassID: e.ClassID, SKUID: e.SKUID.String()}
+	case domain.CategoryCreated:
+		typ, key, payload = EventCategoryCreated, e.CategoryID.String(), categoryPayload(e.CategoryID, e.Name, e.ParentID)
+	case domain.CategoryUpdated:
+		typ, key, payload = EventCategoryUpdated, e.CategoryID.String(), categoryPayload(e.CategoryID, e.Name, e.ParentID)
+	case domain.CategoryDeleted:
+		typ, key, payload = EventCategoryDeleted, e.CategoryID.String(), CategoryDeletedPayload{CategoryID: e.CategoryID.String()}
+	default:
+		return messaging.Message{}, false
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return messaging.Message{}, false
+	}
+	return messaging.Message{
+		Topic:      EventTopic,
+		Type:       typ,
+		Version:    1,
+		Key:        key,
+		OccurredAt: event.OccurredAt(),
+		Payload:    data,
+	}, true
+}
+
+func categoryPayload(id valueobjects.CategoryID, name string, parentID valueobjects.CategoryID) CategoryPayload {
+	p := CategoryPayload{CategoryID: id.String(), Name: name}
+	if !parentID.IsZero() {
+		p.ParentID = parentID.String()
+	}
+	return p
+}
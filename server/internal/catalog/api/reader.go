@@ -2,8 +2,10 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/locale"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
@@ -17,7 +19,43 @@ type SKUView struct {
 	WeightGrams     float64
 	WeightTolerance float64
 	ImageURL        string
-	Active          bool
+	Active          bool                  // on sale: activated and not deleted
+	CategoryID      string                // empty when uncategorized
+	Components      []BundleComponentView // bundles only
+	Deleted         bool
+	UpdatedAt       time.Time
+	Translations    map[string]TranslationView // by language tag, e.g. "de" or "de-CH"
+	ShelfLifeDays   int                        // 0 when not perishable
+	SupplierRef     string                     // the supplier's article number, when known
+	CaseSize        int                        // units per supplier case; 0 when unknown
+	ReorderPoint    int                        // units left in a device at which it needs restocking; 0 when unset
+
+	// Weight calibration from weighed samples; the mean and spread are only
+	// meaningful when WeightCalibrated is set
+	WeightCalibrated  bool
+	WeightMeanGrams   float64
+	WeightStdDevGrams float64
+}
+
+// TranslationView is a SKU's name and description in one language
+type TranslationView struct {
+	Name        string
+	Description string
+}
+
+// Localized returns the SKU's name and description in the first preferred
+// language it is translated to, or its own name when there is none
+func (v SKUView) Localized(preferred []string) TranslationView {
+	if t, ok := locale.Pick(v.Translations, preferred); ok {
+		return t
+	}
+	return TranslationView{Name: v.Name}
+}
+
+// BundleComponentView is one SKU of a bundle
+type BundleComponentView struct {
+	SKUID    string
+	Quantity int
 }
 
 // SKUReader is the interface other contexts use to read catalog data.
@@ -27,6 +65,11 @@ type SKUReader interface {
 	FindByID(ctx context.Context, id string) (*SKUView, error)
 	FindAllActive(ctx context.Context) ([]SKUView, error)
 	FindAll(ctx context.Context) ([]SKUView, error)
+	// FindActiveBundles lists the bundles on sale
+	FindActiveBundles(ctx context.Context) ([]SKUView, error)
+	// FindChangedSince lists the SKUs created, updated, deactivated or deleted
+	// after the given time, oldest change first
+	FindChangedSince(ctx context.Context, since time.Time) ([]SKUView, error)
 }
 
 // SKUReaderAdapter implements SKUReader using the domain repository
@@ -82,8 +125,34 @@ func (a *SKUReaderAdapter) FindAll(ctx context.Context) ([]SKUView, error) {
 	return views, nil
 }
 
+func (a *SKUReaderAdapter) FindActiveBundles(ctx context.Context) ([]SKUView, error) {
+	skus, err := a.repo.FindAllActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var views []SKUView
+	for _, sku := range skus {
+		if sku.IsBundle() {
+			views = append(views, *toSKUView(sku))
+		}
+	}
+	return views, nil
+}
+
+func (a *SKUReaderAdapter) FindChangedSince(ctx context.Context, since time.Time) ([]SKUView, error) {
+	skus, err := a.repo.FindChangedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]SKUView, len(skus))
+	for i, sku := range skus {
+		views[i] = *toSKUView(sku)
+	}
+	return views, nil
+}
+
 func toSKUView(sku *domain.SKU) *SKUView {
-	return &SKUView{
+	view := &SKUView{
 		ID:              sku.ID().String(),
 		Code:            sku.Code(),
 		Name:            sku.Name(),
@@ -92,6 +161,30 @@ func toSKUView(sku *domain.SKU) *SKUView {
 		WeightGrams:     sku.Weight().Grams(),
 		WeightTolerance: sku.WeightTolerance(),
 		ImageURL:        sku.ImageURL(),
-		Active:          sku.IsActive(),
+		Active:          sku.IsActive() && !sku.IsDeleted(),
+		Deleted:         sku.IsDeleted(),
+		UpdatedAt:       sku.UpdatedAt(),
+		ShelfLifeDays:   sku.ShelfLifeDays(),
+		SupplierRef:     sku.Restocking().SupplierRef,
+		CaseSize:        sku.Restocking().CaseSize,
+		ReorderPoint:    sku.Restocking().ReorderPoint,
+	}
+	if stats := sku.WeightStats(); stats.IsCalibrated() {
+		view.WeightCalibrated = true
+		view.WeightMeanGrams = stats.MeanGrams
+		view.WeightStdDevGrams = stats.StdDevGrams
+	}
+	if !sku.CategoryID().IsZero() {
+		view.CategoryID = sku.CategoryID().String()
+	}
+	for _, c := range sku.Components() {
+		view.Components = append(view.Components, BundleComponentView{SKUID: c.SKUID.String(), Quantity: c.Quantity})
+	}
+	if translations := sku.Translations(); len(translations) > 0 {
+		view.Translations = make(map[string]TranslationView, len(translations))
+		for tag, t := range translations {
+			view.Translations[tag] = TranslationView(t)
+		}
 	}
+	return view
 }
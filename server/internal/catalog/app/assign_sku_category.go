@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+// AssignSKUCategoryCommand is the input DTO for filing a SKU under a category
+type AssignSKUCategoryCommand struct {
+	SKUID      string
+	CategoryID string // empty removes the SKU from its category
+}
+
+// AssignSKUCategoryHandler files a SKU under a category
+type AssignSKUCategoryHandler struct {
+	skus       domain.SKURepository
+	categories domain.CategoryRepository
+	publisher  EventPublisher
+}
+
+func NewAssignSKUCategoryHandler(skus domain.SKURepository, categories domain.CategoryRepository, publisher EventPublisher) *AssignSKUCategoryHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AssignSKUCategoryHandler{
+		skus:       skus,
+		categories: categories,
+		publisher:  publisher,
+	}
+}
+
+func (h *AssignSKUCategoryHandler) Handle(ctx context.Context, cmd AssignSKUCategoryCommand) (*domain.SKU, error) {
+	categoryID, err := existingCategoryID(ctx, h.categories, cmd.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return changeSKU(ctx, h.skus, h.publisher, cmd.SKUID, func(s *domain.SKU) {
+		s.AssignCategory(categoryID)
+	})
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ChangeSKULifecycleCommand is the input DTO for publishing or retiring a SKU
+type ChangeSKULifecycleCommand struct {
+	SKUID string
+	To    domain.Lifecycle // published or retired
+}
+
+// ChangeSKULifecycleHandler publishes or retires a SKU. Retired SKUs stay in
+// the catalog, so past sessions and invoices keep resolving them.
+type ChangeSKULifecycleHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewChangeSKULifecycleHandler(skus domain.SKURepository, publisher EventPublisher) *ChangeSKULifecycleHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ChangeSKULifecycleHandler{
+		skus:      skus,
+		publisher: publisher,
+	}
+}
+
+func (h *ChangeSKULifecycleHandler) Handle(ctx context.Context, cmd ChangeSKULifecycleCommand) (*domain.SKU, error) {
+	skuID, err := valueobjects.SKUIDFrom(cmd.SKUID)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+
+	s, err := h.skus.FindByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both transitions are no-ops when the SKU is already in the requested state
+	switch cmd.To {
+	case domain.LifecyclePublished:
+		err = s.Publish()
+	case domain.LifecycleRetired:
+		err = s.Retire()
+	default:
+		err = domain.ErrInvalidLifecycleTransition
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.skus.Save(ctx, s); err != nil {
+		return nil, fmt.Errorf("failed to save SKU: %w", err)
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ErrClassSyncUnavailable is returned when no ML server is configured
+var ErrClassSyncUnavailable = errors.New("ML class sync is unavailable")
+
+// ClassMapping ties a detection model class to the SKU it recognizes
+type ClassMapping struct {
+	ClassID   int
+	SKUID     string
+	ClassName string // the SKU code, as in the training dataset export
+}
+
+// ClassSyncer is an output port for pushing the class mapping to the ML server
+type ClassSyncer interface {
+	// SyncClasses replaces the server's mapping and returns how many classes it holds
+	SyncClasses(ctx context.Context, mappings []ClassMapping) (int, error)
+}
+
+// ClassSyncResult is the outcome of a class mapping sync
+type ClassSyncResult struct {
+	Classes int
+}
+
+// SyncClassesHandler pushes the class→SKU mapping of the catalog to the ML
+// server. Only the classes of SKUs on sale are pushed; a retired SKU keeps
+// its class ID, so putting it back on sale maps the same class again.
+type SyncClassesHandler struct {
+	skus    domain.SKURepository
+	classes domain.SKUClassRepository
+	syncer  ClassSyncer
+}
+
+func NewSyncClassesHandler(skus domain.SKURepository, classes domain.SKUClassRepository, syncer ClassSyncer) *SyncClassesHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if classes == nil {
+		panic("nil SKUClassRepository")
+	}
+	if syncer == nil {
+		panic("nil ClassSyncer")
+	}
+	return &SyncClassesHandler{skus: skus, classes: classes, syncer: syncer}
+}
+
+func (h *SyncClassesHandler) Handle(ctx context.Context) (ClassSyncResult, error) {
+	mappings, err := h.mappings(ctx)
+	if err != nil {
+		return ClassSyncResult{}, err
+	}
+	classes, err := h.syncer.SyncClasses(ctx, mappings)
+	if err != nil {
+		if errors.Is(err, ErrClassSyncUnavailable) {
+			return ClassSyncResult{}, err
+		}
+		return ClassSyncResult{}, fmt.Errorf("failed to sync classes: %w", err)
+	}
+	return ClassSyncResult{Classes: classes}, nil
+}
+
+func (h *SyncClassesHandler) mappings(ctx context.Context) ([]ClassMapping, error) {
+	classes, err := h.classes.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skus, err := h.skus.FindAllActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	onSale := make(map[valueobjects.SKUID]*domain.SKU, len(skus))
+	for _, s := range skus {
+		onSale[s.ID()] = s
+	}
+
+	mappings := make([]ClassMapping, 0, len(classes))
+	for _, class := range classes {
+		if s, ok := onSale[class.SKUID]; ok {
+			mappings = append(mappings, toClassMapping(class, s))
+		}
+	}
+	return mappings, nil
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// BundleComponentInput is one SKU of a new bundle
+type BundleComponentInput struct {
+	SKUID    string
+	Quantity int
+}
+
+// CreateBundleCommand is the input DTO for creating a bundle SKU
+type CreateBundleCommand struct {
+	Code       string
+	Name       string
+	PriceCents int64
+	Currency   string
+	Components []BundleComponentInput
+}
+
+// CreateBundleHandler creates bundle SKUs. A bundle's components must be
+// regular SKUs priced in the bundle's currency; the bundle weighs what its
+// units weigh together.
+type CreateBundleHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewCreateBundleHandler(skus domain.SKURepository, publisher EventPublisher) *CreateBundleHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CreateBundleHandler{skus: skus, publisher: publisher}
+}
+
+func (h *CreateBundleHandler) Handle(ctx context.Context, cmd CreateBundleCommand) (CreateSKUResult, error) {
+	if existing, _ := h.skus.FindByCode(ctx, cmd.Code); existing != nil {
+		return CreateSKUResult{}, domain.ErrDuplicateSKUCode
+	}
+
+	components := make([]domain.BundleComponent, 0, len(cmd.Components))
+	var weightGrams float64
+	for _, input := range cmd.Components {
+		skuID, err := valueobjects.SKUIDFrom(input.SKUID)
+		if err != nil {
+			return CreateSKUResult{}, domain.ErrInvalidBundleComponent
+		}
+		component, err := h.skus.FindByID(ctx, skuID)
+		if errors.Is(err, domain.ErrSKUNotFound) {
+			return CreateSKUResult{}, domain.ErrInvalidBundleComponent
+		}
+		if err != nil {
+			return CreateSKUResult{}, err
+		}
+		if component.IsDeleted() || component.IsBundle() {
+			return CreateSKUResult{}, domain.ErrInvalidBundleComponent
+		}
+		if component.Price().Currency() != cmd.Currency {
+			return CreateSKUResult{}, domain.ErrBundleCurrencyMismatch
+		}
+
+		components = append(components, domain.BundleComponent{SKUID: skuID, Quantity: input.Quantity})
+		weightGrams += component.Weight().Grams() * float64(input.Quantity)
+	}
+
+	s, err := domain.NewBundle(cmd.Code, cmd.Name, cmd.PriceCents, cmd.Currency, weightGrams, components)
+	if err != nil {
+		return CreateSKUResult{}, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	if err := h.skus.Save(ctx, s); err != nil {
+		return CreateSKUResult{}, fmt.Errorf("failed to save bundle: %w", err)
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return CreateSKUResult{SKUID: s.ID().String()}, nil
+}
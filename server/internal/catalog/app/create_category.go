@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// CreateCategoryCommand is the input DTO for creating a category
+type CreateCategoryCommand struct {
+	Name     string
+	ParentID string // empty for a top-level category
+}
+
+// CreateCategoryHandler orchestrates the category creation use case
+type CreateCategoryHandler struct {
+	categories domain.CategoryRepository
+	publisher  EventPublisher
+}
+
+func NewCreateCategoryHandler(categories domain.CategoryRepository, publisher EventPublisher) *CreateCategoryHandler {
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CreateCategoryHandler{
+		categories: categories,
+		publisher:  publisher,
+	}
+}
+
+func (h *CreateCategoryHandler) Handle(ctx context.Context, cmd CreateCategoryCommand) (*domain.Category, error) {
+	parentID, err := existingCategoryID(ctx, h.categories, cmd.ParentID)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return nil, domain.ErrParentCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := domain.NewCategory(cmd.Name, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.categories.Save(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+
+	for _, evt := range c.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return c, nil
+}
+
+// existingCategoryID parses an optional category reference and checks that it
+// exists; an empty string is the zero ID
+func existingCategoryID(ctx context.Context, categories domain.CategoryRepository, raw string) (valueobjects.CategoryID, error) {
+	if raw == "" {
+		return valueobjects.CategoryID{}, nil
+	}
+	id, err := valueobjects.CategoryIDFrom(raw)
+	if err != nil {
+		return valueobjects.CategoryID{}, domain.ErrCategoryNotFound
+	}
+	if _, err := categories.FindByID(ctx, id); err != nil {
+		return valueobjects.CategoryID{}, err
+	}
+	return id, nil
+}
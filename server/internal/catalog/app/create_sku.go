@@ -6,6 +6,7 @@ import (
 
 	"github.com/vending-machine/server/internal/catalog/domain"
 	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
 // EventPublisher is an output port for publishing domain events
@@ -22,6 +23,26 @@ type CreateSKUCommand struct {
 	WeightGrams     float64
 	WeightTolerance float64
 	ImageURL        string
+	CategoryID      string                        // optional
+	Attributes      map[string]any                // optional brand, size, flavor, ...
+	Draft           bool                          // prepare the SKU without putting it on sale
+	ProductInfo     *ProductInfoInput             // optional nutrition facts and allergens
+	Translations    map[string]domain.Translation // optional names by language tag
+	ShelfLifeDays   int                           // optional, 0 when not perishable
+	Restocking      *RestockingInput              // optional supplier, case size and reorder point
+}
+
+// RestockingInput is how a SKU is bought in and when a device runs low on it
+type RestockingInput struct {
+	SupplierRef  string
+	CaseSize     int
+	ReorderPoint int
+}
+
+// ProductInfoInput is the nutrition declaration and allergens of a SKU
+type ProductInfoInput struct {
+	Nutrition *domain.NutritionFacts // nil when not declared
+	Allergens []string
 }
 
 // CreateSKUResult is the output DTO
@@ -31,20 +52,25 @@ type CreateSKUResult struct {
 
 // CreateSKUHandler orchestrates the SKU creation use case
 type CreateSKUHandler struct {
-	skus      domain.SKURepository
-	publisher EventPublisher
+	skus       domain.SKURepository
+	categories domain.CategoryRepository
+	publisher  EventPublisher
 }
 
-func NewCreateSKUHandler(skus domain.SKURepository, publisher EventPublisher) *CreateSKUHandler {
+func NewCreateSKUHandler(skus domain.SKURepository, categories domain.CategoryRepository, publisher EventPublisher) *CreateSKUHandler {
 	if skus == nil {
 		panic("nil SKURepository")
 	}
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
 	if publisher == nil {
 		panic("nil EventPublisher")
 	}
 	return &CreateSKUHandler{
-		skus:      skus,
-		publisher: publisher,
+		skus:       skus,
+		categories: categories,
+		publisher:  publisher,
 	}
 }
 
@@ -55,18 +81,14 @@ func (h *CreateSKUHandler) Handle(ctx context.Context, cmd CreateSKUCommand) (Cr
 		return CreateSKUResult{}, domain.ErrDuplicateSKUCode
 	}
 
-	// Create the aggregate
-	s, err := domain.NewSKU(cmd.Code, cmd.Name, cmd.PriceCents, cmd.Currency, cmd.WeightGrams)
+	categoryID, err := existingCategoryID(ctx, h.categories, cmd.CategoryID)
 	if err != nil {
-		return CreateSKUResult{}, fmt.Errorf("invalid SKU: %w", err)
+		return CreateSKUResult{}, err
 	}
 
-	// Update optional fields
-	if cmd.WeightTolerance > 0 || cmd.ImageURL != "" {
-		err = s.Update(cmd.Name, cmd.PriceCents, cmd.Currency, cmd.WeightGrams, cmd.WeightTolerance, cmd.ImageURL)
-		if err != nil {
-			return CreateSKUResult{}, fmt.Errorf("failed to update SKU: %w", err)
-		}
+	s, err := newSKU(cmd, categoryID)
+	if err != nil {
+		return CreateSKUResult{}, err
 	}
 
 	// Persist
@@ -81,3 +103,70 @@ func (h *CreateSKUHandler) Handle(ctx context.Context, cmd CreateSKUCommand) (Cr
 
 	return CreateSKUResult{SKUID: s.ID().String()}, nil
 }
+
+// newSKU builds a SKU aggregate from a creation command
+func newSKU(cmd CreateSKUCommand, categoryID valueobjects.CategoryID) (*domain.SKU, error) {
+	create := domain.NewSKU
+	if cmd.Draft {
+		create = domain.NewDraftSKU
+	}
+	s, err := create(cmd.Code, cmd.Name, cmd.PriceCents, cmd.Currency, cmd.WeightGrams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SKU: %w", err)
+	}
+
+	// Update optional fields
+	if cmd.WeightTolerance > 0 || cmd.ImageURL != "" {
+		err = s.Update(cmd.Name, cmd.PriceCents, cmd.Currency, cmd.WeightGrams, cmd.WeightTolerance, cmd.ImageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update SKU: %w", err)
+		}
+	}
+
+	if len(cmd.Attributes) > 0 {
+		attrs, err := domain.NewAttributes(cmd.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		s.SetAttributes(attrs)
+	}
+
+	if cmd.ProductInfo != nil {
+		if err := setProductInfo(s, *cmd.ProductInfo); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cmd.Translations) > 0 {
+		translations, err := domain.NewTranslations(cmd.Translations)
+		if err != nil {
+			return nil, err
+		}
+		s.SetTranslations(translations)
+	}
+
+	if cmd.ShelfLifeDays != 0 {
+		if err := s.SetShelfLife(cmd.ShelfLifeDays); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Restocking != nil {
+		info, err := domain.NewRestockingInfo(cmd.Restocking.SupplierRef, cmd.Restocking.CaseSize, cmd.Restocking.ReorderPoint)
+		if err != nil {
+			return nil, err
+		}
+		s.SetRestocking(info)
+	}
+
+	s.AssignCategory(categoryID)
+	return s, nil
+}
+
+func setProductInfo(s *domain.SKU, info ProductInfoInput) error {
+	allergens, err := domain.NewAllergens(info.Allergens)
+	if err != nil {
+		return err
+	}
+	return s.SetProductInfo(info.Nutrition, allergens)
+}
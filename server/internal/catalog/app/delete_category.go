@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DeleteCategoryCommand is the input DTO for deleting a category
+type DeleteCategoryCommand struct {
+	CategoryID string
+}
+
+// DeleteCategoryHandler removes an empty branch of the category tree. SKUs
+// filed under the category become uncategorized; subcategories must be moved
+// or deleted first.
+type DeleteCategoryHandler struct {
+	categories domain.CategoryRepository
+	publisher  EventPublisher
+}
+
+func NewDeleteCategoryHandler(categories domain.CategoryRepository, publisher EventPublisher) *DeleteCategoryHandler {
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DeleteCategoryHandler{
+		categories: categories,
+		publisher:  publisher,
+	}
+}
+
+func (h *DeleteCategoryHandler) Handle(ctx context.Context, cmd DeleteCategoryCommand) error {
+	id, err := valueobjects.CategoryIDFrom(cmd.CategoryID)
+	if err != nil {
+		return domain.ErrCategoryNotFound
+	}
+	c, err := h.categories.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	all, err := h.categories.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(domain.Subtree(all, id)) > 1 {
+		return domain.ErrCategoryInUse
+	}
+
+	if err := h.categories.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	c.MarkDeleted()
+	for _, evt := range c.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return nil
+}
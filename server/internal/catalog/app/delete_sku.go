@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DeleteSKUCommand is the input DTO for soft-deleting or restoring a SKU
+type DeleteSKUCommand struct {
+	SKUID string
+}
+
+// DeleteSKUHandler soft-deletes a SKU. Its row stays, so past sessions and
+// invoices keep resolving it and the code cannot be reused.
+type DeleteSKUHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewDeleteSKUHandler(skus domain.SKURepository, publisher EventPublisher) *DeleteSKUHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DeleteSKUHandler{
+		skus:      skus,
+		publisher: publisher,
+	}
+}
+
+func (h *DeleteSKUHandler) Handle(ctx context.Context, cmd DeleteSKUCommand) (*domain.SKU, error) {
+	return changeSKU(ctx, h.skus, h.publisher, cmd.SKUID, (*domain.SKU).Delete)
+}
+
+// RestoreSKUHandler brings a soft-deleted SKU back into the catalog
+type RestoreSKUHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewRestoreSKUHandler(skus domain.SKURepository, publisher EventPublisher) *RestoreSKUHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RestoreSKUHandler{
+		skus:      skus,
+		publisher: publisher,
+	}
+}
+
+func (h *RestoreSKUHandler) Handle(ctx context.Context, cmd DeleteSKUCommand) (*domain.SKU, error) {
+	return changeSKU(ctx, h.skus, h.publisher, cmd.SKUID, (*domain.SKU).Restore)
+}
+
+// changeSKU loads a SKU, applies a state change, saves it and publishes its events
+func changeSKU(ctx context.Context, skus domain.SKURepository, publisher EventPublisher, id string, change func(*domain.SKU)) (*domain.SKU, error) {
+	skuID, err := valueobjects.SKUIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+
+	s, err := skus.FindByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+
+	change(s)
+
+	if err := skus.Save(ctx, s); err != nil {
+		return nil, fmt.Errorf("failed to save SKU: %w", err)
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = publisher.Publish(ctx, evt)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+// ImportSKURow is one row of an import file, cells as written
+type ImportSKURow struct {
+	Line            int // line in the file, for the error report
+	Code            string
+	Name            string
+	PriceCents      string
+	Currency        string // optional, defaults to USD
+	WeightGrams     string
+	WeightTolerance string // optional
+	ImageURL        string // optional
+	CategoryID      string // optional
+}
+
+// ImportSKUsCommand is the input DTO for a bulk import
+type ImportSKUsCommand struct {
+	Rows []ImportSKURow
+}
+
+// ImportedSKU is a SKU created by an import
+type ImportedSKU struct {
+	Line  int
+	SKUID string
+	Code  string
+}
+
+// ImportRowError explains why a row was rejected
+type ImportRowError struct {
+	Line  int
+	Code  string
+	Error string
+}
+
+// ImportSKUsResult is the output DTO. Either Errors is empty and every row
+// was created, or nothing was created.
+type ImportSKUsResult struct {
+	Created []ImportedSKU
+	Errors  []ImportRowError
+}
+
+// ImportSKUsHandler creates many SKUs at once. Every row is validated first;
+// a single bad row rejects the whole file so an import never half-applies.
+type ImportSKUsHandler struct {
+	skus       domain.SKURepository
+	categories domain.CategoryRepository
+	publisher  EventPublisher
+}
+
+func NewImportSKUsHandler(skus domain.SKURepository, categories domain.CategoryRepository, publisher EventPublisher) *ImportSKUsHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ImportSKUsHandler{skus: skus, categories: categories, publisher: publisher}
+}
+
+func (h *ImportSKUsHandler) Handle(ctx context.Context, cmd ImportSKUsCommand) (ImportSKUsResult, error) {
+	var result ImportSKUsResult
+	created := make([]*domain.SKU, 0, len(cmd.Rows))
+	seen := make(map[string]int, len(cmd.Rows))
+
+	for _, row := range cmd.Rows {
+		code := strings.TrimSpace(row.Code)
+		if first, ok := seen[code]; ok && code != "" {
+			result.Errors = append(result.Errors, ImportRowError{Line: row.Line, Code: code, Error: fmt.Sprintf("duplicate code, first used on line %d", first)})
+			continue
+		}
+		seen[code] = row.Line
+
+		s, err := h.validate(ctx, row)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportRowError{Line: row.Line, Code: code, Error: err.Error()})
+			continue
+		}
+		created = append(created, s)
+		result.Created = append(result.Created, ImportedSKU{Line: row.Line, SKUID: s.ID().String(), Code: s.Code()})
+	}
+
+	if len(result.Errors) > 0 {
+		result.Created = nil
+		return result, nil
+	}
+
+	if err := h.skus.SaveAll(ctx, created); err != nil {
+		return ImportSKUsResult{}, fmt.Errorf("failed to save SKUs: %w", err)
+	}
+
+	for _, s := range created {
+		for _, evt := range s.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+	}
+
+	return result, nil
+}
+
+// validate turns a row into a SKU the same way CreateSKUHandler would
+func (h *ImportSKUsHandler) validate(ctx context.Context, row ImportSKURow) (*domain.SKU, error) {
+	cmd := CreateSKUCommand{
+		Code:       strings.TrimSpace(row.Code),
+		Name:       strings.TrimSpace(row.Name),
+		Currency:   strings.TrimSpace(row.Currency),
+		ImageURL:   strings.TrimSpace(row.ImageURL),
+		CategoryID: strings.TrimSpace(row.CategoryID),
+	}
+	if cmd.Currency == "" {
+		cmd.Currency = "USD"
+	}
+
+	var err error
+	if cmd.PriceCents, err = strconv.ParseInt(strings.TrimSpace(row.PriceCents), 10, 64); err != nil {
+		return nil, domain.ErrInvalidSKUPrice
+	}
+	if cmd.WeightGrams, err = strconv.ParseFloat(strings.TrimSpace(row.WeightGrams), 64); err != nil {
+		return nil, domain.ErrInvalidSKUWeight
+	}
+	if raw := strings.TrimSpace(row.WeightTolerance); raw != "" {
+		if cmd.WeightTolerance, err = strconv.ParseFloat(raw, 64); err != nil || cmd.WeightTolerance < 0 {
+			return nil, fmt.Errorf("weight tolerance must be a non-negative number")
+		}
+	}
+
+	if existing, _ := h.skus.FindByCode(ctx, cmd.Code); existing != nil {
+		return nil, domain.ErrDuplicateSKUCode
+	}
+
+	categoryID, err := existingCategoryID(ctx, h.categories, cmd.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSKU(cmd, categoryID)
+}
@@ -1,7 +1,11 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/vending-machine/server/internal/catalog/domain"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
@@ -9,11 +13,12 @@ import (
 
 // SKUQueryService provides read-only access to SKUs for the catalog context's HTTP layer
 type SKUQueryService struct {
-	repo domain.SKURepository
+	repo       domain.SKURepository
+	categories domain.CategoryRepository
 }
 
-func NewSKUQueryService(repo domain.SKURepository) *SKUQueryService {
-	return &SKUQueryService{repo: repo}
+func NewSKUQueryService(repo domain.SKURepository, categories domain.CategoryRepository) *SKUQueryService {
+	return &SKUQueryService{repo: repo, categories: categories}
 }
 
 func (s *SKUQueryService) FindByID(ctx context.Context, id string) (*domain.SKU, error) {
@@ -31,3 +36,246 @@ func (s *SKUQueryService) FindAll(ctx context.Context) ([]*domain.SKU, error) {
 func (s *SKUQueryService) FindAllActive(ctx context.Context) ([]*domain.SKU, error) {
 	return s.repo.FindAllActive(ctx)
 }
+
+// SKUFilter narrows a SKU listing; zero fields don't filter
+type SKUFilter struct {
+	CategoryID string // the category and its subcategories
+	ActiveOnly bool
+	Lifecycle  domain.Lifecycle
+	Attributes map[string]string // attribute name -> wanted value, see domain.Attributes.Matches
+	Sort       SKUSort
+}
+
+// ErrInvalidSKUSort is returned for an unknown sort field or order
+var ErrInvalidSKUSort = errors.New("sort must be price, name or created_at and order asc or desc")
+
+// SKUSortField is what a SKU listing is ordered by
+type SKUSortField string
+
+const (
+	SortSKUsByName      SKUSortField = "name"
+	SortSKUsByPrice     SKUSortField = "price"
+	SortSKUsByCreatedAt SKUSortField = "created_at"
+)
+
+// SKUSort orders a SKU listing; the zero value lists SKUs by name
+type SKUSort struct {
+	Field      SKUSortField
+	Descending bool
+}
+
+// ParseSKUSort reads a sort field and an asc or desc order; both may be
+// empty, ascending is the default order
+func ParseSKUSort(field, order string) (SKUSort, error) {
+	var o SKUSort
+	switch f := SKUSortField(field); f {
+	case "", SortSKUsByName, SortSKUsByPrice, SortSKUsByCreatedAt:
+		o.Field = f
+	default:
+		return SKUSort{}, ErrInvalidSKUSort
+	}
+	switch order {
+	case "", "asc":
+	case "desc":
+		o.Descending = true
+	default:
+		return SKUSort{}, ErrInvalidSKUSort
+	}
+	return o, nil
+}
+
+// Apply orders the SKUs in place. SKUs that tie are kept in code order
+// whichever way the listing runs, so pages of a sorted listing are stable.
+func (o SKUSort) Apply(skus []*domain.SKU) {
+	slices.SortStableFunc(skus, func(a, b *domain.SKU) int {
+		var c int
+		switch o.Field {
+		case SortSKUsByPrice:
+			c = cmp.Compare(a.Price().Amount(), b.Price().Amount())
+		case SortSKUsByCreatedAt:
+			c = a.CreatedAt().Compare(b.CreatedAt())
+		default:
+			c = cmp.Compare(a.Name(), b.Name())
+		}
+		if o.Descending {
+			c = -c
+		}
+		return cmp.Or(c, cmp.Compare(a.Code(), b.Code()))
+	})
+}
+
+// List returns the SKUs matching every part of the filter, in the filter's order
+func (s *SKUQueryService) List(ctx context.Context, filter SKUFilter) ([]*domain.SKU, error) {
+	skus, err := s.list(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Sort.Apply(skus)
+	return skus, nil
+}
+
+func (s *SKUQueryService) list(ctx context.Context, filter SKUFilter) ([]*domain.SKU, error) {
+	var (
+		skus []*domain.SKU
+		err  error
+	)
+	switch {
+	case filter.CategoryID != "":
+		skus, err = s.FindInCategory(ctx, filter.CategoryID, filter.ActiveOnly)
+	case filter.ActiveOnly:
+		skus, err = s.repo.FindAllActive(ctx)
+	default:
+		skus, err = s.repo.FindAll(ctx)
+	}
+	if err != nil || (len(filter.Attributes) == 0 && filter.Lifecycle == "") {
+		return skus, err
+	}
+
+	var filtered []*domain.SKU
+	for _, sku := range skus {
+		if filter.Lifecycle != "" && sku.Lifecycle() != filter.Lifecycle {
+			continue
+		}
+		if sku.Attributes().Matches(filter.Attributes) {
+			filtered = append(filtered, sku)
+		}
+	}
+	return filtered, nil
+}
+
+// PriceHistory lists a SKU's price changes, oldest first. Deleted SKUs keep
+// their history.
+func (s *SKUQueryService) PriceHistory(ctx context.Context, id string) ([]domain.PriceChange, error) {
+	skuID, err := valueobjects.SKUIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+	if _, err := s.repo.FindByID(ctx, skuID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindPriceHistory(ctx, skuID)
+}
+
+// FindInCategory lists the SKUs filed under a category or any of its
+// subcategories
+func (s *SKUQueryService) FindInCategory(ctx context.Context, categoryID string, activeOnly bool) ([]*domain.SKU, error) {
+	id, err := valueobjects.CategoryIDFrom(categoryID)
+	if err != nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if _, err := s.categories.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	all, err := s.categories.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subtree := domain.Subtree(all, id)
+
+	var skus []*domain.SKU
+	if activeOnly {
+		skus, err = s.repo.FindAllActive(ctx)
+	} else {
+		skus, err = s.repo.FindAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*domain.SKU
+	for _, sku := range skus {
+		if subtree[sku.CategoryID()] {
+			filtered = append(filtered, sku)
+		}
+	}
+	return filtered, nil
+}
+
+// CategoryQueryService provides read-only access to the category tree
+type CategoryQueryService struct {
+	repo domain.CategoryRepository
+}
+
+func NewCategoryQueryService(repo domain.CategoryRepository) *CategoryQueryService {
+	return &CategoryQueryService{repo: repo}
+}
+
+func (s *CategoryQueryService) FindByID(ctx context.Context, id string) (*domain.Category, error) {
+	categoryID, err := valueobjects.CategoryIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return s.repo.FindByID(ctx, categoryID)
+}
+
+func (s *CategoryQueryService) FindAll(ctx context.Context) ([]*domain.Category, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// TrainingImageQueryService reads SKU training images and assembles them into
+// datasets for the ML team
+type TrainingImageQueryService struct {
+	skus    domain.SKURepository
+	images  domain.TrainingImageRepository
+	storage ObjectStorage
+}
+
+func NewTrainingImageQueryService(skus domain.SKURepository, images domain.TrainingImageRepository, storage ObjectStorage) *TrainingImageQueryService {
+	return &TrainingImageQueryService{skus: skus, images: images, storage: storage}
+}
+
+// ListForSKU lists a SKU's training images, oldest first
+func (s *TrainingImageQueryService) ListForSKU(ctx context.Context, id string) ([]domain.TrainingImage, error) {
+	skuID, err := valueobjects.SKUIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+	if _, err := s.skus.FindByID(ctx, skuID); err != nil {
+		return nil, err
+	}
+	return s.images.FindBySKU(ctx, skuID)
+}
+
+// Content returns a training image with the image itself
+func (s *TrainingImageQueryService) Content(ctx context.Context, ref TrainingImageRef) (domain.TrainingImage, []byte, error) {
+	image, err := findTrainingImage(ctx, s.images, ref)
+	if err != nil {
+		return domain.TrainingImage{}, nil, err
+	}
+	data, _, err := s.storage.Get(ctx, image.ObjectKey)
+	if err != nil {
+		return domain.TrainingImage{}, nil, fmt.Errorf("failed to read training image: %w", err)
+	}
+	return image, data, nil
+}
+
+// DatasetEntry is a training image with the SKU code it is labeled with
+type DatasetEntry struct {
+	Image   domain.TrainingImage
+	SKUCode string
+}
+
+// Dataset lists the training images of every SKU in the catalog; images of
+// deleted SKUs are left out
+func (s *TrainingImageQueryService) Dataset(ctx context.Context) ([]DatasetEntry, error) {
+	skus, err := s.skus.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[valueobjects.SKUID]string, len(skus))
+	for _, sku := range skus {
+		codes[sku.ID()] = sku.Code()
+	}
+
+	images, err := s.images.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var dataset []DatasetEntry
+	for _, image := range images {
+		if code, ok := codes[image.SKUID]; ok {
+			dataset = append(dataset, DatasetEntry{Image: image, SKUCode: code})
+		}
+	}
+	return dataset, nil
+}
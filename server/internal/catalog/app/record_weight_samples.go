@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RecordWeightSamplesCommand is the input DTO for recording weighed units of a SKU
+type RecordWeightSamplesCommand struct {
+	SKUID string
+	Grams []float64
+}
+
+// RecordWeightSamplesHandler records calibration weighings of a SKU. Once a SKU
+// has enough of them, detection checks baskets against their measured mean and
+// spread rather than the nominal weight.
+type RecordWeightSamplesHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewRecordWeightSamplesHandler(skus domain.SKURepository, publisher EventPublisher) *RecordWeightSamplesHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordWeightSamplesHandler{skus: skus, publisher: publisher}
+}
+
+func (h *RecordWeightSamplesHandler) Handle(ctx context.Context, cmd RecordWeightSamplesCommand) (*domain.SKU, error) {
+	skuID, err := valueobjects.SKUIDFrom(cmd.SKUID)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+
+	s, err := h.skus.FindByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+	if s.IsDeleted() {
+		return nil, domain.ErrSKUDeleted
+	}
+
+	if err := s.RecordWeightSamples(cmd.Grams); err != nil {
+		return nil, err
+	}
+
+	if err := h.skus.Save(ctx, s); err != nil {
+		return nil, fmt.Errorf("failed to save SKU: %w", err)
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// AssignClassCommand is the input DTO for mapping a detection model class to a SKU
+type AssignClassCommand struct {
+	SKUID   string
+	ClassID *int // nil takes the class ID after the highest one in use
+}
+
+// AssignClassHandler maps a new detection model class to a SKU
+type AssignClassHandler struct {
+	skus      domain.SKURepository
+	classes   domain.SKUClassRepository
+	publisher EventPublisher
+}
+
+func NewAssignClassHandler(skus domain.SKURepository, classes domain.SKUClassRepository, publisher EventPublisher) *AssignClassHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if classes == nil {
+		panic("nil SKUClassRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AssignClassHandler{skus: skus, classes: classes, publisher: publisher}
+}
+
+func (h *AssignClassHandler) Handle(ctx context.Context, cmd AssignClassCommand) (ClassMapping, error) {
+	s, err := classifiableSKU(ctx, h.skus, cmd.SKUID)
+	if err != nil {
+		return ClassMapping{}, err
+	}
+
+	var classID int
+	if cmd.ClassID != nil {
+		classID = *cmd.ClassID
+	} else if classID, err = h.classes.NextClassID(ctx); err != nil {
+		return ClassMapping{}, err
+	}
+
+	class, err := domain.NewSKUClass(classID, s.ID())
+	if err != nil {
+		return ClassMapping{}, err
+	}
+	if err := h.classes.Add(ctx, class); err != nil {
+		return ClassMapping{}, err
+	}
+
+	_ = h.publisher.Publish(ctx, domain.NewSKUClassAssigned(class.ClassID, s.ID()))
+	return toClassMapping(class, s), nil
+}
+
+// ReassignClassCommand is the input DTO for pointing a class at another SKU
+type ReassignClassCommand struct {
+	ClassID int
+	SKUID   string
+}
+
+// ReassignClassHandler points an existing class at another SKU, e.g. when a
+// product is replaced by a successor that looks the same
+type ReassignClassHandler struct {
+	skus      domain.SKURepository
+	classes   domain.SKUClassRepository
+	publisher EventPublisher
+}
+
+func NewReassignClassHandler(skus domain.SKURepository, classes domain.SKUClassRepository, publisher EventPublisher) *ReassignClassHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if classes == nil {
+		panic("nil SKUClassRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ReassignClassHandler{skus: skus, classes: classes, publisher: publisher}
+}
+
+func (h *ReassignClassHandler) Handle(ctx context.Context, cmd ReassignClassCommand) (ClassMapping, error) {
+	if _, err := h.classes.FindByClassID(ctx, cmd.ClassID); err != nil {
+		return ClassMapping{}, err
+	}
+	s, err := classifiableSKU(ctx, h.skus, cmd.SKUID)
+	if err != nil {
+		return ClassMapping{}, err
+	}
+
+	class, err := domain.NewSKUClass(cmd.ClassID, s.ID())
+	if err != nil {
+		return ClassMapping{}, err
+	}
+	if err := h.classes.Update(ctx, class); err != nil {
+		return ClassMapping{}, err
+	}
+
+	_ = h.publisher.Publish(ctx, domain.NewSKUClassAssigned(class.ClassID, s.ID()))
+	return toClassMapping(class, s), nil
+}
+
+// RemoveClassHandler takes a class out of the mapping
+type RemoveClassHandler struct {
+	classes   domain.SKUClassRepository
+	publisher EventPublisher
+}
+
+func NewRemoveClassHandler(classes domain.SKUClassRepository, publisher EventPublisher) *RemoveClassHandler {
+	if classes == nil {
+		panic("nil SKUClassRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RemoveClassHandler{classes: classes, publisher: publisher}
+}
+
+func (h *RemoveClassHandler) Handle(ctx context.Context, classID int) error {
+	class, err := h.classes.FindByClassID(ctx, classID)
+	if err != nil {
+		return err
+	}
+	if err := h.classes.Delete(ctx, classID); err != nil {
+		return err
+	}
+
+	_ = h.publisher.Publish(ctx, domain.NewSKUClassRemoved(class.ClassID, class.SKUID))
+	return nil
+}
+
+// ClassQueryService lists the class mapping with the codes of the SKUs
+type ClassQueryService struct {
+	skus    domain.SKURepository
+	classes domain.SKUClassRepository
+}
+
+func NewClassQueryService(skus domain.SKURepository, classes domain.SKUClassRepository) *ClassQueryService {
+	return &ClassQueryService{skus: skus, classes: classes}
+}
+
+// List returns every class by class ID, those of retired and deleted SKUs included
+func (s *ClassQueryService) List(ctx context.Context) ([]ClassMapping, error) {
+	classes, err := s.classes.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skus, err := s.skus.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[valueobjects.SKUID]*domain.SKU, len(skus))
+	for _, sku := range skus {
+		byID[sku.ID()] = sku
+	}
+
+	mappings := make([]ClassMapping, 0, len(classes))
+	for _, class := range classes {
+		sku, ok := byID[class.SKUID]
+		if !ok {
+			// Listings leave deleted SKUs out
+			if sku, err = s.skus.FindByID(ctx, class.SKUID); err != nil {
+				return nil, err
+			}
+		}
+		mappings = append(mappings, toClassMapping(class, sku))
+	}
+	return mappings, nil
+}
+
+func (s *ClassQueryService) Get(ctx context.Context, classID int) (ClassMapping, error) {
+	class, err := s.classes.FindByClassID(ctx, classID)
+	if err != nil {
+		return ClassMapping{}, err
+	}
+	sku, err := s.skus.FindByID(ctx, class.SKUID)
+	if err != nil {
+		return ClassMapping{}, err
+	}
+	return toClassMapping(class, sku), nil
+}
+
+// classifiableSKU loads a SKU that can be given a class: one that is not
+// deleted and not a bundle
+func classifiableSKU(ctx context.Context, skus domain.SKURepository, id string) (*domain.SKU, error) {
+	skuID, err := valueobjects.SKUIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+	s, err := skus.FindByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+	if s.IsDeleted() {
+		return nil, domain.ErrSKUDeleted
+	}
+	if s.IsBundle() {
+		return nil, domain.ErrBundleNotClassified
+	}
+	return s, nil
+}
+
+func toClassMapping(class domain.SKUClass, s *domain.SKU) ClassMapping {
+	return ClassMapping{ClassID: class.ClassID, SKUID: s.ID().String(), ClassName: s.Code()}
+}
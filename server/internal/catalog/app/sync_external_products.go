@@ -0,0 +1,259 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// externalChangedBy is kept in the price history for prices pushed by the
+// product-information system
+const externalChangedBy = "pim-sync"
+
+// ExternalProductInput is a product pushed by the external
+// product-information system
+type ExternalProductInput struct {
+	ExternalID      string
+	Code            string // the SKU code; links the product on its first push
+	Name            string
+	PriceCents      int64
+	Currency        string
+	WeightGrams     float64
+	WeightTolerance float64
+	ImageURL        string
+	Attributes      map[string]any                // nil keeps the current attributes
+	ProductInfo     *ProductInfoInput             // nil keeps the current nutrition facts and allergens
+	Translations    map[string]domain.Translation // nil keeps the current translations
+}
+
+// SyncExternalProductsCommand is the input DTO for one push of the
+// product-information system
+type SyncExternalProductsCommand struct {
+	Products []ExternalProductInput
+}
+
+// SyncedProduct is a product created or updated by a push
+type SyncedProduct struct {
+	ExternalID string
+	SKUID      string
+	Code       string
+	Created    bool
+}
+
+// ExternalProductError explains why a pushed product was rejected
+type ExternalProductError struct {
+	ExternalID string
+	Error      string
+}
+
+// SyncExternalProductsResult is the output DTO. Either Errors is empty and
+// every product was synced, or nothing was.
+type SyncExternalProductsResult struct {
+	Products []SyncedProduct
+	Errors   []ExternalProductError
+}
+
+// SyncExternalProductsHandler upserts the SKUs pushed by the external
+// product-information system. A product is found by its external ID; the
+// first push of a product links it to the SKU with the code it names,
+// creating that SKU when the catalog has none. Every product is validated
+// first, so a push with a bad product changes nothing.
+type SyncExternalProductsHandler struct {
+	skus      domain.SKURepository
+	links     domain.ExternalLinkRepository
+	publisher EventPublisher
+}
+
+func NewSyncExternalProductsHandler(skus domain.SKURepository, links domain.ExternalLinkRepository, publisher EventPublisher) *SyncExternalProductsHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if links == nil {
+		panic("nil ExternalLinkRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &SyncExternalProductsHandler{skus: skus, links: links, publisher: publisher}
+}
+
+// productChange is a validated product waiting to be saved
+type productChange struct {
+	sku      *domain.SKU
+	oldPrice *valueobjects.Money  // nil for a new SKU
+	link     *domain.ExternalLink // nil when the product is linked already
+}
+
+func (h *SyncExternalProductsHandler) Handle(ctx context.Context, cmd SyncExternalProductsCommand) (SyncExternalProductsResult, error) {
+	var result SyncExternalProductsResult
+	changes := make([]productChange, 0, len(cmd.Products))
+	seen := make(map[string]bool, len(cmd.Products))
+	codes := make(map[string]string, len(cmd.Products)) // SKU code -> external ID
+
+	for _, p := range cmd.Products {
+		p.ExternalID = strings.TrimSpace(p.ExternalID)
+		if seen[p.ExternalID] {
+			result.Errors = append(result.Errors, ExternalProductError{ExternalID: p.ExternalID, Error: "product is pushed twice"})
+			continue
+		}
+		seen[p.ExternalID] = true
+
+		change, err := h.prepare(ctx, p)
+		if err != nil {
+			result.Errors = append(result.Errors, ExternalProductError{ExternalID: p.ExternalID, Error: err.Error()})
+			continue
+		}
+		if other, ok := codes[change.sku.Code()]; ok {
+			result.Errors = append(result.Errors, ExternalProductError{ExternalID: p.ExternalID, Error: fmt.Sprintf("SKU code %s is pushed for %s as well", change.sku.Code(), other)})
+			continue
+		}
+		codes[change.sku.Code()] = p.ExternalID
+		changes = append(changes, change)
+		result.Products = append(result.Products, SyncedProduct{
+			ExternalID: p.ExternalID,
+			SKUID:      change.sku.ID().String(),
+			Code:       change.sku.Code(),
+			Created:    change.oldPrice == nil,
+		})
+	}
+
+	if len(result.Errors) > 0 {
+		result.Products = nil
+		return result, nil
+	}
+
+	for _, change := range changes {
+		if err := h.save(ctx, change); err != nil {
+			return SyncExternalProductsResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// prepare finds or creates the product's SKU and applies the pushed fields to it
+func (h *SyncExternalProductsHandler) prepare(ctx context.Context, p ExternalProductInput) (productChange, error) {
+	code := strings.TrimSpace(p.Code)
+
+	link, err := h.links.FindByExternalID(ctx, p.ExternalID)
+	switch {
+	case err == nil:
+		s, err := h.skus.FindByID(ctx, link.SKUID)
+		if err != nil {
+			return productChange{}, err
+		}
+		if code != "" && code != s.Code() {
+			return productChange{}, fmt.Errorf("product is sold as %s; SKU codes cannot change", s.Code())
+		}
+		return h.update(s, p)
+	case !errors.Is(err, domain.ErrExternalLinkNotFound):
+		return productChange{}, err
+	}
+
+	if code == "" {
+		return productChange{}, errors.New("a SKU code is required to link a new product")
+	}
+
+	s, err := h.skus.FindByCode(ctx, code)
+	switch {
+	case err == nil:
+		// A SKU created by hand before the system pushed it
+		if _, err := h.links.FindBySKUID(ctx, s.ID()); err == nil {
+			return productChange{}, domain.ErrExternalLinkConflict
+		} else if !errors.Is(err, domain.ErrExternalLinkNotFound) {
+			return productChange{}, err
+		}
+		change, err := h.update(s, p)
+		if err != nil {
+			return productChange{}, err
+		}
+		return h.linked(change, p.ExternalID)
+	case !errors.Is(err, domain.ErrSKUNotFound):
+		return productChange{}, err
+	}
+
+	s, err = newSKU(CreateSKUCommand{
+		Code:            code,
+		Name:            strings.TrimSpace(p.Name),
+		PriceCents:      p.PriceCents,
+		Currency:        p.Currency,
+		WeightGrams:     p.WeightGrams,
+		WeightTolerance: p.WeightTolerance,
+		ImageURL:        p.ImageURL,
+		Attributes:      p.Attributes,
+		ProductInfo:     p.ProductInfo,
+		Translations:    p.Translations,
+	}, valueobjects.CategoryID{})
+	if err != nil {
+		return productChange{}, err
+	}
+	return h.linked(productChange{sku: s}, p.ExternalID)
+}
+
+func (h *SyncExternalProductsHandler) update(s *domain.SKU, p ExternalProductInput) (productChange, error) {
+	if s.IsDeleted() {
+		return productChange{}, domain.ErrSKUDeleted
+	}
+
+	oldPrice := s.Price()
+	if err := s.Update(strings.TrimSpace(p.Name), p.PriceCents, p.Currency, p.WeightGrams, p.WeightTolerance, p.ImageURL); err != nil {
+		return productChange{}, err
+	}
+	if p.Attributes != nil {
+		attrs, err := domain.NewAttributes(p.Attributes)
+		if err != nil {
+			return productChange{}, err
+		}
+		s.SetAttributes(attrs)
+	}
+	if p.ProductInfo != nil {
+		if err := setProductInfo(s, *p.ProductInfo); err != nil {
+			return productChange{}, err
+		}
+	}
+	if p.Translations != nil {
+		translations, err := domain.NewTranslations(p.Translations)
+		if err != nil {
+			return productChange{}, err
+		}
+		s.SetTranslations(translations)
+	}
+	return productChange{sku: s, oldPrice: &oldPrice}, nil
+}
+
+func (h *SyncExternalProductsHandler) linked(change productChange, externalID string) (productChange, error) {
+	link, err := domain.NewExternalLink(externalID, change.sku.ID())
+	if err != nil {
+		return productChange{}, err
+	}
+	change.link = &link
+	return change, nil
+}
+
+// save persists a change; the link is saved after the SKU it points to
+func (h *SyncExternalProductsHandler) save(ctx context.Context, change productChange) error {
+	s := change.sku
+	var err error
+	if change.oldPrice == nil || s.Price().Equals(*change.oldPrice) {
+		err = h.skus.Save(ctx, s)
+	} else {
+		priceChange := domain.NewPriceChange(s.ID(), *change.oldPrice, s.Price(), externalChangedBy, s.UpdatedAt())
+		err = h.skus.SaveWithPriceChange(ctx, s, priceChange)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save SKU: %w", err)
+	}
+	if change.link != nil {
+		if err := h.links.Save(ctx, *change.link); err != nil {
+			return fmt.Errorf("failed to link external product: %w", err)
+		}
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ObjectStorage is an output port for keeping binary objects outside the database
+type ObjectStorage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AddTrainingImageCommand is the input DTO for attaching a training image to a SKU
+type AddTrainingImageCommand struct {
+	SKUID      string
+	Image      []byte
+	Box        *domain.BoundingBox // optional
+	Source     string              // optional, e.g. studio or device
+	UploadedBy string
+}
+
+// AddTrainingImageHandler attaches labeled training images to SKUs. The
+// image goes to object storage first, so stored metadata always points at
+// an image; an image whose metadata cannot be saved is removed again.
+type AddTrainingImageHandler struct {
+	skus    domain.SKURepository
+	images  domain.TrainingImageRepository
+	storage ObjectStorage
+}
+
+func NewAddTrainingImageHandler(skus domain.SKURepository, images domain.TrainingImageRepository, storage ObjectStorage) *AddTrainingImageHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if images == nil {
+		panic("nil TrainingImageRepository")
+	}
+	if storage == nil {
+		panic("nil ObjectStorage")
+	}
+	return &AddTrainingImageHandler{skus: skus, images: images, storage: storage}
+}
+
+func (h *AddTrainingImageHandler) Handle(ctx context.Context, cmd AddTrainingImageCommand) (domain.TrainingImage, error) {
+	skuID, err := valueobjects.SKUIDFrom(cmd.SKUID)
+	if err != nil {
+		return domain.TrainingImage{}, domain.ErrSKUNotFound
+	}
+	s, err := h.skus.FindByID(ctx, skuID)
+	if err != nil {
+		return domain.TrainingImage{}, err
+	}
+	if s.IsDeleted() {
+		return domain.TrainingImage{}, domain.ErrSKUDeleted
+	}
+
+	image, err := domain.NewTrainingImage(s.ID(), cmd.Image, cmd.Box, cmd.Source, cmd.UploadedBy)
+	if err != nil {
+		return domain.TrainingImage{}, err
+	}
+
+	if err := h.storage.Put(ctx, image.ObjectKey, image.ContentType, cmd.Image); err != nil {
+		return domain.TrainingImage{}, fmt.Errorf("failed to store training image: %w", err)
+	}
+	if err := h.images.Save(ctx, image); err != nil {
+		_ = h.storage.Delete(ctx, image.ObjectKey)
+		return domain.TrainingImage{}, err
+	}
+	return image, nil
+}
+
+// TrainingImageRef names a training image of a SKU
+type TrainingImageRef struct {
+	SKUID   string
+	ImageID string
+}
+
+// DeleteTrainingImageHandler removes a training image from a SKU's dataset
+type DeleteTrainingImageHandler struct {
+	images  domain.TrainingImageRepository
+	storage ObjectStorage
+}
+
+func NewDeleteTrainingImageHandler(images domain.TrainingImageRepository, storage ObjectStorage) *DeleteTrainingImageHandler {
+	if images == nil {
+		panic("nil TrainingImageRepository")
+	}
+	if storage == nil {
+		panic("nil ObjectStorage")
+	}
+	return &DeleteTrainingImageHandler{images: images, storage: storage}
+}
+
+func (h *DeleteTrainingImageHandler) Handle(ctx context.Context, ref TrainingImageRef) error {
+	image, err := findTrainingImage(ctx, h.images, ref)
+	if err != nil {
+		return err
+	}
+	if err := h.images.Delete(ctx, image.ID); err != nil {
+		return err
+	}
+	// The metadata is gone, so a leftover object is unreachable but harmless
+	_ = h.storage.Delete(ctx, image.ObjectKey)
+	return nil
+}
+
+// findTrainingImage loads an image, which must belong to the referenced SKU
+func findTrainingImage(ctx context.Context, images domain.TrainingImageRepository, ref TrainingImageRef) (domain.TrainingImage, error) {
+	id, err := valueobjects.TrainingImageIDFrom(ref.ImageID)
+	if err != nil {
+		return domain.TrainingImage{}, domain.ErrTrainingImageNotFound
+	}
+	image, err := images.FindByID(ctx, id)
+	if err != nil {
+		return domain.TrainingImage{}, err
+	}
+	if image.SKUID.String() != ref.SKUID {
+		return domain.TrainingImage{}, domain.ErrTrainingImageNotFound
+	}
+	return image, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// UpdateCategoryCommand is the input DTO for renaming or moving a category
+type UpdateCategoryCommand struct {
+	CategoryID string
+	Name       string
+	ParentID   string // empty moves the category to the top level
+}
+
+// UpdateCategoryHandler renames a category and moves it within the tree
+type UpdateCategoryHandler struct {
+	categories domain.CategoryRepository
+	publisher  EventPublisher
+}
+
+func NewUpdateCategoryHandler(categories domain.CategoryRepository, publisher EventPublisher) *UpdateCategoryHandler {
+	if categories == nil {
+		panic("nil CategoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &UpdateCategoryHandler{
+		categories: categories,
+		publisher:  publisher,
+	}
+}
+
+func (h *UpdateCategoryHandler) Handle(ctx context.Context, cmd UpdateCategoryCommand) (*domain.Category, error) {
+	id, err := valueobjects.CategoryIDFrom(cmd.CategoryID)
+	if err != nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	c, err := h.categories.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID, err := existingCategoryID(ctx, h.categories, cmd.ParentID)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return nil, domain.ErrParentCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !parentID.IsZero() && parentID != c.ParentID() {
+		all, err := h.categories.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if domain.Subtree(all, c.ID())[parentID] {
+			return nil, domain.ErrCategoryCycle
+		}
+	}
+
+	if err := c.Update(cmd.Name, parentID); err != nil {
+		return nil, err
+	}
+
+	if err := h.categories.Save(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+
+	for _, evt := range c.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return c, nil
+}
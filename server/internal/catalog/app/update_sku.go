@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// UpdateSKUCommand is the input DTO for editing a SKU. The code and category
+// are changed elsewhere.
+type UpdateSKUCommand struct {
+	SKUID           string
+	Name            string
+	PriceCents      int64
+	Currency        string
+	WeightGrams     float64
+	WeightTolerance float64
+	ImageURL        string
+	Attributes      map[string]any                // nil keeps the current attributes, empty clears them
+	ProductInfo     *ProductInfoInput             // nil keeps the current nutrition facts and allergens
+	Translations    map[string]domain.Translation // nil keeps the current translations, empty clears them
+	ShelfLifeDays   *int                          // nil keeps the current shelf life, 0 marks the SKU not perishable
+	Restocking      *RestockingInput              // nil keeps the current restocking information
+	ChangedBy       string                        // staff member, kept in the price history
+}
+
+// UpdateSKUHandler edits a SKU. A price change is written to the SKU's price
+// history in the same transaction as the SKU itself, so the audit trail can't
+// miss one.
+type UpdateSKUHandler struct {
+	skus      domain.SKURepository
+	publisher EventPublisher
+}
+
+func NewUpdateSKUHandler(skus domain.SKURepository, publisher EventPublisher) *UpdateSKUHandler {
+	if skus == nil {
+		panic("nil SKURepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &UpdateSKUHandler{skus: skus, publisher: publisher}
+}
+
+func (h *UpdateSKUHandler) Handle(ctx context.Context, cmd UpdateSKUCommand) (*domain.SKU, error) {
+	cmd.ChangedBy = strings.TrimSpace(cmd.ChangedBy)
+	if cmd.ChangedBy == "" {
+		return nil, domain.ErrChangedByRequired
+	}
+
+	skuID, err := valueobjects.SKUIDFrom(cmd.SKUID)
+	if err != nil {
+		return nil, domain.ErrSKUNotFound
+	}
+
+	s, err := h.skus.FindByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+	if s.IsDeleted() {
+		return nil, domain.ErrSKUDeleted
+	}
+
+	oldPrice := s.Price()
+	if err := s.Update(cmd.Name, cmd.PriceCents, cmd.Currency, cmd.WeightGrams, cmd.WeightTolerance, cmd.ImageURL); err != nil {
+		return nil, err
+	}
+	if cmd.Attributes != nil {
+		attrs, err := domain.NewAttributes(cmd.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		s.SetAttributes(attrs)
+	}
+	if cmd.ProductInfo != nil {
+		if err := setProductInfo(s, *cmd.ProductInfo); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.Translations != nil {
+		translations, err := domain.NewTranslations(cmd.Translations)
+		if err != nil {
+			return nil, err
+		}
+		s.SetTranslations(translations)
+	}
+	if cmd.ShelfLifeDays != nil {
+		if err := s.SetShelfLife(*cmd.ShelfLifeDays); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.Restocking != nil {
+		info, err := domain.NewRestockingInfo(cmd.Restocking.SupplierRef, cmd.Restocking.CaseSize, cmd.Restocking.ReorderPoint)
+		if err != nil {
+			return nil, err
+		}
+		s.SetRestocking(info)
+	}
+
+	if s.Price().Equals(oldPrice) {
+		err = h.skus.Save(ctx, s)
+	} else {
+		change := domain.NewPriceChange(s.ID(), oldPrice, s.Price(), cmd.ChangedBy, s.UpdatedAt())
+		err = h.skus.SaveWithPriceChange(ctx, s, change)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save SKU: %w", err)
+	}
+
+	for _, evt := range s.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxAttributes          = 32
+	maxAttributeValueLen   = 200
+	maxAttributeListLength = 32
+)
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// Attributes are free-form product facts such as brand, size, flavor or
+// allergens. Keys are lower-case identifiers; a value is a string, number,
+// boolean or a list of strings.
+type Attributes map[string]any
+
+// NewAttributes validates raw attributes, e.g. decoded from JSON, and
+// normalizes lists to []string
+func NewAttributes(raw map[string]any) (Attributes, error) {
+	if len(raw) > maxAttributes {
+		return nil, fmt.Errorf("%w: at most %d are allowed", ErrInvalidSKUAttributes, maxAttributes)
+	}
+
+	attrs := make(Attributes, len(raw))
+	for key, value := range raw {
+		if !attributeKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w: key %q must be lower-case letters, digits and underscores", ErrInvalidSKUAttributes, key)
+		}
+		normalized, err := normalizeAttribute(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidSKUAttributes, key, err)
+		}
+		attrs[key] = normalized
+	}
+	return attrs, nil
+}
+
+func normalizeAttribute(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if v == "" || len(v) > maxAttributeValueLen {
+			return nil, fmt.Errorf("must be 1 to %d characters", maxAttributeValueLen)
+		}
+		return v, nil
+	case float64, bool:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case []string:
+		return normalizeAttributeList(v)
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			list = append(list, s)
+		}
+		return normalizeAttributeList(list)
+	default:
+		return nil, fmt.Errorf("must be a string, number, boolean or list of strings")
+	}
+}
+
+func normalizeAttributeList(list []string) ([]string, error) {
+	if len(list) > maxAttributeListLength {
+		return nil, fmt.Errorf("may list at most %d values", maxAttributeListLength)
+	}
+	out := make([]string, 0, len(list))
+	for _, s := range list {
+		if s == "" || len(s) > maxAttributeValueLen {
+			return nil, fmt.Errorf("values must be 1 to %d characters", maxAttributeValueLen)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Matches reports whether every filter attribute has the wanted value,
+// ignoring case. A list matches when it contains the value.
+func (a Attributes) Matches(filter map[string]string) bool {
+	for key, want := range filter {
+		if !a.has(key, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Attributes) has(key, want string) bool {
+	switch v := a[key].(type) {
+	case string:
+		return strings.EqualFold(v, want)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64) == want
+	case bool:
+		return strconv.FormatBool(v) == strings.ToLower(want)
+	case []string:
+		for _, s := range v {
+			if strings.EqualFold(s, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// BundleComponent is one SKU of a bundle and how many units of it the
+// bundle takes
+type BundleComponent struct {
+	SKUID    valueobjects.SKUID
+	Quantity int
+}
+
+// NewBundle creates a bundle SKU: a combo of other SKUs sold together for
+// its own price. The weight is that of all its units together.
+func NewBundle(code, name string, priceCents int64, currency string, weightGrams float64, components []BundleComponent) (*SKU, error) {
+	units := 0
+	seen := make(map[valueobjects.SKUID]bool, len(components))
+	for _, c := range components {
+		if c.Quantity < 1 || seen[c.SKUID] {
+			return nil, ErrInvalidBundle
+		}
+		seen[c.SKUID] = true
+		units += c.Quantity
+	}
+	if units < 2 {
+		return nil, ErrInvalidBundle
+	}
+
+	s, err := NewSKU(code, name, priceCents, currency, weightGrams)
+	if err != nil {
+		return nil, err
+	}
+	s.components = append([]BundleComponent{}, components...)
+	return s, nil
+}
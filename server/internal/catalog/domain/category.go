@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// Category groups SKUs for browsing and reporting. Categories form a tree;
+// a zero parent ID makes a top-level category.
+type Category struct {
+	id        valueobjects.CategoryID
+	name      string
+	parentID  valueobjects.CategoryID
+	createdAt time.Time
+	updatedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewCategory creates a category below parentID (zero for top level)
+func NewCategory(name string, parentID valueobjects.CategoryID) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCategoryName
+	}
+
+	now := time.Now().UTC()
+	c := &Category{
+		id:        valueobjects.NewCategoryID(),
+		name:      name,
+		parentID:  parentID,
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	c.domainEvents = append(c.domainEvents, NewCategoryCreated(c.id, name, parentID))
+
+	return c, nil
+}
+
+// ReconstituteCategory rebuilds a Category from persistence (no validation, no events)
+func ReconstituteCategory(id valueobjects.CategoryID, name string, parentID valueobjects.CategoryID, createdAt, updatedAt time.Time) *Category {
+	return &Category{
+		id:        id,
+		name:      name,
+		parentID:  parentID,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+// Getters
+func (c *Category) ID() valueobjects.CategoryID       { return c.id }
+func (c *Category) Name() string                      { return c.name }
+func (c *Category) ParentID() valueobjects.CategoryID { return c.parentID }
+func (c *Category) CreatedAt() time.Time              { return c.createdAt }
+func (c *Category) UpdatedAt() time.Time              { return c.updatedAt }
+
+// Update renames the category and moves it below parentID. The caller checks
+// that the new parent exists and is not one of the category's descendants.
+func (c *Category) Update(name string, parentID valueobjects.CategoryID) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidCategoryName
+	}
+	if parentID == c.id {
+		return ErrCategoryCycle
+	}
+
+	c.name = name
+	c.parentID = parentID
+	c.updatedAt = time.Now().UTC()
+
+	c.domainEvents = append(c.domainEvents, NewCategoryUpdated(c.id, name, parentID))
+
+	return nil
+}
+
+// MarkDeleted records the deletion event; the repository removes the row
+func (c *Category) MarkDeleted() {
+	c.domainEvents = append(c.domainEvents, NewCategoryDeleted(c.id))
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (c *Category) PullEvents() []events.DomainEvent {
+	evts := c.domainEvents
+	c.domainEvents = nil
+	return evts
+}
+
+// Subtree returns root and every category below it in the given tree
+func Subtree(categories []*Category, root valueobjects.CategoryID) map[valueobjects.CategoryID]bool {
+	children := make(map[valueobjects.CategoryID][]valueobjects.CategoryID)
+	for _, c := range categories {
+		if !c.parentID.IsZero() {
+			children[c.parentID] = append(children[c.parentID], c.id)
+		}
+	}
+
+	subtree := map[valueobjects.CategoryID]bool{root: true}
+	queue := []valueobjects.CategoryID{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if !subtree[child] {
+				subtree[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return subtree
+}
@@ -3,10 +3,47 @@ package domain
 import "errors"
 
 var (
-	ErrSKUNotFound      = errors.New("SKU not found")
-	ErrInvalidSKUCode   = errors.New("SKU code cannot be empty")
-	ErrInvalidSKUName   = errors.New("SKU name cannot be empty")
-	ErrInvalidSKUPrice  = errors.New("SKU price must be positive")
-	ErrInvalidSKUWeight = errors.New("SKU weight must be positive")
-	ErrDuplicateSKUCode = errors.New("SKU code already exists")
+	ErrSKUNotFound       = errors.New("SKU not found")
+	ErrInvalidSKUCode    = errors.New("SKU code cannot be empty")
+	ErrInvalidSKUName    = errors.New("SKU name cannot be empty")
+	ErrInvalidSKUPrice   = errors.New("SKU price must be positive")
+	ErrInvalidSKUWeight  = errors.New("SKU weight must be positive")
+	ErrDuplicateSKUCode  = errors.New("SKU code already exists")
+	ErrSKUDeleted        = errors.New("SKU is deleted")
+	ErrChangedByRequired = errors.New("the staff member making the change is required")
+
+	ErrInvalidLifecycle           = errors.New("lifecycle must be draft, published or retired")
+	ErrInvalidLifecycleTransition = errors.New("SKU cannot move to that lifecycle state")
+
+	ErrInvalidSKUAttributes  = errors.New("invalid SKU attributes")
+	ErrInvalidWeightSample   = errors.New("weight samples must be positive grams")
+	ErrInvalidProductInfo    = errors.New("invalid product information")
+	ErrInvalidTranslation    = errors.New("invalid SKU translation")
+	ErrInvalidShelfLife      = errors.New("shelf life must be between 0 and 3650 days")
+	ErrInvalidRestockingInfo = errors.New("invalid restocking information")
+
+	ErrInvalidBundle          = errors.New("a bundle needs at least two units of other SKUs, each SKU listed once")
+	ErrInvalidBundleComponent = errors.New("bundle components must be existing SKUs that are not bundles themselves")
+	ErrBundleCurrencyMismatch = errors.New("bundle components must be priced in the bundle's currency")
+
+	ErrInvalidTrainingImage   = errors.New("training image must be a JPEG, PNG or WebP image of at most 10 MB")
+	ErrInvalidBoundingBox     = errors.New("bounding box must lie within the image")
+	ErrTrainingImageNotFound  = errors.New("training image not found")
+	ErrDuplicateTrainingImage = errors.New("the SKU already has this training image")
+
+	ErrInvalidExternalID    = errors.New("external product ID must be 1 to 100 characters")
+	ErrExternalLinkNotFound = errors.New("external product is not linked to a SKU")
+	ErrExternalLinkConflict = errors.New("the SKU is linked to another external product")
+
+	ErrInvalidClassID       = errors.New("class ID must be between 0 and 9999")
+	ErrSKUClassNotFound     = errors.New("class not found")
+	ErrClassIDTaken         = errors.New("class ID is mapped to another SKU")
+	ErrSKUAlreadyClassified = errors.New("SKU is mapped to another class")
+	ErrBundleNotClassified  = errors.New("bundles are detected by their components and cannot have a class")
+
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrInvalidCategoryName    = errors.New("category name cannot be empty")
+	ErrCategoryCycle          = errors.New("category cannot be moved below itself")
+	ErrCategoryInUse          = errors.New("category still has subcategories")
 )
@@ -39,16 +39,198 @@ func NewSKUUpdated(id valueobjects.SKUID, name string) SKUUpdated {
 
 func (SKUUpdated) EventName() string { return "SKUUpdated" }
 
-type SKUDeactivated struct {
+// SKUPriceChanged is raised when an update changes a SKU's price
+type SKUPriceChanged struct {
+	events.BaseEvent
+	SKUID    valueobjects.SKUID
+	OldPrice valueobjects.Money
+	NewPrice valueobjects.Money
+}
+
+func NewSKUPriceChanged(id valueobjects.SKUID, oldPrice, newPrice valueobjects.Money) SKUPriceChanged {
+	return SKUPriceChanged{
+		BaseEvent: events.NewBaseEvent(),
+		SKUID:     id,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+	}
+}
+
+func (SKUPriceChanged) EventName() string { return "SKUPriceChanged" }
+
+// SKUPublished is raised when a draft or retired SKU goes on sale
+type SKUPublished struct {
+	events.BaseEvent
+	SKUID valueobjects.SKUID
+	From  Lifecycle
+}
+
+func NewSKUPublished(id valueobjects.SKUID, from Lifecycle) SKUPublished {
+	return SKUPublished{
+		BaseEvent: events.NewBaseEvent(),
+		SKUID:     id,
+		From:      from,
+	}
+}
+
+func (SKUPublished) EventName() string { return "SKUPublished" }
+
+// SKURetired is raised when a published SKU is taken off sale
+type SKURetired struct {
 	events.BaseEvent
 	SKUID valueobjects.SKUID
 }
 
-func NewSKUDeactivated(id valueobjects.SKUID) SKUDeactivated {
-	return SKUDeactivated{
+func NewSKURetired(id valueobjects.SKUID) SKURetired {
+	return SKURetired{
 		BaseEvent: events.NewBaseEvent(),
 		SKUID:     id,
 	}
 }
 
-func (SKUDeactivated) EventName() string { return "SKUDeactivated" }
+func (SKURetired) EventName() string { return "SKURetired" }
+
+type SKUDeleted struct {
+	events.BaseEvent
+	SKUID valueobjects.SKUID
+}
+
+func NewSKUDeleted(id valueobjects.SKUID) SKUDeleted {
+	return SKUDeleted{
+		BaseEvent: events.NewBaseEvent(),
+		SKUID:     id,
+	}
+}
+
+func (SKUDeleted) EventName() string { return "SKUDeleted" }
+
+type SKURestored struct {
+	events.BaseEvent
+	SKUID valueobjects.SKUID
+}
+
+func NewSKURestored(id valueobjects.SKUID) SKURestored {
+	return SKURestored{
+		BaseEvent: events.NewBaseEvent(),
+		SKUID:     id,
+	}
+}
+
+func (SKURestored) EventName() string { return "SKURestored" }
+
+// SKUWeightSampled is raised when weighed samples are recorded for a SKU
+type SKUWeightSampled struct {
+	events.BaseEvent
+	SKUID valueobjects.SKUID
+	Stats WeightStats
+}
+
+func NewSKUWeightSampled(id valueobjects.SKUID, stats WeightStats) SKUWeightSampled {
+	return SKUWeightSampled{
+		BaseEvent: events.NewBaseEvent(),
+		SKUID:     id,
+		Stats:     stats,
+	}
+}
+
+func (SKUWeightSampled) EventName() string { return "SKUWeightSampled" }
+
+type CategoryCreated struct {
+	events.BaseEvent
+	CategoryID valueobjects.CategoryID
+	Name       string
+	ParentID   valueobjects.CategoryID
+}
+
+func NewCategoryCreated(id valueobjects.CategoryID, name string, parentID valueobjects.CategoryID) CategoryCreated {
+	return CategoryCreated{
+		BaseEvent:  events.NewBaseEvent(),
+		CategoryID: id,
+		Name:       name,
+		ParentID:   parentID,
+	}
+}
+
+func (CategoryCreated) EventName() string { return "CategoryCreated" }
+
+type CategoryUpdated struct {
+	events.BaseEvent
+	CategoryID valueobjects.CategoryID
+	Name       string
+	ParentID   valueobjects.CategoryID
+}
+
+func NewCategoryUpdated(id valueobjects.CategoryID, name string, parentID valueobjects.CategoryID) CategoryUpdated {
+	return CategoryUpdated{
+		BaseEvent:  events.NewBaseEvent(),
+		CategoryID: id,
+		Name:       name,
+		ParentID:   parentID,
+	}
+}
+
+func (CategoryUpdated) EventName() string { return "CategoryUpdated" }
+
+type CategoryDeleted struct {
+	events.BaseEvent
+	CategoryID valueobjects.CategoryID
+}
+
+func NewCategoryDeleted(id valueobjects.CategoryID) CategoryDeleted {
+	return CategoryDeleted{
+		BaseEvent:  events.NewBaseEvent(),
+		CategoryID: id,
+	}
+}
+
+func (CategoryDeleted) EventName() string { return "CategoryDeleted" }
+
+type SKUCategoryChanged struct {
+	events.BaseEvent
+	SKUID      valueobjects.SKUID
+	CategoryID valueobjects.CategoryID
+}
+
+func NewSKUCategoryChanged(id valueobjects.SKUID, categoryID valueobjects.CategoryID) SKUCategoryChanged {
+	return SKUCategoryChanged{
+		BaseEvent:  events.NewBaseEvent(),
+		SKUID:      id,
+		CategoryID: categoryID,
+	}
+}
+
+func (SKUCategoryChanged) EventName() string { return "SKUCategoryChanged" }
+
+// SKUClassAssigned is raised when a detection model class is mapped to a SKU,
+// either for the first time or instead of another SKU
+type SKUClassAssigned struct {
+	events.BaseEvent
+	ClassID int
+	SKUID   valueobjects.SKUID
+}
+
+func NewSKUClassAssigned(classID int, skuID valueobjects.SKUID) SKUClassAssigned {
+	return SKUClassAssigned{
+		BaseEvent: events.NewBaseEvent(),
+		ClassID:   classID,
+		SKUID:     skuID,
+	}
+}
+
+func (SKUClassAssigned) EventName() string { return "SKUClassAssigned" }
+
+type SKUClassRemoved struct {
+	events.BaseEvent
+	ClassID int
+	SKUID   valueobjects.SKUID
+}
+
+func NewSKUClassRemoved(classID int, skuID valueobjects.SKUID) SKUClassRemoved {
+	return SKUClassRemoved{
+		BaseEvent: events.NewBaseEvent(),
+		ClassID:   classID,
+		SKUID:     skuID,
+	}
+}
+
+func (SKUClassRemoved) EventName() string { return "SKUClassRemoved" }
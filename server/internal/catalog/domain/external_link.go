@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const maxExternalIDLen = 100
+
+// ExternalLink ties a product of the external product-information system to
+// the SKU it is sold as. A product and a SKU are linked at most once.
+type ExternalLink struct {
+	ExternalID string
+	SKUID      valueobjects.SKUID
+	LinkedAt   time.Time
+}
+
+// NewExternalLink links an external product ID to a SKU
+func NewExternalLink(externalID string, skuID valueobjects.SKUID) (ExternalLink, error) {
+	externalID = strings.TrimSpace(externalID)
+	if externalID == "" || len(externalID) > maxExternalIDLen {
+		return ExternalLink{}, ErrInvalidExternalID
+	}
+	return ExternalLink{ExternalID: externalID, SKUID: skuID, LinkedAt: time.Now().UTC()}, nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+// Lifecycle is where a SKU stands between being prepared and being phased out.
+// Drafts can be set up and used to train detection models but are not sold;
+// only published SKUs are; retired SKUs are off sale but can be published again.
+type Lifecycle string
+
+const (
+	LifecycleDraft     Lifecycle = "draft"
+	LifecyclePublished Lifecycle = "published"
+	LifecycleRetired   Lifecycle = "retired"
+)
+
+// ParseLifecycle validates a lifecycle state
+func ParseLifecycle(s string) (Lifecycle, error) {
+	switch l := Lifecycle(s); l {
+	case LifecycleDraft, LifecyclePublished, LifecycleRetired:
+		return l, nil
+	}
+	return "", ErrInvalidLifecycle
+}
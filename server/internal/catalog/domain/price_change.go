@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PriceChange is one entry of a SKU's price history, kept for financial
+// audits. Entries are only ever appended.
+type PriceChange struct {
+	SKUID     valueobjects.SKUID
+	OldPrice  valueobjects.Money
+	NewPrice  valueobjects.Money
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+// NewPriceChange records that a SKU's price went from oldPrice to newPrice
+func NewPriceChange(skuID valueobjects.SKUID, oldPrice, newPrice valueobjects.Money, changedBy string, at time.Time) PriceChange {
+	return PriceChange{
+		SKUID:     skuID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		ChangedBy: changedBy,
+		ChangedAt: at,
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Allergen is one of the allergens food labels must declare (EU FIC Annex II)
+type Allergen string
+
+const (
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenMilk        Allergen = "milk"
+	AllergenNuts        Allergen = "nuts"
+	AllergenCelery      Allergen = "celery"
+	AllergenMustard     Allergen = "mustard"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSulphites   Allergen = "sulphites"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMolluscs    Allergen = "molluscs"
+)
+
+var knownAllergens = map[Allergen]bool{
+	AllergenGluten: true, AllergenCrustaceans: true, AllergenEggs: true, AllergenFish: true,
+	AllergenPeanuts: true, AllergenSoybeans: true, AllergenMilk: true, AllergenNuts: true,
+	AllergenCelery: true, AllergenMustard: true, AllergenSesame: true, AllergenSulphites: true,
+	AllergenLupin: true, AllergenMolluscs: true,
+}
+
+// NewAllergens validates declared allergens; duplicates are dropped and the
+// list is sorted
+func NewAllergens(raw []string) ([]Allergen, error) {
+	seen := make(map[Allergen]bool, len(raw))
+	allergens := make([]Allergen, 0, len(raw))
+	for _, r := range raw {
+		a := Allergen(r)
+		if !knownAllergens[a] {
+			return nil, fmt.Errorf("%w: unknown allergen %q", ErrInvalidProductInfo, r)
+		}
+		if !seen[a] {
+			seen[a] = true
+			allergens = append(allergens, a)
+		}
+	}
+	sort.Slice(allergens, func(i, j int) bool { return allergens[i] < allergens[j] })
+	return allergens, nil
+}
+
+// NutritionFacts is the nutrition declaration per 100 g or 100 ml, with the
+// serving size when the product states one
+type NutritionFacts struct {
+	ServingSizeGrams  float64 // optional
+	EnergyKcal        float64
+	FatGrams          float64
+	SaturatedFatGrams float64
+	CarbohydrateGrams float64
+	SugarsGrams       float64
+	ProteinGrams      float64
+	SaltGrams         float64
+}
+
+// Validate checks the facts are plausible: no negative amounts, the "of
+// which" amounts within their totals and no more than 100 g of nutrients
+func (n NutritionFacts) Validate() error {
+	for _, v := range []float64{n.ServingSizeGrams, n.EnergyKcal, n.FatGrams, n.SaturatedFatGrams,
+		n.CarbohydrateGrams, n.SugarsGrams, n.ProteinGrams, n.SaltGrams} {
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%w: nutrition amounts cannot be negative", ErrInvalidProductInfo)
+		}
+	}
+	if n.SaturatedFatGrams > n.FatGrams || n.SugarsGrams > n.CarbohydrateGrams {
+		return fmt.Errorf("%w: saturated fat and sugars cannot exceed fat and carbohydrate", ErrInvalidProductInfo)
+	}
+	if n.FatGrams+n.CarbohydrateGrams+n.ProteinGrams+n.SaltGrams > 100 {
+		return fmt.Errorf("%w: nutrients cannot exceed 100 g per 100 g", ErrInvalidProductInfo)
+	}
+	return nil
+}
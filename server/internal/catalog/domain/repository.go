@@ -2,15 +2,76 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
-// SKURepository is the PORT interface defined by the domain
+// SKURepository is the PORT interface defined by the domain. Lookups by ID or
+// code still find soft-deleted SKUs; the listings leave them out.
 type SKURepository interface {
 	Save(ctx context.Context, sku *SKU) error
+	// SaveAll saves the SKUs atomically: all of them or none
+	SaveAll(ctx context.Context, skus []*SKU) error
+	// SaveWithPriceChange saves the SKU and appends the change to its price
+	// history atomically
+	SaveWithPriceChange(ctx context.Context, sku *SKU, change PriceChange) error
+	// FindPriceHistory lists a SKU's price changes, oldest first
+	FindPriceHistory(ctx context.Context, id valueobjects.SKUID) ([]PriceChange, error)
 	FindByID(ctx context.Context, id valueobjects.SKUID) (*SKU, error)
 	FindByCode(ctx context.Context, code string) (*SKU, error)
 	FindAllActive(ctx context.Context) ([]*SKU, error)
 	FindAll(ctx context.Context) ([]*SKU, error)
+	// FindChangedSince lists the SKUs updated after the given time, deleted
+	// ones included, oldest change first
+	FindChangedSince(ctx context.Context, since time.Time) ([]*SKU, error)
+}
+
+// TrainingImageRepository keeps the metadata of SKU training images; the
+// images themselves are in object storage
+type TrainingImageRepository interface {
+	// Save adds the image; a second image of a SKU with the same checksum is
+	// rejected with ErrDuplicateTrainingImage
+	Save(ctx context.Context, image TrainingImage) error
+	FindByID(ctx context.Context, id valueobjects.TrainingImageID) (TrainingImage, error)
+	// FindBySKU lists a SKU's images, oldest first
+	FindBySKU(ctx context.Context, skuID valueobjects.SKUID) ([]TrainingImage, error)
+	// FindAll lists every image, grouped by SKU and oldest first
+	FindAll(ctx context.Context) ([]TrainingImage, error)
+	Delete(ctx context.Context, id valueobjects.TrainingImageID) error
+}
+
+// ExternalLinkRepository maps the product IDs of the external
+// product-information system to SKUs
+type ExternalLinkRepository interface {
+	// Save adds the link; linking a SKU or external ID a second time is
+	// rejected with ErrExternalLinkConflict
+	Save(ctx context.Context, link ExternalLink) error
+	FindByExternalID(ctx context.Context, externalID string) (ExternalLink, error)
+	FindBySKUID(ctx context.Context, skuID valueobjects.SKUID) (ExternalLink, error)
+}
+
+// SKUClassRepository keeps the class→SKU mapping of the detection model
+type SKUClassRepository interface {
+	// Add maps a new class; an ID in use is rejected with ErrClassIDTaken and a
+	// SKU that has a class with ErrSKUAlreadyClassified
+	Add(ctx context.Context, class SKUClass) error
+	// Update points an existing class at another SKU
+	Update(ctx context.Context, class SKUClass) error
+	FindByClassID(ctx context.Context, classID int) (SKUClass, error)
+	// FindAll lists the classes by class ID
+	FindAll(ctx context.Context) ([]SKUClass, error)
+	// NextClassID returns the class ID after the highest one in use
+	NextClassID(ctx context.Context) (int, error)
+	Delete(ctx context.Context, classID int) error
+}
+
+// CategoryRepository persists the category tree
+type CategoryRepository interface {
+	Save(ctx context.Context, category *Category) error
+	FindByID(ctx context.Context, id valueobjects.CategoryID) (*Category, error)
+	FindAll(ctx context.Context) ([]*Category, error)
+	// Delete removes the category and takes its SKUs, deleted ones included,
+	// out of it
+	Delete(ctx context.Context, id valueobjects.CategoryID) error
 }
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxSupplierRefLen = 64
+	maxCaseSize       = 10000
+	maxReorderPoint   = 10000
+)
+
+// RestockingInfo is how a SKU is bought in and when a device runs low on it
+type RestockingInfo struct {
+	SupplierRef  string // the supplier's article number; empty when unknown
+	CaseSize     int    // units per case ordered from the supplier; 0 when unknown
+	ReorderPoint int    // units left in a device at which it needs restocking; 0 when unset
+}
+
+// IsZero reports whether no restocking information is set
+func (r RestockingInfo) IsZero() bool {
+	return r == RestockingInfo{}
+}
+
+// NewRestockingInfo validates the supplier reference, case size and reorder point
+func NewRestockingInfo(supplierRef string, caseSize, reorderPoint int) (RestockingInfo, error) {
+	supplierRef = strings.TrimSpace(supplierRef)
+	if len(supplierRef) > maxSupplierRefLen {
+		return RestockingInfo{}, fmt.Errorf("%w: supplier reference is longer than %d characters", ErrInvalidRestockingInfo, maxSupplierRefLen)
+	}
+	if caseSize < 0 || caseSize > maxCaseSize {
+		return RestockingInfo{}, fmt.Errorf("%w: case size must be between 0 and %d units", ErrInvalidRestockingInfo, maxCaseSize)
+	}
+	if reorderPoint < 0 || reorderPoint > maxReorderPoint {
+		return RestockingInfo{}, fmt.Errorf("%w: reorder point must be between 0 and %d units", ErrInvalidRestockingInfo, maxReorderPoint)
+	}
+	return RestockingInfo{SupplierRef: supplierRef, CaseSize: caseSize, ReorderPoint: reorderPoint}, nil
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/vending-machine/server/internal/shared/events"
@@ -16,7 +17,17 @@ type SKU struct {
 	weight          valueobjects.Weight
 	weightTolerance float64
 	imageURL        string
-	active          bool
+	lifecycle       Lifecycle
+	categoryID      valueobjects.CategoryID // zero when uncategorized
+	attributes      Attributes              // brand, size, flavor, allergens, ...
+	components      []BundleComponent       // set on bundles only
+	weightSamples   []float64               // weighed units, oldest first
+	nutrition       *NutritionFacts         // nil when not declared
+	allergens       []Allergen              // sorted
+	translations    Translations            // names in other languages
+	shelfLifeDays   int                     // days a unit keeps after restock; 0 when not perishable
+	restocking      RestockingInfo          // supplier, case size and reorder point
+	deletedAt       *time.Time              // soft delete; the SKU still resolves for past sessions
 	createdAt       time.Time
 	updatedAt       time.Time
 
@@ -50,7 +61,7 @@ func NewSKU(code, name string, priceCents int64, currency string, weightGrams fl
 		price:           price,
 		weight:          weight,
 		weightTolerance: 5.0, // default tolerance in grams
-		active:          true,
+		lifecycle:       LifecyclePublished,
 		createdAt:       now,
 		updatedAt:       now,
 	}
@@ -60,6 +71,17 @@ func NewSKU(code, name string, priceCents int64, currency string, weightGrams fl
 	return s, nil
 }
 
+// NewDraftSKU creates a SKU that is prepared, and can be used to train
+// detection, but is not on sale until published
+func NewDraftSKU(code, name string, priceCents int64, currency string, weightGrams float64) (*SKU, error) {
+	s, err := NewSKU(code, name, priceCents, currency, weightGrams)
+	if err != nil {
+		return nil, err
+	}
+	s.lifecycle = LifecycleDraft
+	return s, nil
+}
+
 // Reconstitute rebuilds a SKU from persistence (no validation, no events)
 func Reconstitute(
 	id valueobjects.SKUID,
@@ -68,7 +90,17 @@ func Reconstitute(
 	weight valueobjects.Weight,
 	weightTolerance float64,
 	imageURL string,
-	active bool,
+	lifecycle Lifecycle,
+	categoryID valueobjects.CategoryID,
+	attributes Attributes,
+	components []BundleComponent,
+	weightSamples []float64,
+	nutrition *NutritionFacts,
+	allergens []Allergen,
+	translations Translations,
+	shelfLifeDays int,
+	restocking RestockingInfo,
+	deletedAt *time.Time,
 	createdAt, updatedAt time.Time,
 ) *SKU {
 	return &SKU{
@@ -79,23 +111,50 @@ func Reconstitute(
 		weight:          weight,
 		weightTolerance: weightTolerance,
 		imageURL:        imageURL,
-		active:          active,
+		lifecycle:       lifecycle,
+		categoryID:      categoryID,
+		attributes:      attributes,
+		components:      components,
+		weightSamples:   weightSamples,
+		nutrition:       nutrition,
+		allergens:       allergens,
+		translations:    translations,
+		shelfLifeDays:   shelfLifeDays,
+		restocking:      restocking,
+		deletedAt:       deletedAt,
 		createdAt:       createdAt,
 		updatedAt:       updatedAt,
 	}
 }
 
 // Getters
-func (s *SKU) ID() valueobjects.SKUID      { return s.id }
-func (s *SKU) Code() string                { return s.code }
-func (s *SKU) Name() string                { return s.name }
-func (s *SKU) Price() valueobjects.Money   { return s.price }
-func (s *SKU) Weight() valueobjects.Weight { return s.weight }
-func (s *SKU) WeightTolerance() float64    { return s.weightTolerance }
-func (s *SKU) ImageURL() string            { return s.imageURL }
-func (s *SKU) IsActive() bool              { return s.active }
-func (s *SKU) CreatedAt() time.Time        { return s.createdAt }
-func (s *SKU) UpdatedAt() time.Time        { return s.updatedAt }
+func (s *SKU) ID() valueobjects.SKUID              { return s.id }
+func (s *SKU) Code() string                        { return s.code }
+func (s *SKU) Name() string                        { return s.name }
+func (s *SKU) Price() valueobjects.Money           { return s.price }
+func (s *SKU) Weight() valueobjects.Weight         { return s.weight }
+func (s *SKU) WeightTolerance() float64            { return s.weightTolerance }
+func (s *SKU) ImageURL() string                    { return s.imageURL }
+func (s *SKU) Lifecycle() Lifecycle                { return s.lifecycle }
+func (s *SKU) IsActive() bool                      { return s.lifecycle == LifecyclePublished }
+func (s *SKU) CategoryID() valueobjects.CategoryID { return s.categoryID }
+func (s *SKU) Attributes() Attributes              { return s.attributes }
+func (s *SKU) IsBundle() bool                      { return len(s.components) > 0 }
+func (s *SKU) ShelfLifeDays() int                  { return s.shelfLifeDays }
+func (s *SKU) IsPerishable() bool                  { return s.shelfLifeDays > 0 }
+func (s *SKU) Restocking() RestockingInfo          { return s.restocking }
+func (s *SKU) DeletedAt() *time.Time               { return s.deletedAt }
+func (s *SKU) IsDeleted() bool                     { return s.deletedAt != nil }
+func (s *SKU) CreatedAt() time.Time                { return s.createdAt }
+func (s *SKU) UpdatedAt() time.Time                { return s.updatedAt }
+
+// Components returns what a bundle is made of; nil for other SKUs
+func (s *SKU) Components() []BundleComponent {
+	if s.components == nil {
+		return nil
+	}
+	return append([]BundleComponent{}, s.components...)
+}
 
 // Business methods
 
@@ -114,6 +173,10 @@ func (s *SKU) Update(name string, priceCents int64, currency string, weightGrams
 		return ErrInvalidSKUWeight
 	}
 
+	if !price.Equals(s.price) {
+		s.domainEvents = append(s.domainEvents, NewSKUPriceChanged(s.id, s.price, price))
+	}
+
 	s.name = name
 	s.price = price
 	s.weight = weight
@@ -126,21 +189,174 @@ func (s *SKU) Update(name string, priceCents int64, currency string, weightGrams
 	return nil
 }
 
-func (s *SKU) Deactivate() {
-	if !s.active {
+// Publish puts a draft or retired SKU on sale; publishing a published SKU is a no-op
+func (s *SKU) Publish() error {
+	if s.deletedAt != nil {
+		return ErrSKUDeleted
+	}
+	if s.lifecycle == LifecyclePublished {
+		return nil
+	}
+	from := s.lifecycle
+	s.lifecycle = LifecyclePublished
+	s.updatedAt = time.Now().UTC()
+	s.domainEvents = append(s.domainEvents, NewSKUPublished(s.id, from))
+	return nil
+}
+
+// Retire takes a published SKU off sale; drafts were never on sale and cannot
+// be retired. Retiring a retired SKU is a no-op.
+func (s *SKU) Retire() error {
+	switch s.lifecycle {
+	case LifecycleRetired:
+		return nil
+	case LifecycleDraft:
+		return ErrInvalidLifecycleTransition
+	}
+	s.lifecycle = LifecycleRetired
+	s.updatedAt = time.Now().UTC()
+	s.domainEvents = append(s.domainEvents, NewSKURetired(s.id))
+	return nil
+}
+
+// AssignCategory files the SKU under a category; a zero ID removes it from
+// its category
+func (s *SKU) AssignCategory(categoryID valueobjects.CategoryID) {
+	if s.categoryID == categoryID {
 		return
 	}
-	s.active = false
+	s.categoryID = categoryID
 	s.updatedAt = time.Now().UTC()
-	s.domainEvents = append(s.domainEvents, NewSKUDeactivated(s.id))
+	s.domainEvents = append(s.domainEvents, NewSKUCategoryChanged(s.id, categoryID))
 }
 
-func (s *SKU) Activate() {
-	if s.active {
+// SetAttributes replaces the SKU's attributes; build them with NewAttributes
+func (s *SKU) SetAttributes(attrs Attributes) {
+	s.attributes = attrs
+	s.updatedAt = time.Now().UTC()
+}
+
+// Delete soft-deletes the SKU: it disappears from listings but keeps its code
+// and can be restored
+func (s *SKU) Delete() {
+	if s.deletedAt != nil {
 		return
 	}
-	s.active = true
+	now := time.Now().UTC()
+	s.deletedAt = &now
+	s.updatedAt = now
+	s.domainEvents = append(s.domainEvents, NewSKUDeleted(s.id))
+}
+
+// Restore undoes a soft delete; the SKU comes back as active or inactive as it was
+func (s *SKU) Restore() {
+	if s.deletedAt == nil {
+		return
+	}
+	s.deletedAt = nil
 	s.updatedAt = time.Now().UTC()
+	s.domainEvents = append(s.domainEvents, NewSKURestored(s.id))
+}
+
+// Nutrition returns the nutrition declaration, or nil when none was given
+func (s *SKU) Nutrition() *NutritionFacts {
+	if s.nutrition == nil {
+		return nil
+	}
+	n := *s.nutrition
+	return &n
+}
+
+// Allergens returns the declared allergens, sorted
+func (s *SKU) Allergens() []Allergen {
+	return append([]Allergen{}, s.allergens...)
+}
+
+// SetProductInfo replaces the nutrition declaration and allergens; a nil
+// nutrition clears it. Build the allergens with NewAllergens.
+func (s *SKU) SetProductInfo(nutrition *NutritionFacts, allergens []Allergen) error {
+	if nutrition != nil {
+		if err := nutrition.Validate(); err != nil {
+			return err
+		}
+		n := *nutrition
+		nutrition = &n
+	}
+	s.nutrition = nutrition
+	s.allergens = append([]Allergen{}, allergens...)
+	s.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// Translations returns the SKU's names in other languages
+func (s *SKU) Translations() Translations {
+	translations := make(Translations, len(s.translations))
+	for tag, t := range s.translations {
+		translations[tag] = t
+	}
+	return translations
+}
+
+// SetTranslations replaces the SKU's translations. Build them with
+// NewTranslations.
+func (s *SKU) SetTranslations(translations Translations) {
+	s.translations = make(Translations, len(translations))
+	for tag, t := range translations {
+		s.translations[tag] = t
+	}
+	s.updatedAt = time.Now().UTC()
+}
+
+// MaxShelfLifeDays bounds the shelf life of a perishable SKU
+const MaxShelfLifeDays = 3650
+
+// SetShelfLife sets how many days a unit keeps from the day it is loaded
+// into a device; zero marks the SKU as not perishable
+func (s *SKU) SetShelfLife(days int) error {
+	if days < 0 || days > MaxShelfLifeDays {
+		return ErrInvalidShelfLife
+	}
+	s.shelfLifeDays = days
+	s.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// SetRestocking replaces the supplier reference, case size and reorder
+// point. Build the info with NewRestockingInfo.
+func (s *SKU) SetRestocking(info RestockingInfo) {
+	s.restocking = info
+	s.updatedAt = time.Now().UTC()
+}
+
+// WeightSamples returns the weighed samples, oldest first
+func (s *SKU) WeightSamples() []float64 {
+	return append([]float64{}, s.weightSamples...)
+}
+
+// WeightStats summarizes the weighed samples
+func (s *SKU) WeightStats() WeightStats {
+	return NewWeightStats(s.weightSamples)
+}
+
+// RecordWeightSamples adds weighed units of the SKU. Only the most recent
+// samples are kept.
+func (s *SKU) RecordWeightSamples(grams []float64) error {
+	if len(grams) == 0 {
+		return ErrInvalidWeightSample
+	}
+	for _, g := range grams {
+		if g <= 0 || math.IsNaN(g) || math.IsInf(g, 0) {
+			return ErrInvalidWeightSample
+		}
+	}
+
+	s.weightSamples = append(s.weightSamples, grams...)
+	if excess := len(s.weightSamples) - maxWeightSamples; excess > 0 {
+		s.weightSamples = append([]float64{}, s.weightSamples[excess:]...)
+	}
+	s.updatedAt = time.Now().UTC()
+	s.domainEvents = append(s.domainEvents, NewSKUWeightSampled(s.id, s.WeightStats()))
+	return nil
 }
 
 func (s *SKU) IsWeightMatch(measured valueobjects.Weight) bool {
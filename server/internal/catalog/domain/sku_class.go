@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// MaxClassID is the highest class ID a detection model may use
+const MaxClassID = 9999
+
+// SKUClass ties a detection model class to the SKU it recognizes. A class
+// names one SKU and a SKU has at most one class. SKUs keep their class when
+// they are retired or deleted, so its ID is not handed to another product
+// while a model may still report it.
+type SKUClass struct {
+	ClassID    int
+	SKUID      valueobjects.SKUID
+	AssignedAt time.Time
+}
+
+// NewSKUClass maps a class ID to a SKU
+func NewSKUClass(classID int, skuID valueobjects.SKUID) (SKUClass, error) {
+	if classID < 0 || classID > MaxClassID {
+		return SKUClass{}, ErrInvalidClassID
+	}
+	return SKUClass{ClassID: classID, SKUID: skuID, AssignedAt: time.Now().UTC()}, nil
+}
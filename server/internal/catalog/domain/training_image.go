@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// MaxTrainingImageBytes bounds one training image upload
+const MaxTrainingImageBytes = 10 << 20
+
+// BoundingBox is where the SKU shows in a training image, as fractions of
+// the image's width and height from its top-left corner
+type BoundingBox struct {
+	X, Y, Width, Height float64
+}
+
+func (b BoundingBox) valid() bool {
+	return b.X >= 0 && b.Y >= 0 && b.Width > 0 && b.Height > 0 &&
+		b.X+b.Width <= 1 && b.Y+b.Height <= 1
+}
+
+// TrainingImage is a labeled photo of a SKU for training the recognition
+// model. The SKU is the label; the bounding box, when given, marks where it
+// is. The image itself is kept in object storage under ObjectKey.
+type TrainingImage struct {
+	ID          valueobjects.TrainingImageID
+	SKUID       valueobjects.SKUID
+	ObjectKey   string
+	ContentType string
+	SizeBytes   int
+	Checksum    string       // SHA-256 of the image, hex
+	Box         *BoundingBox // nil when the SKU fills the image
+	Source      string       // where the photo was taken, e.g. studio or device
+	UploadedBy  string
+	CreatedAt   time.Time
+}
+
+// NewTrainingImage describes an uploaded image of a SKU. Only JPEG, PNG and
+// WebP images are accepted.
+func NewTrainingImage(skuID valueobjects.SKUID, image []byte, box *BoundingBox, source, uploadedBy string) (TrainingImage, error) {
+	if len(image) == 0 || len(image) > MaxTrainingImageBytes {
+		return TrainingImage{}, ErrInvalidTrainingImage
+	}
+	contentType, ext := imageType(image)
+	if contentType == "" {
+		return TrainingImage{}, ErrInvalidTrainingImage
+	}
+	if box != nil && !box.valid() {
+		return TrainingImage{}, ErrInvalidBoundingBox
+	}
+
+	id := valueobjects.NewTrainingImageID()
+	sum := sha256.Sum256(image)
+	return TrainingImage{
+		ID:          id,
+		SKUID:       skuID,
+		ObjectKey:   "training-images/" + skuID.String() + "/" + id.String() + ext,
+		ContentType: contentType,
+		SizeBytes:   len(image),
+		Checksum:    hex.EncodeToString(sum[:]),
+		Box:         box,
+		Source:      source,
+		UploadedBy:  uploadedBy,
+		CreatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// imageType recognizes the image format by its signature
+func imageType(image []byte) (contentType, ext string) {
+	switch {
+	case bytes.HasPrefix(image, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg", ".jpg"
+	case bytes.HasPrefix(image, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png", ".png"
+	case len(image) >= 12 && string(image[:4]) == "RIFF" && string(image[8:12]) == "WEBP":
+		return "image/webp", ".webp"
+	}
+	return "", ""
+}
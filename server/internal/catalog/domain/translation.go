@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/locale"
+)
+
+const (
+	maxTranslations             = 20
+	maxTranslatedNameLen        = 255
+	maxTranslatedDescriptionLen = 2000
+)
+
+// Translation is a SKU's name and description in one language
+type Translation struct {
+	Name        string
+	Description string // optional
+}
+
+// Translations are a SKU's translations keyed by language tag, e.g. "de"
+// or "de-CH". The SKU's own name is the fallback for languages without one.
+type Translations map[string]Translation
+
+// NewTranslations validates raw translations and normalizes their tags
+func NewTranslations(raw map[string]Translation) (Translations, error) {
+	if len(raw) > maxTranslations {
+		return nil, fmt.Errorf("%w: at most %d languages are allowed", ErrInvalidTranslation, maxTranslations)
+	}
+
+	translations := make(Translations, len(raw))
+	for tag, t := range raw {
+		normalized := locale.Normalize(tag)
+		if normalized == "" {
+			return nil, fmt.Errorf("%w: %q is not a language tag such as de or de-CH", ErrInvalidTranslation, tag)
+		}
+		if _, dup := translations[normalized]; dup {
+			return nil, fmt.Errorf("%w: %s is given twice", ErrInvalidTranslation, normalized)
+		}
+		t.Name = strings.TrimSpace(t.Name)
+		t.Description = strings.TrimSpace(t.Description)
+		if t.Name == "" || len(t.Name) > maxTranslatedNameLen {
+			return nil, fmt.Errorf("%w: %s needs a name of at most %d characters", ErrInvalidTranslation, normalized, maxTranslatedNameLen)
+		}
+		if len(t.Description) > maxTranslatedDescriptionLen {
+			return nil, fmt.Errorf("%w: %s description is longer than %d characters", ErrInvalidTranslation, normalized, maxTranslatedDescriptionLen)
+		}
+		translations[normalized] = t
+	}
+	return translations, nil
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "math"
+
+const (
+	// MinCalibrationSamples is how many weighings a SKU needs before its
+	// measured spread replaces the nominal weight and tolerance
+	MinCalibrationSamples = 3
+	// maxWeightSamples caps the samples kept per SKU; the oldest are dropped
+	// so recipe or packaging changes work their way in
+	maxWeightSamples = 100
+)
+
+// WeightStats summarizes the weighed samples of a SKU
+type WeightStats struct {
+	Samples     int
+	MeanGrams   float64
+	StdDevGrams float64 // sample standard deviation
+}
+
+// NewWeightStats computes the mean and standard deviation of the samples
+func NewWeightStats(samples []float64) WeightStats {
+	stats := WeightStats{Samples: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	var sum float64
+	for _, g := range samples {
+		sum += g
+	}
+	stats.MeanGrams = sum / float64(len(samples))
+
+	if len(samples) > 1 {
+		var squares float64
+		for _, g := range samples {
+			squares += (g - stats.MeanGrams) * (g - stats.MeanGrams)
+		}
+		stats.StdDevGrams = math.Sqrt(squares / float64(len(samples)-1))
+	}
+	return stats
+}
+
+// IsCalibrated reports whether there are enough samples to rely on the stats
+func (w WeightStats) IsCalibrated() bool {
+	return w.Samples >= MinCalibrationSamples
+}
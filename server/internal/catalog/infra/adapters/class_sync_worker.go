@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+const (
+	// classSyncAttempts bounds the tries for one round of catalog changes
+	classSyncAttempts = 5
+	// classSyncRetryWait is the wait before the first retry; it doubles after
+	// every failed attempt
+	classSyncRetryWait = 5 * time.Second
+)
+
+// ClassSyncWorker pushes the class mapping to the ML server as SKUs are
+// created, edited, published, retired, deleted or restored and as classes
+// are mapped. Changes arriving within the settle time are synced together, so
+// an import costs one sync.
+// Failed syncs are retried with backoff; a change missed by this instance is
+// picked up by the next sync, which always sends the whole mapping.
+type ClassSyncWorker struct {
+	broker  *messaging.InProcessBroker
+	handler *app.SyncClassesHandler
+	settle  time.Duration
+}
+
+func NewClassSyncWorker(broker *messaging.InProcessBroker, handler *app.SyncClassesHandler, settle time.Duration) *ClassSyncWorker {
+	if broker == nil {
+		panic("nil InProcessBroker")
+	}
+	if handler == nil {
+		panic("nil SyncClassesHandler")
+	}
+	return &ClassSyncWorker{broker: broker, handler: handler, settle: settle}
+}
+
+// Run syncs once at startup and then after catalog changes until ctx is cancelled
+func (w *ClassSyncWorker) Run(ctx context.Context) {
+	evts, cancel := w.broker.Subscribe()
+	defer cancel()
+
+	timer := time.NewTimer(w.settle)
+	defer timer.Stop()
+	attempt := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-evts:
+			if !ok {
+				return
+			}
+			if !changesClassMapping(evt) {
+				continue
+			}
+			// A new change restarts the round, pending retries included
+			attempt = 0
+			timer.Reset(w.settle)
+		case <-timer.C:
+			attempt++
+			result, err := w.handler.Handle(ctx)
+			switch {
+			case err == nil:
+				logger.Info("Class mapping synced to ML server", "classes", result.Classes)
+				attempt = 0
+			case errors.Is(err, app.ErrClassSyncUnavailable):
+				logger.Debug("Class mapping not synced, no ML server configured")
+				attempt = 0
+			case attempt < classSyncAttempts:
+				wait := classSyncRetryWait << (attempt - 1)
+				logger.Warn("Class mapping sync failed, retrying", "attempt", attempt, "retry_in", wait, "error", err)
+				timer.Reset(wait)
+			default:
+				logger.Error("Class mapping sync failed, giving up until the next catalog change", "attempts", attempt, "error", err)
+				attempt = 0
+			}
+		}
+	}
+}
+
+// changesClassMapping reports whether the event can add, rename or remove a class
+func changesClassMapping(event events.DomainEvent) bool {
+	switch event.(type) {
+	case domain.SKUCreated, domain.SKUUpdated, domain.SKUPublished, domain.SKURetired,
+		domain.SKUDeleted, domain.SKURestored, domain.SKUClassAssigned, domain.SKUClassRemoved:
+		return true
+	}
+	return false
+}
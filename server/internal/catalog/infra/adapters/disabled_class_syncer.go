@@ -0,0 +1,19 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+)
+
+// DisabledClassSyncer is used when no ML server is wired in; every sync is
+// refused instead of pretending the mapping went out
+type DisabledClassSyncer struct{}
+
+func NewDisabledClassSyncer() *DisabledClassSyncer {
+	return &DisabledClassSyncer{}
+}
+
+func (s *DisabledClassSyncer) SyncClasses(ctx context.Context, mappings []app.ClassMapping) (int, error) {
+	return 0, app.ErrClassSyncUnavailable
+}
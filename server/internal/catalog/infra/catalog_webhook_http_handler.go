@@ -0,0 +1,143 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+	"github.com/vending-machine/server/internal/platform/webhook"
+)
+
+const (
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+
+	// maxWebhookBytes and maxWebhookProducts bound one push
+	maxWebhookBytes    = 5 << 20
+	maxWebhookProducts = 1000
+)
+
+// externalProductDTO is a product as the product-information system pushes it
+type externalProductDTO struct {
+	ExternalID      string          `json:"external_id"`
+	Code            string          `json:"code"` // required on the first push of a product
+	Name            string          `json:"name"`
+	PriceCents      int64           `json:"price_cents"`
+	Currency        string          `json:"currency"`
+	WeightGrams     float64         `json:"weight_grams"`
+	WeightTolerance float64         `json:"weight_tolerance"`
+	ImageURL        string          `json:"image_url"`
+	Attributes      map[string]any  `json:"attributes"`   // omitted keeps the current ones
+	ProductInfo     *productInfoDTO `json:"product_info"` // omitted keeps the current info
+	Translations    translationsDTO `json:"translations"` // omitted keeps the current ones
+}
+
+type catalogWebhookRequest struct {
+	Products []externalProductDTO `json:"products"`
+}
+
+type syncedProductResponse struct {
+	ExternalID string `json:"external_id"`
+	SKUID      string `json:"sku_id"`
+	Code       string `json:"code"`
+	Created    bool   `json:"created"`
+}
+
+type externalProductErrorResponse struct {
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
+
+// CatalogWebhook upserts the SKUs pushed by the external product-information
+// system. The raw body must be signed with the shared secret (see
+// webhook.Verifier). Either every product is synced (200) or none is and the
+// response lists what is wrong with each rejected product (422).
+func (h *HTTPHandler) CatalogWebhook(c *gin.Context) {
+	if !h.webhookVerifier.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": webhook.ErrNotConfigured.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("a push is limited to %d bytes", maxWebhookBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read request body"})
+		return
+	}
+	if err := h.webhookVerifier.Verify(c.GetHeader(webhookTimestampHeader), c.GetHeader(webhookSignatureHeader), body); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req catalogWebhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Products) == 0 || len(req.Products) > maxWebhookProducts {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a push holds 1 to %d products", maxWebhookProducts)})
+		return
+	}
+
+	cmd := app.SyncExternalProductsCommand{Products: make([]app.ExternalProductInput, 0, len(req.Products))}
+	for _, p := range req.Products {
+		currency := p.Currency
+		if currency == "" {
+			currency = "USD"
+		}
+		cmd.Products = append(cmd.Products, app.ExternalProductInput{
+			ExternalID:      p.ExternalID,
+			Code:            p.Code,
+			Name:            p.Name,
+			PriceCents:      p.PriceCents,
+			Currency:        currency,
+			WeightGrams:     p.WeightGrams,
+			WeightTolerance: p.WeightTolerance,
+			ImageURL:        p.ImageURL,
+			Attributes:      p.Attributes,
+			ProductInfo:     p.ProductInfo.toInput(),
+			Translations:    p.Translations.toInput(),
+		})
+	}
+
+	result, err := h.syncExternalProductsHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		report := make([]externalProductErrorResponse, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			report = append(report, externalProductErrorResponse{ExternalID: e.ExternalID, Error: e.Error})
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  fmt.Sprintf("%d of %d products are invalid; nothing was synced", len(report), len(req.Products)),
+			"errors": report,
+		})
+		return
+	}
+
+	products := make([]syncedProductResponse, 0, len(result.Products))
+	created := 0
+	for _, p := range result.Products {
+		if p.Created {
+			created++
+		}
+		products = append(products, syncedProductResponse{ExternalID: p.ExternalID, SKUID: p.SKUID, Code: p.Code, Created: p.Created})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"created":  created,
+		"updated":  len(products) - created,
+		"products": products,
+	})
+}
@@ -0,0 +1,163 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+type categoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parent_id"` // empty for a top-level category
+}
+
+type assignCategoryRequest struct {
+	CategoryID string `json:"category_id"` // empty removes the SKU from its category
+}
+
+type categoryResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateCategory adds a category, optionally below an existing one
+func (h *HTTPHandler) CreateCategory(c *gin.Context) {
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.createCategoryHandler.Handle(c.Request.Context(), app.CreateCategoryCommand{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toCategoryResponse(category))
+}
+
+// ListCategories returns the whole category tree as a flat list ordered by name
+func (h *HTTPHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categoryQuery.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]categoryResponse, 0, len(categories))
+	for _, category := range categories {
+		response = append(response, toCategoryResponse(category))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": response,
+		"count":      len(response),
+	})
+}
+
+func (h *HTTPHandler) GetCategory(c *gin.Context) {
+	category, err := h.categoryQuery.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCategoryResponse(category))
+}
+
+// UpdateCategory renames a category and moves it within the tree
+func (h *HTTPHandler) UpdateCategory(c *gin.Context) {
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.updateCategoryHandler.Handle(c.Request.Context(), app.UpdateCategoryCommand{
+		CategoryID: c.Param("id"),
+		Name:       req.Name,
+		ParentID:   req.ParentID,
+	})
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCategoryResponse(category))
+}
+
+// DeleteCategory removes a category without subcategories; its SKUs become
+// uncategorized
+func (h *HTTPHandler) DeleteCategory(c *gin.Context) {
+	err := h.deleteCategoryHandler.Handle(c.Request.Context(), app.DeleteCategoryCommand{CategoryID: c.Param("id")})
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AssignCategory files a SKU under a category, or removes it from its category
+func (h *HTTPHandler) AssignCategory(c *gin.Context) {
+	var req assignCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s, err := h.assignCategoryHandler.Handle(c.Request.Context(), app.AssignSKUCategoryCommand{
+		SKUID:      c.Param("id"),
+		CategoryID: req.CategoryID,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+func writeCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrParentCategoryNotFound),
+		errors.Is(err, domain.ErrInvalidCategoryName),
+		errors.Is(err, domain.ErrCategoryCycle):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toCategoryResponse(category *domain.Category) categoryResponse {
+	response := categoryResponse{
+		ID:        category.ID().String(),
+		Name:      category.Name(),
+		CreatedAt: category.CreatedAt(),
+		UpdatedAt: category.UpdatedAt(),
+	}
+	if !category.ParentID().IsZero() {
+		response.ParentID = category.ParentID().String()
+	}
+	return response
+}
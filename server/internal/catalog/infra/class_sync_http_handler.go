@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+// SyncClasses pushes the class→SKU mapping to the ML server right away,
+// without waiting for a catalog change
+func (h *HTTPHandler) SyncClasses(c *gin.Context) {
+	result, err := h.syncClassesHandler.Handle(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, app.ErrClassSyncUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "ML server did not accept the class mapping"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"classes": result.Classes})
+}
+
+type assignClassRequest struct {
+	SKUID   string `json:"sku_id" binding:"required"`
+	ClassID *int   `json:"class_id"` // the next free class ID when omitted
+}
+
+type reassignClassRequest struct {
+	SKUID string `json:"sku_id" binding:"required"`
+}
+
+type classResponse struct {
+	ClassID int    `json:"class_id"`
+	SKUID   string `json:"sku_id"`
+	SKUCode string `json:"sku_code"`
+}
+
+// ListClasses lists the class→SKU mapping by class ID
+func (h *HTTPHandler) ListClasses(c *gin.Context) {
+	mappings, err := h.classQuery.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]classResponse, 0, len(mappings))
+	for _, m := range mappings {
+		response = append(response, toClassResponse(m))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"classes": response,
+		"count":   len(response),
+	})
+}
+
+// GetClass returns the SKU a class is mapped to
+func (h *HTTPHandler) GetClass(c *gin.Context) {
+	classID, ok := classIDParam(c)
+	if !ok {
+		return
+	}
+	mapping, err := h.classQuery.Get(c.Request.Context(), classID)
+	if err != nil {
+		writeClassError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toClassResponse(mapping))
+}
+
+// AssignClass maps a new class to a SKU
+func (h *HTTPHandler) AssignClass(c *gin.Context) {
+	var req assignClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := h.assignClassHandler.Handle(c.Request.Context(), app.AssignClassCommand{
+		SKUID:   req.SKUID,
+		ClassID: req.ClassID,
+	})
+	if err != nil {
+		writeClassError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toClassResponse(mapping))
+}
+
+// ReassignClass points an existing class at another SKU
+func (h *HTTPHandler) ReassignClass(c *gin.Context) {
+	classID, ok := classIDParam(c)
+	if !ok {
+		return
+	}
+	var req reassignClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := h.reassignClassHandler.Handle(c.Request.Context(), app.ReassignClassCommand{
+		ClassID: classID,
+		SKUID:   req.SKUID,
+	})
+	if err != nil {
+		writeClassError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toClassResponse(mapping))
+}
+
+// RemoveClass takes a class out of the mapping
+func (h *HTTPHandler) RemoveClass(c *gin.Context) {
+	classID, ok := classIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.removeClassHandler.Handle(c.Request.Context(), classID); err != nil {
+		writeClassError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func classIDParam(c *gin.Context) (int, bool) {
+	classID, err := strconv.Atoi(c.Param("class_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "class ID must be a number"})
+		return 0, false
+	}
+	return classID, true
+}
+
+func writeClassError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSKUClassNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidClassID),
+		errors.Is(err, domain.ErrBundleNotClassified):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrClassIDTaken),
+		errors.Is(err, domain.ErrSKUAlreadyClassified):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		writeSKUChangeError(c, err)
+	}
+}
+
+func toClassResponse(m app.ClassMapping) classResponse {
+	return classResponse{ClassID: m.ClassID, SKUID: m.SKUID, SKUCode: m.ClassName}
+}
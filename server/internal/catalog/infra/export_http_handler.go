@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+// exportColumns is the CSV layout of an export; it is a valid import header
+var exportColumns = []string{"code", "name", "price_cents", "currency", "weight_grams", "weight_tolerance", "image_url", "category_id"}
+
+// Export streams the whole catalog, deleted SKUs excepted, as ?format=csv
+// (the default, in the layout Import reads) or ?format=json
+func (h *HTTPHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+
+	skus, err := h.queryService.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="skus.`+format+`"`)
+	if format == "json" {
+		writeJSONExport(c, skus)
+		return
+	}
+	writeCSVExport(c, skus)
+}
+
+func writeCSVExport(c *gin.Context, skus []*domain.SKU) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(exportColumns)
+	for _, s := range skus {
+		r := toSKUResponse(s)
+		_ = w.Write([]string{
+			r.Code,
+			r.Name,
+			strconv.FormatInt(r.PriceCents, 10),
+			r.Currency,
+			strconv.FormatFloat(r.WeightGrams, 'f', -1, 64),
+			strconv.FormatFloat(r.WeightTolerance, 'f', -1, 64),
+			r.ImageURL,
+			r.CategoryID,
+		})
+	}
+	w.Flush()
+}
+
+// writeJSONExport writes the array one SKU at a time rather than building
+// the whole document in memory
+func writeJSONExport(c *gin.Context, skus []*domain.SKU) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	_, _ = c.Writer.WriteString("[")
+	for i, s := range skus {
+		if i > 0 {
+			_, _ = c.Writer.WriteString(",")
+		}
+		body, err := json.Marshal(toSKUResponse(s))
+		if err != nil {
+			return
+		}
+		_, _ = c.Writer.Write(body)
+	}
+	_, _ = c.Writer.WriteString("]")
+}
@@ -3,50 +3,257 @@ package infra
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/vending-machine/server/internal/catalog/app"
 	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/platform/webhook"
 )
 
+const actorIDHeader = "X-Actor-ID"
+
 type HTTPHandler struct {
-	createHandler *app.CreateSKUHandler
-	queryService  *app.SKUQueryService
+	createHandler    *app.CreateSKUHandler
+	updateHandler    *app.UpdateSKUHandler
+	lifecycleHandler *app.ChangeSKULifecycleHandler
+	deleteHandler    *app.DeleteSKUHandler
+	restoreHandler   *app.RestoreSKUHandler
+	importHandler    *app.ImportSKUsHandler
+	bundleHandler    *app.CreateBundleHandler
+	weightHandler    *app.RecordWeightSamplesHandler
+	queryService     *app.SKUQueryService
+
+	addTrainingImageHandler    *app.AddTrainingImageHandler
+	deleteTrainingImageHandler *app.DeleteTrainingImageHandler
+	trainingImageQuery         *app.TrainingImageQueryService
+	syncClassesHandler         *app.SyncClassesHandler
+	assignClassHandler         *app.AssignClassHandler
+	reassignClassHandler       *app.ReassignClassHandler
+	removeClassHandler         *app.RemoveClassHandler
+	classQuery                 *app.ClassQueryService
+
+	syncExternalProductsHandler *app.SyncExternalProductsHandler
+	webhookVerifier             *webhook.Verifier
+
+	createCategoryHandler *app.CreateCategoryHandler
+	updateCategoryHandler *app.UpdateCategoryHandler
+	deleteCategoryHandler *app.DeleteCategoryHandler
+	assignCategoryHandler *app.AssignSKUCategoryHandler
+	categoryQuery         *app.CategoryQueryService
 }
 
 func NewHTTPHandler(
 	createHandler *app.CreateSKUHandler,
+	updateHandler *app.UpdateSKUHandler,
+	lifecycleHandler *app.ChangeSKULifecycleHandler,
+	deleteHandler *app.DeleteSKUHandler,
+	restoreHandler *app.RestoreSKUHandler,
+	importHandler *app.ImportSKUsHandler,
+	bundleHandler *app.CreateBundleHandler,
+	weightHandler *app.RecordWeightSamplesHandler,
 	queryService *app.SKUQueryService,
+	addTrainingImageHandler *app.AddTrainingImageHandler,
+	deleteTrainingImageHandler *app.DeleteTrainingImageHandler,
+	trainingImageQuery *app.TrainingImageQueryService,
+	syncClassesHandler *app.SyncClassesHandler,
+	assignClassHandler *app.AssignClassHandler,
+	reassignClassHandler *app.ReassignClassHandler,
+	removeClassHandler *app.RemoveClassHandler,
+	classQuery *app.ClassQueryService,
+	syncExternalProductsHandler *app.SyncExternalProductsHandler,
+	webhookVerifier *webhook.Verifier,
+	createCategoryHandler *app.CreateCategoryHandler,
+	updateCategoryHandler *app.UpdateCategoryHandler,
+	deleteCategoryHandler *app.DeleteCategoryHandler,
+	assignCategoryHandler *app.AssignSKUCategoryHandler,
+	categoryQuery *app.CategoryQueryService,
 ) *HTTPHandler {
 	return &HTTPHandler{
-		createHandler: createHandler,
-		queryService:  queryService,
+		createHandler:               createHandler,
+		updateHandler:               updateHandler,
+		lifecycleHandler:            lifecycleHandler,
+		deleteHandler:               deleteHandler,
+		restoreHandler:              restoreHandler,
+		importHandler:               importHandler,
+		bundleHandler:               bundleHandler,
+		weightHandler:               weightHandler,
+		queryService:                queryService,
+		addTrainingImageHandler:     addTrainingImageHandler,
+		deleteTrainingImageHandler:  deleteTrainingImageHandler,
+		trainingImageQuery:          trainingImageQuery,
+		syncClassesHandler:          syncClassesHandler,
+		assignClassHandler:          assignClassHandler,
+		reassignClassHandler:        reassignClassHandler,
+		removeClassHandler:          removeClassHandler,
+		classQuery:                  classQuery,
+		syncExternalProductsHandler: syncExternalProductsHandler,
+		webhookVerifier:             webhookVerifier,
+		createCategoryHandler:       createCategoryHandler,
+		updateCategoryHandler:       updateCategoryHandler,
+		deleteCategoryHandler:       deleteCategoryHandler,
+		assignCategoryHandler:       assignCategoryHandler,
+		categoryQuery:               categoryQuery,
 	}
 }
 
 // Request/Response DTOs (HTTP layer only)
 
 type createSKURequest struct {
-	Code            string  `json:"code" binding:"required"`
-	Name            string  `json:"name" binding:"required"`
-	PriceCents      int64   `json:"price_cents" binding:"required"`
-	Currency        string  `json:"currency"`
-	WeightGrams     float64 `json:"weight_grams" binding:"required"`
-	WeightTolerance float64 `json:"weight_tolerance"`
-	ImageURL        string  `json:"image_url"`
+	Code            string          `json:"code" binding:"required"`
+	Name            string          `json:"name" binding:"required"`
+	PriceCents      int64           `json:"price_cents" binding:"required"`
+	Currency        string          `json:"currency"`
+	WeightGrams     float64         `json:"weight_grams" binding:"required"`
+	WeightTolerance float64         `json:"weight_tolerance"`
+	ImageURL        string          `json:"image_url"`
+	CategoryID      string          `json:"category_id"`
+	Attributes      map[string]any  `json:"attributes"`
+	Lifecycle       string          `json:"lifecycle"` // draft or published (default)
+	ProductInfo     *productInfoDTO `json:"product_info"`
+	Translations    translationsDTO `json:"translations"`
+	ShelfLifeDays   int             `json:"shelf_life_days"` // perishable SKUs only
+	Restocking      *restockingDTO  `json:"restocking"`
+}
+
+type updateSKURequest struct {
+	Name            string          `json:"name" binding:"required"`
+	PriceCents      int64           `json:"price_cents" binding:"required"`
+	Currency        string          `json:"currency"`
+	WeightGrams     float64         `json:"weight_grams" binding:"required"`
+	WeightTolerance float64         `json:"weight_tolerance"`
+	ImageURL        string          `json:"image_url"`
+	Attributes      map[string]any  `json:"attributes"`      // omitted keeps the current ones
+	ProductInfo     *productInfoDTO `json:"product_info"`    // omitted keeps the current info
+	Translations    translationsDTO `json:"translations"`    // omitted keeps the current ones
+	ShelfLifeDays   *int            `json:"shelf_life_days"` // omitted keeps the current shelf life
+	Restocking      *restockingDTO  `json:"restocking"`      // omitted keeps the current info
+}
+
+// translationsDTO holds a SKU's name and description per language tag
+type translationsDTO map[string]translationDTO
+
+type translationDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func (dto translationsDTO) toInput() map[string]domain.Translation {
+	if dto == nil {
+		return nil
+	}
+	translations := make(map[string]domain.Translation, len(dto))
+	for tag, t := range dto {
+		translations[tag] = domain.Translation(t)
+	}
+	return translations
+}
+
+// productInfoDTO is the nutrition declaration, per 100 g or 100 ml, and the
+// declared allergens of a SKU
+type productInfoDTO struct {
+	Nutrition *nutritionDTO `json:"nutrition,omitempty"`
+	Allergens []string      `json:"allergens"`
+}
+
+type nutritionDTO struct {
+	ServingSizeGrams  float64 `json:"serving_size_grams,omitempty"`
+	EnergyKcal        float64 `json:"energy_kcal"`
+	FatGrams          float64 `json:"fat_grams"`
+	SaturatedFatGrams float64 `json:"saturated_fat_grams"`
+	CarbohydrateGrams float64 `json:"carbohydrate_grams"`
+	SugarsGrams       float64 `json:"sugars_grams"`
+	ProteinGrams      float64 `json:"protein_grams"`
+	SaltGrams         float64 `json:"salt_grams"`
+}
+
+func (dto *productInfoDTO) toInput() *app.ProductInfoInput {
+	if dto == nil {
+		return nil
+	}
+	input := &app.ProductInfoInput{Allergens: dto.Allergens}
+	if dto.Nutrition != nil {
+		facts := domain.NutritionFacts(*dto.Nutrition)
+		input.Nutrition = &facts
+	}
+	return input
+}
+
+// restockingDTO is how a SKU is bought in and when a device runs low on it
+type restockingDTO struct {
+	SupplierRef  string `json:"supplier_ref,omitempty"`
+	CaseSize     int    `json:"case_size,omitempty"`
+	ReorderPoint int    `json:"reorder_point,omitempty"`
+}
+
+func (dto *restockingDTO) toInput() *app.RestockingInput {
+	if dto == nil {
+		return nil
+	}
+	input := app.RestockingInput(*dto)
+	return &input
+}
+
+type bundleComponentRequest struct {
+	SKUID    string `json:"sku_id" binding:"required"`
+	Quantity int    `json:"quantity"`
+}
+
+type createBundleRequest struct {
+	Code       string                   `json:"code" binding:"required"`
+	Name       string                   `json:"name" binding:"required"`
+	PriceCents int64                    `json:"price_cents" binding:"required"`
+	Currency   string                   `json:"currency"`
+	Components []bundleComponentRequest `json:"components" binding:"required"`
+}
+
+type bundleComponentResponse struct {
+	SKUID    string `json:"sku_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type priceChangeResponse struct {
+	OldPriceCents int64     `json:"old_price_cents"`
+	OldCurrency   string    `json:"old_currency"`
+	NewPriceCents int64     `json:"new_price_cents"`
+	NewCurrency   string    `json:"new_currency"`
+	ChangedBy     string    `json:"changed_by"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// weightStatsResponse summarizes a SKU's calibration weighings
+type weightStatsResponse struct {
+	Samples     int     `json:"samples"`
+	MeanGrams   float64 `json:"mean_grams"`
+	StdDevGrams float64 `json:"stddev_grams"`
+	Calibrated  bool    `json:"calibrated"` // enough samples for detection to use
+}
+
+type recordWeightSamplesRequest struct {
+	SamplesGrams []float64 `json:"samples_grams"`
 }
 
 type skuResponse struct {
-	ID              string  `json:"id"`
-	Code            string  `json:"code"`
-	Name            string  `json:"name"`
-	PriceCents      int64   `json:"price_cents"`
-	Currency        string  `json:"currency"`
-	WeightGrams     float64 `json:"weight_grams"`
-	WeightTolerance float64 `json:"weight_tolerance"`
-	ImageURL        string  `json:"image_url,omitempty"`
-	Active          bool    `json:"active"`
+	ID              string                    `json:"id"`
+	Code            string                    `json:"code"`
+	Name            string                    `json:"name"`
+	PriceCents      int64                     `json:"price_cents"`
+	Currency        string                    `json:"currency"`
+	WeightGrams     float64                   `json:"weight_grams"`
+	WeightTolerance float64                   `json:"weight_tolerance"`
+	ImageURL        string                    `json:"image_url,omitempty"`
+	Active          bool                      `json:"active"` // published
+	Lifecycle       string                    `json:"lifecycle"`
+	CategoryID      string                    `json:"category_id,omitempty"`
+	Attributes      map[string]any            `json:"attributes,omitempty"`
+	Components      []bundleComponentResponse `json:"components,omitempty"` // bundles only
+	WeightStats     *weightStatsResponse      `json:"weight_stats,omitempty"`
+	ProductInfo     *productInfoDTO           `json:"product_info,omitempty"` // when declared
+	Translations    translationsDTO           `json:"translations,omitempty"`
+	ShelfLifeDays   int                       `json:"shelf_life_days,omitempty"` // perishable SKUs only
+	Restocking      *restockingDTO            `json:"restocking,omitempty"`      // when set
+	DeletedAt       *time.Time                `json:"deleted_at,omitempty"`
 }
 
 // Handlers
@@ -63,6 +270,17 @@ func (h *HTTPHandler) Create(c *gin.Context) {
 		currency = "USD"
 	}
 
+	// New SKUs go on sale right away unless they are created as drafts
+	var draft bool
+	switch domain.Lifecycle(req.Lifecycle) {
+	case "", domain.LifecyclePublished:
+	case domain.LifecycleDraft:
+		draft = true
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "lifecycle must be draft or published"})
+		return
+	}
+
 	cmd := app.CreateSKUCommand{
 		Code:            req.Code,
 		Name:            req.Name,
@@ -71,6 +289,13 @@ func (h *HTTPHandler) Create(c *gin.Context) {
 		WeightGrams:     req.WeightGrams,
 		WeightTolerance: req.WeightTolerance,
 		ImageURL:        req.ImageURL,
+		CategoryID:      req.CategoryID,
+		Attributes:      req.Attributes,
+		Draft:           draft,
+		ProductInfo:     req.ProductInfo.toInput(),
+		Translations:    req.Translations.toInput(),
+		ShelfLifeDays:   req.ShelfLifeDays,
+		Restocking:      req.Restocking.toInput(),
 	}
 
 	result, err := h.createHandler.Handle(c.Request.Context(), cmd)
@@ -80,7 +305,14 @@ func (h *HTTPHandler) Create(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		case errors.Is(err, domain.ErrInvalidSKUName),
 			errors.Is(err, domain.ErrInvalidSKUPrice),
-			errors.Is(err, domain.ErrInvalidSKUWeight):
+			errors.Is(err, domain.ErrInvalidSKUWeight),
+			errors.Is(err, domain.ErrInvalidSKUAttributes),
+			errors.Is(err, domain.ErrInvalidProductInfo),
+			errors.Is(err, domain.ErrInvalidTranslation),
+			errors.Is(err, domain.ErrInvalidShelfLife),
+			errors.Is(err, domain.ErrInvalidRestockingInfo):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrCategoryNotFound):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -94,42 +326,268 @@ func (h *HTTPHandler) Create(c *gin.Context) {
 	})
 }
 
-func (h *HTTPHandler) Get(c *gin.Context) {
-	s, err := h.queryService.FindByID(c.Request.Context(), c.Param("id"))
+// CreateBundle creates a bundle SKU: other SKUs sold together for the
+// bundle's own price when a basket holds all of them
+func (h *HTTPHandler) CreateBundle(c *gin.Context) {
+	var req createBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	cmd := app.CreateBundleCommand{
+		Code:       req.Code,
+		Name:       req.Name,
+		PriceCents: req.PriceCents,
+		Currency:   currency,
+	}
+	for _, component := range req.Components {
+		quantity := component.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		cmd.Components = append(cmd.Components, app.BundleComponentInput{SKUID: component.SKUID, Quantity: quantity})
+	}
+
+	result, err := h.bundleHandler.Handle(c.Request.Context(), cmd)
 	if err != nil {
-		if errors.Is(err, domain.ErrSKUNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "SKU not found"})
-			return
+		switch {
+		case errors.Is(err, domain.ErrDuplicateSKUCode):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidSKUName),
+			errors.Is(err, domain.ErrInvalidSKUPrice),
+			errors.Is(err, domain.ErrInvalidBundle),
+			errors.Is(err, domain.ErrInvalidBundleComponent),
+			errors.Is(err, domain.ErrBundleCurrencyMismatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      result.SKUID,
+		"message": "bundle created",
+	})
+}
+
+// Update edits a SKU on behalf of the staff member in X-Actor-ID; price
+// changes land in the SKU's price history
+func (h *HTTPHandler) Update(c *gin.Context) {
+	var req updateSKURequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	s, err := h.updateHandler.Handle(c.Request.Context(), app.UpdateSKUCommand{
+		SKUID:           c.Param("id"),
+		Name:            req.Name,
+		PriceCents:      req.PriceCents,
+		Currency:        currency,
+		WeightGrams:     req.WeightGrams,
+		WeightTolerance: req.WeightTolerance,
+		ImageURL:        req.ImageURL,
+		Attributes:      req.Attributes,
+		ProductInfo:     req.ProductInfo.toInput(),
+		Translations:    req.Translations.toInput(),
+		ShelfLifeDays:   req.ShelfLifeDays,
+		Restocking:      req.Restocking.toInput(),
+		ChangedBy:       c.GetHeader(actorIDHeader),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChangedByRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidSKUName),
+			errors.Is(err, domain.ErrInvalidSKUPrice),
+			errors.Is(err, domain.ErrInvalidSKUWeight),
+			errors.Is(err, domain.ErrInvalidSKUAttributes),
+			errors.Is(err, domain.ErrInvalidProductInfo),
+			errors.Is(err, domain.ErrInvalidTranslation),
+			errors.Is(err, domain.ErrInvalidShelfLife),
+			errors.Is(err, domain.ErrInvalidRestockingInfo):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			writeSKUChangeError(c, err)
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
 	c.JSON(http.StatusOK, toSKUResponse(s))
 }
 
-func (h *HTTPHandler) List(c *gin.Context) {
-	skus, err := h.queryService.FindAll(c.Request.Context())
+// PriceHistory lists every price change of a SKU, oldest first, for audits
+func (h *HTTPHandler) PriceHistory(c *gin.Context) {
+	history, err := h.queryService.PriceHistory(c.Request.Context(), c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		writeSKUChangeError(c, err)
 		return
 	}
 
-	var response []skuResponse
-	for _, s := range skus {
-		response = append(response, toSKUResponse(s))
+	response := make([]priceChangeResponse, 0, len(history))
+	for _, change := range history {
+		response = append(response, priceChangeResponse{
+			OldPriceCents: change.OldPrice.Amount(),
+			OldCurrency:   change.OldPrice.Currency(),
+			NewPriceCents: change.NewPrice.Amount(),
+			NewCurrency:   change.NewPrice.Currency(),
+			ChangedBy:     change.ChangedBy,
+			ChangedAt:     change.ChangedAt,
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"skus":  response,
-		"count": len(response),
+		"sku_id":  c.Param("id"),
+		"changes": response,
+		"count":   len(response),
 	})
 }
 
-func (h *HTTPHandler) ListActive(c *gin.Context) {
-	skus, err := h.queryService.FindAllActive(c.Request.Context())
+// RecordWeightSamples adds calibration weighings of single units of a SKU
+func (h *HTTPHandler) RecordWeightSamples(c *gin.Context) {
+	var req recordWeightSamplesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s, err := h.weightHandler.Handle(c.Request.Context(), app.RecordWeightSamplesCommand{
+		SKUID: c.Param("id"),
+		Grams: req.SamplesGrams,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWeightSample) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+// Publish puts a draft or retired SKU on sale. /activate is kept as an alias.
+func (h *HTTPHandler) Publish(c *gin.Context) {
+	h.changeLifecycle(c, domain.LifecyclePublished)
+}
+
+// Retire takes a published SKU off sale without deleting it. /deactivate is
+// kept as an alias.
+func (h *HTTPHandler) Retire(c *gin.Context) {
+	h.changeLifecycle(c, domain.LifecycleRetired)
+}
+
+func (h *HTTPHandler) changeLifecycle(c *gin.Context, to domain.Lifecycle) {
+	s, err := h.lifecycleHandler.Handle(c.Request.Context(), app.ChangeSKULifecycleCommand{
+		SKUID: c.Param("id"),
+		To:    to,
+	})
+	if err != nil {
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+// Delete soft-deletes a SKU; it is hidden from listings until restored
+func (h *HTTPHandler) Delete(c *gin.Context) {
+	s, err := h.deleteHandler.Handle(c.Request.Context(), app.DeleteSKUCommand{SKUID: c.Param("id")})
 	if err != nil {
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+// Restore brings a soft-deleted SKU back into the catalog
+func (h *HTTPHandler) Restore(c *gin.Context) {
+	s, err := h.restoreHandler.Handle(c.Request.Context(), app.DeleteSKUCommand{SKUID: c.Param("id")})
+	if err != nil {
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+func writeSKUChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSKUNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "SKU not found"})
+	case errors.Is(err, domain.ErrSKUDeleted),
+		errors.Is(err, domain.ErrInvalidLifecycleTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func (h *HTTPHandler) Get(c *gin.Context) {
+	s, err := h.queryService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrSKUNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "SKU not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toSKUResponse(s))
+}
+
+// List returns every SKU. ?category_id= keeps those in that category and its
+// subcategories, ?attr[<name>]=<value> those with that attribute value,
+// ?lifecycle= those in that lifecycle state. ?sort=price|name|created_at and
+// ?order=asc|desc order the listing, by name by default.
+func (h *HTTPHandler) List(c *gin.Context) {
+	h.list(c, false)
+}
+
+// ListActive returns the SKUs on sale, filtered like List
+func (h *HTTPHandler) ListActive(c *gin.Context) {
+	h.list(c, true)
+}
+
+func (h *HTTPHandler) list(c *gin.Context, activeOnly bool) {
+	filter := app.SKUFilter{
+		CategoryID: c.Query("category_id"),
+		ActiveOnly: activeOnly,
+		Attributes: c.QueryMap("attr"),
+	}
+	if raw := c.Query("lifecycle"); raw != "" {
+		lifecycle, err := domain.ParseLifecycle(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Lifecycle = lifecycle
+	}
+	sort, err := app.ParseSKUSort(c.Query("sort"), c.Query("order"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Sort = sort
+
+	skus, err := h.queryService.List(c.Request.Context(), filter)
+	if err != nil {
+		writeSKUListError(c, err)
 		return
 	}
 
@@ -144,8 +602,16 @@ func (h *HTTPHandler) ListActive(c *gin.Context) {
 	})
 }
 
+func writeSKUListError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
+
 func toSKUResponse(s *domain.SKU) skuResponse {
-	return skuResponse{
+	response := skuResponse{
 		ID:              s.ID().String(),
 		Code:            s.Code(),
 		Name:            s.Name(),
@@ -155,5 +621,47 @@ func toSKUResponse(s *domain.SKU) skuResponse {
 		WeightTolerance: s.WeightTolerance(),
 		ImageURL:        s.ImageURL(),
 		Active:          s.IsActive(),
+		Lifecycle:       string(s.Lifecycle()),
+		Attributes:      s.Attributes(),
+		ShelfLifeDays:   s.ShelfLifeDays(),
+		DeletedAt:       s.DeletedAt(),
+	}
+	if !s.CategoryID().IsZero() {
+		response.CategoryID = s.CategoryID().String()
+	}
+	for _, component := range s.Components() {
+		response.Components = append(response.Components, bundleComponentResponse{SKUID: component.SKUID.String(), Quantity: component.Quantity})
+	}
+	if stats := s.WeightStats(); stats.Samples > 0 {
+		response.WeightStats = &weightStatsResponse{
+			Samples:     stats.Samples,
+			MeanGrams:   stats.MeanGrams,
+			StdDevGrams: stats.StdDevGrams,
+			Calibrated:  stats.IsCalibrated(),
+		}
+	}
+	if nutrition, allergens := s.Nutrition(), s.Allergens(); nutrition != nil || len(allergens) > 0 {
+		response.ProductInfo = &productInfoDTO{Allergens: make([]string, 0, len(allergens))}
+		if nutrition != nil {
+			facts := nutritionDTO(*nutrition)
+			response.ProductInfo.Nutrition = &facts
+		}
+		for _, a := range allergens {
+			response.ProductInfo.Allergens = append(response.ProductInfo.Allergens, string(a))
+		}
+	}
+	if restocking := s.Restocking(); !restocking.IsZero() {
+		response.Restocking = &restockingDTO{
+			SupplierRef:  restocking.SupplierRef,
+			CaseSize:     restocking.CaseSize,
+			ReorderPoint: restocking.ReorderPoint,
+		}
+	}
+	if translations := s.Translations(); len(translations) > 0 {
+		response.Translations = make(translationsDTO, len(translations))
+		for tag, t := range translations {
+			response.Translations[tag] = translationDTO(t)
+		}
 	}
+	return response
 }
@@ -0,0 +1,152 @@
+package infra
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+)
+
+// maxImportRows bounds one import so it fits comfortably in one transaction
+const maxImportRows = 5000
+
+var (
+	importRequiredColumns = []string{"code", "name", "price_cents", "weight_grams"}
+	importOptionalColumns = []string{"currency", "weight_tolerance", "image_url", "category_id"}
+)
+
+type importedSKUResponse struct {
+	Line int    `json:"line"`
+	ID   string `json:"id"`
+	Code string `json:"code"`
+}
+
+type importRowErrorResponse struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// Import creates SKUs from a CSV file uploaded as the multipart field "file".
+// The first line names the columns. Either every row is created (201) or none
+// is and the response lists what is wrong with each rejected row (422).
+func (h *HTTPHandler) Import(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required in the \"file\" field"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read uploaded file"})
+		return
+	}
+	defer f.Close()
+
+	rows, err := readImportCSV(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.importHandler.Handle(c.Request.Context(), app.ImportSKUsCommand{Rows: rows})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		report := make([]importRowErrorResponse, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			report = append(report, importRowErrorResponse{Line: e.Line, Code: e.Code, Error: e.Error})
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  fmt.Sprintf("%d of %d rows are invalid; nothing was imported", len(report), len(rows)),
+			"errors": report,
+		})
+		return
+	}
+
+	created := make([]importedSKUResponse, 0, len(result.Created))
+	for _, s := range result.Created {
+		created = append(created, importedSKUResponse{Line: s.Line, ID: s.SKUID, Code: s.Code})
+	}
+	c.JSON(http.StatusCreated, gin.H{"imported": len(created), "skus": created})
+}
+
+// readImportCSV maps each record onto the columns named by the header line
+func readImportCSV(r io.Reader) ([]app.ImportSKURow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malformed CSV: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isImportColumn(name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []app.ImportSKURow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed CSV: %v", err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("too many rows; at most %d can be imported at once", maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		rows = append(rows, app.ImportSKURow{
+			Line:            line,
+			Code:            cell("code"),
+			Name:            cell("name"),
+			PriceCents:      cell("price_cents"),
+			Currency:        cell("currency"),
+			WeightGrams:     cell("weight_grams"),
+			WeightTolerance: cell("weight_tolerance"),
+			ImageURL:        cell("image_url"),
+			CategoryID:      cell("category_id"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("CSV file has no rows")
+	}
+	return rows, nil
+}
+
+func isImportColumn(name string) bool {
+	return slices.Contains(importRequiredColumns, name) || slices.Contains(importOptionalColumns, name)
+}
@@ -0,0 +1,117 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresCategoryRepository implements domain.CategoryRepository
+type PostgresCategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCategoryRepository(pool *pgxpool.Pool) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{pool: pool}
+}
+
+func (r *PostgresCategoryRepository) Save(ctx context.Context, c *domain.Category) error {
+	var parentID *string
+	if !c.ParentID().IsZero() {
+		id := c.ParentID().String()
+		parentID = &id
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO categories (id, name, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			parent_id = EXCLUDED.parent_id,
+			updated_at = EXCLUDED.updated_at
+	`, c.ID().String(), c.Name(), parentID, c.CreatedAt(), c.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresCategoryRepository) FindByID(ctx context.Context, id valueobjects.CategoryID) (*domain.Category, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, name, parent_id, created_at, updated_at
+		FROM categories WHERE id = $1
+	`, id.String())
+
+	c, err := scanCategory(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *PostgresCategoryRepository) FindAll(ctx context.Context) ([]*domain.Category, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, parent_id, created_at, updated_at
+		FROM categories ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*domain.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, id valueobjects.CategoryID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE skus SET category_id = NULL WHERE category_id = $1`, id.String()); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id.String())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCategoryNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanCategory(row pgx.Row) (*domain.Category, error) {
+	var (
+		rawID, name          string
+		rawParentID          *string
+		createdAt, updatedAt time.Time
+	)
+	if err := row.Scan(&rawID, &name, &rawParentID, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	id, _ := valueobjects.CategoryIDFrom(rawID)
+	var parentID valueobjects.CategoryID
+	if rawParentID != nil {
+		parentID, _ = valueobjects.CategoryIDFrom(*rawParentID)
+	}
+	return domain.ReconstituteCategory(id, name, parentID, createdAt, updatedAt), nil
+}
@@ -0,0 +1,92 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/catalog/api"
+)
+
+// PostgresDeviceSyncReader implements api.DeviceSyncReader with one query on
+// the device_sync_skus view, so device syncs never load whole SKUs
+type PostgresDeviceSyncReader struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDeviceSyncReader(pool *pgxpool.Pool) *PostgresDeviceSyncReader {
+	return &PostgresDeviceSyncReader{pool: pool}
+}
+
+func (r *PostgresDeviceSyncReader) FindActive(ctx context.Context) ([]api.DeviceSyncView, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT code, name, translations, weight_grams, weight_tolerance, class_id, on_sale, deleted, updated_at
+		FROM device_sync_skus WHERE on_sale ORDER BY class_id NULLS LAST, code
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeviceSyncViews(rows)
+}
+
+func (r *PostgresDeviceSyncReader) FindChangedSince(ctx context.Context, since time.Time) ([]api.DeviceSyncView, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT code, name, translations, weight_grams, weight_tolerance, class_id, on_sale, deleted, updated_at
+		FROM device_sync_skus WHERE updated_at > $1 ORDER BY updated_at, code
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeviceSyncViews(rows)
+}
+
+func (r *PostgresDeviceSyncReader) FindClasses(ctx context.Context) ([]api.DeviceClassView, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT class_id, code FROM device_sync_skus
+		WHERE on_sale AND class_id IS NOT NULL ORDER BY class_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := []api.DeviceClassView{}
+	for rows.Next() {
+		var v api.DeviceClassView
+		if err := rows.Scan(&v.ClassID, &v.Code); err != nil {
+			return nil, err
+		}
+		classes = append(classes, v)
+	}
+	return classes, rows.Err()
+}
+
+func scanDeviceSyncViews(rows pgx.Rows) ([]api.DeviceSyncView, error) {
+	defer rows.Close()
+
+	views := []api.DeviceSyncView{}
+	for rows.Next() {
+		var (
+			v            api.DeviceSyncView
+			translations []byte
+		)
+		if err := rows.Scan(&v.Code, &v.Name, &translations, &v.WeightGrams, &v.WeightTolerance,
+			&v.ClassID, &v.Active, &v.Deleted, &v.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		var stored map[string]translationJSON
+		_ = json.Unmarshal(translations, &stored)
+		if len(stored) > 0 {
+			v.Translations = make(map[string]api.TranslationView, len(stored))
+			for tag, t := range stored {
+				v.Translations[tag] = api.TranslationView(t)
+			}
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
@@ -0,0 +1,57 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresExternalLinkRepository implements domain.ExternalLinkRepository
+type PostgresExternalLinkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresExternalLinkRepository(pool *pgxpool.Pool) *PostgresExternalLinkRepository {
+	return &PostgresExternalLinkRepository{pool: pool}
+}
+
+func (r *PostgresExternalLinkRepository) Save(ctx context.Context, link domain.ExternalLink) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sku_external_links (external_id, sku_id, linked_at)
+		VALUES ($1, $2, $3)
+	`, link.ExternalID, link.SKUID.String(), link.LinkedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrExternalLinkConflict
+	}
+	return err
+}
+
+func (r *PostgresExternalLinkRepository) FindByExternalID(ctx context.Context, externalID string) (domain.ExternalLink, error) {
+	return r.find(ctx, `SELECT external_id, sku_id, linked_at FROM sku_external_links WHERE external_id = $1`, externalID)
+}
+
+func (r *PostgresExternalLinkRepository) FindBySKUID(ctx context.Context, skuID valueobjects.SKUID) (domain.ExternalLink, error) {
+	return r.find(ctx, `SELECT external_id, sku_id, linked_at FROM sku_external_links WHERE sku_id = $1`, skuID.String())
+}
+
+func (r *PostgresExternalLinkRepository) find(ctx context.Context, query string, arg any) (domain.ExternalLink, error) {
+	var link domain.ExternalLink
+	var skuID string
+	err := r.pool.QueryRow(ctx, query, arg).Scan(&link.ExternalID, &skuID, &link.LinkedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ExternalLink{}, domain.ErrExternalLinkNotFound
+	}
+	if err != nil {
+		return domain.ExternalLink{}, err
+	}
+	link.SKUID, err = valueobjects.SKUIDFrom(skuID)
+	return link, err
+}
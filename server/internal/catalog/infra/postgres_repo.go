@@ -2,6 +2,7 @@ package infra
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -21,6 +22,36 @@ func NewPostgresSKURepository(pool *pgxpool.Pool) *PostgresSKURepository {
 	return &PostgresSKURepository{pool: pool}
 }
 
+// componentJSON is one bundle component as stored in skus.components
+type componentJSON struct {
+	SKUID    string `json:"sku_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type nutritionJSON struct {
+	ServingSizeGrams  float64 `json:"serving_size_grams,omitempty"`
+	EnergyKcal        float64 `json:"energy_kcal"`
+	FatGrams          float64 `json:"fat_grams"`
+	SaturatedFatGrams float64 `json:"saturated_fat_grams"`
+	CarbohydrateGrams float64 `json:"carbohydrate_grams"`
+	SugarsGrams       float64 `json:"sugars_grams"`
+	ProteinGrams      float64 `json:"protein_grams"`
+	SaltGrams         float64 `json:"salt_grams"`
+}
+
+func toNutritionJSON(n domain.NutritionFacts) nutritionJSON {
+	return nutritionJSON(n)
+}
+
+func (n nutritionJSON) toDomain() domain.NutritionFacts {
+	return domain.NutritionFacts(n)
+}
+
+type translationJSON struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
 // skuRow is a DB-layer struct (never leaves this file)
 type skuRow struct {
 	ID              string
@@ -31,39 +62,206 @@ type skuRow struct {
 	WeightGrams     float64
 	WeightTolerance float64
 	ImageURL        *string
-	Active          bool
+	Active          bool // published; kept so listings of SKUs on sale stay indexed
+	Lifecycle       string
+	CategoryID      *string
+	Attributes      []byte
+	Components      []byte
+	WeightSamples   []byte
+	Nutrition       []byte
+	Allergens       []byte
+	Translations    []byte
+	ShelfLifeDays   int
+	SupplierRef     string
+	CaseSize        int
+	ReorderPoint    int
+	DeletedAt       *time.Time
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
 
+const upsertSKUSQL = `
+	INSERT INTO skus (id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+	ON CONFLICT (id) DO UPDATE SET
+		name = EXCLUDED.name,
+		price_cents = EXCLUDED.price_cents,
+		currency = EXCLUDED.currency,
+		weight_grams = EXCLUDED.weight_grams,
+		weight_tolerance = EXCLUDED.weight_tolerance,
+		image_url = EXCLUDED.image_url,
+		active = EXCLUDED.active,
+		lifecycle = EXCLUDED.lifecycle,
+		category_id = EXCLUDED.category_id,
+		attributes = EXCLUDED.attributes,
+		components = EXCLUDED.components,
+		weight_samples = EXCLUDED.weight_samples,
+		nutrition = EXCLUDED.nutrition,
+		allergens = EXCLUDED.allergens,
+		translations = EXCLUDED.translations,
+		shelf_life_days = EXCLUDED.shelf_life_days,
+		supplier_ref = EXCLUDED.supplier_ref,
+		case_size = EXCLUDED.case_size,
+		reorder_point = EXCLUDED.reorder_point,
+		deleted_at = EXCLUDED.deleted_at,
+		updated_at = EXCLUDED.updated_at
+`
+
 func (r *PostgresSKURepository) Save(ctx context.Context, s *domain.SKU) error {
+	args, err := skuArgs(s)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, upsertSKUSQL, args...)
+	return err
+}
+
+// SaveAll saves the SKUs in one transaction; if any fails, none are saved
+func (r *PostgresSKURepository) SaveAll(ctx context.Context, skus []*domain.SKU) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, s := range skus {
+		args, err := skuArgs(s)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, upsertSKUSQL, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// SaveWithPriceChange saves the SKU and its price history entry in one
+// transaction
+func (r *PostgresSKURepository) SaveWithPriceChange(ctx context.Context, s *domain.SKU, change domain.PriceChange) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	args, err := skuArgs(s)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, upsertSKUSQL, args...); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO sku_price_history (sku_id, old_price_cents, old_currency, new_price_cents, new_currency, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, change.SKUID.String(), change.OldPrice.Amount(), change.OldPrice.Currency(),
+		change.NewPrice.Amount(), change.NewPrice.Currency(), change.ChangedBy, change.ChangedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresSKURepository) FindPriceHistory(ctx context.Context, id valueobjects.SKUID) ([]domain.PriceChange, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT old_price_cents, old_currency, new_price_cents, new_currency, changed_by, changed_at
+		FROM sku_price_history WHERE sku_id = $1
+		ORDER BY changed_at, id
+	`, id.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []domain.PriceChange
+	for rows.Next() {
+		var (
+			oldCents, newCents       int64
+			oldCurrency, newCurrency string
+			change                   = domain.PriceChange{SKUID: id}
+		)
+		if err := rows.Scan(&oldCents, &oldCurrency, &newCents, &newCurrency, &change.ChangedBy, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		if change.OldPrice, err = valueobjects.NewMoney(oldCents, oldCurrency); err != nil {
+			return nil, err
+		}
+		if change.NewPrice, err = valueobjects.NewMoney(newCents, newCurrency); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func skuArgs(s *domain.SKU) ([]any, error) {
 	var imageURL *string
 	if s.ImageURL() != "" {
 		url := s.ImageURL()
 		imageURL = &url
 	}
+	var categoryID *string
+	if !s.CategoryID().IsZero() {
+		id := s.CategoryID().String()
+		categoryID = &id
+	}
 
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO skus (id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		ON CONFLICT (id) DO UPDATE SET
-			name = EXCLUDED.name,
-			price_cents = EXCLUDED.price_cents,
-			currency = EXCLUDED.currency,
-			weight_grams = EXCLUDED.weight_grams,
-			weight_tolerance = EXCLUDED.weight_tolerance,
-			image_url = EXCLUDED.image_url,
-			active = EXCLUDED.active,
-			updated_at = EXCLUDED.updated_at
-	`, s.ID().String(), s.Code(), s.Name(), s.Price().Amount(), s.Price().Currency(),
-		s.Weight().Grams(), s.WeightTolerance(), imageURL, s.IsActive(), s.CreatedAt(), s.UpdatedAt())
+	attributes := s.Attributes()
+	if attributes == nil {
+		attributes = domain.Attributes{}
+	}
+	attributesJSON, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	var componentsJSON []byte
+	if s.IsBundle() {
+		components := make([]componentJSON, 0, len(s.Components()))
+		for _, c := range s.Components() {
+			components = append(components, componentJSON{SKUID: c.SKUID.String(), Quantity: c.Quantity})
+		}
+		if componentsJSON, err = json.Marshal(components); err != nil {
+			return nil, err
+		}
+	}
+
+	var weightSamplesJSON []byte
+	if samples := s.WeightSamples(); len(samples) > 0 {
+		if weightSamplesJSON, err = json.Marshal(samples); err != nil {
+			return nil, err
+		}
+	}
+
+	var nutritionJSON []byte
+	if n := s.Nutrition(); n != nil {
+		if nutritionJSON, err = json.Marshal(toNutritionJSON(*n)); err != nil {
+			return nil, err
+		}
+	}
+	allergensJSON, err := json.Marshal(s.Allergens())
+	if err != nil {
+		return nil, err
+	}
+	translations := make(map[string]translationJSON, len(s.Translations()))
+	for tag, t := range s.Translations() {
+		translations[tag] = translationJSON(t)
+	}
+	translationsJSON, err := json.Marshal(translations)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{s.ID().String(), s.Code(), s.Name(), s.Price().Amount(), s.Price().Currency(),
+		s.Weight().Grams(), s.WeightTolerance(), imageURL, s.IsActive(), string(s.Lifecycle()), categoryID, attributesJSON,
+		componentsJSON, weightSamplesJSON, nutritionJSON, allergensJSON, translationsJSON, s.ShelfLifeDays(),
+		s.Restocking().SupplierRef, s.Restocking().CaseSize, s.Restocking().ReorderPoint, s.DeletedAt(), s.CreatedAt(), s.UpdatedAt()}, nil
 }
 
 func (r *PostgresSKURepository) FindByID(ctx context.Context, id valueobjects.SKUID) (*domain.SKU, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, created_at, updated_at
+		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at
 		FROM skus WHERE id = $1
 	`, id.String())
 
@@ -72,7 +270,7 @@ func (r *PostgresSKURepository) FindByID(ctx context.Context, id valueobjects.SK
 
 func (r *PostgresSKURepository) FindByCode(ctx context.Context, code string) (*domain.SKU, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, created_at, updated_at
+		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at
 		FROM skus WHERE code = $1
 	`, code)
 
@@ -81,8 +279,8 @@ func (r *PostgresSKURepository) FindByCode(ctx context.Context, code string) (*d
 
 func (r *PostgresSKURepository) FindAllActive(ctx context.Context) ([]*domain.SKU, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, created_at, updated_at
-		FROM skus WHERE active = true ORDER BY name
+		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at
+		FROM skus WHERE active = true AND deleted_at IS NULL ORDER BY name
 	`)
 	if err != nil {
 		return nil, err
@@ -94,8 +292,8 @@ func (r *PostgresSKURepository) FindAllActive(ctx context.Context) ([]*domain.SK
 
 func (r *PostgresSKURepository) FindAll(ctx context.Context) ([]*domain.SKU, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, created_at, updated_at
-		FROM skus ORDER BY name
+		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at
+		FROM skus WHERE deleted_at IS NULL ORDER BY name
 	`)
 	if err != nil {
 		return nil, err
@@ -105,11 +303,24 @@ func (r *PostgresSKURepository) FindAll(ctx context.Context) ([]*domain.SKU, err
 	return r.scanSKUs(rows)
 }
 
+func (r *PostgresSKURepository) FindChangedSince(ctx context.Context, since time.Time) ([]*domain.SKU, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, code, name, price_cents, currency, weight_grams, weight_tolerance, image_url, active, lifecycle, category_id, attributes, components, weight_samples, nutrition, allergens, translations, shelf_life_days, supplier_ref, case_size, reorder_point, deleted_at, created_at, updated_at
+		FROM skus WHERE updated_at > $1 ORDER BY updated_at, id
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanSKUs(rows)
+}
+
 func (r *PostgresSKURepository) scanSKU(row pgx.Row) (*domain.SKU, error) {
 	var rec skuRow
 	err := row.Scan(
 		&rec.ID, &rec.Code, &rec.Name, &rec.PriceCents, &rec.Currency,
-		&rec.WeightGrams, &rec.WeightTolerance, &rec.ImageURL, &rec.Active,
+		&rec.WeightGrams, &rec.WeightTolerance, &rec.ImageURL, &rec.Active, &rec.Lifecycle, &rec.CategoryID, &rec.Attributes, &rec.Components, &rec.WeightSamples, &rec.Nutrition, &rec.Allergens, &rec.Translations, &rec.ShelfLifeDays, &rec.SupplierRef, &rec.CaseSize, &rec.ReorderPoint, &rec.DeletedAt,
 		&rec.CreatedAt, &rec.UpdatedAt,
 	)
 	if err != nil {
@@ -128,7 +339,7 @@ func (r *PostgresSKURepository) scanSKUs(rows pgx.Rows) ([]*domain.SKU, error) {
 		var rec skuRow
 		err := rows.Scan(
 			&rec.ID, &rec.Code, &rec.Name, &rec.PriceCents, &rec.Currency,
-			&rec.WeightGrams, &rec.WeightTolerance, &rec.ImageURL, &rec.Active,
+			&rec.WeightGrams, &rec.WeightTolerance, &rec.ImageURL, &rec.Active, &rec.Lifecycle, &rec.CategoryID, &rec.Attributes, &rec.Components, &rec.WeightSamples, &rec.Nutrition, &rec.Allergens, &rec.Translations, &rec.ShelfLifeDays, &rec.SupplierRef, &rec.CaseSize, &rec.ReorderPoint, &rec.DeletedAt,
 			&rec.CreatedAt, &rec.UpdatedAt,
 		)
 		if err != nil {
@@ -149,6 +360,46 @@ func (r *PostgresSKURepository) reconstitute(rec skuRow) *domain.SKU {
 		imageURL = *rec.ImageURL
 	}
 
+	var categoryID valueobjects.CategoryID
+	if rec.CategoryID != nil {
+		categoryID, _ = valueobjects.CategoryIDFrom(*rec.CategoryID)
+	}
+
+	var raw map[string]any
+	_ = json.Unmarshal(rec.Attributes, &raw)
+	attributes, _ := domain.NewAttributes(raw)
+
+	var components []domain.BundleComponent
+	if len(rec.Components) > 0 {
+		var stored []componentJSON
+		_ = json.Unmarshal(rec.Components, &stored)
+		for _, c := range stored {
+			skuID, _ := valueobjects.SKUIDFrom(c.SKUID)
+			components = append(components, domain.BundleComponent{SKUID: skuID, Quantity: c.Quantity})
+		}
+	}
+
+	var weightSamples []float64
+	_ = json.Unmarshal(rec.WeightSamples, &weightSamples)
+
+	var nutrition *domain.NutritionFacts
+	if len(rec.Nutrition) > 0 {
+		var stored nutritionJSON
+		if err := json.Unmarshal(rec.Nutrition, &stored); err == nil {
+			n := stored.toDomain()
+			nutrition = &n
+		}
+	}
+	var allergens []domain.Allergen
+	_ = json.Unmarshal(rec.Allergens, &allergens)
+
+	var storedTranslations map[string]translationJSON
+	_ = json.Unmarshal(rec.Translations, &storedTranslations)
+	translations := make(domain.Translations, len(storedTranslations))
+	for tag, t := range storedTranslations {
+		translations[tag] = domain.Translation(t)
+	}
+
 	return domain.Reconstitute(
 		id,
 		rec.Code,
@@ -157,7 +408,17 @@ func (r *PostgresSKURepository) reconstitute(rec skuRow) *domain.SKU {
 		weight,
 		rec.WeightTolerance,
 		imageURL,
-		rec.Active,
+		domain.Lifecycle(rec.Lifecycle),
+		categoryID,
+		attributes,
+		components,
+		weightSamples,
+		nutrition,
+		allergens,
+		translations,
+		rec.ShelfLifeDays,
+		domain.RestockingInfo{SupplierRef: rec.SupplierRef, CaseSize: rec.CaseSize, ReorderPoint: rec.ReorderPoint},
+		rec.DeletedAt,
 		rec.CreatedAt,
 		rec.UpdatedAt,
 	)
@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresSKUClassRepository implements domain.SKUClassRepository. Every
+// change also stamps the SKUs it maps or unmaps as updated, so incremental
+// device syncs pick up their new class.
+type PostgresSKUClassRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresSKUClassRepository(pool *pgxpool.Pool) *PostgresSKUClassRepository {
+	return &PostgresSKUClassRepository{pool: pool}
+}
+
+func (r *PostgresSKUClassRepository) Add(ctx context.Context, class domain.SKUClass) error {
+	_, err := r.pool.Exec(ctx, `
+		WITH added AS (
+			INSERT INTO sku_classes (class_id, sku_id, assigned_at)
+			VALUES ($1, $2, $3)
+			RETURNING sku_id
+		)
+		UPDATE skus SET updated_at = $3 WHERE id IN (SELECT sku_id FROM added)
+	`, class.ClassID, class.SKUID.String(), class.AssignedAt)
+	return classConflict(err)
+}
+
+func (r *PostgresSKUClassRepository) Update(ctx context.Context, class domain.SKUClass) error {
+	tag, err := r.pool.Exec(ctx, `
+		WITH previous AS (
+			SELECT sku_id FROM sku_classes WHERE class_id = $1
+		), moved AS (
+			UPDATE sku_classes SET sku_id = $2, assigned_at = $3 WHERE class_id = $1
+			RETURNING sku_id
+		)
+		UPDATE skus SET updated_at = $3
+		WHERE id IN (SELECT sku_id FROM previous UNION SELECT sku_id FROM moved)
+	`, class.ClassID, class.SKUID.String(), class.AssignedAt)
+	if err != nil {
+		return classConflict(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSKUClassNotFound
+	}
+	return nil
+}
+
+// classConflict tells a class ID in use from a SKU that has a class already
+func classConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == "sku_classes_pkey" {
+			return domain.ErrClassIDTaken
+		}
+		return domain.ErrSKUAlreadyClassified
+	}
+	return err
+}
+
+func (r *PostgresSKUClassRepository) FindByClassID(ctx context.Context, classID int) (domain.SKUClass, error) {
+	row := r.pool.QueryRow(ctx, `SELECT class_id, sku_id, assigned_at FROM sku_classes WHERE class_id = $1`, classID)
+	class, err := scanSKUClass(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.SKUClass{}, domain.ErrSKUClassNotFound
+	}
+	return class, err
+}
+
+func (r *PostgresSKUClassRepository) FindAll(ctx context.Context) ([]domain.SKUClass, error) {
+	rows, err := r.pool.Query(ctx, `SELECT class_id, sku_id, assigned_at FROM sku_classes ORDER BY class_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var classes []domain.SKUClass
+	for rows.Next() {
+		class, err := scanSKUClass(rows)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+	return classes, rows.Err()
+}
+
+func (r *PostgresSKUClassRepository) NextClassID(ctx context.Context) (int, error) {
+	var next int
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(MAX(class_id) + 1, 0) FROM sku_classes`).Scan(&next)
+	return next, err
+}
+
+func (r *PostgresSKUClassRepository) Delete(ctx context.Context, classID int) error {
+	tag, err := r.pool.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM sku_classes WHERE class_id = $1
+			RETURNING sku_id
+		)
+		UPDATE skus SET updated_at = $2 WHERE id IN (SELECT sku_id FROM removed)
+	`, classID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSKUClassNotFound
+	}
+	return nil
+}
+
+func scanSKUClass(row pgx.Row) (domain.SKUClass, error) {
+	var class domain.SKUClass
+	var skuID string
+	if err := row.Scan(&class.ClassID, &skuID, &class.AssignedAt); err != nil {
+		return domain.SKUClass{}, err
+	}
+	var err error
+	class.SKUID, err = valueobjects.SKUIDFrom(skuID)
+	return class, err
+}
@@ -0,0 +1,126 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/catalog/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const trainingImageColumns = `id, sku_id, object_key, content_type, size_bytes, checksum, bounding_box, source, uploaded_by, created_at`
+
+// PostgresTrainingImageRepository implements domain.TrainingImageRepository
+type PostgresTrainingImageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTrainingImageRepository(pool *pgxpool.Pool) *PostgresTrainingImageRepository {
+	return &PostgresTrainingImageRepository{pool: pool}
+}
+
+type boundingBoxJSON struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+func (r *PostgresTrainingImageRepository) Save(ctx context.Context, image domain.TrainingImage) error {
+	var box []byte
+	if image.Box != nil {
+		box, _ = json.Marshal(boundingBoxJSON(*image.Box))
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sku_training_images (`+trainingImageColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, image.ID.String(), image.SKUID.String(), image.ObjectKey, image.ContentType, image.SizeBytes,
+		image.Checksum, box, image.Source, image.UploadedBy, image.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrDuplicateTrainingImage
+	}
+	return err
+}
+
+func (r *PostgresTrainingImageRepository) FindByID(ctx context.Context, id valueobjects.TrainingImageID) (domain.TrainingImage, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+trainingImageColumns+` FROM sku_training_images WHERE id = $1`, id.String())
+	image, err := scanTrainingImage(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TrainingImage{}, domain.ErrTrainingImageNotFound
+	}
+	return image, err
+}
+
+func (r *PostgresTrainingImageRepository) FindBySKU(ctx context.Context, skuID valueobjects.SKUID) ([]domain.TrainingImage, error) {
+	return r.query(ctx, `
+		SELECT `+trainingImageColumns+` FROM sku_training_images
+		WHERE sku_id = $1 ORDER BY created_at, id
+	`, skuID.String())
+}
+
+func (r *PostgresTrainingImageRepository) FindAll(ctx context.Context) ([]domain.TrainingImage, error) {
+	return r.query(ctx, `
+		SELECT `+trainingImageColumns+` FROM sku_training_images
+		ORDER BY sku_id, created_at, id
+	`)
+}
+
+func (r *PostgresTrainingImageRepository) Delete(ctx context.Context, id valueobjects.TrainingImageID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sku_training_images WHERE id = $1`, id.String())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTrainingImageNotFound
+	}
+	return nil
+}
+
+func (r *PostgresTrainingImageRepository) query(ctx context.Context, sql string, args ...any) ([]domain.TrainingImage, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []domain.TrainingImage
+	for rows.Next() {
+		image, err := scanTrainingImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, rows.Err()
+}
+
+func scanTrainingImage(row pgx.Row) (domain.TrainingImage, error) {
+	var (
+		rawID, rawSKUID string
+		box             []byte
+		image           domain.TrainingImage
+	)
+	if err := row.Scan(&rawID, &rawSKUID, &image.ObjectKey, &image.ContentType, &image.SizeBytes,
+		&image.Checksum, &box, &image.Source, &image.UploadedBy, &image.CreatedAt); err != nil {
+		return domain.TrainingImage{}, err
+	}
+
+	image.ID, _ = valueobjects.TrainingImageIDFrom(rawID)
+	image.SKUID, _ = valueobjects.SKUIDFrom(rawSKUID)
+	if len(box) > 0 {
+		var b boundingBoxJSON
+		if err := json.Unmarshal(box, &b); err == nil {
+			bb := domain.BoundingBox(b)
+			image.Box = &bb
+		}
+	}
+	return image, nil
+}
@@ -9,6 +9,48 @@ func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
 		skus.POST("", h.Create)
 		skus.GET("", h.List)
 		skus.GET("/active", h.ListActive)
+		skus.GET("/export", h.Export)
+		skus.POST("/import", h.Import)
+		skus.POST("/bundles", h.CreateBundle)
+		skus.GET("/training-images", h.ExportTrainingDataset)
 		skus.GET("/:id", h.Get)
+		skus.PUT("/:id", h.Update)
+		skus.GET("/:id/price-history", h.PriceHistory)
+		skus.POST("/:id/weight-samples", h.RecordWeightSamples)
+		skus.DELETE("/:id", h.Delete)
+		skus.POST("/:id/restore", h.Restore)
+		skus.POST("/:id/publish", h.Publish)
+		skus.POST("/:id/retire", h.Retire)
+		skus.POST("/:id/activate", h.Publish)
+		skus.POST("/:id/deactivate", h.Retire)
+		skus.PUT("/:id/category", h.AssignCategory)
+		skus.POST("/:id/training-images", h.AddTrainingImage)
+		skus.GET("/:id/training-images", h.ListTrainingImages)
+		skus.GET("/:id/training-images/:image", h.TrainingImageContent)
+		skus.DELETE("/:id/training-images/:image", h.DeleteTrainingImage)
+	}
+
+	integrations := rg.Group("/integrations")
+	{
+		integrations.POST("/catalog-webhook", h.CatalogWebhook)
+	}
+
+	categories := rg.Group("/categories")
+	{
+		categories.POST("", h.CreateCategory)
+		categories.GET("", h.ListCategories)
+		categories.GET("/:id", h.GetCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+
+	admin := rg.Group("/admin")
+	{
+		admin.POST("/ml/sync-classes", h.SyncClasses)
+		admin.GET("/ml/classes", h.ListClasses)
+		admin.POST("/ml/classes", h.AssignClass)
+		admin.GET("/ml/classes/:class_id", h.GetClass)
+		admin.PUT("/ml/classes/:class_id", h.ReassignClass)
+		admin.DELETE("/ml/classes/:class_id", h.RemoveClass)
 	}
 }
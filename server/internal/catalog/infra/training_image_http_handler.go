@@ -0,0 +1,202 @@
+package infra
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/catalog/app"
+	"github.com/vending-machine/server/internal/catalog/domain"
+)
+
+type boundingBoxResponse struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type trainingImageResponse struct {
+	ID          string               `json:"id"`
+	SKUID       string               `json:"sku_id"`
+	SKUCode     string               `json:"sku_code,omitempty"` // dataset export only
+	ContentType string               `json:"content_type"`
+	SizeBytes   int                  `json:"size_bytes"`
+	Checksum    string               `json:"checksum"` // SHA-256, hex
+	BoundingBox *boundingBoxResponse `json:"bounding_box,omitempty"`
+	Source      string               `json:"source,omitempty"`
+	UploadedBy  string               `json:"uploaded_by,omitempty"`
+	ContentURL  string               `json:"content_url"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// AddTrainingImage attaches a JPEG, PNG or WebP image, uploaded as the
+// multipart field "image", to a SKU. The optional "bounding_box" field marks
+// the SKU in the image as "x,y,width,height" fractions of the image size, and
+// "source" says where the photo was taken.
+func (h *HTTPHandler) AddTrainingImage(c *gin.Context) {
+	// Room for the form fields around the largest accepted image
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxTrainingImageBytes+64<<10)
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrInvalidTrainingImage.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an image is required in the \"image\" field"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read uploaded file"})
+		return
+	}
+	defer f.Close()
+	image, err := io.ReadAll(io.LimitReader(f, domain.MaxTrainingImageBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read uploaded file"})
+		return
+	}
+
+	var box *domain.BoundingBox
+	if raw := c.PostForm("bounding_box"); raw != "" {
+		if box, err = parseBoundingBox(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	added, err := h.addTrainingImageHandler.Handle(c.Request.Context(), app.AddTrainingImageCommand{
+		SKUID:      c.Param("id"),
+		Image:      image,
+		Box:        box,
+		Source:     c.PostForm("source"),
+		UploadedBy: c.GetHeader(actorIDHeader),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTrainingImage),
+			errors.Is(err, domain.ErrInvalidBoundingBox):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrDuplicateTrainingImage):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			writeSKUChangeError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTrainingImageResponse(added))
+}
+
+// ListTrainingImages lists a SKU's training images, oldest first
+func (h *HTTPHandler) ListTrainingImages(c *gin.Context) {
+	images, err := h.trainingImageQuery.ListForSKU(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeSKUChangeError(c, err)
+		return
+	}
+
+	response := make([]trainingImageResponse, 0, len(images))
+	for _, image := range images {
+		response = append(response, toTrainingImageResponse(image))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"images": response,
+		"count":  len(response),
+	})
+}
+
+// TrainingImageContent serves the image itself
+func (h *HTTPHandler) TrainingImageContent(c *gin.Context) {
+	image, data, err := h.trainingImageQuery.Content(c.Request.Context(), trainingImageRef(c))
+	if err != nil {
+		writeTrainingImageError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, image.ContentType, data)
+}
+
+// DeleteTrainingImage takes an image out of a SKU's training data
+func (h *HTTPHandler) DeleteTrainingImage(c *gin.Context) {
+	if err := h.deleteTrainingImageHandler.Handle(c.Request.Context(), trainingImageRef(c)); err != nil {
+		writeTrainingImageError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ExportTrainingDataset lists the training images of the whole catalog,
+// each labeled with its SKU code, for building ML datasets
+func (h *HTTPHandler) ExportTrainingDataset(c *gin.Context) {
+	dataset, err := h.trainingImageQuery.Dataset(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]trainingImageResponse, 0, len(dataset))
+	for _, entry := range dataset {
+		image := toTrainingImageResponse(entry.Image)
+		image.SKUCode = entry.SKUCode
+		response = append(response, image)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"images": response,
+		"count":  len(response),
+	})
+}
+
+func trainingImageRef(c *gin.Context) app.TrainingImageRef {
+	return app.TrainingImageRef{SKUID: c.Param("id"), ImageID: c.Param("image")}
+}
+
+func writeTrainingImageError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrTrainingImageNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
+
+func parseBoundingBox(raw string) (*domain.BoundingBox, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("bounding_box must be x,y,width,height")
+	}
+	var values [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errors.New("bounding_box must be x,y,width,height")
+		}
+		values[i] = v
+	}
+	return &domain.BoundingBox{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
+func toTrainingImageResponse(image domain.TrainingImage) trainingImageResponse {
+	response := trainingImageResponse{
+		ID:          image.ID.String(),
+		SKUID:       image.SKUID.String(),
+		ContentType: image.ContentType,
+		SizeBytes:   image.SizeBytes,
+		Checksum:    image.Checksum,
+		Source:      image.Source,
+		UploadedBy:  image.UploadedBy,
+		ContentURL:  "/api/v1/skus/" + image.SKUID.String() + "/training-images/" + image.ID.String(),
+		CreatedAt:   image.CreatedAt,
+	}
+	if image.Box != nil {
+		box := boundingBoxResponse(*image.Box)
+		response.BoundingBox = &box
+	}
+	return response
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// DecommissionedDeviceID returns the device a DeviceDecommissioned event took
+// out of service, so other contexts can react without importing the device domain
+func DecommissionedDeviceID(evt events.DomainEvent) (string, bool) {
+	decommissioned, ok := evt.(domain.DeviceDecommissioned)
+	if !ok {
+		return "", false
+	}
+	return decommissioned.DeviceID.String(), true
+}
+
+// OfflineDeviceID returns the device a DeviceWentOffline event found silent
+func OfflineDeviceID(evt events.DomainEvent) (string, bool) {
+	offline, ok := evt.(domain.DeviceWentOffline)
+	if !ok {
+		return "", false
+	}
+	return offline.DeviceID.String(), true
+}
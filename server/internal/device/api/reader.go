@@ -2,7 +2,10 @@ package api
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/vending-machine/server/internal/device/app"
 	"github.com/vending-machine/server/internal/device/domain"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
@@ -13,7 +16,45 @@ type DeviceView struct {
 	MachineID string
 	Name      string
 	Location  string
+	Region    string
 	IsActive  bool
+	IsBlocked bool
+
+	// InMaintenance is set while field staff work on the device; it sells
+	// nothing until it leaves maintenance
+	InMaintenance bool
+
+	// Restocking is set while an operator's restock session is open; it
+	// sells nothing until the session is closed
+	Restocking bool
+
+	// VerificationRequiredSince is set after a security incident; the first
+	// session started after it must be verified by cloud detection
+	VerificationRequiredSince *time.Time
+
+	// ConfidenceThreshold is the minimum detection confidence set for the
+	// device or its group; nil when the deployment's default applies
+	ConfidenceThreshold *float64
+
+	// ModelVersion is the detection model assigned to the device or its
+	// group; empty when the deployment's default applies
+	ModelVersion string
+
+	// SessionExpirationMinutes is how long a session started on the device
+	// lasts, set for the device or its group; nil when the deployment's
+	// default applies
+	SessionExpirationMinutes *int
+}
+
+// SalesStatusView tells other contexts whether a device may sell at a given time
+type SalesStatusView struct {
+	Open         bool
+	ClosedReason string
+
+	// RestrictedSKUs maps SKU codes that may not be sold at that time to the reason
+	RestrictedSKUs map[string]string
+	// Markdowns maps SKU codes close to expiry to the percentage taken off their price
+	Markdowns map[string]int
 }
 
 // DeviceReader is the interface other contexts use to read device data.
@@ -21,15 +62,23 @@ type DeviceView struct {
 type DeviceReader interface {
 	FindByMachineID(ctx context.Context, machineID string) (*DeviceView, error)
 	FindByID(ctx context.Context, id string) (*DeviceView, error)
+	SalesStatusAt(ctx context.Context, id string, at time.Time) (*SalesStatusView, error)
+	// AssignedSKUs returns the SKU codes assigned to the device, or nil when
+	// none are and it may sell the whole catalog
+	AssignedSKUs(ctx context.Context, id string) (map[string]bool, error)
 }
 
-// DeviceReaderAdapter implements DeviceReader using the domain repository
+// DeviceReaderAdapter implements DeviceReader using the domain repositories
 type DeviceReaderAdapter struct {
-	repo domain.DeviceRepository
+	repo        domain.DeviceRepository
+	salesHours  domain.SalesHoursRepository
+	expiry      *app.ExpiryQueryService
+	assortments domain.AssortmentRepository
+	policies    *app.PolicyResolver
 }
 
-func NewDeviceReaderAdapter(repo domain.DeviceRepository) *DeviceReaderAdapter {
-	return &DeviceReaderAdapter{repo: repo}
+func NewDeviceReaderAdapter(repo domain.DeviceRepository, salesHours domain.SalesHoursRepository, expiry *app.ExpiryQueryService, assortments domain.AssortmentRepository, policies *app.PolicyResolver) *DeviceReaderAdapter {
+	return &DeviceReaderAdapter{repo: repo, salesHours: salesHours, expiry: expiry, assortments: assortments, policies: policies}
 }
 
 func (a *DeviceReaderAdapter) FindByMachineID(ctx context.Context, machineID string) (*DeviceView, error) {
@@ -37,7 +86,7 @@ func (a *DeviceReaderAdapter) FindByMachineID(ctx context.Context, machineID str
 	if err != nil {
 		return nil, err
 	}
-	return toDeviceView(device), nil
+	return a.toDeviceView(ctx, device)
 }
 
 func (a *DeviceReaderAdapter) FindByID(ctx context.Context, id string) (*DeviceView, error) {
@@ -49,15 +98,82 @@ func (a *DeviceReaderAdapter) FindByID(ctx context.Context, id string) (*DeviceV
 	if err != nil {
 		return nil, err
 	}
-	return toDeviceView(device), nil
+	return a.toDeviceView(ctx, device)
+}
+
+// SalesStatusAt evaluates the device's opening hours and blackouts; devices
+// without sales hours are always open. SKUs with expired batches still in the
+// device are restricted until the batches are written off.
+func (a *DeviceReaderAdapter) SalesStatusAt(ctx context.Context, id string, at time.Time) (*SalesStatusView, error) {
+	deviceID, err := valueobjects.DeviceIDFrom(id)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := a.salesHours.FindByDeviceID(ctx, deviceID)
+	if errors.Is(err, domain.ErrSalesHoursNotFound) {
+		hours = domain.AlwaysOpen(deviceID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	freshness, err := a.expiry.FreshnessAt(ctx, deviceID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	status := hours.StatusAt(at)
+	for code, expiresOn := range freshness.Expired {
+		if _, ok := status.RestrictedSKUs[code]; !ok {
+			status.RestrictedSKUs[code] = "batch expired on " + expiresOn.Format(time.DateOnly)
+		}
+	}
+	return &SalesStatusView{
+		Open:           status.Open,
+		ClosedReason:   status.ClosedReason,
+		RestrictedSKUs: status.RestrictedSKUs,
+		Markdowns:      freshness.Markdowns,
+	}, nil
+}
+
+func (a *DeviceReaderAdapter) AssignedSKUs(ctx context.Context, id string) (map[string]bool, error) {
+	deviceID, err := valueobjects.DeviceIDFrom(id)
+	if err != nil {
+		return nil, err
+	}
+	assortment, err := a.assortments.FindByDeviceID(ctx, deviceID)
+	if errors.Is(err, domain.ErrAssortmentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string]bool)
+	for _, code := range assortment.SKUCodes() {
+		assigned[code] = true
+	}
+	return assigned, nil
 }
 
-func toDeviceView(d *domain.Device) *DeviceView {
+func (a *DeviceReaderAdapter) toDeviceView(ctx context.Context, d *domain.Device) (*DeviceView, error) {
+	policy, err := a.policies.Policy(ctx, d)
+	if err != nil {
+		return nil, err
+	}
 	return &DeviceView{
 		ID:        d.ID().String(),
 		MachineID: d.MachineID(),
 		Name:      d.Name(),
 		Location:  d.Location(),
+		Region:    d.Region(),
 		IsActive:  d.IsActive(),
-	}
+		IsBlocked: d.IsBlocked(),
+
+		InMaintenance:             d.IsInMaintenance(),
+		Restocking:                d.IsRestocking(),
+		VerificationRequiredSince: d.VerificationRequiredSince(),
+		ConfidenceThreshold:       policy.ConfidenceThreshold,
+		ModelVersion:              policy.ModelVersion,
+		SessionExpirationMinutes:  policy.SessionExpirationMinutes,
+	}, nil
 }
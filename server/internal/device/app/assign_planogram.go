@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// AssignPlanogramCommand is the input DTO for setting a device's merchandising layout
+type AssignPlanogramCommand struct {
+	MachineID string
+	Facings   []domain.PlanogramFacing
+}
+
+// AssignPlanogramHandler assigns or replaces the planogram of a device
+type AssignPlanogramHandler struct {
+	devices    domain.DeviceRepository
+	planograms domain.PlanogramRepository
+	publisher  EventPublisher
+}
+
+func NewAssignPlanogramHandler(devices domain.DeviceRepository, planograms domain.PlanogramRepository, publisher EventPublisher) *AssignPlanogramHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if planograms == nil {
+		panic("nil PlanogramRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AssignPlanogramHandler{
+		devices:    devices,
+		planograms: planograms,
+		publisher:  publisher,
+	}
+}
+
+func (h *AssignPlanogramHandler) Handle(ctx context.Context, cmd AssignPlanogramCommand) (*PlanogramView, error) {
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+
+	planogram, err := h.planograms.FindByDeviceID(ctx, dev.ID())
+	switch {
+	case errors.Is(err, domain.ErrPlanogramNotFound):
+		planogram, err = domain.NewPlanogram(dev.ID(), cmd.Facings)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := planogram.Replace(cmd.Facings); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := h.planograms.Save(ctx, planogram); err != nil {
+		return nil, fmt.Errorf("failed to save planogram: %w", err)
+	}
+
+	for _, evt := range planogram.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toPlanogramView(dev, planogram, domain.PolicySourceDevice)
+	return &view, nil
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// AssignSKUsCommand is the input DTO for adding SKUs to a device's assortment
+type AssignSKUsCommand struct {
+	DeviceID string
+	SKUCodes []string
+}
+
+// UnassignSKUCommand is the input DTO for taking a SKU out of a device's assortment
+type UnassignSKUCommand struct {
+	DeviceID string
+	SKUCode  string
+}
+
+// AssignSKUsHandler adds catalog SKUs to the assortment of a device
+type AssignSKUsHandler struct {
+	devices     domain.DeviceRepository
+	assortments domain.AssortmentRepository
+	catalog     SKUCatalog
+	publisher   EventPublisher
+}
+
+func NewAssignSKUsHandler(devices domain.DeviceRepository, assortments domain.AssortmentRepository, catalog SKUCatalog, publisher EventPublisher) *AssignSKUsHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if assortments == nil {
+		panic("nil AssortmentRepository")
+	}
+	if catalog == nil {
+		panic("nil SKUCatalog")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AssignSKUsHandler{
+		devices:     devices,
+		assortments: assortments,
+		catalog:     catalog,
+		publisher:   publisher,
+	}
+}
+
+func (h *AssignSKUsHandler) Handle(ctx context.Context, cmd AssignSKUsCommand) (*AssortmentView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := h.catalog.KnownSKUCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range cmd.SKUCodes {
+		if code = strings.TrimSpace(code); code != "" && !known[code] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownSKU, code)
+		}
+	}
+
+	assortment, err := findOrNewAssortment(ctx, h.assortments, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+	if err := assortment.Assign(cmd.SKUCodes); err != nil {
+		return nil, err
+	}
+
+	if err := h.assortments.Save(ctx, assortment); err != nil {
+		return nil, fmt.Errorf("failed to save assortment: %w", err)
+	}
+
+	for _, evt := range assortment.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toAssortmentView(dev, assortment)
+	return &view, nil
+}
+
+// UnassignSKUHandler takes a SKU out of a device's assortment. Taking out the
+// last one lets the device sell the whole catalog again.
+type UnassignSKUHandler struct {
+	devices     domain.DeviceRepository
+	assortments domain.AssortmentRepository
+	publisher   EventPublisher
+}
+
+func NewUnassignSKUHandler(devices domain.DeviceRepository, assortments domain.AssortmentRepository, publisher EventPublisher) *UnassignSKUHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if assortments == nil {
+		panic("nil AssortmentRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &UnassignSKUHandler{devices: devices, assortments: assortments, publisher: publisher}
+}
+
+func (h *UnassignSKUHandler) Handle(ctx context.Context, cmd UnassignSKUCommand) (*AssortmentView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	assortment, err := findOrNewAssortment(ctx, h.assortments, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+	if err := assortment.Unassign(strings.TrimSpace(cmd.SKUCode)); err != nil {
+		return nil, err
+	}
+
+	if err := h.assortments.Save(ctx, assortment); err != nil {
+		return nil, fmt.Errorf("failed to save assortment: %w", err)
+	}
+
+	for _, evt := range assortment.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toAssortmentView(dev, assortment)
+	return &view, nil
+}
+
+// findOrNewAssortment loads a device's assortment; a device without assigned
+// SKUs has an empty one
+func findOrNewAssortment(ctx context.Context, assortments domain.AssortmentRepository, deviceID valueobjects.DeviceID) (*domain.Assortment, error) {
+	assortment, err := assortments.FindByDeviceID(ctx, deviceID)
+	if errors.Is(err, domain.ErrAssortmentNotFound) {
+		return domain.NewAssortment(deviceID), nil
+	}
+	return assortment, err
+}
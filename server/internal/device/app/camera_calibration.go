@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// CameraCalibrationView is a read-only view of a device's camera calibration
+type CameraCalibrationView struct {
+	DeviceID     string
+	MachineID    string
+	Version      int
+	Homography   domain.Homography
+	Markers      []domain.ReferenceMarker
+	Capture      domain.CameraSettings
+	CalibratedAt time.Time
+}
+
+func toCameraCalibrationView(dev *domain.Device, c *domain.CameraCalibration) CameraCalibrationView {
+	return CameraCalibrationView{
+		DeviceID:     dev.ID().String(),
+		MachineID:    dev.MachineID(),
+		Version:      c.Version(),
+		Homography:   c.Homography(),
+		Markers:      c.Markers(),
+		Capture:      c.Capture(),
+		CalibratedAt: c.CalibratedAt(),
+	}
+}
+
+// UploadCameraCalibrationCommand is a calibration the device just made
+type UploadCameraCalibrationCommand struct {
+	MachineID  string
+	DeviceKey  string
+	Homography []float64 // row-major 3x3
+	Markers    []domain.ReferenceMarker
+	Capture    domain.CameraSettings
+}
+
+// UploadCameraCalibrationHandler keeps the latest camera calibration of a
+// device. The device authenticates with its own key.
+type UploadCameraCalibrationHandler struct {
+	devices      domain.DeviceRepository
+	calibrations domain.CameraCalibrationRepository
+	publisher    EventPublisher
+}
+
+func NewUploadCameraCalibrationHandler(devices domain.DeviceRepository, calibrations domain.CameraCalibrationRepository, publisher EventPublisher) *UploadCameraCalibrationHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if calibrations == nil {
+		panic("nil CameraCalibrationRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &UploadCameraCalibrationHandler{devices: devices, calibrations: calibrations, publisher: publisher}
+}
+
+func (h *UploadCameraCalibrationHandler) Handle(ctx context.Context, cmd UploadCameraCalibrationCommand) (CameraCalibrationView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	if dev.IsDecommissioned() {
+		return CameraCalibrationView{}, domain.ErrDeviceDecommissioned
+	}
+
+	homography, err := domain.NewHomography(cmd.Homography)
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	calibration, err := findOrNewCameraCalibration(ctx, h.calibrations, dev)
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	if err := calibration.Record(homography, cmd.Markers, cmd.Capture, time.Now()); err != nil {
+		return CameraCalibrationView{}, err
+	}
+
+	if err := h.calibrations.Save(ctx, calibration); err != nil {
+		return CameraCalibrationView{}, fmt.Errorf("failed to save camera calibration: %w", err)
+	}
+
+	for _, evt := range calibration.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toCameraCalibrationView(dev, calibration), nil
+}
+
+// CameraCalibrationQueryService reads device camera calibrations
+type CameraCalibrationQueryService struct {
+	devices      domain.DeviceRepository
+	calibrations domain.CameraCalibrationRepository
+}
+
+func NewCameraCalibrationQueryService(devices domain.DeviceRepository, calibrations domain.CameraCalibrationRepository) *CameraCalibrationQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if calibrations == nil {
+		panic("nil CameraCalibrationRepository")
+	}
+	return &CameraCalibrationQueryService{devices: devices, calibrations: calibrations}
+}
+
+// ForDevice hands a device its own calibration, typically on boot. The
+// device authenticates with its key.
+func (s *CameraCalibrationQueryService) ForDevice(ctx context.Context, machineID, key string) (CameraCalibrationView, error) {
+	dev, err := authenticateDevice(ctx, s.devices, machineID, key)
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	return s.find(ctx, dev)
+}
+
+// FindByDeviceID returns a device's calibration for operators
+func (s *CameraCalibrationQueryService) FindByDeviceID(ctx context.Context, deviceID string) (CameraCalibrationView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	return s.find(ctx, dev)
+}
+
+func (s *CameraCalibrationQueryService) find(ctx context.Context, dev *domain.Device) (CameraCalibrationView, error) {
+	calibration, err := s.calibrations.FindByDeviceID(ctx, dev.ID())
+	if err != nil {
+		return CameraCalibrationView{}, err
+	}
+	return toCameraCalibrationView(dev, calibration), nil
+}
+
+func findOrNewCameraCalibration(ctx context.Context, calibrations domain.CameraCalibrationRepository, dev *domain.Device) (*domain.CameraCalibration, error) {
+	calibration, err := calibrations.FindByDeviceID(ctx, dev.ID())
+	if errors.Is(err, domain.ErrCalibrationNotFound) {
+		return domain.NewCameraCalibration(dev.ID()), nil
+	}
+	return calibration, err
+}
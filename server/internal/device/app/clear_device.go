@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// ClearDeviceCommand is the operator's confirmation that a blocked device may sell again
+type ClearDeviceCommand struct {
+	MachineID string
+	ClearedBy string
+	Note      string
+}
+
+// ClearDeviceHandler closes the open excursion and unblocks the device
+type ClearDeviceHandler struct {
+	devices    domain.DeviceRepository
+	excursions domain.ExcursionRepository
+	publisher  EventPublisher
+}
+
+func NewClearDeviceHandler(devices domain.DeviceRepository, excursions domain.ExcursionRepository, publisher EventPublisher) *ClearDeviceHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if excursions == nil {
+		panic("nil ExcursionRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ClearDeviceHandler{
+		devices:    devices,
+		excursions: excursions,
+		publisher:  publisher,
+	}
+}
+
+func (h *ClearDeviceHandler) Handle(ctx context.Context, cmd ClearDeviceCommand) error {
+	if cmd.ClearedBy == "" {
+		return domain.ErrClearedByRequired
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return err
+	}
+	if err := dev.ClearBlock(cmd.ClearedBy); err != nil {
+		return err
+	}
+
+	excursion, err := h.excursions.FindOpenByDeviceID(ctx, dev.ID())
+	if errors.Is(err, domain.ErrExcursionNotFound) {
+		excursion = nil
+	} else if err != nil {
+		return err
+	}
+	if excursion != nil {
+		if err := excursion.Clear(cmd.ClearedBy, cmd.Note); err != nil {
+			return err
+		}
+		if err := h.excursions.Save(ctx, excursion); err != nil {
+			return fmt.Errorf("failed to save excursion: %w", err)
+		}
+	}
+
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// DecommissionDeviceCommand is the operator's order to take a device out of service
+type DecommissionDeviceCommand struct {
+	MachineID        string
+	DecommissionedBy string
+}
+
+// DecommissionDeviceResult is the output DTO
+type DecommissionDeviceResult struct {
+	DeviceID           string
+	MachineID          string
+	DecommissionedAt   time.Time
+	ArchivedExcursions int
+	ArchivedIncidents  int
+}
+
+// DecommissionDeviceHandler takes a device out of service and archives its
+// telemetry history. The session open on the device is cancelled by the
+// transaction context when it sees the DeviceDecommissioned event.
+type DecommissionDeviceHandler struct {
+	devices    domain.DeviceRepository
+	excursions domain.ExcursionRepository
+	incidents  domain.IncidentRepository
+	publisher  EventPublisher
+}
+
+func NewDecommissionDeviceHandler(devices domain.DeviceRepository, excursions domain.ExcursionRepository, incidents domain.IncidentRepository, publisher EventPublisher) *DecommissionDeviceHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if excursions == nil {
+		panic("nil ExcursionRepository")
+	}
+	if incidents == nil {
+		panic("nil IncidentRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DecommissionDeviceHandler{
+		devices:    devices,
+		excursions: excursions,
+		incidents:  incidents,
+		publisher:  publisher,
+	}
+}
+
+func (h *DecommissionDeviceHandler) Handle(ctx context.Context, cmd DecommissionDeviceCommand) (DecommissionDeviceResult, error) {
+	if cmd.DecommissionedBy == "" {
+		return DecommissionDeviceResult{}, domain.ErrDecommissionedByRequired
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return DecommissionDeviceResult{}, err
+	}
+	now := time.Now().UTC()
+	if err := dev.Decommission(cmd.DecommissionedBy, now); err != nil {
+		return DecommissionDeviceResult{}, err
+	}
+
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return DecommissionDeviceResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	excursions, err := h.excursions.ArchiveByDeviceID(ctx, dev.ID(), now)
+	if err != nil {
+		return DecommissionDeviceResult{}, fmt.Errorf("failed to archive excursions: %w", err)
+	}
+	incidents, err := h.incidents.ArchiveByDeviceID(ctx, dev.ID(), now)
+	if err != nil {
+		return DecommissionDeviceResult{}, fmt.Errorf("failed to archive incidents: %w", err)
+	}
+
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return DecommissionDeviceResult{
+		DeviceID:           dev.ID().String(),
+		MachineID:          dev.MachineID(),
+		DecommissionedAt:   now,
+		ArchivedExcursions: excursions,
+		ArchivedIncidents:  incidents,
+	}, nil
+}
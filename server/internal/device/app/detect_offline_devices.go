@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// DetectOfflineDevicesCommand is the input DTO for one offline sweep
+type DetectOfflineDevicesCommand struct {
+	OfflineAfter time.Duration // zero uses the handler's window
+}
+
+// DetectOfflineDevicesResult is the output DTO
+type DetectOfflineDevicesResult struct {
+	OfflineAfter time.Duration
+	WentOffline  []string // machine IDs of the devices this sweep marked offline
+}
+
+// DetectOfflineDevicesHandler marks the devices that stopped sending
+// heartbeats as offline, once per outage. Every server instance sweeps on
+// its own schedule; a device two sweeps both mark raises DeviceWentOffline
+// twice, which listeners tolerate.
+type DetectOfflineDevicesHandler struct {
+	devices      domain.DeviceRepository
+	publisher    EventPublisher
+	offlineAfter time.Duration
+}
+
+func NewDetectOfflineDevicesHandler(devices domain.DeviceRepository, publisher EventPublisher, offlineAfter time.Duration) *DetectOfflineDevicesHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DetectOfflineDevicesHandler{devices: devices, publisher: publisher, offlineAfter: offlineAfter}
+}
+
+func (h *DetectOfflineDevicesHandler) Handle(ctx context.Context, cmd DetectOfflineDevicesCommand) (DetectOfflineDevicesResult, error) {
+	offlineAfter := cmd.OfflineAfter
+	if offlineAfter <= 0 {
+		offlineAfter = h.offlineAfter
+	}
+
+	devices, err := h.devices.FindAll(ctx)
+	if err != nil {
+		return DetectOfflineDevicesResult{}, err
+	}
+
+	now := time.Now()
+	result := DetectOfflineDevicesResult{OfflineAfter: offlineAfter, WentOffline: []string{}}
+	for _, dev := range devices {
+		if !dev.MarkOffline(now, offlineAfter) {
+			continue
+		}
+		if err := h.devices.Save(ctx, dev); err != nil {
+			return result, fmt.Errorf("failed to save device: %w", err)
+		}
+		for _, evt := range dev.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+		result.WentOffline = append(result.WentOffline, dev.MachineID())
+	}
+	return result, nil
+}
@@ -0,0 +1,342 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DeviceCommandFeed is an output port announcing which devices had commands
+// queued. Notifications are hints only: they may be duplicated or lost, so
+// consumers re-read the queue and refresh periodically.
+type DeviceCommandFeed interface {
+	Subscribe() (deviceIDs <-chan string, cancel func())
+}
+
+// DeviceCommandView is a read-only view of a queued command
+type DeviceCommandView struct {
+	ID             string
+	MachineID      string
+	Kind           string
+	Status         string
+	IssuedBy       string
+	IssuedAt       time.Time
+	Deliveries     int
+	DeliveredAt    *time.Time
+	AcknowledgedAt *time.Time
+	Result         string
+}
+
+func toDeviceCommandView(dev *domain.Device, c *domain.DeviceCommand) DeviceCommandView {
+	return DeviceCommandView{
+		ID:             c.ID().String(),
+		MachineID:      dev.MachineID(),
+		Kind:           string(c.Kind()),
+		Status:         string(c.Status()),
+		IssuedBy:       c.IssuedBy(),
+		IssuedAt:       c.IssuedAt(),
+		Deliveries:     c.Deliveries(),
+		DeliveredAt:    c.DeliveredAt(),
+		AcknowledgedAt: c.AcknowledgedAt(),
+		Result:         c.Result(),
+	}
+}
+
+// IssueDeviceCommandCommand is an operator's order for a device
+type IssueDeviceCommandCommand struct {
+	DeviceID string
+	Kind     domain.CommandKind
+	IssuedBy string
+}
+
+// IssueDeviceCommandHandler queues a command for a device. A device that is
+// offline gets it the next time it polls or connects.
+type IssueDeviceCommandHandler struct {
+	devices   domain.DeviceRepository
+	commands  domain.DeviceCommandRepository
+	publisher EventPublisher
+}
+
+func NewIssueDeviceCommandHandler(devices domain.DeviceRepository, commands domain.DeviceCommandRepository, publisher EventPublisher) *IssueDeviceCommandHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &IssueDeviceCommandHandler{devices: devices, commands: commands, publisher: publisher}
+}
+
+func (h *IssueDeviceCommandHandler) Handle(ctx context.Context, cmd IssueDeviceCommandCommand) (DeviceCommandView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return DeviceCommandView{}, err
+	}
+	if dev.IsDecommissioned() {
+		return DeviceCommandView{}, domain.ErrDeviceDecommissioned
+	}
+
+	command, err := domain.NewDeviceCommand(dev.ID(), cmd.Kind, cmd.IssuedBy, time.Now())
+	if err != nil {
+		return DeviceCommandView{}, err
+	}
+	if err := h.commands.Save(ctx, command); err != nil {
+		return DeviceCommandView{}, fmt.Errorf("failed to save device command: %w", err)
+	}
+
+	for _, evt := range command.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toDeviceCommandView(dev, command), nil
+}
+
+// DeviceCommandDelivery hands queued commands to devices, either to a
+// long-poll or to a device connected over a WebSocket
+type DeviceCommandDelivery struct {
+	devices  domain.DeviceRepository
+	commands domain.DeviceCommandRepository
+	feed     DeviceCommandFeed
+	refresh  time.Duration // fallback re-read for commands queued on other server instances
+}
+
+func NewDeviceCommandDelivery(devices domain.DeviceRepository, commands domain.DeviceCommandRepository, feed DeviceCommandFeed, refresh time.Duration) *DeviceCommandDelivery {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	if feed == nil {
+		panic("nil DeviceCommandFeed")
+	}
+	return &DeviceCommandDelivery{devices: devices, commands: commands, feed: feed, refresh: refresh}
+}
+
+// Poll authenticates the device and returns its unacknowledged commands. If
+// there are none it waits up to wait for one to be queued, and returns an
+// empty list when none was.
+func (s *DeviceCommandDelivery) Poll(ctx context.Context, machineID, key string, wait time.Duration) ([]DeviceCommandView, error) {
+	dev, err := authenticateDevice(ctx, s.devices, machineID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before the first read so no command slips in between
+	queued, cancel := s.feed.Subscribe()
+	defer cancel()
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+
+	for {
+		views, err := s.deliver(ctx, dev, nil)
+		if err != nil || len(views) > 0 {
+			return views, err
+		}
+
+		if !awaitCommand(ctx, dev.ID().String(), &queued, ticker.C, timeout.C) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return []DeviceCommandView{}, nil
+		}
+	}
+}
+
+// Watch authenticates the device and returns its unacknowledged commands
+// followed by every command queued while ctx lasts. Each command goes out
+// once per Watch; one left unacknowledged is sent again on the next.
+func (s *DeviceCommandDelivery) Watch(ctx context.Context, machineID, key string) (<-chan DeviceCommandView, error) {
+	dev, err := authenticateDevice(ctx, s.devices, machineID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	queued, cancel := s.feed.Subscribe()
+
+	out := make(chan DeviceCommandView)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+
+		sent := make(map[string]bool)
+		for {
+			views, err := s.deliver(ctx, dev, sent)
+			if err == nil {
+				for _, v := range views {
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				}
+			} // a failed read is retried on the next tick
+
+			if !awaitCommand(ctx, dev.ID().String(), &queued, ticker.C, nil) {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// awaitCommand blocks until a command may have been queued for the device:
+// the feed names it or the refresh ticks. It returns false once ctx is done
+// or timeout fires; a nil timeout never fires.
+func awaitCommand(ctx context.Context, deviceID string, queued *<-chan string, refresh, timeout <-chan time.Time) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timeout:
+			return false
+		case id, ok := <-*queued:
+			if !ok {
+				*queued = nil // feed gone; keep refreshing on the ticker
+				continue
+			}
+			if id == deviceID {
+				return true
+			}
+		case <-refresh:
+			return true
+		}
+	}
+}
+
+// deliver marks the device's unacknowledged commands not in sent as
+// delivered and returns them, adding them to sent when it is not nil
+func (s *DeviceCommandDelivery) deliver(ctx context.Context, dev *domain.Device, sent map[string]bool) ([]DeviceCommandView, error) {
+	commands, err := s.commands.FindUnacknowledged(ctx, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]DeviceCommandView, 0, len(commands))
+	for _, c := range commands {
+		if sent[c.ID().String()] {
+			continue
+		}
+		c.MarkDelivered(now)
+		if err := s.commands.Save(ctx, c); err != nil {
+			return nil, fmt.Errorf("failed to save device command: %w", err)
+		}
+		if sent != nil {
+			sent[c.ID().String()] = true
+		}
+		views = append(views, toDeviceCommandView(dev, c))
+	}
+	return views, nil
+}
+
+// AcknowledgeDeviceCommandCommand is the device's report on a command it ran
+type AcknowledgeDeviceCommandCommand struct {
+	MachineID string
+	DeviceKey string
+	CommandID string
+	Status    domain.CommandStatus
+	Result    string
+}
+
+// AcknowledgeDeviceCommandHandler records how a command went on the device,
+// which takes it out of the device's queue
+type AcknowledgeDeviceCommandHandler struct {
+	devices   domain.DeviceRepository
+	commands  domain.DeviceCommandRepository
+	publisher EventPublisher
+}
+
+func NewAcknowledgeDeviceCommandHandler(devices domain.DeviceRepository, commands domain.DeviceCommandRepository, publisher EventPublisher) *AcknowledgeDeviceCommandHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AcknowledgeDeviceCommandHandler{devices: devices, commands: commands, publisher: publisher}
+}
+
+func (h *AcknowledgeDeviceCommandHandler) Handle(ctx context.Context, cmd AcknowledgeDeviceCommandCommand) (DeviceCommandView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return DeviceCommandView{}, err
+	}
+
+	id, err := valueobjects.DeviceCommandIDFrom(cmd.CommandID)
+	if err != nil {
+		return DeviceCommandView{}, domain.ErrDeviceCommandNotFound
+	}
+	command, err := h.commands.FindByID(ctx, id)
+	if err != nil {
+		return DeviceCommandView{}, err
+	}
+	// Another device's command is as good as unknown
+	if command.DeviceID() != dev.ID() {
+		return DeviceCommandView{}, domain.ErrDeviceCommandNotFound
+	}
+
+	if err := command.Acknowledge(cmd.Status, cmd.Result, time.Now()); err != nil {
+		return DeviceCommandView{}, err
+	}
+	if err := h.commands.Save(ctx, command); err != nil {
+		return DeviceCommandView{}, fmt.Errorf("failed to save device command: %w", err)
+	}
+
+	for _, evt := range command.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toDeviceCommandView(dev, command), nil
+}
+
+// DeviceCommandQueryService lists the commands issued to a device
+type DeviceCommandQueryService struct {
+	devices  domain.DeviceRepository
+	commands domain.DeviceCommandRepository
+}
+
+func NewDeviceCommandQueryService(devices domain.DeviceRepository, commands domain.DeviceCommandRepository) *DeviceCommandQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	return &DeviceCommandQueryService{devices: devices, commands: commands}
+}
+
+// Commands returns the device's latest limit commands, newest first
+func (s *DeviceCommandQueryService) Commands(ctx context.Context, deviceID string, limit int) ([]DeviceCommandView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := s.commands.FindByDeviceID(ctx, dev.ID(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]DeviceCommandView, 0, len(commands))
+	for _, c := range commands {
+		views = append(views, toDeviceCommandView(dev, c))
+	}
+	return views, nil
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// SetDesiredConfigCommand is the input DTO for configuring a device
+type SetDesiredConfigCommand struct {
+	DeviceID            string
+	ConfidenceThreshold *float64
+	SyncIntervalSeconds int
+	Camera              domain.CameraSettings
+	ConfiguredBy        string
+}
+
+// SetDesiredConfigHandler replaces the configuration a device should run; the
+// device picks it up on its next pull
+type SetDesiredConfigHandler struct {
+	devices   domain.DeviceRepository
+	shadows   domain.ConfigShadowRepository
+	resolver  *PolicyResolver
+	publisher EventPublisher
+}
+
+func NewSetDesiredConfigHandler(devices domain.DeviceRepository, shadows domain.ConfigShadowRepository, resolver *PolicyResolver, publisher EventPublisher) *SetDesiredConfigHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if shadows == nil {
+		panic("nil ConfigShadowRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &SetDesiredConfigHandler{devices: devices, shadows: shadows, resolver: resolver, publisher: publisher}
+}
+
+func (h *SetDesiredConfigHandler) Handle(ctx context.Context, cmd SetDesiredConfigCommand) (*ConfigShadowView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if dev.IsDecommissioned() {
+		return nil, domain.ErrDeviceDecommissioned
+	}
+
+	config, err := domain.NewDeviceConfig(cmd.ConfidenceThreshold, cmd.SyncIntervalSeconds, cmd.Camera)
+	if err != nil {
+		return nil, err
+	}
+	shadow, err := findOrNewConfigShadow(ctx, h.shadows, dev)
+	if err != nil {
+		return nil, err
+	}
+	if err := shadow.SetDesired(config, cmd.ConfiguredBy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := h.shadows.Save(ctx, shadow); err != nil {
+		return nil, fmt.Errorf("failed to save device configuration: %w", err)
+	}
+
+	for _, evt := range shadow.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toConfigShadowView(ctx, h.resolver, dev, shadow)
+}
+
+// PullDeviceConfigCommand is the input DTO for a device fetching its configuration
+type PullDeviceConfigCommand struct {
+	MachineID string
+	DeviceKey string
+}
+
+// DesiredConfigView is the configuration a device should run
+type DesiredConfigView struct {
+	MachineID string
+	Version   int
+	Config    domain.DeviceConfig
+}
+
+// PullDeviceConfigHandler hands a device the configuration it should run. The
+// device authenticates with its own key.
+type PullDeviceConfigHandler struct {
+	devices  domain.DeviceRepository
+	shadows  domain.ConfigShadowRepository
+	resolver *PolicyResolver
+}
+
+func NewPullDeviceConfigHandler(devices domain.DeviceRepository, shadows domain.ConfigShadowRepository, resolver *PolicyResolver) *PullDeviceConfigHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if shadows == nil {
+		panic("nil ConfigShadowRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &PullDeviceConfigHandler{devices: devices, shadows: shadows, resolver: resolver}
+}
+
+func (h *PullDeviceConfigHandler) Handle(ctx context.Context, cmd PullDeviceConfigCommand) (*DesiredConfigView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return nil, err
+	}
+	shadow, err := findOrNewConfigShadow(ctx, h.shadows, dev)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := effectiveConfig(ctx, h.resolver, dev, shadow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DesiredConfigView{MachineID: dev.MachineID(), Version: shadow.Version(), Config: desired}, nil
+}
+
+// ReportDeviceConfigCommand is the input DTO for a device reporting the
+// configuration it applied
+type ReportDeviceConfigCommand struct {
+	MachineID           string
+	DeviceKey           string
+	Version             int // the desired version the device applied
+	ConfidenceThreshold *float64
+	SyncIntervalSeconds int
+	Camera              domain.CameraSettings
+}
+
+// ReportDeviceConfigHandler records the configuration a device runs. Values
+// are stored as reported, even out of bounds, so drift shows what the device
+// actually does.
+type ReportDeviceConfigHandler struct {
+	devices  domain.DeviceRepository
+	shadows  domain.ConfigShadowRepository
+	resolver *PolicyResolver
+}
+
+func NewReportDeviceConfigHandler(devices domain.DeviceRepository, shadows domain.ConfigShadowRepository, resolver *PolicyResolver) *ReportDeviceConfigHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if shadows == nil {
+		panic("nil ConfigShadowRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &ReportDeviceConfigHandler{devices: devices, shadows: shadows, resolver: resolver}
+}
+
+func (h *ReportDeviceConfigHandler) Handle(ctx context.Context, cmd ReportDeviceConfigCommand) (*ConfigShadowView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return nil, err
+	}
+	shadow, err := findOrNewConfigShadow(ctx, h.shadows, dev)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := domain.DeviceConfig{
+		ConfidenceThreshold: cmd.ConfidenceThreshold,
+		SyncIntervalSeconds: cmd.SyncIntervalSeconds,
+		Camera:              cmd.Camera,
+	}
+	if err := shadow.Report(reported, cmd.Version, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := h.shadows.Save(ctx, shadow); err != nil {
+		return nil, fmt.Errorf("failed to save device configuration: %w", err)
+	}
+
+	return toConfigShadowView(ctx, h.resolver, dev, shadow)
+}
+
+// ConfigShadowView is a read-only view of a device's desired and reported
+// configuration and how they differ
+type ConfigShadowView struct {
+	DeviceID        string
+	MachineID       string
+	Desired         domain.DeviceConfig // completed with the device's policy
+	Version         int
+	DesiredBy       string
+	DesiredAt       *time.Time // nil when no configuration was ever set
+	Reported        *domain.DeviceConfig
+	ReportedVersion int
+	ReportedAt      *time.Time
+	Status          domain.ConfigStatus
+	Drift           []domain.ConfigDrift
+}
+
+// ConfigQueryService provides read-only access to device configuration shadows
+type ConfigQueryService struct {
+	devices  domain.DeviceRepository
+	shadows  domain.ConfigShadowRepository
+	resolver *PolicyResolver
+}
+
+func NewConfigQueryService(devices domain.DeviceRepository, shadows domain.ConfigShadowRepository, resolver *PolicyResolver) *ConfigQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if shadows == nil {
+		panic("nil ConfigShadowRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &ConfigQueryService{devices: devices, shadows: shadows, resolver: resolver}
+}
+
+// FindByDeviceID returns the device's configuration shadow; a device never
+// configured and never reporting has an empty one
+func (s *ConfigQueryService) FindByDeviceID(ctx context.Context, deviceID string) (*ConfigShadowView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	shadow, err := findOrNewConfigShadow(ctx, s.shadows, dev)
+	if err != nil {
+		return nil, err
+	}
+	return toConfigShadowView(ctx, s.resolver, dev, shadow)
+}
+
+// authenticateDevice finds the device by machine ID and checks its key.
+// Unknown machines get the same answer as wrong keys.
+func authenticateDevice(ctx context.Context, devices domain.DeviceRepository, machineID, key string) (*domain.Device, error) {
+	dev, err := devices.FindByMachineID(ctx, machineID)
+	if errors.Is(err, domain.ErrDeviceNotFound) {
+		return nil, domain.ErrInvalidDeviceKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !dev.Authenticate(key) {
+		return nil, domain.ErrInvalidDeviceKey
+	}
+	return dev, nil
+}
+
+func findOrNewConfigShadow(ctx context.Context, shadows domain.ConfigShadowRepository, dev *domain.Device) (*domain.ConfigShadow, error) {
+	shadow, err := shadows.FindByDeviceID(ctx, dev.ID())
+	if errors.Is(err, domain.ErrConfigNotFound) {
+		return domain.NewConfigShadow(dev.ID()), nil
+	}
+	return shadow, err
+}
+
+// effectiveConfig is the desired configuration completed with the device's
+// policy, which is what the device is handed
+func effectiveConfig(ctx context.Context, resolver *PolicyResolver, dev *domain.Device, shadow *domain.ConfigShadow) (domain.DeviceConfig, error) {
+	policy, err := resolver.Policy(ctx, dev)
+	if err != nil {
+		return domain.DeviceConfig{}, err
+	}
+	return shadow.Desired().WithPolicy(policy), nil
+}
+
+func toConfigShadowView(ctx context.Context, resolver *PolicyResolver, dev *domain.Device, shadow *domain.ConfigShadow) (*ConfigShadowView, error) {
+	desired, err := effectiveConfig(ctx, resolver, dev, shadow)
+	if err != nil {
+		return nil, err
+	}
+	state := shadow.Compare(desired)
+
+	view := &ConfigShadowView{
+		DeviceID:        dev.ID().String(),
+		MachineID:       dev.MachineID(),
+		Desired:         desired,
+		Version:         shadow.Version(),
+		DesiredBy:       shadow.DesiredBy(),
+		Reported:        shadow.Reported(),
+		ReportedVersion: shadow.ReportedVersion(),
+		ReportedAt:      shadow.ReportedAt(),
+		Status:          state.Status,
+		Drift:           state.Drift,
+	}
+	if shadow.Version() > 0 {
+		desiredAt := shadow.DesiredAt()
+		view.DesiredAt = &desiredAt
+	}
+	return view, nil
+}
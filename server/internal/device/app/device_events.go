@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+const (
+	maxDeviceEventsPerReport = 500
+	// maxDeviceClockSkew is how far ahead of the server a device's clock may run
+	maxDeviceClockSkew = 5 * time.Minute
+)
+
+// DeviceEventReport is one door, tamper or power event as the device saw it
+type DeviceEventReport struct {
+	Kind       domain.DeviceEventKind
+	OccurredAt time.Time
+	Detail     string
+}
+
+// RecordDeviceEventsCommand is the input DTO for a batch of device events
+type RecordDeviceEventsCommand struct {
+	MachineID string
+	DeviceKey string
+	Events    []DeviceEventReport
+}
+
+// RecordDeviceEventsResult is the output DTO
+type RecordDeviceEventsResult struct {
+	MachineID  string
+	Received   int
+	Duplicates int // already in the log from an earlier upload of the same batch
+}
+
+// RecordDeviceEventsHandler appends the events a device uploads to its event
+// log. Devices retry whole batches after losing power or connectivity, so
+// events already logged are skipped rather than rejected.
+type RecordDeviceEventsHandler struct {
+	devices   domain.DeviceRepository
+	events    domain.DeviceEventRepository
+	publisher EventPublisher
+}
+
+func NewRecordDeviceEventsHandler(devices domain.DeviceRepository, events domain.DeviceEventRepository, publisher EventPublisher) *RecordDeviceEventsHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if events == nil {
+		panic("nil DeviceEventRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordDeviceEventsHandler{devices: devices, events: events, publisher: publisher}
+}
+
+func (h *RecordDeviceEventsHandler) Handle(ctx context.Context, cmd RecordDeviceEventsCommand) (RecordDeviceEventsResult, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return RecordDeviceEventsResult{}, err
+	}
+	switch {
+	case len(cmd.Events) == 0:
+		return RecordDeviceEventsResult{}, domain.ErrNoDeviceEvents
+	case len(cmd.Events) > maxDeviceEventsPerReport:
+		return RecordDeviceEventsResult{}, domain.ErrTooManyDeviceEvents
+	}
+
+	now := time.Now().UTC()
+	events := make([]*domain.DeviceEvent, 0, len(cmd.Events))
+	for _, r := range cmd.Events {
+		if r.OccurredAt.After(now.Add(maxDeviceClockSkew)) {
+			return RecordDeviceEventsResult{}, domain.ErrInvalidDeviceEvent
+		}
+		evt, err := domain.NewDeviceEvent(dev.ID(), r.Kind, r.OccurredAt, now, r.Detail)
+		if err != nil {
+			return RecordDeviceEventsResult{}, err
+		}
+		events = append(events, evt)
+	}
+
+	logged, err := h.events.Append(ctx, events)
+	if err != nil {
+		return RecordDeviceEventsResult{}, fmt.Errorf("failed to append device events: %w", err)
+	}
+
+	for _, evt := range logged {
+		_ = h.publisher.Publish(ctx, domain.NewDeviceEventLogged(evt))
+	}
+
+	return RecordDeviceEventsResult{
+		MachineID:  dev.MachineID(),
+		Received:   len(logged),
+		Duplicates: len(events) - len(logged),
+	}, nil
+}
+
+// DeviceEventView is a read-only view of a device event log entry
+type DeviceEventView struct {
+	ID         string
+	Kind       string
+	OccurredAt time.Time
+	ReceivedAt time.Time
+	Detail     string
+}
+
+// DeviceEventQueryService reads a device's event log
+type DeviceEventQueryService struct {
+	devices domain.DeviceRepository
+	events  domain.DeviceEventRepository
+}
+
+func NewDeviceEventQueryService(devices domain.DeviceRepository, events domain.DeviceEventRepository) *DeviceEventQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if events == nil {
+		panic("nil DeviceEventRepository")
+	}
+	return &DeviceEventQueryService{devices: devices, events: events}
+}
+
+// Events returns at most limit of the device's events that occurred in
+// [from, to), newest first; a zero from or to is unbounded
+func (s *DeviceEventQueryService) Events(ctx context.Context, machineID string, from, to time.Time, limit int) ([]DeviceEventView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.events.FindByDeviceID(ctx, dev.ID(), from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]DeviceEventView, 0, len(events))
+	for _, e := range events {
+		views = append(views, DeviceEventView{
+			ID:         e.ID().String(),
+			Kind:       string(e.Kind()),
+			OccurredAt: e.OccurredAt(),
+			ReceivedAt: e.ReceivedAt(),
+			Detail:     e.Detail(),
+		})
+	}
+	return views, nil
+}
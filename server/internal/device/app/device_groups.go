@@ -0,0 +1,240 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// CreateDeviceGroupHandler creates empty device groups
+type CreateDeviceGroupHandler struct {
+	groups domain.DeviceGroupRepository
+}
+
+func NewCreateDeviceGroupHandler(groups domain.DeviceGroupRepository) *CreateDeviceGroupHandler {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	return &CreateDeviceGroupHandler{groups: groups}
+}
+
+func (h *CreateDeviceGroupHandler) Handle(ctx context.Context, name string) (*DeviceGroupView, error) {
+	group, err := domain.NewDeviceGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.groups.Save(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to save device group: %w", err)
+	}
+
+	view := toDeviceGroupView(group, []*domain.Device{})
+	return &view, nil
+}
+
+// SetGroupPolicyCommand replaces the policy of a group. Nil or empty fields
+// are left to the deployment's defaults; a nil planogram removes the group's.
+type SetGroupPolicyCommand struct {
+	GroupID                  string
+	ConfidenceThreshold      *float64
+	ModelVersion             string
+	SessionExpirationMinutes *int
+	Planogram                []domain.PlanogramFacing
+}
+
+// SetGroupPolicyHandler configures the policy of a group's devices
+type SetGroupPolicyHandler struct {
+	groups  domain.DeviceGroupRepository
+	devices domain.DeviceRepository
+}
+
+func NewSetGroupPolicyHandler(groups domain.DeviceGroupRepository, devices domain.DeviceRepository) *SetGroupPolicyHandler {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &SetGroupPolicyHandler{groups: groups, devices: devices}
+}
+
+func (h *SetGroupPolicyHandler) Handle(ctx context.Context, cmd SetGroupPolicyCommand) (*DeviceGroupView, error) {
+	group, err := findGroupByID(ctx, h.groups, cmd.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := domain.NewDevicePolicy(cmd.ConfidenceThreshold, cmd.ModelVersion, cmd.SessionExpirationMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if err := group.SetPlanogram(cmd.Planogram); err != nil {
+		return nil, err
+	}
+	group.SetPolicy(policy)
+
+	if err := h.groups.Save(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to save device group: %w", err)
+	}
+
+	members, err := h.devices.FindByGroupID(ctx, group.ID())
+	if err != nil {
+		return nil, err
+	}
+	view := toDeviceGroupView(group, members)
+	return &view, nil
+}
+
+// AssignGroupDevicesCommand is the input DTO for adding devices to a group
+type AssignGroupDevicesCommand struct {
+	GroupID    string
+	MachineIDs []string
+}
+
+// AssignGroupDevicesHandler adds devices to a group, moving them out of the
+// group they were in
+type AssignGroupDevicesHandler struct {
+	groups  domain.DeviceGroupRepository
+	devices domain.DeviceRepository
+}
+
+func NewAssignGroupDevicesHandler(groups domain.DeviceGroupRepository, devices domain.DeviceRepository) *AssignGroupDevicesHandler {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &AssignGroupDevicesHandler{groups: groups, devices: devices}
+}
+
+func (h *AssignGroupDevicesHandler) Handle(ctx context.Context, cmd AssignGroupDevicesCommand) (*DeviceGroupView, error) {
+	group, err := findGroupByID(ctx, h.groups, cmd.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every device is looked up first, so an unknown machine ID assigns none
+	devices := make([]*domain.Device, 0, len(cmd.MachineIDs))
+	for _, machineID := range cmd.MachineIDs {
+		dev, err := h.devices.FindByMachineID(ctx, strings.TrimSpace(machineID))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, machineID)
+		}
+		devices = append(devices, dev)
+	}
+	for _, dev := range devices {
+		dev.JoinGroup(group.ID())
+		if err := h.devices.Save(ctx, dev); err != nil {
+			return nil, fmt.Errorf("failed to save device: %w", err)
+		}
+	}
+
+	members, err := h.devices.FindByGroupID(ctx, group.ID())
+	if err != nil {
+		return nil, err
+	}
+	view := toDeviceGroupView(group, members)
+	return &view, nil
+}
+
+// RemoveGroupDeviceCommand is the input DTO for taking a device out of a group
+type RemoveGroupDeviceCommand struct {
+	GroupID   string
+	MachineID string
+}
+
+// RemoveGroupDeviceHandler takes a device out of its group
+type RemoveGroupDeviceHandler struct {
+	groups  domain.DeviceGroupRepository
+	devices domain.DeviceRepository
+}
+
+func NewRemoveGroupDeviceHandler(groups domain.DeviceGroupRepository, devices domain.DeviceRepository) *RemoveGroupDeviceHandler {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &RemoveGroupDeviceHandler{groups: groups, devices: devices}
+}
+
+func (h *RemoveGroupDeviceHandler) Handle(ctx context.Context, cmd RemoveGroupDeviceCommand) (*DeviceGroupView, error) {
+	group, err := findGroupByID(ctx, h.groups, cmd.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+	if dev.GroupID() == nil || *dev.GroupID() != group.ID() {
+		return nil, domain.ErrDeviceNotInGroup
+	}
+
+	dev.LeaveGroup()
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return nil, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	members, err := h.devices.FindByGroupID(ctx, group.ID())
+	if err != nil {
+		return nil, err
+	}
+	view := toDeviceGroupView(group, members)
+	return &view, nil
+}
+
+// SetDevicePolicyCommand replaces the overrides set on a device itself; nil
+// or empty fields follow the device's group
+type SetDevicePolicyCommand struct {
+	DeviceID                 string
+	ConfidenceThreshold      *float64
+	ModelVersion             string
+	SessionExpirationMinutes *int
+}
+
+// SetDevicePolicyHandler overrides the group policy for one device
+type SetDevicePolicyHandler struct {
+	devices  domain.DeviceRepository
+	resolver *PolicyResolver
+}
+
+func NewSetDevicePolicyHandler(devices domain.DeviceRepository, resolver *PolicyResolver) *SetDevicePolicyHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &SetDevicePolicyHandler{devices: devices, resolver: resolver}
+}
+
+func (h *SetDevicePolicyHandler) Handle(ctx context.Context, cmd SetDevicePolicyCommand) (*DevicePolicyView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := domain.NewDevicePolicy(cmd.ConfidenceThreshold, cmd.ModelVersion, cmd.SessionExpirationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	dev.SetPolicyOverrides(policy)
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return nil, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	return resolveDevicePolicy(ctx, h.resolver, dev)
+}
+
+func findGroupByID(ctx context.Context, groups domain.DeviceGroupRepository, id string) (*domain.DeviceGroup, error) {
+	groupID, err := valueobjects.DeviceGroupIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrDeviceGroupNotFound
+	}
+	return groups.FindByID(ctx, groupID)
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// MachineIDPlaceholder is replaced with the machine ID in the device link template
+const MachineIDPlaceholder = "{machine_id}"
+
+// LabelRenderer is an output port for rendering the QR code of a device label
+type LabelRenderer interface {
+	// RenderQRCode returns a PNG of about size pixels square holding content
+	RenderQRCode(content string, size int) ([]byte, error)
+}
+
+// DeviceQRCode is the QR code printed on a device's label
+type DeviceQRCode struct {
+	MachineID string
+	Content   string // the deep link, or the machine ID when there is none
+	PNG       []byte
+}
+
+// DeviceQRCodeHandler renders the QR code field technicians stick on a
+// machine. It holds the machine's deep link when a link template is
+// configured and the bare machine ID otherwise.
+type DeviceQRCodeHandler struct {
+	devices      domain.DeviceRepository
+	renderer     LabelRenderer
+	linkTemplate string
+}
+
+func NewDeviceQRCodeHandler(devices domain.DeviceRepository, renderer LabelRenderer, linkTemplate string) *DeviceQRCodeHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if renderer == nil {
+		panic("nil LabelRenderer")
+	}
+	return &DeviceQRCodeHandler{devices: devices, renderer: renderer, linkTemplate: linkTemplate}
+}
+
+func (h *DeviceQRCodeHandler) Handle(ctx context.Context, id string, size int) (DeviceQRCode, error) {
+	dev, err := findDeviceByID(ctx, h.devices, id)
+	if err != nil {
+		return DeviceQRCode{}, err
+	}
+	if dev.IsDecommissioned() {
+		return DeviceQRCode{}, domain.ErrDeviceDecommissioned
+	}
+
+	content := dev.MachineID()
+	if h.linkTemplate != "" {
+		content = strings.ReplaceAll(h.linkTemplate, MachineIDPlaceholder, url.QueryEscape(dev.MachineID()))
+	}
+	png, err := h.renderer.RenderQRCode(content, size)
+	if err != nil {
+		return DeviceQRCode{}, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return DeviceQRCode{MachineID: dev.MachineID(), Content: content, PNG: png}, nil
+}
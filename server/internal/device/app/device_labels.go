@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// DeviceLabelsView is a read-only view of a device's tags and metadata
+type DeviceLabelsView struct {
+	DeviceID  string
+	MachineID string
+	Tags      []string
+	Metadata  map[string]string
+}
+
+// SetDeviceLabelsCommand replaces a device's tags and metadata
+type SetDeviceLabelsCommand struct {
+	DeviceID string
+	Tags     []string
+	Metadata map[string]string
+}
+
+// SetDeviceLabelsHandler tags a device and sets its metadata so operators can
+// slice the fleet by them
+type SetDeviceLabelsHandler struct {
+	devices domain.DeviceRepository
+}
+
+func NewSetDeviceLabelsHandler(devices domain.DeviceRepository) *SetDeviceLabelsHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &SetDeviceLabelsHandler{devices: devices}
+}
+
+func (h *SetDeviceLabelsHandler) Handle(ctx context.Context, cmd SetDeviceLabelsCommand) (DeviceLabelsView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return DeviceLabelsView{}, err
+	}
+	labels, err := domain.NewDeviceLabels(cmd.Tags, cmd.Metadata)
+	if err != nil {
+		return DeviceLabelsView{}, err
+	}
+
+	dev.SetLabels(labels)
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return DeviceLabelsView{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	return DeviceLabelsView{
+		DeviceID:  dev.ID().String(),
+		MachineID: dev.MachineID(),
+		Tags:      labels.Tags(),
+		Metadata:  labels.Metadata(),
+	}, nil
+}
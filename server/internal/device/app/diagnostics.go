@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DiagnosticsView is a run_diagnostics command and, once the device has
+// uploaded it, its self-test report
+type DiagnosticsView struct {
+	Command    DeviceCommandView
+	Report     *domain.DiagnosticChecks
+	ReportedAt *time.Time
+}
+
+func toDiagnosticsView(dev *domain.Device, command *domain.DeviceCommand, report *domain.DiagnosticReport) DiagnosticsView {
+	view := DiagnosticsView{Command: toDeviceCommandView(dev, command)}
+	if report != nil {
+		checks := report.Checks()
+		reportedAt := report.ReportedAt()
+		view.Report = &checks
+		view.ReportedAt = &reportedAt
+	}
+	return view
+}
+
+// UploadDiagnosticsCommand is the self-test report a device ran for a
+// run_diagnostics command
+type UploadDiagnosticsCommand struct {
+	MachineID string
+	DeviceKey string
+	CommandID string
+	Checks    domain.DiagnosticChecks
+}
+
+// UploadDiagnosticsHandler stores a device's self-test report and
+// acknowledges the run_diagnostics command it answers. The command succeeds
+// whether or not the checks passed: the device did run them.
+type UploadDiagnosticsHandler struct {
+	devices   domain.DeviceRepository
+	commands  domain.DeviceCommandRepository
+	reports   domain.DiagnosticReportRepository
+	publisher EventPublisher
+}
+
+func NewUploadDiagnosticsHandler(devices domain.DeviceRepository, commands domain.DeviceCommandRepository, reports domain.DiagnosticReportRepository, publisher EventPublisher) *UploadDiagnosticsHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	if reports == nil {
+		panic("nil DiagnosticReportRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &UploadDiagnosticsHandler{devices: devices, commands: commands, reports: reports, publisher: publisher}
+}
+
+func (h *UploadDiagnosticsHandler) Handle(ctx context.Context, cmd UploadDiagnosticsCommand) (DiagnosticsView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+	command, err := findDiagnosticsCommand(ctx, h.commands, dev, cmd.CommandID)
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+
+	now := time.Now()
+	report, err := domain.NewDiagnosticReport(command, cmd.Checks, now)
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+	if err := command.Acknowledge(domain.CommandStatusSucceeded, report.Checks().Summary(), now); err != nil {
+		return DiagnosticsView{}, err
+	}
+
+	if err := h.reports.Save(ctx, report); err != nil {
+		return DiagnosticsView{}, fmt.Errorf("failed to save diagnostic report: %w", err)
+	}
+	if err := h.commands.Save(ctx, command); err != nil {
+		return DiagnosticsView{}, fmt.Errorf("failed to save device command: %w", err)
+	}
+
+	for _, evt := range report.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+	for _, evt := range command.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toDiagnosticsView(dev, command, report), nil
+}
+
+// DiagnosticsQueryService reads diagnostics runs for remote troubleshooting
+type DiagnosticsQueryService struct {
+	devices  domain.DeviceRepository
+	commands domain.DeviceCommandRepository
+	reports  domain.DiagnosticReportRepository
+}
+
+func NewDiagnosticsQueryService(devices domain.DeviceRepository, commands domain.DeviceCommandRepository, reports domain.DiagnosticReportRepository) *DiagnosticsQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if commands == nil {
+		panic("nil DeviceCommandRepository")
+	}
+	if reports == nil {
+		panic("nil DiagnosticReportRepository")
+	}
+	return &DiagnosticsQueryService{devices: devices, commands: commands, reports: reports}
+}
+
+// Diagnostics returns a diagnostics run of the device, with its report once
+// the device has uploaded one
+func (s *DiagnosticsQueryService) Diagnostics(ctx context.Context, deviceID, commandID string) (DiagnosticsView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+	command, err := findDiagnosticsCommand(ctx, s.commands, dev, commandID)
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+
+	report, err := s.reports.FindByCommandID(ctx, command.ID())
+	if errors.Is(err, domain.ErrDiagnosticsNotFound) {
+		return toDiagnosticsView(dev, command, nil), nil
+	}
+	if err != nil {
+		return DiagnosticsView{}, err
+	}
+	return toDiagnosticsView(dev, command, report), nil
+}
+
+// findDiagnosticsCommand loads a run_diagnostics command of the device; any
+// other command, or another device's, is as good as unknown
+func findDiagnosticsCommand(ctx context.Context, commands domain.DeviceCommandRepository, dev *domain.Device, rawID string) (*domain.DeviceCommand, error) {
+	id, err := valueobjects.DeviceCommandIDFrom(rawID)
+	if err != nil {
+		return nil, domain.ErrDiagnosticsNotFound
+	}
+	command, err := commands.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrDeviceCommandNotFound) {
+		return nil, domain.ErrDiagnosticsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if command.DeviceID() != dev.ID() || command.Kind() != domain.CommandRunDiagnostics {
+		return nil, domain.ErrDiagnosticsNotFound
+	}
+	return command, nil
+}
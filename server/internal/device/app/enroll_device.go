@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// EventPublisher is an output port for publishing domain events
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.DomainEvent) error
+}
+
+// CreateEnrollmentTokenCommand is the operator's order to provision a machine
+type CreateEnrollmentTokenCommand struct {
+	MachineID string
+	Name      string
+	Location  string
+	CreatedBy string
+	// Override allows enrolling a decommissioned machine ID again
+	Override bool
+}
+
+// CreateEnrollmentTokenResult is the output DTO
+type CreateEnrollmentTokenResult struct {
+	MachineID string
+	Token     string // shown once; only its hash is kept
+	ExpiresAt time.Time
+}
+
+// CreateEnrollmentTokenHandler creates one-time enrollment tokens
+type CreateEnrollmentTokenHandler struct {
+	devices domain.DeviceRepository
+	tokens  domain.EnrollmentTokenRepository
+	ttl     time.Duration
+}
+
+func NewCreateEnrollmentTokenHandler(devices domain.DeviceRepository, tokens domain.EnrollmentTokenRepository, ttl time.Duration) *CreateEnrollmentTokenHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if tokens == nil {
+		panic("nil EnrollmentTokenRepository")
+	}
+	return &CreateEnrollmentTokenHandler{devices: devices, tokens: tokens, ttl: ttl}
+}
+
+func (h *CreateEnrollmentTokenHandler) Handle(ctx context.Context, cmd CreateEnrollmentTokenCommand) (CreateEnrollmentTokenResult, error) {
+	token, secret, err := domain.NewEnrollmentToken(cmd.MachineID, cmd.Name, cmd.Location, cmd.CreatedBy, cmd.Override, h.ttl)
+	if err != nil {
+		return CreateEnrollmentTokenResult{}, err
+	}
+
+	existing, err := h.devices.FindByMachineID(ctx, token.MachineID())
+	if err != nil && !errors.Is(err, domain.ErrDeviceNotFound) {
+		return CreateEnrollmentTokenResult{}, err
+	}
+	if existing != nil && existing.IsDecommissioned() && !cmd.Override {
+		return CreateEnrollmentTokenResult{}, domain.ErrDeviceDecommissioned
+	}
+
+	if err := h.tokens.Save(ctx, token); err != nil {
+		return CreateEnrollmentTokenResult{}, fmt.Errorf("failed to save enrollment token: %w", err)
+	}
+
+	return CreateEnrollmentTokenResult{
+		MachineID: token.MachineID(),
+		Token:     secret,
+		ExpiresAt: token.ExpiresAt(),
+	}, nil
+}
+
+// EnrollDeviceCommand is the input DTO for a device redeeming its enrollment token
+type EnrollDeviceCommand struct {
+	MachineID       string
+	EnrollmentToken string
+}
+
+// EnrollDeviceResult is the output DTO
+type EnrollDeviceResult struct {
+	DeviceID  string
+	MachineID string
+	Region    string
+	IsNew     bool
+	// Recommissioned is set when an override token put a decommissioned device back into service
+	Recommissioned bool
+	DeviceKey      string // authenticates the device's own requests; any earlier key stops working
+}
+
+// EnrollDeviceHandler redeems an enrollment token. A new machine ID is
+// registered; an enrolled one, e.g. after its hardware was replaced, gets a
+// new key, and a decommissioned one is only put back into service when the
+// operator allowed it when creating the token.
+type EnrollDeviceHandler struct {
+	devices   domain.DeviceRepository
+	tokens    domain.EnrollmentTokenRepository
+	publisher EventPublisher
+	region    string // region served by this deployment; new devices are tagged with it
+}
+
+func NewEnrollDeviceHandler(devices domain.DeviceRepository, tokens domain.EnrollmentTokenRepository, publisher EventPublisher, region string) *EnrollDeviceHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if tokens == nil {
+		panic("nil EnrollmentTokenRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &EnrollDeviceHandler{
+		devices:   devices,
+		tokens:    tokens,
+		publisher: publisher,
+		region:    region,
+	}
+}
+
+func (h *EnrollDeviceHandler) Handle(ctx context.Context, cmd EnrollDeviceCommand) (EnrollDeviceResult, error) {
+	token, err := h.tokens.FindByHash(ctx, domain.HashEnrollmentToken(cmd.EnrollmentToken))
+	if errors.Is(err, domain.ErrEnrollmentTokenNotFound) {
+		return EnrollDeviceResult{}, domain.ErrInvalidEnrollmentToken
+	}
+	if err != nil {
+		return EnrollDeviceResult{}, err
+	}
+	if err := token.Redeem(cmd.MachineID, time.Now()); err != nil {
+		return EnrollDeviceResult{}, err
+	}
+
+	existing, err := h.devices.FindByMachineID(ctx, token.MachineID())
+	if err != nil && !errors.Is(err, domain.ErrDeviceNotFound) {
+		return EnrollDeviceResult{}, err
+	}
+
+	var (
+		dev    *domain.Device
+		result EnrollDeviceResult
+	)
+	switch {
+	case existing == nil:
+		dev, err = domain.NewDevice(token.MachineID(), token.Name(), token.Location(), h.region)
+		if err != nil {
+			return EnrollDeviceResult{}, fmt.Errorf("invalid device: %w", err)
+		}
+		result.IsNew = true
+	case existing.IsDecommissioned():
+		if !token.Override() {
+			return EnrollDeviceResult{}, domain.ErrDeviceDecommissioned
+		}
+		dev = existing
+		if err := dev.Recommission(token.CreatedBy(), token.Name(), token.Location()); err != nil {
+			return EnrollDeviceResult{}, err
+		}
+		result.Recommissioned = true
+	default:
+		dev = existing
+	}
+
+	key := dev.IssueKey()
+
+	// The token is used up first, so a token redeemed twice at once enrolls once
+	if err := h.tokens.Save(ctx, token); err != nil {
+		if errors.Is(err, domain.ErrEnrollmentTokenRedeemed) {
+			return EnrollDeviceResult{}, err
+		}
+		return EnrollDeviceResult{}, fmt.Errorf("failed to save enrollment token: %w", err)
+	}
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return EnrollDeviceResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	result.DeviceID = dev.ID().String()
+	result.MachineID = dev.MachineID()
+	result.Region = dev.Region()
+	result.DeviceKey = key
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// FleetHealth is the state of the whole fleet at one moment, for the ops dashboard
+type FleetHealth struct {
+	DevicesByStatus map[string]int
+	// Offline lists the machines not heard from since OfflineSince, longest
+	// silent first; decommissioned devices are left out
+	Offline      []string
+	OfflineSince time.Time
+	// StaleModels lists the machines running another detection model than
+	// their policy pins; devices without a pinned model are never stale
+	StaleModels []string
+	// OpenSessions counts the active sessions, WeightMismatches those whose
+	// last detection did not match the measured weight
+	OpenSessions     int
+	WeightMismatches int
+}
+
+// WeightMismatchRate is the share of open sessions with a weight mismatch,
+// zero when no session is open
+func (f FleetHealth) WeightMismatchRate() float64 {
+	if f.OpenSessions == 0 {
+		return 0
+	}
+	return float64(f.WeightMismatches) / float64(f.OpenSessions)
+}
+
+// FleetHealthReader is an output port for reading fleet health in one go
+type FleetHealthReader interface {
+	FleetHealth(ctx context.Context, offlineSince time.Time) (FleetHealth, error)
+}
+
+// FleetHealthQueryService summarizes the health of the fleet
+type FleetHealthQueryService struct {
+	reader       FleetHealthReader
+	offlineAfter time.Duration
+}
+
+func NewFleetHealthQueryService(reader FleetHealthReader, offlineAfter time.Duration) *FleetHealthQueryService {
+	if reader == nil {
+		panic("nil FleetHealthReader")
+	}
+	return &FleetHealthQueryService{reader: reader, offlineAfter: offlineAfter}
+}
+
+// OfflineAfter is how long a device may go without a heartbeat before it
+// counts as offline when the caller does not say
+func (s *FleetHealthQueryService) OfflineAfter() time.Duration { return s.offlineAfter }
+
+// Summary returns the fleet health, counting devices silent for longer than
+// offlineAfter as offline; zero uses the default
+func (s *FleetHealthQueryService) Summary(ctx context.Context, offlineAfter time.Duration) (FleetHealth, error) {
+	if offlineAfter <= 0 {
+		offlineAfter = s.offlineAfter
+	}
+	return s.reader.FleetHealth(ctx, time.Now().UTC().Add(-offlineAfter))
+}
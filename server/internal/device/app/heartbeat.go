@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RecordHeartbeatCommand is the input DTO for a device heartbeat
+type RecordHeartbeatCommand struct {
+	MachineID       string
+	DeviceKey       string
+	FirmwareVersion string // empty keeps the version last reported
+	AppVersion      string // empty keeps the version last reported
+	ModelVersion    string // empty keeps the version last reported
+	Updates         []UpdateReport
+}
+
+// UpdateReport is how far the device got with a release offered by a rollout
+type UpdateReport struct {
+	RolloutID string
+	Status    domain.UpdateStatus
+	Detail    string
+}
+
+// RecordHeartbeatResult is the output DTO
+type RecordHeartbeatResult struct {
+	MachineID  string
+	Status     string
+	LastSeenAt time.Time
+	Updates    []OfferedUpdate // the releases the device should install
+}
+
+// RecordHeartbeatHandler marks a device as alive, records the update status
+// it reports and hands it the releases it is offered. The device
+// authenticates with its own key, so one machine cannot keep another
+// looking alive.
+type RecordHeartbeatHandler struct {
+	devices  domain.DeviceRepository
+	rollouts domain.RolloutRepository
+	releases domain.ReleaseRepository
+}
+
+func NewRecordHeartbeatHandler(devices domain.DeviceRepository, rollouts domain.RolloutRepository, releases domain.ReleaseRepository) *RecordHeartbeatHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if rollouts == nil {
+		panic("nil RolloutRepository")
+	}
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	return &RecordHeartbeatHandler{devices: devices, rollouts: rollouts, releases: releases}
+}
+
+func (h *RecordHeartbeatHandler) Handle(ctx context.Context, cmd RecordHeartbeatCommand) (RecordHeartbeatResult, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return RecordHeartbeatResult{}, err
+	}
+
+	now := time.Now()
+	updates := make([]domain.DeviceUpdate, 0, len(cmd.Updates))
+	for _, report := range cmd.Updates {
+		rollout, err := findRolloutByID(ctx, h.rollouts, report.RolloutID)
+		if err != nil {
+			return RecordHeartbeatResult{}, err
+		}
+		update, err := domain.NewDeviceUpdate(rollout.ID(), dev.ID(), report.Status, report.Detail, now)
+		if err != nil {
+			return RecordHeartbeatResult{}, err
+		}
+		updates = append(updates, update)
+	}
+
+	if err := dev.RecordHeartbeat(now, cmd.FirmwareVersion, cmd.AppVersion, cmd.ModelVersion); err != nil {
+		return RecordHeartbeatResult{}, err
+	}
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return RecordHeartbeatResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+	for _, update := range updates {
+		if err := h.rollouts.SaveDeviceUpdate(ctx, update); err != nil {
+			return RecordHeartbeatResult{}, fmt.Errorf("failed to save update status: %w", err)
+		}
+	}
+
+	offers, err := offeredUpdates(ctx, h.rollouts, h.releases, dev)
+	if err != nil {
+		return RecordHeartbeatResult{}, err
+	}
+
+	return RecordHeartbeatResult{
+		MachineID:  dev.MachineID(),
+		Status:     string(dev.Status()),
+		LastSeenAt: *dev.Liveness().LastSeenAt,
+		Updates:    offers,
+	}, nil
+}
+
+// IssueDeviceKeyResult is the output DTO of issuing a device key
+type IssueDeviceKeyResult struct {
+	DeviceID  string
+	MachineID string
+	DeviceKey string
+}
+
+// IssueDeviceKeyHandler gives a device a new key, for devices registered
+// before keys were issued or whose key was lost or leaked
+type IssueDeviceKeyHandler struct {
+	devices domain.DeviceRepository
+}
+
+func NewIssueDeviceKeyHandler(devices domain.DeviceRepository) *IssueDeviceKeyHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &IssueDeviceKeyHandler{devices: devices}
+}
+
+func (h *IssueDeviceKeyHandler) Handle(ctx context.Context, deviceID string) (IssueDeviceKeyResult, error) {
+	id, err := valueobjects.DeviceIDFrom(deviceID)
+	if err != nil {
+		return IssueDeviceKeyResult{}, domain.ErrDeviceNotFound
+	}
+	dev, err := h.devices.FindByID(ctx, id)
+	if err != nil {
+		return IssueDeviceKeyResult{}, err
+	}
+	if dev.IsDecommissioned() {
+		return IssueDeviceKeyResult{}, domain.ErrDeviceDecommissioned
+	}
+
+	key := dev.IssueKey()
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return IssueDeviceKeyResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	return IssueDeviceKeyResult{DeviceID: dev.ID().String(), MachineID: dev.MachineID(), DeviceKey: key}, nil
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// StartTokenIssuer is an output port for signing session-start tokens
+type StartTokenIssuer interface {
+	Issue(machineID string) (token string, expiresAt time.Time)
+}
+
+// IssueStartTokenCommand is the input DTO
+type IssueStartTokenCommand struct {
+	MachineID string
+	DeviceKey string
+}
+
+// IssueStartTokenResult is the output DTO
+type IssueStartTokenResult struct {
+	MachineID string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// IssueStartTokenHandler issues the signed token a device embeds in its QR
+// code. The device authenticates with its own key, so no one else can mint
+// tokens that start sessions on it.
+type IssueStartTokenHandler struct {
+	devices domain.DeviceRepository
+	issuer  StartTokenIssuer
+}
+
+func NewIssueStartTokenHandler(devices domain.DeviceRepository, issuer StartTokenIssuer) *IssueStartTokenHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if issuer == nil {
+		panic("nil StartTokenIssuer")
+	}
+	return &IssueStartTokenHandler{
+		devices: devices,
+		issuer:  issuer,
+	}
+}
+
+func (h *IssueStartTokenHandler) Handle(ctx context.Context, cmd IssueStartTokenCommand) (IssueStartTokenResult, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return IssueStartTokenResult{}, err
+	}
+	if dev.IsDecommissioned() {
+		return IssueStartTokenResult{}, domain.ErrDeviceDecommissioned
+	}
+	if dev.IsBlocked() {
+		return IssueStartTokenResult{}, domain.ErrDeviceBlocked
+	}
+	if dev.IsInMaintenance() {
+		return IssueStartTokenResult{}, domain.ErrDeviceInMaintenance
+	}
+	if dev.IsRestocking() {
+		return IssueStartTokenResult{}, domain.ErrRestockSessionOpen
+	}
+	if !dev.IsActive() {
+		return IssueStartTokenResult{}, domain.ErrDeviceInactive
+	}
+
+	token, expiresAt := h.issuer.Issue(dev.MachineID())
+
+	return IssueStartTokenResult{
+		MachineID: dev.MachineID(),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// EnterMaintenanceCommand is field staff's notice that they are working on a device
+type EnterMaintenanceCommand struct {
+	MachineID string
+	By        string
+	Reason    string
+}
+
+// ExitMaintenanceCommand puts a device under maintenance back into service
+type ExitMaintenanceCommand struct {
+	MachineID string
+	By        string
+}
+
+// MaintenanceResult is the output DTO of both maintenance use cases
+type MaintenanceResult struct {
+	DeviceID  string
+	MachineID string
+	Status    domain.DeviceStatus
+	Since     *time.Time // nil once the device left maintenance
+}
+
+// EnterMaintenanceHandler takes an active device out of sale while it is
+// serviced. Sessions already open on it run to completion.
+type EnterMaintenanceHandler struct {
+	devices   domain.DeviceRepository
+	publisher EventPublisher
+}
+
+func NewEnterMaintenanceHandler(devices domain.DeviceRepository, publisher EventPublisher) *EnterMaintenanceHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &EnterMaintenanceHandler{devices: devices, publisher: publisher}
+}
+
+func (h *EnterMaintenanceHandler) Handle(ctx context.Context, cmd EnterMaintenanceCommand) (MaintenanceResult, error) {
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return MaintenanceResult{}, err
+	}
+	if err := dev.EnterMaintenance(cmd.By, cmd.Reason, time.Now()); err != nil {
+		return MaintenanceResult{}, err
+	}
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return MaintenanceResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return MaintenanceResult{
+		DeviceID:  dev.ID().String(),
+		MachineID: dev.MachineID(),
+		Status:    dev.Status(),
+		Since:     &dev.Maintenance().Since,
+	}, nil
+}
+
+// ExitMaintenanceHandler puts a device back into service after maintenance
+type ExitMaintenanceHandler struct {
+	devices   domain.DeviceRepository
+	publisher EventPublisher
+}
+
+func NewExitMaintenanceHandler(devices domain.DeviceRepository, publisher EventPublisher) *ExitMaintenanceHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ExitMaintenanceHandler{devices: devices, publisher: publisher}
+}
+
+func (h *ExitMaintenanceHandler) Handle(ctx context.Context, cmd ExitMaintenanceCommand) (MaintenanceResult, error) {
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return MaintenanceResult{}, err
+	}
+	if err := dev.ExitMaintenance(cmd.By); err != nil {
+		return MaintenanceResult{}, err
+	}
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return MaintenanceResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return MaintenanceResult{
+		DeviceID:  dev.ID().String(),
+		MachineID: dev.MachineID(),
+		Status:    dev.Status(),
+	}, nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// PolicyResolver works out the policy that applies to a device: what is set
+// on the device itself, else what is set on its group, else the deployment's
+// defaults, which the callers hold
+type PolicyResolver struct {
+	groups     domain.DeviceGroupRepository
+	planograms domain.PlanogramRepository
+}
+
+func NewPolicyResolver(groups domain.DeviceGroupRepository, planograms domain.PlanogramRepository) *PolicyResolver {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if planograms == nil {
+		panic("nil PlanogramRepository")
+	}
+	return &PolicyResolver{groups: groups, planograms: planograms}
+}
+
+// Group returns the device's group, or nil when it is in none
+func (r *PolicyResolver) Group(ctx context.Context, dev *domain.Device) (*domain.DeviceGroup, error) {
+	if dev.GroupID() == nil {
+		return nil, nil
+	}
+	group, err := r.groups.FindByID(ctx, *dev.GroupID())
+	if errors.Is(err, domain.ErrDeviceGroupNotFound) {
+		return nil, nil
+	}
+	return group, err
+}
+
+// Policy resolves the device's detection threshold and model version
+func (r *PolicyResolver) Policy(ctx context.Context, dev *domain.Device) (domain.ResolvedPolicy, error) {
+	group, err := r.Group(ctx, dev)
+	if err != nil {
+		return domain.ResolvedPolicy{}, err
+	}
+	return dev.ResolvePolicy(group), nil
+}
+
+// ConfidenceThreshold returns the device's minimum detection confidence, or
+// fallback when neither the device nor its group sets one
+func (r *PolicyResolver) ConfidenceThreshold(ctx context.Context, dev *domain.Device, fallback float64) (float64, error) {
+	policy, err := r.Policy(ctx, dev)
+	if err != nil {
+		return 0, err
+	}
+	if policy.ConfidenceThreshold == nil {
+		return fallback, nil
+	}
+	return *policy.ConfidenceThreshold, nil
+}
+
+// Planogram returns the layout the device is stocked with: its own, else its
+// group's. It fails with ErrPlanogramNotFound when there is neither.
+func (r *PolicyResolver) Planogram(ctx context.Context, dev *domain.Device) (*domain.Planogram, domain.PolicySource, error) {
+	planogram, err := r.planograms.FindByDeviceID(ctx, dev.ID())
+	if err == nil {
+		return planogram, domain.PolicySourceDevice, nil
+	}
+	if !errors.Is(err, domain.ErrPlanogramNotFound) {
+		return nil, "", err
+	}
+
+	group, err := r.Group(ctx, dev)
+	if err != nil {
+		return nil, "", err
+	}
+	if group != nil {
+		if planogram := group.PlanogramFor(dev.ID()); planogram != nil {
+			return planogram, domain.PolicySourceGroup, nil
+		}
+	}
+	return nil, "", domain.ErrPlanogramNotFound
+}
@@ -2,28 +2,1134 @@ package app
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/policy"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
+// DeviceView is a read-only view of a device and whether it is still heard from
+type DeviceView struct {
+	ID                string
+	MachineID         string
+	Name              string
+	Location          string
+	Region            string
+	Status            string
+	LastSeenAt        *time.Time
+	OfflineSince      *time.Time // when the offline sweep found the device silent
+	FirmwareVersion   string
+	AppVersion        string
+	ModelVersion      string
+	Stale             bool // no heartbeat within the staleness window; never set once decommissioned
+	DecommissionedAt  *time.Time
+	DecommissionedBy  string
+	MaintenanceSince  *time.Time
+	MaintenanceBy     string
+	MaintenanceReason string
+	GroupID           string // empty when the device is in no group
+	RestockSessionID  string // the operator's open restock session, if any
+	Tags              []string
+	Metadata          map[string]string
+}
+
 // DeviceQueryService provides read-only access to devices
 type DeviceQueryService struct {
-	repo domain.DeviceRepository
+	repo       domain.DeviceRepository
+	staleAfter time.Duration
 }
 
-func NewDeviceQueryService(repo domain.DeviceRepository) *DeviceQueryService {
-	return &DeviceQueryService{repo: repo}
+func NewDeviceQueryService(repo domain.DeviceRepository, staleAfter time.Duration) *DeviceQueryService {
+	if repo == nil {
+		panic("nil DeviceRepository")
+	}
+	return &DeviceQueryService{repo: repo, staleAfter: staleAfter}
 }
 
-func (s *DeviceQueryService) FindByID(ctx context.Context, id string) (*domain.Device, error) {
+// StaleAfter is how long a device may go without a heartbeat before it is stale
+func (s *DeviceQueryService) StaleAfter() time.Duration { return s.staleAfter }
+
+func (s *DeviceQueryService) FindByID(ctx context.Context, id string) (*DeviceView, error) {
 	deviceID, err := valueobjects.DeviceIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrDeviceNotFound
+	}
+	dev, err := s.repo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	view := s.toDeviceView(dev, time.Now())
+	return &view, nil
+}
+
+// DeviceFilter narrows a device listing; zero fields don't filter
+type DeviceFilter struct {
+	Status     domain.DeviceStatus
+	Location   string            // case-insensitive part of the location
+	Stale      *bool             // whether the device is stale
+	SeenAfter  *time.Time        // last heartbeat after this time
+	SeenBefore *time.Time        // no heartbeat since this time, never-seen devices included
+	Tags       []string          // devices carrying every one of these tags
+	Metadata   map[string]string // devices with every one of these metadata values
+}
+
+func (f DeviceFilter) matches(v DeviceView) bool {
+	switch {
+	case f.Status != "" && v.Status != string(f.Status):
+		return false
+	case f.Location != "" && !strings.Contains(strings.ToLower(v.Location), strings.ToLower(f.Location)):
+		return false
+	case f.Stale != nil && v.Stale != *f.Stale:
+		return false
+	case f.SeenAfter != nil && (v.LastSeenAt == nil || !v.LastSeenAt.After(*f.SeenAfter)):
+		return false
+	case f.SeenBefore != nil && v.LastSeenAt != nil && !v.LastSeenAt.Before(*f.SeenBefore):
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(v.Tags, domain.NormalizeTag(tag)) {
+			return false
+		}
+	}
+	for key, value := range f.Metadata {
+		if actual, ok := v.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// DevicePage is one page of a device listing
+type DevicePage struct {
+	Devices []DeviceView
+	Total   int // devices matching the filter across all pages
+}
+
+// List returns the devices matching the filter by machine ID, skipping
+// offset of them and returning at most limit
+func (s *DeviceQueryService) List(ctx context.Context, filter DeviceFilter, limit, offset int) (DevicePage, error) {
+	devices, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return DevicePage{}, err
+	}
+
+	now := time.Now()
+	page := DevicePage{Devices: []DeviceView{}}
+	for _, dev := range devices {
+		view := s.toDeviceView(dev, now)
+		if !filter.matches(view) {
+			continue
+		}
+		if page.Total >= offset && len(page.Devices) < limit {
+			page.Devices = append(page.Devices, view)
+		}
+		page.Total++
+	}
+	return page, nil
+}
+
+func (s *DeviceQueryService) toDeviceView(dev *domain.Device, now time.Time) DeviceView {
+	liveness := dev.Liveness()
+	view := DeviceView{
+		ID:              dev.ID().String(),
+		MachineID:       dev.MachineID(),
+		Name:            dev.Name(),
+		Location:        dev.Location(),
+		Region:          dev.Region(),
+		Status:          string(dev.Status()),
+		LastSeenAt:      liveness.LastSeenAt,
+		OfflineSince:    liveness.OfflineSince,
+		FirmwareVersion: liveness.FirmwareVersion,
+		AppVersion:      liveness.AppVersion,
+		ModelVersion:    liveness.ModelVersion,
+		Stale:           !dev.IsDecommissioned() && liveness.IsStale(now, s.staleAfter),
+	}
+	if dc := dev.Decommissioned(); dc != nil {
+		view.DecommissionedAt, view.DecommissionedBy = &dc.At, dc.By
+	}
+	if m := dev.Maintenance(); m != nil {
+		view.MaintenanceSince, view.MaintenanceBy, view.MaintenanceReason = &m.Since, m.By, m.Reason
+	}
+	if groupID := dev.GroupID(); groupID != nil {
+		view.GroupID = groupID.String()
+	}
+	if rs := dev.RestockSessionID(); rs != nil {
+		view.RestockSessionID = rs.String()
+	}
+	view.Tags, view.Metadata = dev.Labels().Tags(), dev.Labels().Metadata()
+	return view
+}
+
+// StockEstimateView is a read-only view of a device's estimated stock
+type StockEstimateView struct {
+	MachineID    string
+	ModelVersion string
+	ObservedAt   *time.Time
+	LowThreshold int
+	Levels       []StockLevelView
+}
+
+// StockLevelView is the estimated quantity of one SKU on a device
+type StockLevelView struct {
+	SKUCode   string
+	Estimated int
+	Low       bool
+}
+
+// StockQueryService provides read-only access to vision-based stock estimates
+type StockQueryService struct {
+	devices      domain.DeviceRepository
+	estimates    domain.StockEstimateRepository
+	lowThreshold int
+}
+
+func NewStockQueryService(devices domain.DeviceRepository, estimates domain.StockEstimateRepository, lowThreshold int) *StockQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if estimates == nil {
+		panic("nil StockEstimateRepository")
+	}
+	return &StockQueryService{devices: devices, estimates: estimates, lowThreshold: lowThreshold}
+}
+
+// FindByMachineID returns the device's latest estimate; a device without
+// snapshots yet has an empty one
+func (s *StockQueryService) FindByMachineID(ctx context.Context, machineID string) (*StockEstimateView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := s.estimates.FindByDeviceID(ctx, dev.ID())
+	if errors.Is(err, domain.ErrStockEstimateNotFound) {
+		estimate = domain.NewStockEstimate(dev.ID())
+	} else if err != nil {
+		return nil, err
+	}
+
+	view := toStockEstimateView(dev, estimate, s.lowThreshold)
+	return &view, nil
+}
+
+func toStockEstimateView(dev *domain.Device, estimate *domain.StockEstimate, lowThreshold int) StockEstimateView {
+	view := StockEstimateView{
+		MachineID:    dev.MachineID(),
+		ModelVersion: estimate.ModelVersion(),
+		LowThreshold: lowThreshold,
+		Levels:       []StockLevelView{},
+	}
+	if observedAt := estimate.ObservedAt(); !observedAt.IsZero() {
+		view.ObservedAt = &observedAt
+	}
+
+	levels := estimate.Levels()
+	for _, code := range estimate.SKUCodes() {
+		view.Levels = append(view.Levels, StockLevelView{
+			SKUCode:   code,
+			Estimated: levels[code],
+			Low:       levels[code] <= lowThreshold,
+		})
+	}
+	return view
+}
+
+// ExcursionView is a read-only view of a temperature excursion
+type ExcursionView struct {
+	ID           string
+	StartedAt    time.Time
+	DetectedAt   time.Time
+	LimitCelsius float64
+	PeakCelsius  float64
+	ClearedAt    *time.Time
+	ClearedBy    string
+	Note         string
+}
+
+// ExcursionQueryService provides read-only access to temperature excursions
+type ExcursionQueryService struct {
+	devices    domain.DeviceRepository
+	excursions domain.ExcursionRepository
+}
+
+func NewExcursionQueryService(devices domain.DeviceRepository, excursions domain.ExcursionRepository) *ExcursionQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if excursions == nil {
+		panic("nil ExcursionRepository")
+	}
+	return &ExcursionQueryService{devices: devices, excursions: excursions}
+}
+
+// FindByMachineID lists a device's excursions, newest first
+func (s *ExcursionQueryService) FindByMachineID(ctx context.Context, machineID string) ([]ExcursionView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	excursions, err := s.excursions.FindByDeviceID(ctx, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ExcursionView, 0, len(excursions))
+	for _, e := range excursions {
+		views = append(views, ExcursionView{
+			ID:           e.ID().String(),
+			StartedAt:    e.StartedAt(),
+			DetectedAt:   e.DetectedAt(),
+			LimitCelsius: e.LimitCelsius(),
+			PeakCelsius:  e.PeakCelsius(),
+			ClearedAt:    e.ClearedAt(),
+			ClearedBy:    e.ClearedBy(),
+			Note:         e.Note(),
+		})
+	}
+	return views, nil
+}
+
+// IncidentView is a read-only view of a security incident
+type IncidentView struct {
+	ID           string
+	Kind         string
+	DoorOpenedAt time.Time
+	DetectedAt   time.Time
+}
+
+// IncidentQueryService provides read-only access to security incidents
+type IncidentQueryService struct {
+	devices   domain.DeviceRepository
+	incidents domain.IncidentRepository
+}
+
+func NewIncidentQueryService(devices domain.DeviceRepository, incidents domain.IncidentRepository) *IncidentQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if incidents == nil {
+		panic("nil IncidentRepository")
+	}
+	return &IncidentQueryService{devices: devices, incidents: incidents}
+}
+
+// FindByMachineID lists a device's security incidents, newest first
+func (s *IncidentQueryService) FindByMachineID(ctx context.Context, machineID string) ([]IncidentView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	incidents, err := s.incidents.FindByDeviceID(ctx, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]IncidentView, 0, len(incidents))
+	for _, i := range incidents {
+		views = append(views, IncidentView{
+			ID:           i.ID().String(),
+			Kind:         string(i.Kind()),
+			DoorOpenedAt: i.DoorOpenedAt(),
+			DetectedAt:   i.DetectedAt(),
+		})
+	}
+	return views, nil
+}
+
+// PlanogramView is a read-only view of a device's planogram
+type PlanogramView struct {
+	MachineID string
+	Version   int
+	Source    domain.PolicySource // device, or group when the device follows its group's layout
+	Facings   []domain.PlanogramFacing
+	UpdatedAt time.Time
+}
+
+// ComplianceReportView is a read-only view of a restock visit's compliance check
+type ComplianceReportView struct {
+	ID               string
+	PlanogramVersion int
+	VisitedBy        string
+	Score            int
+	Compliant        bool
+	Deviations       []domain.Deviation
+	CheckedAt        time.Time
+}
+
+// PlanogramQueryService provides read-only access to planograms and their compliance reports
+type PlanogramQueryService struct {
+	devices  domain.DeviceRepository
+	resolver *PolicyResolver
+	reports  domain.ComplianceReportRepository
+}
+
+func NewPlanogramQueryService(devices domain.DeviceRepository, resolver *PolicyResolver, reports domain.ComplianceReportRepository) *PlanogramQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	if reports == nil {
+		panic("nil ComplianceReportRepository")
+	}
+	return &PlanogramQueryService{devices: devices, resolver: resolver, reports: reports}
+}
+
+// FindByMachineID returns the device's current planogram, its own or its group's
+func (s *PlanogramQueryService) FindByMachineID(ctx context.Context, machineID string) (*PlanogramView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	planogram, source, err := s.resolver.Planogram(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+
+	view := toPlanogramView(dev, planogram, source)
+	return &view, nil
+}
+
+// ReportsByMachineID lists the device's compliance reports, newest first
+func (s *PlanogramQueryService) ReportsByMachineID(ctx context.Context, machineID string) ([]ComplianceReportView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reports.FindByDeviceID(ctx, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ComplianceReportView, 0, len(reports))
+	for _, r := range reports {
+		views = append(views, toComplianceReportView(r))
+	}
+	return views, nil
+}
+
+func toPlanogramView(dev *domain.Device, planogram *domain.Planogram, source domain.PolicySource) PlanogramView {
+	return PlanogramView{
+		MachineID: dev.MachineID(),
+		Version:   planogram.Version(),
+		Source:    source,
+		Facings:   planogram.Facings(),
+		UpdatedAt: planogram.UpdatedAt(),
+	}
+}
+
+func toComplianceReportView(r *domain.ComplianceReport) ComplianceReportView {
+	return ComplianceReportView{
+		ID:               r.ID().String(),
+		PlanogramVersion: r.PlanogramVersion(),
+		VisitedBy:        r.VisitedBy(),
+		Score:            r.Score(),
+		Compliant:        r.IsCompliant(),
+		Deviations:       r.Deviations(),
+		CheckedAt:        r.CheckedAt(),
+	}
+}
+
+// ExpectedModelView is the detection model a device should run
+type ExpectedModelView struct {
+	MachineID string
+	Version   string              // empty when neither the device nor its group pins one
+	Source    domain.PolicySource // device, group, or default when nothing is pinned
+}
+
+// ExpectedModelQueryService tells devices which detection model their policy
+// pins, so they can check they run a compatible one
+type ExpectedModelQueryService struct {
+	devices  domain.DeviceRepository
+	resolver *PolicyResolver
+}
+
+func NewExpectedModelQueryService(devices domain.DeviceRepository, resolver *PolicyResolver) *ExpectedModelQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &ExpectedModelQueryService{devices: devices, resolver: resolver}
+}
+
+func (s *ExpectedModelQueryService) FindByMachineID(ctx context.Context, machineID string) (*ExpectedModelView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.resolver.Policy(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpectedModelView{
+		MachineID: dev.MachineID(),
+		Version:   policy.ModelVersion,
+		Source:    policy.ModelVersionSource,
+	}, nil
+}
+
+// SalesHoursView is a read-only view of a device's sales hours and whether it sells right now
+type SalesHoursView struct {
+	MachineID    string
+	Timezone     string
+	Configured   bool // false for devices without sales hours, which are always open
+	OpeningHours []domain.SalesWindow
+	Blackouts    []domain.SalesBlackout
+	UpdatedAt    *time.Time
+	Status       domain.SalesStatus
+}
+
+// DeviceDetailView is a read-only view of a device for the customer app and operators
+type DeviceDetailView struct {
+	ID         string
+	MachineID  string
+	Name       string
+	Location   string
+	Region     string
+	Status     string
+	SalesHours SalesHoursView
+}
+
+// SalesHoursQueryService provides read-only access to devices and their sales hours
+type SalesHoursQueryService struct {
+	devices domain.DeviceRepository
+	hours   domain.SalesHoursRepository
+}
+
+func NewSalesHoursQueryService(devices domain.DeviceRepository, hours domain.SalesHoursRepository) *SalesHoursQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if hours == nil {
+		panic("nil SalesHoursRepository")
+	}
+	return &SalesHoursQueryService{devices: devices, hours: hours}
+}
+
+// FindByMachineID returns the device's sales hours as of now
+func (s *SalesHoursQueryService) FindByMachineID(ctx context.Context, machineID string) (*SalesHoursView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.salesHoursView(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// DetailsByMachineID returns the device with its sales hours
+func (s *SalesHoursQueryService) DetailsByMachineID(ctx context.Context, machineID string) (*DeviceDetailView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := s.salesHoursView(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceDetailView{
+		ID:         dev.ID().String(),
+		MachineID:  dev.MachineID(),
+		Name:       dev.Name(),
+		Location:   dev.Location(),
+		Region:     dev.Region(),
+		Status:     string(dev.Status()),
+		SalesHours: hours,
+	}, nil
+}
+
+func (s *SalesHoursQueryService) salesHoursView(ctx context.Context, dev *domain.Device) (SalesHoursView, error) {
+	hours, err := s.hours.FindByDeviceID(ctx, dev.ID())
+	switch {
+	case errors.Is(err, domain.ErrSalesHoursNotFound):
+		return toSalesHoursView(dev, domain.AlwaysOpen(dev.ID()), false, time.Now()), nil
+	case err != nil:
+		return SalesHoursView{}, err
+	}
+	return toSalesHoursView(dev, hours, true, time.Now()), nil
+}
+
+func toSalesHoursView(dev *domain.Device, hours *domain.SalesHours, configured bool, at time.Time) SalesHoursView {
+	view := SalesHoursView{
+		MachineID:    dev.MachineID(),
+		Timezone:     hours.Timezone(),
+		Configured:   configured,
+		OpeningHours: hours.OpeningHours(),
+		Blackouts:    hours.Blackouts(),
+		Status:       hours.StatusAt(at),
+	}
+	if configured {
+		updatedAt := hours.UpdatedAt()
+		view.UpdatedAt = &updatedAt
+	}
+	return view
+}
+
+// BatchView is a read-only view of a stock batch and what is estimated to be left of it
+type BatchView struct {
+	ID              string
+	MachineID       string
+	SKUCode         string
+	Quantity        int
+	Remaining       int
+	ExpiresOn       time.Time
+	DaysLeft        int
+	Expired         bool
+	MarkdownPercent int
+	RestockedBy     string
+	RestockedAt     time.Time
+	WrittenOffAt    *time.Time
+	WrittenOffBy    string
+	WastedUnits     int
+}
+
+// WasteLineView totals the units of one SKU written off
+type WasteLineView struct {
+	SKUCode string
+	Batches int
+	Units   int
+}
+
+// WasteReportView lists the batches written off in [From, To)
+type WasteReportView struct {
+	MachineID  string // empty for the whole fleet
+	From       time.Time
+	To         time.Time
+	TotalUnits int
+	Lines      []WasteLineView
+	Batches    []BatchView
+}
+
+// ExpiryQueryService provides read-only access to stock batches, their
+// markdowns and the waste written off
+type ExpiryQueryService struct {
+	devices   domain.DeviceRepository
+	batches   domain.StockBatchRepository
+	hours     domain.SalesHoursRepository
+	sales     SalesReader
+	markdowns policy.MarkdownPolicy
+}
+
+func NewExpiryQueryService(
+	devices domain.DeviceRepository,
+	batches domain.StockBatchRepository,
+	hours domain.SalesHoursRepository,
+	sales SalesReader,
+	markdowns policy.MarkdownPolicy,
+) *ExpiryQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if batches == nil {
+		panic("nil StockBatchRepository")
+	}
+	if hours == nil {
+		panic("nil SalesHoursRepository")
+	}
+	if sales == nil {
+		panic("nil SalesReader")
+	}
+	return &ExpiryQueryService{
+		devices:   devices,
+		batches:   batches,
+		hours:     hours,
+		sales:     sales,
+		markdowns: markdowns,
+	}
+}
+
+// BatchesByMachineID lists the device's open batches, earliest expiry first per SKU
+func (s *ExpiryQueryService) BatchesByMachineID(ctx context.Context, machineID string) ([]BatchView, error) {
+	dev, err := s.devices.FindByMachineID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := batchLevels(ctx, s.batches, s.sales, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+	today, err := deviceToday(ctx, s.hours, dev.ID(), time.Now())
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.FindByID(ctx, deviceID)
+
+	views := make([]BatchView, 0, len(levels))
+	for _, l := range levels {
+		views = append(views, toBatchView(dev, l, today, s.markdowns))
+	}
+	return views, nil
 }
 
-func (s *DeviceQueryService) FindByMachineID(ctx context.Context, machineID string) (*domain.Device, error) {
-	return s.repo.FindByMachineID(ctx, machineID)
+// ExpiringBatches lists the open batches with units left that expire within
+// the given number of days, expired ones included, on one device or across
+// the fleet when machineID is empty. Batches expiring first come first, so
+// operators can mark them down or take them out in time.
+func (s *ExpiryQueryService) ExpiringBatches(ctx context.Context, machineID string, withinDays int) ([]BatchView, error) {
+	byDevice := make(map[valueobjects.DeviceID][]*domain.StockBatch)
+	devices := make(map[valueobjects.DeviceID]*domain.Device)
+	if machineID != "" {
+		dev, err := s.devices.FindByMachineID(ctx, machineID)
+		if err != nil {
+			return nil, err
+		}
+		open, err := s.batches.FindOpenByDeviceID(ctx, dev.ID())
+		if err != nil {
+			return nil, err
+		}
+		devices[dev.ID()] = dev
+		byDevice[dev.ID()] = open
+	} else {
+		open, err := s.batches.FindOpen(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range open {
+			byDevice[b.DeviceID()] = append(byDevice[b.DeviceID()], b)
+		}
+	}
+
+	now := time.Now()
+	views := []BatchView{}
+	for deviceID, open := range byDevice {
+		dev, ok := devices[deviceID]
+		if !ok {
+			d, err := s.devices.FindByID(ctx, deviceID)
+			if err != nil {
+				return nil, err
+			}
+			dev = d
+		}
+
+		levels, err := levelOpenBatches(ctx, s.sales, deviceID, open)
+		if err != nil {
+			return nil, err
+		}
+		today, err := deviceToday(ctx, s.hours, deviceID, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range levels {
+			if l.Remaining > 0 && l.Batch.DaysLeft(today) <= withinDays {
+				views = append(views, toBatchView(dev, l, today, s.markdowns))
+			}
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if !views[i].ExpiresOn.Equal(views[j].ExpiresOn) {
+			return views[i].ExpiresOn.Before(views[j].ExpiresOn)
+		}
+		if views[i].MachineID != views[j].MachineID {
+			return views[i].MachineID < views[j].MachineID
+		}
+		return views[i].SKUCode < views[j].SKUCode
+	})
+	return views, nil
+}
+
+// FreshnessAt evaluates which SKUs of a device are expired or marked down at the given time
+func (s *ExpiryQueryService) FreshnessAt(ctx context.Context, deviceID valueobjects.DeviceID, at time.Time) (domain.Freshness, error) {
+	levels, err := batchLevels(ctx, s.batches, s.sales, deviceID)
+	if err != nil {
+		return domain.Freshness{}, err
+	}
+	today, err := deviceToday(ctx, s.hours, deviceID, at)
+	if err != nil {
+		return domain.Freshness{}, err
+	}
+	return domain.FreshnessOn(levels, today, s.markdowns), nil
+}
+
+// WasteReport totals the batches written off in [from, to), on one device
+// or across the fleet when machineID is empty
+func (s *ExpiryQueryService) WasteReport(ctx context.Context, machineID string, from, to time.Time) (*WasteReportView, error) {
+	var deviceID valueobjects.DeviceID
+	var dev *domain.Device
+	if machineID != "" {
+		d, err := s.devices.FindByMachineID(ctx, machineID)
+		if err != nil {
+			return nil, err
+		}
+		dev, deviceID = d, d.ID()
+	}
+
+	batches, err := s.batches.FindWrittenOffBetween(ctx, deviceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WasteReportView{MachineID: machineID, From: from, To: to, Lines: []WasteLineView{}, Batches: []BatchView{}}
+	lines := make(map[string]*WasteLineView)
+	devices := make(map[valueobjects.DeviceID]*domain.Device)
+	if dev != nil {
+		devices[dev.ID()] = dev
+	}
+	for _, b := range batches {
+		d, ok := devices[b.DeviceID()]
+		if !ok {
+			d, err = s.devices.FindByID(ctx, b.DeviceID())
+			if err != nil {
+				return nil, err
+			}
+			devices[b.DeviceID()] = d
+		}
+
+		line, ok := lines[b.SKUCode()]
+		if !ok {
+			line = &WasteLineView{SKUCode: b.SKUCode()}
+			lines[b.SKUCode()] = line
+		}
+		line.Batches++
+		line.Units += b.WastedUnits()
+		report.TotalUnits += b.WastedUnits()
+		report.Batches = append(report.Batches, toBatchView(d, domain.BatchLevel{Batch: b}, *b.WrittenOffAt(), policy.NoMarkdowns()))
+	}
+	for _, line := range lines {
+		report.Lines = append(report.Lines, *line)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool { return report.Lines[i].SKUCode < report.Lines[j].SKUCode })
+
+	return report, nil
+}
+
+func toBatchView(dev *domain.Device, level domain.BatchLevel, today time.Time, markdowns policy.MarkdownPolicy) BatchView {
+	b := level.Batch
+	view := BatchView{
+		ID:           b.ID().String(),
+		MachineID:    dev.MachineID(),
+		SKUCode:      b.SKUCode(),
+		Quantity:     b.Quantity(),
+		Remaining:    level.Remaining,
+		ExpiresOn:    b.ExpiresOn(),
+		DaysLeft:     b.DaysLeft(today),
+		Expired:      b.IsExpired(today),
+		RestockedBy:  b.RestockedBy(),
+		RestockedAt:  b.RestockedAt(),
+		WrittenOffAt: b.WrittenOffAt(),
+		WrittenOffBy: b.WrittenOffBy(),
+		WastedUnits:  b.WastedUnits(),
+	}
+	if b.IsOpen() && !view.Expired {
+		view.MarkdownPercent = markdowns.PercentFor(view.DaysLeft)
+	}
+	return view
+}
+
+// InventoryView is the counted stock of a device
+type InventoryView struct {
+	DeviceID     string
+	MachineID    string
+	UpdatedAt    *time.Time
+	LowThreshold int
+	Levels       []InventoryLevelView
+}
+
+// InventoryLevelView is the counted quantity of one SKU in a device
+type InventoryLevelView struct {
+	SKUCode  string
+	Quantity int
+	Low      bool
+}
+
+// InventoryQueryService provides read-only access to device inventories
+type InventoryQueryService struct {
+	devices      domain.DeviceRepository
+	inventories  domain.InventoryRepository
+	lowThreshold int
+}
+
+func NewInventoryQueryService(devices domain.DeviceRepository, inventories domain.InventoryRepository, lowThreshold int) *InventoryQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if inventories == nil {
+		panic("nil InventoryRepository")
+	}
+	return &InventoryQueryService{devices: devices, inventories: inventories, lowThreshold: lowThreshold}
+}
+
+// FindByDeviceID returns the device's inventory; a device never restocked has an empty one
+func (s *InventoryQueryService) FindByDeviceID(ctx context.Context, deviceID string) (*InventoryView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, err := findOrNewInventory(ctx, s.inventories, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	view := toInventoryView(dev, inventory, s.lowThreshold)
+	return &view, nil
+}
+
+// LowByDeviceID returns only the SKUs below the low-stock threshold, the
+// shopping list for the device's next restocking run
+func (s *InventoryQueryService) LowByDeviceID(ctx context.Context, deviceID string) (*InventoryView, error) {
+	view, err := s.FindByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	low := []InventoryLevelView{}
+	for _, l := range view.Levels {
+		if l.Low {
+			low = append(low, l)
+		}
+	}
+	view.Levels = low
+	return view, nil
+}
+
+func toInventoryView(dev *domain.Device, inventory *domain.Inventory, lowThreshold int) InventoryView {
+	view := InventoryView{
+		DeviceID:     dev.ID().String(),
+		MachineID:    dev.MachineID(),
+		LowThreshold: lowThreshold,
+		Levels:       []InventoryLevelView{},
+	}
+	if updatedAt := inventory.UpdatedAt(); !updatedAt.IsZero() {
+		view.UpdatedAt = &updatedAt
+	}
+	for _, code := range inventory.SKUCodes() {
+		view.Levels = append(view.Levels, InventoryLevelView{
+			SKUCode:  code,
+			Quantity: inventory.Quantity(code),
+			Low:      inventory.IsLow(code, lowThreshold),
+		})
+	}
+	return view
+}
+
+// AssortmentView lists the SKUs assigned to a device
+type AssortmentView struct {
+	DeviceID  string
+	MachineID string
+	UpdatedAt *time.Time
+	SKUCodes  []string
+}
+
+// AssortmentQueryService provides read-only access to device assortments
+type AssortmentQueryService struct {
+	devices     domain.DeviceRepository
+	assortments domain.AssortmentRepository
+}
+
+func NewAssortmentQueryService(devices domain.DeviceRepository, assortments domain.AssortmentRepository) *AssortmentQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if assortments == nil {
+		panic("nil AssortmentRepository")
+	}
+	return &AssortmentQueryService{devices: devices, assortments: assortments}
+}
+
+// FindByDeviceID returns the SKUs assigned to the device; the list is empty
+// when none are
+func (s *AssortmentQueryService) FindByDeviceID(ctx context.Context, deviceID string) (*AssortmentView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	assortment, err := findOrNewAssortment(ctx, s.assortments, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	view := toAssortmentView(dev, assortment)
+	return &view, nil
+}
+
+func toAssortmentView(dev *domain.Device, assortment *domain.Assortment) AssortmentView {
+	view := AssortmentView{
+		DeviceID:  dev.ID().String(),
+		MachineID: dev.MachineID(),
+		SKUCodes:  assortment.SKUCodes(),
+	}
+	if updatedAt := assortment.UpdatedAt(); !updatedAt.IsZero() {
+		view.UpdatedAt = &updatedAt
+	}
+	return view
+}
+
+// DeviceGroupView is a read-only view of a device group and its policy
+type DeviceGroupView struct {
+	ID                       string
+	Name                     string
+	ConfidenceThreshold      *float64
+	ModelVersion             string
+	SessionExpirationMinutes *int
+	PlanogramVersion         int // 0 when the group never had a planogram
+	Planogram                []domain.PlanogramFacing
+	DeviceCount              int
+	MachineIDs               []string // nil in lists
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
+}
+
+// DevicePolicyView is the policy that applies to a device, with where each
+// setting comes from
+type DevicePolicyView struct {
+	DeviceID                       string
+	MachineID                      string
+	GroupID                        string // empty when the device is in no group
+	ConfidenceThreshold            *float64
+	ConfidenceThresholdSource      domain.PolicySource
+	ModelVersion                   string
+	ModelVersionSource             domain.PolicySource
+	SessionExpirationMinutes       *int
+	SessionExpirationMinutesSource domain.PolicySource
+	Overrides                      domain.DevicePolicy
+	PlanogramVersion               int                 // 0 when the device has no planogram
+	PlanogramSource                domain.PolicySource // empty when the device has no planogram
+}
+
+// DeviceGroupQueryService provides read-only access to device groups and the
+// policy their devices resolve to
+type DeviceGroupQueryService struct {
+	groups   domain.DeviceGroupRepository
+	devices  domain.DeviceRepository
+	resolver *PolicyResolver
+}
+
+func NewDeviceGroupQueryService(groups domain.DeviceGroupRepository, devices domain.DeviceRepository, resolver *PolicyResolver) *DeviceGroupQueryService {
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &DeviceGroupQueryService{groups: groups, devices: devices, resolver: resolver}
+}
+
+// List returns every group by name with how many devices it holds
+func (s *DeviceGroupQueryService) List(ctx context.Context) ([]DeviceGroupView, error) {
+	groups, err := s.groups.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := s.devices.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[valueobjects.DeviceGroupID]int)
+	for _, dev := range devices {
+		if groupID := dev.GroupID(); groupID != nil {
+			counts[*groupID]++
+		}
+	}
+
+	views := make([]DeviceGroupView, 0, len(groups))
+	for _, g := range groups {
+		view := toDeviceGroupView(g, nil)
+		view.DeviceCount = counts[g.ID()]
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// FindByID returns a group with the machine IDs of its devices
+func (s *DeviceGroupQueryService) FindByID(ctx context.Context, id string) (*DeviceGroupView, error) {
+	group, err := findGroupByID(ctx, s.groups, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.devices.FindByGroupID(ctx, group.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	view := toDeviceGroupView(group, members)
+	return &view, nil
+}
+
+// PolicyByDeviceID returns the policy that applies to a device
+func (s *DeviceGroupQueryService) PolicyByDeviceID(ctx context.Context, deviceID string) (*DevicePolicyView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return resolveDevicePolicy(ctx, s.resolver, dev)
+}
+
+func resolveDevicePolicy(ctx context.Context, resolver *PolicyResolver, dev *domain.Device) (*DevicePolicyView, error) {
+	policy, err := resolver.Policy(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+
+	view := DevicePolicyView{
+		DeviceID:                       dev.ID().String(),
+		MachineID:                      dev.MachineID(),
+		ConfidenceThreshold:            policy.ConfidenceThreshold,
+		ConfidenceThresholdSource:      policy.ConfidenceThresholdSource,
+		ModelVersion:                   policy.ModelVersion,
+		ModelVersionSource:             policy.ModelVersionSource,
+		SessionExpirationMinutes:       policy.SessionExpirationMinutes,
+		SessionExpirationMinutesSource: policy.SessionExpirationMinutesSource,
+		Overrides:                      dev.PolicyOverrides(),
+	}
+	if groupID := dev.GroupID(); groupID != nil {
+		view.GroupID = groupID.String()
+	}
+
+	planogram, source, err := resolver.Planogram(ctx, dev)
+	switch {
+	case err == nil:
+		view.PlanogramVersion, view.PlanogramSource = planogram.Version(), source
+	case !errors.Is(err, domain.ErrPlanogramNotFound):
+		return nil, err
+	}
+	return &view, nil
+}
+
+// toDeviceGroupView builds the view of a group; members are listed when given
+func toDeviceGroupView(g *domain.DeviceGroup, members []*domain.Device) DeviceGroupView {
+	policy := g.Policy()
+	view := DeviceGroupView{
+		ID:                       g.ID().String(),
+		Name:                     g.Name(),
+		ConfidenceThreshold:      policy.ConfidenceThreshold,
+		ModelVersion:             policy.ModelVersion,
+		SessionExpirationMinutes: policy.SessionExpirationMinutes,
+		PlanogramVersion:         g.PlanogramVersion(),
+		Planogram:                g.Planogram(),
+		DeviceCount:              len(members),
+		CreatedAt:                g.CreatedAt(),
+		UpdatedAt:                g.UpdatedAt(),
+	}
+	if members != nil {
+		view.MachineIDs = make([]string, 0, len(members))
+		for _, dev := range members {
+			view.MachineIDs = append(view.MachineIDs, dev.MachineID())
+		}
+	}
+	return view
 }
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/policy"
+)
+
+// BatchInput is one batch loaded during a restock
+type BatchInput struct {
+	SKUCode   string
+	Quantity  int
+	ExpiresOn time.Time // zero takes the SKU's shelf life from the restock day
+}
+
+// RecordBatchesCommand is the input DTO for the batches field staff load into a device
+type RecordBatchesCommand struct {
+	MachineID   string
+	RestockedBy string
+	RestockedAt time.Time // zero means now
+	Batches     []BatchInput
+}
+
+// RecordBatchesHandler records restocked batches so their expiry can be
+// tracked. A batch recorded without an expiry date expires its SKU's shelf
+// life after the restock day.
+type RecordBatchesHandler struct {
+	devices   domain.DeviceRepository
+	batches   domain.StockBatchRepository
+	catalog   SKUCatalog
+	publisher EventPublisher
+	markdowns policy.MarkdownPolicy
+}
+
+func NewRecordBatchesHandler(devices domain.DeviceRepository, batches domain.StockBatchRepository, catalog SKUCatalog, publisher EventPublisher, markdowns policy.MarkdownPolicy) *RecordBatchesHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if batches == nil {
+		panic("nil StockBatchRepository")
+	}
+	if catalog == nil {
+		panic("nil SKUCatalog")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordBatchesHandler{
+		devices:   devices,
+		batches:   batches,
+		catalog:   catalog,
+		publisher: publisher,
+		markdowns: markdowns,
+	}
+}
+
+func (h *RecordBatchesHandler) Handle(ctx context.Context, cmd RecordBatchesCommand) ([]BatchView, error) {
+	if cmd.RestockedBy == "" {
+		return nil, domain.ErrRestockedByRequired
+	}
+	if len(cmd.Batches) == 0 {
+		return nil, domain.ErrInvalidBatch
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+
+	restockedAt := cmd.RestockedAt
+	if restockedAt.IsZero() {
+		restockedAt = time.Now().UTC()
+	}
+
+	var shelfLives map[string]int
+	for _, in := range cmd.Batches {
+		if in.ExpiresOn.IsZero() {
+			if shelfLives, err = h.catalog.ShelfLives(ctx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// Validate the whole restock before saving any of it
+	batches := make([]*domain.StockBatch, 0, len(cmd.Batches))
+	for _, in := range cmd.Batches {
+		expiresOn := in.ExpiresOn
+		if expiresOn.IsZero() {
+			days, ok := shelfLives[strings.TrimSpace(in.SKUCode)]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s has no shelf life, its expiry date is required", domain.ErrInvalidBatch, in.SKUCode)
+			}
+			expiresOn = restockedAt.AddDate(0, 0, days)
+		}
+		b, err := domain.NewStockBatch(dev.ID(), in.SKUCode, in.Quantity, expiresOn, cmd.RestockedBy, restockedAt)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+
+	views := make([]BatchView, 0, len(batches))
+	for _, b := range batches {
+		if err := h.batches.Save(ctx, b); err != nil {
+			return nil, fmt.Errorf("failed to save stock batch: %w", err)
+		}
+		for _, evt := range b.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+		views = append(views, toBatchView(dev, domain.BatchLevel{Batch: b, Remaining: b.Quantity()}, restockedAt, h.markdowns))
+	}
+	return views, nil
+}
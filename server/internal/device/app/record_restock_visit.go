@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// RecordRestockVisitCommand is the input DTO for the shelf snapshot field
+// staff take when they finish restocking a device
+type RecordRestockVisitCommand struct {
+	MachineID  string
+	VisitedBy  string
+	Image      []byte
+	CapturedAt time.Time // zero means now
+}
+
+// RecordRestockVisitHandler checks a restock snapshot against the device's
+// planogram, its own or its group's, and records the compliance report for
+// merchandising
+type RecordRestockVisitHandler struct {
+	devices       domain.DeviceRepository
+	resolver      *PolicyResolver
+	reports       domain.ComplianceReportRepository
+	detector      ShelfDetector
+	publisher     EventPublisher
+	minConfidence float64
+}
+
+func NewRecordRestockVisitHandler(
+	devices domain.DeviceRepository,
+	resolver *PolicyResolver,
+	reports domain.ComplianceReportRepository,
+	detector ShelfDetector,
+	publisher EventPublisher,
+	minConfidence float64,
+) *RecordRestockVisitHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	if reports == nil {
+		panic("nil ComplianceReportRepository")
+	}
+	if detector == nil {
+		panic("nil ShelfDetector")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordRestockVisitHandler{
+		devices:       devices,
+		resolver:      resolver,
+		reports:       reports,
+		detector:      detector,
+		publisher:     publisher,
+		minConfidence: minConfidence,
+	}
+}
+
+func (h *RecordRestockVisitHandler) Handle(ctx context.Context, cmd RecordRestockVisitCommand) (*ComplianceReportView, error) {
+	if cmd.VisitedBy == "" {
+		return nil, domain.ErrVisitedByRequired
+	}
+	if len(cmd.Image) == 0 {
+		return nil, domain.ErrEmptySnapshot
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+	planogram, _, err := h.resolver.Planogram(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+	minConfidence, err := h.resolver.ConfidenceThreshold(ctx, dev, h.minConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.detector.DetectShelf(ctx, dev.ID().String(), cmd.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects the detector could not place on a shelf cannot be checked against the layout
+	found := make(map[domain.ShelfSKU]int)
+	for _, d := range result.Detections {
+		if d.SKUCode != "" && d.Shelf > 0 && d.Confidence >= minConfidence {
+			found[domain.ShelfSKU{Shelf: d.Shelf, SKUCode: d.SKUCode}]++
+		}
+	}
+
+	capturedAt := cmd.CapturedAt
+	if capturedAt.IsZero() {
+		capturedAt = time.Now().UTC()
+	}
+	report, err := domain.CheckCompliance(planogram, found, cmd.VisitedBy, capturedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.reports.Save(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to save compliance report: %w", err)
+	}
+
+	for _, evt := range report.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toComplianceReportView(report)
+	return &view, nil
+}
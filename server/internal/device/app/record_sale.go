@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// CompletedSale is what a completed session sold on a device
+type CompletedSale struct {
+	DeviceID string
+	Units    map[string]int // SKU code -> units sold
+}
+
+// CompletedSaleReader is an output port for the sessions the transaction context completed
+type CompletedSaleReader interface {
+	CompletedSale(ctx context.Context, sessionID string) (*CompletedSale, error)
+}
+
+// RecordSaleCommand is the input DTO for taking a completed session out of inventory
+type RecordSaleCommand struct {
+	SessionID string
+}
+
+// RecordSaleHandler takes the units a completed session sold out of its
+// device's inventory. It is safe to call more than once for a session.
+type RecordSaleHandler struct {
+	inventories  domain.InventoryRepository
+	sales        CompletedSaleReader
+	publisher    EventPublisher
+	lowThreshold int
+}
+
+func NewRecordSaleHandler(inventories domain.InventoryRepository, sales CompletedSaleReader, publisher EventPublisher, lowThreshold int) *RecordSaleHandler {
+	if inventories == nil {
+		panic("nil InventoryRepository")
+	}
+	if sales == nil {
+		panic("nil CompletedSaleReader")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordSaleHandler{
+		inventories:  inventories,
+		sales:        sales,
+		publisher:    publisher,
+		lowThreshold: lowThreshold,
+	}
+}
+
+func (h *RecordSaleHandler) Handle(ctx context.Context, cmd RecordSaleCommand) error {
+	sale, err := h.sales.CompletedSale(ctx, cmd.SessionID)
+	if err != nil {
+		return err
+	}
+	if len(sale.Units) == 0 {
+		return nil
+	}
+	deviceID, err := valueobjects.DeviceIDFrom(sale.DeviceID)
+	if err != nil {
+		return fmt.Errorf("session %s has an invalid device: %w", cmd.SessionID, err)
+	}
+
+	inventory, err := findOrNewInventory(ctx, h.inventories, deviceID)
+	if err != nil {
+		return err
+	}
+	inventory.RecordSale(cmd.SessionID, sale.Units, time.Now(), h.lowThreshold)
+
+	err = h.inventories.SaveSale(ctx, inventory, cmd.SessionID)
+	if errors.Is(err, domain.ErrSaleAlreadyRecorded) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save inventory: %w", err)
+	}
+
+	for _, evt := range inventory.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return nil
+}
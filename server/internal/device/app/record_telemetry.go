@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// TemperaturePolicy is the safe range for fresh-food cabinets
+type TemperaturePolicy struct {
+	MaxCelsius  float64
+	MaxDuration time.Duration // how long the cabinet may stay above MaxCelsius
+}
+
+// DoorPolicy bounds how long the door may stay open after a session completes
+type DoorPolicy struct {
+	CloseGrace time.Duration
+}
+
+// SessionActivity is what the transaction context knows about a device's sessions
+type SessionActivity struct {
+	HasActiveSession bool
+	LastCompletedAt  *time.Time
+}
+
+// SessionActivityReader is an output port for correlating door telemetry with sessions
+type SessionActivityReader interface {
+	ActivityByDeviceID(ctx context.Context, deviceID string) (SessionActivity, error)
+}
+
+// RecordTelemetryCommand is the input DTO for a device telemetry report
+type RecordTelemetryCommand struct {
+	MachineID          string
+	RecordedAt         time.Time // zero means now
+	TemperatureCelsius *float64  // only reported by refrigerated devices
+	DoorOpen           *bool     // door switch state
+}
+
+// RecordTelemetryResult is the output DTO
+type RecordTelemetryResult struct {
+	MachineID string
+	Status    string
+	Incident  string // kind of security incident raised by this report, if any
+}
+
+// RecordTelemetryHandler applies device telemetry: it blocks fresh-food
+// devices whose cabinet stays too warm and records door-open security incidents
+type RecordTelemetryHandler struct {
+	devices     domain.DeviceRepository
+	excursions  domain.ExcursionRepository
+	incidents   domain.IncidentRepository
+	sessions    SessionActivityReader
+	publisher   EventPublisher
+	temperature TemperaturePolicy
+	door        DoorPolicy
+}
+
+func NewRecordTelemetryHandler(
+	devices domain.DeviceRepository,
+	excursions domain.ExcursionRepository,
+	incidents domain.IncidentRepository,
+	sessions SessionActivityReader,
+	publisher EventPublisher,
+	temperature TemperaturePolicy,
+	door DoorPolicy,
+) *RecordTelemetryHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if excursions == nil {
+		panic("nil ExcursionRepository")
+	}
+	if incidents == nil {
+		panic("nil IncidentRepository")
+	}
+	if sessions == nil {
+		panic("nil SessionActivityReader")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordTelemetryHandler{
+		devices:     devices,
+		excursions:  excursions,
+		incidents:   incidents,
+		sessions:    sessions,
+		publisher:   publisher,
+		temperature: temperature,
+		door:        door,
+	}
+}
+
+func (h *RecordTelemetryHandler) Handle(ctx context.Context, cmd RecordTelemetryCommand) (RecordTelemetryResult, error) {
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return RecordTelemetryResult{}, err
+	}
+	if dev.IsDecommissioned() {
+		return RecordTelemetryResult{}, domain.ErrDeviceDecommissioned
+	}
+
+	recordedAt := cmd.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now().UTC()
+	}
+
+	var excursion *domain.TemperatureExcursion
+	if cmd.TemperatureCelsius != nil {
+		celsius := *cmd.TemperatureCelsius
+		wasBlocked := dev.IsBlocked()
+
+		if dev.ObserveTemperature(celsius, recordedAt, h.temperature.MaxCelsius, h.temperature.MaxDuration) {
+			excursion = domain.NewTemperatureExcursion(dev.ID(), *dev.OverTempSince(), recordedAt, h.temperature.MaxCelsius, celsius)
+		} else if wasBlocked {
+			excursion, err = h.excursions.FindOpenByDeviceID(ctx, dev.ID())
+			if errors.Is(err, domain.ErrExcursionNotFound) {
+				excursion = nil
+			} else if err != nil {
+				return RecordTelemetryResult{}, err
+			}
+			if excursion != nil {
+				excursion.RecordReading(celsius)
+			}
+		}
+	}
+
+	var incident *domain.SecurityIncident
+	if cmd.DoorOpen != nil {
+		var activity SessionActivity
+		if *cmd.DoorOpen {
+			activity, err = h.sessions.ActivityByDeviceID(ctx, dev.ID().String())
+			if err != nil {
+				return RecordTelemetryResult{}, err
+			}
+		}
+
+		kind, raised := dev.ObserveDoor(*cmd.DoorOpen, recordedAt, activity.HasActiveSession, activity.LastCompletedAt, h.door.CloseGrace)
+		if raised {
+			incident = domain.NewSecurityIncident(dev.ID(), kind, *dev.Door().OpenSince, recordedAt)
+		}
+	}
+
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return RecordTelemetryResult{}, fmt.Errorf("failed to save device: %w", err)
+	}
+	if excursion != nil {
+		if err := h.excursions.Save(ctx, excursion); err != nil {
+			return RecordTelemetryResult{}, fmt.Errorf("failed to save excursion: %w", err)
+		}
+	}
+	if incident != nil {
+		if err := h.incidents.Save(ctx, incident); err != nil {
+			return RecordTelemetryResult{}, fmt.Errorf("failed to save incident: %w", err)
+		}
+	}
+
+	// Publish domain events
+	for _, evt := range dev.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+	if excursion != nil {
+		for _, evt := range excursion.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+	}
+	if incident != nil {
+		for _, evt := range incident.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+	}
+
+	return RecordTelemetryResult{
+		MachineID: dev.MachineID(),
+		Status:    string(dev.Status()),
+		Incident:  incidentKind(incident),
+	}, nil
+}
+
+func incidentKind(incident *domain.SecurityIncident) string {
+	if incident == nil {
+		return ""
+	}
+	return string(incident.Kind())
+}
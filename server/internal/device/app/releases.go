@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// releaseContentType is the content type release artifacts are stored and served with
+const releaseContentType = "application/octet-stream"
+
+// ObjectStorage is an output port for keeping binary objects outside the database
+type ObjectStorage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// UploadReleaseCommand is the input DTO for registering a firmware or app build
+type UploadReleaseCommand struct {
+	Kind       domain.ReleaseKind
+	Version    string
+	Artifact   []byte
+	Notes      string
+	UploadedBy string
+}
+
+// UploadReleaseHandler adds builds to the release registry. The artifact
+// goes to object storage first, so a registered release always has one; an
+// artifact whose release cannot be saved is removed again.
+type UploadReleaseHandler struct {
+	releases domain.ReleaseRepository
+	storage  ObjectStorage
+}
+
+func NewUploadReleaseHandler(releases domain.ReleaseRepository, storage ObjectStorage) *UploadReleaseHandler {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if storage == nil {
+		panic("nil ObjectStorage")
+	}
+	return &UploadReleaseHandler{releases: releases, storage: storage}
+}
+
+func (h *UploadReleaseHandler) Handle(ctx context.Context, cmd UploadReleaseCommand) (domain.Release, error) {
+	release, err := domain.NewRelease(cmd.Kind, cmd.Version, cmd.Artifact, cmd.Notes, cmd.UploadedBy)
+	if err != nil {
+		return domain.Release{}, err
+	}
+
+	if err := h.storage.Put(ctx, release.ObjectKey, releaseContentType, cmd.Artifact); err != nil {
+		return domain.Release{}, fmt.Errorf("failed to store release artifact: %w", err)
+	}
+	if err := h.releases.Save(ctx, release); err != nil {
+		_ = h.storage.Delete(ctx, release.ObjectKey)
+		return domain.Release{}, err
+	}
+	return release, nil
+}
+
+// DownloadReleaseCommand is the input DTO for a device fetching a release artifact
+type DownloadReleaseCommand struct {
+	MachineID string
+	DeviceKey string
+	ReleaseID string
+}
+
+// DownloadReleaseHandler hands devices the artifacts of releases. The device
+// authenticates with its own key.
+type DownloadReleaseHandler struct {
+	devices  domain.DeviceRepository
+	releases domain.ReleaseRepository
+	storage  ObjectStorage
+}
+
+func NewDownloadReleaseHandler(devices domain.DeviceRepository, releases domain.ReleaseRepository, storage ObjectStorage) *DownloadReleaseHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if storage == nil {
+		panic("nil ObjectStorage")
+	}
+	return &DownloadReleaseHandler{devices: devices, releases: releases, storage: storage}
+}
+
+// Handle returns the release and its artifact
+func (h *DownloadReleaseHandler) Handle(ctx context.Context, cmd DownloadReleaseCommand) (domain.Release, []byte, error) {
+	if _, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey); err != nil {
+		return domain.Release{}, nil, err
+	}
+	release, err := findReleaseByID(ctx, h.releases, cmd.ReleaseID)
+	if err != nil {
+		return domain.Release{}, nil, err
+	}
+	data, _, err := h.storage.Get(ctx, release.ObjectKey)
+	if err != nil {
+		return domain.Release{}, nil, fmt.Errorf("failed to load release artifact: %w", err)
+	}
+	return release, data, nil
+}
+
+// ReleaseQueryService provides read-only access to the release registry
+type ReleaseQueryService struct {
+	releases domain.ReleaseRepository
+}
+
+func NewReleaseQueryService(releases domain.ReleaseRepository) *ReleaseQueryService {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	return &ReleaseQueryService{releases: releases}
+}
+
+// List returns the releases of one kind, or of every kind when kind is empty, newest first
+func (s *ReleaseQueryService) List(ctx context.Context, kind domain.ReleaseKind) ([]domain.Release, error) {
+	return s.releases.FindAll(ctx, kind)
+}
+
+func (s *ReleaseQueryService) FindByID(ctx context.Context, id string) (domain.Release, error) {
+	return findReleaseByID(ctx, s.releases, id)
+}
+
+func findReleaseByID(ctx context.Context, releases domain.ReleaseRepository, id string) (domain.Release, error) {
+	releaseID, err := valueobjects.ReleaseIDFrom(id)
+	if err != nil {
+		return domain.Release{}, domain.ErrReleaseNotFound
+	}
+	return releases.FindByID(ctx, releaseID)
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// SKUCatalog is an output port for checking SKU codes against the catalog context
+type SKUCatalog interface {
+	// KnownSKUCodes returns the code of every SKU in the catalog, on sale or not
+	KnownSKUCodes(ctx context.Context) (map[string]bool, error)
+	// ShelfLives returns the shelf life in days of each perishable SKU, by code
+	ShelfLives(ctx context.Context) (map[string]int, error)
+}
+
+// RestockInventoryCommand is the input DTO for the units field staff load into a device
+type RestockInventoryCommand struct {
+	DeviceID    string
+	RestockedBy string
+	Units       map[string]int // SKU code -> units added
+}
+
+// RestockInventoryHandler adds restocked units to a device's inventory
+type RestockInventoryHandler struct {
+	devices      domain.DeviceRepository
+	inventories  domain.InventoryRepository
+	catalog      SKUCatalog
+	publisher    EventPublisher
+	lowThreshold int
+}
+
+func NewRestockInventoryHandler(devices domain.DeviceRepository, inventories domain.InventoryRepository, catalog SKUCatalog, publisher EventPublisher, lowThreshold int) *RestockInventoryHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if inventories == nil {
+		panic("nil InventoryRepository")
+	}
+	if catalog == nil {
+		panic("nil SKUCatalog")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RestockInventoryHandler{
+		devices:      devices,
+		inventories:  inventories,
+		catalog:      catalog,
+		publisher:    publisher,
+		lowThreshold: lowThreshold,
+	}
+}
+
+func (h *RestockInventoryHandler) Handle(ctx context.Context, cmd RestockInventoryCommand) (*InventoryView, error) {
+	if cmd.RestockedBy == "" {
+		return nil, domain.ErrRestockedByRequired
+	}
+
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := h.catalog.KnownSKUCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for code := range cmd.Units {
+		if !known[strings.TrimSpace(code)] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownSKU, code)
+		}
+	}
+
+	inventory, err := findOrNewInventory(ctx, h.inventories, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+	if err := inventory.Restock(cmd.Units, cmd.RestockedBy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := h.inventories.Save(ctx, inventory); err != nil {
+		return nil, fmt.Errorf("failed to save inventory: %w", err)
+	}
+
+	for _, evt := range inventory.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toInventoryView(dev, inventory, h.lowThreshold)
+	return &view, nil
+}
+
+// findDeviceByID resolves a device ID from a request path
+func findDeviceByID(ctx context.Context, devices domain.DeviceRepository, id string) (*domain.Device, error) {
+	deviceID, err := valueobjects.DeviceIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrDeviceNotFound
+	}
+	return devices.FindByID(ctx, deviceID)
+}
+
+// findOrNewInventory loads a device's inventory; a device never restocked
+// has an empty one
+func findOrNewInventory(ctx context.Context, inventories domain.InventoryRepository, deviceID valueobjects.DeviceID) (*domain.Inventory, error) {
+	inventory, err := inventories.FindByDeviceID(ctx, deviceID)
+	if errors.Is(err, domain.ErrInventoryNotFound) {
+		return domain.NewInventory(deviceID), nil
+	}
+	return inventory, err
+}
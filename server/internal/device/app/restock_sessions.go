@@ -0,0 +1,368 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RestockLineView is one SKU of a restock report
+type RestockLineView struct {
+	Code       string
+	Added      int
+	LevelAfter *int // units in the device once the session completed
+}
+
+// RestockSessionView is a read-only view of a restock session, which is its
+// report once the session is closed
+type RestockSessionView struct {
+	ID         string
+	DeviceID   string
+	MachineID  string
+	Status     string
+	StartedBy  string
+	StartedAt  time.Time
+	ClosedBy   string
+	ClosedAt   *time.Time
+	UnitsAdded int
+	Lines      []RestockLineView // by SKU code
+}
+
+func toRestockSessionView(dev *domain.Device, s *domain.RestockSession) RestockSessionView {
+	units := s.Units()
+	levels := s.LevelsAfter()
+
+	view := RestockSessionView{
+		ID:        s.ID().String(),
+		DeviceID:  dev.ID().String(),
+		MachineID: dev.MachineID(),
+		Status:    string(s.Status()),
+		StartedBy: s.StartedBy(),
+		StartedAt: s.StartedAt(),
+		ClosedBy:  s.ClosedBy(),
+		ClosedAt:  s.ClosedAt(),
+		Lines:     make([]RestockLineView, 0, len(units)),
+	}
+	for code, qty := range units {
+		line := RestockLineView{Code: code, Added: qty}
+		if level, ok := levels[code]; ok {
+			line.LevelAfter = &level
+		}
+		view.Lines = append(view.Lines, line)
+		view.UnitsAdded += qty
+	}
+	sort.Slice(view.Lines, func(i, j int) bool { return view.Lines[i].Code < view.Lines[j].Code })
+	return view
+}
+
+// StartRestockSessionCommand is an operator opening a device to restock it
+type StartRestockSessionCommand struct {
+	DeviceID  string
+	StartedBy string
+}
+
+// StartRestockSessionHandler opens a restock session, which keeps customers
+// off the device until the operator completes or cancels it. A device with a
+// customer session under way cannot be restocked until that session ends.
+type StartRestockSessionHandler struct {
+	devices   domain.DeviceRepository
+	sessions  domain.RestockSessionRepository
+	activity  SessionActivityReader
+	publisher EventPublisher
+}
+
+func NewStartRestockSessionHandler(devices domain.DeviceRepository, sessions domain.RestockSessionRepository, activity SessionActivityReader, publisher EventPublisher) *StartRestockSessionHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if sessions == nil {
+		panic("nil RestockSessionRepository")
+	}
+	if activity == nil {
+		panic("nil SessionActivityReader")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &StartRestockSessionHandler{devices: devices, sessions: sessions, activity: activity, publisher: publisher}
+}
+
+func (h *StartRestockSessionHandler) Handle(ctx context.Context, cmd StartRestockSessionCommand) (RestockSessionView, error) {
+	dev, err := findDeviceByID(ctx, h.devices, cmd.DeviceID)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+
+	activity, err := h.activity.ActivityByDeviceID(ctx, dev.ID().String())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	if activity.HasActiveSession {
+		return RestockSessionView{}, domain.ErrCustomerSessionActive
+	}
+
+	session, err := dev.StartRestock(cmd.StartedBy, time.Now())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	if err := h.sessions.Save(ctx, session); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save restock session: %w", err)
+	}
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range session.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRestockSessionView(dev, session), nil
+}
+
+// RecordRestockUnitsCommand is the units an operator loaded so far
+type RecordRestockUnitsCommand struct {
+	SessionID string
+	Units     map[string]int // SKU code -> units loaded
+}
+
+// RecordRestockUnitsHandler counts units against an open restock session.
+// They reach the inventory only when the session completes.
+type RecordRestockUnitsHandler struct {
+	devices  domain.DeviceRepository
+	sessions domain.RestockSessionRepository
+	catalog  SKUCatalog
+}
+
+func NewRecordRestockUnitsHandler(devices domain.DeviceRepository, sessions domain.RestockSessionRepository, catalog SKUCatalog) *RecordRestockUnitsHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if sessions == nil {
+		panic("nil RestockSessionRepository")
+	}
+	if catalog == nil {
+		panic("nil SKUCatalog")
+	}
+	return &RecordRestockUnitsHandler{devices: devices, sessions: sessions, catalog: catalog}
+}
+
+func (h *RecordRestockUnitsHandler) Handle(ctx context.Context, cmd RecordRestockUnitsCommand) (RestockSessionView, error) {
+	session, err := findRestockSession(ctx, h.sessions, cmd.SessionID)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+
+	known, err := h.catalog.KnownSKUCodes(ctx)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	for code := range cmd.Units {
+		if !known[strings.TrimSpace(code)] {
+			return RestockSessionView{}, fmt.Errorf("%w: %s", domain.ErrUnknownSKU, code)
+		}
+	}
+
+	if err := session.AddUnits(cmd.Units); err != nil {
+		return RestockSessionView{}, err
+	}
+	if err := h.sessions.Save(ctx, session); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save restock session: %w", err)
+	}
+
+	dev, err := h.devices.FindByID(ctx, session.DeviceID())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	return toRestockSessionView(dev, session), nil
+}
+
+// CloseRestockSessionCommand is an operator completing or cancelling a
+// restock session
+type CloseRestockSessionCommand struct {
+	SessionID string
+	ClosedBy  string
+}
+
+// CompleteRestockSessionHandler adds the session's units to the device's
+// inventory and lets the device sell again
+type CompleteRestockSessionHandler struct {
+	devices     domain.DeviceRepository
+	sessions    domain.RestockSessionRepository
+	inventories domain.InventoryRepository
+	publisher   EventPublisher
+}
+
+func NewCompleteRestockSessionHandler(devices domain.DeviceRepository, sessions domain.RestockSessionRepository, inventories domain.InventoryRepository, publisher EventPublisher) *CompleteRestockSessionHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if sessions == nil {
+		panic("nil RestockSessionRepository")
+	}
+	if inventories == nil {
+		panic("nil InventoryRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CompleteRestockSessionHandler{devices: devices, sessions: sessions, inventories: inventories, publisher: publisher}
+}
+
+func (h *CompleteRestockSessionHandler) Handle(ctx context.Context, cmd CloseRestockSessionCommand) (RestockSessionView, error) {
+	session, err := findRestockSession(ctx, h.sessions, cmd.SessionID)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	if !session.IsOpen() {
+		return RestockSessionView{}, domain.ErrRestockSessionClosed
+	}
+	dev, err := h.devices.FindByID(ctx, session.DeviceID())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+
+	inventory, err := findOrNewInventory(ctx, h.inventories, dev.ID())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	now := time.Now()
+	// A visit that loaded nothing completes without touching the inventory
+	if units := session.Units(); len(units) > 0 {
+		if err := inventory.Restock(units, cmd.ClosedBy, now); err != nil {
+			return RestockSessionView{}, err
+		}
+	}
+	if err := session.Complete(cmd.ClosedBy, inventory, now); err != nil {
+		return RestockSessionView{}, err
+	}
+
+	if err := h.inventories.Save(ctx, inventory); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save inventory: %w", err)
+	}
+	if err := h.sessions.Save(ctx, session); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save restock session: %w", err)
+	}
+	dev.EndRestock(session)
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range inventory.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+	for _, evt := range session.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRestockSessionView(dev, session), nil
+}
+
+// CancelRestockSessionHandler closes a restock session without adding its
+// units to the inventory and lets the device sell again
+type CancelRestockSessionHandler struct {
+	devices   domain.DeviceRepository
+	sessions  domain.RestockSessionRepository
+	publisher EventPublisher
+}
+
+func NewCancelRestockSessionHandler(devices domain.DeviceRepository, sessions domain.RestockSessionRepository, publisher EventPublisher) *CancelRestockSessionHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if sessions == nil {
+		panic("nil RestockSessionRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CancelRestockSessionHandler{devices: devices, sessions: sessions, publisher: publisher}
+}
+
+func (h *CancelRestockSessionHandler) Handle(ctx context.Context, cmd CloseRestockSessionCommand) (RestockSessionView, error) {
+	session, err := findRestockSession(ctx, h.sessions, cmd.SessionID)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	dev, err := h.devices.FindByID(ctx, session.DeviceID())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+
+	if err := session.Cancel(cmd.ClosedBy, time.Now()); err != nil {
+		return RestockSessionView{}, err
+	}
+	if err := h.sessions.Save(ctx, session); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save restock session: %w", err)
+	}
+	dev.EndRestock(session)
+	if err := h.devices.Save(ctx, dev); err != nil {
+		return RestockSessionView{}, fmt.Errorf("failed to save device: %w", err)
+	}
+
+	for _, evt := range session.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRestockSessionView(dev, session), nil
+}
+
+// RestockSessionQueryService reads restock sessions and their reports
+type RestockSessionQueryService struct {
+	devices  domain.DeviceRepository
+	sessions domain.RestockSessionRepository
+}
+
+func NewRestockSessionQueryService(devices domain.DeviceRepository, sessions domain.RestockSessionRepository) *RestockSessionQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if sessions == nil {
+		panic("nil RestockSessionRepository")
+	}
+	return &RestockSessionQueryService{devices: devices, sessions: sessions}
+}
+
+func (s *RestockSessionQueryService) Get(ctx context.Context, sessionID string) (RestockSessionView, error) {
+	session, err := findRestockSession(ctx, s.sessions, sessionID)
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	dev, err := s.devices.FindByID(ctx, session.DeviceID())
+	if err != nil {
+		return RestockSessionView{}, err
+	}
+	return toRestockSessionView(dev, session), nil
+}
+
+// ListByDevice returns the device's latest limit restock sessions, newest first
+func (s *RestockSessionQueryService) ListByDevice(ctx context.Context, deviceID string, limit int) ([]RestockSessionView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.FindByDeviceID(ctx, dev.ID(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]RestockSessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, toRestockSessionView(dev, session))
+	}
+	return views, nil
+}
+
+// findRestockSession resolves a restock session ID from a request path
+func findRestockSession(ctx context.Context, sessions domain.RestockSessionRepository, id string) (*domain.RestockSession, error) {
+	sessionID, err := valueobjects.RestockSessionIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrRestockSessionNotFound
+	}
+	return sessions.FindByID(ctx, sessionID)
+}
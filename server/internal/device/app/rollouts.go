@@ -0,0 +1,411 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// StartRolloutCommand is the input DTO for rolling a release out to device groups
+type StartRolloutCommand struct {
+	ReleaseID string
+	GroupIDs  []string
+	Stages    []int // percentages of the devices; empty rolls out to all at once
+	StartedBy string
+}
+
+// StartRolloutHandler starts rolling releases out. A group takes one staging
+// rollout of each kind at a time; the newest rollout of a kind reaching a
+// device decides what the device is offered.
+type StartRolloutHandler struct {
+	releases  domain.ReleaseRepository
+	groups    domain.DeviceGroupRepository
+	rollouts  domain.RolloutRepository
+	devices   domain.DeviceRepository
+	publisher EventPublisher
+}
+
+func NewStartRolloutHandler(releases domain.ReleaseRepository, groups domain.DeviceGroupRepository, rollouts domain.RolloutRepository, devices domain.DeviceRepository, publisher EventPublisher) *StartRolloutHandler {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if groups == nil {
+		panic("nil DeviceGroupRepository")
+	}
+	if rollouts == nil {
+		panic("nil RolloutRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &StartRolloutHandler{releases: releases, groups: groups, rollouts: rollouts, devices: devices, publisher: publisher}
+}
+
+func (h *StartRolloutHandler) Handle(ctx context.Context, cmd StartRolloutCommand) (*RolloutView, error) {
+	release, err := findReleaseByID(ctx, h.releases, cmd.ReleaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := make([]valueobjects.DeviceGroupID, 0, len(cmd.GroupIDs))
+	for _, id := range cmd.GroupIDs {
+		group, err := findGroupByID(ctx, h.groups, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, id)
+		}
+		staging, err := h.stagingRollout(ctx, group.ID(), release.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if staging != nil {
+			return nil, fmt.Errorf("%w: rollout %s", domain.ErrRolloutInProgress, staging.ID())
+		}
+		groupIDs = append(groupIDs, group.ID())
+	}
+
+	rollout, err := domain.NewRollout(release, groupIDs, cmd.Stages, cmd.StartedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.rollouts.Save(ctx, rollout); err != nil {
+		return nil, fmt.Errorf("failed to save rollout: %w", err)
+	}
+
+	for _, evt := range rollout.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRolloutView(ctx, h.devices, h.rollouts, release, rollout)
+}
+
+// stagingRollout returns the active rollout of the kind targeting the group, if any
+func (h *StartRolloutHandler) stagingRollout(ctx context.Context, groupID valueobjects.DeviceGroupID, kind domain.ReleaseKind) (*domain.Rollout, error) {
+	rollouts, err := h.rollouts.FindByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rollouts {
+		if r.Kind() == kind && r.Status() == domain.RolloutStatusActive {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// AdvanceRolloutHandler moves rollouts to their next stage
+type AdvanceRolloutHandler struct {
+	releases  domain.ReleaseRepository
+	rollouts  domain.RolloutRepository
+	devices   domain.DeviceRepository
+	publisher EventPublisher
+}
+
+func NewAdvanceRolloutHandler(releases domain.ReleaseRepository, rollouts domain.RolloutRepository, devices domain.DeviceRepository, publisher EventPublisher) *AdvanceRolloutHandler {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if rollouts == nil {
+		panic("nil RolloutRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AdvanceRolloutHandler{releases: releases, rollouts: rollouts, devices: devices, publisher: publisher}
+}
+
+func (h *AdvanceRolloutHandler) Handle(ctx context.Context, rolloutID, advancedBy string) (*RolloutView, error) {
+	rollout, err := findRolloutByID(ctx, h.rollouts, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if err := rollout.Advance(advancedBy); err != nil {
+		return nil, err
+	}
+	if err := h.rollouts.Save(ctx, rollout); err != nil {
+		return nil, fmt.Errorf("failed to save rollout: %w", err)
+	}
+
+	for _, evt := range rollout.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	release, err := h.releases.FindByID(ctx, rollout.ReleaseID())
+	if err != nil {
+		return nil, err
+	}
+	return toRolloutView(ctx, h.devices, h.rollouts, release, rollout)
+}
+
+// AbortRolloutCommand is the input DTO for stopping a rollout
+type AbortRolloutCommand struct {
+	RolloutID string
+	Reason    string
+	AbortedBy string
+}
+
+// AbortRolloutHandler stops rollouts. Devices are no longer offered the
+// release; rolling back means rolling an earlier release out.
+type AbortRolloutHandler struct {
+	releases  domain.ReleaseRepository
+	rollouts  domain.RolloutRepository
+	devices   domain.DeviceRepository
+	publisher EventPublisher
+}
+
+func NewAbortRolloutHandler(releases domain.ReleaseRepository, rollouts domain.RolloutRepository, devices domain.DeviceRepository, publisher EventPublisher) *AbortRolloutHandler {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if rollouts == nil {
+		panic("nil RolloutRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &AbortRolloutHandler{releases: releases, rollouts: rollouts, devices: devices, publisher: publisher}
+}
+
+func (h *AbortRolloutHandler) Handle(ctx context.Context, cmd AbortRolloutCommand) (*RolloutView, error) {
+	rollout, err := findRolloutByID(ctx, h.rollouts, cmd.RolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if err := rollout.Abort(cmd.AbortedBy, cmd.Reason); err != nil {
+		return nil, err
+	}
+	if err := h.rollouts.Save(ctx, rollout); err != nil {
+		return nil, fmt.Errorf("failed to save rollout: %w", err)
+	}
+
+	for _, evt := range rollout.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	release, err := h.releases.FindByID(ctx, rollout.ReleaseID())
+	if err != nil {
+		return nil, err
+	}
+	return toRolloutView(ctx, h.devices, h.rollouts, release, rollout)
+}
+
+// RolloutDeviceView is how far one device got with a rollout's release
+type RolloutDeviceView struct {
+	DeviceID  string
+	MachineID string
+	Status    domain.UpdateStatus
+	Detail    string
+	UpdatedAt *time.Time // nil while pending
+}
+
+// RolloutView is a read-only view of a rollout and its progress. Devices
+// lists the devices offered the release so far, and any device that
+// reported on it since.
+type RolloutView struct {
+	ID          string
+	ReleaseID   string
+	Kind        domain.ReleaseKind
+	Version     string
+	GroupIDs    []string
+	Stages      []int
+	Stage       int
+	Percent     int
+	Status      domain.RolloutStatus
+	CreatedBy   string
+	AbortedBy   string
+	AbortReason string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Targeted    int                         // devices in the targeted groups
+	Progress    map[domain.UpdateStatus]int // devices by update status
+	Devices     []RolloutDeviceView
+}
+
+// RolloutQueryService provides read-only access to rollouts and their progress
+type RolloutQueryService struct {
+	releases domain.ReleaseRepository
+	rollouts domain.RolloutRepository
+	devices  domain.DeviceRepository
+}
+
+func NewRolloutQueryService(releases domain.ReleaseRepository, rollouts domain.RolloutRepository, devices domain.DeviceRepository) *RolloutQueryService {
+	if releases == nil {
+		panic("nil ReleaseRepository")
+	}
+	if rollouts == nil {
+		panic("nil RolloutRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	return &RolloutQueryService{releases: releases, rollouts: rollouts, devices: devices}
+}
+
+// List returns every rollout, newest first
+func (s *RolloutQueryService) List(ctx context.Context) ([]RolloutView, error) {
+	rollouts, err := s.rollouts.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]RolloutView, 0, len(rollouts))
+	for _, r := range rollouts {
+		release, err := s.releases.FindByID(ctx, r.ReleaseID())
+		if err != nil {
+			return nil, err
+		}
+		view, err := toRolloutView(ctx, s.devices, s.rollouts, release, r)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+	return views, nil
+}
+
+func (s *RolloutQueryService) FindByID(ctx context.Context, id string) (*RolloutView, error) {
+	rollout, err := findRolloutByID(ctx, s.rollouts, id)
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.releases.FindByID(ctx, rollout.ReleaseID())
+	if err != nil {
+		return nil, err
+	}
+	return toRolloutView(ctx, s.devices, s.rollouts, release, rollout)
+}
+
+// OfferedUpdate is a release a device should download and install
+type OfferedUpdate struct {
+	RolloutID string
+	ReleaseID string
+	Kind      domain.ReleaseKind
+	Version   string
+	SizeBytes int
+	Checksum  string
+}
+
+// offeredUpdates returns the releases offered to the device, at most one of
+// each kind. The newest rollout of a kind targeting the device's group
+// decides: the device is offered its release if the current stage includes
+// it, it does not run the version yet and it has neither installed nor
+// failed it. An aborted newest rollout offers nothing.
+func offeredUpdates(ctx context.Context, rollouts domain.RolloutRepository, releases domain.ReleaseRepository, dev *domain.Device) ([]OfferedUpdate, error) {
+	offers := []OfferedUpdate{}
+	if dev.GroupID() == nil || dev.IsDecommissioned() {
+		return offers, nil
+	}
+	targeting, err := rollouts.FindByGroupID(ctx, *dev.GroupID())
+	if err != nil {
+		return nil, err
+	}
+
+	decided := make(map[domain.ReleaseKind]bool, 2)
+	for _, r := range targeting {
+		if decided[r.Kind()] {
+			continue
+		}
+		decided[r.Kind()] = true
+		if !r.Includes(dev.ID()) {
+			continue
+		}
+
+		release, err := releases.FindByID(ctx, r.ReleaseID())
+		if err != nil {
+			return nil, err
+		}
+		if dev.RunningVersion(release.Kind) == release.Version {
+			continue
+		}
+		update, err := rollouts.FindDeviceUpdate(ctx, r.ID(), dev.ID())
+		switch {
+		case err == nil && update.IsSettled():
+			continue
+		case err != nil && !errors.Is(err, domain.ErrDeviceUpdateNotFound):
+			return nil, err
+		}
+
+		offers = append(offers, OfferedUpdate{
+			RolloutID: r.ID().String(),
+			ReleaseID: release.ID.String(),
+			Kind:      release.Kind,
+			Version:   release.Version,
+			SizeBytes: release.SizeBytes,
+			Checksum:  release.Checksum,
+		})
+	}
+	return offers, nil
+}
+
+func findRolloutByID(ctx context.Context, rollouts domain.RolloutRepository, id string) (*domain.Rollout, error) {
+	rolloutID, err := valueobjects.RolloutIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrRolloutNotFound
+	}
+	return rollouts.FindByID(ctx, rolloutID)
+}
+
+func toRolloutView(ctx context.Context, devices domain.DeviceRepository, rollouts domain.RolloutRepository, release domain.Release, r *domain.Rollout) (*RolloutView, error) {
+	updates, err := rollouts.FindDeviceUpdates(ctx, r.ID())
+	if err != nil {
+		return nil, err
+	}
+	reported := make(map[valueobjects.DeviceID]domain.DeviceUpdate, len(updates))
+	for _, u := range updates {
+		reported[u.DeviceID] = u
+	}
+
+	view := &RolloutView{
+		ID:          r.ID().String(),
+		ReleaseID:   release.ID.String(),
+		Kind:        r.Kind(),
+		Version:     release.Version,
+		Stages:      r.Stages(),
+		Stage:       r.Stage(),
+		Percent:     r.Percent(),
+		Status:      r.Status(),
+		CreatedBy:   r.CreatedBy(),
+		AbortedBy:   r.AbortedBy(),
+		AbortReason: r.AbortReason(),
+		CreatedAt:   r.CreatedAt(),
+		UpdatedAt:   r.UpdatedAt(),
+		Progress:    make(map[domain.UpdateStatus]int),
+		Devices:     []RolloutDeviceView{},
+	}
+	for _, groupID := range r.GroupIDs() {
+		view.GroupIDs = append(view.GroupIDs, groupID.String())
+		members, err := devices.FindByGroupID(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, dev := range members {
+			view.Targeted++
+			device := RolloutDeviceView{DeviceID: dev.ID().String(), MachineID: dev.MachineID(), Status: domain.UpdateStatusPending}
+			if u, ok := reported[dev.ID()]; ok {
+				device.Status = u.Status
+				device.Detail = u.Detail
+				updatedAt := u.UpdatedAt
+				device.UpdatedAt = &updatedAt
+			} else if dev.RunningVersion(r.Kind()) == release.Version {
+				// Already on the version, e.g. installed by hand
+				device.Status = domain.UpdateStatusInstalled
+			} else if !r.Includes(dev.ID()) {
+				continue
+			}
+			view.Progress[device.Status]++
+			view.Devices = append(view.Devices, device)
+		}
+	}
+	return view, nil
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// RecordScaleCalibrationCommand is a scale calibration the device just made.
+// The readings are the ones found before the scale was zeroed and corrected.
+type RecordScaleCalibrationCommand struct {
+	MachineID    string
+	DeviceKey    string
+	ZeroGrams    float64
+	Readings     []domain.ScaleReading
+	CalibratedAt time.Time // zero means now; devices calibrating offline upload later
+}
+
+// ScaleCalibrationView is a read-only view of one scale calibration
+type ScaleCalibrationView struct {
+	ID           string
+	ZeroGrams    float64
+	Readings     []domain.ScaleReading
+	SpanFactor   float64
+	ErrorGrams   float64
+	CalibratedAt time.Time
+}
+
+func toScaleCalibrationView(c *domain.ScaleCalibration) ScaleCalibrationView {
+	return ScaleCalibrationView{
+		ID:           c.ID().String(),
+		ZeroGrams:    c.ZeroGrams(),
+		Readings:     c.Readings(),
+		SpanFactor:   c.SpanFactor(),
+		ErrorGrams:   c.ErrorGrams(),
+		CalibratedAt: c.CalibratedAt(),
+	}
+}
+
+// RecordScaleCalibrationHandler adds a calibration to the scale history of a
+// device. The device authenticates with its own key.
+type RecordScaleCalibrationHandler struct {
+	devices      domain.DeviceRepository
+	calibrations domain.ScaleCalibrationRepository
+	publisher    EventPublisher
+}
+
+func NewRecordScaleCalibrationHandler(devices domain.DeviceRepository, calibrations domain.ScaleCalibrationRepository, publisher EventPublisher) *RecordScaleCalibrationHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if calibrations == nil {
+		panic("nil ScaleCalibrationRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RecordScaleCalibrationHandler{devices: devices, calibrations: calibrations, publisher: publisher}
+}
+
+func (h *RecordScaleCalibrationHandler) Handle(ctx context.Context, cmd RecordScaleCalibrationCommand) (ScaleCalibrationView, error) {
+	dev, err := authenticateDevice(ctx, h.devices, cmd.MachineID, cmd.DeviceKey)
+	if err != nil {
+		return ScaleCalibrationView{}, err
+	}
+	if dev.IsDecommissioned() {
+		return ScaleCalibrationView{}, domain.ErrDeviceDecommissioned
+	}
+
+	now := time.Now()
+	at := cmd.CalibratedAt
+	if at.IsZero() {
+		at = now
+	} else if at.After(now.Add(maxDeviceClockSkew)) {
+		return ScaleCalibrationView{}, domain.ErrFutureScaleCalibration
+	}
+
+	calibration, err := domain.NewScaleCalibration(dev.ID(), cmd.ZeroGrams, cmd.Readings, at)
+	if err != nil {
+		return ScaleCalibrationView{}, err
+	}
+
+	if err := h.calibrations.Save(ctx, calibration); err != nil {
+		return ScaleCalibrationView{}, fmt.Errorf("failed to save scale calibration: %w", err)
+	}
+
+	for _, evt := range calibration.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toScaleCalibrationView(calibration), nil
+}
+
+// ScaleCalibrationHistoryView is the scale calibration history of a device
+// and the drift it shows
+type ScaleCalibrationHistoryView struct {
+	DeviceID         string
+	MachineID        string
+	Calibrations     []ScaleCalibrationView // newest first
+	DriftGramsPerDay float64
+	RecalibrateBy    *time.Time
+	RecalibrationDue bool
+}
+
+// ScaleCalibrationQueryService reads device scale calibrations
+type ScaleCalibrationQueryService struct {
+	devices      domain.DeviceRepository
+	calibrations domain.ScaleCalibrationRepository
+}
+
+func NewScaleCalibrationQueryService(devices domain.DeviceRepository, calibrations domain.ScaleCalibrationRepository) *ScaleCalibrationQueryService {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if calibrations == nil {
+		panic("nil ScaleCalibrationRepository")
+	}
+	return &ScaleCalibrationQueryService{devices: devices, calibrations: calibrations}
+}
+
+// History returns the device's latest limit scale calibrations and the drift
+// measured over them, so operators can recalibrate before weight checks fail
+func (s *ScaleCalibrationQueryService) History(ctx context.Context, deviceID string, limit int) (ScaleCalibrationHistoryView, error) {
+	dev, err := findDeviceByID(ctx, s.devices, deviceID)
+	if err != nil {
+		return ScaleCalibrationHistoryView{}, err
+	}
+
+	calibrations, err := s.calibrations.FindByDeviceID(ctx, dev.ID(), limit)
+	if err != nil {
+		return ScaleCalibrationHistoryView{}, err
+	}
+
+	views := make([]ScaleCalibrationView, 0, len(calibrations))
+	for _, c := range calibrations {
+		views = append(views, toScaleCalibrationView(c))
+	}
+
+	drift := domain.MeasureScaleDrift(calibrations)
+	return ScaleCalibrationHistoryView{
+		DeviceID:         dev.ID().String(),
+		MachineID:        dev.MachineID(),
+		Calibrations:     views,
+		DriftGramsPerDay: drift.GramsPerDay,
+		RecalibrateBy:    drift.RecalibrateBy,
+		RecalibrationDue: drift.RecalibrateBy != nil && !time.Now().Before(*drift.RecalibrateBy),
+	}, nil
+}
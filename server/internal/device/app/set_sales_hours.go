@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// SetSalesHoursCommand is the input DTO for configuring a device's opening hours and blackouts
+type SetSalesHoursCommand struct {
+	MachineID    string
+	Timezone     string
+	OpeningHours []domain.SalesWindow
+	Blackouts    []domain.SalesBlackout
+}
+
+// SetSalesHoursHandler sets or replaces the sales hours of a device
+type SetSalesHoursHandler struct {
+	devices   domain.DeviceRepository
+	hours     domain.SalesHoursRepository
+	publisher EventPublisher
+}
+
+func NewSetSalesHoursHandler(devices domain.DeviceRepository, hours domain.SalesHoursRepository, publisher EventPublisher) *SetSalesHoursHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if hours == nil {
+		panic("nil SalesHoursRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &SetSalesHoursHandler{
+		devices:   devices,
+		hours:     hours,
+		publisher: publisher,
+	}
+}
+
+func (h *SetSalesHoursHandler) Handle(ctx context.Context, cmd SetSalesHoursCommand) (*SalesHoursView, error) {
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := h.hours.FindByDeviceID(ctx, dev.ID())
+	switch {
+	case errors.Is(err, domain.ErrSalesHoursNotFound):
+		hours, err = domain.NewSalesHours(dev.ID(), cmd.Timezone, cmd.OpeningHours, cmd.Blackouts)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := hours.Replace(cmd.Timezone, cmd.OpeningHours, cmd.Blackouts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := h.hours.Save(ctx, hours); err != nil {
+		return nil, fmt.Errorf("failed to save sales hours: %w", err)
+	}
+
+	for _, evt := range hours.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toSalesHoursView(dev, hours, true, time.Now())
+	return &view, nil
+}
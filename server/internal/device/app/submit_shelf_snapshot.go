@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// ErrShelfDetectionUnavailable is returned when no detection backend is configured
+var ErrShelfDetectionUnavailable = errors.New("shelf detection is unavailable")
+
+// ShelfDetection is a single object found on a shelf snapshot
+type ShelfDetection struct {
+	SKUCode    string
+	Confidence float64
+	Shelf      int // shelf the object sits on, numbered from 1 top down; 0 when unknown
+}
+
+// ShelfDetectionResult is the cloud detector's answer for one snapshot
+type ShelfDetectionResult struct {
+	Detections   []ShelfDetection
+	ModelVersion string
+}
+
+// ShelfDetector is an output port for running cloud detection on a shelf snapshot
+type ShelfDetector interface {
+	DetectShelf(ctx context.Context, deviceID string, image []byte) (ShelfDetectionResult, error)
+}
+
+// SubmitShelfSnapshotCommand is the input DTO for a shelf snapshot taken outside a session
+type SubmitShelfSnapshotCommand struct {
+	MachineID  string
+	Image      []byte
+	CapturedAt time.Time // zero means now
+}
+
+// SubmitShelfSnapshotHandler turns a shelf snapshot into a per-SKU stock estimate
+type SubmitShelfSnapshotHandler struct {
+	devices       domain.DeviceRepository
+	estimates     domain.StockEstimateRepository
+	detector      ShelfDetector
+	publisher     EventPublisher
+	resolver      *PolicyResolver
+	minConfidence float64
+	lowThreshold  int
+}
+
+func NewSubmitShelfSnapshotHandler(
+	devices domain.DeviceRepository,
+	estimates domain.StockEstimateRepository,
+	detector ShelfDetector,
+	publisher EventPublisher,
+	resolver *PolicyResolver,
+	minConfidence float64,
+	lowThreshold int,
+) *SubmitShelfSnapshotHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if estimates == nil {
+		panic("nil StockEstimateRepository")
+	}
+	if detector == nil {
+		panic("nil ShelfDetector")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	if resolver == nil {
+		panic("nil PolicyResolver")
+	}
+	return &SubmitShelfSnapshotHandler{
+		devices:       devices,
+		estimates:     estimates,
+		detector:      detector,
+		publisher:     publisher,
+		resolver:      resolver,
+		minConfidence: minConfidence,
+		lowThreshold:  lowThreshold,
+	}
+}
+
+func (h *SubmitShelfSnapshotHandler) Handle(ctx context.Context, cmd SubmitShelfSnapshotCommand) (*StockEstimateView, error) {
+	if len(cmd.Image) == 0 {
+		return nil, domain.ErrEmptySnapshot
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	if err != nil {
+		return nil, err
+	}
+	// Blocked devices keep reporting stock; only decommissioned ones are refused
+	if dev.Status() == domain.DeviceStatusInactive {
+		return nil, domain.ErrDeviceInactive
+	}
+
+	estimate, err := h.estimates.FindByDeviceID(ctx, dev.ID())
+	if errors.Is(err, domain.ErrStockEstimateNotFound) {
+		estimate = domain.NewStockEstimate(dev.ID())
+	} else if err != nil {
+		return nil, err
+	}
+
+	minConfidence, err := h.resolver.ConfidenceThreshold(ctx, dev, h.minConfidence)
+	if err != nil {
+		return nil, err
+	}
+	result, err := h.detector.DetectShelf(ctx, dev.ID().String(), cmd.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, d := range result.Detections {
+		if d.SKUCode != "" && d.Confidence >= minConfidence {
+			counts[d.SKUCode]++
+		}
+	}
+
+	capturedAt := cmd.CapturedAt
+	if capturedAt.IsZero() {
+		capturedAt = time.Now().UTC()
+	}
+	if err := estimate.Observe(counts, result.ModelVersion, capturedAt, h.lowThreshold); err != nil {
+		return nil, err
+	}
+
+	if err := h.estimates.Save(ctx, estimate); err != nil {
+		return nil, fmt.Errorf("failed to save stock estimate: %w", err)
+	}
+
+	for _, evt := range estimate.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toStockEstimateView(dev, estimate, h.lowThreshold)
+	return &view, nil
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// SalesReader is an output port for the units the transaction context sold on a device
+type SalesReader interface {
+	// SoldUnits counts the units of each SKU sold since the given time for that SKU
+	SoldUnits(ctx context.Context, deviceID string, since map[string]time.Time) (map[string]int, error)
+}
+
+// WriteOffBatchCommand is the input DTO for removing what is left of a batch as waste
+type WriteOffBatchCommand struct {
+	BatchID      string
+	WrittenOffBy string
+}
+
+// WriteOffBatchHandler closes a batch, typically once it has expired, and
+// records its remaining units for the waste report
+type WriteOffBatchHandler struct {
+	devices   domain.DeviceRepository
+	batches   domain.StockBatchRepository
+	sales     SalesReader
+	publisher EventPublisher
+}
+
+func NewWriteOffBatchHandler(devices domain.DeviceRepository, batches domain.StockBatchRepository, sales SalesReader, publisher EventPublisher) *WriteOffBatchHandler {
+	if devices == nil {
+		panic("nil DeviceRepository")
+	}
+	if batches == nil {
+		panic("nil StockBatchRepository")
+	}
+	if sales == nil {
+		panic("nil SalesReader")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &WriteOffBatchHandler{
+		devices:   devices,
+		batches:   batches,
+		sales:     sales,
+		publisher: publisher,
+	}
+}
+
+func (h *WriteOffBatchHandler) Handle(ctx context.Context, cmd WriteOffBatchCommand) (*BatchView, error) {
+	if cmd.WrittenOffBy == "" {
+		return nil, domain.ErrWrittenOffByRequired
+	}
+
+	batchID, err := valueobjects.BatchIDFrom(cmd.BatchID)
+	if err != nil {
+		return nil, domain.ErrBatchNotFound
+	}
+	batch, err := h.batches.FindByID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !batch.IsOpen() {
+		return nil, domain.ErrBatchWrittenOff
+	}
+	dev, err := h.devices.FindByID(ctx, batch.DeviceID())
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := batchLevels(ctx, h.batches, h.sales, dev.ID())
+	if err != nil {
+		return nil, err
+	}
+	remaining := 0
+	for _, l := range levels {
+		if l.Batch.ID() == batch.ID() {
+			remaining = l.Remaining
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := batch.WriteOff(remaining, cmd.WrittenOffBy, now); err != nil {
+		return nil, err
+	}
+	if err := h.batches.Save(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to save stock batch: %w", err)
+	}
+
+	for _, evt := range batch.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	view := toBatchView(dev, domain.BatchLevel{Batch: batch}, now, policy.NoMarkdowns())
+	return &view, nil
+}
+
+// batchLevels estimates the units left of each open batch on a device from
+// the sales recorded since the batches were loaded
+func batchLevels(ctx context.Context, batches domain.StockBatchRepository, sales SalesReader, deviceID valueobjects.DeviceID) ([]domain.BatchLevel, error) {
+	open, err := batches.FindOpenByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return levelOpenBatches(ctx, sales, deviceID, open)
+}
+
+// levelOpenBatches estimates the units left of a device's open batches
+func levelOpenBatches(ctx context.Context, sales SalesReader, deviceID valueobjects.DeviceID, open []*domain.StockBatch) ([]domain.BatchLevel, error) {
+	if len(open) == 0 {
+		return nil, nil
+	}
+
+	sold, err := sales.SoldUnits(ctx, deviceID.String(), domain.OldestRestock(open))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sales: %w", err)
+	}
+	return domain.LevelBatches(open, sold), nil
+}
+
+// deviceToday is the current date in the device's sales hours time zone,
+// or UTC when it has none
+func deviceToday(ctx context.Context, hours domain.SalesHoursRepository, deviceID valueobjects.DeviceID, at time.Time) (time.Time, error) {
+	h, err := hours.FindByDeviceID(ctx, deviceID)
+	switch {
+	case errors.Is(err, domain.ErrSalesHoursNotFound):
+		return at.UTC(), nil
+	case err != nil:
+		return time.Time{}, err
+	}
+	return at.In(h.Location()), nil
+}
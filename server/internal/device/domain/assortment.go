@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// Assortment is the part of the catalog a device stocks. A device without
+// assigned SKUs may sell the whole catalog; once SKUs are assigned, detecting
+// any other SKU in it is suspicious.
+type Assortment struct {
+	deviceID  valueobjects.DeviceID
+	skuCodes  map[string]bool
+	updatedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewAssortment creates an empty assortment for a device
+func NewAssortment(deviceID valueobjects.DeviceID) *Assortment {
+	return &Assortment{
+		deviceID: deviceID,
+		skuCodes: make(map[string]bool),
+	}
+}
+
+// ReconstituteAssortment rebuilds an Assortment from persistence
+func ReconstituteAssortment(deviceID valueobjects.DeviceID, skuCodes []string, updatedAt time.Time) *Assortment {
+	a := NewAssortment(deviceID)
+	for _, code := range skuCodes {
+		a.skuCodes[code] = true
+	}
+	a.updatedAt = updatedAt
+	return a
+}
+
+// Getters
+func (a *Assortment) DeviceID() valueobjects.DeviceID { return a.deviceID }
+func (a *Assortment) UpdatedAt() time.Time            { return a.updatedAt }
+
+// Includes reports whether the SKU is assigned to the device
+func (a *Assortment) Includes(skuCode string) bool {
+	return a.skuCodes[skuCode]
+}
+
+// SKUCodes lists the assigned SKUs, sorted
+func (a *Assortment) SKUCodes() []string {
+	codes := make([]string, 0, len(a.skuCodes))
+	for code := range a.skuCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Business methods
+
+// Assign adds SKUs to the device; SKUs already assigned are left as they are
+func (a *Assortment) Assign(skuCodes []string) error {
+	if len(skuCodes) == 0 {
+		return ErrInvalidAssignment
+	}
+	var added []string
+	for _, code := range skuCodes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			return ErrInvalidAssignment
+		}
+		if !a.skuCodes[code] {
+			a.skuCodes[code] = true
+			added = append(added, code)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	a.updatedAt = time.Now().UTC()
+	sort.Strings(added)
+	a.domainEvents = append(a.domainEvents, NewSKUsAssigned(a.deviceID, added))
+	return nil
+}
+
+// Unassign takes a SKU out of the device's assortment
+func (a *Assortment) Unassign(skuCode string) error {
+	if !a.skuCodes[skuCode] {
+		return ErrSKUNotAssigned
+	}
+	delete(a.skuCodes, skuCode)
+	a.updatedAt = time.Now().UTC()
+	a.domainEvents = append(a.domainEvents, NewSKUUnassigned(a.deviceID, skuCode))
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (a *Assortment) PullEvents() []events.DomainEvent {
+	evts := a.domainEvents
+	a.domainEvents = nil
+	return evts
+}
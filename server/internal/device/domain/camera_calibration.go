@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const (
+	maxReferenceMarkers = 64
+	maxMarkerIDLength   = 50
+	// minHomographyDeterminant tells an invertible homography from a
+	// degenerate one that collapses the shelf onto a line or a point
+	minHomographyDeterminant = 1e-12
+)
+
+// Homography maps camera pixels onto the shelf plane, row-major 3x3
+type Homography [9]float64
+
+// NewHomography validates a row-major 3x3 homography
+func NewHomography(values []float64) (Homography, error) {
+	var h Homography
+	if len(values) != len(h) {
+		return Homography{}, ErrInvalidHomography
+	}
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return Homography{}, ErrInvalidHomography
+		}
+		h[i] = v
+	}
+
+	det := h[0]*(h[4]*h[8]-h[5]*h[7]) - h[1]*(h[3]*h[8]-h[5]*h[6]) + h[2]*(h[3]*h[7]-h[4]*h[6])
+	if math.Abs(det) < minHomographyDeterminant {
+		return Homography{}, ErrInvalidHomography
+	}
+	return h, nil
+}
+
+// ReferenceMarker is a fiducial on the shelf and where the camera saw it
+// when calibrating, so the device can tell later whether the camera moved
+type ReferenceMarker struct {
+	ID string
+	X  float64 // pixels from the left of the frame
+	Y  float64 // pixels from the top of the frame
+}
+
+// CameraCalibration is the latest calibration a device uploaded for its shelf
+// camera. It is kept on the server so the device finds it again after it is
+// reimaged instead of calibrating from scratch.
+type CameraCalibration struct {
+	deviceID     valueobjects.DeviceID
+	homography   Homography
+	markers      []ReferenceMarker
+	capture      CameraSettings // the settings the frames were captured with
+	version      int
+	calibratedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewCameraCalibration is the calibration store of a device that never uploaded one
+func NewCameraCalibration(deviceID valueobjects.DeviceID) *CameraCalibration {
+	return &CameraCalibration{deviceID: deviceID}
+}
+
+// ReconstituteCameraCalibration rebuilds a CameraCalibration from persistence
+func ReconstituteCameraCalibration(
+	deviceID valueobjects.DeviceID,
+	homography Homography,
+	markers []ReferenceMarker,
+	capture CameraSettings,
+	version int,
+	calibratedAt time.Time,
+) *CameraCalibration {
+	return &CameraCalibration{
+		deviceID:     deviceID,
+		homography:   homography,
+		markers:      markers,
+		capture:      capture,
+		version:      version,
+		calibratedAt: calibratedAt,
+	}
+}
+
+// Getters
+func (c *CameraCalibration) DeviceID() valueobjects.DeviceID { return c.deviceID }
+func (c *CameraCalibration) Homography() Homography          { return c.homography }
+func (c *CameraCalibration) Capture() CameraSettings         { return c.capture }
+func (c *CameraCalibration) Version() int                    { return c.version }
+func (c *CameraCalibration) CalibratedAt() time.Time         { return c.calibratedAt }
+
+// Markers returns a copy of the reference markers
+func (c *CameraCalibration) Markers() []ReferenceMarker {
+	markers := make([]ReferenceMarker, len(c.markers))
+	copy(markers, c.markers)
+	return markers
+}
+
+// Business methods
+
+// Record replaces the calibration with one the device just made; every
+// upload gets a new version
+func (c *CameraCalibration) Record(homography Homography, markers []ReferenceMarker, capture CameraSettings, at time.Time) error {
+	if len(markers) > maxReferenceMarkers {
+		return ErrTooManyMarkers
+	}
+	seen := make(map[string]bool, len(markers))
+	normalized := make([]ReferenceMarker, 0, len(markers))
+	for _, m := range markers {
+		m.ID = strings.TrimSpace(m.ID)
+		if m.ID == "" || len(m.ID) > maxMarkerIDLength || seen[m.ID] {
+			return ErrInvalidMarker
+		}
+		if math.IsNaN(m.X) || math.IsInf(m.X, 0) || math.IsNaN(m.Y) || math.IsInf(m.Y, 0) {
+			return ErrInvalidMarker
+		}
+		seen[m.ID] = true
+		normalized = append(normalized, m)
+	}
+	capture, err := capture.normalize()
+	if err != nil {
+		return err
+	}
+
+	c.homography = homography
+	c.markers = normalized
+	c.capture = capture
+	c.version++
+	c.calibratedAt = at.UTC()
+	c.domainEvents = append(c.domainEvents, NewCameraCalibrated(c.deviceID, c.version))
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (c *CameraCalibration) PullEvents() []events.DomainEvent {
+	evts := c.domainEvents
+	c.domainEvents = nil
+	return evts
+}
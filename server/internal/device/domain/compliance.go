@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DeviationKind classifies a difference between a shelf and its planogram
+type DeviationKind string
+
+const (
+	DeviationMissing   DeviationKind = "missing"   // fewer facings than planned on the shelf
+	DeviationMisplaced DeviationKind = "misplaced" // SKU planned on another shelf
+	DeviationUnplanned DeviationKind = "unplanned" // SKU not in the planogram at all
+)
+
+// Deviation is one line of a misplacement report
+type Deviation struct {
+	Shelf    int
+	SKUCode  string
+	Kind     DeviationKind
+	Expected int
+	Found    int
+}
+
+// ComplianceReport is the result of checking one restock visit against the
+// device's planogram. The score is the percentage of planned facings found in
+// place, with every unit stocked where the planogram does not put it counted
+// against it.
+type ComplianceReport struct {
+	id               valueobjects.ComplianceReportID
+	deviceID         valueobjects.DeviceID
+	planogramVersion int
+	visitedBy        string
+	score            int
+	deviations       []Deviation
+	checkedAt        time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// CheckCompliance compares the facings found on a restock snapshot with the planogram
+func CheckCompliance(planogram *Planogram, found map[ShelfSKU]int, visitedBy string, checkedAt time.Time) (*ComplianceReport, error) {
+	visitedBy = strings.TrimSpace(visitedBy)
+	if visitedBy == "" {
+		return nil, ErrVisitedByRequired
+	}
+
+	expected := planogram.Expected()
+	plannedSKUs := make(map[string]bool, len(expected))
+	plannedTotal := 0
+	for key, qty := range expected {
+		plannedSKUs[key.SKUCode] = true
+		plannedTotal += qty
+	}
+
+	var deviations []Deviation
+	matched, extra := 0, 0
+	for key, want := range expected {
+		got := found[key]
+		matched += min(want, got)
+		if got < want {
+			deviations = append(deviations, Deviation{Shelf: key.Shelf, SKUCode: key.SKUCode, Kind: DeviationMissing, Expected: want, Found: got})
+		}
+	}
+	for key, got := range found {
+		if _, planned := expected[key]; planned || got <= 0 {
+			continue
+		}
+		extra += got
+		kind := DeviationUnplanned
+		if plannedSKUs[key.SKUCode] {
+			kind = DeviationMisplaced
+		}
+		deviations = append(deviations, Deviation{Shelf: key.Shelf, SKUCode: key.SKUCode, Kind: kind, Found: got})
+	}
+	sort.Slice(deviations, func(i, j int) bool {
+		if deviations[i].Shelf != deviations[j].Shelf {
+			return deviations[i].Shelf < deviations[j].Shelf
+		}
+		return deviations[i].SKUCode < deviations[j].SKUCode
+	})
+
+	score := 0
+	if total := plannedTotal + extra; total > 0 {
+		score = matched * 100 / total
+	}
+
+	r := &ComplianceReport{
+		id:               valueobjects.NewComplianceReportID(),
+		deviceID:         planogram.DeviceID(),
+		planogramVersion: planogram.Version(),
+		visitedBy:        visitedBy,
+		score:            score,
+		deviations:       deviations,
+		checkedAt:        checkedAt.UTC(),
+	}
+
+	r.domainEvents = append(r.domainEvents, NewPlanogramComplianceChecked(r.id, r.deviceID, score, len(deviations)))
+
+	return r, nil
+}
+
+// ReconstituteComplianceReport rebuilds a ComplianceReport from persistence
+func ReconstituteComplianceReport(
+	id valueobjects.ComplianceReportID,
+	deviceID valueobjects.DeviceID,
+	planogramVersion int,
+	visitedBy string,
+	score int,
+	deviations []Deviation,
+	checkedAt time.Time,
+) *ComplianceReport {
+	return &ComplianceReport{
+		id:               id,
+		deviceID:         deviceID,
+		planogramVersion: planogramVersion,
+		visitedBy:        visitedBy,
+		score:            score,
+		deviations:       deviations,
+		checkedAt:        checkedAt,
+	}
+}
+
+// Getters
+func (r *ComplianceReport) ID() valueobjects.ComplianceReportID { return r.id }
+func (r *ComplianceReport) DeviceID() valueobjects.DeviceID     { return r.deviceID }
+func (r *ComplianceReport) PlanogramVersion() int               { return r.planogramVersion }
+func (r *ComplianceReport) VisitedBy() string                   { return r.visitedBy }
+func (r *ComplianceReport) Score() int                          { return r.score }
+func (r *ComplianceReport) CheckedAt() time.Time                { return r.checkedAt }
+func (r *ComplianceReport) IsCompliant() bool                   { return len(r.deviations) == 0 }
+
+// Deviations returns a copy of the misplacement report, ordered by shelf then SKU code
+func (r *ComplianceReport) Deviations() []Deviation {
+	return append([]Deviation(nil), r.deviations...)
+}
+
+// PullEvents returns and clears domain events
+func (r *ComplianceReport) PullEvents() []events.DomainEvent {
+	evts := r.domainEvents
+	r.domainEvents = nil
+	return evts
+}
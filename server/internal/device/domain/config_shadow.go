@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const (
+	minSyncInterval = 10 * time.Second
+	maxSyncInterval = 24 * time.Hour
+	maxFrameRate    = 120
+)
+
+var resolutionPattern = regexp.MustCompile(`^[1-9][0-9]{1,4}x[1-9][0-9]{1,4}$`)
+
+// CameraSettings configure the device's shelf camera; zero fields keep what
+// the device runs with
+type CameraSettings struct {
+	Resolution     string // width x height, e.g. "1920x1080"
+	FrameRate      int    // frames per second
+	ExposureMicros int    // 0 leaves exposure to the camera
+}
+
+// normalize validates the settings and trims the resolution
+func (c CameraSettings) normalize() (CameraSettings, error) {
+	c.Resolution = strings.TrimSpace(c.Resolution)
+	if c.Resolution != "" && !resolutionPattern.MatchString(c.Resolution) {
+		return CameraSettings{}, ErrInvalidCameraSettings
+	}
+	if c.FrameRate < 0 || c.FrameRate > maxFrameRate || c.ExposureMicros < 0 {
+		return CameraSettings{}, ErrInvalidCameraSettings
+	}
+	return c, nil
+}
+
+// DeviceConfig is the configuration pushed to a device. Zero fields are not
+// managed by the server and keep the device's own defaults.
+type DeviceConfig struct {
+	ConfidenceThreshold *float64 // on-device minimum detection confidence, 0-1
+	SyncIntervalSeconds int      // seconds between syncs with the server
+	Camera              CameraSettings
+}
+
+// NewDeviceConfig validates a configuration
+func NewDeviceConfig(confidenceThreshold *float64, syncIntervalSeconds int, camera CameraSettings) (DeviceConfig, error) {
+	if confidenceThreshold != nil && (*confidenceThreshold < 0 || *confidenceThreshold > 1) {
+		return DeviceConfig{}, ErrInvalidConfidenceThreshold
+	}
+	if interval := time.Duration(syncIntervalSeconds) * time.Second; syncIntervalSeconds != 0 && (interval < minSyncInterval || interval > maxSyncInterval) {
+		return DeviceConfig{}, ErrInvalidSyncInterval
+	}
+	camera, err := camera.normalize()
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	return DeviceConfig{ConfidenceThreshold: confidenceThreshold, SyncIntervalSeconds: syncIntervalSeconds, Camera: camera}, nil
+}
+
+// WithPolicy fills in the confidence threshold of the device's policy when
+// the configuration sets none, so the device detects like the server checks
+func (c DeviceConfig) WithPolicy(policy ResolvedPolicy) DeviceConfig {
+	if c.ConfidenceThreshold == nil {
+		c.ConfidenceThreshold = policy.ConfidenceThreshold
+	}
+	return c
+}
+
+// settings lists the managed settings by name, formatted for comparison
+func (c DeviceConfig) settings() []configSetting {
+	var threshold string
+	if c.ConfidenceThreshold != nil {
+		threshold = strconv.FormatFloat(*c.ConfidenceThreshold, 'f', -1, 64)
+	}
+	return []configSetting{
+		{"confidence_threshold", threshold},
+		{"sync_interval_seconds", formatNonZero(c.SyncIntervalSeconds)},
+		{"camera.resolution", c.Camera.Resolution},
+		{"camera.frame_rate", formatNonZero(c.Camera.FrameRate)},
+		{"camera.exposure_micros", formatNonZero(c.Camera.ExposureMicros)},
+	}
+}
+
+type configSetting struct {
+	name  string
+	value string // empty when unset
+}
+
+func formatNonZero(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// ConfigStatus tells whether a device runs its desired configuration
+type ConfigStatus string
+
+const (
+	ConfigStatusInSync  ConfigStatus = "in_sync"
+	ConfigStatusPending ConfigStatus = "pending" // the device has not applied the latest version yet
+	ConfigStatusDrifted ConfigStatus = "drifted" // the device applied it, but runs other values
+)
+
+// ConfigDrift is a managed setting the device runs with another value
+type ConfigDrift struct {
+	Setting  string
+	Desired  string
+	Reported string // empty when the device did not report the setting
+}
+
+// ConfigState compares the desired configuration with what the device reports
+type ConfigState struct {
+	Status ConfigStatus
+	Drift  []ConfigDrift
+}
+
+// ConfigShadow holds the configuration the server wants a device to run and
+// the configuration the device last reported having applied. Every change
+// of the desired configuration gets a new version, which the device echoes
+// back in its report.
+type ConfigShadow struct {
+	deviceID        valueobjects.DeviceID
+	desired         DeviceConfig
+	version         int
+	desiredBy       string
+	desiredAt       time.Time
+	reported        *DeviceConfig
+	reportedVersion int
+	reportedAt      *time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewConfigShadow is the shadow of a device no configuration was set for
+func NewConfigShadow(deviceID valueobjects.DeviceID) *ConfigShadow {
+	return &ConfigShadow{deviceID: deviceID}
+}
+
+// ReconstituteConfigShadow rebuilds a ConfigShadow from persistence
+func ReconstituteConfigShadow(
+	deviceID valueobjects.DeviceID,
+	desired DeviceConfig,
+	version int,
+	desiredBy string,
+	desiredAt time.Time,
+	reported *DeviceConfig,
+	reportedVersion int,
+	reportedAt *time.Time,
+) *ConfigShadow {
+	return &ConfigShadow{
+		deviceID:        deviceID,
+		desired:         desired,
+		version:         version,
+		desiredBy:       desiredBy,
+		desiredAt:       desiredAt,
+		reported:        reported,
+		reportedVersion: reportedVersion,
+		reportedAt:      reportedAt,
+	}
+}
+
+// Getters
+func (s *ConfigShadow) DeviceID() valueobjects.DeviceID { return s.deviceID }
+func (s *ConfigShadow) Desired() DeviceConfig           { return s.desired }
+func (s *ConfigShadow) Version() int                    { return s.version }
+func (s *ConfigShadow) DesiredBy() string               { return s.desiredBy }
+func (s *ConfigShadow) DesiredAt() time.Time            { return s.desiredAt }
+func (s *ConfigShadow) Reported() *DeviceConfig         { return s.reported }
+func (s *ConfigShadow) ReportedVersion() int            { return s.reportedVersion }
+func (s *ConfigShadow) ReportedAt() *time.Time          { return s.reportedAt }
+
+// Business methods
+
+// SetDesired replaces the configuration the device should run
+func (s *ConfigShadow) SetDesired(config DeviceConfig, by string, at time.Time) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrConfiguredByRequired
+	}
+
+	s.desired = config
+	s.version++
+	s.desiredBy = by
+	s.desiredAt = at.UTC()
+	s.domainEvents = append(s.domainEvents, NewDeviceConfigChanged(s.deviceID, s.version, by))
+	return nil
+}
+
+// Report records the configuration the device applied and the version of
+// the desired configuration it applied it from
+func (s *ConfigShadow) Report(config DeviceConfig, version int, at time.Time) error {
+	if version < 0 || version > s.version {
+		return ErrUnknownConfigVersion
+	}
+
+	s.reported = &config
+	s.reportedVersion = version
+	at = at.UTC()
+	s.reportedAt = &at
+	return nil
+}
+
+// Compare checks the reported configuration against the desired one, which
+// callers may have completed (see DeviceConfig.WithPolicy). Only settings the
+// desired configuration sets are compared.
+func (s *ConfigShadow) Compare(desired DeviceConfig) ConfigState {
+	var reported []configSetting
+	if s.reported != nil {
+		reported = s.reported.settings()
+	}
+
+	state := ConfigState{Status: ConfigStatusInSync, Drift: []ConfigDrift{}}
+	for i, setting := range desired.settings() {
+		if setting.value == "" {
+			continue
+		}
+		var value string
+		if reported != nil {
+			value = reported[i].value
+		}
+		if value != setting.value {
+			state.Drift = append(state.Drift, ConfigDrift{Setting: setting.name, Desired: setting.value, Reported: value})
+		}
+	}
+
+	switch {
+	case s.reportedVersion < s.version:
+		state.Status = ConfigStatusPending
+	case len(state.Drift) > 0:
+		state.Status = ConfigStatusDrifted
+	}
+	return state
+}
+
+// PullEvents returns and clears domain events
+func (s *ConfigShadow) PullEvents() []events.DomainEvent {
+	evts := s.domainEvents
+	s.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Decommissioning records when and by whom a device was taken out of service
+type Decommissioning struct {
+	At time.Time
+	By string
+}
+
+// Decommission takes the device out of service for good: it stops selling,
+// its key stops working and registering its machine ID again needs an
+// operator override
+func (d *Device) Decommission(by string, at time.Time) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrDecommissionedByRequired
+	}
+	if d.decommissioned != nil {
+		return ErrDeviceDecommissioned
+	}
+
+	d.decommissioned = &Decommissioning{At: at.UTC(), By: by}
+	d.status = DeviceStatusDecommissioned
+	d.maintenance = nil
+	d.overTempSince = nil
+	d.door = DoorState{}
+	d.verificationRequiredSince = nil
+	d.keyHash = nil
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceDecommissioned(d.id, d.machineID, by))
+
+	return nil
+}
+
+// Recommission puts a decommissioned device back into service under its
+// machine ID, as a newly installed machine with the given name and location;
+// empty ones keep the old
+func (d *Device) Recommission(by, name, location string) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrOverrideByRequired
+	}
+	if d.decommissioned == nil {
+		return ErrDeviceNotDecommissioned
+	}
+
+	d.decommissioned = nil
+	d.status = DeviceStatusActive
+	if name = strings.TrimSpace(name); name != "" {
+		d.name = name
+	}
+	if location = strings.TrimSpace(location); location != "" {
+		d.location = location
+	}
+	d.liveness = Liveness{}
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceRecommissioned(d.id, d.machineID, by))
+
+	return nil
+}
+
+// Decommissioned is set once the device is taken out of service
+func (d *Device) Decommissioned() *Decommissioning { return d.decommissioned }
+
+func (d *Device) IsDecommissioned() bool { return d.decommissioned != nil }
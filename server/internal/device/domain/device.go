@@ -12,6 +12,11 @@ type DeviceStatus string
 const (
 	DeviceStatusActive   DeviceStatus = "active"
 	DeviceStatusInactive DeviceStatus = "inactive"
+	DeviceStatusBlocked  DeviceStatus = "blocked" // no selling until an operator clears it
+
+	DeviceStatusMaintenance DeviceStatus = "maintenance" // no selling while field staff work on it
+
+	DeviceStatusDecommissioned DeviceStatus = "decommissioned" // out of service for good
 )
 
 // Device is the aggregate root for vending machine devices
@@ -20,15 +25,39 @@ type Device struct {
 	machineID string // QR code identifier
 	name      string
 	location  string
+	region    string // data residency region the device's data is kept in; empty for single-region deployments
 	status    DeviceStatus
 	createdAt time.Time
 	updatedAt time.Time
 
+	// overTempSince is when the cabinet last went above the safe temperature;
+	// nil while the temperature is within limits
+	overTempSince *time.Time
+
+	door DoorState
+
+	// verificationRequiredSince marks a security incident; the first session
+	// started after it must be verified by cloud detection
+	verificationRequiredSince *time.Time
+
+	liveness Liveness
+	keyHash  []byte // SHA-256 of the device key; nil until a key is issued
+
+	decommissioned *Decommissioning // nil while the device is in service
+	maintenance    *Maintenance     // nil unless field staff are working on the device
+
+	restockSessionID *valueobjects.RestockSessionID // nil unless an operator is restocking the device
+
+	groupID *valueobjects.DeviceGroupID // nil when the device is in no group
+	policy  DevicePolicy                // overrides of the group's policy
+
+	labels DeviceLabels
+
 	domainEvents []events.DomainEvent
 }
 
 // NewDevice creates a new device with validation
-func NewDevice(machineID, name, location string) (*Device, error) {
+func NewDevice(machineID, name, location, region string) (*Device, error) {
 	if machineID == "" {
 		return nil, ErrInvalidMachineID
 	}
@@ -39,6 +68,7 @@ func NewDevice(machineID, name, location string) (*Device, error) {
 		machineID: machineID,
 		name:      name,
 		location:  location,
+		region:    region,
 		status:    DeviceStatusActive,
 		createdAt: now,
 		updatedAt: now,
@@ -52,18 +82,41 @@ func NewDevice(machineID, name, location string) (*Device, error) {
 // Reconstitute rebuilds a Device from persistence
 func Reconstitute(
 	id valueobjects.DeviceID,
-	machineID, name, location string,
+	machineID, name, location, region string,
 	status DeviceStatus,
+	overTempSince *time.Time,
+	door DoorState,
+	verificationRequiredSince *time.Time,
+	liveness Liveness,
+	keyHash []byte,
+	decommissioned *Decommissioning,
+	maintenance *Maintenance,
+	restockSessionID *valueobjects.RestockSessionID,
+	groupID *valueobjects.DeviceGroupID,
+	policy DevicePolicy,
+	labels DeviceLabels,
 	createdAt, updatedAt time.Time,
 ) *Device {
 	return &Device{
-		id:        id,
-		machineID: machineID,
-		name:      name,
-		location:  location,
-		status:    status,
-		createdAt: createdAt,
-		updatedAt: updatedAt,
+		id:                        id,
+		machineID:                 machineID,
+		name:                      name,
+		location:                  location,
+		region:                    region,
+		status:                    status,
+		overTempSince:             overTempSince,
+		door:                      door,
+		verificationRequiredSince: verificationRequiredSince,
+		liveness:                  liveness,
+		keyHash:                   keyHash,
+		decommissioned:            decommissioned,
+		maintenance:               maintenance,
+		restockSessionID:          restockSessionID,
+		groupID:                   groupID,
+		policy:                    policy,
+		labels:                    labels,
+		createdAt:                 createdAt,
+		updatedAt:                 updatedAt,
 	}
 }
 
@@ -72,30 +125,128 @@ func (d *Device) ID() valueobjects.DeviceID { return d.id }
 func (d *Device) MachineID() string         { return d.machineID }
 func (d *Device) Name() string              { return d.name }
 func (d *Device) Location() string          { return d.location }
+func (d *Device) Region() string            { return d.region }
 func (d *Device) Status() DeviceStatus      { return d.status }
 func (d *Device) CreatedAt() time.Time      { return d.createdAt }
 func (d *Device) UpdatedAt() time.Time      { return d.updatedAt }
+func (d *Device) OverTempSince() *time.Time { return d.overTempSince }
+func (d *Device) Door() DoorState           { return d.door }
+func (d *Device) Liveness() Liveness        { return d.liveness }
+func (d *Device) KeyHash() []byte           { return d.keyHash }
+
+// VerificationRequiredSince is the time of the latest security incident, if any
+func (d *Device) VerificationRequiredSince() *time.Time { return d.verificationRequiredSince }
 
 func (d *Device) IsActive() bool {
 	return d.status == DeviceStatusActive
 }
 
+func (d *Device) IsBlocked() bool {
+	return d.status == DeviceStatusBlocked
+}
+
 // Business methods
 
 func (d *Device) Deactivate() {
-	if d.status == DeviceStatusInactive {
+	if d.status == DeviceStatusInactive || d.decommissioned != nil {
 		return
 	}
 	d.status = DeviceStatusInactive
+	d.maintenance = nil
 	d.updatedAt = time.Now().UTC()
 }
 
 func (d *Device) Activate() {
-	if d.status == DeviceStatusActive {
+	if d.status == DeviceStatusActive || d.decommissioned != nil {
 		return
 	}
 	d.status = DeviceStatusActive
+	d.maintenance = nil
+	d.updatedAt = time.Now().UTC()
+}
+
+// ObserveTemperature records a cabinet temperature reading. Once the cabinet
+// has stayed above maxCelsius for maxDuration, an active device is blocked
+// and true is returned. Dropping back within limits does not unblock it.
+func (d *Device) ObserveTemperature(celsius float64, at time.Time, maxCelsius float64, maxDuration time.Duration) bool {
+	if celsius <= maxCelsius {
+		if d.overTempSince != nil {
+			d.overTempSince = nil
+			d.updatedAt = time.Now().UTC()
+		}
+		return false
+	}
+
+	if d.overTempSince == nil {
+		since := at.UTC()
+		d.overTempSince = &since
+		d.updatedAt = time.Now().UTC()
+	}
+
+	if d.status != DeviceStatusActive || at.Sub(*d.overTempSince) < maxDuration {
+		return false
+	}
+
+	d.status = DeviceStatusBlocked
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceBlocked(d.id, BlockReasonTemperatureExcursion))
+
+	return true
+}
+
+// ObserveDoor records a door-switch reading and decides whether it is a
+// security incident: the door opened while no session was active, or it was
+// still open closeGrace after the last session completed. Openings during
+// maintenance are never incidents. At most one incident is reported per door
+// opening; it also flags the next session for cloud verification.
+func (d *Device) ObserveDoor(open bool, at time.Time, sessionActive bool, lastCompletedAt *time.Time, closeGrace time.Duration) (IncidentKind, bool) {
+	if !open {
+		if d.door.IsOpen() {
+			d.door = DoorState{}
+			d.updatedAt = time.Now().UTC()
+		}
+		return "", false
+	}
+
+	if !d.door.IsOpen() {
+		since := at.UTC()
+		d.door = DoorState{OpenSince: &since}
+		d.updatedAt = time.Now().UTC()
+	}
+	// Field staff open the door while working on the device
+	if sessionActive || d.door.AlarmRaised || d.IsInMaintenance() || d.IsRestocking() {
+		return "", false
+	}
+
+	kind := IncidentDoorOpenWithoutSession
+	if lastCompletedAt != nil && !lastCompletedAt.Before(*d.door.OpenSince) {
+		// Opened during the last session; allow the customer time to close it
+		if at.Sub(*lastCompletedAt) < closeGrace {
+			return "", false
+		}
+		kind = IncidentDoorLeftOpen
+	}
+
+	detectedAt := at.UTC()
+	d.door.AlarmRaised = true
+	d.verificationRequiredSince = &detectedAt
+	d.updatedAt = time.Now().UTC()
+
+	return kind, true
+}
+
+// ClearBlock resumes selling after an operator has dealt with the cause
+func (d *Device) ClearBlock(clearedBy string) error {
+	if d.status != DeviceStatusBlocked {
+		return ErrDeviceNotBlocked
+	}
+
+	d.status = DeviceStatusActive
+	d.overTempSince = nil
 	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceCleared(d.id, clearedBy))
+
+	return nil
 }
 
 // PullEvents returns accumulated domain events and clears the slice
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// CommandKind is what the server asks a device to do
+type CommandKind string
+
+const (
+	CommandReboot           CommandKind = "reboot"
+	CommandResyncCatalog    CommandKind = "resync_catalog"
+	CommandCaptureTestImage CommandKind = "capture_test_image"
+	// CommandRunDiagnostics asks the device to self-test and upload a
+	// DiagnosticReport, which acknowledges the command
+	CommandRunDiagnostics CommandKind = "run_diagnostics"
+)
+
+func (k CommandKind) IsValid() bool {
+	switch k {
+	case CommandReboot, CommandResyncCatalog, CommandCaptureTestImage, CommandRunDiagnostics:
+		return true
+	}
+	return false
+}
+
+// CommandStatus tracks a command from the queue to the device's acknowledgement
+type CommandStatus string
+
+const (
+	CommandStatusPending   CommandStatus = "pending"   // queued, never handed to the device
+	CommandStatusDelivered CommandStatus = "delivered" // handed to the device, not acknowledged yet
+	CommandStatusSucceeded CommandStatus = "succeeded"
+	CommandStatusFailed    CommandStatus = "failed"
+)
+
+// IsAcknowledged reports whether the device has reported the outcome
+func (s CommandStatus) IsAcknowledged() bool {
+	return s == CommandStatusSucceeded || s == CommandStatusFailed
+}
+
+const maxCommandResultLength = 500
+
+// DeviceCommand is an instruction queued for one device. It is delivered at
+// least once: until the device acknowledges it, every poll or reconnect
+// hands it over again.
+type DeviceCommand struct {
+	id             valueobjects.DeviceCommandID
+	deviceID       valueobjects.DeviceID
+	kind           CommandKind
+	status         CommandStatus
+	issuedBy       string
+	issuedAt       time.Time
+	deliveries     int
+	deliveredAt    *time.Time // last delivery
+	acknowledgedAt *time.Time
+	result         string // what the device reported with its acknowledgement
+
+	domainEvents []events.DomainEvent
+}
+
+// NewDeviceCommand queues a command for the device
+func NewDeviceCommand(deviceID valueobjects.DeviceID, kind CommandKind, issuedBy string, at time.Time) (*DeviceCommand, error) {
+	issuedBy = strings.TrimSpace(issuedBy)
+	if issuedBy == "" {
+		return nil, ErrCommandIssuerRequired
+	}
+	if !kind.IsValid() {
+		return nil, ErrInvalidCommandKind
+	}
+
+	c := &DeviceCommand{
+		id:       valueobjects.NewDeviceCommandID(),
+		deviceID: deviceID,
+		kind:     kind,
+		status:   CommandStatusPending,
+		issuedBy: issuedBy,
+		issuedAt: at.UTC(),
+	}
+	c.domainEvents = append(c.domainEvents, NewDeviceCommandIssued(c.id, deviceID, kind, issuedBy))
+
+	return c, nil
+}
+
+// ReconstituteDeviceCommand rebuilds a DeviceCommand from persistence
+func ReconstituteDeviceCommand(
+	id valueobjects.DeviceCommandID,
+	deviceID valueobjects.DeviceID,
+	kind CommandKind,
+	status CommandStatus,
+	issuedBy string,
+	issuedAt time.Time,
+	deliveries int,
+	deliveredAt, acknowledgedAt *time.Time,
+	result string,
+) *DeviceCommand {
+	return &DeviceCommand{
+		id:             id,
+		deviceID:       deviceID,
+		kind:           kind,
+		status:         status,
+		issuedBy:       issuedBy,
+		issuedAt:       issuedAt,
+		deliveries:     deliveries,
+		deliveredAt:    deliveredAt,
+		acknowledgedAt: acknowledgedAt,
+		result:         result,
+	}
+}
+
+// MarkDelivered records that the command was handed to the device
+func (c *DeviceCommand) MarkDelivered(at time.Time) {
+	if c.status.IsAcknowledged() {
+		return
+	}
+	deliveredAt := at.UTC()
+	c.status = CommandStatusDelivered
+	c.deliveries++
+	c.deliveredAt = &deliveredAt
+}
+
+// Acknowledge records the outcome the device reported, succeeded or failed
+func (c *DeviceCommand) Acknowledge(status CommandStatus, result string, at time.Time) error {
+	result = strings.TrimSpace(result)
+	if !status.IsAcknowledged() || len(result) > maxCommandResultLength {
+		return ErrInvalidCommandResult
+	}
+	if c.status.IsAcknowledged() {
+		return ErrCommandAcknowledged
+	}
+
+	acknowledgedAt := at.UTC()
+	c.status = status
+	c.result = result
+	c.acknowledgedAt = &acknowledgedAt
+	c.domainEvents = append(c.domainEvents, NewDeviceCommandAcknowledged(c.id, c.deviceID, c.kind, status))
+
+	return nil
+}
+
+// Getters
+func (c *DeviceCommand) ID() valueobjects.DeviceCommandID { return c.id }
+func (c *DeviceCommand) DeviceID() valueobjects.DeviceID  { return c.deviceID }
+func (c *DeviceCommand) Kind() CommandKind                { return c.kind }
+func (c *DeviceCommand) Status() CommandStatus            { return c.status }
+func (c *DeviceCommand) IssuedBy() string                 { return c.issuedBy }
+func (c *DeviceCommand) IssuedAt() time.Time              { return c.issuedAt }
+func (c *DeviceCommand) Deliveries() int                  { return c.deliveries }
+func (c *DeviceCommand) DeliveredAt() *time.Time          { return c.deliveredAt }
+func (c *DeviceCommand) AcknowledgedAt() *time.Time       { return c.acknowledgedAt }
+func (c *DeviceCommand) Result() string                   { return c.result }
+
+// PullEvents returns and clears domain events
+func (c *DeviceCommand) PullEvents() []events.DomainEvent {
+	evts := c.domainEvents
+	c.domainEvents = nil
+	return evts
+}
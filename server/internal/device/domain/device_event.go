@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DeviceEventKind is what a device's sensors observed
+type DeviceEventKind string
+
+const (
+	DeviceEventDoorOpened    DeviceEventKind = "door_opened"
+	DeviceEventDoorClosed    DeviceEventKind = "door_closed"
+	DeviceEventTamper        DeviceEventKind = "tamper" // the tamper switch tripped
+	DeviceEventPowerLost     DeviceEventKind = "power_lost"
+	DeviceEventPowerRestored DeviceEventKind = "power_restored"
+)
+
+const maxDeviceEventDetailLength = 500
+
+func (k DeviceEventKind) IsValid() bool {
+	switch k {
+	case DeviceEventDoorOpened, DeviceEventDoorClosed, DeviceEventTamper, DeviceEventPowerLost, DeviceEventPowerRestored:
+		return true
+	}
+	return false
+}
+
+// DeviceEvent is one entry in a device's append-only event log. Devices
+// buffer them while offline, so OccurredAt may lie well before ReceivedAt.
+type DeviceEvent struct {
+	id         valueobjects.DeviceEventID
+	deviceID   valueobjects.DeviceID
+	kind       DeviceEventKind
+	occurredAt time.Time
+	receivedAt time.Time
+	detail     string
+}
+
+// NewDeviceEvent validates an event reported by a device
+func NewDeviceEvent(deviceID valueobjects.DeviceID, kind DeviceEventKind, occurredAt, receivedAt time.Time, detail string) (*DeviceEvent, error) {
+	detail = strings.TrimSpace(detail)
+	if !kind.IsValid() || occurredAt.IsZero() || len(detail) > maxDeviceEventDetailLength {
+		return nil, ErrInvalidDeviceEvent
+	}
+	return &DeviceEvent{
+		id:         valueobjects.NewDeviceEventID(),
+		deviceID:   deviceID,
+		kind:       kind,
+		occurredAt: occurredAt.UTC(),
+		receivedAt: receivedAt.UTC(),
+		detail:     detail,
+	}, nil
+}
+
+// ReconstituteDeviceEvent rebuilds a DeviceEvent from persistence
+func ReconstituteDeviceEvent(
+	id valueobjects.DeviceEventID,
+	deviceID valueobjects.DeviceID,
+	kind DeviceEventKind,
+	occurredAt, receivedAt time.Time,
+	detail string,
+) *DeviceEvent {
+	return &DeviceEvent{
+		id:         id,
+		deviceID:   deviceID,
+		kind:       kind,
+		occurredAt: occurredAt,
+		receivedAt: receivedAt,
+		detail:     detail,
+	}
+}
+
+// Getters
+func (e *DeviceEvent) ID() valueobjects.DeviceEventID  { return e.id }
+func (e *DeviceEvent) DeviceID() valueobjects.DeviceID { return e.deviceID }
+func (e *DeviceEvent) Kind() DeviceEventKind           { return e.kind }
+func (e *DeviceEvent) OccurredAt() time.Time           { return e.occurredAt }
+func (e *DeviceEvent) ReceivedAt() time.Time           { return e.receivedAt }
+func (e *DeviceEvent) Detail() string                  { return e.detail }
@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const (
+	maxGroupNameLength    = 100
+	maxModelVersionLength = 100
+
+	maxSessionExpirationMinutes = 24 * 60
+)
+
+// DevicePolicy holds the settings a group or a device can configure. Unset
+// fields are inherited: a device falls back to its group, a group to the
+// deployment's defaults.
+type DevicePolicy struct {
+	ConfidenceThreshold      *float64 // minimum detection confidence, 0-1
+	ModelVersion             string   // detection model the device runs
+	SessionExpirationMinutes *int     // how long a session started on the device lasts
+}
+
+// NewDevicePolicy validates a policy
+func NewDevicePolicy(confidenceThreshold *float64, modelVersion string, sessionExpirationMinutes *int) (DevicePolicy, error) {
+	if confidenceThreshold != nil && (*confidenceThreshold < 0 || *confidenceThreshold > 1) {
+		return DevicePolicy{}, ErrInvalidConfidenceThreshold
+	}
+	modelVersion = strings.TrimSpace(modelVersion)
+	if len(modelVersion) > maxModelVersionLength {
+		return DevicePolicy{}, ErrInvalidModelVersion
+	}
+	if sessionExpirationMinutes != nil && (*sessionExpirationMinutes < 1 || *sessionExpirationMinutes > maxSessionExpirationMinutes) {
+		return DevicePolicy{}, ErrInvalidSessionExpiration
+	}
+	return DevicePolicy{
+		ConfidenceThreshold:      confidenceThreshold,
+		ModelVersion:             modelVersion,
+		SessionExpirationMinutes: sessionExpirationMinutes,
+	}, nil
+}
+
+// PolicySource tells where a resolved setting comes from
+type PolicySource string
+
+const (
+	PolicySourceDevice  PolicySource = "device"
+	PolicySourceGroup   PolicySource = "group"
+	PolicySourceDefault PolicySource = "default" // unset; the deployment's default applies
+)
+
+// ResolvedPolicy is the policy that applies to a device, with where each setting comes from
+type ResolvedPolicy struct {
+	DevicePolicy
+	ConfidenceThresholdSource      PolicySource
+	ModelVersionSource             PolicySource
+	SessionExpirationMinutesSource PolicySource
+}
+
+// ResolvePolicy applies the device's overrides over its group's policy;
+// group is nil for a device in no group
+func (d *Device) ResolvePolicy(group *DeviceGroup) ResolvedPolicy {
+	resolved := ResolvedPolicy{
+		ConfidenceThresholdSource:      PolicySourceDefault,
+		ModelVersionSource:             PolicySourceDefault,
+		SessionExpirationMinutesSource: PolicySourceDefault,
+	}
+	if group != nil {
+		if group.policy.ConfidenceThreshold != nil {
+			resolved.ConfidenceThreshold = group.policy.ConfidenceThreshold
+			resolved.ConfidenceThresholdSource = PolicySourceGroup
+		}
+		if group.policy.ModelVersion != "" {
+			resolved.ModelVersion = group.policy.ModelVersion
+			resolved.ModelVersionSource = PolicySourceGroup
+		}
+		if group.policy.SessionExpirationMinutes != nil {
+			resolved.SessionExpirationMinutes = group.policy.SessionExpirationMinutes
+			resolved.SessionExpirationMinutesSource = PolicySourceGroup
+		}
+	}
+	if d.policy.ConfidenceThreshold != nil {
+		resolved.ConfidenceThreshold = d.policy.ConfidenceThreshold
+		resolved.ConfidenceThresholdSource = PolicySourceDevice
+	}
+	if d.policy.ModelVersion != "" {
+		resolved.ModelVersion = d.policy.ModelVersion
+		resolved.ModelVersionSource = PolicySourceDevice
+	}
+	if d.policy.SessionExpirationMinutes != nil {
+		resolved.SessionExpirationMinutes = d.policy.SessionExpirationMinutes
+		resolved.SessionExpirationMinutesSource = PolicySourceDevice
+	}
+	return resolved
+}
+
+// DeviceGroup is a fleet of devices, e.g. one site, configured together. A
+// device belongs to at most one group.
+type DeviceGroup struct {
+	id               valueobjects.DeviceGroupID
+	name             string
+	policy           DevicePolicy
+	planogram        []PlanogramFacing // nil when the group has no planogram
+	planogramVersion int
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// NewDeviceGroup creates an empty group
+func NewDeviceGroup(name string) (*DeviceGroup, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxGroupNameLength {
+		return nil, ErrInvalidGroupName
+	}
+
+	now := time.Now().UTC()
+	return &DeviceGroup{
+		id:        valueobjects.NewDeviceGroupID(),
+		name:      name,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// ReconstituteDeviceGroup rebuilds a DeviceGroup from persistence
+func ReconstituteDeviceGroup(
+	id valueobjects.DeviceGroupID,
+	name string,
+	policy DevicePolicy,
+	planogram []PlanogramFacing,
+	planogramVersion int,
+	createdAt, updatedAt time.Time,
+) *DeviceGroup {
+	return &DeviceGroup{
+		id:               id,
+		name:             name,
+		policy:           policy,
+		planogram:        planogram,
+		planogramVersion: planogramVersion,
+		createdAt:        createdAt,
+		updatedAt:        updatedAt,
+	}
+}
+
+// Getters
+func (g *DeviceGroup) ID() valueobjects.DeviceGroupID { return g.id }
+func (g *DeviceGroup) Name() string                   { return g.name }
+func (g *DeviceGroup) Policy() DevicePolicy           { return g.policy }
+func (g *DeviceGroup) PlanogramVersion() int          { return g.planogramVersion }
+func (g *DeviceGroup) CreatedAt() time.Time           { return g.createdAt }
+func (g *DeviceGroup) UpdatedAt() time.Time           { return g.updatedAt }
+
+// Planogram returns a copy of the group's layout, ordered by shelf then SKU code
+func (g *DeviceGroup) Planogram() []PlanogramFacing {
+	return append([]PlanogramFacing(nil), g.planogram...)
+}
+
+// Business methods
+
+// SetPolicy replaces the group's policy
+func (g *DeviceGroup) SetPolicy(policy DevicePolicy) {
+	g.policy = policy
+	g.updatedAt = time.Now().UTC()
+}
+
+// SetPlanogram replaces the layout the group's devices are stocked with,
+// unless they have their own; nil facings remove it
+func (g *DeviceGroup) SetPlanogram(facings []PlanogramFacing) error {
+	if facings == nil {
+		g.planogram = nil
+		g.updatedAt = time.Now().UTC()
+		return nil
+	}
+
+	normalized, err := normalizeFacings(facings)
+	if err != nil {
+		return err
+	}
+	g.planogram = normalized
+	g.planogramVersion++
+	g.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// PlanogramFor returns the group's layout as the planogram of one of its
+// devices, or nil when the group has none
+func (g *DeviceGroup) PlanogramFor(deviceID valueobjects.DeviceID) *Planogram {
+	if len(g.planogram) == 0 {
+		return nil
+	}
+	return ReconstitutePlanogram(deviceID, g.planogramVersion, g.Planogram(), g.updatedAt)
+}
+
+// Group membership and overrides of a device
+
+// GroupID is the group the device belongs to; nil when it is in none
+func (d *Device) GroupID() *valueobjects.DeviceGroupID { return d.groupID }
+
+// PolicyOverrides are the settings configured on the device itself
+func (d *Device) PolicyOverrides() DevicePolicy { return d.policy }
+
+// JoinGroup moves the device into the group, out of any group it was in
+func (d *Device) JoinGroup(groupID valueobjects.DeviceGroupID) {
+	d.groupID = &groupID
+	d.updatedAt = time.Now().UTC()
+}
+
+// LeaveGroup takes the device out of its group
+func (d *Device) LeaveGroup() {
+	d.groupID = nil
+	d.updatedAt = time.Now().UTC()
+}
+
+// SetPolicyOverrides replaces the settings configured on the device itself;
+// unset fields follow the group
+func (d *Device) SetPolicyOverrides(policy DevicePolicy) {
+	d.policy = policy
+	d.updatedAt = time.Now().UTC()
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxDeviceTags          = 50
+	maxTagLength           = 50
+	maxMetadataEntries     = 50
+	maxMetadataKeyLength   = 50
+	maxMetadataValueLength = 200
+)
+
+// DeviceLabels are the tags and free-form metadata operators put on a device
+// to slice the fleet, e.g. by venue type, contract or owner. Tags are kept
+// lower-case and sorted; metadata keys are case-sensitive.
+type DeviceLabels struct {
+	tags     []string
+	metadata map[string]string
+}
+
+// NewDeviceLabels validates and normalises tags and metadata; duplicate tags
+// collapse into one
+func NewDeviceLabels(tags []string, metadata map[string]string) (DeviceLabels, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || len(tag) > maxTagLength {
+			return DeviceLabels{}, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxDeviceTags {
+		return DeviceLabels{}, ErrInvalidTag
+	}
+	sort.Strings(normalized)
+
+	if len(metadata) > maxMetadataEntries {
+		return DeviceLabels{}, ErrInvalidMetadata
+	}
+	entries := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" || len(key) > maxMetadataKeyLength || len(value) > maxMetadataValueLength {
+			return DeviceLabels{}, ErrInvalidMetadata
+		}
+		entries[key] = value
+	}
+
+	return DeviceLabels{tags: normalized, metadata: entries}, nil
+}
+
+// ReconstituteDeviceLabels rebuilds DeviceLabels from persistence
+func ReconstituteDeviceLabels(tags []string, metadata map[string]string) DeviceLabels {
+	return DeviceLabels{tags: tags, metadata: metadata}
+}
+
+// NormalizeTag is the form tags are stored and matched in
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Tags returns a copy of the tags, sorted
+func (l DeviceLabels) Tags() []string {
+	tags := make([]string, len(l.tags))
+	copy(tags, l.tags)
+	return tags
+}
+
+// Metadata returns a copy of the metadata
+func (l DeviceLabels) Metadata() map[string]string {
+	metadata := make(map[string]string, len(l.metadata))
+	for key, value := range l.metadata {
+		metadata[key] = value
+	}
+	return metadata
+}
+
+// HasTag reports whether the labels carry the tag, in any case
+func (l DeviceLabels) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	i := sort.SearchStrings(l.tags, tag)
+	return i < len(l.tags) && l.tags[i] == tag
+}
+
+// MetadataValue returns the value under key and whether it is set
+func (l DeviceLabels) MetadataValue(key string) (string, bool) {
+	value, ok := l.metadata[key]
+	return value, ok
+}
+
+// Labels are the tags and metadata operators put on the device
+func (d *Device) Labels() DeviceLabels { return d.labels }
+
+// SetLabels replaces the device's tags and metadata
+func (d *Device) SetLabels(labels DeviceLabels) {
+	d.labels = labels
+	d.updatedAt = time.Now().UTC()
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const maxDiagnosticNotesLength = 500
+
+// DiagnosticChecks is the outcome of a device self-test
+type DiagnosticChecks struct {
+	CameraOK     bool
+	ScaleOK      bool
+	ModelLoaded  bool
+	ModelVersion string // the detection model the device has loaded, if any
+	Notes        string // free-form detail for the operator troubleshooting
+}
+
+// Passed reports whether every check passed
+func (c DiagnosticChecks) Passed() bool {
+	return c.CameraOK && c.ScaleOK && c.ModelLoaded
+}
+
+// Summary is a one-line account of the checks, e.g. "camera ok, scale
+// failed, model loaded"
+func (c DiagnosticChecks) Summary() string {
+	outcome := func(ok bool, pass, fail string) string {
+		if ok {
+			return pass
+		}
+		return fail
+	}
+	return fmt.Sprintf("camera %s, scale %s, model %s",
+		outcome(c.CameraOK, "ok", "failed"),
+		outcome(c.ScaleOK, "ok", "failed"),
+		outcome(c.ModelLoaded, "loaded", "not loaded"))
+}
+
+// DiagnosticReport is the self-test report a device uploads in answer to a
+// run_diagnostics command. There is at most one per command.
+type DiagnosticReport struct {
+	commandID  valueobjects.DeviceCommandID
+	deviceID   valueobjects.DeviceID
+	checks     DiagnosticChecks
+	reportedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewDiagnosticReport records the checks a device ran for the command
+func NewDiagnosticReport(command *DeviceCommand, checks DiagnosticChecks, at time.Time) (*DiagnosticReport, error) {
+	if command.Kind() != CommandRunDiagnostics {
+		return nil, ErrDiagnosticsNotFound
+	}
+	checks.ModelVersion = strings.TrimSpace(checks.ModelVersion)
+	checks.Notes = strings.TrimSpace(checks.Notes)
+	if len(checks.Notes) > maxDiagnosticNotesLength || len(checks.ModelVersion) > maxModelVersionLength {
+		return nil, ErrInvalidDiagnostics
+	}
+
+	r := &DiagnosticReport{
+		commandID:  command.ID(),
+		deviceID:   command.DeviceID(),
+		checks:     checks,
+		reportedAt: at.UTC(),
+	}
+	r.domainEvents = append(r.domainEvents, NewDiagnosticsReported(r.commandID, r.deviceID, checks.Passed()))
+
+	return r, nil
+}
+
+// ReconstituteDiagnosticReport rebuilds a DiagnosticReport from persistence
+func ReconstituteDiagnosticReport(
+	commandID valueobjects.DeviceCommandID,
+	deviceID valueobjects.DeviceID,
+	checks DiagnosticChecks,
+	reportedAt time.Time,
+) *DiagnosticReport {
+	return &DiagnosticReport{
+		commandID:  commandID,
+		deviceID:   deviceID,
+		checks:     checks,
+		reportedAt: reportedAt,
+	}
+}
+
+// Getters
+func (r *DiagnosticReport) CommandID() valueobjects.DeviceCommandID { return r.commandID }
+func (r *DiagnosticReport) DeviceID() valueobjects.DeviceID         { return r.deviceID }
+func (r *DiagnosticReport) Checks() DiagnosticChecks                { return r.checks }
+func (r *DiagnosticReport) ReportedAt() time.Time                   { return r.reportedAt }
+
+// PullEvents returns and clears domain events
+func (r *DiagnosticReport) PullEvents() []events.DomainEvent {
+	evts := r.domainEvents
+	r.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// DoorState tracks the current opening of a device's door
+type DoorState struct {
+	OpenSince   *time.Time // nil while the door is closed
+	AlarmRaised bool       // an incident was already reported for this opening
+}
+
+func (s DoorState) IsOpen() bool { return s.OpenSince != nil }
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// EnrollmentToken is a one-time secret an operator creates for a machine ID.
+// The device redeems it to enroll and receive its key, so only machines the
+// operator provisioned can act under a machine ID. Only the token's hash is kept.
+type EnrollmentToken struct {
+	hash       []byte
+	machineID  string
+	name       string
+	location   string
+	createdBy  string
+	override   bool // recommissions the machine ID if it was decommissioned
+	createdAt  time.Time
+	expiresAt  time.Time
+	redeemedAt *time.Time
+}
+
+// NewEnrollmentToken creates a token for the machine ID valid for ttl and
+// returns it with the secret to hand to the device
+func NewEnrollmentToken(machineID, name, location, createdBy string, override bool, ttl time.Duration) (*EnrollmentToken, string, error) {
+	machineID = strings.TrimSpace(machineID)
+	if machineID == "" {
+		return nil, "", ErrInvalidMachineID
+	}
+	createdBy = strings.TrimSpace(createdBy)
+	if createdBy == "" {
+		return nil, "", ErrEnrolledByRequired
+	}
+
+	// Tokens are generated and hashed like device keys
+	secret, hash := newDeviceKey()
+	now := time.Now().UTC()
+	return &EnrollmentToken{
+		hash:      hash,
+		machineID: machineID,
+		name:      strings.TrimSpace(name),
+		location:  strings.TrimSpace(location),
+		createdBy: createdBy,
+		override:  override,
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}, secret, nil
+}
+
+// ReconstituteEnrollmentToken rebuilds an EnrollmentToken from persistence
+func ReconstituteEnrollmentToken(
+	hash []byte,
+	machineID, name, location, createdBy string,
+	override bool,
+	createdAt, expiresAt time.Time,
+	redeemedAt *time.Time,
+) *EnrollmentToken {
+	return &EnrollmentToken{
+		hash:       hash,
+		machineID:  machineID,
+		name:       name,
+		location:   location,
+		createdBy:  createdBy,
+		override:   override,
+		createdAt:  createdAt,
+		expiresAt:  expiresAt,
+		redeemedAt: redeemedAt,
+	}
+}
+
+// HashEnrollmentToken returns the hash a token is stored and looked up by
+func HashEnrollmentToken(secret string) []byte {
+	return hashDeviceKey(secret)
+}
+
+// Redeem uses the token up for the machine ID presenting it
+func (t *EnrollmentToken) Redeem(machineID string, at time.Time) error {
+	if machineID != t.machineID {
+		return ErrInvalidEnrollmentToken
+	}
+	if t.redeemedAt != nil {
+		return ErrEnrollmentTokenRedeemed
+	}
+	if !at.Before(t.expiresAt) {
+		return ErrEnrollmentTokenExpired
+	}
+
+	at = at.UTC()
+	t.redeemedAt = &at
+	return nil
+}
+
+// Getters
+func (t *EnrollmentToken) Hash() []byte           { return t.hash }
+func (t *EnrollmentToken) MachineID() string      { return t.machineID }
+func (t *EnrollmentToken) Name() string           { return t.name }
+func (t *EnrollmentToken) Location() string       { return t.location }
+func (t *EnrollmentToken) CreatedBy() string      { return t.createdBy }
+func (t *EnrollmentToken) Override() bool         { return t.override }
+func (t *EnrollmentToken) CreatedAt() time.Time   { return t.createdAt }
+func (t *EnrollmentToken) ExpiresAt() time.Time   { return t.expiresAt }
+func (t *EnrollmentToken) RedeemedAt() *time.Time { return t.redeemedAt }
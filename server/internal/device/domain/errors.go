@@ -7,4 +7,114 @@ var (
 	ErrInvalidMachineID   = errors.New("machine ID cannot be empty")
 	ErrDeviceInactive     = errors.New("device is inactive")
 	ErrDuplicateMachineID = errors.New("machine ID already registered")
+	ErrDeviceBlocked      = errors.New("device is blocked pending operator clearance")
+	ErrDeviceNotBlocked   = errors.New("device is not blocked")
+	ErrInvalidDeviceKey   = errors.New("invalid device credentials")
+	ErrInvalidVersion     = errors.New("firmware and app versions are limited to 50 characters")
+
+	ErrDeviceDecommissioned     = errors.New("device is decommissioned")
+	ErrDeviceNotDecommissioned  = errors.New("device is not decommissioned")
+	ErrDecommissionedByRequired = errors.New("decommissioning operator is required")
+	ErrOverrideByRequired       = errors.New("operator overriding the decommissioning is required")
+
+	ErrDeviceInMaintenance    = errors.New("device is under maintenance")
+	ErrDeviceNotInMaintenance = errors.New("device is not under maintenance")
+	ErrMaintainedByRequired   = errors.New("operator starting or ending the maintenance is required")
+
+	ErrEnrollmentTokenNotFound = errors.New("enrollment token not found")
+	ErrInvalidEnrollmentToken  = errors.New("invalid enrollment token")
+	ErrEnrollmentTokenRedeemed = errors.New("enrollment token has already been used")
+	ErrEnrollmentTokenExpired  = errors.New("enrollment token has expired")
+	ErrEnrolledByRequired      = errors.New("enrolling operator is required")
+
+	ErrDeviceGroupNotFound        = errors.New("device group not found")
+	ErrDeviceNotInGroup           = errors.New("device is not in the group")
+	ErrInvalidGroupName           = errors.New("group name is required and limited to 100 characters")
+	ErrInvalidConfidenceThreshold = errors.New("confidence threshold must be between 0 and 1")
+	ErrInvalidModelVersion        = errors.New("model version is limited to 100 characters")
+	ErrInvalidSessionExpiration   = errors.New("session expiration must be between 1 minute and 24 hours")
+	ErrInvalidTag                 = errors.New("a device takes up to 50 tags of up to 50 characters")
+	ErrInvalidMetadata            = errors.New("device metadata takes up to 50 entries with keys of up to 50 characters and values of up to 200")
+
+	ErrConfigNotFound        = errors.New("device configuration not found")
+	ErrConfiguredByRequired  = errors.New("configuring operator is required")
+	ErrInvalidSyncInterval   = errors.New("sync interval must be between 10 seconds and 24 hours")
+	ErrInvalidCameraSettings = errors.New("camera resolution must be like 1920x1080, frame rate up to 120 and exposure not negative")
+	ErrUnknownConfigVersion  = errors.New("reported configuration version was never desired")
+
+	ErrReleaseNotFound         = errors.New("release not found")
+	ErrInvalidRelease          = errors.New("release needs a kind of firmware or app, a version of up to 50 characters and an artifact of up to 64 MiB")
+	ErrDuplicateRelease        = errors.New("a release of this kind and version already exists")
+	ErrUploadedByRequired      = errors.New("uploading operator is required")
+	ErrRolloutNotFound         = errors.New("rollout not found")
+	ErrRolloutOperatorRequired = errors.New("rollout operator is required")
+	ErrRolloutTargetRequired   = errors.New("rollout needs at least one device group")
+	ErrInvalidRolloutStages    = errors.New("rollout stages must be increasing percentages ending at 100")
+	ErrRolloutInProgress       = errors.New("a rollout of this kind is still staging to one of the groups")
+	ErrRolloutNotActive        = errors.New("only a staging rollout can advance")
+	ErrRolloutAborted          = errors.New("rollout is already aborted")
+	ErrInvalidUpdateStatus     = errors.New("update status must be downloading, downloaded, installing, installed or failed")
+	ErrDeviceUpdateNotFound    = errors.New("device update not found")
+
+	ErrStockEstimateNotFound = errors.New("stock estimate not found")
+	ErrEmptySnapshot         = errors.New("shelf snapshot image is required")
+	ErrStaleSnapshot         = errors.New("shelf snapshot is older than the current estimate")
+
+	ErrExcursionNotFound = errors.New("temperature excursion not found")
+	ErrClearedByRequired = errors.New("clearing operator is required")
+	ErrExcursionCleared  = errors.New("temperature excursion already cleared")
+
+	ErrInvalidDeviceEvent  = errors.New("device event needs a kind of door_opened, door_closed, tamper, power_lost or power_restored, the time it occurred and a detail of up to 500 characters")
+	ErrNoDeviceEvents      = errors.New("at least one device event is required")
+	ErrTooManyDeviceEvents = errors.New("at most 500 device events can be reported at once")
+
+	ErrDeviceCommandNotFound = errors.New("device command not found")
+	ErrInvalidCommandKind    = errors.New("command kind must be reboot, resync_catalog, capture_test_image or run_diagnostics")
+	ErrCommandIssuerRequired = errors.New("operator issuing the command is required")
+	ErrInvalidCommandResult  = errors.New("command acknowledgement needs a status of succeeded or failed and a result of up to 500 characters")
+	ErrCommandAcknowledged   = errors.New("command already acknowledged")
+
+	ErrDiagnosticsNotFound = errors.New("diagnostics run not found")
+	ErrInvalidDiagnostics  = errors.New("diagnostic report notes are limited to 500 characters and the model version to 100")
+
+	ErrPlanogramNotFound = errors.New("planogram not found")
+	ErrEmptyPlanogram    = errors.New("planogram needs at least one facing")
+	ErrInvalidFacing     = errors.New("planogram facing needs a shelf, a SKU code and a positive count")
+	ErrDuplicateFacing   = errors.New("SKU appears more than once on the same shelf")
+	ErrVisitedByRequired = errors.New("visiting field staff is required")
+
+	ErrSalesHoursNotFound     = errors.New("sales hours not found")
+	ErrInvalidSalesWindow     = errors.New("sales window needs a start and end between 00:00 and 24:00 and valid weekdays")
+	ErrInvalidTimezone        = errors.New("unknown timezone")
+	ErrBlackoutReasonRequired = errors.New("blackout window needs a reason")
+
+	ErrBatchNotFound        = errors.New("stock batch not found")
+	ErrInvalidBatch         = errors.New("stock batch needs a SKU code, a positive quantity and an expiry date")
+	ErrRestockedByRequired  = errors.New("restocking field staff is required")
+	ErrWrittenOffByRequired = errors.New("field staff writing off the batch is required")
+	ErrBatchWrittenOff      = errors.New("stock batch already written off")
+
+	ErrInventoryNotFound   = errors.New("inventory not found")
+	ErrInvalidRestock      = errors.New("restock needs SKU codes and positive quantities")
+	ErrUnknownSKU          = errors.New("SKU is not in the catalog")
+	ErrSaleAlreadyRecorded = errors.New("sale already taken out of the inventory")
+
+	ErrRestockSessionNotFound = errors.New("restock session not found")
+	ErrRestockSessionOpen     = errors.New("device is being restocked")
+	ErrRestockSessionClosed   = errors.New("restock session is already closed")
+	ErrCustomerSessionActive  = errors.New("a customer session is open on the device")
+
+	ErrCalibrationNotFound = errors.New("camera calibration not found")
+	ErrInvalidHomography   = errors.New("homography needs 9 finite values forming an invertible matrix")
+	ErrInvalidMarker       = errors.New("reference marker needs a unique ID and finite coordinates")
+	ErrTooManyMarkers      = errors.New("too many reference markers")
+
+	ErrInvalidScaleReading    = errors.New("scale readings need a positive reference weight and finite measurements")
+	ErrNoScaleReadings        = errors.New("scale calibration needs at least one known-weight reading")
+	ErrTooManyScaleReadings   = errors.New("too many scale readings")
+	ErrFutureScaleCalibration = errors.New("scale calibration cannot be dated in the future")
+
+	ErrAssortmentNotFound = errors.New("no SKUs assigned to the device")
+	ErrInvalidAssignment  = errors.New("assignment needs at least one SKU code")
+	ErrSKUNotAssigned     = errors.New("SKU is not assigned to the device")
 )
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/vending-machine/server/internal/shared/events"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
@@ -20,3 +22,659 @@ func NewDeviceRegistered(deviceID valueobjects.DeviceID, machineID string) Devic
 }
 
 func (DeviceRegistered) EventName() string { return "DeviceRegistered" }
+
+// StockLowEstimated is raised when a shelf snapshot estimates a SKU at or
+// below the low-stock threshold
+type StockLowEstimated struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	SKUCode   string
+	Estimated int
+	Threshold int
+}
+
+func NewStockLowEstimated(deviceID valueobjects.DeviceID, skuCode string, estimated, threshold int) StockLowEstimated {
+	return StockLowEstimated{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		SKUCode:   skuCode,
+		Estimated: estimated,
+		Threshold: threshold,
+	}
+}
+
+func (StockLowEstimated) EventName() string { return "StockLowEstimated" }
+
+// BlockReasonTemperatureExcursion marks a device blocked by an over-temperature cabinet
+const BlockReasonTemperatureExcursion = "temperature_excursion"
+
+type DeviceBlocked struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	Reason   string
+}
+
+func NewDeviceBlocked(deviceID valueobjects.DeviceID, reason string) DeviceBlocked {
+	return DeviceBlocked{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		Reason:    reason,
+	}
+}
+
+func (DeviceBlocked) EventName() string { return "DeviceBlocked" }
+
+type DeviceCleared struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	ClearedBy string
+}
+
+func NewDeviceCleared(deviceID valueobjects.DeviceID, clearedBy string) DeviceCleared {
+	return DeviceCleared{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		ClearedBy: clearedBy,
+	}
+}
+
+func (DeviceCleared) EventName() string { return "DeviceCleared" }
+
+type TemperatureExcursionRecorded struct {
+	events.BaseEvent
+	ExcursionID  valueobjects.ExcursionID
+	DeviceID     valueobjects.DeviceID
+	PeakCelsius  float64
+	LimitCelsius float64
+}
+
+func NewTemperatureExcursionRecorded(id valueobjects.ExcursionID, deviceID valueobjects.DeviceID, peak, limit float64) TemperatureExcursionRecorded {
+	return TemperatureExcursionRecorded{
+		BaseEvent:    events.NewBaseEvent(),
+		ExcursionID:  id,
+		DeviceID:     deviceID,
+		PeakCelsius:  peak,
+		LimitCelsius: limit,
+	}
+}
+
+func (TemperatureExcursionRecorded) EventName() string { return "TemperatureExcursionRecorded" }
+
+// SecurityIncidentRaised alerts operators to suspicious access to a device
+type SecurityIncidentRaised struct {
+	events.BaseEvent
+	IncidentID valueobjects.IncidentID
+	DeviceID   valueobjects.DeviceID
+	Kind       IncidentKind
+}
+
+func NewSecurityIncidentRaised(id valueobjects.IncidentID, deviceID valueobjects.DeviceID, kind IncidentKind) SecurityIncidentRaised {
+	return SecurityIncidentRaised{
+		BaseEvent:  events.NewBaseEvent(),
+		IncidentID: id,
+		DeviceID:   deviceID,
+		Kind:       kind,
+	}
+}
+
+func (SecurityIncidentRaised) EventName() string { return "SecurityIncidentRaised" }
+
+type PlanogramAssigned struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	Version  int
+}
+
+func NewPlanogramAssigned(deviceID valueobjects.DeviceID, version int) PlanogramAssigned {
+	return PlanogramAssigned{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		Version:   version,
+	}
+}
+
+func (PlanogramAssigned) EventName() string { return "PlanogramAssigned" }
+
+// PlanogramComplianceChecked reports the outcome of a restock visit to merchandising
+type PlanogramComplianceChecked struct {
+	events.BaseEvent
+	ReportID   valueobjects.ComplianceReportID
+	DeviceID   valueobjects.DeviceID
+	Score      int
+	Deviations int
+}
+
+func NewPlanogramComplianceChecked(id valueobjects.ComplianceReportID, deviceID valueobjects.DeviceID, score, deviations int) PlanogramComplianceChecked {
+	return PlanogramComplianceChecked{
+		BaseEvent:  events.NewBaseEvent(),
+		ReportID:   id,
+		DeviceID:   deviceID,
+		Score:      score,
+		Deviations: deviations,
+	}
+}
+
+func (PlanogramComplianceChecked) EventName() string { return "PlanogramComplianceChecked" }
+
+// SalesHoursChanged is raised when a device's opening hours or blackout windows change
+type SalesHoursChanged struct {
+	events.BaseEvent
+	DeviceID    valueobjects.DeviceID
+	OpenWindows int
+	Blackouts   int
+}
+
+func NewSalesHoursChanged(deviceID valueobjects.DeviceID, openWindows, blackouts int) SalesHoursChanged {
+	return SalesHoursChanged{
+		BaseEvent:   events.NewBaseEvent(),
+		DeviceID:    deviceID,
+		OpenWindows: openWindows,
+		Blackouts:   blackouts,
+	}
+}
+
+func (SalesHoursChanged) EventName() string { return "SalesHoursChanged" }
+
+// StockBatchRestocked is raised when field staff load a batch into a device
+type StockBatchRestocked struct {
+	events.BaseEvent
+	BatchID   valueobjects.BatchID
+	DeviceID  valueobjects.DeviceID
+	SKUCode   string
+	Quantity  int
+	ExpiresOn time.Time
+}
+
+func NewStockBatchRestocked(id valueobjects.BatchID, deviceID valueobjects.DeviceID, skuCode string, quantity int, expiresOn time.Time) StockBatchRestocked {
+	return StockBatchRestocked{
+		BaseEvent: events.NewBaseEvent(),
+		BatchID:   id,
+		DeviceID:  deviceID,
+		SKUCode:   skuCode,
+		Quantity:  quantity,
+		ExpiresOn: expiresOn,
+	}
+}
+
+func (StockBatchRestocked) EventName() string { return "StockBatchRestocked" }
+
+// StockBatchWrittenOff is raised when the rest of a batch is removed as waste
+type StockBatchWrittenOff struct {
+	events.BaseEvent
+	BatchID     valueobjects.BatchID
+	DeviceID    valueobjects.DeviceID
+	SKUCode     string
+	WastedUnits int
+}
+
+func NewStockBatchWrittenOff(id valueobjects.BatchID, deviceID valueobjects.DeviceID, skuCode string, wastedUnits int) StockBatchWrittenOff {
+	return StockBatchWrittenOff{
+		BaseEvent:   events.NewBaseEvent(),
+		BatchID:     id,
+		DeviceID:    deviceID,
+		SKUCode:     skuCode,
+		WastedUnits: wastedUnits,
+	}
+}
+
+func (StockBatchWrittenOff) EventName() string { return "StockBatchWrittenOff" }
+
+// InventoryRestocked is raised for each SKU field staff add to a device's inventory
+type InventoryRestocked struct {
+	events.BaseEvent
+	DeviceID    valueobjects.DeviceID
+	SKUCode     string
+	Added       int
+	Quantity    int // units in the device after the restock
+	RestockedBy string
+}
+
+func NewInventoryRestocked(deviceID valueobjects.DeviceID, skuCode string, added, quantity int, restockedBy string) InventoryRestocked {
+	return InventoryRestocked{
+		BaseEvent:   events.NewBaseEvent(),
+		DeviceID:    deviceID,
+		SKUCode:     skuCode,
+		Added:       added,
+		Quantity:    quantity,
+		RestockedBy: restockedBy,
+	}
+}
+
+func (InventoryRestocked) EventName() string { return "InventoryRestocked" }
+
+// InventorySold is raised for each SKU a completed session took out of a device
+type InventorySold struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	SessionID string
+	SKUCode   string
+	Sold      int
+	Quantity  int // units left in the device
+}
+
+func NewInventorySold(deviceID valueobjects.DeviceID, sessionID, skuCode string, sold, quantity int) InventorySold {
+	return InventorySold{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		SessionID: sessionID,
+		SKUCode:   skuCode,
+		Sold:      sold,
+		Quantity:  quantity,
+	}
+}
+
+func (InventorySold) EventName() string { return "InventorySold" }
+
+// StockLow is raised when a sale takes a SKU's counted inventory in a device
+// below the low-stock threshold, so the device can be put on a restocking run
+type StockLow struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	SKUCode   string
+	Quantity  int
+	Threshold int
+}
+
+func NewStockLow(deviceID valueobjects.DeviceID, skuCode string, quantity, threshold int) StockLow {
+	return StockLow{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		SKUCode:   skuCode,
+		Quantity:  quantity,
+		Threshold: threshold,
+	}
+}
+
+func (StockLow) EventName() string { return "StockLow" }
+
+// SKUsAssigned is raised when SKUs are added to a device's assortment
+type SKUsAssigned struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	SKUCodes []string
+}
+
+func NewSKUsAssigned(deviceID valueobjects.DeviceID, skuCodes []string) SKUsAssigned {
+	return SKUsAssigned{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		SKUCodes:  skuCodes,
+	}
+}
+
+func (SKUsAssigned) EventName() string { return "SKUsAssigned" }
+
+// SKUUnassigned is raised when a SKU is taken out of a device's assortment
+type SKUUnassigned struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	SKUCode  string
+}
+
+func NewSKUUnassigned(deviceID valueobjects.DeviceID, skuCode string) SKUUnassigned {
+	return SKUUnassigned{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		SKUCode:   skuCode,
+	}
+}
+
+func (SKUUnassigned) EventName() string { return "SKUUnassigned" }
+
+// DeviceDecommissioned is raised when a device is taken out of service; the
+// transaction context cancels the session still open on it
+type DeviceDecommissioned struct {
+	events.BaseEvent
+	DeviceID         valueobjects.DeviceID
+	MachineID        string
+	DecommissionedBy string
+}
+
+func NewDeviceDecommissioned(deviceID valueobjects.DeviceID, machineID, by string) DeviceDecommissioned {
+	return DeviceDecommissioned{
+		BaseEvent:        events.NewBaseEvent(),
+		DeviceID:         deviceID,
+		MachineID:        machineID,
+		DecommissionedBy: by,
+	}
+}
+
+func (DeviceDecommissioned) EventName() string { return "DeviceDecommissioned" }
+
+// DeviceRecommissioned is raised when an operator re-registers a
+// decommissioned machine ID
+type DeviceRecommissioned struct {
+	events.BaseEvent
+	DeviceID         valueobjects.DeviceID
+	MachineID        string
+	RecommissionedBy string
+}
+
+func NewDeviceRecommissioned(deviceID valueobjects.DeviceID, machineID, by string) DeviceRecommissioned {
+	return DeviceRecommissioned{
+		BaseEvent:        events.NewBaseEvent(),
+		DeviceID:         deviceID,
+		MachineID:        machineID,
+		RecommissionedBy: by,
+	}
+}
+
+func (DeviceRecommissioned) EventName() string { return "DeviceRecommissioned" }
+
+// DeviceConfigChanged is raised when an operator changes the configuration a
+// device should run
+type DeviceConfigChanged struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	Version   int
+	ChangedBy string
+}
+
+func NewDeviceConfigChanged(deviceID valueobjects.DeviceID, version int, by string) DeviceConfigChanged {
+	return DeviceConfigChanged{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		Version:   version,
+		ChangedBy: by,
+	}
+}
+
+func (DeviceConfigChanged) EventName() string { return "DeviceConfigChanged" }
+
+// RolloutStarted is raised when an operator starts rolling a release out
+type RolloutStarted struct {
+	events.BaseEvent
+	RolloutID valueobjects.RolloutID
+	ReleaseID valueobjects.ReleaseID
+	Version   string
+	StartedBy string
+}
+
+func NewRolloutStarted(rolloutID valueobjects.RolloutID, releaseID valueobjects.ReleaseID, version, by string) RolloutStarted {
+	return RolloutStarted{
+		BaseEvent: events.NewBaseEvent(),
+		RolloutID: rolloutID,
+		ReleaseID: releaseID,
+		Version:   version,
+		StartedBy: by,
+	}
+}
+
+func (RolloutStarted) EventName() string { return "RolloutStarted" }
+
+// RolloutAdvanced is raised when a rollout moves to its next stage
+type RolloutAdvanced struct {
+	events.BaseEvent
+	RolloutID  valueobjects.RolloutID
+	Percent    int
+	AdvancedBy string
+}
+
+func NewRolloutAdvanced(rolloutID valueobjects.RolloutID, percent int, by string) RolloutAdvanced {
+	return RolloutAdvanced{
+		BaseEvent:  events.NewBaseEvent(),
+		RolloutID:  rolloutID,
+		Percent:    percent,
+		AdvancedBy: by,
+	}
+}
+
+func (RolloutAdvanced) EventName() string { return "RolloutAdvanced" }
+
+// RolloutAborted is raised when an operator stops a rollout
+type RolloutAborted struct {
+	events.BaseEvent
+	RolloutID valueobjects.RolloutID
+	AbortedBy string
+	Reason    string
+}
+
+func NewRolloutAborted(rolloutID valueobjects.RolloutID, by, reason string) RolloutAborted {
+	return RolloutAborted{
+		BaseEvent: events.NewBaseEvent(),
+		RolloutID: rolloutID,
+		AbortedBy: by,
+		Reason:    reason,
+	}
+}
+
+func (RolloutAborted) EventName() string { return "RolloutAborted" }
+
+// DeviceMaintenanceStarted is raised when field staff put a device into
+// maintenance; it sells nothing until the maintenance ends
+type DeviceMaintenanceStarted struct {
+	events.BaseEvent
+	DeviceID  valueobjects.DeviceID
+	StartedBy string
+	Reason    string
+}
+
+func NewDeviceMaintenanceStarted(deviceID valueobjects.DeviceID, by, reason string) DeviceMaintenanceStarted {
+	return DeviceMaintenanceStarted{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		StartedBy: by,
+		Reason:    reason,
+	}
+}
+
+func (DeviceMaintenanceStarted) EventName() string { return "DeviceMaintenanceStarted" }
+
+// DeviceMaintenanceEnded is raised when a device returns to service after maintenance
+type DeviceMaintenanceEnded struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	EndedBy  string
+}
+
+func NewDeviceMaintenanceEnded(deviceID valueobjects.DeviceID, by string) DeviceMaintenanceEnded {
+	return DeviceMaintenanceEnded{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		EndedBy:   by,
+	}
+}
+
+func (DeviceMaintenanceEnded) EventName() string { return "DeviceMaintenanceEnded" }
+
+// DeviceEventLogged is raised for every new entry in a device's event log,
+// for rules that watch door, tamper and power activity
+type DeviceEventLogged struct {
+	events.BaseEvent
+	DeviceID   valueobjects.DeviceID
+	Kind       DeviceEventKind
+	ObservedAt time.Time // when the device saw it, not when it was logged
+}
+
+func NewDeviceEventLogged(e *DeviceEvent) DeviceEventLogged {
+	return DeviceEventLogged{
+		BaseEvent:  events.NewBaseEvent(),
+		DeviceID:   e.DeviceID(),
+		Kind:       e.Kind(),
+		ObservedAt: e.OccurredAt(),
+	}
+}
+
+func (DeviceEventLogged) EventName() string { return "DeviceEventLogged" }
+
+// DeviceCommandIssued is raised when an operator queues a command for a
+// device; it wakes the device's open long-poll or WebSocket
+type DeviceCommandIssued struct {
+	events.BaseEvent
+	CommandID valueobjects.DeviceCommandID
+	DeviceID  valueobjects.DeviceID
+	Kind      CommandKind
+	IssuedBy  string
+}
+
+func NewDeviceCommandIssued(commandID valueobjects.DeviceCommandID, deviceID valueobjects.DeviceID, kind CommandKind, by string) DeviceCommandIssued {
+	return DeviceCommandIssued{
+		BaseEvent: events.NewBaseEvent(),
+		CommandID: commandID,
+		DeviceID:  deviceID,
+		Kind:      kind,
+		IssuedBy:  by,
+	}
+}
+
+func (DeviceCommandIssued) EventName() string { return "DeviceCommandIssued" }
+
+// DeviceCommandAcknowledged is raised when a device reports how a command went
+type DeviceCommandAcknowledged struct {
+	events.BaseEvent
+	CommandID valueobjects.DeviceCommandID
+	DeviceID  valueobjects.DeviceID
+	Kind      CommandKind
+	Status    CommandStatus
+}
+
+func NewDeviceCommandAcknowledged(commandID valueobjects.DeviceCommandID, deviceID valueobjects.DeviceID, kind CommandKind, status CommandStatus) DeviceCommandAcknowledged {
+	return DeviceCommandAcknowledged{
+		BaseEvent: events.NewBaseEvent(),
+		CommandID: commandID,
+		DeviceID:  deviceID,
+		Kind:      kind,
+		Status:    status,
+	}
+}
+
+func (DeviceCommandAcknowledged) EventName() string { return "DeviceCommandAcknowledged" }
+
+// DiagnosticsReported is raised when a device uploads its self-test report
+type DiagnosticsReported struct {
+	events.BaseEvent
+	CommandID valueobjects.DeviceCommandID
+	DeviceID  valueobjects.DeviceID
+	Passed    bool
+}
+
+func NewDiagnosticsReported(commandID valueobjects.DeviceCommandID, deviceID valueobjects.DeviceID, passed bool) DiagnosticsReported {
+	return DiagnosticsReported{
+		BaseEvent: events.NewBaseEvent(),
+		CommandID: commandID,
+		DeviceID:  deviceID,
+		Passed:    passed,
+	}
+}
+
+func (DiagnosticsReported) EventName() string { return "DiagnosticsReported" }
+
+// DeviceWentOffline is raised when a device that used to send heartbeats has
+// been silent for longer than the offline window; the transaction context may
+// cancel the session left open on it
+type DeviceWentOffline struct {
+	events.BaseEvent
+	DeviceID   valueobjects.DeviceID
+	MachineID  string
+	LastSeenAt time.Time
+}
+
+func NewDeviceWentOffline(deviceID valueobjects.DeviceID, machineID string, lastSeenAt time.Time) DeviceWentOffline {
+	return DeviceWentOffline{
+		BaseEvent:  events.NewBaseEvent(),
+		DeviceID:   deviceID,
+		MachineID:  machineID,
+		LastSeenAt: lastSeenAt,
+	}
+}
+
+func (DeviceWentOffline) EventName() string { return "DeviceWentOffline" }
+
+// RestockSessionStarted is raised when an operator opens a device to restock
+// it; the device starts no customer sessions until the session is closed
+type RestockSessionStarted struct {
+	events.BaseEvent
+	RestockSessionID valueobjects.RestockSessionID
+	DeviceID         valueobjects.DeviceID
+	StartedBy        string
+}
+
+func NewRestockSessionStarted(id valueobjects.RestockSessionID, deviceID valueobjects.DeviceID, by string) RestockSessionStarted {
+	return RestockSessionStarted{
+		BaseEvent:        events.NewBaseEvent(),
+		RestockSessionID: id,
+		DeviceID:         deviceID,
+		StartedBy:        by,
+	}
+}
+
+func (RestockSessionStarted) EventName() string { return "RestockSessionStarted" }
+
+// RestockSessionCompleted is raised when a restock session's units were added
+// to the device's inventory
+type RestockSessionCompleted struct {
+	events.BaseEvent
+	RestockSessionID valueobjects.RestockSessionID
+	DeviceID         valueobjects.DeviceID
+	CompletedBy      string
+	Units            map[string]int // SKU code -> units loaded
+}
+
+func NewRestockSessionCompleted(id valueobjects.RestockSessionID, deviceID valueobjects.DeviceID, by string, units map[string]int) RestockSessionCompleted {
+	return RestockSessionCompleted{
+		BaseEvent:        events.NewBaseEvent(),
+		RestockSessionID: id,
+		DeviceID:         deviceID,
+		CompletedBy:      by,
+		Units:            units,
+	}
+}
+
+func (RestockSessionCompleted) EventName() string { return "RestockSessionCompleted" }
+
+// RestockSessionCancelled is raised when a restock session is closed without
+// touching the inventory
+type RestockSessionCancelled struct {
+	events.BaseEvent
+	RestockSessionID valueobjects.RestockSessionID
+	DeviceID         valueobjects.DeviceID
+	CancelledBy      string
+}
+
+func NewRestockSessionCancelled(id valueobjects.RestockSessionID, deviceID valueobjects.DeviceID, by string) RestockSessionCancelled {
+	return RestockSessionCancelled{
+		BaseEvent:        events.NewBaseEvent(),
+		RestockSessionID: id,
+		DeviceID:         deviceID,
+		CancelledBy:      by,
+	}
+}
+
+func (RestockSessionCancelled) EventName() string { return "RestockSessionCancelled" }
+
+// CameraCalibrated is raised when a device uploads a new camera calibration
+type CameraCalibrated struct {
+	events.BaseEvent
+	DeviceID valueobjects.DeviceID
+	Version  int
+}
+
+func NewCameraCalibrated(deviceID valueobjects.DeviceID, version int) CameraCalibrated {
+	return CameraCalibrated{
+		BaseEvent: events.NewBaseEvent(),
+		DeviceID:  deviceID,
+		Version:   version,
+	}
+}
+
+func (CameraCalibrated) EventName() string { return "CameraCalibrated" }
+
+// ScaleCalibrated is raised when a device recalibrates its scale. ErrorGrams
+// is how far off the scale was found before it was corrected.
+type ScaleCalibrated struct {
+	events.BaseEvent
+	CalibrationID valueobjects.ScaleCalibrationID
+	DeviceID      valueobjects.DeviceID
+	ErrorGrams    float64
+}
+
+func NewScaleCalibrated(id valueobjects.ScaleCalibrationID, deviceID valueobjects.DeviceID, errorGrams float64) ScaleCalibrated {
+	return ScaleCalibrated{
+		BaseEvent:     events.NewBaseEvent(),
+		CalibrationID: id,
+		DeviceID:      deviceID,
+		ErrorGrams:    errorGrams,
+	}
+}
+
+func (ScaleCalibrated) EventName() string { return "ScaleCalibrated" }
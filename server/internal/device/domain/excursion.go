@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// TemperatureExcursion records a period where a fresh-food cabinet stayed
+// above its safe temperature long enough to block the device. It stays open
+// until an operator clears it.
+type TemperatureExcursion struct {
+	id           valueobjects.ExcursionID
+	deviceID     valueobjects.DeviceID
+	startedAt    time.Time // first reading above the limit
+	detectedAt   time.Time // reading that blocked the device
+	limitCelsius float64
+	peakCelsius  float64
+	clearedAt    *time.Time
+	clearedBy    string
+	note         string
+
+	domainEvents []events.DomainEvent
+}
+
+// NewTemperatureExcursion records an excursion that has just blocked a device
+func NewTemperatureExcursion(deviceID valueobjects.DeviceID, startedAt, detectedAt time.Time, limitCelsius, celsius float64) *TemperatureExcursion {
+	e := &TemperatureExcursion{
+		id:           valueobjects.NewExcursionID(),
+		deviceID:     deviceID,
+		startedAt:    startedAt.UTC(),
+		detectedAt:   detectedAt.UTC(),
+		limitCelsius: limitCelsius,
+		peakCelsius:  celsius,
+	}
+
+	e.domainEvents = append(e.domainEvents, NewTemperatureExcursionRecorded(e.id, deviceID, celsius, limitCelsius))
+
+	return e
+}
+
+// ReconstituteExcursion rebuilds a TemperatureExcursion from persistence
+func ReconstituteExcursion(
+	id valueobjects.ExcursionID,
+	deviceID valueobjects.DeviceID,
+	startedAt, detectedAt time.Time,
+	limitCelsius, peakCelsius float64,
+	clearedAt *time.Time,
+	clearedBy, note string,
+) *TemperatureExcursion {
+	return &TemperatureExcursion{
+		id:           id,
+		deviceID:     deviceID,
+		startedAt:    startedAt,
+		detectedAt:   detectedAt,
+		limitCelsius: limitCelsius,
+		peakCelsius:  peakCelsius,
+		clearedAt:    clearedAt,
+		clearedBy:    clearedBy,
+		note:         note,
+	}
+}
+
+// Getters
+func (e *TemperatureExcursion) ID() valueobjects.ExcursionID    { return e.id }
+func (e *TemperatureExcursion) DeviceID() valueobjects.DeviceID { return e.deviceID }
+func (e *TemperatureExcursion) StartedAt() time.Time            { return e.startedAt }
+func (e *TemperatureExcursion) DetectedAt() time.Time           { return e.detectedAt }
+func (e *TemperatureExcursion) LimitCelsius() float64           { return e.limitCelsius }
+func (e *TemperatureExcursion) PeakCelsius() float64            { return e.peakCelsius }
+func (e *TemperatureExcursion) ClearedAt() *time.Time           { return e.clearedAt }
+func (e *TemperatureExcursion) ClearedBy() string               { return e.clearedBy }
+func (e *TemperatureExcursion) Note() string                    { return e.note }
+func (e *TemperatureExcursion) IsOpen() bool                    { return e.clearedAt == nil }
+
+// Business methods
+
+// RecordReading tracks the peak temperature while the excursion is open
+func (e *TemperatureExcursion) RecordReading(celsius float64) {
+	if e.IsOpen() && celsius > e.peakCelsius {
+		e.peakCelsius = celsius
+	}
+}
+
+// Clear closes the excursion on behalf of an operator
+func (e *TemperatureExcursion) Clear(clearedBy, note string) error {
+	if clearedBy == "" {
+		return ErrClearedByRequired
+	}
+	if !e.IsOpen() {
+		return ErrExcursionCleared
+	}
+
+	now := time.Now().UTC()
+	e.clearedAt = &now
+	e.clearedBy = clearedBy
+	e.note = note
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (e *TemperatureExcursion) PullEvents() []events.DomainEvent {
+	evts := e.domainEvents
+	e.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// maxVersionLength bounds the firmware and app versions a device reports
+const maxVersionLength = 50
+
+// Liveness is what a device last reported about itself in a heartbeat
+type Liveness struct {
+	LastSeenAt      *time.Time // nil until the first heartbeat
+	OfflineSince    *time.Time // set when the device is found silent, cleared by its next heartbeat
+	FirmwareVersion string
+	AppVersion      string
+	ModelVersion    string // detection model the device runs
+}
+
+// IsStale reports whether the device has not been heard from for longer than
+// after; a device that never sent a heartbeat is stale
+func (l Liveness) IsStale(now time.Time, after time.Duration) bool {
+	return l.LastSeenAt == nil || now.Sub(*l.LastSeenAt) > after
+}
+
+// newDeviceKey returns a random device key and the hash kept of it
+func newDeviceKey() (string, []byte) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		panic("device key: " + err.Error())
+	}
+	key := hex.EncodeToString(raw)
+	return key, hashDeviceKey(key)
+}
+
+func hashDeviceKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// IssueKey gives the device a new key to authenticate its own requests with
+// and returns it; only its hash is kept, and any earlier key stops working
+func (d *Device) IssueKey() string {
+	key, hash := newDeviceKey()
+	d.keyHash = hash
+	d.updatedAt = time.Now().UTC()
+	return key
+}
+
+// Authenticate reports whether key is the device's current key
+func (d *Device) Authenticate(key string) bool {
+	if len(d.keyHash) == 0 || key == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hashDeviceKey(key), d.keyHash) == 1
+}
+
+// RecordHeartbeat marks the device as alive at the given time with the
+// versions it runs. An older heartbeat arriving late is ignored.
+func (d *Device) RecordHeartbeat(at time.Time, firmwareVersion, appVersion, modelVersion string) error {
+	firmwareVersion, appVersion = strings.TrimSpace(firmwareVersion), strings.TrimSpace(appVersion)
+	if len(firmwareVersion) > maxVersionLength || len(appVersion) > maxVersionLength {
+		return ErrInvalidVersion
+	}
+	modelVersion = strings.TrimSpace(modelVersion)
+	if len(modelVersion) > maxModelVersionLength {
+		return ErrInvalidModelVersion
+	}
+	if d.liveness.LastSeenAt != nil && at.Before(*d.liveness.LastSeenAt) {
+		return nil
+	}
+
+	seenAt := at.UTC()
+	d.liveness.LastSeenAt = &seenAt
+	d.liveness.OfflineSince = nil
+	if firmwareVersion != "" {
+		d.liveness.FirmwareVersion = firmwareVersion
+	}
+	if appVersion != "" {
+		d.liveness.AppVersion = appVersion
+	}
+	if modelVersion != "" {
+		d.liveness.ModelVersion = modelVersion
+	}
+	return nil
+}
+
+// MarkOffline records that the device has been silent for longer than after
+// and reports whether it just went offline. A device already offline, one
+// that never sent a heartbeat and a decommissioned one are left alone.
+func (d *Device) MarkOffline(now time.Time, after time.Duration) bool {
+	if d.IsDecommissioned() || d.liveness.LastSeenAt == nil || d.liveness.OfflineSince != nil {
+		return false
+	}
+	if !d.liveness.IsStale(now, after) {
+		return false
+	}
+
+	since := now.UTC()
+	d.liveness.OfflineSince = &since
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceWentOffline(d.id, d.machineID, *d.liveness.LastSeenAt))
+	return true
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// Inventory is the counted stock of each SKU in one device. Field staff add
+// the units they load and every completed session takes out the units it
+// sold. Unlike StockEstimate it is a ledger kept by the server, so it only
+// drifts through breakage, theft or miscounted restocks.
+type Inventory struct {
+	deviceID  valueobjects.DeviceID
+	levels    map[string]int // SKU code -> units in the device
+	updatedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewInventory creates an empty inventory for a device that was never restocked
+func NewInventory(deviceID valueobjects.DeviceID) *Inventory {
+	return &Inventory{
+		deviceID: deviceID,
+		levels:   make(map[string]int),
+	}
+}
+
+// ReconstituteInventory rebuilds an Inventory from persistence
+func ReconstituteInventory(deviceID valueobjects.DeviceID, levels map[string]int, updatedAt time.Time) *Inventory {
+	if levels == nil {
+		levels = make(map[string]int)
+	}
+	return &Inventory{
+		deviceID:  deviceID,
+		levels:    levels,
+		updatedAt: updatedAt,
+	}
+}
+
+// Getters
+func (i *Inventory) DeviceID() valueobjects.DeviceID { return i.deviceID }
+func (i *Inventory) UpdatedAt() time.Time            { return i.updatedAt }
+
+// Quantity returns the units of a SKU in the device
+func (i *Inventory) Quantity(skuCode string) int {
+	return i.levels[skuCode]
+}
+
+// Levels returns a copy of the units per SKU code
+func (i *Inventory) Levels() map[string]int {
+	levels := make(map[string]int, len(i.levels))
+	for code, qty := range i.levels {
+		levels[code] = qty
+	}
+	return levels
+}
+
+// SKUCodes lists the SKUs the device has been stocked with, sorted
+func (i *Inventory) SKUCodes() []string {
+	codes := make([]string, 0, len(i.levels))
+	for code := range i.levels {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Business methods
+
+// Restock adds the units field staff loaded into the device
+func (i *Inventory) Restock(units map[string]int, restockedBy string, at time.Time) error {
+	if restockedBy == "" {
+		return ErrRestockedByRequired
+	}
+	if len(units) == 0 {
+		return ErrInvalidRestock
+	}
+	for code, qty := range units {
+		if strings.TrimSpace(code) == "" || qty <= 0 {
+			return ErrInvalidRestock
+		}
+	}
+
+	for _, code := range sortedCodes(units) {
+		i.levels[code] += units[code]
+		i.domainEvents = append(i.domainEvents, NewInventoryRestocked(i.deviceID, code, units[code], i.levels[code], restockedBy))
+	}
+	i.updatedAt = at.UTC()
+
+	return nil
+}
+
+// RecordSale takes the units a completed session sold out of the device. A
+// count never goes below zero: selling more than was counted means the last
+// restock was miscounted, and the next one corrects it. A StockLow event is
+// raised when a SKU falls below lowThreshold, but not again while it stays there.
+func (i *Inventory) RecordSale(sessionID string, sold map[string]int, at time.Time, lowThreshold int) {
+	for _, code := range sortedCodes(sold) {
+		if sold[code] <= 0 {
+			continue
+		}
+		previous := i.levels[code]
+		i.levels[code] = max(previous-sold[code], 0)
+		i.domainEvents = append(i.domainEvents, NewInventorySold(i.deviceID, sessionID, code, sold[code], i.levels[code]))
+		if previous >= lowThreshold && i.levels[code] < lowThreshold {
+			i.domainEvents = append(i.domainEvents, NewStockLow(i.deviceID, code, i.levels[code], lowThreshold))
+		}
+	}
+	i.updatedAt = at.UTC()
+}
+
+// IsLow reports whether a SKU the device was stocked with is below the threshold
+func (i *Inventory) IsLow(skuCode string, lowThreshold int) bool {
+	qty, ok := i.levels[skuCode]
+	return ok && qty < lowThreshold
+}
+
+// PullEvents returns and clears domain events
+func (i *Inventory) PullEvents() []events.DomainEvent {
+	evts := i.domainEvents
+	i.domainEvents = nil
+	return evts
+}
+
+// sortedCodes keeps event order stable across map iterations
+func sortedCodes(units map[string]int) []string {
+	codes := make([]string, 0, len(units))
+	for code := range units {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Maintenance records who put a device into maintenance, when and why
+type Maintenance struct {
+	Since  time.Time
+	By     string
+	Reason string
+}
+
+// EnterMaintenance stops an active device from selling while field staff
+// work on it; opening its door raises no security incident meanwhile
+func (d *Device) EnterMaintenance(by, reason string, at time.Time) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrMaintainedByRequired
+	}
+	switch d.status {
+	case DeviceStatusActive:
+	case DeviceStatusMaintenance:
+		return ErrDeviceInMaintenance
+	case DeviceStatusBlocked:
+		return ErrDeviceBlocked
+	case DeviceStatusDecommissioned:
+		return ErrDeviceDecommissioned
+	default:
+		return ErrDeviceInactive
+	}
+
+	reason = strings.TrimSpace(reason)
+	d.maintenance = &Maintenance{Since: at.UTC(), By: by, Reason: reason}
+	d.status = DeviceStatusMaintenance
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceMaintenanceStarted(d.id, by, reason))
+
+	return nil
+}
+
+// ExitMaintenance puts the device back into service
+func (d *Device) ExitMaintenance(by string) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrMaintainedByRequired
+	}
+	if d.status != DeviceStatusMaintenance {
+		return ErrDeviceNotInMaintenance
+	}
+
+	d.maintenance = nil
+	d.status = DeviceStatusActive
+	d.updatedAt = time.Now().UTC()
+	d.domainEvents = append(d.domainEvents, NewDeviceMaintenanceEnded(d.id, by))
+
+	return nil
+}
+
+// Maintenance is set while the device is under maintenance
+func (d *Device) Maintenance() *Maintenance { return d.maintenance }
+
+func (d *Device) IsInMaintenance() bool { return d.status == DeviceStatusMaintenance }
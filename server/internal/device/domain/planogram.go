@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ShelfSKU identifies a SKU on a given shelf of a device (shelves are numbered from 1, top down)
+type ShelfSKU struct {
+	Shelf   int
+	SKUCode string
+}
+
+// PlanogramFacing is one planned position: how many units of a SKU face out on a shelf
+type PlanogramFacing struct {
+	Shelf   int
+	SKUCode string
+	Facings int
+}
+
+// Planogram is the merchandising layout a device is designed to be stocked with.
+// Replacing the layout bumps the version so compliance reports stay traceable.
+type Planogram struct {
+	deviceID  valueobjects.DeviceID
+	version   int
+	facings   []PlanogramFacing
+	updatedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewPlanogram assigns a first layout to a device
+func NewPlanogram(deviceID valueobjects.DeviceID, facings []PlanogramFacing) (*Planogram, error) {
+	p := &Planogram{deviceID: deviceID}
+	if err := p.Replace(facings); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReconstitutePlanogram rebuilds a Planogram from persistence
+func ReconstitutePlanogram(deviceID valueobjects.DeviceID, version int, facings []PlanogramFacing, updatedAt time.Time) *Planogram {
+	return &Planogram{
+		deviceID:  deviceID,
+		version:   version,
+		facings:   facings,
+		updatedAt: updatedAt,
+	}
+}
+
+// Getters
+func (p *Planogram) DeviceID() valueobjects.DeviceID { return p.deviceID }
+func (p *Planogram) Version() int                    { return p.version }
+func (p *Planogram) UpdatedAt() time.Time            { return p.updatedAt }
+
+// Facings returns a copy of the planned positions, ordered by shelf then SKU code
+func (p *Planogram) Facings() []PlanogramFacing {
+	return append([]PlanogramFacing(nil), p.facings...)
+}
+
+// Expected returns the planned number of facings per shelf and SKU
+func (p *Planogram) Expected() map[ShelfSKU]int {
+	expected := make(map[ShelfSKU]int, len(p.facings))
+	for _, f := range p.facings {
+		expected[ShelfSKU{Shelf: f.Shelf, SKUCode: f.SKUCode}] = f.Facings
+	}
+	return expected
+}
+
+// Business methods
+
+// Replace swaps in a new layout. Every facing needs a shelf, a SKU code and a
+// positive count, and a SKU may appear only once per shelf.
+func (p *Planogram) Replace(facings []PlanogramFacing) error {
+	normalized, err := normalizeFacings(facings)
+	if err != nil {
+		return err
+	}
+
+	p.facings = normalized
+	p.version++
+	p.updatedAt = time.Now().UTC()
+
+	p.domainEvents = append(p.domainEvents, NewPlanogramAssigned(p.deviceID, p.version))
+
+	return nil
+}
+
+// normalizeFacings validates a layout and orders it by shelf then SKU code
+func normalizeFacings(facings []PlanogramFacing) ([]PlanogramFacing, error) {
+	if len(facings) == 0 {
+		return nil, ErrEmptyPlanogram
+	}
+
+	seen := make(map[ShelfSKU]bool, len(facings))
+	normalized := make([]PlanogramFacing, 0, len(facings))
+	for _, f := range facings {
+		f.SKUCode = strings.TrimSpace(f.SKUCode)
+		if f.Shelf < 1 || f.SKUCode == "" || f.Facings < 1 {
+			return nil, ErrInvalidFacing
+		}
+		key := ShelfSKU{Shelf: f.Shelf, SKUCode: f.SKUCode}
+		if seen[key] {
+			return nil, ErrDuplicateFacing
+		}
+		seen[key] = true
+		normalized = append(normalized, f)
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		if normalized[i].Shelf != normalized[j].Shelf {
+			return normalized[i].Shelf < normalized[j].Shelf
+		}
+		return normalized[i].SKUCode < normalized[j].SKUCode
+	})
+	return normalized, nil
+}
+
+// PullEvents returns and clears domain events
+func (p *Planogram) PullEvents() []events.DomainEvent {
+	evts := p.domainEvents
+	p.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// MaxReleaseBytes bounds the size of an uploaded release artifact
+const MaxReleaseBytes = 64 << 20
+
+// ReleaseKind tells what a release updates on the device
+type ReleaseKind string
+
+const (
+	ReleaseKindFirmware ReleaseKind = "firmware"
+	ReleaseKindApp      ReleaseKind = "app"
+)
+
+// Release is an uploaded firmware or app build that can be rolled out to
+// devices. The artifact itself is kept in object storage under ObjectKey.
+type Release struct {
+	ID         valueobjects.ReleaseID
+	Kind       ReleaseKind
+	Version    string
+	ObjectKey  string
+	SizeBytes  int
+	Checksum   string // hex SHA-256 of the artifact, checked by devices after download
+	Notes      string
+	UploadedBy string
+	CreatedAt  time.Time
+}
+
+// NewRelease validates an uploaded artifact and describes it as a release
+func NewRelease(kind ReleaseKind, version string, artifact []byte, notes, uploadedBy string) (Release, error) {
+	uploadedBy = strings.TrimSpace(uploadedBy)
+	if uploadedBy == "" {
+		return Release{}, ErrUploadedByRequired
+	}
+	version = strings.TrimSpace(version)
+	if kind != ReleaseKindFirmware && kind != ReleaseKindApp ||
+		version == "" || len(version) > maxVersionLength ||
+		len(artifact) == 0 || len(artifact) > MaxReleaseBytes {
+		return Release{}, ErrInvalidRelease
+	}
+
+	id := valueobjects.NewReleaseID()
+	sum := sha256.Sum256(artifact)
+	return Release{
+		ID:         id,
+		Kind:       kind,
+		Version:    version,
+		ObjectKey:  "releases/" + string(kind) + "/" + id.String() + ".bin",
+		SizeBytes:  len(artifact),
+		Checksum:   hex.EncodeToString(sum[:]),
+		Notes:      strings.TrimSpace(notes),
+		UploadedBy: uploadedBy,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// RunningVersion is the version of the release's kind the device last reported
+func (d *Device) RunningVersion(kind ReleaseKind) string {
+	if kind == ReleaseKindFirmware {
+		return d.liveness.FirmwareVersion
+	}
+	return d.liveness.AppVersion
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
@@ -11,4 +12,176 @@ type DeviceRepository interface {
 	Save(ctx context.Context, device *Device) error
 	FindByID(ctx context.Context, id valueobjects.DeviceID) (*Device, error)
 	FindByMachineID(ctx context.Context, machineID string) (*Device, error)
+	FindAll(ctx context.Context) ([]*Device, error)
+	FindByGroupID(ctx context.Context, groupID valueobjects.DeviceGroupID) ([]*Device, error)
+}
+
+// EnrollmentTokenRepository persists enrollment tokens by their hash
+type EnrollmentTokenRepository interface {
+	// Save stores the token; saving a redeemed token fails with
+	// ErrEnrollmentTokenRedeemed if it was redeemed concurrently
+	Save(ctx context.Context, token *EnrollmentToken) error
+	FindByHash(ctx context.Context, hash []byte) (*EnrollmentToken, error)
+}
+
+// DeviceGroupRepository persists device groups; membership is kept on the devices
+type DeviceGroupRepository interface {
+	Save(ctx context.Context, group *DeviceGroup) error
+	FindByID(ctx context.Context, id valueobjects.DeviceGroupID) (*DeviceGroup, error)
+	FindAll(ctx context.Context) ([]*DeviceGroup, error)
+}
+
+// StockEstimateRepository persists the latest vision-based stock estimate per device
+type StockEstimateRepository interface {
+	Save(ctx context.Context, estimate *StockEstimate) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*StockEstimate, error)
+}
+
+// ExcursionRepository persists temperature excursions
+type ExcursionRepository interface {
+	Save(ctx context.Context, excursion *TemperatureExcursion) error
+	FindOpenByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*TemperatureExcursion, error)
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*TemperatureExcursion, error)
+	// ArchiveByDeviceID hides the device's excursions from the finders and
+	// returns how many were archived
+	ArchiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, at time.Time) (int, error)
+}
+
+// IncidentRepository persists security incidents
+type IncidentRepository interface {
+	Save(ctx context.Context, incident *SecurityIncident) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*SecurityIncident, error)
+	// ArchiveByDeviceID hides the device's incidents from the finders and
+	// returns how many were archived
+	ArchiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, at time.Time) (int, error)
+}
+
+// DeviceEventRepository stores the device event log. Entries are never
+// updated or deleted.
+type DeviceEventRepository interface {
+	// Append stores the events, skipping any the device already reported
+	// with the same kind and time, and returns the ones that were new
+	Append(ctx context.Context, events []*DeviceEvent) ([]*DeviceEvent, error)
+	// FindByDeviceID returns at most limit of the device's events that
+	// occurred in [from, to), newest first; a zero from or to is unbounded
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, from, to time.Time, limit int) ([]*DeviceEvent, error)
+}
+
+// DeviceCommandRepository persists the command queue of every device
+type DeviceCommandRepository interface {
+	Save(ctx context.Context, command *DeviceCommand) error
+	FindByID(ctx context.Context, id valueobjects.DeviceCommandID) (*DeviceCommand, error)
+	// FindUnacknowledged returns the device's commands still waiting for an
+	// acknowledgement, oldest first
+	FindUnacknowledged(ctx context.Context, deviceID valueobjects.DeviceID) ([]*DeviceCommand, error)
+	// FindByDeviceID returns the device's latest limit commands, newest first
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*DeviceCommand, error)
+}
+
+// DiagnosticReportRepository persists the self-test reports devices upload,
+// one per run_diagnostics command
+type DiagnosticReportRepository interface {
+	Save(ctx context.Context, report *DiagnosticReport) error
+	FindByCommandID(ctx context.Context, commandID valueobjects.DeviceCommandID) (*DiagnosticReport, error)
+}
+
+// PlanogramRepository persists the current planogram per device
+type PlanogramRepository interface {
+	Save(ctx context.Context, planogram *Planogram) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*Planogram, error)
+}
+
+// ConfigShadowRepository persists the desired and reported configuration per device
+type ConfigShadowRepository interface {
+	Save(ctx context.Context, shadow *ConfigShadow) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*ConfigShadow, error)
+}
+
+// CameraCalibrationRepository persists the latest camera calibration per device
+type CameraCalibrationRepository interface {
+	Save(ctx context.Context, calibration *CameraCalibration) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*CameraCalibration, error)
+}
+
+// ReleaseRepository persists release metadata; the artifacts are kept in
+// object storage
+type ReleaseRepository interface {
+	// Save stores a new release; a release of the same kind and version
+	// fails with ErrDuplicateRelease
+	Save(ctx context.Context, release Release) error
+	FindByID(ctx context.Context, id valueobjects.ReleaseID) (Release, error)
+	// FindAll lists the releases of one kind, or of every kind when kind is
+	// empty, newest first
+	FindAll(ctx context.Context, kind ReleaseKind) ([]Release, error)
+}
+
+// RolloutRepository persists rollouts and the update status their devices report
+type RolloutRepository interface {
+	Save(ctx context.Context, rollout *Rollout) error
+	FindByID(ctx context.Context, id valueobjects.RolloutID) (*Rollout, error)
+	// FindAll lists every rollout, newest first
+	FindAll(ctx context.Context) ([]*Rollout, error)
+	// FindByGroupID lists the rollouts targeting the group, newest first
+	FindByGroupID(ctx context.Context, groupID valueobjects.DeviceGroupID) ([]*Rollout, error)
+	SaveDeviceUpdate(ctx context.Context, update DeviceUpdate) error
+	FindDeviceUpdate(ctx context.Context, rolloutID valueobjects.RolloutID, deviceID valueobjects.DeviceID) (DeviceUpdate, error)
+	FindDeviceUpdates(ctx context.Context, rolloutID valueobjects.RolloutID) ([]DeviceUpdate, error)
+}
+
+// SalesHoursRepository persists the sales hours per device
+type SalesHoursRepository interface {
+	Save(ctx context.Context, hours *SalesHours) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*SalesHours, error)
+}
+
+// StockBatchRepository persists the batches loaded into devices
+type StockBatchRepository interface {
+	Save(ctx context.Context, batch *StockBatch) error
+	FindByID(ctx context.Context, id valueobjects.BatchID) (*StockBatch, error)
+	FindOpenByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*StockBatch, error)
+	// FindOpen returns the open batches of every device
+	FindOpen(ctx context.Context) ([]*StockBatch, error)
+	// FindWrittenOffBetween returns the batches written off in [from, to),
+	// on one device or on every device when deviceID is zero
+	FindWrittenOffBetween(ctx context.Context, deviceID valueobjects.DeviceID, from, to time.Time) ([]*StockBatch, error)
+}
+
+// ComplianceReportRepository persists planogram compliance reports
+type ComplianceReportRepository interface {
+	Save(ctx context.Context, report *ComplianceReport) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*ComplianceReport, error)
+}
+
+// InventoryRepository persists the counted stock per device
+type InventoryRepository interface {
+	Save(ctx context.Context, inventory *Inventory) error
+	// SaveSale saves the inventory after taking a session's sale out of it.
+	// Each session is applied once; a repeat returns ErrSaleAlreadyRecorded
+	// and leaves the inventory unchanged.
+	SaveSale(ctx context.Context, inventory *Inventory, sessionID string) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*Inventory, error)
+}
+
+// AssortmentRepository persists the SKUs assigned to each device. A device
+// without assigned SKUs has no assortment and FindByDeviceID returns
+// ErrAssortmentNotFound.
+type AssortmentRepository interface {
+	Save(ctx context.Context, assortment *Assortment) error
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*Assortment, error)
+}
+
+// RestockSessionRepository persists operator restock sessions
+type RestockSessionRepository interface {
+	Save(ctx context.Context, session *RestockSession) error
+	FindByID(ctx context.Context, id valueobjects.RestockSessionID) (*RestockSession, error)
+	// FindByDeviceID lists the device's latest limit restock sessions, newest first
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*RestockSession, error)
+}
+
+// ScaleCalibrationRepository stores the scale calibration history of every
+// device. Calibrations are never updated.
+type ScaleCalibrationRepository interface {
+	Save(ctx context.Context, calibration *ScaleCalibration) error
+	// FindByDeviceID returns the device's latest limit calibrations, newest first
+	FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*ScaleCalibration, error)
 }
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RestockSessionStatus tracks a restock session from the operator opening
+// the device to closing it again
+type RestockSessionStatus string
+
+const (
+	RestockStatusOpen      RestockSessionStatus = "open"
+	RestockStatusCompleted RestockSessionStatus = "completed" // units added to the inventory
+	RestockStatusCancelled RestockSessionStatus = "cancelled" // units discarded
+)
+
+// RestockSession is an operator's visit to refill a device. While it is open
+// the device starts no customer sessions and opening its door raises no
+// incident. The units loaded are counted per SKU as the operator goes and
+// reach the inventory only when the session completes, so an abandoned
+// visit leaves the inventory untouched.
+type RestockSession struct {
+	id          valueobjects.RestockSessionID
+	deviceID    valueobjects.DeviceID
+	status      RestockSessionStatus
+	startedBy   string
+	startedAt   time.Time
+	units       map[string]int // SKU code -> units loaded
+	levelsAfter map[string]int // SKU code -> units in the device once completed
+	closedBy    string
+	closedAt    *time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// StartRestock opens a restock session on the device. Only an active device
+// without a restock session open can be restocked this way.
+func (d *Device) StartRestock(by string, at time.Time) (*RestockSession, error) {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return nil, ErrRestockedByRequired
+	}
+	switch {
+	case d.IsDecommissioned():
+		return nil, ErrDeviceDecommissioned
+	case d.IsBlocked():
+		return nil, ErrDeviceBlocked
+	case d.IsInMaintenance():
+		return nil, ErrDeviceInMaintenance
+	case !d.IsActive():
+		return nil, ErrDeviceInactive
+	case d.restockSessionID != nil:
+		return nil, ErrRestockSessionOpen
+	}
+
+	s := &RestockSession{
+		id:        valueobjects.NewRestockSessionID(),
+		deviceID:  d.id,
+		status:    RestockStatusOpen,
+		startedBy: by,
+		startedAt: at.UTC(),
+		units:     make(map[string]int),
+	}
+	d.restockSessionID = &s.id
+	d.updatedAt = time.Now().UTC()
+	s.domainEvents = append(s.domainEvents, NewRestockSessionStarted(s.id, d.id, by))
+
+	return s, nil
+}
+
+// EndRestock lets the device sell again once its restock session is closed
+func (d *Device) EndRestock(session *RestockSession) {
+	if d.restockSessionID == nil || *d.restockSessionID != session.id {
+		return
+	}
+	d.restockSessionID = nil
+	d.updatedAt = time.Now().UTC()
+}
+
+// RestockSessionID is the restock session open on the device, if any
+func (d *Device) RestockSessionID() *valueobjects.RestockSessionID { return d.restockSessionID }
+
+func (d *Device) IsRestocking() bool { return d.restockSessionID != nil }
+
+// ReconstituteRestockSession rebuilds a RestockSession from persistence
+func ReconstituteRestockSession(
+	id valueobjects.RestockSessionID,
+	deviceID valueobjects.DeviceID,
+	status RestockSessionStatus,
+	startedBy string,
+	startedAt time.Time,
+	units, levelsAfter map[string]int,
+	closedBy string,
+	closedAt *time.Time,
+) *RestockSession {
+	if units == nil {
+		units = make(map[string]int)
+	}
+	return &RestockSession{
+		id:          id,
+		deviceID:    deviceID,
+		status:      status,
+		startedBy:   startedBy,
+		startedAt:   startedAt,
+		units:       units,
+		levelsAfter: levelsAfter,
+		closedBy:    closedBy,
+		closedAt:    closedAt,
+	}
+}
+
+// AddUnits counts units the operator loaded; a SKU counted twice adds up
+func (s *RestockSession) AddUnits(units map[string]int) error {
+	if s.status != RestockStatusOpen {
+		return ErrRestockSessionClosed
+	}
+	if len(units) == 0 {
+		return ErrInvalidRestock
+	}
+	for code, qty := range units {
+		if strings.TrimSpace(code) == "" || qty <= 0 {
+			return ErrInvalidRestock
+		}
+	}
+
+	for code, qty := range units {
+		s.units[strings.TrimSpace(code)] += qty
+	}
+	return nil
+}
+
+// Complete closes the session once its units are in the inventory, keeping
+// the resulting levels of the SKUs loaded for the report
+func (s *RestockSession) Complete(by string, inventory *Inventory, at time.Time) error {
+	if err := s.close(by, RestockStatusCompleted, at); err != nil {
+		return err
+	}
+
+	s.levelsAfter = make(map[string]int, len(s.units))
+	for code := range s.units {
+		s.levelsAfter[code] = inventory.Quantity(code)
+	}
+	s.domainEvents = append(s.domainEvents, NewRestockSessionCompleted(s.id, s.deviceID, s.closedBy, s.Units()))
+	return nil
+}
+
+// Cancel closes the session without touching the inventory
+func (s *RestockSession) Cancel(by string, at time.Time) error {
+	if err := s.close(by, RestockStatusCancelled, at); err != nil {
+		return err
+	}
+	s.domainEvents = append(s.domainEvents, NewRestockSessionCancelled(s.id, s.deviceID, s.closedBy))
+	return nil
+}
+
+func (s *RestockSession) close(by string, status RestockSessionStatus, at time.Time) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrRestockedByRequired
+	}
+	if s.status != RestockStatusOpen {
+		return ErrRestockSessionClosed
+	}
+
+	closedAt := at.UTC()
+	s.status = status
+	s.closedBy = by
+	s.closedAt = &closedAt
+	return nil
+}
+
+// Getters
+func (s *RestockSession) ID() valueobjects.RestockSessionID { return s.id }
+func (s *RestockSession) DeviceID() valueobjects.DeviceID   { return s.deviceID }
+func (s *RestockSession) Status() RestockSessionStatus      { return s.status }
+func (s *RestockSession) StartedBy() string                 { return s.startedBy }
+func (s *RestockSession) StartedAt() time.Time              { return s.startedAt }
+func (s *RestockSession) ClosedBy() string                  { return s.closedBy }
+func (s *RestockSession) ClosedAt() *time.Time              { return s.closedAt }
+func (s *RestockSession) IsOpen() bool                      { return s.status == RestockStatusOpen }
+
+// Units returns a copy of the units loaded per SKU code
+func (s *RestockSession) Units() map[string]int {
+	units := make(map[string]int, len(s.units))
+	for code, qty := range s.units {
+		units[code] = qty
+	}
+	return units
+}
+
+// LevelsAfter returns the units in the device of each SKU loaded once the
+// session completed; nil before
+func (s *RestockSession) LevelsAfter() map[string]int {
+	if s.levelsAfter == nil {
+		return nil
+	}
+	levels := make(map[string]int, len(s.levelsAfter))
+	for code, qty := range s.levelsAfter {
+		levels[code] = qty
+	}
+	return levels
+}
+
+// PullEvents returns and clears domain events
+func (s *RestockSession) PullEvents() []events.DomainEvent {
+	evts := s.domainEvents
+	s.domainEvents = nil
+	return evts
+}
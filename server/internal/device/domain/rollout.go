@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RolloutStatus is the lifecycle state of a rollout
+type RolloutStatus string
+
+const (
+	RolloutStatusActive    RolloutStatus = "active"    // staging; more devices get the release as it advances
+	RolloutStatusCompleted RolloutStatus = "completed" // at its last stage, offered to every targeted device
+	RolloutStatusAborted   RolloutStatus = "aborted"   // no longer offered to any device
+)
+
+// Rollout offers a release to the devices of some groups in stages. Each
+// stage is the percentage of the devices offered the release; a device's
+// place in the order is fixed per rollout, so the devices offered the
+// release at one stage still are at the next.
+type Rollout struct {
+	id          valueobjects.RolloutID
+	releaseID   valueobjects.ReleaseID
+	kind        ReleaseKind
+	groupIDs    []valueobjects.DeviceGroupID
+	stages      []int
+	stage       int // index into stages
+	status      RolloutStatus
+	createdBy   string
+	abortedBy   string
+	abortReason string
+	createdAt   time.Time
+	updatedAt   time.Time
+	events      []events.DomainEvent
+}
+
+// NewRollout starts rolling the release out to the groups. Stages are
+// increasing percentages ending at 100; none rolls out to every device at once.
+func NewRollout(release Release, groupIDs []valueobjects.DeviceGroupID, stages []int, createdBy string) (*Rollout, error) {
+	createdBy = strings.TrimSpace(createdBy)
+	if createdBy == "" {
+		return nil, ErrRolloutOperatorRequired
+	}
+	targets := make([]valueobjects.DeviceGroupID, 0, len(groupIDs))
+	seen := make(map[valueobjects.DeviceGroupID]bool, len(groupIDs))
+	for _, id := range groupIDs {
+		if !seen[id] {
+			seen[id] = true
+			targets = append(targets, id)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrRolloutTargetRequired
+	}
+	if len(stages) == 0 {
+		stages = []int{100}
+	}
+	for i, percent := range stages {
+		if percent < 1 || percent > 100 || i > 0 && percent <= stages[i-1] {
+			return nil, ErrInvalidRolloutStages
+		}
+	}
+	if stages[len(stages)-1] != 100 {
+		return nil, ErrInvalidRolloutStages
+	}
+
+	now := time.Now().UTC()
+	r := &Rollout{
+		id:        valueobjects.NewRolloutID(),
+		releaseID: release.ID,
+		kind:      release.Kind,
+		groupIDs:  targets,
+		stages:    append([]int(nil), stages...),
+		status:    RolloutStatusActive,
+		createdBy: createdBy,
+		createdAt: now,
+		updatedAt: now,
+	}
+	if len(stages) == 1 {
+		r.status = RolloutStatusCompleted
+	}
+	r.events = append(r.events, NewRolloutStarted(r.id, release.ID, release.Version, createdBy))
+	return r, nil
+}
+
+// ReconstituteRollout rebuilds a Rollout from persistence
+func ReconstituteRollout(
+	id valueobjects.RolloutID,
+	releaseID valueobjects.ReleaseID,
+	kind ReleaseKind,
+	groupIDs []valueobjects.DeviceGroupID,
+	stages []int,
+	stage int,
+	status RolloutStatus,
+	createdBy, abortedBy, abortReason string,
+	createdAt, updatedAt time.Time,
+) *Rollout {
+	return &Rollout{
+		id:          id,
+		releaseID:   releaseID,
+		kind:        kind,
+		groupIDs:    groupIDs,
+		stages:      stages,
+		stage:       stage,
+		status:      status,
+		createdBy:   createdBy,
+		abortedBy:   abortedBy,
+		abortReason: abortReason,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+	}
+}
+
+// Getters
+func (r *Rollout) ID() valueobjects.RolloutID        { return r.id }
+func (r *Rollout) ReleaseID() valueobjects.ReleaseID { return r.releaseID }
+func (r *Rollout) Kind() ReleaseKind                 { return r.kind }
+func (r *Rollout) Stage() int                        { return r.stage }
+func (r *Rollout) Status() RolloutStatus             { return r.status }
+func (r *Rollout) CreatedBy() string                 { return r.createdBy }
+func (r *Rollout) AbortedBy() string                 { return r.abortedBy }
+func (r *Rollout) AbortReason() string               { return r.abortReason }
+func (r *Rollout) CreatedAt() time.Time              { return r.createdAt }
+func (r *Rollout) UpdatedAt() time.Time              { return r.updatedAt }
+
+// GroupIDs returns a copy of the targeted groups
+func (r *Rollout) GroupIDs() []valueobjects.DeviceGroupID {
+	return append([]valueobjects.DeviceGroupID(nil), r.groupIDs...)
+}
+
+// Stages returns a copy of the stage percentages
+func (r *Rollout) Stages() []int { return append([]int(nil), r.stages...) }
+
+// Percent is the share of the targeted devices offered the release now
+func (r *Rollout) Percent() int { return r.stages[r.stage] }
+
+// Targets reports whether the rollout targets the group
+func (r *Rollout) Targets(groupID valueobjects.DeviceGroupID) bool {
+	for _, id := range r.groupIDs {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes reports whether a device of a targeted group is offered the
+// release at the current stage
+func (r *Rollout) Includes(deviceID valueobjects.DeviceID) bool {
+	if r.status == RolloutStatusAborted {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(r.id.String() + "/" + deviceID.String()))
+	return int(h.Sum32()%100) < r.Percent()
+}
+
+// Business methods
+
+// Advance moves the rollout to its next stage; reaching the last one
+// completes it
+func (r *Rollout) Advance(by string) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrRolloutOperatorRequired
+	}
+	if r.status != RolloutStatusActive {
+		return ErrRolloutNotActive
+	}
+
+	r.stage++
+	if r.stage == len(r.stages)-1 {
+		r.status = RolloutStatusCompleted
+	}
+	r.updatedAt = time.Now().UTC()
+	r.events = append(r.events, NewRolloutAdvanced(r.id, r.Percent(), by))
+	return nil
+}
+
+// Abort stops offering the release. Devices that installed it keep it.
+func (r *Rollout) Abort(by, reason string) error {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return ErrRolloutOperatorRequired
+	}
+	if r.status == RolloutStatusAborted {
+		return ErrRolloutAborted
+	}
+
+	r.status = RolloutStatusAborted
+	r.abortedBy = by
+	r.abortReason = strings.TrimSpace(reason)
+	r.updatedAt = time.Now().UTC()
+	r.events = append(r.events, NewRolloutAborted(r.id, by, r.abortReason))
+	return nil
+}
+
+// PullEvents returns and clears pending domain events
+func (r *Rollout) PullEvents() []events.DomainEvent {
+	evts := r.events
+	r.events = nil
+	return evts
+}
+
+// UpdateStatus is how far a device got with a release it was offered
+type UpdateStatus string
+
+const (
+	UpdateStatusPending     UpdateStatus = "pending" // offered, nothing reported yet
+	UpdateStatusDownloading UpdateStatus = "downloading"
+	UpdateStatusDownloaded  UpdateStatus = "downloaded"
+	UpdateStatusInstalling  UpdateStatus = "installing"
+	UpdateStatusInstalled   UpdateStatus = "installed"
+	UpdateStatusFailed      UpdateStatus = "failed"
+)
+
+// maxUpdateDetailLength bounds the detail a device reports with a status
+const maxUpdateDetailLength = 500
+
+// DeviceUpdate is the status a device last reported for a rollout
+type DeviceUpdate struct {
+	RolloutID valueobjects.RolloutID
+	DeviceID  valueobjects.DeviceID
+	Status    UpdateStatus
+	Detail    string // e.g. why the install failed
+	UpdatedAt time.Time
+}
+
+// NewDeviceUpdate validates a status reported by a device
+func NewDeviceUpdate(rolloutID valueobjects.RolloutID, deviceID valueobjects.DeviceID, status UpdateStatus, detail string, at time.Time) (DeviceUpdate, error) {
+	switch status {
+	case UpdateStatusDownloading, UpdateStatusDownloaded, UpdateStatusInstalling, UpdateStatusInstalled, UpdateStatusFailed:
+	default:
+		return DeviceUpdate{}, ErrInvalidUpdateStatus
+	}
+	detail = strings.TrimSpace(detail)
+	if len(detail) > maxUpdateDetailLength {
+		detail = detail[:maxUpdateDetailLength]
+	}
+	return DeviceUpdate{RolloutID: rolloutID, DeviceID: deviceID, Status: status, Detail: detail, UpdatedAt: at.UTC()}, nil
+}
+
+// IsSettled reports whether the device is done with the release, installed
+// or failed; a settled device is not offered the release again
+func (u DeviceUpdate) IsSettled() bool {
+	return u.Status == UpdateStatusInstalled || u.Status == UpdateStatusFailed
+}
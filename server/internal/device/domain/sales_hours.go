@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// minutesPerDay bounds the clock times of a sales window
+const minutesPerDay = 24 * 60
+
+// SalesWindow is a daily time range in the device's local time, in minutes
+// after midnight. A window ending at or before its start runs past midnight
+// (e.g. 22:00-06:00) and belongs to the day it starts on.
+type SalesWindow struct {
+	Days  []time.Weekday // empty means every day
+	Start int
+	End   int // up to 1440, so 00:00-24:00 covers the whole day
+}
+
+// Contains reports whether the local time falls inside the window
+func (w SalesWindow) Contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	if w.Start < w.End {
+		return w.onDay(today) && minute >= w.Start && minute < w.End
+	}
+	yesterday := (today + 6) % 7
+	return (w.onDay(today) && minute >= w.Start) || (w.onDay(yesterday) && minute < w.End)
+}
+
+func (w SalesWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w SalesWindow) validate() error {
+	if w.Start < 0 || w.Start >= minutesPerDay || w.End < 0 || w.End > minutesPerDay || w.Start == w.End {
+		return ErrInvalidSalesWindow
+	}
+	for _, d := range w.Days {
+		if d < time.Sunday || d > time.Saturday {
+			return ErrInvalidSalesWindow
+		}
+	}
+	return nil
+}
+
+// SalesBlackout is a window during which sales are blocked, either for the
+// whole device or only for some SKUs (e.g. no alcohol after 22:00)
+type SalesBlackout struct {
+	Window   SalesWindow
+	SKUCodes []string // empty blocks every sale
+	Reason   string   // shown to the customer
+}
+
+// AppliesToDevice reports whether the blackout closes the whole device
+func (b SalesBlackout) AppliesToDevice() bool { return len(b.SKUCodes) == 0 }
+
+// SalesStatus is whether a device may sell at a given time
+type SalesStatus struct {
+	Open         bool
+	ClosedReason string
+	// RestrictedSKUs maps the codes that may not be sold right now to the reason
+	RestrictedSKUs map[string]string
+}
+
+// SalesHours holds a device's operating hours and blackout windows.
+// A device without opening hours is open around the clock.
+type SalesHours struct {
+	deviceID     valueobjects.DeviceID
+	timezone     string
+	openingHours []SalesWindow
+	blackouts    []SalesBlackout
+	updatedAt    time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewSalesHours sets the first sales hours of a device
+func NewSalesHours(deviceID valueobjects.DeviceID, timezone string, openingHours []SalesWindow, blackouts []SalesBlackout) (*SalesHours, error) {
+	h := &SalesHours{deviceID: deviceID}
+	if err := h.Replace(timezone, openingHours, blackouts); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ReconstituteSalesHours rebuilds SalesHours from persistence
+func ReconstituteSalesHours(deviceID valueobjects.DeviceID, timezone string, openingHours []SalesWindow, blackouts []SalesBlackout, updatedAt time.Time) *SalesHours {
+	return &SalesHours{
+		deviceID:     deviceID,
+		timezone:     timezone,
+		openingHours: openingHours,
+		blackouts:    blackouts,
+		updatedAt:    updatedAt,
+	}
+}
+
+// AlwaysOpen is the sales hours of a device that has none configured
+func AlwaysOpen(deviceID valueobjects.DeviceID) *SalesHours {
+	return &SalesHours{deviceID: deviceID, timezone: "UTC"}
+}
+
+// Getters
+func (h *SalesHours) DeviceID() valueobjects.DeviceID { return h.deviceID }
+func (h *SalesHours) Timezone() string                { return h.timezone }
+func (h *SalesHours) UpdatedAt() time.Time            { return h.updatedAt }
+
+// OpeningHours returns a copy of the windows the device is open in
+func (h *SalesHours) OpeningHours() []SalesWindow {
+	return append([]SalesWindow(nil), h.openingHours...)
+}
+
+// Blackouts returns a copy of the blackout windows
+func (h *SalesHours) Blackouts() []SalesBlackout {
+	return append([]SalesBlackout(nil), h.blackouts...)
+}
+
+// StatusAt evaluates the sales hours at the given time
+func (h *SalesHours) StatusAt(at time.Time) SalesStatus {
+	local := at.In(h.Location())
+	status := SalesStatus{Open: true, RestrictedSKUs: map[string]string{}}
+
+	if len(h.openingHours) > 0 {
+		open := false
+		for _, w := range h.openingHours {
+			if w.Contains(local) {
+				open = true
+				break
+			}
+		}
+		if !open {
+			status.Open = false
+			status.ClosedReason = "outside opening hours"
+		}
+	}
+
+	for _, b := range h.blackouts {
+		if !b.Window.Contains(local) {
+			continue
+		}
+		if b.AppliesToDevice() {
+			if status.Open {
+				status.Open = false
+				status.ClosedReason = b.Reason
+			}
+			continue
+		}
+		for _, code := range b.SKUCodes {
+			if _, ok := status.RestrictedSKUs[code]; !ok {
+				status.RestrictedSKUs[code] = b.Reason
+			}
+		}
+	}
+
+	return status
+}
+
+// Location is the device's time zone, UTC if it cannot be loaded
+func (h *SalesHours) Location() *time.Location {
+	loc, err := time.LoadLocation(h.timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Business methods
+
+// Replace swaps in new sales hours. The timezone must be an IANA name
+// (e.g. "Europe/Zurich") since windows are in the device's local time.
+func (h *SalesHours) Replace(timezone string, openingHours []SalesWindow, blackouts []SalesBlackout) error {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	for _, w := range openingHours {
+		if err := w.validate(); err != nil {
+			return err
+		}
+	}
+
+	normalized := make([]SalesBlackout, 0, len(blackouts))
+	for _, b := range blackouts {
+		if err := b.Window.validate(); err != nil {
+			return err
+		}
+		b.Reason = strings.TrimSpace(b.Reason)
+		if b.Reason == "" {
+			return ErrBlackoutReasonRequired
+		}
+		var codes []string
+		for _, code := range b.SKUCodes {
+			if code = strings.TrimSpace(code); code != "" {
+				codes = append(codes, code)
+			}
+		}
+		sort.Strings(codes)
+		b.SKUCodes = codes
+		normalized = append(normalized, b)
+	}
+
+	h.timezone = timezone
+	h.openingHours = append([]SalesWindow(nil), openingHours...)
+	h.blackouts = normalized
+	h.updatedAt = time.Now().UTC()
+
+	h.domainEvents = append(h.domainEvents, NewSalesHoursChanged(h.deviceID, len(h.openingHours), len(h.blackouts)))
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (h *SalesHours) PullEvents() []events.DomainEvent {
+	evts := h.domainEvents
+	h.domainEvents = nil
+	return evts
+}
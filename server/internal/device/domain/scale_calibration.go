@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+const (
+	maxScaleReadings = 20
+	// ScaleDriftToleranceGrams is how far the scale may drift before weight
+	// checks of calibrated SKUs, which go down to the scale resolution, start
+	// to fail
+	ScaleDriftToleranceGrams = 2.0
+	// maxRecalibrationDays bounds the forecast of a scale that barely drifts
+	maxRecalibrationDays = 3650
+)
+
+// ScaleReading is what the scale showed with a known weight on it
+type ScaleReading struct {
+	ReferenceGrams float64
+	MeasuredGrams  float64
+}
+
+// ScaleCalibration is one calibration of a device's weight scale. The device
+// reports the readings as found, before it zeroes the scale and corrects its
+// span, so the error of a calibration is how far the scale drifted since the
+// one before.
+type ScaleCalibration struct {
+	id           valueobjects.ScaleCalibrationID
+	deviceID     valueobjects.DeviceID
+	zeroGrams    float64 // reading with nothing on the scale
+	readings     []ScaleReading
+	calibratedAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewScaleCalibration records a calibration a device just made
+func NewScaleCalibration(deviceID valueobjects.DeviceID, zeroGrams float64, readings []ScaleReading, at time.Time) (*ScaleCalibration, error) {
+	if len(readings) == 0 {
+		return nil, ErrNoScaleReadings
+	}
+	if len(readings) > maxScaleReadings {
+		return nil, ErrTooManyScaleReadings
+	}
+	if !isFinite(zeroGrams) {
+		return nil, ErrInvalidScaleReading
+	}
+	for _, r := range readings {
+		if !isFinite(r.ReferenceGrams) || r.ReferenceGrams <= 0 || !isFinite(r.MeasuredGrams) {
+			return nil, ErrInvalidScaleReading
+		}
+	}
+
+	c := &ScaleCalibration{
+		id:           valueobjects.NewScaleCalibrationID(),
+		deviceID:     deviceID,
+		zeroGrams:    zeroGrams,
+		readings:     append([]ScaleReading(nil), readings...),
+		calibratedAt: at.UTC(),
+	}
+	c.domainEvents = append(c.domainEvents, NewScaleCalibrated(c.id, deviceID, c.ErrorGrams()))
+	return c, nil
+}
+
+// ReconstituteScaleCalibration rebuilds a ScaleCalibration from persistence
+func ReconstituteScaleCalibration(
+	id valueobjects.ScaleCalibrationID,
+	deviceID valueobjects.DeviceID,
+	zeroGrams float64,
+	readings []ScaleReading,
+	calibratedAt time.Time,
+) *ScaleCalibration {
+	return &ScaleCalibration{
+		id:           id,
+		deviceID:     deviceID,
+		zeroGrams:    zeroGrams,
+		readings:     readings,
+		calibratedAt: calibratedAt,
+	}
+}
+
+// Getters
+func (c *ScaleCalibration) ID() valueobjects.ScaleCalibrationID { return c.id }
+func (c *ScaleCalibration) DeviceID() valueobjects.DeviceID     { return c.deviceID }
+func (c *ScaleCalibration) ZeroGrams() float64                  { return c.zeroGrams }
+func (c *ScaleCalibration) CalibratedAt() time.Time             { return c.calibratedAt }
+
+// Readings returns a copy of the known-weight readings
+func (c *ScaleCalibration) Readings() []ScaleReading {
+	readings := make([]ScaleReading, len(c.readings))
+	copy(readings, c.readings)
+	return readings
+}
+
+// SpanFactor is how much the scale over- or under-weighed once zeroed, as the
+// least-squares ratio of zeroed readings to reference weights; 1 is exact
+func (c *ScaleCalibration) SpanFactor() float64 {
+	var num, den float64
+	for _, r := range c.readings {
+		num += r.ReferenceGrams * (r.MeasuredGrams - c.zeroGrams)
+		den += r.ReferenceGrams * r.ReferenceGrams
+	}
+	if den == 0 {
+		return 1
+	}
+	return num / den
+}
+
+// ErrorGrams is the largest error the scale was found with, empty or loaded
+func (c *ScaleCalibration) ErrorGrams() float64 {
+	worst := math.Abs(c.zeroGrams)
+	for _, r := range c.readings {
+		worst = math.Max(worst, math.Abs(r.MeasuredGrams-r.ReferenceGrams))
+	}
+	return worst
+}
+
+// PullEvents returns and clears domain events
+func (c *ScaleCalibration) PullEvents() []events.DomainEvent {
+	evts := c.domainEvents
+	c.domainEvents = nil
+	return evts
+}
+
+// ScaleDrift summarises how fast a device's scale goes off between calibrations
+type ScaleDrift struct {
+	// GramsPerDay is the error found at recalibration over the time since
+	// the calibration before, across the whole history; zero with fewer than
+	// two calibrations
+	GramsPerDay float64
+	// RecalibrateBy is when the scale is expected to drift past
+	// ScaleDriftToleranceGrams since its latest calibration; nil while the
+	// drift is unknown or too slow to matter
+	RecalibrateBy *time.Time
+}
+
+// MeasureScaleDrift works out the drift of a device's scale from its
+// calibration history, given newest first. The first calibration only sets a
+// baseline: its error says nothing about how long the scale took to drift.
+func MeasureScaleDrift(history []*ScaleCalibration) ScaleDrift {
+	if len(history) < 2 {
+		return ScaleDrift{}
+	}
+
+	var errorGrams, days float64
+	for i := 0; i < len(history)-1; i++ {
+		errorGrams += history[i].ErrorGrams()
+		days += history[i].calibratedAt.Sub(history[i+1].calibratedAt).Hours() / 24
+	}
+	if days <= 0 || errorGrams == 0 {
+		return ScaleDrift{}
+	}
+
+	rate := errorGrams / days
+	daysLeft := ScaleDriftToleranceGrams / rate
+	if daysLeft > maxRecalibrationDays {
+		return ScaleDrift{GramsPerDay: rate}
+	}
+	due := history[0].calibratedAt.Add(time.Duration(daysLeft * 24 * float64(time.Hour)))
+	return ScaleDrift{GramsPerDay: rate, RecalibrateBy: &due}
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type IncidentKind string
+
+const (
+	IncidentDoorOpenWithoutSession IncidentKind = "door_open_without_session"
+	IncidentDoorLeftOpen           IncidentKind = "door_left_open"
+)
+
+// SecurityIncident records suspicious physical access to a device
+type SecurityIncident struct {
+	id           valueobjects.IncidentID
+	deviceID     valueobjects.DeviceID
+	kind         IncidentKind
+	doorOpenedAt time.Time
+	detectedAt   time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewSecurityIncident records an incident and raises the alert event
+func NewSecurityIncident(deviceID valueobjects.DeviceID, kind IncidentKind, doorOpenedAt, detectedAt time.Time) *SecurityIncident {
+	i := &SecurityIncident{
+		id:           valueobjects.NewIncidentID(),
+		deviceID:     deviceID,
+		kind:         kind,
+		doorOpenedAt: doorOpenedAt.UTC(),
+		detectedAt:   detectedAt.UTC(),
+	}
+
+	i.domainEvents = append(i.domainEvents, NewSecurityIncidentRaised(i.id, deviceID, kind))
+
+	return i
+}
+
+// ReconstituteIncident rebuilds a SecurityIncident from persistence
+func ReconstituteIncident(
+	id valueobjects.IncidentID,
+	deviceID valueobjects.DeviceID,
+	kind IncidentKind,
+	doorOpenedAt, detectedAt time.Time,
+) *SecurityIncident {
+	return &SecurityIncident{
+		id:           id,
+		deviceID:     deviceID,
+		kind:         kind,
+		doorOpenedAt: doorOpenedAt,
+		detectedAt:   detectedAt,
+	}
+}
+
+// Getters
+func (i *SecurityIncident) ID() valueobjects.IncidentID     { return i.id }
+func (i *SecurityIncident) DeviceID() valueobjects.DeviceID { return i.deviceID }
+func (i *SecurityIncident) Kind() IncidentKind              { return i.kind }
+func (i *SecurityIncident) DoorOpenedAt() time.Time         { return i.doorOpenedAt }
+func (i *SecurityIncident) DetectedAt() time.Time           { return i.detectedAt }
+
+// PullEvents returns and clears domain events
+func (i *SecurityIncident) PullEvents() []events.DomainEvent {
+	evts := i.domainEvents
+	i.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// StockBatch is a quantity of one SKU loaded into a device during a restock,
+// all sharing the same expiry date. A batch stays open until field staff
+// write off whatever is left of it.
+type StockBatch struct {
+	id           valueobjects.BatchID
+	deviceID     valueobjects.DeviceID
+	skuCode      string
+	quantity     int
+	expiresOn    time.Time // last day the batch may be sold, midnight UTC
+	restockedBy  string
+	restockedAt  time.Time
+	writtenOffAt *time.Time
+	writtenOffBy string
+	wastedUnits  int
+
+	domainEvents []events.DomainEvent
+}
+
+// NewStockBatch records a batch loaded into a device
+func NewStockBatch(deviceID valueobjects.DeviceID, skuCode string, quantity int, expiresOn time.Time, restockedBy string, restockedAt time.Time) (*StockBatch, error) {
+	if restockedBy == "" {
+		return nil, ErrRestockedByRequired
+	}
+	skuCode = strings.TrimSpace(skuCode)
+	if skuCode == "" || quantity <= 0 || expiresOn.IsZero() {
+		return nil, ErrInvalidBatch
+	}
+
+	b := &StockBatch{
+		id:          valueobjects.NewBatchID(),
+		deviceID:    deviceID,
+		skuCode:     skuCode,
+		quantity:    quantity,
+		expiresOn:   dateOf(expiresOn),
+		restockedBy: restockedBy,
+		restockedAt: restockedAt.UTC(),
+	}
+
+	b.domainEvents = append(b.domainEvents, NewStockBatchRestocked(b.id, deviceID, skuCode, quantity, b.expiresOn))
+
+	return b, nil
+}
+
+// ReconstituteStockBatch rebuilds a StockBatch from persistence
+func ReconstituteStockBatch(
+	id valueobjects.BatchID,
+	deviceID valueobjects.DeviceID,
+	skuCode string,
+	quantity int,
+	expiresOn time.Time,
+	restockedBy string,
+	restockedAt time.Time,
+	writtenOffAt *time.Time,
+	writtenOffBy string,
+	wastedUnits int,
+) *StockBatch {
+	return &StockBatch{
+		id:           id,
+		deviceID:     deviceID,
+		skuCode:      skuCode,
+		quantity:     quantity,
+		expiresOn:    dateOf(expiresOn),
+		restockedBy:  restockedBy,
+		restockedAt:  restockedAt,
+		writtenOffAt: writtenOffAt,
+		writtenOffBy: writtenOffBy,
+		wastedUnits:  wastedUnits,
+	}
+}
+
+// Getters
+func (b *StockBatch) ID() valueobjects.BatchID        { return b.id }
+func (b *StockBatch) DeviceID() valueobjects.DeviceID { return b.deviceID }
+func (b *StockBatch) SKUCode() string                 { return b.skuCode }
+func (b *StockBatch) Quantity() int                   { return b.quantity }
+func (b *StockBatch) ExpiresOn() time.Time            { return b.expiresOn }
+func (b *StockBatch) RestockedBy() string             { return b.restockedBy }
+func (b *StockBatch) RestockedAt() time.Time          { return b.restockedAt }
+func (b *StockBatch) WrittenOffAt() *time.Time        { return b.writtenOffAt }
+func (b *StockBatch) WrittenOffBy() string            { return b.writtenOffBy }
+func (b *StockBatch) WastedUnits() int                { return b.wastedUnits }
+func (b *StockBatch) IsOpen() bool                    { return b.writtenOffAt == nil }
+
+// DaysLeft is the number of days from the given day until the expiry date;
+// negative once the batch has expired
+func (b *StockBatch) DaysLeft(today time.Time) int {
+	return int(b.expiresOn.Sub(dateOf(today)).Hours() / 24)
+}
+
+// IsExpired reports whether the batch is past its expiry date on the given day
+func (b *StockBatch) IsExpired(today time.Time) bool {
+	return b.DaysLeft(today) < 0
+}
+
+// Business methods
+
+// WriteOff closes the batch, recording the units still in the device as waste
+func (b *StockBatch) WriteOff(remaining int, writtenOffBy string, at time.Time) error {
+	if writtenOffBy == "" {
+		return ErrWrittenOffByRequired
+	}
+	if !b.IsOpen() {
+		return ErrBatchWrittenOff
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	at = at.UTC()
+	b.writtenOffAt = &at
+	b.writtenOffBy = writtenOffBy
+	b.wastedUnits = remaining
+
+	b.domainEvents = append(b.domainEvents, NewStockBatchWrittenOff(b.id, b.deviceID, b.skuCode, remaining))
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (b *StockBatch) PullEvents() []events.DomainEvent {
+	evts := b.domainEvents
+	b.domainEvents = nil
+	return evts
+}
+
+// BatchLevel is an open batch with the units estimated to be left in the device
+type BatchLevel struct {
+	Batch     *StockBatch
+	Remaining int
+}
+
+// LevelBatches estimates what is left of each open batch. Units sold since
+// the oldest open batch of a SKU was loaded are taken from its batches in
+// expiry order, as field staff load older stock at the front.
+func LevelBatches(batches []*StockBatch, sold map[string]int) []BatchLevel {
+	open := make([]*StockBatch, 0, len(batches))
+	for _, b := range batches {
+		if b.IsOpen() {
+			open = append(open, b)
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].skuCode != open[j].skuCode {
+			return open[i].skuCode < open[j].skuCode
+		}
+		if !open[i].expiresOn.Equal(open[j].expiresOn) {
+			return open[i].expiresOn.Before(open[j].expiresOn)
+		}
+		return open[i].restockedAt.Before(open[j].restockedAt)
+	})
+
+	unallocated := make(map[string]int, len(sold))
+	for code, units := range sold {
+		unallocated[code] = units
+	}
+
+	levels := make([]BatchLevel, 0, len(open))
+	for _, b := range open {
+		taken := min(unallocated[b.skuCode], b.quantity)
+		unallocated[b.skuCode] -= taken
+		levels = append(levels, BatchLevel{Batch: b, Remaining: b.quantity - taken})
+	}
+	return levels
+}
+
+// OldestRestock returns when the oldest open batch of each SKU was loaded,
+// the point from which its sales are counted
+func OldestRestock(batches []*StockBatch) map[string]time.Time {
+	oldest := make(map[string]time.Time)
+	for _, b := range batches {
+		if !b.IsOpen() {
+			continue
+		}
+		if t, ok := oldest[b.skuCode]; !ok || b.restockedAt.Before(t) {
+			oldest[b.skuCode] = b.restockedAt
+		}
+	}
+	return oldest
+}
+
+// Freshness is what a device's batches allow it to sell on a given day
+type Freshness struct {
+	// Expired maps SKU codes with expired units still in the device to the
+	// earliest expiry date; they may not be sold until written off
+	Expired map[string]time.Time
+	// Markdowns maps SKU codes to the percentage taken off their price
+	Markdowns map[string]int
+}
+
+// FreshnessOn evaluates the batch levels on a day in the device's local time.
+// A SKU is marked down by its oldest batch still in stock.
+func FreshnessOn(levels []BatchLevel, today time.Time, markdowns policy.MarkdownPolicy) Freshness {
+	f := Freshness{Expired: map[string]time.Time{}, Markdowns: map[string]int{}}
+	oldest := make(map[string]*StockBatch)
+	for _, l := range levels {
+		if l.Remaining <= 0 {
+			continue
+		}
+		b := l.Batch
+		if b.IsExpired(today) {
+			if t, ok := f.Expired[b.skuCode]; !ok || b.expiresOn.Before(t) {
+				f.Expired[b.skuCode] = b.expiresOn
+			}
+			continue
+		}
+		if o, ok := oldest[b.skuCode]; !ok || b.expiresOn.Before(o.expiresOn) {
+			oldest[b.skuCode] = b
+		}
+	}
+	for code, b := range oldest {
+		if pct := markdowns.PercentFor(b.DaysLeft(today)); pct > 0 {
+			f.Markdowns[code] = pct
+		}
+	}
+	return f
+}
+
+// dateOf truncates a time to its calendar date, keeping the wall clock date
+// of its location
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// StockEstimate holds the remaining quantity per SKU on a device as counted
+// by cloud detection on the latest shelf snapshot. It is an estimate, not a
+// ledger: occluded or misdetected items make it drift until the next snapshot.
+type StockEstimate struct {
+	deviceID     valueobjects.DeviceID
+	levels       map[string]int // SKU code -> estimated quantity
+	modelVersion string
+	observedAt   time.Time
+	updatedAt    time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewStockEstimate creates an empty estimate for a device that has not sent a snapshot yet
+func NewStockEstimate(deviceID valueobjects.DeviceID) *StockEstimate {
+	return &StockEstimate{
+		deviceID: deviceID,
+		levels:   make(map[string]int),
+	}
+}
+
+// ReconstituteStockEstimate rebuilds a StockEstimate from persistence
+func ReconstituteStockEstimate(
+	deviceID valueobjects.DeviceID,
+	levels map[string]int,
+	modelVersion string,
+	observedAt, updatedAt time.Time,
+) *StockEstimate {
+	if levels == nil {
+		levels = make(map[string]int)
+	}
+	return &StockEstimate{
+		deviceID:     deviceID,
+		levels:       levels,
+		modelVersion: modelVersion,
+		observedAt:   observedAt,
+		updatedAt:    updatedAt,
+	}
+}
+
+// Getters
+func (e *StockEstimate) DeviceID() valueobjects.DeviceID { return e.deviceID }
+func (e *StockEstimate) ModelVersion() string            { return e.modelVersion }
+func (e *StockEstimate) ObservedAt() time.Time           { return e.observedAt }
+func (e *StockEstimate) UpdatedAt() time.Time            { return e.updatedAt }
+
+// Levels returns a copy of the estimated quantity per SKU code
+func (e *StockEstimate) Levels() map[string]int {
+	levels := make(map[string]int, len(e.levels))
+	for code, qty := range e.levels {
+		levels[code] = qty
+	}
+	return levels
+}
+
+// SKUCodes lists the SKUs with an estimate, sorted
+func (e *StockEstimate) SKUCodes() []string {
+	codes := make([]string, 0, len(e.levels))
+	for code := range e.levels {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Business methods
+
+// Observe replaces the estimate with the counts from a snapshot taken at the
+// given time. SKUs seen on an earlier snapshot but missing from this one are
+// estimated at zero. A StockLowEstimated event is raised when a SKU drops to
+// or below lowThreshold, but not again while it stays there.
+func (e *StockEstimate) Observe(counts map[string]int, modelVersion string, at time.Time, lowThreshold int) error {
+	at = at.UTC()
+	if !e.observedAt.IsZero() && at.Before(e.observedAt) {
+		return ErrStaleSnapshot
+	}
+
+	levels := make(map[string]int, len(counts))
+	for code := range e.levels {
+		levels[code] = 0
+	}
+	for code, qty := range counts {
+		if code != "" && qty > 0 {
+			levels[code] = qty
+		}
+	}
+
+	codes := make([]string, 0, len(levels))
+	for code := range levels {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		previous, known := e.levels[code]
+		if levels[code] <= lowThreshold && (!known || previous > lowThreshold) {
+			e.domainEvents = append(e.domainEvents, NewStockLowEstimated(e.deviceID, code, levels[code], lowThreshold))
+		}
+	}
+
+	e.levels = levels
+	e.modelVersion = modelVersion
+	e.observedAt = at
+	e.updatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (e *StockEstimate) PullEvents() []events.DomainEvent {
+	evts := e.domainEvents
+	e.domainEvents = nil
+	return evts
+}
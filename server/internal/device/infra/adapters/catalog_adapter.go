@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"context"
+
+	catalogapi "github.com/vending-machine/server/internal/catalog/api"
+)
+
+// CatalogAdapter implements app.SKUCatalog using the catalog context API
+type CatalogAdapter struct {
+	reader catalogapi.SKUReader
+}
+
+func NewCatalogAdapter(reader catalogapi.SKUReader) *CatalogAdapter {
+	if reader == nil {
+		panic("nil SKUReader")
+	}
+	return &CatalogAdapter{reader: reader}
+}
+
+func (a *CatalogAdapter) KnownSKUCodes(ctx context.Context) (map[string]bool, error) {
+	skus, err := a.reader.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		codes[sku.Code] = true
+	}
+	return codes, nil
+}
+
+func (a *CatalogAdapter) ShelfLives(ctx context.Context) (map[string]int, error) {
+	skus, err := a.reader.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]int)
+	for _, sku := range skus {
+		if sku.ShelfLifeDays > 0 {
+			days[sku.Code] = sku.ShelfLifeDays
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,37 @@
+package adapters
+
+import (
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/platform/messaging"
+)
+
+// DeviceCommandFeed implements app.DeviceCommandFeed on top of the
+// in-process event broker; it only sees commands issued on this instance
+type DeviceCommandFeed struct {
+	broker *messaging.InProcessBroker
+}
+
+func NewDeviceCommandFeed(broker *messaging.InProcessBroker) *DeviceCommandFeed {
+	return &DeviceCommandFeed{broker: broker}
+}
+
+func (f *DeviceCommandFeed) Subscribe() (<-chan string, func()) {
+	evts, cancel := f.broker.Subscribe()
+
+	deviceIDs := make(chan string, cap(evts))
+	go func() {
+		defer close(deviceIDs)
+		for evt := range evts {
+			issued, ok := evt.(domain.DeviceCommandIssued)
+			if !ok {
+				continue
+			}
+			select {
+			case deviceIDs <- issued.DeviceID.String():
+			default:
+			}
+		}
+	}()
+
+	return deviceIDs, cancel
+}
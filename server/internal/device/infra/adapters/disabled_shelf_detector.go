@@ -0,0 +1,19 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+// DisabledShelfDetector is used when no cloud detection backend is wired in;
+// every snapshot is rejected instead of producing an empty (all zero) estimate
+type DisabledShelfDetector struct{}
+
+func NewDisabledShelfDetector() *DisabledShelfDetector {
+	return &DisabledShelfDetector{}
+}
+
+func (d *DisabledShelfDetector) DetectShelf(ctx context.Context, deviceID string, image []byte) (app.ShelfDetectionResult, error) {
+	return app.ShelfDetectionResult{}, app.ErrShelfDetectionUnavailable
+}
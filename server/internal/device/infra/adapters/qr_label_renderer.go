@@ -0,0 +1,25 @@
+package adapters
+
+import (
+	"github.com/skip2/go-qrcode"
+)
+
+// QRLabelRenderer implements app.LabelRenderer
+type QRLabelRenderer struct{}
+
+func NewQRLabelRenderer() *QRLabelRenderer {
+	return &QRLabelRenderer{}
+}
+
+// RenderQRCode encodes content at error correction level M and scales the
+// code by whole pixels per module, so the image is at most size pixels wide
+// unless the code needs more than one pixel a module
+func (r *QRLabelRenderer) RenderQRCode(content string, size int) ([]byte, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	// The bitmap includes the quiet zone around the symbol
+	modules := len(code.Bitmap())
+	return code.PNG(-max(1, size/modules))
+}
@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	gozxingqr "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+func TestRenderQRCodeDecodesBackToItsContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		size    int
+	}{
+		{"machine ID", "FRIDGE-001", 256},
+		{"deep link", "https://shop.example.com/m/FRIDGE-001", 256},
+		{"escaped machine ID", "https://shop.example.com/m/LOBBY+2%2FEAST", 128},
+		{"long deep link at the smallest size", "https://shop.example.com/m/" + strings.Repeat("A", 120), 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewQRLabelRenderer().RenderQRCode(tt.content, tt.size)
+			if err != nil {
+				t.Fatalf("RenderQRCode: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("not a PNG: %v", err)
+			}
+
+			bounds := img.Bounds()
+			if bounds.Dx() != bounds.Dy() {
+				t.Errorf("image is %dx%d, want a square", bounds.Dx(), bounds.Dy())
+			}
+			if tt.size >= 128 && bounds.Dx() > tt.size {
+				t.Errorf("image is %d pixels wide, want at most %d", bounds.Dx(), tt.size)
+			}
+
+			bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+			if err != nil {
+				t.Fatalf("NewBinaryBitmapFromImage: %v", err)
+			}
+			result, err := gozxingqr.NewQRCodeReader().Decode(bitmap, nil)
+			if err != nil {
+				t.Fatalf("the label does not scan: %v", err)
+			}
+			if result.GetText() != tt.content {
+				t.Errorf("label holds %q, want %q", result.GetText(), tt.content)
+			}
+		})
+	}
+}
+
+func TestRenderQRCodeRejectsContentTooLongForASymbol(t *testing.T) {
+	if _, err := NewQRLabelRenderer().RenderQRCode(strings.Repeat("A", 8000), 256); err == nil {
+		t.Error("RenderQRCode accepted content no QR code can hold")
+	}
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	transactionapi "github.com/vending-machine/server/internal/transaction/api"
+)
+
+// SaleListener takes completed sessions out of device inventories as the
+// transaction context publishes them. It only sees sessions completed by this
+// instance, and a session missed while the listener lagged is not replayed;
+// the next restock count corrects the inventory.
+type SaleListener struct {
+	broker  *messaging.InProcessBroker
+	handler *app.RecordSaleHandler
+}
+
+func NewSaleListener(broker *messaging.InProcessBroker, handler *app.RecordSaleHandler) *SaleListener {
+	if broker == nil {
+		panic("nil InProcessBroker")
+	}
+	if handler == nil {
+		panic("nil RecordSaleHandler")
+	}
+	return &SaleListener{broker: broker, handler: handler}
+}
+
+// Run handles completed sessions until ctx is cancelled
+func (l *SaleListener) Run(ctx context.Context) {
+	evts, cancel := l.broker.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-evts:
+			if !ok {
+				return
+			}
+			sessionID, ok := transactionapi.CompletedSessionID(evt)
+			if !ok {
+				continue
+			}
+			if err := l.handler.Handle(ctx, app.RecordSaleCommand{SessionID: sessionID}); err != nil {
+				logger.Error("Failed to take sale out of inventory", "session_id", sessionID, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/device/app"
+	transactionapi "github.com/vending-machine/server/internal/transaction/api"
+)
+
+// TransactionAdapter implements app.SessionActivityReader, app.SalesReader and
+// app.CompletedSaleReader using the transaction context API
+type TransactionAdapter struct {
+	reader transactionapi.SessionReader
+}
+
+func NewTransactionAdapter(reader transactionapi.SessionReader) *TransactionAdapter {
+	if reader == nil {
+		panic("nil SessionReader")
+	}
+	return &TransactionAdapter{reader: reader}
+}
+
+func (a *TransactionAdapter) ActivityByDeviceID(ctx context.Context, deviceID string) (app.SessionActivity, error) {
+	view, err := a.reader.DeviceActivity(ctx, deviceID)
+	if err != nil {
+		return app.SessionActivity{}, err
+	}
+
+	return app.SessionActivity{
+		HasActiveSession: view.HasActiveSession,
+		LastCompletedAt:  view.LastCompletedAt,
+	}, nil
+}
+
+func (a *TransactionAdapter) SoldUnits(ctx context.Context, deviceID string, since map[string]time.Time) (map[string]int, error) {
+	return a.reader.SoldUnits(ctx, deviceID, since)
+}
+
+func (a *TransactionAdapter) CompletedSale(ctx context.Context, sessionID string) (*app.CompletedSale, error) {
+	view, err := a.reader.FindByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if view.CompletedAt == nil {
+		return nil, fmt.Errorf("session %s is not completed", sessionID)
+	}
+
+	sale := &app.CompletedSale{DeviceID: view.DeviceID, Units: make(map[string]int)}
+	for _, item := range view.Items {
+		sale.Units[item.Code] += item.Quantity
+	}
+	return sale, nil
+}
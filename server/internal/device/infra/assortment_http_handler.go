@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type assignSKUsRequest struct {
+	SKUCodes []string `json:"sku_codes"`
+}
+
+type assortmentResponse struct {
+	DeviceID  string     `json:"device_id"`
+	MachineID string     `json:"machine_id"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	SKUCodes  []string   `json:"sku_codes"`
+}
+
+// AssignSKUs adds catalog SKUs to the assortment the device stocks
+func (h *HTTPHandler) AssignSKUs(c *gin.Context) {
+	var req assignSKUsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.assignHandler.Handle(c.Request.Context(), app.AssignSKUsCommand{
+		DeviceID: c.Param("id"),
+		SKUCodes: req.SKUCodes,
+	})
+	if err != nil {
+		h.writeAssortmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAssortmentResponse(*view))
+}
+
+// UnassignSKU takes a SKU out of the device's assortment
+func (h *HTTPHandler) UnassignSKU(c *gin.Context) {
+	view, err := h.unassignHandler.Handle(c.Request.Context(), app.UnassignSKUCommand{
+		DeviceID: c.Param("id"),
+		SKUCode:  c.Param("code"),
+	})
+	if err != nil {
+		h.writeAssortmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAssortmentResponse(*view))
+}
+
+// AssignedSKUs returns the active catalog SKUs assigned to the device, in the
+// shape of the device SKU sync
+func (h *HTTPHandler) AssignedSKUs(c *gin.Context) {
+	view, err := h.assortmentQuery.FindByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeAssortmentError(c, err)
+		return
+	}
+
+	skus, err := h.skuReader.FindAllActive(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	assigned := make(map[string]bool, len(view.SKUCodes))
+	for _, code := range view.SKUCodes {
+		assigned[code] = true
+	}
+	languages := preferredLanguages(c)
+	response := []gin.H{}
+	for _, s := range skus {
+		if !assigned[s.Code] {
+			continue
+		}
+		response = append(response, localizedSKU(gin.H{
+			"code":             s.Code,
+			"weight_grams":     s.WeightGrams,
+			"weight_tolerance": s.WeightTolerance,
+		}, s, languages))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"device_id":  view.DeviceID,
+		"machine_id": view.MachineID,
+		"skus":       response,
+		"count":      len(response),
+	})
+}
+
+func (h *HTTPHandler) writeAssortmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrSKUNotAssigned):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAssignment),
+		errors.Is(err, domain.ErrUnknownSKU):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toAssortmentResponse(v app.AssortmentView) assortmentResponse {
+	return assortmentResponse{
+		DeviceID:  v.DeviceID,
+		MachineID: v.MachineID,
+		UpdatedAt: v.UpdatedAt,
+		SKUCodes:  v.SKUCodes,
+	}
+}
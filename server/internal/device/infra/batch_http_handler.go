@@ -0,0 +1,256 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// defaultWastePeriod is how far back the waste report goes without a from date
+const defaultWastePeriod = 30 * 24 * time.Hour
+
+// defaultExpiringWithin is how many days ahead the expiring batches are
+// listed without within_days
+const defaultExpiringWithin = 3
+
+type batchInputDTO struct {
+	SKUCode   string `json:"sku_code"`
+	Quantity  int    `json:"quantity"`
+	ExpiresOn string `json:"expires_on"` // "YYYY-MM-DD", last day the batch may be sold; omitted takes the SKU's shelf life
+}
+
+type recordBatchesRequest struct {
+	MachineID   string          `json:"machine_id" binding:"required"`
+	RestockedAt *time.Time      `json:"restocked_at"`
+	Batches     []batchInputDTO `json:"batches"`
+}
+
+type batchResponse struct {
+	ID              string     `json:"id"`
+	MachineID       string     `json:"machine_id"`
+	SKUCode         string     `json:"sku_code"`
+	Quantity        int        `json:"quantity"`
+	Remaining       int        `json:"remaining"`
+	ExpiresOn       string     `json:"expires_on"`
+	DaysLeft        int        `json:"days_left"`
+	Expired         bool       `json:"expired"`
+	MarkdownPercent int        `json:"markdown_percent"`
+	RestockedBy     string     `json:"restocked_by"`
+	RestockedAt     time.Time  `json:"restocked_at"`
+	WrittenOffAt    *time.Time `json:"written_off_at,omitempty"`
+	WrittenOffBy    string     `json:"written_off_by,omitempty"`
+	WastedUnits     int        `json:"wasted_units,omitempty"`
+}
+
+type wasteLineResponse struct {
+	SKUCode string `json:"sku_code"`
+	Batches int    `json:"batches"`
+	Units   int    `json:"units"`
+}
+
+// RecordBatches records the batches field staff loaded during a restock,
+// with their expiry dates
+func (h *HTTPHandler) RecordBatches(c *gin.Context) {
+	var req recordBatchesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordBatchesCommand{
+		MachineID:   req.MachineID,
+		RestockedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	}
+	if req.RestockedAt != nil {
+		cmd.RestockedAt = *req.RestockedAt
+	}
+	for _, b := range req.Batches {
+		var expiresOn time.Time
+		if b.ExpiresOn != "" {
+			t, err := time.Parse(time.DateOnly, b.ExpiresOn)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "expires_on must be a date (YYYY-MM-DD)"})
+				return
+			}
+			expiresOn = t
+		}
+		cmd.Batches = append(cmd.Batches, app.BatchInput{SKUCode: b.SKUCode, Quantity: b.Quantity, ExpiresOn: expiresOn})
+	}
+
+	views, err := h.batchHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toBatchResponses(views))
+}
+
+// Batches lists the device's open batches with their estimated remaining
+// units, days to expiry and current markdown
+func (h *HTTPHandler) Batches(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	views, err := h.expiryQuery.BatchesByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toBatchResponses(views))
+}
+
+// ExpiringBatches lists the batches with units left that expire within
+// within_days days, expired ones included, on one device or across the
+// fleet, so operators can mark them down or take them out
+func (h *HTTPHandler) ExpiringBatches(c *gin.Context) {
+	within := defaultExpiringWithin
+	if raw := c.Query("within_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "within_days must be a non-negative number of days"})
+			return
+		}
+		within = days
+	}
+
+	views, err := h.expiryQuery.ExpiringBatches(c.Request.Context(), c.Query("machine_id"), within)
+	if err != nil {
+		h.writeBatchError(c, err)
+		return
+	}
+
+	units := 0
+	for _, v := range views {
+		units += v.Remaining
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"within_days": within,
+		"units":       units,
+		"batches":     toBatchResponses(views),
+		"count":       len(views),
+	})
+}
+
+// WriteOffBatch removes what is left of a batch from sale and records it as waste
+func (h *HTTPHandler) WriteOffBatch(c *gin.Context) {
+	cmd := app.WriteOffBatchCommand{
+		BatchID:      c.Param("id"),
+		WrittenOffBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	}
+
+	view, err := h.writeOffHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toBatchResponse(*view))
+}
+
+// WasteReport totals the units written off between two dates (inclusive),
+// for one device or the whole fleet
+func (h *HTTPHandler) WasteReport(c *gin.Context) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if raw := c.Query("to"); raw != "" {
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultWastePeriod)
+	if raw := c.Query("from"); raw != "" {
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return
+		}
+		from = t
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	view, err := h.expiryQuery.WasteReport(c.Request.Context(), c.Query("machine_id"), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		h.writeBatchError(c, err)
+		return
+	}
+
+	lines := make([]wasteLineResponse, 0, len(view.Lines))
+	for _, l := range view.Lines {
+		lines = append(lines, wasteLineResponse{SKUCode: l.SKUCode, Batches: l.Batches, Units: l.Units})
+	}
+
+	response := gin.H{
+		"from":        from.Format(time.DateOnly),
+		"to":          to.Format(time.DateOnly),
+		"total_units": view.TotalUnits,
+		"lines":       lines,
+		"batches":     toBatchResponses(view.Batches),
+	}
+	if view.MachineID != "" {
+		response["machine_id"] = view.MachineID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) writeBatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRestockedByRequired),
+		errors.Is(err, domain.ErrWrittenOffByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidBatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBatchWrittenOff):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toBatchResponses(views []app.BatchView) []batchResponse {
+	response := make([]batchResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toBatchResponse(v))
+	}
+	return response
+}
+
+func toBatchResponse(v app.BatchView) batchResponse {
+	return batchResponse{
+		ID:              v.ID,
+		MachineID:       v.MachineID,
+		SKUCode:         v.SKUCode,
+		Quantity:        v.Quantity,
+		Remaining:       v.Remaining,
+		ExpiresOn:       v.ExpiresOn.Format(time.DateOnly),
+		DaysLeft:        v.DaysLeft,
+		Expired:         v.Expired,
+		MarkdownPercent: v.MarkdownPercent,
+		RestockedBy:     v.RestockedBy,
+		RestockedAt:     v.RestockedAt,
+		WrittenOffAt:    v.WrittenOffAt,
+		WrittenOffBy:    v.WrittenOffBy,
+		WastedUnits:     v.WastedUnits,
+	}
+}
@@ -0,0 +1,129 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type referenceMarkerDTO struct {
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+type uploadCalibrationRequest struct {
+	MachineID  string               `json:"machine_id" binding:"required"`
+	Homography []float64            `json:"homography" binding:"required"`
+	Markers    []referenceMarkerDTO `json:"markers"`
+	Capture    cameraSettingsDTO    `json:"capture"`
+}
+
+type calibrationResponse struct {
+	DeviceID     string               `json:"device_id"`
+	MachineID    string               `json:"machine_id"`
+	Version      int                  `json:"version"`
+	Homography   []float64            `json:"homography"`
+	Markers      []referenceMarkerDTO `json:"markers"`
+	Capture      cameraSettingsDTO    `json:"capture"`
+	CalibratedAt time.Time            `json:"calibrated_at"`
+}
+
+// UploadCalibration stores the camera calibration the device just made,
+// replacing the previous one. The device authenticates with X-Device-Key.
+func (h *HTTPHandler) UploadCalibration(c *gin.Context) {
+	var req uploadCalibrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	markers := make([]domain.ReferenceMarker, 0, len(req.Markers))
+	for _, m := range req.Markers {
+		markers = append(markers, domain.ReferenceMarker(m))
+	}
+
+	view, err := h.calibrationUploader.Handle(c.Request.Context(), app.UploadCameraCalibrationCommand{
+		MachineID:  req.MachineID,
+		DeviceKey:  c.GetHeader(deviceKeyHeader),
+		Homography: req.Homography,
+		Markers:    markers,
+		Capture:    domain.CameraSettings(req.Capture),
+	})
+	if err != nil {
+		h.writeCalibrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCalibrationResponse(view))
+}
+
+// Calibration hands the device its stored camera calibration, which it
+// fetches on boot so a reimaged device need not calibrate again. The device
+// authenticates with X-Device-Key.
+func (h *HTTPHandler) Calibration(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.calibrationQuery.ForDevice(c.Request.Context(), machineID, c.GetHeader(deviceKeyHeader))
+	if err != nil {
+		h.writeCalibrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCalibrationResponse(view))
+}
+
+// DeviceCalibration returns a device's camera calibration for operators
+func (h *HTTPHandler) DeviceCalibration(c *gin.Context) {
+	view, err := h.calibrationQuery.FindByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeCalibrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCalibrationResponse(view))
+}
+
+func (h *HTTPHandler) writeCalibrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeviceKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotFound),
+		errors.Is(err, domain.ErrCalibrationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceDecommissioned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidHomography),
+		errors.Is(err, domain.ErrInvalidMarker),
+		errors.Is(err, domain.ErrTooManyMarkers),
+		errors.Is(err, domain.ErrInvalidCameraSettings):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toCalibrationResponse(v app.CameraCalibrationView) calibrationResponse {
+	markers := make([]referenceMarkerDTO, 0, len(v.Markers))
+	for _, m := range v.Markers {
+		markers = append(markers, referenceMarkerDTO(m))
+	}
+	return calibrationResponse{
+		DeviceID:     v.DeviceID,
+		MachineID:    v.MachineID,
+		Version:      v.Version,
+		Homography:   v.Homography[:],
+		Markers:      markers,
+		Capture:      cameraSettingsDTO(v.Capture),
+		CalibratedAt: v.CalibratedAt,
+	}
+}
@@ -0,0 +1,187 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type cameraSettingsDTO struct {
+	Resolution     string `json:"resolution,omitempty"`
+	FrameRate      int    `json:"frame_rate,omitempty"`
+	ExposureMicros int    `json:"exposure_micros,omitempty"`
+}
+
+type deviceConfigDTO struct {
+	ConfidenceThreshold *float64          `json:"confidence_threshold,omitempty"`
+	SyncIntervalSeconds int               `json:"sync_interval_seconds,omitempty"`
+	Camera              cameraSettingsDTO `json:"camera"`
+}
+
+type reportDeviceConfigRequest struct {
+	MachineID string          `json:"machine_id" binding:"required"`
+	Version   int             `json:"version"`
+	Config    deviceConfigDTO `json:"config"`
+}
+
+type configDriftResponse struct {
+	Setting  string `json:"setting"`
+	Desired  string `json:"desired"`
+	Reported string `json:"reported"`
+}
+
+type configShadowResponse struct {
+	DeviceID        string                `json:"device_id"`
+	MachineID       string                `json:"machine_id"`
+	Status          string                `json:"status"`
+	Version         int                   `json:"version"`
+	Desired         deviceConfigDTO       `json:"desired"`
+	DesiredBy       string                `json:"desired_by,omitempty"`
+	DesiredAt       *time.Time            `json:"desired_at,omitempty"`
+	Reported        *deviceConfigDTO      `json:"reported"`
+	ReportedVersion int                   `json:"reported_version"`
+	ReportedAt      *time.Time            `json:"reported_at"`
+	Drift           []configDriftResponse `json:"drift"`
+}
+
+// SetDeviceConfig replaces the configuration the device should run. The
+// operator is taken from X-Actor-ID.
+func (h *HTTPHandler) SetDeviceConfig(c *gin.Context) {
+	var req deviceConfigDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.configHandler.Handle(c.Request.Context(), app.SetDesiredConfigCommand{
+		DeviceID:            c.Param("id"),
+		ConfidenceThreshold: req.ConfidenceThreshold,
+		SyncIntervalSeconds: req.SyncIntervalSeconds,
+		Camera:              domain.CameraSettings(req.Camera),
+		ConfiguredBy:        strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toConfigShadowResponse(*view))
+}
+
+// DeviceConfig returns the desired and reported configuration of a device
+// and the settings that drifted
+func (h *HTTPHandler) DeviceConfig(c *gin.Context) {
+	view, err := h.configQuery.FindByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toConfigShadowResponse(*view))
+}
+
+// PullConfig hands the device the configuration it should run. The device
+// authenticates with its key in X-Device-Key.
+func (h *HTTPHandler) PullConfig(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.configPuller.Handle(c.Request.Context(), app.PullDeviceConfigCommand{
+		MachineID: machineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+	})
+	if err != nil {
+		h.writeConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"machine_id": view.MachineID,
+		"version":    view.Version,
+		"config":     toDeviceConfigDTO(view.Config),
+	})
+}
+
+// ReportConfig records the configuration the device applied, with the
+// version it applied it from. The device authenticates with X-Device-Key.
+func (h *HTTPHandler) ReportConfig(c *gin.Context) {
+	var req reportDeviceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.configReporter.Handle(c.Request.Context(), app.ReportDeviceConfigCommand{
+		MachineID:           req.MachineID,
+		DeviceKey:           c.GetHeader(deviceKeyHeader),
+		Version:             req.Version,
+		ConfidenceThreshold: req.Config.ConfidenceThreshold,
+		SyncIntervalSeconds: req.Config.SyncIntervalSeconds,
+		Camera:              domain.CameraSettings(req.Config.Camera),
+	})
+	if err != nil {
+		h.writeConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toConfigShadowResponse(*view))
+}
+
+func (h *HTTPHandler) writeConfigError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeviceKey),
+		errors.Is(err, domain.ErrConfiguredByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceDecommissioned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidConfidenceThreshold),
+		errors.Is(err, domain.ErrInvalidSyncInterval),
+		errors.Is(err, domain.ErrInvalidCameraSettings),
+		errors.Is(err, domain.ErrUnknownConfigVersion):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toDeviceConfigDTO(c domain.DeviceConfig) deviceConfigDTO {
+	return deviceConfigDTO{
+		ConfidenceThreshold: c.ConfidenceThreshold,
+		SyncIntervalSeconds: c.SyncIntervalSeconds,
+		Camera:              cameraSettingsDTO(c.Camera),
+	}
+}
+
+func toConfigShadowResponse(v app.ConfigShadowView) configShadowResponse {
+	response := configShadowResponse{
+		DeviceID:        v.DeviceID,
+		MachineID:       v.MachineID,
+		Status:          string(v.Status),
+		Version:         v.Version,
+		Desired:         toDeviceConfigDTO(v.Desired),
+		DesiredBy:       v.DesiredBy,
+		DesiredAt:       v.DesiredAt,
+		ReportedVersion: v.ReportedVersion,
+		ReportedAt:      v.ReportedAt,
+		Drift:           make([]configDriftResponse, 0, len(v.Drift)),
+	}
+	if v.Reported != nil {
+		reported := toDeviceConfigDTO(*v.Reported)
+		response.Reported = &reported
+	}
+	for _, d := range v.Drift {
+		response.Drift = append(response.Drift, configDriftResponse(d))
+	}
+	return response
+}
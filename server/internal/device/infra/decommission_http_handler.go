@@ -0,0 +1,43 @@
+package infra
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type decommissionDeviceRequest struct {
+	MachineID string `json:"machine_id" binding:"required"`
+}
+
+// Decommission takes a device out of service for good. The operator is taken
+// from X-Actor-ID; registering the machine ID again needs an override.
+func (h *HTTPHandler) Decommission(c *gin.Context) {
+	var req decommissionDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.decommissioner.Handle(c.Request.Context(), app.DecommissionDeviceCommand{
+		MachineID:        req.MachineID,
+		DecommissionedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                  result.DeviceID,
+		"machine_id":          result.MachineID,
+		"status":              string(domain.DeviceStatusDecommissioned),
+		"decommissioned_at":   result.DecommissionedAt,
+		"archived_excursions": result.ArchivedExcursions,
+		"archived_incidents":  result.ArchivedIncidents,
+	})
+}
@@ -0,0 +1,275 @@
+package infra
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+const (
+	defaultCommandPollWait = 25 * time.Second
+	maxCommandPollWait     = 60 * time.Second
+	defaultCommandLimit    = 50
+	maxCommandLimit        = 200
+	commandSocketKeepAlive = 30 * time.Second
+)
+
+type issueCommandRequest struct {
+	Kind string `json:"kind" binding:"required"`
+}
+
+type acknowledgeCommandRequest struct {
+	MachineID string `json:"machine_id" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Result    string `json:"result"`
+}
+
+type deviceCommandResponse struct {
+	ID             string     `json:"id"`
+	MachineID      string     `json:"machine_id"`
+	Kind           string     `json:"kind"`
+	Status         string     `json:"status"`
+	IssuedBy       string     `json:"issued_by"`
+	IssuedAt       time.Time  `json:"issued_at"`
+	Deliveries     int        `json:"deliveries"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	Result         string     `json:"result,omitempty"`
+}
+
+// commandSocketMessage is one frame on the command WebSocket. The server
+// sends "command", "ack" (the acknowledged command), "error" and "ping"
+// frames; the device sends "ack" frames.
+type commandSocketMessage struct {
+	Type      string                 `json:"type"`
+	Command   *deviceCommandResponse `json:"command,omitempty"`
+	CommandID string                 `json:"command_id,omitempty"`
+	Status    string                 `json:"status,omitempty"`
+	Result    string                 `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// IssueCommand queues a reboot, resync_catalog, capture_test_image or
+// run_diagnostics command for the device. The operator is taken from
+// X-Actor-ID.
+func (h *HTTPHandler) IssueCommand(c *gin.Context) {
+	var req issueCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.commandIssuer.Handle(c.Request.Context(), app.IssueDeviceCommandCommand{
+		DeviceID: c.Param("id"),
+		Kind:     domain.CommandKind(req.Kind),
+		IssuedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDeviceCommandResponse(view))
+}
+
+// DeviceCommands lists the commands issued to the device newest first, with
+// their delivery and acknowledgement; ?limit= defaults to 50, max 200
+func (h *HTTPHandler) DeviceCommands(c *gin.Context) {
+	limit := defaultCommandLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxCommandLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	views, err := h.commandQuery.Commands(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDeviceCommandResponses(views))
+}
+
+// PollCommands is the device's long-poll for commands. It answers at once
+// with every command the device has not acknowledged yet, or waits up to
+// ?wait= seconds (default 25, max 60) for one and answers with an empty list
+// if none came. The device authenticates with its key in X-Device-Key.
+func (h *HTTPHandler) PollCommands(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+	wait := defaultCommandPollWait
+	if raw := c.Query("wait"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxCommandPollWait {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wait must be between 0 and 60 seconds"})
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	// The poll may outlast the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	views, err := h.commandDelivery.Poll(c.Request.Context(), machineID, c.GetHeader(deviceKeyHeader), wait)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDeviceCommandResponses(views))
+}
+
+// AcknowledgeCommand records whether the command succeeded or failed on the
+// device. The device authenticates with its key in X-Device-Key.
+func (h *HTTPHandler) AcknowledgeCommand(c *gin.Context) {
+	var req acknowledgeCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.commandAcknowledger.Handle(c.Request.Context(), app.AcknowledgeDeviceCommandCommand{
+		MachineID: req.MachineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+		CommandID: c.Param("id"),
+		Status:    domain.CommandStatus(req.Status),
+		Result:    req.Result,
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDeviceCommandResponse(view))
+}
+
+// CommandSocket delivers commands over a WebSocket as they are queued, after
+// the ones still unacknowledged when the device connects. The device may
+// acknowledge them on the socket with "ack" frames or with
+// AcknowledgeCommand. It authenticates with its key in X-Device-Key on the
+// upgrade request.
+func (h *HTTPHandler) CommandSocket(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+	key := c.GetHeader(deviceKeyHeader)
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// Authenticate before upgrading so a bad key gets a plain 401
+	commands, err := h.commandDelivery.Watch(ctx, machineID, key)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	websocket.Server{
+		// Devices are not browsers; the key authenticates them, not the origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer cancel()
+			// The socket outlives the server's read and write timeouts
+			_ = ws.SetDeadline(time.Time{})
+
+			replies := make(chan commandSocketMessage)
+			go h.receiveCommandAcks(ctx, cancel, ws, machineID, key, replies)
+
+			keepAlive := time.NewTicker(commandSocketKeepAlive)
+			defer keepAlive.Stop()
+
+			for {
+				var msg commandSocketMessage
+				select {
+				case <-ctx.Done():
+					return
+				case view, ok := <-commands:
+					if !ok {
+						return
+					}
+					response := toDeviceCommandResponse(view)
+					msg = commandSocketMessage{Type: "command", Command: &response}
+				case msg = <-replies:
+				case <-keepAlive.C:
+					msg = commandSocketMessage{Type: "ping"}
+				}
+				if err := websocket.JSON.Send(ws, msg); err != nil {
+					return
+				}
+			}
+		},
+	}.ServeHTTP(c.Writer, c.Request)
+}
+
+// receiveCommandAcks applies the "ack" frames the device sends and queues
+// the replies; it cancels the socket once the device hangs up
+func (h *HTTPHandler) receiveCommandAcks(ctx context.Context, cancel context.CancelFunc, ws *websocket.Conn, machineID, key string, replies chan<- commandSocketMessage) {
+	defer cancel()
+
+	for {
+		var msg commandSocketMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		reply := commandSocketMessage{Type: "error", CommandID: msg.CommandID, Error: "unknown message type"}
+		if msg.Type == "ack" {
+			view, err := h.commandAcknowledger.Handle(ctx, app.AcknowledgeDeviceCommandCommand{
+				MachineID: machineID,
+				DeviceKey: key,
+				CommandID: msg.CommandID,
+				Status:    domain.CommandStatus(msg.Status),
+				Result:    msg.Result,
+			})
+			if err != nil {
+				reply.Error = err.Error()
+			} else {
+				response := toDeviceCommandResponse(view)
+				reply = commandSocketMessage{Type: "ack", CommandID: view.ID, Command: &response}
+			}
+		}
+
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func toDeviceCommandResponse(v app.DeviceCommandView) deviceCommandResponse {
+	return deviceCommandResponse{
+		ID:             v.ID,
+		MachineID:      v.MachineID,
+		Kind:           v.Kind,
+		Status:         v.Status,
+		IssuedBy:       v.IssuedBy,
+		IssuedAt:       v.IssuedAt,
+		Deliveries:     v.Deliveries,
+		DeliveredAt:    v.DeliveredAt,
+		AcknowledgedAt: v.AcknowledgedAt,
+		Result:         v.Result,
+	}
+}
+
+func toDeviceCommandResponses(views []app.DeviceCommandView) []deviceCommandResponse {
+	response := make([]deviceCommandResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toDeviceCommandResponse(v))
+	}
+	return response
+}
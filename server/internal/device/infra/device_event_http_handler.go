@@ -0,0 +1,127 @@
+package infra
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+const (
+	defaultDeviceEventLimit = 100
+	maxDeviceEventLimit     = 1000
+)
+
+type deviceEventsRequest struct {
+	MachineID string               `json:"machine_id" binding:"required"`
+	Events    []deviceEventRequest `json:"events"`
+}
+
+type deviceEventRequest struct {
+	Kind       string    `json:"kind"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Detail     string    `json:"detail"`
+}
+
+type deviceEventResponse struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ReceivedAt time.Time `json:"received_at"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// RecordEvents appends door, tamper and power events to the device's event
+// log. Devices upload what they buffered while offline in batches of up to
+// 500; re-uploading a batch is harmless. The device authenticates with its
+// key in X-Device-Key.
+func (h *HTTPHandler) RecordEvents(c *gin.Context) {
+	var req deviceEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordDeviceEventsCommand{
+		MachineID: req.MachineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+	}
+	for _, e := range req.Events {
+		cmd.Events = append(cmd.Events, app.DeviceEventReport{
+			Kind:       domain.DeviceEventKind(e.Kind),
+			OccurredAt: e.OccurredAt,
+			Detail:     e.Detail,
+		})
+	}
+
+	result, err := h.eventRecorder.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"machine_id": result.MachineID,
+		"received":   result.Received,
+		"duplicates": result.Duplicates,
+	})
+}
+
+// Events lists the device's event log newest first. ?from= and ?to= (RFC
+// 3339) bound when the events occurred, to exclusive; ?limit= defaults to
+// 100 and is capped at 1000.
+func (h *HTTPHandler) Events(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	var from, to time.Time
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 timestamp"})
+				return
+			}
+			*target = t
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	limit := defaultDeviceEventLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDeviceEventLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+
+	views, err := h.eventQuery.Events(c.Request.Context(), machineID, from, to, limit)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	response := make([]deviceEventResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, deviceEventResponse{
+			ID:         v.ID,
+			Kind:       v.Kind,
+			OccurredAt: v.OccurredAt,
+			ReceivedAt: v.ReceivedAt,
+			Detail:     v.Detail,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
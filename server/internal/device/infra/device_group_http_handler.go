@@ -0,0 +1,274 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type createDeviceGroupRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type setGroupPolicyRequest struct {
+	ConfidenceThreshold      *float64             `json:"confidence_threshold"`
+	ModelVersion             string               `json:"model_version"`
+	SessionExpirationMinutes *int                 `json:"session_expiration_minutes"`
+	Planogram                []planogramFacingDTO `json:"planogram"` // omitted or null removes the group's planogram
+}
+
+type assignGroupDevicesRequest struct {
+	MachineIDs []string `json:"machine_ids" binding:"required"`
+}
+
+type setDevicePolicyRequest struct {
+	ConfidenceThreshold      *float64 `json:"confidence_threshold"`
+	ModelVersion             string   `json:"model_version"`
+	SessionExpirationMinutes *int     `json:"session_expiration_minutes"`
+}
+
+type deviceGroupResponse struct {
+	ID                       string               `json:"id"`
+	Name                     string               `json:"name"`
+	ConfidenceThreshold      *float64             `json:"confidence_threshold"`
+	ModelVersion             string               `json:"model_version,omitempty"`
+	SessionExpirationMinutes *int                 `json:"session_expiration_minutes"`
+	PlanogramVersion         int                  `json:"planogram_version,omitempty"`
+	Planogram                []planogramFacingDTO `json:"planogram,omitempty"`
+	DeviceCount              int                  `json:"device_count"`
+	MachineIDs               []string             `json:"machine_ids,omitempty"`
+	CreatedAt                time.Time            `json:"created_at"`
+	UpdatedAt                time.Time            `json:"updated_at"`
+}
+
+type devicePolicySettingResponse struct {
+	ConfidenceThreshold      *float64 `json:"confidence_threshold"`
+	ModelVersion             string   `json:"model_version,omitempty"`
+	SessionExpirationMinutes *int     `json:"session_expiration_minutes"`
+}
+
+type devicePolicyResponse struct {
+	DeviceID                       string                      `json:"device_id"`
+	MachineID                      string                      `json:"machine_id"`
+	GroupID                        string                      `json:"group_id,omitempty"`
+	ConfidenceThreshold            *float64                    `json:"confidence_threshold"`
+	ConfidenceThresholdSource      string                      `json:"confidence_threshold_source"`
+	ModelVersion                   string                      `json:"model_version,omitempty"`
+	ModelVersionSource             string                      `json:"model_version_source"`
+	SessionExpirationMinutes       *int                        `json:"session_expiration_minutes"`
+	SessionExpirationMinutesSource string                      `json:"session_expiration_minutes_source"`
+	PlanogramVersion               int                         `json:"planogram_version,omitempty"`
+	PlanogramSource                string                      `json:"planogram_source,omitempty"`
+	Overrides                      devicePolicySettingResponse `json:"overrides"`
+}
+
+// CreateDeviceGroup creates an empty device group
+func (h *HTTPHandler) CreateDeviceGroup(c *gin.Context) {
+	var req createDeviceGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.groupCreator.Handle(c.Request.Context(), req.Name)
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDeviceGroupResponse(*view))
+}
+
+// ListDeviceGroups lists the device groups by name with their device counts
+func (h *HTTPHandler) ListDeviceGroups(c *gin.Context) {
+	views, err := h.groupQuery.List(c.Request.Context())
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	response := make([]deviceGroupResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toDeviceGroupResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeviceGroup returns a device group with its policy and devices
+func (h *HTTPHandler) GetDeviceGroup(c *gin.Context) {
+	view, err := h.groupQuery.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeviceGroupResponse(*view))
+}
+
+// SetGroupPolicy replaces the policy of a group's devices. Settings left out
+// fall back to the deployment's defaults.
+func (h *HTTPHandler) SetGroupPolicy(c *gin.Context) {
+	var req setGroupPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.SetGroupPolicyCommand{
+		GroupID:                  c.Param("id"),
+		ConfidenceThreshold:      req.ConfidenceThreshold,
+		ModelVersion:             req.ModelVersion,
+		SessionExpirationMinutes: req.SessionExpirationMinutes,
+	}
+	if req.Planogram != nil {
+		cmd.Planogram = make([]domain.PlanogramFacing, 0, len(req.Planogram))
+		for _, f := range req.Planogram {
+			cmd.Planogram = append(cmd.Planogram, domain.PlanogramFacing{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+		}
+	}
+
+	view, err := h.groupPolicyHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeviceGroupResponse(*view))
+}
+
+// AssignGroupDevices moves devices into a group; no device is moved when a
+// machine ID is unknown
+func (h *HTTPHandler) AssignGroupDevices(c *gin.Context) {
+	var req assignGroupDevicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.groupAssigner.Handle(c.Request.Context(), app.AssignGroupDevicesCommand{
+		GroupID:    c.Param("id"),
+		MachineIDs: req.MachineIDs,
+	})
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeviceGroupResponse(*view))
+}
+
+// RemoveGroupDevice takes a device out of a group
+func (h *HTTPHandler) RemoveGroupDevice(c *gin.Context) {
+	view, err := h.groupRemover.Handle(c.Request.Context(), app.RemoveGroupDeviceCommand{
+		GroupID:   c.Param("id"),
+		MachineID: c.Param("machine_id"),
+	})
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeviceGroupResponse(*view))
+}
+
+// SetDevicePolicy replaces the settings overriding the group policy for one
+// device. Settings left out follow the group.
+func (h *HTTPHandler) SetDevicePolicy(c *gin.Context) {
+	var req setDevicePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.devicePolicyHandler.Handle(c.Request.Context(), app.SetDevicePolicyCommand{
+		DeviceID:                 c.Param("id"),
+		ConfidenceThreshold:      req.ConfidenceThreshold,
+		ModelVersion:             req.ModelVersion,
+		SessionExpirationMinutes: req.SessionExpirationMinutes,
+	})
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDevicePolicyResponse(*view))
+}
+
+// DevicePolicy returns the policy that applies to a device and where each
+// setting comes from
+func (h *HTTPHandler) DevicePolicy(c *gin.Context) {
+	view, err := h.groupQuery.PolicyByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeviceGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDevicePolicyResponse(*view))
+}
+
+func (h *HTTPHandler) writeDeviceGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceGroupNotFound),
+		errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotInGroup):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidGroupName),
+		errors.Is(err, domain.ErrInvalidConfidenceThreshold),
+		errors.Is(err, domain.ErrInvalidModelVersion),
+		errors.Is(err, domain.ErrInvalidSessionExpiration),
+		errors.Is(err, domain.ErrEmptyPlanogram),
+		errors.Is(err, domain.ErrInvalidFacing),
+		errors.Is(err, domain.ErrDuplicateFacing):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toDeviceGroupResponse(v app.DeviceGroupView) deviceGroupResponse {
+	response := deviceGroupResponse{
+		ID:                       v.ID,
+		Name:                     v.Name,
+		ConfidenceThreshold:      v.ConfidenceThreshold,
+		ModelVersion:             v.ModelVersion,
+		SessionExpirationMinutes: v.SessionExpirationMinutes,
+		DeviceCount:              v.DeviceCount,
+		MachineIDs:               v.MachineIDs,
+		CreatedAt:                v.CreatedAt,
+		UpdatedAt:                v.UpdatedAt,
+	}
+	if len(v.Planogram) > 0 {
+		response.PlanogramVersion = v.PlanogramVersion
+		for _, f := range v.Planogram {
+			response.Planogram = append(response.Planogram, planogramFacingDTO{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+		}
+	}
+	return response
+}
+
+func toDevicePolicyResponse(v app.DevicePolicyView) devicePolicyResponse {
+	return devicePolicyResponse{
+		DeviceID:                       v.DeviceID,
+		MachineID:                      v.MachineID,
+		GroupID:                        v.GroupID,
+		ConfidenceThreshold:            v.ConfidenceThreshold,
+		ConfidenceThresholdSource:      string(v.ConfidenceThresholdSource),
+		ModelVersion:                   v.ModelVersion,
+		ModelVersionSource:             string(v.ModelVersionSource),
+		SessionExpirationMinutes:       v.SessionExpirationMinutes,
+		SessionExpirationMinutesSource: string(v.SessionExpirationMinutesSource),
+		PlanogramVersion:               v.PlanogramVersion,
+		PlanogramSource:                string(v.PlanogramSource),
+		Overrides: devicePolicySettingResponse{
+			ConfidenceThreshold:      v.Overrides.ConfidenceThreshold,
+			ModelVersion:             v.Overrides.ModelVersion,
+			SessionExpirationMinutes: v.Overrides.SessionExpirationMinutes,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package infra
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQRCodeSize = 256
+	minQRCodeSize     = 64
+	maxQRCodeSize     = 1024
+)
+
+// DeviceQRCode renders the QR code for a device's label as a PNG of about
+// size pixels square, holding the device's deep link or its machine ID
+func (h *HTTPHandler) DeviceQRCode(c *gin.Context) {
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultQRCodeSize)))
+	if err != nil || size < minQRCodeSize || size > maxQRCodeSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("size must be between %d and %d", minQRCodeSize, maxQRCodeSize)})
+		return
+	}
+
+	label, err := h.qrCodeHandler.Handle(c.Request.Context(), c.Param("id"), size)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", label.MachineID+".png"))
+	c.Data(http.StatusOK, "image/png", label.PNG)
+}
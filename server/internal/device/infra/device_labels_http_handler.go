@@ -0,0 +1,48 @@
+package infra
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+type setDeviceLabelsRequest struct {
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type deviceLabelsResponse struct {
+	DeviceID  string            `json:"device_id"`
+	MachineID string            `json:"machine_id"`
+	Tags      []string          `json:"tags"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// SetDeviceLabels replaces the device's tags and metadata, which the device
+// listing filters on with ?tag= and ?metadata[key]=
+func (h *HTTPHandler) SetDeviceLabels(c *gin.Context) {
+	var req setDeviceLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.labelsSetter.Handle(c.Request.Context(), app.SetDeviceLabelsCommand{
+		DeviceID: c.Param("id"),
+		Tags:     req.Tags,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceLabelsResponse{
+		DeviceID:  view.DeviceID,
+		MachineID: view.MachineID,
+		Tags:      view.Tags,
+		Metadata:  view.Metadata,
+	})
+}
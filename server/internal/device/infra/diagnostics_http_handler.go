@@ -0,0 +1,119 @@
+package infra
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type uploadDiagnosticsRequest struct {
+	MachineID    string `json:"machine_id" binding:"required"`
+	CameraOK     bool   `json:"camera_ok"`
+	ScaleOK      bool   `json:"scale_ok"`
+	ModelLoaded  bool   `json:"model_loaded"`
+	ModelVersion string `json:"model_version"`
+	Notes        string `json:"notes"`
+}
+
+type diagnosticReportResponse struct {
+	Passed       bool      `json:"passed"`
+	CameraOK     bool      `json:"camera_ok"`
+	ScaleOK      bool      `json:"scale_ok"`
+	ModelLoaded  bool      `json:"model_loaded"`
+	ModelVersion string    `json:"model_version,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+type diagnosticsResponse struct {
+	CommandID string                    `json:"command_id"`
+	MachineID string                    `json:"machine_id"`
+	Status    string                    `json:"status"` // status of the run_diagnostics command
+	IssuedBy  string                    `json:"issued_by"`
+	IssuedAt  time.Time                 `json:"issued_at"`
+	Report    *diagnosticReportResponse `json:"report,omitempty"`
+}
+
+// RequestDiagnostics queues a run_diagnostics command for the device and
+// answers 202 with the run to poll for its report. The operator is taken
+// from X-Actor-ID.
+func (h *HTTPHandler) RequestDiagnostics(c *gin.Context) {
+	view, err := h.commandIssuer.Handle(c.Request.Context(), app.IssueDeviceCommandCommand{
+		DeviceID: c.Param("id"),
+		Kind:     domain.CommandRunDiagnostics,
+		IssuedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toDiagnosticsResponse(app.DiagnosticsView{Command: view}))
+}
+
+// Diagnostics returns a diagnostics run of the device, with the device's
+// self-test report once it has been uploaded
+func (h *HTTPHandler) Diagnostics(c *gin.Context) {
+	view, err := h.diagnosticsQuery.Diagnostics(c.Request.Context(), c.Param("id"), c.Param("command_id"))
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDiagnosticsResponse(view))
+}
+
+// UploadDiagnostics stores the self-test report the device ran for a
+// run_diagnostics command, which acknowledges the command. The device
+// authenticates with its key in X-Device-Key.
+func (h *HTTPHandler) UploadDiagnostics(c *gin.Context) {
+	var req uploadDiagnosticsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.diagnosticsUploader.Handle(c.Request.Context(), app.UploadDiagnosticsCommand{
+		MachineID: req.MachineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+		CommandID: c.Param("id"),
+		Checks: domain.DiagnosticChecks{
+			CameraOK:     req.CameraOK,
+			ScaleOK:      req.ScaleOK,
+			ModelLoaded:  req.ModelLoaded,
+			ModelVersion: req.ModelVersion,
+			Notes:        req.Notes,
+		},
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toDiagnosticsResponse(view))
+}
+
+func toDiagnosticsResponse(v app.DiagnosticsView) diagnosticsResponse {
+	response := diagnosticsResponse{
+		CommandID: v.Command.ID,
+		MachineID: v.Command.MachineID,
+		Status:    v.Command.Status,
+		IssuedBy:  v.Command.IssuedBy,
+		IssuedAt:  v.Command.IssuedAt,
+	}
+	if v.Report != nil {
+		response.Report = &diagnosticReportResponse{
+			Passed:       v.Report.Passed(),
+			CameraOK:     v.Report.CameraOK,
+			ScaleOK:      v.Report.ScaleOK,
+			ModelLoaded:  v.Report.ModelLoaded,
+			ModelVersion: v.Report.ModelVersion,
+			Notes:        v.Report.Notes,
+			ReportedAt:   *v.ReportedAt,
+		}
+	}
+	return response
+}
@@ -0,0 +1,109 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type enrollmentTokenRequest struct {
+	MachineID string `json:"machine_id" binding:"required"`
+	Name      string `json:"name"`
+	Location  string `json:"location"`
+	// Override allows enrolling a decommissioned machine ID again
+	Override bool `json:"override"`
+}
+
+type enrollDeviceRequest struct {
+	MachineID       string `json:"machine_id" binding:"required"`
+	EnrollmentToken string `json:"enrollment_token" binding:"required"`
+}
+
+// CreateEnrollmentToken provisions a machine: the operator in X-Actor-ID gets
+// a one-time token to enroll the device with. The token is shown once.
+func (h *HTTPHandler) CreateEnrollmentToken(c *gin.Context) {
+	var req enrollmentTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.tokenHandler.Handle(c.Request.Context(), app.CreateEnrollmentTokenCommand{
+		MachineID: req.MachineID,
+		Name:      req.Name,
+		Location:  req.Location,
+		CreatedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+		Override:  req.Override,
+	})
+	if err != nil {
+		writeEnrollmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"machine_id":       result.MachineID,
+		"enrollment_token": result.Token,
+		"expires_at":       result.ExpiresAt,
+	})
+}
+
+// Enroll redeems an enrollment token and returns the device's key. The key is
+// shown once; the device keeps it to authenticate its own requests.
+func (h *HTTPHandler) Enroll(c *gin.Context) {
+	var req enrollDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.enrollHandler.Handle(c.Request.Context(), app.EnrollDeviceCommand{
+		MachineID:       req.MachineID,
+		EnrollmentToken: req.EnrollmentToken,
+	})
+	if err != nil {
+		writeEnrollmentError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	message := "device registered"
+	switch {
+	case result.Recommissioned:
+		message = "device recommissioned"
+	case !result.IsNew:
+		status = http.StatusOK
+		message = "device re-enrolled"
+	}
+
+	response := gin.H{
+		"id":         result.DeviceID,
+		"machine_id": result.MachineID,
+		"message":    message,
+		"device_key": result.DeviceKey,
+	}
+	if result.Region != "" {
+		response["region"] = result.Region
+	}
+	c.JSON(status, response)
+}
+
+func writeEnrollmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMachineID):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrEnrolledByRequired),
+		errors.Is(err, domain.ErrInvalidEnrollmentToken),
+		errors.Is(err, domain.ErrEnrollmentTokenRedeemed),
+		errors.Is(err, domain.ErrEnrollmentTokenExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceDecommissioned):
+		c.JSON(http.StatusConflict, gin.H{"error": "device is decommissioned; an operator override is required to enroll it again"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
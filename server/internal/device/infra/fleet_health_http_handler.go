@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+type fleetHealthResponse struct {
+	DevicesByStatus map[string]int             `json:"devices_by_status"`
+	Offline         offlineDevicesResponse     `json:"offline"`
+	StaleModels     staleModelDevicesResponse  `json:"stale_models"`
+	WeightMismatch  weightMismatchRateResponse `json:"weight_mismatch"`
+}
+
+type offlineDevicesResponse struct {
+	AfterMinutes int       `json:"after_minutes"`
+	Since        time.Time `json:"since"`
+	Count        int       `json:"count"`
+	MachineIDs   []string  `json:"machine_ids"`
+}
+
+type staleModelDevicesResponse struct {
+	Count      int      `json:"count"`
+	MachineIDs []string `json:"machine_ids"`
+}
+
+type weightMismatchRateResponse struct {
+	OpenSessions int     `json:"open_sessions"`
+	Mismatched   int     `json:"mismatched"`
+	Rate         float64 `json:"rate"`
+}
+
+// FleetHealth summarizes the fleet for the ops dashboard: devices per
+// status, devices offline for longer than offline_minutes (the staleness
+// window by default), devices running another model than their policy pins
+// and the share of open sessions with a weight mismatch
+func (h *HTTPHandler) FleetHealth(c *gin.Context) {
+	offlineAfter := h.fleetHealth.OfflineAfter()
+	if raw := c.Query("offline_minutes"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil || minutes < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offline_minutes must be a positive number"})
+			return
+		}
+		offlineAfter = time.Duration(minutes) * time.Minute
+	}
+
+	health, err := h.fleetHealth.Summary(c.Request.Context(), offlineAfter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, toFleetHealthResponse(health, offlineAfter))
+}
+
+func toFleetHealthResponse(health app.FleetHealth, offlineAfter time.Duration) fleetHealthResponse {
+	return fleetHealthResponse{
+		DevicesByStatus: health.DevicesByStatus,
+		Offline: offlineDevicesResponse{
+			AfterMinutes: int(offlineAfter / time.Minute),
+			Since:        health.OfflineSince,
+			Count:        len(health.Offline),
+			MachineIDs:   health.Offline,
+		},
+		StaleModels: staleModelDevicesResponse{
+			Count:      len(health.StaleModels),
+			MachineIDs: health.StaleModels,
+		},
+		WeightMismatch: weightMismatchRateResponse{
+			OpenSessions: health.OpenSessions,
+			Mismatched:   health.WeightMismatches,
+			Rate:         health.WeightMismatchRate(),
+		},
+	}
+}
@@ -0,0 +1,273 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// deviceKeyHeader carries the key issued to the device at registration
+const deviceKeyHeader = "X-Device-Key"
+
+const (
+	defaultDeviceLimit = 50
+	maxDeviceLimit     = 200
+)
+
+type heartbeatRequest struct {
+	MachineID       string                `json:"machine_id" binding:"required"`
+	FirmwareVersion string                `json:"firmware_version"`
+	AppVersion      string                `json:"app_version"`
+	ModelVersion    string                `json:"model_version"`
+	Updates         []updateReportRequest `json:"updates"`
+}
+
+type updateReportRequest struct {
+	RolloutID string `json:"rollout_id" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Detail    string `json:"detail"`
+}
+
+type deviceResponse struct {
+	ID                string            `json:"id"`
+	MachineID         string            `json:"machine_id"`
+	Name              string            `json:"name,omitempty"`
+	Location          string            `json:"location,omitempty"`
+	Region            string            `json:"region,omitempty"`
+	Status            string            `json:"status"`
+	LastSeenAt        *time.Time        `json:"last_seen_at"`
+	OfflineSince      *time.Time        `json:"offline_since,omitempty"`
+	FirmwareVersion   string            `json:"firmware_version,omitempty"`
+	AppVersion        string            `json:"app_version,omitempty"`
+	ModelVersion      string            `json:"model_version,omitempty"`
+	Stale             bool              `json:"stale"`
+	DecommissionedAt  *time.Time        `json:"decommissioned_at,omitempty"`
+	DecommissionedBy  string            `json:"decommissioned_by,omitempty"`
+	MaintenanceSince  *time.Time        `json:"maintenance_since,omitempty"`
+	MaintenanceBy     string            `json:"maintenance_by,omitempty"`
+	MaintenanceReason string            `json:"maintenance_reason,omitempty"`
+	GroupID           string            `json:"group_id,omitempty"`
+	RestockSessionID  string            `json:"restock_session_id,omitempty"`
+	Tags              []string          `json:"tags"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// Heartbeat marks the device as alive and records the versions it runs and
+// how far it got with the updates it was offered. The response lists the
+// releases the device should download and install. The device authenticates
+// with its key in X-Device-Key.
+func (h *HTTPHandler) Heartbeat(c *gin.Context) {
+	var req heartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordHeartbeatCommand{
+		MachineID:       req.MachineID,
+		DeviceKey:       c.GetHeader(deviceKeyHeader),
+		FirmwareVersion: req.FirmwareVersion,
+		AppVersion:      req.AppVersion,
+		ModelVersion:    req.ModelVersion,
+	}
+	for _, u := range req.Updates {
+		cmd.Updates = append(cmd.Updates, app.UpdateReport{
+			RolloutID: u.RolloutID,
+			Status:    domain.UpdateStatus(u.Status),
+			Detail:    u.Detail,
+		})
+	}
+
+	result, err := h.heartbeatHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"machine_id":   result.MachineID,
+		"status":       result.Status,
+		"last_seen_at": result.LastSeenAt,
+		"updates":      toOfferedUpdateResponses(result.MachineID, result.Updates),
+	})
+}
+
+// ListDevices lists devices by machine ID for the operator dashboard. It
+// filters by ?status=, ?location= (part of it), ?stale=true|false, the last
+// heartbeat with ?seen_after= and ?seen_before= (RFC 3339; devices never
+// seen count as seen before any time), labels with ?tag= (repeatable; every
+// tag must be on the device) and ?metadata[key]=value, and pages with
+// ?limit= and ?offset=.
+func (h *HTTPHandler) ListDevices(c *gin.Context) {
+	filter, err := parseDeviceFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeviceLimit)))
+	if err != nil || limit < 1 || limit > maxDeviceLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxDeviceLimit)})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must not be negative"})
+		return
+	}
+
+	page, err := h.deviceQuery.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	response := make([]deviceResponse, 0, len(page.Devices))
+	for _, v := range page.Devices {
+		response = append(response, toDeviceResponse(v))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"devices":     response,
+		"total":       page.Total,
+		"limit":       limit,
+		"offset":      offset,
+		"stale_after": h.deviceQuery.StaleAfter().String(),
+	})
+}
+
+func parseDeviceFilter(c *gin.Context) (app.DeviceFilter, error) {
+	filter := app.DeviceFilter{
+		Location: strings.TrimSpace(c.Query("location")),
+		Tags:     c.QueryArray("tag"),
+		Metadata: c.QueryMap("metadata"),
+	}
+
+	switch status := domain.DeviceStatus(c.Query("status")); status {
+	case "", domain.DeviceStatusActive, domain.DeviceStatusInactive, domain.DeviceStatusBlocked, domain.DeviceStatusMaintenance, domain.DeviceStatusDecommissioned:
+		filter.Status = status
+	default:
+		return app.DeviceFilter{}, errors.New("status must be active, inactive, blocked, maintenance or decommissioned")
+	}
+
+	if raw := c.Query("stale"); raw != "" {
+		stale, err := strconv.ParseBool(raw)
+		if err != nil {
+			return app.DeviceFilter{}, errors.New("stale must be true or false")
+		}
+		filter.Stale = &stale
+	}
+
+	for param, target := range map[string]**time.Time{"seen_after": &filter.SeenAfter, "seen_before": &filter.SeenBefore} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return app.DeviceFilter{}, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = &t
+		}
+	}
+	return filter, nil
+}
+
+// GetDevice returns one device with its last heartbeat
+func (h *HTTPHandler) GetDevice(c *gin.Context) {
+	view, err := h.deviceQuery.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeviceResponse(*view))
+}
+
+// IssueKey gives the device a new key, replacing the one it had
+func (h *HTTPHandler) IssueKey(c *gin.Context) {
+	result, err := h.keyHandler.Handle(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         result.DeviceID,
+		"machine_id": result.MachineID,
+		"device_key": result.DeviceKey,
+	})
+}
+
+func (h *HTTPHandler) writeDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeviceKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotFound),
+		errors.Is(err, domain.ErrDeviceCommandNotFound),
+		errors.Is(err, domain.ErrDiagnosticsNotFound),
+		errors.Is(err, domain.ErrRestockSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidVersion),
+		errors.Is(err, domain.ErrInvalidModelVersion),
+		errors.Is(err, domain.ErrRolloutNotFound),
+		errors.Is(err, domain.ErrInvalidUpdateStatus),
+		errors.Is(err, domain.ErrInvalidDeviceEvent),
+		errors.Is(err, domain.ErrNoDeviceEvents),
+		errors.Is(err, domain.ErrTooManyDeviceEvents),
+		errors.Is(err, domain.ErrInvalidCommandKind),
+		errors.Is(err, domain.ErrInvalidCommandResult),
+		errors.Is(err, domain.ErrInvalidDiagnostics),
+		errors.Is(err, domain.ErrInvalidTag),
+		errors.Is(err, domain.ErrInvalidMetadata),
+		errors.Is(err, domain.ErrInvalidRestock),
+		errors.Is(err, domain.ErrUnknownSKU):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDecommissionedByRequired),
+		errors.Is(err, domain.ErrMaintainedByRequired),
+		errors.Is(err, domain.ErrCommandIssuerRequired),
+		errors.Is(err, domain.ErrRestockedByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceDecommissioned),
+		errors.Is(err, domain.ErrDeviceInMaintenance),
+		errors.Is(err, domain.ErrDeviceNotInMaintenance),
+		errors.Is(err, domain.ErrDeviceBlocked),
+		errors.Is(err, domain.ErrDeviceInactive),
+		errors.Is(err, domain.ErrCommandAcknowledged),
+		errors.Is(err, domain.ErrRestockSessionOpen),
+		errors.Is(err, domain.ErrRestockSessionClosed),
+		errors.Is(err, domain.ErrCustomerSessionActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toDeviceResponse(v app.DeviceView) deviceResponse {
+	return deviceResponse{
+		ID:                v.ID,
+		MachineID:         v.MachineID,
+		Name:              v.Name,
+		Location:          v.Location,
+		Region:            v.Region,
+		Status:            v.Status,
+		LastSeenAt:        v.LastSeenAt,
+		OfflineSince:      v.OfflineSince,
+		FirmwareVersion:   v.FirmwareVersion,
+		AppVersion:        v.AppVersion,
+		ModelVersion:      v.ModelVersion,
+		Stale:             v.Stale,
+		DecommissionedAt:  v.DecommissionedAt,
+		DecommissionedBy:  v.DecommissionedBy,
+		MaintenanceSince:  v.MaintenanceSince,
+		MaintenanceBy:     v.MaintenanceBy,
+		MaintenanceReason: v.MaintenanceReason,
+		GroupID:           v.GroupID,
+		RestockSessionID:  v.RestockSessionID,
+		Tags:              v.Tags,
+		Metadata:          v.Metadata,
+	}
+}
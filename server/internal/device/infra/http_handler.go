@@ -3,98 +3,662 @@ package infra
 import (
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/vending-machine/server/internal/catalog/api"
 	"github.com/vending-machine/server/internal/device/app"
 	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/locale"
 )
 
+// actorIDHeader carries the operator identity, set by the operator's authenticating proxy
+const actorIDHeader = "X-Actor-ID"
+
 type HTTPHandler struct {
-	registerHandler *app.RegisterDeviceHandler
-	skuReader       api.SKUReader // Cross-context read
+	enrollHandler           *app.EnrollDeviceHandler
+	tokenHandler            *app.CreateEnrollmentTokenHandler
+	startTokenHandler       *app.IssueStartTokenHandler
+	snapshotHandler         *app.SubmitShelfSnapshotHandler
+	stockQuery              *app.StockQueryService
+	telemetryHandler        *app.RecordTelemetryHandler
+	clearHandler            *app.ClearDeviceHandler
+	excursionQuery          *app.ExcursionQueryService
+	incidentQuery           *app.IncidentQueryService
+	planogramHandler        *app.AssignPlanogramHandler
+	visitHandler            *app.RecordRestockVisitHandler
+	planogramQuery          *app.PlanogramQueryService
+	salesHoursHandler       *app.SetSalesHoursHandler
+	salesHoursQuery         *app.SalesHoursQueryService
+	batchHandler            *app.RecordBatchesHandler
+	writeOffHandler         *app.WriteOffBatchHandler
+	expiryQuery             *app.ExpiryQueryService
+	restockHandler          *app.RestockInventoryHandler
+	inventoryQuery          *app.InventoryQueryService
+	assignHandler           *app.AssignSKUsHandler
+	unassignHandler         *app.UnassignSKUHandler
+	assortmentQuery         *app.AssortmentQueryService
+	heartbeatHandler        *app.RecordHeartbeatHandler
+	keyHandler              *app.IssueDeviceKeyHandler
+	deviceQuery             *app.DeviceQueryService
+	decommissioner          *app.DecommissionDeviceHandler
+	groupCreator            *app.CreateDeviceGroupHandler
+	groupPolicyHandler      *app.SetGroupPolicyHandler
+	groupAssigner           *app.AssignGroupDevicesHandler
+	groupRemover            *app.RemoveGroupDeviceHandler
+	devicePolicyHandler     *app.SetDevicePolicyHandler
+	groupQuery              *app.DeviceGroupQueryService
+	configHandler           *app.SetDesiredConfigHandler
+	configPuller            *app.PullDeviceConfigHandler
+	configReporter          *app.ReportDeviceConfigHandler
+	configQuery             *app.ConfigQueryService
+	releaseUploader         *app.UploadReleaseHandler
+	releaseDownloader       *app.DownloadReleaseHandler
+	releaseQuery            *app.ReleaseQueryService
+	rolloutStarter          *app.StartRolloutHandler
+	rolloutAdvancer         *app.AdvanceRolloutHandler
+	rolloutAborter          *app.AbortRolloutHandler
+	rolloutQuery            *app.RolloutQueryService
+	fleetHealth             *app.FleetHealthQueryService
+	qrCodeHandler           *app.DeviceQRCodeHandler
+	maintenanceEntry        *app.EnterMaintenanceHandler
+	maintenanceExit         *app.ExitMaintenanceHandler
+	eventRecorder           *app.RecordDeviceEventsHandler
+	eventQuery              *app.DeviceEventQueryService
+	commandIssuer           *app.IssueDeviceCommandHandler
+	commandDelivery         *app.DeviceCommandDelivery
+	commandAcknowledger     *app.AcknowledgeDeviceCommandHandler
+	commandQuery            *app.DeviceCommandQueryService
+	expectedModelQuery      *app.ExpectedModelQueryService
+	offlineDetector         *app.DetectOfflineDevicesHandler
+	restockSessionStarter   *app.StartRestockSessionHandler
+	restockUnitsRecorder    *app.RecordRestockUnitsHandler
+	restockSessionCompleter *app.CompleteRestockSessionHandler
+	restockSessionCanceller *app.CancelRestockSessionHandler
+	restockSessionQuery     *app.RestockSessionQueryService
+	calibrationUploader     *app.UploadCameraCalibrationHandler
+	calibrationQuery        *app.CameraCalibrationQueryService
+	scaleCalibrator         *app.RecordScaleCalibrationHandler
+	scaleCalibrationQuery   *app.ScaleCalibrationQueryService
+	diagnosticsUploader     *app.UploadDiagnosticsHandler
+	diagnosticsQuery        *app.DiagnosticsQueryService
+	labelsSetter            *app.SetDeviceLabelsHandler
+	skuReader               api.SKUReader        // Cross-context read
+	deviceSyncReader        api.DeviceSyncReader // Cross-context read
 }
 
 func NewHTTPHandler(
-	registerHandler *app.RegisterDeviceHandler,
+	enrollHandler *app.EnrollDeviceHandler,
+	tokenHandler *app.CreateEnrollmentTokenHandler,
+	startTokenHandler *app.IssueStartTokenHandler,
+	snapshotHandler *app.SubmitShelfSnapshotHandler,
+	stockQuery *app.StockQueryService,
+	telemetryHandler *app.RecordTelemetryHandler,
+	clearHandler *app.ClearDeviceHandler,
+	excursionQuery *app.ExcursionQueryService,
+	incidentQuery *app.IncidentQueryService,
+	planogramHandler *app.AssignPlanogramHandler,
+	visitHandler *app.RecordRestockVisitHandler,
+	planogramQuery *app.PlanogramQueryService,
+	salesHoursHandler *app.SetSalesHoursHandler,
+	salesHoursQuery *app.SalesHoursQueryService,
+	batchHandler *app.RecordBatchesHandler,
+	writeOffHandler *app.WriteOffBatchHandler,
+	expiryQuery *app.ExpiryQueryService,
+	restockHandler *app.RestockInventoryHandler,
+	inventoryQuery *app.InventoryQueryService,
+	assignHandler *app.AssignSKUsHandler,
+	unassignHandler *app.UnassignSKUHandler,
+	assortmentQuery *app.AssortmentQueryService,
+	heartbeatHandler *app.RecordHeartbeatHandler,
+	keyHandler *app.IssueDeviceKeyHandler,
+	deviceQuery *app.DeviceQueryService,
+	decommissioner *app.DecommissionDeviceHandler,
+	groupCreator *app.CreateDeviceGroupHandler,
+	groupPolicyHandler *app.SetGroupPolicyHandler,
+	groupAssigner *app.AssignGroupDevicesHandler,
+	groupRemover *app.RemoveGroupDeviceHandler,
+	devicePolicyHandler *app.SetDevicePolicyHandler,
+	groupQuery *app.DeviceGroupQueryService,
+	configHandler *app.SetDesiredConfigHandler,
+	configPuller *app.PullDeviceConfigHandler,
+	configReporter *app.ReportDeviceConfigHandler,
+	configQuery *app.ConfigQueryService,
+	releaseUploader *app.UploadReleaseHandler,
+	releaseDownloader *app.DownloadReleaseHandler,
+	releaseQuery *app.ReleaseQueryService,
+	rolloutStarter *app.StartRolloutHandler,
+	rolloutAdvancer *app.AdvanceRolloutHandler,
+	rolloutAborter *app.AbortRolloutHandler,
+	rolloutQuery *app.RolloutQueryService,
+	fleetHealth *app.FleetHealthQueryService,
+	qrCodeHandler *app.DeviceQRCodeHandler,
+	maintenanceEntry *app.EnterMaintenanceHandler,
+	maintenanceExit *app.ExitMaintenanceHandler,
+	eventRecorder *app.RecordDeviceEventsHandler,
+	eventQuery *app.DeviceEventQueryService,
+	commandIssuer *app.IssueDeviceCommandHandler,
+	commandDelivery *app.DeviceCommandDelivery,
+	commandAcknowledger *app.AcknowledgeDeviceCommandHandler,
+	commandQuery *app.DeviceCommandQueryService,
+	expectedModelQuery *app.ExpectedModelQueryService,
+	offlineDetector *app.DetectOfflineDevicesHandler,
+	restockSessionStarter *app.StartRestockSessionHandler,
+	restockUnitsRecorder *app.RecordRestockUnitsHandler,
+	restockSessionCompleter *app.CompleteRestockSessionHandler,
+	restockSessionCanceller *app.CancelRestockSessionHandler,
+	restockSessionQuery *app.RestockSessionQueryService,
+	calibrationUploader *app.UploadCameraCalibrationHandler,
+	calibrationQuery *app.CameraCalibrationQueryService,
+	scaleCalibrator *app.RecordScaleCalibrationHandler,
+	scaleCalibrationQuery *app.ScaleCalibrationQueryService,
+	diagnosticsUploader *app.UploadDiagnosticsHandler,
+	diagnosticsQuery *app.DiagnosticsQueryService,
+	labelsSetter *app.SetDeviceLabelsHandler,
 	skuReader api.SKUReader,
+	deviceSyncReader api.DeviceSyncReader,
 ) *HTTPHandler {
 	return &HTTPHandler{
-		registerHandler: registerHandler,
-		skuReader:       skuReader,
+		enrollHandler:           enrollHandler,
+		tokenHandler:            tokenHandler,
+		startTokenHandler:       startTokenHandler,
+		snapshotHandler:         snapshotHandler,
+		stockQuery:              stockQuery,
+		telemetryHandler:        telemetryHandler,
+		clearHandler:            clearHandler,
+		excursionQuery:          excursionQuery,
+		incidentQuery:           incidentQuery,
+		planogramHandler:        planogramHandler,
+		visitHandler:            visitHandler,
+		planogramQuery:          planogramQuery,
+		salesHoursHandler:       salesHoursHandler,
+		salesHoursQuery:         salesHoursQuery,
+		batchHandler:            batchHandler,
+		writeOffHandler:         writeOffHandler,
+		expiryQuery:             expiryQuery,
+		restockHandler:          restockHandler,
+		inventoryQuery:          inventoryQuery,
+		assignHandler:           assignHandler,
+		unassignHandler:         unassignHandler,
+		assortmentQuery:         assortmentQuery,
+		heartbeatHandler:        heartbeatHandler,
+		keyHandler:              keyHandler,
+		deviceQuery:             deviceQuery,
+		decommissioner:          decommissioner,
+		groupCreator:            groupCreator,
+		groupPolicyHandler:      groupPolicyHandler,
+		groupAssigner:           groupAssigner,
+		groupRemover:            groupRemover,
+		devicePolicyHandler:     devicePolicyHandler,
+		groupQuery:              groupQuery,
+		configHandler:           configHandler,
+		configPuller:            configPuller,
+		configReporter:          configReporter,
+		configQuery:             configQuery,
+		releaseUploader:         releaseUploader,
+		releaseDownloader:       releaseDownloader,
+		releaseQuery:            releaseQuery,
+		rolloutStarter:          rolloutStarter,
+		rolloutAdvancer:         rolloutAdvancer,
+		rolloutAborter:          rolloutAborter,
+		rolloutQuery:            rolloutQuery,
+		fleetHealth:             fleetHealth,
+		qrCodeHandler:           qrCodeHandler,
+		maintenanceEntry:        maintenanceEntry,
+		maintenanceExit:         maintenanceExit,
+		eventRecorder:           eventRecorder,
+		eventQuery:              eventQuery,
+		commandIssuer:           commandIssuer,
+		commandDelivery:         commandDelivery,
+		commandAcknowledger:     commandAcknowledger,
+		commandQuery:            commandQuery,
+		expectedModelQuery:      expectedModelQuery,
+		offlineDetector:         offlineDetector,
+		restockSessionStarter:   restockSessionStarter,
+		restockUnitsRecorder:    restockUnitsRecorder,
+		restockSessionCompleter: restockSessionCompleter,
+		restockSessionCanceller: restockSessionCanceller,
+		restockSessionQuery:     restockSessionQuery,
+		calibrationUploader:     calibrationUploader,
+		calibrationQuery:        calibrationQuery,
+		scaleCalibrator:         scaleCalibrator,
+		scaleCalibrationQuery:   scaleCalibrationQuery,
+		diagnosticsUploader:     diagnosticsUploader,
+		diagnosticsQuery:        diagnosticsQuery,
+		labelsSetter:            labelsSetter,
+		skuReader:               skuReader,
+		deviceSyncReader:        deviceSyncReader,
 	}
 }
 
 // Request/Response DTOs
 
-type registerDeviceRequest struct {
+type shelfSnapshotRequest struct {
+	MachineID  string     `json:"machine_id" binding:"required"`
+	Image      []byte     `json:"image"` // base64-encoded JPEG/PNG
+	CapturedAt *time.Time `json:"captured_at"`
+}
+
+type stockLevelResponse struct {
+	SKUCode   string `json:"sku_code"`
+	Estimated int    `json:"estimated_quantity"`
+	Low       bool   `json:"low"`
+}
+
+type telemetryRequest struct {
+	MachineID          string     `json:"machine_id" binding:"required"`
+	RecordedAt         *time.Time `json:"recorded_at"`
+	TemperatureCelsius *float64   `json:"temperature_celsius"`
+	DoorOpen           *bool      `json:"door_open"`
+}
+
+type incidentResponse struct {
+	ID           string    `json:"id"`
+	Kind         string    `json:"kind"`
+	DoorOpenedAt time.Time `json:"door_opened_at"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+type clearDeviceRequest struct {
 	MachineID string `json:"machine_id" binding:"required"`
-	Name      string `json:"name"`
-	Location  string `json:"location"`
+	Note      string `json:"note"`
+}
+
+type excursionResponse struct {
+	ID           string     `json:"id"`
+	StartedAt    time.Time  `json:"started_at"`
+	DetectedAt   time.Time  `json:"detected_at"`
+	LimitCelsius float64    `json:"limit_celsius"`
+	PeakCelsius  float64    `json:"peak_celsius"`
+	ClearedAt    *time.Time `json:"cleared_at,omitempty"`
+	ClearedBy    string     `json:"cleared_by,omitempty"`
+	Note         string     `json:"note,omitempty"`
 }
 
 // Handlers
 
-func (h *HTTPHandler) Register(c *gin.Context) {
-	var req registerDeviceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// StartToken issues a signed, short-lived session-start token for the device's QR code.
+// The device refreshes its displayed QR code before the token expires and
+// authenticates with its key in X-Device-Key.
+func (h *HTTPHandler) StartToken(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
 		return
 	}
 
-	cmd := app.RegisterDeviceCommand{
-		MachineID: req.MachineID,
-		Name:      req.Name,
-		Location:  req.Location,
-	}
-
-	result, err := h.registerHandler.Handle(c.Request.Context(), cmd)
+	result, err := h.startTokenHandler.Handle(c.Request.Context(), app.IssueStartTokenCommand{
+		MachineID: machineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+	})
 	if err != nil {
 		switch {
-		case errors.Is(err, domain.ErrInvalidMachineID):
+		case errors.Is(err, domain.ErrInvalidDeviceKey):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrDeviceBlocked),
+			errors.Is(err, domain.ErrDeviceDecommissioned):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrDeviceInMaintenance):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_maintenance"})
+		case errors.Is(err, domain.ErrRestockSessionOpen):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_restocking"})
+		case errors.Is(err, domain.ErrDeviceInactive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "device is inactive"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
 		return
 	}
 
-	status := http.StatusCreated
-	message := "device registered"
-	if !result.IsNew {
-		status = http.StatusOK
-		message = "device already registered"
-	}
-
-	c.JSON(status, gin.H{
-		"id":         result.DeviceID,
+	c.JSON(http.StatusOK, gin.H{
 		"machine_id": result.MachineID,
-		"message":    message,
+		"token":      result.Token,
+		"expires_at": result.ExpiresAt,
 	})
 }
 
 // GetSKUs returns active SKUs for device ML model sync
-// This is a cross-context read using the Catalog API
+// This is a cross-context read using the Catalog API's device sync read
+// model; each SKU carries the class ID the detection model knows it by
+//
+// With ?since=<RFC 3339 timestamp> only the SKUs created, updated, deactivated
+// or deleted after it are returned, so devices can sync deltas. SKUs no longer
+// on sale come back as tombstones with active false. Either way the response
+// carries the cursor to pass as since on the next sync.
+//
+// Every response also carries the full class mapping, and with ?machine_id=
+// the model version the device's policy pins, so the device can check it
+// runs a compatible model before it trusts its detections.
 func (h *HTTPHandler) GetSKUs(c *gin.Context) {
-	skus, err := h.skuReader.FindAllActive(c.Request.Context())
+	cursor := time.Now().UTC()
+
+	if raw := c.Query("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		h.changedSKUs(c, since, cursor)
+		return
+	}
+
+	skus, err := h.deviceSyncReader.FindActive(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	languages := preferredLanguages(c)
 	var response []gin.H
 	for _, s := range skus {
-		response = append(response, gin.H{
+		response = append(response, localizedSKU(gin.H{
 			"code":             s.Code,
-			"name":             s.Name,
 			"weight_grams":     s.WeightGrams,
 			"weight_tolerance": s.WeightTolerance,
-		})
+			"class_id":         s.ClassID,
+		}, s, languages))
+	}
+
+	body := gin.H{
+		"skus":   response,
+		"count":  len(response),
+		"cursor": cursor,
+	}
+	if h.addModelCompatibility(c, body) {
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+func (h *HTTPHandler) changedSKUs(c *gin.Context, since, cursor time.Time) {
+	skus, err := h.deviceSyncReader.FindChangedSince(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	languages := preferredLanguages(c)
+	response := []gin.H{}
+	for _, s := range skus {
+		response = append(response, localizedSKU(gin.H{
+			"code":             s.Code,
+			"weight_grams":     s.WeightGrams,
+			"weight_tolerance": s.WeightTolerance,
+			"class_id":         s.ClassID,
+			"active":           s.Active,
+			"deleted":          s.Deleted,
+			"updated_at":       s.UpdatedAt,
+		}, s, languages))
+	}
+
+	body := gin.H{
+		"skus":   response,
+		"count":  len(response),
+		"since":  since,
+		"cursor": cursor,
+	}
+	if h.addModelCompatibility(c, body) {
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// addModelCompatibility adds the class mapping to a SKU sync response, and
+// the model version expected of the device when ?machine_id= names one. It
+// writes the error response and returns false when either read fails.
+func (h *HTTPHandler) addModelCompatibility(c *gin.Context, body gin.H) bool {
+	classes, err := h.deviceSyncReader.FindClasses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return false
+	}
+	mapping := make([]gin.H, 0, len(classes))
+	for _, class := range classes {
+		mapping = append(mapping, gin.H{"class_id": class.ClassID, "code": class.Code})
+	}
+	body["classes"] = mapping
+
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		return true
+	}
+	model, err := h.expectedModelQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return false
+	}
+	var version *string // null when no model is pinned
+	if model.Version != "" {
+		version = &model.Version
+	}
+	body["model_version"] = version
+	body["model_version_source"] = string(model.Source)
+	return true
+}
+
+// preferredLanguages reads the Accept-Language header; responses naming
+// SKUs vary with it
+func preferredLanguages(c *gin.Context) []string {
+	c.Header("Vary", "Accept-Language")
+	return locale.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// localizable is a catalog view that can name its SKU in a preferred language
+type localizable interface {
+	Localized(preferred []string) api.TranslationView
+}
+
+// localizedSKU adds the SKU's name, and description when it has one, in the
+// first preferred language it is translated to
+func localizedSKU(entry gin.H, s localizable, languages []string) gin.H {
+	localized := s.Localized(languages)
+	entry["name"] = localized.Name
+	if localized.Description != "" {
+		entry["description"] = localized.Description
+	}
+	return entry
+}
+
+// SubmitShelfSnapshot accepts a shelf photo taken outside a session and
+// updates the device's vision-based stock estimate
+func (h *HTTPHandler) SubmitShelfSnapshot(c *gin.Context) {
+	var req shelfSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.SubmitShelfSnapshotCommand{
+		MachineID: req.MachineID,
+		Image:     req.Image,
+	}
+	if req.CapturedAt != nil {
+		cmd.CapturedAt = *req.CapturedAt
+	}
+
+	view, err := h.snapshotHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockEstimateResponse(view))
+}
+
+// Stock returns the device's latest vision-based stock estimate
+func (h *HTTPHandler) Stock(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.stockQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockEstimateResponse(view))
+}
+
+func (h *HTTPHandler) writeStockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrDeviceInactive):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "device is inactive"})
+	case errors.Is(err, domain.ErrEmptySnapshot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrStaleSnapshot):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, app.ErrShelfDetectionUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toStockEstimateResponse(v *app.StockEstimateView) gin.H {
+	levels := make([]stockLevelResponse, 0, len(v.Levels))
+	for _, l := range v.Levels {
+		levels = append(levels, stockLevelResponse{SKUCode: l.SKUCode, Estimated: l.Estimated, Low: l.Low})
+	}
+
+	return gin.H{
+		"machine_id":    v.MachineID,
+		"model_version": v.ModelVersion,
+		"observed_at":   v.ObservedAt,
+		"low_threshold": v.LowThreshold,
+		"levels":        levels,
+	}
+}
+
+// Telemetry ingests periodic device readings. Refrigerated devices report the
+// cabinet temperature and are blocked after a sustained excursion.
+func (h *HTTPHandler) Telemetry(c *gin.Context) {
+	var req telemetryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordTelemetryCommand{
+		MachineID:          req.MachineID,
+		TemperatureCelsius: req.TemperatureCelsius,
+		DoorOpen:           req.DoorOpen,
+	}
+	if req.RecordedAt != nil {
+		cmd.RecordedAt = *req.RecordedAt
+	}
+
+	result, err := h.telemetryHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeExcursionError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"machine_id": result.MachineID,
+		"status":     result.Status,
+	}
+	if result.Incident != "" {
+		response["incident"] = result.Incident
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Clear is the operator action that lets a blocked device sell again
+func (h *HTTPHandler) Clear(c *gin.Context) {
+	var req clearDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.ClearDeviceCommand{
+		MachineID: req.MachineID,
+		ClearedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+		Note:      req.Note,
+	}
+	if err := h.clearHandler.Handle(c.Request.Context(), cmd); err != nil {
+		h.writeExcursionError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"skus":  response,
-		"count": len(response),
+		"machine_id": req.MachineID,
+		"status":     string(domain.DeviceStatusActive),
 	})
 }
+
+// Excursions lists the device's temperature excursions, newest first
+func (h *HTTPHandler) Excursions(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	views, err := h.excursionQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeExcursionError(c, err)
+		return
+	}
+
+	response := make([]excursionResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, excursionResponse{
+			ID:           v.ID,
+			StartedAt:    v.StartedAt,
+			DetectedAt:   v.DetectedAt,
+			LimitCelsius: v.LimitCelsius,
+			PeakCelsius:  v.PeakCelsius,
+			ClearedAt:    v.ClearedAt,
+			ClearedBy:    v.ClearedBy,
+			Note:         v.Note,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Incidents lists the device's security incidents, newest first
+func (h *HTTPHandler) Incidents(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	views, err := h.incidentQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeExcursionError(c, err)
+		return
+	}
+
+	response := make([]incidentResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, incidentResponse{
+			ID:           v.ID,
+			Kind:         v.Kind,
+			DoorOpenedAt: v.DoorOpenedAt,
+			DetectedAt:   v.DetectedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) writeExcursionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrClearedByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotBlocked),
+		errors.Is(err, domain.ErrExcursionCleared),
+		errors.Is(err, domain.ErrDeviceDecommissioned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,118 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type restockLineDTO struct {
+	SKUCode  string `json:"sku_code"`
+	Quantity int    `json:"quantity"`
+}
+
+type restockInventoryRequest struct {
+	Items []restockLineDTO `json:"items"`
+}
+
+type inventoryLevelResponse struct {
+	SKUCode  string `json:"sku_code"`
+	Quantity int    `json:"quantity"`
+	Low      bool   `json:"low"`
+}
+
+type inventoryResponse struct {
+	DeviceID     string                   `json:"device_id"`
+	MachineID    string                   `json:"machine_id"`
+	UpdatedAt    *time.Time               `json:"updated_at,omitempty"`
+	LowThreshold int                      `json:"low_threshold"`
+	Levels       []inventoryLevelResponse `json:"levels"`
+}
+
+// RestockInventory adds the units field staff loaded into a device to its
+// inventory; lines for the same SKU are added up
+func (h *HTTPHandler) RestockInventory(c *gin.Context) {
+	var req restockInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RestockInventoryCommand{
+		DeviceID:    c.Param("id"),
+		RestockedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+		Units:       make(map[string]int, len(req.Items)),
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			h.writeInventoryError(c, domain.ErrInvalidRestock)
+			return
+		}
+		cmd.Units[strings.TrimSpace(item.SKUCode)] += item.Quantity
+	}
+
+	view, err := h.restockHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInventoryResponse(*view))
+}
+
+// Inventory returns the counted units of each SKU in a device
+func (h *HTTPHandler) Inventory(c *gin.Context) {
+	view, err := h.inventoryQuery.FindByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInventoryResponse(*view))
+}
+
+// LowInventory lists the SKUs in a device below the low-stock threshold, for
+// planning restocking runs
+func (h *HTTPHandler) LowInventory(c *gin.Context) {
+	view, err := h.inventoryQuery.LowByDeviceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInventoryResponse(*view))
+}
+
+func (h *HTTPHandler) writeInventoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrRestockedByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRestock),
+		errors.Is(err, domain.ErrUnknownSKU):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toInventoryResponse(v app.InventoryView) inventoryResponse {
+	levels := make([]inventoryLevelResponse, 0, len(v.Levels))
+	for _, l := range v.Levels {
+		levels = append(levels, inventoryLevelResponse{SKUCode: l.SKUCode, Quantity: l.Quantity, Low: l.Low})
+	}
+	return inventoryResponse{
+		DeviceID:     v.DeviceID,
+		MachineID:    v.MachineID,
+		UpdatedAt:    v.UpdatedAt,
+		LowThreshold: v.LowThreshold,
+		Levels:       levels,
+	}
+}
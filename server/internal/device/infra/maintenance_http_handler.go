@@ -0,0 +1,70 @@
+package infra
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+type enterMaintenanceRequest struct {
+	MachineID string `json:"machine_id" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
+type exitMaintenanceRequest struct {
+	MachineID string `json:"machine_id" binding:"required"`
+}
+
+// EnterMaintenance stops an active device from selling while it is serviced.
+// The technician is taken from X-Actor-ID.
+func (h *HTTPHandler) EnterMaintenance(c *gin.Context) {
+	var req enterMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.maintenanceEntry.Handle(c.Request.Context(), app.EnterMaintenanceCommand{
+		MachineID: req.MachineID,
+		By:        strings.TrimSpace(c.GetHeader(actorIDHeader)),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                result.DeviceID,
+		"machine_id":        result.MachineID,
+		"status":            string(result.Status),
+		"maintenance_since": result.Since,
+	})
+}
+
+// ExitMaintenance puts a device under maintenance back into service
+func (h *HTTPHandler) ExitMaintenance(c *gin.Context) {
+	var req exitMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.maintenanceExit.Handle(c.Request.Context(), app.ExitMaintenanceCommand{
+		MachineID: req.MachineID,
+		By:        strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         result.DeviceID,
+		"machine_id": result.MachineID,
+		"status":     string(result.Status),
+	})
+}
@@ -0,0 +1,37 @@
+package infra
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+// SweepOfflineDevices runs the offline sweep now instead of waiting for the
+// next scheduled one. ?offline_seconds= overrides the offline window.
+func (h *HTTPHandler) SweepOfflineDevices(c *gin.Context) {
+	var cmd app.DetectOfflineDevicesCommand
+	if raw := c.Query("offline_seconds"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offline_seconds must be a positive number"})
+			return
+		}
+		cmd.OfflineAfter = time.Duration(seconds) * time.Second
+	}
+
+	result, err := h.offlineDetector.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"offline_after_seconds": int(result.OfflineAfter / time.Second),
+		"count":                 len(result.WentOffline),
+		"machine_ids":           result.WentOffline,
+	})
+}
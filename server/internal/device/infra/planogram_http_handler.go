@@ -0,0 +1,194 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type planogramFacingDTO struct {
+	Shelf   int    `json:"shelf"`
+	SKUCode string `json:"sku_code"`
+	Facings int    `json:"facings"`
+}
+
+type assignPlanogramRequest struct {
+	MachineID string               `json:"machine_id" binding:"required"`
+	Facings   []planogramFacingDTO `json:"facings"`
+}
+
+type restockVisitRequest struct {
+	MachineID  string     `json:"machine_id" binding:"required"`
+	Image      []byte     `json:"image"` // base64-encoded JPEG/PNG taken after restocking
+	CapturedAt *time.Time `json:"captured_at"`
+}
+
+type deviationResponse struct {
+	Shelf    int    `json:"shelf"`
+	SKUCode  string `json:"sku_code"`
+	Kind     string `json:"kind"`
+	Expected int    `json:"expected_facings"`
+	Found    int    `json:"found_facings"`
+}
+
+type complianceReportResponse struct {
+	ID               string              `json:"id"`
+	PlanogramVersion int                 `json:"planogram_version"`
+	VisitedBy        string              `json:"visited_by"`
+	Score            int                 `json:"score"`
+	Compliant        bool                `json:"compliant"`
+	Deviations       []deviationResponse `json:"deviations"`
+	CheckedAt        time.Time           `json:"checked_at"`
+}
+
+// AssignPlanogram sets or replaces the merchandising layout of a device
+func (h *HTTPHandler) AssignPlanogram(c *gin.Context) {
+	var req assignPlanogramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.AssignPlanogramCommand{MachineID: req.MachineID}
+	for _, f := range req.Facings {
+		cmd.Facings = append(cmd.Facings, domain.PlanogramFacing{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+	}
+
+	view, err := h.planogramHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writePlanogramError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPlanogramResponse(view))
+}
+
+// Planogram returns the device's current planogram
+func (h *HTTPHandler) Planogram(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.planogramQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writePlanogramError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPlanogramResponse(view))
+}
+
+// RecordRestockVisit checks the field staff's post-restock shelf snapshot
+// against the planogram and returns the compliance report
+func (h *HTTPHandler) RecordRestockVisit(c *gin.Context) {
+	var req restockVisitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordRestockVisitCommand{
+		MachineID: req.MachineID,
+		VisitedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+		Image:     req.Image,
+	}
+	if req.CapturedAt != nil {
+		cmd.CapturedAt = *req.CapturedAt
+	}
+
+	view, err := h.visitHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writePlanogramError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toComplianceReportResponse(*view))
+}
+
+// ComplianceReports lists the device's restock visit reports, newest first
+func (h *HTTPHandler) ComplianceReports(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	views, err := h.planogramQuery.ReportsByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writePlanogramError(c, err)
+		return
+	}
+
+	response := make([]complianceReportResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toComplianceReportResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) writePlanogramError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrPlanogramNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrVisitedByRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrEmptyPlanogram),
+		errors.Is(err, domain.ErrInvalidFacing),
+		errors.Is(err, domain.ErrDuplicateFacing):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrEmptySnapshot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, app.ErrShelfDetectionUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toPlanogramResponse(v *app.PlanogramView) gin.H {
+	facings := make([]planogramFacingDTO, 0, len(v.Facings))
+	for _, f := range v.Facings {
+		facings = append(facings, planogramFacingDTO{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+	}
+
+	return gin.H{
+		"machine_id": v.MachineID,
+		"version":    v.Version,
+		"source":     v.Source,
+		"facings":    facings,
+		"updated_at": v.UpdatedAt,
+	}
+}
+
+func toComplianceReportResponse(v app.ComplianceReportView) complianceReportResponse {
+	deviations := make([]deviationResponse, 0, len(v.Deviations))
+	for _, d := range v.Deviations {
+		deviations = append(deviations, deviationResponse{
+			Shelf:    d.Shelf,
+			SKUCode:  d.SKUCode,
+			Kind:     string(d.Kind),
+			Expected: d.Expected,
+			Found:    d.Found,
+		})
+	}
+
+	return complianceReportResponse{
+		ID:               v.ID,
+		PlanogramVersion: v.PlanogramVersion,
+		VisitedBy:        v.VisitedBy,
+		Score:            v.Score,
+		Compliant:        v.Compliant,
+		Deviations:       deviations,
+		CheckedAt:        v.CheckedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresAssortmentRepository implements domain.AssortmentRepository
+type PostgresAssortmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAssortmentRepository(pool *pgxpool.Pool) *PostgresAssortmentRepository {
+	return &PostgresAssortmentRepository{pool: pool}
+}
+
+// Save replaces the device's assigned SKUs with the assortment's
+func (r *PostgresAssortmentRepository) Save(ctx context.Context, a *domain.Assortment) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM device_sku_assignments WHERE device_id = $1`, a.DeviceID().String()); err != nil {
+		return err
+	}
+	for _, code := range a.SKUCodes() {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO device_sku_assignments (device_id, sku_code, updated_at)
+			VALUES ($1, $2, $3)
+		`, a.DeviceID().String(), code, a.UpdatedAt())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresAssortmentRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.Assortment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT sku_code, updated_at
+		FROM device_sku_assignments
+		WHERE device_id = $1
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		codes     []string
+		updatedAt time.Time
+	)
+	for rows.Next() {
+		var (
+			code string
+			at   time.Time
+		)
+		if err := rows.Scan(&code, &at); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		if at.After(updatedAt) {
+			updatedAt = at
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, domain.ErrAssortmentNotFound
+	}
+
+	return domain.ReconstituteAssortment(deviceID, codes, updatedAt), nil
+}
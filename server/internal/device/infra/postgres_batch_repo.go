@@ -0,0 +1,154 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresStockBatchRepository implements domain.StockBatchRepository
+type PostgresStockBatchRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStockBatchRepository(pool *pgxpool.Pool) *PostgresStockBatchRepository {
+	return &PostgresStockBatchRepository{pool: pool}
+}
+
+type stockBatchRow struct {
+	ID           string
+	DeviceID     string
+	SKUCode      string
+	Quantity     int
+	ExpiresOn    time.Time
+	RestockedBy  string
+	RestockedAt  time.Time
+	WrittenOffAt *time.Time
+	WrittenOffBy string
+	WastedUnits  int
+}
+
+const stockBatchColumns = `id, device_id, sku_code, quantity, expires_on, restocked_by, restocked_at, written_off_at, written_off_by, wasted_units`
+
+func (r *PostgresStockBatchRepository) Save(ctx context.Context, b *domain.StockBatch) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO stock_batches (`+stockBatchColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			written_off_at = EXCLUDED.written_off_at,
+			written_off_by = EXCLUDED.written_off_by,
+			wasted_units = EXCLUDED.wasted_units
+	`, b.ID().String(), b.DeviceID().String(), b.SKUCode(), b.Quantity(), b.ExpiresOn(),
+		b.RestockedBy(), b.RestockedAt(), b.WrittenOffAt(), b.WrittenOffBy(), b.WastedUnits())
+
+	return err
+}
+
+func (r *PostgresStockBatchRepository) FindByID(ctx context.Context, id valueobjects.BatchID) (*domain.StockBatch, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+stockBatchColumns+`
+		FROM stock_batches
+		WHERE id = $1
+	`, id.String())
+
+	return r.scanBatch(row)
+}
+
+func (r *PostgresStockBatchRepository) FindOpenByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*domain.StockBatch, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+stockBatchColumns+`
+		FROM stock_batches
+		WHERE device_id = $1 AND written_off_at IS NULL
+		ORDER BY sku_code, expires_on, restocked_at
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanBatches(rows)
+}
+
+func (r *PostgresStockBatchRepository) FindOpen(ctx context.Context) ([]*domain.StockBatch, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+stockBatchColumns+`
+		FROM stock_batches
+		WHERE written_off_at IS NULL
+		ORDER BY device_id, sku_code, expires_on, restocked_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanBatches(rows)
+}
+
+func (r *PostgresStockBatchRepository) FindWrittenOffBetween(ctx context.Context, deviceID valueobjects.DeviceID, from, to time.Time) ([]*domain.StockBatch, error) {
+	var device *string
+	if !deviceID.IsZero() {
+		id := deviceID.String()
+		device = &id
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+stockBatchColumns+`
+		FROM stock_batches
+		WHERE written_off_at >= $1 AND written_off_at < $2
+			AND ($3::uuid IS NULL OR device_id = $3::uuid)
+		ORDER BY written_off_at
+	`, from, to, device)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanBatches(rows)
+}
+
+func (r *PostgresStockBatchRepository) scanBatches(rows pgx.Rows) ([]*domain.StockBatch, error) {
+	defer rows.Close()
+
+	var batches []*domain.StockBatch
+	for rows.Next() {
+		b, err := r.scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+func (r *PostgresStockBatchRepository) scanBatch(row pgx.Row) (*domain.StockBatch, error) {
+	var rec stockBatchRow
+	err := row.Scan(
+		&rec.ID, &rec.DeviceID, &rec.SKUCode, &rec.Quantity, &rec.ExpiresOn,
+		&rec.RestockedBy, &rec.RestockedAt, &rec.WrittenOffAt, &rec.WrittenOffBy, &rec.WastedUnits,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBatchNotFound
+		}
+		return nil, err
+	}
+
+	id, _ := valueobjects.BatchIDFrom(rec.ID)
+	deviceID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+
+	return domain.ReconstituteStockBatch(
+		id,
+		deviceID,
+		rec.SKUCode,
+		rec.Quantity,
+		rec.ExpiresOn,
+		rec.RestockedBy,
+		rec.RestockedAt,
+		rec.WrittenOffAt,
+		rec.WrittenOffBy,
+		rec.WastedUnits,
+	), nil
+}
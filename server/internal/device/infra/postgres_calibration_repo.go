@@ -0,0 +1,97 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresCameraCalibrationRepository implements domain.CameraCalibrationRepository
+type PostgresCameraCalibrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCameraCalibrationRepository(pool *pgxpool.Pool) *PostgresCameraCalibrationRepository {
+	return &PostgresCameraCalibrationRepository{pool: pool}
+}
+
+type referenceMarkerJSON struct {
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+type captureSettingsJSON struct {
+	Resolution     string `json:"resolution,omitempty"`
+	FrameRate      int    `json:"frame_rate,omitempty"`
+	ExposureMicros int    `json:"exposure_micros,omitempty"`
+}
+
+func (r *PostgresCameraCalibrationRepository) Save(ctx context.Context, c *domain.CameraCalibration) error {
+	homography := c.Homography()
+	homographyData, _ := json.Marshal(homography[:])
+	markers := make([]referenceMarkerJSON, 0, len(c.Markers()))
+	for _, m := range c.Markers() {
+		markers = append(markers, referenceMarkerJSON(m))
+	}
+	markersData, _ := json.Marshal(markers)
+	captureData, _ := json.Marshal(captureSettingsJSON(c.Capture()))
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO camera_calibrations (device_id, homography, markers, capture, version, calibrated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (device_id) DO UPDATE SET
+			homography = EXCLUDED.homography,
+			markers = EXCLUDED.markers,
+			capture = EXCLUDED.capture,
+			version = EXCLUDED.version,
+			calibrated_at = EXCLUDED.calibrated_at
+	`, c.DeviceID().String(), homographyData, markersData, captureData, c.Version(), c.CalibratedAt())
+
+	return err
+}
+
+func (r *PostgresCameraCalibrationRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.CameraCalibration, error) {
+	var (
+		homographyData []byte
+		markersData    []byte
+		captureData    []byte
+		version        int
+		calibratedAt   time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT homography, markers, capture, version, calibrated_at
+		FROM camera_calibrations
+		WHERE device_id = $1
+	`, deviceID.String()).Scan(&homographyData, &markersData, &captureData, &version, &calibratedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCalibrationNotFound
+		}
+		return nil, err
+	}
+
+	var values []float64
+	_ = json.Unmarshal(homographyData, &values)
+	var homography domain.Homography
+	copy(homography[:], values)
+
+	var stored []referenceMarkerJSON
+	_ = json.Unmarshal(markersData, &stored)
+	markers := make([]domain.ReferenceMarker, 0, len(stored))
+	for _, m := range stored {
+		markers = append(markers, domain.ReferenceMarker(m))
+	}
+
+	var capture captureSettingsJSON
+	_ = json.Unmarshal(captureData, &capture)
+
+	return domain.ReconstituteCameraCalibration(deviceID, homography, markers, domain.CameraSettings(capture), version, calibratedAt), nil
+}
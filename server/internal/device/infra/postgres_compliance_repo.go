@@ -0,0 +1,96 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresComplianceReportRepository implements domain.ComplianceReportRepository
+type PostgresComplianceReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresComplianceReportRepository(pool *pgxpool.Pool) *PostgresComplianceReportRepository {
+	return &PostgresComplianceReportRepository{pool: pool}
+}
+
+type deviationJSON struct {
+	Shelf    int    `json:"shelf"`
+	SKUCode  string `json:"sku_code"`
+	Kind     string `json:"kind"`
+	Expected int    `json:"expected"`
+	Found    int    `json:"found"`
+}
+
+func (r *PostgresComplianceReportRepository) Save(ctx context.Context, report *domain.ComplianceReport) error {
+	deviations := make([]deviationJSON, 0, len(report.Deviations()))
+	for _, d := range report.Deviations() {
+		deviations = append(deviations, deviationJSON{
+			Shelf:    d.Shelf,
+			SKUCode:  d.SKUCode,
+			Kind:     string(d.Kind),
+			Expected: d.Expected,
+			Found:    d.Found,
+		})
+	}
+	deviationsData, _ := json.Marshal(deviations)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO planogram_compliance_reports (id, device_id, planogram_version, visited_by, score, deviations, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`, report.ID().String(), report.DeviceID().String(), report.PlanogramVersion(), report.VisitedBy(),
+		report.Score(), deviationsData, report.CheckedAt())
+
+	return err
+}
+
+func (r *PostgresComplianceReportRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*domain.ComplianceReport, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, planogram_version, visited_by, score, deviations, checked_at
+		FROM planogram_compliance_reports
+		WHERE device_id = $1
+		ORDER BY checked_at DESC
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*domain.ComplianceReport
+	for rows.Next() {
+		var (
+			rawID          string
+			version, score int
+			visitedBy      string
+			deviationsData []byte
+			checkedAt      time.Time
+		)
+		if err := rows.Scan(&rawID, &version, &visitedBy, &score, &deviationsData, &checkedAt); err != nil {
+			return nil, err
+		}
+
+		var stored []deviationJSON
+		_ = json.Unmarshal(deviationsData, &stored)
+		deviations := make([]domain.Deviation, 0, len(stored))
+		for _, d := range stored {
+			deviations = append(deviations, domain.Deviation{
+				Shelf:    d.Shelf,
+				SKUCode:  d.SKUCode,
+				Kind:     domain.DeviationKind(d.Kind),
+				Expected: d.Expected,
+				Found:    d.Found,
+			})
+		}
+
+		id, _ := valueobjects.ComplianceReportIDFrom(rawID)
+		reports = append(reports, domain.ReconstituteComplianceReport(id, deviceID, version, visitedBy, score, deviations, checkedAt))
+	}
+	return reports, rows.Err()
+}
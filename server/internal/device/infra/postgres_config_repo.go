@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresConfigShadowRepository implements domain.ConfigShadowRepository
+type PostgresConfigShadowRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresConfigShadowRepository(pool *pgxpool.Pool) *PostgresConfigShadowRepository {
+	return &PostgresConfigShadowRepository{pool: pool}
+}
+
+type deviceConfigJSON struct {
+	ConfidenceThreshold *float64 `json:"confidence_threshold,omitempty"`
+	SyncIntervalSeconds int      `json:"sync_interval_seconds,omitempty"`
+	Resolution          string   `json:"resolution,omitempty"`
+	FrameRate           int      `json:"frame_rate,omitempty"`
+	ExposureMicros      int      `json:"exposure_micros,omitempty"`
+}
+
+func (r *PostgresConfigShadowRepository) Save(ctx context.Context, s *domain.ConfigShadow) error {
+	desired, _ := json.Marshal(toDeviceConfigJSON(s.Desired()))
+	var reported []byte
+	if cfg := s.Reported(); cfg != nil {
+		reported, _ = json.Marshal(toDeviceConfigJSON(*cfg))
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_config_shadows (device_id, desired, version, desired_by, desired_at, reported, reported_version, reported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (device_id) DO UPDATE SET
+			desired = EXCLUDED.desired,
+			version = EXCLUDED.version,
+			desired_by = EXCLUDED.desired_by,
+			desired_at = EXCLUDED.desired_at,
+			reported = EXCLUDED.reported,
+			reported_version = EXCLUDED.reported_version,
+			reported_at = EXCLUDED.reported_at
+	`, s.DeviceID().String(), desired, s.Version(), s.DesiredBy(), s.DesiredAt(), reported, s.ReportedVersion(), s.ReportedAt())
+
+	return err
+}
+
+func (r *PostgresConfigShadowRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.ConfigShadow, error) {
+	var (
+		desiredData     []byte
+		version         int
+		desiredBy       string
+		desiredAt       time.Time
+		reportedData    []byte
+		reportedVersion int
+		reportedAt      *time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT desired, version, desired_by, desired_at, reported, reported_version, reported_at
+		FROM device_config_shadows
+		WHERE device_id = $1
+	`, deviceID.String()).Scan(&desiredData, &version, &desiredBy, &desiredAt, &reportedData, &reportedVersion, &reportedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrConfigNotFound
+		}
+		return nil, err
+	}
+
+	var desired deviceConfigJSON
+	_ = json.Unmarshal(desiredData, &desired)
+	var reported *domain.DeviceConfig
+	if reportedData != nil {
+		var stored deviceConfigJSON
+		_ = json.Unmarshal(reportedData, &stored)
+		cfg := fromDeviceConfigJSON(stored)
+		reported = &cfg
+	}
+
+	return domain.ReconstituteConfigShadow(deviceID, fromDeviceConfigJSON(desired), version, desiredBy, desiredAt,
+		reported, reportedVersion, reportedAt), nil
+}
+
+func toDeviceConfigJSON(c domain.DeviceConfig) deviceConfigJSON {
+	return deviceConfigJSON{
+		ConfidenceThreshold: c.ConfidenceThreshold,
+		SyncIntervalSeconds: c.SyncIntervalSeconds,
+		Resolution:          c.Camera.Resolution,
+		FrameRate:           c.Camera.FrameRate,
+		ExposureMicros:      c.Camera.ExposureMicros,
+	}
+}
+
+func fromDeviceConfigJSON(c deviceConfigJSON) domain.DeviceConfig {
+	return domain.DeviceConfig{
+		ConfidenceThreshold: c.ConfidenceThreshold,
+		SyncIntervalSeconds: c.SyncIntervalSeconds,
+		Camera: domain.CameraSettings{
+			Resolution:     c.Resolution,
+			FrameRate:      c.FrameRate,
+			ExposureMicros: c.ExposureMicros,
+		},
+	}
+}
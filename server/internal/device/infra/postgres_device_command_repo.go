@@ -0,0 +1,123 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresDeviceCommandRepository implements domain.DeviceCommandRepository
+type PostgresDeviceCommandRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDeviceCommandRepository(pool *pgxpool.Pool) *PostgresDeviceCommandRepository {
+	return &PostgresDeviceCommandRepository{pool: pool}
+}
+
+type deviceCommandRow struct {
+	ID             string
+	DeviceID       string
+	Kind           string
+	Status         string
+	IssuedBy       string
+	IssuedAt       time.Time
+	Deliveries     int
+	DeliveredAt    *time.Time
+	AcknowledgedAt *time.Time
+	Result         string
+}
+
+const deviceCommandColumns = `id, device_id, kind, status, issued_by, issued_at, deliveries, delivered_at, acknowledged_at, result`
+
+func (r *PostgresDeviceCommandRepository) Save(ctx context.Context, c *domain.DeviceCommand) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_commands (`+deviceCommandColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			deliveries = EXCLUDED.deliveries,
+			delivered_at = EXCLUDED.delivered_at,
+			acknowledged_at = EXCLUDED.acknowledged_at,
+			result = EXCLUDED.result
+	`, c.ID().String(), c.DeviceID().String(), string(c.Kind()), string(c.Status()), c.IssuedBy(), c.IssuedAt(),
+		c.Deliveries(), c.DeliveredAt(), c.AcknowledgedAt(), c.Result())
+
+	return err
+}
+
+func (r *PostgresDeviceCommandRepository) FindByID(ctx context.Context, id valueobjects.DeviceCommandID) (*domain.DeviceCommand, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+deviceCommandColumns+` FROM device_commands WHERE id = $1`, id.String())
+
+	c, err := scanDeviceCommand(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrDeviceCommandNotFound
+	}
+	return c, err
+}
+
+func (r *PostgresDeviceCommandRepository) FindUnacknowledged(ctx context.Context, deviceID valueobjects.DeviceID) ([]*domain.DeviceCommand, error) {
+	return r.query(ctx, `
+		SELECT `+deviceCommandColumns+`
+		FROM device_commands
+		WHERE device_id = $1 AND acknowledged_at IS NULL
+		ORDER BY issued_at
+	`, deviceID.String())
+}
+
+func (r *PostgresDeviceCommandRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*domain.DeviceCommand, error) {
+	return r.query(ctx, `
+		SELECT `+deviceCommandColumns+`
+		FROM device_commands
+		WHERE device_id = $1
+		ORDER BY issued_at DESC
+		LIMIT $2
+	`, deviceID.String(), limit)
+}
+
+func (r *PostgresDeviceCommandRepository) query(ctx context.Context, sql string, args ...any) ([]*domain.DeviceCommand, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commands []*domain.DeviceCommand
+	for rows.Next() {
+		c, err := scanDeviceCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, c)
+	}
+	return commands, rows.Err()
+}
+
+func scanDeviceCommand(row pgx.Row) (*domain.DeviceCommand, error) {
+	var rec deviceCommandRow
+	if err := row.Scan(&rec.ID, &rec.DeviceID, &rec.Kind, &rec.Status, &rec.IssuedBy, &rec.IssuedAt,
+		&rec.Deliveries, &rec.DeliveredAt, &rec.AcknowledgedAt, &rec.Result); err != nil {
+		return nil, err
+	}
+
+	id, _ := valueobjects.DeviceCommandIDFrom(rec.ID)
+	devID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+	return domain.ReconstituteDeviceCommand(
+		id,
+		devID,
+		domain.CommandKind(rec.Kind),
+		domain.CommandStatus(rec.Status),
+		rec.IssuedBy,
+		rec.IssuedAt,
+		rec.Deliveries,
+		rec.DeliveredAt,
+		rec.AcknowledgedAt,
+		rec.Result,
+	), nil
+}
@@ -0,0 +1,99 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresDeviceEventRepository implements domain.DeviceEventRepository
+type PostgresDeviceEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDeviceEventRepository(pool *pgxpool.Pool) *PostgresDeviceEventRepository {
+	return &PostgresDeviceEventRepository{pool: pool}
+}
+
+type deviceEventRow struct {
+	ID         string
+	DeviceID   string
+	Kind       string
+	OccurredAt time.Time
+	ReceivedAt time.Time
+	Detail     string
+}
+
+// Append inserts the events in one transaction; the unique index on device,
+// kind and occurred_at drops the ones a retried upload repeats
+func (r *PostgresDeviceEventRepository) Append(ctx context.Context, events []*domain.DeviceEvent) ([]*domain.DeviceEvent, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var logged []*domain.DeviceEvent
+	for _, e := range events {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO device_events (id, device_id, kind, occurred_at, received_at, detail)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (device_id, kind, occurred_at) DO NOTHING
+		`, e.ID().String(), e.DeviceID().String(), string(e.Kind()), e.OccurredAt(), e.ReceivedAt(), e.Detail())
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() > 0 {
+			logged = append(logged, e)
+		}
+	}
+	return logged, tx.Commit(ctx)
+}
+
+func (r *PostgresDeviceEventRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, from, to time.Time, limit int) ([]*domain.DeviceEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, kind, occurred_at, received_at, detail
+		FROM device_events
+		WHERE device_id = $1
+			AND ($2::timestamptz IS NULL OR occurred_at >= $2)
+			AND ($3::timestamptz IS NULL OR occurred_at < $3)
+		ORDER BY occurred_at DESC
+		LIMIT $4
+	`, deviceID.String(), nullableTime(from), nullableTime(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.DeviceEvent
+	for rows.Next() {
+		var rec deviceEventRow
+		if err := rows.Scan(&rec.ID, &rec.DeviceID, &rec.Kind, &rec.OccurredAt, &rec.ReceivedAt, &rec.Detail); err != nil {
+			return nil, err
+		}
+
+		id, _ := valueobjects.DeviceEventIDFrom(rec.ID)
+		devID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+		events = append(events, domain.ReconstituteDeviceEvent(
+			id,
+			devID,
+			domain.DeviceEventKind(rec.Kind),
+			rec.OccurredAt,
+			rec.ReceivedAt,
+			rec.Detail,
+		))
+	}
+	return events, rows.Err()
+}
+
+// nullableTime maps the zero time to SQL NULL
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
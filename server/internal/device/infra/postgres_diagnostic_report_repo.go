@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresDiagnosticReportRepository implements domain.DiagnosticReportRepository
+type PostgresDiagnosticReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDiagnosticReportRepository(pool *pgxpool.Pool) *PostgresDiagnosticReportRepository {
+	return &PostgresDiagnosticReportRepository{pool: pool}
+}
+
+func (r *PostgresDiagnosticReportRepository) Save(ctx context.Context, report *domain.DiagnosticReport) error {
+	checks := report.Checks()
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO diagnostic_reports (command_id, device_id, camera_ok, scale_ok, model_loaded, model_version, notes, reported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, report.CommandID().String(), report.DeviceID().String(), checks.CameraOK, checks.ScaleOK, checks.ModelLoaded,
+		checks.ModelVersion, checks.Notes, report.ReportedAt())
+
+	return err
+}
+
+func (r *PostgresDiagnosticReportRepository) FindByCommandID(ctx context.Context, commandID valueobjects.DeviceCommandID) (*domain.DiagnosticReport, error) {
+	var (
+		rawDeviceID string
+		checks      domain.DiagnosticChecks
+		reportedAt  time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT device_id, camera_ok, scale_ok, model_loaded, model_version, notes, reported_at
+		FROM diagnostic_reports
+		WHERE command_id = $1
+	`, commandID.String()).Scan(&rawDeviceID, &checks.CameraOK, &checks.ScaleOK, &checks.ModelLoaded,
+		&checks.ModelVersion, &checks.Notes, &reportedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDiagnosticsNotFound
+		}
+		return nil, err
+	}
+
+	deviceID, _ := valueobjects.DeviceIDFrom(rawDeviceID)
+	return domain.ReconstituteDiagnosticReport(commandID, deviceID, checks, reportedAt), nil
+}
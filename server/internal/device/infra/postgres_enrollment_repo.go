@@ -0,0 +1,81 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+// PostgresEnrollmentTokenRepository implements domain.EnrollmentTokenRepository
+type PostgresEnrollmentTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresEnrollmentTokenRepository(pool *pgxpool.Pool) *PostgresEnrollmentTokenRepository {
+	return &PostgresEnrollmentTokenRepository{pool: pool}
+}
+
+type enrollmentTokenRow struct {
+	Hash       []byte
+	MachineID  string
+	Name       string
+	Location   string
+	CreatedBy  string
+	Override   bool
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RedeemedAt *time.Time
+}
+
+func (r *PostgresEnrollmentTokenRepository) Save(ctx context.Context, t *domain.EnrollmentToken) error {
+	// A token is only ever redeemed once: the update is skipped for a token
+	// redeemed in the meantime
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO enrollment_tokens (token_hash, machine_id, name, location, created_by, override, created_at, expires_at, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (token_hash) DO UPDATE SET
+			redeemed_at = EXCLUDED.redeemed_at
+		WHERE enrollment_tokens.redeemed_at IS NULL
+	`, t.Hash(), t.MachineID(), t.Name(), t.Location(), t.CreatedBy(), t.Override(),
+		t.CreatedAt(), t.ExpiresAt(), t.RedeemedAt())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrEnrollmentTokenRedeemed
+	}
+	return nil
+}
+
+func (r *PostgresEnrollmentTokenRepository) FindByHash(ctx context.Context, hash []byte) (*domain.EnrollmentToken, error) {
+	var rec enrollmentTokenRow
+	err := r.pool.QueryRow(ctx, `
+		SELECT token_hash, machine_id, name, location, created_by, override, created_at, expires_at, redeemed_at
+		FROM enrollment_tokens
+		WHERE token_hash = $1
+	`, hash).Scan(&rec.Hash, &rec.MachineID, &rec.Name, &rec.Location, &rec.CreatedBy, &rec.Override,
+		&rec.CreatedAt, &rec.ExpiresAt, &rec.RedeemedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEnrollmentTokenNotFound
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteEnrollmentToken(
+		rec.Hash,
+		rec.MachineID,
+		rec.Name,
+		rec.Location,
+		rec.CreatedBy,
+		rec.Override,
+		rec.CreatedAt,
+		rec.ExpiresAt,
+		rec.RedeemedAt,
+	), nil
+}
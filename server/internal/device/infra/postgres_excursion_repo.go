@@ -0,0 +1,126 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresExcursionRepository implements domain.ExcursionRepository
+type PostgresExcursionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresExcursionRepository(pool *pgxpool.Pool) *PostgresExcursionRepository {
+	return &PostgresExcursionRepository{pool: pool}
+}
+
+type excursionRow struct {
+	ID            string
+	DeviceID      string
+	StartedAt     time.Time
+	DetectedAt    time.Time
+	LimitCelsius  float64
+	PeakCelsius   float64
+	ClearedAt     *time.Time
+	ClearedBy     string
+	ClearanceNote string
+}
+
+const excursionColumns = `id, device_id, started_at, detected_at, limit_celsius, peak_celsius, cleared_at, cleared_by, clearance_note`
+
+func (r *PostgresExcursionRepository) Save(ctx context.Context, e *domain.TemperatureExcursion) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO temperature_excursions (`+excursionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			peak_celsius = EXCLUDED.peak_celsius,
+			cleared_at = EXCLUDED.cleared_at,
+			cleared_by = EXCLUDED.cleared_by,
+			clearance_note = EXCLUDED.clearance_note
+	`, e.ID().String(), e.DeviceID().String(), e.StartedAt(), e.DetectedAt(),
+		e.LimitCelsius(), e.PeakCelsius(), e.ClearedAt(), e.ClearedBy(), e.Note())
+
+	return err
+}
+
+func (r *PostgresExcursionRepository) FindOpenByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.TemperatureExcursion, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+excursionColumns+`
+		FROM temperature_excursions
+		WHERE device_id = $1 AND cleared_at IS NULL AND archived_at IS NULL
+		ORDER BY detected_at DESC
+		LIMIT 1
+	`, deviceID.String())
+
+	return r.scanExcursion(row)
+}
+
+func (r *PostgresExcursionRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*domain.TemperatureExcursion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+excursionColumns+`
+		FROM temperature_excursions
+		WHERE device_id = $1 AND archived_at IS NULL
+		ORDER BY detected_at DESC
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var excursions []*domain.TemperatureExcursion
+	for rows.Next() {
+		e, err := r.scanExcursion(rows)
+		if err != nil {
+			return nil, err
+		}
+		excursions = append(excursions, e)
+	}
+	return excursions, rows.Err()
+}
+
+func (r *PostgresExcursionRepository) ArchiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, at time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE temperature_excursions SET archived_at = $2
+		WHERE device_id = $1 AND archived_at IS NULL
+	`, deviceID.String(), at)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *PostgresExcursionRepository) scanExcursion(row pgx.Row) (*domain.TemperatureExcursion, error) {
+	var rec excursionRow
+	err := row.Scan(
+		&rec.ID, &rec.DeviceID, &rec.StartedAt, &rec.DetectedAt,
+		&rec.LimitCelsius, &rec.PeakCelsius, &rec.ClearedAt, &rec.ClearedBy, &rec.ClearanceNote,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExcursionNotFound
+		}
+		return nil, err
+	}
+
+	id, _ := valueobjects.ExcursionIDFrom(rec.ID)
+	deviceID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+
+	return domain.ReconstituteExcursion(
+		id,
+		deviceID,
+		rec.StartedAt,
+		rec.DetectedAt,
+		rec.LimitCelsius,
+		rec.PeakCelsius,
+		rec.ClearedAt,
+		rec.ClearedBy,
+		rec.ClearanceNote,
+	), nil
+}
@@ -0,0 +1,53 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/app"
+)
+
+// PostgresFleetHealthReader implements app.FleetHealthReader with one query.
+// It reads the sessions table of the transaction context alongside the
+// devices, so the dashboard costs a single round trip.
+type PostgresFleetHealthReader struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresFleetHealthReader(pool *pgxpool.Pool) *PostgresFleetHealthReader {
+	return &PostgresFleetHealthReader{pool: pool}
+}
+
+func (r *PostgresFleetHealthReader) FleetHealth(ctx context.Context, offlineSince time.Time) (app.FleetHealth, error) {
+	health := app.FleetHealth{OfflineSince: offlineSince}
+	var byStatus []byte
+	err := r.pool.QueryRow(ctx, `
+		WITH fleet AS (
+			SELECT d.machine_id, COALESCE(d.status, 'active') AS status, d.last_seen_at, d.reported_model_version,
+				COALESCE(NULLIF(d.model_version, ''), g.model_version, '') AS pinned_model_version
+			FROM devices d LEFT JOIN device_groups g ON g.id = d.group_id
+		), open_sessions AS (
+			SELECT COUNT(*) AS open, COUNT(*) FILTER (WHERE weight_mismatch) AS mismatched
+			FROM sessions WHERE status = 'active' AND expires_at > NOW()
+		)
+		SELECT
+			COALESCE((SELECT jsonb_object_agg(status, n) FROM (SELECT status, COUNT(*) AS n FROM fleet GROUP BY status) s), '{}'),
+			COALESCE((SELECT array_agg(machine_id::TEXT ORDER BY last_seen_at NULLS FIRST, machine_id) FROM fleet
+				WHERE status <> 'decommissioned' AND (last_seen_at IS NULL OR last_seen_at < $1)), '{}'),
+			COALESCE((SELECT array_agg(machine_id::TEXT ORDER BY machine_id) FROM fleet
+				WHERE status <> 'decommissioned' AND pinned_model_version <> ''
+					AND reported_model_version <> '' AND reported_model_version <> pinned_model_version), '{}'),
+			o.open, o.mismatched
+		FROM open_sessions o
+	`, offlineSince).Scan(&byStatus, &health.Offline, &health.StaleModels, &health.OpenSessions, &health.WeightMismatches)
+	if err != nil {
+		return app.FleetHealth{}, err
+	}
+
+	health.DevicesByStatus = map[string]int{}
+	_ = json.Unmarshal(byStatus, &health.DevicesByStatus)
+	return health, nil
+}
@@ -0,0 +1,124 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresDeviceGroupRepository implements domain.DeviceGroupRepository
+type PostgresDeviceGroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDeviceGroupRepository(pool *pgxpool.Pool) *PostgresDeviceGroupRepository {
+	return &PostgresDeviceGroupRepository{pool: pool}
+}
+
+const groupColumns = `id, name, confidence_threshold, model_version, session_expiration_minutes, planogram, planogram_version, created_at, updated_at`
+
+type groupRow struct {
+	ID                       string
+	Name                     string
+	ConfidenceThreshold      *float64
+	ModelVersion             string
+	SessionExpirationMinutes *int
+	Planogram                []byte
+	PlanogramVersion         int
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
+}
+
+func (r *PostgresDeviceGroupRepository) Save(ctx context.Context, g *domain.DeviceGroup) error {
+	var planogram []byte
+	if facings := g.Planogram(); len(facings) > 0 {
+		stored := make([]facingJSON, 0, len(facings))
+		for _, f := range facings {
+			stored = append(stored, facingJSON{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+		}
+		planogram, _ = json.Marshal(stored)
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_groups (`+groupColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			confidence_threshold = EXCLUDED.confidence_threshold,
+			model_version = EXCLUDED.model_version,
+			session_expiration_minutes = EXCLUDED.session_expiration_minutes,
+			planogram = EXCLUDED.planogram,
+			planogram_version = EXCLUDED.planogram_version,
+			updated_at = EXCLUDED.updated_at
+	`, g.ID().String(), g.Name(), g.Policy().ConfidenceThreshold, g.Policy().ModelVersion, g.Policy().SessionExpirationMinutes,
+		planogram, g.PlanogramVersion(), g.CreatedAt(), g.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresDeviceGroupRepository) FindByID(ctx context.Context, id valueobjects.DeviceGroupID) (*domain.DeviceGroup, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+groupColumns+` FROM device_groups WHERE id = $1`, id.String())
+	return r.scanGroup(row)
+}
+
+// FindAll lists every group by name
+func (r *PostgresDeviceGroupRepository) FindAll(ctx context.Context) ([]*domain.DeviceGroup, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+groupColumns+` FROM device_groups ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*domain.DeviceGroup{}
+	for rows.Next() {
+		g, err := r.scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func (r *PostgresDeviceGroupRepository) scanGroup(row pgx.Row) (*domain.DeviceGroup, error) {
+	var rec groupRow
+	err := row.Scan(&rec.ID, &rec.Name, &rec.ConfidenceThreshold, &rec.ModelVersion, &rec.SessionExpirationMinutes,
+		&rec.Planogram, &rec.PlanogramVersion, &rec.CreatedAt, &rec.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceGroupNotFound
+		}
+		return nil, err
+	}
+
+	var planogram []domain.PlanogramFacing
+	if len(rec.Planogram) > 0 {
+		var stored []facingJSON
+		_ = json.Unmarshal(rec.Planogram, &stored)
+		for _, f := range stored {
+			planogram = append(planogram, domain.PlanogramFacing{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+		}
+	}
+
+	id, _ := valueobjects.DeviceGroupIDFrom(rec.ID)
+	return domain.ReconstituteDeviceGroup(
+		id,
+		rec.Name,
+		domain.DevicePolicy{
+			ConfidenceThreshold:      rec.ConfidenceThreshold,
+			ModelVersion:             rec.ModelVersion,
+			SessionExpirationMinutes: rec.SessionExpirationMinutes,
+		},
+		planogram,
+		rec.PlanogramVersion,
+		rec.CreatedAt,
+		rec.UpdatedAt,
+	), nil
+}
@@ -0,0 +1,81 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresIncidentRepository implements domain.IncidentRepository
+type PostgresIncidentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresIncidentRepository(pool *pgxpool.Pool) *PostgresIncidentRepository {
+	return &PostgresIncidentRepository{pool: pool}
+}
+
+type incidentRow struct {
+	ID           string
+	DeviceID     string
+	Kind         string
+	DoorOpenedAt time.Time
+	DetectedAt   time.Time
+}
+
+func (r *PostgresIncidentRepository) Save(ctx context.Context, i *domain.SecurityIncident) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO security_incidents (id, device_id, kind, door_opened_at, detected_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO NOTHING
+	`, i.ID().String(), i.DeviceID().String(), string(i.Kind()), i.DoorOpenedAt(), i.DetectedAt())
+
+	return err
+}
+
+func (r *PostgresIncidentRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) ([]*domain.SecurityIncident, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, kind, door_opened_at, detected_at
+		FROM security_incidents
+		WHERE device_id = $1 AND archived_at IS NULL
+		ORDER BY detected_at DESC
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []*domain.SecurityIncident
+	for rows.Next() {
+		var rec incidentRow
+		if err := rows.Scan(&rec.ID, &rec.DeviceID, &rec.Kind, &rec.DoorOpenedAt, &rec.DetectedAt); err != nil {
+			return nil, err
+		}
+
+		id, _ := valueobjects.IncidentIDFrom(rec.ID)
+		devID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+		incidents = append(incidents, domain.ReconstituteIncident(
+			id,
+			devID,
+			domain.IncidentKind(rec.Kind),
+			rec.DoorOpenedAt,
+			rec.DetectedAt,
+		))
+	}
+	return incidents, rows.Err()
+}
+
+func (r *PostgresIncidentRepository) ArchiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, at time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE security_incidents SET archived_at = $2
+		WHERE device_id = $1 AND archived_at IS NULL
+	`, deviceID.String(), at)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
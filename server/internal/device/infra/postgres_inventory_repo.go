@@ -0,0 +1,114 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresInventoryRepository implements domain.InventoryRepository
+type PostgresInventoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresInventoryRepository(pool *pgxpool.Pool) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{pool: pool}
+}
+
+func (r *PostgresInventoryRepository) Save(ctx context.Context, inv *domain.Inventory) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := saveInventoryLevels(ctx, tx, inv); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInventoryRepository) SaveSale(ctx context.Context, inv *domain.Inventory, sessionID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO device_inventory_sales (session_id, device_id, recorded_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO NOTHING
+	`, sessionID, inv.DeviceID().String(), inv.UpdatedAt())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSaleAlreadyRecorded
+	}
+
+	if err := saveInventoryLevels(ctx, tx, inv); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInventoryRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.Inventory, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT sku_code, quantity, updated_at
+		FROM device_inventory
+		WHERE device_id = $1
+	`, deviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[string]int)
+	var updatedAt time.Time
+	for rows.Next() {
+		var (
+			code     string
+			quantity int
+			at       time.Time
+		)
+		if err := rows.Scan(&code, &quantity, &at); err != nil {
+			return nil, err
+		}
+		levels[code] = quantity
+		if at.After(updatedAt) {
+			updatedAt = at
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, domain.ErrInventoryNotFound
+	}
+
+	return domain.ReconstituteInventory(deviceID, levels, updatedAt), nil
+}
+
+// saveInventoryLevels upserts one row per SKU; an inventory never forgets a SKU
+// it was stocked with, so there is nothing to delete
+func saveInventoryLevels(ctx context.Context, tx pgx.Tx, inv *domain.Inventory) error {
+	for code, quantity := range inv.Levels() {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO device_inventory (device_id, sku_code, quantity, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (device_id, sku_code) DO UPDATE SET
+				quantity = EXCLUDED.quantity,
+				updated_at = EXCLUDED.updated_at
+		`, inv.DeviceID().String(), code, quantity, inv.UpdatedAt())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
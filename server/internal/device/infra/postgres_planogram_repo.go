@@ -0,0 +1,76 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresPlanogramRepository implements domain.PlanogramRepository
+type PostgresPlanogramRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPlanogramRepository(pool *pgxpool.Pool) *PostgresPlanogramRepository {
+	return &PostgresPlanogramRepository{pool: pool}
+}
+
+type facingJSON struct {
+	Shelf   int    `json:"shelf"`
+	SKUCode string `json:"sku_code"`
+	Facings int    `json:"facings"`
+}
+
+func (r *PostgresPlanogramRepository) Save(ctx context.Context, p *domain.Planogram) error {
+	facings := make([]facingJSON, 0, len(p.Facings()))
+	for _, f := range p.Facings() {
+		facings = append(facings, facingJSON{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+	}
+	facingsData, _ := json.Marshal(facings)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_planograms (device_id, version, facings, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (device_id) DO UPDATE SET
+			version = EXCLUDED.version,
+			facings = EXCLUDED.facings,
+			updated_at = EXCLUDED.updated_at
+	`, p.DeviceID().String(), p.Version(), facingsData, p.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresPlanogramRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.Planogram, error) {
+	var (
+		version     int
+		facingsData []byte
+		updatedAt   time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT version, facings, updated_at
+		FROM device_planograms
+		WHERE device_id = $1
+	`, deviceID.String()).Scan(&version, &facingsData, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPlanogramNotFound
+		}
+		return nil, err
+	}
+
+	var rows []facingJSON
+	_ = json.Unmarshal(facingsData, &rows)
+	facings := make([]domain.PlanogramFacing, 0, len(rows))
+	for _, f := range rows {
+		facings = append(facings, domain.PlanogramFacing{Shelf: f.Shelf, SKUCode: f.SKUCode, Facings: f.Facings})
+	}
+
+	return domain.ReconstitutePlanogram(deviceID, version, facings, updatedAt), nil
+}
@@ -0,0 +1,89 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresReleaseRepository implements domain.ReleaseRepository
+type PostgresReleaseRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresReleaseRepository(pool *pgxpool.Pool) *PostgresReleaseRepository {
+	return &PostgresReleaseRepository{pool: pool}
+}
+
+const releaseColumns = `id, kind, version, object_key, size_bytes, checksum, notes, uploaded_by, created_at`
+
+func (r *PostgresReleaseRepository) Save(ctx context.Context, release domain.Release) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO releases (`+releaseColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, release.ID.String(), string(release.Kind), release.Version, release.ObjectKey, release.SizeBytes,
+		release.Checksum, release.Notes, release.UploadedBy, release.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrDuplicateRelease
+	}
+	return err
+}
+
+func (r *PostgresReleaseRepository) FindByID(ctx context.Context, id valueobjects.ReleaseID) (domain.Release, error) {
+	release, err := scanRelease(r.pool.QueryRow(ctx, `
+		SELECT `+releaseColumns+` FROM releases WHERE id = $1
+	`, id.String()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Release{}, domain.ErrReleaseNotFound
+	}
+	return release, err
+}
+
+func (r *PostgresReleaseRepository) FindAll(ctx context.Context, kind domain.ReleaseKind) ([]domain.Release, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+releaseColumns+` FROM releases
+		WHERE $1 = '' OR kind = $1
+		ORDER BY created_at DESC
+	`, string(kind))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	releases := []domain.Release{}
+	for rows.Next() {
+		release, err := scanRelease(rows)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releases, rows.Err()
+}
+
+func scanRelease(row pgx.Row) (domain.Release, error) {
+	var (
+		release domain.Release
+		id      string
+		kind    string
+	)
+	if err := row.Scan(&id, &kind, &release.Version, &release.ObjectKey, &release.SizeBytes,
+		&release.Checksum, &release.Notes, &release.UploadedBy, &release.CreatedAt); err != nil {
+		return domain.Release{}, err
+	}
+	releaseID, err := valueobjects.ReleaseIDFrom(id)
+	if err != nil {
+		return domain.Release{}, err
+	}
+	release.ID = releaseID
+	release.Kind = domain.ReleaseKind(kind)
+	return release, nil
+}
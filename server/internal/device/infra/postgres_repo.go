@@ -2,6 +2,7 @@ package infra
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -21,14 +22,43 @@ func NewPostgresDeviceRepository(pool *pgxpool.Pool) *PostgresDeviceRepository {
 	return &PostgresDeviceRepository{pool: pool}
 }
 
+const deviceColumns = `id, machine_id, name, location, region, status, over_temp_since,
+	door_open_since, door_alarm_raised, verification_required_since,
+	last_seen_at, firmware_version, app_version, reported_model_version, key_hash, decommissioned_at, decommissioned_by,
+	maintenance_since, maintenance_by, maintenance_reason, offline_since, restock_session_id,
+	group_id, confidence_threshold, model_version, session_expiration_minutes, tags, metadata, created_at, updated_at`
+
 type deviceRow struct {
-	ID        string
-	MachineID string
-	Name      *string
-	Location  *string
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                        string
+	MachineID                 string
+	Name                      *string
+	Location                  *string
+	Region                    string
+	Status                    string
+	OverTempSince             *time.Time
+	DoorOpenSince             *time.Time
+	DoorAlarmRaised           bool
+	VerificationRequiredSince *time.Time
+	LastSeenAt                *time.Time
+	FirmwareVersion           string
+	AppVersion                string
+	ReportedModelVersion      string
+	KeyHash                   []byte
+	DecommissionedAt          *time.Time
+	DecommissionedBy          string
+	MaintenanceSince          *time.Time
+	MaintenanceBy             string
+	MaintenanceReason         string
+	OfflineSince              *time.Time
+	RestockSessionID          *string
+	GroupID                   *string
+	ConfidenceThreshold       *float64
+	ModelVersion              string
+	SessionExpirationMinutes  *int
+	Tags                      []byte
+	Metadata                  []byte
+	CreatedAt                 time.Time
+	UpdatedAt                 time.Time
 }
 
 func (r *PostgresDeviceRepository) Save(ctx context.Context, d *domain.Device) error {
@@ -42,22 +72,85 @@ func (r *PostgresDeviceRepository) Save(ctx context.Context, d *domain.Device) e
 		location = &l
 	}
 
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO devices (id, machine_id, name, location, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	var decommissionedAt *time.Time
+	var decommissionedBy string
+	if dc := d.Decommissioned(); dc != nil {
+		decommissionedAt, decommissionedBy = &dc.At, dc.By
+	}
+
+	var maintenanceSince *time.Time
+	var maintenanceBy, maintenanceReason string
+	if m := d.Maintenance(); m != nil {
+		maintenanceSince, maintenanceBy, maintenanceReason = &m.Since, m.By, m.Reason
+	}
+
+	var restockSessionID *string
+	if rs := d.RestockSessionID(); rs != nil {
+		id := rs.String()
+		restockSessionID = &id
+	}
+
+	var groupID *string
+	if g := d.GroupID(); g != nil {
+		id := g.String()
+		groupID = &id
+	}
+
+	tags, err := json.Marshal(d.Labels().Tags())
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(d.Labels().Metadata())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO devices (id, machine_id, name, location, region, status, over_temp_since,
+			door_open_since, door_alarm_raised, verification_required_since,
+			last_seen_at, firmware_version, app_version, reported_model_version, key_hash, decommissioned_at, decommissioned_by,
+			maintenance_since, maintenance_by, maintenance_reason, offline_since, restock_session_id,
+			group_id, confidence_threshold, model_version, session_expiration_minutes, tags, metadata, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			location = EXCLUDED.location,
 			status = EXCLUDED.status,
+			over_temp_since = EXCLUDED.over_temp_since,
+			door_open_since = EXCLUDED.door_open_since,
+			door_alarm_raised = EXCLUDED.door_alarm_raised,
+			verification_required_since = EXCLUDED.verification_required_since,
+			last_seen_at = EXCLUDED.last_seen_at,
+			firmware_version = EXCLUDED.firmware_version,
+			app_version = EXCLUDED.app_version,
+			reported_model_version = EXCLUDED.reported_model_version,
+			key_hash = EXCLUDED.key_hash,
+			decommissioned_at = EXCLUDED.decommissioned_at,
+			decommissioned_by = EXCLUDED.decommissioned_by,
+			maintenance_since = EXCLUDED.maintenance_since,
+			maintenance_by = EXCLUDED.maintenance_by,
+			maintenance_reason = EXCLUDED.maintenance_reason,
+			offline_since = EXCLUDED.offline_since,
+			restock_session_id = EXCLUDED.restock_session_id,
+			group_id = EXCLUDED.group_id,
+			confidence_threshold = EXCLUDED.confidence_threshold,
+			model_version = EXCLUDED.model_version,
+			session_expiration_minutes = EXCLUDED.session_expiration_minutes,
+			tags = EXCLUDED.tags,
+			metadata = EXCLUDED.metadata,
 			updated_at = EXCLUDED.updated_at
-	`, d.ID().String(), d.MachineID(), name, location, string(d.Status()), d.CreatedAt(), d.UpdatedAt())
+	`, d.ID().String(), d.MachineID(), name, location, d.Region(), string(d.Status()), d.OverTempSince(),
+		d.Door().OpenSince, d.Door().AlarmRaised, d.VerificationRequiredSince(),
+		d.Liveness().LastSeenAt, d.Liveness().FirmwareVersion, d.Liveness().AppVersion, d.Liveness().ModelVersion, d.KeyHash(),
+		decommissionedAt, decommissionedBy, maintenanceSince, maintenanceBy, maintenanceReason, d.Liveness().OfflineSince, restockSessionID, groupID, d.PolicyOverrides().ConfidenceThreshold, d.PolicyOverrides().ModelVersion,
+		d.PolicyOverrides().SessionExpirationMinutes, tags, metadata, d.CreatedAt(), d.UpdatedAt())
 
 	return err
 }
 
 func (r *PostgresDeviceRepository) FindByID(ctx context.Context, id valueobjects.DeviceID) (*domain.Device, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, machine_id, name, location, status, created_at, updated_at
+		SELECT `+deviceColumns+`
 		FROM devices WHERE id = $1
 	`, id.String())
 
@@ -66,18 +159,57 @@ func (r *PostgresDeviceRepository) FindByID(ctx context.Context, id valueobjects
 
 func (r *PostgresDeviceRepository) FindByMachineID(ctx context.Context, machineID string) (*domain.Device, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, machine_id, name, location, status, created_at, updated_at
+		SELECT `+deviceColumns+`
 		FROM devices WHERE machine_id = $1
 	`, machineID)
 
 	return r.scanDevice(row)
 }
 
+// FindAll lists every device by machine ID
+func (r *PostgresDeviceRepository) FindAll(ctx context.Context) ([]*domain.Device, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+deviceColumns+` FROM devices ORDER BY machine_id`)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanDevices(rows)
+}
+
+// FindByGroupID lists the devices in the group by machine ID
+func (r *PostgresDeviceRepository) FindByGroupID(ctx context.Context, groupID valueobjects.DeviceGroupID) ([]*domain.Device, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deviceColumns+`
+		FROM devices WHERE group_id = $1 ORDER BY machine_id
+	`, groupID.String())
+	if err != nil {
+		return nil, err
+	}
+	return r.scanDevices(rows)
+}
+
+func (r *PostgresDeviceRepository) scanDevices(rows pgx.Rows) ([]*domain.Device, error) {
+	defer rows.Close()
+
+	devices := []*domain.Device{}
+	for rows.Next() {
+		d, err := r.scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
 func (r *PostgresDeviceRepository) scanDevice(row pgx.Row) (*domain.Device, error) {
 	var rec deviceRow
 	err := row.Scan(
-		&rec.ID, &rec.MachineID, &rec.Name, &rec.Location,
-		&rec.Status, &rec.CreatedAt, &rec.UpdatedAt,
+		&rec.ID, &rec.MachineID, &rec.Name, &rec.Location, &rec.Region,
+		&rec.Status, &rec.OverTempSince, &rec.DoorOpenSince, &rec.DoorAlarmRaised,
+		&rec.VerificationRequiredSince, &rec.LastSeenAt, &rec.FirmwareVersion, &rec.AppVersion, &rec.ReportedModelVersion,
+		&rec.KeyHash, &rec.DecommissionedAt, &rec.DecommissionedBy,
+		&rec.MaintenanceSince, &rec.MaintenanceBy, &rec.MaintenanceReason, &rec.OfflineSince, &rec.RestockSessionID,
+		&rec.GroupID, &rec.ConfidenceThreshold, &rec.ModelVersion, &rec.SessionExpirationMinutes, &rec.Tags, &rec.Metadata, &rec.CreatedAt, &rec.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -86,10 +218,10 @@ func (r *PostgresDeviceRepository) scanDevice(row pgx.Row) (*domain.Device, erro
 		return nil, err
 	}
 
-	return r.reconstitute(rec), nil
+	return r.reconstitute(rec)
 }
 
-func (r *PostgresDeviceRepository) reconstitute(rec deviceRow) *domain.Device {
+func (r *PostgresDeviceRepository) reconstitute(rec deviceRow) (*domain.Device, error) {
 	id, _ := valueobjects.DeviceIDFrom(rec.ID)
 
 	name := ""
@@ -101,13 +233,68 @@ func (r *PostgresDeviceRepository) reconstitute(rec deviceRow) *domain.Device {
 		location = *rec.Location
 	}
 
+	var decommissioned *domain.Decommissioning
+	if rec.DecommissionedAt != nil {
+		decommissioned = &domain.Decommissioning{At: *rec.DecommissionedAt, By: rec.DecommissionedBy}
+	}
+
+	var maintenance *domain.Maintenance
+	if rec.MaintenanceSince != nil {
+		maintenance = &domain.Maintenance{Since: *rec.MaintenanceSince, By: rec.MaintenanceBy, Reason: rec.MaintenanceReason}
+	}
+
+	var restockSessionID *valueobjects.RestockSessionID
+	if rec.RestockSessionID != nil {
+		if rs, err := valueobjects.RestockSessionIDFrom(*rec.RestockSessionID); err == nil {
+			restockSessionID = &rs
+		}
+	}
+
+	var groupID *valueobjects.DeviceGroupID
+	if rec.GroupID != nil {
+		if g, err := valueobjects.DeviceGroupIDFrom(*rec.GroupID); err == nil {
+			groupID = &g
+		}
+	}
+
+	var tags []string
+	if err := json.Unmarshal(rec.Tags, &tags); err != nil {
+		return nil, err
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(rec.Metadata, &metadata); err != nil {
+		return nil, err
+	}
+
 	return domain.Reconstitute(
 		id,
 		rec.MachineID,
 		name,
 		location,
+		rec.Region,
 		domain.DeviceStatus(rec.Status),
+		rec.OverTempSince,
+		domain.DoorState{OpenSince: rec.DoorOpenSince, AlarmRaised: rec.DoorAlarmRaised},
+		rec.VerificationRequiredSince,
+		domain.Liveness{
+			LastSeenAt:      rec.LastSeenAt,
+			OfflineSince:    rec.OfflineSince,
+			FirmwareVersion: rec.FirmwareVersion,
+			AppVersion:      rec.AppVersion,
+			ModelVersion:    rec.ReportedModelVersion,
+		},
+		rec.KeyHash,
+		decommissioned,
+		maintenance,
+		restockSessionID,
+		groupID,
+		domain.DevicePolicy{
+			ConfidenceThreshold:      rec.ConfidenceThreshold,
+			ModelVersion:             rec.ModelVersion,
+			SessionExpirationMinutes: rec.SessionExpirationMinutes,
+		},
+		domain.ReconstituteDeviceLabels(tags, metadata),
 		rec.CreatedAt,
 		rec.UpdatedAt,
-	)
+	), nil
 }
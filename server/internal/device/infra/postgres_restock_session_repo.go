@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresRestockSessionRepository implements domain.RestockSessionRepository
+type PostgresRestockSessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRestockSessionRepository(pool *pgxpool.Pool) *PostgresRestockSessionRepository {
+	return &PostgresRestockSessionRepository{pool: pool}
+}
+
+type restockSessionRow struct {
+	ID          string
+	DeviceID    string
+	Status      string
+	StartedBy   string
+	StartedAt   time.Time
+	Units       []byte
+	LevelsAfter []byte
+	ClosedBy    string
+	ClosedAt    *time.Time
+}
+
+const restockSessionColumns = `id, device_id, status, started_by, started_at, units, levels_after, closed_by, closed_at`
+
+func (r *PostgresRestockSessionRepository) Save(ctx context.Context, s *domain.RestockSession) error {
+	units, err := json.Marshal(s.Units())
+	if err != nil {
+		return err
+	}
+	var levelsAfter []byte
+	if levels := s.LevelsAfter(); levels != nil {
+		if levelsAfter, err = json.Marshal(levels); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO restock_sessions (`+restockSessionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			units = EXCLUDED.units,
+			levels_after = EXCLUDED.levels_after,
+			closed_by = EXCLUDED.closed_by,
+			closed_at = EXCLUDED.closed_at
+	`, s.ID().String(), s.DeviceID().String(), string(s.Status()), s.StartedBy(), s.StartedAt(),
+		units, levelsAfter, s.ClosedBy(), s.ClosedAt())
+
+	return err
+}
+
+func (r *PostgresRestockSessionRepository) FindByID(ctx context.Context, id valueobjects.RestockSessionID) (*domain.RestockSession, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+restockSessionColumns+` FROM restock_sessions WHERE id = $1`, id.String())
+
+	s, err := scanRestockSession(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRestockSessionNotFound
+	}
+	return s, err
+}
+
+func (r *PostgresRestockSessionRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*domain.RestockSession, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+restockSessionColumns+`
+		FROM restock_sessions
+		WHERE device_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, deviceID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.RestockSession
+	for rows.Next() {
+		s, err := scanRestockSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func scanRestockSession(row pgx.Row) (*domain.RestockSession, error) {
+	var rec restockSessionRow
+	if err := row.Scan(&rec.ID, &rec.DeviceID, &rec.Status, &rec.StartedBy, &rec.StartedAt,
+		&rec.Units, &rec.LevelsAfter, &rec.ClosedBy, &rec.ClosedAt); err != nil {
+		return nil, err
+	}
+
+	var units, levelsAfter map[string]int
+	if err := json.Unmarshal(rec.Units, &units); err != nil {
+		return nil, err
+	}
+	if rec.LevelsAfter != nil {
+		if err := json.Unmarshal(rec.LevelsAfter, &levelsAfter); err != nil {
+			return nil, err
+		}
+	}
+
+	id, _ := valueobjects.RestockSessionIDFrom(rec.ID)
+	devID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+	return domain.ReconstituteRestockSession(
+		id,
+		devID,
+		domain.RestockSessionStatus(rec.Status),
+		rec.StartedBy,
+		rec.StartedAt,
+		units,
+		levelsAfter,
+		rec.ClosedBy,
+		rec.ClosedAt,
+	), nil
+}
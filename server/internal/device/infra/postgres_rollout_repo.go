@@ -0,0 +1,198 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresRolloutRepository implements domain.RolloutRepository
+type PostgresRolloutRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRolloutRepository(pool *pgxpool.Pool) *PostgresRolloutRepository {
+	return &PostgresRolloutRepository{pool: pool}
+}
+
+const rolloutColumns = `id, release_id, kind, group_ids::text[], stages, stage, status, created_by, aborted_by, abort_reason, created_at, updated_at`
+
+func (r *PostgresRolloutRepository) Save(ctx context.Context, rollout *domain.Rollout) error {
+	groupIDs := make([]string, 0, len(rollout.GroupIDs()))
+	for _, id := range rollout.GroupIDs() {
+		groupIDs = append(groupIDs, id.String())
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO rollouts (id, release_id, kind, group_ids, stages, stage, status, created_by, aborted_by, abort_reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::uuid[], $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE SET
+			stage = EXCLUDED.stage,
+			status = EXCLUDED.status,
+			aborted_by = EXCLUDED.aborted_by,
+			abort_reason = EXCLUDED.abort_reason,
+			updated_at = EXCLUDED.updated_at
+	`, rollout.ID().String(), rollout.ReleaseID().String(), string(rollout.Kind()), groupIDs, rollout.Stages(),
+		rollout.Stage(), string(rollout.Status()), rollout.CreatedBy(), rollout.AbortedBy(), rollout.AbortReason(),
+		rollout.CreatedAt(), rollout.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresRolloutRepository) FindByID(ctx context.Context, id valueobjects.RolloutID) (*domain.Rollout, error) {
+	rollout, err := scanRollout(r.pool.QueryRow(ctx, `
+		SELECT `+rolloutColumns+` FROM rollouts WHERE id = $1
+	`, id.String()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRolloutNotFound
+	}
+	return rollout, err
+}
+
+func (r *PostgresRolloutRepository) FindAll(ctx context.Context) ([]*domain.Rollout, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+rolloutColumns+` FROM rollouts ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanRollouts(rows)
+}
+
+func (r *PostgresRolloutRepository) FindByGroupID(ctx context.Context, groupID valueobjects.DeviceGroupID) ([]*domain.Rollout, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+rolloutColumns+` FROM rollouts
+		WHERE group_ids @> ARRAY[$1::uuid]
+		ORDER BY created_at DESC
+	`, groupID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanRollouts(rows)
+}
+
+func (r *PostgresRolloutRepository) SaveDeviceUpdate(ctx context.Context, u domain.DeviceUpdate) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO rollout_devices (rollout_id, device_id, status, detail, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rollout_id, device_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			detail = EXCLUDED.detail,
+			updated_at = EXCLUDED.updated_at
+	`, u.RolloutID.String(), u.DeviceID.String(), string(u.Status), u.Detail, u.UpdatedAt)
+
+	return err
+}
+
+func (r *PostgresRolloutRepository) FindDeviceUpdate(ctx context.Context, rolloutID valueobjects.RolloutID, deviceID valueobjects.DeviceID) (domain.DeviceUpdate, error) {
+	var (
+		status    string
+		detail    string
+		updatedAt time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT status, detail, updated_at FROM rollout_devices
+		WHERE rollout_id = $1 AND device_id = $2
+	`, rolloutID.String(), deviceID.String()).Scan(&status, &detail, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DeviceUpdate{}, domain.ErrDeviceUpdateNotFound
+		}
+		return domain.DeviceUpdate{}, err
+	}
+
+	return domain.DeviceUpdate{
+		RolloutID: rolloutID,
+		DeviceID:  deviceID,
+		Status:    domain.UpdateStatus(status),
+		Detail:    detail,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+func (r *PostgresRolloutRepository) FindDeviceUpdates(ctx context.Context, rolloutID valueobjects.RolloutID) ([]domain.DeviceUpdate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT device_id, status, detail, updated_at FROM rollout_devices
+		WHERE rollout_id = $1
+	`, rolloutID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updates := []domain.DeviceUpdate{}
+	for rows.Next() {
+		var (
+			deviceID string
+			status   string
+			u        domain.DeviceUpdate
+		)
+		if err := rows.Scan(&deviceID, &status, &u.Detail, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		id, err := valueobjects.DeviceIDFrom(deviceID)
+		if err != nil {
+			return nil, err
+		}
+		u.RolloutID = rolloutID
+		u.DeviceID = id
+		u.Status = domain.UpdateStatus(status)
+		updates = append(updates, u)
+	}
+	return updates, rows.Err()
+}
+
+func scanRollouts(rows pgx.Rows) ([]*domain.Rollout, error) {
+	defer rows.Close()
+
+	rollouts := []*domain.Rollout{}
+	for rows.Next() {
+		rollout, err := scanRollout(rows)
+		if err != nil {
+			return nil, err
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, rows.Err()
+}
+
+func scanRollout(row pgx.Row) (*domain.Rollout, error) {
+	var (
+		id, releaseID, kind, status       string
+		groups                            []string
+		stages                            []int
+		stage                             int
+		createdBy, abortedBy, abortReason string
+		createdAt, updatedAt              time.Time
+	)
+	if err := row.Scan(&id, &releaseID, &kind, &groups, &stages, &stage, &status,
+		&createdBy, &abortedBy, &abortReason, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	rolloutID, err := valueobjects.RolloutIDFrom(id)
+	if err != nil {
+		return nil, err
+	}
+	relID, err := valueobjects.ReleaseIDFrom(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]valueobjects.DeviceGroupID, 0, len(groups))
+	for _, raw := range groups {
+		groupID, err := valueobjects.DeviceGroupIDFrom(raw)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+
+	return domain.ReconstituteRollout(rolloutID, relID, domain.ReleaseKind(kind), groupIDs, stages, stage,
+		domain.RolloutStatus(status), createdBy, abortedBy, abortReason, createdAt, updatedAt), nil
+}
@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresSalesHoursRepository implements domain.SalesHoursRepository
+type PostgresSalesHoursRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresSalesHoursRepository(pool *pgxpool.Pool) *PostgresSalesHoursRepository {
+	return &PostgresSalesHoursRepository{pool: pool}
+}
+
+type salesWindowJSON struct {
+	Days  []int `json:"days,omitempty"`
+	Start int   `json:"start"`
+	End   int   `json:"end"`
+}
+
+type salesBlackoutJSON struct {
+	Window   salesWindowJSON `json:"window"`
+	SKUCodes []string        `json:"sku_codes,omitempty"`
+	Reason   string          `json:"reason"`
+}
+
+func (r *PostgresSalesHoursRepository) Save(ctx context.Context, h *domain.SalesHours) error {
+	opening := make([]salesWindowJSON, 0, len(h.OpeningHours()))
+	for _, w := range h.OpeningHours() {
+		opening = append(opening, toSalesWindowJSON(w))
+	}
+	blackouts := make([]salesBlackoutJSON, 0, len(h.Blackouts()))
+	for _, b := range h.Blackouts() {
+		blackouts = append(blackouts, salesBlackoutJSON{Window: toSalesWindowJSON(b.Window), SKUCodes: b.SKUCodes, Reason: b.Reason})
+	}
+	openingData, _ := json.Marshal(opening)
+	blackoutsData, _ := json.Marshal(blackouts)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_sales_hours (device_id, timezone, opening_hours, blackouts, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (device_id) DO UPDATE SET
+			timezone = EXCLUDED.timezone,
+			opening_hours = EXCLUDED.opening_hours,
+			blackouts = EXCLUDED.blackouts,
+			updated_at = EXCLUDED.updated_at
+	`, h.DeviceID().String(), h.Timezone(), openingData, blackoutsData, h.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresSalesHoursRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.SalesHours, error) {
+	var (
+		timezone      string
+		openingData   []byte
+		blackoutsData []byte
+		updatedAt     time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT timezone, opening_hours, blackouts, updated_at
+		FROM device_sales_hours
+		WHERE device_id = $1
+	`, deviceID.String()).Scan(&timezone, &openingData, &blackoutsData, &updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSalesHoursNotFound
+		}
+		return nil, err
+	}
+
+	var openingRows []salesWindowJSON
+	_ = json.Unmarshal(openingData, &openingRows)
+	opening := make([]domain.SalesWindow, 0, len(openingRows))
+	for _, w := range openingRows {
+		opening = append(opening, fromSalesWindowJSON(w))
+	}
+
+	var blackoutRows []salesBlackoutJSON
+	_ = json.Unmarshal(blackoutsData, &blackoutRows)
+	blackouts := make([]domain.SalesBlackout, 0, len(blackoutRows))
+	for _, b := range blackoutRows {
+		blackouts = append(blackouts, domain.SalesBlackout{Window: fromSalesWindowJSON(b.Window), SKUCodes: b.SKUCodes, Reason: b.Reason})
+	}
+
+	return domain.ReconstituteSalesHours(deviceID, timezone, opening, blackouts, updatedAt), nil
+}
+
+func toSalesWindowJSON(w domain.SalesWindow) salesWindowJSON {
+	out := salesWindowJSON{Start: w.Start, End: w.End}
+	for _, d := range w.Days {
+		out.Days = append(out.Days, int(d))
+	}
+	return out
+}
+
+func fromSalesWindowJSON(w salesWindowJSON) domain.SalesWindow {
+	out := domain.SalesWindow{Start: w.Start, End: w.End}
+	for _, d := range w.Days {
+		out.Days = append(out.Days, time.Weekday(d))
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresScaleCalibrationRepository implements domain.ScaleCalibrationRepository
+type PostgresScaleCalibrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresScaleCalibrationRepository(pool *pgxpool.Pool) *PostgresScaleCalibrationRepository {
+	return &PostgresScaleCalibrationRepository{pool: pool}
+}
+
+type scaleReadingJSON struct {
+	ReferenceGrams float64 `json:"reference_grams"`
+	MeasuredGrams  float64 `json:"measured_grams"`
+}
+
+func (r *PostgresScaleCalibrationRepository) Save(ctx context.Context, c *domain.ScaleCalibration) error {
+	readings := make([]scaleReadingJSON, 0, len(c.Readings()))
+	for _, reading := range c.Readings() {
+		readings = append(readings, scaleReadingJSON(reading))
+	}
+	readingsData, err := json.Marshal(readings)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO scale_calibrations (id, device_id, zero_grams, readings, calibrated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, c.ID().String(), c.DeviceID().String(), c.ZeroGrams(), readingsData, c.CalibratedAt())
+
+	return err
+}
+
+func (r *PostgresScaleCalibrationRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID, limit int) ([]*domain.ScaleCalibration, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, zero_grams, readings, calibrated_at
+		FROM scale_calibrations
+		WHERE device_id = $1
+		ORDER BY calibrated_at DESC
+		LIMIT $2
+	`, deviceID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calibrations []*domain.ScaleCalibration
+	for rows.Next() {
+		var (
+			rawID        string
+			zeroGrams    float64
+			readingsData []byte
+			calibratedAt time.Time
+		)
+		if err := rows.Scan(&rawID, &zeroGrams, &readingsData, &calibratedAt); err != nil {
+			return nil, err
+		}
+
+		var stored []scaleReadingJSON
+		if err := json.Unmarshal(readingsData, &stored); err != nil {
+			return nil, err
+		}
+		readings := make([]domain.ScaleReading, 0, len(stored))
+		for _, reading := range stored {
+			readings = append(readings, domain.ScaleReading(reading))
+		}
+
+		id, _ := valueobjects.ScaleCalibrationIDFrom(rawID)
+		calibrations = append(calibrations, domain.ReconstituteScaleCalibration(id, deviceID, zeroGrams, readings, calibratedAt))
+	}
+	return calibrations, rows.Err()
+}
@@ -0,0 +1,68 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/device/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresStockEstimateRepository implements domain.StockEstimateRepository
+type PostgresStockEstimateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStockEstimateRepository(pool *pgxpool.Pool) *PostgresStockEstimateRepository {
+	return &PostgresStockEstimateRepository{pool: pool}
+}
+
+type stockEstimateRow struct {
+	DeviceID     string
+	Levels       []byte
+	ModelVersion string
+	ObservedAt   time.Time
+	UpdatedAt    time.Time
+}
+
+func (r *PostgresStockEstimateRepository) Save(ctx context.Context, e *domain.StockEstimate) error {
+	levelsData, _ := json.Marshal(e.Levels())
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO device_stock_estimates (device_id, levels, model_version, observed_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (device_id) DO UPDATE SET
+			levels = EXCLUDED.levels,
+			model_version = EXCLUDED.model_version,
+			observed_at = EXCLUDED.observed_at,
+			updated_at = EXCLUDED.updated_at
+	`, e.DeviceID().String(), levelsData, e.ModelVersion(), e.ObservedAt(), e.UpdatedAt())
+
+	return err
+}
+
+func (r *PostgresStockEstimateRepository) FindByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.StockEstimate, error) {
+	var rec stockEstimateRow
+	err := r.pool.QueryRow(ctx, `
+		SELECT device_id, levels, model_version, observed_at, updated_at
+		FROM device_stock_estimates
+		WHERE device_id = $1
+	`, deviceID.String()).Scan(&rec.DeviceID, &rec.Levels, &rec.ModelVersion, &rec.ObservedAt, &rec.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrStockEstimateNotFound
+		}
+		return nil, err
+	}
+
+	id, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+	var levels map[string]int
+	_ = json.Unmarshal(rec.Levels, &levels)
+
+	return domain.ReconstituteStockEstimate(id, levels, rec.ModelVersion, rec.ObservedAt, rec.UpdatedAt), nil
+}
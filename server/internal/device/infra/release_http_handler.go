@@ -0,0 +1,353 @@
+package infra
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+type releaseResponse struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Version    string    `json:"version"`
+	SizeBytes  int       `json:"size_bytes"`
+	Checksum   string    `json:"checksum"` // SHA-256, hex
+	Notes      string    `json:"notes,omitempty"`
+	UploadedBy string    `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type startRolloutRequest struct {
+	ReleaseID string   `json:"release_id" binding:"required"`
+	GroupIDs  []string `json:"group_ids"`
+	Stages    []int    `json:"stages"`
+}
+
+type abortRolloutRequest struct {
+	Reason string `json:"reason"`
+}
+
+type rolloutDeviceResponse struct {
+	DeviceID  string     `json:"device_id"`
+	MachineID string     `json:"machine_id"`
+	Status    string     `json:"status"`
+	Detail    string     `json:"detail,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type rolloutResponse struct {
+	ID          string                  `json:"id"`
+	ReleaseID   string                  `json:"release_id"`
+	Kind        string                  `json:"kind"`
+	Version     string                  `json:"version"`
+	GroupIDs    []string                `json:"group_ids"`
+	Stages      []int                   `json:"stages"`
+	Stage       int                     `json:"stage"`
+	Percent     int                     `json:"percent"`
+	Status      string                  `json:"status"`
+	CreatedBy   string                  `json:"created_by"`
+	AbortedBy   string                  `json:"aborted_by,omitempty"`
+	AbortReason string                  `json:"abort_reason,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	Targeted    int                     `json:"targeted"`
+	Progress    map[string]int          `json:"progress"`
+	Devices     []rolloutDeviceResponse `json:"devices"`
+}
+
+type offeredUpdateResponse struct {
+	RolloutID   string `json:"rollout_id"`
+	ReleaseID   string `json:"release_id"`
+	Kind        string `json:"kind"`
+	Version     string `json:"version"`
+	SizeBytes   int    `json:"size_bytes"`
+	Checksum    string `json:"checksum"`
+	DownloadURL string `json:"download_url"`
+}
+
+// UploadRelease registers a firmware or app build, uploaded as the multipart
+// field "artifact" with its "kind" (firmware or app), "version" and optional
+// "notes". The operator is taken from X-Actor-ID.
+func (h *HTTPHandler) UploadRelease(c *gin.Context) {
+	// Room for the form fields around the largest accepted artifact
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxReleaseBytes+64<<10)
+
+	file, err := c.FormFile("artifact")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrInvalidRelease.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an artifact is required in the \"artifact\" field"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read uploaded file"})
+		return
+	}
+	defer f.Close()
+	artifact, err := io.ReadAll(io.LimitReader(f, domain.MaxReleaseBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read uploaded file"})
+		return
+	}
+
+	release, err := h.releaseUploader.Handle(c.Request.Context(), app.UploadReleaseCommand{
+		Kind:       domain.ReleaseKind(c.PostForm("kind")),
+		Version:    c.PostForm("version"),
+		Artifact:   artifact,
+		Notes:      c.PostForm("notes"),
+		UploadedBy: c.GetHeader(actorIDHeader),
+	})
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toReleaseResponse(release))
+}
+
+// ListReleases lists the release registry, newest first, optionally of one ?kind=
+func (h *HTTPHandler) ListReleases(c *gin.Context) {
+	kind := domain.ReleaseKind(c.Query("kind"))
+	switch kind {
+	case "", domain.ReleaseKindFirmware, domain.ReleaseKindApp:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be firmware or app"})
+		return
+	}
+
+	releases, err := h.releaseQuery.List(c.Request.Context(), kind)
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	response := make([]releaseResponse, 0, len(releases))
+	for _, r := range releases {
+		response = append(response, toReleaseResponse(r))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"releases": response,
+		"count":    len(response),
+	})
+}
+
+// GetRelease returns one release of the registry
+func (h *HTTPHandler) GetRelease(c *gin.Context) {
+	release, err := h.releaseQuery.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toReleaseResponse(release))
+}
+
+// DownloadRelease serves a release artifact to a device. The device
+// authenticates with its key in X-Device-Key and checks the artifact against
+// the X-Checksum-SHA256 header.
+func (h *HTTPHandler) DownloadRelease(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	release, data, err := h.releaseDownloader.Handle(c.Request.Context(), app.DownloadReleaseCommand{
+		MachineID: machineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+		ReleaseID: c.Param("id"),
+	})
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.Header("X-Checksum-SHA256", release.Checksum)
+	c.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+// StartRollout rolls a release out to device groups, in increasing
+// percentage stages. The operator is taken from X-Actor-ID.
+func (h *HTTPHandler) StartRollout(c *gin.Context) {
+	var req startRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.rolloutStarter.Handle(c.Request.Context(), app.StartRolloutCommand{
+		ReleaseID: req.ReleaseID,
+		GroupIDs:  req.GroupIDs,
+		Stages:    req.Stages,
+		StartedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toRolloutResponse(*view))
+}
+
+// ListRollouts lists every rollout with its progress, newest first
+func (h *HTTPHandler) ListRollouts(c *gin.Context) {
+	views, err := h.rolloutQuery.List(c.Request.Context())
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	response := make([]rolloutResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toRolloutResponse(v))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"rollouts": response,
+		"count":    len(response),
+	})
+}
+
+// GetRollout returns a rollout with the update status of its devices
+func (h *HTTPHandler) GetRollout(c *gin.Context) {
+	view, err := h.rolloutQuery.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRolloutResponse(*view))
+}
+
+// AdvanceRollout offers the release to the next stage of devices. The
+// operator is taken from X-Actor-ID.
+func (h *HTTPHandler) AdvanceRollout(c *gin.Context) {
+	view, err := h.rolloutAdvancer.Handle(c.Request.Context(), c.Param("id"), strings.TrimSpace(c.GetHeader(actorIDHeader)))
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRolloutResponse(*view))
+}
+
+// AbortRollout stops offering the release to devices. The operator is taken
+// from X-Actor-ID.
+func (h *HTTPHandler) AbortRollout(c *gin.Context) {
+	var req abortRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.rolloutAborter.Handle(c.Request.Context(), app.AbortRolloutCommand{
+		RolloutID: c.Param("id"),
+		Reason:    req.Reason,
+		AbortedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeRolloutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRolloutResponse(*view))
+}
+
+func (h *HTTPHandler) writeRolloutError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeviceKey),
+		errors.Is(err, domain.ErrUploadedByRequired),
+		errors.Is(err, domain.ErrRolloutOperatorRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReleaseNotFound),
+		errors.Is(err, domain.ErrRolloutNotFound),
+		errors.Is(err, domain.ErrDeviceGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDuplicateRelease),
+		errors.Is(err, domain.ErrRolloutInProgress),
+		errors.Is(err, domain.ErrRolloutNotActive),
+		errors.Is(err, domain.ErrRolloutAborted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRelease),
+		errors.Is(err, domain.ErrRolloutTargetRequired),
+		errors.Is(err, domain.ErrInvalidRolloutStages):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toReleaseResponse(r domain.Release) releaseResponse {
+	return releaseResponse{
+		ID:         r.ID.String(),
+		Kind:       string(r.Kind),
+		Version:    r.Version,
+		SizeBytes:  r.SizeBytes,
+		Checksum:   r.Checksum,
+		Notes:      r.Notes,
+		UploadedBy: r.UploadedBy,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+func toRolloutResponse(v app.RolloutView) rolloutResponse {
+	response := rolloutResponse{
+		ID:          v.ID,
+		ReleaseID:   v.ReleaseID,
+		Kind:        string(v.Kind),
+		Version:     v.Version,
+		GroupIDs:    v.GroupIDs,
+		Stages:      v.Stages,
+		Stage:       v.Stage,
+		Percent:     v.Percent,
+		Status:      string(v.Status),
+		CreatedBy:   v.CreatedBy,
+		AbortedBy:   v.AbortedBy,
+		AbortReason: v.AbortReason,
+		CreatedAt:   v.CreatedAt,
+		UpdatedAt:   v.UpdatedAt,
+		Targeted:    v.Targeted,
+		Progress:    make(map[string]int, len(v.Progress)),
+		Devices:     make([]rolloutDeviceResponse, 0, len(v.Devices)),
+	}
+	for status, count := range v.Progress {
+		response.Progress[string(status)] = count
+	}
+	for _, d := range v.Devices {
+		response.Devices = append(response.Devices, rolloutDeviceResponse{
+			DeviceID:  d.DeviceID,
+			MachineID: d.MachineID,
+			Status:    string(d.Status),
+			Detail:    d.Detail,
+			UpdatedAt: d.UpdatedAt,
+		})
+	}
+	return response
+}
+
+func toOfferedUpdateResponses(machineID string, offers []app.OfferedUpdate) []offeredUpdateResponse {
+	response := make([]offeredUpdateResponse, 0, len(offers))
+	for _, o := range offers {
+		response = append(response, offeredUpdateResponse{
+			RolloutID:   o.RolloutID,
+			ReleaseID:   o.ReleaseID,
+			Kind:        string(o.Kind),
+			Version:     o.Version,
+			SizeBytes:   o.SizeBytes,
+			Checksum:    o.Checksum,
+			DownloadURL: "/api/v1/device/releases/" + o.ReleaseID + "?machine_id=" + url.QueryEscape(machineID),
+		})
+	}
+	return response
+}
@@ -0,0 +1,168 @@
+package infra
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+const (
+	defaultRestockSessionLimit = 20
+	maxRestockSessionLimit     = 100
+)
+
+type recordRestockUnitsRequest struct {
+	Items []restockLineDTO `json:"items"`
+}
+
+type restockLineResponse struct {
+	SKUCode    string `json:"sku_code"`
+	Added      int    `json:"added"`
+	LevelAfter *int   `json:"level_after,omitempty"`
+}
+
+type restockSessionResponse struct {
+	ID         string                `json:"id"`
+	DeviceID   string                `json:"device_id"`
+	MachineID  string                `json:"machine_id"`
+	Status     string                `json:"status"`
+	StartedBy  string                `json:"started_by"`
+	StartedAt  time.Time             `json:"started_at"`
+	ClosedBy   string                `json:"closed_by,omitempty"`
+	ClosedAt   *time.Time            `json:"closed_at,omitempty"`
+	UnitsAdded int                   `json:"units_added"`
+	Lines      []restockLineResponse `json:"lines"`
+}
+
+// StartRestockSession opens a restock session on the device; no customer
+// session starts on it until the session is completed or cancelled. The
+// operator is taken from X-Actor-ID.
+func (h *HTTPHandler) StartRestockSession(c *gin.Context) {
+	view, err := h.restockSessionStarter.Handle(c.Request.Context(), app.StartRestockSessionCommand{
+		DeviceID:  c.Param("id"),
+		StartedBy: strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toRestockSessionResponse(view))
+}
+
+// DeviceRestockSessions lists the device's restock sessions newest first;
+// ?limit= defaults to 20, max 100
+func (h *HTTPHandler) DeviceRestockSessions(c *gin.Context) {
+	limit := defaultRestockSessionLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxRestockSessionLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	views, err := h.restockSessionQuery.ListByDevice(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	response := make([]restockSessionResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toRestockSessionResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RestockSession returns a restock session; once closed it is the restock report
+func (h *HTTPHandler) RestockSession(c *gin.Context) {
+	view, err := h.restockSessionQuery.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRestockSessionResponse(view))
+}
+
+// RecordRestockUnits counts units the operator loaded; lines for the same
+// SKU, in one request or several, are added up
+func (h *HTTPHandler) RecordRestockUnits(c *gin.Context) {
+	var req recordRestockUnitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.RecordRestockUnitsCommand{
+		SessionID: c.Param("id"),
+		Units:     make(map[string]int, len(req.Items)),
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			h.writeDeviceError(c, domain.ErrInvalidRestock)
+			return
+		}
+		cmd.Units[strings.TrimSpace(item.SKUCode)] += item.Quantity
+	}
+
+	view, err := h.restockUnitsRecorder.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRestockSessionResponse(view))
+}
+
+// CompleteRestockSession adds the session's units to the device's inventory,
+// reopens the device to customers and returns the restock report. The
+// operator is taken from X-Actor-ID.
+func (h *HTTPHandler) CompleteRestockSession(c *gin.Context) {
+	view, err := h.restockSessionCompleter.Handle(c.Request.Context(), app.CloseRestockSessionCommand{
+		SessionID: c.Param("id"),
+		ClosedBy:  strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRestockSessionResponse(view))
+}
+
+// CancelRestockSession discards the session's units and reopens the device
+// to customers. The operator is taken from X-Actor-ID.
+func (h *HTTPHandler) CancelRestockSession(c *gin.Context) {
+	view, err := h.restockSessionCanceller.Handle(c.Request.Context(), app.CloseRestockSessionCommand{
+		SessionID: c.Param("id"),
+		ClosedBy:  strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRestockSessionResponse(view))
+}
+
+func toRestockSessionResponse(v app.RestockSessionView) restockSessionResponse {
+	lines := make([]restockLineResponse, 0, len(v.Lines))
+	for _, l := range v.Lines {
+		lines = append(lines, restockLineResponse{SKUCode: l.Code, Added: l.Added, LevelAfter: l.LevelAfter})
+	}
+	return restockSessionResponse{
+		ID:         v.ID,
+		DeviceID:   v.DeviceID,
+		MachineID:  v.MachineID,
+		Status:     v.Status,
+		StartedBy:  v.StartedBy,
+		StartedAt:  v.StartedAt,
+		ClosedBy:   v.ClosedBy,
+		ClosedAt:   v.ClosedAt,
+		UnitsAdded: v.UnitsAdded,
+		Lines:      lines,
+	}
+}
@@ -6,7 +6,110 @@ import "github.com/gin-gonic/gin"
 func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	device := rg.Group("/device")
 	{
-		device.POST("/register", h.Register)
+		device.POST("/enroll", h.Enroll)
 		device.GET("/skus", h.GetSKUs)
+		device.GET("/start-token", h.StartToken)
+		device.POST("/snapshot", h.SubmitShelfSnapshot)
+		device.GET("/stock", h.Stock)
+		device.POST("/telemetry", h.Telemetry)
+		device.POST("/heartbeat", h.Heartbeat)
+		device.GET("/config", h.PullConfig)
+		device.POST("/config/reported", h.ReportConfig)
+		device.PUT("/calibration", h.UploadCalibration)
+		device.GET("/calibration", h.Calibration)
+		device.POST("/scale/calibrate", h.CalibrateScale)
+		device.GET("/releases/:id", h.DownloadRelease)
+		device.POST("/clear", h.Clear)
+		device.POST("/decommission", h.Decommission)
+		device.POST("/maintenance/enter", h.EnterMaintenance)
+		device.POST("/maintenance/exit", h.ExitMaintenance)
+		device.GET("/excursions", h.Excursions)
+		device.GET("/incidents", h.Incidents)
+		device.POST("/events", h.RecordEvents)
+		device.GET("/events", h.Events)
+		device.GET("/commands", h.PollCommands)
+		device.GET("/commands/ws", h.CommandSocket)
+		device.POST("/commands/:id/ack", h.AcknowledgeCommand)
+		device.POST("/commands/:id/diagnostics", h.UploadDiagnostics)
+		device.PUT("/planogram", h.AssignPlanogram)
+		device.GET("/planogram", h.Planogram)
+		device.POST("/restock-visit", h.RecordRestockVisit)
+		device.GET("/compliance", h.ComplianceReports)
+		device.GET("/details", h.Details)
+		device.PUT("/sales-hours", h.SetSalesHours)
+		device.GET("/sales-hours", h.SalesHours)
+		device.POST("/batches", h.RecordBatches)
+		device.GET("/batches", h.Batches)
+		device.GET("/batches/expiring", h.ExpiringBatches)
+		device.POST("/batches/:id/write-off", h.WriteOffBatch)
+		device.GET("/waste", h.WasteReport)
+	}
+
+	devices := rg.Group("/devices")
+	{
+		devices.GET("", h.ListDevices)
+		devices.POST("/enrollment-tokens", h.CreateEnrollmentToken)
+		devices.GET("/:id", h.GetDevice)
+		devices.GET("/:id/qrcode.png", h.DeviceQRCode)
+		devices.POST("/:id/commands", h.IssueCommand)
+		devices.GET("/:id/commands", h.DeviceCommands)
+		devices.POST("/:id/diagnostics", h.RequestDiagnostics)
+		devices.GET("/:id/diagnostics/:command_id", h.Diagnostics)
+		devices.POST("/:id/key", h.IssueKey)
+		devices.POST("/:id/inventory", h.RestockInventory)
+		devices.GET("/:id/inventory", h.Inventory)
+		devices.GET("/:id/inventory/low", h.LowInventory)
+		devices.POST("/:id/restock-sessions", h.StartRestockSession)
+		devices.GET("/:id/restock-sessions", h.DeviceRestockSessions)
+		devices.POST("/:id/skus", h.AssignSKUs)
+		devices.GET("/:id/skus", h.AssignedSKUs)
+		devices.DELETE("/:id/skus/:code", h.UnassignSKU)
+		devices.PUT("/:id/policy", h.SetDevicePolicy)
+		devices.GET("/:id/policy", h.DevicePolicy)
+		devices.PUT("/:id/labels", h.SetDeviceLabels)
+		devices.PUT("/:id/config", h.SetDeviceConfig)
+		devices.GET("/:id/config", h.DeviceConfig)
+		devices.GET("/:id/calibration", h.DeviceCalibration)
+		devices.GET("/:id/scale/calibrations", h.ScaleCalibrations)
+	}
+
+	groups := rg.Group("/device-groups")
+	{
+		groups.POST("", h.CreateDeviceGroup)
+		groups.GET("", h.ListDeviceGroups)
+		groups.GET("/:id", h.GetDeviceGroup)
+		groups.PUT("/:id/policy", h.SetGroupPolicy)
+		groups.POST("/:id/devices", h.AssignGroupDevices)
+		groups.DELETE("/:id/devices/:machine_id", h.RemoveGroupDevice)
+	}
+
+	releases := rg.Group("/releases")
+	{
+		releases.POST("", h.UploadRelease)
+		releases.GET("", h.ListReleases)
+		releases.GET("/:id", h.GetRelease)
+	}
+
+	rollouts := rg.Group("/rollouts")
+	{
+		rollouts.POST("", h.StartRollout)
+		rollouts.GET("", h.ListRollouts)
+		rollouts.GET("/:id", h.GetRollout)
+		rollouts.POST("/:id/advance", h.AdvanceRollout)
+		rollouts.POST("/:id/abort", h.AbortRollout)
+	}
+
+	restockSessions := rg.Group("/restock-sessions")
+	{
+		restockSessions.GET("/:id", h.RestockSession)
+		restockSessions.POST("/:id/units", h.RecordRestockUnits)
+		restockSessions.POST("/:id/complete", h.CompleteRestockSession)
+		restockSessions.POST("/:id/cancel", h.CancelRestockSession)
+	}
+
+	admin := rg.Group("/admin")
+	{
+		admin.GET("/fleet/health", h.FleetHealth)
+		admin.POST("/fleet/offline-sweep", h.SweepOfflineDevices)
 	}
 }
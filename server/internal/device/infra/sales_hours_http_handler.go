@@ -0,0 +1,221 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type salesWindowDTO struct {
+	Days  []string `json:"days,omitempty"` // "mon".."sun"; empty means every day
+	Start string   `json:"start"`          // "HH:MM", device local time
+	End   string   `json:"end"`            // "HH:MM", up to "24:00"; before start runs past midnight
+}
+
+type salesBlackoutDTO struct {
+	salesWindowDTO
+	SKUCodes []string `json:"sku_codes,omitempty"` // empty closes the whole device
+	Reason   string   `json:"reason"`
+}
+
+type setSalesHoursRequest struct {
+	MachineID    string             `json:"machine_id" binding:"required"`
+	Timezone     string             `json:"timezone"` // IANA name, e.g. "Europe/Zurich"; defaults to UTC
+	OpeningHours []salesWindowDTO   `json:"opening_hours"`
+	Blackouts    []salesBlackoutDTO `json:"blackouts"`
+}
+
+type restrictedSKUResponse struct {
+	SKUCode string `json:"sku_code"`
+	Reason  string `json:"reason"`
+}
+
+// SetSalesHours sets or replaces the opening hours and blackout windows of a device
+func (h *HTTPHandler) SetSalesHours(c *gin.Context) {
+	var req setSalesHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.SetSalesHoursCommand{MachineID: req.MachineID, Timezone: req.Timezone}
+	for _, w := range req.OpeningHours {
+		window, err := w.toDomain()
+		if err != nil {
+			h.writeSalesHoursError(c, err)
+			return
+		}
+		cmd.OpeningHours = append(cmd.OpeningHours, window)
+	}
+	for _, b := range req.Blackouts {
+		window, err := b.salesWindowDTO.toDomain()
+		if err != nil {
+			h.writeSalesHoursError(c, err)
+			return
+		}
+		cmd.Blackouts = append(cmd.Blackouts, domain.SalesBlackout{Window: window, SKUCodes: b.SKUCodes, Reason: b.Reason})
+	}
+
+	view, err := h.salesHoursHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeSalesHoursError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSalesHoursResponse(*view))
+}
+
+// SalesHours returns the device's sales hours and whether it sells right now
+func (h *HTTPHandler) SalesHours(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.salesHoursQuery.FindByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeSalesHoursError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSalesHoursResponse(*view))
+}
+
+// Details returns the device as shown in the customer app, including its sales hours
+func (h *HTTPHandler) Details(c *gin.Context) {
+	machineID := c.Query("machine_id")
+	if machineID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "machine_id is required"})
+		return
+	}
+
+	view, err := h.salesHoursQuery.DetailsByMachineID(c.Request.Context(), machineID)
+	if err != nil {
+		h.writeSalesHoursError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"id":          view.ID,
+		"machine_id":  view.MachineID,
+		"name":        view.Name,
+		"location":    view.Location,
+		"status":      view.Status,
+		"sales_hours": toSalesHoursResponse(view.SalesHours),
+	}
+	if view.Region != "" {
+		response["region"] = view.Region
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) writeSalesHoursError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+	case errors.Is(err, domain.ErrInvalidSalesWindow),
+		errors.Is(err, domain.ErrInvalidTimezone),
+		errors.Is(err, domain.ErrBlackoutReasonRequired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func (w salesWindowDTO) toDomain() (domain.SalesWindow, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return domain.SalesWindow{}, err
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return domain.SalesWindow{}, err
+	}
+
+	window := domain.SalesWindow{Start: start, End: end}
+	for _, name := range w.Days {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return domain.SalesWindow{}, domain.ErrInvalidSalesWindow
+		}
+		window.Days = append(window.Days, day)
+	}
+	return window, nil
+}
+
+func parseClock(s string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &hour, &minute); err != nil {
+		return 0, domain.ErrInvalidSalesWindow
+	}
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, domain.ErrInvalidSalesWindow
+	}
+	return hour*60 + minute, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, n := range weekdayNames {
+		if strings.HasPrefix(name, n) {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+func toSalesWindowDTO(w domain.SalesWindow) salesWindowDTO {
+	dto := salesWindowDTO{Start: formatClock(w.Start), End: formatClock(w.End)}
+	for _, d := range w.Days {
+		dto.Days = append(dto.Days, weekdayNames[d])
+	}
+	return dto
+}
+
+func toSalesHoursResponse(v app.SalesHoursView) gin.H {
+	opening := make([]salesWindowDTO, 0, len(v.OpeningHours))
+	for _, w := range v.OpeningHours {
+		opening = append(opening, toSalesWindowDTO(w))
+	}
+	blackouts := make([]salesBlackoutDTO, 0, len(v.Blackouts))
+	for _, b := range v.Blackouts {
+		blackouts = append(blackouts, salesBlackoutDTO{salesWindowDTO: toSalesWindowDTO(b.Window), SKUCodes: b.SKUCodes, Reason: b.Reason})
+	}
+	restricted := make([]restrictedSKUResponse, 0, len(v.Status.RestrictedSKUs))
+	for code, reason := range v.Status.RestrictedSKUs {
+		restricted = append(restricted, restrictedSKUResponse{SKUCode: code, Reason: reason})
+	}
+	sort.Slice(restricted, func(i, j int) bool { return restricted[i].SKUCode < restricted[j].SKUCode })
+
+	response := gin.H{
+		"machine_id":      v.MachineID,
+		"timezone":        v.Timezone,
+		"configured":      v.Configured,
+		"opening_hours":   opening,
+		"blackouts":       blackouts,
+		"open_now":        v.Status.Open,
+		"restricted_skus": restricted,
+	}
+	if v.Status.ClosedReason != "" {
+		response["closed_reason"] = v.Status.ClosedReason
+	}
+	if v.UpdatedAt != nil {
+		response["updated_at"] = v.UpdatedAt
+	}
+	return response
+}
@@ -0,0 +1,148 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/device/app"
+	"github.com/vending-machine/server/internal/device/domain"
+)
+
+const (
+	defaultScaleCalibrationLimit = 20
+	maxScaleCalibrationLimit     = 100
+)
+
+type scaleReadingDTO struct {
+	ReferenceGrams float64 `json:"reference_grams"`
+	MeasuredGrams  float64 `json:"measured_grams"`
+}
+
+type calibrateScaleRequest struct {
+	MachineID    string            `json:"machine_id" binding:"required"`
+	ZeroGrams    float64           `json:"zero_grams"`
+	Readings     []scaleReadingDTO `json:"readings"`
+	CalibratedAt *time.Time        `json:"calibrated_at"`
+}
+
+type scaleCalibrationResponse struct {
+	ID           string            `json:"id"`
+	ZeroGrams    float64           `json:"zero_grams"`
+	Readings     []scaleReadingDTO `json:"readings"`
+	SpanFactor   float64           `json:"span_factor"`
+	ErrorGrams   float64           `json:"error_grams"`
+	CalibratedAt time.Time         `json:"calibrated_at"`
+}
+
+type scaleCalibrationHistoryResponse struct {
+	DeviceID         string                     `json:"device_id"`
+	MachineID        string                     `json:"machine_id"`
+	Calibrations     []scaleCalibrationResponse `json:"calibrations"`
+	DriftGramsPerDay float64                    `json:"drift_grams_per_day"`
+	RecalibrateBy    *time.Time                 `json:"recalibrate_by,omitempty"`
+	RecalibrationDue bool                       `json:"recalibration_due"`
+}
+
+// CalibrateScale records a scale calibration against known weights. The
+// device reports the readings as found, before zeroing and correcting the
+// scale, and authenticates with X-Device-Key.
+func (h *HTTPHandler) CalibrateScale(c *gin.Context) {
+	var req calibrateScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	readings := make([]domain.ScaleReading, 0, len(req.Readings))
+	for _, r := range req.Readings {
+		readings = append(readings, domain.ScaleReading(r))
+	}
+	cmd := app.RecordScaleCalibrationCommand{
+		MachineID: req.MachineID,
+		DeviceKey: c.GetHeader(deviceKeyHeader),
+		ZeroGrams: req.ZeroGrams,
+		Readings:  readings,
+	}
+	if req.CalibratedAt != nil {
+		cmd.CalibratedAt = *req.CalibratedAt
+	}
+
+	view, err := h.scaleCalibrator.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeScaleCalibrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScaleCalibrationResponse(view))
+}
+
+// ScaleCalibrations returns a device's scale calibration history, newest
+// first, with the drift it shows and when to recalibrate; ?limit= defaults
+// to 20, max 100
+func (h *HTTPHandler) ScaleCalibrations(c *gin.Context) {
+	limit := defaultScaleCalibrationLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxScaleCalibrationLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	history, err := h.scaleCalibrationQuery.History(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		h.writeScaleCalibrationError(c, err)
+		return
+	}
+
+	calibrations := make([]scaleCalibrationResponse, 0, len(history.Calibrations))
+	for _, v := range history.Calibrations {
+		calibrations = append(calibrations, toScaleCalibrationResponse(v))
+	}
+	c.JSON(http.StatusOK, scaleCalibrationHistoryResponse{
+		DeviceID:         history.DeviceID,
+		MachineID:        history.MachineID,
+		Calibrations:     calibrations,
+		DriftGramsPerDay: history.DriftGramsPerDay,
+		RecalibrateBy:    history.RecalibrateBy,
+		RecalibrationDue: history.RecalibrationDue,
+	})
+}
+
+func (h *HTTPHandler) writeScaleCalibrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeviceKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeviceDecommissioned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidScaleReading),
+		errors.Is(err, domain.ErrNoScaleReadings),
+		errors.Is(err, domain.ErrTooManyScaleReadings),
+		errors.Is(err, domain.ErrFutureScaleCalibration):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toScaleCalibrationResponse(v app.ScaleCalibrationView) scaleCalibrationResponse {
+	readings := make([]scaleReadingDTO, 0, len(v.Readings))
+	for _, r := range v.Readings {
+		readings = append(readings, scaleReadingDTO(r))
+	}
+	return scaleCalibrationResponse{
+		ID:           v.ID,
+		ZeroGrams:    v.ZeroGrams,
+		Readings:     readings,
+		SpanFactor:   v.SpanFactor,
+		ErrorGrams:   v.ErrorGrams,
+		CalibratedAt: v.CalibratedAt,
+	}
+}
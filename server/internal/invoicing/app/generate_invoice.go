@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+	"github.com/vending-machine/server/internal/invoicing/domain"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// EventPublisher is the interface for publishing domain events
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.DomainEvent) error
+}
+
+// GenerateInvoiceCommand is the input DTO for generating a monthly invoice
+type GenerateInvoiceCommand struct {
+	CustomerID  string
+	Period      string // YYYY-MM
+	CompanyName string
+	VATID       string
+	Address     string
+	Email       string
+}
+
+// InvoiceResult is the output DTO for invoice commands
+type InvoiceResult struct {
+	InvoiceID  string
+	Number     string
+	Period     string
+	NetCents   int64
+	VATCents   int64
+	GrossCents int64
+	Currency   string
+	LineCount  int
+}
+
+// GenerateInvoiceHandler consolidates a customer's completed transactions
+// of one month into a single numbered invoice
+type GenerateInvoiceHandler struct {
+	invoices     domain.InvoiceRepository
+	transactions ports.TransactionSource
+	publisher    EventPublisher
+	vatRateBP    int
+}
+
+func NewGenerateInvoiceHandler(
+	invoices domain.InvoiceRepository,
+	transactions ports.TransactionSource,
+	publisher EventPublisher,
+	vatRateBP int,
+) *GenerateInvoiceHandler {
+	if invoices == nil {
+		panic("nil InvoiceRepository")
+	}
+	if transactions == nil {
+		panic("nil TransactionSource")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &GenerateInvoiceHandler{
+		invoices:     invoices,
+		transactions: transactions,
+		publisher:    publisher,
+		vatRateBP:    vatRateBP,
+	}
+}
+
+func (h *GenerateInvoiceHandler) Handle(ctx context.Context, cmd GenerateInvoiceCommand) (InvoiceResult, error) {
+	period, err := domain.ParseBillingPeriod(cmd.Period)
+	if err != nil {
+		return InvoiceResult{}, err
+	}
+	now := time.Now().UTC()
+	if !period.IsClosed(now) {
+		return InvoiceResult{}, domain.ErrBillingPeriodNotClosed
+	}
+
+	// One invoice per customer and month
+	if _, err := h.invoices.FindByCustomerAndPeriod(ctx, cmd.CustomerID, period); err == nil {
+		return InvoiceResult{}, domain.ErrInvoiceAlreadyIssued
+	} else if !errors.Is(err, domain.ErrInvoiceNotFound) {
+		return InvoiceResult{}, err
+	}
+
+	txs, err := h.transactions.CompletedByCustomer(ctx, cmd.CustomerID, period.Start(), period.End())
+	if err != nil {
+		return InvoiceResult{}, fmt.Errorf("failed to load transactions: %w", err)
+	}
+	if len(txs) == 0 {
+		return InvoiceResult{}, domain.ErrNoBillableTransactions
+	}
+
+	currency := txs[0].Currency
+	lines := make([]domain.InvoiceLine, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Currency != currency {
+			return InvoiceResult{}, domain.ErrMixedCurrencies
+		}
+		lines = append(lines, domain.InvoiceLine{
+			SessionID:      tx.SessionID,
+			Date:           tx.CompletedAt,
+			Description:    fmt.Sprintf("Vending purchase, %d item(s)", tx.ItemCount),
+			GrossCents:     tx.TotalCents,
+			FiscalDocument: tx.FiscalDocument,
+		})
+	}
+
+	seq, err := h.invoices.NextNumber(ctx, now.Year())
+	if err != nil {
+		return InvoiceResult{}, fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+
+	customer := domain.BillingDetails{
+		CustomerID:  cmd.CustomerID,
+		CompanyName: cmd.CompanyName,
+		VATID:       cmd.VATID,
+		Address:     cmd.Address,
+		Email:       cmd.Email,
+	}
+	invoice, err := domain.IssueInvoice(domain.FormatInvoiceNumber(now.Year(), seq), customer, period, lines, h.vatRateBP, currency)
+	if err != nil {
+		return InvoiceResult{}, err
+	}
+
+	if err := h.invoices.Save(ctx, invoice); err != nil {
+		return InvoiceResult{}, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range invoice.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toInvoiceResult(invoice), nil
+}
+
+func toInvoiceResult(inv *domain.Invoice) InvoiceResult {
+	return InvoiceResult{
+		InvoiceID:  inv.ID().String(),
+		Number:     inv.Number(),
+		Period:     inv.Period().String(),
+		NetCents:   inv.Net().Amount(),
+		VATCents:   inv.VAT().Amount(),
+		GrossCents: inv.Gross().Amount(),
+		Currency:   inv.Gross().Currency(),
+		LineCount:  len(inv.Lines()),
+	}
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vending-machine/server/internal/invoicing/domain"
+)
+
+// ErrMailerDisabled is returned by mailers that are not configured
+var ErrMailerDisabled = errors.New("invoice email delivery is not configured")
+
+// InvoiceRenderer is an output port that renders an invoice document
+type InvoiceRenderer interface {
+	RenderPDF(invoice *domain.Invoice) ([]byte, error)
+}
+
+// InvoiceMailer is an output port that emails an invoice PDF
+type InvoiceMailer interface {
+	SendInvoice(ctx context.Context, to, subject, body, filename string, pdf []byte) error
+}
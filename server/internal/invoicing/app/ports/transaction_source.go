@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// CompletedTransaction is the invoicing context's view of a completed sale
+type CompletedTransaction struct {
+	SessionID      string
+	DeviceID       string
+	CompletedAt    time.Time
+	ItemCount      int
+	TotalCents     int64
+	Currency       string
+	FiscalDocument string // fiscal receipt number of the sale, if any
+}
+
+// TransactionSource is an output port for reading a customer's completed
+// transactions from the transaction context
+type TransactionSource interface {
+	CompletedByCustomer(ctx context.Context, customerID string, from, to time.Time) ([]CompletedTransaction, error)
+}
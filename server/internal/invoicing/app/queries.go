@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+	"github.com/vending-machine/server/internal/invoicing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// InvoiceView is a read-only view of an invoice
+type InvoiceView struct {
+	ID          string
+	Number      string
+	CustomerID  string
+	CompanyName string
+	VATID       string
+	Address     string
+	Email       string
+	Period      string
+	Lines       []InvoiceLineView
+	VATRateBP   int
+	NetCents    int64
+	VATCents    int64
+	GrossCents  int64
+	Currency    string
+	IssuedAt    string
+	EmailedAt   *string
+}
+
+// InvoiceLineView is a read-only view of an invoice line
+type InvoiceLineView struct {
+	SessionID      string
+	Date           string
+	Description    string
+	GrossCents     int64
+	FiscalDocument string // fiscal receipt number of the sale, if any
+}
+
+// InvoiceQueryService provides read-only access to invoices
+type InvoiceQueryService struct {
+	invoices domain.InvoiceRepository
+	renderer ports.InvoiceRenderer
+}
+
+func NewInvoiceQueryService(invoices domain.InvoiceRepository, renderer ports.InvoiceRenderer) *InvoiceQueryService {
+	if invoices == nil {
+		panic("nil InvoiceRepository")
+	}
+	if renderer == nil {
+		panic("nil InvoiceRenderer")
+	}
+	return &InvoiceQueryService{invoices: invoices, renderer: renderer}
+}
+
+func (s *InvoiceQueryService) FindByID(ctx context.Context, id string) (*InvoiceView, error) {
+	invoice, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toView(invoice), nil
+}
+
+func (s *InvoiceQueryService) FindByCustomer(ctx context.Context, customerID string) ([]*InvoiceView, error) {
+	invoices, err := s.invoices.FindByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*InvoiceView, 0, len(invoices))
+	for _, inv := range invoices {
+		views = append(views, s.toView(inv))
+	}
+	return views, nil
+}
+
+// RenderPDF returns the invoice PDF and its invoice number
+func (s *InvoiceQueryService) RenderPDF(ctx context.Context, id string) ([]byte, string, error) {
+	invoice, err := s.load(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, err := s.renderer.RenderPDF(invoice)
+	if err != nil {
+		return nil, "", err
+	}
+	return pdf, invoice.Number(), nil
+}
+
+func (s *InvoiceQueryService) load(ctx context.Context, id string) (*domain.Invoice, error) {
+	invoiceID, err := valueobjects.InvoiceIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return s.invoices.FindByID(ctx, invoiceID)
+}
+
+func (s *InvoiceQueryService) toView(inv *domain.Invoice) *InvoiceView {
+	var lines []InvoiceLineView
+	for _, line := range inv.Lines() {
+		lines = append(lines, InvoiceLineView{
+			SessionID:      line.SessionID,
+			Date:           line.Date.Format("2006-01-02"),
+			Description:    line.Description,
+			GrossCents:     line.GrossCents,
+			FiscalDocument: line.FiscalDocument,
+		})
+	}
+
+	var emailedAt *string
+	if inv.EmailedAt() != nil {
+		t := inv.EmailedAt().Format("2006-01-02T15:04:05Z07:00")
+		emailedAt = &t
+	}
+
+	customer := inv.Customer()
+	return &InvoiceView{
+		ID:          inv.ID().String(),
+		Number:      inv.Number(),
+		CustomerID:  customer.CustomerID,
+		CompanyName: customer.CompanyName,
+		VATID:       customer.VATID,
+		Address:     customer.Address,
+		Email:       customer.Email,
+		Period:      inv.Period().String(),
+		Lines:       lines,
+		VATRateBP:   inv.VATRateBP(),
+		NetCents:    inv.Net().Amount(),
+		VATCents:    inv.VAT().Amount(),
+		GrossCents:  inv.Gross().Amount(),
+		Currency:    inv.Gross().Currency(),
+		IssuedAt:    inv.IssuedAt().Format("2006-01-02T15:04:05Z07:00"),
+		EmailedAt:   emailedAt,
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+	"github.com/vending-machine/server/internal/invoicing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+var (
+	ErrRecipientRequired = errors.New("no recipient email address")
+	ErrDeliveryFailed    = errors.New("invoice email delivery failed")
+)
+
+// SendInvoiceCommand is the input DTO for emailing an invoice
+type SendInvoiceCommand struct {
+	InvoiceID string
+	To        string // defaults to the billing email on the invoice
+}
+
+// SendInvoiceHandler renders an invoice as PDF and emails it to the customer
+type SendInvoiceHandler struct {
+	invoices  domain.InvoiceRepository
+	renderer  ports.InvoiceRenderer
+	mailer    ports.InvoiceMailer
+	publisher EventPublisher
+}
+
+func NewSendInvoiceHandler(
+	invoices domain.InvoiceRepository,
+	renderer ports.InvoiceRenderer,
+	mailer ports.InvoiceMailer,
+	publisher EventPublisher,
+) *SendInvoiceHandler {
+	if invoices == nil {
+		panic("nil InvoiceRepository")
+	}
+	if renderer == nil {
+		panic("nil InvoiceRenderer")
+	}
+	if mailer == nil {
+		panic("nil InvoiceMailer")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &SendInvoiceHandler{
+		invoices:  invoices,
+		renderer:  renderer,
+		mailer:    mailer,
+		publisher: publisher,
+	}
+}
+
+func (h *SendInvoiceHandler) Handle(ctx context.Context, cmd SendInvoiceCommand) (InvoiceResult, error) {
+	invoiceID, err := valueobjects.InvoiceIDFrom(cmd.InvoiceID)
+	if err != nil {
+		return InvoiceResult{}, domain.ErrInvoiceNotFound
+	}
+
+	invoice, err := h.invoices.FindByID(ctx, invoiceID)
+	if err != nil {
+		return InvoiceResult{}, domain.ErrInvoiceNotFound
+	}
+
+	to := cmd.To
+	if to == "" {
+		to = invoice.Customer().Email
+	}
+	if to == "" {
+		return InvoiceResult{}, ErrRecipientRequired
+	}
+
+	pdf, err := h.renderer.RenderPDF(invoice)
+	if err != nil {
+		return InvoiceResult{}, fmt.Errorf("failed to render invoice: %w", err)
+	}
+
+	subject := fmt.Sprintf("Invoice %s for %s", invoice.Number(), invoice.Period())
+	body := fmt.Sprintf("Dear %s,\n\nplease find attached invoice %s covering your purchases in %s.\n",
+		invoice.Customer().CompanyName, invoice.Number(), invoice.Period())
+	if err := h.mailer.SendInvoice(ctx, to, subject, body, invoice.Number()+".pdf", pdf); err != nil {
+		if errors.Is(err, ports.ErrMailerDisabled) {
+			return InvoiceResult{}, err
+		}
+		return InvoiceResult{}, fmt.Errorf("%w: %v", ErrDeliveryFailed, err)
+	}
+
+	invoice.MarkEmailed(to)
+	if err := h.invoices.Save(ctx, invoice); err != nil {
+		return InvoiceResult{}, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range invoice.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toInvoiceResult(invoice), nil
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvoiceNotFound        = errors.New("invoice not found")
+	ErrInvoiceAlreadyIssued   = errors.New("invoice already issued for this period")
+	ErrInvalidBillingPeriod   = errors.New("billing period must be in YYYY-MM format")
+	ErrBillingPeriodNotClosed = errors.New("billing period has not ended yet")
+	ErrInvalidBillingDetails  = errors.New("customer ID and company name are required")
+	ErrNoBillableTransactions = errors.New("no completed transactions in billing period")
+	ErrInvalidVATRate         = errors.New("VAT rate cannot be negative")
+	ErrMixedCurrencies        = errors.New("transactions in billing period use different currencies")
+)
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type InvoiceIssued struct {
+	events.BaseEvent
+	InvoiceID  valueobjects.InvoiceID
+	Number     string
+	CustomerID string
+	Gross      valueobjects.Money
+}
+
+func NewInvoiceIssued(invoiceID valueobjects.InvoiceID, number, customerID string, gross valueobjects.Money) InvoiceIssued {
+	return InvoiceIssued{
+		BaseEvent:  events.NewBaseEvent(),
+		InvoiceID:  invoiceID,
+		Number:     number,
+		CustomerID: customerID,
+		Gross:      gross,
+	}
+}
+
+func (InvoiceIssued) EventName() string { return "InvoiceIssued" }
+
+type InvoiceEmailed struct {
+	events.BaseEvent
+	InvoiceID valueobjects.InvoiceID
+	To        string
+}
+
+func NewInvoiceEmailed(invoiceID valueobjects.InvoiceID, to string) InvoiceEmailed {
+	return InvoiceEmailed{
+		BaseEvent: events.NewBaseEvent(),
+		InvoiceID: invoiceID,
+		To:        to,
+	}
+}
+
+func (InvoiceEmailed) EventName() string { return "InvoiceEmailed" }
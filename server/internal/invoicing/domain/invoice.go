@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// BillingPeriod is a value object for a calendar month
+type BillingPeriod struct {
+	year  int
+	month time.Month
+}
+
+// ParseBillingPeriod parses a period in YYYY-MM format
+func ParseBillingPeriod(raw string) (BillingPeriod, error) {
+	t, err := time.Parse("2006-01", raw)
+	if err != nil {
+		return BillingPeriod{}, ErrInvalidBillingPeriod
+	}
+	return BillingPeriod{year: t.Year(), month: t.Month()}, nil
+}
+
+func (p BillingPeriod) Year() int                   { return p.year }
+func (p BillingPeriod) Month() time.Month           { return p.month }
+func (p BillingPeriod) String() string              { return fmt.Sprintf("%04d-%02d", p.year, p.month) }
+func (p BillingPeriod) Start() time.Time            { return time.Date(p.year, p.month, 1, 0, 0, 0, 0, time.UTC) }
+func (p BillingPeriod) End() time.Time              { return p.Start().AddDate(0, 1, 0) }
+func (p BillingPeriod) IsClosed(now time.Time) bool { return !now.Before(p.End()) }
+
+// BillingDetails is a value object with the invoiced company's details
+type BillingDetails struct {
+	CustomerID  string // the corporate user ID sessions are recorded under
+	CompanyName string
+	VATID       string
+	Address     string
+	Email       string
+}
+
+// InvoiceLine is a single completed transaction on an invoice
+type InvoiceLine struct {
+	SessionID      string
+	Date           time.Time
+	Description    string
+	GrossCents     int64
+	FiscalDocument string // fiscal receipt number of the sale, where the country requires one
+}
+
+// Invoice is the aggregate root for a consolidated monthly B2B invoice.
+// Line prices are gross (consumer prices include VAT); net and VAT are derived.
+type Invoice struct {
+	id        valueobjects.InvoiceID
+	number    string
+	customer  BillingDetails
+	period    BillingPeriod
+	lines     []InvoiceLine
+	vatRateBP int // VAT rate in basis points, e.g. 1900 = 19%
+	net       valueobjects.Money
+	vat       valueobjects.Money
+	gross     valueobjects.Money
+	issuedAt  time.Time
+	emailedAt *time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// IssueInvoice creates a numbered invoice for a closed billing period
+func IssueInvoice(
+	number string,
+	customer BillingDetails,
+	period BillingPeriod,
+	lines []InvoiceLine,
+	vatRateBP int,
+	currency string,
+) (*Invoice, error) {
+	if customer.CustomerID == "" || customer.CompanyName == "" {
+		return nil, ErrInvalidBillingDetails
+	}
+	if len(lines) == 0 {
+		return nil, ErrNoBillableTransactions
+	}
+	if vatRateBP < 0 {
+		return nil, ErrInvalidVATRate
+	}
+
+	var grossCents int64
+	for _, line := range lines {
+		grossCents += line.GrossCents
+	}
+	// net = gross / (1 + rate), rounded half up
+	netCents := (grossCents*10000*2 + int64(10000+vatRateBP)) / (int64(10000+vatRateBP) * 2)
+
+	gross, err := valueobjects.NewMoney(grossCents, currency)
+	if err != nil {
+		return nil, err
+	}
+	net, _ := valueobjects.NewMoney(netCents, currency)
+	vat, _ := gross.Subtract(net)
+
+	inv := &Invoice{
+		id:        valueobjects.NewInvoiceID(),
+		number:    number,
+		customer:  customer,
+		period:    period,
+		lines:     lines,
+		vatRateBP: vatRateBP,
+		net:       net,
+		vat:       vat,
+		gross:     gross,
+		issuedAt:  time.Now().UTC(),
+	}
+
+	inv.domainEvents = append(inv.domainEvents, NewInvoiceIssued(inv.id, number, customer.CustomerID, gross))
+
+	return inv, nil
+}
+
+// Reconstitute rebuilds an Invoice from persistence
+func Reconstitute(
+	id valueobjects.InvoiceID,
+	number string,
+	customer BillingDetails,
+	period BillingPeriod,
+	lines []InvoiceLine,
+	vatRateBP int,
+	net, vat, gross valueobjects.Money,
+	issuedAt time.Time,
+	emailedAt *time.Time,
+) *Invoice {
+	return &Invoice{
+		id:        id,
+		number:    number,
+		customer:  customer,
+		period:    period,
+		lines:     lines,
+		vatRateBP: vatRateBP,
+		net:       net,
+		vat:       vat,
+		gross:     gross,
+		issuedAt:  issuedAt,
+		emailedAt: emailedAt,
+	}
+}
+
+// Getters
+func (i *Invoice) ID() valueobjects.InvoiceID { return i.id }
+func (i *Invoice) Number() string             { return i.number }
+func (i *Invoice) Customer() BillingDetails   { return i.customer }
+func (i *Invoice) Period() BillingPeriod      { return i.period }
+func (i *Invoice) Lines() []InvoiceLine       { return append([]InvoiceLine{}, i.lines...) }
+func (i *Invoice) VATRateBP() int             { return i.vatRateBP }
+func (i *Invoice) Net() valueobjects.Money    { return i.net }
+func (i *Invoice) VAT() valueobjects.Money    { return i.vat }
+func (i *Invoice) Gross() valueobjects.Money  { return i.gross }
+func (i *Invoice) IssuedAt() time.Time        { return i.issuedAt }
+func (i *Invoice) EmailedAt() *time.Time      { return i.emailedAt }
+
+// Business methods
+
+// MarkEmailed records delivery of the invoice PDF
+func (i *Invoice) MarkEmailed(to string) {
+	now := time.Now().UTC()
+	i.emailedAt = &now
+	i.domainEvents = append(i.domainEvents, NewInvoiceEmailed(i.id, to))
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (i *Invoice) PullEvents() []events.DomainEvent {
+	evts := i.domainEvents
+	i.domainEvents = nil
+	return evts
+}
+
+// FormatInvoiceNumber renders the sequential invoice number for a year
+func FormatInvoiceNumber(year int, seq int64) string {
+	return fmt.Sprintf("INV-%04d-%06d", year, seq)
+}
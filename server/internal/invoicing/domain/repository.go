@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// InvoiceRepository is the PORT interface defined by the domain
+type InvoiceRepository interface {
+	Save(ctx context.Context, invoice *Invoice) error
+	FindByID(ctx context.Context, id valueobjects.InvoiceID) (*Invoice, error)
+	FindByCustomerAndPeriod(ctx context.Context, customerID string, period BillingPeriod) (*Invoice, error)
+	FindByCustomer(ctx context.Context, customerID string) ([]*Invoice, error)
+	// NextNumber allocates the next gapless invoice sequence number for a year
+	NextNumber(ctx context.Context, year int) (int64, error)
+}
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/vending-machine/server/internal/invoicing/domain"
+)
+
+// SellerDetails identifies the operator issuing the invoices
+type SellerDetails struct {
+	Name    string
+	Address string
+	VATID   string
+}
+
+// PDFInvoiceRenderer implements ports.InvoiceRenderer with an A4 PDF layout
+type PDFInvoiceRenderer struct {
+	seller SellerDetails
+}
+
+func NewPDFInvoiceRenderer(seller SellerDetails) *PDFInvoiceRenderer {
+	return &PDFInvoiceRenderer{seller: seller}
+}
+
+func (r *PDFInvoiceRenderer) RenderPDF(inv *domain.Invoice) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+inv.Number(), true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// Core fonts are cp1252; translate UTF-8 input so umlauts etc. render
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Seller
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 7, tr(r.seller.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, 4.5, tr(r.seller.Address), "", "L", false)
+	if r.seller.VATID != "" {
+		pdf.CellFormat(0, 4.5, tr("VAT ID: "+r.seller.VATID), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Customer
+	customer := inv.Customer()
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 5, tr(customer.CompanyName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	if customer.Address != "" {
+		pdf.MultiCell(0, 4.5, tr(customer.Address), "", "L", false)
+	}
+	if customer.VATID != "" {
+		pdf.CellFormat(0, 4.5, tr("VAT ID: "+customer.VATID), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, "Invoice "+inv.Number(), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 4.5, "Invoice date: "+inv.IssuedAt().Format("2006-01-02"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 4.5, "Billing period: "+inv.Period().String(), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	// Lines
+	currency := inv.Gross().Currency()
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(25, 6, "Date", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(75, 6, "Description", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 6, "Reference", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(30, 6, "Amount ("+currency+")", "B", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range inv.Lines() {
+		pdf.CellFormat(25, 5.5, line.Date.Format("2006-01-02"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(75, 5.5, tr(line.Description), "", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 5.5, lineRef(line), "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 5.5, formatAmount(line.GrossCents), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Totals
+	vatRate := fmt.Sprintf("VAT %d.%02d%%", inv.VATRateBP()/100, inv.VATRateBP()%100)
+	totals := []struct {
+		label string
+		cents int64
+		bold  bool
+	}{
+		{"Net amount", inv.Net().Amount(), false},
+		{vatRate, inv.VAT().Amount(), false},
+		{"Total", inv.Gross().Amount(), true},
+	}
+	for _, t := range totals {
+		style := ""
+		if t.bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		pdf.CellFormat(140, 5.5, t.label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 5.5, formatAmount(t.cents)+" "+currency, "", 1, "R", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatAmount(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// lineRef is the fiscal receipt number of a line's sale, or the session
+// where the sale needed none
+func lineRef(line domain.InvoiceLine) string {
+	if line.FiscalDocument != "" {
+		return line.FiscalDocument
+	}
+	return shortRef(line.SessionID)
+}
+
+// shortRef shortens a session UUID for display
+func shortRef(sessionID string) string {
+	if len(sessionID) > 8 {
+		return sessionID[:8]
+	}
+	return sessionID
+}
@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+)
+
+// SMTPConfig configures outgoing invoice email
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPInvoiceMailer implements ports.InvoiceMailer over SMTP (STARTTLS when offered)
+type SMTPInvoiceMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPInvoiceMailer(cfg SMTPConfig) *SMTPInvoiceMailer {
+	if cfg.Host == "" || cfg.From == "" {
+		panic("incomplete SMTP configuration")
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &SMTPInvoiceMailer{cfg: cfg}
+}
+
+func (m *SMTPInvoiceMailer) SendInvoice(ctx context.Context, to, subject, body, filename string, pdf []byte) error {
+	// Reject header injection through the recipient address
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+
+	msg := m.buildMessage(to, subject, body, filename, pdf)
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{to}, msg)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *SMTPInvoiceMailer) buildMessage(to, subject, body, filename string, pdf []byte) []byte {
+	boundary := "invoice-" + uuid.NewString()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	fmt.Fprintf(&b, "Content-Type: application/pdf; name=%q\r\n", filename)
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n\r\n", filename)
+
+	encoded := base64.StdEncoding.EncodeToString(pdf)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+
+	return b.Bytes()
+}
+
+// DisabledInvoiceMailer is used when no SMTP server is configured
+type DisabledInvoiceMailer struct{}
+
+func NewDisabledInvoiceMailer() *DisabledInvoiceMailer {
+	return &DisabledInvoiceMailer{}
+}
+
+func (DisabledInvoiceMailer) SendInvoice(ctx context.Context, to, subject, body, filename string, pdf []byte) error {
+	return ports.ErrMailerDisabled
+}
@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+	transactionapi "github.com/vending-machine/server/internal/transaction/api"
+)
+
+// TransactionAdapter implements ports.TransactionSource using the transaction context API
+type TransactionAdapter struct {
+	reader transactionapi.SessionReader
+}
+
+func NewTransactionAdapter(reader transactionapi.SessionReader) *TransactionAdapter {
+	if reader == nil {
+		panic("nil SessionReader")
+	}
+	return &TransactionAdapter{reader: reader}
+}
+
+func (a *TransactionAdapter) CompletedByCustomer(ctx context.Context, customerID string, from, to time.Time) ([]ports.CompletedTransaction, error) {
+	views, err := a.reader.FindCompletedByUser(ctx, customerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]ports.CompletedTransaction, 0, len(views))
+	for _, view := range views {
+		tx := ports.CompletedTransaction{
+			SessionID:      view.ID,
+			DeviceID:       view.DeviceID,
+			TotalCents:     view.TotalCents,
+			Currency:       view.Currency,
+			FiscalDocument: view.FiscalDocument,
+		}
+		for _, item := range view.Items {
+			tx.ItemCount += item.Quantity
+		}
+		if view.CompletedAt != nil {
+			tx.CompletedAt = *view.CompletedAt
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
@@ -0,0 +1,256 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/invoicing/app"
+	"github.com/vending-machine/server/internal/invoicing/app/ports"
+	"github.com/vending-machine/server/internal/invoicing/domain"
+)
+
+type HTTPHandler struct {
+	generateHandler *app.GenerateInvoiceHandler
+	sendHandler     *app.SendInvoiceHandler
+	queryService    *app.InvoiceQueryService
+}
+
+func NewHTTPHandler(
+	generateHandler *app.GenerateInvoiceHandler,
+	sendHandler *app.SendInvoiceHandler,
+	queryService *app.InvoiceQueryService,
+) *HTTPHandler {
+	return &HTTPHandler{
+		generateHandler: generateHandler,
+		sendHandler:     sendHandler,
+		queryService:    queryService,
+	}
+}
+
+// Request/Response DTOs (HTTP layer only)
+
+type generateInvoiceRequest struct {
+	CustomerID  string `json:"customer_id" binding:"required"`
+	Period      string `json:"period" binding:"required"`
+	CompanyName string `json:"company_name" binding:"required"`
+	VATID       string `json:"vat_id"`
+	Address     string `json:"address"`
+	Email       string `json:"email"`
+	SendEmail   bool   `json:"send_email"`
+}
+
+type sendInvoiceRequest struct {
+	To string `json:"to"`
+}
+
+type invoiceResultResponse struct {
+	InvoiceID  string `json:"invoice_id"`
+	Number     string `json:"number"`
+	Period     string `json:"period"`
+	NetCents   int64  `json:"net_cents"`
+	VATCents   int64  `json:"vat_cents"`
+	GrossCents int64  `json:"gross_cents"`
+	Currency   string `json:"currency"`
+	LineCount  int    `json:"line_count"`
+}
+
+type invoiceResponse struct {
+	ID          string                `json:"id"`
+	Number      string                `json:"number"`
+	CustomerID  string                `json:"customer_id"`
+	CompanyName string                `json:"company_name"`
+	VATID       string                `json:"vat_id,omitempty"`
+	Address     string                `json:"address,omitempty"`
+	Email       string                `json:"email,omitempty"`
+	Period      string                `json:"period"`
+	Lines       []invoiceLineResponse `json:"lines"`
+	VATRateBP   int                   `json:"vat_rate_bp"`
+	NetCents    int64                 `json:"net_cents"`
+	VATCents    int64                 `json:"vat_cents"`
+	GrossCents  int64                 `json:"gross_cents"`
+	Currency    string                `json:"currency"`
+	IssuedAt    string                `json:"issued_at"`
+	EmailedAt   *string               `json:"emailed_at,omitempty"`
+}
+
+type invoiceLineResponse struct {
+	SessionID      string `json:"session_id"`
+	Date           string `json:"date"`
+	Description    string `json:"description"`
+	GrossCents     int64  `json:"gross_cents"`
+	FiscalDocument string `json:"fiscal_document,omitempty"`
+}
+
+// Handlers
+
+func (h *HTTPHandler) Generate(c *gin.Context) {
+	var req generateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.GenerateInvoiceCommand{
+		CustomerID:  req.CustomerID,
+		Period:      req.Period,
+		CompanyName: req.CompanyName,
+		VATID:       req.VATID,
+		Address:     req.Address,
+		Email:       req.Email,
+	}
+
+	result, err := h.generateHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	// Delivery failure must not hide the issued invoice; the caller can retry via /send
+	if req.SendEmail {
+		sent, err := h.sendHandler.Handle(c.Request.Context(), app.SendInvoiceCommand{InvoiceID: result.InvoiceID})
+		if err != nil {
+			c.JSON(http.StatusCreated, gin.H{
+				"invoice":        toInvoiceResultResponse(result),
+				"delivery_error": err.Error(),
+			})
+			return
+		}
+		result = sent
+	}
+
+	c.JSON(http.StatusCreated, toInvoiceResultResponse(result))
+}
+
+func (h *HTTPHandler) Send(c *gin.Context) {
+	var req sendInvoiceRequest
+	// Body is optional; an empty body sends to the billing address on file
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.sendHandler.Handle(c.Request.Context(), app.SendInvoiceCommand{
+		InvoiceID: c.Param("id"),
+		To:        req.To,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInvoiceResultResponse(result))
+}
+
+func (h *HTTPHandler) Get(c *gin.Context) {
+	view, err := h.queryService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInvoiceResponse(view))
+}
+
+func (h *HTTPHandler) List(c *gin.Context) {
+	customerID := c.Query("customer_id")
+	if customerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "customer_id is required"})
+		return
+	}
+
+	views, err := h.queryService.FindByCustomer(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]invoiceResponse, 0, len(views))
+	for _, view := range views {
+		response = append(response, toInvoiceResponse(view))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) DownloadPDF(c *gin.Context) {
+	pdf, number, err := h.queryService.RenderPDF(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", number+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (h *HTTPHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvoiceAlreadyIssued):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidBillingPeriod),
+		errors.Is(err, domain.ErrInvalidBillingDetails),
+		errors.Is(err, app.ErrRecipientRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBillingPeriodNotClosed),
+		errors.Is(err, domain.ErrNoBillableTransactions),
+		errors.Is(err, domain.ErrMixedCurrencies):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, ports.ErrMailerDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, app.ErrDeliveryFailed):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toInvoiceResultResponse(r app.InvoiceResult) invoiceResultResponse {
+	return invoiceResultResponse{
+		InvoiceID:  r.InvoiceID,
+		Number:     r.Number,
+		Period:     r.Period,
+		NetCents:   r.NetCents,
+		VATCents:   r.VATCents,
+		GrossCents: r.GrossCents,
+		Currency:   r.Currency,
+		LineCount:  r.LineCount,
+	}
+}
+
+func toInvoiceResponse(v *app.InvoiceView) invoiceResponse {
+	lines := make([]invoiceLineResponse, 0, len(v.Lines))
+	for _, line := range v.Lines {
+		lines = append(lines, invoiceLineResponse{
+			SessionID:      line.SessionID,
+			Date:           line.Date,
+			Description:    line.Description,
+			GrossCents:     line.GrossCents,
+			FiscalDocument: line.FiscalDocument,
+		})
+	}
+
+	return invoiceResponse{
+		ID:          v.ID,
+		Number:      v.Number,
+		CustomerID:  v.CustomerID,
+		CompanyName: v.CompanyName,
+		VATID:       v.VATID,
+		Address:     v.Address,
+		Email:       v.Email,
+		Period:      v.Period,
+		Lines:       lines,
+		VATRateBP:   v.VATRateBP,
+		NetCents:    v.NetCents,
+		VATCents:    v.VATCents,
+		GrossCents:  v.GrossCents,
+		Currency:    v.Currency,
+		IssuedAt:    v.IssuedAt,
+		EmailedAt:   v.EmailedAt,
+	}
+}
@@ -0,0 +1,201 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/invoicing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresInvoiceRepository implements domain.InvoiceRepository
+type PostgresInvoiceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresInvoiceRepository(pool *pgxpool.Pool) *PostgresInvoiceRepository {
+	return &PostgresInvoiceRepository{pool: pool}
+}
+
+// invoiceRow is a DB-layer struct (never leaves this file)
+type invoiceRow struct {
+	ID          string
+	Number      string
+	CustomerID  string
+	CompanyName string
+	VATID       *string
+	Address     *string
+	Email       *string
+	Period      string
+	Lines       []byte
+	VATRateBP   int
+	NetCents    int64
+	VATCents    int64
+	GrossCents  int64
+	Currency    string
+	IssuedAt    time.Time
+	EmailedAt   *time.Time
+}
+
+type invoiceLineJSON struct {
+	SessionID      string    `json:"session_id"`
+	Date           time.Time `json:"date"`
+	Description    string    `json:"description"`
+	GrossCents     int64     `json:"gross_cents"`
+	FiscalDocument string    `json:"fiscal_document,omitempty"`
+}
+
+const invoiceColumns = `id, number, customer_id, company_name, vat_id, address, email, period, lines,
+	vat_rate_bp, net_cents, vat_cents, gross_cents, currency, issued_at, emailed_at`
+
+func (r *PostgresInvoiceRepository) Save(ctx context.Context, inv *domain.Invoice) error {
+	var lines []invoiceLineJSON
+	for _, line := range inv.Lines() {
+		lines = append(lines, invoiceLineJSON{
+			SessionID:      line.SessionID,
+			Date:           line.Date,
+			Description:    line.Description,
+			GrossCents:     line.GrossCents,
+			FiscalDocument: line.FiscalDocument,
+		})
+	}
+	linesData, _ := json.Marshal(lines)
+
+	customer := inv.Customer()
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO invoices (`+invoiceColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (id) DO UPDATE SET
+			emailed_at = EXCLUDED.emailed_at
+	`, inv.ID().String(), inv.Number(), customer.CustomerID, customer.CompanyName,
+		customer.VATID, customer.Address, customer.Email, inv.Period().String(), linesData,
+		inv.VATRateBP(), inv.Net().Amount(), inv.VAT().Amount(), inv.Gross().Amount(), inv.Gross().Currency(),
+		inv.IssuedAt(), inv.EmailedAt())
+
+	// A concurrent request already issued this customer's invoice for the period
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrInvoiceAlreadyIssued
+	}
+	return err
+}
+
+func (r *PostgresInvoiceRepository) FindByID(ctx context.Context, id valueobjects.InvoiceID) (*domain.Invoice, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id.String())
+	return r.scanInvoice(row)
+}
+
+func (r *PostgresInvoiceRepository) FindByCustomerAndPeriod(ctx context.Context, customerID string, period domain.BillingPeriod) (*domain.Invoice, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices WHERE customer_id = $1 AND period = $2
+	`, customerID, period.String())
+	return r.scanInvoice(row)
+}
+
+func (r *PostgresInvoiceRepository) FindByCustomer(ctx context.Context, customerID string) ([]*domain.Invoice, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices WHERE customer_id = $1
+		ORDER BY period DESC
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for rows.Next() {
+		inv, err := r.scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// NextNumber increments the per-year counter atomically; the row lock keeps
+// concurrent issuers from receiving the same number
+func (r *PostgresInvoiceRepository) NextNumber(ctx context.Context, year int) (int64, error) {
+	var next int64
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO invoice_counters (year, last_number) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number
+	`, year).Scan(&next)
+	return next, err
+}
+
+func (r *PostgresInvoiceRepository) scanInvoice(row pgx.Row) (*domain.Invoice, error) {
+	var rec invoiceRow
+	err := row.Scan(
+		&rec.ID, &rec.Number, &rec.CustomerID, &rec.CompanyName, &rec.VATID, &rec.Address, &rec.Email,
+		&rec.Period, &rec.Lines, &rec.VATRateBP, &rec.NetCents, &rec.VATCents, &rec.GrossCents,
+		&rec.Currency, &rec.IssuedAt, &rec.EmailedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return r.reconstitute(rec), nil
+}
+
+func (r *PostgresInvoiceRepository) reconstitute(rec invoiceRow) *domain.Invoice {
+	id, _ := valueobjects.InvoiceIDFrom(rec.ID)
+	period, _ := domain.ParseBillingPeriod(rec.Period)
+
+	var linesJSON []invoiceLineJSON
+	_ = json.Unmarshal(rec.Lines, &linesJSON)
+
+	lines := make([]domain.InvoiceLine, 0, len(linesJSON))
+	for _, line := range linesJSON {
+		lines = append(lines, domain.InvoiceLine{
+			SessionID:      line.SessionID,
+			Date:           line.Date,
+			Description:    line.Description,
+			GrossCents:     line.GrossCents,
+			FiscalDocument: line.FiscalDocument,
+		})
+	}
+
+	net, _ := valueobjects.NewMoney(rec.NetCents, rec.Currency)
+	vat, _ := valueobjects.NewMoney(rec.VATCents, rec.Currency)
+	gross, _ := valueobjects.NewMoney(rec.GrossCents, rec.Currency)
+
+	customer := domain.BillingDetails{
+		CustomerID:  rec.CustomerID,
+		CompanyName: rec.CompanyName,
+		VATID:       deref(rec.VATID),
+		Address:     deref(rec.Address),
+		Email:       deref(rec.Email),
+	}
+
+	return domain.Reconstitute(
+		id,
+		rec.Number,
+		customer,
+		period,
+		lines,
+		rec.VATRateBP,
+		net, vat, gross,
+		rec.IssuedAt,
+		rec.EmailedAt,
+	)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
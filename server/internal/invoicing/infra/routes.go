@@ -0,0 +1,15 @@
+package infra
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes registers the invoicing context routes
+func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	invoices := rg.Group("/invoices")
+	{
+		invoices.POST("", h.Generate)
+		invoices.GET("", h.List)
+		invoices.GET("/:id", h.Get)
+		invoices.GET("/:id/pdf", h.DownloadPDF)
+		invoices.POST("/:id/send", h.Send)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds connecting, each command and the wait for a pooled
+// connection, so a stalled Redis slows requests down instead of blocking them
+const redisTimeout = 500 * time.Millisecond
+
+// RedisStore is a Store in Redis, so server instances share cached entries
+// and an entry dropped by one instance is gone for all of them. Commands run
+// on a pool of connections, so concurrent lookups do not wait on each other.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a store for a redis://[user:password@]host[:port][/db]
+// URL. It connects on first use.
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL %q: %w", rawURL, err)
+	}
+	if opts.Password == "" && opts.Username != "" {
+		// redis://secret@host is a password without a user
+		opts.Username, opts.Password = "", opts.Username
+	}
+	opts.DialTimeout = redisTimeout
+	opts.ReadTimeout = redisTimeout
+	opts.WriteTimeout = redisTimeout
+	opts.PoolTimeout = redisTimeout
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis GET: %w", err)
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// A zero TTL would keep the entry forever
+	if err := s.client.Set(ctx, key, value, max(ttl, time.Millisecond)).Err(); err != nil {
+		return fmt.Errorf("redis SET: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis DEL: %w", err)
+	}
+	return nil
+}
+
+// Close closes the pooled connections
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
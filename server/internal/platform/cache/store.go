@@ -0,0 +1,84 @@
+// Package cache provides key-value stores with expiring entries for
+// read-through caches: an in-process store and a Redis store shared by every
+// server instance.
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps values until their TTL runs out. A cache must treat a store
+// error as a miss and fall back to the source of truth.
+type Store interface {
+	// Get returns the value and whether the key was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store in this process's memory. Expired entries are
+// dropped as they are read or overwritten, and in one sweep whenever the
+// store has doubled since the last one.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	sweepSize int
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]memoryEntry),
+		sweepSize: 1024,
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.entries[key] = memoryEntry{value: append([]byte(nil), value...), expiresAt: now.Add(ttl)}
+	if len(s.entries) >= s.sweepSize {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweepSize = max(1024, 2*len(s.entries))
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
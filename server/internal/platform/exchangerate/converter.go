@@ -0,0 +1,135 @@
+// Package exchangerate implements the currency.Converter port: from an
+// exchange-rate API, from fixed rates, or not at all.
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/currency"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// APIConverter converts with the latest rates of an exchange-rate API that
+// answers GET <endpoint>/latest/<base> with {"base_code": ..., "rates": {...}},
+// as open.er-api.com and its self-hosted mirrors do. Rates are fetched at
+// most once per TTL; when a refresh fails the last rates keep being used.
+type APIConverter struct {
+	endpoint   string
+	base       string
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	rates     currency.Rates
+	fetchedAt time.Time
+}
+
+func NewAPIConverter(endpoint, base string, ttl time.Duration) *APIConverter {
+	if endpoint == "" {
+		panic("empty exchange rate API endpoint")
+	}
+	return &APIConverter{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		base:       strings.ToUpper(base),
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type latestRatesResponse struct {
+	BaseCode string             `json:"base_code"`
+	Rates    map[string]float64 `json:"rates"`
+}
+
+func (c *APIConverter) Convert(ctx context.Context, amount valueobjects.Money, to string) (valueobjects.Money, error) {
+	if strings.EqualFold(amount.Currency(), to) {
+		return amount, nil
+	}
+	rates, err := c.latest(ctx)
+	if err != nil {
+		return valueobjects.Money{}, err
+	}
+	return rates.Convert(amount, to)
+}
+
+func (c *APIConverter) latest(ctx context.Context) (currency.Rates, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
+		return c.rates, nil
+	}
+	rates, err := c.fetch(ctx)
+	if err != nil {
+		if !c.fetchedAt.IsZero() {
+			return c.rates, nil
+		}
+		return currency.Rates{}, fmt.Errorf("%w: %v", currency.ErrRateUnavailable, err)
+	}
+	c.rates, c.fetchedAt = rates, time.Now()
+	return rates, nil
+}
+
+func (c *APIConverter) fetch(ctx context.Context) (currency.Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/latest/"+c.base, nil)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return currency.Rates{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return currency.Rates{}, fmt.Errorf("exchange rate API returned status %d", resp.StatusCode)
+	}
+
+	var latest latestRatesResponse
+	if err := json.Unmarshal(body, &latest); err != nil {
+		return currency.Rates{}, fmt.Errorf("invalid exchange rate response: %w", err)
+	}
+	if !strings.EqualFold(latest.BaseCode, c.base) || len(latest.Rates) == 0 {
+		return currency.Rates{}, fmt.Errorf("exchange rate API returned no rates for %s", c.base)
+	}
+	return currency.Rates{Base: c.base, PerBase: latest.Rates}, nil
+}
+
+// StaticConverter converts with fixed rates
+type StaticConverter struct {
+	rates currency.Rates
+}
+
+func NewStaticConverter(rates currency.Rates) *StaticConverter {
+	return &StaticConverter{rates: rates}
+}
+
+func (c *StaticConverter) Convert(ctx context.Context, amount valueobjects.Money, to string) (valueobjects.Money, error) {
+	return c.rates.Convert(amount, to)
+}
+
+// DisabledConverter is used when no exchange rates are configured; only
+// amounts already in the target currency convert
+type DisabledConverter struct{}
+
+func NewDisabledConverter() *DisabledConverter {
+	return &DisabledConverter{}
+}
+
+func (DisabledConverter) Convert(ctx context.Context, amount valueobjects.Money, to string) (valueobjects.Money, error) {
+	if strings.EqualFold(amount.Currency(), to) {
+		return amount, nil
+	}
+	return valueobjects.Money{}, currency.ErrRateUnavailable
+}
@@ -3,8 +3,14 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/platform/region"
+	"github.com/vending-machine/server/internal/platform/status"
+
 	cataloginfra "github.com/vending-machine/server/internal/catalog/infra"
 	deviceinfra "github.com/vending-machine/server/internal/device/infra"
+	invoicinginfra "github.com/vending-machine/server/internal/invoicing/infra"
+	pricinginfra "github.com/vending-machine/server/internal/pricing/infra"
 	transactioninfra "github.com/vending-machine/server/internal/transaction/infra"
 )
 
@@ -13,6 +19,11 @@ type Router struct {
 	catalogHandler     *cataloginfra.HTTPHandler
 	deviceHandler      *deviceinfra.HTTPHandler
 	transactionHandler *transactioninfra.HTTPHandler
+	invoicingHandler   *invoicinginfra.HTTPHandler
+	pricingHandler     *pricinginfra.HTTPHandler
+	statusHandler      *status.HTTPHandler
+	eventsHandler      *messaging.HTTPHandler
+	region             region.Config
 }
 
 // NewRouter creates a new router that composes all context handlers
@@ -20,11 +31,21 @@ func NewRouter(
 	catalogHandler *cataloginfra.HTTPHandler,
 	deviceHandler *deviceinfra.HTTPHandler,
 	transactionHandler *transactioninfra.HTTPHandler,
+	invoicingHandler *invoicinginfra.HTTPHandler,
+	pricingHandler *pricinginfra.HTTPHandler,
+	statusHandler *status.HTTPHandler,
+	eventsHandler *messaging.HTTPHandler,
+	regionConfig region.Config,
 ) *Router {
 	return &Router{
 		catalogHandler:     catalogHandler,
 		deviceHandler:      deviceHandler,
 		transactionHandler: transactionHandler,
+		invoicingHandler:   invoicingHandler,
+		pricingHandler:     pricingHandler,
+		statusHandler:      statusHandler,
+		eventsHandler:      eventsHandler,
+		region:             regionConfig,
 	}
 }
 
@@ -32,9 +53,16 @@ func NewRouter(
 func (r *Router) Engine() *gin.Engine {
 	engine := gin.Default()
 
+	// Data residency: requests tagged for another region get a routing hint
+	engine.Use(r.region.Middleware())
+
 	// Health check
 	engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+		response := gin.H{"status": "ok"}
+		if r.region.Current != "" {
+			response["region"] = r.region.Current
+		}
+		c.JSON(200, response)
 	})
 
 	// API v1
@@ -44,6 +72,10 @@ func (r *Router) Engine() *gin.Engine {
 		r.catalogHandler.RegisterRoutes(v1)
 		r.deviceHandler.RegisterRoutes(v1)
 		r.transactionHandler.RegisterRoutes(v1)
+		r.invoicingHandler.RegisterRoutes(v1)
+		r.pricingHandler.RegisterRoutes(v1)
+		r.statusHandler.RegisterRoutes(v1)
+		r.eventsHandler.RegisterRoutes(v1)
 	}
 
 	return engine
@@ -0,0 +1,72 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// Publisher is the event publishing contract shared by every bounded context
+type Publisher interface {
+	Publish(ctx context.Context, event events.DomainEvent) error
+}
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before further events are dropped for it
+const subscriberBuffer = 64
+
+// InProcessBroker fans domain events out to subscribers in this process and
+// forwards them to the next publisher. Delivery is best effort: subscribers
+// that fall behind lose events, and other server instances never see them,
+// so subscribers must be able to recover by re-reading state.
+type InProcessBroker struct {
+	next Publisher
+
+	mu          sync.RWMutex
+	subscribers map[chan events.DomainEvent]struct{}
+}
+
+func NewInProcessBroker(next Publisher) *InProcessBroker {
+	if next == nil {
+		panic("nil Publisher")
+	}
+	return &InProcessBroker{
+		next:        next,
+		subscribers: make(map[chan events.DomainEvent]struct{}),
+	}
+}
+
+// Publish delivers the event to every subscriber without blocking, then forwards it
+func (b *InProcessBroker) Publish(ctx context.Context, event events.DomainEvent) error {
+	b.mu.RLock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	b.mu.RUnlock()
+
+	return b.next.Publish(ctx, event)
+}
+
+// Subscribe returns a channel receiving every event published from now on.
+// The returned function unsubscribes and closes the channel.
+func (b *InProcessBroker) Subscribe() (<-chan events.DomainEvent, func()) {
+	ch := make(chan events.DomainEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
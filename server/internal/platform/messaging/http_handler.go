@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReadLimit = 100
+	maxReadLimit     = 1000
+)
+
+type messageResponse struct {
+	Offset     int64           `json:"offset"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Key        string          `json:"key"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type topicResponse struct {
+	Topic    string            `json:"topic"`
+	Messages []messageResponse `json:"messages"`
+	// NextOffset is the after= of the next read; it stays put when the
+	// subscriber has caught up
+	NextOffset int64 `json:"next_offset"`
+}
+
+// HTTPHandler lets subscribers outside this process read the published topics
+type HTTPHandler struct {
+	topic  Topic
+	topics map[string]bool
+}
+
+func NewHTTPHandler(topic Topic, topics ...string) *HTTPHandler {
+	if topic == nil {
+		panic("nil Topic")
+	}
+	known := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		known[t] = true
+	}
+	return &HTTPHandler{topic: topic, topics: known}
+}
+
+// RegisterRoutes registers the topic routes on the given router group
+func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/events/:topic", h.Read)
+}
+
+// Read returns the messages after ?after= (0 reads from the start), up to
+// ?limit= of them
+func (h *HTTPHandler) Read(c *gin.Context) {
+	topic := c.Param("topic")
+	if !h.topics[topic] {
+		h.writeError(c, ErrTopicNotFound)
+		return
+	}
+
+	after, err := strconv.ParseInt(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil || after < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a non-negative offset"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultReadLimit)))
+	if err != nil || limit < 1 || limit > maxReadLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxReadLimit)})
+		return
+	}
+
+	messages, err := h.topic.Read(c.Request.Context(), topic, after, limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := topicResponse{Topic: topic, Messages: make([]messageResponse, 0, len(messages)), NextOffset: after}
+	for _, m := range messages {
+		response.Messages = append(response.Messages, messageResponse{
+			Offset:     m.Offset,
+			Type:       m.Type,
+			Version:    m.Version,
+			Key:        m.Key,
+			OccurredAt: m.OccurredAt,
+			Payload:    m.Payload,
+		})
+		response.NextOffset = m.Offset
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,42 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// Outbox appends the events its encoders know to their topic as part of the
+// change that raised them: staged in the unit of work that saves the change,
+// a message is on the topic exactly when the change is. Unlike
+// TopicPublisher it does not deliver the events in this process; they are
+// published after the unit of work commits.
+type Outbox struct {
+	topic    Topic
+	encoders []Encoder
+}
+
+func NewOutbox(topic Topic, encoders ...Encoder) *Outbox {
+	if topic == nil {
+		panic("nil Topic")
+	}
+	return &Outbox{topic: topic, encoders: encoders}
+}
+
+// Stage appends the messages of the events; a failed append fails the unit of work
+func (o *Outbox) Stage(ctx context.Context, evts []events.DomainEvent) error {
+	for _, event := range evts {
+		for _, encode := range o.encoders {
+			msg, ok := encode(event)
+			if !ok {
+				continue
+			}
+			if err := o.topic.Append(ctx, msg); err != nil {
+				return fmt.Errorf("failed to stage %s on %s: %w", msg.Type, msg.Topic, err)
+			}
+			break
+		}
+	}
+	return nil
+}
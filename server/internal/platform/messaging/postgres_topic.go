@@ -0,0 +1,56 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/platform/postgres"
+)
+
+// PostgresTopic implements Topic on one table shared by all topics; offsets
+// increase across topics, so a topic's offsets have gaps. Appends take part
+// in the unit of work running in their context.
+type PostgresTopic struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTopic(pool *pgxpool.Pool) *PostgresTopic {
+	return &PostgresTopic{pool: pool}
+}
+
+func (t *PostgresTopic) Append(ctx context.Context, msg Message) error {
+	_, err := postgres.Conn(ctx, t.pool).Exec(ctx, `
+		INSERT INTO event_messages (topic, type, version, key, occurred_at, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, msg.Topic, msg.Type, msg.Version, msg.Key, msg.OccurredAt, []byte(msg.Payload))
+	return err
+}
+
+func (t *PostgresTopic) Read(ctx context.Context, topic string, after int64, limit int) ([]Message, error) {
+	rows, err := t.pool.Query(ctx, `
+		SELECT "offset", topic, type, version, key, occurred_at, payload
+		FROM event_messages
+		WHERE topic = $1 AND "offset" > $2
+		ORDER BY "offset"
+		LIMIT $3
+	`, topic, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []Message{}
+	for rows.Next() {
+		var (
+			m       Message
+			payload []byte
+		)
+		if err := rows.Scan(&m.Offset, &m.Topic, &m.Type, &m.Version, &m.Key, &m.OccurredAt, &payload); err != nil {
+			return nil, err
+		}
+		m.Payload = payload
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,71 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// ErrTopicNotFound is returned when reading a topic nothing is published on
+var ErrTopicNotFound = errors.New("topic not found")
+
+// Message is a domain event in its wire format, as kept on a topic
+type Message struct {
+	Offset     int64 // position on the topic, assigned when appended
+	Topic      string
+	Type       string // e.g. catalog.sku.created
+	Version    int    // schema version of the payload of the type
+	Key        string // the aggregate the event is about
+	OccurredAt time.Time
+	Payload    json.RawMessage
+}
+
+// Encoder turns the events of one bounded context into messages. It reports
+// false for events that are not published.
+type Encoder func(event events.DomainEvent) (Message, bool)
+
+// Topic is an append-only log of messages that subscribers read from the
+// offset they stopped at
+type Topic interface {
+	Append(ctx context.Context, msg Message) error
+	// Read returns up to limit messages after the offset, oldest first
+	Read(ctx context.Context, topic string, after int64, limit int) ([]Message, error)
+}
+
+// TopicPublisher appends the events its encoders know to their topic, so
+// services outside this process can subscribe to them, and forwards every
+// event to the next publisher. Messages are appended after the change that
+// raised them is saved; a failed append is logged and the event is lost.
+type TopicPublisher struct {
+	next     Publisher
+	topic    Topic
+	encoders []Encoder
+}
+
+func NewTopicPublisher(next Publisher, topic Topic, encoders ...Encoder) *TopicPublisher {
+	if next == nil {
+		panic("nil Publisher")
+	}
+	if topic == nil {
+		panic("nil Topic")
+	}
+	return &TopicPublisher{next: next, topic: topic, encoders: encoders}
+}
+
+func (p *TopicPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	for _, encode := range p.encoders {
+		msg, ok := encode(event)
+		if !ok {
+			continue
+		}
+		if err := p.topic.Append(ctx, msg); err != nil {
+			logger.Error("Failed to append event to topic", "topic", msg.Topic, "type", msg.Type, "error", err)
+		}
+		break
+	}
+	return p.next.Publish(ctx, event)
+}
@@ -0,0 +1,109 @@
+// Package objectstore keeps binary objects such as images outside the
+// database: in a local directory for single-binary deployments, or in an
+// S3-compatible bucket.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Store holds objects under slash-separated keys
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get returns the object and its content type
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete removes the object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey accepts relative slash-separated keys without . or .. segments,
+// so no key can leave the store
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && key != "." && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+// FileStore is a Store in a local directory. The content type is derived
+// from the key's extension.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) *FileStore {
+	if dir == "" {
+		panic("empty object store directory")
+	}
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write aside and rename, so readers never see half an object
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	if !validKey(key) {
+		return nil, "", ErrInvalidKey
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return data, contentType, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+
+	"github.com/vending-machine/server/internal/pkg/logger"
+)
+
+// EmbeddedConfig holds settings for running PostgreSQL inside the server process
+type EmbeddedConfig struct {
+	DataDir  string // persistent data directory (survives restarts)
+	Port     uint32
+	Database string
+	Username string
+	Password string
+}
+
+// DefaultEmbeddedConfig returns the embedded database configuration used by kiosk deployments
+func DefaultEmbeddedConfig() EmbeddedConfig {
+	return EmbeddedConfig{
+		DataDir:  "data/postgres",
+		Port:     5433,
+		Database: "vending",
+		Username: "vending",
+		Password: "vending",
+	}
+}
+
+// EmbeddedServer is a PostgreSQL instance started and owned by the server process.
+// It lets a single binary run fully self-contained at the edge while reusing the
+// regular Postgres repositories and migrations.
+type EmbeddedServer struct {
+	db  *embeddedpostgres.EmbeddedPostgres
+	url string
+}
+
+// StartEmbedded starts an embedded PostgreSQL instance, initialising the data
+// directory on first run and reusing it afterwards
+func StartEmbedded(cfg EmbeddedConfig) (*EmbeddedServer, error) {
+	dataDir, runtimeDir, err := cfg.paths()
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded data directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dataDir), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create embedded data directory: %w", err)
+	}
+
+	pgCfg := embeddedpostgres.DefaultConfig().
+		Port(cfg.Port).
+		Database(cfg.Database).
+		Username(cfg.Username).
+		Password(cfg.Password).
+		DataPath(dataDir).
+		RuntimePath(runtimeDir).
+		Logger(os.Stderr)
+
+	db := embeddedpostgres.NewDatabase(pgCfg)
+	if err := db.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start embedded postgres: %w", err)
+	}
+
+	logger.Info("Embedded postgres started", "port", cfg.Port, "data_dir", dataDir)
+
+	return &EmbeddedServer{db: db, url: cfg.url()}, nil
+}
+
+// paths resolves the data directory and the runtime directory next to it
+func (cfg EmbeddedConfig) paths() (dataDir, runtimeDir string, err error) {
+	dataDir, err = filepath.Abs(cfg.DataDir)
+	if err != nil {
+		return "", "", err
+	}
+	return dataDir, filepath.Join(filepath.Dir(dataDir), "runtime"), nil
+}
+
+// url is the connection string of the instance; credentials are escaped
+func (cfg EmbeddedConfig) url() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     fmt.Sprintf("localhost:%d", cfg.Port),
+		Path:     "/" + cfg.Database,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
+}
+
+// URL returns the connection string for the embedded instance
+func (s *EmbeddedServer) URL() string {
+	return s.url
+}
+
+// Stop shuts down the embedded instance
+func (s *EmbeddedServer) Stop() error {
+	return s.db.Stop()
+}
@@ -88,6 +88,645 @@ func RunMigrations(pool *pgxpool.Pool) error {
 		`CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_skus_code ON skus(code)`,
 		`CREATE INDEX IF NOT EXISTS idx_skus_active ON skus(active)`,
+
+		// =========================================================================
+		// Schema Changes (additive, safe to re-run)
+		// =========================================================================
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS payment_intent_id VARCHAR(100)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS payment_method JSONB`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS fiscal_record JSONB`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES sessions(id)`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS requested_by VARCHAR(100)`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS decided_by VARCHAR(100)`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS audit_trail JSONB DEFAULT '[]'`,
+		`CREATE INDEX IF NOT EXISTS idx_refunds_session_id ON refunds(session_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds(status)`,
+
+		`CREATE TABLE IF NOT EXISTS invoices (
+			id UUID PRIMARY KEY,
+			number VARCHAR(30) UNIQUE NOT NULL,
+			customer_id VARCHAR(100) NOT NULL,
+			company_name VARCHAR(255) NOT NULL,
+			vat_id VARCHAR(50),
+			address TEXT,
+			email VARCHAR(255),
+			period VARCHAR(7) NOT NULL,
+			lines JSONB NOT NULL,
+			vat_rate_bp INTEGER NOT NULL,
+			net_cents BIGINT NOT NULL,
+			vat_cents BIGINT NOT NULL,
+			gross_cents BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			emailed_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (customer_id, period)
+		)`,
+		`CREATE TABLE IF NOT EXISTS invoice_counters (
+			year INTEGER PRIMARY KEY,
+			last_number BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_user_completed ON sessions(user_id, completed_at)`,
+
+		`CREATE TABLE IF NOT EXISTS price_experiments (
+			id UUID PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			variants JSONB NOT NULL,
+			device_ids JSONB NOT NULL DEFAULT '[]',
+			starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			stopped_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_price_experiments_ends_at ON price_experiments(ends_at)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS experiments JSONB DEFAULT '[]'`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_experiments ON sessions USING GIN (experiments jsonb_path_ops)`,
+
+		`CREATE TABLE IF NOT EXISTS device_stock_estimates (
+			device_id UUID PRIMARY KEY REFERENCES devices(id),
+			levels JSONB NOT NULL DEFAULT '{}',
+			model_version VARCHAR(100) NOT NULL DEFAULT '',
+			observed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS over_temp_since TIMESTAMP WITH TIME ZONE`,
+		`CREATE TABLE IF NOT EXISTS temperature_excursions (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			detected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			limit_celsius DOUBLE PRECISION NOT NULL,
+			peak_celsius DOUBLE PRECISION NOT NULL,
+			cleared_at TIMESTAMP WITH TIME ZONE,
+			cleared_by VARCHAR(100) NOT NULL DEFAULT '',
+			clearance_note TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_temperature_excursions_device ON temperature_excursions(device_id, detected_at)`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS door_open_since TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS door_alarm_raised BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS verification_required_since TIMESTAMP WITH TIME ZONE`,
+		`CREATE TABLE IF NOT EXISTS security_incidents (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			kind VARCHAR(50) NOT NULL,
+			door_opened_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			detected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_security_incidents_device ON security_incidents(device_id, detected_at)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS cloud_verification_required BOOLEAN NOT NULL DEFAULT false`,
+
+		`CREATE TABLE IF NOT EXISTS device_planograms (
+			device_id UUID PRIMARY KEY REFERENCES devices(id),
+			version INTEGER NOT NULL,
+			facings JSONB NOT NULL DEFAULT '[]',
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS planogram_compliance_reports (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			planogram_version INTEGER NOT NULL,
+			visited_by VARCHAR(100) NOT NULL,
+			score INTEGER NOT NULL,
+			deviations JSONB NOT NULL DEFAULT '[]',
+			checked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_planogram_compliance_reports_device ON planogram_compliance_reports(device_id, checked_at)`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS region VARCHAR(20) NOT NULL DEFAULT ''`,
+
+		`CREATE TABLE IF NOT EXISTS offline_sync_entries (
+			device_id UUID NOT NULL,
+			client_id VARCHAR(100) NOT NULL,
+			session_id VARCHAR(100) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			synced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (device_id, client_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_offline_sync_entries_session ON offline_sync_entries(session_id, kind, status)`,
+
+		`CREATE TABLE IF NOT EXISTS session_images (
+			session_id UUID PRIMARY KEY REFERENCES sessions(id),
+			image BYTEA NOT NULL,
+			captured_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_completed_at ON sessions(completed_at)`,
+		`CREATE TABLE IF NOT EXISTS reconciliation_reports (
+			day DATE PRIMARY KEY,
+			model_version VARCHAR(100) NOT NULL DEFAULT '',
+			sessions_checked INTEGER NOT NULL,
+			sessions_skipped INTEGER NOT NULL,
+			discrepancies JSONB NOT NULL DEFAULT '[]',
+			generated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS rounding_cents BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS rounding_cents BIGINT NOT NULL DEFAULT 0`,
+
+		`CREATE TABLE IF NOT EXISTS device_sales_hours (
+			device_id UUID PRIMARY KEY REFERENCES devices(id),
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			opening_hours JSONB NOT NULL DEFAULT '[]',
+			blackouts JSONB NOT NULL DEFAULT '[]',
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS stock_batches (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			sku_code VARCHAR(50) NOT NULL,
+			quantity INTEGER NOT NULL,
+			expires_on DATE NOT NULL,
+			restocked_by VARCHAR(255) NOT NULL,
+			restocked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			written_off_at TIMESTAMP WITH TIME ZONE,
+			written_off_by VARCHAR(255) NOT NULL DEFAULT '',
+			wasted_units INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_stock_batches_open ON stock_batches(device_id) WHERE written_off_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_stock_batches_written_off ON stock_batches(written_off_at)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS markdowns JSONB NOT NULL DEFAULT '{}'`,
+
+		`CREATE TABLE IF NOT EXISTS status_incidents (
+			id UUID PRIMARY KEY,
+			component VARCHAR(50) NOT NULL,
+			level VARCHAR(20) NOT NULL,
+			message TEXT NOT NULL,
+			created_by VARCHAR(255) NOT NULL,
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			resolved_at TIMESTAMP WITH TIME ZONE,
+			resolved_by VARCHAR(255) NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_status_incidents_active ON status_incidents(started_at) WHERE resolved_at IS NULL`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE`,
+
+		`CREATE TABLE IF NOT EXISTS categories (
+			id UUID PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			parent_id UUID REFERENCES categories(id),
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id)`,
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id)`,
+		`CREATE INDEX IF NOT EXISTS idx_skus_category ON skus(category_id)`,
+
+		`CREATE TABLE IF NOT EXISTS device_inventory (
+			device_id UUID NOT NULL REFERENCES devices(id),
+			sku_code VARCHAR(50) NOT NULL,
+			quantity INTEGER NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (device_id, sku_code)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS device_inventory_sales (
+			session_id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS sku_price_history (
+			id BIGSERIAL PRIMARY KEY,
+			sku_id UUID NOT NULL REFERENCES skus(id),
+			old_price_cents BIGINT NOT NULL,
+			old_currency VARCHAR(3) NOT NULL,
+			new_price_cents BIGINT NOT NULL,
+			new_currency VARCHAR(3) NOT NULL,
+			changed_by VARCHAR(255) NOT NULL,
+			changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sku_price_history_sku ON sku_price_history(sku_id, changed_at)`,
+
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS tax JSONB`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS components JSONB`,
+
+		`CREATE TABLE IF NOT EXISTS device_sku_assignments (
+			device_id UUID NOT NULL REFERENCES devices(id),
+			sku_code VARCHAR(50) NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (device_id, sku_code)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_skus_updated_at ON skus(updated_at)`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS weight_samples JSONB`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS lifecycle VARCHAR(20)`,
+		`UPDATE skus SET lifecycle = CASE WHEN active THEN 'published' ELSE 'retired' END WHERE lifecycle IS NULL`,
+		`ALTER TABLE skus ALTER COLUMN lifecycle SET NOT NULL`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS nutrition JSONB`,
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS allergens JSONB NOT NULL DEFAULT '[]'`,
+
+		`CREATE TABLE IF NOT EXISTS sku_training_images (
+			id UUID PRIMARY KEY,
+			sku_id UUID NOT NULL REFERENCES skus(id),
+			object_key VARCHAR(255) NOT NULL,
+			content_type VARCHAR(50) NOT NULL,
+			size_bytes INTEGER NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			bounding_box JSONB,
+			source VARCHAR(50) NOT NULL DEFAULT '',
+			uploaded_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (sku_id, checksum)
+		)`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS shelf_life_days INT NOT NULL DEFAULT 0`,
+
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS supplier_ref VARCHAR(64) NOT NULL DEFAULT ''`,
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS case_size INT NOT NULL DEFAULT 0`,
+		`ALTER TABLE skus ADD COLUMN IF NOT EXISTS reorder_point INT NOT NULL DEFAULT 0`,
+
+		`CREATE TABLE IF NOT EXISTS sku_external_links (
+			external_id VARCHAR(100) PRIMARY KEY,
+			sku_id UUID NOT NULL UNIQUE REFERENCES skus(id),
+			linked_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_skus_classes ON skus(created_at, code) WHERE active AND deleted_at IS NULL AND components IS NULL`,
+		`CREATE OR REPLACE VIEW device_sync_skus AS
+			SELECT code, name, translations, weight_grams, weight_tolerance,
+				active AND deleted_at IS NULL AS on_sale,
+				deleted_at IS NOT NULL AS deleted,
+				updated_at,
+				CASE WHEN active AND deleted_at IS NULL AND components IS NULL
+					THEN ROW_NUMBER() OVER (PARTITION BY active AND deleted_at IS NULL AND components IS NULL ORDER BY created_at, code) - 1
+				END AS class_id
+			FROM skus`,
+
+		`CREATE TABLE IF NOT EXISTS sku_classes (
+			class_id INT PRIMARY KEY,
+			sku_id UUID NOT NULL UNIQUE REFERENCES skus(id),
+			assigned_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		// Until a class is mapped, seed the mapping with the classes numbered
+		// by creation order, as they were before the mapping was kept
+		`INSERT INTO sku_classes (class_id, sku_id, assigned_at)
+			SELECT ROW_NUMBER() OVER (ORDER BY created_at, code) - 1, id, NOW()
+			FROM skus
+			WHERE active AND deleted_at IS NULL AND components IS NULL
+				AND NOT EXISTS (SELECT 1 FROM sku_classes)`,
+		`CREATE OR REPLACE VIEW device_sync_skus AS
+			SELECT s.code, s.name, s.translations, s.weight_grams, s.weight_tolerance,
+				s.active AND s.deleted_at IS NULL AS on_sale,
+				s.deleted_at IS NOT NULL AS deleted,
+				s.updated_at,
+				c.class_id::BIGINT AS class_id
+			FROM skus s LEFT JOIN sku_classes c ON c.sku_id = s.id`,
+
+		`CREATE TABLE IF NOT EXISTS event_messages (
+			"offset" BIGSERIAL PRIMARY KEY,
+			topic VARCHAR(100) NOT NULL,
+			type VARCHAR(100) NOT NULL,
+			version INT NOT NULL,
+			key VARCHAR(100) NOT NULL,
+			occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			payload JSONB NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_event_messages_topic ON event_messages(topic, "offset")`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS firmware_version VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS app_version VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS key_hash BYTEA`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS decommissioned_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS decommissioned_by VARCHAR(100) NOT NULL DEFAULT ''`,
+		`ALTER TABLE temperature_excursions ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE security_incidents ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE`,
+
+		`CREATE TABLE IF NOT EXISTS enrollment_tokens (
+			token_hash BYTEA PRIMARY KEY,
+			machine_id VARCHAR(50) NOT NULL,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			location VARCHAR(255) NOT NULL DEFAULT '',
+			created_by VARCHAR(100) NOT NULL,
+			override BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			redeemed_at TIMESTAMP WITH TIME ZONE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS device_groups (
+			id UUID PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			confidence_threshold DOUBLE PRECISION,
+			model_version VARCHAR(100) NOT NULL DEFAULT '',
+			planogram JSONB,
+			planogram_version INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES device_groups(id)`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS confidence_threshold DOUBLE PRECISION`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS model_version VARCHAR(100) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_devices_group_id ON devices(group_id)`,
+
+		`CREATE TABLE IF NOT EXISTS device_config_shadows (
+			device_id UUID PRIMARY KEY REFERENCES devices(id),
+			desired JSONB NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL DEFAULT 0,
+			desired_by VARCHAR(100) NOT NULL DEFAULT '',
+			desired_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			reported JSONB,
+			reported_version INTEGER NOT NULL DEFAULT 0,
+			reported_at TIMESTAMP WITH TIME ZONE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS releases (
+			id UUID PRIMARY KEY,
+			kind VARCHAR(20) NOT NULL,
+			version VARCHAR(50) NOT NULL,
+			object_key VARCHAR(255) NOT NULL,
+			size_bytes BIGINT NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			uploaded_by VARCHAR(100) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (kind, version)
+		)`,
+		`CREATE TABLE IF NOT EXISTS rollouts (
+			id UUID PRIMARY KEY,
+			release_id UUID NOT NULL REFERENCES releases(id),
+			kind VARCHAR(20) NOT NULL,
+			group_ids UUID[] NOT NULL,
+			stages INTEGER[] NOT NULL,
+			stage INTEGER NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL,
+			created_by VARCHAR(100) NOT NULL,
+			aborted_by VARCHAR(100) NOT NULL DEFAULT '',
+			abort_reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rollouts_group_ids ON rollouts USING GIN (group_ids)`,
+		`CREATE TABLE IF NOT EXISTS rollout_devices (
+			rollout_id UUID NOT NULL REFERENCES rollouts(id),
+			device_id UUID NOT NULL REFERENCES devices(id),
+			status VARCHAR(20) NOT NULL,
+			detail TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (rollout_id, device_id)
+		)`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS reported_model_version VARCHAR(100) NOT NULL DEFAULT ''`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS weight_mismatch BOOLEAN NOT NULL DEFAULT false`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS maintenance_since TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS maintenance_by VARCHAR(100) NOT NULL DEFAULT ''`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS maintenance_reason TEXT NOT NULL DEFAULT ''`,
+
+		`CREATE TABLE IF NOT EXISTS device_events (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			kind VARCHAR(50) NOT NULL,
+			occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			detail TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_device_events_occurrence ON device_events(device_id, kind, occurred_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_device_events_device ON device_events(device_id, occurred_at DESC)`,
+
+		`CREATE TABLE IF NOT EXISTS device_commands (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			kind VARCHAR(50) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			issued_by VARCHAR(100) NOT NULL,
+			issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			deliveries INTEGER NOT NULL DEFAULT 0,
+			delivered_at TIMESTAMP WITH TIME ZONE,
+			acknowledged_at TIMESTAMP WITH TIME ZONE,
+			result TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_device_commands_device ON device_commands(device_id, issued_at)`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS offline_since TIMESTAMP WITH TIME ZONE`,
+
+		`CREATE TABLE IF NOT EXISTS restock_sessions (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			status VARCHAR(20) NOT NULL,
+			started_by VARCHAR(100) NOT NULL,
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			units JSONB NOT NULL DEFAULT '{}',
+			levels_after JSONB,
+			closed_by VARCHAR(100) NOT NULL DEFAULT '',
+			closed_at TIMESTAMP WITH TIME ZONE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_restock_sessions_device ON restock_sessions(device_id, started_at DESC)`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS restock_session_id UUID`,
+
+		`CREATE TABLE IF NOT EXISTS camera_calibrations (
+			device_id UUID PRIMARY KEY REFERENCES devices(id),
+			homography JSONB NOT NULL,
+			markers JSONB NOT NULL DEFAULT '[]',
+			capture JSONB NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL,
+			calibrated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS scale_calibrations (
+			id UUID PRIMARY KEY,
+			device_id UUID NOT NULL REFERENCES devices(id),
+			zero_grams DOUBLE PRECISION NOT NULL,
+			readings JSONB NOT NULL,
+			calibrated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scale_calibrations_device ON scale_calibrations(device_id, calibrated_at DESC)`,
+
+		`CREATE TABLE IF NOT EXISTS diagnostic_reports (
+			command_id UUID PRIMARY KEY REFERENCES device_commands(id),
+			device_id UUID NOT NULL REFERENCES devices(id),
+			camera_ok BOOLEAN NOT NULL,
+			scale_ok BOOLEAN NOT NULL,
+			model_loaded BOOLEAN NOT NULL,
+			model_version VARCHAR(100) NOT NULL DEFAULT '',
+			notes TEXT NOT NULL DEFAULT '',
+			reported_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'`,
+
+		`CREATE INDEX IF NOT EXISTS idx_sessions_created_at ON sessions(created_at DESC, id DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_device_created_at ON sessions(device_id, created_at DESC, id DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_user_created_at ON sessions(user_id, created_at DESC, id DESC)`,
+
+		`CREATE TABLE IF NOT EXISTS processed_detections (
+			device_id UUID NOT NULL,
+			idempotency_key VARCHAR(100) NOT NULL,
+			session_id UUID NOT NULL REFERENCES sessions(id),
+			result JSONB NOT NULL,
+			processed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (device_id, idempotency_key)
+		)`,
+
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS frame_sequence BIGINT NOT NULL DEFAULT 0`,
+
+		// Completed sales; sessions completed before transactions were
+		// recorded get theirs copied from the session
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS device_id UUID REFERENCES devices(id)`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS user_id VARCHAR(100)`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_cents BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS rounding_cents BIGINT NOT NULL DEFAULT 0`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_session_id ON transactions(session_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_user_completed ON transactions(user_id, completed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_device_completed ON transactions(device_id, completed_at)`,
+		`INSERT INTO transactions (id, session_id, device_id, user_id, items, total_cents, tax_cents, rounding_cents, currency, status, payment_ref, created_at, completed_at)
+			SELECT gen_random_uuid(), s.id, s.device_id, s.user_id, COALESCE(s.items, '[]'), COALESCE(s.total_cents, 0),
+				COALESCE((SELECT SUM((l->>'tax_cents')::bigint) FROM jsonb_array_elements(s.tax->'lines') AS l), 0),
+				s.rounding_cents, COALESCE(s.currency, 'USD'), 'completed', s.payment_intent_id, s.completed_at, s.completed_at
+			FROM sessions s
+			WHERE s.status = 'completed' AND s.completed_at IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.session_id = s.id)`,
+		`UPDATE refunds r SET transaction_id = t.id
+			FROM transactions t
+			WHERE r.transaction_id IS NULL AND t.session_id = r.session_id`,
+
+		// Audit log of every detection submitted for a session
+		`CREATE TABLE IF NOT EXISTS detections (
+			id BIGSERIAL PRIMARY KEY,
+			session_id UUID NOT NULL,
+			device_id VARCHAR(100) NOT NULL,
+			source VARCHAR(30) NOT NULL,
+			payload JSONB NOT NULL,
+			outcome VARCHAR(20) NOT NULL,
+			decision JSONB NOT NULL,
+			received_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_detections_session_id ON detections(session_id, id)`,
+
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS weight_override JSONB`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS fraud_flags JSONB`,
+
+		// Checkout coupons; sales that used one record its code on their transaction
+		`CREATE TABLE IF NOT EXISTS coupons (
+			id UUID PRIMARY KEY,
+			code VARCHAR(50) NOT NULL UNIQUE,
+			percent_off INTEGER NOT NULL DEFAULT 0,
+			amount_off_cents BIGINT NOT NULL DEFAULT 0,
+			min_basket_cents BIGINT NOT NULL DEFAULT 0,
+			currency VARCHAR(3) NOT NULL DEFAULT '',
+			max_redemptions INTEGER NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			disabled_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS coupon JSONB`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(50)`,
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_cents BIGINT NOT NULL DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_coupon_code ON transactions(coupon_code) WHERE coupon_code IS NOT NULL`,
+
+		// How long sessions last, set per device or per group
+		`ALTER TABLE devices ADD COLUMN IF NOT EXISTS session_expiration_minutes INTEGER`,
+		`ALTER TABLE device_groups ADD COLUMN IF NOT EXISTS session_expiration_minutes INTEGER`,
+
+		// Devices signal when the customer leaves; unpaid sessions are cancelled after a grace
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS walkaway_signal VARCHAR(20)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS walkaway_signaled_at TIMESTAMP WITH TIME ZONE`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_walkaway ON sessions(walkaway_signaled_at) WHERE status = 'active' AND walkaway_signaled_at IS NOT NULL`,
+
+		// Codes detected in a session that the catalog does not sell
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS unrecognized_items JSONB`,
+
+		// Chargebacks of completed sales, worked by support and resolved by finance
+		`CREATE TABLE IF NOT EXISTS disputes (
+			id UUID PRIMARY KEY,
+			session_id UUID NOT NULL REFERENCES sessions(id),
+			transaction_id UUID NOT NULL REFERENCES transactions(id),
+			amount_cents BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			reason TEXT NOT NULL,
+			provider_ref VARCHAR(100),
+			status VARCHAR(20) NOT NULL,
+			opened_by VARCHAR(100) NOT NULL,
+			resolved_by VARCHAR(100),
+			resolution_note TEXT,
+			notes JSONB,
+			evidence JSONB,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			resolved_at TIMESTAMP WITH TIME ZONE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_disputes_session_id ON disputes(session_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_disputes_created_at ON disputes(created_at)`,
+		`CREATE TABLE IF NOT EXISTS session_notes (
+			id BIGSERIAL PRIMARY KEY,
+			session_id UUID NOT NULL REFERENCES sessions(id),
+			author_id VARCHAR(100) NOT NULL,
+			note TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_session_notes_session_id ON session_notes(session_id, id)`,
+
+		// Ended sessions move to an archive after a while; what refers to
+		// them loses its foreign key to the hot table here and gets one to
+		// session_ids below
+		`CREATE TABLE IF NOT EXISTS sessions_archive (
+			id UUID PRIMARY KEY,
+			device_id UUID,
+			user_id VARCHAR(100),
+			status VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			archived_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			data JSONB NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_archive_created_at ON sessions_archive(created_at)`,
+		`ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_session_id_fkey`,
+		`ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_session_id_fkey`,
+		`ALTER TABLE session_images DROP CONSTRAINT IF EXISTS session_images_session_id_fkey`,
+		`ALTER TABLE disputes DROP CONSTRAINT IF EXISTS disputes_session_id_fkey`,
+		`ALTER TABLE session_notes DROP CONSTRAINT IF EXISTS session_notes_session_id_fkey`,
+
+		// Fiscal identifiers are kept with the sale they were issued for;
+		// sales recorded before get them from their session
+		`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fiscal_record JSONB`,
+		`UPDATE transactions t SET fiscal_record = s.fiscal_record
+			FROM sessions s
+			WHERE t.session_id = s.id AND t.fiscal_record IS NULL AND s.fiscal_record IS NOT NULL`,
+		`UPDATE transactions t SET fiscal_record = a.data->'fiscal_record'
+			FROM sessions_archive a
+			WHERE t.session_id = a.id AND t.fiscal_record IS NULL AND jsonb_typeof(a.data->'fiscal_record') = 'object'`,
+
+		// Every session ID, of a session in the hot table or the archive, so
+		// the rows referring to a session keep a foreign key through its
+		// archival. Registered once, the first time the keys are added.
+		`CREATE TABLE IF NOT EXISTS session_ids (
+			id UUID PRIMARY KEY
+		)`,
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'sessions_session_ids_fkey') THEN
+				INSERT INTO session_ids (id)
+					SELECT id FROM sessions UNION SELECT id FROM sessions_archive
+					ON CONFLICT DO NOTHING;
+				ALTER TABLE sessions ADD CONSTRAINT sessions_session_ids_fkey FOREIGN KEY (id) REFERENCES session_ids(id);
+				ALTER TABLE sessions_archive ADD CONSTRAINT sessions_archive_session_ids_fkey FOREIGN KEY (id) REFERENCES session_ids(id);
+				ALTER TABLE transactions ADD CONSTRAINT transactions_session_ids_fkey FOREIGN KEY (session_id) REFERENCES session_ids(id);
+				ALTER TABLE refunds ADD CONSTRAINT refunds_session_ids_fkey FOREIGN KEY (session_id) REFERENCES session_ids(id);
+				ALTER TABLE session_images ADD CONSTRAINT session_images_session_ids_fkey FOREIGN KEY (session_id) REFERENCES session_ids(id);
+				ALTER TABLE disputes ADD CONSTRAINT disputes_session_ids_fkey FOREIGN KEY (session_id) REFERENCES session_ids(id);
+				ALTER TABLE session_notes ADD CONSTRAINT session_notes_session_ids_fkey FOREIGN KEY (session_id) REFERENCES session_ids(id);
+			END IF;
+		END $$`,
+
+		// A session has at most one open dispute, also when two are opened at once
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_open_session ON disputes(session_id) WHERE status = 'open'`,
 	}
 
 	for i, migration := range migrations {
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DB is what repositories run their statements on: the pool, or the
+// database transaction of the unit of work they take part in
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// UnitOfWork runs a use case's writes in one database transaction.
+// Repositories join it by getting their connection through Conn or Begin
+// with the context passed to the function.
+type UnitOfWork struct {
+	pool *pgxpool.Pool
+}
+
+func NewUnitOfWork(pool *pgxpool.Pool) *UnitOfWork {
+	if pool == nil {
+		panic("nil Pool")
+	}
+	return &UnitOfWork{pool: pool}
+}
+
+// Do commits everything fn wrote if it returns nil and rolls it all back
+// otherwise. Inside another unit of work it runs in a savepoint of it.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := Begin(ctx, u.pool)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Conn returns the transaction of the unit of work running in ctx, or the
+// pool outside of one
+func Conn(ctx context.Context, pool *pgxpool.Pool) DB {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// Begin starts a database transaction, or a savepoint within the unit of
+// work running in ctx; a repository saving several rows uses it so that its
+// save is atomic on its own and still part of the enclosing unit of work
+func Begin(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return pool.Begin(ctx)
+}
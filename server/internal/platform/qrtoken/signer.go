@@ -0,0 +1,96 @@
+// Package qrtoken issues and verifies the signed, expiring session-start
+// tokens embedded in device QR codes.
+package qrtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid session start token")
+	ErrTokenExpired = errors.New("session start token has expired")
+)
+
+// Signer creates and validates HMAC-SHA256 signed tokens of the form
+// base64url(machineID|expiresUnix).base64url(signature)
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner creates a signer with the given secret and token lifetime
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	if len(secret) == 0 {
+		panic("empty token secret")
+	}
+	return &Signer{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Issue returns a token for the machine and its expiry time
+func (s *Signer) Issue(machineID string) (string, time.Time) {
+	expiresAt := s.now().UTC().Add(s.ttl).Truncate(time.Second)
+	payload := machineID + "|" + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	token := encode([]byte(payload)) + "." + encode(s.sign(payload))
+	return token, expiresAt
+}
+
+// Verify checks the token signature and expiry and returns the machine ID it was issued for
+func (s *Signer) Verify(token string) (string, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	payload, err := decode(encPayload)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	sig, err := decode(encSig)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if !hmac.Equal(sig, s.sign(string(payload))) {
+		return "", ErrInvalidToken
+	}
+
+	sep := strings.LastIndexByte(string(payload), '|')
+	if sep <= 0 {
+		return "", ErrInvalidToken
+	}
+	machineID := string(payload[:sep])
+	expiresUnix, err := strconv.ParseInt(string(payload[sep+1:]), 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if s.now().After(time.Unix(expiresUnix, 0)) {
+		return "", ErrTokenExpired
+	}
+
+	return machineID, nil
+}
+
+func (s *Signer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
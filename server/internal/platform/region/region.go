@@ -0,0 +1,92 @@
+// Package region lets one binary serve several regions while keeping each
+// region's customer data on that region's infrastructure. Every deployment
+// serves exactly one region against its own database; requests tagged for
+// another region are answered with a routing hint instead of being served.
+package region
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers used for request routing
+const (
+	Header         = "X-Region"          // region the caller's data lives in (request) or the serving region (response)
+	EndpointHeader = "X-Region-Endpoint" // base URL of the region that should serve the request
+)
+
+// Config is the region this process serves and where the other regions are reachable
+type Config struct {
+	Current   string            // empty disables region handling (single-region deployment)
+	Endpoints map[string]string // region -> base URL
+}
+
+// ParseEndpoints reads a list of the form "eu=https://eu.example.com,us=https://us.example.com"
+func ParseEndpoints(raw string) (map[string]string, error) {
+	endpoints := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(entry, "=")
+		name, url = Normalize(name), strings.TrimSpace(url)
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid region endpoint %q", entry)
+		}
+		endpoints[name] = url
+	}
+	return endpoints, nil
+}
+
+// Normalize makes region tags case-insensitive
+func Normalize(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Setting resolves a region-scoped setting: KEY_<REGION> wins over KEY, so a
+// shared environment can hold the database and storage targets of every region
+func (c Config) Setting(key, defaultValue string) string {
+	if c.Current != "" {
+		if value := os.Getenv(key + "_" + strings.ToUpper(c.Current)); value != "" {
+			return value
+		}
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Middleware tags every response with the serving region and refuses requests
+// tagged for another region with 421 Misdirected Request, pointing the caller
+// at that region's endpoint when it is known
+func (c Config) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c.Current == "" {
+			ctx.Next()
+			return
+		}
+		ctx.Header(Header, c.Current)
+
+		requested := Normalize(ctx.GetHeader(Header))
+		if requested == "" || requested == c.Current {
+			ctx.Next()
+			return
+		}
+
+		response := gin.H{
+			"error":  fmt.Sprintf("request belongs to region %q, this server serves %q", requested, c.Current),
+			"region": requested,
+		}
+		if endpoint, ok := c.Endpoints[requested]; ok {
+			ctx.Header(EndpointHeader, endpoint)
+			response["endpoint"] = endpoint
+		}
+		ctx.AbortWithStatusJSON(http.StatusMisdirectedRequest, response)
+	}
+}
@@ -0,0 +1,31 @@
+// Package schedule runs periodic background jobs inside the server process.
+// Every server instance runs its own schedule, so jobs must be idempotent.
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// Daily calls job once a day at the given hour (UTC) until ctx is cancelled
+func Daily(ctx context.Context, hour int, job func(ctx context.Context, now time.Time)) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now().UTC(), hour)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			job(ctx, now.UTC())
+		}
+	}
+}
+
+// nextRun is the first occurrence of the hour strictly after now
+func nextRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
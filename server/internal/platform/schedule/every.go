@@ -0,0 +1,23 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls job once per interval, the first time one interval from now,
+// until ctx is cancelled. A job running late delays the next call rather
+// than overlapping it.
+func Every(ctx context.Context, interval time.Duration, job func(ctx context.Context, now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			job(ctx, now.UTC())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package status
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const actorIDHeader = "X-Actor-ID"
+
+type componentResponse struct {
+	Name    Component `json:"name"`
+	Status  Level     `json:"status"`
+	Message string    `json:"message,omitempty"`
+}
+
+type incidentResponse struct {
+	ID         string     `json:"id"`
+	Component  Component  `json:"component"`
+	Level      Level      `json:"level"`
+	Message    string     `json:"message"`
+	StartedAt  time.Time  `json:"started_at"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+}
+
+type statusResponse struct {
+	Status     Level               `json:"status"`
+	Components []componentResponse `json:"components"`
+	Incidents  []incidentResponse  `json:"incidents"`
+}
+
+type raiseIncidentRequest struct {
+	Component Component `json:"component" binding:"required"`
+	Level     Level     `json:"level" binding:"required"`
+	Message   string    `json:"message" binding:"required"`
+}
+
+// HTTPHandler serves the public status page and the incident admin API
+type HTTPHandler struct {
+	service *Service
+}
+
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	if service == nil {
+		panic("nil Service")
+	}
+	return &HTTPHandler{service: service}
+}
+
+// RegisterRoutes registers the status routes on the given router group
+func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/status", h.Status)
+
+	incidents := rg.Group("/status/incidents")
+	{
+		incidents.GET("", h.Incidents)
+		incidents.POST("", h.RaiseIncident)
+		incidents.POST("/:id/resolve", h.ResolveIncident)
+	}
+}
+
+// Status is the public, unauthenticated service status. It may be cached by
+// clients and CDNs for the report TTL and revalidated with If-None-Match.
+func (h *HTTPHandler) Status(c *gin.Context) {
+	report := h.service.Report(c.Request.Context())
+
+	response := statusResponse{Status: report.Level, Incidents: toIncidentResponses(report.Incidents, false)}
+	for _, st := range report.Components {
+		response.Components = append(response.Components, componentResponse{Name: st.Component, Status: st.Level, Message: st.Message})
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.service.TTL().Seconds())))
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// Incidents lists active incidents, or all incidents started since ?since=
+// (RFC 3339) for the operator dashboard
+func (h *HTTPHandler) Incidents(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		since = t
+	}
+
+	incidents, err := h.service.Incidents(c.Request.Context(), since)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toIncidentResponses(incidents, true))
+}
+
+// RaiseIncident flags a component as degraded or down
+func (h *HTTPHandler) RaiseIncident(c *gin.Context) {
+	var req raiseIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incident, err := h.service.RaiseIncident(c.Request.Context(), RaiseIncidentCommand{
+		Component: req.Component,
+		Level:     req.Level,
+		Message:   req.Message,
+		RaisedBy:  strings.TrimSpace(c.GetHeader(actorIDHeader)),
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toIncidentResponse(*incident, true))
+}
+
+// ResolveIncident clears an incident flag
+func (h *HTTPHandler) ResolveIncident(c *gin.Context) {
+	incident, err := h.service.ResolveIncident(c.Request.Context(), c.Param("id"), strings.TrimSpace(c.GetHeader(actorIDHeader)))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toIncidentResponse(*incident, true))
+}
+
+func (h *HTTPHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrActorRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrIncidentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidIncident):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrIncidentResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// toIncidentResponses renders incidents; staff names are only shown to operators
+func toIncidentResponses(incidents []Incident, withStaff bool) []incidentResponse {
+	response := make([]incidentResponse, 0, len(incidents))
+	for _, i := range incidents {
+		response = append(response, toIncidentResponse(i, withStaff))
+	}
+	return response
+}
+
+func toIncidentResponse(i Incident, withStaff bool) incidentResponse {
+	response := incidentResponse{
+		ID:         i.ID,
+		Component:  i.Component,
+		Level:      i.Level,
+		Message:    i.Message,
+		StartedAt:  i.StartedAt,
+		ResolvedAt: i.ResolvedAt,
+	}
+	if withStaff {
+		response.CreatedBy, response.ResolvedBy = i.CreatedBy, i.ResolvedBy
+	}
+	return response
+}
@@ -0,0 +1,95 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresIncidentStore implements IncidentStore
+type PostgresIncidentStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresIncidentStore(pool *pgxpool.Pool) *PostgresIncidentStore {
+	return &PostgresIncidentStore{pool: pool}
+}
+
+const incidentColumns = `id, component, level, message, created_by, started_at, resolved_at, resolved_by`
+
+func (s *PostgresIncidentStore) Save(ctx context.Context, i *Incident) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO status_incidents (`+incidentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			resolved_at = EXCLUDED.resolved_at,
+			resolved_by = EXCLUDED.resolved_by
+	`, i.ID, string(i.Component), string(i.Level), i.Message, i.CreatedBy, i.StartedAt, i.ResolvedAt, i.ResolvedBy)
+
+	return err
+}
+
+func (s *PostgresIncidentStore) FindByID(ctx context.Context, id string) (*Incident, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+incidentColumns+` FROM status_incidents WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := scanIncidents(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(incidents) == 0 {
+		return nil, ErrIncidentNotFound
+	}
+	return &incidents[0], nil
+}
+
+func (s *PostgresIncidentStore) FindActive(ctx context.Context) ([]Incident, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+incidentColumns+`
+		FROM status_incidents
+		WHERE resolved_at IS NULL
+		ORDER BY started_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanIncidents(rows)
+}
+
+func (s *PostgresIncidentStore) FindStartedSince(ctx context.Context, since time.Time) ([]Incident, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+incidentColumns+`
+		FROM status_incidents
+		WHERE started_at >= $1
+		ORDER BY started_at
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanIncidents(rows)
+}
+
+func scanIncidents(rows pgx.Rows) ([]Incident, error) {
+	defer rows.Close()
+
+	var incidents []Incident
+	for rows.Next() {
+		var (
+			i                Incident
+			component, level string
+		)
+		if err := rows.Scan(&i.ID, &component, &level, &i.Message, &i.CreatedBy, &i.StartedAt, &i.ResolvedAt, &i.ResolvedBy); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrIncidentNotFound
+			}
+			return nil, err
+		}
+		i.Component, i.Level = Component(component), Level(level)
+		incidents = append(incidents, i)
+	}
+	return incidents, rows.Err()
+}
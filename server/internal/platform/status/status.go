@@ -0,0 +1,281 @@
+// Package status answers "is the service working right now?" for the customer
+// app and operator dashboards. Each component's availability combines a live
+// probe with the incidents operators raise; the worse of the two wins. Reports
+// are cached briefly so a burst of app launches never fans out into probes.
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Component is a part of the service the apps surface separately
+type Component string
+
+const (
+	ComponentAPI            Component = "api"
+	ComponentPayments       Component = "payments"
+	ComponentMLVerification Component = "ml_verification"
+)
+
+// Components lists every known component in display order
+var Components = []Component{ComponentAPI, ComponentPayments, ComponentMLVerification}
+
+// Level is the availability of a component or of the service as a whole
+type Level string
+
+const (
+	LevelOperational   Level = "operational"
+	LevelNotConfigured Level = "not_configured" // the deployment runs without it; never a degradation
+	LevelDegraded      Level = "degraded"
+	LevelOutage        Level = "outage"
+)
+
+// severity orders levels so the worst one can be picked
+func (l Level) severity() int {
+	switch l {
+	case LevelDegraded:
+		return 1
+	case LevelOutage:
+		return 2
+	default:
+		return 0
+	}
+}
+
+var (
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrInvalidIncident  = errors.New("invalid incident")
+	ErrIncidentResolved = errors.New("incident already resolved")
+	ErrActorRequired    = errors.New("actor ID is required to manage incidents")
+)
+
+// probeTimeout bounds each live probe so a hanging dependency reads as an outage
+const probeTimeout = 2 * time.Second
+
+// Probe checks a dependency; an error means the component is down
+type Probe func(ctx context.Context) error
+
+// Check describes how one component's availability is determined
+type Check struct {
+	Component  Component
+	Configured bool  // false reports the component as not_configured
+	Probe      Probe // optional; without one a configured component is operational
+}
+
+// Incident is an operator-declared disruption of one component
+type Incident struct {
+	ID         string
+	Component  Component
+	Level      Level
+	Message    string
+	CreatedBy  string
+	StartedAt  time.Time
+	ResolvedAt *time.Time
+	ResolvedBy string
+}
+
+// IsActive reports whether the incident is still affecting its component
+func (i Incident) IsActive() bool {
+	return i.ResolvedAt == nil
+}
+
+// IncidentStore persists incidents
+type IncidentStore interface {
+	Save(ctx context.Context, incident *Incident) error
+	FindByID(ctx context.Context, id string) (*Incident, error)
+	FindActive(ctx context.Context) ([]Incident, error)
+	FindStartedSince(ctx context.Context, since time.Time) ([]Incident, error)
+}
+
+// ComponentStatus is the availability of one component
+type ComponentStatus struct {
+	Component Component
+	Level     Level
+	Message   string // the most severe active incident's message, or why the probe failed
+}
+
+// Report is the public status of the service
+type Report struct {
+	Level      Level
+	Components []ComponentStatus
+	Incidents  []Incident // active only
+}
+
+// RaiseIncidentCommand is the input for declaring an incident
+type RaiseIncidentCommand struct {
+	Component Component
+	Level     Level
+	Message   string
+	RaisedBy  string
+}
+
+// Service aggregates probes and incidents into a cached Report
+type Service struct {
+	checks    []Check
+	incidents IncidentStore
+	ttl       time.Duration
+
+	mu       sync.Mutex
+	cached   *Report
+	cachedAt time.Time
+}
+
+func NewService(checks []Check, incidents IncidentStore, ttl time.Duration) *Service {
+	if incidents == nil {
+		panic("nil IncidentStore")
+	}
+	return &Service{checks: checks, incidents: incidents, ttl: ttl}
+}
+
+// TTL is how long a report is reused before components are probed again
+func (s *Service) TTL() time.Duration {
+	return s.ttl
+}
+
+// Report returns the current status, probing components at most once per TTL
+func (s *Service) Report(ctx context.Context) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cachedAt) < s.ttl {
+		return *s.cached
+	}
+
+	// A caller hanging up mid-probe must not cache an outage for everyone else
+	report := s.build(context.WithoutCancel(ctx))
+	s.cached, s.cachedAt = &report, time.Now()
+	return report
+}
+
+// RaiseIncident declares a disruption; it shows in the next report immediately
+func (s *Service) RaiseIncident(ctx context.Context, cmd RaiseIncidentCommand) (*Incident, error) {
+	cmd.RaisedBy = strings.TrimSpace(cmd.RaisedBy)
+	if cmd.RaisedBy == "" {
+		return nil, ErrActorRequired
+	}
+	if !slices.Contains(Components, cmd.Component) {
+		return nil, fmt.Errorf("%w: unknown component %q", ErrInvalidIncident, cmd.Component)
+	}
+	if cmd.Level != LevelDegraded && cmd.Level != LevelOutage {
+		return nil, fmt.Errorf("%w: level must be degraded or outage", ErrInvalidIncident)
+	}
+	cmd.Message = strings.TrimSpace(cmd.Message)
+	if cmd.Message == "" {
+		return nil, fmt.Errorf("%w: message is required", ErrInvalidIncident)
+	}
+
+	incident := &Incident{
+		ID:        uuid.New().String(),
+		Component: cmd.Component,
+		Level:     cmd.Level,
+		Message:   cmd.Message,
+		CreatedBy: cmd.RaisedBy,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.incidents.Save(ctx, incident); err != nil {
+		return nil, fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	s.invalidate()
+	return incident, nil
+}
+
+// ResolveIncident ends an incident; it drops out of the next report immediately
+func (s *Service) ResolveIncident(ctx context.Context, id, resolvedBy string) (*Incident, error) {
+	resolvedBy = strings.TrimSpace(resolvedBy)
+	if resolvedBy == "" {
+		return nil, ErrActorRequired
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrIncidentNotFound
+	}
+
+	incident, err := s.incidents.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !incident.IsActive() {
+		return nil, ErrIncidentResolved
+	}
+
+	now := time.Now().UTC()
+	incident.ResolvedAt = &now
+	incident.ResolvedBy = resolvedBy
+	if err := s.incidents.Save(ctx, incident); err != nil {
+		return nil, fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	s.invalidate()
+	return incident, nil
+}
+
+// Incidents lists active incidents, or every incident started since the given
+// time when since is non-zero
+func (s *Service) Incidents(ctx context.Context, since time.Time) ([]Incident, error) {
+	if since.IsZero() {
+		return s.incidents.FindActive(ctx)
+	}
+	return s.incidents.FindStartedSince(ctx, since)
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+func (s *Service) build(ctx context.Context) Report {
+	statuses := make(map[Component]*ComponentStatus, len(Components))
+	for _, c := range Components {
+		statuses[c] = &ComponentStatus{Component: c, Level: LevelNotConfigured}
+	}
+
+	for _, check := range s.checks {
+		st, ok := statuses[check.Component]
+		if !ok || !check.Configured {
+			continue
+		}
+		st.Level = LevelOperational
+		if check.Probe == nil {
+			continue
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := check.Probe(probeCtx)
+		cancel()
+		if err != nil {
+			st.Level, st.Message = LevelOutage, fmt.Sprintf("%s is not responding", check.Component)
+		}
+	}
+
+	// Incidents live in the database; if it is unreachable the API is down
+	// whatever the probes said
+	active, err := s.incidents.FindActive(ctx)
+	if err != nil {
+		api := statuses[ComponentAPI]
+		api.Level, api.Message = LevelOutage, "api is not responding"
+	}
+	for _, incident := range active {
+		st, ok := statuses[incident.Component]
+		if ok && incident.Level.severity() > st.Level.severity() {
+			st.Level, st.Message = incident.Level, incident.Message
+		}
+	}
+
+	report := Report{Level: LevelOperational, Incidents: active}
+	for _, c := range Components {
+		st := *statuses[c]
+		report.Components = append(report.Components, st)
+		if st.Level.severity() > report.Level.severity() {
+			report.Level = st.Level
+		}
+	}
+	return report
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+)
+
+// ErrCouponNotFound is returned by CouponReader when no coupon has the code
+var ErrCouponNotFound = domain.ErrCouponNotFound
+
+// CouponTermsView is the DTO exposed to other contexts describing a coupon
+// and whether it can be used at the time it was read
+type CouponTermsView struct {
+	Code           string
+	Status         string // active, expired or disabled
+	PercentOff     int
+	AmountOffCents int64
+	MinBasketCents int64
+	Currency       string
+	MaxRedemptions int // 0 means no limit
+}
+
+// CouponReader is the interface other contexts use to read coupons
+type CouponReader interface {
+	FindByCode(ctx context.Context, code string) (*CouponTermsView, error)
+}
+
+// CouponReaderAdapter implements CouponReader using the domain repository
+type CouponReaderAdapter struct {
+	repo domain.CouponRepository
+}
+
+func NewCouponReaderAdapter(repo domain.CouponRepository) *CouponReaderAdapter {
+	return &CouponReaderAdapter{repo: repo}
+}
+
+// FindByCode returns the coupon with the code, in any letter case
+func (a *CouponReaderAdapter) FindByCode(ctx context.Context, code string) (*CouponTermsView, error) {
+	coupon, err := a.repo.FindByCode(ctx, domain.NormalizeCouponCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	terms := coupon.Terms()
+	return &CouponTermsView{
+		Code:           coupon.Code(),
+		Status:         string(coupon.Status(time.Now().UTC())),
+		PercentOff:     terms.PercentOff,
+		AmountOffCents: terms.AmountOffCents,
+		MinBasketCents: terms.MinBasketCents,
+		Currency:       terms.Currency,
+		MaxRedemptions: terms.MaxRedemptions,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+)
+
+// AssignmentView is the DTO exposed to other contexts describing the
+// experiment variant a device is assigned to
+type AssignmentView struct {
+	ExperimentID string
+	Variant      string
+	Prices       map[string]int64 // SKU code -> price in cents
+}
+
+// ExperimentReader is the interface other contexts use to read price experiments
+type ExperimentReader interface {
+	AssignmentsFor(ctx context.Context, deviceID string, at time.Time) ([]AssignmentView, error)
+}
+
+// ExperimentReaderAdapter implements ExperimentReader using the domain repository
+type ExperimentReaderAdapter struct {
+	repo domain.ExperimentRepository
+}
+
+func NewExperimentReaderAdapter(repo domain.ExperimentRepository) *ExperimentReaderAdapter {
+	return &ExperimentReaderAdapter{repo: repo}
+}
+
+// AssignmentsFor returns the variants of all experiments running on the device at the given time
+func (a *ExperimentReaderAdapter) AssignmentsFor(ctx context.Context, deviceID string, at time.Time) ([]AssignmentView, error) {
+	experiments, err := a.repo.FindNotEndedAt(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []AssignmentView
+	for _, e := range experiments {
+		if e.Status(at) != domain.ExperimentStatusRunning {
+			continue
+		}
+		variant, ok := e.Assign(deviceID)
+		if !ok {
+			continue
+		}
+		views = append(views, AssignmentView{
+			ExperimentID: e.ID().String(),
+			Variant:      variant.Name,
+			Prices:       variant.Prices,
+		})
+	}
+	return views, nil
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/app/ports"
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// CouponView is a read-only view of a coupon and how often it was used
+type CouponView struct {
+	ID             string
+	Code           string
+	Status         string
+	PercentOff     int
+	AmountOffCents int64
+	MinBasketCents int64
+	Currency       string
+	MaxRedemptions int
+	Redemptions    int
+	ExpiresAt      *time.Time
+	DisabledAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// CouponQueryService provides read-only access to coupons
+type CouponQueryService struct {
+	coupons     domain.CouponRepository
+	redemptions ports.RedemptionSource
+}
+
+func NewCouponQueryService(coupons domain.CouponRepository, redemptions ports.RedemptionSource) *CouponQueryService {
+	if coupons == nil {
+		panic("nil CouponRepository")
+	}
+	if redemptions == nil {
+		panic("nil RedemptionSource")
+	}
+	return &CouponQueryService{coupons: coupons, redemptions: redemptions}
+}
+
+func (s *CouponQueryService) FindByID(ctx context.Context, id string) (*CouponView, error) {
+	couponID, err := valueobjects.CouponIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrCouponNotFound
+	}
+	coupon, err := s.coupons.FindByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toView(ctx, coupon)
+}
+
+func (s *CouponQueryService) FindAll(ctx context.Context) ([]CouponView, error) {
+	coupons, err := s.coupons.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]CouponView, 0, len(coupons))
+	for _, c := range coupons {
+		view, err := s.toView(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+	return views, nil
+}
+
+func (s *CouponQueryService) toView(ctx context.Context, c *domain.Coupon) (*CouponView, error) {
+	redemptions, err := s.redemptions.CouponRedemptions(ctx, c.Code())
+	if err != nil {
+		return nil, err
+	}
+
+	terms := c.Terms()
+	return &CouponView{
+		ID:             c.ID().String(),
+		Code:           c.Code(),
+		Status:         string(c.Status(time.Now().UTC())),
+		PercentOff:     terms.PercentOff,
+		AmountOffCents: terms.AmountOffCents,
+		MinBasketCents: terms.MinBasketCents,
+		Currency:       terms.Currency,
+		MaxRedemptions: terms.MaxRedemptions,
+		Redemptions:    redemptions,
+		ExpiresAt:      c.ExpiresAt(),
+		DisabledAt:     c.DisabledAt(),
+		CreatedAt:      c.CreatedAt(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+)
+
+// CreateCouponCommand is the input DTO for defining a coupon
+type CreateCouponCommand struct {
+	Code           string
+	PercentOff     int
+	AmountOffCents int64
+	MinBasketCents int64
+	Currency       string
+	MaxRedemptions int        // 1 for single-use, 0 for no limit
+	ExpiresAt      *time.Time // nil never expires
+}
+
+// CreateCouponResult is the output DTO
+type CreateCouponResult struct {
+	CouponID string
+	Code     string
+}
+
+// CreateCouponHandler orchestrates the create coupon use case
+type CreateCouponHandler struct {
+	coupons   domain.CouponRepository
+	publisher EventPublisher
+}
+
+func NewCreateCouponHandler(coupons domain.CouponRepository, publisher EventPublisher) *CreateCouponHandler {
+	if coupons == nil {
+		panic("nil CouponRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CreateCouponHandler{coupons: coupons, publisher: publisher}
+}
+
+func (h *CreateCouponHandler) Handle(ctx context.Context, cmd CreateCouponCommand) (CreateCouponResult, error) {
+	coupon, err := domain.NewCoupon(cmd.Code, domain.CouponTerms{
+		PercentOff:     cmd.PercentOff,
+		AmountOffCents: cmd.AmountOffCents,
+		MinBasketCents: cmd.MinBasketCents,
+		Currency:       cmd.Currency,
+		MaxRedemptions: cmd.MaxRedemptions,
+	}, cmd.ExpiresAt)
+	if err != nil {
+		return CreateCouponResult{}, err
+	}
+
+	if err := h.coupons.Save(ctx, coupon); err != nil {
+		return CreateCouponResult{}, fmt.Errorf("failed to save coupon: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range coupon.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return CreateCouponResult{
+		CouponID: coupon.ID().String(),
+		Code:     coupon.Code(),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// EventPublisher is the interface for publishing domain events
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.DomainEvent) error
+}
+
+// VariantInput describes one experiment arm
+type VariantInput struct {
+	Name   string
+	Weight int
+	Prices map[string]int64 // SKU code -> price in cents
+}
+
+// CreateExperimentCommand is the input DTO for defining a price experiment
+type CreateExperimentCommand struct {
+	Name      string
+	Variants  []VariantInput
+	DeviceIDs []string // cohort; empty means every device
+	StartsAt  time.Time
+	EndsAt    time.Time
+}
+
+// CreateExperimentResult is the output DTO
+type CreateExperimentResult struct {
+	ExperimentID string
+	Status       string
+}
+
+// CreateExperimentHandler orchestrates the create experiment use case
+type CreateExperimentHandler struct {
+	experiments domain.ExperimentRepository
+	publisher   EventPublisher
+}
+
+func NewCreateExperimentHandler(experiments domain.ExperimentRepository, publisher EventPublisher) *CreateExperimentHandler {
+	if experiments == nil {
+		panic("nil ExperimentRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CreateExperimentHandler{experiments: experiments, publisher: publisher}
+}
+
+func (h *CreateExperimentHandler) Handle(ctx context.Context, cmd CreateExperimentCommand) (CreateExperimentResult, error) {
+	variants := make([]domain.Variant, 0, len(cmd.Variants))
+	for _, v := range cmd.Variants {
+		variants = append(variants, domain.Variant{Name: v.Name, Weight: v.Weight, Prices: v.Prices})
+	}
+
+	startsAt := cmd.StartsAt
+	if startsAt.IsZero() {
+		startsAt = time.Now().UTC()
+	}
+
+	experiment, err := domain.NewExperiment(cmd.Name, variants, cmd.DeviceIDs, startsAt, cmd.EndsAt)
+	if err != nil {
+		return CreateExperimentResult{}, err
+	}
+
+	// A device must never see two experiment prices for the same SKU
+	existing, err := h.experiments.FindNotEndedAt(ctx, time.Now().UTC())
+	if err != nil {
+		return CreateExperimentResult{}, fmt.Errorf("failed to load experiments: %w", err)
+	}
+	for _, other := range existing {
+		if experiment.ConflictsWith(other) {
+			return CreateExperimentResult{}, domain.ErrExperimentConflict
+		}
+	}
+
+	if err := h.experiments.Save(ctx, experiment); err != nil {
+		return CreateExperimentResult{}, fmt.Errorf("failed to save experiment: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range experiment.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return CreateExperimentResult{
+		ExperimentID: experiment.ID().String(),
+		Status:       string(experiment.Status(time.Now().UTC())),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// DisableCouponHandler withdraws a coupon
+type DisableCouponHandler struct {
+	coupons   domain.CouponRepository
+	publisher EventPublisher
+}
+
+func NewDisableCouponHandler(coupons domain.CouponRepository, publisher EventPublisher) *DisableCouponHandler {
+	if coupons == nil {
+		panic("nil CouponRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DisableCouponHandler{coupons: coupons, publisher: publisher}
+}
+
+func (h *DisableCouponHandler) Handle(ctx context.Context, couponID string) error {
+	id, err := valueobjects.CouponIDFrom(couponID)
+	if err != nil {
+		return domain.ErrCouponNotFound
+	}
+
+	coupon, err := h.coupons.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := coupon.Disable(); err != nil {
+		return err
+	}
+
+	if err := h.coupons.Save(ctx, coupon); err != nil {
+		return fmt.Errorf("failed to save coupon: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range coupon.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return nil
+}
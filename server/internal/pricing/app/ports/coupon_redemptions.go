@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// RedemptionSource is an input port for counting the sales that used a
+// coupon. This port is defined by the pricing context (consumer) and
+// implemented by an adapter that calls the transaction context API.
+type RedemptionSource interface {
+	CouponRedemptions(ctx context.Context, code string) (int, error)
+}
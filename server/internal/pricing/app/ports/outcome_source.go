@@ -0,0 +1,19 @@
+package ports
+
+import "context"
+
+// VariantOutcome aggregates the sessions tagged with one experiment variant
+type VariantOutcome struct {
+	Variant           string
+	Sessions          int64
+	CompletedSessions int64
+	RevenueCents      int64
+	Currency          string
+}
+
+// OutcomeSource is an input port for reading session outcomes.
+// This port is defined by the pricing context (consumer) and
+// implemented by an adapter that calls the transaction context API.
+type OutcomeSource interface {
+	VariantOutcomes(ctx context.Context, experimentID string) ([]VariantOutcome, error)
+}
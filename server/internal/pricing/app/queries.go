@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/pricing/app/ports"
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ExperimentView is a read-only view of a price experiment
+type ExperimentView struct {
+	ID        string
+	Name      string
+	Status    string
+	Variants  []VariantView
+	DeviceIDs []string
+	SKUCodes  []string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	StoppedAt *time.Time
+	CreatedAt time.Time
+}
+
+// VariantView is a read-only view of an experiment variant
+type VariantView struct {
+	Name   string
+	Weight int
+	Prices map[string]int64
+}
+
+// ExperimentReport compares conversion and revenue across variants
+type ExperimentReport struct {
+	Experiment ExperimentView
+	Variants   []VariantResult
+}
+
+// VariantResult holds the outcome metrics of one variant
+type VariantResult struct {
+	Variant                string
+	Sessions               int64
+	CompletedSessions      int64
+	ConversionRate         float64 // completed / started sessions
+	RevenueCents           int64
+	RevenuePerSessionCents float64
+	Currency               string
+}
+
+// ExperimentQueryService provides read-only access to experiments and their results
+type ExperimentQueryService struct {
+	experiments domain.ExperimentRepository
+	outcomes    ports.OutcomeSource
+}
+
+func NewExperimentQueryService(experiments domain.ExperimentRepository, outcomes ports.OutcomeSource) *ExperimentQueryService {
+	if experiments == nil {
+		panic("nil ExperimentRepository")
+	}
+	if outcomes == nil {
+		panic("nil OutcomeSource")
+	}
+	return &ExperimentQueryService{experiments: experiments, outcomes: outcomes}
+}
+
+func (s *ExperimentQueryService) FindByID(ctx context.Context, id string) (*ExperimentView, error) {
+	experiment, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	view := toExperimentView(experiment)
+	return &view, nil
+}
+
+func (s *ExperimentQueryService) FindAll(ctx context.Context) ([]ExperimentView, error) {
+	experiments, err := s.experiments.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ExperimentView, 0, len(experiments))
+	for _, e := range experiments {
+		views = append(views, toExperimentView(e))
+	}
+	return views, nil
+}
+
+// Report summarises the sessions tagged with each variant
+func (s *ExperimentQueryService) Report(ctx context.Context, id string) (*ExperimentReport, error) {
+	experiment, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes, err := s.outcomes.VariantOutcomes(ctx, experiment.ID().String())
+	if err != nil {
+		return nil, err
+	}
+	byVariant := make(map[string]ports.VariantOutcome, len(outcomes))
+	for _, o := range outcomes {
+		byVariant[o.Variant] = o
+	}
+
+	report := &ExperimentReport{Experiment: toExperimentView(experiment)}
+	for _, v := range experiment.Variants() {
+		o := byVariant[v.Name]
+		result := VariantResult{
+			Variant:           v.Name,
+			Sessions:          o.Sessions,
+			CompletedSessions: o.CompletedSessions,
+			RevenueCents:      o.RevenueCents,
+			Currency:          o.Currency,
+		}
+		if o.Sessions > 0 {
+			result.ConversionRate = float64(o.CompletedSessions) / float64(o.Sessions)
+			result.RevenuePerSessionCents = float64(o.RevenueCents) / float64(o.Sessions)
+		}
+		report.Variants = append(report.Variants, result)
+	}
+	return report, nil
+}
+
+func (s *ExperimentQueryService) load(ctx context.Context, id string) (*domain.Experiment, error) {
+	experimentID, err := valueobjects.ExperimentIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrExperimentNotFound
+	}
+	return s.experiments.FindByID(ctx, experimentID)
+}
+
+func toExperimentView(e *domain.Experiment) ExperimentView {
+	variants := make([]VariantView, 0, len(e.Variants()))
+	for _, v := range e.Variants() {
+		variants = append(variants, VariantView{Name: v.Name, Weight: v.Weight, Prices: v.Prices})
+	}
+
+	return ExperimentView{
+		ID:        e.ID().String(),
+		Name:      e.Name(),
+		Status:    string(e.Status(time.Now().UTC())),
+		Variants:  variants,
+		DeviceIDs: e.Cohort(),
+		SKUCodes:  e.SKUCodes(),
+		StartsAt:  e.StartsAt(),
+		EndsAt:    e.EndsAt(),
+		StoppedAt: e.StoppedAt(),
+		CreatedAt: e.CreatedAt(),
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// StopExperimentHandler ends a price experiment early
+type StopExperimentHandler struct {
+	experiments domain.ExperimentRepository
+	publisher   EventPublisher
+}
+
+func NewStopExperimentHandler(experiments domain.ExperimentRepository, publisher EventPublisher) *StopExperimentHandler {
+	if experiments == nil {
+		panic("nil ExperimentRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &StopExperimentHandler{experiments: experiments, publisher: publisher}
+}
+
+func (h *StopExperimentHandler) Handle(ctx context.Context, experimentID string) error {
+	id, err := valueobjects.ExperimentIDFrom(experimentID)
+	if err != nil {
+		return domain.ErrExperimentNotFound
+	}
+
+	experiment, err := h.experiments.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := experiment.Stop(); err != nil {
+		return err
+	}
+
+	if err := h.experiments.Save(ctx, experiment); err != nil {
+		return fmt.Errorf("failed to save experiment: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range experiment.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type CouponStatus string
+
+const (
+	CouponStatusActive   CouponStatus = "active"
+	CouponStatusExpired  CouponStatus = "expired"
+	CouponStatusDisabled CouponStatus = "disabled"
+)
+
+// CouponTerms is what a coupon takes off a basket and which baskets qualify.
+// A coupon takes either a percentage or a fixed amount off; amounts are in
+// Currency.
+type CouponTerms struct {
+	PercentOff     int
+	AmountOffCents int64
+	MinBasketCents int64 // item prices before the discount; 0 means any basket
+	Currency       string
+	// MaxRedemptions is how many sales may use the coupon: 1 for a
+	// single-use coupon, 0 for no limit
+	MaxRedemptions int
+}
+
+// Coupon is the aggregate root for a checkout coupon code
+type Coupon struct {
+	id         valueobjects.CouponID
+	code       string
+	terms      CouponTerms
+	expiresAt  *time.Time
+	disabledAt *time.Time
+	createdAt  time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NormalizeCouponCode makes coupon codes case-insensitive
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NewCoupon defines a coupon; it never expires when expiresAt is nil
+func NewCoupon(code string, terms CouponTerms, expiresAt *time.Time) (*Coupon, error) {
+	code = NormalizeCouponCode(code)
+	if code == "" || len(code) > 50 {
+		return nil, ErrInvalidCouponCode
+	}
+
+	hasPercent, hasAmount := terms.PercentOff != 0, terms.AmountOffCents != 0
+	if hasPercent == hasAmount || terms.PercentOff < 0 || terms.PercentOff > 100 || terms.AmountOffCents < 0 {
+		return nil, ErrInvalidCouponDiscount
+	}
+	if terms.MinBasketCents < 0 || terms.MaxRedemptions < 0 {
+		return nil, ErrInvalidCouponLimits
+	}
+	terms.Currency = strings.ToUpper(strings.TrimSpace(terms.Currency))
+	if (hasAmount || terms.MinBasketCents > 0) && len(terms.Currency) != 3 {
+		return nil, ErrCouponCurrencyRequired
+	}
+
+	now := time.Now().UTC()
+	if expiresAt != nil {
+		if !expiresAt.After(now) {
+			return nil, ErrInvalidCouponExpiry
+		}
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	c := &Coupon{
+		id:        valueobjects.NewCouponID(),
+		code:      code,
+		terms:     terms,
+		expiresAt: expiresAt,
+		createdAt: now,
+	}
+
+	c.domainEvents = append(c.domainEvents, NewCouponCreated(c.id, code))
+
+	return c, nil
+}
+
+// ReconstituteCoupon rebuilds a Coupon from persistence
+func ReconstituteCoupon(
+	id valueobjects.CouponID,
+	code string,
+	terms CouponTerms,
+	expiresAt, disabledAt *time.Time,
+	createdAt time.Time,
+) *Coupon {
+	return &Coupon{
+		id:         id,
+		code:       code,
+		terms:      terms,
+		expiresAt:  expiresAt,
+		disabledAt: disabledAt,
+		createdAt:  createdAt,
+	}
+}
+
+// Getters
+func (c *Coupon) ID() valueobjects.CouponID { return c.id }
+func (c *Coupon) Code() string              { return c.code }
+func (c *Coupon) Terms() CouponTerms        { return c.terms }
+func (c *Coupon) ExpiresAt() *time.Time     { return c.expiresAt }
+func (c *Coupon) DisabledAt() *time.Time    { return c.disabledAt }
+func (c *Coupon) CreatedAt() time.Time      { return c.createdAt }
+
+func (c *Coupon) Status(at time.Time) CouponStatus {
+	switch {
+	case c.disabledAt != nil:
+		return CouponStatusDisabled
+	case c.expiresAt != nil && !at.Before(*c.expiresAt):
+		return CouponStatusExpired
+	default:
+		return CouponStatusActive
+	}
+}
+
+// Business methods
+
+// Disable withdraws the coupon; sessions it was applied to can no longer check out with it
+func (c *Coupon) Disable() error {
+	if c.disabledAt != nil {
+		return ErrCouponDisabled
+	}
+
+	now := time.Now().UTC()
+	c.disabledAt = &now
+	c.domainEvents = append(c.domainEvents, NewCouponDisabled(c.id, c.code))
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (c *Coupon) PullEvents() []events.DomainEvent {
+	evts := c.domainEvents
+	c.domainEvents = nil
+	return evts
+}
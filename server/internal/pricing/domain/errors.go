@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+var (
+	ErrExperimentNotFound      = errors.New("experiment not found")
+	ErrExperimentNameRequired  = errors.New("experiment name is required")
+	ErrTooFewVariants          = errors.New("experiment needs at least two variants")
+	ErrInvalidVariant          = errors.New("variant names must be unique and weights non-negative")
+	ErrInvalidVariantPrice     = errors.New("variant prices must be positive and name a SKU code")
+	ErrNoPriceOverrides        = errors.New("at least one variant must override a SKU price")
+	ErrInvalidExperimentWindow = errors.New("experiment must end after it starts and in the future")
+	ErrExperimentConflict      = errors.New("another experiment already varies these SKU prices on the same devices")
+	ErrExperimentEnded         = errors.New("experiment has already ended")
+
+	ErrCouponNotFound         = errors.New("coupon not found")
+	ErrInvalidCouponCode      = errors.New("coupon code is required and limited to 50 characters")
+	ErrInvalidCouponDiscount  = errors.New("coupon takes either a percentage between 1 and 100 or a positive amount off")
+	ErrInvalidCouponLimits    = errors.New("coupon minimum basket and redemption limit must not be negative")
+	ErrCouponCurrencyRequired = errors.New("coupon amounts need a 3-letter currency")
+	ErrInvalidCouponExpiry    = errors.New("coupon must expire in the future")
+	ErrCouponCodeTaken        = errors.New("another coupon already uses this code")
+	ErrCouponDisabled         = errors.New("coupon is already disabled")
+)
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type ExperimentCreated struct {
+	events.BaseEvent
+	ExperimentID valueobjects.ExperimentID
+	Name         string
+	SKUCodes     []string
+}
+
+func NewExperimentCreated(experimentID valueobjects.ExperimentID, name string, skuCodes []string) ExperimentCreated {
+	return ExperimentCreated{
+		BaseEvent:    events.NewBaseEvent(),
+		ExperimentID: experimentID,
+		Name:         name,
+		SKUCodes:     skuCodes,
+	}
+}
+
+func (ExperimentCreated) EventName() string { return "ExperimentCreated" }
+
+type ExperimentStopped struct {
+	events.BaseEvent
+	ExperimentID valueobjects.ExperimentID
+}
+
+func NewExperimentStopped(experimentID valueobjects.ExperimentID) ExperimentStopped {
+	return ExperimentStopped{
+		BaseEvent:    events.NewBaseEvent(),
+		ExperimentID: experimentID,
+	}
+}
+
+func (ExperimentStopped) EventName() string { return "ExperimentStopped" }
+
+type CouponCreated struct {
+	events.BaseEvent
+	CouponID valueobjects.CouponID
+	Code     string
+}
+
+func NewCouponCreated(couponID valueobjects.CouponID, code string) CouponCreated {
+	return CouponCreated{
+		BaseEvent: events.NewBaseEvent(),
+		CouponID:  couponID,
+		Code:      code,
+	}
+}
+
+func (CouponCreated) EventName() string { return "CouponCreated" }
+
+type CouponDisabled struct {
+	events.BaseEvent
+	CouponID valueobjects.CouponID
+	Code     string
+}
+
+func NewCouponDisabled(couponID valueobjects.CouponID, code string) CouponDisabled {
+	return CouponDisabled{
+		BaseEvent: events.NewBaseEvent(),
+		CouponID:  couponID,
+		Code:      code,
+	}
+}
+
+func (CouponDisabled) EventName() string { return "CouponDisabled" }
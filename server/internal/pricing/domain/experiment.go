@@ -0,0 +1,277 @@
+package domain
+
+import (
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type ExperimentStatus string
+
+const (
+	ExperimentStatusScheduled ExperimentStatus = "scheduled"
+	ExperimentStatusRunning   ExperimentStatus = "running"
+	ExperimentStatusEnded     ExperimentStatus = "ended"
+)
+
+// Variant is one arm of a price experiment. SKUs without a price in the
+// variant keep their catalog price, so a variant with no prices is a control.
+type Variant struct {
+	Name   string
+	Weight int              // relative share of devices assigned to this variant
+	Prices map[string]int64 // SKU code -> price in cents
+}
+
+// PriceFor returns the variant price for a SKU, if the variant overrides it
+func (v Variant) PriceFor(code string) (int64, bool) {
+	cents, ok := v.Prices[code]
+	return cents, ok
+}
+
+// Experiment is the aggregate root for a price experiment. Devices in the
+// cohort are split across variants deterministically, so a device shows the
+// same prices for the whole experiment.
+type Experiment struct {
+	id        valueobjects.ExperimentID
+	name      string
+	variants  []Variant
+	cohort    []string // device IDs; empty means every device
+	startsAt  time.Time
+	endsAt    time.Time
+	stoppedAt *time.Time
+	createdAt time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewExperiment defines a price experiment running in [startsAt, endsAt)
+func NewExperiment(name string, variants []Variant, cohort []string, startsAt, endsAt time.Time) (*Experiment, error) {
+	if name == "" {
+		return nil, ErrExperimentNameRequired
+	}
+	if len(variants) < 2 {
+		return nil, ErrTooFewVariants
+	}
+
+	now := time.Now().UTC()
+	if !endsAt.After(startsAt) || !endsAt.After(now) {
+		return nil, ErrInvalidExperimentWindow
+	}
+
+	names := make(map[string]bool)
+	normalized := make([]Variant, 0, len(variants))
+	overrides := 0
+	for _, v := range variants {
+		if v.Name == "" || names[v.Name] || v.Weight < 0 {
+			return nil, ErrInvalidVariant
+		}
+		names[v.Name] = true
+
+		if v.Weight == 0 {
+			v.Weight = 1
+		}
+		prices := make(map[string]int64, len(v.Prices))
+		for code, cents := range v.Prices {
+			if code == "" || cents <= 0 {
+				return nil, ErrInvalidVariantPrice
+			}
+			prices[code] = cents
+		}
+		v.Prices = prices
+		overrides += len(prices)
+		normalized = append(normalized, v)
+	}
+	if overrides == 0 {
+		return nil, ErrNoPriceOverrides
+	}
+
+	e := &Experiment{
+		id:        valueobjects.NewExperimentID(),
+		name:      name,
+		variants:  normalized,
+		cohort:    dedupe(cohort),
+		startsAt:  startsAt.UTC(),
+		endsAt:    endsAt.UTC(),
+		createdAt: now,
+	}
+
+	e.domainEvents = append(e.domainEvents, NewExperimentCreated(e.id, name, e.SKUCodes()))
+
+	return e, nil
+}
+
+// Reconstitute rebuilds an Experiment from persistence
+func Reconstitute(
+	id valueobjects.ExperimentID,
+	name string,
+	variants []Variant,
+	cohort []string,
+	startsAt, endsAt time.Time,
+	stoppedAt *time.Time,
+	createdAt time.Time,
+) *Experiment {
+	return &Experiment{
+		id:        id,
+		name:      name,
+		variants:  variants,
+		cohort:    cohort,
+		startsAt:  startsAt,
+		endsAt:    endsAt,
+		stoppedAt: stoppedAt,
+		createdAt: createdAt,
+	}
+}
+
+// Getters
+func (e *Experiment) ID() valueobjects.ExperimentID { return e.id }
+func (e *Experiment) Name() string                  { return e.name }
+func (e *Experiment) Variants() []Variant           { return append([]Variant{}, e.variants...) }
+func (e *Experiment) Cohort() []string              { return append([]string{}, e.cohort...) }
+func (e *Experiment) StartsAt() time.Time           { return e.startsAt }
+func (e *Experiment) EndsAt() time.Time             { return e.endsAt }
+func (e *Experiment) StoppedAt() *time.Time         { return e.stoppedAt }
+func (e *Experiment) CreatedAt() time.Time          { return e.createdAt }
+
+// SKUCodes lists the SKUs whose price is varied, sorted
+func (e *Experiment) SKUCodes() []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, v := range e.variants {
+		for code := range v.Prices {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// effectiveEnd is the scheduled end, or the stop time if stopped early
+func (e *Experiment) effectiveEnd() time.Time {
+	if e.stoppedAt != nil && e.stoppedAt.Before(e.endsAt) {
+		return *e.stoppedAt
+	}
+	return e.endsAt
+}
+
+func (e *Experiment) Status(at time.Time) ExperimentStatus {
+	switch {
+	case at.Before(e.startsAt):
+		return ExperimentStatusScheduled
+	case at.Before(e.effectiveEnd()):
+		return ExperimentStatusRunning
+	default:
+		return ExperimentStatusEnded
+	}
+}
+
+// Includes reports whether a device belongs to the experiment's cohort
+func (e *Experiment) Includes(deviceID string) bool {
+	if len(e.cohort) == 0 {
+		return true
+	}
+	for _, id := range e.cohort {
+		if id == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Assign returns the variant a device sees. Assignment hashes the experiment
+// and device IDs, so it is stable across requests and server instances.
+func (e *Experiment) Assign(deviceID string) (Variant, bool) {
+	if !e.Includes(deviceID) {
+		return Variant{}, false
+	}
+
+	total := 0
+	for _, v := range e.variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return Variant{}, false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.id.String() + ":" + deviceID))
+	bucket := int(h.Sum32() % uint32(total))
+	for _, v := range e.variants {
+		if bucket < v.Weight {
+			return v, true
+		}
+		bucket -= v.Weight
+	}
+	return Variant{}, false
+}
+
+// ConflictsWith reports whether two experiments could price the same SKU on
+// the same device at the same time
+func (e *Experiment) ConflictsWith(other *Experiment) bool {
+	if !e.startsAt.Before(other.effectiveEnd()) || !other.startsAt.Before(e.effectiveEnd()) {
+		return false
+	}
+
+	if len(e.cohort) > 0 && len(other.cohort) > 0 {
+		shared := false
+		for _, id := range e.cohort {
+			if other.Includes(id) {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			return false
+		}
+	}
+
+	otherCodes := make(map[string]bool)
+	for _, code := range other.SKUCodes() {
+		otherCodes[code] = true
+	}
+	for _, code := range e.SKUCodes() {
+		if otherCodes[code] {
+			return true
+		}
+	}
+	return false
+}
+
+// Business methods
+
+// Stop ends the experiment early; devices revert to catalog prices
+func (e *Experiment) Stop() error {
+	now := time.Now().UTC()
+	if e.Status(now) == ExperimentStatusEnded {
+		return ErrExperimentEnded
+	}
+
+	e.stoppedAt = &now
+	e.domainEvents = append(e.domainEvents, NewExperimentStopped(e.id))
+
+	return nil
+}
+
+// PullEvents returns and clears domain events
+func (e *Experiment) PullEvents() []events.DomainEvent {
+	evts := e.domainEvents
+	e.domainEvents = nil
+	return evts
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ExperimentRepository is the PORT interface defined by the domain
+type ExperimentRepository interface {
+	Save(ctx context.Context, experiment *Experiment) error
+	FindByID(ctx context.Context, id valueobjects.ExperimentID) (*Experiment, error)
+	FindAll(ctx context.Context) ([]*Experiment, error)
+	// FindNotEndedAt returns experiments that are scheduled or running at the given time
+	FindNotEndedAt(ctx context.Context, at time.Time) ([]*Experiment, error)
+}
+
+// CouponRepository is the PORT interface for coupons
+type CouponRepository interface {
+	// Save stores the coupon, or returns ErrCouponCodeTaken when another
+	// coupon has its code
+	Save(ctx context.Context, coupon *Coupon) error
+	FindByID(ctx context.Context, id valueobjects.CouponID) (*Coupon, error)
+	FindByCode(ctx context.Context, code string) (*Coupon, error)
+	FindAll(ctx context.Context) ([]*Coupon, error)
+}
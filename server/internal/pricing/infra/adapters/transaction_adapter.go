@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/pricing/app/ports"
+	transactionapi "github.com/vending-machine/server/internal/transaction/api"
+)
+
+// TransactionAdapter implements ports.OutcomeSource and ports.RedemptionSource
+// using the transaction context API
+type TransactionAdapter struct {
+	reader transactionapi.SessionReader
+}
+
+func NewTransactionAdapter(reader transactionapi.SessionReader) *TransactionAdapter {
+	if reader == nil {
+		panic("nil SessionReader")
+	}
+	return &TransactionAdapter{reader: reader}
+}
+
+func (a *TransactionAdapter) VariantOutcomes(ctx context.Context, experimentID string) ([]ports.VariantOutcome, error) {
+	views, err := a.reader.ExperimentOutcomes(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]ports.VariantOutcome, 0, len(views))
+	for _, view := range views {
+		outcomes = append(outcomes, ports.VariantOutcome{
+			Variant:           view.Variant,
+			Sessions:          view.Sessions,
+			CompletedSessions: view.CompletedSessions,
+			RevenueCents:      view.RevenueCents,
+			Currency:          view.Currency,
+		})
+	}
+	return outcomes, nil
+}
+
+func (a *TransactionAdapter) CouponRedemptions(ctx context.Context, code string) (int, error) {
+	return a.reader.CouponRedemptions(ctx, code)
+}
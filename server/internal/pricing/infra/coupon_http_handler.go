@@ -0,0 +1,140 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/pricing/app"
+	"github.com/vending-machine/server/internal/pricing/domain"
+)
+
+// Request/Response DTOs (HTTP layer only)
+
+type createCouponRequest struct {
+	Code           string     `json:"code" binding:"required"`
+	PercentOff     int        `json:"percent_off"`
+	AmountOffCents int64      `json:"amount_off_cents"`
+	MinBasketCents int64      `json:"min_basket_cents"`
+	Currency       string     `json:"currency"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type couponResponse struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Status         string     `json:"status"`
+	PercentOff     int        `json:"percent_off,omitempty"`
+	AmountOffCents int64      `json:"amount_off_cents,omitempty"`
+	MinBasketCents int64      `json:"min_basket_cents,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	Redemptions    int        `json:"redemptions"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Handlers
+
+func (h *HTTPHandler) CreateCoupon(c *gin.Context) {
+	var req createCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.createCouponHandler.Handle(c.Request.Context(), app.CreateCouponCommand{
+		Code:           req.Code,
+		PercentOff:     req.PercentOff,
+		AmountOffCents: req.AmountOffCents,
+		MinBasketCents: req.MinBasketCents,
+		Currency:       req.Currency,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+	})
+	if err != nil {
+		h.writeCouponError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"coupon_id": result.CouponID,
+		"code":      result.Code,
+	})
+}
+
+func (h *HTTPHandler) ListCoupons(c *gin.Context) {
+	views, err := h.couponQueryService.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]couponResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toCouponResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) GetCoupon(c *gin.Context) {
+	view, err := h.couponQueryService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeCouponError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCouponResponse(*view))
+}
+
+func (h *HTTPHandler) DisableCoupon(c *gin.Context) {
+	if err := h.disableCouponHandler.Handle(c.Request.Context(), c.Param("id")); err != nil {
+		h.writeCouponError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"coupon_id": c.Param("id"),
+		"status":    string(domain.CouponStatusDisabled),
+	})
+}
+
+func (h *HTTPHandler) writeCouponError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCouponNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCouponCodeTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCouponDisabled):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidCouponCode),
+		errors.Is(err, domain.ErrInvalidCouponDiscount),
+		errors.Is(err, domain.ErrInvalidCouponLimits),
+		errors.Is(err, domain.ErrCouponCurrencyRequired),
+		errors.Is(err, domain.ErrInvalidCouponExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toCouponResponse(v app.CouponView) couponResponse {
+	return couponResponse{
+		ID:             v.ID,
+		Code:           v.Code,
+		Status:         v.Status,
+		PercentOff:     v.PercentOff,
+		AmountOffCents: v.AmountOffCents,
+		MinBasketCents: v.MinBasketCents,
+		Currency:       v.Currency,
+		MaxRedemptions: v.MaxRedemptions,
+		Redemptions:    v.Redemptions,
+		ExpiresAt:      v.ExpiresAt,
+		DisabledAt:     v.DisabledAt,
+		CreatedAt:      v.CreatedAt,
+	}
+}
@@ -0,0 +1,219 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/pricing/app"
+	"github.com/vending-machine/server/internal/pricing/domain"
+)
+
+type HTTPHandler struct {
+	createHandler        *app.CreateExperimentHandler
+	stopHandler          *app.StopExperimentHandler
+	queryService         *app.ExperimentQueryService
+	createCouponHandler  *app.CreateCouponHandler
+	disableCouponHandler *app.DisableCouponHandler
+	couponQueryService   *app.CouponQueryService
+}
+
+func NewHTTPHandler(
+	createHandler *app.CreateExperimentHandler,
+	stopHandler *app.StopExperimentHandler,
+	queryService *app.ExperimentQueryService,
+	createCouponHandler *app.CreateCouponHandler,
+	disableCouponHandler *app.DisableCouponHandler,
+	couponQueryService *app.CouponQueryService,
+) *HTTPHandler {
+	return &HTTPHandler{
+		createHandler:        createHandler,
+		stopHandler:          stopHandler,
+		queryService:         queryService,
+		createCouponHandler:  createCouponHandler,
+		disableCouponHandler: disableCouponHandler,
+		couponQueryService:   couponQueryService,
+	}
+}
+
+// Request/Response DTOs (HTTP layer only)
+
+type createExperimentRequest struct {
+	Name      string           `json:"name" binding:"required"`
+	Variants  []variantRequest `json:"variants" binding:"required"`
+	DeviceIDs []string         `json:"device_ids"`
+	StartsAt  *time.Time       `json:"starts_at"`
+	EndsAt    time.Time        `json:"ends_at" binding:"required"`
+}
+
+type variantRequest struct {
+	Name   string           `json:"name"`
+	Weight int              `json:"weight"`
+	Prices map[string]int64 `json:"prices"`
+}
+
+type experimentResponse struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Variants  []variantResponse `json:"variants"`
+	DeviceIDs []string          `json:"device_ids"`
+	SKUCodes  []string          `json:"sku_codes"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	StoppedAt *time.Time        `json:"stopped_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type variantResponse struct {
+	Name   string           `json:"name"`
+	Weight int              `json:"weight"`
+	Prices map[string]int64 `json:"prices"`
+}
+
+type variantResultResponse struct {
+	Variant                string  `json:"variant"`
+	Sessions               int64   `json:"sessions"`
+	CompletedSessions      int64   `json:"completed_sessions"`
+	ConversionRate         float64 `json:"conversion_rate"`
+	RevenueCents           int64   `json:"revenue_cents"`
+	RevenuePerSessionCents float64 `json:"revenue_per_session_cents"`
+	Currency               string  `json:"currency,omitempty"`
+}
+
+// Handlers
+
+func (h *HTTPHandler) Create(c *gin.Context) {
+	var req createExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.CreateExperimentCommand{
+		Name:      req.Name,
+		DeviceIDs: req.DeviceIDs,
+		EndsAt:    req.EndsAt,
+	}
+	if req.StartsAt != nil {
+		cmd.StartsAt = *req.StartsAt
+	}
+	for _, v := range req.Variants {
+		cmd.Variants = append(cmd.Variants, app.VariantInput{Name: v.Name, Weight: v.Weight, Prices: v.Prices})
+	}
+
+	result, err := h.createHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"experiment_id": result.ExperimentID,
+		"status":        result.Status,
+	})
+}
+
+func (h *HTTPHandler) List(c *gin.Context) {
+	views, err := h.queryService.FindAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]experimentResponse, 0, len(views))
+	for _, v := range views {
+		response = append(response, toExperimentResponse(v))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) Get(c *gin.Context) {
+	view, err := h.queryService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toExperimentResponse(*view))
+}
+
+func (h *HTTPHandler) Stop(c *gin.Context) {
+	if err := h.stopHandler.Handle(c.Request.Context(), c.Param("id")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiment_id": c.Param("id"),
+		"status":        string(domain.ExperimentStatusEnded),
+	})
+}
+
+func (h *HTTPHandler) Results(c *gin.Context) {
+	report, err := h.queryService.Report(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	variants := make([]variantResultResponse, 0, len(report.Variants))
+	for _, v := range report.Variants {
+		variants = append(variants, variantResultResponse{
+			Variant:                v.Variant,
+			Sessions:               v.Sessions,
+			CompletedSessions:      v.CompletedSessions,
+			ConversionRate:         v.ConversionRate,
+			RevenueCents:           v.RevenueCents,
+			RevenuePerSessionCents: v.RevenuePerSessionCents,
+			Currency:               v.Currency,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiment": toExperimentResponse(report.Experiment),
+		"variants":   variants,
+	})
+}
+
+func (h *HTTPHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrExperimentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExperimentConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExperimentEnded):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExperimentNameRequired),
+		errors.Is(err, domain.ErrTooFewVariants),
+		errors.Is(err, domain.ErrInvalidVariant),
+		errors.Is(err, domain.ErrInvalidVariantPrice),
+		errors.Is(err, domain.ErrNoPriceOverrides),
+		errors.Is(err, domain.ErrInvalidExperimentWindow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func toExperimentResponse(v app.ExperimentView) experimentResponse {
+	variants := make([]variantResponse, 0, len(v.Variants))
+	for _, variant := range v.Variants {
+		variants = append(variants, variantResponse{Name: variant.Name, Weight: variant.Weight, Prices: variant.Prices})
+	}
+
+	return experimentResponse{
+		ID:        v.ID,
+		Name:      v.Name,
+		Status:    v.Status,
+		Variants:  variants,
+		DeviceIDs: v.DeviceIDs,
+		SKUCodes:  v.SKUCodes,
+		StartsAt:  v.StartsAt,
+		EndsAt:    v.EndsAt,
+		StoppedAt: v.StoppedAt,
+		CreatedAt: v.CreatedAt,
+	}
+}
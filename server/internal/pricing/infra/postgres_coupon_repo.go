@@ -0,0 +1,92 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresCouponRepository implements domain.CouponRepository
+type PostgresCouponRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCouponRepository(pool *pgxpool.Pool) *PostgresCouponRepository {
+	return &PostgresCouponRepository{pool: pool}
+}
+
+const couponColumns = `id, code, percent_off, amount_off_cents, min_basket_cents, currency, max_redemptions, expires_at, disabled_at, created_at`
+
+func (r *PostgresCouponRepository) Save(ctx context.Context, c *domain.Coupon) error {
+	terms := c.Terms()
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO coupons (`+couponColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			disabled_at = EXCLUDED.disabled_at
+	`, c.ID().String(), c.Code(), terms.PercentOff, terms.AmountOffCents, terms.MinBasketCents, terms.Currency,
+		terms.MaxRedemptions, c.ExpiresAt(), c.DisabledAt(), c.CreatedAt())
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrCouponCodeTaken
+	}
+	return err
+}
+
+func (r *PostgresCouponRepository) FindByID(ctx context.Context, id valueobjects.CouponID) (*domain.Coupon, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+couponColumns+` FROM coupons WHERE id = $1`, id.String())
+	return r.scanCoupon(row)
+}
+
+func (r *PostgresCouponRepository) FindByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+couponColumns+` FROM coupons WHERE code = $1`, code)
+	return r.scanCoupon(row)
+}
+
+func (r *PostgresCouponRepository) FindAll(ctx context.Context) ([]*domain.Coupon, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+couponColumns+` FROM coupons ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var coupons []*domain.Coupon
+	for rows.Next() {
+		c, err := r.scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, c)
+	}
+	return coupons, rows.Err()
+}
+
+func (r *PostgresCouponRepository) scanCoupon(row pgx.Row) (*domain.Coupon, error) {
+	var (
+		rawID, code           string
+		terms                 domain.CouponTerms
+		expiresAt, disabledAt *time.Time
+		createdAt             time.Time
+	)
+	err := row.Scan(
+		&rawID, &code, &terms.PercentOff, &terms.AmountOffCents, &terms.MinBasketCents, &terms.Currency,
+		&terms.MaxRedemptions, &expiresAt, &disabledAt, &createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	id, _ := valueobjects.CouponIDFrom(rawID)
+	return domain.ReconstituteCoupon(id, code, terms, expiresAt, disabledAt, createdAt), nil
+}
@@ -0,0 +1,139 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/pricing/domain"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// PostgresExperimentRepository implements domain.ExperimentRepository
+type PostgresExperimentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresExperimentRepository(pool *pgxpool.Pool) *PostgresExperimentRepository {
+	return &PostgresExperimentRepository{pool: pool}
+}
+
+// experimentRow is a DB-layer struct (never leaves this file)
+type experimentRow struct {
+	ID        string
+	Name      string
+	Variants  []byte
+	DeviceIDs []byte
+	StartsAt  time.Time
+	EndsAt    time.Time
+	StoppedAt *time.Time
+	CreatedAt time.Time
+}
+
+type variantJSON struct {
+	Name   string           `json:"name"`
+	Weight int              `json:"weight"`
+	Prices map[string]int64 `json:"prices"`
+}
+
+const experimentColumns = `id, name, variants, device_ids, starts_at, ends_at, stopped_at, created_at`
+
+func (r *PostgresExperimentRepository) Save(ctx context.Context, e *domain.Experiment) error {
+	var variants []variantJSON
+	for _, v := range e.Variants() {
+		variants = append(variants, variantJSON{Name: v.Name, Weight: v.Weight, Prices: v.Prices})
+	}
+	variantsData, _ := json.Marshal(variants)
+	deviceIDsData, _ := json.Marshal(e.Cohort())
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO price_experiments (`+experimentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			stopped_at = EXCLUDED.stopped_at
+	`, e.ID().String(), e.Name(), variantsData, deviceIDsData,
+		e.StartsAt(), e.EndsAt(), e.StoppedAt(), e.CreatedAt())
+
+	return err
+}
+
+func (r *PostgresExperimentRepository) FindByID(ctx context.Context, id valueobjects.ExperimentID) (*domain.Experiment, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+experimentColumns+` FROM price_experiments WHERE id = $1`, id.String())
+	return r.scanExperiment(row)
+}
+
+func (r *PostgresExperimentRepository) FindAll(ctx context.Context) ([]*domain.Experiment, error) {
+	return r.query(ctx, `SELECT `+experimentColumns+` FROM price_experiments ORDER BY starts_at DESC`)
+}
+
+func (r *PostgresExperimentRepository) FindNotEndedAt(ctx context.Context, at time.Time) ([]*domain.Experiment, error) {
+	return r.query(ctx, `
+		SELECT `+experimentColumns+`
+		FROM price_experiments
+		WHERE ends_at > $1 AND (stopped_at IS NULL OR stopped_at > $1)
+		ORDER BY starts_at
+	`, at)
+}
+
+func (r *PostgresExperimentRepository) query(ctx context.Context, sql string, args ...any) ([]*domain.Experiment, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var experiments []*domain.Experiment
+	for rows.Next() {
+		e, err := r.scanExperiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		experiments = append(experiments, e)
+	}
+	return experiments, rows.Err()
+}
+
+func (r *PostgresExperimentRepository) scanExperiment(row pgx.Row) (*domain.Experiment, error) {
+	var rec experimentRow
+	err := row.Scan(
+		&rec.ID, &rec.Name, &rec.Variants, &rec.DeviceIDs,
+		&rec.StartsAt, &rec.EndsAt, &rec.StoppedAt, &rec.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExperimentNotFound
+		}
+		return nil, err
+	}
+
+	return r.reconstitute(rec), nil
+}
+
+func (r *PostgresExperimentRepository) reconstitute(rec experimentRow) *domain.Experiment {
+	id, _ := valueobjects.ExperimentIDFrom(rec.ID)
+
+	var variantsJSON []variantJSON
+	_ = json.Unmarshal(rec.Variants, &variantsJSON)
+	variants := make([]domain.Variant, 0, len(variantsJSON))
+	for _, v := range variantsJSON {
+		variants = append(variants, domain.Variant{Name: v.Name, Weight: v.Weight, Prices: v.Prices})
+	}
+
+	var deviceIDs []string
+	_ = json.Unmarshal(rec.DeviceIDs, &deviceIDs)
+
+	return domain.Reconstitute(
+		id,
+		rec.Name,
+		variants,
+		deviceIDs,
+		rec.StartsAt,
+		rec.EndsAt,
+		rec.StoppedAt,
+		rec.CreatedAt,
+	)
+}
@@ -0,0 +1,23 @@
+package infra
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes registers the pricing context routes
+func (h *HTTPHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	experiments := rg.Group("/experiments")
+	{
+		experiments.POST("", h.Create)
+		experiments.GET("", h.List)
+		experiments.GET("/:id", h.Get)
+		experiments.POST("/:id/stop", h.Stop)
+		experiments.GET("/:id/results", h.Results)
+	}
+
+	coupons := rg.Group("/coupons")
+	{
+		coupons.POST("", h.CreateCoupon)
+		coupons.GET("", h.ListCoupons)
+		coupons.GET("/:id", h.GetCoupon)
+		coupons.POST("/:id/disable", h.DisableCoupon)
+	}
+}
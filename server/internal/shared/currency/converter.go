@@ -0,0 +1,54 @@
+// Package currency defines the port for converting money between currencies.
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ErrRateUnavailable is returned when there is no exchange rate between two currencies
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Converter is an output port for converting amounts between currencies
+type Converter interface {
+	// Convert returns the amount in the target currency, rounded to the cent.
+	// Amounts already in that currency come back unchanged.
+	Convert(ctx context.Context, amount valueobjects.Money, to string) (valueobjects.Money, error)
+}
+
+// Rates are exchange rates quoted against one base currency: one unit of
+// the base buys PerBase[c] units of currency c
+type Rates struct {
+	Base    string
+	PerBase map[string]float64
+}
+
+// Convert converts through the base currency; rounding halves away from zero
+func (r Rates) Convert(amount valueobjects.Money, to string) (valueobjects.Money, error) {
+	to = strings.ToUpper(to)
+	if strings.EqualFold(amount.Currency(), to) {
+		return amount, nil
+	}
+	from, ok := r.rate(amount.Currency())
+	if !ok {
+		return valueobjects.Money{}, ErrRateUnavailable
+	}
+	target, ok := r.rate(to)
+	if !ok {
+		return valueobjects.Money{}, ErrRateUnavailable
+	}
+	cents := math.Round(float64(amount.Amount()) / from * target)
+	return valueobjects.NewMoney(int64(cents), to)
+}
+
+func (r Rates) rate(code string) (float64, bool) {
+	if strings.EqualFold(code, r.Base) {
+		return 1, true
+	}
+	rate, ok := r.PerBase[strings.ToUpper(code)]
+	return rate, ok && rate > 0
+}
@@ -6,4 +6,9 @@ import "errors"
 var (
 	ErrInvalidConfidenceThreshold = errors.New("confidence threshold must be between 0 and 1")
 	ErrInvalidWeightTolerance     = errors.New("weight tolerance cannot be negative")
+	ErrInvalidRoundingIncrement   = errors.New("rounding increment must be positive")
+	ErrInvalidRoundingMode        = errors.New("rounding mode must be nearest, up or down")
+	ErrInvalidMarkdownPercent     = errors.New("markdown percent must be between 1 and 100")
+	ErrInvalidMarkdownDays        = errors.New("markdown days before expiry cannot be negative")
+	ErrInvalidTaxRate             = errors.New("tax rate must be between 0 and 100 percent")
 )
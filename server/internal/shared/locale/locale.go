@@ -0,0 +1,117 @@
+// Package locale picks translations for the languages a client prefers
+package locale
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Normalize returns the canonical form of a language tag: a language such as
+// "de", optionally with a region such as "de-CH". Anything else, including
+// longer tags, yields "".
+func Normalize(tag string) string {
+	language, region, hasRegion := strings.Cut(strings.TrimSpace(tag), "-")
+	if !isLetters(language, 2, 3) {
+		return ""
+	}
+	language = strings.ToLower(language)
+	if !hasRegion {
+		return language
+	}
+	if !isLetters(region, 2, 2) && !isDigits(region, 3) {
+		return ""
+	}
+	return language + "-" + strings.ToUpper(region)
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header, most
+// preferred first. Wildcards, tags refused with q=0 and malformed entries
+// are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = Normalize(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+
+	tags := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !slices.Contains(tags, e.tag) {
+			tags = append(tags, e.tag)
+		}
+	}
+	return tags
+}
+
+// Pick returns the translation for the first preferred tag it has one for.
+// A tag matches exactly, then by language: "de-CH" takes "de", and "de"
+// takes a regional variant such as "de-AT" when there is no plain "de".
+func Pick[T any](translations map[string]T, preferred []string) (T, bool) {
+	for _, tag := range preferred {
+		if t, ok := translations[tag]; ok {
+			return t, true
+		}
+		language, _, _ := strings.Cut(tag, "-")
+		if t, ok := translations[language]; ok {
+			return t, true
+		}
+		var variants []string
+		for available := range translations {
+			if strings.HasPrefix(available, language+"-") {
+				variants = append(variants, available)
+			}
+		}
+		if len(variants) > 0 {
+			slices.Sort(variants)
+			return translations[variants[0]], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func isLetters(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
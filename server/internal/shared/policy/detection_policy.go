@@ -1,6 +1,8 @@
 package policy
 
 import (
+	"math"
+
 	"github.com/vending-machine/server/internal/shared/errors"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
@@ -11,6 +13,16 @@ import (
 type DetectionPolicy struct {
 	confidenceThreshold  float64 // Minimum confidence to accept detection (0.0-1.0)
 	weightToleranceGrams float64 // Maximum weight difference in grams
+	weightSigmas         float64 // Standard deviations allowed for calibrated SKUs
+	scaleResolutionGrams float64 // Smallest tolerance, for calibrated SKUs that barely vary
+}
+
+// ItemWeight is what one detected item is expected to weigh. Calibrated items
+// carry the spread of their weighed samples; the others only a nominal weight.
+type ItemWeight struct {
+	Grams       float64
+	StdDevGrams float64
+	Calibrated  bool
 }
 
 // DefaultDetectionPolicy returns the standard detection policy
@@ -18,6 +30,8 @@ func DefaultDetectionPolicy() DetectionPolicy {
 	return DetectionPolicy{
 		confidenceThreshold:  0.80,
 		weightToleranceGrams: 10.0,
+		weightSigmas:         3.0,
+		scaleResolutionGrams: 2.0,
 	}
 }
 
@@ -29,10 +43,20 @@ func NewDetectionPolicy(confidenceThreshold, weightToleranceGrams float64) (Dete
 	if weightToleranceGrams < 0 {
 		return DetectionPolicy{}, errors.ErrInvalidWeightTolerance
 	}
-	return DetectionPolicy{
-		confidenceThreshold:  confidenceThreshold,
-		weightToleranceGrams: weightToleranceGrams,
-	}, nil
+	p := DefaultDetectionPolicy()
+	p.confidenceThreshold = confidenceThreshold
+	p.weightToleranceGrams = weightToleranceGrams
+	return p, nil
+}
+
+// WithConfidenceThreshold returns a copy of the policy with another minimum
+// confidence level, e.g. one configured for a device
+func (p DetectionPolicy) WithConfidenceThreshold(confidenceThreshold float64) (DetectionPolicy, error) {
+	if confidenceThreshold < 0 || confidenceThreshold > 1 {
+		return DetectionPolicy{}, errors.ErrInvalidConfidenceThreshold
+	}
+	p.confidenceThreshold = confidenceThreshold
+	return p, nil
 }
 
 // ConfidenceThreshold returns the minimum confidence level
@@ -54,3 +78,39 @@ func (p DetectionPolicy) IsConfidenceAcceptable(confidence float64) bool {
 func (p DetectionPolicy) IsWeightMatch(expected, measured valueobjects.Weight) bool {
 	return expected.IsWithinTolerance(measured, p.weightToleranceGrams)
 }
+
+// WeightToleranceFor returns how far a basket of the items may weigh from
+// their expected total. The spreads of calibrated items add up as variances,
+// of which weightSigmas standard deviations are allowed; any uncalibrated item
+// brings in the flat tolerance on top. A fully calibrated basket is never held
+// to less than the scale resolution.
+func (p DetectionPolicy) WeightToleranceFor(items []ItemWeight) float64 {
+	var variance float64
+	calibrated := 0
+	for _, item := range items {
+		if item.Calibrated {
+			variance += item.StdDevGrams * item.StdDevGrams
+			calibrated++
+		}
+	}
+	statistical := p.weightSigmas * math.Sqrt(variance)
+
+	if len(items) > 0 && calibrated == len(items) {
+		return math.Max(statistical, p.scaleResolutionGrams)
+	}
+	return p.weightToleranceGrams + statistical
+}
+
+// IsBasketWeightMatch checks the measured weight against the items' expected
+// total, within the tolerance their calibration allows
+func (p DetectionPolicy) IsBasketWeightMatch(items []ItemWeight, measured valueobjects.Weight) bool {
+	var expectedGrams float64
+	for _, item := range items {
+		expectedGrams += item.Grams
+	}
+	expected, err := valueobjects.NewWeight(expectedGrams)
+	if err != nil {
+		return false
+	}
+	return expected.IsWithinTolerance(measured, p.WeightToleranceFor(items))
+}
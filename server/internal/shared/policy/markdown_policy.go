@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/errors"
+)
+
+// MarkdownTier takes a percentage off the price once a batch is within
+// DaysLeft days of its expiry date (0 is the expiry day itself)
+type MarkdownTier struct {
+	DaysLeft int
+	Percent  int
+}
+
+// MarkdownPolicy holds the markdown tiers for batches approaching expiry.
+// A policy without tiers never marks down.
+type MarkdownPolicy struct {
+	tiers []MarkdownTier // sorted by DaysLeft ascending
+}
+
+// NoMarkdowns returns a policy that keeps every price unchanged
+func NoMarkdowns() MarkdownPolicy {
+	return MarkdownPolicy{}
+}
+
+// NewMarkdownPolicy creates a policy with validation
+func NewMarkdownPolicy(tiers []MarkdownTier) (MarkdownPolicy, error) {
+	sorted := append([]MarkdownTier(nil), tiers...)
+	for _, t := range sorted {
+		if t.DaysLeft < 0 {
+			return MarkdownPolicy{}, errors.ErrInvalidMarkdownDays
+		}
+		if t.Percent < 1 || t.Percent > 100 {
+			return MarkdownPolicy{}, errors.ErrInvalidMarkdownPercent
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].DaysLeft < sorted[j].DaysLeft })
+	return MarkdownPolicy{tiers: sorted}, nil
+}
+
+// ParseMarkdownPolicy reads tiers of the form "2=25,0=50": 25% off from two
+// days before expiry, 50% off on the expiry day
+func ParseMarkdownPolicy(raw string) (MarkdownPolicy, error) {
+	var tiers []MarkdownTier
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		daysRaw, percentRaw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return MarkdownPolicy{}, fmt.Errorf("invalid markdown tier %q", entry)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(daysRaw))
+		if err != nil {
+			return MarkdownPolicy{}, fmt.Errorf("invalid markdown tier %q: %w", entry, err)
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(percentRaw), "%"))
+		if err != nil {
+			return MarkdownPolicy{}, fmt.Errorf("invalid markdown tier %q: %w", entry, err)
+		}
+		tiers = append(tiers, MarkdownTier{DaysLeft: days, Percent: percent})
+	}
+	p, err := NewMarkdownPolicy(tiers)
+	if err != nil {
+		return MarkdownPolicy{}, fmt.Errorf("invalid markdown tiers %q: %w", raw, err)
+	}
+	return p, nil
+}
+
+// Tiers returns a copy of the tiers, nearest to expiry first
+func (p MarkdownPolicy) Tiers() []MarkdownTier {
+	return append([]MarkdownTier(nil), p.tiers...)
+}
+
+// PercentFor returns the markdown for a batch with the given days left
+// before expiry, from the tightest tier that applies; 0 means full price
+func (p MarkdownPolicy) PercentFor(daysLeft int) int {
+	if daysLeft < 0 {
+		return 0
+	}
+	for _, t := range p.tiers {
+		if daysLeft <= t.DaysLeft {
+			return t.Percent
+		}
+	}
+	return 0
+}
+
+// ApplyMarkdown takes a markdown percentage off a price in cents, rounding the
+// discount down so the customer never pays less than the tier says
+func ApplyMarkdown(cents int64, percent int) int64 {
+	if percent <= 0 {
+		return cents
+	}
+	if percent >= 100 {
+		return 0
+	}
+	return cents - cents*int64(percent)/100
+}
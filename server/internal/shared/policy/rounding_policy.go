@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/errors"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// RoundingMode decides which way an amount between two increments goes
+type RoundingMode string
+
+const (
+	RoundNearest RoundingMode = "nearest" // halves round up, e.g. Swiss 5-rappen rounding
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+)
+
+// RoundingRule rounds amounts of one currency to a multiple of an increment (in cents)
+type RoundingRule struct {
+	increment int64
+	mode      RoundingMode
+}
+
+// NewRoundingRule creates a rule with validation
+func NewRoundingRule(increment int64, mode RoundingMode) (RoundingRule, error) {
+	if increment <= 0 {
+		return RoundingRule{}, errors.ErrInvalidRoundingIncrement
+	}
+	switch mode {
+	case RoundNearest, RoundUp, RoundDown:
+	default:
+		return RoundingRule{}, errors.ErrInvalidRoundingMode
+	}
+	return RoundingRule{increment: increment, mode: mode}, nil
+}
+
+func (r RoundingRule) Increment() int64   { return r.increment }
+func (r RoundingRule) Mode() RoundingMode { return r.mode }
+
+// Round returns the amount rounded to the rule's increment
+func (r RoundingRule) Round(cents int64) int64 {
+	if r.increment <= 1 {
+		return cents
+	}
+	remainder := cents % r.increment
+	if remainder == 0 {
+		return cents
+	}
+	down := cents - remainder
+	switch r.mode {
+	case RoundUp:
+		return down + r.increment
+	case RoundDown:
+		return down
+	default:
+		if remainder*2 >= r.increment {
+			return down + r.increment
+		}
+		return down
+	}
+}
+
+// RoundingPolicy holds the rounding rule of every currency that needs one.
+// Currencies without a rule are charged to the cent.
+type RoundingPolicy struct {
+	rules map[string]RoundingRule
+}
+
+// NoRounding returns a policy that leaves every amount unchanged
+func NoRounding() RoundingPolicy {
+	return RoundingPolicy{}
+}
+
+// NewRoundingPolicy creates a policy from per-currency rules
+func NewRoundingPolicy(rules map[string]RoundingRule) RoundingPolicy {
+	p := RoundingPolicy{rules: make(map[string]RoundingRule, len(rules))}
+	for currency, rule := range rules {
+		p.rules[strings.ToUpper(currency)] = rule
+	}
+	return p
+}
+
+// ParseRoundingPolicy reads rules of the form "CHF=5,SEK=100:down"; the mode
+// defaults to nearest
+func ParseRoundingPolicy(raw string) (RoundingPolicy, error) {
+	rules := make(map[string]RoundingRule)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		currency, spec, ok := strings.Cut(entry, "=")
+		if !ok || len(strings.TrimSpace(currency)) != 3 {
+			return RoundingPolicy{}, fmt.Errorf("invalid rounding rule %q", entry)
+		}
+		incrementRaw, mode, hasMode := strings.Cut(spec, ":")
+		if !hasMode {
+			mode = string(RoundNearest)
+		}
+		increment, err := strconv.ParseInt(strings.TrimSpace(incrementRaw), 10, 64)
+		if err != nil {
+			return RoundingPolicy{}, fmt.Errorf("invalid rounding rule %q: %w", entry, err)
+		}
+		rule, err := NewRoundingRule(increment, RoundingMode(strings.TrimSpace(mode)))
+		if err != nil {
+			return RoundingPolicy{}, fmt.Errorf("invalid rounding rule %q: %w", entry, err)
+		}
+		rules[strings.TrimSpace(currency)] = rule
+	}
+	return NewRoundingPolicy(rules), nil
+}
+
+// RuleFor returns the rule of a currency, if it has one
+func (p RoundingPolicy) RuleFor(currency string) (RoundingRule, bool) {
+	rule, ok := p.rules[strings.ToUpper(currency)]
+	return rule, ok
+}
+
+// Round rounds an amount by its currency's rule and returns the rounded
+// amount with the difference (rounded minus original, in cents)
+func (p RoundingPolicy) Round(amount valueobjects.Money) (valueobjects.Money, int64) {
+	rule, ok := p.RuleFor(amount.Currency())
+	if !ok {
+		return amount, 0
+	}
+	rounded, err := valueobjects.NewMoney(rule.Round(amount.Amount()), amount.Currency())
+	if err != nil {
+		return amount, 0
+	}
+	return rounded, rounded.Amount() - amount.Amount()
+}
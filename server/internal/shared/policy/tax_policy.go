@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/errors"
+)
+
+// TaxRate is a tax rate in basis points, e.g. 810 for 8.1%
+type TaxRate int64
+
+// NewTaxRate creates a rate from a percentage with validation
+func NewTaxRate(percent float64) (TaxRate, error) {
+	if percent < 0 || percent > 100 || math.IsNaN(percent) {
+		return 0, errors.ErrInvalidTaxRate
+	}
+	return TaxRate(math.Round(percent * 100)), nil
+}
+
+// Percent returns the rate as a percentage
+func (r TaxRate) Percent() float64 { return float64(r) / 100 }
+
+// TaxOn returns the tax due on an amount, rounded half up to the cent. When
+// the amount already includes the tax, the included share is returned.
+func (r TaxRate) TaxOn(cents int64, included bool) int64 {
+	if r == 0 || cents == 0 {
+		return 0
+	}
+	base := int64(10000)
+	if included {
+		base += int64(r)
+	}
+	return (cents*int64(r)*2 + base) / (base * 2)
+}
+
+// TaxPolicy holds the tax rates of a deployment. A SKU category's rate wins
+// over the device region's, which wins over the default. Without any rule
+// nothing is taxed.
+type TaxPolicy struct {
+	included   bool // shelf prices already include tax
+	fallback   TaxRate
+	regions    map[string]TaxRate
+	categories map[string]TaxRate
+}
+
+// NoTax returns a policy that never charges tax
+func NoTax() TaxPolicy {
+	return TaxPolicy{}
+}
+
+// ParseTaxPolicy reads rules of the form
+// "default=8.1,region:eu-central=7.7,category:<category id>=2.6"; rates are
+// percentages. included says whether shelf prices already contain the tax.
+func ParseTaxPolicy(raw string, included bool) (TaxPolicy, error) {
+	p := TaxPolicy{
+		included:   included,
+		regions:    make(map[string]TaxRate),
+		categories: make(map[string]TaxRate),
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		scope, rateRaw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return TaxPolicy{}, fmt.Errorf("invalid tax rule %q", entry)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(rateRaw), 64)
+		if err != nil {
+			return TaxPolicy{}, fmt.Errorf("invalid tax rule %q: %w", entry, err)
+		}
+		rate, err := NewTaxRate(percent)
+		if err != nil {
+			return TaxPolicy{}, fmt.Errorf("invalid tax rule %q: %w", entry, err)
+		}
+
+		kind, key, _ := strings.Cut(strings.TrimSpace(scope), ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch {
+		case kind == "default" && key == "":
+			p.fallback = rate
+		case kind == "region" && key != "":
+			p.regions[key] = rate
+		case kind == "category" && key != "":
+			p.categories[key] = rate
+		default:
+			return TaxPolicy{}, fmt.Errorf("invalid tax rule %q: scope must be default, region:<name> or category:<id>", entry)
+		}
+	}
+	return p, nil
+}
+
+// IsZero reports whether the policy has no rate at all, so nothing is taxed
+func (p TaxPolicy) IsZero() bool {
+	return p.fallback == 0 && len(p.regions) == 0 && len(p.categories) == 0
+}
+
+// Included reports whether shelf prices already include tax
+func (p TaxPolicy) Included() bool { return p.included }
+
+// RateFor returns the rate of a SKU in the given category sold by a device in
+// the given region; either may be empty
+func (p TaxPolicy) RateFor(categoryID, region string) TaxRate {
+	if rate, ok := p.categories[strings.ToLower(categoryID)]; ok && categoryID != "" {
+		return rate
+	}
+	if rate, ok := p.regions[strings.ToLower(region)]; ok && region != "" {
+		return rate
+	}
+	return p.fallback
+}
@@ -46,6 +46,26 @@ func SKUIDFrom(raw string) (SKUID, error) {
 func (s SKUID) String() string { return s.value.String() }
 func (s SKUID) IsZero() bool   { return s.value == uuid.Nil }
 
+// CategoryID is a strongly-typed ID for product categories
+type CategoryID struct {
+	value uuid.UUID
+}
+
+func NewCategoryID() CategoryID {
+	return CategoryID{value: uuid.New()}
+}
+
+func CategoryIDFrom(raw string) (CategoryID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return CategoryID{}, errors.New("invalid category ID format")
+	}
+	return CategoryID{value: id}, nil
+}
+
+func (c CategoryID) String() string { return c.value.String() }
+func (c CategoryID) IsZero() bool   { return c.value == uuid.Nil }
+
 // SessionID is a strongly-typed ID for sessions
 type SessionID struct {
 	value uuid.UUID
@@ -105,3 +125,343 @@ func TransactionIDFrom(raw string) (TransactionID, error) {
 
 func (t TransactionID) String() string { return t.value.String() }
 func (t TransactionID) IsZero() bool   { return t.value == uuid.Nil }
+
+// RefundID is a strongly-typed ID for refunds
+type RefundID struct {
+	value uuid.UUID
+}
+
+func NewRefundID() RefundID {
+	return RefundID{value: uuid.New()}
+}
+
+func RefundIDFrom(raw string) (RefundID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return RefundID{}, errors.New("invalid refund ID format")
+	}
+	return RefundID{value: id}, nil
+}
+
+func (r RefundID) String() string { return r.value.String() }
+func (r RefundID) IsZero() bool   { return r.value == uuid.Nil }
+
+// InvoiceID is a strongly-typed ID for invoices
+type InvoiceID struct {
+	value uuid.UUID
+}
+
+func NewInvoiceID() InvoiceID {
+	return InvoiceID{value: uuid.New()}
+}
+
+func InvoiceIDFrom(raw string) (InvoiceID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return InvoiceID{}, errors.New("invalid invoice ID format")
+	}
+	return InvoiceID{value: id}, nil
+}
+
+func (i InvoiceID) String() string { return i.value.String() }
+func (i InvoiceID) IsZero() bool   { return i.value == uuid.Nil }
+
+// ExperimentID is a strongly-typed ID for price experiments
+type ExperimentID struct {
+	value uuid.UUID
+}
+
+func NewExperimentID() ExperimentID {
+	return ExperimentID{value: uuid.New()}
+}
+
+func ExperimentIDFrom(raw string) (ExperimentID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return ExperimentID{}, errors.New("invalid experiment ID format")
+	}
+	return ExperimentID{value: id}, nil
+}
+
+func (i ExperimentID) String() string { return i.value.String() }
+func (i ExperimentID) IsZero() bool   { return i.value == uuid.Nil }
+
+// ExcursionID is a strongly-typed ID for device temperature excursions
+type ExcursionID struct {
+	value uuid.UUID
+}
+
+func NewExcursionID() ExcursionID {
+	return ExcursionID{value: uuid.New()}
+}
+
+func ExcursionIDFrom(raw string) (ExcursionID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return ExcursionID{}, errors.New("invalid excursion ID format")
+	}
+	return ExcursionID{value: id}, nil
+}
+
+func (i ExcursionID) String() string { return i.value.String() }
+func (i ExcursionID) IsZero() bool   { return i.value == uuid.Nil }
+
+// IncidentID is a strongly-typed ID for device security incidents
+type IncidentID struct {
+	value uuid.UUID
+}
+
+func NewIncidentID() IncidentID {
+	return IncidentID{value: uuid.New()}
+}
+
+func IncidentIDFrom(raw string) (IncidentID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return IncidentID{}, errors.New("invalid incident ID format")
+	}
+	return IncidentID{value: id}, nil
+}
+
+func (i IncidentID) String() string { return i.value.String() }
+func (i IncidentID) IsZero() bool   { return i.value == uuid.Nil }
+
+// ComplianceReportID is a strongly-typed ID for planogram compliance reports
+type ComplianceReportID struct {
+	value uuid.UUID
+}
+
+func NewComplianceReportID() ComplianceReportID {
+	return ComplianceReportID{value: uuid.New()}
+}
+
+func ComplianceReportIDFrom(raw string) (ComplianceReportID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return ComplianceReportID{}, errors.New("invalid compliance report ID format")
+	}
+	return ComplianceReportID{value: id}, nil
+}
+
+func (c ComplianceReportID) String() string { return c.value.String() }
+func (c ComplianceReportID) IsZero() bool   { return c.value == uuid.Nil }
+
+// BatchID is a strongly-typed ID for stock batches
+type BatchID struct {
+	value uuid.UUID
+}
+
+func NewBatchID() BatchID {
+	return BatchID{value: uuid.New()}
+}
+
+func BatchIDFrom(raw string) (BatchID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return BatchID{}, errors.New("invalid batch ID format")
+	}
+	return BatchID{value: id}, nil
+}
+
+func (b BatchID) String() string { return b.value.String() }
+func (b BatchID) IsZero() bool   { return b.value == uuid.Nil }
+
+// TrainingImageID is a strongly-typed ID for SKU training images
+type TrainingImageID struct {
+	value uuid.UUID
+}
+
+func NewTrainingImageID() TrainingImageID {
+	return TrainingImageID{value: uuid.New()}
+}
+
+func TrainingImageIDFrom(raw string) (TrainingImageID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return TrainingImageID{}, errors.New("invalid training image ID format")
+	}
+	return TrainingImageID{value: id}, nil
+}
+
+func (t TrainingImageID) String() string { return t.value.String() }
+func (t TrainingImageID) IsZero() bool   { return t.value == uuid.Nil }
+
+// DeviceGroupID is a strongly-typed ID for device groups
+type DeviceGroupID struct {
+	value uuid.UUID
+}
+
+func NewDeviceGroupID() DeviceGroupID {
+	return DeviceGroupID{value: uuid.New()}
+}
+
+func DeviceGroupIDFrom(raw string) (DeviceGroupID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return DeviceGroupID{}, errors.New("invalid device group ID format")
+	}
+	return DeviceGroupID{value: id}, nil
+}
+
+func (g DeviceGroupID) String() string { return g.value.String() }
+func (g DeviceGroupID) IsZero() bool   { return g.value == uuid.Nil }
+
+// ReleaseID is a strongly-typed ID for firmware and app releases
+type ReleaseID struct {
+	value uuid.UUID
+}
+
+func NewReleaseID() ReleaseID {
+	return ReleaseID{value: uuid.New()}
+}
+
+func ReleaseIDFrom(raw string) (ReleaseID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return ReleaseID{}, errors.New("invalid release ID format")
+	}
+	return ReleaseID{value: id}, nil
+}
+
+func (r ReleaseID) String() string { return r.value.String() }
+func (r ReleaseID) IsZero() bool   { return r.value == uuid.Nil }
+
+// RolloutID is a strongly-typed ID for release rollouts
+type RolloutID struct {
+	value uuid.UUID
+}
+
+func NewRolloutID() RolloutID {
+	return RolloutID{value: uuid.New()}
+}
+
+func RolloutIDFrom(raw string) (RolloutID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return RolloutID{}, errors.New("invalid rollout ID format")
+	}
+	return RolloutID{value: id}, nil
+}
+
+func (r RolloutID) String() string { return r.value.String() }
+func (r RolloutID) IsZero() bool   { return r.value == uuid.Nil }
+
+// DeviceEventID is a strongly-typed ID for entries in a device's event log
+type DeviceEventID struct {
+	value uuid.UUID
+}
+
+func NewDeviceEventID() DeviceEventID {
+	return DeviceEventID{value: uuid.New()}
+}
+
+func DeviceEventIDFrom(raw string) (DeviceEventID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return DeviceEventID{}, errors.New("invalid device event ID format")
+	}
+	return DeviceEventID{value: id}, nil
+}
+
+func (e DeviceEventID) String() string { return e.value.String() }
+func (e DeviceEventID) IsZero() bool   { return e.value == uuid.Nil }
+
+// DeviceCommandID is a strongly-typed ID for commands queued for devices
+type DeviceCommandID struct {
+	value uuid.UUID
+}
+
+func NewDeviceCommandID() DeviceCommandID {
+	return DeviceCommandID{value: uuid.New()}
+}
+
+func DeviceCommandIDFrom(raw string) (DeviceCommandID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return DeviceCommandID{}, errors.New("invalid device command ID format")
+	}
+	return DeviceCommandID{value: id}, nil
+}
+
+func (c DeviceCommandID) String() string { return c.value.String() }
+func (c DeviceCommandID) IsZero() bool   { return c.value == uuid.Nil }
+
+// RestockSessionID is a strongly-typed ID for operator restock sessions
+type RestockSessionID struct {
+	value uuid.UUID
+}
+
+func NewRestockSessionID() RestockSessionID {
+	return RestockSessionID{value: uuid.New()}
+}
+
+func RestockSessionIDFrom(raw string) (RestockSessionID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return RestockSessionID{}, errors.New("invalid restock session ID format")
+	}
+	return RestockSessionID{value: id}, nil
+}
+
+func (r RestockSessionID) String() string { return r.value.String() }
+func (r RestockSessionID) IsZero() bool   { return r.value == uuid.Nil }
+
+// ScaleCalibrationID is a strongly-typed ID for device scale calibrations
+type ScaleCalibrationID struct {
+	value uuid.UUID
+}
+
+func NewScaleCalibrationID() ScaleCalibrationID {
+	return ScaleCalibrationID{value: uuid.New()}
+}
+
+func ScaleCalibrationIDFrom(raw string) (ScaleCalibrationID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return ScaleCalibrationID{}, errors.New("invalid scale calibration ID format")
+	}
+	return ScaleCalibrationID{value: id}, nil
+}
+
+func (s ScaleCalibrationID) String() string { return s.value.String() }
+func (s ScaleCalibrationID) IsZero() bool   { return s.value == uuid.Nil }
+
+// CouponID is a strongly-typed ID for checkout coupons
+type CouponID struct {
+	value uuid.UUID
+}
+
+func NewCouponID() CouponID {
+	return CouponID{value: uuid.New()}
+}
+
+func CouponIDFrom(raw string) (CouponID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return CouponID{}, errors.New("invalid coupon ID format")
+	}
+	return CouponID{value: id}, nil
+}
+
+func (c CouponID) String() string { return c.value.String() }
+func (c CouponID) IsZero() bool   { return c.value == uuid.Nil }
+
+// DisputeID is a strongly-typed ID for payment disputes
+type DisputeID struct {
+	value uuid.UUID
+}
+
+func NewDisputeID() DisputeID {
+	return DisputeID{value: uuid.New()}
+}
+
+func DisputeIDFrom(raw string) (DisputeID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return DisputeID{}, errors.New("invalid dispute ID format")
+	}
+	return DisputeID{value: id}, nil
+}
+
+func (d DisputeID) String() string { return d.value.String() }
+func (d DisputeID) IsZero() bool   { return d.value == uuid.Nil }
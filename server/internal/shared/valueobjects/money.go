@@ -3,8 +3,13 @@ package valueobjects
 import (
 	"errors"
 	"fmt"
+	"math"
 )
 
+// maxExactCents is the largest amount a float64 holds to the cent; Multiply
+// goes through a float64 and refuses results beyond it
+const maxExactCents = 1 << 53
+
 // Money is a Value Object representing monetary amounts
 type Money struct {
 	amount   int64  // stored in cents
@@ -28,9 +33,53 @@ func (m Money) Add(other Money) (Money, error) {
 	if m.currency != other.currency {
 		return Money{}, fmt.Errorf("cannot add %s to %s", other.currency, m.currency)
 	}
+	if other.amount > math.MaxInt64-m.amount {
+		return Money{}, errors.New("money amount out of range")
+	}
 	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
 }
 
+// Subtract takes other off m; money cannot go below zero
+func (m Money) Subtract(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, fmt.Errorf("cannot subtract %s from %s", other.currency, m.currency)
+	}
+	if other.amount > m.amount {
+		return Money{}, errors.New("money amount cannot be negative")
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Multiply scales m by rate, rounding half away from zero to the cent. It
+// fails when m or the result is too large to be scaled to the cent.
+func (m Money) Multiply(rate float64) (Money, error) {
+	if rate < 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return Money{}, errors.New("money can only be multiplied by a non-negative rate")
+	}
+	scaled := math.Round(float64(m.amount) * rate)
+	if m.amount > maxExactCents || scaled > maxExactCents {
+		return Money{}, errors.New("money amount out of range")
+	}
+	return Money{amount: int64(scaled), currency: m.currency}, nil
+}
+
+// Allocate splits m into n parts that add up to it to the cent. The parts
+// differ by at most a cent, the first ones taking the cents left over.
+func (m Money) Allocate(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("money must be allocated to at least one part")
+	}
+	share, leftover := m.amount/int64(n), m.amount%int64(n)
+	parts := make([]Money, n)
+	for i := range parts {
+		parts[i] = Money{amount: share, currency: m.currency}
+		if int64(i) < leftover {
+			parts[i].amount++
+		}
+	}
+	return parts, nil
+}
+
 func (m Money) Equals(other Money) bool {
 	return m.amount == other.amount && m.currency == other.currency
 }
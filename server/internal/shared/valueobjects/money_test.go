@@ -0,0 +1,162 @@
+package valueobjects
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+// cents keeps generated amounts far enough from the int64 limits that sums
+// cannot overflow
+func cents(n int64) int64 {
+	if n < 0 {
+		n = -n
+	}
+	return n % 1_000_000_000_000
+}
+
+func TestMoneyAllocatePartsAddUpAndDifferByAtMostACent(t *testing.T) {
+	property := func(amount int64, n uint8) bool {
+		parts := int(n%50) + 1
+		m, err := NewMoney(cents(amount), "EUR")
+		if err != nil {
+			return false
+		}
+		allocated, err := m.Allocate(parts)
+		if err != nil || len(allocated) != parts {
+			return false
+		}
+
+		var sum int64
+		least, most := allocated[0].Amount(), allocated[0].Amount()
+		for _, part := range allocated {
+			if part.Currency() != m.Currency() {
+				return false
+			}
+			sum += part.Amount()
+			least, most = min(least, part.Amount()), max(most, part.Amount())
+		}
+		return sum == m.Amount() && most-least <= 1
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoneyAllocateRejectsNoParts(t *testing.T) {
+	m, _ := NewMoney(100, "EUR")
+	for _, n := range []int{0, -1} {
+		if _, err := m.Allocate(n); err == nil {
+			t.Errorf("Allocate(%d) succeeded", n)
+		}
+	}
+}
+
+func TestMoneySubtractUndoesAdd(t *testing.T) {
+	property := func(a, b int64) bool {
+		m, _ := NewMoney(cents(a), "EUR")
+		other, _ := NewMoney(cents(b), "EUR")
+		sum, err := m.Add(other)
+		if err != nil {
+			return false
+		}
+		back, err := sum.Subtract(other)
+		return err == nil && back.Equals(m)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoneySubtractNeverGoesNegative(t *testing.T) {
+	property := func(a, b int64) bool {
+		m, _ := NewMoney(cents(a), "EUR")
+		other, _ := NewMoney(cents(b), "EUR")
+		diff, err := m.Subtract(other)
+		if other.Amount() > m.Amount() {
+			return err != nil
+		}
+		return err == nil && diff.Amount() == m.Amount()-other.Amount()
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoneyRejectsMixedCurrencies(t *testing.T) {
+	eur, _ := NewMoney(100, "EUR")
+	usd, _ := NewMoney(100, "USD")
+	if _, err := eur.Add(usd); err == nil {
+		t.Error("Add mixed EUR and USD")
+	}
+	if _, err := eur.Subtract(usd); err == nil {
+		t.Error("Subtract mixed EUR and USD")
+	}
+}
+
+func TestMoneyMultiply(t *testing.T) {
+	tests := []struct {
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{255, 0.1, 26}, // half a cent rounds up
+		{254, 0.1, 25},
+		{300, 200.0 / 350, 171},
+		{999, 1, 999},
+		{999, 0, 0},
+	}
+	for _, tt := range tests {
+		m, _ := NewMoney(tt.amount, "EUR")
+		got, err := m.Multiply(tt.rate)
+		if err != nil {
+			t.Fatalf("Multiply(%v): %v", tt.rate, err)
+		}
+		if got.Amount() != tt.want {
+			t.Errorf("%d * %v = %d, want %d", tt.amount, tt.rate, got.Amount(), tt.want)
+		}
+	}
+}
+
+func TestMoneyMultiplyStaysWithinTheAmountForRatesUpToOne(t *testing.T) {
+	property := func(amount int64, num, den uint16) bool {
+		rate := float64(num%(den+1)) / float64(den+1)
+		m, _ := NewMoney(cents(amount), "EUR")
+		got, err := m.Multiply(rate)
+		return err == nil && got.Amount() >= 0 && got.Amount() <= m.Amount()
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoneyMultiplyRejectsAmountsOutOfRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		rate   float64
+	}{
+		{"result overflows", math.MaxInt64 / 2, 4},
+		{"result beyond exact cents", maxExactCents, 2},
+		{"amount beyond exact cents", maxExactCents + 1, 0.5},
+	}
+	for _, tt := range tests {
+		m, _ := NewMoney(tt.amount, "EUR")
+		if got, err := m.Multiply(tt.rate); err == nil {
+			t.Errorf("%s: %d * %v = %d, want an error", tt.name, tt.amount, tt.rate, got.Amount())
+		}
+	}
+
+	m, _ := NewMoney(maxExactCents, "EUR")
+	if got, err := m.Multiply(1); err != nil || got.Amount() != maxExactCents {
+		t.Errorf("%d * 1 = %d, %v; want it unchanged", int64(maxExactCents), got.Amount(), err)
+	}
+}
+
+func TestMoneyAddRejectsOverflow(t *testing.T) {
+	m, _ := NewMoney(math.MaxInt64, "EUR")
+	cent, _ := NewMoney(1, "EUR")
+	if sum, err := m.Add(cent); err == nil {
+		t.Errorf("MaxInt64 + 1 = %d, want an error", sum.Amount())
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// EventTopic is the topic transaction events are published on
+const EventTopic = "transaction"
+
+// Transaction message types, versioned like the catalog's
+const (
+	EventSaleRecorded = "transaction.sale.recorded"
+)
+
+// SaleRecordedPayload is the payload of transaction.sale.recorded, version 1
+type SaleRecordedPayload struct {
+	TransactionID string `json:"transaction_id"`
+	SessionID     string `json:"session_id"`
+	DeviceID      string `json:"device_id"`
+	TotalCents    int64  `json:"total_cents"`
+	Currency      string `json:"currency"`
+	Units         int    `json:"units"`
+	PaymentRef    string `json:"payment_ref,omitempty"`
+}
+
+// EncodeEvent is the messaging.Encoder of transaction events. Sales are
+// staged on the topic in the unit of work that records them (see
+// messaging.Outbox), keyed by the session they closed.
+func EncodeEvent(event events.DomainEvent) (messaging.Message, bool) {
+	e, ok := event.(domain.TransactionRecorded)
+	if !ok {
+		return messaging.Message{}, false
+	}
+
+	data, err := json.Marshal(SaleRecordedPayload{
+		TransactionID: e.TransactionID.String(),
+		SessionID:     e.SessionID.String(),
+		DeviceID:      e.DeviceID.String(),
+		TotalCents:    e.Total.Amount(),
+		Currency:      e.Total.Currency(),
+		Units:         e.Units,
+		PaymentRef:    e.PaymentRef,
+	})
+	if err != nil {
+		return messaging.Message{}, false
+	}
+	return messaging.Message{
+		Topic:      EventTopic,
+		Type:       EventSaleRecorded,
+		Version:    1,
+		Key:        e.SessionID.String(),
+		OccurredAt: event.OccurredAt(),
+		Payload:    data,
+	}, true
+}
+
+// CompletedSessionID returns the session a SessionCompleted event completed,
+// so other contexts can react to sales without importing the transaction domain
+func CompletedSessionID(evt events.DomainEvent) (string, bool) {
+	completed, ok := evt.(domain.SessionCompleted)
+	if !ok {
+		return "", false
+	}
+	return completed.SessionID.String(), true
+}
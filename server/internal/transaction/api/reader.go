@@ -2,34 +2,71 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/vending-machine/server/internal/transaction/app"
 )
 
 // SessionView is the DTO exposed to other contexts
 type SessionView struct {
-	ID          string
-	DeviceID    string
-	UserID      string
-	Status      string
-	TotalCents  int64
-	Currency    string
-	TotalWeight float64
+	ID             string
+	DeviceID       string
+	UserID         string
+	Status         string
+	TotalCents     int64
+	Currency       string
+	TotalWeight    float64
+	Items          []SessionItemView
+	CompletedAt    *time.Time
+	FiscalDocument string // fiscal receipt number of the sale, empty where none is required
+}
+
+// SessionItemView is a line of purchased items exposed to other contexts
+type SessionItemView struct {
+	Code       string
+	Name       string
+	PriceCents int64 // of one unit
+	Quantity   int
+	Currency   string
+}
+
+// ExperimentOutcomeView summarises the sessions of one price experiment variant
+type ExperimentOutcomeView struct {
+	Variant           string
+	Sessions          int64
+	CompletedSessions int64
+	RevenueCents      int64
+	Currency          string
+}
+
+// DeviceActivityView describes the latest session on a device
+type DeviceActivityView struct {
+	HasActiveSession bool
+	LastCompletedAt  *time.Time
 }
 
 // SessionReader is the interface exposed to other contexts for reading session data
 type SessionReader interface {
 	FindByID(ctx context.Context, id string) (*SessionView, error)
 	FindActiveByDeviceID(ctx context.Context, deviceID string) (*SessionView, error)
+	FindCompletedByUser(ctx context.Context, userID string, from, to time.Time) ([]*SessionView, error)
+	ExperimentOutcomes(ctx context.Context, experimentID string) ([]ExperimentOutcomeView, error)
+	DeviceActivity(ctx context.Context, deviceID string) (*DeviceActivityView, error)
+	// SoldUnits counts the units of each SKU sold on the device since the given time for that SKU
+	SoldUnits(ctx context.Context, deviceID string, since map[string]time.Time) (map[string]int, error)
+	// CouponRedemptions counts the completed sales checked out with the coupon
+	CouponRedemptions(ctx context.Context, code string) (int, error)
 }
 
-// SessionReaderAdapter implements SessionReader using the app layer query service
+// SessionReaderAdapter implements SessionReader using the app layer query
+// services; completed sales are read from their transactions
 type SessionReaderAdapter struct {
 	queryService *app.SessionQueryService
+	transactions *app.TransactionQueryService
 }
 
-func NewSessionReaderAdapter(queryService *app.SessionQueryService) *SessionReaderAdapter {
-	return &SessionReaderAdapter{queryService: queryService}
+func NewSessionReaderAdapter(queryService *app.SessionQueryService, transactions *app.TransactionQueryService) *SessionReaderAdapter {
+	return &SessionReaderAdapter{queryService: queryService, transactions: transactions}
 }
 
 func (a *SessionReaderAdapter) FindByID(ctx context.Context, id string) (*SessionView, error) {
@@ -38,6 +75,89 @@ func (a *SessionReaderAdapter) FindByID(ctx context.Context, id string) (*Sessio
 		return nil, err
 	}
 
+	return toSessionView(view), nil
+}
+
+func (a *SessionReaderAdapter) FindActiveByDeviceID(ctx context.Context, deviceID string) (*SessionView, error) {
+	view, err := a.queryService.FindActiveByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSessionView(view), nil
+}
+
+func (a *SessionReaderAdapter) FindCompletedByUser(ctx context.Context, userID string, from, to time.Time) ([]*SessionView, error) {
+	views, err := a.transactions.FindCompletedByUser(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*SessionView, 0, len(views))
+	for _, view := range views {
+		out = append(out, transactionToSessionView(view))
+	}
+	return out, nil
+}
+
+func (a *SessionReaderAdapter) ExperimentOutcomes(ctx context.Context, experimentID string) ([]ExperimentOutcomeView, error) {
+	stats, err := a.queryService.ExperimentOutcomes(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ExperimentOutcomeView, 0, len(stats))
+	for _, st := range stats {
+		out = append(out, ExperimentOutcomeView{
+			Variant:           st.Variant,
+			Sessions:          st.Sessions,
+			CompletedSessions: st.CompletedSessions,
+			RevenueCents:      st.RevenueCents,
+			Currency:          st.Currency,
+		})
+	}
+	return out, nil
+}
+
+func (a *SessionReaderAdapter) DeviceActivity(ctx context.Context, deviceID string) (*DeviceActivityView, error) {
+	view, err := a.queryService.DeviceActivity(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceActivityView{
+		HasActiveSession: view.HasActiveSession,
+		LastCompletedAt:  view.LastCompletedAt,
+	}, nil
+}
+
+func (a *SessionReaderAdapter) SoldUnits(ctx context.Context, deviceID string, since map[string]time.Time) (map[string]int, error) {
+	return a.transactions.SoldUnits(ctx, deviceID, since)
+}
+
+func (a *SessionReaderAdapter) CouponRedemptions(ctx context.Context, code string) (int, error) {
+	return a.transactions.CouponRedemptions(ctx, code)
+}
+
+func toSessionView(view *app.SessionView) *SessionView {
+	items := make([]SessionItemView, 0, len(view.Items))
+	for _, item := range view.Items {
+		items = append(items, SessionItemView{
+			Code:       item.Code,
+			Name:       item.Name,
+			PriceCents: item.PriceCents,
+			Quantity:   item.Quantity,
+			Currency:   item.Currency,
+		})
+	}
+
+	var completedAt *time.Time
+	if view.CompletedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *view.CompletedAt); err == nil {
+			completedAt = &t
+		}
+	}
+
 	return &SessionView{
 		ID:          view.ID,
 		DeviceID:    view.DeviceID,
@@ -46,22 +166,37 @@ func (a *SessionReaderAdapter) FindByID(ctx context.Context, id string) (*Sessio
 		TotalCents:  view.TotalCents,
 		Currency:    view.Currency,
 		TotalWeight: view.TotalWeight,
-	}, nil
+		Items:       items,
+		CompletedAt: completedAt,
+	}
 }
 
-func (a *SessionReaderAdapter) FindActiveByDeviceID(ctx context.Context, deviceID string) (*SessionView, error) {
-	view, err := a.queryService.FindActiveByDeviceID(ctx, deviceID)
-	if err != nil {
-		return nil, err
+// transactionToSessionView shows a completed sale as the session it closed
+func transactionToSessionView(view *app.TransactionView) *SessionView {
+	items := make([]SessionItemView, 0, len(view.Lines))
+	for _, line := range view.Lines {
+		items = append(items, SessionItemView{
+			Code:       line.Code,
+			Name:       line.Name,
+			PriceCents: line.PriceCents,
+			Quantity:   line.Quantity,
+			Currency:   line.Currency,
+		})
 	}
 
-	return &SessionView{
-		ID:          view.ID,
+	completedAt := view.CompletedAt
+	out := &SessionView{
+		ID:          view.SessionID,
 		DeviceID:    view.DeviceID,
 		UserID:      view.UserID,
 		Status:      view.Status,
 		TotalCents:  view.TotalCents,
 		Currency:    view.Currency,
-		TotalWeight: view.TotalWeight,
-	}, nil
+		Items:       items,
+		CompletedAt: &completedAt,
+	}
+	if view.Fiscal != nil {
+		out.FiscalDocument = view.Fiscal.DocumentNumber
+	}
+	return out
 }
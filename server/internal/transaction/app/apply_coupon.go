@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// CouponChecker tells whether a coupon can be used on a session's basket: it
+// has to be active, not used up, in the basket's currency and the basket has
+// to reach its minimum
+type CouponChecker struct {
+	coupons      ports.Coupons
+	transactions domain.TransactionRepository
+}
+
+func NewCouponChecker(coupons ports.Coupons, transactions domain.TransactionRepository) *CouponChecker {
+	if coupons == nil {
+		panic("nil Coupons")
+	}
+	if transactions == nil {
+		panic("nil TransactionRepository")
+	}
+	return &CouponChecker{coupons: coupons, transactions: transactions}
+}
+
+// Check returns the coupon with the code as it applies to the session
+func (c *CouponChecker) Check(ctx context.Context, sess *domain.Session, code string) (domain.AppliedCoupon, error) {
+	coupon, err := c.coupons.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, ports.ErrCouponNotFound) {
+			return domain.AppliedCoupon{}, domain.ErrCouponNotFound
+		}
+		return domain.AppliedCoupon{}, fmt.Errorf("failed to find coupon: %w", err)
+	}
+
+	switch coupon.Status {
+	case "expired":
+		return domain.AppliedCoupon{}, domain.ErrCouponExpired
+	case "disabled":
+		return domain.AppliedCoupon{}, domain.ErrCouponDisabled
+	}
+
+	items := sess.DetectedItems()
+	if len(items) == 0 {
+		return domain.AppliedCoupon{}, domain.ErrNoItemsDetected
+	}
+	if coupon.Currency != "" && coupon.Currency != items[0].Price().Currency() {
+		return domain.AppliedCoupon{}, domain.ErrCouponCurrencyMismatch
+	}
+	itemsCents, err := sess.ItemsCents()
+	if err != nil {
+		return domain.AppliedCoupon{}, err
+	}
+	if itemsCents < coupon.MinBasketCents {
+		return domain.AppliedCoupon{}, domain.ErrCouponBasketTooSmall
+	}
+
+	if coupon.MaxRedemptions > 0 {
+		redemptions, err := c.transactions.CouponRedemptions(ctx, coupon.Code)
+		if err != nil {
+			return domain.AppliedCoupon{}, fmt.Errorf("failed to count coupon redemptions: %w", err)
+		}
+		if redemptions >= coupon.MaxRedemptions {
+			return domain.AppliedCoupon{}, domain.ErrCouponExhausted
+		}
+	}
+
+	return domain.NewAppliedCoupon(coupon.Code, coupon.PercentOff, coupon.AmountOffCents), nil
+}
+
+// Redeem checks the session's coupon once more as its sale is recorded. It
+// runs in the unit of work saving the sale and holds the coupon until the
+// work is done, so a coupon is never redeemed more often than allowed.
+func (c *CouponChecker) Redeem(ctx context.Context, sess *domain.Session) error {
+	code := sess.Coupon().Code()
+	if code == "" {
+		return nil
+	}
+	if err := c.transactions.HoldCoupon(ctx, code); err != nil {
+		return fmt.Errorf("failed to hold coupon: %w", err)
+	}
+	_, err := c.Check(ctx, sess, code)
+	return err
+}
+
+// ApplyCouponCommand is the input DTO for applying a coupon to a session
+type ApplyCouponCommand struct {
+	SessionID string
+	Code      string
+}
+
+// ApplyCouponResult is the output DTO
+type ApplyCouponResult struct {
+	SessionID     string
+	Code          string
+	DiscountCents int64
+	TotalCents    int64
+	Currency      string
+}
+
+// ApplyCouponHandler takes a coupon off a session's basket and prices it
+// again, keeping the guest checkout payment intent in sync
+type ApplyCouponHandler struct {
+	sessions  domain.SessionRepository
+	checker   *CouponChecker
+	taxes     *TaxAssessor
+	payments  ports.PaymentGateway
+	rounding  policy.RoundingPolicy
+	publisher eventPublisher
+}
+
+func NewApplyCouponHandler(
+	sessions domain.SessionRepository,
+	checker *CouponChecker,
+	taxes *TaxAssessor,
+	payments ports.PaymentGateway,
+	rounding policy.RoundingPolicy,
+	publisher eventPublisher,
+) *ApplyCouponHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if checker == nil {
+		panic("nil CouponChecker")
+	}
+	if taxes == nil {
+		panic("nil TaxAssessor")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ApplyCouponHandler{
+		sessions:  sessions,
+		checker:   checker,
+		taxes:     taxes,
+		payments:  payments,
+		rounding:  rounding,
+		publisher: publisher,
+	}
+}
+
+func (h *ApplyCouponHandler) Handle(ctx context.Context, cmd ApplyCouponCommand) (ApplyCouponResult, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return ApplyCouponResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return ApplyCouponResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return ApplyCouponResult{}, domain.ErrSessionNotActive
+	}
+	if sess.FraudBlocked() {
+		return ApplyCouponResult{}, domain.ErrFraudSuspected
+	}
+
+	coupon, err := h.checker.Check(ctx, sess, cmd.Code)
+	if err != nil {
+		return ApplyCouponResult{}, err
+	}
+	if err := sess.ApplyCoupon(coupon, h.rounding); err != nil {
+		return ApplyCouponResult{}, err
+	}
+
+	// Tax is charged on the discounted basket
+	tax, err := h.taxes.Assess(ctx, sess)
+	if err != nil {
+		return ApplyCouponResult{}, fmt.Errorf("failed to assess tax: %w", err)
+	}
+	if err := sess.ApplyTax(tax, h.rounding); err != nil {
+		return ApplyCouponResult{}, err
+	}
+	total := sess.TotalAmount()
+
+	if sess.PaymentIntentID() != "" {
+		_, err := h.payments.UpdateIntentAmount(ctx, sess.PaymentIntentID(), total.Amount(), total.Currency())
+		if err != nil {
+			return ApplyCouponResult{}, fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+		}
+	}
+
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return ApplyCouponResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range sess.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	discount, err := sess.DiscountCents()
+	if err != nil {
+		return ApplyCouponResult{}, err
+	}
+	return ApplyCouponResult{
+		SessionID:     sess.ID().String(),
+		Code:          coupon.Code(),
+		DiscountCents: discount,
+		TotalCents:    total.Amount(),
+		Currency:      total.Currency(),
+	}, nil
+}
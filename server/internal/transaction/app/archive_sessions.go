@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// DefaultArchiveBatchSize is how many sessions are archived at a time when
+// the policy does not say
+const DefaultArchiveBatchSize = 500
+
+// ArchivePolicy sets how long ended sessions stay in the hot table and how
+// many are moved to the archive at a time
+type ArchivePolicy struct {
+	After     time.Duration
+	BatchSize int
+}
+
+// ArchiveSessionsCommand is the input DTO for one archival run
+type ArchiveSessionsCommand struct {
+	After *time.Duration // nil uses the policy's age
+}
+
+// ArchiveSessionsResult is the output DTO
+type ArchiveSessionsResult struct {
+	After    time.Duration
+	Archived int
+}
+
+// ArchiveSessionsHandler moves the sessions that ended and started longer
+// than the policy's age ago to the archive, a batch at a time so that no
+// run holds many sessions locked
+type ArchiveSessionsHandler struct {
+	archive domain.SessionArchive
+	policy  ArchivePolicy
+}
+
+func NewArchiveSessionsHandler(archive domain.SessionArchive, policy ArchivePolicy) *ArchiveSessionsHandler {
+	if archive == nil {
+		panic("nil SessionArchive")
+	}
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = DefaultArchiveBatchSize
+	}
+	return &ArchiveSessionsHandler{archive: archive, policy: policy}
+}
+
+func (h *ArchiveSessionsHandler) Handle(ctx context.Context, cmd ArchiveSessionsCommand) (ArchiveSessionsResult, error) {
+	after := h.policy.After
+	if cmd.After != nil {
+		after = *cmd.After
+	}
+
+	startedBefore := time.Now().UTC().Add(-after)
+	result := ArchiveSessionsResult{After: after}
+	for {
+		archived, err := h.archive.ArchiveEnded(ctx, startedBefore, h.policy.BatchSize)
+		result.Archived += archived
+		if err != nil {
+			return result, fmt.Errorf("failed to archive sessions: %w", err)
+		}
+		if archived < h.policy.BatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// CancelDeviceSessionCommand is the input DTO for cancelling the session open
+// on a device taken out of service
+type CancelDeviceSessionCommand struct {
+	DeviceID string
+	Reason   string
+}
+
+// CancelDeviceSessionHandler cancels the active session on a device, if any
+type CancelDeviceSessionHandler struct {
+	sessions  domain.SessionRepository
+	publisher eventPublisher
+}
+
+func NewCancelDeviceSessionHandler(sessions domain.SessionRepository, publisher eventPublisher) *CancelDeviceSessionHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CancelDeviceSessionHandler{
+		sessions:  sessions,
+		publisher: publisher,
+	}
+}
+
+// Handle returns the ID of the cancelled session, or "" when the device had none open
+func (h *CancelDeviceSessionHandler) Handle(ctx context.Context, cmd CancelDeviceSessionCommand) (string, error) {
+	deviceID, err := valueobjects.DeviceIDFrom(cmd.DeviceID)
+	if err != nil {
+		return "", fmt.Errorf("invalid device ID: %w", err)
+	}
+
+	sess, err := h.sessions.FindActiveByDeviceID(ctx, deviceID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := sess.Cancel(cmd.Reason); err != nil {
+		return "", err
+	}
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	for _, evt := range sess.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return sess.ID().String(), nil
+}
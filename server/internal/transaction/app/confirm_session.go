@@ -2,12 +2,24 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/vending-machine/server/internal/shared/policy"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
 	"github.com/vending-machine/server/internal/transaction/domain"
 )
 
+// ErrFiscalizationFailed is returned when the fiscal signature could not be obtained
+var ErrFiscalizationFailed = errors.New("fiscalization failed")
+
+// ErrSaleRestricted is returned when the basket holds a SKU that may not be
+// sold at this time, e.g. alcohol during a blackout window
+var ErrSaleRestricted = errors.New("item may not be sold at this time")
+
 // ConfirmSessionCommand is the input DTO for confirming a session
 type ConfirmSessionCommand struct {
 	SessionID  string
@@ -16,28 +28,128 @@ type ConfirmSessionCommand struct {
 
 // ConfirmSessionResult is the output DTO
 type ConfirmSessionResult struct {
-	SessionID  string
-	TotalCents int64
-	Currency   string
-	PaymentRef string
+	SessionID     string
+	SubtotalCents int64 // item prices without tax
+	DiscountCents int64 // taken off by the coupon
+	CouponCode    string
+	TaxCents      int64
+	RoundingCents int64
+	TotalCents    int64
+	Currency      string
+	PaymentRef    string
+	// TransactionID identifies the sale recorded for the session
+	TransactionID string
+
+	// Tax per rate for the receipt; TaxIncluded means the item prices
+	// already contained it
+	TaxLines    []TaxLineView
+	TaxIncluded bool
+
+	// Receipt metadata, set when the payment method is known
+	Wallet    string
+	CardBrand string
+	CardLast4 string
+
+	// Fiscal identifiers for the receipt, nil where fiscalization is not required
+	Fiscal *FiscalRecordView
+
+	// AwaitingPayment is set while the provider is still settling the
+	// payment; the session completes once the payment webhook reports it
+	AwaitingPayment bool
+
+	// Replayed is set when the session was already confirmed with the same
+	// payment reference and the result is that of the original confirmation
+	Replayed bool
 }
 
 // ConfirmSessionHandler orchestrates the session confirmation use case
 type ConfirmSessionHandler struct {
-	sessions  domain.SessionRepository
-	publisher eventPublisher
+	sessions     domain.SessionRepository
+	transactions domain.TransactionRepository
+	devices      ports.DeviceReader
+	payments     ports.PaymentGateway
+	fiscalizer   ports.Fiscalizer
+	taxes        *TaxAssessor
+	rounding     policy.RoundingPolicy
+	uow          ports.UnitOfWork
+	outbox       ports.EventOutbox
+	publisher    eventPublisher
+	fraud        *FraudScreen
+	coupons      *CouponChecker
+
+	// weightResolutionRequired holds back checkout of a session whose
+	// weight mismatch no attendant has resolved
+	weightResolutionRequired bool
+	// itemReviewRequired holds back checkout of a session with lines
+	// detected below the confidence threshold no attendant has reviewed
+	itemReviewRequired bool
 }
 
-func NewConfirmSessionHandler(sessions domain.SessionRepository, publisher eventPublisher) *ConfirmSessionHandler {
+func NewConfirmSessionHandler(
+	sessions domain.SessionRepository,
+	transactions domain.TransactionRepository,
+	devices ports.DeviceReader,
+	payments ports.PaymentGateway,
+	fiscalizer ports.Fiscalizer,
+	taxes *TaxAssessor,
+	rounding policy.RoundingPolicy,
+	uow ports.UnitOfWork,
+	outbox ports.EventOutbox,
+	publisher eventPublisher,
+	fraud *FraudScreen,
+	coupons *CouponChecker,
+	weightResolutionRequired bool,
+	itemReviewRequired bool,
+) *ConfirmSessionHandler {
 	if sessions == nil {
 		panic("nil SessionRepository")
 	}
+	if transactions == nil {
+		panic("nil TransactionRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
+	if fiscalizer == nil {
+		panic("nil Fiscalizer")
+	}
+	if taxes == nil {
+		panic("nil TaxAssessor")
+	}
+	if uow == nil {
+		panic("nil UnitOfWork")
+	}
+	if outbox == nil {
+		panic("nil EventOutbox")
+	}
 	if publisher == nil {
 		panic("nil EventPublisher")
 	}
+	if fraud == nil {
+		panic("nil FraudScreen")
+	}
+	if coupons == nil {
+		panic("nil CouponChecker")
+	}
 	return &ConfirmSessionHandler{
-		sessions:  sessions,
-		publisher: publisher,
+		sessions:     sessions,
+		transactions: transactions,
+		devices:      devices,
+		payments:     payments,
+		fiscalizer:   fiscalizer,
+		taxes:        taxes,
+		rounding:     rounding,
+		uow:          uow,
+		outbox:       outbox,
+		publisher:    publisher,
+		fraud:        fraud,
+		coupons:      coupons,
+
+		weightResolutionRequired: weightResolutionRequired,
+		itemReviewRequired:       itemReviewRequired,
 	}
 }
 
@@ -52,23 +164,271 @@ func (h *ConfirmSessionHandler) Handle(ctx context.Context, cmd ConfirmSessionCo
 		return ConfirmSessionResult{}, domain.ErrSessionNotFound
 	}
 
-	if err := sess.Confirm(cmd.PaymentRef); err != nil {
+	// A client retrying after a timeout gets the outcome of the confirmation
+	// that went through
+	if sess.Status() == domain.SessionStatusCompleted {
+		return h.replay(ctx, sess, cmd.PaymentRef)
+	}
+
+	if sess.IsActive() {
+		if h.weightResolutionRequired && sess.WeightDisputed() {
+			return ConfirmSessionResult{}, domain.ErrWeightMismatchUnresolved
+		}
+		if h.itemReviewRequired && len(sess.AwaitingReview()) > 0 {
+			return ConfirmSessionResult{}, domain.ErrItemReviewPending
+		}
+		if err := h.fraud.Screen(ctx, domain.FraudFacts{
+			Stage:   domain.FraudStageConfirm,
+			Session: sess,
+			At:      time.Now().UTC(),
+		}); err != nil {
+			return ConfirmSessionResult{}, err
+		}
+
+		// Sessions can outlast the opening hours, so they are checked again at checkout
+		if err := h.checkSalesHours(ctx, sess); err != nil {
+			return ConfirmSessionResult{}, err
+		}
+
+		// The coupon may have been withdrawn or the basket changed since it
+		// was applied
+		if code := sess.Coupon().Code(); code != "" {
+			if _, err := h.coupons.Check(ctx, sess, code); err != nil {
+				return ConfirmSessionResult{}, err
+			}
+		}
+
+		// The receipt's tax lines come from the rules in force at checkout
+		tax, err := h.taxes.Assess(ctx, sess)
+		if err != nil {
+			return ConfirmSessionResult{}, fmt.Errorf("failed to assess tax: %w", err)
+		}
+		if err := sess.ApplyTax(tax, h.rounding); err != nil {
+			return ConfirmSessionResult{}, err
+		}
+	}
+
+	paymentRef := cmd.PaymentRef
+
+	// With a payment provider the session is paid through its intent, which
+	// must be captured for exactly the session total; without one the sale
+	// is confirmed against the external payment reference
+	if sess.PaymentIntentID() == "" && h.payments.Enabled() {
+		return ConfirmSessionResult{}, domain.ErrPaymentNotCaptured
+	}
+	if sess.PaymentIntentID() != "" {
+		intent, err := h.payments.GetIntent(ctx, sess.PaymentIntentID())
+		if err != nil {
+			return ConfirmSessionResult{}, fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+		}
+		total := sess.TotalAmount()
+		if intent.AmountCents != total.Amount() || !strings.EqualFold(intent.Currency, total.Currency()) {
+			return ConfirmSessionResult{}, domain.ErrPaymentNotCaptured
+		}
+		switch {
+		case intent.IsCaptured():
+		case intent.Status == ports.PaymentIntentProcessing:
+			return h.awaitPayment(ctx, sess)
+		default:
+			return ConfirmSessionResult{}, domain.ErrPaymentNotCaptured
+		}
+		paymentRef = intent.ID
+		if sess.PaymentMethod().IsZero() && intent.Card != nil {
+			method := domain.NewPaymentMethod(domain.WalletType(intent.Card.Wallet), intent.Card.Brand, intent.Card.Last4)
+			if err := sess.RecordPaymentMethod(method); err != nil {
+				return ConfirmSessionResult{}, err
+			}
+		}
+	}
+
+	if err := sess.Confirm(paymentRef); err != nil {
 		return ConfirmSessionResult{}, err
 	}
 
-	if err := h.sessions.Save(ctx, sess); err != nil {
-		return ConfirmSessionResult{}, fmt.Errorf("failed to save session: %w", err)
+	// A sale may not be completed without its fiscal signature; the session
+	// is left active so the confirmation can be retried. The fiscalizer is
+	// keyed on the session, so a retry gets the signature issued before, or
+	// a new one if the basket changed in between.
+	if err := h.fiscalize(ctx, sess, paymentRef); err != nil {
+		return ConfirmSessionResult{}, err
+	}
+
+	// The sale is recorded with the session, so a confirmed session always
+	// has its transaction
+	txn, err := domain.RecordTransaction(sess, paymentRef)
+	if err != nil {
+		return ConfirmSessionResult{}, err
+	}
+
+	// The session, its transaction and the events announcing the sale are
+	// stored all-or-nothing. A failed confirmation stores none of them and
+	// can be retried; only the fiscal document stays issued, and the retry
+	// is handed the same one unless it was issued for another total.
+	evts := append(sess.PullEvents(), txn.PullEvents()...)
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		if err := h.coupons.Redeem(ctx, sess); err != nil {
+			return err
+		}
+		if err := h.sessions.SaveCompleted(ctx, sess, txn); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		return h.outbox.Stage(ctx, evts)
+	})
+	if err != nil {
+		return ConfirmSessionResult{}, err
 	}
 
 	// Publish domain events
-	for _, evt := range sess.PullEvents() {
+	for _, evt := range evts {
 		_ = h.publisher.Publish(ctx, evt)
 	}
 
+	return completedResult(sess, txn)
+}
+
+// replay returns the result of the confirmation of a completed session to a
+// retry with the same payment reference. A session paid through its intent
+// was confirmed with the intent, which the client need not send again. Any
+// other reference finds the session no longer active.
+func (h *ConfirmSessionHandler) replay(ctx context.Context, sess *domain.Session, paymentRef string) (ConfirmSessionResult, error) {
+	txn, err := h.transactions.FindBySessionID(ctx, sess.ID())
+	if err != nil {
+		return ConfirmSessionResult{}, domain.ErrSessionNotActive
+	}
+	if paymentRef == "" {
+		paymentRef = sess.PaymentIntentID()
+	}
+	if paymentRef != txn.PaymentRef() {
+		return ConfirmSessionResult{}, domain.ErrSessionNotActive
+	}
+
+	result, err := completedResult(sess, txn)
+	if err != nil {
+		return ConfirmSessionResult{}, err
+	}
+	result.Replayed = true
+	return result, nil
+}
+
+// completedResult is the result of the confirmation that completed the
+// session with its transaction
+func completedResult(sess *domain.Session, txn *domain.Transaction) (ConfirmSessionResult, error) {
+	subtotal, err := sess.SubtotalCents()
+	if err != nil {
+		return ConfirmSessionResult{}, err
+	}
+	return ConfirmSessionResult{
+		SessionID:     sess.ID().String(),
+		TransactionID: txn.ID().String(),
+		SubtotalCents: subtotal,
+		DiscountCents: txn.DiscountCents(),
+		CouponCode:    sess.Coupon().Code(),
+		TaxCents:      sess.Tax().Cents(),
+		RoundingCents: sess.RoundingCents(),
+		TotalCents:    sess.TotalAmount().Amount(),
+		Currency:      sess.TotalAmount().Currency(),
+		PaymentRef:    txn.PaymentRef(),
+		TaxLines:      toTaxLineViews(sess.Tax()),
+		TaxIncluded:   sess.Tax().Included(),
+		Wallet:        string(sess.PaymentMethod().Wallet()),
+		CardBrand:     sess.PaymentMethod().Brand(),
+		CardLast4:     sess.PaymentMethod().Last4(),
+		Fiscal:        toFiscalRecordView(txn.FiscalRecord()),
+	}, nil
+}
+
+// awaitPayment holds the basket until the provider reports the outcome of a
+// payment it is still settling
+func (h *ConfirmSessionHandler) awaitPayment(ctx context.Context, sess *domain.Session) (ConfirmSessionResult, error) {
+	if sess.Status() != domain.SessionStatusAwaitingPayment {
+		if err := sess.AwaitPayment(); err != nil {
+			return ConfirmSessionResult{}, err
+		}
+		if err := h.sessions.Save(ctx, sess); err != nil {
+			return ConfirmSessionResult{}, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+
+	subtotal, err := sess.SubtotalCents()
+	if err != nil {
+		return ConfirmSessionResult{}, err
+	}
+	discount, err := sess.DiscountCents()
+	if err != nil {
+		return ConfirmSessionResult{}, err
+	}
 	return ConfirmSessionResult{
-		SessionID:  sess.ID().String(),
-		TotalCents: sess.TotalAmount().Amount(),
-		Currency:   sess.TotalAmount().Currency(),
-		PaymentRef: cmd.PaymentRef,
+		SessionID:       sess.ID().String(),
+		SubtotalCents:   subtotal,
+		DiscountCents:   discount,
+		CouponCode:      sess.Coupon().Code(),
+		TaxCents:        sess.Tax().Cents(),
+		RoundingCents:   sess.RoundingCents(),
+		TotalCents:      sess.TotalAmount().Amount(),
+		Currency:        sess.TotalAmount().Currency(),
+		PaymentRef:      sess.PaymentIntentID(),
+		TaxLines:        toTaxLineViews(sess.Tax()),
+		TaxIncluded:     sess.Tax().Included(),
+		AwaitingPayment: true,
 	}, nil
 }
+
+func (h *ConfirmSessionHandler) checkSalesHours(ctx context.Context, sess *domain.Session) error {
+	sales, err := h.devices.SalesStatus(ctx, sess.DeviceID().String(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check sales hours: %w", err)
+	}
+	if !sales.Open {
+		return fmt.Errorf("%w: %s", ErrDeviceClosed, sales.ClosedReason)
+	}
+	for _, item := range sess.DetectedItems() {
+		if reason, restricted := sales.RestrictedSKUs[item.Code()]; restricted {
+			return fmt.Errorf("%w: %s (%s)", ErrSaleRestricted, item.Name(), reason)
+		}
+	}
+	return nil
+}
+
+func (h *ConfirmSessionHandler) fiscalize(ctx context.Context, sess *domain.Session, paymentRef string) error {
+	discount, err := sess.DiscountCents()
+	if err != nil {
+		return err
+	}
+	receipt := ports.FiscalReceipt{
+		SessionID:     sess.ID().String(),
+		DeviceID:      sess.DeviceID().String(),
+		DiscountCents: discount,
+		RoundingCents: sess.RoundingCents(),
+		TotalCents:    sess.TotalAmount().Amount(),
+		Currency:      sess.TotalAmount().Currency(),
+		PaymentRef:    paymentRef,
+		CompletedAt:   *sess.CompletedAt(),
+	}
+	for _, item := range sess.DetectedItems() {
+		receipt.Lines = append(receipt.Lines, ports.FiscalReceiptLine{
+			Code:       item.Code(),
+			Name:       item.Name(),
+			Quantity:   item.Quantity(),
+			PriceCents: item.LineTotal().Amount(),
+		})
+	}
+
+	sig, err := h.fiscalizer.Fiscalize(ctx, receipt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFiscalizationFailed, err)
+	}
+	if sig == nil {
+		return nil
+	}
+
+	return sess.RecordFiscalization(domain.NewFiscalRecord(
+		sig.Country,
+		sig.Provider,
+		sig.DocumentNumber,
+		sig.SerialNumber,
+		sig.Signature,
+		sig.SignatureCounter,
+		sig.QRCode,
+		sig.IssuedAt,
+	))
+}
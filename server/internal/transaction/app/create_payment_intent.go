@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// CreatePaymentIntentCommand is the input DTO for paying a session by card
+type CreatePaymentIntentCommand struct {
+	SessionID string
+}
+
+// CreatePaymentIntentResult is the output DTO; the app collects the card
+// with the client secret and then confirms the session
+type CreatePaymentIntentResult struct {
+	SessionID       string
+	PaymentIntentID string
+	ClientSecret    string
+	Status          string
+	AmountCents     int64
+	Currency        string
+}
+
+// CreatePaymentIntentHandler opens the provider payment intent a session is
+// paid through, for the session total. A session that already has one, such
+// as a guest session, gets it back with the amount brought up to date.
+type CreatePaymentIntentHandler struct {
+	sessions domain.SessionRepository
+	payments ports.PaymentGateway
+}
+
+func NewCreatePaymentIntentHandler(
+	sessions domain.SessionRepository,
+	payments ports.PaymentGateway,
+) *CreatePaymentIntentHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
+	return &CreatePaymentIntentHandler{
+		sessions: sessions,
+		payments: payments,
+	}
+}
+
+func (h *CreatePaymentIntentHandler) Handle(ctx context.Context, cmd CreatePaymentIntentCommand) (CreatePaymentIntentResult, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return CreatePaymentIntentResult{}, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return CreatePaymentIntentResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return CreatePaymentIntentResult{}, domain.ErrSessionNotActive
+	}
+	if len(sess.DetectedItems()) == 0 {
+		return CreatePaymentIntentResult{}, domain.ErrNoItemsDetected
+	}
+
+	total := sess.TotalAmount()
+
+	var intent *ports.PaymentIntent
+	if sess.PaymentIntentID() == "" {
+		intent, err = h.payments.CreateIntent(ctx, sess.ID().String(), total.Amount(), total.Currency())
+		if err != nil {
+			return CreatePaymentIntentResult{}, h.providerError(err)
+		}
+		if err := sess.AttachPaymentIntent(intent.ID); err != nil {
+			return CreatePaymentIntentResult{}, err
+		}
+		if err := h.sessions.Save(ctx, sess); err != nil {
+			return CreatePaymentIntentResult{}, fmt.Errorf("failed to save session: %w", err)
+		}
+	} else {
+		intent, err = h.payments.GetIntent(ctx, sess.PaymentIntentID())
+		if err != nil {
+			return CreatePaymentIntentResult{}, h.providerError(err)
+		}
+		// A captured intent can no longer change; confirm tells whether it
+		// covers the total
+		stale := intent.AmountCents != total.Amount() || !strings.EqualFold(intent.Currency, total.Currency())
+		if stale && !intent.IsCaptured() {
+			intent, err = h.payments.UpdateIntentAmount(ctx, intent.ID, total.Amount(), total.Currency())
+			if err != nil {
+				return CreatePaymentIntentResult{}, h.providerError(err)
+			}
+		}
+	}
+
+	return CreatePaymentIntentResult{
+		SessionID:       sess.ID().String(),
+		PaymentIntentID: intent.ID,
+		ClientSecret:    intent.ClientSecret,
+		Status:          string(intent.Status),
+		AmountCents:     intent.AmountCents,
+		Currency:        intent.Currency,
+	}, nil
+}
+
+func (h *CreatePaymentIntentHandler) providerError(err error) error {
+	if errors.Is(err, ports.ErrPaymentGatewayDisabled) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+}
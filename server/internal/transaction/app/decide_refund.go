@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// DecideRefundCommand is the input DTO for approving or rejecting a refund
+type DecideRefundCommand struct {
+	RefundID   string
+	Approve    bool
+	Note       string
+	ActorID    string
+	ActorRoles []string
+}
+
+// DecideRefundHandler orchestrates the finance approval step of a refund
+type DecideRefundHandler struct {
+	refunds   domain.RefundRepository
+	publisher eventPublisher
+}
+
+func NewDecideRefundHandler(refunds domain.RefundRepository, publisher eventPublisher) *DecideRefundHandler {
+	if refunds == nil {
+		panic("nil RefundRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &DecideRefundHandler{
+		refunds:   refunds,
+		publisher: publisher,
+	}
+}
+
+func (h *DecideRefundHandler) Handle(ctx context.Context, cmd DecideRefundCommand) (RefundResult, error) {
+	refundID, err := valueobjects.RefundIDFrom(cmd.RefundID)
+	if err != nil {
+		return RefundResult{}, domain.ErrRefundNotFound
+	}
+
+	refund, err := h.refunds.FindByID(ctx, refundID)
+	if err != nil {
+		return RefundResult{}, domain.ErrRefundNotFound
+	}
+
+	approver := domain.NewActor(cmd.ActorID, cmd.ActorRoles)
+	if cmd.Approve {
+		err = refund.Approve(approver, cmd.Note)
+	} else {
+		err = refund.Reject(approver, cmd.Note)
+	}
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	if err := h.refunds.Save(ctx, refund); err != nil {
+		return RefundResult{}, fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range refund.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRefundResult(refund), nil
+}
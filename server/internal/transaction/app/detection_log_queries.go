@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// DetectionRecordView is a read-only view of a detection audit log entry
+type DetectionRecordView struct {
+	ID         int64
+	SessionID  string
+	DeviceID   string
+	Source     string
+	Payload    []byte // JSON
+	Outcome    string
+	Decision   []byte // JSON
+	ReceivedAt time.Time
+}
+
+// DetectionLogQueryService provides read-only access to the detections
+// submitted for a session, for disputes and model debugging
+type DetectionLogQueryService struct {
+	sessions domain.SessionRepository
+	log      domain.DetectionLogRepository
+}
+
+func NewDetectionLogQueryService(sessions domain.SessionRepository, log domain.DetectionLogRepository) *DetectionLogQueryService {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if log == nil {
+		panic("nil DetectionLogRepository")
+	}
+	return &DetectionLogQueryService{sessions: sessions, log: log}
+}
+
+// ListBySession lists the detections submitted for the session, oldest first
+func (s *DetectionLogQueryService) ListBySession(ctx context.Context, sessionID string) ([]DetectionRecordView, error) {
+	id, err := valueobjects.SessionIDFrom(sessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	if _, err := s.sessions.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	records, err := s.log.FindBySession(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]DetectionRecordView, 0, len(records))
+	for _, r := range records {
+		views = append(views, DetectionRecordView{
+			ID:         r.ID,
+			SessionID:  r.SessionID,
+			DeviceID:   r.DeviceID,
+			Source:     r.Source,
+			Payload:    r.Payload,
+			Outcome:    r.Outcome,
+			Decision:   r.Decision,
+			ReceivedAt: r.ReceivedAt,
+		})
+	}
+	return views, nil
+}
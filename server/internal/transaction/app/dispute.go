@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// OpenDisputeCommand is the input DTO for recording a customer's chargeback
+type OpenDisputeCommand struct {
+	SessionID   string
+	AmountCents int64 // 0 disputes the whole total
+	Reason      string
+	ProviderRef string
+	ActorID     string
+	ActorRoles  []string
+}
+
+// OpenDisputeHandler opens a dispute against the transaction of a completed
+// session. A session has at most one open dispute at a time.
+type OpenDisputeHandler struct {
+	sessions     domain.SessionRepository
+	transactions domain.TransactionRepository
+	disputes     domain.DisputeRepository
+	publisher    eventPublisher
+}
+
+func NewOpenDisputeHandler(
+	sessions domain.SessionRepository,
+	transactions domain.TransactionRepository,
+	disputes domain.DisputeRepository,
+	publisher eventPublisher,
+) *OpenDisputeHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if transactions == nil {
+		panic("nil TransactionRepository")
+	}
+	if disputes == nil {
+		panic("nil DisputeRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &OpenDisputeHandler{
+		sessions:     sessions,
+		transactions: transactions,
+		disputes:     disputes,
+		publisher:    publisher,
+	}
+}
+
+func (h *OpenDisputeHandler) Handle(ctx context.Context, cmd OpenDisputeCommand) (*DisputeView, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	txn, err := h.transactions.FindBySessionID(ctx, sessionID)
+	if errors.Is(err, domain.ErrTransactionNotFound) {
+		// Only completed sessions have a transaction
+		if _, err := h.sessions.FindByID(ctx, sessionID); err != nil {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, domain.ErrDisputeSessionNotCompleted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction: %w", err)
+	}
+
+	existing, err := h.disputes.FindBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load disputes: %w", err)
+	}
+	for _, d := range existing {
+		if d.IsOpen() {
+			return nil, domain.ErrDisputeAlreadyOpen
+		}
+	}
+
+	amount := txn.Total()
+	if cmd.AmountCents != 0 {
+		amount, err = valueobjects.NewMoney(cmd.AmountCents, amount.Currency())
+		if err != nil {
+			return nil, domain.ErrInvalidDisputeAmount
+		}
+	}
+
+	actor := domain.NewActor(cmd.ActorID, cmd.ActorRoles)
+	dispute, err := domain.OpenDispute(txn, amount, cmd.Reason, cmd.ProviderRef, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.disputes.Save(ctx, dispute); err != nil {
+		// Another dispute was opened for the session since the check above
+		if errors.Is(err, domain.ErrDisputeAlreadyOpen) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save dispute: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range dispute.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toDisputeView(dispute), nil
+}
+
+// AnnotateDisputeCommand is the input DTO for adding a note to a dispute
+type AnnotateDisputeCommand struct {
+	DisputeID string
+	Note      string
+	ActorID   string
+}
+
+// AnnotateDisputeHandler records the notes staff make while working a dispute
+type AnnotateDisputeHandler struct {
+	disputes domain.DisputeRepository
+}
+
+func NewAnnotateDisputeHandler(disputes domain.DisputeRepository) *AnnotateDisputeHandler {
+	if disputes == nil {
+		panic("nil DisputeRepository")
+	}
+	return &AnnotateDisputeHandler{disputes: disputes}
+}
+
+func (h *AnnotateDisputeHandler) Handle(ctx context.Context, cmd AnnotateDisputeCommand) (*DisputeView, error) {
+	dispute, err := findDispute(ctx, h.disputes, cmd.DisputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dispute.Annotate(domain.NewActor(cmd.ActorID, nil), cmd.Note); err != nil {
+		return nil, err
+	}
+	if err := h.disputes.Save(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("failed to save dispute: %w", err)
+	}
+
+	return toDisputeView(dispute), nil
+}
+
+// AttachDisputeEvidenceCommand is the input DTO for attaching what the
+// device recorded of the session to its dispute
+type AttachDisputeEvidenceCommand struct {
+	DisputeID string
+	Kind      string // detections or image
+	Note      string
+	ActorID   string
+}
+
+// AttachDisputeEvidenceHandler attaches the session's detection audit log
+// entries or its image to an open dispute
+type AttachDisputeEvidenceHandler struct {
+	disputes   domain.DisputeRepository
+	detections domain.DetectionLogRepository
+	images     domain.SessionImageRepository
+}
+
+func NewAttachDisputeEvidenceHandler(
+	disputes domain.DisputeRepository,
+	detections domain.DetectionLogRepository,
+	images domain.SessionImageRepository,
+) *AttachDisputeEvidenceHandler {
+	if disputes == nil {
+		panic("nil DisputeRepository")
+	}
+	if detections == nil {
+		panic("nil DetectionLogRepository")
+	}
+	if images == nil {
+		panic("nil SessionImageRepository")
+	}
+	return &AttachDisputeEvidenceHandler{
+		disputes:   disputes,
+		detections: detections,
+		images:     images,
+	}
+}
+
+func (h *AttachDisputeEvidenceHandler) Handle(ctx context.Context, cmd AttachDisputeEvidenceCommand) (*DisputeView, error) {
+	kind, err := domain.ParseDisputeEvidenceKind(cmd.Kind)
+	if err != nil {
+		return nil, err
+	}
+	dispute, err := findDispute(ctx, h.disputes, cmd.DisputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := domain.DisputeEvidence{Kind: kind, Note: cmd.Note}
+	switch kind {
+	case domain.DisputeEvidenceDetections:
+		records, err := h.detections.FindBySession(ctx, dispute.SessionID().String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load detections: %w", err)
+		}
+		for _, r := range records {
+			evidence.DetectionIDs = append(evidence.DetectionIDs, r.ID)
+		}
+		if len(evidence.DetectionIDs) == 0 {
+			return nil, domain.ErrNoDisputeEvidence
+		}
+	case domain.DisputeEvidenceImage:
+		image, err := h.images.FindBySessionID(ctx, dispute.SessionID())
+		if errors.Is(err, domain.ErrSessionImageNotFound) {
+			return nil, domain.ErrNoDisputeEvidence
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session image: %w", err)
+		}
+		evidence.ImageBytes = len(image)
+	}
+
+	if err := dispute.AttachEvidence(domain.NewActor(cmd.ActorID, nil), evidence); err != nil {
+		return nil, err
+	}
+	if err := h.disputes.Save(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("failed to save dispute: %w", err)
+	}
+
+	return toDisputeView(dispute), nil
+}
+
+// ResolveDisputeCommand is the input DTO for closing a dispute
+type ResolveDisputeCommand struct {
+	DisputeID  string
+	Outcome    string // won or lost
+	Note       string
+	ActorID    string
+	ActorRoles []string
+}
+
+// ResolveDisputeHandler records the outcome of a dispute, a finance decision
+type ResolveDisputeHandler struct {
+	disputes  domain.DisputeRepository
+	publisher eventPublisher
+}
+
+func NewResolveDisputeHandler(disputes domain.DisputeRepository, publisher eventPublisher) *ResolveDisputeHandler {
+	if disputes == nil {
+		panic("nil DisputeRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ResolveDisputeHandler{disputes: disputes, publisher: publisher}
+}
+
+func (h *ResolveDisputeHandler) Handle(ctx context.Context, cmd ResolveDisputeCommand) (*DisputeView, error) {
+	outcome, err := domain.ParseDisputeOutcome(cmd.Outcome)
+	if err != nil {
+		return nil, err
+	}
+	dispute, err := findDispute(ctx, h.disputes, cmd.DisputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dispute.Resolve(domain.NewActor(cmd.ActorID, cmd.ActorRoles), outcome, cmd.Note); err != nil {
+		return nil, err
+	}
+	if err := h.disputes.Save(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("failed to save dispute: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range dispute.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toDisputeView(dispute), nil
+}
+
+func findDispute(ctx context.Context, disputes domain.DisputeRepository, id string) (*domain.Dispute, error) {
+	disputeID, err := valueobjects.DisputeIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrDisputeNotFound
+	}
+	dispute, err := disputes.FindByID(ctx, disputeID)
+	if err != nil {
+		return nil, domain.ErrDisputeNotFound
+	}
+	return dispute, nil
+}
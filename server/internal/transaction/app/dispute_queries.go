@@ -0,0 +1,248 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ErrUnknownDisputeStatus is returned when disputes are listed by a status
+// other than open, won or lost
+var ErrUnknownDisputeStatus = errors.New("status must be open, won or lost")
+
+// DisputeView is a read-only view of a dispute
+type DisputeView struct {
+	ID             string
+	SessionID      string
+	TransactionID  string
+	AmountCents    int64
+	Currency       string
+	Reason         string
+	ProviderRef    string
+	Status         string
+	OpenedBy       string
+	ResolvedBy     string
+	ResolutionNote string
+	Notes          []DisputeNoteView
+	Evidence       []DisputeEvidenceView
+	CreatedAt      string
+	ResolvedAt     *string
+}
+
+// DisputeNoteView is a read-only view of a note on a dispute
+type DisputeNoteView struct {
+	ActorID string
+	Note    string
+	At      string
+}
+
+// DisputeEvidenceView is a read-only view of evidence attached to a dispute
+type DisputeEvidenceView struct {
+	Kind         string
+	DetectionIDs []int64 // entries of the session's detection audit log
+	ImageBytes   int
+	AttachedBy   string
+	Note         string
+	At           string
+}
+
+// DisputeListQuery filters the dispute listing; zero fields match all
+type DisputeListQuery struct {
+	Status string
+	From   time.Time // opened on or after
+	To     time.Time // opened before
+}
+
+// DisputeReport sums up the disputes opened in a period for the finance team
+type DisputeReport struct {
+	Lines []DisputeReportLine // by status, then currency
+}
+
+// DisputeReportLine counts the disputes of one status in one currency
+type DisputeReportLine struct {
+	Status      string
+	Currency    string
+	Count       int
+	AmountCents int64
+}
+
+// DisputeQueryService provides read-only access to disputes and their evidence
+type DisputeQueryService struct {
+	disputes domain.DisputeRepository
+	images   domain.SessionImageRepository
+}
+
+func NewDisputeQueryService(disputes domain.DisputeRepository, images domain.SessionImageRepository) *DisputeQueryService {
+	if disputes == nil {
+		panic("nil DisputeRepository")
+	}
+	if images == nil {
+		panic("nil SessionImageRepository")
+	}
+	return &DisputeQueryService{disputes: disputes, images: images}
+}
+
+func (s *DisputeQueryService) FindByID(ctx context.Context, id string) (*DisputeView, error) {
+	dispute, err := findDispute(ctx, s.disputes, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDisputeView(dispute), nil
+}
+
+func (s *DisputeQueryService) FindBySessionID(ctx context.Context, sessionID string) ([]*DisputeView, error) {
+	id, err := valueobjects.SessionIDFrom(sessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	disputes, err := s.disputes.FindBySessionID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDisputeViews(disputes), nil
+}
+
+// List returns the disputes matching the query, oldest first
+func (s *DisputeQueryService) List(ctx context.Context, q DisputeListQuery) ([]*DisputeView, error) {
+	filter, err := disputeFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	disputes, err := s.disputes.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return toDisputeViews(disputes), nil
+}
+
+// Report counts the disputes opened in [from, to) and the amounts they
+// hold, per status and currency
+func (s *DisputeQueryService) Report(ctx context.Context, from, to time.Time) (*DisputeReport, error) {
+	disputes, err := s.disputes.Find(ctx, domain.DisputeFilter{OpenedFrom: from, OpenedTo: to})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ status, currency string }
+	lines := make(map[key]*DisputeReportLine)
+	for _, d := range disputes {
+		k := key{string(d.Status()), d.Amount().Currency()}
+		line, ok := lines[k]
+		if !ok {
+			line = &DisputeReportLine{Status: k.status, Currency: k.currency}
+			lines[k] = line
+		}
+		line.Count++
+		line.AmountCents += d.Amount().Amount()
+	}
+
+	report := &DisputeReport{Lines: make([]DisputeReportLine, 0, len(lines))}
+	for _, line := range lines {
+		report.Lines = append(report.Lines, *line)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		a, b := report.Lines[i], report.Lines[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.Currency < b.Currency
+	})
+	return report, nil
+}
+
+// Image returns the session image attached to the dispute as evidence
+func (s *DisputeQueryService) Image(ctx context.Context, id string) ([]byte, error) {
+	dispute, err := findDispute(ctx, s.disputes, id)
+	if err != nil {
+		return nil, err
+	}
+	attached := false
+	for _, e := range dispute.Evidence() {
+		if e.Kind == domain.DisputeEvidenceImage {
+			attached = true
+		}
+	}
+	if !attached {
+		return nil, domain.ErrNoDisputeEvidence
+	}
+
+	image, err := s.images.FindBySessionID(ctx, dispute.SessionID())
+	if errors.Is(err, domain.ErrSessionImageNotFound) {
+		return nil, domain.ErrNoDisputeEvidence
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session image: %w", err)
+	}
+	return image, nil
+}
+
+func disputeFilter(q DisputeListQuery) (domain.DisputeFilter, error) {
+	status := domain.DisputeStatus(q.Status)
+	switch status {
+	case "", domain.DisputeStatusOpen, domain.DisputeStatusWon, domain.DisputeStatusLost:
+	default:
+		return domain.DisputeFilter{}, ErrUnknownDisputeStatus
+	}
+	return domain.DisputeFilter{Status: status, OpenedFrom: q.From, OpenedTo: q.To}, nil
+}
+
+func toDisputeViews(disputes []*domain.Dispute) []*DisputeView {
+	views := make([]*DisputeView, 0, len(disputes))
+	for _, d := range disputes {
+		views = append(views, toDisputeView(d))
+	}
+	return views
+}
+
+func toDisputeView(d *domain.Dispute) *DisputeView {
+	var notes []DisputeNoteView
+	for _, n := range d.Notes() {
+		notes = append(notes, DisputeNoteView{
+			ActorID: n.ActorID,
+			Note:    n.Note,
+			At:      n.At.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	var evidence []DisputeEvidenceView
+	for _, e := range d.Evidence() {
+		evidence = append(evidence, DisputeEvidenceView{
+			Kind:         string(e.Kind),
+			DetectionIDs: e.DetectionIDs,
+			ImageBytes:   e.ImageBytes,
+			AttachedBy:   e.AttachedBy,
+			Note:         e.Note,
+			At:           e.At.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	var resolvedAt *string
+	if d.ResolvedAt() != nil {
+		t := d.ResolvedAt().Format("2006-01-02T15:04:05Z07:00")
+		resolvedAt = &t
+	}
+
+	return &DisputeView{
+		ID:             d.ID().String(),
+		SessionID:      d.SessionID().String(),
+		TransactionID:  d.TransactionID().String(),
+		AmountCents:    d.Amount().Amount(),
+		Currency:       d.Amount().Currency(),
+		Reason:         d.Reason(),
+		ProviderRef:    d.ProviderRef(),
+		Status:         string(d.Status()),
+		OpenedBy:       d.OpenedBy(),
+		ResolvedBy:     d.ResolvedBy(),
+		ResolutionNote: d.ResolutionNote(),
+		Notes:          notes,
+		Evidence:       evidence,
+		CreatedAt:      d.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		ResolvedAt:     resolvedAt,
+	}
+}
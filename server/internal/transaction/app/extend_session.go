@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ExtendSessionCommand is the input DTO for extending a session. Minutes is
+// how long to extend it by; 0 extends it by the expiration of its device.
+type ExtendSessionCommand struct {
+	SessionID string
+	Minutes   int
+}
+
+// ExtendSessionResult is the output DTO
+type ExtendSessionResult struct {
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// ExtendSessionHandler pushes back the expiry of a session, for a customer
+// who needs more time at the device
+type ExtendSessionHandler struct {
+	sessions  domain.SessionRepository
+	devices   ports.DeviceReader
+	lifetime  domain.SessionLifetime
+	publisher eventPublisher
+}
+
+func NewExtendSessionHandler(
+	sessions domain.SessionRepository,
+	devices ports.DeviceReader,
+	lifetime domain.SessionLifetime,
+	publisher eventPublisher,
+) *ExtendSessionHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ExtendSessionHandler{
+		sessions:  sessions,
+		devices:   devices,
+		lifetime:  lifetime,
+		publisher: publisher,
+	}
+}
+
+func (h *ExtendSessionHandler) Handle(ctx context.Context, cmd ExtendSessionCommand) (ExtendSessionResult, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return ExtendSessionResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return ExtendSessionResult{}, domain.ErrSessionNotFound
+	}
+
+	by := time.Duration(cmd.Minutes) * time.Minute
+	if cmd.Minutes == 0 {
+		dev, err := h.devices.FindByID(ctx, sess.DeviceID().String())
+		if err != nil {
+			return ExtendSessionResult{}, fmt.Errorf("failed to find device: %w", err)
+		}
+		by = h.lifetime.For(dev.SessionExpirationMinutes)
+	}
+
+	if err := sess.Extend(by, h.lifetime.Max); err != nil {
+		return ExtendSessionResult{}, err
+	}
+
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return ExtendSessionResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range sess.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return ExtendSessionResult{
+		SessionID: sess.ID().String(),
+		ExpiresAt: sess.ExpiresAt(),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// FraudScreen runs the fraud rules on a session as a detection is submitted
+// and at checkout. Findings are recorded on the session, which the caller
+// saves with the rest of its changes; a session the rules block is saved
+// right away and ErrFraudSuspected returned.
+type FraudScreen struct {
+	sessions  domain.SessionRepository
+	publisher eventPublisher
+	rules     domain.FraudRules
+}
+
+func NewFraudScreen(sessions domain.SessionRepository, publisher eventPublisher, rules domain.FraudRules) *FraudScreen {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &FraudScreen{
+		sessions:  sessions,
+		publisher: publisher,
+		rules:     rules,
+	}
+}
+
+// Screen evaluates the rules against the facts of the session
+func (s *FraudScreen) Screen(ctx context.Context, facts domain.FraudFacts) error {
+	sess := facts.Session
+	if sess.FraudBlocked() {
+		return domain.ErrFraudSuspected
+	}
+	if s.rules.IsEmpty() {
+		return nil
+	}
+
+	if window := s.rules.CancellationWindow(); window > 0 && sess.UserID() != "" {
+		count, err := s.recentCancellations(ctx, sess.UserID(), facts.At.Add(-window))
+		if err != nil {
+			return err
+		}
+		facts.RecentCancellations = count
+	}
+
+	sess.FlagFraud(s.rules.Evaluate(facts))
+	if !sess.FraudBlocked() {
+		return nil
+	}
+
+	if err := s.sessions.Save(ctx, sess); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	for _, evt := range sess.PullEvents() {
+		_ = s.publisher.Publish(ctx, evt)
+	}
+	return domain.ErrFraudSuspected
+}
+
+// maxCountedCancellations bounds the page read to count a user's
+// cancellations; no rule needs to tell more apart
+const maxCountedCancellations = 100
+
+func (s *FraudScreen) recentCancellations(ctx context.Context, userID string, since time.Time) (int, error) {
+	sessions, err := s.sessions.FindPage(ctx, domain.SessionFilter{
+		UserID: userID,
+		Status: domain.SessionStatusCancelled,
+		From:   since,
+	}, nil, maxCountedCancellations)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cancelled sessions: %w", err)
+	}
+	return len(sessions), nil
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/shared/locale"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// ItemLocalizer names session items in the customer's languages. Sessions
+// keep the name an item was sold under; translations come from the catalog.
+type ItemLocalizer struct {
+	catalog ports.CatalogReader
+}
+
+func NewItemLocalizer(catalog ports.CatalogReader) *ItemLocalizer {
+	if catalog == nil {
+		panic("nil CatalogReader")
+	}
+	return &ItemLocalizer{catalog: catalog}
+}
+
+// Localize returns the SKU's translation for the first preferred language it
+// has one for. ok is false when there is none, or the SKU cannot be read, and
+// the item keeps its own name.
+func (l *ItemLocalizer) Localize(ctx context.Context, skuCode string, languages []string) (ports.SKUTranslation, bool) {
+	if len(languages) == 0 {
+		return ports.SKUTranslation{}, false
+	}
+	sku, err := l.catalog.FindSKUByCode(ctx, skuCode)
+	if err != nil {
+		return ports.SKUTranslation{}, false
+	}
+	return locale.Pick(sku.Translations, languages)
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// CorrectedItemInput is a line of the basket as counted by the attendant
+type CorrectedItemInput struct {
+	SKU      string
+	Quantity int
+}
+
+// OverrideWeightCommand is the input DTO for resolving a weight mismatch
+type OverrideWeightCommand struct {
+	SessionID string
+	ActorID   string
+	Decision  string // approved or corrected
+	Reason    string
+	// Items replace the basket when the decision is corrected
+	Items []CorrectedItemInput
+}
+
+// OverrideWeightResult is the output DTO
+type OverrideWeightResult struct {
+	SessionID    string
+	ActorID      string
+	Decision     string
+	Reason       string
+	OverriddenAt time.Time
+	// WeightMismatch is whether the basket, corrected or not, still differs
+	// from the measured weight; the override lets it through checkout anyway
+	WeightMismatch bool
+}
+
+// OverrideWeightHandler lets an attendant resolve a session flagged for a
+// weight mismatch, either approving the basket as detected or replacing it
+// with what they counted
+type OverrideWeightHandler struct {
+	sessions  domain.SessionRepository
+	submit    *SubmitDetectionHandler
+	publisher eventPublisher
+}
+
+func NewOverrideWeightHandler(sessions domain.SessionRepository, submit *SubmitDetectionHandler, publisher eventPublisher) *OverrideWeightHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if submit == nil {
+		panic("nil SubmitDetectionHandler")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &OverrideWeightHandler{
+		sessions:  sessions,
+		submit:    submit,
+		publisher: publisher,
+	}
+}
+
+func (h *OverrideWeightHandler) Handle(ctx context.Context, cmd OverrideWeightCommand) (OverrideWeightResult, error) {
+	if cmd.ActorID == "" {
+		return OverrideWeightResult{}, domain.ErrActorRequired
+	}
+	decision, err := domain.ParseWeightDecision(cmd.Decision)
+	if err != nil {
+		return OverrideWeightResult{}, err
+	}
+	if cmd.Reason == "" {
+		return OverrideWeightResult{}, domain.ErrWeightOverrideReasonMissing
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return OverrideWeightResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return OverrideWeightResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return OverrideWeightResult{}, domain.ErrSessionNotActive
+	}
+	if !sess.WeightMismatch() {
+		return OverrideWeightResult{}, domain.ErrNoWeightMismatch
+	}
+
+	// The counted basket is priced like a frame from the device, at the
+	// weight the device measured
+	if decision == domain.WeightDecisionCorrected {
+		var items []DetectedItemInput
+		for _, line := range cmd.Items {
+			for i := 0; i < line.Quantity; i++ {
+				items = append(items, DetectedItemInput{SKU: line.SKU, Confidence: 1})
+			}
+		}
+		_, err := h.submit.Handle(ctx, SubmitDetectionCommand{
+			DeviceID:    sess.DeviceID().String(),
+			SessionID:   sess.ID().String(),
+			Items:       items,
+			TotalWeight: sess.TotalWeight().Grams(),
+			Source:      domain.DetectionSourceAttendant,
+		})
+		if err != nil {
+			return OverrideWeightResult{}, err
+		}
+		if sess, err = h.sessions.FindByID(ctx, sessionID); err != nil {
+			return OverrideWeightResult{}, err
+		}
+	}
+
+	override := domain.NewWeightOverride(cmd.ActorID, decision, cmd.Reason, time.Now().UTC())
+	if err := sess.OverrideWeight(override); err != nil {
+		return OverrideWeightResult{}, err
+	}
+
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return OverrideWeightResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range sess.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return OverrideWeightResult{
+		SessionID:      sess.ID().String(),
+		ActorID:        override.ActorID(),
+		Decision:       string(override.Decision()),
+		Reason:         override.Reason(),
+		OverriddenAt:   override.OverriddenAt(),
+		WeightMismatch: sess.WeightMismatch(),
+	}, nil
+}
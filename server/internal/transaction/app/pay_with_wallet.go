@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+var (
+	ErrWalletTokenRequired      = errors.New("wallet token is required")
+	ErrValidationURLRequired    = errors.New("validation URL is required")
+	ErrInvalidValidationURL     = errors.New("validation URL is not an Apple Pay endpoint")
+	ErrMerchantValidationFailed = errors.New("apple pay merchant validation failed")
+)
+
+// PayWithWalletCommand is the input DTO for paying a session with Apple Pay / Google Pay
+type PayWithWalletCommand struct {
+	SessionID string
+	Wallet    string
+	Token     string // provider token created from the wallet payment data
+}
+
+// PayWithWalletResult is the output DTO
+type PayWithWalletResult struct {
+	SessionID       string
+	PaymentIntentID string
+	Status          string
+	AmountCents     int64
+	Currency        string
+	Wallet          string
+	CardBrand       string
+	CardLast4       string
+}
+
+// PayWithWalletHandler charges a session's total through a mobile wallet.
+// Token decryption happens at the payment provider; we only relay the token.
+type PayWithWalletHandler struct {
+	sessions domain.SessionRepository
+	payments ports.PaymentGateway
+}
+
+func NewPayWithWalletHandler(
+	sessions domain.SessionRepository,
+	payments ports.PaymentGateway,
+) *PayWithWalletHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
+	return &PayWithWalletHandler{
+		sessions: sessions,
+		payments: payments,
+	}
+}
+
+func (h *PayWithWalletHandler) Handle(ctx context.Context, cmd PayWithWalletCommand) (PayWithWalletResult, error) {
+	wallet, err := domain.ParseWalletType(cmd.Wallet)
+	if err != nil {
+		return PayWithWalletResult{}, err
+	}
+	if cmd.Token == "" {
+		return PayWithWalletResult{}, ErrWalletTokenRequired
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return PayWithWalletResult{}, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return PayWithWalletResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return PayWithWalletResult{}, domain.ErrSessionNotActive
+	}
+	if len(sess.DetectedItems()) == 0 {
+		return PayWithWalletResult{}, domain.ErrNoItemsDetected
+	}
+
+	total := sess.TotalAmount()
+
+	// Registered users have no intent yet; open one for the current total
+	intentID := sess.PaymentIntentID()
+	if intentID == "" {
+		intent, err := h.payments.CreateIntent(ctx, sess.ID().String(), total.Amount(), total.Currency())
+		if err != nil {
+			return PayWithWalletResult{}, h.providerError(err)
+		}
+		if err := sess.AttachPaymentIntent(intent.ID); err != nil {
+			return PayWithWalletResult{}, err
+		}
+		intentID = intent.ID
+	}
+
+	intent, err := h.payments.ConfirmWithWalletToken(ctx, intentID, string(wallet), cmd.Token)
+	if err != nil {
+		return PayWithWalletResult{}, h.providerError(err)
+	}
+	if intent.AmountCents != total.Amount() || !strings.EqualFold(intent.Currency, total.Currency()) {
+		return PayWithWalletResult{}, domain.ErrPaymentNotCaptured
+	}
+
+	// Keep what the receipt needs: wallet, card brand and device account suffix
+	var brand, last4 string
+	if intent.Card != nil {
+		brand, last4 = intent.Card.Brand, intent.Card.Last4
+	}
+	if err := sess.RecordPaymentMethod(domain.NewPaymentMethod(wallet, brand, last4)); err != nil {
+		return PayWithWalletResult{}, err
+	}
+
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return PayWithWalletResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return PayWithWalletResult{
+		SessionID:       sess.ID().String(),
+		PaymentIntentID: intent.ID,
+		Status:          string(intent.Status),
+		AmountCents:     intent.AmountCents,
+		Currency:        intent.Currency,
+		Wallet:          string(wallet),
+		CardBrand:       brand,
+		CardLast4:       last4,
+	}, nil
+}
+
+func (h *PayWithWalletHandler) providerError(err error) error {
+	if errors.Is(err, ports.ErrPaymentGatewayDisabled) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+}
+
+// ValidateApplePayMerchantCommand is the input DTO for Apple Pay merchant validation
+type ValidateApplePayMerchantCommand struct {
+	ValidationURL string // from the onvalidatemerchant event
+	DomainName    string // domain the payment sheet is shown on
+}
+
+// ValidateApplePayMerchantHandler obtains an Apple Pay merchant session
+// so the app can present the payment sheet
+type ValidateApplePayMerchantHandler struct {
+	validator ports.ApplePayMerchantValidator
+}
+
+func NewValidateApplePayMerchantHandler(validator ports.ApplePayMerchantValidator) *ValidateApplePayMerchantHandler {
+	if validator == nil {
+		panic("nil ApplePayMerchantValidator")
+	}
+	return &ValidateApplePayMerchantHandler{validator: validator}
+}
+
+func (h *ValidateApplePayMerchantHandler) Handle(ctx context.Context, cmd ValidateApplePayMerchantCommand) ([]byte, error) {
+	if cmd.ValidationURL == "" {
+		return nil, ErrValidationURLRequired
+	}
+	// The URL comes from the client; only ever send our merchant certificate to Apple
+	if !isApplePayValidationURL(cmd.ValidationURL) {
+		return nil, ErrInvalidValidationURL
+	}
+
+	session, err := h.validator.ValidateMerchant(ctx, cmd.ValidationURL, cmd.DomainName)
+	if err != nil {
+		if errors.Is(err, ports.ErrWalletNotConfigured) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrMerchantValidationFailed, err)
+	}
+	return session, nil
+}
+
+func isApplePayValidationURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "apple.com" || strings.HasSuffix(host, ".apple.com")
+}
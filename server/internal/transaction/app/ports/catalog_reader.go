@@ -10,6 +10,29 @@ type SKUInfo struct {
 	PriceCents  int64
 	Currency    string
 	WeightGrams float64
+	Active      bool
+	CategoryID  string // empty when uncategorized
+
+	// WeightCalibrated is set when WeightGrams is the mean of weighed samples
+	// and WeightStdDevGrams their spread
+	WeightCalibrated  bool
+	WeightStdDevGrams float64
+
+	Translations map[string]SKUTranslation // by language tag, e.g. "de" or "de-CH"
+}
+
+// SKUTranslation is a SKU's name and description in one language
+type SKUTranslation struct {
+	Name        string
+	Description string
+}
+
+// BundleInfo is a bundle SKU: the listed units sold together for PriceCents
+type BundleInfo struct {
+	Code       string
+	PriceCents int64
+	Currency   string
+	Components map[string]int // SKU ID -> units
 }
 
 // CatalogReader is an input port for reading catalog context data.
@@ -17,4 +40,5 @@ type SKUInfo struct {
 // implemented by an adapter that calls the catalog context API.
 type CatalogReader interface {
 	FindSKUByCode(ctx context.Context, code string) (*SKUInfo, error)
+	FindActiveBundles(ctx context.Context) ([]BundleInfo, error)
 }
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCouponNotFound is returned by Coupons when no coupon has the code
+var ErrCouponNotFound = errors.New("coupon not found")
+
+// CouponInfo is a DTO describing a coupon and whether it can be used
+type CouponInfo struct {
+	Code           string
+	Status         string // active, expired or disabled
+	PercentOff     int
+	AmountOffCents int64
+	MinBasketCents int64
+	Currency       string
+	MaxRedemptions int // 0 means no limit
+}
+
+// Coupons is an input port for reading checkout coupons.
+// This port is defined by the transaction context (consumer) and
+// implemented by an adapter that calls the pricing context API.
+type Coupons interface {
+	FindByCode(ctx context.Context, code string) (*CouponInfo, error)
+}
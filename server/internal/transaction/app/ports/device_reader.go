@@ -1,12 +1,50 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // DeviceInfo is a DTO representing device information needed by transaction context
 type DeviceInfo struct {
 	ID        string
 	MachineID string
+	Region    string
 	IsActive  bool
+	IsBlocked bool // blocked until an operator clears it, e.g. after a temperature excursion
+
+	// InMaintenance is set while field staff work on the device
+	InMaintenance bool
+
+	// Restocking is set while an operator restocks the device
+	Restocking bool
+
+	// VerificationRequiredSince is the time of the device's latest security incident
+	VerificationRequiredSince *time.Time
+
+	// ConfidenceThreshold overrides the detection policy's threshold for the
+	// device; nil keeps the policy's
+	ConfidenceThreshold *float64
+
+	// ModelVersion is the detection model the device should run; empty when
+	// none is assigned to it
+	ModelVersion string
+
+	// SessionExpirationMinutes is how long sessions started on the device
+	// last; nil keeps the deployment's default
+	SessionExpirationMinutes *int
+}
+
+// SalesStatus tells whether a device may sell at a given time, from its
+// opening hours, blackout windows and the expiry of its stock
+type SalesStatus struct {
+	Open         bool
+	ClosedReason string
+
+	// RestrictedSKUs maps SKU codes that may not be sold at that time to the reason
+	RestrictedSKUs map[string]string
+	// Markdowns maps SKU codes close to expiry to the percentage taken off their price
+	Markdowns map[string]int
 }
 
 // DeviceReader is an input port for reading device context data.
@@ -14,4 +52,9 @@ type DeviceInfo struct {
 // implemented by an adapter that calls the device context API.
 type DeviceReader interface {
 	FindByMachineID(ctx context.Context, machineID string) (*DeviceInfo, error)
+	FindByID(ctx context.Context, id string) (*DeviceInfo, error)
+	SalesStatus(ctx context.Context, deviceID string, at time.Time) (*SalesStatus, error)
+	// AssignedSKUs returns the SKU codes the device stocks, or nil when it may
+	// sell the whole catalog
+	AssignedSKUs(ctx context.Context, deviceID string) (map[string]bool, error)
 }
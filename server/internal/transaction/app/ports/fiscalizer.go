@@ -0,0 +1,53 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// FiscalReceipt is the sale data sent to a country's fiscalization system
+type FiscalReceipt struct {
+	SessionID     string
+	DeviceID      string
+	Lines         []FiscalReceiptLine
+	DiscountCents int64 // coupon discount taken off the line sum, shown as its own receipt line
+	RoundingCents int64 // cash rounding of the line sum, shown as its own receipt line
+	TotalCents    int64
+	Currency      string
+	PaymentRef    string
+	CompletedAt   time.Time
+}
+
+// FiscalReceiptLine is one line of identical sold items on a fiscal receipt
+type FiscalReceiptLine struct {
+	Code       string
+	Name       string
+	Quantity   int
+	PriceCents int64 // of the whole line
+}
+
+// FiscalSignature is the DTO returned by a fiscalization system
+type FiscalSignature struct {
+	Country          string
+	Provider         string
+	DocumentNumber   string
+	SerialNumber     string
+	Signature        string
+	SignatureCounter int64
+	QRCode           string
+	IssuedAt         time.Time
+}
+
+// Fiscalizer is an output port for country-specific fiscal receipt
+// compliance (German TSE, Italian RT, ...). It returns nil when the
+// deployment's country does not require fiscalization.
+//
+// Sales are fiscalized before they are stored, so a confirmation that fails
+// afterwards is retried with the same session. Fiscalize must therefore be
+// keyed on SessionID and return the signature issued for it before instead
+// of issuing a second document. The basket may have changed in between; a
+// document issued for another total must not be handed back; the receipt is
+// signed anew and the earlier document voided.
+type Fiscalizer interface {
+	Fiscalize(ctx context.Context, receipt FiscalReceipt) (*FiscalSignature, error)
+}
@@ -0,0 +1,68 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrPaymentGatewayDisabled is returned by gateways that are not configured
+	ErrPaymentGatewayDisabled = errors.New("payment gateway is not configured")
+	// ErrWalletNotConfigured is returned when wallet merchant credentials are missing
+	ErrWalletNotConfigured = errors.New("wallet payments are not configured")
+)
+
+// PaymentIntentStatus mirrors the payment provider's intent lifecycle
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentRequiresPaymentMethod PaymentIntentStatus = "requires_payment_method"
+	PaymentIntentRequiresConfirmation  PaymentIntentStatus = "requires_confirmation"
+	PaymentIntentRequiresAction        PaymentIntentStatus = "requires_action"
+	PaymentIntentProcessing            PaymentIntentStatus = "processing"
+	PaymentIntentRequiresCapture       PaymentIntentStatus = "requires_capture"
+	PaymentIntentSucceeded             PaymentIntentStatus = "succeeded"
+	PaymentIntentCanceled              PaymentIntentStatus = "canceled"
+)
+
+// PaymentIntent is a DTO describing a provider-side payment intent
+type PaymentIntent struct {
+	ID           string
+	ClientSecret string // handed to the customer app to collect the card
+	AmountCents  int64
+	Currency     string
+	Status       PaymentIntentStatus
+	Card         *CardDetails // set once a payment method is attached
+}
+
+// CardDetails describes the card (or wallet device token) behind an intent
+type CardDetails struct {
+	Wallet string // "apple_pay", "google_pay", or empty for manual entry
+	Brand  string
+	Last4  string
+}
+
+// IsCaptured reports whether the funds have been captured
+func (p PaymentIntent) IsCaptured() bool {
+	return p.Status == PaymentIntentSucceeded
+}
+
+// PaymentGateway is an output port for the external payment provider.
+// It lets anonymous customers pay in-app without registering.
+type PaymentGateway interface {
+	// Enabled reports whether a provider is configured; without one, sales
+	// are confirmed against an external payment reference
+	Enabled() bool
+	CreateIntent(ctx context.Context, sessionID string, amountCents int64, currency string) (*PaymentIntent, error)
+	UpdateIntentAmount(ctx context.Context, intentID string, amountCents int64, currency string) (*PaymentIntent, error)
+	GetIntent(ctx context.Context, intentID string) (*PaymentIntent, error)
+	// ConfirmWithWalletToken pays the intent with an Apple Pay / Google Pay
+	// token; the provider decrypts the token, we never see card data
+	ConfirmWithWalletToken(ctx context.Context, intentID, wallet, token string) (*PaymentIntent, error)
+}
+
+// ApplePayMerchantValidator is an output port for Apple Pay merchant validation.
+// It returns Apple's opaque merchant session to hand back to the app.
+type ApplePayMerchantValidator interface {
+	ValidateMerchant(ctx context.Context, validationURL, domainName string) ([]byte, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// ExperimentAssignment is a DTO describing the price experiment variant a
+// device is assigned to
+type ExperimentAssignment struct {
+	ExperimentID string
+	Variant      string
+	Prices       map[string]int64 // SKU code -> price in cents
+}
+
+// PriceExperiments is an input port for reading running price experiments.
+// This port is defined by the transaction context (consumer) and
+// implemented by an adapter that calls the pricing context API.
+type PriceExperiments interface {
+	AssignmentsFor(ctx context.Context, deviceID string, at time.Time) ([]ExperimentAssignment, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import "context"
+
+// ScoredSKU is a recommendation candidate; higher scores rank first
+type ScoredSKU struct {
+	Code  string
+	Score float64
+}
+
+// Recommender suggests SKUs that complement a basket. The co-purchase
+// implementation can be swapped for a model-backed one without touching
+// the use case.
+type Recommender interface {
+	// Recommend returns up to limit candidates, never including basket codes.
+	// An empty basket yields the overall best sellers.
+	Recommend(ctx context.Context, basket []string, limit int) ([]ScoredSKU, error)
+}
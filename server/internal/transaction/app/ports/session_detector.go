@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCloudDetectionUnavailable is returned when no cloud detection backend is configured
+var ErrCloudDetectionUnavailable = errors.New("cloud detection is unavailable")
+
+// CloudDetection is one object the cloud model finds on a session image.
+// BBox is where, as [x, y, width, height] fractions of the image; nil when
+// the model gives none.
+type CloudDetection struct {
+	SKUCode    string
+	Confidence float64
+	BBox       []float64
+}
+
+// SessionDetectionResult is the cloud model's answer for one session image
+type SessionDetectionResult struct {
+	Detections   []CloudDetection
+	ModelVersion string
+}
+
+// SessionDetector is an output port for re-running a session image through
+// the current cloud model
+type SessionDetector interface {
+	DetectSession(ctx context.Context, deviceID string, image []byte) (SessionDetectionResult, error)
+}
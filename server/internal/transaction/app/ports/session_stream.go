@@ -0,0 +1,19 @@
+package ports
+
+import "time"
+
+// SessionTokens is an output port for the signed tokens that scope a
+// customer's live session stream to a single session
+type SessionTokens interface {
+	Issue(sessionID string) (token string, expiresAt time.Time)
+	// Verify returns the session ID the token was issued for, or an error
+	// if the token is malformed, forged, or expired
+	Verify(token string) (sessionID string, err error)
+}
+
+// SessionChangeFeed is an output port announcing which sessions changed.
+// Notifications are hints only: they may be duplicated or lost, so
+// consumers re-read the session and refresh periodically.
+type SessionChangeFeed interface {
+	Subscribe() (sessionIDs <-chan string, cancel func())
+}
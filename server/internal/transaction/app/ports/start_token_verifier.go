@@ -0,0 +1,9 @@
+package ports
+
+// StartTokenVerifier is an output port for validating the signed session-start
+// tokens embedded in device QR codes.
+type StartTokenVerifier interface {
+	// Verify returns the machine ID the token was issued for, or an error
+	// if the token is malformed, forged, or expired
+	Verify(token string) (machineID string, err error)
+}
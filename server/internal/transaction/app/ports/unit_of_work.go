@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/shared/events"
+)
+
+// UnitOfWork is an output port running writes all-or-nothing: what the
+// repositories and the outbox write with the context passed to fn is kept
+// only if fn returns nil
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventOutbox is an output port staging events for subscribers outside this
+// process within the unit of work that saves the change raising them
+type EventOutbox interface {
+	Stage(ctx context.Context, evts []events.DomainEvent) error
+}
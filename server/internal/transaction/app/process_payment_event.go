@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PaymentOutcome is what the payment provider reports about a payment
+type PaymentOutcome string
+
+const (
+	PaymentOutcomeSucceeded PaymentOutcome = "succeeded"
+	PaymentOutcomeFailed    PaymentOutcome = "failed"
+)
+
+// ProcessPaymentEventCommand is the input DTO for an asynchronous payment
+// outcome delivered by the provider's webhook
+type ProcessPaymentEventCommand struct {
+	SessionID     string // from the payment intent's metadata
+	IntentID      string
+	Outcome       PaymentOutcome
+	FailureReason string
+}
+
+// ProcessPaymentEventResult is the output DTO. Applied is false for events
+// that do not concern a session awaiting its payment, which are acknowledged
+// and dropped: the provider may deliver them more than once and out of order.
+type ProcessPaymentEventResult struct {
+	SessionID string
+	Status    string
+	Applied   bool
+}
+
+// ProcessPaymentEventHandler settles sessions awaiting their payment: a
+// payment that went through completes the session like a confirmation, one
+// that failed hands the session back to the customer
+type ProcessPaymentEventHandler struct {
+	sessions  domain.SessionRepository
+	confirm   *ConfirmSessionHandler
+	publisher eventPublisher
+}
+
+func NewProcessPaymentEventHandler(
+	sessions domain.SessionRepository,
+	confirm *ConfirmSessionHandler,
+	publisher eventPublisher,
+) *ProcessPaymentEventHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if confirm == nil {
+		panic("nil ConfirmSessionHandler")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ProcessPaymentEventHandler{
+		sessions:  sessions,
+		confirm:   confirm,
+		publisher: publisher,
+	}
+}
+
+func (h *ProcessPaymentEventHandler) Handle(ctx context.Context, cmd ProcessPaymentEventCommand) (ProcessPaymentEventResult, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return ProcessPaymentEventResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return ProcessPaymentEventResult{}, domain.ErrSessionNotFound
+	}
+
+	ignored := ProcessPaymentEventResult{SessionID: sess.ID().String(), Status: string(sess.Status())}
+	if sess.Status() != domain.SessionStatusAwaitingPayment || sess.PaymentIntentID() != cmd.IntentID {
+		return ignored, nil
+	}
+
+	switch cmd.Outcome {
+	case PaymentOutcomeSucceeded:
+		// The confirmation reads the intent back from the provider, so the
+		// session only completes on what the provider itself says
+		result, err := h.confirm.Handle(ctx, ConfirmSessionCommand{SessionID: cmd.SessionID})
+		if err != nil {
+			return ProcessPaymentEventResult{}, err
+		}
+		status := domain.SessionStatusCompleted
+		if result.AwaitingPayment {
+			status = domain.SessionStatusAwaitingPayment
+		}
+		return ProcessPaymentEventResult{SessionID: result.SessionID, Status: string(status), Applied: !result.AwaitingPayment && !result.Replayed}, nil
+
+	case PaymentOutcomeFailed:
+		if err := sess.FailPayment(cmd.FailureReason); err != nil {
+			return ProcessPaymentEventResult{}, err
+		}
+		if err := h.sessions.Save(ctx, sess); err != nil {
+			return ProcessPaymentEventResult{}, fmt.Errorf("failed to save session: %w", err)
+		}
+		for _, evt := range sess.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+		return ProcessPaymentEventResult{SessionID: sess.ID().String(), Status: string(sess.Status()), Applied: true}, nil
+
+	default:
+		return ignored, nil
+	}
+}
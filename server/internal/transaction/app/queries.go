@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 	"github.com/vending-machine/server/internal/transaction/domain"
@@ -9,39 +11,137 @@ import (
 
 // SessionView is a read-only view of a session
 type SessionView struct {
-	ID          string
-	DeviceID    string
-	UserID      string
-	Status      string
-	Items       []SessionItemView
-	TotalCents  int64
-	Currency    string
-	TotalWeight float64
-	CreatedAt   string
-	ExpiresAt   string
-	CompletedAt *string
+	ID            string
+	DeviceID      string
+	UserID        string
+	Status        string
+	Items         []SessionItemView
+	Units         int // items over all lines
+	TotalCents    int64
+	Currency      string
+	SubtotalCents int64 // item sum without tax, before the discount and rounding
+	DiscountCents int64 // taken off by the coupon
+	CouponCode    string
+	TaxCents      int64
+	RoundingCents int64 // TotalCents minus SubtotalCents and TaxCents, plus DiscountCents
+	TaxLines      []TaxLineView
+	TaxIncluded   bool
+	TotalWeight   float64
+	CreatedAt     string
+	ExpiresAt     string
+	CompletedAt   *string
+	Payment       *PaymentMethodView
+	Fiscal        *FiscalRecordView
+	Experiments   []ExperimentTagView
+
+	CloudVerificationRequired bool
+	// WeightMismatch flags a basket that does not match the measured weight;
+	// WeightOverride is set once an attendant resolved it
+	WeightMismatch bool
+	WeightOverride *WeightOverrideView
+	// FraudFlags are the fraud rules the session matched; FraudBlocked is
+	// set when one of them blocks it
+	FraudFlags   []FraudFlagView
+	FraudBlocked bool
+	// Unrecognized are the codes detected that the catalog does not sell,
+	// each with its most confident sighting
+	Unrecognized []UnrecognizedItemOutput
+}
+
+// FraudFlagView is a read-only view of a fraud rule a session matched
+type FraudFlagView struct {
+	Rule       string
+	Action     string
+	Reason     string
+	Stage      string
+	DetectedAt string
+}
+
+// WeightOverrideView is a read-only view of an attendant's resolution of a weight mismatch
+type WeightOverrideView struct {
+	ActorID      string
+	Decision     string
+	Reason       string
+	OverriddenAt string
+}
+
+// ExperimentTagView is a read-only view of a session's price experiment variant
+type ExperimentTagView struct {
+	ExperimentID string
+	Variant      string
+}
+
+// FiscalRecordView is a read-only view of a sale's fiscal identifiers
+type FiscalRecordView struct {
+	Country          string
+	Provider         string
+	DocumentNumber   string
+	SerialNumber     string
+	Signature        string
+	SignatureCounter int64
+	QRCode           string
+	IssuedAt         string
+}
+
+func toFiscalRecordView(rec domain.FiscalRecord) *FiscalRecordView {
+	if rec.IsZero() {
+		return nil
+	}
+	return &FiscalRecordView{
+		Country:          rec.Country(),
+		Provider:         rec.Provider(),
+		DocumentNumber:   rec.DocumentNumber(),
+		SerialNumber:     rec.SerialNumber(),
+		Signature:        rec.Signature(),
+		SignatureCounter: rec.SignatureCounter(),
+		QRCode:           rec.QRCode(),
+		IssuedAt:         rec.IssuedAt().Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// PaymentMethodView is a read-only view of how a session was paid
+type PaymentMethodView struct {
+	Wallet    string
+	CardBrand string
+	CardLast4 string
 }
 
 // SessionItemView is a read-only view of a detected item
 type SessionItemView struct {
-	SKUID      string
-	Code       string
-	Name       string
-	Confidence float64
-	PriceCents int64
-	Currency   string
+	SKUID           string
+	Code            string
+	Name            string
+	Confidence      float64
+	PriceCents      int64 // of one unit
+	Quantity        int
+	LineTotalCents  int64
+	Currency        string
+	MarkdownPercent int
+	Bundle          string      // bundle the item was sold in, if any
+	Review          string      // pending while it awaits an attendant, accepted once reviewed
+	BBoxes          [][]float64 // [x, y, width, height] of the units the device located
+}
+
+// DeviceActivityView describes the latest session on a device
+type DeviceActivityView struct {
+	HasActiveSession bool
+	LastCompletedAt  *time.Time
 }
 
 // SessionQueryService provides read-only access to sessions
 type SessionQueryService struct {
 	sessions domain.SessionRepository
+	notes    domain.SessionNoteRepository
 }
 
-func NewSessionQueryService(sessions domain.SessionRepository) *SessionQueryService {
+func NewSessionQueryService(sessions domain.SessionRepository, notes domain.SessionNoteRepository) *SessionQueryService {
 	if sessions == nil {
 		panic("nil SessionRepository")
 	}
-	return &SessionQueryService{sessions: sessions}
+	if notes == nil {
+		panic("nil SessionNoteRepository")
+	}
+	return &SessionQueryService{sessions: sessions, notes: notes}
 }
 
 func (s *SessionQueryService) FindByID(ctx context.Context, id string) (*SessionView, error) {
@@ -55,7 +155,22 @@ func (s *SessionQueryService) FindByID(ctx context.Context, id string) (*Session
 		return nil, err
 	}
 
-	return s.toView(sess), nil
+	return s.toView(sess)
+}
+
+// Notes lists the notes staff attached to the session, oldest first. They
+// are not part of SessionView, which also feeds the customer's live stream.
+func (s *SessionQueryService) Notes(ctx context.Context, id string) ([]SessionNoteView, error) {
+	sessionID, err := valueobjects.SessionIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	notes, err := s.notes.FindBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return toSessionNoteViews(notes), nil
 }
 
 func (s *SessionQueryService) FindActiveByDeviceID(ctx context.Context, deviceID string) (*SessionView, error) {
@@ -69,39 +184,399 @@ func (s *SessionQueryService) FindActiveByDeviceID(ctx context.Context, deviceID
 		return nil, err
 	}
 
-	return s.toView(sess), nil
+	return s.toView(sess)
+}
+
+// DeviceActivity summarises the device's latest session, for correlating door telemetry
+func (s *SessionQueryService) DeviceActivity(ctx context.Context, deviceID string) (*DeviceActivityView, error) {
+	devID, err := valueobjects.DeviceIDFrom(deviceID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	sess, err := s.sessions.FindLatestByDeviceID(ctx, devID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return &DeviceActivityView{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceActivityView{
+		HasActiveSession: sess.IsActive(),
+		LastCompletedAt:  sess.CompletedAt(),
+	}, nil
 }
 
-func (s *SessionQueryService) toView(sess *domain.Session) *SessionView {
+// FindAwaitingReview returns the active sessions with lines awaiting an
+// attendant's review, oldest first
+func (s *SessionQueryService) FindAwaitingReview(ctx context.Context) ([]*SessionView, error) {
+	sessions, err := s.sessions.FindAwaitingReview(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*SessionView, 0, len(sessions))
+	for _, sess := range sessions {
+		view, err := s.toView(sess)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ExperimentOutcomes returns per-variant session outcomes of a price experiment
+func (s *SessionQueryService) ExperimentOutcomes(ctx context.Context, experimentID string) ([]domain.ExperimentVariantStats, error) {
+	return s.sessions.ExperimentStats(ctx, experimentID)
+}
+
+func (s *SessionQueryService) toView(sess *domain.Session) (*SessionView, error) {
 	var items []SessionItemView
 	for _, item := range sess.DetectedItems() {
 		items = append(items, SessionItemView{
-			SKUID:      item.SKUID().String(),
-			Code:       item.Code(),
-			Name:       item.Name(),
-			Confidence: item.Confidence(),
-			PriceCents: item.Price().Amount(),
-			Currency:   item.Price().Currency(),
+			SKUID:           item.SKUID().String(),
+			Code:            item.Code(),
+			Name:            item.Name(),
+			Confidence:      item.Confidence(),
+			PriceCents:      item.Price().Amount(),
+			Quantity:        item.Quantity(),
+			LineTotalCents:  item.LineTotal().Amount(),
+			Currency:        item.Price().Currency(),
+			MarkdownPercent: sess.MarkdownPercent(item.Code()),
+			Bundle:          item.Bundle(),
+			Review:          string(item.Review()),
+			BBoxes:          boxCoords(item.Boxes()),
 		})
 	}
 
+	subtotal, err := sess.SubtotalCents()
+	if err != nil {
+		return nil, err
+	}
+	discount, err := sess.DiscountCents()
+	if err != nil {
+		return nil, err
+	}
+
 	var completedAt *string
 	if sess.CompletedAt() != nil {
 		t := sess.CompletedAt().Format("2006-01-02T15:04:05Z07:00")
 		completedAt = &t
 	}
 
+	var payment *PaymentMethodView
+	if pm := sess.PaymentMethod(); !pm.IsZero() {
+		payment = &PaymentMethodView{
+			Wallet:    string(pm.Wallet()),
+			CardBrand: pm.Brand(),
+			CardLast4: pm.Last4(),
+		}
+	}
+
+	var experiments []ExperimentTagView
+	for _, tag := range sess.Experiments() {
+		experiments = append(experiments, ExperimentTagView{
+			ExperimentID: tag.ExperimentID(),
+			Variant:      tag.Variant(),
+		})
+	}
+
 	return &SessionView{
-		ID:          sess.ID().String(),
-		DeviceID:    sess.DeviceID().String(),
-		UserID:      sess.UserID(),
-		Status:      string(sess.Status()),
-		Items:       items,
-		TotalCents:  sess.TotalAmount().Amount(),
-		Currency:    sess.TotalAmount().Currency(),
-		TotalWeight: sess.TotalWeight().Grams(),
-		CreatedAt:   sess.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
-		ExpiresAt:   sess.ExpiresAt().Format("2006-01-02T15:04:05Z07:00"),
-		CompletedAt: completedAt,
+		ID:            sess.ID().String(),
+		DeviceID:      sess.DeviceID().String(),
+		UserID:        sess.UserID(),
+		Status:        string(sess.Status()),
+		Items:         items,
+		Units:         sess.UnitCount(),
+		TotalCents:    sess.TotalAmount().Amount(),
+		Currency:      sess.TotalAmount().Currency(),
+		SubtotalCents: subtotal,
+		DiscountCents: discount,
+		CouponCode:    sess.Coupon().Code(),
+		TaxCents:      sess.Tax().Cents(),
+		RoundingCents: sess.RoundingCents(),
+		TaxLines:      toTaxLineViews(sess.Tax()),
+		TaxIncluded:   sess.Tax().Included(),
+		TotalWeight:   sess.TotalWeight().Grams(),
+		CreatedAt:     sess.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		ExpiresAt:     sess.ExpiresAt().Format("2006-01-02T15:04:05Z07:00"),
+		CompletedAt:   completedAt,
+		Payment:       payment,
+		Fiscal:        toFiscalRecordView(sess.FiscalRecord()),
+		Experiments:   experiments,
+
+		CloudVerificationRequired: sess.CloudVerificationRequired(),
+		WeightMismatch:            sess.WeightMismatch(),
+		WeightOverride:            toWeightOverrideView(sess.WeightOverride()),
+		FraudFlags:                toFraudFlagViews(sess.FraudFlags()),
+		FraudBlocked:              sess.FraudBlocked(),
+		Unrecognized:              toUnrecognizedItemOutputs(sess.UnrecognizedItems()),
+	}, nil
+}
+
+func toUnrecognizedItemOutputs(items []domain.UnrecognizedItem) []UnrecognizedItemOutput {
+	var outputs []UnrecognizedItemOutput
+	for _, u := range items {
+		outputs = append(outputs, toUnrecognizedItemOutput(u))
+	}
+	return outputs
+}
+
+func toFraudFlagViews(findings []domain.FraudFinding) []FraudFlagView {
+	var views []FraudFlagView
+	for _, f := range findings {
+		views = append(views, FraudFlagView{
+			Rule:       f.Rule(),
+			Action:     string(f.Action()),
+			Reason:     f.Reason(),
+			Stage:      string(f.Stage()),
+			DetectedAt: f.DetectedAt().Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return views
+}
+
+func toWeightOverrideView(o domain.WeightOverride) *WeightOverrideView {
+	if o.IsZero() {
+		return nil
+	}
+	return &WeightOverrideView{
+		ActorID:      o.ActorID(),
+		Decision:     string(o.Decision()),
+		Reason:       o.Reason(),
+		OverriddenAt: o.OverriddenAt().Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// RefundView is a read-only view of a refund including its audit trail
+type RefundView struct {
+	ID            string
+	SessionID     string
+	TransactionID string
+	AmountCents   int64
+	RoundingCents int64
+	Currency      string
+	Reason        string
+	Status        string
+	RequestedBy   string
+	DecidedBy     string
+	CreatedAt     string
+	DecidedAt     *string
+	AuditTrail    []RefundAuditView
+}
+
+// RefundAuditView is a read-only view of a refund audit entry
+type RefundAuditView struct {
+	Action  string
+	ActorID string
+	Roles   []string
+	Note    string
+	At      string
+}
+
+// RefundQueryService provides read-only access to refunds
+type RefundQueryService struct {
+	refunds domain.RefundRepository
+}
+
+func NewRefundQueryService(refunds domain.RefundRepository) *RefundQueryService {
+	if refunds == nil {
+		panic("nil RefundRepository")
+	}
+	return &RefundQueryService{refunds: refunds}
+}
+
+func (s *RefundQueryService) FindByID(ctx context.Context, id string) (*RefundView, error) {
+	refundID, err := valueobjects.RefundIDFrom(id)
+	if err != nil {
+		return nil, domain.ErrRefundNotFound
+	}
+
+	refund, err := s.refunds.FindByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toView(refund), nil
+}
+
+func (s *RefundQueryService) FindBySessionID(ctx context.Context, sessionID string) ([]*RefundView, error) {
+	id, err := valueobjects.SessionIDFrom(sessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	refunds, err := s.refunds.FindBySessionID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toViews(refunds), nil
+}
+
+// FindPendingApproval lists refunds waiting for a finance approver
+func (s *RefundQueryService) FindPendingApproval(ctx context.Context) ([]*RefundView, error) {
+	refunds, err := s.refunds.FindByStatus(ctx, domain.RefundStatusPendingApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toViews(refunds), nil
+}
+
+func (s *RefundQueryService) toViews(refunds []*domain.Refund) []*RefundView {
+	views := make([]*RefundView, 0, len(refunds))
+	for _, r := range refunds {
+		views = append(views, s.toView(r))
+	}
+	return views
+}
+
+func (s *RefundQueryService) toView(r *domain.Refund) *RefundView {
+	var audit []RefundAuditView
+	for _, entry := range r.AuditTrail() {
+		audit = append(audit, RefundAuditView{
+			Action:  string(entry.Action),
+			ActorID: entry.ActorID,
+			Roles:   entry.Roles,
+			Note:    entry.Note,
+			At:      entry.At.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	var decidedAt *string
+	if r.DecidedAt() != nil {
+		t := r.DecidedAt().Format("2006-01-02T15:04:05Z07:00")
+		decidedAt = &t
+	}
+
+	return &RefundView{
+		ID:            r.ID().String(),
+		SessionID:     r.SessionID().String(),
+		TransactionID: transactionIDString(r.TransactionID()),
+		AmountCents:   r.Amount().Amount(),
+		RoundingCents: r.RoundingCents(),
+		Currency:      r.Amount().Currency(),
+		Reason:        r.Reason(),
+		Status:        string(r.Status()),
+		RequestedBy:   r.RequestedBy(),
+		DecidedBy:     r.DecidedBy(),
+		CreatedAt:     r.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		DecidedAt:     decidedAt,
+		AuditTrail:    audit,
+	}
+}
+
+// ReconciliationReportView is a read-only view of a day's edge vs cloud reconciliation
+type ReconciliationReportView struct {
+	Day                     string
+	ModelVersion            string
+	SessionsChecked         int
+	SessionsSkipped         int
+	SessionsWithDiscrepancy int
+	GeneratedAt             string
+	Lines                   []ReconciliationLineView
+	Discrepancies           []SessionDiscrepancyView
+}
+
+// ReconciliationLineView is the revenue impact of one SKU on one device
+type ReconciliationLineView struct {
+	DeviceID          string
+	SKUCode           string
+	Currency          string
+	Sessions          int
+	UnderchargedUnits int
+	OverchargedUnits  int
+	UnderchargedCents int64
+	OverchargedCents  int64
+	NetImpactCents    int64
+}
+
+// SessionDiscrepancyView is one SKU of one session the cloud model disagrees with
+type SessionDiscrepancyView struct {
+	SessionID   string
+	DeviceID    string
+	SKUCode     string
+	Charged     int
+	Detected    int
+	ImpactCents int64
+	Currency    string
+}
+
+// ReconciliationQueryService provides read-only access to reconciliation reports
+type ReconciliationQueryService struct {
+	reports domain.ReconciliationRepository
+}
+
+func NewReconciliationQueryService(reports domain.ReconciliationRepository) *ReconciliationQueryService {
+	if reports == nil {
+		panic("nil ReconciliationRepository")
+	}
+	return &ReconciliationQueryService{reports: reports}
+}
+
+// FindByDay returns the report of a day (YYYY-MM-DD), narrowed to one device if deviceID is set
+func (s *ReconciliationQueryService) FindByDay(ctx context.Context, day, deviceID string) (*ReconciliationReportView, error) {
+	if _, err := time.Parse(domain.ReconciliationDayLayout, day); err != nil {
+		return nil, domain.ErrReconciliationReportNotFound
+	}
+
+	report, err := s.reports.FindByDay(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	return toReconciliationReportView(report, deviceID), nil
+}
+
+func toReconciliationReportView(r *domain.ReconciliationReport, deviceID string) *ReconciliationReportView {
+	var discrepancies []domain.SessionDiscrepancy
+	for _, d := range r.Discrepancies() {
+		if deviceID == "" || d.DeviceID == deviceID {
+			discrepancies = append(discrepancies, d)
+		}
 	}
+
+	view := &ReconciliationReportView{
+		Day:             r.Day(),
+		ModelVersion:    r.ModelVersion(),
+		SessionsChecked: r.SessionsChecked(),
+		SessionsSkipped: r.SessionsSkipped(),
+		GeneratedAt:     r.GeneratedAt().Format("2006-01-02T15:04:05Z07:00"),
+		Lines:           []ReconciliationLineView{},
+		Discrepancies:   []SessionDiscrepancyView{},
+	}
+
+	sessions := make(map[string]bool)
+	for _, d := range discrepancies {
+		sessions[d.SessionID] = true
+		view.Discrepancies = append(view.Discrepancies, SessionDiscrepancyView{
+			SessionID:   d.SessionID,
+			DeviceID:    d.DeviceID,
+			SKUCode:     d.SKUCode,
+			Charged:     d.Charged,
+			Detected:    d.Detected,
+			ImpactCents: d.ImpactCents,
+			Currency:    d.Currency,
+		})
+	}
+	view.SessionsWithDiscrepancy = len(sessions)
+
+	for _, line := range domain.SummarizeDiscrepancies(discrepancies) {
+		view.Lines = append(view.Lines, ReconciliationLineView{
+			DeviceID:          line.DeviceID,
+			SKUCode:           line.SKUCode,
+			Currency:          line.Currency,
+			Sessions:          line.Sessions,
+			UnderchargedUnits: line.UnderchargedUnits,
+			OverchargedUnits:  line.OverchargedUnits,
+			UnderchargedCents: line.UnderchargedCents,
+			OverchargedCents:  line.OverchargedCents,
+			NetImpactCents:    line.NetImpactCents(),
+		})
+	}
+
+	return view
 }
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+const (
+	DefaultRecommendationLimit = 3
+	MaxRecommendationLimit     = 10
+)
+
+// RecommendationView is a suggested SKU for upselling during a session
+type RecommendationView struct {
+	Code       string
+	Name       string
+	PriceCents int64
+	Currency   string
+	Score      float64
+}
+
+// RecommendationService suggests SKUs that complement a session's basket
+type RecommendationService struct {
+	sessions    domain.SessionRepository
+	recommender ports.Recommender
+	catalog     ports.CatalogReader
+}
+
+func NewRecommendationService(
+	sessions domain.SessionRepository,
+	recommender ports.Recommender,
+	catalog ports.CatalogReader,
+) *RecommendationService {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if recommender == nil {
+		panic("nil Recommender")
+	}
+	if catalog == nil {
+		panic("nil CatalogReader")
+	}
+	return &RecommendationService{
+		sessions:    sessions,
+		recommender: recommender,
+		catalog:     catalog,
+	}
+}
+
+// ForSession returns up to limit active SKUs not already in the session's basket
+func (s *RecommendationService) ForSession(ctx context.Context, sessionID string, limit int) ([]RecommendationView, error) {
+	if limit <= 0 {
+		limit = DefaultRecommendationLimit
+	}
+	if limit > MaxRecommendationLimit {
+		limit = MaxRecommendationLimit
+	}
+
+	id, err := valueobjects.SessionIDFrom(sessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	sess, err := s.sessions.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var basket []string
+	for _, item := range sess.DetectedItems() {
+		if !seen[item.Code()] {
+			seen[item.Code()] = true
+			basket = append(basket, item.Code())
+		}
+	}
+
+	// Over-fetch so delisted SKUs can be dropped without coming up short
+	candidates, err := s.recommender.Recommend(ctx, basket, limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]RecommendationView, 0, limit)
+	for _, candidate := range candidates {
+		if len(views) == limit {
+			break
+		}
+		sku, err := s.catalog.FindSKUByCode(ctx, candidate.Code)
+		if err != nil || !sku.Active {
+			continue
+		}
+		views = append(views, RecommendationView{
+			Code:       sku.Code,
+			Name:       sku.Name,
+			PriceCents: sku.PriceCents,
+			Currency:   sku.Currency,
+			Score:      candidate.Score,
+		})
+	}
+	return views, nil
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ErrReconciliationDayNotOver is returned when reconciling a day that has not ended yet
+var ErrReconciliationDayNotOver = errors.New("day has not ended yet")
+
+// ReconcileSessionsCommand is the input DTO for reconciling a day of sessions
+type ReconcileSessionsCommand struct {
+	Day time.Time // any time within the day (UTC)
+
+	// Rerun replaces an existing report, e.g. after a model update;
+	// otherwise the existing report is returned unchanged
+	Rerun bool
+}
+
+// ReconcileSessionsResult is the output DTO
+type ReconcileSessionsResult struct {
+	Report *ReconciliationReportView
+	Ran    bool // false when an existing report was returned
+}
+
+// ReconcileSessionsHandler re-runs the stored images of a day's completed
+// sessions through the current cloud model and compares the result with
+// what the customers were charged from the edge detections
+type ReconcileSessionsHandler struct {
+	sessions      domain.SessionRepository
+	images        domain.SessionImageRepository
+	reports       domain.ReconciliationRepository
+	catalog       ports.CatalogReader
+	detector      ports.SessionDetector
+	publisher     eventPublisher
+	minConfidence float64
+}
+
+func NewReconcileSessionsHandler(
+	sessions domain.SessionRepository,
+	images domain.SessionImageRepository,
+	reports domain.ReconciliationRepository,
+	catalog ports.CatalogReader,
+	detector ports.SessionDetector,
+	publisher eventPublisher,
+	minConfidence float64,
+) *ReconcileSessionsHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if images == nil {
+		panic("nil SessionImageRepository")
+	}
+	if reports == nil {
+		panic("nil ReconciliationRepository")
+	}
+	if catalog == nil {
+		panic("nil CatalogReader")
+	}
+	if detector == nil {
+		panic("nil SessionDetector")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ReconcileSessionsHandler{
+		sessions:      sessions,
+		images:        images,
+		reports:       reports,
+		catalog:       catalog,
+		detector:      detector,
+		publisher:     publisher,
+		minConfidence: minConfidence,
+	}
+}
+
+func (h *ReconcileSessionsHandler) Handle(ctx context.Context, cmd ReconcileSessionsCommand) (ReconcileSessionsResult, error) {
+	day := cmd.Day.UTC().Truncate(24 * time.Hour)
+	if day.Add(24 * time.Hour).After(time.Now()) {
+		return ReconcileSessionsResult{}, ErrReconciliationDayNotOver
+	}
+
+	if !cmd.Rerun {
+		existing, err := h.reports.FindByDay(ctx, day.Format(domain.ReconciliationDayLayout))
+		if err == nil {
+			return ReconcileSessionsResult{Report: toReconciliationReportView(existing, "")}, nil
+		}
+		if !errors.Is(err, domain.ErrReconciliationReportNotFound) {
+			return ReconcileSessionsResult{}, err
+		}
+	}
+
+	sessions, err := h.sessions.FindCompletedBetween(ctx, day, day.Add(24*time.Hour))
+	if err != nil {
+		return ReconcileSessionsResult{}, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	report := domain.NewReconciliationReport(day)
+	modelVersion := ""
+	for _, sess := range sessions {
+		image, err := h.images.FindBySessionID(ctx, sess.ID())
+		if err != nil {
+			if errors.Is(err, domain.ErrSessionImageNotFound) {
+				report.SkipSession()
+				continue
+			}
+			return ReconcileSessionsResult{}, err
+		}
+
+		result, err := h.detector.DetectSession(ctx, sess.DeviceID().String(), image)
+		if err != nil {
+			if errors.Is(err, ports.ErrCloudDetectionUnavailable) {
+				return ReconcileSessionsResult{}, err
+			}
+			report.SkipSession()
+			continue
+		}
+		modelVersion = result.ModelVersion
+
+		report.RecordSession(sess.ID(), sess.DeviceID(), sess.TotalAmount().Currency(), h.compare(ctx, sess, result.Detections))
+	}
+	report.Complete(modelVersion)
+
+	if err := h.reports.Save(ctx, report); err != nil {
+		return ReconcileSessionsResult{}, fmt.Errorf("failed to save reconciliation report: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range report.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return ReconcileSessionsResult{Report: toReconciliationReportView(report, ""), Ran: true}, nil
+}
+
+// compare lines up the charged units per SKU with the confident cloud detections
+func (h *ReconcileSessionsHandler) compare(ctx context.Context, sess *domain.Session, detections []ports.CloudDetection) []domain.SKUComparison {
+	byCode := make(map[string]*domain.SKUComparison)
+	line := func(code string) *domain.SKUComparison {
+		if c, ok := byCode[code]; ok {
+			return c
+		}
+		c := &domain.SKUComparison{SKUCode: code}
+		byCode[code] = c
+		return c
+	}
+
+	for _, item := range sess.DetectedItems() {
+		c := line(item.Code())
+		c.Charged += item.Quantity()
+		c.UnitPriceCents = item.Price().Amount()
+	}
+	for _, d := range detections {
+		if d.Confidence < h.minConfidence {
+			continue
+		}
+		line(d.SKUCode).Detected++
+	}
+
+	comparisons := make([]domain.SKUComparison, 0, len(byCode))
+	for _, c := range byCode {
+		// SKUs the customer was not charged for are valued at what they would have paid
+		if c.Charged == 0 {
+			c.UnitPriceCents = h.unitPrice(ctx, sess, c.SKUCode)
+		}
+		comparisons = append(comparisons, *c)
+	}
+	sort.Slice(comparisons, func(i, j int) bool { return comparisons[i].SKUCode < comparisons[j].SKUCode })
+
+	return comparisons
+}
+
+func (h *ReconcileSessionsHandler) unitPrice(ctx context.Context, sess *domain.Session, code string) int64 {
+	markdown := sess.MarkdownPercent(code)
+	if cents, ok := sess.ExperimentPrice(code); ok {
+		return policy.ApplyMarkdown(cents, markdown)
+	}
+	sku, err := h.catalog.FindSKUByCode(ctx, code)
+	if err != nil {
+		return 0
+	}
+	return policy.ApplyMarkdown(sku.PriceCents, markdown)
+}
@@ -0,0 +1,354 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reconciliation strategies
+const (
+	// ReconcileCloudFirst takes the cloud model's count of each SKU it sees
+	// and the device's count of the others
+	ReconcileCloudFirst = "cloud_first"
+	// ReconcileIoU pairs the device's and the cloud model's detections of the
+	// same object by the overlap of their boxes and keeps the more confident
+	// of each pair
+	ReconcileIoU = "iou"
+	// ReconcileConfidence takes the count of each SKU from whichever side is
+	// more confident of it, the cloud model's confidence weighted
+	ReconcileConfidence = "confidence"
+	// ReconcileMajority takes the count of each SKU most of the device's
+	// latest frames and the cloud model agree on
+	ReconcileMajority = "majority"
+)
+
+const (
+	defaultIoUThreshold  = 0.5
+	defaultCloudWeight   = 1.0
+	defaultMajorityFrame = 3
+)
+
+// ErrInvalidReconcileStrategy is returned for a strategy that cannot be parsed
+var ErrInvalidReconcileStrategy = errors.New("invalid detection reconciliation strategy")
+
+// FrameDetection is one object a model found on a frame. Box is where, as
+// [x, y, width, height] fractions of the frame; nil when the model gave none.
+type FrameDetection struct {
+	SKU        string
+	Confidence float64
+	Box        []float64
+}
+
+// ReconcileInput is what the device and the cloud model saw of a basket
+type ReconcileInput struct {
+	Basket []FrameDetection   // the session's basket, one detection per unit
+	Frames [][]FrameDetection // the device's latest full frames, oldest first
+	Cloud  []FrameDetection   // what the cloud model found on the session image
+}
+
+// DetectionReconciler merges what the device and the cloud model saw of a
+// basket into the units to charge for. Detections below the minimum
+// confidence are left out on both sides.
+type DetectionReconciler struct {
+	strategy      string
+	param         float64 // IoU threshold, cloud weight or frames voting, by strategy
+	minConfidence float64
+}
+
+// ParseDetectionReconciler reads a strategy such as "cloud_first", "iou=0.5"
+// (the overlap that makes two boxes one object), "confidence=1.5" (how much
+// more the cloud model's confidence counts) or "majority=3" (the device
+// frames voting with the cloud model). Empty is cloud_first.
+func ParseDetectionReconciler(spec string, minConfidence float64) (*DetectionReconciler, error) {
+	name, value, hasValue := strings.Cut(strings.TrimSpace(spec), "=")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = ReconcileCloudFirst
+	}
+
+	r := &DetectionReconciler{strategy: name, minConfidence: minConfidence}
+	switch name {
+	case ReconcileCloudFirst:
+		if hasValue {
+			return nil, fmt.Errorf("%w: %s takes no value", ErrInvalidReconcileStrategy, name)
+		}
+		return r, nil
+	case ReconcileIoU:
+		r.param = defaultIoUThreshold
+	case ReconcileConfidence:
+		r.param = defaultCloudWeight
+	case ReconcileMajority:
+		r.param = defaultMajorityFrame
+	default:
+		return nil, fmt.Errorf("%w: unknown strategy %q", ErrInvalidReconcileStrategy, name)
+	}
+	if !hasValue {
+		return r, nil
+	}
+
+	param, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s=%s", ErrInvalidReconcileStrategy, name, value)
+	}
+	switch {
+	case name == ReconcileIoU && (param <= 0 || param > 1),
+		name == ReconcileConfidence && param <= 0,
+		name == ReconcileMajority && (param < 1 || param != float64(int(param))):
+		return nil, fmt.Errorf("%w: %s=%s", ErrInvalidReconcileStrategy, name, value)
+	}
+	r.param = param
+	return r, nil
+}
+
+// Strategy is the name of the reconciler's strategy
+func (r *DetectionReconciler) Strategy() string { return r.strategy }
+
+// FramesNeeded is how many of the device's latest frames the strategy looks
+// at; 0 when the basket is enough
+func (r *DetectionReconciler) FramesNeeded() int {
+	switch r.strategy {
+	case ReconcileIoU:
+		return 1
+	case ReconcileMajority:
+		return int(r.param)
+	}
+	return 0
+}
+
+// Reconcile returns the units of the reconciled basket, grouped by SKU in the
+// order of the basket, then of the SKUs only seen elsewhere
+func (r *DetectionReconciler) Reconcile(in ReconcileInput) []FrameDetection {
+	basket := r.confident(in.Basket)
+	cloud := r.confident(in.Cloud)
+	frames := make([][]FrameDetection, 0, len(in.Frames))
+	for _, frame := range in.Frames {
+		frames = append(frames, r.confident(frame))
+	}
+
+	var units []FrameDetection
+	switch r.strategy {
+	case ReconcileIoU:
+		// The basket keeps the boxes of its units for devices that only
+		// send incremental frames
+		edge := basket
+		if len(frames) > 0 {
+			edge = frames[len(frames)-1]
+		}
+		if !boxed(edge) || !boxed(cloud) {
+			// Without boxes objects cannot be paired
+			units = cloudFirst(basket, cloud)
+		} else {
+			units = matchByIoU(edge, cloud, r.param)
+		}
+	case ReconcileConfidence:
+		units = weighConfidence(basket, cloud, r.param)
+	case ReconcileMajority:
+		if len(frames) == 0 {
+			frames = [][]FrameDetection{basket}
+		}
+		units = majorityVote(append(frames, cloud))
+	default:
+		units = cloudFirst(basket, cloud)
+	}
+	return groupBySKU(in.Basket, units)
+}
+
+func (r *DetectionReconciler) confident(detections []FrameDetection) []FrameDetection {
+	out := make([]FrameDetection, 0, len(detections))
+	for _, d := range detections {
+		if d.Confidence >= r.minConfidence {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// cloudFirst takes the cloud model's units of each SKU it sees and the
+// device's units of the others
+func cloudFirst(basket, cloud []FrameDetection) []FrameDetection {
+	seen := make(map[string]bool)
+	for _, d := range cloud {
+		seen[d.SKU] = true
+	}
+	units := append([]FrameDetection{}, cloud...)
+	for _, d := range basket {
+		if !seen[d.SKU] {
+			units = append(units, d)
+		}
+	}
+	return units
+}
+
+// weighConfidence takes each SKU's units from the side with the higher mean
+// confidence, the cloud model's multiplied by cloudWeight
+func weighConfidence(basket, cloud []FrameDetection, cloudWeight float64) []FrameDetection {
+	edgeBySKU, cloudBySKU := bySKU(basket), bySKU(cloud)
+
+	var units []FrameDetection
+	for _, code := range skuOrder(basket, cloud) {
+		edge, cl := edgeBySKU[code], cloudBySKU[code]
+		switch {
+		case len(cl) == 0:
+			units = append(units, edge...)
+		case len(edge) == 0:
+			units = append(units, cl...)
+		case meanConfidence(cl)*cloudWeight >= meanConfidence(edge):
+			units = append(units, cl...)
+		default:
+			units = append(units, edge...)
+		}
+	}
+	return units
+}
+
+// matchByIoU pairs detections of the device and the cloud model whose boxes
+// overlap by at least threshold, best overlaps first. A pair is one unit of
+// the more confident side's SKU; unpaired detections are units of their own.
+func matchByIoU(edge, cloud []FrameDetection, threshold float64) []FrameDetection {
+	type pair struct {
+		e, c int
+		iou  float64
+	}
+	var pairs []pair
+	for i, e := range edge {
+		for j, c := range cloud {
+			if v := iou(e.Box, c.Box); v >= threshold {
+				pairs = append(pairs, pair{e: i, c: j, iou: v})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].iou > pairs[j].iou })
+
+	edgePaired := make([]bool, len(edge))
+	cloudPaired := make([]bool, len(cloud))
+	var units []FrameDetection
+	for _, p := range pairs {
+		if edgePaired[p.e] || cloudPaired[p.c] {
+			continue
+		}
+		edgePaired[p.e], cloudPaired[p.c] = true, true
+		unit := edge[p.e]
+		if cloud[p.c].Confidence >= unit.Confidence {
+			unit = cloud[p.c]
+		}
+		units = append(units, unit)
+	}
+	for i, e := range edge {
+		if !edgePaired[i] {
+			units = append(units, e)
+		}
+	}
+	for j, c := range cloud {
+		if !cloudPaired[j] {
+			units = append(units, c)
+		}
+	}
+	return units
+}
+
+// majorityVote takes for each SKU the unit count most frames agree on; a tie
+// goes to the count of the latest frame among the tied ones. The units are
+// taken from the latest frame with that count.
+func majorityVote(frames [][]FrameDetection) []FrameDetection {
+	perFrame := make([]map[string][]FrameDetection, len(frames))
+	for i, frame := range frames {
+		perFrame[i] = bySKU(frame)
+	}
+
+	var units []FrameDetection
+	for _, code := range skuOrder(frames...) {
+		votes := make(map[int]int)
+		latest := make(map[int]int) // count -> latest frame with it
+		for i := range frames {
+			count := len(perFrame[i][code])
+			votes[count]++
+			latest[count] = i
+		}
+
+		best := -1
+		for count, n := range votes {
+			if best < 0 || n > votes[best] || (n == votes[best] && latest[count] > latest[best]) {
+				best = count
+			}
+		}
+		units = append(units, perFrame[latest[best]][code]...)
+	}
+	return units
+}
+
+// iou is the area two boxes share over the area they cover together; 0 when
+// either is not a box
+func iou(a, b []float64) float64 {
+	if len(a) != 4 || len(b) != 4 {
+		return 0
+	}
+	left, top := max(a[0], b[0]), max(a[1], b[1])
+	right, bottom := min(a[0]+a[2], b[0]+b[2]), min(a[1]+a[3], b[1]+b[3])
+	if right <= left || bottom <= top {
+		return 0
+	}
+	shared := (right - left) * (bottom - top)
+	union := a[2]*a[3] + b[2]*b[3] - shared
+	if union <= 0 {
+		return 0
+	}
+	return shared / union
+}
+
+// boxed reports whether every detection has a box
+func boxed(detections []FrameDetection) bool {
+	for _, d := range detections {
+		if len(d.Box) != 4 {
+			return false
+		}
+	}
+	return true
+}
+
+func bySKU(detections []FrameDetection) map[string][]FrameDetection {
+	out := make(map[string][]FrameDetection)
+	for _, d := range detections {
+		out[d.SKU] = append(out[d.SKU], d)
+	}
+	return out
+}
+
+// skuOrder lists the SKUs of the detections in the order first seen
+func skuOrder(lists ...[]FrameDetection) []string {
+	seen := make(map[string]bool)
+	var order []string
+	for _, list := range lists {
+		for _, d := range list {
+			if !seen[d.SKU] {
+				seen[d.SKU] = true
+				order = append(order, d.SKU)
+			}
+		}
+	}
+	return order
+}
+
+// groupBySKU keeps the units of a SKU together, ordered by SKU as the basket
+// lists them, then the SKUs not in the basket
+func groupBySKU(basket, units []FrameDetection) []FrameDetection {
+	grouped := bySKU(units)
+	out := make([]FrameDetection, 0, len(units))
+	for _, code := range skuOrder(basket, units) {
+		out = append(out, grouped[code]...)
+	}
+	return out
+}
+
+func meanConfidence(detections []FrameDetection) float64 {
+	if len(detections) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, d := range detections {
+		sum += d.Confidence
+	}
+	return sum / float64(len(detections))
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// seen returns n detections of a SKU at the given confidence, without boxes
+func seen(sku string, n int, confidence float64) []FrameDetection {
+	out := make([]FrameDetection, n)
+	for i := range out {
+		out[i] = FrameDetection{SKU: sku, Confidence: confidence}
+	}
+	return out
+}
+
+func boxedAt(sku string, confidence float64, box ...float64) FrameDetection {
+	return FrameDetection{SKU: sku, Confidence: confidence, Box: box}
+}
+
+func join(lists ...[]FrameDetection) []FrameDetection {
+	var out []FrameDetection
+	for _, list := range lists {
+		out = append(out, list...)
+	}
+	return out
+}
+
+// counts renders reconciled units as "SKU:n" in their order, e.g. "COLA:2 CHIPS:1"
+func counts(units []FrameDetection) string {
+	var parts []string
+	for i := 0; i < len(units); {
+		j := i
+		for j < len(units) && units[j].SKU == units[i].SKU {
+			j++
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d", units[i].SKU, j-i))
+		i = j
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDetectionReconcilerReconcile(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		in       ReconcileInput
+		want     string
+	}{
+		{
+			name:     "cloud_first takes the cloud count of the SKUs it sees",
+			strategy: "cloud_first",
+			in: ReconcileInput{
+				Basket: join(seen("COLA", 1, 0.9), seen("CHIPS", 1, 0.9)),
+				Cloud:  seen("COLA", 2, 0.8),
+			},
+			want: "COLA:2 CHIPS:1",
+		},
+		{
+			name:     "cloud_first adds SKUs only the cloud sees after the basket",
+			strategy: "",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.9),
+				Cloud:  join(seen("WATER", 1, 0.8), seen("COLA", 1, 0.8)),
+			},
+			want: "COLA:1 WATER:1",
+		},
+		{
+			name:     "iou pairs overlapping boxes and keeps the more confident SKU",
+			strategy: "iou=0.5",
+			in: ReconcileInput{
+				Basket: join(seen("COLA", 1, 0.7), seen("CHIPS", 1, 0.9)),
+				Frames: [][]FrameDetection{{
+					boxedAt("COLA", 0.7, 0.10, 0.10, 0.20, 0.20),
+					boxedAt("CHIPS", 0.9, 0.60, 0.60, 0.20, 0.20),
+				}},
+				Cloud: []FrameDetection{
+					boxedAt("WATER", 0.95, 0.11, 0.11, 0.20, 0.20),
+				},
+			},
+			want: "CHIPS:1 WATER:1",
+		},
+		{
+			name:     "iou counts boxes that overlap too little as two objects",
+			strategy: "iou=0.9",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.8),
+				Frames: [][]FrameDetection{{boxedAt("COLA", 0.8, 0.10, 0.10, 0.20, 0.20)}},
+				Cloud:  []FrameDetection{boxedAt("COLA", 0.9, 0.15, 0.15, 0.20, 0.20)},
+			},
+			want: "COLA:2",
+		},
+		{
+			name:     "iou falls back to cloud_first without boxes",
+			strategy: "iou",
+			in: ReconcileInput{
+				Basket: join(seen("COLA", 1, 0.9), seen("CHIPS", 1, 0.9)),
+				Cloud:  seen("COLA", 3, 0.8),
+			},
+			want: "COLA:3 CHIPS:1",
+		},
+		{
+			name:     "confidence takes the more confident side",
+			strategy: "confidence",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.9),
+				Cloud:  seen("COLA", 2, 0.6),
+			},
+			want: "COLA:1",
+		},
+		{
+			name:     "confidence weighs the cloud model",
+			strategy: "confidence=2",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.9),
+				Cloud:  seen("COLA", 2, 0.6),
+			},
+			want: "COLA:2",
+		},
+		{
+			name:     "confidence tie goes to the cloud model",
+			strategy: "confidence",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.8),
+				Cloud:  seen("COLA", 2, 0.8),
+			},
+			want: "COLA:2",
+		},
+		{
+			name:     "majority takes the count most frames agree on",
+			strategy: "majority=3",
+			in: ReconcileInput{
+				Basket: seen("COLA", 2, 0.9),
+				Frames: [][]FrameDetection{seen("COLA", 2, 0.9), seen("COLA", 1, 0.9), seen("COLA", 2, 0.9)},
+				Cloud:  seen("COLA", 2, 0.8),
+			},
+			want: "COLA:2",
+		},
+		{
+			name:     "majority tie goes to the latest of the tied counts",
+			strategy: "majority=3",
+			in: ReconcileInput{
+				Basket: seen("COLA", 2, 0.9),
+				Frames: [][]FrameDetection{seen("COLA", 1, 0.9), seen("COLA", 2, 0.9), seen("COLA", 2, 0.9)},
+				Cloud:  seen("COLA", 1, 0.8),
+			},
+			want: "COLA:1",
+		},
+		{
+			name:     "majority votes with the basket when no frames were sent",
+			strategy: "majority",
+			in: ReconcileInput{
+				Basket: seen("COLA", 3, 0.9),
+				Cloud:  seen("COLA", 3, 0.8),
+			},
+			want: "COLA:3",
+		},
+		{
+			name:     "detections below the minimum confidence are left out",
+			strategy: "cloud_first",
+			in: ReconcileInput{
+				Basket: seen("COLA", 1, 0.9),
+				Cloud:  seen("COLA", 2, 0.3),
+			},
+			want: "COLA:1",
+		},
+	}
+	for _, strategy := range []string{"cloud_first", "iou", "confidence", "majority"} {
+		tests = append(tests, struct {
+			name     string
+			strategy string
+			in       ReconcileInput
+			want     string
+		}{name: strategy + " of nothing is nothing", strategy: strategy})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseDetectionReconciler(tt.strategy, 0.5)
+			if err != nil {
+				t.Fatalf("ParseDetectionReconciler(%q): %v", tt.strategy, err)
+			}
+			if got := counts(r.Reconcile(tt.in)); got != tt.want {
+				t.Errorf("Reconcile = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDetectionReconciler(t *testing.T) {
+	tests := []struct {
+		spec     string
+		strategy string
+		frames   int
+		invalid  bool
+	}{
+		{spec: "", strategy: ReconcileCloudFirst},
+		{spec: "cloud_first", strategy: ReconcileCloudFirst},
+		{spec: "iou", strategy: ReconcileIoU, frames: 1},
+		{spec: "confidence=1.5", strategy: ReconcileConfidence},
+		{spec: "majority", strategy: ReconcileMajority, frames: defaultMajorityFrame},
+		{spec: "majority=5", strategy: ReconcileMajority, frames: 5},
+		{spec: "cloud_first=1", invalid: true},
+		{spec: "iou=1.5", invalid: true},
+		{spec: "confidence=0", invalid: true},
+		{spec: "majority=2.5", invalid: true},
+		{spec: "majority=abc", invalid: true},
+		{spec: "vote", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			r, err := ParseDetectionReconciler(tt.spec, 0.5)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidReconcileStrategy) {
+					t.Errorf("error = %v, want ErrInvalidReconcileStrategy", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDetectionReconciler: %v", err)
+			}
+			if r.Strategy() != tt.strategy || r.FramesNeeded() != tt.frames {
+				t.Errorf("got %s needing %d frames, want %s needing %d", r.Strategy(), r.FramesNeeded(), tt.strategy, tt.frames)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// RequestRefundCommand is the input DTO for requesting a refund
+type RequestRefundCommand struct {
+	SessionID   string
+	AmountCents int64
+	Reason      string
+	ActorID     string
+	ActorRoles  []string
+}
+
+// RefundResult is the output DTO for refund commands
+type RefundResult struct {
+	RefundID      string
+	SessionID     string
+	TransactionID string
+	AmountCents   int64
+	RoundingCents int64
+	Currency      string
+	Status        string
+}
+
+// RequestRefundHandler orchestrates the refund request use case. Refunds are
+// checked against the session's transaction, i.e. what was charged. Refunds
+// are approved immediately while the transaction's refunds add up to at most
+// the policy threshold.
+type RequestRefundHandler struct {
+	sessions     domain.SessionRepository
+	transactions domain.TransactionRepository
+	refunds      domain.RefundRepository
+	policy       domain.RefundPolicy
+	rounding     policy.RoundingPolicy
+	uow          ports.UnitOfWork
+	publisher    eventPublisher
+}
+
+func NewRequestRefundHandler(
+	sessions domain.SessionRepository,
+	transactions domain.TransactionRepository,
+	refunds domain.RefundRepository,
+	refundPolicy domain.RefundPolicy,
+	rounding policy.RoundingPolicy,
+	uow ports.UnitOfWork,
+	publisher eventPublisher,
+) *RequestRefundHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if transactions == nil {
+		panic("nil TransactionRepository")
+	}
+	if refunds == nil {
+		panic("nil RefundRepository")
+	}
+	if uow == nil {
+		panic("nil UnitOfWork")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &RequestRefundHandler{
+		sessions:     sessions,
+		transactions: transactions,
+		refunds:      refunds,
+		policy:       refundPolicy,
+		rounding:     rounding,
+		uow:          uow,
+		publisher:    publisher,
+	}
+}
+
+func (h *RequestRefundHandler) Handle(ctx context.Context, cmd RequestRefundCommand) (RefundResult, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return RefundResult{}, domain.ErrSessionNotFound
+	}
+
+	txn, err := h.transactions.FindBySessionID(ctx, sessionID)
+	if errors.Is(err, domain.ErrTransactionNotFound) {
+		// Only completed sessions have a transaction
+		if _, err := h.sessions.FindByID(ctx, sessionID); err != nil {
+			return RefundResult{}, domain.ErrSessionNotFound
+		}
+		return RefundResult{}, domain.ErrRefundSessionNotCompleted
+	}
+	if err != nil {
+		return RefundResult{}, fmt.Errorf("failed to load transaction: %w", err)
+	}
+
+	currency := txn.Total().Currency()
+	amount, err := valueobjects.NewMoney(cmd.AmountCents, currency)
+	if err != nil {
+		return RefundResult{}, domain.ErrInvalidRefundAmount
+	}
+
+	// The refunds so far are added up and the new one saved while no other
+	// request for the transaction can, so together they never refund more
+	// than was charged
+	var refund *domain.Refund
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		if err := h.transactions.HoldRefunds(ctx, txn.ID()); err != nil {
+			return fmt.Errorf("failed to hold refunds: %w", err)
+		}
+		existing, err := h.refunds.FindBySessionID(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to load refunds: %w", err)
+		}
+		var refundedCents int64
+		for _, r := range existing {
+			if r.CountsTowardsTotal() {
+				refundedCents += r.Amount().Amount()
+			}
+		}
+		alreadyRefunded, _ := valueobjects.NewMoney(refundedCents, currency)
+
+		actor := domain.NewActor(cmd.ActorID, cmd.ActorRoles)
+		refund, err = domain.RequestRefund(txn, amount, alreadyRefunded, cmd.Reason, actor, h.policy, h.rounding)
+		if err != nil {
+			return err
+		}
+		if err := h.refunds.Save(ctx, refund); err != nil {
+			return fmt.Errorf("failed to save refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	// Publish domain events
+	for _, evt := range refund.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return toRefundResult(refund), nil
+}
+
+func toRefundResult(r *domain.Refund) RefundResult {
+	return RefundResult{
+		RefundID:      r.ID().String(),
+		SessionID:     r.SessionID().String(),
+		TransactionID: transactionIDString(r.TransactionID()),
+		AmountCents:   r.Amount().Amount(),
+		RoundingCents: r.RoundingCents(),
+		Currency:      r.Amount().Currency(),
+		Status:        string(r.Status()),
+	}
+}
+
+// transactionIDString is empty for refunds not tied to a transaction
+func transactionIDString(id valueobjects.TransactionID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.String()
+}
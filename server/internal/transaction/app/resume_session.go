@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ErrResumeIdentityRequired is returned when a session is looked up for
+// resumption without the device or the user it belongs to
+var ErrResumeIdentityRequired = errors.New("machine_id and user_id are required")
+
+// ResumeSessionQuery looks up the session a user has open on a device
+type ResumeSessionQuery struct {
+	MachineID string
+	UserID    string
+}
+
+// ResumeSessionResult is the session to resume with a fresh stream token,
+// as the one issued at start may have been lost with the connection
+type ResumeSessionResult struct {
+	Session     *SessionView
+	StreamToken string
+}
+
+// ResumeSessionHandler lets a client that lost connectivity find the active
+// session of its user on a device instead of starting a duplicate one. Guest
+// sessions cannot be looked up, as nothing ties them to the caller.
+type ResumeSessionHandler struct {
+	devices      ports.DeviceReader
+	queries      *SessionQueryService
+	streamTokens ports.SessionTokens
+}
+
+func NewResumeSessionHandler(devices ports.DeviceReader, queries *SessionQueryService, streamTokens ports.SessionTokens) *ResumeSessionHandler {
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	if queries == nil {
+		panic("nil SessionQueryService")
+	}
+	if streamTokens == nil {
+		panic("nil SessionTokens")
+	}
+	return &ResumeSessionHandler{
+		devices:      devices,
+		queries:      queries,
+		streamTokens: streamTokens,
+	}
+}
+
+func (h *ResumeSessionHandler) Handle(ctx context.Context, q ResumeSessionQuery) (ResumeSessionResult, error) {
+	if q.MachineID == "" || q.UserID == "" {
+		return ResumeSessionResult{}, ErrResumeIdentityRequired
+	}
+
+	dev, err := h.devices.FindByMachineID(ctx, q.MachineID)
+	if err != nil {
+		return ResumeSessionResult{}, ErrDeviceNotFound
+	}
+
+	view, err := h.queries.FindActiveByDeviceID(ctx, dev.ID)
+	if err != nil {
+		return ResumeSessionResult{}, err
+	}
+	// Another customer's session on the device is not theirs to resume
+	if view.UserID != q.UserID {
+		return ResumeSessionResult{}, domain.ErrSessionNotFound
+	}
+
+	token, _ := h.streamTokens.Issue(view.ID)
+	return ResumeSessionResult{Session: view, StreamToken: token}, nil
+}
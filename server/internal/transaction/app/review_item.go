@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ReviewItemCommand is the input DTO for reviewing a line detected below the
+// confidence threshold
+type ReviewItemCommand struct {
+	SessionID string
+	ActorID   string
+	SKU       string // the line awaiting review
+	Decision  string // accepted or replaced
+	// Replacement is the SKU the attendant found instead, when replaced;
+	// Quantity its units, 0 for as many as the line held
+	Replacement string
+	Quantity    int
+}
+
+// ReviewItemResult is the output DTO
+type ReviewItemResult struct {
+	SessionID string
+	// AwaitingReview is how many lines still wait for an attendant
+	AwaitingReview int
+}
+
+// ReviewItemHandler lets an attendant work through the lines of a session
+// the device detected below the confidence threshold, accepting each as
+// detected or replacing it with what is actually in the basket
+type ReviewItemHandler struct {
+	sessions  domain.SessionRepository
+	catalog   ports.CatalogReader
+	submit    *SubmitDetectionHandler
+	publisher eventPublisher
+}
+
+func NewReviewItemHandler(
+	sessions domain.SessionRepository,
+	catalog ports.CatalogReader,
+	submit *SubmitDetectionHandler,
+	publisher eventPublisher,
+) *ReviewItemHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if catalog == nil {
+		panic("nil CatalogReader")
+	}
+	if submit == nil {
+		panic("nil SubmitDetectionHandler")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &ReviewItemHandler{
+		sessions:  sessions,
+		catalog:   catalog,
+		submit:    submit,
+		publisher: publisher,
+	}
+}
+
+func (h *ReviewItemHandler) Handle(ctx context.Context, cmd ReviewItemCommand) (ReviewItemResult, error) {
+	if cmd.ActorID == "" {
+		return ReviewItemResult{}, domain.ErrActorRequired
+	}
+	decision, err := domain.ParseItemDecision(cmd.Decision)
+	if err != nil {
+		return ReviewItemResult{}, err
+	}
+	if decision == domain.ItemDecisionReplaced && cmd.Replacement == "" {
+		return ReviewItemResult{}, domain.ErrItemReplacementMissing
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return ReviewItemResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return ReviewItemResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return ReviewItemResult{}, domain.ErrSessionNotActive
+	}
+
+	if decision == domain.ItemDecisionReplaced {
+		// An unknown SKU would be left out of the basket along with the line
+		if _, err := h.catalog.FindSKUByCode(ctx, cmd.Replacement); err != nil {
+			return ReviewItemResult{}, fmt.Errorf("%w: %s", domain.ErrUnknownReplacementSKU, cmd.Replacement)
+		}
+		items, ok := replaceLines(sess, cmd.SKU, cmd.Replacement, cmd.Quantity)
+		if !ok {
+			return ReviewItemResult{}, domain.ErrNoItemToReview
+		}
+		// The basket with the replacement is priced like a frame from the
+		// device, at the weight the device measured
+		_, err := h.submit.Handle(ctx, SubmitDetectionCommand{
+			DeviceID:    sess.DeviceID().String(),
+			SessionID:   sess.ID().String(),
+			Items:       items,
+			TotalWeight: sess.TotalWeight().Grams(),
+			Source:      domain.DetectionSourceAttendant,
+		})
+		if err != nil {
+			return ReviewItemResult{}, err
+		}
+		if sess, err = h.sessions.FindByID(ctx, sessionID); err != nil {
+			return ReviewItemResult{}, err
+		}
+	}
+
+	if err := sess.ReviewItem(cmd.SKU, decision, cmd.Replacement, cmd.ActorID); err != nil {
+		return ReviewItemResult{}, err
+	}
+
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return ReviewItemResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// Publish domain events
+	for _, evt := range sess.PullEvents() {
+		_ = h.publisher.Publish(ctx, evt)
+	}
+
+	return ReviewItemResult{
+		SessionID:      sess.ID().String(),
+		AwaitingReview: len(sess.AwaitingReview()),
+	}, nil
+}
+
+// replaceLines returns the units of the basket with the lines of the SKU
+// awaiting review replaced by units of the replacement; false when no line
+// of the SKU awaits review
+func replaceLines(sess *domain.Session, code, replacement string, quantity int) ([]DetectedItemInput, bool) {
+	var units []DetectedItemInput
+	replaced := 0
+	for _, item := range sess.DetectedItems() {
+		if item.Code() == code && item.Review() == domain.ItemReviewPending {
+			replaced += item.Quantity()
+			continue
+		}
+		units = append(units, lineUnits(item)...)
+	}
+	if replaced == 0 {
+		return nil, false
+	}
+
+	if quantity == 0 {
+		quantity = replaced
+	}
+	for i := 0; i < quantity; i++ {
+		units = append(units, DetectedItemInput{SKU: replacement, Confidence: 1})
+	}
+	return units, true
+}
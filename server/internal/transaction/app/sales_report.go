@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// DefaultSalesReportPeriod is how far back a sales report without a start goes
+const DefaultSalesReportPeriod = 30 * 24 * time.Hour
+
+var (
+	ErrUnknownSalesGrouping = errors.New("group_by must be day, device or sku")
+	ErrInvalidSalesPeriod   = errors.New("from must be before to")
+)
+
+// SalesReportQuery selects the completed sales to aggregate. A zero To is
+// now and a zero From is DefaultSalesReportPeriod before To.
+type SalesReportQuery struct {
+	GroupBy string // day, device or sku; day when empty
+	From    time.Time
+	To      time.Time
+}
+
+// SalesReport aggregates the sales completed in [From, To)
+type SalesReport struct {
+	GroupBy string
+	From    time.Time
+	To      time.Time
+	Lines   []SalesReportLine
+}
+
+// SalesReportLine holds the figures of one group in one currency; amounts
+// in different currencies are never added up
+type SalesReportLine struct {
+	Key                string // the day, device ID or SKU code
+	Currency           string
+	Transactions       int64
+	RevenueCents       int64
+	Units              int64
+	AverageBasketCents int64   // revenue per transaction, rounded
+	AverageBasketUnits float64 // units per transaction, to two decimals
+}
+
+// SalesReport aggregates revenue, transaction counts and basket sizes of
+// the completed sales in the query's period
+func (s *TransactionQueryService) SalesReport(ctx context.Context, q SalesReportQuery) (*SalesReport, error) {
+	groupBy := domain.SalesGrouping(q.GroupBy)
+	switch groupBy {
+	case "":
+		groupBy = domain.SalesByDay
+	case domain.SalesByDay, domain.SalesByDevice, domain.SalesBySKU:
+	default:
+		return nil, ErrUnknownSalesGrouping
+	}
+
+	to := q.To
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	from := q.From
+	if from.IsZero() {
+		from = to.Add(-DefaultSalesReportPeriod)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidSalesPeriod
+	}
+
+	figures, err := s.transactions.SalesFigures(ctx, groupBy, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SalesReport{
+		GroupBy: string(groupBy),
+		From:    from,
+		To:      to,
+		Lines:   make([]SalesReportLine, 0, len(figures)),
+	}
+	for _, f := range figures {
+		line := SalesReportLine{
+			Key:          f.Key,
+			Currency:     f.Currency,
+			Transactions: f.Transactions,
+			RevenueCents: f.RevenueCents,
+			Units:        f.Units,
+		}
+		if f.Transactions > 0 {
+			n := float64(f.Transactions)
+			line.AverageBasketCents = int64(math.Round(float64(f.RevenueCents) / n))
+			line.AverageBasketUnits = math.Round(float64(f.Units)/n*100) / 100
+		}
+		report.Lines = append(report.Lines, line)
+	}
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ErrInvalidSessionCursor is returned for a cursor not handed out by a previous page
+var ErrInvalidSessionCursor = errors.New("invalid cursor")
+
+// SessionHistoryQuery selects a page of the session history; zero fields don't filter
+type SessionHistoryQuery struct {
+	DeviceID string
+	Status   domain.SessionStatus
+	From     time.Time // started at or after
+	To       time.Time // started before
+	Limit    int
+	Cursor   string // NextCursor of the previous page
+}
+
+// SessionHistoryPage is one page of sessions, newest first
+type SessionHistoryPage struct {
+	Sessions   []*SessionView
+	NextCursor string // empty on the last page
+}
+
+// History lists sessions for the operator dashboard and support tooling
+func (s *SessionQueryService) History(ctx context.Context, q SessionHistoryQuery) (*SessionHistoryPage, error) {
+	var filter domain.SessionFilter
+	if q.DeviceID != "" {
+		devID, err := valueobjects.DeviceIDFrom(q.DeviceID)
+		if err != nil {
+			return nil, domain.ErrInvalidDeviceID
+		}
+		filter.DeviceID = devID
+	}
+	filter.Status = q.Status
+	filter.From = q.From
+	filter.To = q.To
+
+	return s.page(ctx, filter, q.Cursor, q.Limit)
+}
+
+// PurchaseHistory lists the user's completed sessions, newest first, for the
+// purchase history in the mobile app
+func (s *SessionQueryService) PurchaseHistory(ctx context.Context, userID string, limit int, cursor string) (*SessionHistoryPage, error) {
+	if userID == "" {
+		return &SessionHistoryPage{Sessions: []*SessionView{}}, nil
+	}
+	filter := domain.SessionFilter{UserID: userID, Status: domain.SessionStatusCompleted}
+	return s.page(ctx, filter, cursor, limit)
+}
+
+func (s *SessionQueryService) page(ctx context.Context, filter domain.SessionFilter, cursor string, limit int) (*SessionHistoryPage, error) {
+	var after *domain.SessionCursor
+	if cursor != "" {
+		c, err := decodeSessionCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &c
+	}
+
+	// One more than asked tells whether another page follows
+	sessions, err := s.sessions.FindPage(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SessionHistoryPage{Sessions: make([]*SessionView, 0, len(sessions))}
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+		last := sessions[len(sessions)-1]
+		page.NextCursor = encodeSessionCursor(domain.SessionCursor{CreatedAt: last.CreatedAt(), ID: last.ID()})
+	}
+	for _, sess := range sessions {
+		view, err := s.toView(sess)
+		if err != nil {
+			return nil, err
+		}
+		page.Sessions = append(page.Sessions, view)
+	}
+	return page, nil
+}
+
+// Cursors are opaque to clients: the start time and ID of the last session
+// of the page
+func encodeSessionCursor(c domain.SessionCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()))
+}
+
+func decodeSessionCursor(raw string) (domain.SessionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return domain.SessionCursor{}, ErrInvalidSessionCursor
+	}
+	createdAt, id, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return domain.SessionCursor{}, ErrInvalidSessionCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return domain.SessionCursor{}, ErrInvalidSessionCursor
+	}
+	sessionID, err := valueobjects.SessionIDFrom(id)
+	if err != nil {
+		return domain.SessionCursor{}, ErrInvalidSessionCursor
+	}
+	return domain.SessionCursor{CreatedAt: t, ID: sessionID}, nil
+}
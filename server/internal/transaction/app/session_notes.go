@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// AddSessionNoteCommand is the input DTO for annotating a session
+type AddSessionNoteCommand struct {
+	SessionID string
+	Note      string
+	ActorID   string
+}
+
+// SessionNoteView is a read-only view of a note on a session
+type SessionNoteView struct {
+	ID        int64
+	AuthorID  string
+	Note      string
+	CreatedAt string
+}
+
+// AddSessionNoteHandler lets support staff annotate a session of any status
+// while investigating a complaint
+type AddSessionNoteHandler struct {
+	sessions domain.SessionRepository
+	notes    domain.SessionNoteRepository
+}
+
+func NewAddSessionNoteHandler(sessions domain.SessionRepository, notes domain.SessionNoteRepository) *AddSessionNoteHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if notes == nil {
+		panic("nil SessionNoteRepository")
+	}
+	return &AddSessionNoteHandler{sessions: sessions, notes: notes}
+}
+
+func (h *AddSessionNoteHandler) Handle(ctx context.Context, cmd AddSessionNoteCommand) (*SessionNoteView, error) {
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	note, err := domain.NewSessionNote(sessionID, domain.NewActor(cmd.ActorID, nil), cmd.Note)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.sessions.FindByID(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	note, err = h.notes.Append(ctx, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save session note: %w", err)
+	}
+
+	view := toSessionNoteView(note)
+	return &view, nil
+}
+
+func toSessionNoteViews(notes []domain.SessionNote) []SessionNoteView {
+	views := make([]SessionNoteView, 0, len(notes))
+	for _, n := range notes {
+		views = append(views, toSessionNoteView(n))
+	}
+	return views
+}
+
+func toSessionNoteView(n domain.SessionNote) SessionNoteView {
+	return SessionNoteView{
+		ID:        n.ID,
+		AuthorID:  n.AuthorID,
+		Note:      n.Note,
+		CreatedAt: n.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -12,10 +12,13 @@ import (
 	"github.com/vending-machine/server/internal/transaction/domain"
 )
 
-const defaultSessionExpirationMinutes = 30
+const defaultCurrency = "USD"
 
-// StartSessionCommand is the input DTO for starting a session
+// StartSessionCommand is the input DTO for starting a session.
+// Token is the signed value scanned from the device QR code; MachineID is
+// only honoured when unsigned starts are allowed.
 type StartSessionCommand struct {
+	Token     string
 	MachineID string
 	UserID    string
 }
@@ -25,14 +28,30 @@ type StartSessionResult struct {
 	SessionID string
 	DeviceID  string
 	ExpiresAt time.Time
+
+	// StreamToken lets the customer app subscribe to live basket updates
+	StreamToken string
+
+	// Set for guest sessions when a payment gateway is configured
+	PaymentIntentID     string
+	PaymentClientSecret string
 }
 
 // Errors for start session use case
 var (
-	ErrDeviceNotFound = errors.New("device not found")
-	ErrDeviceInactive = errors.New("device is inactive")
+	ErrDeviceNotFound     = errors.New("device not found")
+	ErrDeviceInactive     = errors.New("device is inactive")
+	ErrDeviceBlocked      = errors.New("device is blocked pending operator clearance")
+	ErrDeviceMaintenance  = errors.New("device is under maintenance")
+	ErrDeviceRestocking   = errors.New("device is being restocked")
+	ErrDeviceClosed       = errors.New("device is closed")
+	ErrStartTokenRequired = errors.New("session start token required")
+	ErrInvalidStartToken  = errors.New("invalid or expired session start token")
 )
 
+// ErrPaymentProviderUnavailable is returned when the payment gateway call fails
+var ErrPaymentProviderUnavailable = errors.New("payment provider unavailable")
+
 // eventPublisher is a local interface for publishing domain events
 type eventPublisher interface {
 	Publish(ctx context.Context, event events.DomainEvent) error
@@ -40,15 +59,30 @@ type eventPublisher interface {
 
 // StartSessionHandler orchestrates the session start use case
 type StartSessionHandler struct {
-	devices   ports.DeviceReader
-	sessions  domain.SessionRepository
-	publisher eventPublisher
+	devices      ports.DeviceReader
+	sessions     domain.SessionRepository
+	tokens       ports.StartTokenVerifier
+	streamTokens ports.SessionTokens
+	payments     ports.PaymentGateway
+	experiments  ports.PriceExperiments
+	publisher    eventPublisher
+	lifetime     domain.SessionLifetime
+	requireToken bool
 }
 
+// NewStartSessionHandler creates the handler. Sessions last as long as the
+// lifetime allows for their device. When requireToken is set, a raw machine
+// ID is rejected and sessions can only be started with a signed QR token.
 func NewStartSessionHandler(
 	devices ports.DeviceReader,
 	sessions domain.SessionRepository,
+	tokens ports.StartTokenVerifier,
+	streamTokens ports.SessionTokens,
+	payments ports.PaymentGateway,
+	experiments ports.PriceExperiments,
 	publisher eventPublisher,
+	lifetime domain.SessionLifetime,
+	requireToken bool,
 ) *StartSessionHandler {
 	if devices == nil {
 		panic("nil DeviceReader")
@@ -56,38 +90,134 @@ func NewStartSessionHandler(
 	if sessions == nil {
 		panic("nil SessionRepository")
 	}
+	if tokens == nil {
+		panic("nil StartTokenVerifier")
+	}
+	if streamTokens == nil {
+		panic("nil SessionTokens")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
+	if experiments == nil {
+		panic("nil PriceExperiments")
+	}
 	if publisher == nil {
 		panic("nil EventPublisher")
 	}
 	return &StartSessionHandler{
-		devices:   devices,
-		sessions:  sessions,
-		publisher: publisher,
+		devices:      devices,
+		sessions:     sessions,
+		tokens:       tokens,
+		streamTokens: streamTokens,
+		payments:     payments,
+		experiments:  experiments,
+		publisher:    publisher,
+		lifetime:     lifetime,
+		requireToken: requireToken,
 	}
 }
 
 func (h *StartSessionHandler) Handle(ctx context.Context, cmd StartSessionCommand) (StartSessionResult, error) {
+	machineID, err := h.resolveMachineID(cmd)
+	if err != nil {
+		return StartSessionResult{}, err
+	}
+
 	// Find device by machine ID using the cross-context port
-	dev, err := h.devices.FindByMachineID(ctx, cmd.MachineID)
+	dev, err := h.devices.FindByMachineID(ctx, machineID)
 	if err != nil {
 		return StartSessionResult{}, ErrDeviceNotFound
 	}
 
+	if dev.IsBlocked {
+		return StartSessionResult{}, ErrDeviceBlocked
+	}
+	if dev.InMaintenance {
+		return StartSessionResult{}, ErrDeviceMaintenance
+	}
+	if dev.Restocking {
+		return StartSessionResult{}, ErrDeviceRestocking
+	}
 	if !dev.IsActive {
 		return StartSessionResult{}, ErrDeviceInactive
 	}
 
+	// Devices only sell within their opening hours
+	sales, err := h.devices.SalesStatus(ctx, dev.ID, time.Now())
+	if err != nil {
+		return StartSessionResult{}, fmt.Errorf("failed to check sales hours: %w", err)
+	}
+	if !sales.Open {
+		return StartSessionResult{}, fmt.Errorf("%w: %s", ErrDeviceClosed, sales.ClosedReason)
+	}
+
 	// Parse device ID
 	deviceID, err := valueobjects.DeviceIDFrom(dev.ID)
 	if err != nil {
 		return StartSessionResult{}, fmt.Errorf("invalid device ID: %w", err)
 	}
 
+	// The first session after a security incident on the device is verified in the cloud
+	cloudVerify := false
+	if dev.VerificationRequiredSince != nil {
+		latest, err := h.sessions.FindLatestByDeviceID(ctx, deviceID)
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			cloudVerify = true
+		case err != nil:
+			return StartSessionResult{}, err
+		default:
+			cloudVerify = latest.CreatedAt().Before(*dev.VerificationRequiredSince)
+		}
+	}
+
 	// Create new session
-	sess, err := domain.NewSession(deviceID, cmd.UserID, defaultSessionExpirationMinutes)
+	sess, err := domain.NewSession(deviceID, cmd.UserID, h.lifetime.For(dev.SessionExpirationMinutes))
 	if err != nil {
 		return StartSessionResult{}, fmt.Errorf("failed to create session: %w", err)
 	}
+	if cloudVerify {
+		sess.RequireCloudVerification()
+	}
+
+	// Price experiments: the device's variant prices apply for the whole session
+	assignments, err := h.experiments.AssignmentsFor(ctx, dev.ID, sess.CreatedAt())
+	if err != nil {
+		return StartSessionResult{}, fmt.Errorf("failed to resolve price experiments: %w", err)
+	}
+	if len(assignments) > 0 {
+		tags := make([]domain.ExperimentTag, 0, len(assignments))
+		for _, a := range assignments {
+			tags = append(tags, domain.NewExperimentTag(a.ExperimentID, a.Variant, a.Prices))
+		}
+		if err := sess.TagExperiments(tags); err != nil {
+			return StartSessionResult{}, err
+		}
+	}
+
+	// Expiry markdowns apply for the whole session, like experiment prices
+	if len(sales.Markdowns) > 0 {
+		if err := sess.ApplyMarkdowns(sales.Markdowns); err != nil {
+			return StartSessionResult{}, err
+		}
+	}
+
+	// Guest checkout: anonymous customers pay in-app through a payment intent
+	var intent *ports.PaymentIntent
+	if sess.IsGuest() {
+		intent, err = h.payments.CreateIntent(ctx, sess.ID().String(), 0, defaultCurrency)
+		switch {
+		case errors.Is(err, ports.ErrPaymentGatewayDisabled):
+			intent = nil
+		case err != nil:
+			return StartSessionResult{}, fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+		default:
+			if err := sess.AttachPaymentIntent(intent.ID); err != nil {
+				return StartSessionResult{}, err
+			}
+		}
+	}
 
 	// Persist
 	if err := h.sessions.Save(ctx, sess); err != nil {
@@ -99,9 +229,33 @@ func (h *StartSessionHandler) Handle(ctx context.Context, cmd StartSessionComman
 		_ = h.publisher.Publish(ctx, evt)
 	}
 
-	return StartSessionResult{
+	result := StartSessionResult{
 		SessionID: sess.ID().String(),
 		DeviceID:  dev.ID,
 		ExpiresAt: sess.ExpiresAt(),
-	}, nil
+	}
+	result.StreamToken, _ = h.streamTokens.Issue(result.SessionID)
+	if intent != nil {
+		result.PaymentIntentID = intent.ID
+		result.PaymentClientSecret = intent.ClientSecret
+	}
+
+	return result, nil
+}
+
+// resolveMachineID returns the machine the session is started on, taken from
+// the verified QR token or, if allowed, the raw machine ID
+func (h *StartSessionHandler) resolveMachineID(cmd StartSessionCommand) (string, error) {
+	if cmd.Token != "" {
+		machineID, err := h.tokens.Verify(cmd.Token)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidStartToken, err)
+		}
+		return machineID, nil
+	}
+
+	if h.requireToken || cmd.MachineID == "" {
+		return "", ErrStartTokenRequired
+	}
+	return cmd.MachineID, nil
 }
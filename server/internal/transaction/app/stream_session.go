@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ErrInvalidSessionToken is returned when a stream token is missing, forged,
+// expired, or issued for another session
+var ErrInvalidSessionToken = errors.New("invalid or expired session token")
+
+// SessionStreamService pushes a session's basket to the customer app as it
+// changes, so the app does not have to poll
+type SessionStreamService struct {
+	queries *SessionQueryService
+	tokens  ports.SessionTokens
+	feed    ports.SessionChangeFeed
+	refresh time.Duration // fallback re-read for changes made on other server instances
+}
+
+func NewSessionStreamService(queries *SessionQueryService, tokens ports.SessionTokens, feed ports.SessionChangeFeed, refresh time.Duration) *SessionStreamService {
+	if queries == nil {
+		panic("nil SessionQueryService")
+	}
+	if tokens == nil {
+		panic("nil SessionTokens")
+	}
+	if feed == nil {
+		panic("nil SessionChangeFeed")
+	}
+	return &SessionStreamService{queries: queries, tokens: tokens, feed: feed, refresh: refresh}
+}
+
+// SessionUpdate is one state pushed to the customer; Final marks the last one
+type SessionUpdate struct {
+	Session *SessionView
+	Final   bool
+}
+
+// Watch verifies the stream token and returns the session's current state
+// followed by every change. The channel is closed after the final update
+// (the session is no longer active) or when ctx is done.
+func (s *SessionStreamService) Watch(ctx context.Context, sessionID, token string) (<-chan SessionUpdate, error) {
+	tokenSessionID, err := s.tokens.Verify(token)
+	if err != nil || tokenSessionID != sessionID {
+		return nil, ErrInvalidSessionToken
+	}
+
+	// Subscribe before the first read so no change slips in between
+	changes, cancel := s.feed.Subscribe()
+
+	view, err := s.queries.FindByID(ctx, sessionID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	updates := make(chan SessionUpdate)
+	go func() {
+		defer close(updates)
+		defer cancel()
+
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+
+		var last *SessionView
+		for {
+			if view != nil && !reflect.DeepEqual(view, last) {
+				update := SessionUpdate{Session: view, Final: isFinal(view)}
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+				if update.Final {
+					return
+				}
+				last = view
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case id, ok := <-changes:
+				if !ok {
+					changes = nil // feed gone; keep refreshing on the ticker
+					continue
+				}
+				if id != sessionID {
+					view = nil
+					continue
+				}
+			case <-ticker.C:
+			}
+
+			view, err = s.queries.FindByID(ctx, sessionID)
+			switch {
+			case errors.Is(err, domain.ErrSessionNotFound):
+				return
+			case err != nil:
+				view = nil // transient read error; retry on the next tick
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// Session event types, named after what changed in the session
+const (
+	SessionEventSnapshot     = "session"       // the session as the stream opens
+	SessionEventItemDetected = "item_detected" // the basket changed
+	SessionEventTotalUpdated = "total_updated" // the total changed, with the basket, tax or a coupon
+	SessionEventConfirmed    = "confirmed"
+	SessionEventCancelled    = "cancelled"
+	SessionEventExpired      = "expired"
+)
+
+// SessionEvent is one change of a session; Session is its state after it
+type SessionEvent struct {
+	Type    string
+	Session *SessionView
+}
+
+// Final reports whether the event is the last one of the stream
+func (e SessionEvent) Final() bool {
+	switch e.Type {
+	case SessionEventConfirmed, SessionEventCancelled, SessionEventExpired:
+		return true
+	}
+	return false
+}
+
+// Events is Watch told as what changed: a snapshot of the session, then an
+// event for each change, ending with the session confirmed, cancelled or
+// expired. The channel is closed after the final event or when ctx is done.
+func (s *SessionStreamService) Events(ctx context.Context, sessionID, token string) (<-chan SessionEvent, error) {
+	updates, err := s.Watch(ctx, sessionID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan SessionEvent)
+	go func() {
+		defer close(events)
+
+		var last *SessionView
+		for update := range updates {
+			for _, evt := range sessionEvents(last, update) {
+				select {
+				case events <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = update.Session
+		}
+	}()
+
+	return events, nil
+}
+
+// sessionEvents tells what changed between two states of a session; last is
+// nil for the first state seen
+func sessionEvents(last *SessionView, update SessionUpdate) []SessionEvent {
+	view := update.Session
+
+	var evts []SessionEvent
+	if last == nil {
+		evts = append(evts, SessionEvent{Type: SessionEventSnapshot, Session: view})
+	} else {
+		if !reflect.DeepEqual(last.Items, view.Items) {
+			evts = append(evts, SessionEvent{Type: SessionEventItemDetected, Session: view})
+		}
+		if last.TotalCents != view.TotalCents || last.Currency != view.Currency {
+			evts = append(evts, SessionEvent{Type: SessionEventTotalUpdated, Session: view})
+		}
+	}
+
+	if update.Final {
+		switch view.Status {
+		case string(domain.SessionStatusCompleted):
+			evts = append(evts, SessionEvent{Type: SessionEventConfirmed, Session: view})
+		case string(domain.SessionStatusCancelled):
+			evts = append(evts, SessionEvent{Type: SessionEventCancelled, Session: view})
+		default:
+			evts = append(evts, SessionEvent{Type: SessionEventExpired, Session: view})
+		}
+	}
+	return evts
+}
+
+// isFinal reports whether the session can no longer change for the customer
+func isFinal(view *SessionView) bool {
+	switch view.Status {
+	case string(domain.SessionStatusAwaitingPayment):
+		return false
+	case string(domain.SessionStatusActive):
+	default:
+		return true
+	}
+	expiresAt, err := time.Parse(time.RFC3339, view.ExpiresAt)
+	return err == nil && time.Now().After(expiresAt)
+}
@@ -2,14 +2,23 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/vending-machine/server/internal/shared/currency"
 	"github.com/vending-machine/server/internal/shared/policy"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 	"github.com/vending-machine/server/internal/transaction/app/ports"
 	"github.com/vending-machine/server/internal/transaction/domain"
 )
 
+// ErrCurrencyConversionUnavailable is returned when a basket mixes currencies
+// and an item price cannot be converted into the basket's currency
+var ErrCurrencyConversionUnavailable = errors.New("currency conversion unavailable")
+
 // DetectedItemInput represents a detected item from the device
 type DetectedItemInput struct {
 	SKU        string
@@ -23,82 +32,238 @@ type SubmitDetectionCommand struct {
 	SessionID   string
 	Items       []DetectedItemInput
 	TotalWeight float64
+	Image       []byte // optional camera frame, kept for nightly reconciliation
+	// ModelVersion is the detection model the device ran; devices that do
+	// not report it are not checked
+	ModelVersion string
+	// IdempotencyKey identifies the frame across retries; a detection resent
+	// with the same key gets the original result back
+	IdempotencyKey string
+	// Sequence orders the device's frames within the session; a frame no
+	// newer than one already applied is refused. Zero leaves it unordered.
+	Sequence int64
+	// Incremental frames change the basket instead of replacing it: Items
+	// were placed since the previous frame and Removed taken out
+	Incremental bool
+	Removed     []DetectedItemInput
+	// Source is recorded in the detection audit log; empty means the device
+	Source string
 }
 
-// DetectedItemOutput represents an enriched detected item
+// DetectedItemOutput represents a line of identical enriched detected items
 type DetectedItemOutput struct {
+	SKU             string
+	Name            string
+	PriceCents      int64 // of one unit, in the basket's currency
+	Quantity        int
+	LineTotalCents  int64
+	Currency        string      // the basket's currency
+	Confidence      float64     // lowest of the line's units
+	MarkdownPercent int         // expiry markdown already taken off PriceCents
+	Bundle          string      // bundle the item was sold in; PriceCents is its share of the bundle price
+	Suspicious      bool        // the SKU is not assigned to the device, so it should not be in it
+	Review          string      // pending while detected below the confidence threshold, accepted once an attendant vouched for it
+	BBoxes          [][]float64 // [x, y, width, height] of the units the device located
+}
+
+// UnrecognizedItemOutput is a detected unit of a code the catalog does not sell
+type UnrecognizedItemOutput struct {
 	SKU        string
-	Name       string
-	PriceCents int64
-	Currency   string
 	Confidence float64
+	BBox       []float64 // [x, y, width, height], when the device located the unit
 }
 
 // SubmitDetectionResult is the output DTO
 type SubmitDetectionResult struct {
-	SessionID    string
-	Items        []DetectedItemOutput
-	TotalCents   int64
-	Currency     string
-	WeightMatch  bool
-	NeedsCloudML bool
+	SessionID     string
+	Items         []DetectedItemOutput
+	SubtotalCents int64 // item prices without tax
+	TaxCents      int64
+	RoundingCents int64
+	TotalCents    int64
+	Currency      string
+	WeightMatch   bool
+	NeedsCloudML  bool
+	// Unrecognized are the detected codes the catalog does not sell, left
+	// out of the basket and recorded on the session
+	Unrecognized []UnrecognizedItemOutput
+
+	// ExpectedModelVersion is the model assigned to the device, if any.
+	// ModelVersionMismatch is set when the device reported running another,
+	// in which case its detections are verified in the cloud.
+	ExpectedModelVersion string
+	ModelVersionMismatch bool
+
+	// Replayed is set when the result is that of an earlier submission with
+	// the same idempotency key
+	Replayed bool
 }
 
-// SubmitDetectionHandler orchestrates the detection submission use case
+// SubmitDetectionHandler orchestrates the detection submission use case. A
+// basket is priced in the currency of its first item; items and bundles
+// priced in another currency are converted into it.
 type SubmitDetectionHandler struct {
 	sessions  domain.SessionRepository
+	images    domain.SessionImageRepository
+	processed domain.ProcessedDetectionRepository
+	audit     domain.DetectionLogRepository
+	fraud     *FraudScreen
 	catalog   ports.CatalogReader
+	devices   ports.DeviceReader
+	payments  ports.PaymentGateway
 	publisher eventPublisher
 	policy    policy.DetectionPolicy
+	rounding  policy.RoundingPolicy
+	taxes     *TaxAssessor
+	converter currency.Converter
 }
 
 func NewSubmitDetectionHandler(
 	sessions domain.SessionRepository,
+	images domain.SessionImageRepository,
+	processed domain.ProcessedDetectionRepository,
+	audit domain.DetectionLogRepository,
+	fraud *FraudScreen,
 	catalog ports.CatalogReader,
+	devices ports.DeviceReader,
+	payments ports.PaymentGateway,
 	publisher eventPublisher,
+	rounding policy.RoundingPolicy,
+	taxes *TaxAssessor,
+	converter currency.Converter,
 ) *SubmitDetectionHandler {
 	if sessions == nil {
 		panic("nil SessionRepository")
 	}
+	if images == nil {
+		panic("nil SessionImageRepository")
+	}
+	if processed == nil {
+		panic("nil ProcessedDetectionRepository")
+	}
+	if audit == nil {
+		panic("nil DetectionLogRepository")
+	}
+	if fraud == nil {
+		panic("nil FraudScreen")
+	}
 	if catalog == nil {
 		panic("nil CatalogReader")
 	}
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
 	if publisher == nil {
 		panic("nil EventPublisher")
 	}
+	if taxes == nil {
+		panic("nil TaxAssessor")
+	}
+	if converter == nil {
+		panic("nil currency Converter")
+	}
 	return &SubmitDetectionHandler{
 		sessions:  sessions,
+		images:    images,
+		processed: processed,
+		audit:     audit,
+		fraud:     fraud,
 		catalog:   catalog,
+		devices:   devices,
+		payments:  payments,
 		publisher: publisher,
 		policy:    policy.DefaultDetectionPolicy(),
+		rounding:  rounding,
+		taxes:     taxes,
+		converter: converter,
 	}
 }
 
 // NewSubmitDetectionHandlerWithPolicy creates a handler with a custom detection policy
 func NewSubmitDetectionHandlerWithPolicy(
 	sessions domain.SessionRepository,
+	images domain.SessionImageRepository,
+	processed domain.ProcessedDetectionRepository,
+	audit domain.DetectionLogRepository,
+	fraud *FraudScreen,
 	catalog ports.CatalogReader,
+	devices ports.DeviceReader,
+	payments ports.PaymentGateway,
 	publisher eventPublisher,
 	detectionPolicy policy.DetectionPolicy,
+	rounding policy.RoundingPolicy,
+	taxes *TaxAssessor,
+	converter currency.Converter,
 ) *SubmitDetectionHandler {
 	if sessions == nil {
 		panic("nil SessionRepository")
 	}
+	if images == nil {
+		panic("nil SessionImageRepository")
+	}
+	if processed == nil {
+		panic("nil ProcessedDetectionRepository")
+	}
+	if audit == nil {
+		panic("nil DetectionLogRepository")
+	}
+	if fraud == nil {
+		panic("nil FraudScreen")
+	}
 	if catalog == nil {
 		panic("nil CatalogReader")
 	}
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	if payments == nil {
+		panic("nil PaymentGateway")
+	}
 	if publisher == nil {
 		panic("nil EventPublisher")
 	}
+	if taxes == nil {
+		panic("nil TaxAssessor")
+	}
+	if converter == nil {
+		panic("nil currency Converter")
+	}
 	return &SubmitDetectionHandler{
 		sessions:  sessions,
+		images:    images,
+		processed: processed,
+		audit:     audit,
+		fraud:     fraud,
 		catalog:   catalog,
+		devices:   devices,
+		payments:  payments,
 		publisher: publisher,
 		policy:    detectionPolicy,
+		rounding:  rounding,
+		taxes:     taxes,
+		converter: converter,
 	}
 }
 
+// Handle applies the detection and records it in the audit log, whether it
+// was applied or not
 func (h *SubmitDetectionHandler) Handle(ctx context.Context, cmd SubmitDetectionCommand) (SubmitDetectionResult, error) {
+	receivedAt := time.Now().UTC()
+	result, err := h.handle(ctx, cmd)
+	// The log serves disputes and model debugging, so failing to write it
+	// does not fail the detection
+	_ = h.record(ctx, cmd, receivedAt, result, err)
+	return result, err
+}
+
+func (h *SubmitDetectionHandler) handle(ctx context.Context, cmd SubmitDetectionCommand) (SubmitDetectionResult, error) {
+	if cmd.Sequence < 0 || (cmd.Incremental && cmd.Sequence == 0) {
+		return SubmitDetectionResult{}, domain.ErrInvalidFrameSequence
+	}
+
 	// Parse session ID
 	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
 	if err != nil {
@@ -111,27 +276,92 @@ func (h *SubmitDetectionHandler) Handle(ctx context.Context, cmd SubmitDetection
 		return SubmitDetectionResult{}, domain.ErrSessionNotFound
 	}
 
+	// A retried frame is answered from the first result, even once the
+	// session has moved on
+	if cmd.IdempotencyKey != "" {
+		if len(cmd.IdempotencyKey) > domain.MaxIdempotencyKeyLength {
+			return SubmitDetectionResult{}, domain.ErrInvalidIdempotencyKey
+		}
+		result, err := h.replay(ctx, sess, cmd.IdempotencyKey)
+		if !errors.Is(err, domain.ErrProcessedDetectionNotFound) {
+			return result, err
+		}
+	}
+
 	if !sess.IsActive() {
 		return SubmitDetectionResult{}, domain.ErrSessionNotActive
 	}
+	if err := sess.AdvanceFrame(cmd.Sequence); err != nil {
+		return SubmitDetectionResult{}, err
+	}
+	inputs := cmd.Items
+	if cmd.Incremental {
+		inputs, err = basketAfter(sess, cmd.Items, cmd.Removed)
+		if err != nil {
+			return SubmitDetectionResult{}, err
+		}
+	}
 
 	// Enrich detected items with SKU details from catalog context
 	var detectedItems []domain.DetectedItem
-	var outputItems []DetectedItemOutput
-	var expectedWeightGrams float64
-	var needsCloudML bool
-	var totalCents int64
-	currency := "USD" // default
+	skuOutputs := make(map[string]DetectedItemOutput) // SKU code -> what its lines share
+	var expectedWeights []policy.ItemWeight
+	// Sessions flagged after a security incident are always verified in the cloud
+	needsCloudML := sess.CloudVerificationRequired()
+	basketCurrency := ""
+	var unrecognized []UnrecognizedItemOutput
+	var sightings []domain.UnrecognizedItem
+
+	// A device stocking only part of the catalog should never see other SKUs
+	assigned, err := h.devices.AssignedSKUs(ctx, sess.DeviceID().String())
+	if err != nil {
+		return SubmitDetectionResult{}, fmt.Errorf("failed to load assigned SKUs: %w", err)
+	}
+	device, err := h.devices.FindByID(ctx, sess.DeviceID().String())
+	if err != nil {
+		return SubmitDetectionResult{}, fmt.Errorf("failed to load device: %w", err)
+	}
+	detectionPolicy, err := h.policyFor(device)
+	if err != nil {
+		return SubmitDetectionResult{}, err
+	}
+	// A device on another model than the one assigned to it classifies with
+	// a class mapping its detections cannot be trusted against
+	modelMismatch := device.ModelVersion != "" && cmd.ModelVersion != "" && cmd.ModelVersion != device.ModelVersion
+	if modelMismatch {
+		needsCloudML = true
+	}
 
-	for _, item := range cmd.Items {
+	for _, item := range inputs {
 		skuInfo, err := h.catalog.FindSKUByCode(ctx, item.SKU)
 		if err != nil {
+			sighting := domain.NewUnrecognizedItem(item.SKU, item.Confidence)
+			if box, ok := domain.NewBoundingBox(item.BBox); ok {
+				sighting = sighting.At(box)
+			}
+			sightings = append(sightings, sighting)
+			unrecognized = append(unrecognized, toUnrecognizedItemOutput(sighting))
 			needsCloudML = true
 			continue
 		}
 
+		// Sessions in a price experiment pay their variant's price
+		priceCents := skuInfo.PriceCents
+		if cents, ok := sess.ExperimentPrice(skuInfo.Code); ok {
+			priceCents = cents
+		}
+		// Batches close to expiry are marked down on top of that
+		markdown := sess.MarkdownPercent(skuInfo.Code)
+		priceCents = policy.ApplyMarkdown(priceCents, markdown)
+
+		if basketCurrency == "" {
+			basketCurrency = skuInfo.Currency
+		}
+		price, err := h.convert(ctx, priceCents, skuInfo.Currency, basketCurrency)
+		if err != nil {
+			return SubmitDetectionResult{}, err
+		}
 		skuID, _ := valueobjects.SKUIDFrom(skuInfo.ID)
-		price, _ := valueobjects.NewMoney(skuInfo.PriceCents, skuInfo.Currency)
 
 		detectedItem := domain.NewDetectedItem(
 			skuID,
@@ -140,55 +370,380 @@ func (h *SubmitDetectionHandler) Handle(ctx context.Context, cmd SubmitDetection
 			item.Confidence,
 			price,
 		)
-		detectedItems = append(detectedItems, detectedItem)
+		if box, ok := domain.NewBoundingBox(item.BBox); ok {
+			detectedItem = detectedItem.WithBoxes([]domain.BoundingBox{box})
+		}
+		suspicious := assigned != nil && !assigned[skuInfo.Code]
 
-		outputItems = append(outputItems, DetectedItemOutput{
-			SKU:        skuInfo.Code,
-			Name:       skuInfo.Name,
-			PriceCents: skuInfo.PriceCents,
-			Currency:   skuInfo.Currency,
-			Confidence: item.Confidence,
-		})
+		skuOutputs[skuInfo.Code] = DetectedItemOutput{
+			SKU:             skuInfo.Code,
+			Name:            skuInfo.Name,
+			Currency:        price.Currency(),
+			MarkdownPercent: markdown,
+			Suspicious:      suspicious,
+		}
 
-		expectedWeightGrams += skuInfo.WeightGrams
-		totalCents += skuInfo.PriceCents
-		currency = skuInfo.Currency
+		expectedWeights = append(expectedWeights, policy.ItemWeight{
+			Grams:       skuInfo.WeightGrams,
+			StdDevGrams: skuInfo.WeightStdDevGrams,
+			Calibrated:  skuInfo.WeightCalibrated,
+		})
 
-		if !h.policy.IsConfidenceAcceptable(item.Confidence) {
+		if !detectionPolicy.IsConfidenceAcceptable(item.Confidence) {
+			// The unit's line waits for an attendant to accept or replace it
+			detectedItem = detectedItem.WithReview(domain.ItemReviewPending)
+			needsCloudML = true
+		}
+		detectedItems = append(detectedItems, detectedItem)
+		if suspicious {
 			needsCloudML = true
 		}
 	}
 
-	// Check weight tolerance using policy
+	// Items that together make up a bundle are sold at the bundle price
+	bundles, err := h.catalog.FindActiveBundles(ctx)
+	if err != nil {
+		return SubmitDetectionResult{}, fmt.Errorf("failed to load bundles: %w", err)
+	}
+	if basketCurrency == "" {
+		basketCurrency = defaultCurrency
+	}
+	detectedItems = domain.ApplyBundles(detectedItems, h.toDomainBundles(ctx, bundles, basketCurrency))
+
+	// Identical units make up one line with a quantity
+	detectedItems = domain.AggregateItems(detectedItems)
+
+	// Check weight tolerance using policy; calibrated SKUs bring their own spread
 	measuredWeight, _ := valueobjects.NewWeight(cmd.TotalWeight)
-	expectedWeight, _ := valueobjects.NewWeight(expectedWeightGrams)
-	weightMatch := h.policy.IsWeightMatch(expectedWeight, measuredWeight)
+	weightMatch := detectionPolicy.IsBasketWeightMatch(expectedWeights, measuredWeight)
 
 	if !weightMatch {
 		needsCloudML = true
 	}
 
+	var expectedGrams float64
+	for _, w := range expectedWeights {
+		expectedGrams += w.Grams
+	}
+	if err := h.fraud.Screen(ctx, domain.FraudFacts{
+		Stage:         domain.FraudStageSubmit,
+		Session:       sess,
+		At:            time.Now().UTC(),
+		Units:         len(expectedWeights),
+		ExpectedGrams: expectedGrams,
+		MeasuredGrams: measuredWeight.Grams(),
+		Weighed:       true,
+	}); err != nil {
+		return SubmitDetectionResult{}, err
+	}
+
 	// Record detection in session
-	if err := sess.RecordDetection(detectedItems, measuredWeight); err != nil {
+	if err := sess.RecordDetection(detectedItems, measuredWeight, weightMatch, h.rounding); err != nil {
 		return SubmitDetectionResult{}, fmt.Errorf("failed to record detection: %w", err)
 	}
 
+	sess.RecordUnrecognized(sightings)
+
+	// The session keeps the reviews attendants already made of the basket
+	outputItems := make([]DetectedItemOutput, 0, len(detectedItems))
+	for _, item := range sess.DetectedItems() {
+		out := skuOutputs[item.Code()]
+		out.PriceCents = item.Price().Amount()
+		out.Quantity = item.Quantity()
+		out.LineTotalCents = item.LineTotal().Amount()
+		out.Confidence = item.Confidence()
+		out.Bundle = item.Bundle()
+		out.Review = string(item.Review())
+		out.BBoxes = boxCoords(item.Boxes())
+		outputItems = append(outputItems, out)
+	}
+
+	// Tax is charged from the first quote, so the guest payment intent already
+	// holds what checkout will ask for
+	tax, err := h.taxes.Assess(ctx, sess)
+	if err != nil {
+		return SubmitDetectionResult{}, fmt.Errorf("failed to assess tax: %w", err)
+	}
+	if err := sess.ApplyTax(tax, h.rounding); err != nil {
+		return SubmitDetectionResult{}, err
+	}
+	total := sess.TotalAmount()
+
+	// Keep the guest checkout payment intent in sync with the basket
+	if sess.PaymentIntentID() != "" {
+		_, err := h.payments.UpdateIntentAmount(ctx, sess.PaymentIntentID(), total.Amount(), basketCurrency)
+		if err != nil {
+			return SubmitDetectionResult{}, fmt.Errorf("%w: %v", ErrPaymentProviderUnavailable, err)
+		}
+	}
+
 	// Persist
 	if err := h.sessions.Save(ctx, sess); err != nil {
 		return SubmitDetectionResult{}, fmt.Errorf("failed to save session: %w", err)
 	}
 
+	// The image only serves reconciliation, where sessions without one are
+	// skipped, so failing to store it does not fail the detection
+	if len(cmd.Image) > 0 {
+		_ = h.images.Save(ctx, sess.ID(), cmd.Image, time.Now().UTC())
+	}
+
 	// Publish domain events
 	for _, evt := range sess.PullEvents() {
 		_ = h.publisher.Publish(ctx, evt)
 	}
 
-	return SubmitDetectionResult{
-		SessionID:    sess.ID().String(),
-		Items:        outputItems,
-		TotalCents:   totalCents,
-		Currency:     currency,
-		WeightMatch:  weightMatch,
-		NeedsCloudML: needsCloudML,
-	}, nil
+	subtotal, err := sess.SubtotalCents()
+	if err != nil {
+		return SubmitDetectionResult{}, err
+	}
+	result := SubmitDetectionResult{
+		SessionID:     sess.ID().String(),
+		Items:         outputItems,
+		SubtotalCents: subtotal,
+		TaxCents:      tax.Cents(),
+		RoundingCents: sess.RoundingCents(),
+		TotalCents:    total.Amount(),
+		Currency:      basketCurrency,
+		WeightMatch:   weightMatch,
+		NeedsCloudML:  needsCloudML,
+		Unrecognized:  unrecognized,
+
+		ExpectedModelVersion: device.ModelVersion,
+		ModelVersionMismatch: modelMismatch,
+	}
+	// The detection is applied either way; failing to record it only means a
+	// retry recomputes the same basket
+	if cmd.IdempotencyKey != "" {
+		_ = h.remember(ctx, sess, cmd.IdempotencyKey, result)
+	}
+	return result, nil
+}
+
+// basketAfter returns the units of the session's basket once the units of an
+// incremental frame are placed in it and taken out of it. A unit taken out is
+// the one of its SKU located at the same box, or else the last one placed.
+func basketAfter(sess *domain.Session, added, removed []DetectedItemInput) ([]DetectedItemInput, error) {
+	var units []DetectedItemInput
+	for _, item := range sess.DetectedItems() {
+		units = append(units, lineUnits(item)...)
+	}
+
+	for _, r := range removed {
+		found := -1
+		for i := len(units) - 1; i >= 0; i-- {
+			if units[i].SKU != r.SKU {
+				continue
+			}
+			if found < 0 {
+				found = i
+			}
+			if r.BBox != nil && slices.Equal(units[i].BBox, r.BBox) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil, fmt.Errorf("%w: %s", domain.ErrItemNotInBasket, r.SKU)
+		}
+		units = append(units[:found], units[found+1:]...)
+	}
+	return append(units, added...), nil
+}
+
+// lineUnits lists a basket line one input per unit, the units the device
+// located first
+func lineUnits(item domain.DetectedItem) []DetectedItemInput {
+	boxes := item.Boxes()
+	units := make([]DetectedItemInput, item.Quantity())
+	for i := range units {
+		units[i] = DetectedItemInput{SKU: item.Code(), Confidence: item.Confidence()}
+		if i < len(boxes) {
+			units[i].BBox = boxes[i].Coords()
+		}
+	}
+	return units
+}
+
+// toUnrecognizedItemOutput reports a sighting of a SKU the catalog does not
+// know, with its box when the device located it
+func toUnrecognizedItemOutput(u domain.UnrecognizedItem) UnrecognizedItemOutput {
+	out := UnrecognizedItemOutput{SKU: u.Code(), Confidence: u.Confidence()}
+	if box, ok := u.Box(); ok {
+		out.BBox = box.Coords()
+	}
+	return out
+}
+
+// boxCoords returns the boxes as [x, y, width, height]
+func boxCoords(boxes []domain.BoundingBox) [][]float64 {
+	var coords [][]float64
+	for _, b := range boxes {
+		coords = append(coords, b.Coords())
+	}
+	return coords
+}
+
+// replay returns the result recorded for the key, or
+// ErrProcessedDetectionNotFound when the frame was not processed yet
+func (h *SubmitDetectionHandler) replay(ctx context.Context, sess *domain.Session, key string) (SubmitDetectionResult, error) {
+	processed, err := h.processed.Find(ctx, sess.DeviceID().String(), key)
+	if err != nil {
+		return SubmitDetectionResult{}, err
+	}
+	if processed.SessionID != sess.ID().String() {
+		return SubmitDetectionResult{}, domain.ErrIdempotencyKeyReused
+	}
+
+	var result SubmitDetectionResult
+	if err := json.Unmarshal(processed.Result, &result); err != nil {
+		return SubmitDetectionResult{}, fmt.Errorf("failed to read processed detection: %w", err)
+	}
+	result.Replayed = true
+	return result, nil
+}
+
+// remember records the result for retries of the frame
+func (h *SubmitDetectionHandler) remember(ctx context.Context, sess *domain.Session, key string, result SubmitDetectionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to record processed detection: %w", err)
+	}
+	err = h.processed.Save(ctx, domain.ProcessedDetection{
+		DeviceID:    sess.DeviceID().String(),
+		Key:         key,
+		SessionID:   sess.ID().String(),
+		Result:      data,
+		ProcessedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record processed detection: %w", err)
+	}
+	return nil
+}
+
+// detectionPayload is a submission as kept in the audit log
+type detectionPayload struct {
+	Items          []detectionPayloadItem `json:"items"`
+	Removed        []detectionPayloadItem `json:"removed,omitempty"`
+	Incremental    bool                   `json:"incremental,omitempty"`
+	TotalWeight    float64                `json:"total_weight"`
+	ImageBytes     int                    `json:"image_bytes,omitempty"` // the image itself is kept for reconciliation only
+	ModelVersion   string                 `json:"model_version,omitempty"`
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"`
+	Sequence       int64                  `json:"sequence,omitempty"`
+}
+
+type detectionPayloadItem struct {
+	SKU        string    `json:"sku"`
+	Confidence float64   `json:"confidence"`
+	BBox       []float64 `json:"bbox,omitempty"`
+}
+
+func toDetectionPayloadItems(items []DetectedItemInput) []detectionPayloadItem {
+	out := make([]detectionPayloadItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, detectionPayloadItem{SKU: item.SKU, Confidence: item.Confidence, BBox: item.BBox})
+	}
+	return out
+}
+
+// record appends the submission and the decision on it to the audit log;
+// submissions for malformed session IDs cannot be looked up and are left out
+func (h *SubmitDetectionHandler) record(ctx context.Context, cmd SubmitDetectionCommand, receivedAt time.Time, result SubmitDetectionResult, handleErr error) error {
+	if _, err := valueobjects.SessionIDFrom(cmd.SessionID); err != nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(detectionPayload{
+		Items:          toDetectionPayloadItems(cmd.Items),
+		Removed:        toDetectionPayloadItems(cmd.Removed),
+		Incremental:    cmd.Incremental,
+		TotalWeight:    cmd.TotalWeight,
+		ImageBytes:     len(cmd.Image),
+		ModelVersion:   cmd.ModelVersion,
+		IdempotencyKey: cmd.IdempotencyKey,
+		Sequence:       cmd.Sequence,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record detection: %w", err)
+	}
+
+	outcome := domain.DetectionOutcomeApplied
+	var decision []byte
+	switch {
+	case handleErr != nil:
+		outcome = domain.DetectionOutcomeRejected
+		decision, err = json.Marshal(map[string]string{"error": handleErr.Error()})
+	case result.Replayed:
+		outcome = domain.DetectionOutcomeReplayed
+		decision, err = json.Marshal(result)
+	default:
+		decision, err = json.Marshal(result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record detection: %w", err)
+	}
+
+	source := cmd.Source
+	if source == "" {
+		source = domain.DetectionSourceDevice
+	}
+	err = h.audit.Append(ctx, domain.DetectionRecord{
+		SessionID:  cmd.SessionID,
+		DeviceID:   cmd.DeviceID,
+		Source:     source,
+		Payload:    payload,
+		Outcome:    outcome,
+		Decision:   decision,
+		ReceivedAt: receivedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record detection: %w", err)
+	}
+	return nil
+}
+
+// convert prices an amount in the basket's currency
+func (h *SubmitDetectionHandler) convert(ctx context.Context, cents int64, from, to string) (valueobjects.Money, error) {
+	price, err := valueobjects.NewMoney(cents, from)
+	if err != nil {
+		return valueobjects.Money{}, err
+	}
+	converted, err := h.converter.Convert(ctx, price, to)
+	if err != nil {
+		return valueobjects.Money{}, fmt.Errorf("%w: %s to %s: %v", ErrCurrencyConversionUnavailable, from, to, err)
+	}
+	return converted, nil
+}
+
+// toDomainBundles prices the bundles in the basket's currency; a bundle
+// without an exchange rate is left out
+func (h *SubmitDetectionHandler) toDomainBundles(ctx context.Context, bundles []ports.BundleInfo, basketCurrency string) []domain.Bundle {
+	out := make([]domain.Bundle, 0, len(bundles))
+	for _, b := range bundles {
+		price, err := h.convert(ctx, b.PriceCents, b.Currency, basketCurrency)
+		if err != nil {
+			continue
+		}
+		bundle := domain.Bundle{Code: b.Code, Price: price, Components: make(map[valueobjects.SKUID]int, len(b.Components))}
+		for id, quantity := range b.Components {
+			skuID, err := valueobjects.SKUIDFrom(id)
+			if err != nil {
+				continue
+			}
+			bundle.Components[skuID] = quantity
+		}
+		out = append(out, bundle)
+	}
+	return out
+}
+
+// policyFor returns the detection policy with the confidence threshold
+// configured for the device or its group, if any
+func (h *SubmitDetectionHandler) policyFor(device *ports.DeviceInfo) (policy.DetectionPolicy, error) {
+	if device.ConfidenceThreshold == nil {
+		return h.policy, nil
+	}
+	return h.policy.WithConfidenceThreshold(*device.ConfidenceThreshold)
 }
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// MaxOfflineEntries caps how many buffered entries a device may sync in one request
+const MaxOfflineEntries = 500
+
+// ErrTooManyOfflineEntries is returned when a sync batch exceeds MaxOfflineEntries
+var ErrTooManyOfflineEntries = errors.New("too many offline entries in one batch")
+
+const offlineCancelReason = "cancelled on device while offline"
+
+// OfflineEntryInput is one detection or state transition buffered by a device
+type OfflineEntryInput struct {
+	ClientID    string
+	Type        string
+	SessionID   string
+	RecordedAt  time.Time
+	Items       []DetectedItemInput
+	TotalWeight float64
+	Reason      string
+}
+
+// SyncOfflineEntriesCommand is the input DTO for syncing a device's offline buffer
+type SyncOfflineEntriesCommand struct {
+	DeviceID string
+	Entries  []OfflineEntryInput
+}
+
+// SyncEntryResult is the outcome of one entry
+type SyncEntryResult struct {
+	ClientID       string
+	SessionID      string
+	Status         string
+	Error          string
+	PreviousStatus string // outcome of the original submission, set for duplicates
+}
+
+// SyncOfflineEntriesResult lists the outcomes in the order the entries were submitted
+type SyncOfflineEntriesResult struct {
+	DeviceID string
+	Results  []SyncEntryResult
+}
+
+// SyncOfflineEntriesHandler replays what a device recorded while it had no
+// connectivity. Entries are applied in the order they were recorded through
+// the regular detection and cancellation use cases; every entry is answered
+// once per client ID, so devices can resend a batch until they get a reply.
+//
+// Devices are expected to drain their buffer before resuming live
+// submissions: only detections applied through sync are checked for being
+// superseded.
+type SyncOfflineEntriesHandler struct {
+	sessions domain.SessionRepository
+	syncLog  domain.SyncLogRepository
+	detect   *SubmitDetectionHandler
+	cancel   *CancelSessionHandler
+}
+
+func NewSyncOfflineEntriesHandler(
+	sessions domain.SessionRepository,
+	syncLog domain.SyncLogRepository,
+	detect *SubmitDetectionHandler,
+	cancel *CancelSessionHandler,
+) *SyncOfflineEntriesHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if syncLog == nil {
+		panic("nil SyncLogRepository")
+	}
+	if detect == nil {
+		panic("nil SubmitDetectionHandler")
+	}
+	if cancel == nil {
+		panic("nil CancelSessionHandler")
+	}
+	return &SyncOfflineEntriesHandler{
+		sessions: sessions,
+		syncLog:  syncLog,
+		detect:   detect,
+		cancel:   cancel,
+	}
+}
+
+func (h *SyncOfflineEntriesHandler) Handle(ctx context.Context, cmd SyncOfflineEntriesCommand) (SyncOfflineEntriesResult, error) {
+	deviceID, err := valueobjects.DeviceIDFrom(cmd.DeviceID)
+	if err != nil {
+		return SyncOfflineEntriesResult{}, domain.ErrInvalidDeviceID
+	}
+	if len(cmd.Entries) > MaxOfflineEntries {
+		return SyncOfflineEntriesResult{}, ErrTooManyOfflineEntries
+	}
+
+	// Apply in recording order; ties keep the order the device sent them in
+	order := make([]int, len(cmd.Entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return cmd.Entries[order[a]].RecordedAt.Before(cmd.Entries[order[b]].RecordedAt)
+	})
+
+	results := make([]SyncEntryResult, len(cmd.Entries))
+	inBatch := make(map[string]domain.SyncStatus, len(cmd.Entries))
+	for _, i := range order {
+		results[i] = h.syncEntry(ctx, deviceID, cmd.Entries[i], inBatch)
+	}
+
+	return SyncOfflineEntriesResult{
+		DeviceID: deviceID.String(),
+		Results:  results,
+	}, nil
+}
+
+func (h *SyncOfflineEntriesHandler) syncEntry(ctx context.Context, deviceID valueobjects.DeviceID, entry OfflineEntryInput, inBatch map[string]domain.SyncStatus) SyncEntryResult {
+	clientID := strings.TrimSpace(entry.ClientID)
+	result := SyncEntryResult{ClientID: clientID, SessionID: entry.SessionID}
+	if clientID == "" {
+		result.Status = string(domain.SyncStatusRejected)
+		result.Error = domain.ErrClientIDRequired.Error()
+		return result
+	}
+
+	// Idempotency: an entry is only ever applied once per device and client ID
+	if previous, seen := inBatch[clientID]; seen {
+		result.Status = string(domain.SyncStatusDuplicate)
+		result.PreviousStatus = string(previous)
+		return result
+	}
+	record, err := h.syncLog.Find(ctx, deviceID.String(), clientID)
+	switch {
+	case err == nil:
+		inBatch[clientID] = record.Status
+		result.Status = string(domain.SyncStatusDuplicate)
+		result.PreviousStatus = string(record.Status)
+		return result
+	case !errors.Is(err, domain.ErrSyncRecordNotFound):
+		result.Status = string(domain.SyncStatusRetry)
+		result.Error = err.Error()
+		return result
+	}
+
+	status, applyErr := h.apply(ctx, deviceID, entry)
+	result.Status = string(status)
+	if applyErr != nil {
+		result.Error = applyErr.Error()
+	}
+	if !status.IsFinal() {
+		return result
+	}
+
+	inBatch[clientID] = status
+	// The outcome stands even if it cannot be logged; a resent entry is then
+	// re-evaluated against the session's current state
+	_ = h.syncLog.Save(ctx, domain.SyncRecord{
+		DeviceID:   deviceID.String(),
+		ClientID:   clientID,
+		SessionID:  entry.SessionID,
+		Kind:       domain.OfflineEntryKind(entry.Type),
+		RecordedAt: entry.RecordedAt.UTC(),
+		Status:     status,
+		Reason:     result.Error,
+		SyncedAt:   time.Now().UTC(),
+	})
+
+	return result
+}
+
+// apply checks an entry against the session's current state and applies it
+func (h *SyncOfflineEntriesHandler) apply(ctx context.Context, deviceID valueobjects.DeviceID, entry OfflineEntryInput) (domain.SyncStatus, error) {
+	kind := domain.OfflineEntryKind(entry.Type)
+	if !kind.IsValid() {
+		return domain.SyncStatusRejected, domain.ErrUnknownOfflineEntry
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(entry.SessionID)
+	if err != nil {
+		return domain.SyncStatusRejected, fmt.Errorf("invalid session ID: %w", err)
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return domain.SyncStatusRejected, domain.ErrSessionNotFound
+		}
+		return domain.SyncStatusRetry, err
+	}
+	if sess.DeviceID() != deviceID {
+		return domain.SyncStatusRejected, domain.ErrSessionDeviceMismatch
+	}
+
+	// Nothing recorded after the session ran out may change it
+	if entry.RecordedAt.After(sess.ExpiresAt()) {
+		return domain.SyncStatusConflict, domain.ErrSessionExpired
+	}
+
+	switch kind {
+	case domain.OfflineEntryDetection:
+		return h.applyDetection(ctx, sess, entry)
+	default:
+		return h.applyCancel(ctx, sess, entry)
+	}
+}
+
+func (h *SyncOfflineEntriesHandler) applyDetection(ctx context.Context, sess *domain.Session, entry OfflineEntryInput) (domain.SyncStatus, error) {
+	// Detections are full basket snapshots, so an older one must not replace a newer one
+	latest, err := h.syncLog.LatestAppliedDetection(ctx, sess.ID().String())
+	if err != nil {
+		return domain.SyncStatusRetry, err
+	}
+	if latest != nil && !entry.RecordedAt.After(*latest) {
+		return domain.SyncStatusConflict, domain.ErrDetectionSuperseded
+	}
+
+	if sess.Status() == domain.SessionStatusActive && sess.IsExpired() {
+		return domain.SyncStatusConflict, domain.ErrSessionExpired
+	}
+	if !sess.IsActive() {
+		return domain.SyncStatusConflict, domain.ErrSessionNotActive
+	}
+
+	_, err = h.detect.Handle(ctx, SubmitDetectionCommand{
+		DeviceID:    sess.DeviceID().String(),
+		SessionID:   sess.ID().String(),
+		Items:       entry.Items,
+		TotalWeight: entry.TotalWeight,
+		Source:      domain.DetectionSourceOfflineSync,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotActive) {
+			return domain.SyncStatusConflict, domain.ErrSessionNotActive
+		}
+		return domain.SyncStatusRetry, err
+	}
+
+	return domain.SyncStatusApplied, nil
+}
+
+func (h *SyncOfflineEntriesHandler) applyCancel(ctx context.Context, sess *domain.Session, entry OfflineEntryInput) (domain.SyncStatus, error) {
+	switch sess.Status() {
+	case domain.SessionStatusCompleted:
+		return domain.SyncStatusConflict, domain.ErrSessionAlreadyCompleted
+	case domain.SessionStatusActive:
+	default:
+		return domain.SyncStatusConflict, domain.ErrSessionNotActive
+	}
+
+	reason := strings.TrimSpace(entry.Reason)
+	if reason == "" {
+		reason = offlineCancelReason
+	}
+
+	_, err := h.cancel.Handle(ctx, CancelSessionCommand{
+		SessionID: sess.ID().String(),
+		Reason:    reason,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionAlreadyCompleted) {
+			return domain.SyncStatusConflict, domain.ErrSessionAlreadyCompleted
+		}
+		return domain.SyncStatusRetry, err
+	}
+
+	return domain.SyncStatusApplied, nil
+}
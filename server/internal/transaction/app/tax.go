@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// TaxAssessor works out the tax of a basket from the tax policy, the category
+// of each SKU and the region of the device
+type TaxAssessor struct {
+	catalog ports.CatalogReader
+	devices ports.DeviceReader
+	policy  policy.TaxPolicy
+}
+
+func NewTaxAssessor(catalog ports.CatalogReader, devices ports.DeviceReader, taxPolicy policy.TaxPolicy) *TaxAssessor {
+	if catalog == nil {
+		panic("nil CatalogReader")
+	}
+	if devices == nil {
+		panic("nil DeviceReader")
+	}
+	return &TaxAssessor{catalog: catalog, devices: devices, policy: taxPolicy}
+}
+
+// Assess returns the tax of the session's current basket
+func (a *TaxAssessor) Assess(ctx context.Context, sess *domain.Session) (domain.Tax, error) {
+	items := sess.DetectedItems()
+	if a.policy.IsZero() || len(items) == 0 {
+		return domain.Tax{}, nil
+	}
+
+	device, err := a.devices.FindByID(ctx, sess.DeviceID().String())
+	if err != nil {
+		return domain.Tax{}, fmt.Errorf("failed to find device: %w", err)
+	}
+
+	categories := make(map[string]string, len(items)) // SKU code -> category ID
+	amounts := make([]domain.TaxableAmount, 0, len(items))
+	for _, item := range items {
+		categoryID, ok := categories[item.Code()]
+		if !ok {
+			sku, err := a.catalog.FindSKUByCode(ctx, item.Code())
+			if err != nil {
+				return domain.Tax{}, fmt.Errorf("failed to find SKU %s: %w", item.Code(), err)
+			}
+			categoryID = sku.CategoryID
+			categories[item.Code()] = categoryID
+		}
+		amounts = append(amounts, domain.TaxableAmount{
+			Rate:  a.policy.RateFor(categoryID, device.Region),
+			Cents: item.LineTotal().Amount(),
+		})
+	}
+
+	// Tax is charged on what the customer pays once the coupon is taken off
+	amounts = sess.Coupon().Discounted(amounts, items[0].LineTotal().Currency())
+
+	return domain.NewTax(amounts, a.policy.Included()), nil
+}
+
+// TaxLineView is one rate's share of a basket's tax
+type TaxLineView struct {
+	RatePercent  float64
+	TaxableCents int64
+	TaxCents     int64
+}
+
+func toTaxLineViews(tax domain.Tax) []TaxLineView {
+	var views []TaxLineView
+	for _, l := range tax.Lines() {
+		views = append(views, TaxLineView{RatePercent: l.Rate().Percent(), TaxableCents: l.TaxableCents(), TaxCents: l.TaxCents()})
+	}
+	return views
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// TransactionView is a read-only view of a completed sale
+type TransactionView struct {
+	ID            string
+	SessionID     string
+	DeviceID      string
+	UserID        string
+	Lines         []TransactionLineView
+	Units         int
+	TotalCents    int64
+	TaxCents      int64
+	RoundingCents int64
+	Currency      string
+	CouponCode    string
+	DiscountCents int64
+	PaymentRef    string
+	Fiscal        *FiscalRecordView // nil where fiscalization is not required
+	Status        string
+	CompletedAt   time.Time
+}
+
+// TransactionLineView is a read-only view of a line of a sale
+type TransactionLineView struct {
+	SKUID          string
+	Code           string
+	Name           string
+	PriceCents     int64 // of one unit
+	Quantity       int
+	LineTotalCents int64
+	Currency       string
+	Bundle         string
+}
+
+// TransactionQueryService provides read-only access to completed sales, for
+// refunds and sales reporting
+type TransactionQueryService struct {
+	transactions domain.TransactionRepository
+}
+
+func NewTransactionQueryService(transactions domain.TransactionRepository) *TransactionQueryService {
+	if transactions == nil {
+		panic("nil TransactionRepository")
+	}
+	return &TransactionQueryService{transactions: transactions}
+}
+
+func (s *TransactionQueryService) FindBySessionID(ctx context.Context, sessionID string) (*TransactionView, error) {
+	id, err := valueobjects.SessionIDFrom(sessionID)
+	if err != nil {
+		return nil, domain.ErrTransactionNotFound
+	}
+
+	txn, err := s.transactions.FindBySessionID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionView(txn), nil
+}
+
+// FindCompletedByUser lists the user's sales completed in [from, to)
+func (s *TransactionQueryService) FindCompletedByUser(ctx context.Context, userID string, from, to time.Time) ([]*TransactionView, error) {
+	txns, err := s.transactions.FindCompletedByUser(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*TransactionView, 0, len(txns))
+	for _, txn := range txns {
+		views = append(views, toTransactionView(txn))
+	}
+	return views, nil
+}
+
+// SoldUnits counts the units of each SKU sold on the device since the given time for that SKU
+func (s *TransactionQueryService) SoldUnits(ctx context.Context, deviceID string, since map[string]time.Time) (map[string]int, error) {
+	devID, err := valueobjects.DeviceIDFrom(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transactions.SoldUnits(ctx, devID, since)
+}
+
+// CouponRedemptions counts the completed sales checked out with the coupon
+func (s *TransactionQueryService) CouponRedemptions(ctx context.Context, code string) (int, error) {
+	return s.transactions.CouponRedemptions(ctx, code)
+}
+
+func toTransactionView(txn *domain.Transaction) *TransactionView {
+	lines := make([]TransactionLineView, 0, len(txn.Lines()))
+	for _, l := range txn.Lines() {
+		lines = append(lines, TransactionLineView{
+			SKUID:          l.SKUID().String(),
+			Code:           l.Code(),
+			Name:           l.Name(),
+			PriceCents:     l.UnitPrice().Amount(),
+			Quantity:       l.Quantity(),
+			LineTotalCents: l.LineTotal().Amount(),
+			Currency:       l.UnitPrice().Currency(),
+			Bundle:         l.Bundle(),
+		})
+	}
+
+	return &TransactionView{
+		ID:            txn.ID().String(),
+		SessionID:     txn.SessionID().String(),
+		DeviceID:      txn.DeviceID().String(),
+		UserID:        txn.UserID(),
+		Lines:         lines,
+		Units:         txn.UnitCount(),
+		TotalCents:    txn.Total().Amount(),
+		TaxCents:      txn.TaxCents(),
+		RoundingCents: txn.RoundingCents(),
+		Currency:      txn.Total().Currency(),
+		CouponCode:    txn.CouponCode(),
+		DiscountCents: txn.DiscountCents(),
+		PaymentRef:    txn.PaymentRef(),
+		Fiscal:        toFiscalRecordView(txn.FiscalRecord()),
+		Status:        string(txn.Status()),
+		CompletedAt:   txn.CompletedAt(),
+	}
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+var (
+	// ErrVerificationImageRequired is returned when a cloud verification is requested without an image
+	ErrVerificationImageRequired = errors.New("image is required")
+	// ErrCloudDetectionFailed is returned when the cloud model could not process the image
+	ErrCloudDetectionFailed = errors.New("cloud detection failed")
+)
+
+// VerifySessionImageCommand is the input DTO for verifying a session in the cloud
+type VerifySessionImageCommand struct {
+	SessionID string
+	Image     []byte
+}
+
+// VerifiedItem compares the units of a SKU the device and the cloud model saw
+type VerifiedItem struct {
+	SKU           string
+	EdgeQuantity  int
+	CloudQuantity int
+	Quantity      int // units in the basket after verification
+}
+
+// VerifySessionImageResult is the output DTO: the basket as priced after
+// verification, and how the cloud model's view differed from the device's
+type VerifySessionImageResult struct {
+	SubmitDetectionResult
+	ModelVersion string
+	Strategy     string // how the device's and the cloud model's detections were reconciled
+	Verified     []VerifiedItem
+	Corrected    bool // the basket changed
+}
+
+// VerifySessionImageHandler runs the image of a session the device could not
+// identify confidently through the cloud model and updates the basket with
+// the detections of both, merged by the detection reconciler
+type VerifySessionImageHandler struct {
+	sessions   domain.SessionRepository
+	detector   ports.SessionDetector
+	submit     *SubmitDetectionHandler
+	detections domain.DetectionLogRepository
+	reconciler *DetectionReconciler
+}
+
+func NewVerifySessionImageHandler(
+	sessions domain.SessionRepository,
+	detector ports.SessionDetector,
+	submit *SubmitDetectionHandler,
+	detections domain.DetectionLogRepository,
+	reconciler *DetectionReconciler,
+) *VerifySessionImageHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if detector == nil {
+		panic("nil SessionDetector")
+	}
+	if submit == nil {
+		panic("nil SubmitDetectionHandler")
+	}
+	if detections == nil {
+		panic("nil DetectionLogRepository")
+	}
+	if reconciler == nil {
+		panic("nil DetectionReconciler")
+	}
+	return &VerifySessionImageHandler{
+		sessions:   sessions,
+		detector:   detector,
+		submit:     submit,
+		detections: detections,
+		reconciler: reconciler,
+	}
+}
+
+func (h *VerifySessionImageHandler) Handle(ctx context.Context, cmd VerifySessionImageCommand) (VerifySessionImageResult, error) {
+	if len(cmd.Image) == 0 {
+		return VerifySessionImageResult{}, ErrVerificationImageRequired
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return VerifySessionImageResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return VerifySessionImageResult{}, domain.ErrSessionNotFound
+	}
+	if !sess.IsActive() {
+		return VerifySessionImageResult{}, domain.ErrSessionNotActive
+	}
+
+	detection, err := h.detector.DetectSession(ctx, sess.DeviceID().String(), cmd.Image)
+	if err != nil {
+		if errors.Is(err, ports.ErrCloudDetectionUnavailable) {
+			return VerifySessionImageResult{}, err
+		}
+		return VerifySessionImageResult{}, fmt.Errorf("%w: %v", ErrCloudDetectionFailed, err)
+	}
+
+	frames, err := h.latestFrames(ctx, sess.ID().String())
+	if err != nil {
+		return VerifySessionImageResult{}, err
+	}
+	in := ReconcileInput{Basket: basketUnits(sess), Frames: frames}
+	for _, d := range detection.Detections {
+		in.Cloud = append(in.Cloud, FrameDetection{SKU: d.SKUCode, Confidence: d.Confidence, Box: d.BBox})
+	}
+	units := h.reconciler.Reconcile(in)
+	verified, corrected := h.compare(in, units)
+
+	items := make([]DetectedItemInput, 0, len(units))
+	for _, u := range units {
+		items = append(items, DetectedItemInput{SKU: u.SKU, Confidence: u.Confidence, BBox: u.Box})
+	}
+
+	// The reconciled basket is priced like any full frame from the device
+	result, err := h.submit.Handle(ctx, SubmitDetectionCommand{
+		DeviceID:    sess.DeviceID().String(),
+		SessionID:   sess.ID().String(),
+		Items:       items,
+		TotalWeight: sess.TotalWeight().Grams(),
+		Image:       cmd.Image,
+		Source:      domain.DetectionSourceCloudVerification,
+	})
+	if err != nil {
+		return VerifySessionImageResult{}, err
+	}
+	// The cloud model has had its look; asking for another upload would loop
+	result.NeedsCloudML = false
+
+	return VerifySessionImageResult{
+		SubmitDetectionResult: result,
+		ModelVersion:          detection.ModelVersion,
+		Strategy:              h.reconciler.Strategy(),
+		Verified:              verified,
+		Corrected:             corrected,
+	}, nil
+}
+
+// latestFrames returns the detections of the latest full frames the device
+// submitted for the session, as many as the reconciler looks at, oldest first
+func (h *VerifySessionImageHandler) latestFrames(ctx context.Context, sessionID string) ([][]FrameDetection, error) {
+	needed := h.reconciler.FramesNeeded()
+	if needed == 0 {
+		return nil, nil
+	}
+	records, err := h.detections.FindBySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load detection log: %w", err)
+	}
+
+	var frames [][]FrameDetection
+	for _, record := range records {
+		if record.Outcome != domain.DetectionOutcomeApplied ||
+			(record.Source != domain.DetectionSourceDevice && record.Source != domain.DetectionSourceOfflineSync) {
+			continue
+		}
+		var payload detectionPayload
+		if err := json.Unmarshal(record.Payload, &payload); err != nil || payload.Incremental {
+			continue
+		}
+		frame := make([]FrameDetection, 0, len(payload.Items))
+		for _, item := range payload.Items {
+			frame = append(frame, FrameDetection{SKU: item.SKU, Confidence: item.Confidence, Box: item.BBox})
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) > needed {
+		frames = frames[len(frames)-needed:]
+	}
+	return frames, nil
+}
+
+// basketUnits lists the session's basket one detection per unit
+func basketUnits(sess *domain.Session) []FrameDetection {
+	var units []FrameDetection
+	for _, item := range sess.DetectedItems() {
+		for _, u := range lineUnits(item) {
+			units = append(units, FrameDetection{SKU: u.SKU, Confidence: u.Confidence, Box: u.BBox})
+		}
+	}
+	return units
+}
+
+// compare lines up per SKU the units in the basket, the units the cloud
+// model saw confidently and the reconciled units, in the order the SKUs were
+// first seen
+func (h *VerifySessionImageHandler) compare(in ReconcileInput, units []FrameDetection) ([]VerifiedItem, bool) {
+	var order []string
+	lines := make(map[string]*VerifiedItem)
+	line := func(code string) *VerifiedItem {
+		if v, ok := lines[code]; ok {
+			return v
+		}
+		v := &VerifiedItem{SKU: code}
+		lines[code] = v
+		order = append(order, code)
+		return v
+	}
+
+	for _, u := range in.Basket {
+		line(u.SKU).EdgeQuantity++
+	}
+	for _, d := range h.reconciler.confident(in.Cloud) {
+		line(d.SKU).CloudQuantity++
+	}
+	for _, u := range units {
+		line(u.SKU).Quantity++
+	}
+
+	verified := make([]VerifiedItem, 0, len(order))
+	corrected := false
+	for _, code := range order {
+		v := lines[code]
+		if v.Quantity != v.EdgeQuantity {
+			corrected = true
+		}
+		verified = append(verified, *v)
+	}
+	return verified, corrected
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// WalkawayPolicy bounds how long a customer who left the device has to pay
+// before the session is cancelled
+type WalkawayPolicy struct {
+	CancelAfter time.Duration
+}
+
+// SignalWalkawayCommand is the input DTO for a device reporting that the
+// customer left: the door closed or the items were taken off the scale
+type SignalWalkawayCommand struct {
+	DeviceID  string
+	SessionID string
+	Signal    string
+}
+
+// SignalWalkawayResult is the output DTO
+type SignalWalkawayResult struct {
+	SessionID  string
+	Signal     string // the first signal, which the grace runs from
+	SignaledAt time.Time
+	// CancelAt is when the session is cancelled if it holds items and is
+	// still unpaid
+	CancelAt time.Time
+}
+
+// SignalWalkawayHandler records the device's signal on the session it
+// reports for
+type SignalWalkawayHandler struct {
+	sessions domain.SessionRepository
+	policy   WalkawayPolicy
+}
+
+func NewSignalWalkawayHandler(sessions domain.SessionRepository, policy WalkawayPolicy) *SignalWalkawayHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	return &SignalWalkawayHandler{sessions: sessions, policy: policy}
+}
+
+func (h *SignalWalkawayHandler) Handle(ctx context.Context, cmd SignalWalkawayCommand) (SignalWalkawayResult, error) {
+	signal, err := domain.ParseWalkawaySignal(cmd.Signal)
+	if err != nil {
+		return SignalWalkawayResult{}, err
+	}
+
+	sessionID, err := valueobjects.SessionIDFrom(cmd.SessionID)
+	if err != nil {
+		return SignalWalkawayResult{}, domain.ErrSessionNotFound
+	}
+	sess, err := h.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		return SignalWalkawayResult{}, domain.ErrSessionNotFound
+	}
+	if sess.DeviceID().String() != cmd.DeviceID {
+		return SignalWalkawayResult{}, domain.ErrSessionDeviceMismatch
+	}
+
+	if err := sess.SignalWalkaway(signal, time.Now().UTC()); err != nil {
+		return SignalWalkawayResult{}, err
+	}
+	if err := h.sessions.Save(ctx, sess); err != nil {
+		return SignalWalkawayResult{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	walkaway := sess.Walkaway()
+	return SignalWalkawayResult{
+		SessionID:  sess.ID().String(),
+		Signal:     string(walkaway.Signal()),
+		SignaledAt: walkaway.SignaledAt(),
+		CancelAt:   walkaway.SignaledAt().Add(h.policy.CancelAfter),
+	}, nil
+}
+
+// CancelWalkawaysCommand is the input DTO for one walkaway sweep
+type CancelWalkawaysCommand struct {
+	CancelAfter *time.Duration // nil uses the policy's grace
+}
+
+// CancelWalkawaysResult is the output DTO
+type CancelWalkawaysResult struct {
+	CancelAfter time.Duration
+	Cancelled   []string // IDs of the sessions this sweep cancelled
+}
+
+// CancelWalkawaysHandler cancels the sessions whose customer left the device
+// with items and did not pay within the grace, raising WalkawayDetected for
+// fraud analytics. A session saved concurrently, say by a payment coming
+// in, is left for the next sweep to look at again.
+type CancelWalkawaysHandler struct {
+	sessions  domain.SessionRepository
+	publisher eventPublisher
+	policy    WalkawayPolicy
+}
+
+func NewCancelWalkawaysHandler(sessions domain.SessionRepository, publisher eventPublisher, policy WalkawayPolicy) *CancelWalkawaysHandler {
+	if sessions == nil {
+		panic("nil SessionRepository")
+	}
+	if publisher == nil {
+		panic("nil EventPublisher")
+	}
+	return &CancelWalkawaysHandler{sessions: sessions, publisher: publisher, policy: policy}
+}
+
+func (h *CancelWalkawaysHandler) Handle(ctx context.Context, cmd CancelWalkawaysCommand) (CancelWalkawaysResult, error) {
+	cancelAfter := h.policy.CancelAfter
+	if cmd.CancelAfter != nil {
+		cancelAfter = *cmd.CancelAfter
+	}
+
+	now := time.Now().UTC()
+	sessions, err := h.sessions.FindWalkedAway(ctx, now.Add(-cancelAfter))
+	if err != nil {
+		return CancelWalkawaysResult{}, err
+	}
+
+	result := CancelWalkawaysResult{CancelAfter: cancelAfter, Cancelled: []string{}}
+	for _, sess := range sessions {
+		if !sess.CancelWalkaway(now, cancelAfter) {
+			continue
+		}
+		if err := h.sessions.Save(ctx, sess); err != nil {
+			if errors.Is(err, domain.ErrSessionConflict) {
+				continue
+			}
+			return result, fmt.Errorf("failed to save session: %w", err)
+		}
+		for _, evt := range sess.PullEvents() {
+			_ = h.publisher.Publish(ctx, evt)
+		}
+		result.Cancelled = append(result.Cancelled, sess.ID().String())
+	}
+	return result, nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "slices"
+
+// RoleFinance is held by operator staff allowed to approve refunds
+const RoleFinance = "finance"
+
+// Actor is a value object identifying the operator staff member performing
+// an action, as asserted by the operator's identity provider
+type Actor struct {
+	id    string
+	roles []string
+}
+
+func NewActor(id string, roles []string) Actor {
+	return Actor{id: id, roles: append([]string{}, roles...)}
+}
+
+// SystemActor is used for decisions made automatically by policy
+func SystemActor() Actor {
+	return Actor{id: "system"}
+}
+
+func (a Actor) ID() string      { return a.id }
+func (a Actor) Roles() []string { return append([]string{}, a.roles...) }
+func (a Actor) IsZero() bool    { return a.id == "" }
+
+func (a Actor) HasRole(role string) bool {
+	return slices.Contains(a.roles, role)
+}
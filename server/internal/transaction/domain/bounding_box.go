@@ -0,0 +1,28 @@
+package domain
+
+import "math"
+
+// BoundingBox is a value object locating a detected unit in the camera
+// frame, as fractions of the image
+type BoundingBox struct {
+	x, y, width, height float64
+}
+
+// NewBoundingBox takes the [x, y, width, height] a device reports; false
+// when that is not four non-negative numbers
+func NewBoundingBox(coords []float64) (BoundingBox, bool) {
+	if len(coords) != 4 {
+		return BoundingBox{}, false
+	}
+	for _, c := range coords {
+		if c < 0 || math.IsNaN(c) || math.IsInf(c, 0) {
+			return BoundingBox{}, false
+		}
+	}
+	return BoundingBox{x: coords[0], y: coords[1], width: coords[2], height: coords[3]}, true
+}
+
+// Coords returns the box as [x, y, width, height]
+func (b BoundingBox) Coords() []float64 {
+	return []float64{b.x, b.y, b.width, b.height}
+}
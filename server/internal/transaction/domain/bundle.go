@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// Bundle is a combo offer from the catalog: the listed units sold together
+// for one price
+type Bundle struct {
+	Code       string
+	Price      valueobjects.Money
+	Components map[valueobjects.SKUID]int // units of each SKU
+}
+
+// ApplyBundles prices the items that make up bundles. The bundle saving the
+// customer the most is taken first, as often as the basket holds it, then the
+// next; a bundle that is not cheaper than its items is never applied. Each
+// bundle's price is split over its items in proportion to their own prices, so
+// the items still add up to what the customer pays. The items are single units,
+// as detected; lines are aggregated afterwards.
+func ApplyBundles(items []DetectedItem, bundles []Bundle) []DetectedItem {
+	priced := append([]DetectedItem{}, items...)
+	if len(bundles) == 0 || len(items) < 2 {
+		return priced
+	}
+
+	// Stable order so equal savings always resolve the same way
+	bundles = append([]Bundle{}, bundles...)
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Code < bundles[j].Code })
+
+	for {
+		var (
+			best       Bundle
+			bestUnits  []int
+			bestSaving int64
+		)
+		for _, b := range bundles {
+			units, regular, ok := takeUnits(priced, b)
+			if !ok {
+				continue
+			}
+			if saving := regular - b.Price.Amount(); saving > bestSaving {
+				best, bestUnits, bestSaving = b, units, saving
+			}
+		}
+		if bestUnits == nil {
+			return priced
+		}
+		splitBundlePrice(priced, bestUnits, best)
+	}
+}
+
+// takeUnits picks items not yet in a bundle that fill the bundle, and returns
+// their indexes and what they cost on their own
+func takeUnits(items []DetectedItem, b Bundle) ([]int, int64, bool) {
+	var (
+		units   []int
+		regular int64
+	)
+	for skuID, quantity := range b.Components {
+		found := 0
+		for i, item := range items {
+			if found == quantity {
+				break
+			}
+			if item.bundle != "" || item.skuID != skuID || item.price.Currency() != b.Price.Currency() {
+				continue
+			}
+			units = append(units, i)
+			regular += item.price.Amount()
+			found++
+		}
+		if found < quantity {
+			return nil, 0, false
+		}
+	}
+	sort.Ints(units)
+	return units, regular, len(units) > 0
+}
+
+func splitBundlePrice(items []DetectedItem, units []int, b Bundle) {
+	weights := make([]int64, len(units))
+	for n, i := range units {
+		weights[n] = items[i].price.Amount()
+	}
+	for n, share := range splitInProportion(b.Price, weights) {
+		items[units[n]] = items[units[n]].InBundle(b.Code, share)
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import "github.com/vending-machine/server/internal/shared/valueobjects"
+
+// AppliedCoupon is a value object recording the coupon a session is checked
+// out with and what it takes off: either a percentage or a fixed amount
+type AppliedCoupon struct {
+	code           string
+	percentOff     int
+	amountOffCents int64
+}
+
+func NewAppliedCoupon(code string, percentOff int, amountOffCents int64) AppliedCoupon {
+	return AppliedCoupon{
+		code:           code,
+		percentOff:     percentOff,
+		amountOffCents: amountOffCents,
+	}
+}
+
+func (c AppliedCoupon) Code() string          { return c.code }
+func (c AppliedCoupon) PercentOff() int       { return c.percentOff }
+func (c AppliedCoupon) AmountOffCents() int64 { return c.amountOffCents }
+func (c AppliedCoupon) IsZero() bool          { return c == AppliedCoupon{} }
+
+// DiscountOn is what the coupon takes off items costing subtotal, a
+// percentage rounded to the cent; it never takes off more than the items cost
+// and takes off nothing from items too expensive to take a percentage of
+func (c AppliedCoupon) DiscountOn(subtotal valueobjects.Money) valueobjects.Money {
+	none, _ := subtotal.Subtract(subtotal)
+	if subtotal.Amount() <= 0 {
+		return none
+	}
+	var discount valueobjects.Money
+	if c.percentOff > 0 {
+		percent, err := subtotal.Multiply(float64(c.percentOff) / 100)
+		if err != nil {
+			return none
+		}
+		discount = percent
+	} else if amount, err := valueobjects.NewMoney(c.amountOffCents, subtotal.Currency()); err == nil {
+		discount = amount
+	} else {
+		return none
+	}
+	if discount.Amount() > subtotal.Amount() {
+		return subtotal
+	}
+	return discount
+}
+
+// Discounted spreads the discount over the amounts in proportion to their
+// price, so tax is charged on what the customer pays
+func (c AppliedCoupon) Discounted(amounts []TaxableAmount, currency string) []TaxableAmount {
+	prices := make([]valueobjects.Money, len(amounts))
+	weights := make([]int64, len(amounts))
+	var subtotal int64
+	for i, a := range amounts {
+		price, err := valueobjects.NewMoney(a.Cents, currency)
+		if err != nil {
+			return amounts
+		}
+		prices[i], weights[i] = price, a.Cents
+		subtotal += a.Cents
+	}
+	total, err := valueobjects.NewMoney(subtotal, currency)
+	if err != nil {
+		return amounts
+	}
+	discount := c.DiscountOn(total)
+	if discount.Amount() == 0 {
+		return amounts
+	}
+
+	out := make([]TaxableAmount, len(amounts))
+	for i, share := range splitInProportion(discount, weights) {
+		paid, _ := prices[i].Subtract(share)
+		out[i] = TaxableAmount{Rate: amounts[i].Rate, Cents: paid.Amount()}
+	}
+	return out
+}
+
+// splitInProportion splits total into shares in proportion to the weights,
+// to the cent. Each share is taken from what is left in proportion to the
+// weight left, so no share exceeds what it would be without rounding by
+// more than a cent and the shares add up to total; with no weight at all
+// the last share takes everything.
+func splitInProportion(total valueobjects.Money, weights []int64) []valueobjects.Money {
+	var rest int64
+	for _, w := range weights {
+		rest += w
+	}
+
+	shares := make([]valueobjects.Money, len(weights))
+	remaining := total
+	for i, w := range weights {
+		var rate float64
+		switch {
+		case rest > 0:
+			rate = float64(w) / float64(rest)
+		case i == len(weights)-1:
+			rate = 1
+		}
+		shares[i], _ = remaining.Multiply(rate)
+		remaining, _ = remaining.Subtract(shares[i])
+		rest -= w
+	}
+	return shares
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+func TestSplitInProportionAddsUpToTheTotal(t *testing.T) {
+	property := func(total uint32, raw []uint16) bool {
+		if len(raw) == 0 {
+			return true
+		}
+		weights := make([]int64, len(raw))
+		for i, w := range raw {
+			weights[i] = int64(w)
+		}
+		money, _ := valueobjects.NewMoney(int64(total), "EUR")
+
+		var sum int64
+		for _, share := range splitInProportion(money, weights) {
+			if share.Amount() < 0 {
+				return false
+			}
+			sum += share.Amount()
+		}
+		return sum == money.Amount()
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitInProportionFollowsTheWeights(t *testing.T) {
+	total, _ := valueobjects.NewMoney(300, "EUR")
+	tests := []struct {
+		weights []int64
+		want    []int64
+	}{
+		{[]int64{200, 150}, []int64{171, 129}},
+		{[]int64{1, 1, 1}, []int64{100, 100, 100}},
+		{[]int64{0, 0}, []int64{0, 300}},
+		{[]int64{1, 0}, []int64{300, 0}},
+	}
+	for _, tt := range tests {
+		shares := splitInProportion(total, tt.weights)
+		for i, share := range shares {
+			if share.Amount() != tt.want[i] {
+				t.Errorf("weights %v: share %d = %d, want %d", tt.weights, i, share.Amount(), tt.want[i])
+			}
+		}
+	}
+}
+
+func TestAppliedCouponDiscountOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		coupon AppliedCoupon
+		items  int64
+		want   int64
+	}{
+		{"percentage", NewAppliedCoupon("TEN", 10, 0), 500, 50},
+		{"percentage rounded to the cent", NewAppliedCoupon("TEN", 10, 0), 255, 26},
+		{"amount", NewAppliedCoupon("FIFTY", 0, 50), 500, 50},
+		{"capped at the items", NewAppliedCoupon("FIFTY", 0, 50), 30, 30},
+		{"nothing off nothing", NewAppliedCoupon("TEN", 10, 0), 0, 0},
+		{"no percentage off items beyond exact cents", NewAppliedCoupon("TEN", 10, 0), 1<<53 + 2, 0},
+		{"amount off items beyond exact cents", NewAppliedCoupon("FIFTY", 0, 50), 1<<53 + 2, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtotal, _ := valueobjects.NewMoney(tt.items, "EUR")
+			if got := tt.coupon.DiscountOn(subtotal); got.Amount() != tt.want {
+				t.Errorf("DiscountOn(%d) = %d, want %d", tt.items, got.Amount(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAppliedCouponDiscountedTakesOffTheDiscount(t *testing.T) {
+	property := func(percent uint8, raw []uint16) bool {
+		coupon := NewAppliedCoupon("SALE", int(percent%100)+1, 0)
+		amounts := make([]TaxableAmount, len(raw))
+		var before int64
+		for i, cents := range raw {
+			amounts[i] = TaxableAmount{Cents: int64(cents)}
+			before += int64(cents)
+		}
+		subtotal, _ := valueobjects.NewMoney(before, "EUR")
+		discount := coupon.DiscountOn(subtotal).Amount()
+
+		var after int64
+		for i, a := range coupon.Discounted(amounts, "EUR") {
+			if a.Cents < 0 || a.Cents > amounts[i].Cents {
+				return false
+			}
+			after += a.Cents
+		}
+		return before-after == discount
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
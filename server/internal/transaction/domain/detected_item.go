@@ -2,13 +2,18 @@ package domain
 
 import "github.com/vending-machine/server/internal/shared/valueobjects"
 
-// DetectedItem is a value object representing a detected SKU
+// DetectedItem is a value object representing a line of identical detected
+// SKUs
 type DetectedItem struct {
 	skuID      valueobjects.SKUID
 	code       string
 	name       string
 	confidence float64
-	price      valueobjects.Money
+	price      valueobjects.Money // of one unit
+	quantity   int
+	bundle     string // code of the bundle the item was sold in, if any
+	review     ItemReview
+	boxes      []BoundingBox // where the units were seen, for those the device located
 }
 
 func NewDetectedItem(skuID valueobjects.SKUID, code, name string, confidence float64, price valueobjects.Money) DetectedItem {
@@ -18,11 +23,86 @@ func NewDetectedItem(skuID valueobjects.SKUID, code, name string, confidence flo
 		name:       name,
 		confidence: confidence,
 		price:      price,
+		quantity:   1,
 	}
 }
 
-func (d DetectedItem) SKUID() valueobjects.SKUID  { return d.skuID }
-func (d DetectedItem) Code() string               { return d.code }
-func (d DetectedItem) Name() string               { return d.name }
-func (d DetectedItem) Confidence() float64        { return d.confidence }
-func (d DetectedItem) Price() valueobjects.Money  { return d.price }
+func (d DetectedItem) SKUID() valueobjects.SKUID { return d.skuID }
+func (d DetectedItem) Code() string              { return d.code }
+func (d DetectedItem) Name() string              { return d.name }
+func (d DetectedItem) Confidence() float64       { return d.confidence }
+func (d DetectedItem) Price() valueobjects.Money { return d.price }
+func (d DetectedItem) Quantity() int             { return d.quantity }
+func (d DetectedItem) Bundle() string            { return d.bundle }
+func (d DetectedItem) Review() ItemReview        { return d.review }
+func (d DetectedItem) Boxes() []BoundingBox      { return append([]BoundingBox{}, d.boxes...) }
+
+// LineTotal is the price of all units of the line
+func (d DetectedItem) LineTotal() valueobjects.Money {
+	total, _ := valueobjects.NewMoney(d.price.Amount()*int64(d.quantity), d.price.Currency())
+	return total
+}
+
+// InBundle returns the item as sold in a bundle, priced at its share of the
+// bundle price
+func (d DetectedItem) InBundle(bundleCode string, price valueobjects.Money) DetectedItem {
+	d.bundle = bundleCode
+	d.price = price
+	return d
+}
+
+// WithQuantity returns the line holding the given number of units; fewer
+// than one is taken as one
+func (d DetectedItem) WithQuantity(quantity int) DetectedItem {
+	if quantity < 1 {
+		quantity = 1
+	}
+	d.quantity = quantity
+	return d
+}
+
+// WithReview returns the line in the given review state
+func (d DetectedItem) WithReview(review ItemReview) DetectedItem {
+	d.review = review
+	return d
+}
+
+// WithBoxes returns the line with the units located at the given boxes
+func (d DetectedItem) WithBoxes(boxes []BoundingBox) DetectedItem {
+	d.boxes = append([]BoundingBox{}, boxes...)
+	return d
+}
+
+// AggregateItems merges units of the same SKU sold at the same price, and in
+// the same bundle if any, into one line each, in the order the SKUs were
+// first detected. A merged line keeps the lowest confidence of its units and
+// the boxes of all of them, and awaits review when any of them does.
+func AggregateItems(items []DetectedItem) []DetectedItem {
+	type lineKey struct {
+		skuID  valueobjects.SKUID
+		bundle string
+		cents  int64
+		cur    string
+	}
+
+	lines := make([]DetectedItem, 0, len(items))
+	index := make(map[lineKey]int, len(items))
+	for _, item := range items {
+		key := lineKey{item.skuID, item.bundle, item.price.Amount(), item.price.Currency()}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(lines)
+			lines = append(lines, item)
+			continue
+		}
+		lines[i].quantity += item.quantity
+		lines[i].boxes = append(lines[i].Boxes(), item.boxes...)
+		if item.confidence < lines[i].confidence {
+			lines[i].confidence = item.confidence
+		}
+		if item.review == ItemReviewPending || lines[i].review == ItemReviewNone {
+			lines[i].review = item.review
+		}
+	}
+	return lines
+}
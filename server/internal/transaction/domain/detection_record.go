@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Where a detection came from
+const (
+	DetectionSourceDevice            = "device"
+	DetectionSourceOfflineSync       = "offline_sync"
+	DetectionSourceCloudVerification = "cloud_verification"
+	DetectionSourceAttendant         = "attendant"
+)
+
+// Detection outcomes kept in the audit log
+const (
+	DetectionOutcomeApplied  = "applied"
+	DetectionOutcomeReplayed = "replayed" // answered from an earlier submission with the same key
+	DetectionOutcomeRejected = "rejected"
+)
+
+// DetectionRecord is an entry of the detection audit log: a submission as the
+// device sent it and what the server decided on it. A session's state only
+// holds the latest basket, so disputes and model debugging read the log.
+type DetectionRecord struct {
+	ID         int64
+	SessionID  string
+	DeviceID   string
+	Source     string
+	Payload    []byte // the submission without its image, serialized by the application
+	Outcome    string
+	Decision   []byte // the result or the reason for the rejection, serialized by the application
+	ReceivedAt time.Time
+}
@@ -0,0 +1,244 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen DisputeStatus = "open"
+	// DisputeStatusWon is a dispute decided for the operator; the charge stands
+	DisputeStatusWon DisputeStatus = "won"
+	// DisputeStatusLost is a dispute decided for the customer; the charge is reversed
+	DisputeStatusLost DisputeStatus = "lost"
+)
+
+// ParseDisputeOutcome validates how a dispute was resolved
+func ParseDisputeOutcome(raw string) (DisputeStatus, error) {
+	switch DisputeStatus(raw) {
+	case DisputeStatusWon, DisputeStatusLost:
+		return DisputeStatus(raw), nil
+	default:
+		return "", ErrUnknownDisputeOutcome
+	}
+}
+
+// DisputeEvidenceKind names what was attached to a dispute
+type DisputeEvidenceKind string
+
+const (
+	// DisputeEvidenceDetections are the session's entries in the detection audit log
+	DisputeEvidenceDetections DisputeEvidenceKind = "detections"
+	// DisputeEvidenceImage is the last image the device sent of the basket
+	DisputeEvidenceImage DisputeEvidenceKind = "image"
+)
+
+// ParseDisputeEvidenceKind validates the kind of evidence to attach
+func ParseDisputeEvidenceKind(raw string) (DisputeEvidenceKind, error) {
+	switch DisputeEvidenceKind(raw) {
+	case DisputeEvidenceDetections, DisputeEvidenceImage:
+		return DisputeEvidenceKind(raw), nil
+	default:
+		return "", ErrUnknownDisputeEvidence
+	}
+}
+
+// DisputeEvidence records evidence attached to a dispute. Detections refer
+// to the audit log entries of the session by ID; an image is the session's
+// image, ImageBytes long when it was attached.
+type DisputeEvidence struct {
+	Kind         DisputeEvidenceKind
+	DetectionIDs []int64
+	ImageBytes   int
+	AttachedBy   string
+	Note         string
+	At           time.Time
+}
+
+// DisputeNote is an annotation made by operator staff while working a dispute
+type DisputeNote struct {
+	ActorID string
+	Note    string
+	At      time.Time
+}
+
+// Dispute is the aggregate root for a customer's chargeback of a completed
+// sale. It is opened against the session's transaction, collects notes and
+// evidence while open and is resolved by a finance team member.
+type Dispute struct {
+	id             valueobjects.DisputeID
+	sessionID      valueobjects.SessionID
+	transactionID  valueobjects.TransactionID
+	amount         valueobjects.Money
+	reason         string
+	providerRef    string // the payment provider's ID of the chargeback, if any
+	status         DisputeStatus
+	openedBy       string
+	resolvedBy     string
+	resolutionNote string
+	notes          []DisputeNote
+	evidence       []DisputeEvidence
+	createdAt      time.Time
+	resolvedAt     *time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// OpenDispute records a dispute of the transaction for up to its total
+func OpenDispute(
+	transaction *Transaction,
+	amount valueobjects.Money,
+	reason string,
+	providerRef string,
+	openedBy Actor,
+) (*Dispute, error) {
+	if openedBy.IsZero() {
+		return nil, ErrActorRequired
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrDisputeReasonMissing
+	}
+	if transaction.Status() != TransactionStatusCompleted {
+		return nil, ErrDisputeSessionNotCompleted
+	}
+	total := transaction.Total()
+	if amount.Amount() <= 0 {
+		return nil, ErrInvalidDisputeAmount
+	}
+	if amount.Currency() != total.Currency() {
+		return nil, ErrDisputeCurrencyMismatch
+	}
+	if amount.Amount() > total.Amount() {
+		return nil, ErrDisputeExceedsTotal
+	}
+
+	d := &Dispute{
+		id:            valueobjects.NewDisputeID(),
+		sessionID:     transaction.SessionID(),
+		transactionID: transaction.ID(),
+		amount:        amount,
+		reason:        reason,
+		providerRef:   providerRef,
+		status:        DisputeStatusOpen,
+		openedBy:      openedBy.ID(),
+		createdAt:     time.Now().UTC(),
+	}
+	d.domainEvents = append(d.domainEvents, NewDisputeOpened(d.id, d.sessionID, d.transactionID, amount, openedBy.ID()))
+
+	return d, nil
+}
+
+// ReconstituteDispute rebuilds a Dispute from persistence
+func ReconstituteDispute(
+	id valueobjects.DisputeID,
+	sessionID valueobjects.SessionID,
+	transactionID valueobjects.TransactionID,
+	amount valueobjects.Money,
+	reason, providerRef string,
+	status DisputeStatus,
+	openedBy, resolvedBy, resolutionNote string,
+	notes []DisputeNote,
+	evidence []DisputeEvidence,
+	createdAt time.Time,
+	resolvedAt *time.Time,
+) *Dispute {
+	return &Dispute{
+		id:             id,
+		sessionID:      sessionID,
+		transactionID:  transactionID,
+		amount:         amount,
+		reason:         reason,
+		providerRef:    providerRef,
+		status:         status,
+		openedBy:       openedBy,
+		resolvedBy:     resolvedBy,
+		resolutionNote: resolutionNote,
+		notes:          notes,
+		evidence:       evidence,
+		createdAt:      createdAt,
+		resolvedAt:     resolvedAt,
+	}
+}
+
+// Getters
+func (d *Dispute) ID() valueobjects.DisputeID                { return d.id }
+func (d *Dispute) SessionID() valueobjects.SessionID         { return d.sessionID }
+func (d *Dispute) TransactionID() valueobjects.TransactionID { return d.transactionID }
+func (d *Dispute) Amount() valueobjects.Money                { return d.amount }
+func (d *Dispute) Reason() string                            { return d.reason }
+func (d *Dispute) ProviderRef() string                       { return d.providerRef }
+func (d *Dispute) Status() DisputeStatus                     { return d.status }
+func (d *Dispute) OpenedBy() string                          { return d.openedBy }
+func (d *Dispute) ResolvedBy() string                        { return d.resolvedBy }
+func (d *Dispute) ResolutionNote() string                    { return d.resolutionNote }
+func (d *Dispute) Notes() []DisputeNote                      { return append([]DisputeNote{}, d.notes...) }
+func (d *Dispute) Evidence() []DisputeEvidence               { return append([]DisputeEvidence{}, d.evidence...) }
+func (d *Dispute) CreatedAt() time.Time                      { return d.createdAt }
+func (d *Dispute) ResolvedAt() *time.Time                    { return d.resolvedAt }
+func (d *Dispute) IsOpen() bool                              { return d.status == DisputeStatusOpen }
+
+// Business methods
+
+// Annotate adds a note to the dispute; resolved disputes can still be
+// annotated, e.g. when the provider reports the final settlement
+func (d *Dispute) Annotate(actor Actor, note string) error {
+	if actor.IsZero() {
+		return ErrActorRequired
+	}
+	if strings.TrimSpace(note) == "" {
+		return ErrDisputeNoteMissing
+	}
+	d.notes = append(d.notes, DisputeNote{ActorID: actor.ID(), Note: note, At: time.Now().UTC()})
+	return nil
+}
+
+// AttachEvidence adds evidence to an open dispute
+func (d *Dispute) AttachEvidence(actor Actor, evidence DisputeEvidence) error {
+	if actor.IsZero() {
+		return ErrActorRequired
+	}
+	if !d.IsOpen() {
+		return ErrDisputeNotOpen
+	}
+	evidence.AttachedBy = actor.ID()
+	evidence.At = time.Now().UTC()
+	d.evidence = append(d.evidence, evidence)
+	return nil
+}
+
+// Resolve closes an open dispute as won or lost. Only the finance team
+// resolves disputes, as the outcome moves money.
+func (d *Dispute) Resolve(resolver Actor, outcome DisputeStatus, note string) error {
+	if resolver.IsZero() {
+		return ErrActorRequired
+	}
+	if !d.IsOpen() {
+		return ErrDisputeNotOpen
+	}
+	if !resolver.HasRole(RoleFinance) {
+		return ErrDisputeResolverNotAuthorized
+	}
+	if outcome != DisputeStatusWon && outcome != DisputeStatusLost {
+		return ErrUnknownDisputeOutcome
+	}
+
+	now := time.Now().UTC()
+	d.status = outcome
+	d.resolvedBy = resolver.ID()
+	d.resolutionNote = note
+	d.resolvedAt = &now
+	d.domainEvents = append(d.domainEvents, NewDisputeResolved(d.id, d.sessionID, d.amount, outcome, resolver.ID()))
+	return nil
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (d *Dispute) PullEvents() []events.DomainEvent {
+	evts := d.domainEvents
+	d.domainEvents = nil
+	return evts
+}
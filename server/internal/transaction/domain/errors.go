@@ -1,12 +1,99 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrSessionNotFound         = errors.New("session not found")
-	ErrInvalidDeviceID         = errors.New("invalid device ID")
-	ErrSessionNotActive        = errors.New("session is not active")
-	ErrSessionExpired          = errors.New("session has expired")
-	ErrSessionAlreadyCompleted = errors.New("session already completed")
-	ErrNoItemsDetected         = errors.New("no items detected in session")
+	ErrSessionNotFound           = errors.New("session not found")
+	ErrInvalidDeviceID           = errors.New("invalid device ID")
+	ErrSessionNotActive          = errors.New("session is not active")
+	ErrSessionExpired            = errors.New("session has expired")
+	ErrSessionAlreadyCompleted   = errors.New("session already completed")
+	ErrNoItemsDetected           = errors.New("no items detected in session")
+	ErrPaymentNotCaptured        = errors.New("payment has not been captured")
+	ErrSessionAwaitingPayment    = errors.New("session is awaiting the outcome of its payment")
+	ErrSessionNotAwaitingPayment = errors.New("session is not awaiting payment")
+	ErrSessionNotCompleted       = errors.New("session is not completed")
+	// ErrSessionConflict is returned when the session changed since it was
+	// loaded; the whole operation can be retried against the new state
+	ErrSessionConflict = errors.New("session was changed concurrently, retry the request")
+	// ErrSessionArchived is returned when saving a session that was moved to
+	// the archive; like any ended session it is no longer active
+	ErrSessionArchived = fmt.Errorf("%w: the session is archived", ErrSessionNotActive)
+
+	ErrInvalidSessionLifetime  = errors.New("session expiration must be positive and no longer than the maximum session duration")
+	ErrInvalidSessionExtension = errors.New("session extension must be positive")
+	ErrSessionExtensionLimit   = errors.New("session has reached its maximum duration")
+
+	ErrTransactionNotFound            = errors.New("transaction not found")
+	ErrTransactionSessionNotCompleted = errors.New("only completed sessions record a transaction")
+
+	ErrRefundNotFound              = errors.New("refund not found")
+	ErrActorRequired               = errors.New("acting user is required")
+	ErrRefundSessionNotCompleted   = errors.New("only completed sessions can be refunded")
+	ErrInvalidRefundAmount         = errors.New("refund amount must be positive")
+	ErrRefundCurrencyMismatch      = errors.New("refund currency does not match session")
+	ErrRefundExceedsTotal          = errors.New("refund exceeds remaining session total")
+	ErrRefundNotPending            = errors.New("refund is not pending approval")
+	ErrRefundApproverNotAuthorized = errors.New("approver lacks the finance role")
+	ErrRefundSelfApproval          = errors.New("refunds cannot be approved by their requester")
+
+	ErrDisputeNotFound              = errors.New("dispute not found")
+	ErrDisputeSessionNotCompleted   = errors.New("only completed sessions can be disputed")
+	ErrDisputeReasonMissing         = errors.New("a reason is required to open a dispute")
+	ErrInvalidDisputeAmount         = errors.New("dispute amount must be positive")
+	ErrDisputeCurrencyMismatch      = errors.New("dispute currency does not match session")
+	ErrDisputeExceedsTotal          = errors.New("dispute exceeds the session total")
+	ErrDisputeAlreadyOpen           = errors.New("the session already has an open dispute")
+	ErrDisputeNotOpen               = errors.New("dispute is already resolved")
+	ErrDisputeNoteMissing           = errors.New("a dispute note cannot be empty")
+	ErrDisputeResolverNotAuthorized = errors.New("resolver lacks the finance role")
+	ErrUnknownDisputeOutcome        = errors.New("unknown dispute outcome")
+	ErrUnknownDisputeEvidence       = errors.New("unknown dispute evidence kind")
+	ErrNoDisputeEvidence            = errors.New("the session has no such evidence to attach")
+
+	ErrSessionNoteMissing = errors.New("a session note cannot be empty")
+	ErrSessionNoteTooLong = errors.New("a session note is limited to 2000 characters")
+
+	ErrSyncRecordNotFound    = errors.New("sync record not found")
+	ErrClientIDRequired      = errors.New("client ID is required")
+	ErrUnknownOfflineEntry   = errors.New("unknown offline entry type")
+	ErrSessionDeviceMismatch = errors.New("session belongs to another device")
+	ErrDetectionSuperseded   = errors.New("a newer detection was already applied")
+
+	ErrProcessedDetectionNotFound = errors.New("processed detection not found")
+	ErrInvalidIdempotencyKey      = errors.New("idempotency key is limited to 100 characters")
+	ErrIdempotencyKeyReused       = errors.New("idempotency key was already used for another session")
+
+	ErrStaleDetectionFrame  = errors.New("detection frame is not newer than the last one applied")
+	ErrInvalidFrameSequence = errors.New("incremental detections need a positive frame sequence")
+	ErrItemNotInBasket      = errors.New("removed item is not in the basket")
+
+	ErrNoWeightMismatch            = errors.New("session has no weight mismatch to resolve")
+	ErrWeightMismatchUnresolved    = errors.New("weight mismatch must be resolved by an attendant before checkout")
+	ErrWeightOverrideReasonMissing = errors.New("a reason is required to override a weight mismatch")
+	ErrUnknownWeightDecision       = errors.New("unknown weight override decision")
+
+	ErrNoItemToReview         = errors.New("no item of this SKU awaits review")
+	ErrItemReviewPending      = errors.New("low-confidence items must be reviewed by an attendant before checkout")
+	ErrUnknownItemDecision    = errors.New("unknown item review decision")
+	ErrItemReplacementMissing = errors.New("a replacement SKU is required to replace an item")
+	ErrUnknownReplacementSKU  = errors.New("replacement SKU is not in the catalog")
+
+	ErrUnknownWalkawaySignal = errors.New("unknown walkaway signal")
+
+	ErrFraudSuspected   = errors.New("session is blocked as suspected fraud")
+	ErrInvalidFraudRule = errors.New("invalid fraud rule")
+
+	ErrCouponNotFound         = errors.New("coupon not found")
+	ErrCouponExpired          = errors.New("coupon has expired")
+	ErrCouponDisabled         = errors.New("coupon is no longer valid")
+	ErrCouponExhausted        = errors.New("coupon has been used up")
+	ErrCouponBasketTooSmall   = errors.New("basket does not reach the coupon's minimum")
+	ErrCouponCurrencyMismatch = errors.New("coupon currency does not match the basket")
+
+	ErrSessionImageNotFound         = errors.New("session image not found")
+	ErrReconciliationReportNotFound = errors.New("reconciliation report not found")
 )
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/vending-machine/server/internal/shared/events"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
@@ -72,3 +74,307 @@ func NewSessionCancelled(sessionID valueobjects.SessionID, reason string) Sessio
 }
 
 func (SessionCancelled) EventName() string { return "SessionCancelled" }
+
+// SessionExtended is raised when a session's expiry is pushed back
+type SessionExtended struct {
+	events.BaseEvent
+	SessionID valueobjects.SessionID
+	ExpiresAt time.Time
+}
+
+func NewSessionExtended(sessionID valueobjects.SessionID, expiresAt time.Time) SessionExtended {
+	return SessionExtended{
+		BaseEvent: events.NewBaseEvent(),
+		SessionID: sessionID,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func (SessionExtended) EventName() string { return "SessionExtended" }
+
+// WeightOverridden is raised when an attendant resolves a session's weight mismatch
+type WeightOverridden struct {
+	events.BaseEvent
+	SessionID valueobjects.SessionID
+	ActorID   string
+	Decision  WeightDecision
+	Reason    string
+}
+
+func NewWeightOverridden(sessionID valueobjects.SessionID, override WeightOverride) WeightOverridden {
+	return WeightOverridden{
+		BaseEvent: events.NewBaseEvent(),
+		SessionID: sessionID,
+		ActorID:   override.ActorID(),
+		Decision:  override.Decision(),
+		Reason:    override.Reason(),
+	}
+}
+
+func (WeightOverridden) EventName() string { return "WeightOverridden" }
+
+// ItemReviewed is raised when an attendant reviews a line detected below the
+// confidence threshold; Replacement is the SKU it was replaced with, if any
+type ItemReviewed struct {
+	events.BaseEvent
+	SessionID   valueobjects.SessionID
+	SKUCode     string
+	Decision    ItemDecision
+	Replacement string
+	ActorID     string
+}
+
+func NewItemReviewed(sessionID valueobjects.SessionID, code string, decision ItemDecision, replacement, actorID string) ItemReviewed {
+	return ItemReviewed{
+		BaseEvent:   events.NewBaseEvent(),
+		SessionID:   sessionID,
+		SKUCode:     code,
+		Decision:    decision,
+		Replacement: replacement,
+		ActorID:     actorID,
+	}
+}
+
+func (ItemReviewed) EventName() string { return "ItemReviewed" }
+
+// FraudSuspected is raised when a fraud rule matches a session
+type FraudSuspected struct {
+	events.BaseEvent
+	SessionID valueobjects.SessionID
+	DeviceID  valueobjects.DeviceID
+	UserID    string
+	Rule      string
+	Action    FraudAction
+	Reason    string
+	Stage     FraudStage
+}
+
+func NewFraudSuspected(sessionID valueobjects.SessionID, deviceID valueobjects.DeviceID, userID string, finding FraudFinding) FraudSuspected {
+	return FraudSuspected{
+		BaseEvent: events.NewBaseEvent(),
+		SessionID: sessionID,
+		DeviceID:  deviceID,
+		UserID:    userID,
+		Rule:      finding.Rule(),
+		Action:    finding.Action(),
+		Reason:    finding.Reason(),
+		Stage:     finding.Stage(),
+	}
+}
+
+func (FraudSuspected) EventName() string { return "FraudSuspected" }
+
+// WalkawayDetected is raised when a session is cancelled because the customer
+// left the device with items and did not pay, for fraud analytics
+type WalkawayDetected struct {
+	events.BaseEvent
+	SessionID  valueobjects.SessionID
+	DeviceID   valueobjects.DeviceID
+	UserID     string
+	Signal     WalkawaySignal
+	SignaledAt time.Time
+	ItemCount  int
+	Unpaid     valueobjects.Money
+}
+
+func NewWalkawayDetected(s *Session) WalkawayDetected {
+	return WalkawayDetected{
+		BaseEvent:  events.NewBaseEvent(),
+		SessionID:  s.ID(),
+		DeviceID:   s.DeviceID(),
+		UserID:     s.UserID(),
+		Signal:     s.Walkaway().Signal(),
+		SignaledAt: s.Walkaway().SignaledAt(),
+		ItemCount:  s.UnitCount(),
+		Unpaid:     s.TotalAmount(),
+	}
+}
+
+func (WalkawayDetected) EventName() string { return "WalkawayDetected" }
+
+// CouponApplied is raised when a coupon is applied to a session's basket
+type CouponApplied struct {
+	events.BaseEvent
+	SessionID     valueobjects.SessionID
+	Code          string
+	DiscountCents int64
+}
+
+func NewCouponApplied(sessionID valueobjects.SessionID, code string, discountCents int64) CouponApplied {
+	return CouponApplied{
+		BaseEvent:     events.NewBaseEvent(),
+		SessionID:     sessionID,
+		Code:          code,
+		DiscountCents: discountCents,
+	}
+}
+
+func (CouponApplied) EventName() string { return "CouponApplied" }
+
+// PaymentFailed is raised when a payment the session was awaiting failed and
+// the customer is back to paying
+type PaymentFailed struct {
+	events.BaseEvent
+	SessionID valueobjects.SessionID
+	Reason    string
+}
+
+func NewPaymentFailed(sessionID valueobjects.SessionID, reason string) PaymentFailed {
+	return PaymentFailed{
+		BaseEvent: events.NewBaseEvent(),
+		SessionID: sessionID,
+		Reason:    reason,
+	}
+}
+
+func (PaymentFailed) EventName() string { return "PaymentFailed" }
+
+type RefundRequested struct {
+	events.BaseEvent
+	RefundID    valueobjects.RefundID
+	SessionID   valueobjects.SessionID
+	Amount      valueobjects.Money
+	RequestedBy string
+}
+
+func NewRefundRequested(refundID valueobjects.RefundID, sessionID valueobjects.SessionID, amount valueobjects.Money, requestedBy string) RefundRequested {
+	return RefundRequested{
+		BaseEvent:   events.NewBaseEvent(),
+		RefundID:    refundID,
+		SessionID:   sessionID,
+		Amount:      amount,
+		RequestedBy: requestedBy,
+	}
+}
+
+func (RefundRequested) EventName() string { return "RefundRequested" }
+
+type RefundApproved struct {
+	events.BaseEvent
+	RefundID   valueobjects.RefundID
+	SessionID  valueobjects.SessionID
+	Amount     valueobjects.Money
+	ApprovedBy string
+}
+
+func NewRefundApproved(refundID valueobjects.RefundID, sessionID valueobjects.SessionID, amount valueobjects.Money, approvedBy string) RefundApproved {
+	return RefundApproved{
+		BaseEvent:  events.NewBaseEvent(),
+		RefundID:   refundID,
+		SessionID:  sessionID,
+		Amount:     amount,
+		ApprovedBy: approvedBy,
+	}
+}
+
+func (RefundApproved) EventName() string { return "RefundApproved" }
+
+type RefundRejected struct {
+	events.BaseEvent
+	RefundID   valueobjects.RefundID
+	SessionID  valueobjects.SessionID
+	RejectedBy string
+	Reason     string
+}
+
+func NewRefundRejected(refundID valueobjects.RefundID, sessionID valueobjects.SessionID, rejectedBy, reason string) RefundRejected {
+	return RefundRejected{
+		BaseEvent:  events.NewBaseEvent(),
+		RefundID:   refundID,
+		SessionID:  sessionID,
+		RejectedBy: rejectedBy,
+		Reason:     reason,
+	}
+}
+
+func (RefundRejected) EventName() string { return "RefundRejected" }
+
+type DisputeOpened struct {
+	events.BaseEvent
+	DisputeID     valueobjects.DisputeID
+	SessionID     valueobjects.SessionID
+	TransactionID valueobjects.TransactionID
+	Amount        valueobjects.Money
+	OpenedBy      string
+}
+
+func NewDisputeOpened(disputeID valueobjects.DisputeID, sessionID valueobjects.SessionID, transactionID valueobjects.TransactionID, amount valueobjects.Money, openedBy string) DisputeOpened {
+	return DisputeOpened{
+		BaseEvent:     events.NewBaseEvent(),
+		DisputeID:     disputeID,
+		SessionID:     sessionID,
+		TransactionID: transactionID,
+		Amount:        amount,
+		OpenedBy:      openedBy,
+	}
+}
+
+func (DisputeOpened) EventName() string { return "DisputeOpened" }
+
+type DisputeResolved struct {
+	events.BaseEvent
+	DisputeID  valueobjects.DisputeID
+	SessionID  valueobjects.SessionID
+	Amount     valueobjects.Money
+	Outcome    DisputeStatus // won or lost
+	ResolvedBy string
+}
+
+func NewDisputeResolved(disputeID valueobjects.DisputeID, sessionID valueobjects.SessionID, amount valueobjects.Money, outcome DisputeStatus, resolvedBy string) DisputeResolved {
+	return DisputeResolved{
+		BaseEvent:  events.NewBaseEvent(),
+		DisputeID:  disputeID,
+		SessionID:  sessionID,
+		Amount:     amount,
+		Outcome:    outcome,
+		ResolvedBy: resolvedBy,
+	}
+}
+
+func (DisputeResolved) EventName() string { return "DisputeResolved" }
+
+type SessionsReconciled struct {
+	events.BaseEvent
+	Day                     string
+	SessionsChecked         int
+	SessionsWithDiscrepancy int
+	UnderchargedCents       int64
+	OverchargedCents        int64
+}
+
+func NewSessionsReconciled(day string, checked, withDiscrepancy int, underchargedCents, overchargedCents int64) SessionsReconciled {
+	return SessionsReconciled{
+		BaseEvent:               events.NewBaseEvent(),
+		Day:                     day,
+		SessionsChecked:         checked,
+		SessionsWithDiscrepancy: withDiscrepancy,
+		UnderchargedCents:       underchargedCents,
+		OverchargedCents:        overchargedCents,
+	}
+}
+
+func (SessionsReconciled) EventName() string { return "SessionsReconciled" }
+
+type TransactionRecorded struct {
+	events.BaseEvent
+	TransactionID valueobjects.TransactionID
+	SessionID     valueobjects.SessionID
+	DeviceID      valueobjects.DeviceID
+	Total         valueobjects.Money
+	Units         int
+	PaymentRef    string
+}
+
+func NewTransactionRecorded(t *Transaction) TransactionRecorded {
+	return TransactionRecorded{
+		BaseEvent:     events.NewBaseEvent(),
+		TransactionID: t.ID(),
+		SessionID:     t.SessionID(),
+		DeviceID:      t.DeviceID(),
+		Total:         t.Total(),
+		Units:         t.UnitCount(),
+		PaymentRef:    t.PaymentRef(),
+	}
+}
+
+func (TransactionRecorded) EventName() string { return "TransactionRecorded" }
@@ -0,0 +1,39 @@
+package domain
+
+// ExperimentTag is a value object recording the price experiment variant a
+// session was assigned at start. The variant's prices are kept on the tag so
+// the basket is priced consistently even if the experiment ends mid-session.
+type ExperimentTag struct {
+	experimentID string
+	variant      string
+	prices       map[string]int64 // SKU code -> price in cents
+}
+
+func NewExperimentTag(experimentID, variant string, prices map[string]int64) ExperimentTag {
+	copied := make(map[string]int64, len(prices))
+	for code, cents := range prices {
+		copied[code] = cents
+	}
+	return ExperimentTag{
+		experimentID: experimentID,
+		variant:      variant,
+		prices:       copied,
+	}
+}
+
+func (t ExperimentTag) ExperimentID() string { return t.experimentID }
+func (t ExperimentTag) Variant() string      { return t.variant }
+
+func (t ExperimentTag) Prices() map[string]int64 {
+	copied := make(map[string]int64, len(t.prices))
+	for code, cents := range t.prices {
+		copied[code] = cents
+	}
+	return copied
+}
+
+// PriceFor returns the variant price for a SKU, if the variant overrides it
+func (t ExperimentTag) PriceFor(code string) (int64, bool) {
+	cents, ok := t.prices[code]
+	return cents, ok
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// FiscalRecord is a value object holding the identifiers issued by a
+// country's fiscalization system (e.g. a German TSE signature or an Italian
+// RT document number). They must be printed on the customer receipt.
+type FiscalRecord struct {
+	country          string // ISO 3166-1 alpha-2
+	provider         string
+	documentNumber   string
+	serialNumber     string // TSE serial / RT device serial
+	signature        string
+	signatureCounter int64
+	qrCode           string // data for the receipt QR code, if the country requires one
+	issuedAt         time.Time
+}
+
+func NewFiscalRecord(
+	country, provider, documentNumber, serialNumber, signature string,
+	signatureCounter int64,
+	qrCode string,
+	issuedAt time.Time,
+) FiscalRecord {
+	return FiscalRecord{
+		country:          country,
+		provider:         provider,
+		documentNumber:   documentNumber,
+		serialNumber:     serialNumber,
+		signature:        signature,
+		signatureCounter: signatureCounter,
+		qrCode:           qrCode,
+		issuedAt:         issuedAt,
+	}
+}
+
+func (f FiscalRecord) Country() string         { return f.country }
+func (f FiscalRecord) Provider() string        { return f.provider }
+func (f FiscalRecord) DocumentNumber() string  { return f.documentNumber }
+func (f FiscalRecord) SerialNumber() string    { return f.serialNumber }
+func (f FiscalRecord) Signature() string       { return f.signature }
+func (f FiscalRecord) SignatureCounter() int64 { return f.signatureCounter }
+func (f FiscalRecord) QRCode() string          { return f.qrCode }
+func (f FiscalRecord) IssuedAt() time.Time     { return f.issuedAt }
+func (f FiscalRecord) IsZero() bool            { return f.country == "" && f.documentNumber == "" }
@@ -0,0 +1,244 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FraudStage is the point of a session at which the fraud rules run
+type FraudStage string
+
+const (
+	FraudStageSubmit  FraudStage = "submit"  // a detection is submitted
+	FraudStageConfirm FraudStage = "confirm" // the customer checks out
+)
+
+// FraudAction is what happens to a session a fraud rule matches
+type FraudAction string
+
+const (
+	// FraudActionFlag marks the session for review and lets it go on
+	FraudActionFlag FraudAction = "flag"
+	// FraudActionBlock marks it and refuses its detections and checkout
+	FraudActionBlock FraudAction = "block"
+)
+
+// FraudFacts is what the fraud rules see of a session
+type FraudFacts struct {
+	Stage   FraudStage
+	Session *Session
+	At      time.Time // when the detection was received or checkout requested
+
+	// Units, ExpectedGrams and MeasuredGrams describe the detection being
+	// submitted; Weighed is unset at checkout, where no detection is weighed
+	Units         int
+	ExpectedGrams float64
+	MeasuredGrams float64
+	Weighed       bool
+
+	// RecentCancellations counts the sessions of the session's user cancelled
+	// within the cancellation window of the rules
+	RecentCancellations int
+}
+
+// FraudRule is one check of the rules engine. Evaluate returns why the
+// session looks suspicious, and false when it does not.
+type FraudRule interface {
+	Name() string
+	Evaluate(facts FraudFacts) (reason string, suspicious bool)
+}
+
+// WeightDeviationRule matches a detection whose items weigh more than
+// MaxGrams off the measured weight. A weight an attendant has vouched for is
+// not held against the session.
+type WeightDeviationRule struct {
+	MaxGrams float64
+}
+
+func (WeightDeviationRule) Name() string { return "weight_deviation" }
+
+func (r WeightDeviationRule) Evaluate(facts FraudFacts) (string, bool) {
+	if !facts.Weighed || !facts.Session.WeightOverride().IsZero() {
+		return "", false
+	}
+	deviation := math.Abs(facts.ExpectedGrams - facts.MeasuredGrams)
+	if deviation <= r.MaxGrams {
+		return "", false
+	}
+	return fmt.Sprintf("items weigh %.0fg off the measured weight", deviation), true
+}
+
+// RepeatedCancellationsRule matches sessions of a user with at least Max
+// cancelled sessions among those they started within Window
+type RepeatedCancellationsRule struct {
+	Max    int
+	Window time.Duration
+}
+
+func (RepeatedCancellationsRule) Name() string { return "repeated_cancellations" }
+
+func (r RepeatedCancellationsRule) Evaluate(facts FraudFacts) (string, bool) {
+	if facts.Session.UserID() == "" || facts.RecentCancellations < r.Max {
+		return "", false
+	}
+	return fmt.Sprintf("user cancelled %d sessions within %s", facts.RecentCancellations, r.Window), true
+}
+
+// EarlyDetectionRule matches items detected within Within of the door
+// opening, sooner than a customer can take anything. The door opens when
+// the session starts.
+type EarlyDetectionRule struct {
+	Within time.Duration
+}
+
+func (EarlyDetectionRule) Name() string { return "early_detection" }
+
+func (r EarlyDetectionRule) Evaluate(facts FraudFacts) (string, bool) {
+	if facts.Stage != FraudStageSubmit || facts.Units == 0 {
+		return "", false
+	}
+	elapsed := facts.At.Sub(facts.Session.CreatedAt())
+	if elapsed >= r.Within {
+		return "", false
+	}
+	return fmt.Sprintf("items detected %s after the door opened", elapsed.Round(time.Millisecond)), true
+}
+
+// FraudFinding is a value object recording a fraud rule that matched a session
+type FraudFinding struct {
+	rule       string
+	action     FraudAction
+	reason     string
+	stage      FraudStage
+	detectedAt time.Time
+}
+
+func NewFraudFinding(rule string, action FraudAction, reason string, stage FraudStage, detectedAt time.Time) FraudFinding {
+	return FraudFinding{
+		rule:       rule,
+		action:     action,
+		reason:     reason,
+		stage:      stage,
+		detectedAt: detectedAt,
+	}
+}
+
+func (f FraudFinding) Rule() string          { return f.rule }
+func (f FraudFinding) Action() FraudAction   { return f.action }
+func (f FraudFinding) Reason() string        { return f.reason }
+func (f FraudFinding) Stage() FraudStage     { return f.stage }
+func (f FraudFinding) DetectedAt() time.Time { return f.detectedAt }
+
+// FraudRules is the rules engine: each rule runs with the action it was
+// configured with
+type FraudRules struct {
+	rules []configuredFraudRule
+}
+
+type configuredFraudRule struct {
+	rule   FraudRule
+	action FraudAction
+}
+
+// With returns the rules with another one added
+func (r FraudRules) With(rule FraudRule, action FraudAction) FraudRules {
+	rules := append([]configuredFraudRule{}, r.rules...)
+	r.rules = append(rules, configuredFraudRule{rule: rule, action: action})
+	return r
+}
+
+// IsEmpty tells whether no rule is configured
+func (r FraudRules) IsEmpty() bool { return len(r.rules) == 0 }
+
+// CancellationWindow is how far back the user's cancellations are counted
+// for the rules, 0 when no rule needs them
+func (r FraudRules) CancellationWindow() time.Duration {
+	var window time.Duration
+	for _, c := range r.rules {
+		if rule, ok := c.rule.(RepeatedCancellationsRule); ok && rule.Window > window {
+			window = rule.Window
+		}
+	}
+	return window
+}
+
+// Evaluate returns a finding for each rule the facts match
+func (r FraudRules) Evaluate(facts FraudFacts) []FraudFinding {
+	var findings []FraudFinding
+	for _, c := range r.rules {
+		if reason, suspicious := c.rule.Evaluate(facts); suspicious {
+			findings = append(findings, NewFraudFinding(c.rule.Name(), c.action, reason, facts.Stage, facts.At))
+		}
+	}
+	return findings
+}
+
+// ParseFraudRules reads a list of rules of the form
+// "weight_deviation=50:block,repeated_cancellations=3/24h:flag,early_detection=1s:flag",
+// where the value after the rule name is its threshold: grams of deviation,
+// cancellations per window, or time since the door opened. The action
+// defaults to flag.
+func ParseFraudRules(raw string) (FraudRules, error) {
+	var rules FraudRules
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return FraudRules{}, fmt.Errorf("%w: %q", ErrInvalidFraudRule, entry)
+		}
+		threshold, rawAction, _ := strings.Cut(spec, ":")
+		action := FraudActionFlag
+		if rawAction != "" {
+			action = FraudAction(rawAction)
+		}
+		if action != FraudActionFlag && action != FraudActionBlock {
+			return FraudRules{}, fmt.Errorf("%w: %q", ErrInvalidFraudRule, entry)
+		}
+
+		rule, err := parseFraudRule(strings.TrimSpace(name), strings.TrimSpace(threshold))
+		if err != nil {
+			return FraudRules{}, fmt.Errorf("%w: %q", ErrInvalidFraudRule, entry)
+		}
+		rules = rules.With(rule, action)
+	}
+	return rules, nil
+}
+
+func parseFraudRule(name, threshold string) (FraudRule, error) {
+	switch name {
+	case WeightDeviationRule{}.Name():
+		grams, err := strconv.ParseFloat(threshold, 64)
+		if err != nil || grams < 0 {
+			return nil, ErrInvalidFraudRule
+		}
+		return WeightDeviationRule{MaxGrams: grams}, nil
+	case RepeatedCancellationsRule{}.Name():
+		rawMax, rawWindow, ok := strings.Cut(threshold, "/")
+		if !ok {
+			return nil, ErrInvalidFraudRule
+		}
+		max, err := strconv.Atoi(rawMax)
+		if err != nil || max < 1 {
+			return nil, ErrInvalidFraudRule
+		}
+		window, err := time.ParseDuration(rawWindow)
+		if err != nil || window <= 0 {
+			return nil, ErrInvalidFraudRule
+		}
+		return RepeatedCancellationsRule{Max: max, Window: window}, nil
+	case EarlyDetectionRule{}.Name():
+		within, err := time.ParseDuration(threshold)
+		if err != nil || within <= 0 {
+			return nil, ErrInvalidFraudRule
+		}
+		return EarlyDetectionRule{Within: within}, nil
+	default:
+		return nil, ErrInvalidFraudRule
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+// ItemReview is where a line stands in the attendant review of items
+// detected below the confidence threshold
+type ItemReview string
+
+const (
+	// ItemReviewNone is a line detected confidently
+	ItemReviewNone ItemReview = ""
+	// ItemReviewPending is a line awaiting an attendant
+	ItemReviewPending ItemReview = "pending"
+	// ItemReviewAccepted is a line an attendant vouched for
+	ItemReviewAccepted ItemReview = "accepted"
+)
+
+// ItemDecision is how an attendant resolved a line awaiting review
+type ItemDecision string
+
+const (
+	// ItemDecisionAccepted keeps the line as detected
+	ItemDecisionAccepted ItemDecision = "accepted"
+	// ItemDecisionReplaced replaces the line with the SKU the attendant found
+	ItemDecisionReplaced ItemDecision = "replaced"
+)
+
+// ParseItemDecision validates a decision received from the attendant
+func ParseItemDecision(raw string) (ItemDecision, error) {
+	switch ItemDecision(raw) {
+	case ItemDecisionAccepted, ItemDecisionReplaced:
+		return ItemDecision(raw), nil
+	default:
+		return "", ErrUnknownItemDecision
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// OfflineEntryKind is the type of change a device recorded while offline
+type OfflineEntryKind string
+
+const (
+	OfflineEntryDetection OfflineEntryKind = "detection" // full basket snapshot, as sent to /device/detection
+	OfflineEntryCancel    OfflineEntryKind = "cancel"    // session cancelled on the device, e.g. door closed empty-handed
+)
+
+// IsValid reports whether the device may sync entries of this kind
+func (k OfflineEntryKind) IsValid() bool {
+	return k == OfflineEntryDetection || k == OfflineEntryCancel
+}
+
+// SyncStatus is the outcome of applying one offline entry
+type SyncStatus string
+
+const (
+	SyncStatusApplied   SyncStatus = "applied"
+	SyncStatusDuplicate SyncStatus = "duplicate" // client ID already synced; the entry was not applied again
+	SyncStatusConflict  SyncStatus = "conflict"  // the session moved on while the device was offline
+	SyncStatusRejected  SyncStatus = "rejected"  // the entry can never be applied
+	SyncStatusRetry     SyncStatus = "retry"     // transient failure; the device should resend the entry
+)
+
+// IsFinal reports whether the outcome is recorded, so that resending the
+// entry is answered as a duplicate
+func (s SyncStatus) IsFinal() bool {
+	return s == SyncStatusApplied || s == SyncStatusConflict || s == SyncStatusRejected
+}
+
+// SyncRecord remembers how an offline entry was resolved, keyed by the
+// device and the client ID the device assigned to the entry
+type SyncRecord struct {
+	DeviceID   string
+	ClientID   string
+	SessionID  string
+	Kind       OfflineEntryKind
+	RecordedAt time.Time
+	Status     SyncStatus
+	Reason     string
+	SyncedAt   time.Time
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "errors"
+
+// ErrUnsupportedWallet is returned for wallet types we cannot accept
+var ErrUnsupportedWallet = errors.New("unsupported wallet type")
+
+// WalletType identifies the mobile wallet used to pay
+type WalletType string
+
+const (
+	WalletApplePay  WalletType = "apple_pay"
+	WalletGooglePay WalletType = "google_pay"
+)
+
+// ParseWalletType validates a wallet type received from the app
+func ParseWalletType(raw string) (WalletType, error) {
+	switch WalletType(raw) {
+	case WalletApplePay, WalletGooglePay:
+		return WalletType(raw), nil
+	default:
+		return "", ErrUnsupportedWallet
+	}
+}
+
+// PaymentMethod is a value object describing how a session was paid,
+// printed on receipts (e.g. "Apple Pay, Visa ending 4242")
+type PaymentMethod struct {
+	wallet WalletType
+	brand  string
+	last4  string // device account number suffix for wallets
+}
+
+func NewPaymentMethod(wallet WalletType, brand, last4 string) PaymentMethod {
+	return PaymentMethod{
+		wallet: wallet,
+		brand:  brand,
+		last4:  last4,
+	}
+}
+
+func (p PaymentMethod) Wallet() WalletType { return p.wallet }
+func (p PaymentMethod) Brand() string      { return p.brand }
+func (p PaymentMethod) Last4() string      { return p.last4 }
+func (p PaymentMethod) IsZero() bool       { return p == PaymentMethod{} }
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// MaxIdempotencyKeyLength bounds the keys devices send with their detections
+const MaxIdempotencyKeyLength = 100
+
+// ProcessedDetection remembers the result of a detection submitted with an
+// idempotency key, so that a device retrying the same frame gets the original
+// result back instead of having it processed again. Keys are scoped to the
+// device that sent them.
+type ProcessedDetection struct {
+	DeviceID    string
+	Key         string
+	SessionID   string
+	Result      []byte // the result as first returned, serialized by the application
+	ProcessedAt time.Time
+}
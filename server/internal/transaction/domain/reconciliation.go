@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// ReconciliationDayLayout is the format of a reconciliation report's day
+const ReconciliationDayLayout = "2006-01-02"
+
+// SKUComparison is what a session was charged for one SKU next to what the
+// cloud model sees on the session image
+type SKUComparison struct {
+	SKUCode        string
+	Charged        int
+	Detected       int
+	UnitPriceCents int64
+}
+
+// SessionDiscrepancy is one SKU of a session where the charge and the cloud
+// result disagree. A positive impact is revenue that was not charged
+// (undercharge, a fraud signal); a negative impact is a customer charged for
+// something the cloud model does not see (overcharge, an accuracy signal).
+type SessionDiscrepancy struct {
+	SessionID   string
+	DeviceID    string
+	SKUCode     string
+	Charged     int
+	Detected    int
+	ImpactCents int64
+	Currency    string
+}
+
+// ReconciliationLine aggregates the discrepancies of one SKU on one device
+type ReconciliationLine struct {
+	DeviceID          string
+	SKUCode           string
+	Currency          string
+	Sessions          int
+	UnderchargedUnits int
+	OverchargedUnits  int
+	UnderchargedCents int64
+	OverchargedCents  int64
+}
+
+// NetImpactCents is the revenue the device lost (positive) or overcharged (negative)
+func (l ReconciliationLine) NetImpactCents() int64 {
+	return l.UnderchargedCents - l.OverchargedCents
+}
+
+// ReconciliationReport compares one day of completed sessions, as charged from
+// the edge detections, against the current cloud model
+type ReconciliationReport struct {
+	day             string
+	modelVersion    string
+	sessionsChecked int
+	sessionsSkipped int // no stored image, or the cloud model failed on it
+	discrepancies   []SessionDiscrepancy
+	generatedAt     time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// NewReconciliationReport starts the report for the day containing the given time (UTC)
+func NewReconciliationReport(day time.Time) *ReconciliationReport {
+	return &ReconciliationReport{
+		day: day.UTC().Format(ReconciliationDayLayout),
+	}
+}
+
+// ReconstituteReconciliationReport rebuilds a ReconciliationReport from persistence
+func ReconstituteReconciliationReport(
+	day string,
+	modelVersion string,
+	sessionsChecked, sessionsSkipped int,
+	discrepancies []SessionDiscrepancy,
+	generatedAt time.Time,
+) *ReconciliationReport {
+	return &ReconciliationReport{
+		day:             day,
+		modelVersion:    modelVersion,
+		sessionsChecked: sessionsChecked,
+		sessionsSkipped: sessionsSkipped,
+		discrepancies:   discrepancies,
+		generatedAt:     generatedAt,
+	}
+}
+
+// Getters
+func (r *ReconciliationReport) Day() string            { return r.day }
+func (r *ReconciliationReport) ModelVersion() string   { return r.modelVersion }
+func (r *ReconciliationReport) SessionsChecked() int   { return r.sessionsChecked }
+func (r *ReconciliationReport) SessionsSkipped() int   { return r.sessionsSkipped }
+func (r *ReconciliationReport) GeneratedAt() time.Time { return r.generatedAt }
+
+// Discrepancies returns a copy of the per-session discrepancies
+func (r *ReconciliationReport) Discrepancies() []SessionDiscrepancy {
+	return append([]SessionDiscrepancy(nil), r.discrepancies...)
+}
+
+// SessionsWithDiscrepancy counts the sessions with at least one discrepancy
+func (r *ReconciliationReport) SessionsWithDiscrepancy() int {
+	seen := make(map[string]bool)
+	for _, d := range r.discrepancies {
+		seen[d.SessionID] = true
+	}
+	return len(seen)
+}
+
+// Lines aggregates the discrepancies per device and SKU, largest net impact first
+func (r *ReconciliationReport) Lines() []ReconciliationLine {
+	return SummarizeDiscrepancies(r.discrepancies)
+}
+
+// Business methods
+
+// RecordSession compares one completed session with the cloud result
+func (r *ReconciliationReport) RecordSession(sessionID valueobjects.SessionID, deviceID valueobjects.DeviceID, currency string, comparisons []SKUComparison) {
+	r.sessionsChecked++
+	for _, c := range comparisons {
+		if c.Charged == c.Detected {
+			continue
+		}
+		r.discrepancies = append(r.discrepancies, SessionDiscrepancy{
+			SessionID:   sessionID.String(),
+			DeviceID:    deviceID.String(),
+			SKUCode:     c.SKUCode,
+			Charged:     c.Charged,
+			Detected:    c.Detected,
+			ImpactCents: int64(c.Detected-c.Charged) * c.UnitPriceCents,
+			Currency:    currency,
+		})
+	}
+}
+
+// SkipSession counts a session that could not be re-run through the cloud model
+func (r *ReconciliationReport) SkipSession() {
+	r.sessionsSkipped++
+}
+
+// Complete stamps the report with the model version it was produced with
+func (r *ReconciliationReport) Complete(modelVersion string) {
+	r.modelVersion = modelVersion
+	r.generatedAt = time.Now().UTC()
+
+	var under, over int64
+	for _, line := range r.Lines() {
+		under += line.UnderchargedCents
+		over += line.OverchargedCents
+	}
+
+	r.domainEvents = append(r.domainEvents, NewSessionsReconciled(r.day, r.sessionsChecked, r.SessionsWithDiscrepancy(), under, over))
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (r *ReconciliationReport) PullEvents() []events.DomainEvent {
+	evts := r.domainEvents
+	r.domainEvents = nil
+	return evts
+}
+
+// SummarizeDiscrepancies aggregates discrepancies per device and SKU, largest net impact first
+func SummarizeDiscrepancies(discrepancies []SessionDiscrepancy) []ReconciliationLine {
+	type key struct{ deviceID, skuCode, currency string }
+	index := make(map[key]int)
+	var lines []ReconciliationLine
+	for _, d := range discrepancies {
+		k := key{d.DeviceID, d.SKUCode, d.Currency}
+		i, ok := index[k]
+		if !ok {
+			i = len(lines)
+			index[k] = i
+			lines = append(lines, ReconciliationLine{DeviceID: d.DeviceID, SKUCode: d.SKUCode, Currency: d.Currency})
+		}
+		line := &lines[i]
+		line.Sessions++
+		if d.Detected > d.Charged {
+			line.UnderchargedUnits += d.Detected - d.Charged
+			line.UnderchargedCents += d.ImpactCents
+		} else {
+			line.OverchargedUnits += d.Charged - d.Detected
+			line.OverchargedCents -= d.ImpactCents
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return abs(lines[i].NetImpactCents()) > abs(lines[j].NetImpactCents())
+	})
+	return lines
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPendingApproval RefundStatus = "pending_approval"
+	RefundStatusApproved        RefundStatus = "approved"
+	RefundStatusRejected        RefundStatus = "rejected"
+)
+
+// RefundAuditAction names an entry in a refund's audit trail
+type RefundAuditAction string
+
+const (
+	RefundAuditRequested    RefundAuditAction = "requested"
+	RefundAuditAutoApproved RefundAuditAction = "auto_approved"
+	RefundAuditApproved     RefundAuditAction = "approved"
+	RefundAuditRejected     RefundAuditAction = "rejected"
+)
+
+// RefundAuditEntry records who did what to a refund, and when
+type RefundAuditEntry struct {
+	Action  RefundAuditAction
+	ActorID string
+	Roles   []string
+	Note    string
+	At      time.Time
+}
+
+// RefundPolicy decides which refunds need a second pair of eyes. It looks at
+// everything refunded of a transaction, so a large refund split into small
+// requests still needs approval.
+type RefundPolicy struct {
+	approvalThresholdCents int64
+}
+
+// NewRefundPolicy creates a policy where refunds taking the transaction's
+// refunds above the threshold require approval by a finance approver
+func NewRefundPolicy(approvalThresholdCents int64) RefundPolicy {
+	return RefundPolicy{approvalThresholdCents: approvalThresholdCents}
+}
+
+func (p RefundPolicy) ApprovalThresholdCents() int64 { return p.approvalThresholdCents }
+
+func (p RefundPolicy) RequiresApproval(amount valueobjects.Money) bool {
+	return amount.Amount() > p.approvalThresholdCents
+}
+
+// Refund is the aggregate root for a (partial) refund of a completed session.
+// Refunds follow a request/approve flow with a full audit trail.
+type Refund struct {
+	id            valueobjects.RefundID
+	sessionID     valueobjects.SessionID
+	transactionID valueobjects.TransactionID
+	amount        valueobjects.Money
+	rounding      int64 // amount minus the requested amount, from the currency's rounding rule
+	reason        string
+	status        RefundStatus
+	requestedBy   string
+	decidedBy     string
+	createdAt     time.Time
+	decidedAt     *time.Time
+	audit         []RefundAuditEntry
+
+	domainEvents []events.DomainEvent
+}
+
+// RequestRefund creates a refund against the transaction of a completed
+// session. alreadyRefunded is the sum of the session's refunds that have not
+// been rejected. The requested amount is rounded like the session total was,
+// so cash refunds can be paid out.
+func RequestRefund(
+	transaction *Transaction,
+	amount valueobjects.Money,
+	alreadyRefunded valueobjects.Money,
+	reason string,
+	requestedBy Actor,
+	policy RefundPolicy,
+	rounding policy.RoundingPolicy,
+) (*Refund, error) {
+	if requestedBy.IsZero() {
+		return nil, ErrActorRequired
+	}
+	if transaction.Status() != TransactionStatusCompleted {
+		return nil, ErrRefundSessionNotCompleted
+	}
+	if amount.Amount() <= 0 {
+		return nil, ErrInvalidRefundAmount
+	}
+	total := transaction.Total()
+	if amount.Currency() != total.Currency() {
+		return nil, ErrRefundCurrencyMismatch
+	}
+	amount, roundingCents := rounding.Round(amount)
+	if amount.Amount() <= 0 {
+		return nil, ErrInvalidRefundAmount
+	}
+	remaining, err := total.Subtract(alreadyRefunded)
+	if err != nil || amount.Amount() > remaining.Amount() {
+		return nil, ErrRefundExceedsTotal
+	}
+
+	now := time.Now().UTC()
+	r := &Refund{
+		id:            valueobjects.NewRefundID(),
+		sessionID:     transaction.SessionID(),
+		transactionID: transaction.ID(),
+		amount:        amount,
+		rounding:      roundingCents,
+		reason:        reason,
+		status:        RefundStatusPendingApproval,
+		requestedBy:   requestedBy.ID(),
+		createdAt:     now,
+	}
+	r.record(RefundAuditRequested, requestedBy, reason, now)
+	r.domainEvents = append(r.domainEvents, NewRefundRequested(r.id, r.sessionID, amount, requestedBy.ID()))
+
+	// Approval is decided on all that the transaction's refunds add up to
+	refunded, err := alreadyRefunded.Add(amount)
+	if err != nil {
+		return nil, ErrRefundCurrencyMismatch
+	}
+	if !policy.RequiresApproval(refunded) {
+		r.decide(RefundStatusApproved, RefundAuditAutoApproved, SystemActor(), "below approval threshold", now)
+	}
+
+	return r, nil
+}
+
+// ReconstituteRefund rebuilds a Refund from persistence
+func ReconstituteRefund(
+	id valueobjects.RefundID,
+	sessionID valueobjects.SessionID,
+	transactionID valueobjects.TransactionID,
+	amount valueobjects.Money,
+	roundingCents int64,
+	reason string,
+	status RefundStatus,
+	requestedBy, decidedBy string,
+	createdAt time.Time,
+	decidedAt *time.Time,
+	audit []RefundAuditEntry,
+) *Refund {
+	return &Refund{
+		id:            id,
+		sessionID:     sessionID,
+		transactionID: transactionID,
+		amount:        amount,
+		rounding:      roundingCents,
+		reason:        reason,
+		status:        status,
+		requestedBy:   requestedBy,
+		decidedBy:     decidedBy,
+		createdAt:     createdAt,
+		decidedAt:     decidedAt,
+		audit:         audit,
+	}
+}
+
+// Getters
+func (r *Refund) ID() valueobjects.RefundID                 { return r.id }
+func (r *Refund) SessionID() valueobjects.SessionID         { return r.sessionID }
+func (r *Refund) TransactionID() valueobjects.TransactionID { return r.transactionID }
+func (r *Refund) Amount() valueobjects.Money                { return r.amount }
+func (r *Refund) RoundingCents() int64                      { return r.rounding }
+func (r *Refund) Reason() string                            { return r.reason }
+func (r *Refund) Status() RefundStatus                      { return r.status }
+func (r *Refund) RequestedBy() string                       { return r.requestedBy }
+func (r *Refund) DecidedBy() string                         { return r.decidedBy }
+func (r *Refund) CreatedAt() time.Time                      { return r.createdAt }
+func (r *Refund) DecidedAt() *time.Time                     { return r.decidedAt }
+func (r *Refund) AuditTrail() []RefundAuditEntry            { return append([]RefundAuditEntry{}, r.audit...) }
+func (r *Refund) IsPending() bool                           { return r.status == RefundStatusPendingApproval }
+func (r *Refund) CountsTowardsTotal() bool                  { return r.status != RefundStatusRejected }
+
+// Business methods
+
+// Approve releases a pending refund. Approvers need the finance role and
+// cannot approve their own requests.
+func (r *Refund) Approve(approver Actor, note string) error {
+	if err := r.checkDecision(approver); err != nil {
+		return err
+	}
+	r.decide(RefundStatusApproved, RefundAuditApproved, approver, note, time.Now().UTC())
+	return nil
+}
+
+// Reject declines a pending refund
+func (r *Refund) Reject(approver Actor, note string) error {
+	if err := r.checkDecision(approver); err != nil {
+		return err
+	}
+	r.decide(RefundStatusRejected, RefundAuditRejected, approver, note, time.Now().UTC())
+	return nil
+}
+
+func (r *Refund) checkDecision(approver Actor) error {
+	if approver.IsZero() {
+		return ErrActorRequired
+	}
+	if !r.IsPending() {
+		return ErrRefundNotPending
+	}
+	if !approver.HasRole(RoleFinance) {
+		return ErrRefundApproverNotAuthorized
+	}
+	if approver.ID() == r.requestedBy {
+		return ErrRefundSelfApproval
+	}
+	return nil
+}
+
+func (r *Refund) decide(status RefundStatus, action RefundAuditAction, actor Actor, note string, at time.Time) {
+	r.status = status
+	r.decidedBy = actor.ID()
+	r.decidedAt = &at
+	r.record(action, actor, note, at)
+
+	if status == RefundStatusApproved {
+		r.domainEvents = append(r.domainEvents, NewRefundApproved(r.id, r.sessionID, r.amount, actor.ID()))
+	} else {
+		r.domainEvents = append(r.domainEvents, NewRefundRejected(r.id, r.sessionID, actor.ID(), note))
+	}
+}
+
+func (r *Refund) record(action RefundAuditAction, actor Actor, note string, at time.Time) {
+	r.audit = append(r.audit, RefundAuditEntry{
+		Action:  action,
+		ActorID: actor.ID(),
+		Roles:   actor.Roles(),
+		Note:    note,
+		At:      at,
+	})
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (r *Refund) PullEvents() []events.DomainEvent {
+	evts := r.domainEvents
+	r.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+func completedTransaction(totalCents int64) *Transaction {
+	total, _ := valueobjects.NewMoney(totalCents, "EUR")
+	return ReconstituteTransaction(valueobjects.NewTransactionID(), valueobjects.NewSessionID(), valueobjects.NewDeviceID(),
+		"", nil, total, 0, 0, "", 0, "PAY-1", FiscalRecord{}, TransactionStatusCompleted, time.Now().UTC())
+}
+
+func TestRequestRefundDecidesApprovalOnAllRefundsOfTheTransaction(t *testing.T) {
+	tests := []struct {
+		name            string
+		alreadyRefunded int64
+		amount          int64
+		want            RefundStatus
+	}{
+		{"first request below the threshold", 0, 1500, RefundStatusApproved},
+		{"first request at the threshold", 0, 2000, RefundStatusApproved},
+		{"first request above the threshold", 0, 2500, RefundStatusPendingApproval},
+		{"split request staying within the threshold", 1500, 500, RefundStatusApproved},
+		{"split request taking the refunds past the threshold", 1500, 1000, RefundStatusPendingApproval},
+		{"small request after a large one", 2500, 100, RefundStatusPendingApproval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, _ := valueobjects.NewMoney(tt.amount, "EUR")
+			already, _ := valueobjects.NewMoney(tt.alreadyRefunded, "EUR")
+			refund, err := RequestRefund(completedTransaction(2800), amount, already, "damaged",
+				NewActor("agent-7", []string{"support"}), NewRefundPolicy(2000), policy.NoRounding())
+			if err != nil {
+				t.Fatalf("RequestRefund: %v", err)
+			}
+			if refund.Status() != tt.want {
+				t.Errorf("status = %s, want %s", refund.Status(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestRefundCannotExceedWhatWasCharged(t *testing.T) {
+	amount, _ := valueobjects.NewMoney(1000, "EUR")
+	already, _ := valueobjects.NewMoney(2000, "EUR")
+	_, err := RequestRefund(completedTransaction(2800), amount, already, "damaged",
+		NewActor("agent-7", nil), NewRefundPolicy(2000), policy.NoRounding())
+	if !errors.Is(err, ErrRefundExceedsTotal) {
+		t.Errorf("error = %v, want ErrRefundExceedsTotal", err)
+	}
+}
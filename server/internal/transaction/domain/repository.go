@@ -2,13 +2,190 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
 // SessionRepository is the PORT interface defined by the domain
 type SessionRepository interface {
+	// Save stores the session, or returns ErrSessionConflict when it was
+	// saved by someone else since it was loaded
 	Save(ctx context.Context, session *Session) error
+	// SaveCompleted stores a session that has just been confirmed together
+	// with its transaction: either both are stored or neither is
+	SaveCompleted(ctx context.Context, session *Session, transaction *Transaction) error
 	FindByID(ctx context.Context, id valueobjects.SessionID) (*Session, error)
 	FindActiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*Session, error)
+	// FindLatestByDeviceID returns the most recently started session on the device
+	FindLatestByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*Session, error)
+	// FindCompletedBetween returns all sessions completed in [from, to)
+	FindCompletedBetween(ctx context.Context, from, to time.Time) ([]*Session, error)
+	// FindAwaitingReview returns the active sessions with lines awaiting an
+	// attendant's review, oldest first
+	FindAwaitingReview(ctx context.Context) ([]*Session, error)
+	// FindWalkedAway returns the active sessions the device signalled the
+	// customer left before the given time, oldest signal first
+	FindWalkedAway(ctx context.Context, signaledBefore time.Time) ([]*Session, error)
+	// ExperimentStats aggregates the sessions tagged with an experiment, per variant
+	ExperimentStats(ctx context.Context, experimentID string) ([]ExperimentVariantStats, error)
+	// FindPage returns up to limit sessions matching the filter, newest first,
+	// starting after the cursor when one is given
+	FindPage(ctx context.Context, filter SessionFilter, after *SessionCursor, limit int) ([]*Session, error)
+}
+
+// SessionArchive moves ended sessions out of the hot sessions table. An
+// archived session is still found by SessionRepository.FindByID, listed by
+// FindPage and read by FindCompletedBetween and ExperimentStats, but no
+// longer shows in device lookups, and saving it fails with
+// ErrSessionArchived.
+type SessionArchive interface {
+	// ArchiveEnded moves up to limit completed, cancelled or expired sessions
+	// started before the given time, oldest first, and returns how many it
+	// moved
+	ArchiveEnded(ctx context.Context, startedBefore time.Time, limit int) (int, error)
+}
+
+// SessionFilter narrows a session listing; zero fields don't filter
+type SessionFilter struct {
+	DeviceID valueobjects.DeviceID
+	UserID   string
+	Status   SessionStatus
+	From     time.Time // started at or after
+	To       time.Time // started before
+}
+
+// SessionCursor is the last session of a page; the next page starts after it
+type SessionCursor struct {
+	CreatedAt time.Time
+	ID        valueobjects.SessionID
+}
+
+// ExperimentVariantStats is a read model of session outcomes for one variant
+type ExperimentVariantStats struct {
+	Variant           string
+	Sessions          int64
+	CompletedSessions int64
+	RevenueCents      int64
+	Currency          string
+}
+
+// TransactionRepository is the PORT interface for reading completed sales;
+// transactions are written with their session (see SessionRepository.SaveCompleted)
+type TransactionRepository interface {
+	FindByID(ctx context.Context, id valueobjects.TransactionID) (*Transaction, error)
+	FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) (*Transaction, error)
+	// FindCompletedByUser returns the user's transactions completed in [from, to)
+	FindCompletedByUser(ctx context.Context, userID string, from, to time.Time) ([]*Transaction, error)
+	// SoldUnits counts the units of each SKU sold on the device since the
+	// given time for that SKU
+	SoldUnits(ctx context.Context, deviceID valueobjects.DeviceID, since map[string]time.Time) (map[string]int, error)
+	// CouponRedemptions counts the completed sales checked out with the coupon
+	CouponRedemptions(ctx context.Context, code string) (int, error)
+	// HoldCoupon keeps other checkouts from redeeming the coupon until the
+	// unit of work the context carries is done
+	HoldCoupon(ctx context.Context, code string) error
+	// HoldRefunds keeps other refund requests for the transaction from
+	// adding up its refunds until the unit of work the context carries is done
+	HoldRefunds(ctx context.Context, id valueobjects.TransactionID) error
+	// SalesFigures aggregates the sales completed in [from, to) per group
+	// and currency, ordered by group then currency
+	SalesFigures(ctx context.Context, groupBy SalesGrouping, from, to time.Time) ([]SalesFigures, error)
+}
+
+// SalesGrouping is what a sales report aggregates completed sales by
+type SalesGrouping string
+
+const (
+	SalesByDay    SalesGrouping = "day"    // the UTC day of completion, YYYY-MM-DD
+	SalesByDevice SalesGrouping = "device" // the device ID
+	SalesBySKU    SalesGrouping = "sku"    // the SKU code
+)
+
+// SalesFigures is a read model of the completed sales of one group in one
+// currency. Grouped by SKU, revenue is the SKU's line totals before tax and
+// discounts, and only the transactions that sold the SKU are counted.
+type SalesFigures struct {
+	Key          string
+	Currency     string
+	Transactions int64
+	RevenueCents int64
+	Units        int64
+}
+
+// RefundRepository is the PORT interface for refund persistence
+type RefundRepository interface {
+	Save(ctx context.Context, refund *Refund) error
+	FindByID(ctx context.Context, id valueobjects.RefundID) (*Refund, error)
+	FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]*Refund, error)
+	FindByStatus(ctx context.Context, status RefundStatus) ([]*Refund, error)
+}
+
+// DisputeFilter narrows a dispute listing; zero fields match all disputes
+type DisputeFilter struct {
+	Status     DisputeStatus
+	OpenedFrom time.Time // inclusive
+	OpenedTo   time.Time // exclusive
+}
+
+// DisputeRepository is the PORT interface for dispute persistence
+type DisputeRepository interface {
+	// Save stores the dispute, or returns ErrDisputeAlreadyOpen when it would
+	// be a second open dispute of its session
+	Save(ctx context.Context, dispute *Dispute) error
+	FindByID(ctx context.Context, id valueobjects.DisputeID) (*Dispute, error)
+	FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]*Dispute, error)
+	// Find lists the disputes matching the filter, oldest first
+	Find(ctx context.Context, filter DisputeFilter) ([]*Dispute, error)
+}
+
+// SyncLogRepository is the PORT interface for the offline sync log
+type SyncLogRepository interface {
+	// Save records the outcome of an entry; a record for the same device and
+	// client ID is never overwritten
+	Save(ctx context.Context, record SyncRecord) error
+	Find(ctx context.Context, deviceID, clientID string) (*SyncRecord, error)
+	// LatestAppliedDetection returns when the newest detection applied to the
+	// session through sync was recorded, or nil if there is none
+	LatestAppliedDetection(ctx context.Context, sessionID string) (*time.Time, error)
+}
+
+// ProcessedDetectionRepository is the PORT interface for the results of
+// detections submitted with an idempotency key
+type ProcessedDetectionRepository interface {
+	// Save records the result; a result already recorded for the device and
+	// key is never overwritten
+	Save(ctx context.Context, detection ProcessedDetection) error
+	Find(ctx context.Context, deviceID, key string) (*ProcessedDetection, error)
+}
+
+// DetectionLogRepository is the PORT interface for the detection audit log;
+// entries are only ever appended
+type DetectionLogRepository interface {
+	Append(ctx context.Context, record DetectionRecord) error
+	// FindBySession lists the session's entries, oldest first
+	FindBySession(ctx context.Context, sessionID string) ([]DetectionRecord, error)
+}
+
+// SessionNoteRepository is the PORT interface for the notes staff attach to
+// sessions; notes are only ever appended
+type SessionNoteRepository interface {
+	// Append stores the note and returns it with its ID
+	Append(ctx context.Context, note SessionNote) (SessionNote, error)
+	// FindBySession lists the session's notes, oldest first
+	FindBySession(ctx context.Context, sessionID valueobjects.SessionID) ([]SessionNote, error)
+}
+
+// SessionImageRepository is the PORT interface for the images devices send
+// with their detections; only the latest image of a session is kept
+type SessionImageRepository interface {
+	Save(ctx context.Context, sessionID valueobjects.SessionID, image []byte, capturedAt time.Time) error
+	FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]byte, error)
+}
+
+// ReconciliationRepository is the PORT interface for reconciliation reports
+type ReconciliationRepository interface {
+	// Save stores the report, replacing an earlier run for the same day
+	Save(ctx context.Context, report *ReconciliationReport) error
+	FindByDay(ctx context.Context, day string) (*ReconciliationReport, error)
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/policy"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 )
 
@@ -14,6 +15,9 @@ const (
 	SessionStatusCompleted SessionStatus = "completed"
 	SessionStatusCancelled SessionStatus = "cancelled"
 	SessionStatusExpired   SessionStatus = "expired"
+	// SessionStatusAwaitingPayment holds the basket while the provider
+	// settles a payment; it ends completed, or active again if it fails
+	SessionStatusAwaitingPayment SessionStatus = "awaiting_payment"
 )
 
 // Session is the aggregate root for a customer interaction session
@@ -25,15 +29,32 @@ type Session struct {
 	detectedItems []DetectedItem
 	totalWeight   valueobjects.Weight
 	totalAmount   valueobjects.Money
+	roundingCents int64  // total minus the sum of the item prices and added tax, from the currency's rounding rule
+	tax           Tax    // per-rate tax of the current basket
+	paymentIntent string // provider payment intent for guest checkout, if any
+	paymentMethod PaymentMethod
+	fiscalRecord  FiscalRecord
+	experiments   []ExperimentTag
+	markdowns     map[string]int // SKU code -> percent off, for batches close to expiry at session start
+	cloudVerify   bool           // device had a security incident; items must be verified by cloud detection
+	weightMiss    bool           // the last detection's items did not match the measured weight
+	weightFix     WeightOverride // attendant resolution of the mismatch, zero while unresolved
+	fraudFlags    []FraudFinding // fraud rules the session matched, one finding per rule
+	coupon        AppliedCoupon  // coupon taken off the items, zero without one
+	frameSeq      int64          // sequence of the last detection frame applied, 0 before any
+	walkaway      Walkaway       // first sign from the device that the customer left, zero until then
+	unrecognized  []UnrecognizedItem
 	createdAt     time.Time
 	expiresAt     time.Time
 	completedAt   *time.Time
+	version       int  // saves so far; a save must start from the version it loaded
+	archived      bool // read back from the archive of ended sessions, which keeps it as it was
 
 	domainEvents []events.DomainEvent
 }
 
 // NewSession creates a new session when user scans QR code
-func NewSession(deviceID valueobjects.DeviceID, userID string, expirationMinutes int) (*Session, error) {
+func NewSession(deviceID valueobjects.DeviceID, userID string, expiration time.Duration) (*Session, error) {
 	if deviceID.IsZero() {
 		return nil, ErrInvalidDeviceID
 	}
@@ -46,7 +67,7 @@ func NewSession(deviceID valueobjects.DeviceID, userID string, expirationMinutes
 		status:        SessionStatusActive,
 		detectedItems: []DetectedItem{},
 		createdAt:     now,
-		expiresAt:     now.Add(time.Duration(expirationMinutes) * time.Minute),
+		expiresAt:     now.Add(expiration),
 	}
 
 	s.domainEvents = append(s.domainEvents, NewSessionStarted(s.id, deviceID, userID))
@@ -63,8 +84,24 @@ func Reconstitute(
 	detectedItems []DetectedItem,
 	totalWeight valueobjects.Weight,
 	totalAmount valueobjects.Money,
+	roundingCents int64,
+	tax Tax,
+	paymentIntent string,
+	paymentMethod PaymentMethod,
+	fiscalRecord FiscalRecord,
+	experiments []ExperimentTag,
+	markdowns map[string]int,
+	cloudVerify bool,
+	weightMismatch bool,
+	weightOverride WeightOverride,
+	fraudFlags []FraudFinding,
+	coupon AppliedCoupon,
+	frameSequence int64,
+	walkaway Walkaway,
+	unrecognized []UnrecognizedItem,
 	createdAt, expiresAt time.Time,
 	completedAt *time.Time,
+	version int,
 ) *Session {
 	return &Session{
 		id:            id,
@@ -74,9 +111,25 @@ func Reconstitute(
 		detectedItems: detectedItems,
 		totalWeight:   totalWeight,
 		totalAmount:   totalAmount,
+		roundingCents: roundingCents,
+		tax:           tax,
+		paymentIntent: paymentIntent,
+		paymentMethod: paymentMethod,
+		fiscalRecord:  fiscalRecord,
+		experiments:   experiments,
+		markdowns:     markdowns,
+		cloudVerify:   cloudVerify,
+		weightMiss:    weightMismatch,
+		weightFix:     weightOverride,
+		fraudFlags:    fraudFlags,
+		coupon:        coupon,
+		frameSeq:      frameSequence,
+		walkaway:      walkaway,
+		unrecognized:  unrecognized,
 		createdAt:     createdAt,
 		expiresAt:     expiresAt,
 		completedAt:   completedAt,
+		version:       version,
 	}
 }
 
@@ -88,9 +141,44 @@ func (s *Session) Status() SessionStatus            { return s.status }
 func (s *Session) DetectedItems() []DetectedItem    { return append([]DetectedItem{}, s.detectedItems...) }
 func (s *Session) TotalWeight() valueobjects.Weight { return s.totalWeight }
 func (s *Session) TotalAmount() valueobjects.Money  { return s.totalAmount }
+func (s *Session) RoundingCents() int64             { return s.roundingCents }
+func (s *Session) Tax() Tax                         { return s.tax }
+func (s *Session) PaymentIntentID() string          { return s.paymentIntent }
+func (s *Session) PaymentMethod() PaymentMethod     { return s.paymentMethod }
+func (s *Session) FiscalRecord() FiscalRecord       { return s.fiscalRecord }
+func (s *Session) Experiments() []ExperimentTag     { return append([]ExperimentTag{}, s.experiments...) }
 func (s *Session) CreatedAt() time.Time             { return s.createdAt }
 func (s *Session) ExpiresAt() time.Time             { return s.expiresAt }
 func (s *Session) CompletedAt() *time.Time          { return s.completedAt }
+func (s *Session) CloudVerificationRequired() bool  { return s.cloudVerify }
+func (s *Session) WeightMismatch() bool             { return s.weightMiss }
+func (s *Session) WeightOverride() WeightOverride   { return s.weightFix }
+func (s *Session) FrameSequence() int64             { return s.frameSeq }
+func (s *Session) Walkaway() Walkaway               { return s.walkaway }
+func (s *Session) FraudFlags() []FraudFinding       { return append([]FraudFinding{}, s.fraudFlags...) }
+func (s *Session) Coupon() AppliedCoupon            { return s.coupon }
+func (s *Session) Version() int                     { return s.version }
+
+// SetVersion is called by the repository once a save went through, so the
+// next save of the same session starts from it
+func (s *Session) SetVersion(version int) { s.version = version }
+
+// Archived reports whether the session was read from the archive of ended
+// sessions; an archived session is read-only and cannot be saved again
+func (s *Session) Archived() bool { return s.archived }
+
+// MarkArchived is called by the repository for a session it read from the
+// archive
+func (s *Session) MarkArchived() { s.archived = true }
+
+// UnitCount is the number of units in the basket, over all its lines
+func (s *Session) UnitCount() int {
+	units := 0
+	for _, item := range s.detectedItems {
+		units += item.Quantity()
+	}
+	return units
+}
 
 func (s *Session) IsActive() bool {
 	return s.status == SessionStatusActive && time.Now().Before(s.expiresAt)
@@ -100,10 +188,119 @@ func (s *Session) IsExpired() bool {
 	return time.Now().After(s.expiresAt)
 }
 
+// SubtotalCents is the sum of the item prices without tax, before the
+// coupon discount and rounding
+func (s *Session) SubtotalCents() (int64, error) {
+	discount, err := s.DiscountCents()
+	if err != nil {
+		return 0, err
+	}
+	return s.totalAmount.Amount() - s.roundingCents - s.tax.Cents() + discount, nil
+}
+
+// ItemsCents is the sum of the item prices as detected, before any discount
+func (s *Session) ItemsCents() (int64, error) {
+	total, err := s.itemsTotal()
+	if err != nil {
+		return 0, err
+	}
+	return total.Amount(), nil
+}
+
+// DiscountCents is what the session's coupon takes off the items
+func (s *Session) DiscountCents() (int64, error) {
+	total, err := s.itemsTotal()
+	if err != nil {
+		return 0, err
+	}
+	return s.coupon.DiscountOn(total).Amount(), nil
+}
+
+// itemsTotal is the sum of the item prices as detected, zero without items.
+// It fails when the prices cannot be added up, in different currencies or
+// beyond what money can hold.
+func (s *Session) itemsTotal() (valueobjects.Money, error) {
+	var total valueobjects.Money
+	for i, item := range s.detectedItems {
+		if i == 0 {
+			total = item.LineTotal()
+			continue
+		}
+		sum, err := total.Add(item.LineTotal())
+		if err != nil {
+			return valueobjects.Money{}, err
+		}
+		total = sum
+	}
+	return total, nil
+}
+
+// IsGuest reports whether the session was started without a user account
+func (s *Session) IsGuest() bool {
+	return s.userID == ""
+}
+
+// ExperimentPrice returns the experiment price for a SKU if the session was
+// assigned a variant that overrides it
+func (s *Session) ExperimentPrice(code string) (int64, bool) {
+	for _, tag := range s.experiments {
+		if cents, ok := tag.PriceFor(code); ok {
+			return cents, true
+		}
+	}
+	return 0, false
+}
+
+// Markdowns returns a copy of the markdown percentages recorded at session start
+func (s *Session) Markdowns() map[string]int {
+	copied := make(map[string]int, len(s.markdowns))
+	for code, pct := range s.markdowns {
+		copied[code] = pct
+	}
+	return copied
+}
+
+// MarkdownPercent returns the percentage taken off a SKU's price in this session
+func (s *Session) MarkdownPercent(code string) int {
+	return s.markdowns[code]
+}
+
 // Business methods
 
-// RecordDetection records items detected by the device
-func (s *Session) RecordDetection(items []DetectedItem, totalWeight valueobjects.Weight) error {
+// TagExperiments records the price experiment variants assigned at session start
+func (s *Session) TagExperiments(tags []ExperimentTag) error {
+	if s.status != SessionStatusActive || len(s.detectedItems) > 0 {
+		return ErrSessionNotActive
+	}
+	s.experiments = append([]ExperimentTag{}, tags...)
+	return nil
+}
+
+// ApplyMarkdowns records the expiry markdowns of the device at session start,
+// so the basket is priced consistently even if a markdown tier changes mid-session
+func (s *Session) ApplyMarkdowns(markdowns map[string]int) error {
+	if s.status != SessionStatusActive || len(s.detectedItems) > 0 {
+		return ErrSessionNotActive
+	}
+	s.markdowns = make(map[string]int, len(markdowns))
+	for code, pct := range markdowns {
+		if pct > 0 {
+			s.markdowns[code] = pct
+		}
+	}
+	return nil
+}
+
+// RequireCloudVerification makes every detection in this session go through
+// cloud verification, e.g. after a security incident on the device
+func (s *Session) RequireCloudVerification() {
+	s.cloudVerify = true
+}
+
+// RecordDetection records items detected by the device. The total is rounded
+// by the currency's rule and the difference kept alongside it. weightMatch
+// tells whether the items add up to the measured weight.
+func (s *Session) RecordDetection(items []DetectedItem, totalWeight valueobjects.Weight, weightMatch bool, rounding policy.RoundingPolicy) error {
 	if !s.IsActive() {
 		return ErrSessionNotActive
 	}
@@ -112,37 +309,289 @@ func (s *Session) RecordDetection(items []DetectedItem, totalWeight valueobjects
 		return ErrSessionExpired
 	}
 
-	s.detectedItems = items
+	s.detectedItems = carryOverReviews(s.detectedItems, items)
 	s.totalWeight = totalWeight
+	s.weightMiss = !weightMatch
+	// An override vouched for the previous basket only
+	s.weightFix = WeightOverride{}
 
-	// Calculate total amount
-	var total valueobjects.Money
-	for i, item := range items {
-		if i == 0 {
-			total = item.Price()
-		} else {
-			var err error
-			total, err = total.Add(item.Price())
-			if err != nil {
-				return err
-			}
+	// A changed basket has to be taxed again
+	s.tax = Tax{}
+	if err := s.price(rounding); err != nil {
+		return err
+	}
+
+	s.domainEvents = append(s.domainEvents, NewItemsDetected(s.id, s.UnitCount(), totalWeight.Grams()))
+
+	return nil
+}
+
+// WeightDisputed tells whether the basket does not match the measured weight
+// and no attendant has resolved it yet
+func (s *Session) WeightDisputed() bool {
+	return s.weightMiss && s.weightFix.IsZero()
+}
+
+// OverrideWeight records an attendant's resolution of a weight mismatch. A
+// correction is recorded after the corrected basket, which may match the
+// weight by then; an approval needs a mismatch to approve.
+func (s *Session) OverrideWeight(override WeightOverride) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	if override.ActorID() == "" {
+		return ErrActorRequired
+	}
+	if override.Reason() == "" {
+		return ErrWeightOverrideReasonMissing
+	}
+	if override.Decision() == WeightDecisionApproved && !s.weightMiss {
+		return ErrNoWeightMismatch
+	}
+	s.weightFix = override
+
+	s.domainEvents = append(s.domainEvents, NewWeightOverridden(s.id, override))
+
+	return nil
+}
+
+// carryOverReviews keeps an attendant's acceptance of a line through later
+// detections, as long as they find no more units of its SKU than were
+// accepted
+func carryOverReviews(previous, items []DetectedItem) []DetectedItem {
+	accepted := make(map[string]int)
+	for _, item := range previous {
+		if item.review == ItemReviewAccepted {
+			accepted[item.code] += item.quantity
+		}
+	}
+	units := make(map[string]int)
+	for _, item := range items {
+		units[item.code] += item.quantity
+	}
+
+	out := make([]DetectedItem, 0, len(items))
+	for _, item := range items {
+		if item.review == ItemReviewPending && units[item.code] <= accepted[item.code] {
+			item.review = ItemReviewAccepted
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// AwaitingReview lists the lines detected below the confidence threshold
+// that no attendant has reviewed yet
+func (s *Session) AwaitingReview() []DetectedItem {
+	var pending []DetectedItem
+	for _, item := range s.detectedItems {
+		if item.review == ItemReviewPending {
+			pending = append(pending, item)
 		}
 	}
-	s.totalAmount = total
+	return pending
+}
 
-	s.domainEvents = append(s.domainEvents, NewItemsDetected(s.id, len(items), totalWeight.Grams()))
+// ReviewItem records an attendant's review of the lines of a SKU. Accepting
+// vouches for the lines awaiting review; a replacement is recorded once the
+// basket holds the replacement SKU, whose lines the attendant vouches for.
+func (s *Session) ReviewItem(code string, decision ItemDecision, replacement, actorID string) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	if actorID == "" {
+		return ErrActorRequired
+	}
+
+	reviewed := code
+	switch decision {
+	case ItemDecisionAccepted:
+		if !s.awaitsReview(code) {
+			return ErrNoItemToReview
+		}
+	case ItemDecisionReplaced:
+		if replacement == "" {
+			return ErrItemReplacementMissing
+		}
+		reviewed = replacement
+	default:
+		return ErrUnknownItemDecision
+	}
+
+	found := false
+	for i, item := range s.detectedItems {
+		if item.code == reviewed {
+			s.detectedItems[i].review = ItemReviewAccepted
+			found = true
+		}
+	}
+	if !found {
+		return ErrNoItemToReview
+	}
+
+	s.domainEvents = append(s.domainEvents, NewItemReviewed(s.id, code, decision, replacement, actorID))
 
 	return nil
 }
 
-// Confirm completes the session after payment
-func (s *Session) Confirm(paymentRef string) error {
+func (s *Session) awaitsReview(code string) bool {
+	for _, item := range s.detectedItems {
+		if item.code == code && item.review == ItemReviewPending {
+			return true
+		}
+	}
+	return false
+}
+
+// FlagFraud records the findings of the fraud rules. A rule already flagged
+// is only recorded again when it now blocks the session.
+func (s *Session) FlagFraud(findings []FraudFinding) {
+	for _, finding := range findings {
+		if s.flaggedBy(finding.Rule(), finding.Action()) {
+			continue
+		}
+		s.fraudFlags = append(s.fraudFlags, finding)
+		s.domainEvents = append(s.domainEvents, NewFraudSuspected(s.id, s.deviceID, s.userID, finding))
+	}
+}
+
+func (s *Session) flaggedBy(rule string, action FraudAction) bool {
+	for _, f := range s.fraudFlags {
+		if f.Rule() == rule && (f.Action() == action || f.Action() == FraudActionBlock) {
+			return true
+		}
+	}
+	return false
+}
+
+// FraudBlocked tells whether a fraud rule blocked the session, which then
+// takes no more detections and cannot be checked out
+func (s *Session) FraudBlocked() bool {
+	for _, f := range s.fraudFlags {
+		if f.Action() == FraudActionBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyCoupon takes the coupon off the basket, replacing any coupon applied
+// before. The basket has to be taxed again.
+func (s *Session) ApplyCoupon(coupon AppliedCoupon, rounding policy.RoundingPolicy) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	if len(s.detectedItems) == 0 {
+		return ErrNoItemsDetected
+	}
+	s.coupon = coupon
+
+	s.tax = Tax{}
+	if err := s.price(rounding); err != nil {
+		return err
+	}
+
+	discount, err := s.DiscountCents()
+	if err != nil {
+		return err
+	}
+	s.domainEvents = append(s.domainEvents, NewCouponApplied(s.id, coupon.Code(), discount))
+
+	return nil
+}
+
+// AdvanceFrame records the sequence of the detection frame about to be
+// applied. Frames without a sequence are not ordered; a frame no newer than
+// the last one applied is stale.
+func (s *Session) AdvanceFrame(sequence int64) error {
+	if sequence == 0 {
+		return nil
+	}
+	if sequence <= s.frameSeq {
+		return ErrStaleDetectionFrame
+	}
+	s.frameSeq = sequence
+	return nil
+}
+
+// ApplyTax sets the tax of the current basket; tax that is not included in
+// the item prices is added to the total before rounding
+func (s *Session) ApplyTax(tax Tax, rounding policy.RoundingPolicy) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	s.tax = tax
+	return s.price(rounding)
+}
+
+// price recomputes the total from the items, the coupon and the tax
+func (s *Session) price(rounding policy.RoundingPolicy) error {
+	total, err := s.itemsTotal()
+	if err != nil {
+		return err
+	}
+	if adjust := s.tax.added() - s.coupon.DiscountOn(total).Amount(); adjust != 0 {
+		total, err = valueobjects.NewMoney(total.Amount()+adjust, total.Currency())
+		if err != nil {
+			return err
+		}
+	}
+	s.totalAmount, s.roundingCents = rounding.Round(total)
+	return nil
+}
+
+// AttachPaymentIntent links the provider payment intent used for guest checkout
+func (s *Session) AttachPaymentIntent(intentID string) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	s.paymentIntent = intentID
+	return nil
+}
+
+// RecordPaymentMethod stores how the session was paid for receipts
+func (s *Session) RecordPaymentMethod(method PaymentMethod) error {
+	if !s.IsActive() && s.status != SessionStatusAwaitingPayment {
+		return ErrSessionNotActive
+	}
+	s.paymentMethod = method
+	return nil
+}
+
+// AwaitPayment holds the basket while the provider settles the payment
+func (s *Session) AwaitPayment() error {
 	if !s.IsActive() {
 		return ErrSessionNotActive
 	}
 	if len(s.detectedItems) == 0 {
 		return ErrNoItemsDetected
 	}
+	s.status = SessionStatusAwaitingPayment
+	return nil
+}
+
+// FailPayment returns a session whose payment failed to the customer, who
+// can pay again or change the basket
+func (s *Session) FailPayment(reason string) error {
+	if s.status != SessionStatusAwaitingPayment {
+		return ErrSessionNotAwaitingPayment
+	}
+	s.status = SessionStatusActive
+
+	s.domainEvents = append(s.domainEvents, NewPaymentFailed(s.id, reason))
+
+	return nil
+}
+
+// Confirm completes the session after payment. A session awaiting its
+// payment completes even past its expiry, as the customer has already paid.
+func (s *Session) Confirm(paymentRef string) error {
+	if !s.IsActive() && s.status != SessionStatusAwaitingPayment {
+		return ErrSessionNotActive
+	}
+	if len(s.detectedItems) == 0 {
+		return ErrNoItemsDetected
+	}
 
 	now := time.Now().UTC()
 	s.status = SessionStatusCompleted
@@ -153,11 +602,23 @@ func (s *Session) Confirm(paymentRef string) error {
 	return nil
 }
 
+// RecordFiscalization stores the fiscal identifiers issued for a completed sale
+func (s *Session) RecordFiscalization(record FiscalRecord) error {
+	if s.status != SessionStatusCompleted {
+		return ErrSessionNotCompleted
+	}
+	s.fiscalRecord = record
+	return nil
+}
+
 // Cancel cancels the session
 func (s *Session) Cancel(reason string) error {
 	if s.status == SessionStatusCompleted {
 		return ErrSessionAlreadyCompleted
 	}
+	if s.status == SessionStatusAwaitingPayment {
+		return ErrSessionAwaitingPayment
+	}
 
 	now := time.Now().UTC()
 	s.status = SessionStatusCancelled
@@ -168,6 +629,68 @@ func (s *Session) Cancel(reason string) error {
 	return nil
 }
 
+// SignalWalkaway records that the device saw the customer leave. Only the
+// first signal counts, as the grace to pay runs from it.
+func (s *Session) SignalWalkaway(signal WalkawaySignal, at time.Time) error {
+	if !s.IsActive() {
+		return ErrSessionNotActive
+	}
+	if s.walkaway.IsZero() {
+		s.walkaway = NewWalkaway(signal, at)
+	}
+	return nil
+}
+
+// CancelWalkaway cancels the session when the customer left with items and
+// did not pay within grace of the first walkaway signal; false when they
+// have not walked away
+func (s *Session) CancelWalkaway(now time.Time, grace time.Duration) bool {
+	if !s.IsActive() || s.walkaway.IsZero() || len(s.detectedItems) == 0 {
+		return false
+	}
+	if now.Before(s.walkaway.SignaledAt().Add(grace)) {
+		return false
+	}
+
+	s.status = SessionStatusCancelled
+	s.completedAt = &now
+
+	s.domainEvents = append(s.domainEvents,
+		NewSessionCancelled(s.id, walkawayReason),
+		NewWalkawayDetected(s),
+	)
+
+	return true
+}
+
+// Extend pushes the session's expiry back by the given time, up to maxDuration
+// after the session started
+func (s *Session) Extend(by, maxDuration time.Duration) error {
+	if s.status != SessionStatusActive {
+		return ErrSessionNotActive
+	}
+	if s.IsExpired() {
+		s.status = SessionStatusExpired
+		return ErrSessionExpired
+	}
+	if by <= 0 {
+		return ErrInvalidSessionExtension
+	}
+
+	limit := s.createdAt.Add(maxDuration)
+	if !s.expiresAt.Before(limit) {
+		return ErrSessionExtensionLimit
+	}
+	s.expiresAt = s.expiresAt.Add(by)
+	if s.expiresAt.After(limit) {
+		s.expiresAt = limit
+	}
+
+	s.domainEvents = append(s.domainEvents, NewSessionExtended(s.id, s.expiresAt))
+
+	return nil
+}
+
 // PullEvents returns accumulated domain events and clears the slice
 func (s *Session) PullEvents() []events.DomainEvent {
 	evts := s.domainEvents
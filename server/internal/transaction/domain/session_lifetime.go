@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// SessionLifetime is how long sessions last: Default unless their device sets
+// otherwise, and never longer than Max from their start however often they
+// are extended
+type SessionLifetime struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// NewSessionLifetime validates a lifetime
+func NewSessionLifetime(defaultExpiration, maxDuration time.Duration) (SessionLifetime, error) {
+	if defaultExpiration <= 0 || maxDuration < defaultExpiration {
+		return SessionLifetime{}, ErrInvalidSessionLifetime
+	}
+	return SessionLifetime{Default: defaultExpiration, Max: maxDuration}, nil
+}
+
+// For is how long a session started on a device lasts; deviceMinutes is the
+// expiration set for the device, nil when it sets none
+func (l SessionLifetime) For(deviceMinutes *int) time.Duration {
+	expiration := l.Default
+	if deviceMinutes != nil {
+		expiration = time.Duration(*deviceMinutes) * time.Minute
+	}
+	if expiration > l.Max {
+		return l.Max
+	}
+	return expiration
+}
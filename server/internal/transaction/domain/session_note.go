@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+// MaxSessionNoteLength caps a session note, in characters
+const MaxSessionNoteLength = 2000
+
+// SessionNote is a free-text annotation support staff attach to a session
+// while investigating a customer complaint. Notes are only ever added, so
+// they are kept apart from the session and never conflict with its updates.
+type SessionNote struct {
+	ID        int64
+	SessionID valueobjects.SessionID
+	AuthorID  string
+	Note      string
+	CreatedAt time.Time
+}
+
+// NewSessionNote validates a note written by author on the session
+func NewSessionNote(sessionID valueobjects.SessionID, author Actor, note string) (SessionNote, error) {
+	if author.IsZero() {
+		return SessionNote{}, ErrActorRequired
+	}
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return SessionNote{}, ErrSessionNoteMissing
+	}
+	if utf8.RuneCountInString(note) > MaxSessionNoteLength {
+		return SessionNote{}, ErrSessionNoteTooLong
+	}
+	return SessionNote{
+		SessionID: sessionID,
+		AuthorID:  author.ID(),
+		Note:      note,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
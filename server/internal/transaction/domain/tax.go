@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/vending-machine/server/internal/shared/policy"
+)
+
+// TaxableAmount is the price of one basket item with the rate it is taxed at
+type TaxableAmount struct {
+	Rate  policy.TaxRate
+	Cents int64
+}
+
+// TaxLine is the tax of all items sharing one rate, as printed on receipts
+type TaxLine struct {
+	rate         policy.TaxRate
+	taxableCents int64 // sum of the item prices at this rate, as charged
+	taxCents     int64
+}
+
+func NewTaxLine(rate policy.TaxRate, taxableCents, taxCents int64) TaxLine {
+	return TaxLine{rate: rate, taxableCents: taxableCents, taxCents: taxCents}
+}
+
+func (l TaxLine) Rate() policy.TaxRate { return l.rate }
+func (l TaxLine) TaxableCents() int64  { return l.taxableCents }
+func (l TaxLine) TaxCents() int64      { return l.taxCents }
+
+// Tax is a value object holding the tax of a basket. When included, the item
+// prices already contain it and the total does not change.
+type Tax struct {
+	included bool
+	lines    []TaxLine // ordered by rate
+}
+
+// NewTax groups the items by rate and taxes each group once, so rounding
+// happens per rate rather than per item
+func NewTax(amounts []TaxableAmount, included bool) Tax {
+	byRate := make(map[policy.TaxRate]int64)
+	for _, a := range amounts {
+		if a.Rate > 0 {
+			byRate[a.Rate] += a.Cents
+		}
+	}
+
+	t := Tax{included: included}
+	for rate, cents := range byRate {
+		t.lines = append(t.lines, NewTaxLine(rate, cents, rate.TaxOn(cents, included)))
+	}
+	sort.Slice(t.lines, func(i, j int) bool { return t.lines[i].rate < t.lines[j].rate })
+	return t
+}
+
+// ReconstituteTax rebuilds a Tax from persistence
+func ReconstituteTax(included bool, lines []TaxLine) Tax {
+	return Tax{included: included, lines: lines}
+}
+
+func (t Tax) Included() bool   { return t.included }
+func (t Tax) Lines() []TaxLine { return append([]TaxLine{}, t.lines...) }
+func (t Tax) IsZero() bool     { return len(t.lines) == 0 }
+
+// Cents is the tax of the whole basket
+func (t Tax) Cents() int64 {
+	var total int64
+	for _, l := range t.lines {
+		total += l.taxCents
+	}
+	return total
+}
+
+// added is the part of the tax charged on top of the item prices
+func (t Tax) added() int64 {
+	if t.included {
+		return 0
+	}
+	return t.Cents()
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+)
+
+type TransactionStatus string
+
+const (
+	TransactionStatusCompleted TransactionStatus = "completed"
+)
+
+// TransactionLine is a value object for a line of a sale as it was charged
+type TransactionLine struct {
+	skuID     valueobjects.SKUID
+	code      string
+	name      string
+	unitPrice valueobjects.Money
+	quantity  int
+	bundle    string
+}
+
+func NewTransactionLine(skuID valueobjects.SKUID, code, name string, unitPrice valueobjects.Money, quantity int, bundle string) TransactionLine {
+	if quantity < 1 {
+		quantity = 1
+	}
+	return TransactionLine{
+		skuID:     skuID,
+		code:      code,
+		name:      name,
+		unitPrice: unitPrice,
+		quantity:  quantity,
+		bundle:    bundle,
+	}
+}
+
+func (l TransactionLine) SKUID() valueobjects.SKUID     { return l.skuID }
+func (l TransactionLine) Code() string                  { return l.code }
+func (l TransactionLine) Name() string                  { return l.name }
+func (l TransactionLine) UnitPrice() valueobjects.Money { return l.unitPrice }
+func (l TransactionLine) Quantity() int                 { return l.quantity }
+func (l TransactionLine) Bundle() string                { return l.bundle }
+
+// LineTotal is the price of all units of the line
+func (l TransactionLine) LineTotal() valueobjects.Money {
+	total, _ := valueobjects.NewMoney(l.unitPrice.Amount()*int64(l.quantity), l.unitPrice.Currency())
+	return total
+}
+
+// Transaction is the aggregate root for a completed sale. It is recorded
+// together with the session's confirmation and never changes afterwards, so
+// refunds and sales reporting see what was charged even if the session
+// record is touched later.
+type Transaction struct {
+	id            valueobjects.TransactionID
+	sessionID     valueobjects.SessionID
+	deviceID      valueobjects.DeviceID
+	userID        string
+	lines         []TransactionLine
+	total         valueobjects.Money
+	taxCents      int64
+	roundingCents int64
+	couponCode    string // coupon the sale was checked out with, if any
+	discountCents int64  // taken off the lines by the coupon
+	paymentRef    string
+	fiscalRecord  FiscalRecord // identifiers the fiscalization system issued for the sale, zero where none is required
+	status        TransactionStatus
+	completedAt   time.Time
+
+	domainEvents []events.DomainEvent
+}
+
+// RecordTransaction records the sale of a session that has just been confirmed
+func RecordTransaction(session *Session, paymentRef string) (*Transaction, error) {
+	if session.Status() != SessionStatusCompleted || session.CompletedAt() == nil {
+		return nil, ErrTransactionSessionNotCompleted
+	}
+
+	discount, err := session.DiscountCents()
+	if err != nil {
+		return nil, err
+	}
+
+	items := session.DetectedItems()
+	lines := make([]TransactionLine, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, NewTransactionLine(item.SKUID(), item.Code(), item.Name(), item.Price(), item.Quantity(), item.Bundle()))
+	}
+
+	t := &Transaction{
+		id:            valueobjects.NewTransactionID(),
+		sessionID:     session.ID(),
+		deviceID:      session.DeviceID(),
+		userID:        session.UserID(),
+		lines:         lines,
+		total:         session.TotalAmount(),
+		taxCents:      session.Tax().Cents(),
+		roundingCents: session.RoundingCents(),
+		couponCode:    session.Coupon().Code(),
+		discountCents: discount,
+		paymentRef:    paymentRef,
+		fiscalRecord:  session.FiscalRecord(),
+		status:        TransactionStatusCompleted,
+		completedAt:   *session.CompletedAt(),
+	}
+	t.domainEvents = append(t.domainEvents, NewTransactionRecorded(t))
+
+	return t, nil
+}
+
+// ReconstituteTransaction rebuilds a Transaction from persistence
+func ReconstituteTransaction(
+	id valueobjects.TransactionID,
+	sessionID valueobjects.SessionID,
+	deviceID valueobjects.DeviceID,
+	userID string,
+	lines []TransactionLine,
+	total valueobjects.Money,
+	taxCents, roundingCents int64,
+	couponCode string,
+	discountCents int64,
+	paymentRef string,
+	fiscalRecord FiscalRecord,
+	status TransactionStatus,
+	completedAt time.Time,
+) *Transaction {
+	return &Transaction{
+		id:            id,
+		sessionID:     sessionID,
+		deviceID:      deviceID,
+		userID:        userID,
+		lines:         lines,
+		total:         total,
+		taxCents:      taxCents,
+		roundingCents: roundingCents,
+		couponCode:    couponCode,
+		discountCents: discountCents,
+		paymentRef:    paymentRef,
+		fiscalRecord:  fiscalRecord,
+		status:        status,
+		completedAt:   completedAt,
+	}
+}
+
+// Getters
+func (t *Transaction) ID() valueobjects.TransactionID    { return t.id }
+func (t *Transaction) SessionID() valueobjects.SessionID { return t.sessionID }
+func (t *Transaction) DeviceID() valueobjects.DeviceID   { return t.deviceID }
+func (t *Transaction) UserID() string                    { return t.userID }
+func (t *Transaction) Lines() []TransactionLine          { return append([]TransactionLine{}, t.lines...) }
+func (t *Transaction) Total() valueobjects.Money         { return t.total }
+func (t *Transaction) TaxCents() int64                   { return t.taxCents }
+func (t *Transaction) RoundingCents() int64              { return t.roundingCents }
+func (t *Transaction) CouponCode() string                { return t.couponCode }
+func (t *Transaction) DiscountCents() int64              { return t.discountCents }
+func (t *Transaction) PaymentRef() string                { return t.paymentRef }
+func (t *Transaction) FiscalRecord() FiscalRecord        { return t.fiscalRecord }
+func (t *Transaction) Status() TransactionStatus         { return t.status }
+func (t *Transaction) CompletedAt() time.Time            { return t.completedAt }
+
+// UnitCount is the number of units sold
+func (t *Transaction) UnitCount() int {
+	n := 0
+	for _, l := range t.lines {
+		n += l.Quantity()
+	}
+	return n
+}
+
+// PullEvents returns accumulated domain events and clears the slice
+func (t *Transaction) PullEvents() []events.DomainEvent {
+	evts := t.domainEvents
+	t.domainEvents = nil
+	return evts
+}
@@ -0,0 +1,52 @@
+package domain
+
+// UnrecognizedItem is a value object recording a code the device detected
+// that the catalog does not sell, kept on the session so the catalog team
+// can see what the model finds in baskets
+type UnrecognizedItem struct {
+	code       string
+	confidence float64
+	box        BoundingBox
+	located    bool // whether the device reported where the unit was
+}
+
+func NewUnrecognizedItem(code string, confidence float64) UnrecognizedItem {
+	return UnrecognizedItem{code: code, confidence: confidence}
+}
+
+// At returns the sighting with the box the unit was seen in
+func (u UnrecognizedItem) At(box BoundingBox) UnrecognizedItem {
+	u.box, u.located = box, true
+	return u
+}
+
+func (u UnrecognizedItem) Code() string             { return u.code }
+func (u UnrecognizedItem) Confidence() float64      { return u.confidence }
+func (u UnrecognizedItem) Box() (BoundingBox, bool) { return u.box, u.located }
+
+// RecordUnrecognized keeps the codes of a detection the catalog does not
+// sell. A code already recorded keeps its most confident sighting.
+func (s *Session) RecordUnrecognized(items []UnrecognizedItem) {
+	for _, item := range items {
+		known := false
+		for i, recorded := range s.unrecognized {
+			if recorded.code != item.code {
+				continue
+			}
+			known = true
+			if item.confidence > recorded.confidence {
+				s.unrecognized[i] = item
+			}
+			break
+		}
+		if !known {
+			s.unrecognized = append(s.unrecognized, item)
+		}
+	}
+}
+
+// UnrecognizedItems are the codes detected in the session that the catalog
+// does not sell, one per code, in the order first seen
+func (s *Session) UnrecognizedItems() []UnrecognizedItem {
+	return append([]UnrecognizedItem{}, s.unrecognized...)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// walkawayReason is the cancellation reason of sessions the customer walked away from
+const walkawayReason = "customer walked away without paying"
+
+// WalkawaySignal is what a device reports when the customer appears to have
+// left with the items
+type WalkawaySignal string
+
+const (
+	// WalkawaySignalDoorClosed is the door closing on the session
+	WalkawaySignalDoorClosed WalkawaySignal = "door_closed"
+	// WalkawaySignalItemsRemoved is the scale losing the weight of the items
+	WalkawaySignalItemsRemoved WalkawaySignal = "items_removed"
+)
+
+// ParseWalkawaySignal validates a signal received from a device
+func ParseWalkawaySignal(raw string) (WalkawaySignal, error) {
+	switch WalkawaySignal(raw) {
+	case WalkawaySignalDoorClosed, WalkawaySignalItemsRemoved:
+		return WalkawaySignal(raw), nil
+	default:
+		return "", ErrUnknownWalkawaySignal
+	}
+}
+
+// Walkaway is a value object recording the first signal that the customer
+// left the device while the session was still open
+type Walkaway struct {
+	signal     WalkawaySignal
+	signaledAt time.Time
+}
+
+func NewWalkaway(signal WalkawaySignal, signaledAt time.Time) Walkaway {
+	return Walkaway{signal: signal, signaledAt: signaledAt}
+}
+
+func (w Walkaway) Signal() WalkawaySignal { return w.signal }
+func (w Walkaway) SignaledAt() time.Time  { return w.signaledAt }
+func (w Walkaway) IsZero() bool           { return w == Walkaway{} }
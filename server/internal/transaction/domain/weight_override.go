@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// WeightDecision is how an attendant resolved a weight mismatch
+type WeightDecision string
+
+const (
+	// WeightDecisionApproved accepts the basket as detected
+	WeightDecisionApproved WeightDecision = "approved"
+	// WeightDecisionCorrected replaces the basket with what the attendant counted
+	WeightDecisionCorrected WeightDecision = "corrected"
+)
+
+// ParseWeightDecision validates a decision received from the attendant
+func ParseWeightDecision(raw string) (WeightDecision, error) {
+	switch WeightDecision(raw) {
+	case WeightDecisionApproved, WeightDecisionCorrected:
+		return WeightDecision(raw), nil
+	default:
+		return "", ErrUnknownWeightDecision
+	}
+}
+
+// WeightOverride is a value object recording who resolved a session's weight
+// mismatch, how and why
+type WeightOverride struct {
+	actorID      string
+	decision     WeightDecision
+	reason       string
+	overriddenAt time.Time
+}
+
+func NewWeightOverride(actorID string, decision WeightDecision, reason string, overriddenAt time.Time) WeightOverride {
+	return WeightOverride{
+		actorID:      actorID,
+		decision:     decision,
+		reason:       reason,
+		overriddenAt: overriddenAt,
+	}
+}
+
+func (o WeightOverride) ActorID() string          { return o.actorID }
+func (o WeightOverride) Decision() WeightDecision { return o.decision }
+func (o WeightOverride) Reason() string           { return o.reason }
+func (o WeightOverride) OverriddenAt() time.Time  { return o.overriddenAt }
+func (o WeightOverride) IsZero() bool             { return o == WeightOverride{} }
@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// ApplePayMerchantValidator implements ports.ApplePayMerchantValidator by
+// requesting a merchant session from Apple, authenticated with the merchant
+// identity certificate (mutual TLS)
+type ApplePayMerchantValidator struct {
+	merchantID  string
+	displayName string
+	httpClient  *http.Client
+}
+
+func NewApplePayMerchantValidator(merchantID, displayName, certFile, keyFile string) (*ApplePayMerchantValidator, error) {
+	if merchantID == "" {
+		panic("empty Apple Pay merchant ID")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Apple Pay merchant identity certificate: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	return &ApplePayMerchantValidator{
+		merchantID:  merchantID,
+		displayName: displayName,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+	}, nil
+}
+
+func (v *ApplePayMerchantValidator) ValidateMerchant(ctx context.Context, validationURL, domainName string) ([]byte, error) {
+	payload, _ := json.Marshal(map[string]string{
+		"merchantIdentifier": v.merchantID,
+		"displayName":        v.displayName,
+		"initiative":         "web",
+		"initiativeContext":  domainName,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, validationURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("apple pay request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apple pay response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apple pay returned status %d", resp.StatusCode)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("apple pay returned an invalid merchant session")
+	}
+
+	return data, nil
+}
+
+// DisabledApplePayMerchantValidator is used when no merchant identity is configured
+type DisabledApplePayMerchantValidator struct{}
+
+func NewDisabledApplePayMerchantValidator() *DisabledApplePayMerchantValidator {
+	return &DisabledApplePayMerchantValidator{}
+}
+
+func (DisabledApplePayMerchantValidator) ValidateMerchant(ctx context.Context, validationURL, domainName string) ([]byte, error) {
+	return nil, ports.ErrWalletNotConfigured
+}
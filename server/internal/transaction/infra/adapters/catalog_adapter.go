@@ -25,12 +25,49 @@ func (a *CatalogAdapter) FindSKUByCode(ctx context.Context, code string) (*ports
 		return nil, err
 	}
 
-	return &ports.SKUInfo{
+	info := &ports.SKUInfo{
 		ID:          view.ID,
 		Code:        view.Code,
 		Name:        view.Name,
 		PriceCents:  view.PriceCents,
 		Currency:    view.Currency,
 		WeightGrams: view.WeightGrams,
-	}, nil
+		Active:      view.Active,
+		CategoryID:  view.CategoryID,
+	}
+	// Calibrated SKUs are expected to weigh what they were measured at
+	if view.WeightCalibrated {
+		info.WeightGrams = view.WeightMeanGrams
+		info.WeightCalibrated = true
+		info.WeightStdDevGrams = view.WeightStdDevGrams
+	}
+	if len(view.Translations) > 0 {
+		info.Translations = make(map[string]ports.SKUTranslation, len(view.Translations))
+		for tag, t := range view.Translations {
+			info.Translations[tag] = ports.SKUTranslation(t)
+		}
+	}
+	return info, nil
+}
+
+func (a *CatalogAdapter) FindActiveBundles(ctx context.Context) ([]ports.BundleInfo, error) {
+	views, err := a.reader.FindActiveBundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make([]ports.BundleInfo, 0, len(views))
+	for _, view := range views {
+		bundle := ports.BundleInfo{
+			Code:       view.Code,
+			PriceCents: view.PriceCents,
+			Currency:   view.Currency,
+			Components: make(map[string]int, len(view.Components)),
+		}
+		for _, c := range view.Components {
+			bundle.Components[c.SKUID] = c.Quantity
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
 }
@@ -0,0 +1,41 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+
+	pricingapi "github.com/vending-machine/server/internal/pricing/api"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// CouponAdapter implements ports.Coupons using the pricing context API
+type CouponAdapter struct {
+	reader pricingapi.CouponReader
+}
+
+func NewCouponAdapter(reader pricingapi.CouponReader) *CouponAdapter {
+	if reader == nil {
+		panic("nil CouponReader")
+	}
+	return &CouponAdapter{reader: reader}
+}
+
+func (a *CouponAdapter) FindByCode(ctx context.Context, code string) (*ports.CouponInfo, error) {
+	view, err := a.reader.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pricingapi.ErrCouponNotFound) {
+			return nil, ports.ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	return &ports.CouponInfo{
+		Code:           view.Code,
+		Status:         view.Status,
+		PercentOff:     view.PercentOff,
+		AmountOffCents: view.AmountOffCents,
+		MinBasketCents: view.MinBasketCents,
+		Currency:       view.Currency,
+		MaxRedemptions: view.MaxRedemptions,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"context"
+
+	deviceapi "github.com/vending-machine/server/internal/device/api"
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/transaction/app"
+)
+
+// decommissionReason is recorded on sessions cancelled by a decommissioning
+const decommissionReason = "device decommissioned"
+
+// DecommissionListener cancels the session open on a device when the device
+// context takes it out of service. Like the sale listener it only sees events
+// published by this instance; a session it misses expires on its own.
+type DecommissionListener struct {
+	broker  *messaging.InProcessBroker
+	handler *app.CancelDeviceSessionHandler
+}
+
+func NewDecommissionListener(broker *messaging.InProcessBroker, handler *app.CancelDeviceSessionHandler) *DecommissionListener {
+	if broker == nil {
+		panic("nil InProcessBroker")
+	}
+	if handler == nil {
+		panic("nil CancelDeviceSessionHandler")
+	}
+	return &DecommissionListener{broker: broker, handler: handler}
+}
+
+// Run handles decommissioned devices until ctx is cancelled
+func (l *DecommissionListener) Run(ctx context.Context) {
+	evts, cancel := l.broker.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-evts:
+			if !ok {
+				return
+			}
+			deviceID, ok := deviceapi.DecommissionedDeviceID(evt)
+			if !ok {
+				continue
+			}
+			sessionID, err := l.handler.Handle(ctx, app.CancelDeviceSessionCommand{DeviceID: deviceID, Reason: decommissionReason})
+			if err != nil {
+				logger.Error("Failed to cancel session on decommissioned device", "device_id", deviceID, "error", err)
+				continue
+			}
+			if sessionID != "" {
+				logger.Info("Cancelled session on decommissioned device", "device_id", deviceID, "session_id", sessionID)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"time"
 
 	deviceapi "github.com/vending-machine/server/internal/device/api"
 	"github.com/vending-machine/server/internal/transaction/app/ports"
@@ -24,10 +25,48 @@ func (a *DeviceAdapter) FindByMachineID(ctx context.Context, machineID string) (
 	if err != nil {
 		return nil, err
 	}
+	return toDeviceInfo(view), nil
+}
+
+func (a *DeviceAdapter) FindByID(ctx context.Context, id string) (*ports.DeviceInfo, error) {
+	view, err := a.reader.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDeviceInfo(view), nil
+}
+
+func (a *DeviceAdapter) SalesStatus(ctx context.Context, deviceID string, at time.Time) (*ports.SalesStatus, error) {
+	view, err := a.reader.SalesStatusAt(ctx, deviceID, at)
+	if err != nil {
+		return nil, err
+	}
 
+	return &ports.SalesStatus{
+		Open:           view.Open,
+		ClosedReason:   view.ClosedReason,
+		RestrictedSKUs: view.RestrictedSKUs,
+		Markdowns:      view.Markdowns,
+	}, nil
+}
+
+func (a *DeviceAdapter) AssignedSKUs(ctx context.Context, deviceID string) (map[string]bool, error) {
+	return a.reader.AssignedSKUs(ctx, deviceID)
+}
+
+func toDeviceInfo(view *deviceapi.DeviceView) *ports.DeviceInfo {
 	return &ports.DeviceInfo{
 		ID:        view.ID,
 		MachineID: view.MachineID,
+		Region:    view.Region,
 		IsActive:  view.IsActive,
-	}, nil
+		IsBlocked: view.IsBlocked,
+
+		InMaintenance:             view.InMaintenance,
+		Restocking:                view.Restocking,
+		VerificationRequiredSince: view.VerificationRequiredSince,
+		ConfidenceThreshold:       view.ConfidenceThreshold,
+		ModelVersion:              view.ModelVersion,
+		SessionExpirationMinutes:  view.SessionExpirationMinutes,
+	}
 }
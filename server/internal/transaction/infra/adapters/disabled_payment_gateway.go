@@ -0,0 +1,34 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// DisabledPaymentGateway is used when no payment provider is configured.
+// Guest sessions are started without a payment intent and the app falls
+// back to an external payment reference on confirm.
+type DisabledPaymentGateway struct{}
+
+func NewDisabledPaymentGateway() *DisabledPaymentGateway {
+	return &DisabledPaymentGateway{}
+}
+
+func (DisabledPaymentGateway) Enabled() bool { return false }
+
+func (DisabledPaymentGateway) CreateIntent(ctx context.Context, sessionID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	return nil, ports.ErrPaymentGatewayDisabled
+}
+
+func (DisabledPaymentGateway) UpdateIntentAmount(ctx context.Context, intentID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	return nil, ports.ErrPaymentGatewayDisabled
+}
+
+func (DisabledPaymentGateway) GetIntent(ctx context.Context, intentID string) (*ports.PaymentIntent, error) {
+	return nil, ports.ErrPaymentGatewayDisabled
+}
+
+func (DisabledPaymentGateway) ConfirmWithWalletToken(ctx context.Context, intentID, wallet, token string) (*ports.PaymentIntent, error) {
+	return nil, ports.ErrPaymentGatewayDisabled
+}
@@ -0,0 +1,19 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// DisabledSessionDetector is used when no cloud detection backend is wired in;
+// reconciliation runs fail instead of reporting every item as a discrepancy
+type DisabledSessionDetector struct{}
+
+func NewDisabledSessionDetector() *DisabledSessionDetector {
+	return &DisabledSessionDetector{}
+}
+
+func (DisabledSessionDetector) DetectSession(ctx context.Context, deviceID string, image []byte) (ports.SessionDetectionResult, error) {
+	return ports.SessionDetectionResult{}, ports.ErrCloudDetectionUnavailable
+}
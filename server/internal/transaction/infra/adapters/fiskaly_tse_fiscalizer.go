@@ -0,0 +1,320 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+const fiskalyAPIBase = "https://kassensichv-middleware.fiskaly.com/api/v2"
+
+// errFiskalyNotFound is returned by a call for a resource fiskaly does not know
+var errFiskalyNotFound = errors.New("fiskaly resource not found")
+
+// FiskalyTSEConfig configures the German cloud TSE (KassenSichV) adapter
+type FiskalyTSEConfig struct {
+	APIKey    string
+	APISecret string
+	TSSID     string // technical security system
+	ClientID  string // registered client (the vending fleet's cash register)
+	VATRate   string // fiskaly VAT rate bucket, e.g. "NORMAL" or "REDUCED_1"
+}
+
+// FiskalyTSEFiscalizer implements ports.Fiscalizer for Germany using the
+// fiskaly SIGN DE cloud TSE. Every sale is recorded as a TSE transaction
+// that is started and finished in one go.
+type FiskalyTSEFiscalizer struct {
+	cfg        FiskalyTSEConfig
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFiskalyTSEFiscalizer(cfg FiskalyTSEConfig) *FiskalyTSEFiscalizer {
+	if cfg.APIKey == "" || cfg.APISecret == "" || cfg.TSSID == "" || cfg.ClientID == "" {
+		panic("incomplete fiskaly TSE configuration")
+	}
+	if cfg.VATRate == "" {
+		cfg.VATRate = "NORMAL"
+	}
+	return &FiskalyTSEFiscalizer{
+		cfg:        cfg,
+		baseURL:    fiskalyAPIBase,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type fiskalyTx struct {
+	State           string `json:"state"`
+	Number          int64  `json:"number"`
+	TimeEnd         int64  `json:"time_end"`
+	TSSSerialNumber string `json:"tss_serial_number"`
+	QRCodeData      string `json:"qr_code_data"`
+	Signature       struct {
+		Value   string `json:"value"`
+		Counter int64  `json:"counter"`
+	} `json:"signature"`
+	Schema struct {
+		StandardV1 struct {
+			Receipt struct {
+				AmountsPerPaymentType []struct {
+					Amount string `json:"amount"`
+				} `json:"amounts_per_payment_type"`
+			} `json:"receipt"`
+		} `json:"standard_v1"`
+	} `json:"schema"`
+}
+
+// Fiscalize signs the receipt as the session's TSE transaction. The first
+// transaction of a session has the session ID as its ID and any later one an
+// ID derived from it, so a retried confirmation walks the transactions of
+// the attempts before it: one signed for the receipt's total is the sale's
+// signature, one left active is finished with the receipt. One signed for
+// another total was issued for a basket that has changed since; it is
+// voided with a cancellation receipt and the sale signed as the next
+// transaction.
+func (f *FiskalyTSEFiscalizer) Fiscalize(ctx context.Context, receipt ports.FiscalReceipt) (*ports.FiscalSignature, error) {
+	token, err := f.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < fiskalyMaxAttempts; attempt++ {
+		txPath := f.txPath(fiskalyTxID(receipt.SessionID, "sale", attempt))
+
+		var tx fiskalyTx
+		err := f.call(ctx, http.MethodGet, txPath, token, nil, &tx)
+		switch {
+		case errors.Is(err, errFiskalyNotFound):
+			return f.sign(ctx, token, txPath, "RECEIPT", receipt.TotalCents, receipt.Currency, true)
+		case err != nil:
+			return nil, err
+		case tx.State != "FINISHED":
+			return f.sign(ctx, token, txPath, "RECEIPT", receipt.TotalCents, receipt.Currency, false)
+		}
+
+		signed, err := tx.signedCents()
+		if err != nil {
+			return nil, err
+		}
+		if signed == receipt.TotalCents {
+			return tx.signature(), nil
+		}
+		if err := f.cancel(ctx, token, fiskalyTxID(receipt.SessionID, "cancellation", attempt), signed, receipt.Currency); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("session %s was signed %d times for a changed basket", receipt.SessionID, fiskalyMaxAttempts)
+}
+
+// fiskalyMaxAttempts bounds the TSE transactions signed for one session
+const fiskalyMaxAttempts = 10
+
+// fiskalyTxID is the ID of a session's TSE transaction of the given kind.
+// The first sale keeps the session ID, which fiskaly takes as a UUID.
+func fiskalyTxID(sessionID, kind string, attempt int) string {
+	if kind == "sale" && attempt == 0 {
+		return sessionID
+	}
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s/%s/%d", sessionID, kind, attempt))).String()
+}
+
+func (f *FiskalyTSEFiscalizer) txPath(txID string) string {
+	return fmt.Sprintf("/tss/%s/tx/%s", url.PathEscape(f.cfg.TSSID), url.PathEscape(txID))
+}
+
+// cancel voids a signed sale with a cancellation receipt for its negated
+// amount, unless an earlier attempt already did
+func (f *FiskalyTSEFiscalizer) cancel(ctx context.Context, token, txID string, cents int64, currency string) error {
+	txPath := f.txPath(txID)
+	var tx fiskalyTx
+	err := f.call(ctx, http.MethodGet, txPath, token, nil, &tx)
+	switch {
+	case errors.Is(err, errFiskalyNotFound):
+		_, err = f.sign(ctx, token, txPath, "CANCELLATION", -cents, currency, true)
+	case err == nil && tx.State != "FINISHED":
+		_, err = f.sign(ctx, token, txPath, "CANCELLATION", -cents, currency, false)
+	}
+	return err
+}
+
+// sign finishes a TSE transaction with a receipt for the amount, starting it
+// first unless an earlier attempt left it active
+func (f *FiskalyTSEFiscalizer) sign(ctx context.Context, token, txPath, receiptType string, cents int64, currency string, start bool) (*ports.FiscalSignature, error) {
+	if start {
+		active := map[string]any{
+			"state":     "ACTIVE",
+			"client_id": f.cfg.ClientID,
+		}
+		if err := f.call(ctx, http.MethodPut, txPath+"?tx_revision=1", token, active, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	amount := formatDecimal(cents)
+	finish := map[string]any{
+		"state":     "FINISHED",
+		"client_id": f.cfg.ClientID,
+		"schema": map[string]any{
+			"standard_v1": map[string]any{
+				"receipt": map[string]any{
+					"receipt_type": receiptType,
+					"amounts_per_vat_rate": []map[string]string{
+						{"vat_rate": f.cfg.VATRate, "amount": amount},
+					},
+					"amounts_per_payment_type": []map[string]string{
+						{"payment_type": "NON_CASH", "amount": amount, "currency_code": currency},
+					},
+				},
+			},
+		},
+	}
+	var tx fiskalyTx
+	if err := f.call(ctx, http.MethodPut, txPath+"?tx_revision=2", token, finish, &tx); err != nil {
+		return nil, err
+	}
+
+	return tx.signature(), nil
+}
+
+// signedCents is the total of the receipt the transaction was signed with
+func (tx fiskalyTx) signedCents() (int64, error) {
+	var total int64
+	for _, p := range tx.Schema.StandardV1.Receipt.AmountsPerPaymentType {
+		cents, err := parseDecimal(p.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected fiskaly receipt amount %q: %w", p.Amount, err)
+		}
+		total += cents
+	}
+	return total, nil
+}
+
+func (tx fiskalyTx) signature() *ports.FiscalSignature {
+	return &ports.FiscalSignature{
+		Country:          "DE",
+		Provider:         "fiskaly-tse",
+		DocumentNumber:   strconv.FormatInt(tx.Number, 10),
+		SerialNumber:     tx.TSSSerialNumber,
+		Signature:        tx.Signature.Value,
+		SignatureCounter: tx.Signature.Counter,
+		QRCode:           tx.QRCodeData,
+		IssuedAt:         time.Unix(tx.TimeEnd, 0).UTC(),
+	}
+}
+
+// token returns a cached access token, re-authenticating shortly before expiry
+func (f *FiskalyTSEFiscalizer) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	var auth struct {
+		AccessToken          string `json:"access_token"`
+		AccessTokenExpiresIn int64  `json:"access_token_expires_in"`
+	}
+	creds := map[string]string{"api_key": f.cfg.APIKey, "api_secret": f.cfg.APISecret}
+	if err := f.call(ctx, http.MethodPost, "/auth", "", creds, &auth); err != nil {
+		return "", err
+	}
+
+	f.accessToken = auth.AccessToken
+	f.expiresAt = time.Now().Add(time.Duration(auth.AccessTokenExpiresIn)*time.Second - time.Minute)
+	return f.accessToken, nil
+}
+
+func (f *FiskalyTSEFiscalizer) call(ctx context.Context, method, path, token string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, f.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fiskaly request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read fiskaly response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", errFiskalyNotFound, bytes.TrimSpace(data))
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("fiskaly error %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode fiskaly response: %w", err)
+	}
+	return nil
+}
+
+// formatDecimal renders cents as a decimal string with two places
+func formatDecimal(cents int64) string {
+	if cents < 0 {
+		return "-" + formatDecimal(-cents)
+	}
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// parseDecimal reads a decimal string with two places, as written by
+// formatDecimal, back into cents
+func parseDecimal(amount string) (int64, error) {
+	units, fraction, ok := strings.Cut(amount, ".")
+	if !ok || len(fraction) != 2 {
+		return 0, errors.New("not a decimal with two places")
+	}
+	negative := strings.HasPrefix(units, "-")
+	whole, err := strconv.ParseInt(strings.TrimPrefix(units, "-"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		return -(whole*100 + cents), nil
+	}
+	return whole*100 + cents, nil
+}
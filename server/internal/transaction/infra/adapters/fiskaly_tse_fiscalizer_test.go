@@ -0,0 +1,191 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// fakeFiskaly keeps TSE transactions in memory and counts the ones it signs
+type fakeFiskaly struct {
+	mu      sync.Mutex
+	txs     map[string]*fiskalyTx
+	signed  int
+	counter int64
+	types   []string // receipt types in the order they were signed
+}
+
+func (f *fakeFiskaly) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/auth" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "access_token_expires_in": 3600})
+		return
+	}
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	tx, ok := f.txs[id]
+
+	switch r.Method {
+	case http.MethodGet:
+		if !ok {
+			http.Error(w, `{"code":"E_TX_NOT_FOUND"}`, http.StatusNotFound)
+			return
+		}
+	case http.MethodPut:
+		var body struct {
+			State  string `json:"state"`
+			Schema struct {
+				StandardV1 struct {
+					Receipt struct {
+						ReceiptType string `json:"receipt_type"`
+					} `json:"receipt"`
+				} `json:"standard_v1"`
+			} `json:"schema"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		switch {
+		case body.State == "ACTIVE" && !ok:
+			tx = &fiskalyTx{State: "ACTIVE"}
+			f.txs[id] = tx
+		case body.State == "FINISHED" && ok && tx.State == "ACTIVE":
+			f.counter++
+			f.signed++
+			tx.State = "FINISHED"
+			tx.Number = f.counter
+			tx.TSSSerialNumber = "serial"
+			tx.Signature.Value = "sig-" + id
+			tx.Signature.Counter = f.counter
+			tx.TimeEnd = time.Now().Unix()
+			_ = json.Unmarshal(data, tx)
+			f.types = append(f.types, body.Schema.StandardV1.Receipt.ReceiptType)
+		default:
+			http.Error(w, `{"code":"E_TX_REVISION"}`, http.StatusConflict)
+			return
+		}
+	}
+	_ = json.NewEncoder(w).Encode(tx)
+}
+
+func newTestFiskaly(t *testing.T) (*FiskalyTSEFiscalizer, *fakeFiskaly) {
+	fake := &fakeFiskaly{txs: make(map[string]*fiskalyTx)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	f := NewFiskalyTSEFiscalizer(FiskalyTSEConfig{APIKey: "key", APISecret: "secret", TSSID: "tss", ClientID: "client"})
+	f.baseURL = server.URL
+	return f, fake
+}
+
+func TestFiskalyTSEFiscalizerReturnsTheFirstSignatureToARetry(t *testing.T) {
+	ctx := context.Background()
+	f, fake := newTestFiskaly(t)
+	receipt := ports.FiscalReceipt{SessionID: "session-1", TotalCents: 250, Currency: "EUR"}
+
+	first, err := f.Fiscalize(ctx, receipt)
+	if err != nil {
+		t.Fatalf("Fiscalize: %v", err)
+	}
+	retry, err := f.Fiscalize(ctx, receipt)
+	if err != nil {
+		t.Fatalf("Fiscalize on retry: %v", err)
+	}
+
+	if *retry != *first {
+		t.Errorf("retry got %+v, want the first signature %+v", retry, first)
+	}
+	if fake.signed != 1 {
+		t.Errorf("%d TSE transactions were signed, want 1", fake.signed)
+	}
+}
+
+func TestFiskalyTSEFiscalizerFinishesATransactionLeftActive(t *testing.T) {
+	ctx := context.Background()
+	f, fake := newTestFiskaly(t)
+	// An earlier attempt started the transaction but never finished it
+	fake.txs["session-1"] = &fiskalyTx{State: "ACTIVE"}
+
+	sig, err := f.Fiscalize(ctx, ports.FiscalReceipt{SessionID: "session-1", TotalCents: 250, Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Fiscalize: %v", err)
+	}
+	if sig.Signature != "sig-session-1" {
+		t.Errorf("Signature = %q, want sig-session-1", sig.Signature)
+	}
+}
+
+func TestFiskalyTSEFiscalizerVoidsASignatureForAChangedBasket(t *testing.T) {
+	ctx := context.Background()
+	f, fake := newTestFiskaly(t)
+
+	// The first attempt was signed, then the basket changed before the
+	// confirmation was retried
+	first, err := f.Fiscalize(ctx, ports.FiscalReceipt{SessionID: "session-1", TotalCents: 250, Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Fiscalize: %v", err)
+	}
+	retry, err := f.Fiscalize(ctx, ports.FiscalReceipt{SessionID: "session-1", TotalCents: 400, Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Fiscalize on retry: %v", err)
+	}
+
+	if retry.Signature == first.Signature {
+		t.Errorf("retry got the signature of the changed basket %q", first.Signature)
+	}
+	if got := strings.Join(fake.types, " "); got != "RECEIPT CANCELLATION RECEIPT" {
+		t.Errorf("signed %q, want the first receipt voided and the new one signed", got)
+	}
+	cancellation := fake.txs[fiskalyTxID("session-1", "cancellation", 0)]
+	if cents, _ := cancellation.signedCents(); cents != -250 {
+		t.Errorf("cancellation of %d cents, want -250", cents)
+	}
+
+	// Retrying again with the same basket hands back the new signature
+	again, err := f.Fiscalize(ctx, ports.FiscalReceipt{SessionID: "session-1", TotalCents: 400, Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Fiscalize on second retry: %v", err)
+	}
+	if *again != *retry {
+		t.Errorf("second retry got %+v, want %+v", again, retry)
+	}
+	if fake.signed != 3 {
+		t.Errorf("%d TSE transactions were signed, want 3", fake.signed)
+	}
+}
+
+func TestParseDecimalReadsFormatDecimalBack(t *testing.T) {
+	for _, cents := range []int64{0, 5, 99, 100, 250, -250, -5, 123456789} {
+		got, err := parseDecimal(formatDecimal(cents))
+		if err != nil || got != cents {
+			t.Errorf("parseDecimal(%q) = %d, %v, want %d", formatDecimal(cents), got, err, cents)
+		}
+	}
+	for _, amount := range []string{"", "2", "2.5", "a.00", "2.ab"} {
+		if _, err := parseDecimal(amount); err == nil {
+			t.Errorf("parseDecimal(%q) succeeded", amount)
+		}
+	}
+}
+
+func TestFiskalyTSEFiscalizerSignsEachSessionSeparately(t *testing.T) {
+	ctx := context.Background()
+	f, fake := newTestFiskaly(t)
+
+	for _, id := range []string{"session-1", "session-2"} {
+		if _, err := f.Fiscalize(ctx, ports.FiscalReceipt{SessionID: id, TotalCents: 250, Currency: "EUR"}); err != nil {
+			t.Fatalf("Fiscalize %s: %v", id, err)
+		}
+	}
+	if fake.signed != 2 {
+		t.Errorf("%d TSE transactions were signed, want 2", fake.signed)
+	}
+}
@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// ItalyRTFiscalizer implements ports.Fiscalizer for Italy. Sales are sent as
+// "documenti commerciali" to an RT server (Registratore Telematico) through
+// its HTTP gateway; the RT transmits the daily totals to the Agenzia delle Entrate.
+type ItalyRTFiscalizer struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewItalyRTFiscalizer(endpoint, apiKey string) *ItalyRTFiscalizer {
+	if endpoint == "" {
+		panic("empty RT server endpoint")
+	}
+	return &ItalyRTFiscalizer{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type rtDocumentLine struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	Amount      string `json:"amount"`
+}
+
+type rtDocumentRequest struct {
+	ExternalID  string           `json:"external_id"`
+	Lines       []rtDocumentLine `json:"lines"`
+	Total       string           `json:"total"`
+	PaymentType string           `json:"payment_type"`
+}
+
+type rtDocumentResponse struct {
+	DocumentNumber string    `json:"document_number"` // "<z-report>-<progressive>"
+	SerialNumber   string    `json:"serial_number"`
+	IssuedAt       time.Time `json:"issued_at"`
+}
+
+func (f *ItalyRTFiscalizer) Fiscalize(ctx context.Context, receipt ports.FiscalReceipt) (*ports.FiscalSignature, error) {
+	doc := rtDocumentRequest{
+		ExternalID:  receipt.SessionID,
+		Total:       formatDecimal(receipt.TotalCents),
+		PaymentType: "electronic",
+	}
+	for _, line := range receipt.Lines {
+		doc.Lines = append(doc.Lines, rtDocumentLine{
+			Description: line.Name,
+			Quantity:    line.Quantity,
+			Amount:      formatDecimal(line.PriceCents),
+		})
+	}
+	// The lines must add up to the total, so the coupon discount and cash
+	// rounding get lines of their own
+	if receipt.DiscountCents != 0 {
+		doc.Lines = append(doc.Lines, rtDocumentLine{
+			Description: "Sconto",
+			Quantity:    1,
+			Amount:      formatDecimal(-receipt.DiscountCents),
+		})
+	}
+	if receipt.RoundingCents != 0 {
+		doc.Lines = append(doc.Lines, rtDocumentLine{
+			Description: "Arrotondamento",
+			Quantity:    1,
+			Amount:      formatDecimal(receipt.RoundingCents),
+		})
+	}
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint+"/documents", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+f.apiKey)
+	}
+	// The RT must not issue two documents for the same sale. A retry for a
+	// basket that changed since is a different sale to the RT, which issues
+	// a document of its own for it; the first one has to be annulled at the RT.
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("session-%s-%d", receipt.SessionID, receipt.TotalCents))
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RT server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RT server response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("RT server error %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var out rtDocumentResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode RT server response: %w", err)
+	}
+
+	return &ports.FiscalSignature{
+		Country:        "IT",
+		Provider:       "rt-server",
+		DocumentNumber: out.DocumentNumber,
+		SerialNumber:   out.SerialNumber,
+		IssuedAt:       out.IssuedAt.UTC(),
+	}, nil
+}
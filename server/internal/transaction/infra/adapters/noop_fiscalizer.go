@@ -0,0 +1,18 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// NoOpFiscalizer is used in countries without fiscal receipt requirements
+type NoOpFiscalizer struct{}
+
+func NewNoOpFiscalizer() *NoOpFiscalizer {
+	return &NoOpFiscalizer{}
+}
+
+func (NoOpFiscalizer) Fiscalize(ctx context.Context, receipt ports.FiscalReceipt) (*ports.FiscalSignature, error) {
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"context"
+
+	deviceapi "github.com/vending-machine/server/internal/device/api"
+	"github.com/vending-machine/server/internal/pkg/logger"
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/transaction/app"
+)
+
+// offlineReason is recorded on sessions cancelled because their device went offline
+const offlineReason = "device went offline"
+
+// OfflineListener cancels the session open on a device the offline sweep
+// found silent, so a shopper is not left with a door that will never report
+// back. It is off unless configured; a session it misses expires on its own.
+type OfflineListener struct {
+	broker  *messaging.InProcessBroker
+	handler *app.CancelDeviceSessionHandler
+}
+
+func NewOfflineListener(broker *messaging.InProcessBroker, handler *app.CancelDeviceSessionHandler) *OfflineListener {
+	if broker == nil {
+		panic("nil InProcessBroker")
+	}
+	if handler == nil {
+		panic("nil CancelDeviceSessionHandler")
+	}
+	return &OfflineListener{broker: broker, handler: handler}
+}
+
+// Run handles devices going offline until ctx is cancelled
+func (l *OfflineListener) Run(ctx context.Context) {
+	evts, cancel := l.broker.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-evts:
+			if !ok {
+				return
+			}
+			deviceID, ok := deviceapi.OfflineDeviceID(evt)
+			if !ok {
+				continue
+			}
+			sessionID, err := l.handler.Handle(ctx, app.CancelDeviceSessionCommand{DeviceID: deviceID, Reason: offlineReason})
+			if err != nil {
+				logger.Error("Failed to cancel session on offline device", "device_id", deviceID, "error", err)
+				continue
+			}
+			if sessionID != "" {
+				logger.Info("Cancelled session on offline device", "device_id", deviceID, "session_id", sessionID)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	pricingapi "github.com/vending-machine/server/internal/pricing/api"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// PricingAdapter implements ports.PriceExperiments using the pricing context API
+type PricingAdapter struct {
+	reader pricingapi.ExperimentReader
+}
+
+func NewPricingAdapter(reader pricingapi.ExperimentReader) *PricingAdapter {
+	if reader == nil {
+		panic("nil ExperimentReader")
+	}
+	return &PricingAdapter{reader: reader}
+}
+
+func (a *PricingAdapter) AssignmentsFor(ctx context.Context, deviceID string, at time.Time) ([]ports.ExperimentAssignment, error) {
+	views, err := a.reader.AssignmentsFor(ctx, deviceID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]ports.ExperimentAssignment, 0, len(views))
+	for _, view := range views {
+		assignments = append(assignments, ports.ExperimentAssignment{
+			ExperimentID: view.ExperimentID,
+			Variant:      view.Variant,
+			Prices:       view.Prices,
+		})
+	}
+	return assignments, nil
+}
@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/shared/events"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// SessionChangeFeed implements ports.SessionChangeFeed on top of the
+// in-process event broker; it only sees sessions changed by this instance
+type SessionChangeFeed struct {
+	broker *messaging.InProcessBroker
+}
+
+func NewSessionChangeFeed(broker *messaging.InProcessBroker) *SessionChangeFeed {
+	return &SessionChangeFeed{broker: broker}
+}
+
+func (f *SessionChangeFeed) Subscribe() (<-chan string, func()) {
+	evts, cancel := f.broker.Subscribe()
+
+	sessionIDs := make(chan string, cap(evts))
+	go func() {
+		defer close(sessionIDs)
+		for evt := range evts {
+			id, ok := changedSession(evt)
+			if !ok {
+				continue
+			}
+			select {
+			case sessionIDs <- id:
+			default:
+			}
+		}
+	}()
+
+	return sessionIDs, cancel
+}
+
+// changedSession returns the session a transaction event changed
+func changedSession(evt events.DomainEvent) (string, bool) {
+	switch e := evt.(type) {
+	case domain.SessionStarted:
+		return e.SessionID.String(), true
+	case domain.ItemsDetected:
+		return e.SessionID.String(), true
+	case domain.SessionCompleted:
+		return e.SessionID.String(), true
+	case domain.SessionCancelled:
+		return e.SessionID.String(), true
+	case domain.SessionExtended:
+		return e.SessionID.String(), true
+	case domain.PaymentFailed:
+		return e.SessionID.String(), true
+	case domain.FraudSuspected:
+		return e.SessionID.String(), true
+	case domain.CouponApplied:
+		return e.SessionID.String(), true
+	case domain.ItemReviewed:
+		return e.SessionID.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,170 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+const (
+	stripeAPIBase = "https://api.stripe.com/v1"
+
+	// Stripe rejects intents below the minimum chargeable amount, so guest
+	// intents are opened at this amount and updated once items are detected.
+	stripeMinimumAmountCents = 50
+)
+
+// StripePaymentGateway implements ports.PaymentGateway using the Stripe
+// PaymentIntents REST API
+type StripePaymentGateway struct {
+	secretKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewStripePaymentGateway(secretKey string) *StripePaymentGateway {
+	if secretKey == "" {
+		panic("empty Stripe secret key")
+	}
+	return &StripePaymentGateway{
+		secretKey:  secretKey,
+		baseURL:    stripeAPIBase,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// stripeIntent is the subset of the Stripe PaymentIntent object we use
+type stripeIntent struct {
+	ID           string `json:"id"`
+	ClientSecret string `json:"client_secret"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+
+	// Only populated when requested with expand[]=payment_method
+	PaymentMethod *struct {
+		Card *struct {
+			Brand  string `json:"brand"`
+			Last4  string `json:"last4"`
+			Wallet *struct {
+				Type         string `json:"type"`
+				DynamicLast4 string `json:"dynamic_last4"`
+			} `json:"wallet"`
+		} `json:"card"`
+	} `json:"payment_method"`
+}
+
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (g *StripePaymentGateway) Enabled() bool { return true }
+
+func (g *StripePaymentGateway) CreateIntent(ctx context.Context, sessionID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(max(amountCents, stripeMinimumAmountCents), 10))
+	form.Set("currency", strings.ToLower(currency))
+	form.Set("capture_method", "automatic")
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("metadata[session_id]", sessionID)
+
+	// One intent per session, even if the request is retried
+	return g.call(ctx, http.MethodPost, "/payment_intents", form, "session-intent-"+sessionID)
+}
+
+func (g *StripePaymentGateway) UpdateIntentAmount(ctx context.Context, intentID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(max(amountCents, stripeMinimumAmountCents), 10))
+	form.Set("currency", strings.ToLower(currency))
+
+	return g.call(ctx, http.MethodPost, "/payment_intents/"+url.PathEscape(intentID), form, "")
+}
+
+func (g *StripePaymentGateway) GetIntent(ctx context.Context, intentID string) (*ports.PaymentIntent, error) {
+	return g.call(ctx, http.MethodGet, "/payment_intents/"+url.PathEscape(intentID), nil, "")
+}
+
+// ConfirmWithWalletToken confirms the intent with a wallet token produced by
+// Stripe.js / the mobile SDK from the Apple Pay or Google Pay payment data.
+// Stripe decrypts the device token, so no card data passes through us.
+func (g *StripePaymentGateway) ConfirmWithWalletToken(ctx context.Context, intentID, wallet, token string) (*ports.PaymentIntent, error) {
+	form := url.Values{}
+	form.Set("payment_method_data[type]", "card")
+	form.Set("payment_method_data[card][token]", token)
+	form.Set("payment_method_data[metadata][wallet]", wallet)
+	form.Add("expand[]", "payment_method")
+
+	return g.call(ctx, http.MethodPost, "/payment_intents/"+url.PathEscape(intentID)+"/confirm", form, "wallet-confirm-"+intentID)
+}
+
+func (g *StripePaymentGateway) call(ctx context.Context, method, path string, form url.Values, idempotencyKey string) (*ports.PaymentIntent, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.secretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stripe response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var se stripeError
+		_ = json.Unmarshal(data, &se)
+		return nil, fmt.Errorf("stripe error %d (%s): %s", resp.StatusCode, se.Error.Type, se.Error.Message)
+	}
+
+	var intent stripeIntent
+	if err := json.Unmarshal(data, &intent); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+
+	result := &ports.PaymentIntent{
+		ID:           intent.ID,
+		ClientSecret: intent.ClientSecret,
+		AmountCents:  intent.Amount,
+		Currency:     strings.ToUpper(intent.Currency),
+		Status:       ports.PaymentIntentStatus(intent.Status),
+	}
+	if pm := intent.PaymentMethod; pm != nil && pm.Card != nil {
+		card := &ports.CardDetails{Brand: pm.Card.Brand, Last4: pm.Card.Last4}
+		if w := pm.Card.Wallet; w != nil {
+			card.Wallet = w.Type
+			// For wallets the receipt should show the device account number
+			if w.DynamicLast4 != "" {
+				card.Last4 = w.DynamicLast4
+			}
+		}
+		result.Card = card
+	}
+	return result, nil
+}
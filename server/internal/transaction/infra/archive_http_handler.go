@@ -0,0 +1,38 @@
+package infra
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+)
+
+// SweepArchive runs the session archival now instead of waiting for the next
+// scheduled one. ?after_days= overrides how long ended sessions are kept in
+// the hot table.
+func (h *HTTPHandler) SweepArchive(c *gin.Context) {
+	var cmd app.ArchiveSessionsCommand
+	if raw := c.Query("after_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after_days must be a non-negative number"})
+			return
+		}
+		after := time.Duration(days) * 24 * time.Hour
+		cmd.After = &after
+	}
+
+	result, err := h.archiveSweeper.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"after_days": int(result.After / (24 * time.Hour)),
+		"count":      result.Archived,
+	})
+}
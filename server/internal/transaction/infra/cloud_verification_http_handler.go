@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type verifyImageRequest struct {
+	Image []byte `json:"image"` // base64
+}
+
+// VerifyImage takes the image a device uploads when a detection came back
+// with needs_cloud_ml and updates the basket from the cloud model's view of it
+func (h *HTTPHandler) VerifyImage(c *gin.Context) {
+	var req verifyImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.verifyHandler.Handle(c.Request.Context(), app.VerifySessionImageCommand{
+		SessionID: c.Param("id"),
+		Image:     req.Image,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrVerificationImageRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
+		case errors.Is(err, ports.ErrCloudDetectionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		case errors.Is(err, app.ErrCloudDetectionFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "cloud detection failed"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrCurrencyConversionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "code": "currency_conversion_unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	verified := make([]gin.H, 0, len(result.Verified))
+	for _, v := range result.Verified {
+		verified = append(verified, gin.H{
+			"sku":            v.SKU,
+			"edge_quantity":  v.EdgeQuantity,
+			"cloud_quantity": v.CloudQuantity,
+			"quantity":       v.Quantity,
+		})
+	}
+
+	response := h.detectionResponse(c, result.SubmitDetectionResult)
+	response["model_version"] = result.ModelVersion
+	response["strategy"] = result.Strategy
+	response["verified"] = verified
+	response["corrected"] = result.Corrected
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,70 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type applyCouponRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ApplyCoupon takes a coupon off the session's basket and returns the
+// session priced with the discount
+func (h *HTTPHandler) ApplyCoupon(c *gin.Context) {
+	var req applyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.applyCouponHandler.Handle(c.Request.Context(), app.ApplyCouponCommand{
+		SessionID: c.Param("id"),
+		Code:      req.Code,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrCouponNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrNoItemsDetected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no items detected"})
+		case isCouponNotApplicable(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "coupon_not_applicable"})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	view, err := h.queryService.FindByID(c.Request.Context(), result.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(c.Request.Context(), view, preferredLanguages(c)))
+}
+
+// isCouponNotApplicable tells whether the coupon exists but cannot be used
+// on the basket
+func isCouponNotApplicable(err error) bool {
+	return errors.Is(err, domain.ErrCouponExpired) ||
+		errors.Is(err, domain.ErrCouponDisabled) ||
+		errors.Is(err, domain.ErrCouponExhausted) ||
+		errors.Is(err, domain.ErrCouponBasketTooSmall) ||
+		errors.Is(err, domain.ErrCouponCurrencyMismatch)
+}
@@ -0,0 +1,39 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ListSessionDetections lists every detection submitted for the session with
+// the decision taken on it, for disputes and model debugging
+func (h *HTTPHandler) ListSessionDetections(c *gin.Context) {
+	views, err := h.detections.ListBySession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	detections := make([]gin.H, 0, len(views))
+	for _, v := range views {
+		detections = append(detections, gin.H{
+			"id":          v.ID,
+			"device_id":   v.DeviceID,
+			"source":      v.Source,
+			"payload":     json.RawMessage(v.Payload),
+			"outcome":     v.Outcome,
+			"decision":    json.RawMessage(v.Decision),
+			"received_at": v.ReceivedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"session_id": c.Param("id"), "detections": detections})
+}
@@ -0,0 +1,302 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type openDisputeRequest struct {
+	AmountCents int64  `json:"amount_cents"` // omitted disputes the whole total
+	Reason      string `json:"reason" binding:"required"`
+	ProviderRef string `json:"provider_ref"`
+}
+
+type disputeNoteRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+type disputeEvidenceRequest struct {
+	Kind string `json:"kind" binding:"required"`
+	Note string `json:"note"`
+}
+
+type resolveDisputeRequest struct {
+	Outcome string `json:"outcome" binding:"required"`
+	Note    string `json:"note"`
+}
+
+func (h *HTTPHandler) OpenDispute(c *gin.Context) {
+	var req openDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, roles := actorFromRequest(c)
+	view, err := h.openDisputeHandler.Handle(c.Request.Context(), app.OpenDisputeCommand{
+		SessionID:   c.Param("id"),
+		AmountCents: req.AmountCents,
+		Reason:      req.Reason,
+		ProviderRef: req.ProviderRef,
+		ActorID:     actorID,
+		ActorRoles:  roles,
+	})
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, disputeViewResponse(view))
+}
+
+func (h *HTTPHandler) AnnotateDispute(c *gin.Context) {
+	var req disputeNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := actorFromRequest(c)
+	view, err := h.annotateDisputeHandler.Handle(c.Request.Context(), app.AnnotateDisputeCommand{
+		DisputeID: c.Param("id"),
+		Note:      req.Note,
+		ActorID:   actorID,
+	})
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, disputeViewResponse(view))
+}
+
+func (h *HTTPHandler) AttachDisputeEvidence(c *gin.Context) {
+	var req disputeEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := actorFromRequest(c)
+	view, err := h.disputeEvidenceHandler.Handle(c.Request.Context(), app.AttachDisputeEvidenceCommand{
+		DisputeID: c.Param("id"),
+		Kind:      req.Kind,
+		Note:      req.Note,
+		ActorID:   actorID,
+	})
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, disputeViewResponse(view))
+}
+
+func (h *HTTPHandler) ResolveDispute(c *gin.Context) {
+	var req resolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, roles := actorFromRequest(c)
+	view, err := h.resolveDisputeHandler.Handle(c.Request.Context(), app.ResolveDisputeCommand{
+		DisputeID:  c.Param("id"),
+		Outcome:    req.Outcome,
+		Note:       req.Note,
+		ActorID:    actorID,
+		ActorRoles: roles,
+	})
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disputeViewResponse(view))
+}
+
+func (h *HTTPHandler) GetDispute(c *gin.Context) {
+	view, err := h.disputeQueries.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disputeViewResponse(view))
+}
+
+// GetDisputeImage serves the session image attached to a dispute, for
+// forwarding to the payment provider
+func (h *HTTPHandler) GetDisputeImage(c *gin.Context) {
+	image, err := h.disputeQueries.Image(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, http.DetectContentType(image), image)
+}
+
+func (h *HTTPHandler) ListSessionDisputes(c *gin.Context) {
+	views, err := h.disputeQueries.FindBySessionID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputeViewsResponse(views)})
+}
+
+func (h *HTTPHandler) ListDisputes(c *gin.Context) {
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	views, err := h.disputeQueries.List(c.Request.Context(), app.DisputeListQuery{
+		Status: c.Query("status"),
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		h.writeDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputeViewsResponse(views)})
+}
+
+// DisputeReport sums up the disputes opened in a period by status and
+// currency, for the finance team
+func (h *HTTPHandler) DisputeReport(c *gin.Context) {
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.disputeQueries.Report(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	lines := make([]gin.H, 0, len(report.Lines))
+	for _, line := range report.Lines {
+		lines = append(lines, gin.H{
+			"status":       line.Status,
+			"currency":     line.Currency,
+			"count":        line.Count,
+			"amount_cents": line.AmountCents,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"lines": lines})
+}
+
+// parsePeriod reads the optional from (inclusive) and to (exclusive)
+// RFC 3339 bounds of a listing or report
+func parsePeriod(c *gin.Context) (from, to time.Time, err error) {
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if raw := c.Query(param); raw != "" {
+			t, perr := time.Parse(time.RFC3339, raw)
+			if perr != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = t
+		}
+	}
+	return from, to, nil
+}
+
+func (h *HTTPHandler) writeDisputeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrActorRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "acting user is required"})
+	case errors.Is(err, domain.ErrDisputeResolverNotAuthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+	case errors.Is(err, domain.ErrDisputeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "dispute not found"})
+	case errors.Is(err, domain.ErrNoDisputeEvidence):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidDisputeAmount),
+		errors.Is(err, domain.ErrDisputeReasonMissing),
+		errors.Is(err, domain.ErrDisputeNoteMissing),
+		errors.Is(err, domain.ErrUnknownDisputeOutcome),
+		errors.Is(err, domain.ErrUnknownDisputeEvidence),
+		errors.Is(err, app.ErrUnknownDisputeStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDisputeAlreadyOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDisputeSessionNotCompleted),
+		errors.Is(err, domain.ErrDisputeCurrencyMismatch),
+		errors.Is(err, domain.ErrDisputeExceedsTotal),
+		errors.Is(err, domain.ErrDisputeNotOpen):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func disputeViewsResponse(views []*app.DisputeView) []gin.H {
+	out := make([]gin.H, 0, len(views))
+	for _, v := range views {
+		out = append(out, disputeViewResponse(v))
+	}
+	return out
+}
+
+func disputeViewResponse(v *app.DisputeView) gin.H {
+	notes := make([]gin.H, 0, len(v.Notes))
+	for _, n := range v.Notes {
+		notes = append(notes, gin.H{
+			"actor_id": n.ActorID,
+			"note":     n.Note,
+			"at":       n.At,
+		})
+	}
+
+	evidence := make([]gin.H, 0, len(v.Evidence))
+	for _, e := range v.Evidence {
+		entry := gin.H{
+			"kind":        e.Kind,
+			"attached_by": e.AttachedBy,
+			"note":        e.Note,
+			"at":          e.At,
+		}
+		switch e.Kind {
+		case string(domain.DisputeEvidenceDetections):
+			entry["detection_ids"] = e.DetectionIDs
+		case string(domain.DisputeEvidenceImage):
+			entry["image_bytes"] = e.ImageBytes
+		}
+		evidence = append(evidence, entry)
+	}
+
+	return gin.H{
+		"id":              v.ID,
+		"session_id":      v.SessionID,
+		"transaction_id":  v.TransactionID,
+		"amount_cents":    v.AmountCents,
+		"currency":        v.Currency,
+		"reason":          v.Reason,
+		"provider_ref":    v.ProviderRef,
+		"status":          v.Status,
+		"opened_by":       v.OpenedBy,
+		"resolved_by":     v.ResolvedBy,
+		"resolution_note": v.ResolutionNote,
+		"notes":           notes,
+		"evidence":        evidence,
+		"created_at":      v.CreatedAt,
+		"resolved_at":     v.ResolvedAt,
+	}
+}
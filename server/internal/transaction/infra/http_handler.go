@@ -1,12 +1,16 @@
 package infra
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/vending-machine/server/internal/platform/webhook"
+	"github.com/vending-machine/server/internal/shared/locale"
 	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
 	"github.com/vending-machine/server/internal/transaction/domain"
 )
 
@@ -14,31 +18,127 @@ import (
 type HTTPHandler struct {
 	startHandler     *app.StartSessionHandler
 	submitHandler    *app.SubmitDetectionHandler
+	verifyHandler    *app.VerifySessionImageHandler
 	confirmHandler   *app.ConfirmSessionHandler
 	cancelHandler    *app.CancelSessionHandler
+	walletHandler    *app.PayWithWalletHandler
+	paymentHandler   *app.CreatePaymentIntentHandler
+	merchantHandler  *app.ValidateApplePayMerchantHandler
+	refundHandler    *app.RequestRefundHandler
+	decideHandler    *app.DecideRefundHandler
 	queryService     *app.SessionQueryService
+	refundQueries    *app.RefundQueryService
+	recommendations  *app.RecommendationService
+	sessionStream    *app.SessionStreamService
+	syncHandler      *app.SyncOfflineEntriesHandler
+	reconcileHandler *app.ReconcileSessionsHandler
+	reconciliations  *app.ReconciliationQueryService
+	localizer        *app.ItemLocalizer
+	detections       *app.DetectionLogQueryService
+
+	paymentEventHandler    *app.ProcessPaymentEventHandler
+	paymentWebhookVerifier *webhook.Verifier
+	overrideWeightHandler  *app.OverrideWeightHandler
+	applyCouponHandler     *app.ApplyCouponHandler
+	extendHandler          *app.ExtendSessionHandler
+	reviewItemHandler      *app.ReviewItemHandler
+	resumeHandler          *app.ResumeSessionHandler
+	walkawayHandler        *app.SignalWalkawayHandler
+	walkawaySweeper        *app.CancelWalkawaysHandler
+	addSessionNoteHandler  *app.AddSessionNoteHandler
+	openDisputeHandler     *app.OpenDisputeHandler
+	annotateDisputeHandler *app.AnnotateDisputeHandler
+	disputeEvidenceHandler *app.AttachDisputeEvidenceHandler
+	resolveDisputeHandler  *app.ResolveDisputeHandler
+	disputeQueries         *app.DisputeQueryService
+	transactionQueries     *app.TransactionQueryService
+	archiveSweeper         *app.ArchiveSessionsHandler
 }
 
 func NewHTTPHandler(
 	startHandler *app.StartSessionHandler,
 	submitHandler *app.SubmitDetectionHandler,
+	verifyHandler *app.VerifySessionImageHandler,
 	confirmHandler *app.ConfirmSessionHandler,
 	cancelHandler *app.CancelSessionHandler,
+	walletHandler *app.PayWithWalletHandler,
+	paymentHandler *app.CreatePaymentIntentHandler,
+	merchantHandler *app.ValidateApplePayMerchantHandler,
+	refundHandler *app.RequestRefundHandler,
+	decideHandler *app.DecideRefundHandler,
 	queryService *app.SessionQueryService,
+	refundQueries *app.RefundQueryService,
+	recommendations *app.RecommendationService,
+	sessionStream *app.SessionStreamService,
+	syncHandler *app.SyncOfflineEntriesHandler,
+	reconcileHandler *app.ReconcileSessionsHandler,
+	reconciliations *app.ReconciliationQueryService,
+	localizer *app.ItemLocalizer,
+	detections *app.DetectionLogQueryService,
+	paymentEventHandler *app.ProcessPaymentEventHandler,
+	paymentWebhookVerifier *webhook.Verifier,
+	overrideWeightHandler *app.OverrideWeightHandler,
+	applyCouponHandler *app.ApplyCouponHandler,
+	extendHandler *app.ExtendSessionHandler,
+	reviewItemHandler *app.ReviewItemHandler,
+	resumeHandler *app.ResumeSessionHandler,
+	walkawayHandler *app.SignalWalkawayHandler,
+	walkawaySweeper *app.CancelWalkawaysHandler,
+	addSessionNoteHandler *app.AddSessionNoteHandler,
+	openDisputeHandler *app.OpenDisputeHandler,
+	annotateDisputeHandler *app.AnnotateDisputeHandler,
+	disputeEvidenceHandler *app.AttachDisputeEvidenceHandler,
+	resolveDisputeHandler *app.ResolveDisputeHandler,
+	disputeQueries *app.DisputeQueryService,
+	transactionQueries *app.TransactionQueryService,
+	archiveSweeper *app.ArchiveSessionsHandler,
 ) *HTTPHandler {
 	return &HTTPHandler{
-		startHandler:   startHandler,
-		submitHandler:  submitHandler,
-		confirmHandler: confirmHandler,
-		cancelHandler:  cancelHandler,
-		queryService:   queryService,
+		startHandler:     startHandler,
+		submitHandler:    submitHandler,
+		verifyHandler:    verifyHandler,
+		confirmHandler:   confirmHandler,
+		cancelHandler:    cancelHandler,
+		walletHandler:    walletHandler,
+		paymentHandler:   paymentHandler,
+		merchantHandler:  merchantHandler,
+		refundHandler:    refundHandler,
+		decideHandler:    decideHandler,
+		queryService:     queryService,
+		refundQueries:    refundQueries,
+		recommendations:  recommendations,
+		sessionStream:    sessionStream,
+		syncHandler:      syncHandler,
+		reconcileHandler: reconcileHandler,
+		reconciliations:  reconciliations,
+		localizer:        localizer,
+		detections:       detections,
+
+		paymentEventHandler:    paymentEventHandler,
+		paymentWebhookVerifier: paymentWebhookVerifier,
+		overrideWeightHandler:  overrideWeightHandler,
+		applyCouponHandler:     applyCouponHandler,
+		extendHandler:          extendHandler,
+		reviewItemHandler:      reviewItemHandler,
+		resumeHandler:          resumeHandler,
+		walkawayHandler:        walkawayHandler,
+		walkawaySweeper:        walkawaySweeper,
+		addSessionNoteHandler:  addSessionNoteHandler,
+		openDisputeHandler:     openDisputeHandler,
+		annotateDisputeHandler: annotateDisputeHandler,
+		disputeEvidenceHandler: disputeEvidenceHandler,
+		resolveDisputeHandler:  resolveDisputeHandler,
+		disputeQueries:         disputeQueries,
+		transactionQueries:     transactionQueries,
+		archiveSweeper:         archiveSweeper,
 	}
 }
 
 // Request/Response DTOs
 
 type startSessionRequest struct {
-	MachineID string `json:"machine_id" binding:"required"`
+	Token     string `json:"token"`
+	MachineID string `json:"machine_id"`
 	UserID    string `json:"user_id"`
 }
 
@@ -47,6 +147,28 @@ type submitDetectionRequest struct {
 	SessionID   string                `json:"session_id" binding:"required"`
 	Items       []detectedItemRequest `json:"items" binding:"required"`
 	TotalWeight float64               `json:"total_weight"`
+	Image       []byte                `json:"image"` // base64, optional
+	// ModelVersion is the detection model that produced the items
+	ModelVersion string `json:"model_version"`
+	// DetectionID is the device's ID of the frame, used as the idempotency
+	// key when no Idempotency-Key header is sent
+	DetectionID string `json:"detection_id"`
+	// Sequence orders the frames of a session, optional on full snapshots
+	Sequence int64 `json:"sequence"`
+}
+
+// detectionChangesRequest is an incremental frame: the items placed on and
+// taken off the scale since the previous frame
+type detectionChangesRequest struct {
+	DeviceID     string                `json:"device_id" binding:"required"`
+	SessionID    string                `json:"session_id" binding:"required"`
+	Sequence     int64                 `json:"sequence" binding:"required"`
+	Added        []detectedItemRequest `json:"added"`
+	Removed      []detectedItemRequest `json:"removed"`
+	TotalWeight  float64               `json:"total_weight"`
+	Image        []byte                `json:"image"` // base64, optional
+	ModelVersion string                `json:"model_version"`
+	DetectionID  string                `json:"detection_id"`
 }
 
 type detectedItemRequest struct {
@@ -56,11 +178,55 @@ type detectedItemRequest struct {
 }
 
 type sessionItemResponse struct {
-	Code       string  `json:"code"`
-	Name       string  `json:"name"`
-	PriceCents int64   `json:"price_cents"`
-	Currency   string  `json:"currency"`
-	Confidence float64 `json:"confidence"`
+	Code            string      `json:"code"`
+	Name            string      `json:"name"` // in the customer's language when translated
+	Description     string      `json:"description,omitempty"`
+	PriceCents      int64       `json:"price_cents"` // of one unit
+	Quantity        int         `json:"quantity"`
+	LineTotalCents  int64       `json:"line_total_cents"`
+	Currency        string      `json:"currency"`
+	Confidence      float64     `json:"confidence"`                 // lowest of the line's units
+	MarkdownPercent int         `json:"markdown_percent,omitempty"` // expiry markdown already in PriceCents
+	Bundle          string      `json:"bundle,omitempty"`           // bundle code; PriceCents is the item's share of it
+	Suspicious      bool        `json:"suspicious,omitempty"`       // SKU not assigned to the device
+	Review          string      `json:"review,omitempty"`           // pending until an attendant reviews a low-confidence line, then accepted
+	BBoxes          [][]float64 `json:"bboxes,omitempty"`           // [x, y, width, height] of the units the device located
+}
+
+type walletPaymentRequest struct {
+	Wallet string `json:"wallet" binding:"required"`
+	Token  string `json:"token" binding:"required"`
+}
+
+type merchantValidationRequest struct {
+	ValidationURL string `json:"validation_url" binding:"required"`
+	DomainName    string `json:"domain_name" binding:"required"`
+}
+
+// paymentMethodResponse renders receipt metadata, or nil when unknown
+func paymentMethodResponse(wallet, brand, last4 string) gin.H {
+	if wallet == "" && brand == "" && last4 == "" {
+		return nil
+	}
+	return gin.H{
+		"wallet": wallet,
+		"brand":  brand,
+		"last4":  last4,
+	}
+}
+
+// fiscalResponse renders the fiscal identifiers to print on the receipt
+func fiscalResponse(f *app.FiscalRecordView) gin.H {
+	return gin.H{
+		"country":           f.Country,
+		"provider":          f.Provider,
+		"document_number":   f.DocumentNumber,
+		"serial_number":     f.SerialNumber,
+		"signature":         f.Signature,
+		"signature_counter": f.SignatureCounter,
+		"qr_code":           f.QRCode,
+		"issued_at":         f.IssuedAt,
+	}
 }
 
 // Handlers
@@ -73,6 +239,7 @@ func (h *HTTPHandler) Start(c *gin.Context) {
 	}
 
 	cmd := app.StartSessionCommand{
+		Token:     req.Token,
 		MachineID: req.MachineID,
 		UserID:    req.UserID,
 	}
@@ -80,22 +247,47 @@ func (h *HTTPHandler) Start(c *gin.Context) {
 	result, err := h.startHandler.Handle(c.Request.Context(), cmd)
 	if err != nil {
 		switch {
+		case errors.Is(err, app.ErrStartTokenRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "session start token required"})
+		case errors.Is(err, app.ErrInvalidStartToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired session start token"})
 		case errors.Is(err, app.ErrDeviceNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		case errors.Is(err, app.ErrDeviceBlocked):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, app.ErrDeviceMaintenance):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_maintenance"})
+		case errors.Is(err, app.ErrDeviceRestocking):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_restocking"})
 		case errors.Is(err, app.ErrDeviceInactive):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "device is inactive"})
+		case errors.Is(err, app.ErrDeviceClosed):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_closed"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"session_id": result.SessionID,
 		"device_id":  result.DeviceID,
 		"expires_at": result.ExpiresAt,
 		"message":    "session started, place items on scale",
-	})
+	}
+	if result.StreamToken != "" {
+		response["stream_token"] = result.StreamToken
+	}
+	if result.PaymentIntentID != "" {
+		response["payment_intent"] = gin.H{
+			"id":            result.PaymentIntentID,
+			"client_secret": result.PaymentClientSecret,
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *HTTPHandler) SubmitDetection(c *gin.Context) {
@@ -105,54 +297,173 @@ func (h *HTTPHandler) SubmitDetection(c *gin.Context) {
 		return
 	}
 
-	var items []app.DetectedItemInput
-	for _, item := range req.Items {
-		items = append(items, app.DetectedItemInput{
-			SKU:        item.SKU,
-			Confidence: item.Confidence,
-			BBox:       item.BBox,
-		})
+	idempotencyKey, ok := detectionIdempotencyKey(c, req.DetectionID)
+	if !ok {
+		return
 	}
 
 	cmd := app.SubmitDetectionCommand{
 		DeviceID:    req.DeviceID,
 		SessionID:   req.SessionID,
-		Items:       items,
+		Items:       toDetectedItemInputs(req.Items),
 		TotalWeight: req.TotalWeight,
+		Image:       req.Image,
+
+		ModelVersion:   req.ModelVersion,
+		IdempotencyKey: idempotencyKey,
+		Sequence:       req.Sequence,
 	}
+	h.submitDetection(c, cmd)
+}
 
+// SubmitDetectionChanges applies an incremental frame to the basket, for
+// devices that report items as they are placed and taken out
+func (h *HTTPHandler) SubmitDetectionChanges(c *gin.Context) {
+	var req detectionChangesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idempotencyKey, ok := detectionIdempotencyKey(c, req.DetectionID)
+	if !ok {
+		return
+	}
+
+	cmd := app.SubmitDetectionCommand{
+		DeviceID:    req.DeviceID,
+		SessionID:   req.SessionID,
+		Items:       toDetectedItemInputs(req.Added),
+		TotalWeight: req.TotalWeight,
+		Image:       req.Image,
+
+		ModelVersion:   req.ModelVersion,
+		IdempotencyKey: idempotencyKey,
+		Sequence:       req.Sequence,
+		Incremental:    true,
+		Removed:        toDetectedItemInputs(req.Removed),
+	}
+	h.submitDetection(c, cmd)
+}
+
+// detectionIdempotencyKey takes the Idempotency-Key header, or the frame's
+// detection ID without one; it answers 400 when both are set and differ
+func detectionIdempotencyKey(c *gin.Context, detectionID string) (string, bool) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		return detectionID, true
+	}
+	if detectionID != "" && detectionID != key {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header and detection_id differ"})
+		return "", false
+	}
+	return key, true
+}
+
+func toDetectedItemInputs(items []detectedItemRequest) []app.DetectedItemInput {
+	var inputs []app.DetectedItemInput
+	for _, item := range items {
+		inputs = append(inputs, app.DetectedItemInput{
+			SKU:        item.SKU,
+			Confidence: item.Confidence,
+			BBox:       item.BBox,
+		})
+	}
+	return inputs
+}
+
+func (h *HTTPHandler) submitDetection(c *gin.Context, cmd app.SubmitDetectionCommand) {
 	result, err := h.submitHandler.Handle(c.Request.Context(), cmd)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrSessionNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
 		case errors.Is(err, domain.ErrSessionNotActive):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrCurrencyConversionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "code": "currency_conversion_unavailable"})
+		case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidFrameSequence):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrStaleDetectionFrame):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "stale_frame"})
+		case errors.Is(err, domain.ErrItemNotInBasket):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
 		return
 	}
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, h.detectionResponse(c, result))
+}
 
+// detectionResponse is the priced basket the device shows after a frame
+func (h *HTTPHandler) detectionResponse(c *gin.Context, result app.SubmitDetectionResult) gin.H {
+	languages := preferredLanguages(c)
 	var outputItems []sessionItemResponse
 	for _, item := range result.Items {
-		outputItems = append(outputItems, sessionItemResponse{
-			Code:       item.SKU,
-			Name:       item.Name,
-			PriceCents: item.PriceCents,
-			Currency:   item.Currency,
-			Confidence: item.Confidence,
-		})
+		outputItems = append(outputItems, h.localizeItem(c.Request.Context(), sessionItemResponse{
+			Code:            item.SKU,
+			Name:            item.Name,
+			PriceCents:      item.PriceCents,
+			Quantity:        item.Quantity,
+			LineTotalCents:  item.LineTotalCents,
+			Currency:        item.Currency,
+			Confidence:      item.Confidence,
+			MarkdownPercent: item.MarkdownPercent,
+			Bundle:          item.Bundle,
+			Suspicious:      item.Suspicious,
+			Review:          item.Review,
+			BBoxes:          item.BBoxes,
+		}, languages))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"session_id":    result.SessionID,
-		"items":         outputItems,
-		"total_cents":   result.TotalCents,
-		"currency":      result.Currency,
-		"weight_match":  result.WeightMatch,
-		"needs_cloud_ml": result.NeedsCloudML,
-	})
+	response := gin.H{
+		"session_id":             result.SessionID,
+		"items":                  outputItems,
+		"subtotal_cents":         result.SubtotalCents,
+		"tax_cents":              result.TaxCents,
+		"rounding_cents":         result.RoundingCents,
+		"total_cents":            result.TotalCents,
+		"currency":               result.Currency,
+		"weight_match":           result.WeightMatch,
+		"needs_cloud_ml":         result.NeedsCloudML,
+		"model_version_mismatch": result.ModelVersionMismatch,
+	}
+	// The device resyncs its model when told which one it should run
+	if result.ExpectedModelVersion != "" {
+		response["expected_model_version"] = result.ExpectedModelVersion
+	}
+	if len(result.Unrecognized) > 0 {
+		response["unrecognized_items"] = unrecognizedItems(result.Unrecognized)
+	}
+	return response
+}
+
+// unrecognizedItems are the detected codes left out of the basket because
+// the catalog does not sell them
+func unrecognizedItems(items []app.UnrecognizedItemOutput) []gin.H {
+	out := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		entry := gin.H{"code": item.SKU, "confidence": item.Confidence}
+		if item.BBox != nil {
+			entry["bbox"] = item.BBox
+		}
+		out = append(out, entry)
+	}
+	return out
 }
 
 func (h *HTTPHandler) Get(c *gin.Context) {
@@ -167,19 +478,51 @@ func (h *HTTPHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
+	notes, err := h.queryService.Notes(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
 
-	var items []sessionItemResponse
-	for _, item := range view.Items {
-		items = append(items, sessionItemResponse{
-			Code:       item.Code,
-			Name:       item.Name,
-			PriceCents: item.PriceCents,
-			Currency:   item.Currency,
-			Confidence: item.Confidence,
-		})
+	response := h.sessionResponse(c.Request.Context(), view, preferredLanguages(c))
+	if len(notes) > 0 {
+		response["notes"] = sessionNotesResponse(notes)
 	}
+	c.JSON(http.StatusOK, response)
+}
 
-	c.JSON(http.StatusOK, gin.H{
+// Resume finds the active session of a user on a device, for a customer app
+// that lost connectivity, with a fresh stream token to resubscribe
+func (h *HTTPHandler) Resume(c *gin.Context) {
+	result, err := h.resumeHandler.Handle(c.Request.Context(), app.ResumeSessionQuery{
+		MachineID: c.Query("machine_id"),
+		UserID:    c.Query("user_id"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrResumeIdentityRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, app.ErrDeviceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "no active session"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	response := h.sessionResponse(c.Request.Context(), result.Session, preferredLanguages(c))
+	if result.StreamToken != "" {
+		response["stream_token"] = result.StreamToken
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// sessionResponse is the customer-facing session state, shared by Get and
+// the live stream, with item names in the customer's languages
+func (h *HTTPHandler) sessionResponse(ctx context.Context, view *app.SessionView, languages []string) gin.H {
+	response := gin.H{
 		"session": gin.H{
 			"id":         view.ID,
 			"device_id":  view.DeviceID,
@@ -187,10 +530,110 @@ func (h *HTTPHandler) Get(c *gin.Context) {
 			"created_at": view.CreatedAt,
 			"expires_at": view.ExpiresAt,
 		},
-		"items":       items,
-		"total_cents": view.TotalCents,
-		"currency":    view.Currency,
-	})
+		"items":          h.sessionItems(ctx, view, languages),
+		"subtotal_cents": view.SubtotalCents,
+		"tax_cents":      view.TaxCents,
+		"tax_lines":      taxLinesResponse(view.TaxLines),
+		"tax_included":   view.TaxIncluded,
+		"rounding_cents": view.RoundingCents,
+		"total_cents":    view.TotalCents,
+		"currency":       view.Currency,
+	}
+	if view.CouponCode != "" {
+		response["coupon"] = gin.H{
+			"code":           view.CouponCode,
+			"discount_cents": view.DiscountCents,
+		}
+		response["discount_cents"] = view.DiscountCents
+	}
+	if view.Payment != nil {
+		response["payment_method"] = paymentMethodResponse(view.Payment.Wallet, view.Payment.CardBrand, view.Payment.CardLast4)
+	}
+	if view.Fiscal != nil {
+		response["fiscal"] = fiscalResponse(view.Fiscal)
+	}
+	if len(view.Experiments) > 0 {
+		var experiments []gin.H
+		for _, tag := range view.Experiments {
+			experiments = append(experiments, gin.H{
+				"experiment_id": tag.ExperimentID,
+				"variant":       tag.Variant,
+			})
+		}
+		response["experiments"] = experiments
+	}
+	if view.CloudVerificationRequired {
+		response["cloud_verification_required"] = true
+	}
+	if view.WeightMismatch {
+		response["weight_mismatch"] = true
+	}
+	if o := view.WeightOverride; o != nil {
+		response["weight_override"] = gin.H{
+			"actor_id":      o.ActorID,
+			"decision":      o.Decision,
+			"reason":        o.Reason,
+			"overridden_at": o.OverriddenAt,
+		}
+	}
+	if len(view.FraudFlags) > 0 {
+		flags := make([]gin.H, 0, len(view.FraudFlags))
+		for _, f := range view.FraudFlags {
+			flags = append(flags, gin.H{
+				"rule":        f.Rule,
+				"action":      f.Action,
+				"reason":      f.Reason,
+				"stage":       f.Stage,
+				"detected_at": f.DetectedAt,
+			})
+		}
+		response["fraud_flags"] = flags
+		response["fraud_blocked"] = view.FraudBlocked
+	}
+	if len(view.Unrecognized) > 0 {
+		response["unrecognized_items"] = unrecognizedItems(view.Unrecognized)
+	}
+
+	return response
+}
+
+// sessionItems are the session's basket lines with item names in the
+// customer's languages
+func (h *HTTPHandler) sessionItems(ctx context.Context, view *app.SessionView, languages []string) []sessionItemResponse {
+	var items []sessionItemResponse
+	for _, item := range view.Items {
+		items = append(items, h.localizeItem(ctx, sessionItemResponse{
+			Code:            item.Code,
+			Name:            item.Name,
+			PriceCents:      item.PriceCents,
+			Quantity:        item.Quantity,
+			LineTotalCents:  item.LineTotalCents,
+			Currency:        item.Currency,
+			Confidence:      item.Confidence,
+			MarkdownPercent: item.MarkdownPercent,
+			Bundle:          item.Bundle,
+			Review:          item.Review,
+			BBoxes:          item.BBoxes,
+		}, languages))
+	}
+	return items
+}
+
+// localizeItem names the item in the first preferred language its SKU is
+// translated to
+func (h *HTTPHandler) localizeItem(ctx context.Context, item sessionItemResponse, languages []string) sessionItemResponse {
+	if t, ok := h.localizer.Localize(ctx, item.Code, languages); ok {
+		item.Name = t.Name
+		item.Description = t.Description
+	}
+	return item
+}
+
+// preferredLanguages reads the Accept-Language header; responses naming
+// items vary with it
+func preferredLanguages(c *gin.Context) []string {
+	c.Header("Vary", "Accept-Language")
+	return locale.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
 }
 
 func (h *HTTPHandler) Confirm(c *gin.Context) {
@@ -209,10 +652,113 @@ func (h *HTTPHandler) Confirm(c *gin.Context) {
 		switch {
 		case errors.Is(err, domain.ErrSessionNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
 		case errors.Is(err, domain.ErrSessionNotActive):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
 		case errors.Is(err, domain.ErrNoItemsDetected):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no items detected"})
+		case errors.Is(err, domain.ErrWeightMismatchUnresolved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "weight_mismatch"})
+		case errors.Is(err, domain.ErrItemReviewPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "item_review_pending"})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
+		case isCouponNotApplicable(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "coupon_not_applicable"})
+		case errors.Is(err, domain.ErrPaymentNotCaptured):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "payment has not been captured"})
+		case errors.Is(err, app.ErrDeviceClosed):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "device_closed"})
+		case errors.Is(err, app.ErrSaleRestricted):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "sale_restricted"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrFiscalizationFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "fiscal signature unavailable, please retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	response := gin.H{
+		"status":         "completed",
+		"message":        "purchase confirmed",
+		"session_id":     result.SessionID,
+		"subtotal_cents": result.SubtotalCents,
+		"tax_cents":      result.TaxCents,
+		"tax_lines":      taxLinesResponse(result.TaxLines),
+		"tax_included":   result.TaxIncluded,
+		"rounding_cents": result.RoundingCents,
+		"total_cents":    result.TotalCents,
+		"currency":       result.Currency,
+	}
+	if result.CouponCode != "" {
+		response["coupon"] = gin.H{
+			"code":           result.CouponCode,
+			"discount_cents": result.DiscountCents,
+		}
+		response["discount_cents"] = result.DiscountCents
+	}
+	if pm := paymentMethodResponse(result.Wallet, result.CardBrand, result.CardLast4); pm != nil {
+		response["payment_method"] = pm
+	}
+	if result.Fiscal != nil {
+		response["fiscal"] = fiscalResponse(result.Fiscal)
+	}
+	if result.TransactionID != "" {
+		response["transaction_id"] = result.TransactionID
+	}
+
+	// The provider reports the outcome to the payment webhook later
+	if result.AwaitingPayment {
+		response["status"] = string(domain.SessionStatusAwaitingPayment)
+		response["message"] = "payment is being processed"
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HTTPHandler) PayWithWallet(c *gin.Context) {
+	var req walletPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.PayWithWalletCommand{
+		SessionID: c.Param("id"),
+		Wallet:    req.Wallet,
+		Token:     req.Token,
+	}
+
+	result, err := h.walletHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnsupportedWallet):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported wallet type"})
+		case errors.Is(err, app.ErrWalletTokenRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wallet token required"})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrNoItemsDetected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no items detected"})
+		case errors.Is(err, domain.ErrPaymentNotCaptured):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "payment amount does not match session total"})
+		case errors.Is(err, ports.ErrPaymentGatewayDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wallet payments are not available"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
@@ -220,14 +766,83 @@ func (h *HTTPHandler) Confirm(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":      "completed",
-		"message":     "purchase confirmed",
-		"session_id":  result.SessionID,
-		"total_cents": result.TotalCents,
-		"currency":    result.Currency,
+		"session_id":        result.SessionID,
+		"payment_intent_id": result.PaymentIntentID,
+		"status":            result.Status,
+		"amount_cents":      result.AmountCents,
+		"currency":          result.Currency,
+		"payment_method":    paymentMethodResponse(result.Wallet, result.CardBrand, result.CardLast4),
 	})
 }
 
+// Pay opens the payment intent the app collects the card for; the session
+// is confirmed once the intent has been paid
+func (h *HTTPHandler) Pay(c *gin.Context) {
+	result, err := h.paymentHandler.Handle(c.Request.Context(), app.CreatePaymentIntentCommand{
+		SessionID: c.Param("id"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrNoItemsDetected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no items detected"})
+		case errors.Is(err, ports.ErrPaymentGatewayDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "card payments are not available"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id":        result.SessionID,
+		"payment_intent_id": result.PaymentIntentID,
+		"client_secret":     result.ClientSecret,
+		"status":            result.Status,
+		"amount_cents":      result.AmountCents,
+		"currency":          result.Currency,
+	})
+}
+
+func (h *HTTPHandler) ApplePayMerchantSession(c *gin.Context) {
+	var req merchantValidationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.ValidateApplePayMerchantCommand{
+		ValidationURL: req.ValidationURL,
+		DomainName:    req.DomainName,
+	}
+
+	session, err := h.merchantHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrValidationURLRequired),
+			errors.Is(err, app.ErrInvalidValidationURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ports.ErrWalletNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "apple pay is not available"})
+		case errors.Is(err, app.ErrMerchantValidationFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "apple pay merchant validation failed"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// Apple's merchant session is opaque; pass it through unchanged
+	c.Data(http.StatusOK, "application/json", session)
+}
+
 func (h *HTTPHandler) Cancel(c *gin.Context) {
 	var req struct {
 		Reason string `json:"reason"`
@@ -244,6 +859,8 @@ func (h *HTTPHandler) Cancel(c *gin.Context) {
 		switch {
 		case errors.Is(err, domain.ErrSessionNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
 		case errors.Is(err, domain.ErrSessionAlreadyCompleted):
 			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session already completed"})
 		default:
@@ -258,3 +875,54 @@ func (h *HTTPHandler) Cancel(c *gin.Context) {
 		"session_id": result.SessionID,
 	})
 }
+
+// Extend gives the customer more time before the session expires, by the
+// minutes asked for or else by the device's session expiration
+func (h *HTTPHandler) Extend(c *gin.Context) {
+	var req struct {
+		Minutes int `json:"minutes"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	result, err := h.extendHandler.Handle(c.Request.Context(), app.ExtendSessionCommand{
+		SessionID: c.Param("id"),
+		Minutes:   req.Minutes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrInvalidSessionExtension):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrSessionExtensionLimit):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "session_extension_limit"})
+		case errors.Is(err, domain.ErrSessionNotActive),
+			errors.Is(err, domain.ErrSessionExpired):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": result.SessionID,
+		"expires_at": result.ExpiresAt,
+	})
+}
+
+type taxLineResponse struct {
+	RatePercent  float64 `json:"rate_percent"`
+	TaxableCents int64   `json:"taxable_cents"`
+	TaxCents     int64   `json:"tax_cents"`
+}
+
+func taxLinesResponse(lines []app.TaxLineView) []taxLineResponse {
+	response := make([]taxLineResponse, 0, len(lines))
+	for _, l := range lines {
+		response = append(response, taxLineResponse{RatePercent: l.RatePercent, TaxableCents: l.TaxableCents, TaxCents: l.TaxCents})
+	}
+	return response
+}
@@ -0,0 +1,104 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type itemReviewRequest struct {
+	SKU         string `json:"sku" binding:"required"`      // the line awaiting review
+	Decision    string `json:"decision" binding:"required"` // accepted or replaced
+	Replacement string `json:"replacement_sku"`             // the SKU found instead, when replaced
+	Quantity    int    `json:"quantity" binding:"min=0"`    // of the replacement; 0 for as many as the line held
+}
+
+// ReviewItem lets an attendant (X-Actor-ID) accept a line the device
+// detected below the confidence threshold or replace it with what is
+// actually in the basket
+func (h *HTTPHandler) ReviewItem(c *gin.Context) {
+	var req itemReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := actorFromRequest(c)
+	result, err := h.reviewItemHandler.Handle(c.Request.Context(), app.ReviewItemCommand{
+		SessionID:   c.Param("id"),
+		ActorID:     actorID,
+		SKU:         req.SKU,
+		Decision:    req.Decision,
+		Replacement: req.Replacement,
+		Quantity:    req.Quantity,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrActorRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "acting user is required"})
+		case errors.Is(err, domain.ErrUnknownItemDecision),
+			errors.Is(err, domain.ErrItemReplacementMissing):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrNoItemToReview),
+			errors.Is(err, domain.ErrUnknownReplacementSKU):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrCurrencyConversionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "code": "currency_conversion_unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	view, err := h.queryService.FindByID(c.Request.Context(), result.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	response := h.sessionResponse(c.Request.Context(), view, preferredLanguages(c))
+	response["awaiting_review"] = result.AwaitingReview
+	c.JSON(http.StatusOK, response)
+}
+
+// ListPendingReviews is the attendants' queue: the active sessions with the
+// lines still awaiting review, oldest session first
+func (h *HTTPHandler) ListPendingReviews(c *gin.Context) {
+	views, err := h.queryService.FindAwaitingReview(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	languages := preferredLanguages(c)
+	sessions := make([]gin.H, 0, len(views))
+	for _, view := range views {
+		var pending []sessionItemResponse
+		for _, item := range h.sessionItems(c.Request.Context(), view, languages) {
+			if item.Review == string(domain.ItemReviewPending) {
+				pending = append(pending, item)
+			}
+		}
+		sessions = append(sessions, gin.H{
+			"session_id": view.ID,
+			"device_id":  view.DeviceID,
+			"created_at": view.CreatedAt,
+			"expires_at": view.ExpiresAt,
+			"items":      pending,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
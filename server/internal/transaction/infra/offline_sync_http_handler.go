@@ -0,0 +1,98 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type syncOfflineEntriesRequest struct {
+	DeviceID string                `json:"device_id" binding:"required"`
+	Entries  []offlineEntryRequest `json:"entries" binding:"required"`
+}
+
+type offlineEntryRequest struct {
+	ClientID    string                `json:"client_id"`
+	Type        string                `json:"type"`
+	SessionID   string                `json:"session_id"`
+	RecordedAt  time.Time             `json:"recorded_at"`
+	Items       []detectedItemRequest `json:"items"`
+	TotalWeight float64               `json:"total_weight"`
+	Reason      string                `json:"reason"`
+}
+
+type syncEntryResponse struct {
+	ClientID       string `json:"client_id"`
+	SessionID      string `json:"session_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+}
+
+// SyncOfflineEntries applies the detections and state transitions a device
+// buffered while offline. Outcomes are reported per entry, so the request
+// succeeds even when individual entries conflict.
+func (h *HTTPHandler) SyncOfflineEntries(c *gin.Context) {
+	var req syncOfflineEntriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cmd := app.SyncOfflineEntriesCommand{DeviceID: req.DeviceID}
+	for _, entry := range req.Entries {
+		input := app.OfflineEntryInput{
+			ClientID:    entry.ClientID,
+			Type:        entry.Type,
+			SessionID:   entry.SessionID,
+			RecordedAt:  entry.RecordedAt,
+			TotalWeight: entry.TotalWeight,
+			Reason:      entry.Reason,
+		}
+		for _, item := range entry.Items {
+			input.Items = append(input.Items, app.DetectedItemInput{
+				SKU:        item.SKU,
+				Confidence: item.Confidence,
+				BBox:       item.BBox,
+			})
+		}
+		cmd.Entries = append(cmd.Entries, input)
+	}
+
+	result, err := h.syncHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidDeviceID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device ID"})
+		case errors.Is(err, app.ErrTooManyOfflineEntries):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error(), "max_entries": app.MaxOfflineEntries})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	results := make([]syncEntryResponse, 0, len(result.Results))
+	counts := make(map[string]int)
+	for _, r := range result.Results {
+		results = append(results, syncEntryResponse{
+			ClientID:       r.ClientID,
+			SessionID:      r.SessionID,
+			Status:         r.Status,
+			Error:          r.Error,
+			PreviousStatus: r.PreviousStatus,
+		})
+		counts[r.Status]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"device_id": result.DeviceID,
+		"results":   results,
+		"summary":   counts,
+	})
+}
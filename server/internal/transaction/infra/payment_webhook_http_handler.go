@@ -0,0 +1,149 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/platform/webhook"
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+const (
+	// paymentSignatureHeader carries "t=<unix>,v1=<hex>[,v1=<hex>...]"; the
+	// provider sends one v1 per active secret while a secret is being rolled
+	paymentSignatureHeader = "Stripe-Signature"
+
+	maxPaymentWebhookBytes = 1 << 20
+)
+
+// paymentEventRequest is the part of a provider event the webhook reads
+type paymentEventRequest struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID       string            `json:"id"`
+			Metadata map[string]string `json:"metadata"`
+			// LastPaymentError explains a failed payment
+			LastPaymentError *struct {
+				Message string `json:"message"`
+			} `json:"last_payment_error"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// paymentEventOutcomes maps the provider event types the webhook acts on
+var paymentEventOutcomes = map[string]app.PaymentOutcome{
+	"payment_intent.succeeded":      app.PaymentOutcomeSucceeded,
+	"payment_intent.payment_failed": app.PaymentOutcomeFailed,
+	"payment_intent.canceled":       app.PaymentOutcomeFailed,
+}
+
+// PaymentWebhook settles sessions awaiting the outcome of their card payment.
+// Every signed event is acknowledged with 200 unless processing it failed in
+// a way a redelivery can fix, which is answered with a 5xx so the provider
+// retries.
+func (h *HTTPHandler) PaymentWebhook(c *gin.Context) {
+	if !h.paymentWebhookVerifier.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": webhook.ErrNotConfigured.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPaymentWebhookBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("an event is limited to %d bytes", maxPaymentWebhookBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read request body"})
+		return
+	}
+	if err := h.verifyPaymentSignature(c.GetHeader(paymentSignatureHeader), body); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event paymentEventRequest
+	if err := json.Unmarshal(body, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outcome, ok := paymentEventOutcomes[event.Type]
+	sessionID := event.Data.Object.Metadata["session_id"]
+	if !ok || sessionID == "" {
+		c.JSON(http.StatusOK, gin.H{"received": true, "applied": false})
+		return
+	}
+
+	cmd := app.ProcessPaymentEventCommand{
+		SessionID: sessionID,
+		IntentID:  event.Data.Object.ID,
+		Outcome:   outcome,
+	}
+	if e := event.Data.Object.LastPaymentError; e != nil {
+		cmd.FailureReason = e.Message
+	}
+
+	result, err := h.paymentEventHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusOK, gin.H{"received": true, "applied": false})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrPaymentNotCaptured):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "payment does not cover the session total"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrFiscalizationFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "fiscal signature unavailable, please retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"received":   true,
+		"applied":    result.Applied,
+		"session_id": result.SessionID,
+		"status":     result.Status,
+	})
+}
+
+// verifyPaymentSignature accepts the body if any of the header's v1
+// signatures matches
+func (h *HTTPHandler) verifyPaymentSignature(header string, body []byte) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	err := webhook.ErrInvalidSignature
+	for _, sig := range signatures {
+		err = h.paymentWebhookVerifier.Verify(timestamp, "sha256="+sig, body)
+		if err == nil || errors.Is(err, webhook.ErrStaleDelivery) {
+			return err
+		}
+	}
+	return err
+}
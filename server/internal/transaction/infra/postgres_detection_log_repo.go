@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresDetectionLogRepository implements domain.DetectionLogRepository
+type PostgresDetectionLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDetectionLogRepository(pool *pgxpool.Pool) *PostgresDetectionLogRepository {
+	return &PostgresDetectionLogRepository{pool: pool}
+}
+
+func (r *PostgresDetectionLogRepository) Append(ctx context.Context, d domain.DetectionRecord) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO detections (session_id, device_id, source, payload, outcome, decision, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, d.SessionID, d.DeviceID, d.Source, d.Payload, d.Outcome, d.Decision, d.ReceivedAt)
+
+	return err
+}
+
+func (r *PostgresDetectionLogRepository) FindBySession(ctx context.Context, sessionID string) ([]domain.DetectionRecord, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, session_id, device_id, source, payload, outcome, decision, received_at
+		FROM detections
+		WHERE session_id = $1
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.DetectionRecord
+	for rows.Next() {
+		var d domain.DetectionRecord
+		if err := rows.Scan(&d.ID, &d.SessionID, &d.DeviceID, &d.Source, &d.Payload, &d.Outcome, &d.Decision, &d.ReceivedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, d)
+	}
+	return records, rows.Err()
+}
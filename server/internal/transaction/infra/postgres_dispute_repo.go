@@ -0,0 +1,238 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresDisputeRepository implements domain.DisputeRepository
+type PostgresDisputeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresDisputeRepository(pool *pgxpool.Pool) *PostgresDisputeRepository {
+	return &PostgresDisputeRepository{pool: pool}
+}
+
+type disputeRow struct {
+	ID             string
+	SessionID      string
+	TransactionID  string
+	AmountCents    int64
+	Currency       string
+	Reason         string
+	ProviderRef    *string
+	Status         string
+	OpenedBy       string
+	ResolvedBy     *string
+	ResolutionNote *string
+	Notes          []byte
+	Evidence       []byte
+	CreatedAt      time.Time
+	ResolvedAt     *time.Time
+}
+
+type disputeNoteJSON struct {
+	ActorID string    `json:"actor_id"`
+	Note    string    `json:"note"`
+	At      time.Time `json:"at"`
+}
+
+type disputeEvidenceJSON struct {
+	Kind         string    `json:"kind"`
+	DetectionIDs []int64   `json:"detection_ids,omitempty"`
+	ImageBytes   int       `json:"image_bytes,omitempty"`
+	AttachedBy   string    `json:"attached_by"`
+	Note         string    `json:"note,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+const disputeColumns = `id, session_id, transaction_id, amount_cents, currency, reason, provider_ref, status, opened_by, resolved_by, resolution_note, notes, evidence, created_at, resolved_at`
+
+func (r *PostgresDisputeRepository) Save(ctx context.Context, dispute *domain.Dispute) error {
+	var notesJSON []disputeNoteJSON
+	for _, n := range dispute.Notes() {
+		notesJSON = append(notesJSON, disputeNoteJSON{ActorID: n.ActorID, Note: n.Note, At: n.At})
+	}
+	notesData, _ := json.Marshal(notesJSON)
+
+	var evidenceJSON []disputeEvidenceJSON
+	for _, e := range dispute.Evidence() {
+		evidenceJSON = append(evidenceJSON, disputeEvidenceJSON{
+			Kind:         string(e.Kind),
+			DetectionIDs: e.DetectionIDs,
+			ImageBytes:   e.ImageBytes,
+			AttachedBy:   e.AttachedBy,
+			Note:         e.Note,
+			At:           e.At,
+		})
+	}
+	evidenceData, _ := json.Marshal(evidenceJSON)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO disputes (`+disputeColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			resolved_by = EXCLUDED.resolved_by,
+			resolution_note = EXCLUDED.resolution_note,
+			notes = EXCLUDED.notes,
+			evidence = EXCLUDED.evidence,
+			resolved_at = EXCLUDED.resolved_at
+	`, dispute.ID().String(), dispute.SessionID().String(), dispute.TransactionID().String(),
+		dispute.Amount().Amount(), dispute.Amount().Currency(), dispute.Reason(), nullable(dispute.ProviderRef()),
+		string(dispute.Status()), dispute.OpenedBy(), nullable(dispute.ResolvedBy()), nullable(dispute.ResolutionNote()),
+		notesData, evidenceData, dispute.CreatedAt(), dispute.ResolvedAt())
+
+	// Only the index of open disputes per session can conflict
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrDisputeAlreadyOpen
+	}
+	return sessionReferenceError(err)
+}
+
+func (r *PostgresDisputeRepository) FindByID(ctx context.Context, id valueobjects.DisputeID) (*domain.Dispute, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = $1`, id.String())
+
+	dispute, err := r.scanDispute(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, err
+	}
+	return dispute, nil
+}
+
+func (r *PostgresDisputeRepository) FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]*domain.Dispute, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+disputeColumns+`
+		FROM disputes
+		WHERE session_id = $1
+		ORDER BY created_at
+	`, sessionID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanDisputes(rows)
+}
+
+func (r *PostgresDisputeRepository) Find(ctx context.Context, filter domain.DisputeFilter) ([]*domain.Dispute, error) {
+	var status *string
+	var from, to *time.Time
+	if filter.Status != "" {
+		st := string(filter.Status)
+		status = &st
+	}
+	if !filter.OpenedFrom.IsZero() {
+		from = &filter.OpenedFrom
+	}
+	if !filter.OpenedTo.IsZero() {
+		to = &filter.OpenedTo
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+disputeColumns+`
+		FROM disputes
+		WHERE ($1::text IS NULL OR status = $1)
+			AND ($2::timestamptz IS NULL OR created_at >= $2)
+			AND ($3::timestamptz IS NULL OR created_at < $3)
+		ORDER BY created_at, id
+	`, status, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanDisputes(rows)
+}
+
+func (r *PostgresDisputeRepository) scanDisputes(rows pgx.Rows) ([]*domain.Dispute, error) {
+	var disputes []*domain.Dispute
+	for rows.Next() {
+		dispute, err := r.scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+	return disputes, rows.Err()
+}
+
+func (r *PostgresDisputeRepository) scanDispute(row pgx.Row) (*domain.Dispute, error) {
+	var rec disputeRow
+	err := row.Scan(
+		&rec.ID, &rec.SessionID, &rec.TransactionID, &rec.AmountCents, &rec.Currency, &rec.Reason, &rec.ProviderRef, &rec.Status,
+		&rec.OpenedBy, &rec.ResolvedBy, &rec.ResolutionNote, &rec.Notes, &rec.Evidence, &rec.CreatedAt, &rec.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reconstitute(rec), nil
+}
+
+func (r *PostgresDisputeRepository) reconstitute(rec disputeRow) *domain.Dispute {
+	id, _ := valueobjects.DisputeIDFrom(rec.ID)
+	sessionID, _ := valueobjects.SessionIDFrom(rec.SessionID)
+	transactionID, _ := valueobjects.TransactionIDFrom(rec.TransactionID)
+	amount, _ := valueobjects.NewMoney(rec.AmountCents, rec.Currency)
+
+	var notesJSON []disputeNoteJSON
+	_ = json.Unmarshal(rec.Notes, &notesJSON)
+	var notes []domain.DisputeNote
+	for _, n := range notesJSON {
+		notes = append(notes, domain.DisputeNote{ActorID: n.ActorID, Note: n.Note, At: n.At})
+	}
+
+	var evidenceJSON []disputeEvidenceJSON
+	_ = json.Unmarshal(rec.Evidence, &evidenceJSON)
+	var evidence []domain.DisputeEvidence
+	for _, e := range evidenceJSON {
+		evidence = append(evidence, domain.DisputeEvidence{
+			Kind:         domain.DisputeEvidenceKind(e.Kind),
+			DetectionIDs: e.DetectionIDs,
+			ImageBytes:   e.ImageBytes,
+			AttachedBy:   e.AttachedBy,
+			Note:         e.Note,
+			At:           e.At,
+		})
+	}
+
+	return domain.ReconstituteDispute(
+		id,
+		sessionID,
+		transactionID,
+		amount,
+		rec.Reason,
+		deref(rec.ProviderRef),
+		domain.DisputeStatus(rec.Status),
+		rec.OpenedBy,
+		deref(rec.ResolvedBy),
+		deref(rec.ResolutionNote),
+		notes,
+		evidence,
+		rec.CreatedAt,
+		rec.ResolvedAt,
+	)
+}
+
+// nullable stores an empty string as NULL, the reverse of deref
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,46 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresProcessedDetectionRepository implements domain.ProcessedDetectionRepository
+type PostgresProcessedDetectionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresProcessedDetectionRepository(pool *pgxpool.Pool) *PostgresProcessedDetectionRepository {
+	return &PostgresProcessedDetectionRepository{pool: pool}
+}
+
+func (r *PostgresProcessedDetectionRepository) Save(ctx context.Context, d domain.ProcessedDetection) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO processed_detections (device_id, idempotency_key, session_id, result, processed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (device_id, idempotency_key) DO NOTHING
+	`, d.DeviceID, d.Key, d.SessionID, d.Result, d.ProcessedAt)
+
+	return err
+}
+
+func (r *PostgresProcessedDetectionRepository) Find(ctx context.Context, deviceID, key string) (*domain.ProcessedDetection, error) {
+	var d domain.ProcessedDetection
+	err := r.pool.QueryRow(ctx, `
+		SELECT device_id, idempotency_key, session_id, result, processed_at
+		FROM processed_detections
+		WHERE device_id = $1 AND idempotency_key = $2
+	`, deviceID, key).Scan(&d.DeviceID, &d.Key, &d.SessionID, &d.Result, &d.ProcessedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProcessedDetectionNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
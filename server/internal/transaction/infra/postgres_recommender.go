@@ -0,0 +1,94 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// PostgresCoPurchaseRecommender implements ports.Recommender with co-purchase
+// statistics over recently completed sessions. A candidate's score is the
+// share of sales containing a basket item that also contained the candidate.
+type PostgresCoPurchaseRecommender struct {
+	pool     *pgxpool.Pool
+	lookback time.Duration
+}
+
+func NewPostgresCoPurchaseRecommender(pool *pgxpool.Pool, lookback time.Duration) *PostgresCoPurchaseRecommender {
+	return &PostgresCoPurchaseRecommender{pool: pool, lookback: lookback}
+}
+
+// salesCTE yields one row per (completed session, SKU code) within the lookback window
+const salesCTE = `
+	WITH sales AS (
+		SELECT s.id AS session_id, item->>'code' AS code
+		FROM sessions s, jsonb_array_elements(s.items) AS item
+		WHERE s.status = 'completed' AND s.completed_at >= $1
+		GROUP BY s.id, item->>'code'
+	)`
+
+func (r *PostgresCoPurchaseRecommender) Recommend(ctx context.Context, basket []string, limit int) ([]ports.ScoredSKU, error) {
+	since := time.Now().Add(-r.lookback)
+	if basket == nil {
+		basket = []string{}
+	}
+
+	var recs []ports.ScoredSKU
+	if len(basket) > 0 {
+		var err error
+		recs, err = r.query(ctx, salesCTE+`,
+			anchor AS (SELECT DISTINCT session_id FROM sales WHERE code = ANY($2))
+			SELECT sales.code, COUNT(*)::float8 / GREATEST((SELECT COUNT(*) FROM anchor), 1) AS score
+			FROM sales JOIN anchor USING (session_id)
+			WHERE NOT sales.code = ANY($2)
+			GROUP BY sales.code
+			ORDER BY score DESC, sales.code
+			LIMIT $3
+		`, since, basket, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(recs) >= limit {
+		return recs, nil
+	}
+
+	// Top up with best sellers so sparse history still yields suggestions
+	exclude := append([]string{}, basket...)
+	for _, rec := range recs {
+		exclude = append(exclude, rec.Code)
+	}
+	best, err := r.query(ctx, salesCTE+`
+		SELECT code, COUNT(*)::float8 / GREATEST((SELECT COUNT(DISTINCT session_id) FROM sales), 1) AS score
+		FROM sales
+		WHERE NOT code = ANY($2)
+		GROUP BY code
+		ORDER BY score DESC, code
+		LIMIT $3
+	`, since, exclude, limit-len(recs))
+	if err != nil {
+		return nil, err
+	}
+	return append(recs, best...), nil
+}
+
+func (r *PostgresCoPurchaseRecommender) query(ctx context.Context, sql string, args ...any) ([]ports.ScoredSKU, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recs []ports.ScoredSKU
+	for rows.Next() {
+		var rec ports.ScoredSKU
+		if err := rows.Scan(&rec.Code, &rec.Score); err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, rows.Err()
+}
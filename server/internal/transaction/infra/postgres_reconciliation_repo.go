@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresReconciliationRepository implements domain.ReconciliationRepository
+type PostgresReconciliationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresReconciliationRepository(pool *pgxpool.Pool) *PostgresReconciliationRepository {
+	return &PostgresReconciliationRepository{pool: pool}
+}
+
+type discrepancyJSON struct {
+	SessionID   string `json:"session_id"`
+	DeviceID    string `json:"device_id"`
+	SKUCode     string `json:"sku_code"`
+	Charged     int    `json:"charged"`
+	Detected    int    `json:"detected"`
+	ImpactCents int64  `json:"impact_cents"`
+	Currency    string `json:"currency"`
+}
+
+func (r *PostgresReconciliationRepository) Save(ctx context.Context, report *domain.ReconciliationReport) error {
+	discrepancies := []discrepancyJSON{}
+	for _, d := range report.Discrepancies() {
+		discrepancies = append(discrepancies, discrepancyJSON{
+			SessionID:   d.SessionID,
+			DeviceID:    d.DeviceID,
+			SKUCode:     d.SKUCode,
+			Charged:     d.Charged,
+			Detected:    d.Detected,
+			ImpactCents: d.ImpactCents,
+			Currency:    d.Currency,
+		})
+	}
+	discrepancyData, _ := json.Marshal(discrepancies)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO reconciliation_reports (day, model_version, sessions_checked, sessions_skipped, discrepancies, generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (day) DO UPDATE SET
+			model_version = EXCLUDED.model_version,
+			sessions_checked = EXCLUDED.sessions_checked,
+			sessions_skipped = EXCLUDED.sessions_skipped,
+			discrepancies = EXCLUDED.discrepancies,
+			generated_at = EXCLUDED.generated_at
+	`, report.Day(), report.ModelVersion(), report.SessionsChecked(), report.SessionsSkipped(),
+		discrepancyData, report.GeneratedAt())
+
+	return err
+}
+
+func (r *PostgresReconciliationRepository) FindByDay(ctx context.Context, day string) (*domain.ReconciliationReport, error) {
+	var (
+		modelVersion             string
+		sessionsChecked, skipped int
+		discrepancyData          []byte
+		generatedAt              time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT model_version, sessions_checked, sessions_skipped, discrepancies, generated_at
+		FROM reconciliation_reports
+		WHERE day = $1
+	`, day).Scan(&modelVersion, &sessionsChecked, &skipped, &discrepancyData, &generatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReconciliationReportNotFound
+		}
+		return nil, err
+	}
+
+	var stored []discrepancyJSON
+	_ = json.Unmarshal(discrepancyData, &stored)
+
+	var discrepancies []domain.SessionDiscrepancy
+	for _, d := range stored {
+		discrepancies = append(discrepancies, domain.SessionDiscrepancy{
+			SessionID:   d.SessionID,
+			DeviceID:    d.DeviceID,
+			SKUCode:     d.SKUCode,
+			Charged:     d.Charged,
+			Detected:    d.Detected,
+			ImpactCents: d.ImpactCents,
+			Currency:    d.Currency,
+		})
+	}
+
+	return domain.ReconstituteReconciliationReport(day, modelVersion, sessionsChecked, skipped, discrepancies, generatedAt), nil
+}
@@ -0,0 +1,204 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresRefundRepository implements domain.RefundRepository
+type PostgresRefundRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRefundRepository(pool *pgxpool.Pool) *PostgresRefundRepository {
+	return &PostgresRefundRepository{pool: pool}
+}
+
+type refundRow struct {
+	ID            string
+	SessionID     string
+	TransactionID *string
+	AmountCents   int64
+	RoundingCents int64
+	Currency      string
+	Reason        *string
+	Status        string
+	RequestedBy   *string
+	DecidedBy     *string
+	Audit         []byte
+	CreatedAt     time.Time
+	DecidedAt     *time.Time
+}
+
+type refundAuditJSON struct {
+	Action  string    `json:"action"`
+	ActorID string    `json:"actor_id"`
+	Roles   []string  `json:"roles,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+const refundColumns = `id, session_id, transaction_id, amount_cents, rounding_cents, currency, reason, status, requested_by, decided_by, audit_trail, created_at, decided_at`
+
+func (r *PostgresRefundRepository) Save(ctx context.Context, refund *domain.Refund) error {
+	var auditJSON []refundAuditJSON
+	for _, entry := range refund.AuditTrail() {
+		auditJSON = append(auditJSON, refundAuditJSON{
+			Action:  string(entry.Action),
+			ActorID: entry.ActorID,
+			Roles:   entry.Roles,
+			Note:    entry.Note,
+			At:      entry.At,
+		})
+	}
+	auditData, _ := json.Marshal(auditJSON)
+
+	var decidedBy *string
+	if refund.DecidedBy() != "" {
+		d := refund.DecidedBy()
+		decidedBy = &d
+	}
+
+	var transactionID *string
+	if !refund.TransactionID().IsZero() {
+		id := refund.TransactionID().String()
+		transactionID = &id
+	}
+
+	_, err := postgres.Conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO refunds (`+refundColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			decided_by = EXCLUDED.decided_by,
+			audit_trail = EXCLUDED.audit_trail,
+			decided_at = EXCLUDED.decided_at
+	`, refund.ID().String(), refund.SessionID().String(), transactionID, refund.Amount().Amount(), refund.RoundingCents(),
+		refund.Amount().Currency(), refund.Reason(), string(refund.Status()), refund.RequestedBy(), decidedBy,
+		auditData, refund.CreatedAt(), refund.DecidedAt())
+
+	return sessionReferenceError(err)
+}
+
+func (r *PostgresRefundRepository) FindByID(ctx context.Context, id valueobjects.RefundID) (*domain.Refund, error) {
+	row := postgres.Conn(ctx, r.pool).QueryRow(ctx, `SELECT `+refundColumns+` FROM refunds WHERE id = $1`, id.String())
+
+	refund, err := r.scanRefund(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRefundNotFound
+		}
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (r *PostgresRefundRepository) FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]*domain.Refund, error) {
+	rows, err := postgres.Conn(ctx, r.pool).Query(ctx, `
+		SELECT `+refundColumns+`
+		FROM refunds
+		WHERE session_id = $1
+		ORDER BY created_at
+	`, sessionID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanRefunds(rows)
+}
+
+func (r *PostgresRefundRepository) FindByStatus(ctx context.Context, status domain.RefundStatus) ([]*domain.Refund, error) {
+	rows, err := postgres.Conn(ctx, r.pool).Query(ctx, `
+		SELECT `+refundColumns+`
+		FROM refunds
+		WHERE status = $1 AND session_id IS NOT NULL
+		ORDER BY created_at
+	`, string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanRefunds(rows)
+}
+
+func (r *PostgresRefundRepository) scanRefunds(rows pgx.Rows) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	for rows.Next() {
+		refund, err := r.scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
+
+func (r *PostgresRefundRepository) scanRefund(row pgx.Row) (*domain.Refund, error) {
+	var rec refundRow
+	err := row.Scan(
+		&rec.ID, &rec.SessionID, &rec.TransactionID, &rec.AmountCents, &rec.RoundingCents, &rec.Currency, &rec.Reason, &rec.Status,
+		&rec.RequestedBy, &rec.DecidedBy, &rec.Audit, &rec.CreatedAt, &rec.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reconstitute(rec), nil
+}
+
+func (r *PostgresRefundRepository) reconstitute(rec refundRow) *domain.Refund {
+	id, _ := valueobjects.RefundIDFrom(rec.ID)
+	sessionID, _ := valueobjects.SessionIDFrom(rec.SessionID)
+	var transactionID valueobjects.TransactionID
+	if rec.TransactionID != nil {
+		transactionID, _ = valueobjects.TransactionIDFrom(*rec.TransactionID)
+	}
+	amount, _ := valueobjects.NewMoney(rec.AmountCents, rec.Currency)
+
+	var auditJSON []refundAuditJSON
+	_ = json.Unmarshal(rec.Audit, &auditJSON)
+
+	var audit []domain.RefundAuditEntry
+	for _, entry := range auditJSON {
+		audit = append(audit, domain.RefundAuditEntry{
+			Action:  domain.RefundAuditAction(entry.Action),
+			ActorID: entry.ActorID,
+			Roles:   entry.Roles,
+			Note:    entry.Note,
+			At:      entry.At,
+		})
+	}
+
+	return domain.ReconstituteRefund(
+		id,
+		sessionID,
+		transactionID,
+		amount,
+		rec.RoundingCents,
+		deref(rec.Reason),
+		domain.RefundStatus(rec.Status),
+		deref(rec.RequestedBy),
+		deref(rec.DecidedBy),
+		rec.CreatedAt,
+		rec.DecidedAt,
+		audit,
+	)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/shared/policy"
 	"github.com/vending-machine/server/internal/shared/valueobjects"
 	"github.com/vending-machine/server/internal/transaction/domain"
 )
@@ -23,17 +25,34 @@ func NewPostgresSessionRepository(pool *pgxpool.Pool) *PostgresSessionRepository
 }
 
 type sessionRow struct {
-	ID          string
-	DeviceID    string
-	UserID      *string
-	Status      string
-	Items       []byte
-	TotalWeight float64
-	TotalCents  int64
-	Currency    string
-	CreatedAt   time.Time
-	ExpiresAt   time.Time
-	CompletedAt *time.Time
+	ID             string
+	DeviceID       string
+	UserID         *string
+	Status         string
+	Items          []byte
+	TotalWeight    float64
+	TotalCents     int64
+	Rounding       int64
+	Tax            []byte
+	Currency       string
+	IntentID       *string
+	Method         []byte
+	Fiscal         []byte
+	Experiments    []byte
+	Markdowns      []byte
+	CloudVerify    bool
+	WeightMismatch bool
+	WeightOverride []byte
+	FraudFlags     []byte
+	Coupon         []byte
+	FrameSequence  int64
+	WalkawaySignal *string
+	WalkawayAt     *time.Time
+	Unrecognized   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	CompletedAt    *time.Time
+	Version        int
 }
 
 type itemJSON struct {
@@ -42,10 +61,171 @@ type itemJSON struct {
 	Name       string  `json:"name"`
 	Confidence float64 `json:"confidence"`
 	PriceCents int64   `json:"price_cents"`
+	Quantity   int     `json:"quantity"` // absent on lines saved before quantities, which held one unit
 	Currency   string  `json:"currency"`
+	Bundle     string  `json:"bundle,omitempty"`
+	Review     string  `json:"review,omitempty"`
+	// BBoxes are the [x, y, width, height] of the units the device located
+	BBoxes [][]float64 `json:"bboxes,omitempty"`
+}
+
+type unrecognizedItemJSON struct {
+	Code       string    `json:"code"`
+	Confidence float64   `json:"confidence"`
+	BBox       []float64 `json:"bbox,omitempty"`
+}
+
+type fiscalRecordJSON struct {
+	Country          string    `json:"country"`
+	Provider         string    `json:"provider"`
+	DocumentNumber   string    `json:"document_number"`
+	SerialNumber     string    `json:"serial_number,omitempty"`
+	Signature        string    `json:"signature,omitempty"`
+	SignatureCounter int64     `json:"signature_counter,omitempty"`
+	QRCode           string    `json:"qr_code,omitempty"`
+	IssuedAt         time.Time `json:"issued_at"`
+}
+
+// fiscalRecordData is the JSON stored for a fiscal record, nil for none
+func fiscalRecordData(fr domain.FiscalRecord) []byte {
+	if fr.IsZero() {
+		return nil
+	}
+	data, _ := json.Marshal(fiscalRecordJSON{
+		Country:          fr.Country(),
+		Provider:         fr.Provider(),
+		DocumentNumber:   fr.DocumentNumber(),
+		SerialNumber:     fr.SerialNumber(),
+		Signature:        fr.Signature(),
+		SignatureCounter: fr.SignatureCounter(),
+		QRCode:           fr.QRCode(),
+		IssuedAt:         fr.IssuedAt(),
+	})
+	return data
+}
+
+// fiscalRecordFrom reads a stored fiscal record back, zero for none
+func fiscalRecordFrom(data []byte) domain.FiscalRecord {
+	var fr fiscalRecordJSON
+	if len(data) == 0 || json.Unmarshal(data, &fr) != nil {
+		return domain.FiscalRecord{}
+	}
+	return domain.NewFiscalRecord(fr.Country, fr.Provider, fr.DocumentNumber, fr.SerialNumber,
+		fr.Signature, fr.SignatureCounter, fr.QRCode, fr.IssuedAt)
+}
+
+type experimentTagJSON struct {
+	ExperimentID string           `json:"experiment_id"`
+	Variant      string           `json:"variant"`
+	Prices       map[string]int64 `json:"prices,omitempty"`
+}
+
+type taxJSON struct {
+	Included bool          `json:"included"`
+	Lines    []taxLineJSON `json:"lines"`
+}
+
+type taxLineJSON struct {
+	RateBasisPoints int64 `json:"rate_bp"`
+	TaxableCents    int64 `json:"taxable_cents"`
+	TaxCents        int64 `json:"tax_cents"`
+}
+
+type weightOverrideJSON struct {
+	ActorID      string    `json:"actor_id"`
+	Decision     string    `json:"decision"`
+	Reason       string    `json:"reason"`
+	OverriddenAt time.Time `json:"overridden_at"`
+}
+
+type fraudFindingJSON struct {
+	Rule       string    `json:"rule"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	Stage      string    `json:"stage"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type appliedCouponJSON struct {
+	Code           string `json:"code"`
+	PercentOff     int    `json:"percent_off,omitempty"`
+	AmountOffCents int64  `json:"amount_off_cents,omitempty"`
+}
+
+type paymentMethodJSON struct {
+	Wallet string `json:"wallet,omitempty"`
+	Brand  string `json:"brand,omitempty"`
+	Last4  string `json:"last4,omitempty"`
 }
 
 func (r *PostgresSessionRepository) Save(ctx context.Context, s *domain.Session) error {
+	tx, err := postgres.Begin(ctx, r.pool)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	version, err := saveSession(ctx, tx, s)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.SetVersion(version)
+	return nil
+}
+
+// SaveCompleted stores the session and inserts its transaction in one
+// database transaction; a session records at most one transaction
+func (r *PostgresSessionRepository) SaveCompleted(ctx context.Context, s *domain.Session, txn *domain.Transaction) error {
+	tx, err := postgres.Begin(ctx, r.pool)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	version, err := saveSession(ctx, tx, s)
+	if err != nil {
+		return err
+	}
+	if err := insertTransaction(ctx, tx, txn); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.SetVersion(version)
+	return nil
+}
+
+// saveSession writes the session and returns its new version
+func saveSession(ctx context.Context, tx pgx.Tx, s *domain.Session) (int, error) {
+	if s.Archived() {
+		return 0, domain.ErrSessionArchived
+	}
+	// A session loaded from the sessions table may have been archived since;
+	// the upsert would insert it again. Locking the row keeps the archival
+	// from moving it until the save is done.
+	if s.Version() > 0 {
+		var found int
+		err := tx.QueryRow(ctx, `SELECT 1 FROM sessions WHERE id = $1 FOR UPDATE`, s.ID().String()).Scan(&found)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrSessionArchived
+		}
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		// The ID outlives the session's move to the archive, so what refers
+		// to the session keeps a foreign key to it
+		if _, err := tx.Exec(ctx, `INSERT INTO session_ids (id) VALUES ($1) ON CONFLICT DO NOTHING`, s.ID().String()); err != nil {
+			return 0, err
+		}
+	}
+
 	var userID *string
 	if s.UserID() != "" {
 		u := s.UserID()
@@ -61,40 +241,162 @@ func (r *PostgresSessionRepository) Save(ctx context.Context, s *domain.Session)
 			Name:       item.Name(),
 			Confidence: item.Confidence(),
 			PriceCents: item.Price().Amount(),
+			Quantity:   item.Quantity(),
 			Currency:   item.Price().Currency(),
+			Bundle:     item.Bundle(),
+			Review:     string(item.Review()),
+			BBoxes:     boxCoords(item.Boxes()),
 		})
 	}
 	itemsData, _ := json.Marshal(itemsJSON)
 
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO sessions (id, device_id, user_id, status, items, total_weight, total_cents, currency, created_at, expires_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	var intentID *string
+	if s.PaymentIntentID() != "" {
+		id := s.PaymentIntentID()
+		intentID = &id
+	}
+
+	var methodData []byte
+	if pm := s.PaymentMethod(); !pm.IsZero() {
+		methodData, _ = json.Marshal(paymentMethodJSON{
+			Wallet: string(pm.Wallet()),
+			Brand:  pm.Brand(),
+			Last4:  pm.Last4(),
+		})
+	}
+
+	experimentsJSON := []experimentTagJSON{}
+	for _, tag := range s.Experiments() {
+		experimentsJSON = append(experimentsJSON, experimentTagJSON{
+			ExperimentID: tag.ExperimentID(),
+			Variant:      tag.Variant(),
+			Prices:       tag.Prices(),
+		})
+	}
+	experimentsData, _ := json.Marshal(experimentsJSON)
+	markdownsData, _ := json.Marshal(s.Markdowns())
+
+	var overrideData []byte
+	if o := s.WeightOverride(); !o.IsZero() {
+		overrideData, _ = json.Marshal(weightOverrideJSON{
+			ActorID:      o.ActorID(),
+			Decision:     string(o.Decision()),
+			Reason:       o.Reason(),
+			OverriddenAt: o.OverriddenAt(),
+		})
+	}
+
+	var fraudData []byte
+	if flags := s.FraudFlags(); len(flags) > 0 {
+		records := make([]fraudFindingJSON, 0, len(flags))
+		for _, f := range flags {
+			records = append(records, fraudFindingJSON{
+				Rule:       f.Rule(),
+				Action:     string(f.Action()),
+				Reason:     f.Reason(),
+				Stage:      string(f.Stage()),
+				DetectedAt: f.DetectedAt(),
+			})
+		}
+		fraudData, _ = json.Marshal(records)
+	}
+
+	var couponData []byte
+	if c := s.Coupon(); !c.IsZero() {
+		couponData, _ = json.Marshal(appliedCouponJSON{
+			Code:           c.Code(),
+			PercentOff:     c.PercentOff(),
+			AmountOffCents: c.AmountOffCents(),
+		})
+	}
+
+	var taxData []byte
+	if tax := s.Tax(); !tax.IsZero() {
+		record := taxJSON{Included: tax.Included()}
+		for _, l := range tax.Lines() {
+			record.Lines = append(record.Lines, taxLineJSON{RateBasisPoints: int64(l.Rate()), TaxableCents: l.TaxableCents(), TaxCents: l.TaxCents()})
+		}
+		taxData, _ = json.Marshal(record)
+	}
+
+	var walkawaySignal *string
+	var walkawayAt *time.Time
+	if w := s.Walkaway(); !w.IsZero() {
+		signal, at := string(w.Signal()), w.SignaledAt()
+		walkawaySignal, walkawayAt = &signal, &at
+	}
+
+	var unrecognizedData []byte
+	if items := s.UnrecognizedItems(); len(items) > 0 {
+		records := make([]unrecognizedItemJSON, 0, len(items))
+		for _, u := range items {
+			record := unrecognizedItemJSON{Code: u.Code(), Confidence: u.Confidence()}
+			if box, ok := u.Box(); ok {
+				record.BBox = box.Coords()
+			}
+			records = append(records, record)
+		}
+		unrecognizedData, _ = json.Marshal(records)
+	}
+
+	// The update only goes through from the version the session was loaded
+	// at; a new session inserts version 1 and conflicts with an existing one
+	var version int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO sessions (id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, 1)
 		ON CONFLICT (id) DO UPDATE SET
 			status = EXCLUDED.status,
 			items = EXCLUDED.items,
 			total_weight = EXCLUDED.total_weight,
 			total_cents = EXCLUDED.total_cents,
+			rounding_cents = EXCLUDED.rounding_cents,
+			tax = EXCLUDED.tax,
 			currency = EXCLUDED.currency,
-			completed_at = EXCLUDED.completed_at
+			payment_intent_id = EXCLUDED.payment_intent_id,
+			payment_method = EXCLUDED.payment_method,
+			fiscal_record = EXCLUDED.fiscal_record,
+			experiments = EXCLUDED.experiments,
+			markdowns = EXCLUDED.markdowns,
+			cloud_verification_required = EXCLUDED.cloud_verification_required,
+			weight_mismatch = EXCLUDED.weight_mismatch,
+			weight_override = EXCLUDED.weight_override,
+			fraud_flags = EXCLUDED.fraud_flags,
+			coupon = EXCLUDED.coupon,
+			frame_sequence = EXCLUDED.frame_sequence,
+			walkaway_signal = EXCLUDED.walkaway_signal,
+			walkaway_signaled_at = EXCLUDED.walkaway_signaled_at,
+			unrecognized_items = EXCLUDED.unrecognized_items,
+			completed_at = EXCLUDED.completed_at,
+			version = sessions.version + 1
+		WHERE sessions.version = $28
+		RETURNING version
 	`, s.ID().String(), s.DeviceID().String(), userID, string(s.Status()),
-		itemsData, s.TotalWeight().Grams(), s.TotalAmount().Amount(), s.TotalAmount().Currency(),
-		s.CreatedAt(), s.ExpiresAt(), s.CompletedAt())
-
-	return err
+		itemsData, s.TotalWeight().Grams(), s.TotalAmount().Amount(), s.RoundingCents(), taxData, s.TotalAmount().Currency(),
+		intentID, methodData, fiscalRecordData(s.FiscalRecord()), experimentsData, markdownsData, s.CloudVerificationRequired(), s.WeightMismatch(), overrideData, fraudData, couponData, s.FrameSequence(), walkawaySignal, walkawayAt, unrecognizedData, s.CreatedAt(), s.ExpiresAt(), s.CompletedAt(),
+		s.Version()).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrSessionConflict
+	}
+	return version, err
 }
 
 func (r *PostgresSessionRepository) FindByID(ctx context.Context, id valueobjects.SessionID) (*domain.Session, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, device_id, user_id, status, items, total_weight, total_cents, currency, created_at, expires_at, completed_at
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
 		FROM sessions WHERE id = $1
 	`, id.String())
 
-	return r.scanSession(row)
+	sess, err := r.scanSession(row)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return r.findArchived(ctx, id)
+	}
+	return sess, err
 }
 
 func (r *PostgresSessionRepository) FindActiveByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.Session, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, device_id, user_id, status, items, total_weight, total_cents, currency, created_at, expires_at, completed_at
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
 		FROM sessions
 		WHERE device_id = $1 AND status = 'active' AND expires_at > NOW()
 		ORDER BY created_at DESC
@@ -104,13 +406,189 @@ func (r *PostgresSessionRepository) FindActiveByDeviceID(ctx context.Context, de
 	return r.scanSession(row)
 }
 
-func (r *PostgresSessionRepository) scanSession(row pgx.Row) (*domain.Session, error) {
+func (r *PostgresSessionRepository) FindLatestByDeviceID(ctx context.Context, deviceID valueobjects.DeviceID) (*domain.Session, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
+		FROM sessions
+		WHERE device_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, deviceID.String())
+
+	return r.scanSession(row)
+}
+
+// FindCompletedBetween reads archived sessions too, so a past day can be
+// reconciled again after its sessions were archived
+func (r *PostgresSessionRepository) FindCompletedBetween(ctx context.Context, from, to time.Time) ([]*domain.Session, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version, archived
+		FROM `+sessionsAndArchive+`
+		WHERE status = 'completed' AND completed_at >= $1 AND completed_at < $2
+		ORDER BY completed_at
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		sess, err := r.scanListedSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// FindAwaitingReview filters on the items by containment, as the items are
+// stored as JSON
+func (r *PostgresSessionRepository) FindAwaitingReview(ctx context.Context) ([]*domain.Session, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
+		FROM sessions
+		WHERE status = 'active' AND expires_at > NOW() AND items @> '[{"review": "pending"}]'
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		sess, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// FindWalkedAway returns the active sessions whose first walkaway signal came
+// before the given time, oldest signal first
+func (r *PostgresSessionRepository) FindWalkedAway(ctx context.Context, signaledBefore time.Time) ([]*domain.Session, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
+		FROM sessions
+		WHERE status = 'active' AND walkaway_signaled_at <= $1
+		ORDER BY walkaway_signaled_at
+	`, signaledBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		sess, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// ExperimentStats counts started and completed sessions and completed revenue
+// per variant, archived sessions included; the GIN index on experiments
+// serves the containment filter on the sessions table
+func (r *PostgresSessionRepository) ExperimentStats(ctx context.Context, experimentID string) ([]domain.ExperimentVariantStats, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT tag->>'variant' AS variant,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE s.status = 'completed'),
+			COALESCE(SUM(s.total_cents) FILTER (WHERE s.status = 'completed'), 0),
+			COALESCE(MAX(s.currency), '')
+		FROM `+sessionsAndArchive+`, jsonb_array_elements(s.experiments) AS tag
+		WHERE s.experiments @> jsonb_build_array(jsonb_build_object('experiment_id', $1::text))
+			AND tag->>'experiment_id' = $1
+		GROUP BY variant
+		ORDER BY variant
+	`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.ExperimentVariantStats
+	for rows.Next() {
+		var st domain.ExperimentVariantStats
+		if err := rows.Scan(&st.Variant, &st.Sessions, &st.CompletedSessions, &st.RevenueCents, &st.Currency); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
+
+// FindPage pages through sessions by (created_at, id) so that sessions
+// started while an operator pages are neither skipped nor repeated; archived
+// sessions are listed along with the others
+func (r *PostgresSessionRepository) FindPage(ctx context.Context, filter domain.SessionFilter, after *domain.SessionCursor, limit int) ([]*domain.Session, error) {
+	var device, user, status, afterID *string
+	var from, to, afterCreatedAt *time.Time
+	if !filter.DeviceID.IsZero() {
+		id := filter.DeviceID.String()
+		device = &id
+	}
+	if filter.UserID != "" {
+		user = &filter.UserID
+	}
+	if filter.Status != "" {
+		st := string(filter.Status)
+		status = &st
+	}
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	if after != nil {
+		id := after.ID.String()
+		afterID, afterCreatedAt = &id, &after.CreatedAt
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version, archived
+		FROM `+sessionsAndArchive+`
+		WHERE ($1::uuid IS NULL OR device_id = $1::uuid)
+			AND ($2::text IS NULL OR user_id = $2)
+			AND ($3::text IS NULL OR status = $3)
+			AND ($4::timestamptz IS NULL OR created_at >= $4)
+			AND ($5::timestamptz IS NULL OR created_at < $5)
+			AND ($6::timestamptz IS NULL OR (created_at, id) < ($6, $7::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $8
+	`, device, user, status, from, to, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		sess, err := r.scanListedSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// scanSession scans the session columns, then any extra columns into extra
+func (r *PostgresSessionRepository) scanSession(row pgx.Row, extra ...any) (*domain.Session, error) {
 	var rec sessionRow
-	err := row.Scan(
+	err := row.Scan(append([]any{
 		&rec.ID, &rec.DeviceID, &rec.UserID, &rec.Status, &rec.Items,
-		&rec.TotalWeight, &rec.TotalCents, &rec.Currency,
-		&rec.CreatedAt, &rec.ExpiresAt, &rec.CompletedAt,
-	)
+		&rec.TotalWeight, &rec.TotalCents, &rec.Rounding, &rec.Tax, &rec.Currency, &rec.IntentID, &rec.Method, &rec.Fiscal, &rec.Experiments, &rec.Markdowns, &rec.CloudVerify, &rec.WeightMismatch, &rec.WeightOverride, &rec.FraudFlags, &rec.Coupon, &rec.FrameSequence, &rec.WalkawaySignal, &rec.WalkawayAt, &rec.Unrecognized,
+		&rec.CreatedAt, &rec.ExpiresAt, &rec.CompletedAt, &rec.Version,
+	}, extra...)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrSessionNotFound
@@ -138,18 +616,110 @@ func (r *PostgresSessionRepository) reconstitute(rec sessionRow) *domain.Session
 	for _, item := range itemsJSON {
 		skuID, _ := valueobjects.SKUIDFrom(item.SKUID)
 		price, _ := valueobjects.NewMoney(item.PriceCents, item.Currency)
-		detectedItems = append(detectedItems, domain.NewDetectedItem(
+		detectedItem := domain.NewDetectedItem(
 			skuID,
 			item.Code,
 			item.Name,
 			item.Confidence,
 			price,
-		))
+		)
+		if item.Bundle != "" {
+			detectedItem = detectedItem.InBundle(item.Bundle, price)
+		}
+		detectedItem = detectedItem.WithQuantity(item.Quantity)
+		detectedItem = detectedItem.WithReview(domain.ItemReview(item.Review))
+		detectedItem = detectedItem.WithBoxes(boundingBoxes(item.BBoxes))
+		detectedItems = append(detectedItems, detectedItem)
 	}
 
 	totalWeight, _ := valueobjects.NewWeight(rec.TotalWeight)
 	totalAmount, _ := valueobjects.NewMoney(rec.TotalCents, rec.Currency)
 
+	intentID := ""
+	if rec.IntentID != nil {
+		intentID = *rec.IntentID
+	}
+
+	var method domain.PaymentMethod
+	if len(rec.Method) > 0 {
+		var pm paymentMethodJSON
+		if json.Unmarshal(rec.Method, &pm) == nil {
+			method = domain.NewPaymentMethod(domain.WalletType(pm.Wallet), pm.Brand, pm.Last4)
+		}
+	}
+
+	var experiments []domain.ExperimentTag
+	if len(rec.Experiments) > 0 {
+		var tags []experimentTagJSON
+		if json.Unmarshal(rec.Experiments, &tags) == nil {
+			for _, tag := range tags {
+				experiments = append(experiments, domain.NewExperimentTag(tag.ExperimentID, tag.Variant, tag.Prices))
+			}
+		}
+	}
+
+	markdowns := map[string]int{}
+	if len(rec.Markdowns) > 0 {
+		_ = json.Unmarshal(rec.Markdowns, &markdowns)
+	}
+
+	var tax domain.Tax
+	if len(rec.Tax) > 0 {
+		var record taxJSON
+		if json.Unmarshal(rec.Tax, &record) == nil {
+			var lines []domain.TaxLine
+			for _, l := range record.Lines {
+				lines = append(lines, domain.NewTaxLine(policy.TaxRate(l.RateBasisPoints), l.TaxableCents, l.TaxCents))
+			}
+			tax = domain.ReconstituteTax(record.Included, lines)
+		}
+	}
+
+	var override domain.WeightOverride
+	if len(rec.WeightOverride) > 0 {
+		var o weightOverrideJSON
+		if json.Unmarshal(rec.WeightOverride, &o) == nil {
+			override = domain.NewWeightOverride(o.ActorID, domain.WeightDecision(o.Decision), o.Reason, o.OverriddenAt)
+		}
+	}
+
+	var flags []domain.FraudFinding
+	if len(rec.FraudFlags) > 0 {
+		var records []fraudFindingJSON
+		if json.Unmarshal(rec.FraudFlags, &records) == nil {
+			for _, f := range records {
+				flags = append(flags, domain.NewFraudFinding(f.Rule, domain.FraudAction(f.Action), f.Reason, domain.FraudStage(f.Stage), f.DetectedAt))
+			}
+		}
+	}
+
+	var coupon domain.AppliedCoupon
+	if len(rec.Coupon) > 0 {
+		var c appliedCouponJSON
+		if json.Unmarshal(rec.Coupon, &c) == nil {
+			coupon = domain.NewAppliedCoupon(c.Code, c.PercentOff, c.AmountOffCents)
+		}
+	}
+
+	var walkaway domain.Walkaway
+	if rec.WalkawaySignal != nil && rec.WalkawayAt != nil {
+		walkaway = domain.NewWalkaway(domain.WalkawaySignal(*rec.WalkawaySignal), *rec.WalkawayAt)
+	}
+
+	var unrecognized []domain.UnrecognizedItem
+	if len(rec.Unrecognized) > 0 {
+		var records []unrecognizedItemJSON
+		if json.Unmarshal(rec.Unrecognized, &records) == nil {
+			for _, u := range records {
+				item := domain.NewUnrecognizedItem(u.Code, u.Confidence)
+				if box, ok := domain.NewBoundingBox(u.BBox); ok {
+					item = item.At(box)
+				}
+				unrecognized = append(unrecognized, item)
+			}
+		}
+	}
+
 	return domain.Reconstitute(
 		id,
 		deviceID,
@@ -158,8 +728,44 @@ func (r *PostgresSessionRepository) reconstitute(rec sessionRow) *domain.Session
 		detectedItems,
 		totalWeight,
 		totalAmount,
+		rec.Rounding,
+		tax,
+		intentID,
+		method,
+		fiscalRecordFrom(rec.Fiscal),
+		experiments,
+		markdowns,
+		rec.CloudVerify,
+		rec.WeightMismatch,
+		override,
+		flags,
+		coupon,
+		rec.FrameSequence,
+		walkaway,
+		unrecognized,
 		rec.CreatedAt,
 		rec.ExpiresAt,
 		rec.CompletedAt,
+		rec.Version,
 	)
 }
+
+func boxCoords(boxes []domain.BoundingBox) [][]float64 {
+	var coords [][]float64
+	for _, b := range boxes {
+		coords = append(coords, b.Coords())
+	}
+	return coords
+}
+
+// boundingBoxes rebuilds the stored boxes, skipping any that no longer
+// make a valid box
+func boundingBoxes(coords [][]float64) []domain.BoundingBox {
+	var boxes []domain.BoundingBox
+	for _, c := range coords {
+		if box, ok := domain.NewBoundingBox(c); ok {
+			boxes = append(boxes, box)
+		}
+	}
+	return boxes
+}
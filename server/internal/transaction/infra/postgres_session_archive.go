@@ -0,0 +1,129 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// ArchiveEnded implements domain.SessionArchive. The sessions are moved to
+// sessions_archive as whole rows, so an archive outlives later changes to the
+// sessions table; their idempotency records go with them, while their
+// transactions, refunds, disputes, notes and images stay where they are,
+// still referring to them through session_ids.
+func (r *PostgresSessionRepository) ArchiveEnded(ctx context.Context, startedBefore time.Time, limit int) (int, error) {
+	tx, err := postgres.Begin(ctx, r.pool)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Sessions being saved right now are skipped rather than waited for
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM sessions
+		WHERE status IN ('completed', 'cancelled', 'expired') AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, startedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM processed_detections WHERE session_id = ANY($1::uuid[])`, ids); err != nil {
+		return 0, err
+	}
+
+	// Archived sessions cannot be saved again, so each is archived once
+	tag, err := tx.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM sessions WHERE id = ANY($1::uuid[])
+			RETURNING *
+		)
+		INSERT INTO sessions_archive (id, device_id, user_id, status, created_at, archived_at, data)
+		SELECT id, device_id, user_id, status, created_at, $2, to_jsonb(moved) FROM moved
+	`, ids, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// findArchived reads an archived session back into the shape of a sessions
+// row, marked archived so it cannot be saved; it returns
+// domain.ErrSessionNotFound when the archive lacks it too
+func (r *PostgresSessionRepository) findArchived(ctx context.Context, id valueobjects.SessionID) (*domain.Session, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, device_id, user_id, status, items, total_weight, total_cents, rounding_cents, tax, currency, payment_intent_id, payment_method, fiscal_record, experiments, markdowns, cloud_verification_required, weight_mismatch, weight_override, fraud_flags, coupon, frame_sequence, walkaway_signal, walkaway_signaled_at, unrecognized_items, created_at, expires_at, completed_at, version
+		FROM (SELECT (jsonb_populate_record(NULL::sessions, data)).* FROM sessions_archive WHERE id = $1) archived
+	`, id.String())
+
+	sess, err := r.scanSession(row)
+	if err != nil {
+		return nil, err
+	}
+	sess.MarkArchived()
+	return sess, nil
+}
+
+// sessionsAndArchive is the sessions table and the archive as one relation
+// "s" of sessions rows followed by an archived column, for the reads that
+// fall through to the archive
+const sessionsAndArchive = `(
+		SELECT sessions.*, false AS archived FROM sessions
+		UNION ALL
+		SELECT (jsonb_populate_record(NULL::sessions, data)).*, true FROM sessions_archive
+	) s`
+
+// scanListedSession scans a row read from sessionsAndArchive, marking a
+// session that came from the archive
+func (r *PostgresSessionRepository) scanListedSession(row pgx.Row) (*domain.Session, error) {
+	var archived bool
+	sess, err := r.scanSession(row, &archived)
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		sess.MarkArchived()
+	}
+	return sess, nil
+}
+
+// sessionReferenceError maps a row referring to a session that does not
+// exist to domain.ErrSessionNotFound. Rows refer to sessions through
+// session_ids, which keeps every session ID whether the session is in the
+// sessions table or the archive.
+func sessionReferenceError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && strings.HasSuffix(pgErr.ConstraintName, "_session_ids_fkey") {
+		return domain.ErrSessionNotFound
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresSessionImageRepository implements domain.SessionImageRepository
+type PostgresSessionImageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresSessionImageRepository(pool *pgxpool.Pool) *PostgresSessionImageRepository {
+	return &PostgresSessionImageRepository{pool: pool}
+}
+
+func (r *PostgresSessionImageRepository) Save(ctx context.Context, sessionID valueobjects.SessionID, image []byte, capturedAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO session_images (session_id, image, captured_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO UPDATE SET
+			image = EXCLUDED.image,
+			captured_at = EXCLUDED.captured_at
+	`, sessionID.String(), image, capturedAt)
+
+	return sessionReferenceError(err)
+}
+
+func (r *PostgresSessionImageRepository) FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) ([]byte, error) {
+	var image []byte
+	err := r.pool.QueryRow(ctx, `SELECT image FROM session_images WHERE session_id = $1`, sessionID.String()).Scan(&image)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSessionImageNotFound
+		}
+		return nil, err
+	}
+	return image, nil
+}
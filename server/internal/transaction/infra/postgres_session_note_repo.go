@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresSessionNoteRepository implements domain.SessionNoteRepository
+type PostgresSessionNoteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresSessionNoteRepository(pool *pgxpool.Pool) *PostgresSessionNoteRepository {
+	return &PostgresSessionNoteRepository{pool: pool}
+}
+
+func (r *PostgresSessionNoteRepository) Append(ctx context.Context, n domain.SessionNote) (domain.SessionNote, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO session_notes (session_id, author_id, note, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, n.SessionID.String(), n.AuthorID, n.Note, n.CreatedAt).Scan(&n.ID)
+
+	return n, sessionReferenceError(err)
+}
+
+func (r *PostgresSessionNoteRepository) FindBySession(ctx context.Context, sessionID valueobjects.SessionID) ([]domain.SessionNote, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, author_id, note, created_at
+		FROM session_notes
+		WHERE session_id = $1
+		ORDER BY id
+	`, sessionID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []domain.SessionNote
+	for rows.Next() {
+		n := domain.SessionNote{SessionID: sessionID}
+		if err := rows.Scan(&n.ID, &n.AuthorID, &n.Note, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
@@ -0,0 +1,69 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresSyncLogRepository implements domain.SyncLogRepository
+type PostgresSyncLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresSyncLogRepository(pool *pgxpool.Pool) *PostgresSyncLogRepository {
+	return &PostgresSyncLogRepository{pool: pool}
+}
+
+func (r *PostgresSyncLogRepository) Save(ctx context.Context, record domain.SyncRecord) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO offline_sync_entries (device_id, client_id, session_id, kind, recorded_at, status, reason, synced_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (device_id, client_id) DO NOTHING
+	`, record.DeviceID, record.ClientID, record.SessionID, string(record.Kind),
+		record.RecordedAt, string(record.Status), record.Reason, record.SyncedAt)
+
+	return err
+}
+
+func (r *PostgresSyncLogRepository) Find(ctx context.Context, deviceID, clientID string) (*domain.SyncRecord, error) {
+	var (
+		rec          domain.SyncRecord
+		kind, status string
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT device_id, client_id, session_id, kind, recorded_at, status, reason, synced_at
+		FROM offline_sync_entries
+		WHERE device_id = $1 AND client_id = $2
+	`, deviceID, clientID).Scan(
+		&rec.DeviceID, &rec.ClientID, &rec.SessionID, &kind, &rec.RecordedAt, &status, &rec.Reason, &rec.SyncedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSyncRecordNotFound
+		}
+		return nil, err
+	}
+
+	rec.Kind = domain.OfflineEntryKind(kind)
+	rec.Status = domain.SyncStatus(status)
+	return &rec, nil
+}
+
+func (r *PostgresSyncLogRepository) LatestAppliedDetection(ctx context.Context, sessionID string) (*time.Time, error) {
+	var latest *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT MAX(recorded_at)
+		FROM offline_sync_entries
+		WHERE session_id = $1 AND kind = $2 AND status = $3
+	`, sessionID, string(domain.OfflineEntryDetection), string(domain.SyncStatusApplied)).Scan(&latest)
+	if err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
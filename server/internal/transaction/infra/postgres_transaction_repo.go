@@ -0,0 +1,289 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/shared/valueobjects"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// PostgresTransactionRepository implements domain.TransactionRepository;
+// transactions are inserted by PostgresSessionRepository.SaveCompleted
+type PostgresTransactionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTransactionRepository(pool *pgxpool.Pool) *PostgresTransactionRepository {
+	return &PostgresTransactionRepository{pool: pool}
+}
+
+type transactionRow struct {
+	ID            string
+	SessionID     string
+	DeviceID      string
+	UserID        *string
+	Items         []byte
+	TotalCents    int64
+	TaxCents      int64
+	RoundingCents int64
+	Currency      string
+	CouponCode    *string
+	DiscountCents int64
+	PaymentRef    *string
+	Fiscal        []byte
+	Status        string
+	CompletedAt   time.Time
+}
+
+// transactionLineJSON has the keys of a session's items, so transactions of
+// sessions completed before they were recorded could be copied from them
+type transactionLineJSON struct {
+	SKUID      string `json:"sku_id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	PriceCents int64  `json:"price_cents"`
+	Quantity   int    `json:"quantity"`
+	Currency   string `json:"currency"`
+	Bundle     string `json:"bundle,omitempty"`
+}
+
+const transactionColumns = `id, session_id, device_id, user_id, items, total_cents, tax_cents, rounding_cents, currency, coupon_code, discount_cents, status, payment_ref, fiscal_record, completed_at`
+
+func insertTransaction(ctx context.Context, tx pgx.Tx, txn *domain.Transaction) error {
+	lines := make([]transactionLineJSON, 0, len(txn.Lines()))
+	for _, l := range txn.Lines() {
+		lines = append(lines, transactionLineJSON{
+			SKUID:      l.SKUID().String(),
+			Code:       l.Code(),
+			Name:       l.Name(),
+			PriceCents: l.UnitPrice().Amount(),
+			Quantity:   l.Quantity(),
+			Currency:   l.UnitPrice().Currency(),
+			Bundle:     l.Bundle(),
+		})
+	}
+	linesData, _ := json.Marshal(lines)
+
+	var userID, couponCode, paymentRef *string
+	if txn.UserID() != "" {
+		u := txn.UserID()
+		userID = &u
+	}
+	if txn.CouponCode() != "" {
+		code := txn.CouponCode()
+		couponCode = &code
+	}
+	if txn.PaymentRef() != "" {
+		ref := txn.PaymentRef()
+		paymentRef = &ref
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO transactions (`+transactionColumns+`, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15)
+	`, txn.ID().String(), txn.SessionID().String(), txn.DeviceID().String(), userID, linesData,
+		txn.Total().Amount(), txn.TaxCents(), txn.RoundingCents(), txn.Total().Currency(),
+		couponCode, txn.DiscountCents(), string(txn.Status()), paymentRef, fiscalRecordData(txn.FiscalRecord()), txn.CompletedAt())
+	return sessionReferenceError(err)
+}
+
+func (r *PostgresTransactionRepository) FindByID(ctx context.Context, id valueobjects.TransactionID) (*domain.Transaction, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id.String())
+	return r.scanTransaction(row)
+}
+
+func (r *PostgresTransactionRepository) FindBySessionID(ctx context.Context, sessionID valueobjects.SessionID) (*domain.Transaction, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE session_id = $1`, sessionID.String())
+	return r.scanTransaction(row)
+}
+
+func (r *PostgresTransactionRepository) FindCompletedByUser(ctx context.Context, userID string, from, to time.Time) ([]*domain.Transaction, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE user_id = $1 AND status = 'completed' AND completed_at >= $2 AND completed_at < $3
+		ORDER BY completed_at
+	`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txns []*domain.Transaction
+	for rows.Next() {
+		txn, err := r.scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	return txns, rows.Err()
+}
+
+func (r *PostgresTransactionRepository) SoldUnits(ctx context.Context, deviceID valueobjects.DeviceID, since map[string]time.Time) (map[string]int, error) {
+	sold := make(map[string]int, len(since))
+	if len(since) == 0 {
+		return sold, nil
+	}
+
+	codes := make([]string, 0, len(since))
+	times := make([]time.Time, 0, len(since))
+	for code, t := range since {
+		codes = append(codes, code)
+		times = append(times, t)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT item->>'code', SUM(COALESCE((item->>'quantity')::int, 1))
+		FROM transactions t
+		CROSS JOIN jsonb_array_elements(t.items) AS item
+		JOIN unnest($2::text[], $3::timestamptz[]) AS w(code, since) ON w.code = item->>'code'
+		WHERE t.device_id = $1 AND t.status = 'completed' AND t.completed_at >= w.since
+		GROUP BY 1
+	`, deviceID.String(), codes, times)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var units int
+		if err := rows.Scan(&code, &units); err != nil {
+			return nil, err
+		}
+		sold[code] = units
+	}
+	return sold, rows.Err()
+}
+
+func (r *PostgresTransactionRepository) CouponRedemptions(ctx context.Context, code string) (int, error) {
+	var count int
+	err := postgres.Conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT COUNT(*) FROM transactions WHERE coupon_code = $1 AND status = 'completed'
+	`, code).Scan(&count)
+	return count, err
+}
+
+// HoldCoupon takes a transaction-scoped advisory lock on the coupon code, so
+// checkouts redeeming it count its redemptions one after the other
+func (r *PostgresTransactionRepository) HoldCoupon(ctx context.Context, code string) error {
+	_, err := postgres.Conn(ctx, r.pool).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('coupon:' || $1))`, code)
+	return err
+}
+
+// HoldRefunds takes a transaction-scoped advisory lock on the transaction, so
+// refund requests for it check what is left to refund one after the other
+func (r *PostgresTransactionRepository) HoldRefunds(ctx context.Context, id valueobjects.TransactionID) error {
+	_, err := postgres.Conn(ctx, r.pool).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('refunds:' || $1))`, id.String())
+	return err
+}
+
+func (r *PostgresTransactionRepository) scanTransaction(row pgx.Row) (*domain.Transaction, error) {
+	var rec transactionRow
+	err := row.Scan(
+		&rec.ID, &rec.SessionID, &rec.DeviceID, &rec.UserID, &rec.Items, &rec.TotalCents, &rec.TaxCents,
+		&rec.RoundingCents, &rec.Currency, &rec.CouponCode, &rec.DiscountCents, &rec.Status, &rec.PaymentRef, &rec.Fiscal, &rec.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	id, _ := valueobjects.TransactionIDFrom(rec.ID)
+	sessionID, _ := valueobjects.SessionIDFrom(rec.SessionID)
+	deviceID, _ := valueobjects.DeviceIDFrom(rec.DeviceID)
+	total, _ := valueobjects.NewMoney(rec.TotalCents, rec.Currency)
+
+	var linesJSON []transactionLineJSON
+	_ = json.Unmarshal(rec.Items, &linesJSON)
+
+	lines := make([]domain.TransactionLine, 0, len(linesJSON))
+	for _, l := range linesJSON {
+		skuID, _ := valueobjects.SKUIDFrom(l.SKUID)
+		price, _ := valueobjects.NewMoney(l.PriceCents, l.Currency)
+		lines = append(lines, domain.NewTransactionLine(skuID, l.Code, l.Name, price, l.Quantity, l.Bundle))
+	}
+
+	return domain.ReconstituteTransaction(
+		id,
+		sessionID,
+		deviceID,
+		deref(rec.UserID),
+		lines,
+		total,
+		rec.TaxCents,
+		rec.RoundingCents,
+		deref(rec.CouponCode),
+		rec.DiscountCents,
+		deref(rec.PaymentRef),
+		fiscalRecordFrom(rec.Fiscal),
+		domain.TransactionStatus(rec.Status),
+		rec.CompletedAt,
+	), nil
+}
+
+// salesGroupKeys are the SQL expressions a sales report groups by
+var salesGroupKeys = map[domain.SalesGrouping]string{
+	domain.SalesByDay:    `to_char(t.completed_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')`,
+	domain.SalesByDevice: `t.device_id::text`,
+}
+
+func (r *PostgresTransactionRepository) SalesFigures(ctx context.Context, groupBy domain.SalesGrouping, from, to time.Time) ([]domain.SalesFigures, error) {
+	var query string
+	if groupBy == domain.SalesBySKU {
+		query = `
+			SELECT item->>'code', COALESCE(item->>'currency', t.currency),
+				COUNT(DISTINCT t.id),
+				SUM((item->>'price_cents')::bigint * COALESCE((item->>'quantity')::int, 1))::bigint,
+				SUM(COALESCE((item->>'quantity')::int, 1))::bigint
+			FROM transactions t
+			CROSS JOIN jsonb_array_elements(t.items) AS item
+			WHERE t.status = 'completed' AND t.completed_at >= $1 AND t.completed_at < $2
+			GROUP BY 1, 2
+			ORDER BY 1, 2`
+	} else {
+		key, ok := salesGroupKeys[groupBy]
+		if !ok {
+			return nil, fmt.Errorf("unknown sales grouping %q", groupBy)
+		}
+		query = `
+			SELECT ` + key + `, t.currency,
+				COUNT(*),
+				SUM(t.total_cents)::bigint,
+				COALESCE(SUM((
+					SELECT SUM(COALESCE((item->>'quantity')::int, 1))
+					FROM jsonb_array_elements(t.items) AS item
+				)), 0)::bigint
+			FROM transactions t
+			WHERE t.status = 'completed' AND t.completed_at >= $1 AND t.completed_at < $2
+			GROUP BY 1, 2
+			ORDER BY 1, 2`
+	}
+
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var figures []domain.SalesFigures
+	for rows.Next() {
+		var f domain.SalesFigures
+		if err := rows.Scan(&f.Key, &f.Currency, &f.Transactions, &f.RevenueCents, &f.Units); err != nil {
+			return nil, err
+		}
+		figures = append(figures, f)
+	}
+	return figures, rows.Err()
+}
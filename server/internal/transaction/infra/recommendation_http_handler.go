@@ -0,0 +1,57 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type recommendationResponse struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	PriceCents int64   `json:"price_cents"`
+	Currency   string  `json:"currency"`
+	Score      float64 `json:"score"`
+}
+
+func (h *HTTPHandler) Recommendations(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = n
+	}
+
+	recs, err := h.recommendations.ForSession(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]recommendationResponse, 0, len(recs))
+	for _, rec := range recs {
+		response = append(response, recommendationResponse{
+			Code:       rec.Code,
+			Name:       rec.Name,
+			PriceCents: rec.PriceCents,
+			Currency:   rec.Currency,
+			Score:      rec.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id":      c.Param("id"),
+		"recommendations": response,
+	})
+}
@@ -0,0 +1,108 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type runReconciliationRequest struct {
+	Day   string `json:"day" binding:"required"` // YYYY-MM-DD (UTC)
+	Rerun bool   `json:"rerun"`
+}
+
+// RunReconciliation reconciles a past day on demand; the nightly job covers
+// the previous day automatically
+func (h *HTTPHandler) RunReconciliation(c *gin.Context) {
+	var req runReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	day, err := time.Parse(domain.ReconciliationDayLayout, req.Day)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "day must be formatted as YYYY-MM-DD"})
+		return
+	}
+
+	result, err := h.reconcileHandler.Handle(c.Request.Context(), app.ReconcileSessionsCommand{Day: day, Rerun: req.Rerun})
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrReconciliationDayNotOver):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ports.ErrCloudDetectionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if result.Ran {
+		status = http.StatusCreated
+	}
+	c.JSON(status, reconciliationReportResponse(result.Report))
+}
+
+func (h *HTTPHandler) GetReconciliationReport(c *gin.Context) {
+	view, err := h.reconciliations.FindByDay(c.Request.Context(), c.Param("day"), c.Query("device_id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrReconciliationReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "reconciliation report not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliationReportResponse(view))
+}
+
+func reconciliationReportResponse(view *app.ReconciliationReportView) gin.H {
+	lines := make([]gin.H, 0, len(view.Lines))
+	for _, line := range view.Lines {
+		lines = append(lines, gin.H{
+			"device_id":          line.DeviceID,
+			"sku_code":           line.SKUCode,
+			"currency":           line.Currency,
+			"sessions":           line.Sessions,
+			"undercharged_units": line.UnderchargedUnits,
+			"overcharged_units":  line.OverchargedUnits,
+			"undercharged_cents": line.UnderchargedCents,
+			"overcharged_cents":  line.OverchargedCents,
+			"net_impact_cents":   line.NetImpactCents,
+		})
+	}
+
+	discrepancies := make([]gin.H, 0, len(view.Discrepancies))
+	for _, d := range view.Discrepancies {
+		discrepancies = append(discrepancies, gin.H{
+			"session_id":   d.SessionID,
+			"device_id":    d.DeviceID,
+			"sku_code":     d.SKUCode,
+			"charged":      d.Charged,
+			"detected":     d.Detected,
+			"impact_cents": d.ImpactCents,
+			"currency":     d.Currency,
+		})
+	}
+
+	return gin.H{
+		"day":                       view.Day,
+		"model_version":             view.ModelVersion,
+		"sessions_checked":          view.SessionsChecked,
+		"sessions_skipped":          view.SessionsSkipped,
+		"sessions_with_discrepancy": view.SessionsWithDiscrepancy,
+		"generated_at":              view.GeneratedAt,
+		"lines":                     lines,
+		"discrepancies":             discrepancies,
+	}
+}
@@ -0,0 +1,194 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// Operator identity headers, set by the operator's authenticating proxy
+const (
+	actorIDHeader    = "X-Actor-ID"
+	actorRolesHeader = "X-Actor-Roles" // comma-separated, e.g. "support,finance"
+)
+
+type requestRefundRequest struct {
+	AmountCents int64  `json:"amount_cents" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+}
+
+type refundDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+func actorFromRequest(c *gin.Context) (string, []string) {
+	var roles []string
+	for _, role := range strings.Split(c.GetHeader(actorRolesHeader), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader(actorIDHeader)), roles
+}
+
+func (h *HTTPHandler) RequestRefund(c *gin.Context) {
+	var req requestRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, roles := actorFromRequest(c)
+	cmd := app.RequestRefundCommand{
+		SessionID:   c.Param("id"),
+		AmountCents: req.AmountCents,
+		Reason:      req.Reason,
+		ActorID:     actorID,
+		ActorRoles:  roles,
+	}
+
+	result, err := h.refundHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refundResultResponse(result))
+}
+
+func (h *HTTPHandler) ApproveRefund(c *gin.Context) {
+	h.decideRefund(c, true)
+}
+
+func (h *HTTPHandler) RejectRefund(c *gin.Context) {
+	h.decideRefund(c, false)
+}
+
+func (h *HTTPHandler) decideRefund(c *gin.Context, approve bool) {
+	var req refundDecisionRequest
+	_ = c.ShouldBindJSON(&req)
+
+	actorID, roles := actorFromRequest(c)
+	cmd := app.DecideRefundCommand{
+		RefundID:   c.Param("id"),
+		Approve:    approve,
+		Note:       req.Note,
+		ActorID:    actorID,
+		ActorRoles: roles,
+	}
+
+	result, err := h.decideHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		h.writeRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refundResultResponse(result))
+}
+
+func (h *HTTPHandler) GetRefund(c *gin.Context) {
+	view, err := h.refundQueries.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refundViewResponse(view))
+}
+
+func (h *HTTPHandler) ListSessionRefunds(c *gin.Context) {
+	views, err := h.refundQueries.FindBySessionID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refundViewsResponse(views)})
+}
+
+func (h *HTTPHandler) ListPendingRefunds(c *gin.Context) {
+	views, err := h.refundQueries.FindPendingApproval(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refundViewsResponse(views)})
+}
+
+func (h *HTTPHandler) writeRefundError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrActorRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "acting user is required"})
+	case errors.Is(err, domain.ErrRefundApproverNotAuthorized),
+		errors.Is(err, domain.ErrRefundSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+	case errors.Is(err, domain.ErrRefundNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "refund not found"})
+	case errors.Is(err, domain.ErrInvalidRefundAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRefundSessionNotCompleted),
+		errors.Is(err, domain.ErrRefundCurrencyMismatch),
+		errors.Is(err, domain.ErrRefundExceedsTotal),
+		errors.Is(err, domain.ErrRefundNotPending):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func refundResultResponse(result app.RefundResult) gin.H {
+	return gin.H{
+		"refund_id":      result.RefundID,
+		"session_id":     result.SessionID,
+		"transaction_id": result.TransactionID,
+		"amount_cents":   result.AmountCents,
+		"rounding_cents": result.RoundingCents,
+		"currency":       result.Currency,
+		"status":         result.Status,
+	}
+}
+
+func refundViewsResponse(views []*app.RefundView) []gin.H {
+	out := make([]gin.H, 0, len(views))
+	for _, v := range views {
+		out = append(out, refundViewResponse(v))
+	}
+	return out
+}
+
+func refundViewResponse(v *app.RefundView) gin.H {
+	audit := make([]gin.H, 0, len(v.AuditTrail))
+	for _, entry := range v.AuditTrail {
+		audit = append(audit, gin.H{
+			"action":   entry.Action,
+			"actor_id": entry.ActorID,
+			"roles":    entry.Roles,
+			"note":     entry.Note,
+			"at":       entry.At,
+		})
+	}
+
+	return gin.H{
+		"id":             v.ID,
+		"session_id":     v.SessionID,
+		"transaction_id": v.TransactionID,
+		"amount_cents":   v.AmountCents,
+		"rounding_cents": v.RoundingCents,
+		"currency":       v.Currency,
+		"reason":         v.Reason,
+		"status":         v.Status,
+		"requested_by":   v.RequestedBy,
+		"decided_by":     v.DecidedBy,
+		"created_at":     v.CreatedAt,
+		"decided_at":     v.DecidedAt,
+		"audit_trail":    audit,
+	}
+}
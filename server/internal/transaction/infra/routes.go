@@ -8,14 +8,91 @@ func (h *HTTPHandler) RegisterRoutes(r *gin.RouterGroup) {
 	sessions := r.Group("/session")
 	{
 		sessions.POST("/start", h.Start)
+		sessions.GET("/active", h.Resume)
 		sessions.GET("/:id", h.Get)
+		sessions.GET("/:id/stream", h.Stream)
+		sessions.GET("/:id/events", h.Events)
 		sessions.POST("/:id/confirm", h.Confirm)
 		sessions.POST("/:id/cancel", h.Cancel)
+		sessions.POST("/:id/extend", h.Extend)
+		sessions.POST("/:id/verify-image", h.VerifyImage)
+		sessions.GET("/:id/detections", h.ListSessionDetections)
+		sessions.POST("/:id/weight-override", h.OverrideWeight)
+		sessions.POST("/:id/item-review", h.ReviewItem)
+		sessions.POST("/:id/apply-coupon", h.ApplyCoupon)
+		sessions.POST("/:id/notes", h.AddSessionNote)
+		sessions.POST("/:id/pay", h.Pay)
+		sessions.POST("/:id/pay/wallet", h.PayWithWallet)
+		sessions.POST("/:id/refunds", h.RequestRefund)
+		sessions.GET("/:id/refunds", h.ListSessionRefunds)
+		sessions.POST("/:id/disputes", h.OpenDispute)
+		sessions.GET("/:id/disputes", h.ListSessionDisputes)
 	}
 
+	// Session history (operator dashboard and support tooling)
+	r.GET("/sessions", h.ListSessions)
+
+	// Cancel the unpaid sessions customers walked away from now
+	r.POST("/sessions/walkaway-sweep", h.SweepWalkaways)
+
+	// Move the sessions that ended long ago to the archive now
+	r.POST("/sessions/archive-sweep", h.SweepArchive)
+
+	// Purchase history (mobile app)
+	r.GET("/users/:id/sessions", h.ListUserSessions)
+
+	// Upsell suggestions for the mobile app while the fridge is open
+	r.GET("/sessions/:id/recommendations", h.Recommendations)
+
+	// Lines detected below the confidence threshold, for attendants to review
+	r.GET("/reviews/pending", h.ListPendingReviews)
+
+	// Refund approval routes (operator staff)
+	refunds := r.Group("/refunds")
+	{
+		refunds.GET("/pending", h.ListPendingRefunds)
+		refunds.GET("/:id", h.GetRefund)
+		refunds.POST("/:id/approve", h.ApproveRefund)
+		refunds.POST("/:id/reject", h.RejectRefund)
+	}
+
+	// Chargeback tracking (operator staff; resolution by the finance team)
+	disputes := r.Group("/disputes")
+	{
+		disputes.GET("", h.ListDisputes)
+		disputes.GET("/report", h.DisputeReport)
+		disputes.GET("/:id", h.GetDispute)
+		disputes.GET("/:id/image", h.GetDisputeImage)
+		disputes.POST("/:id/notes", h.AnnotateDispute)
+		disputes.POST("/:id/evidence", h.AttachDisputeEvidence)
+		disputes.POST("/:id/resolve", h.ResolveDispute)
+	}
+
+	// Sales figures for the operator dashboard and finance
+	r.GET("/reports/sales", h.SalesReport)
+
+	// Edge vs cloud reconciliation (fraud and accuracy reporting)
+	reconciliation := r.Group("/reconciliation")
+	{
+		reconciliation.POST("/runs", h.RunReconciliation)
+		reconciliation.GET("/reports/:day", h.GetReconciliationReport)
+	}
+
+	// Wallet payment routes
+	payments := r.Group("/payments")
+	{
+		payments.POST("/apple-pay/merchant-session", h.ApplePayMerchantSession)
+	}
+
+	// Asynchronous payment outcomes from the payment provider
+	r.POST("/webhooks/payments", h.PaymentWebhook)
+
 	// Device detection route (used by ESP32 devices)
 	device := r.Group("/device")
 	{
 		device.POST("/detection", h.SubmitDetection)
+		device.POST("/detection/changes", h.SubmitDetectionChanges)
+		device.POST("/sync", h.SyncOfflineEntries)
+		device.POST("/signal", h.DeviceSignal)
 	}
 }
@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+)
+
+// SalesReport aggregates the completed sales of a period by day, device or
+// SKU, with the revenue, transaction count and average basket of each
+func (h *HTTPHandler) SalesReport(c *gin.Context) {
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.transactionQueries.SalesReport(c.Request.Context(), app.SalesReportQuery{
+		GroupBy: c.Query("group_by"),
+		From:    from,
+		To:      to,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrUnknownSalesGrouping),
+			errors.Is(err, app.ErrInvalidSalesPeriod):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	lines := make([]gin.H, 0, len(report.Lines))
+	for _, line := range report.Lines {
+		lines = append(lines, gin.H{
+			"key":                  line.Key,
+			"currency":             line.Currency,
+			"transactions":         line.Transactions,
+			"revenue_cents":        line.RevenueCents,
+			"units":                line.Units,
+			"average_basket_cents": line.AverageBasketCents,
+			"average_basket_units": line.AverageBasketUnits,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"group_by": report.GroupBy,
+		"from":     report.From.UTC().Format(time.RFC3339),
+		"to":       report.To.UTC().Format(time.RFC3339),
+		"lines":    lines,
+	})
+}
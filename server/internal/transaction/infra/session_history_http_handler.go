@@ -0,0 +1,196 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+const (
+	defaultSessionHistoryLimit = 50
+	maxSessionHistoryLimit     = 200
+)
+
+type sessionSummaryResponse struct {
+	ID          string  `json:"id"`
+	DeviceID    string  `json:"device_id"`
+	UserID      string  `json:"user_id,omitempty"`
+	Status      string  `json:"status"`
+	Items       int     `json:"items"`
+	TotalCents  int64   `json:"total_cents"`
+	Currency    string  `json:"currency"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+// ListSessions pages through the session history, newest first, for the
+// operator dashboard and support tooling. It filters by ?device_id=,
+// ?status= and the start time with ?from= and ?to= (RFC 3339), and pages
+// with ?limit= and the ?cursor= of the previous page's next_cursor.
+func (h *HTTPHandler) ListSessions(c *gin.Context) {
+	query, err := parseSessionHistoryQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.queryService.History(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidDeviceID),
+			errors.Is(err, app.ErrInvalidSessionCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	sessions := make([]sessionSummaryResponse, 0, len(page.Sessions))
+	for _, view := range page.Sessions {
+		sessions = append(sessions, sessionSummaryResponse{
+			ID:          view.ID,
+			DeviceID:    view.DeviceID,
+			UserID:      view.UserID,
+			Status:      view.Status,
+			Items:       view.Units,
+			TotalCents:  view.TotalCents,
+			Currency:    view.Currency,
+			CreatedAt:   view.CreatedAt,
+			CompletedAt: view.CompletedAt,
+		})
+	}
+	response := gin.H{
+		"sessions": sessions,
+		"limit":    query.Limit,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func parseSessionHistoryQuery(c *gin.Context) (app.SessionHistoryQuery, error) {
+	query := app.SessionHistoryQuery{
+		DeviceID: c.Query("device_id"),
+		Cursor:   c.Query("cursor"),
+	}
+
+	switch status := domain.SessionStatus(c.Query("status")); status {
+	case "", domain.SessionStatusActive, domain.SessionStatusAwaitingPayment, domain.SessionStatusCompleted, domain.SessionStatusCancelled, domain.SessionStatusExpired:
+		query.Status = status
+	default:
+		return app.SessionHistoryQuery{}, errors.New("status must be active, awaiting_payment, completed, cancelled or expired")
+	}
+
+	for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return app.SessionHistoryQuery{}, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = t
+		}
+	}
+
+	limit, err := parseSessionHistoryLimit(c)
+	if err != nil {
+		return app.SessionHistoryQuery{}, err
+	}
+	query.Limit = limit
+	return query, nil
+}
+
+func parseSessionHistoryLimit(c *gin.Context) (int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSessionHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxSessionHistoryLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxSessionHistoryLimit)
+	}
+	return limit, nil
+}
+
+type purchaseResponse struct {
+	SessionID     string                `json:"session_id"`
+	DeviceID      string                `json:"device_id"`
+	Items         []sessionItemResponse `json:"items"`
+	SubtotalCents int64                 `json:"subtotal_cents"`
+	TaxCents      int64                 `json:"tax_cents"`
+	RoundingCents int64                 `json:"rounding_cents"`
+	TotalCents    int64                 `json:"total_cents"`
+	Currency      string                `json:"currency"`
+	CompletedAt   *string               `json:"completed_at"`
+	PaymentMethod gin.H                 `json:"payment_method,omitempty"`
+}
+
+// ListUserSessions is the purchase history of the mobile app: the user's
+// completed sessions, newest first, with item names in the customer's
+// languages. It pages with ?limit= and ?cursor= like ListSessions.
+func (h *HTTPHandler) ListUserSessions(c *gin.Context) {
+	limit, err := parseSessionHistoryLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.queryService.PurchaseHistory(c.Request.Context(), c.Param("id"), limit, c.Query("cursor"))
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidSessionCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	languages := preferredLanguages(c)
+	purchases := make([]purchaseResponse, 0, len(page.Sessions))
+	for _, view := range page.Sessions {
+		items := make([]sessionItemResponse, 0, len(view.Items))
+		for _, item := range view.Items {
+			items = append(items, h.localizeItem(c.Request.Context(), sessionItemResponse{
+				Code:            item.Code,
+				Name:            item.Name,
+				PriceCents:      item.PriceCents,
+				Quantity:        item.Quantity,
+				LineTotalCents:  item.LineTotalCents,
+				Currency:        item.Currency,
+				Confidence:      item.Confidence,
+				MarkdownPercent: item.MarkdownPercent,
+				Bundle:          item.Bundle,
+			}, languages))
+		}
+
+		purchase := purchaseResponse{
+			SessionID:     view.ID,
+			DeviceID:      view.DeviceID,
+			Items:         items,
+			SubtotalCents: view.SubtotalCents,
+			TaxCents:      view.TaxCents,
+			RoundingCents: view.RoundingCents,
+			TotalCents:    view.TotalCents,
+			Currency:      view.Currency,
+			CompletedAt:   view.CompletedAt,
+		}
+		if view.Payment != nil {
+			purchase.PaymentMethod = paymentMethodResponse(view.Payment.Wallet, view.Payment.CardBrand, view.Payment.CardLast4)
+		}
+		purchases = append(purchases, purchase)
+	}
+
+	response := gin.H{
+		"user_id":  c.Param("id"),
+		"sessions": purchases,
+		"limit":    limit,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,65 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type sessionNoteRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// AddSessionNote attaches a support staff member's note to a session; the
+// notes are listed on the session detail
+func (h *HTTPHandler) AddSessionNote(c *gin.Context) {
+	var req sessionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := actorFromRequest(c)
+	note, err := h.addSessionNoteHandler.Handle(c.Request.Context(), app.AddSessionNoteCommand{
+		SessionID: c.Param("id"),
+		Note:      req.Note,
+		ActorID:   actorID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrActorRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "acting user is required"})
+		case errors.Is(err, domain.ErrSessionNoteMissing),
+			errors.Is(err, domain.ErrSessionNoteTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, sessionNoteResponse(*note))
+}
+
+func sessionNotesResponse(notes []app.SessionNoteView) []gin.H {
+	out := make([]gin.H, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, sessionNoteResponse(n))
+	}
+	return out
+}
+
+func sessionNoteResponse(n app.SessionNoteView) gin.H {
+	return gin.H{
+		"id":         n.ID,
+		"author_id":  n.AuthorID,
+		"note":       n.Note,
+		"created_at": n.CreatedAt,
+	}
+}
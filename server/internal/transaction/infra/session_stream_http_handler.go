@@ -0,0 +1,146 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+// streamKeepAlive keeps idle proxies from closing the stream between updates
+const streamKeepAlive = 15 * time.Second
+
+// Stream pushes the session's items and total to the customer app as
+// Server-Sent Events: an "update" event for the current state and each
+// change, then a "final" event once the session is confirmed, cancelled or
+// expired. EventSource cannot send headers, so the stream token issued at
+// session start is passed as ?token=.
+func (h *HTTPHandler) Stream(c *gin.Context) {
+	updates, err := h.sessionStream.Watch(c.Request.Context(), c.Param("id"), c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidSessionToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	languages := preferredLanguages(c)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			event := "update"
+			if update.Final {
+				event = "final"
+			}
+			c.SSEvent(event, h.sessionResponse(c.Request.Context(), update.Session, languages))
+			return !update.Final
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+			return true
+		}
+	})
+}
+
+// Events pushes what happens to the session as Server-Sent Events named
+// after it: "session" with the session as the stream opens, "item_detected"
+// with the basket and "total_updated" with the totals as they change, then
+// "confirmed", "cancelled" or "expired" to end the stream. It takes the same
+// ?token= as Stream.
+func (h *HTTPHandler) Events(c *gin.Context) {
+	events, err := h.sessionStream.Events(c.Request.Context(), c.Param("id"), c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidSessionToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	languages := preferredLanguages(c)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(evt.Type, h.sessionEventData(c.Request.Context(), evt, languages))
+			return !evt.Final()
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+			return true
+		}
+	})
+}
+
+// sessionEventData is what an event tells about the session: all of it for
+// the snapshot, else what the event is about
+func (h *HTTPHandler) sessionEventData(ctx context.Context, evt app.SessionEvent, languages []string) gin.H {
+	view := evt.Session
+	switch evt.Type {
+	case app.SessionEventItemDetected:
+		return gin.H{
+			"session_id": view.ID,
+			"items":      h.sessionItems(ctx, view, languages),
+			"units":      view.Units,
+		}
+	case app.SessionEventTotalUpdated:
+		return gin.H{
+			"session_id":     view.ID,
+			"subtotal_cents": view.SubtotalCents,
+			"discount_cents": view.DiscountCents,
+			"tax_cents":      view.TaxCents,
+			"rounding_cents": view.RoundingCents,
+			"total_cents":    view.TotalCents,
+			"currency":       view.Currency,
+		}
+	case app.SessionEventConfirmed, app.SessionEventCancelled, app.SessionEventExpired:
+		data := gin.H{
+			"session_id":  view.ID,
+			"status":      view.Status,
+			"total_cents": view.TotalCents,
+			"currency":    view.Currency,
+		}
+		if view.CompletedAt != nil {
+			data["completed_at"] = *view.CompletedAt
+		}
+		return data
+	default:
+		return h.sessionResponse(ctx, view, languages)
+	}
+}
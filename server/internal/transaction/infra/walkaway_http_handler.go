@@ -0,0 +1,86 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type deviceSignalRequest struct {
+	DeviceID  string `json:"device_id" binding:"required"`
+	SessionID string `json:"session_id" binding:"required"`
+	Signal    string `json:"signal" binding:"required"` // door_closed or items_removed
+}
+
+// DeviceSignal records that the customer left the device; the session is
+// cancelled at cancel_at unless it is paid for by then or holds no items
+func (h *HTTPHandler) DeviceSignal(c *gin.Context) {
+	var req deviceSignalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.walkawayHandler.Handle(c.Request.Context(), app.SignalWalkawayCommand{
+		DeviceID:  req.DeviceID,
+		SessionID: req.SessionID,
+		Signal:    req.Signal,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownWalkawaySignal):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionDeviceMismatch):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id":  result.SessionID,
+		"signal":      result.Signal,
+		"signaled_at": result.SignaledAt,
+		"cancel_at":   result.CancelAt,
+	})
+}
+
+// SweepWalkaways runs the walkaway sweep now instead of waiting for the next
+// scheduled one. ?cancel_after_seconds= overrides the grace.
+func (h *HTTPHandler) SweepWalkaways(c *gin.Context) {
+	var cmd app.CancelWalkawaysCommand
+	if raw := c.Query("cancel_after_seconds"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cancel_after_seconds must be a non-negative number"})
+			return
+		}
+		cancelAfter := time.Duration(seconds) * time.Second
+		cmd.CancelAfter = &cancelAfter
+	}
+
+	result, err := h.walkawaySweeper.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cancel_after_seconds": int(result.CancelAfter / time.Second),
+		"count":                len(result.Cancelled),
+		"session_ids":          result.Cancelled,
+	})
+}
@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vending-machine/server/internal/transaction/app"
+	"github.com/vending-machine/server/internal/transaction/domain"
+)
+
+type weightOverrideRequest struct {
+	Decision string                 `json:"decision" binding:"required"` // approved or corrected
+	Reason   string                 `json:"reason" binding:"required"`
+	Items    []correctedItemRequest `json:"items"` // the counted basket, when corrected
+}
+
+type correctedItemRequest struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+// OverrideWeight lets an attendant (X-Actor-ID) resolve a session's weight
+// mismatch by approving or correcting the basket
+func (h *HTTPHandler) OverrideWeight(c *gin.Context) {
+	var req weightOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, _ := actorFromRequest(c)
+	cmd := app.OverrideWeightCommand{
+		SessionID: c.Param("id"),
+		ActorID:   actorID,
+		Decision:  req.Decision,
+		Reason:    req.Reason,
+	}
+	for _, item := range req.Items {
+		cmd.Items = append(cmd.Items, app.CorrectedItemInput{SKU: item.SKU, Quantity: item.Quantity})
+	}
+
+	result, err := h.overrideWeightHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrActorRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "acting user is required"})
+		case errors.Is(err, domain.ErrUnknownWeightDecision),
+			errors.Is(err, domain.ErrWeightOverrideReasonMissing):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		case errors.Is(err, domain.ErrSessionNotActive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "session not active"})
+		case errors.Is(err, domain.ErrNoWeightMismatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSessionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "session_conflict"})
+		case errors.Is(err, domain.ErrFraudSuspected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "fraud_suspected"})
+		case errors.Is(err, app.ErrPaymentProviderUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+		case errors.Is(err, app.ErrCurrencyConversionUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "code": "currency_conversion_unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	view, err := h.queryService.FindByID(c.Request.Context(), result.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(c.Request.Context(), view, preferredLanguages(c)))
+}
@@ -61,12 +61,16 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	// Common steps
 	ctx.Step(`^the API server is running$`, theAPIServerIsRunning)
 	ctx.Step(`^the database is clean$`, theDatabaseIsClean)
+	ctx.Step(`^(\d+) seconds? pass(?:es)?$`, secondsPass)
 	ctx.Step(`^I send a (GET|POST|PUT|DELETE) request to "([^"]*)"$`, iSendRequestTo)
+	ctx.Step(`^I send a (GET|POST|PUT|DELETE) request to "([^"]*)" tagged for region "([^"]*)"$`, iSendRequestToTaggedForRegion)
 	ctx.Step(`^the response status should be (\d+)$`, theResponseStatusShouldBe)
 	ctx.Step(`^the response should contain field "([^"]*)"$`, theResponseShouldContainField)
 	ctx.Step(`^the response should contain field "([^"]*)" with value "([^"]*)"$`, theResponseShouldContainFieldWithValue)
+	ctx.Step(`^the response should not contain field "([^"]*)"$`, theResponseShouldNotContainField)
 	ctx.Step(`^the response field "([^"]*)" should be "([^"]*)"$`, theResponseFieldShouldBe)
 	ctx.Step(`^the response should contain error "([^"]*)"$`, theResponseShouldContainError)
+	ctx.Step(`^the response header "([^"]*)" should be "([^"]*)"$`, theResponseHeaderShouldBe)
 
 	// Catalog steps
 	ctx.Step(`^I create a SKU with the following details:$`, iCreateSKUWithDetails)
@@ -75,23 +79,267 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the following SKUs exist:$`, theFollowingSKUsExist)
 	ctx.Step(`^the response should contain (\d+) SKUs$`, theResponseShouldContainSKUs)
 	ctx.Step(`^each SKU should have fields "([^"]*)"$`, eachSKUShouldHaveFields)
+	ctx.Step(`^I create the SKU "([^"]*)" with the attributes:$`, iCreateTheSKUWithTheAttributes)
+	ctx.Step(`^I create the SKU "([^"]*)" with the product info:$`, iCreateTheSKUWithTheProductInfo)
+	ctx.Step(`^I create the SKU "([^"]*)" with the translations:$`, iCreateTheSKUWithTheTranslations)
+	ctx.Step(`^I create the SKU "([^"]*)" with a shelf life of (-?\d+) days$`, iCreateTheSKUWithAShelfLife)
+	ctx.Step(`^I create the SKU "([^"]*)" with the restocking info:$`, iCreateTheSKUWithTheRestockingInfo)
+	ctx.Step(`^the product-information system pushes:$`, theProductInformationSystemPushes)
+	ctx.Step(`^the product-information system pushes with the wrong secret:$`, theProductInformationSystemPushesWithTheWrongSecret)
+	ctx.Step(`^the product-information system pushes a delivery signed (\d+) minutes ago:$`, theProductInformationSystemPushesADeliverySignedMinutesAgo)
+	ctx.Step(`^staff "([^"]*)" changes the restocking info of the SKU "([^"]*)" to:$`, staffChangesTheRestockingInfoOfTheSKU)
+	ctx.Step(`^I fetch the SKU "([^"]*)"$`, iFetchTheSKU)
+	ctx.Step(`^I upload the training image "([^"]*)" of the SKU "([^"]*)"$`, iUploadTheTrainingImageOfTheSKU)
+	ctx.Step(`^I upload the training image "([^"]*)" of the SKU "([^"]*)" with the bounding box "([^"]*)"$`, iUploadTheTrainingImageOfTheSKUWithTheBoundingBox)
+	ctx.Step(`^I upload a text file as training image of the SKU "([^"]*)"$`, iUploadATextFileAsTrainingImageOfTheSKU)
+	ctx.Step(`^I list the training images of the SKU "([^"]*)"$`, iListTheTrainingImagesOfTheSKU)
+	ctx.Step(`^I fetch the training image "([^"]*)" of the SKU "([^"]*)"$`, iFetchTheTrainingImageOfTheSKU)
+	ctx.Step(`^I delete the training image "([^"]*)" of the SKU "([^"]*)"$`, iDeleteTheTrainingImageOfTheSKU)
+	ctx.Step(`^I export the training dataset$`, iExportTheTrainingDataset)
+	ctx.Step(`^I trigger a class mapping sync$`, iTriggerAClassMappingSync)
+	ctx.Step(`^I map the SKU "([^"]*)" to class (\d+)$`, iMapTheSKUToClass)
+	ctx.Step(`^the SKU "([^"]*)" is mapped to class (\d+)$`, theSKUIsMappedToClass)
+	ctx.Step(`^I map the SKU "([^"]*)" to the next free class$`, iMapTheSKUToTheNextFreeClass)
+	ctx.Step(`^I point class (\d+) at the SKU "([^"]*)"$`, iPointClassAtTheSKU)
+	ctx.Step(`^a subscriber has read the "([^"]*)" topic to its end$`, aSubscriberHasReadTheTopicToItsEnd)
+	ctx.Step(`^the subscriber reads the "([^"]*)" topic$`, theSubscriberReadsTheTopic)
+	ctx.Step(`^I record the weight samples "([^"]*)" for the SKU "([^"]*)"$`, iRecordTheWeightSamplesForTheSKU)
+	ctx.Step(`^I list the (active )?SKUs with attribute "([^"]*)" set to "([^"]*)"$`, iListTheSKUsWithAttribute)
+	ctx.Step(`^I list the (active )?SKUs sorted by "([^"]*)" in "([^"]*)" order$`, iListTheSKUsSortedBy)
+	ctx.Step(`^the SKUs should be listed in the order "([^"]*)"$`, theSKUsShouldBeListedInTheOrder)
+	ctx.Step(`^I create the bundle "([^"]*)" priced at (\d+) cents with:$`, iCreateTheBundlePricedAtWith)
+	ctx.Step(`^a bundle "([^"]*)" priced at (\d+) cents exists with:$`, aBundlePricedAtExistsWith)
+	ctx.Step(`^I (delete|restore|activate|deactivate|publish|retire) the SKU "([^"]*)"$`, iChangeTheSKU)
+	ctx.Step(`^"([^"]*)" changes the price of the SKU "([^"]*)" to (\d+)$`, staffChangesThePriceOfTheSKU)
+	ctx.Step(`^the price of the SKU "([^"]*)" is changed to (\d+) anonymously$`, theSKUPriceIsChangedAnonymously)
+	ctx.Step(`^I request the price history of the SKU "([^"]*)"$`, iRequestThePriceHistoryOfTheSKU)
+	ctx.Step(`^I create the category "([^"]*)"(?: under "([^"]*)")?$`, iCreateTheCategory)
+	ctx.Step(`^a category "([^"]*)" exists(?: under "([^"]*)")?$`, aCategoryExists)
+	ctx.Step(`^the taxed category "([^"]*)" exists$`, theTaxedCategoryExists)
+	ctx.Step(`^I move the category "([^"]*)" under "([^"]*)"$`, iMoveTheCategoryUnder)
+	ctx.Step(`^I delete the category "([^"]*)"$`, iDeleteTheCategory)
+	ctx.Step(`^I assign the SKU "([^"]*)" to the category "([^"]*)"$`, iAssignTheSKUToCategory)
+	ctx.Step(`^I list the (active )?SKUs in the category "([^"]*)"$`, iListTheSKUsInCategory)
+	ctx.Step(`^I import SKUs from the CSV:$`, iImportSKUsFromTheCSV)
+	ctx.Step(`^no SKU with code "([^"]*)" should exist$`, noSKUWithCodeShouldExist)
+	ctx.Step(`^the export should contain the SKU "([^"]*)"$`, theExportShouldContainTheSKU)
 
 	// Device steps
 	ctx.Step(`^I register a device with the following details:$`, iRegisterDeviceWithDetails)
 	ctx.Step(`^a device exists with machine ID "([^"]*)"$`, aDeviceExistsWithMachineID)
+	ctx.Step(`^I submit a shelf snapshot for device "([^"]*)"$`, iSubmitShelfSnapshotForDevice)
+	ctx.Step(`^device "([^"]*)" reports a cabinet temperature of ([\d.]+) degrees (\d+) minutes ago$`, deviceReportsCabinetTemperature)
+	ctx.Step(`^operator "([^"]*)" clears device "([^"]*)"$`, operatorClearsDevice)
+	ctx.Step(`^operator "([^"]*)" decommissions device "([^"]*)"$`, operatorDecommissionsDevice)
+	ctx.Step(`^operator "([^"]*)" re-enrolls device "([^"]*)" with an override$`, operatorReEnrollsDeviceWithAnOverride)
+	ctx.Step(`^operator "([^"]*)" creates an enrollment token for "([^"]*)"$`, operatorCreatesAnEnrollmentTokenFor)
+	ctx.Step(`^device "([^"]*)" enrolls with its token$`, deviceEnrollsWithItsToken)
+	ctx.Step(`^device "([^"]*)" enrolls with the token for "([^"]*)"$`, deviceEnrollsWithTheTokenFor)
+	ctx.Step(`^device "([^"]*)" reports the door (open|closed)$`, deviceReportsTheDoor)
+	ctx.Step(`^device "([^"]*)" sends a heartbeat with firmware "([^"]*)" and app "([^"]*)"$`, deviceSendsAHeartbeatWithFirmwareAndApp)
+	ctx.Step(`^device "([^"]*)" sends a heartbeat with the key of device "([^"]*)"$`, deviceSendsAHeartbeatWithTheKeyOf)
+	ctx.Step(`^device "([^"]*)" requests a start token$`, deviceRequestsAStartToken)
+	ctx.Step(`^device "([^"]*)" requests a start token with the key of device "([^"]*)"$`, deviceRequestsAStartTokenWithTheKeyOf)
+	ctx.Step(`^the device "([^"]*)" should be listed as (stale|alive)$`, theDeviceShouldBeListedAs)
+	ctx.Step(`^I assign the following planogram to device "([^"]*)":$`, iAssignPlanogramToDevice)
+	ctx.Step(`^field staff "([^"]*)" submits a restock snapshot for device "([^"]*)"$`, fieldStaffSubmitsRestockSnapshot)
+	ctx.Step(`^I set the following sales hours for device "([^"]*)" in timezone "([^"]*)":$`, iSetSalesHoursForDevice)
+	ctx.Step(`^field staff "([^"]*)" restocks device "([^"]*)" with the following batches:$`, fieldStaffRestocksDeviceWithBatches)
+	ctx.Step(`^field staff "([^"]*)" writes off the batch of "([^"]*)" on device "([^"]*)"$`, fieldStaffWritesOffBatchOnDevice)
+	ctx.Step(`^field staff "([^"]*)" restocks the inventory of device "([^"]*)" with:$`, fieldStaffRestocksInventoryOfDevice)
+	ctx.Step(`^the inventory of device "([^"]*)" should have (\d+) units of "([^"]*)"$`, theInventoryOfDeviceShouldHave)
+	ctx.Step(`^I request the low inventory of device "([^"]*)"$`, iRequestTheLowInventoryOfDevice)
+	ctx.Step(`^I assign the SKUs "([^"]*)" to device "([^"]*)"$`, iAssignTheSKUsToDevice)
+	ctx.Step(`^I unassign the SKU "([^"]*)" from device "([^"]*)"$`, iUnassignTheSKUFromDevice)
+	ctx.Step(`^I request the SKUs of device "([^"]*)"$`, iRequestTheSKUsOfDevice)
+	ctx.Step(`^the device has synced its SKUs$`, theDeviceHasSyncedItsSKUs)
+	ctx.Step(`^the device syncs the SKU changes since its last sync$`, theDeviceSyncsTheSKUChangesSinceItsLastSync)
+	ctx.Step(`^the device syncs its SKUs in "([^"]*)"$`, theDeviceSyncsItsSKUsIn)
+	ctx.Step(`^the SKU sync should map class (\d+) to "([^"]*)"$`, theSKUSyncShouldMapClassTo)
+	ctx.Step(`^I create the device group "([^"]*)"$`, iCreateTheDeviceGroup)
+	ctx.Step(`^I add the devices "([^"]*)" to the group "([^"]*)"$`, iAddTheDevicesToTheGroup)
+	ctx.Step(`^I remove the device "([^"]*)" from the group "([^"]*)"$`, iRemoveTheDeviceFromTheGroup)
+	ctx.Step(`^I request the device group "([^"]*)"$`, iRequestTheDeviceGroup)
+	ctx.Step(`^I set the following policy for the group "([^"]*)":$`, iSetTheFollowingPolicyForTheGroup)
+	ctx.Step(`^I set the following planogram for the group "([^"]*)":$`, iSetTheFollowingPlanogramForTheGroup)
+	ctx.Step(`^I set the following policy overrides for device "([^"]*)":$`, iSetTheFollowingPolicyOverridesForDevice)
+	ctx.Step(`^I request the policy of device "([^"]*)"$`, iRequestThePolicyOfDevice)
+	ctx.Step(`^operator "([^"]*)" sets the following configuration for device "([^"]*)":$`, operatorSetsTheFollowingConfigurationForDevice)
+	ctx.Step(`^device "([^"]*)" pulls its configuration$`, devicePullsItsConfiguration)
+	ctx.Step(`^device "([^"]*)" uploads a camera calibration with homography "([^"]*)" and markers:$`, deviceUploadsACameraCalibrationWithMarkers)
+	ctx.Step(`^device "([^"]*)" uploads a camera calibration with homography "([^"]*)" captured at "([^"]*)"$`, deviceUploadsACameraCalibrationCapturedAt)
+	ctx.Step(`^device "([^"]*)" pulls its camera calibration$`, devicePullsItsCameraCalibration)
+	ctx.Step(`^I request the camera calibration of device "([^"]*)"$`, iRequestTheCameraCalibrationOfDevice)
+	ctx.Step(`^device "([^"]*)" calibrates its scale with zero reading "([^"]*)" and readings:$`, deviceCalibratesItsScale)
+	ctx.Step(`^device "([^"]*)" calibrated its scale (\d+) days ago with zero reading "([^"]*)" and readings:$`, deviceCalibratedItsScaleDaysAgo)
+	ctx.Step(`^I request the scale calibrations of device "([^"]*)"$`, iRequestTheScaleCalibrationsOfDevice)
+	ctx.Step(`^device "([^"]*)" reports applying configuration version (\d+) with:$`, deviceReportsApplyingConfigurationVersion)
+	ctx.Step(`^I request the configuration of device "([^"]*)"$`, iRequestTheConfigurationOfDevice)
+	ctx.Step(`^operator "([^"]*)" uploads the (firmware|app) release "([^"]*)"$`, operatorUploadsTheRelease)
+	ctx.Step(`^operator "([^"]*)" rolls out the release "([^"]*)" to the groups "([^"]*)"$`, operatorRollsOutTheRelease)
+	ctx.Step(`^operator "([^"]*)" rolls out the release "([^"]*)" to the groups "([^"]*)" in stages "([^"]*)"$`, operatorRollsOutTheReleaseInStages)
+	ctx.Step(`^operator "([^"]*)" advances the rollout of release "([^"]*)"$`, operatorAdvancesTheRolloutOfRelease)
+	ctx.Step(`^operator "([^"]*)" aborts the rollout of release "([^"]*)" because "([^"]*)"$`, operatorAbortsTheRolloutOfRelease)
+	ctx.Step(`^I request the rollout of release "([^"]*)"$`, iRequestTheRolloutOfRelease)
+	ctx.Step(`^device "([^"]*)" reports the update of release "([^"]*)" as "([^"]*)"$`, deviceReportsTheUpdateOfReleaseAs)
+	ctx.Step(`^device "([^"]*)" downloads the release "([^"]*)"$`, deviceDownloadsTheRelease)
+	ctx.Step(`^the heartbeat should offer (\d+) updates?$`, theHeartbeatShouldOfferUpdates)
+	ctx.Step(`^device "([^"]*)" sends a heartbeat running model "([^"]*)"$`, deviceSendsAHeartbeatRunningModel)
+	ctx.Step(`^I request the fleet health$`, iRequestTheFleetHealth)
+	ctx.Step(`^I request the fleet health counting devices offline after (\d+) minutes?$`, iRequestTheFleetHealthCountingDevicesOfflineAfter)
+	ctx.Step(`^the fleet health should (not )?list device "([^"]*)" as (offline|running a stale model)$`, theFleetHealthShouldListDeviceAs)
+	ctx.Step(`^the offline sweep runs with a window of (\d+) seconds?$`, theOfflineSweepRunsWithAWindowOf)
+	ctx.Step(`^the offline sweep should (not )?list device "([^"]*)"$`, theOfflineSweepShouldListDevice)
+	ctx.Step(`^the fleet health should count at least (\d+) open sessions? with a weight mismatch$`, theFleetHealthShouldCountAtLeastOpenSessionsWithAWeightMismatch)
+	ctx.Step(`^I request the QR code of device "([^"]*)"$`, iRequestTheQRCodeOfDevice)
+	ctx.Step(`^I request the QR code of device "([^"]*)" at (\d+) pixels$`, iRequestTheQRCodeOfDeviceAtPixels)
+	ctx.Step(`^the response should be a PNG image at most (\d+) pixels wide$`, theResponseShouldBeAPNGImageAtMostPixelsWide)
+	ctx.Step(`^the QR code should hold the deep link of device "([^"]*)"$`, theQRCodeShouldHoldTheDeepLinkOfDevice)
+	ctx.Step(`^technician "([^"]*)" puts device "([^"]*)" into maintenance because "([^"]*)"$`, technicianPutsDeviceIntoMaintenance)
+	ctx.Step(`^technician "([^"]*)" takes device "([^"]*)" out of maintenance$`, technicianTakesDeviceOutOfMaintenance)
+	ctx.Step(`^device "([^"]*)" uploads the following events:$`, deviceUploadsTheFollowingEvents)
+	ctx.Step(`^device "([^"]*)" uploads events with the key "([^"]*)"$`, deviceUploadsEventsWithTheKey)
+	ctx.Step(`^I request the event log of device "([^"]*)" from (\d+) to (\d+) minutes ago$`, iRequestTheEventLogOfDeviceFromToMinutesAgo)
+	ctx.Step(`^the device event log should be "([^"]*)"$`, theDeviceEventLogShouldBe)
+	ctx.Step(`^operator "([^"]*)" sends a "([^"]*)" command to device "([^"]*)"$`, operatorSendsACommandToDevice)
+	ctx.Step(`^device "([^"]*)" polls for commands$`, devicePollsForCommands)
+	ctx.Step(`^device "([^"]*)" polls for commands with the key "([^"]*)"$`, devicePollsForCommandsWithTheKey)
+	ctx.Step(`^device "([^"]*)" acknowledges the "([^"]*)" command as "([^"]*)"$`, deviceAcknowledgesTheCommandAs)
+	ctx.Step(`^device "([^"]*)" receives the "([^"]*)" command on the command socket$`, deviceReceivesTheCommandOnTheCommandSocket)
+	ctx.Step(`^I request the commands of device "([^"]*)"$`, iRequestTheCommandsOfDevice)
+	ctx.Step(`^the commands should be "([^"]*)"$`, theCommandsShouldBe)
+	ctx.Step(`^operator "([^"]*)" requests diagnostics of device "([^"]*)"$`, operatorRequestsDiagnosticsOfDevice)
+	ctx.Step(`^device "([^"]*)" uploads its diagnostics:$`, deviceUploadsItsDiagnostics)
+	ctx.Step(`^I request the diagnostics of device "([^"]*)"$`, iRequestTheDiagnosticsOfDevice)
+	ctx.Step(`^I label device "([^"]*)" with tags "([^"]*)"$`, iLabelDeviceWithTags)
+	ctx.Step(`^I label device "([^"]*)" with tags "([^"]*)" and metadata:$`, iLabelDeviceWithTagsAndMetadata)
+	ctx.Step(`^operator "([^"]*)" starts restocking device "([^"]*)"$`, operatorStartsRestockingDevice)
+	ctx.Step(`^the operator loads the following units in the restock session:$`, operatorLoadsTheFollowingUnitsInTheRestockSession)
+	ctx.Step(`^operator "([^"]*)" (completes|cancels) the restock session$`, operatorClosesTheRestockSession)
+	ctx.Step(`^I request the restock report$`, iRequestTheRestockReport)
+	ctx.Step(`^the restock report should be "([^"]*)"$`, theRestockReportShouldBe)
+
+	// Service status steps
+	ctx.Step(`^I request the status again with the last ETag$`, iRequestTheStatusAgainWithTheLastETag)
+	ctx.Step(`^operator "([^"]*)" raises an? "([^"]*)" incident on "([^"]*)" saying "([^"]*)"$`, operatorRaisesIncident)
+	ctx.Step(`^operator "([^"]*)" resolves the incidents on "([^"]*)"$`, operatorResolvesIncidentsOn)
 
 	// Transaction steps
 	ctx.Step(`^I start a session on device "([^"]*)"$`, iStartSessionOnDevice)
+	ctx.Step(`^I open the live stream of the current session$`, iOpenTheLiveStreamOfTheCurrentSession)
+	ctx.Step(`^I open the live stream of the current session with token "([^"]*)"$`, iOpenTheLiveStreamOfTheCurrentSessionWithToken)
+	ctx.Step(`^the stream should end with a "([^"]*)" event$`, theStreamShouldEndWithEvent)
+	ctx.Step(`^I open the event stream of the current session$`, iOpenTheEventStreamOfTheCurrentSession)
+	ctx.Step(`^I open the event stream of the current session with token "([^"]*)"$`, iOpenTheEventStreamOfTheCurrentSessionWithToken)
+	ctx.Step(`^the stream should contain an? "([^"]*)" event$`, theStreamShouldContainEvent)
 	ctx.Step(`^an active session exists on device "([^"]*)"$`, anActiveSessionExistsOnDevice)
 	ctx.Step(`^an active session with items exists on device "([^"]*)"$`, anActiveSessionWithItemsExistsOnDevice)
 	ctx.Step(`^a completed session exists on device "([^"]*)"$`, aCompletedSessionExistsOnDevice)
 	ctx.Step(`^I submit the following detections to the session:$`, iSubmitDetectionsToSession)
+	ctx.Step(`^I submit the following detections weighing ([\d.]+) grams to the session:$`, iSubmitDetectionsWeighingToSession)
+	ctx.Step(`^I submit the following detections from model "([^"]*)" to the session:$`, iSubmitDetectionsFromModelToSession)
+	ctx.Step(`^I submit the following detections with (idempotency key|detection ID) "([^"]*)" to the session:$`, iSubmitDetectionsWithIdempotencyKeyToSession)
+	ctx.Step(`^I submit frame (\d+) with the following detections to the session:$`, iSubmitFrameWithDetections)
+	ctx.Step(`^I submit frame (\d+) with the following changes to the session:$`, iSubmitFrameWithChanges)
 	ctx.Step(`^I submit detections to session "([^"]*)"$`, iSubmitDetectionsToSessionID)
 	ctx.Step(`^I confirm the session with payment reference "([^"]*)"$`, iConfirmSessionWithPaymentRef)
+	ctx.Step(`^I retry the confirmation with payment reference "([^"]*)"$`, iRetryTheConfirmationWithPaymentReference)
+	ctx.Step(`^the response should repeat the original confirmation$`, theResponseShouldRepeatTheOriginalConfirmation)
+	ctx.Step(`^the response should repeat the original fiscal receipt$`, theResponseShouldRepeatTheOriginalFiscalReceipt)
+	ctx.Step(`^device "([^"]*)" is in a country that requires fiscal receipts$`, deviceIsInACountryThatRequiresFiscalReceipts)
 	ctx.Step(`^I cancel the session with reason "([^"]*)"$`, iCancelSessionWithReason)
+	ctx.Step(`^the current session should become "([^"]*)"$`, theCurrentSessionShouldBecome)
+	ctx.Step(`^I pay for the session with wallet "([^"]*)" and token "([^"]*)"$`, iPayForTheSessionWithWallet)
+	ctx.Step(`^I pay for the session by card$`, iPayForTheSessionByCard)
+	ctx.Step(`^I confirm the session$`, iConfirmTheSession)
+	ctx.Step(`^the payment provider is available$`, thePaymentProviderIsAvailable)
+	ctx.Step(`^the response should hand over the session's payment intent$`, theResponseShouldHandOverTheSessionPaymentIntent)
+	ctx.Step(`^the session's payment intent should be for (\d+) cents$`, theSessionPaymentIntentShouldBeForCents)
+	ctx.Step(`^the customer pays the session's payment intent by card$`, theCustomerPaysTheSessionPaymentIntentByCard)
+	ctx.Step(`^the customer pays (\d+) cents for the session by card$`, theCustomerPaysCentsForTheSessionByCard)
+	ctx.Step(`^the session's card payment is (still processing|captured|declined)$`, theSessionCardPaymentIs)
+	ctx.Step(`^the payment provider reports that the session's payment (succeeded|failed)$`, thePaymentProviderReportsThatTheSessionPayment)
+	ctx.Step(`^the payment provider reports that the session's payment succeeded with the wrong secret$`, thePaymentProviderReportsThatTheSessionPaymentSucceededWithTheWrongSecret)
+	ctx.Step(`^the payment provider sends a "([^"]*)" event for the session$`, thePaymentProviderSendsAnEventForTheSession)
+	ctx.Step(`^another request saves the session first$`, anotherRequestSavesTheSessionFirst)
+	ctx.Step(`^I request an Apple Pay merchant session from "([^"]*)"$`, iRequestAnApplePayMerchantSessionFrom)
+	ctx.Step(`^I request a refund of (\d+) cents for the session$`, iRequestARefundOfCentsForTheSession)
+	ctx.Step(`^support agent "([^"]*)" requests a refund of (\d+) cents for the session$`, supportAgentRequestsARefundOfCentsForTheSession)
+	ctx.Step(`^staff member "([^"]*)" with roles "([^"]*)" requests a refund of (\d+) cents for the session$`, staffMemberWithRolesRequestsARefundOfCentsForTheSession)
+	ctx.Step(`^staff member "([^"]*)" with roles "([^"]*)" (approves|rejects) the refund$`, staffMemberWithRolesDecidesTheRefund)
+	ctx.Step(`^the pending refunds should (not )?include the refund$`, thePendingRefundsShouldIncludeTheRefund)
+	ctx.Step(`^support agent "([^"]*)" notes "([^"]*)" on the session$`, supportAgentNotesOnTheSession)
+	ctx.Step(`^support agent "([^"]*)" notes "([^"]*)" on session "([^"]*)"$`, supportAgentNotesOnSession)
+	ctx.Step(`^I request the sales report grouped by "([^"]*)"$`, iRequestTheSalesReportGroupedBy)
+	ctx.Step(`^the sales report should have a line for "([^"]*)"$`, theSalesReportShouldHaveALineFor)
+	ctx.Step(`^the sales report line for "([^"]*)" should show (\d+) transactions, (\d+) units and (\d+) cents of revenue$`, theSalesReportLineForShouldShow)
+	ctx.Step(`^the sales report line for "([^"]*)" should average (\d+) cents and ([\d.]+) units per basket$`, theSalesReportLineForShouldAveragePerBasket)
+	ctx.Step(`^staff member "([^"]*)" disputes (\d+) cents of the session because "([^"]*)"$`, staffMemberDisputesCentsOfTheSessionBecause)
+	ctx.Step(`^staff member "([^"]*)" notes "([^"]*)" on the dispute$`, staffMemberNotesOnTheDispute)
+	ctx.Step(`^staff member "([^"]*)" attaches the session's (detections|image) to the dispute$`, staffMemberAttachesTheSessionsEvidenceToTheDispute)
+	ctx.Step(`^staff member "([^"]*)" with roles "([^"]*)" resolves the dispute as "([^"]*)"$`, staffMemberWithRolesResolvesTheDisputeAs)
+	ctx.Step(`^I fetch the dispute image$`, iFetchTheDisputeImage)
+	ctx.Step(`^I list the "([^"]*)" disputes opened since the dispute$`, iListTheDisputesOpenedSinceTheDispute)
+	ctx.Step(`^I request the dispute report since the dispute$`, iRequestTheDisputeReportSinceTheDispute)
+	ctx.Step(`^the dispute list should (not )?include the dispute$`, theDisputeListShouldIncludeTheDispute)
+	ctx.Step(`^the dispute report should count (\d+) "([^"]*)" disputes? of (\d+) cents$`, theDisputeReportShouldCountDisputesOfCents)
+	ctx.Step(`^I request recommendations for the session$`, iRequestRecommendationsForTheSession)
+	ctx.Step(`^I fetch the current session$`, iFetchTheCurrentSession)
+	ctx.Step(`^I fetch the current session in "([^"]*)"$`, iFetchTheCurrentSessionIn)
+	ctx.Step(`^device "([^"]*)" syncs the following offline entries for the current session:$`, deviceSyncsOfflineEntriesForTheCurrentSession)
+	ctx.Step(`^the device resends the last offline batch$`, theDeviceResendsTheLastOfflineBatch)
+	ctx.Step(`^the sync result for "([^"]*)" should be "([^"]*)"$`, theSyncResultShouldBe)
+	ctx.Step(`^the sync result for "([^"]*)" should be "([^"]*)" with error "([^"]*)"$`, theSyncResultShouldBeWithError)
+	ctx.Step(`^I send a detection with an image for the current session on device "([^"]*)"$`, iSendADetectionWithAnImageForTheCurrentSessionOnDevice)
+	ctx.Step(`^I upload (the|an empty) image of the current session for cloud verification$`, iUploadTheImageOfTheCurrentSessionForCloudVerification)
+	ctx.Step(`^I upload an image of session "([^"]*)" for cloud verification$`, iUploadAnImageOfSessionForCloudVerification)
+	ctx.Step(`^the cloud model finds the following on device "([^"]*)":$`, theCloudModelFindsTheFollowingOnDevice)
+	ctx.Step(`^I list the detections of the current session$`, iListTheDetectionsOfTheCurrentSession)
+	ctx.Step(`^attendant "([^"]*)" resolves the weight of the current session as "([^"]*)" because "([^"]*)"$`, attendantResolvesTheWeightOfTheCurrentSessionAsBecause)
+	ctx.Step(`^attendant "([^"]*)" corrects the current session to (\d+) of "([^"]*)" because "([^"]*)"$`, attendantCorrectsTheCurrentSessionToOfBecause)
+	ctx.Step(`^attendant "([^"]*)" accepts the "([^"]*)" items of the current session$`, attendantAcceptsTheItemsOfTheCurrentSession)
+	ctx.Step(`^attendant "([^"]*)" replaces the "([^"]*)" items of the current session with "([^"]*)"$`, attendantReplacesTheItemsOfTheCurrentSessionWith)
+	ctx.Step(`^I list the sessions awaiting review$`, iListTheSessionsAwaitingReview)
+	ctx.Step(`^the review queue should (not )?list the current session$`, theReviewQueueShouldListTheCurrentSession)
+	ctx.Step(`^device "([^"]*)" signals "([^"]*)" for the current session$`, deviceSignalsForTheCurrentSession)
+	ctx.Step(`^the walkaway sweep runs$`, theWalkawaySweepRuns)
+	ctx.Step(`^the walkaway sweep runs with a grace of (\d+) seconds$`, theWalkawaySweepRunsWithAGraceOf)
+	ctx.Step(`^the walkaway sweep should (not )?have cancelled the current session$`, theWalkawaySweepShouldHaveCancelledTheCurrentSession)
+	ctx.Step(`^the session archival runs$`, theSessionArchivalRuns)
+	ctx.Step(`^the session archival runs for sessions older than (-?\d+) days$`, theSessionArchivalRunsForSessionsOlderThan)
+	ctx.Step(`^I apply the coupon "([^"]*)" to the session$`, iApplyTheCouponToTheSession)
+	ctx.Step(`^I extend the session$`, iExtendTheSession)
+	ctx.Step(`^I extend the session by (\d+) minutes$`, iExtendTheSessionByMinutes)
+	ctx.Step(`^the session should expire (\d+) minutes after it started$`, theSessionShouldExpireMinutesAfterItStarted)
+	ctx.Step(`^I (run|rerun) the session reconciliation for "([^"]*)"$`, iRunTheSessionReconciliationFor)
+	ctx.Step(`^I list the sessions of device "([^"]*)"$`, iListTheSessionsOfDevice)
+	ctx.Step(`^I list the sessions of device "([^"]*)" (\d+) at a time$`, iListTheSessionsOfDeviceAtATime)
+	ctx.Step(`^I request the next page of sessions$`, iRequestTheNextPageOfSessions)
+	ctx.Step(`^the session history should list (\d+) sessions? "([^"]*)"$`, theSessionHistoryShouldList)
+	ctx.Step(`^user "([^"]*)" completed a purchase on device "([^"]*)"$`, userCompletedAPurchaseOnDevice)
+	ctx.Step(`^user "([^"]*)" starts a session on device "([^"]*)"$`, userStartsASessionOnDevice)
+	ctx.Step(`^user "([^"]*)" resumes the active session on device "([^"]*)"$`, userResumesTheActiveSessionOnDevice)
+	ctx.Step(`^the resumed session should be the current session$`, theResumedSessionShouldBeTheCurrentSession)
+	ctx.Step(`^I request the purchase history of user "([^"]*)"$`, iRequestThePurchaseHistoryOfUser)
+	ctx.Step(`^I request the purchase history of user "([^"]*)" (\d+) at a time$`, iRequestThePurchaseHistoryOfUserAtATime)
 	ctx.Step(`^the response should contain (\d+) items$`, theResponseShouldContainItems)
 	ctx.Step(`^the total should be (\d+) cents$`, theTotalShouldBeCents)
 	ctx.Step(`^the response should contain items$`, theResponseShouldContainItems)
+
+	// Pricing steps
+	ctx.Step(`^I create a price experiment for SKU "([^"]*)" with variant price (\d+)$`, iCreateAPriceExperimentForSKUWithVariantPrice)
+	ctx.Step(`^I create a price experiment with a single variant for SKU "([^"]*)"$`, iCreateAPriceExperimentWithASingleVariantForSKU)
+	ctx.Step(`^a coupon "([^"]*)" takes (\d+) percent off$`, aCouponTakesPercentOff)
+	ctx.Step(`^a single-use coupon "([^"]*)" takes (\d+) percent off$`, aSingleUseCouponTakesPercentOff)
+	ctx.Step(`^a coupon "([^"]*)" takes (\d+) cents off baskets of at least (\d+) cents$`, aCouponTakesCentsOffBasketsOfAtLeastCents)
+	ctx.Step(`^I create a coupon "([^"]*)" taking (\d+) percent and (\d+) cents off$`, iCreateACouponTakingPercentAndCentsOff)
+	ctx.Step(`^I create a coupon "([^"]*)" that expired yesterday$`, iCreateACouponThatExpiredYesterday)
+	ctx.Step(`^I disable the coupon "([^"]*)"$`, iDisableTheCoupon)
+	ctx.Step(`^I fetch the coupon "([^"]*)"$`, iFetchTheCoupon)
+
+	// Invoicing steps
+	ctx.Step(`^I generate an invoice for customer "([^"]*)" for period "([^"]*)"$`, iGenerateAnInvoiceForCustomerForPeriod)
+	ctx.Step(`^I generate an invoice for customer "([^"]*)" for the current month$`, iGenerateAnInvoiceForCustomerForTheCurrentMonth)
 }
 
 func beforeScenario(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
@@ -1,11 +1,24 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cucumber/godog"
+
+	"github.com/vending-machine/server/internal/platform/webhook"
+	"github.com/vending-machine/server/test/support"
 )
 
 // Catalog-specific step definitions
@@ -28,6 +41,9 @@ func iCreateSKUWithDetails(table *godog.Table) error {
 	if tolerance := getCellValue(table, row, "weight_tolerance"); tolerance != "" {
 		sku["weight_tolerance"] = parseCellFloat(table, row, "weight_tolerance")
 	}
+	if lifecycle := getCellValue(table, row, "lifecycle"); lifecycle != "" {
+		sku["lifecycle"] = lifecycle
+	}
 
 	err := testContext.SendRequest("POST", "/api/v1/skus", sku)
 	if err != nil {
@@ -123,6 +139,12 @@ func theFollowingSKUsExist(table *godog.Table) error {
 		if tolerance := getCellValue(table, row, "weight_tolerance"); tolerance != "" {
 			sku["weight_tolerance"] = parseCellFloat(table, row, "weight_tolerance")
 		}
+		if currency := getCellValue(table, row, "currency"); currency != "" {
+			sku["currency"] = currency
+		}
+		if lifecycle := getCellValue(table, row, "lifecycle"); lifecycle != "" {
+			sku["lifecycle"] = lifecycle
+		}
 
 		err := testContext.SendRequest("POST", "/api/v1/skus", sku)
 		if err != nil {
@@ -145,6 +167,361 @@ func theFollowingSKUsExist(table *godog.Table) error {
 	return nil
 }
 
+func iCreateTheSKUWithTheAttributes(code string, attributes *godog.DocString) error {
+	var attrs map[string]interface{}
+	if err := json.Unmarshal([]byte(attributes.Content), &attrs); err != nil {
+		return fmt.Errorf("attributes are not a JSON object: %w", err)
+	}
+	return createSKUWith(code, "attributes", attrs)
+}
+
+func iCreateTheSKUWithTheProductInfo(code string, productInfo *godog.DocString) error {
+	var info map[string]interface{}
+	if err := json.Unmarshal([]byte(productInfo.Content), &info); err != nil {
+		return fmt.Errorf("product info is not a JSON object: %w", err)
+	}
+	return createSKUWith(code, "product_info", info)
+}
+
+func iCreateTheSKUWithTheTranslations(code string, table *godog.Table) error {
+	translations := map[string]interface{}{}
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue // Skip header
+		}
+		translations[getCellValue(table, row, "language")] = map[string]interface{}{
+			"name":        getCellValue(table, row, "name"),
+			"description": getCellValue(table, row, "description"),
+		}
+	}
+	return createSKUWith(code, "translations", translations)
+}
+
+func iCreateTheSKUWithAShelfLife(code string, days int) error {
+	return createSKUWith(code, "shelf_life_days", days)
+}
+
+func iCreateTheSKUWithTheRestockingInfo(code string, restocking *godog.DocString) error {
+	var info map[string]interface{}
+	if err := json.Unmarshal([]byte(restocking.Content), &info); err != nil {
+		return fmt.Errorf("restocking info is not a JSON object: %w", err)
+	}
+	return createSKUWith(code, "restocking", info)
+}
+
+func staffChangesTheRestockingInfoOfTheSKU(staff, code string, restocking *godog.DocString) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal([]byte(restocking.Content), &info); err != nil {
+		return fmt.Errorf("restocking info is not a JSON object: %w", err)
+	}
+
+	// The rest of the SKU is sent as createSKUWith created it
+	body := map[string]interface{}{
+		"name":         "Product " + code,
+		"price_cents":  150,
+		"weight_grams": 100,
+		"restocking":   info,
+	}
+	return testContext.SendRequestWithHeaders("PUT", "/api/v1/skus/"+id, body, map[string]string{
+		"X-Actor-ID": staff,
+	})
+}
+
+// createSKUWith creates a plain SKU with one extra field set
+func createSKUWith(code, field string, value interface{}) error {
+	sku := map[string]interface{}{
+		"code":         code,
+		"name":         "Product " + code,
+		"price_cents":  150,
+		"weight_grams": 100,
+		field:          value,
+	}
+	if err := testContext.SendRequest("POST", "/api/v1/skus", sku); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["id"].(string); ok {
+			testContext.CreatedSKUs[code] = id
+		}
+	}
+	return nil
+}
+
+func iFetchTheSKU(code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	return testContext.SendRequest("GET", "/api/v1/skus/"+id, nil)
+}
+
+func iRecordTheWeightSamplesForTheSKU(samples, code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+
+	grams := []float64{}
+	for _, part := range strings.Split(samples, ",") {
+		g, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return fmt.Errorf("invalid weight sample %q: %w", part, err)
+		}
+		grams = append(grams, g)
+	}
+	return testContext.SendRequest("POST", "/api/v1/skus/"+id+"/weight-samples", map[string]interface{}{
+		"samples_grams": grams,
+	})
+}
+
+func iListTheSKUsWithAttribute(active, name, value string) error {
+	path := "/api/v1/skus"
+	if active != "" {
+		path += "/active"
+	}
+	query := url.Values{}
+	query.Set("attr["+name+"]", value)
+	return testContext.SendRequest("GET", path+"?"+query.Encode(), nil)
+}
+
+func iListTheSKUsSortedBy(active, field, order string) error {
+	path := "/api/v1/skus"
+	if active != "" {
+		path += "/active"
+	}
+	query := url.Values{}
+	query.Set("sort", field)
+	query.Set("order", order)
+	return testContext.SendRequest("GET", path+"?"+query.Encode(), nil)
+}
+
+func theSKUsShouldBeListedInTheOrder(codes string) error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	skus, ok := response["skus"].([]interface{})
+	if !ok {
+		return fmt.Errorf("skus field not found or not an array")
+	}
+
+	var listed []string
+	for _, item := range skus {
+		sku, _ := item.(map[string]interface{})
+		code, _ := sku["code"].(string)
+		listed = append(listed, code)
+	}
+	if got := strings.Join(listed, ", "); got != codes {
+		return fmt.Errorf("expected SKUs in the order %q, got %q", codes, got)
+	}
+	return nil
+}
+
+func iCreateTheBundlePricedAtWith(code string, priceCents int64, table *godog.Table) error {
+	var components []map[string]interface{}
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue // Skip header
+		}
+		sku := getCellValue(table, row, "sku")
+		id, ok := testContext.CreatedSKUs[sku]
+		if !ok {
+			return fmt.Errorf("SKU %s was not created in this scenario", sku)
+		}
+		components = append(components, map[string]interface{}{
+			"sku_id":   id,
+			"quantity": parseCellInt(table, row, "quantity"),
+		})
+	}
+
+	bundle := map[string]interface{}{
+		"code":        code,
+		"name":        "Bundle " + code,
+		"price_cents": priceCents,
+		"components":  components,
+	}
+	if err := testContext.SendRequest("POST", "/api/v1/skus/bundles", bundle); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["id"].(string); ok {
+			testContext.CreatedSKUs[code] = id
+		}
+	}
+	return nil
+}
+
+func aBundlePricedAtExistsWith(code string, priceCents int64, table *godog.Table) error {
+	if err := iCreateTheBundlePricedAtWith(code, priceCents, table); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return fmt.Errorf("failed to create bundle %s: status %d", code, testContext.LastResponse.StatusCode)
+	}
+	return nil
+}
+
+func iChangeTheSKU(action, code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+
+	if action == "delete" {
+		return testContext.SendRequest("DELETE", "/api/v1/skus/"+id, nil)
+	}
+	return testContext.SendRequest("POST", "/api/v1/skus/"+id+"/"+action, nil)
+}
+
+func staffChangesThePriceOfTheSKU(actor, code string, priceCents int64) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+
+	if err := testContext.SendRequest("GET", "/api/v1/skus/"+id, nil); err != nil {
+		return err
+	}
+	current, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+
+	update := map[string]interface{}{
+		"name":             current["name"],
+		"price_cents":      priceCents,
+		"currency":         current["currency"],
+		"weight_grams":     current["weight_grams"],
+		"weight_tolerance": current["weight_tolerance"],
+	}
+	var headers map[string]string
+	if actor != "" {
+		headers = map[string]string{"X-Actor-ID": actor}
+	}
+	return testContext.SendRequestWithHeaders("PUT", "/api/v1/skus/"+id, update, headers)
+}
+
+func theSKUPriceIsChangedAnonymously(code string, priceCents int64) error {
+	return staffChangesThePriceOfTheSKU("", code, priceCents)
+}
+
+func iRequestThePriceHistoryOfTheSKU(code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	return testContext.SendRequest("GET", "/api/v1/skus/"+id+"/price-history", nil)
+}
+
+func iCreateTheCategory(name, parent string) error {
+	category := map[string]interface{}{"name": name}
+	if parent != "" {
+		parentID, ok := testContext.CreatedCategories[parent]
+		if !ok {
+			return fmt.Errorf("category %s was not created in this scenario", parent)
+		}
+		category["parent_id"] = parentID
+	}
+
+	if err := testContext.SendRequest("POST", "/api/v1/categories", category); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["id"].(string); ok {
+			testContext.CreatedCategories[name] = id
+		}
+	}
+
+	return nil
+}
+
+func aCategoryExists(name, parent string) error {
+	if err := iCreateTheCategory(name, parent); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return fmt.Errorf("failed to create category %s: status %d", name, testContext.LastResponse.StatusCode)
+	}
+	return nil
+}
+
+// theTaxedCategoryExists creates the category the test server's tax policy
+// charges 10% on. Its ID is fixed by the policy, so it is written directly.
+func theTaxedCategoryExists(name string) error {
+	_, err := testContext.DBPool.Exec(context.Background(), `
+		INSERT INTO categories (id, name, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, parent_id = NULL`,
+		support.TaxedCategoryID, name)
+	if err != nil {
+		return fmt.Errorf("failed to create taxed category %s: %w", name, err)
+	}
+	testContext.CreatedCategories[name] = support.TaxedCategoryID
+	return nil
+}
+
+func iMoveTheCategoryUnder(name, parent string) error {
+	id, ok := testContext.CreatedCategories[name]
+	if !ok {
+		return fmt.Errorf("category %s was not created in this scenario", name)
+	}
+	parentID, ok := testContext.CreatedCategories[parent]
+	if !ok {
+		return fmt.Errorf("category %s was not created in this scenario", parent)
+	}
+
+	return testContext.SendRequest("PUT", "/api/v1/categories/"+id, map[string]interface{}{
+		"name":      name,
+		"parent_id": parentID,
+	})
+}
+
+func iDeleteTheCategory(name string) error {
+	id, ok := testContext.CreatedCategories[name]
+	if !ok {
+		return fmt.Errorf("category %s was not created in this scenario", name)
+	}
+	return testContext.SendRequest("DELETE", "/api/v1/categories/"+id, nil)
+}
+
+func iAssignTheSKUToCategory(code, name string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	categoryID, ok := testContext.CreatedCategories[name]
+	if !ok {
+		return fmt.Errorf("category %s was not created in this scenario", name)
+	}
+
+	return testContext.SendRequest("PUT", "/api/v1/skus/"+id+"/category", map[string]interface{}{
+		"category_id": categoryID,
+	})
+}
+
+func iListTheSKUsInCategory(active, name string) error {
+	categoryID, ok := testContext.CreatedCategories[name]
+	if !ok {
+		return fmt.Errorf("category %s was not created in this scenario", name)
+	}
+
+	path := "/api/v1/skus"
+	if active != "" {
+		path += "/active"
+	}
+	return testContext.SendRequest("GET", path+"?category_id="+categoryID, nil)
+}
+
 func theResponseShouldContainSKUs(count int) error {
 	response, err := testContext.GetResponseJSON()
 	if err != nil {
@@ -192,6 +569,78 @@ func eachSKUShouldHaveFields(fields string) error {
 	return nil
 }
 
+func iImportSKUsFromTheCSV(doc *godog.DocString) error {
+	if err := testContext.SendFile("POST", "/api/v1/skus/import", "file", "skus.csv", []byte(doc.Content)); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		var response struct {
+			SKUs []struct {
+				ID   string `json:"id"`
+				Code string `json:"code"`
+			} `json:"skus"`
+		}
+		if err := json.Unmarshal(testContext.LastBody, &response); err != nil {
+			return err
+		}
+		for _, s := range response.SKUs {
+			testContext.CreatedSKUs[s.Code] = s.ID
+		}
+	}
+	return nil
+}
+
+func noSKUWithCodeShouldExist(code string) error {
+	if err := testContext.SendRequest("GET", "/api/v1/skus", nil); err != nil {
+		return err
+	}
+	var response struct {
+		SKUs []struct {
+			Code string `json:"code"`
+		} `json:"skus"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &response); err != nil {
+		return err
+	}
+	for _, s := range response.SKUs {
+		if s.Code == code {
+			return fmt.Errorf("SKU %s exists", code)
+		}
+	}
+	return nil
+}
+
+func theExportShouldContainTheSKU(code string) error {
+	var codes []string
+	if strings.HasPrefix(testContext.LastResponse.Header.Get("Content-Type"), "text/csv") {
+		records, err := csv.NewReader(bytes.NewReader(testContext.LastBody)).ReadAll()
+		if err != nil {
+			return err
+		}
+		for _, record := range records[1:] {
+			codes = append(codes, record[0])
+		}
+	} else {
+		var skus []struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(testContext.LastBody, &skus); err != nil {
+			return err
+		}
+		for _, s := range skus {
+			codes = append(codes, s.Code)
+		}
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("SKU %s not in export", code)
+}
+
 // Helper functions
 
 func getCellValue(table *godog.Table, row *godog.TableRow, columnName string) string {
@@ -220,3 +669,218 @@ func parseCellFloat(table *godog.Table, row *godog.TableRow, columnName string)
 	v, _ := strconv.ParseFloat(value, 64)
 	return v
 }
+
+// trainingImagePNG draws a small PNG whose pixels depend on the name, so the
+// same name always gives the same image
+func trainingImagePNG(name string) ([]byte, error) {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	sum := h.Sum32()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(sum), G: uint8(sum >> 8), B: uint8(sum >> 16), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func uploadTrainingImage(code, name string, data []byte, fields map[string]string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	if err := testContext.SendFileWithFields("POST", "/api/v1/skus/"+id+"/training-images", "image", name, data, fields); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if imageID, ok := response["id"].(string); ok {
+			testContext.TrainingImages[name] = imageID
+		}
+	}
+	return nil
+}
+
+func iUploadTheTrainingImageOfTheSKU(name, code string) error {
+	data, err := trainingImagePNG(name)
+	if err != nil {
+		return err
+	}
+	return uploadTrainingImage(code, name, data, map[string]string{"source": "studio"})
+}
+
+func iUploadTheTrainingImageOfTheSKUWithTheBoundingBox(name, code, box string) error {
+	data, err := trainingImagePNG(name)
+	if err != nil {
+		return err
+	}
+	return uploadTrainingImage(code, name, data, map[string]string{"bounding_box": box})
+}
+
+func iUploadATextFileAsTrainingImageOfTheSKU(code string) error {
+	return uploadTrainingImage(code, "notes.txt", []byte("not an image"), nil)
+}
+
+func trainingImagePath(name, code string) (string, error) {
+	skuID, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return "", fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	imageID, ok := testContext.TrainingImages[name]
+	if !ok {
+		return "", fmt.Errorf("training image %s was not uploaded in this scenario", name)
+	}
+	return "/api/v1/skus/" + skuID + "/training-images/" + imageID, nil
+}
+
+func iListTheTrainingImagesOfTheSKU(code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	return testContext.SendRequest("GET", "/api/v1/skus/"+id+"/training-images", nil)
+}
+
+func iFetchTheTrainingImageOfTheSKU(name, code string) error {
+	path, err := trainingImagePath(name, code)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("GET", path, nil)
+}
+
+func iDeleteTheTrainingImageOfTheSKU(name, code string) error {
+	path, err := trainingImagePath(name, code)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("DELETE", path, nil)
+}
+
+func iExportTheTrainingDataset() error {
+	return testContext.SendRequest("GET", "/api/v1/skus/training-images", nil)
+}
+
+func iTriggerAClassMappingSync() error {
+	return testContext.SendRequest("POST", "/api/v1/admin/ml/sync-classes", nil)
+}
+
+func iMapTheSKUToClass(code string, classID int) error {
+	return mapSKUToClass(code, &classID)
+}
+
+func theSKUIsMappedToClass(code string, classID int) error {
+	if err := mapSKUToClass(code, &classID); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return fmt.Errorf("failed to map class %d: status %d: %s", classID, testContext.LastResponse.StatusCode, testContext.LastBody)
+	}
+	return nil
+}
+
+func iMapTheSKUToTheNextFreeClass(code string) error {
+	return mapSKUToClass(code, nil)
+}
+
+func mapSKUToClass(code string, classID *int) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	body := map[string]any{"sku_id": id}
+	if classID != nil {
+		body["class_id"] = *classID
+	}
+	return testContext.SendRequest("POST", "/api/v1/admin/ml/classes", body)
+}
+
+func iPointClassAtTheSKU(classID int, code string) error {
+	id, ok := testContext.CreatedSKUs[code]
+	if !ok {
+		return fmt.Errorf("SKU %s was not created in this scenario", code)
+	}
+	return testContext.SendRequest("PUT", fmt.Sprintf("/api/v1/admin/ml/classes/%d", classID), map[string]any{"sku_id": id})
+}
+
+func theProductInformationSystemPushes(products *godog.DocString) error {
+	return pushCatalogWebhook(products.Content, support.CatalogWebhookSecret, time.Now())
+}
+
+func theProductInformationSystemPushesWithTheWrongSecret(products *godog.DocString) error {
+	return pushCatalogWebhook(products.Content, "not-the-webhook-secret", time.Now())
+}
+
+func theProductInformationSystemPushesADeliverySignedMinutesAgo(minutes int, products *godog.DocString) error {
+	return pushCatalogWebhook(products.Content, support.CatalogWebhookSecret, time.Now().Add(-time.Duration(minutes)*time.Minute))
+}
+
+// pushCatalogWebhook sends a catalog webhook delivery signed with the secret
+// at the given time, and remembers the SKUs it created by code
+func pushCatalogWebhook(content, secret string, at time.Time) error {
+	// Sign the body exactly as it is sent
+	body, err := json.Marshal(json.RawMessage(content))
+	if err != nil {
+		return fmt.Errorf("delivery is not JSON: %w", err)
+	}
+	timestamp, signature := webhook.NewVerifier([]byte(secret), 0).Sign(at, body)
+
+	err = testContext.SendRequestWithHeaders("POST", "/api/v1/integrations/catalog-webhook", json.RawMessage(body), map[string]string{
+		"X-Webhook-Timestamp": timestamp,
+		"X-Webhook-Signature": signature,
+	})
+	if err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 200 {
+		var result struct {
+			Products []struct {
+				SKUID string `json:"sku_id"`
+				Code  string `json:"code"`
+			} `json:"products"`
+		}
+		if err := json.Unmarshal(testContext.LastBody, &result); err == nil {
+			for _, p := range result.Products {
+				testContext.CreatedSKUs[p.Code] = p.SKUID
+			}
+		}
+	}
+	return nil
+}
+
+func aSubscriberHasReadTheTopicToItsEnd(topic string) error {
+	for {
+		if err := theSubscriberReadsTheTopic(topic); err != nil {
+			return err
+		}
+		if testContext.LastResponse.StatusCode != 200 {
+			return fmt.Errorf("failed to read topic %s: status %d", topic, testContext.LastResponse.StatusCode)
+		}
+
+		var page struct {
+			Messages   []json.RawMessage `json:"messages"`
+			NextOffset int64             `json:"next_offset"`
+		}
+		if err := json.Unmarshal(testContext.LastBody, &page); err != nil {
+			return fmt.Errorf("failed to parse topic: %w", err)
+		}
+		if len(page.Messages) == 0 {
+			return nil
+		}
+		testContext.TopicOffset = page.NextOffset
+	}
+}
+
+// theSubscriberReadsTheTopic reads the messages published since the
+// subscriber last caught up, so earlier scenarios do not show
+func theSubscriberReadsTheTopic(topic string) error {
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/events/%s?after=%d&limit=1000", topic, testContext.TopicOffset), nil)
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/vending-machine/server/test/support"
 )
@@ -18,12 +19,26 @@ func theDatabaseIsClean() error {
 	return testContext.CleanDatabase()
 }
 
+// secondsPass lets real time pass for steps that depend on how long ago
+// something happened
+func secondsPass(seconds int) error {
+	time.Sleep(time.Duration(seconds) * time.Second)
+	return nil
+}
+
 func iSendRequestTo(method, path string) error {
 	// Replace placeholders in path
 	path = replacePlaceholders(path)
 	return testContext.SendRequest(method, path, nil)
 }
 
+func iSendRequestToTaggedForRegion(method, path, region string) error {
+	path = replacePlaceholders(path)
+	return testContext.SendRequestWithHeaders(method, path, nil, map[string]string{
+		"X-Region": region,
+	})
+}
+
 func theResponseStatusShouldBe(expectedStatus int) error {
 	if testContext.LastResponse.StatusCode != expectedStatus {
 		return fmt.Errorf("expected status %d, got %d. Body: %s",
@@ -45,6 +60,30 @@ func theResponseShouldContainField(field string) error {
 	return nil
 }
 
+func theResponseShouldNotContainField(field string) error {
+	if strings.Contains(field, ".") {
+		value, err := testContext.GetNestedField(field)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			return fmt.Errorf("field %s unexpectedly present in response: %v", field, value)
+		}
+		return nil
+	}
+
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+
+	if value, exists := response[field]; exists {
+		return fmt.Errorf("field %s unexpectedly present in response: %v", field, value)
+	}
+
+	return nil
+}
+
 func theResponseShouldContainFieldWithValue(field, expectedValue string) error {
 	response, err := testContext.GetResponseJSON()
 	if err != nil {
@@ -1,63 +1,1534 @@
 package test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cucumber/godog"
+	"github.com/makiuchi-d/gozxing"
+	gozxingqr "github.com/makiuchi-d/gozxing/qrcode"
+	"golang.org/x/net/websocket"
 )
 
 // Device-specific step definitions
 
+// provisioningOperator creates the enrollment tokens of devices set up by steps
+const provisioningOperator = "ops-provisioning"
+
 func iRegisterDeviceWithDetails(table *godog.Table) error {
 	if len(table.Rows) < 2 {
 		return fmt.Errorf("table must have at least 2 rows (header + data)")
 	}
 
 	row := table.Rows[1]
-	device := map[string]interface{}{
-		"machine_id": getCellValue(table, row, "machine_id"),
-		"name":       getCellValue(table, row, "name"),
-		"location":   getCellValue(table, row, "location"),
+	return enrollDevice(provisioningOperator, getCellValue(table, row, "machine_id"),
+		getCellValue(table, row, "name"), getCellValue(table, row, "location"), false)
+}
+
+func aDeviceExistsWithMachineID(machineID string) error {
+	if err := enrollDevice(provisioningOperator, machineID, "Test Device", "Test Location", false); err != nil {
+		return err
 	}
 
-	err := testContext.SendRequest("POST", "/api/v1/device/register", device)
-	if err != nil {
+	if testContext.LastResponse.StatusCode != 201 && testContext.LastResponse.StatusCode != 200 {
+		return fmt.Errorf("failed to create device: status %d", testContext.LastResponse.StatusCode)
+	}
+	return nil
+}
+
+// enrollDevice provisions a device the way an operator and the device do it:
+// the operator creates an enrollment token and the device redeems it. When
+// the operator is refused, that response is left as the last one.
+func enrollDevice(operator, machineID, name, location string, override bool) error {
+	if err := operatorCreatesEnrollmentToken(operator, machineID, name, location, override); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return nil
+	}
+	return deviceEnrollsWithTheTokenFor(machineID, machineID)
+}
+
+func operatorCreatesEnrollmentToken(operator, machineID, name, location string, override bool) error {
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"name":       name,
+		"location":   location,
+		"override":   override,
+	}
+	headers := map[string]string{}
+	if operator != "" {
+		headers["X-Actor-ID"] = operator
+	}
+
+	if err := testContext.SendRequestWithHeaders("POST", "/api/v1/devices/enrollment-tokens", body, headers); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if token, ok := response["enrollment_token"].(string); ok {
+			testContext.EnrollmentTokens[machineID] = token
+		}
+	}
+	return nil
+}
+
+func operatorCreatesAnEnrollmentTokenFor(operator, machineID string) error {
+	return operatorCreatesEnrollmentToken(operator, machineID, "Test Device", "Test Location", false)
+}
+
+func deviceEnrollsWithItsToken(machineID string) error {
+	return deviceEnrollsWithTheTokenFor(machineID, machineID)
+}
+
+func deviceEnrollsWithTheTokenFor(machineID, tokenMachineID string) error {
+	body := map[string]interface{}{
+		"machine_id":       machineID,
+		"enrollment_token": testContext.EnrollmentTokens[tokenMachineID],
+	}
+
+	if err := testContext.SendRequest("POST", "/api/v1/device/enroll", body); err != nil {
 		return err
 	}
 
-	// Store created device ID if successful
 	if testContext.LastResponse.StatusCode == 201 || testContext.LastResponse.StatusCode == 200 {
 		response, _ := testContext.GetResponseJSON()
 		if id, ok := response["id"].(string); ok {
-			machineID := getCellValue(table, row, "machine_id")
 			testContext.CreatedDevices[machineID] = id
 		}
+		if key, ok := response["device_key"].(string); ok {
+			testContext.DeviceKeys[machineID] = key
+		}
 	}
-
 	return nil
 }
 
-func aDeviceExistsWithMachineID(machineID string) error {
-	device := map[string]interface{}{
+func iSubmitShelfSnapshotForDevice(machineID string) error {
+	snapshot := map[string]interface{}{
+		"machine_id": machineID,
+		"image":      base64.StdEncoding.EncodeToString([]byte("fake-jpeg-bytes")),
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/snapshot", snapshot)
+}
+
+func deviceReportsCabinetTemperature(machineID, celsius string, minutesAgo int) error {
+	temperature, err := strconv.ParseFloat(celsius, 64)
+	if err != nil {
+		return fmt.Errorf("invalid temperature %q: %w", celsius, err)
+	}
+
+	telemetry := map[string]interface{}{
+		"machine_id":          machineID,
+		"temperature_celsius": temperature,
+		"recorded_at":         time.Now().UTC().Add(-time.Duration(minutesAgo) * time.Minute),
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/telemetry", telemetry)
+}
+
+func operatorClearsDevice(operator, machineID string) error {
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"note":       "cabinet checked, spoiled stock removed",
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/clear", body, map[string]string{
+		"X-Actor-ID": operator,
+	})
+}
+
+func deviceReportsTheDoor(machineID, state string) error {
+	telemetry := map[string]interface{}{
 		"machine_id": machineID,
-		"name":       "Test Device",
-		"location":   "Test Location",
+		"door_open":  state == "open",
 	}
 
-	err := testContext.SendRequest("POST", "/api/v1/device/register", device)
+	return testContext.SendRequest("POST", "/api/v1/device/telemetry", telemetry)
+}
+
+func iAssignPlanogramToDevice(machineID string, table *godog.Table) error {
+	facings, err := planogramFacings(table)
 	if err != nil {
 		return err
 	}
 
-	if testContext.LastResponse.StatusCode != 201 && testContext.LastResponse.StatusCode != 200 {
-		return fmt.Errorf("failed to create device: status %d", testContext.LastResponse.StatusCode)
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"facings":    facings,
+	}
+
+	return testContext.SendRequest("PUT", "/api/v1/device/planogram", body)
+}
+
+func planogramFacings(table *godog.Table) ([]map[string]interface{}, error) {
+	var facings []map[string]interface{}
+	for _, row := range table.Rows[1:] {
+		shelf, err := strconv.Atoi(getCellValue(table, row, "shelf"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid shelf: %w", err)
+		}
+		count, err := strconv.Atoi(getCellValue(table, row, "facings"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid facings: %w", err)
+		}
+		facings = append(facings, map[string]interface{}{
+			"shelf":    shelf,
+			"sku_code": getCellValue(table, row, "sku_code"),
+			"facings":  count,
+		})
+	}
+	return facings, nil
+}
+
+func fieldStaffSubmitsRestockSnapshot(staff, machineID string) error {
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"image":      base64.StdEncoding.EncodeToString([]byte("fake-jpeg-bytes")),
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/restock-visit", body, map[string]string{
+		"X-Actor-ID": staff,
+	})
+}
+
+// iSetSalesHoursForDevice sends one row per window; "kind" is "open" for
+// opening hours or "blackout", and days/sku_codes are comma separated
+func iSetSalesHoursForDevice(machineID, timezone string, table *godog.Table) error {
+	opening := []map[string]interface{}{}
+	blackouts := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		window := map[string]interface{}{
+			"days":  splitCell(getCellValue(table, row, "days")),
+			"start": getCellValue(table, row, "start"),
+			"end":   getCellValue(table, row, "end"),
+		}
+		switch kind := getCellValue(table, row, "kind"); kind {
+		case "open":
+			opening = append(opening, window)
+		case "blackout":
+			window["sku_codes"] = splitCell(getCellValue(table, row, "sku_codes"))
+			window["reason"] = getCellValue(table, row, "reason")
+			blackouts = append(blackouts, window)
+		default:
+			return fmt.Errorf("unknown window kind %q", kind)
+		}
+	}
+
+	body := map[string]interface{}{
+		"machine_id":    machineID,
+		"timezone":      timezone,
+		"opening_hours": opening,
+		"blackouts":     blackouts,
+	}
+
+	return testContext.SendRequest("PUT", "/api/v1/device/sales-hours", body)
+}
+
+func fieldStaffRestocksDeviceWithBatches(staff, machineID string, table *godog.Table) error {
+	today := time.Now().UTC()
+	batches := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		quantity, err := strconv.Atoi(getCellValue(table, row, "quantity"))
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %w", err)
+		}
+		batch := map[string]interface{}{
+			"sku_code": getCellValue(table, row, "sku_code"),
+			"quantity": quantity,
+		}
+		// An empty expiry leaves it to the SKU's shelf life
+		if raw := getCellValue(table, row, "expires_in_days"); raw != "" {
+			days, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("invalid expires_in_days: %w", err)
+			}
+			batch["expires_on"] = today.AddDate(0, 0, days).Format(time.DateOnly)
+		}
+		batches = append(batches, batch)
+	}
+
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"batches":    batches,
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/batches", body, map[string]string{
+		"X-Actor-ID": staff,
+	})
+}
+
+func fieldStaffWritesOffBatchOnDevice(staff, skuCode, machineID string) error {
+	if err := testContext.SendRequest("GET", "/api/v1/device/batches?machine_id="+machineID, nil); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 200 {
+		return fmt.Errorf("failed to list batches: status %d", testContext.LastResponse.StatusCode)
+	}
+
+	var batches []struct {
+		ID      string `json:"id"`
+		SKUCode string `json:"sku_code"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &batches); err != nil {
+		return fmt.Errorf("failed to parse batches: %w", err)
+	}
+	for _, b := range batches {
+		if b.SKUCode == skuCode {
+			return testContext.SendRequestWithHeaders("POST", "/api/v1/device/batches/"+b.ID+"/write-off", nil, map[string]string{
+				"X-Actor-ID": staff,
+			})
+		}
+	}
+	return fmt.Errorf("no open batch of %s on device %s", skuCode, machineID)
+}
+
+func splitCell(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func fieldStaffRestocksInventoryOfDevice(staff, machineID string, table *godog.Table) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+
+	items := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		quantity, err := strconv.Atoi(getCellValue(table, row, "quantity"))
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %w", err)
+		}
+		items = append(items, map[string]interface{}{
+			"sku_code": getCellValue(table, row, "sku_code"),
+			"quantity": quantity,
+		})
+	}
+
+	headers := map[string]string{}
+	if staff != "" {
+		headers["X-Actor-ID"] = staff
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/devices/"+deviceID+"/inventory", map[string]interface{}{
+		"items": items,
+	}, headers)
+}
+
+// theInventoryOfDeviceShouldHave polls briefly, since completed sessions are
+// taken out of the inventory asynchronously
+func theInventoryOfDeviceShouldHave(machineID string, expected int, skuCode string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+
+	var quantity int
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err := testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/inventory", nil); err != nil {
+			return err
+		}
+		if testContext.LastResponse.StatusCode != 200 {
+			return fmt.Errorf("failed to get inventory: status %d", testContext.LastResponse.StatusCode)
+		}
+
+		var inventory struct {
+			Levels []struct {
+				SKUCode  string `json:"sku_code"`
+				Quantity int    `json:"quantity"`
+			} `json:"levels"`
+		}
+		if err := json.Unmarshal(testContext.LastBody, &inventory); err != nil {
+			return fmt.Errorf("failed to parse inventory: %w", err)
+		}
+		quantity = 0
+		for _, l := range inventory.Levels {
+			if l.SKUCode == skuCode {
+				quantity = l.Quantity
+			}
+		}
+		if quantity == expected || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if quantity != expected {
+		return fmt.Errorf("expected %d units of %s in device %s, got %d", expected, skuCode, machineID, quantity)
+	}
+	return nil
+}
+
+func iRequestTheLowInventoryOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/inventory/low", nil)
+}
+
+func iAssignTheSKUsToDevice(skuCodes, machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("POST", "/api/v1/devices/"+deviceID+"/skus", map[string]interface{}{
+		"sku_codes": splitCell(skuCodes),
+	})
+}
+
+func iUnassignTheSKUFromDevice(skuCode, machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("DELETE", "/api/v1/devices/"+deviceID+"/skus/"+skuCode, nil)
+}
+
+func iRequestTheSKUsOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/skus", nil)
+}
+
+func theDeviceHasSyncedItsSKUs() error {
+	if err := testContext.SendRequest("GET", "/api/v1/device/skus", nil); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 200 {
+		return fmt.Errorf("failed to sync SKUs: status %d", testContext.LastResponse.StatusCode)
+	}
+
+	var sync struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &sync); err != nil {
+		return fmt.Errorf("failed to parse sync: %w", err)
+	}
+	if sync.Cursor == "" {
+		return fmt.Errorf("sync returned no cursor")
+	}
+	testContext.SyncCursor = sync.Cursor
+	return nil
+}
+
+func theDeviceSyncsItsSKUsIn(languages string) error {
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/device/skus", nil, map[string]string{"Accept-Language": languages})
+}
+
+func theSKUSyncShouldMapClassTo(classID int, code string) error {
+	var sync struct {
+		Classes []struct {
+			ClassID int    `json:"class_id"`
+			Code    string `json:"code"`
+		} `json:"classes"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &sync); err != nil {
+		return fmt.Errorf("failed to parse sync: %w", err)
+	}
+
+	for _, c := range sync.Classes {
+		if c.ClassID == classID && c.Code == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("class %d is not mapped to %s in %s", classID, code, string(testContext.LastBody))
+}
+
+func theDeviceSyncsTheSKUChangesSinceItsLastSync() error {
+	if testContext.SyncCursor == "" {
+		return fmt.Errorf("the device has not synced in this scenario")
+	}
+	return testContext.SendRequest("GET", "/api/v1/device/skus?since="+url.QueryEscape(testContext.SyncCursor), nil)
+}
+
+func deviceSendsAHeartbeatWithFirmwareAndApp(machineID, firmware, appVersion string) error {
+	return sendHeartbeat(machineID, testContext.DeviceKeys[machineID], map[string]interface{}{
+		"machine_id":       machineID,
+		"firmware_version": firmware,
+		"app_version":      appVersion,
+	})
+}
+
+func deviceSendsAHeartbeatWithTheKeyOf(machineID, keyOwner string) error {
+	return sendHeartbeat(machineID, testContext.DeviceKeys[keyOwner], map[string]interface{}{"machine_id": machineID})
+}
+
+func deviceRequestsAStartToken(machineID string) error {
+	return deviceRequestsAStartTokenWithTheKeyOf(machineID, machineID)
+}
+
+func deviceRequestsAStartTokenWithTheKeyOf(machineID, keyOwner string) error {
+	key := testContext.DeviceKeys[keyOwner]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", keyOwner)
+	}
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/device/start-token?machine_id="+url.QueryEscape(machineID), nil, map[string]string{"X-Device-Key": key})
+}
+
+func sendHeartbeat(machineID, key string, body map[string]interface{}) error {
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for the device sending as %s", machineID)
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/heartbeat", body, map[string]string{"X-Device-Key": key})
+}
+
+func theDeviceShouldBeListedAs(machineID, state string) error {
+	if err := testContext.SendRequest("GET", "/api/v1/devices", nil); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 200 {
+		return fmt.Errorf("failed to list devices: status %d", testContext.LastResponse.StatusCode)
+	}
+
+	var listing struct {
+		Devices []struct {
+			MachineID string `json:"machine_id"`
+			Stale     bool   `json:"stale"`
+		} `json:"devices"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &listing); err != nil {
+		return fmt.Errorf("failed to parse devices: %w", err)
+	}
+	for _, d := range listing.Devices {
+		if d.MachineID != machineID {
+			continue
+		}
+		if d.Stale != (state == "stale") {
+			return fmt.Errorf("device %s: expected %s, got stale=%v", machineID, state, d.Stale)
+		}
+		return nil
 	}
+	return fmt.Errorf("device %s is not listed", machineID)
+}
+
+func operatorDecommissionsDevice(operator, machineID string) error {
+	headers := map[string]string{}
+	if operator != "" {
+		headers["X-Actor-ID"] = operator
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/decommission", map[string]interface{}{
+		"machine_id": machineID,
+	}, headers)
+}
+
+func operatorReEnrollsDeviceWithAnOverride(operator, machineID string) error {
+	return enrollDevice(operator, machineID, "Replacement Device", "Test Location", true)
+}
 
-	// Store created device ID
-	response, _ := testContext.GetResponseJSON()
-	if id, ok := response["id"].(string); ok {
-		testContext.CreatedDevices[machineID] = id
+func iCreateTheDeviceGroup(name string) error {
+	if err := testContext.SendRequest("POST", "/api/v1/device-groups", map[string]interface{}{
+		"name": name,
+	}); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return fmt.Errorf("failed to create device group %s: status %d: %s", name, testContext.LastResponse.StatusCode, testContext.LastBody)
 	}
 
+	var group struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &group); err != nil {
+		return fmt.Errorf("failed to parse device group: %w", err)
+	}
+	testContext.DeviceGroups[name] = group.ID
 	return nil
 }
+
+func deviceGroupID(name string) (string, error) {
+	groupID, ok := testContext.DeviceGroups[name]
+	if !ok {
+		return "", fmt.Errorf("device group %s was not created in this scenario", name)
+	}
+	return groupID, nil
+}
+
+func iAddTheDevicesToTheGroup(machineIDs, name string) error {
+	groupID, err := deviceGroupID(name)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("POST", "/api/v1/device-groups/"+groupID+"/devices", map[string]interface{}{
+		"machine_ids": splitCell(machineIDs),
+	})
+}
+
+func iRemoveTheDeviceFromTheGroup(machineID, name string) error {
+	groupID, err := deviceGroupID(name)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("DELETE", "/api/v1/device-groups/"+groupID+"/devices/"+machineID, nil)
+}
+
+func iRequestTheDeviceGroup(name string) error {
+	groupID, err := deviceGroupID(name)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("GET", "/api/v1/device-groups/"+groupID, nil)
+}
+
+// iSetTheFollowingPolicyForTheGroup reads an optional confidence_threshold
+// and model_version from a single-row table
+func iSetTheFollowingPolicyForTheGroup(name string, table *godog.Table) error {
+	groupID, err := deviceGroupID(name)
+	if err != nil {
+		return err
+	}
+	body, err := policyBody(table)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("PUT", "/api/v1/device-groups/"+groupID+"/policy", body)
+}
+
+func iSetTheFollowingPlanogramForTheGroup(name string, table *godog.Table) error {
+	groupID, err := deviceGroupID(name)
+	if err != nil {
+		return err
+	}
+	facings, err := planogramFacings(table)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("PUT", "/api/v1/device-groups/"+groupID+"/policy", map[string]interface{}{
+		"planogram": facings,
+	})
+}
+
+func iSetTheFollowingPolicyOverridesForDevice(machineID string, table *godog.Table) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	body, err := policyBody(table)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("PUT", "/api/v1/devices/"+deviceID+"/policy", body)
+}
+
+func iRequestThePolicyOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/policy", nil)
+}
+
+func policyBody(table *godog.Table) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	if len(table.Rows) < 2 {
+		return body, nil
+	}
+	row := table.Rows[1]
+	if threshold := getCellValue(table, row, "confidence_threshold"); threshold != "" {
+		value, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid confidence_threshold: %w", err)
+		}
+		body["confidence_threshold"] = value
+	}
+	if version := getCellValue(table, row, "model_version"); version != "" {
+		body["model_version"] = version
+	}
+	if expiration := getCellValue(table, row, "session_expiration_minutes"); expiration != "" {
+		value, err := strconv.Atoi(expiration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session_expiration_minutes: %w", err)
+		}
+		body["session_expiration_minutes"] = value
+	}
+	return body, nil
+}
+
+func operatorSetsTheFollowingConfigurationForDevice(operator, machineID string, table *godog.Table) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	config, err := deviceConfigBody(table)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequestWithHeaders("PUT", "/api/v1/devices/"+deviceID+"/config", config, map[string]string{"X-Actor-ID": operator})
+}
+
+func devicePullsItsConfiguration(machineID string) error {
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/device/config?machine_id="+machineID, nil, map[string]string{"X-Device-Key": key})
+}
+
+func deviceReportsApplyingConfigurationVersion(machineID string, version int, table *godog.Table) error {
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+	config, err := deviceConfigBody(table)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/config/reported", map[string]interface{}{
+		"machine_id": machineID,
+		"version":    version,
+		"config":     config,
+	}, map[string]string{"X-Device-Key": key})
+}
+
+func iRequestTheConfigurationOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/config", nil)
+}
+
+// deviceConfigBody reads the settings of a single-row table; empty cells are left out
+func deviceConfigBody(table *godog.Table) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	camera := map[string]interface{}{}
+	if len(table.Rows) < 2 {
+		return config, nil
+	}
+	row := table.Rows[1]
+
+	if threshold := getCellValue(table, row, "confidence_threshold"); threshold != "" {
+		value, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid confidence_threshold: %w", err)
+		}
+		config["confidence_threshold"] = value
+	}
+	for column, target := range map[string]map[string]interface{}{
+		"sync_interval_seconds": config,
+		"frame_rate":            camera,
+		"exposure_micros":       camera,
+	} {
+		if raw := getCellValue(table, row, column); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", column, err)
+			}
+			target[column] = value
+		}
+	}
+	if resolution := getCellValue(table, row, "resolution"); resolution != "" {
+		camera["resolution"] = resolution
+	}
+	config["camera"] = camera
+	return config, nil
+}
+
+func operatorUploadsTheRelease(operator, kind, version string) error {
+	artifact := []byte(kind + " build " + version)
+	if err := testContext.SendFileWithHeaders("POST", "/api/v1/releases", "artifact", version+".bin", artifact,
+		map[string]string{"kind": kind, "version": version}, map[string]string{"X-Actor-ID": operator}); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return nil
+	}
+
+	var release struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &release); err != nil {
+		return fmt.Errorf("failed to parse release: %w", err)
+	}
+	testContext.Releases[version] = release.ID
+	return nil
+}
+
+func operatorRollsOutTheRelease(operator, version, groups string) error {
+	return operatorRollsOutTheReleaseInStages(operator, version, groups, "")
+}
+
+func operatorRollsOutTheReleaseInStages(operator, version, groups, stages string) error {
+	releaseID, ok := testContext.Releases[version]
+	if !ok {
+		return fmt.Errorf("release %s was not uploaded in this scenario", version)
+	}
+	groupIDs := []string{}
+	for _, name := range splitCell(groups) {
+		groupID, err := deviceGroupID(name)
+		if err != nil {
+			return err
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+	percentages := []int{}
+	for _, raw := range splitCell(stages) {
+		percent, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid stage %q: %w", raw, err)
+		}
+		percentages = append(percentages, percent)
+	}
+
+	if err := testContext.SendRequestWithHeaders("POST", "/api/v1/rollouts", map[string]interface{}{
+		"release_id": releaseID,
+		"group_ids":  groupIDs,
+		"stages":     percentages,
+	}, map[string]string{"X-Actor-ID": operator}); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return nil
+	}
+
+	var rollout struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &rollout); err != nil {
+		return fmt.Errorf("failed to parse rollout: %w", err)
+	}
+	testContext.Rollouts[version] = rollout.ID
+	return nil
+}
+
+func rolloutOfRelease(version string) (string, error) {
+	rolloutID, ok := testContext.Rollouts[version]
+	if !ok {
+		return "", fmt.Errorf("release %s was not rolled out in this scenario", version)
+	}
+	return rolloutID, nil
+}
+
+func operatorAdvancesTheRolloutOfRelease(operator, version string) error {
+	rolloutID, err := rolloutOfRelease(version)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/rollouts/"+rolloutID+"/advance", nil, map[string]string{"X-Actor-ID": operator})
+}
+
+func operatorAbortsTheRolloutOfRelease(operator, version, reason string) error {
+	rolloutID, err := rolloutOfRelease(version)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/rollouts/"+rolloutID+"/abort", map[string]interface{}{
+		"reason": reason,
+	}, map[string]string{"X-Actor-ID": operator})
+}
+
+func iRequestTheRolloutOfRelease(version string) error {
+	rolloutID, err := rolloutOfRelease(version)
+	if err != nil {
+		return err
+	}
+	return testContext.SendRequest("GET", "/api/v1/rollouts/"+rolloutID, nil)
+}
+
+func deviceReportsTheUpdateOfReleaseAs(machineID, version, status string) error {
+	rolloutID, err := rolloutOfRelease(version)
+	if err != nil {
+		return err
+	}
+	return sendHeartbeat(machineID, testContext.DeviceKeys[machineID], map[string]interface{}{
+		"machine_id": machineID,
+		"updates": []map[string]interface{}{
+			{"rollout_id": rolloutID, "status": status},
+		},
+	})
+}
+
+func deviceDownloadsTheRelease(machineID, version string) error {
+	releaseID, ok := testContext.Releases[version]
+	if !ok {
+		return fmt.Errorf("release %s was not uploaded in this scenario", version)
+	}
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+	path := "/api/v1/device/releases/" + releaseID + "?machine_id=" + url.QueryEscape(machineID)
+	return testContext.SendRequestWithHeaders("GET", path, nil, map[string]string{"X-Device-Key": key})
+}
+
+func theHeartbeatShouldOfferUpdates(count int) error {
+	var heartbeat struct {
+		Updates []json.RawMessage `json:"updates"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &heartbeat); err != nil {
+		return fmt.Errorf("failed to parse heartbeat: %w", err)
+	}
+	if len(heartbeat.Updates) != count {
+		return fmt.Errorf("expected %d offered updates, got %d: %s", count, len(heartbeat.Updates), testContext.LastBody)
+	}
+	return nil
+}
+
+func deviceSendsAHeartbeatRunningModel(machineID, modelVersion string) error {
+	return sendHeartbeat(machineID, testContext.DeviceKeys[machineID], map[string]interface{}{
+		"machine_id":    machineID,
+		"model_version": modelVersion,
+	})
+}
+
+func iRequestTheFleetHealth() error {
+	return testContext.SendRequest("GET", "/api/v1/admin/fleet/health", nil)
+}
+
+func iRequestTheFleetHealthCountingDevicesOfflineAfter(minutes int) error {
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/admin/fleet/health?offline_minutes=%d", minutes), nil)
+}
+
+type fleetHealthBody struct {
+	Offline struct {
+		MachineIDs []string `json:"machine_ids"`
+	} `json:"offline"`
+	StaleModels struct {
+		MachineIDs []string `json:"machine_ids"`
+	} `json:"stale_models"`
+	WeightMismatch struct {
+		Mismatched int `json:"mismatched"`
+	} `json:"weight_mismatch"`
+}
+
+func lastFleetHealth() (fleetHealthBody, error) {
+	var health fleetHealthBody
+	if err := json.Unmarshal(testContext.LastBody, &health); err != nil {
+		return fleetHealthBody{}, fmt.Errorf("failed to parse fleet health: %w", err)
+	}
+	return health, nil
+}
+
+func theFleetHealthShouldListDeviceAs(negation, machineID, list string) error {
+	health, err := lastFleetHealth()
+	if err != nil {
+		return err
+	}
+	machineIDs := health.Offline.MachineIDs
+	if list == "running a stale model" {
+		machineIDs = health.StaleModels.MachineIDs
+	}
+
+	listed := false
+	for _, id := range machineIDs {
+		if id == machineID {
+			listed = true
+			break
+		}
+	}
+	if want := negation == ""; listed != want {
+		return fmt.Errorf("expected device %s %slisted as %s: %s", machineID, negation, list, testContext.LastBody)
+	}
+	return nil
+}
+
+func theFleetHealthShouldCountAtLeastOpenSessionsWithAWeightMismatch(count int) error {
+	health, err := lastFleetHealth()
+	if err != nil {
+		return err
+	}
+	if health.WeightMismatch.Mismatched < count {
+		return fmt.Errorf("expected at least %d open sessions with a weight mismatch, got %d", count, health.WeightMismatch.Mismatched)
+	}
+	return nil
+}
+
+func iRequestTheQRCodeOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/qrcode.png", nil)
+}
+
+func iRequestTheQRCodeOfDeviceAtPixels(machineID string, size int) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s was not created in this scenario", machineID)
+	}
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/devices/%s/qrcode.png?size=%d", deviceID, size), nil)
+}
+
+func theResponseShouldBeAPNGImageAtMostPixelsWide(size int) error {
+	img, err := png.Decode(bytes.NewReader(testContext.LastBody))
+	if err != nil {
+		return fmt.Errorf("response is not a PNG: %w", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() != bounds.Dy() {
+		return fmt.Errorf("expected a square image, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if bounds.Dx() > size {
+		return fmt.Errorf("expected an image at most %d pixels wide, got %d", size, bounds.Dx())
+	}
+	return nil
+}
+
+func theQRCodeShouldHoldTheDeepLinkOfDevice(machineID string) error {
+	img, err := png.Decode(bytes.NewReader(testContext.LastBody))
+	if err != nil {
+		return fmt.Errorf("response is not a PNG: %w", err)
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return err
+	}
+	result, err := gozxingqr.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		return fmt.Errorf("the QR code does not scan: %w", err)
+	}
+	// The test server links devices to https://shop.example.com/m/{machine_id}
+	expected := "https://shop.example.com/m/" + url.QueryEscape(machineID)
+	if result.GetText() != expected {
+		return fmt.Errorf("expected the QR code to hold %q, got %q", expected, result.GetText())
+	}
+	return nil
+}
+
+func technicianPutsDeviceIntoMaintenance(technician, machineID, reason string) error {
+	headers := map[string]string{}
+	if technician != "" {
+		headers["X-Actor-ID"] = technician
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/maintenance/enter", map[string]interface{}{
+		"machine_id": machineID,
+		"reason":     reason,
+	}, headers)
+}
+
+func technicianTakesDeviceOutOfMaintenance(technician, machineID string) error {
+	headers := map[string]string{}
+	if technician != "" {
+		headers["X-Actor-ID"] = technician
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/maintenance/exit", map[string]interface{}{
+		"machine_id": machineID,
+	}, headers)
+}
+
+// deviceEventAnchors pins "minutes ago" to the first upload per device, so
+// a batch uploaded again carries the same timestamps
+var deviceEventAnchors = map[string]time.Time{}
+
+func deviceEventAnchor(machineID string) time.Time {
+	anchor, ok := deviceEventAnchors[machineID]
+	if !ok {
+		anchor = time.Now().UTC().Truncate(time.Second)
+		deviceEventAnchors[machineID] = anchor
+	}
+	return anchor
+}
+
+func deviceUploadsTheFollowingEvents(machineID string, table *godog.Table) error {
+	anchor := deviceEventAnchor(machineID)
+	header := table.Rows[0].Cells
+	var events []map[string]interface{}
+	for _, row := range table.Rows[1:] {
+		event := map[string]interface{}{}
+		for i, cell := range row.Cells {
+			switch header[i].Value {
+			case "minutes_ago":
+				minutes, err := strconv.Atoi(cell.Value)
+				if err != nil {
+					return fmt.Errorf("invalid minutes_ago %q: %w", cell.Value, err)
+				}
+				event["occurred_at"] = anchor.Add(-time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+			default:
+				event[header[i].Value] = cell.Value
+			}
+		}
+		events = append(events, event)
+	}
+
+	return sendDeviceEvents(machineID, testContext.DeviceKeys[machineID], events)
+}
+
+func deviceUploadsEventsWithTheKey(machineID, key string) error {
+	return sendDeviceEvents(machineID, key, []map[string]interface{}{
+		{"kind": "door_opened", "occurred_at": time.Now().UTC().Format(time.RFC3339)},
+	})
+}
+
+func sendDeviceEvents(machineID, key string, events []map[string]interface{}) error {
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/events", map[string]interface{}{
+		"machine_id": machineID,
+		"events":     events,
+	}, map[string]string{"X-Device-Key": key})
+}
+
+func iRequestTheEventLogOfDeviceFromToMinutesAgo(machineID string, fromMinutes, toMinutes int) error {
+	anchor := deviceEventAnchor(machineID)
+	query := url.Values{
+		"machine_id": {machineID},
+		"from":       {anchor.Add(-time.Duration(fromMinutes) * time.Minute).Format(time.RFC3339)},
+		"to":         {anchor.Add(-time.Duration(toMinutes) * time.Minute).Format(time.RFC3339)},
+	}
+	return testContext.SendRequest("GET", "/api/v1/device/events?"+query.Encode(), nil)
+}
+
+func theDeviceEventLogShouldBe(kinds string) error {
+	var events []struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &events); err != nil {
+		return fmt.Errorf("failed to parse device events: %w", err)
+	}
+
+	got := make([]string, 0, len(events))
+	for _, e := range events {
+		got = append(got, e.Kind)
+	}
+	if strings.Join(got, ",") != kinds {
+		return fmt.Errorf("expected device events %s, got %s", kinds, strings.Join(got, ","))
+	}
+	return nil
+}
+
+func operatorSendsACommandToDevice(operator, kind, machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	headers := map[string]string{}
+	if operator != "" {
+		headers["X-Actor-ID"] = operator
+	}
+
+	err := testContext.SendRequestWithHeaders("POST", "/api/v1/devices/"+deviceID+"/commands", map[string]interface{}{
+		"kind": kind,
+	}, headers)
+	if err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["id"].(string); ok {
+			testContext.DeviceCommands[kind] = id
+		}
+	}
+	return nil
+}
+
+func devicePollsForCommands(machineID string) error {
+	return devicePollsForCommandsWithTheKey(machineID, testContext.DeviceKeys[machineID])
+}
+
+func devicePollsForCommandsWithTheKey(machineID, key string) error {
+	query := url.Values{"machine_id": {machineID}, "wait": {"0"}}
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/device/commands?"+query.Encode(), nil,
+		map[string]string{"X-Device-Key": key})
+}
+
+func deviceAcknowledgesTheCommandAs(machineID, kind, status string) error {
+	commandID, ok := testContext.DeviceCommands[kind]
+	if !ok {
+		return fmt.Errorf("no %s command issued", kind)
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/commands/"+commandID+"/ack", map[string]interface{}{
+		"machine_id": machineID,
+		"status":     status,
+	}, map[string]string{"X-Device-Key": testContext.DeviceKeys[machineID]})
+}
+
+func operatorRequestsDiagnosticsOfDevice(operator, machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+
+	err := testContext.SendRequestWithHeaders("POST", "/api/v1/devices/"+deviceID+"/diagnostics", nil,
+		map[string]string{"X-Actor-ID": operator})
+	if err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 202 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["command_id"].(string); ok {
+			testContext.DeviceCommands["run_diagnostics"] = id
+		}
+	}
+	return nil
+}
+
+// deviceUploadsItsDiagnostics uploads the self-test report for the last
+// diagnostics run, one | field | value | row per reported field
+func deviceUploadsItsDiagnostics(machineID string, table *godog.Table) error {
+	commandID, ok := testContext.DeviceCommands["run_diagnostics"]
+	if !ok {
+		return fmt.Errorf("no diagnostics requested")
+	}
+
+	body := map[string]interface{}{"machine_id": machineID}
+	for _, row := range table.Rows {
+		field, value := row.Cells[0].Value, row.Cells[1].Value
+		switch field {
+		case "camera_ok", "scale_ok", "model_loaded":
+			body[field] = value == "true"
+		default:
+			body[field] = value
+		}
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/commands/"+commandID+"/diagnostics", body,
+		map[string]string{"X-Device-Key": testContext.DeviceKeys[machineID]})
+}
+
+func iRequestTheDiagnosticsOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	commandID, ok := testContext.DeviceCommands["run_diagnostics"]
+	if !ok {
+		return fmt.Errorf("no diagnostics requested")
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/diagnostics/"+commandID, nil)
+}
+
+func iLabelDeviceWithTags(machineID, tags string) error {
+	return iLabelDeviceWithTagsAndMetadata(machineID, tags, nil)
+}
+
+// iLabelDeviceWithTagsAndMetadata replaces the device's labels with the comma
+// separated tags and one | key | value | row per metadata entry
+func iLabelDeviceWithTagsAndMetadata(machineID, tags string, table *godog.Table) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+
+	tagList := []string{}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tagList = append(tagList, tag)
+		}
+	}
+	metadata := map[string]string{}
+	if table != nil {
+		for _, row := range table.Rows {
+			metadata[row.Cells[0].Value] = row.Cells[1].Value
+		}
+	}
+
+	return testContext.SendRequest("PUT", "/api/v1/devices/"+deviceID+"/labels", map[string]interface{}{
+		"tags":     tagList,
+		"metadata": metadata,
+	})
+}
+
+// deviceReceivesTheCommandOnTheCommandSocket connects to the command socket
+// and waits for the command among the frames the server sends
+func deviceReceivesTheCommandOnTheCommandSocket(machineID, kind string) error {
+	commandID, ok := testContext.DeviceCommands[kind]
+	if !ok {
+		return fmt.Errorf("no %s command issued", kind)
+	}
+
+	endpoint := "ws" + strings.TrimPrefix(testContext.Server.URL, "http") +
+		"/api/v1/device/commands/ws?" + url.Values{"machine_id": {machineID}}.Encode()
+	config, err := websocket.NewConfig(endpoint, testContext.Server.URL)
+	if err != nil {
+		return err
+	}
+	config.Header.Set("X-Device-Key", testContext.DeviceKeys[machineID])
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the command socket: %w", err)
+	}
+	defer ws.Close()
+	if err := ws.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return err
+	}
+
+	for {
+		var msg struct {
+			Type    string `json:"type"`
+			Command struct {
+				ID string `json:"id"`
+			} `json:"command"`
+		}
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return fmt.Errorf("no %s command on the command socket: %w", kind, err)
+		}
+		if msg.Type == "command" && msg.Command.ID == commandID {
+			return nil
+		}
+	}
+}
+
+func iRequestTheCommandsOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/commands", nil)
+}
+
+// theCommandsShouldBe compares the "kind:status" pairs of the listed or
+// polled commands, in order
+func theCommandsShouldBe(expected string) error {
+	var commands []struct {
+		Kind   string `json:"kind"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &commands); err != nil {
+		return fmt.Errorf("failed to parse commands: %w", err)
+	}
+
+	got := make([]string, 0, len(commands))
+	for _, c := range commands {
+		got = append(got, c.Kind+":"+c.Status)
+	}
+	if strings.Join(got, ",") != expected {
+		return fmt.Errorf("expected commands %q, got %q", expected, strings.Join(got, ","))
+	}
+	return nil
+}
+
+func theOfflineSweepRunsWithAWindowOf(seconds int) error {
+	return testContext.SendRequest("POST", "/api/v1/admin/fleet/offline-sweep?offline_seconds="+strconv.Itoa(seconds), nil)
+}
+
+func theOfflineSweepShouldListDevice(not, machineID string) error {
+	var sweep struct {
+		MachineIDs []string `json:"machine_ids"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &sweep); err != nil {
+		return fmt.Errorf("failed to parse offline sweep: %w", err)
+	}
+
+	listed := false
+	for _, id := range sweep.MachineIDs {
+		if id == machineID {
+			listed = true
+		}
+	}
+	if listed != (not == "") {
+		return fmt.Errorf("expected device %s %slisted by the offline sweep, got %v", machineID, not, sweep.MachineIDs)
+	}
+	return nil
+}
+
+func operatorStartsRestockingDevice(operator, machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	headers := map[string]string{}
+	if operator != "" {
+		headers["X-Actor-ID"] = operator
+	}
+
+	if err := testContext.SendRequestWithHeaders("POST", "/api/v1/devices/"+deviceID+"/restock-sessions", nil, headers); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		response, _ := testContext.GetResponseJSON()
+		if id, ok := response["id"].(string); ok {
+			testContext.RestockSession = id
+		}
+	}
+	return nil
+}
+
+func operatorLoadsTheFollowingUnitsInTheRestockSession(table *godog.Table) error {
+	if testContext.RestockSession == "" {
+		return fmt.Errorf("no restock session was started in this scenario")
+	}
+
+	items := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		quantity, err := strconv.Atoi(getCellValue(table, row, "quantity"))
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %w", err)
+		}
+		items = append(items, map[string]interface{}{
+			"sku_code": getCellValue(table, row, "sku_code"),
+			"quantity": quantity,
+		})
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/restock-sessions/"+testContext.RestockSession+"/units", map[string]interface{}{
+		"items": items,
+	})
+}
+
+func operatorClosesTheRestockSession(operator, action string) error {
+	if testContext.RestockSession == "" {
+		return fmt.Errorf("no restock session was started in this scenario")
+	}
+	headers := map[string]string{}
+	if operator != "" {
+		headers["X-Actor-ID"] = operator
+	}
+
+	path := "/api/v1/restock-sessions/" + testContext.RestockSession + "/complete"
+	if action == "cancels" {
+		path = "/api/v1/restock-sessions/" + testContext.RestockSession + "/cancel"
+	}
+	return testContext.SendRequestWithHeaders("POST", path, nil, headers)
+}
+
+func iRequestTheRestockReport() error {
+	return testContext.SendRequest("GET", "/api/v1/restock-sessions/"+testContext.RestockSession, nil)
+}
+
+// theRestockReportShouldBe compares the report's lines as
+// "code:added:level_after" triples, with "-" for a level not yet known
+func theRestockReportShouldBe(expected string) error {
+	var report struct {
+		Lines []struct {
+			SKUCode    string `json:"sku_code"`
+			Added      int    `json:"added"`
+			LevelAfter *int   `json:"level_after"`
+		} `json:"lines"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &report); err != nil {
+		return fmt.Errorf("failed to parse restock report: %w", err)
+	}
+
+	got := make([]string, 0, len(report.Lines))
+	for _, l := range report.Lines {
+		level := "-"
+		if l.LevelAfter != nil {
+			level = strconv.Itoa(*l.LevelAfter)
+		}
+		got = append(got, fmt.Sprintf("%s:%d:%s", l.SKUCode, l.Added, level))
+	}
+	if strings.Join(got, ",") != expected {
+		return fmt.Errorf("expected restock report %q, got %q", expected, strings.Join(got, ","))
+	}
+	return nil
+}
+
+func deviceUploadsACameraCalibrationWithMarkers(machineID, homography string, table *godog.Table) error {
+	markers := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		markers = append(markers, map[string]interface{}{
+			"id": getCellValue(table, row, "id"),
+			"x":  parseCellFloat(table, row, "x"),
+			"y":  parseCellFloat(table, row, "y"),
+		})
+	}
+	return uploadCameraCalibration(machineID, homography, markers, "")
+}
+
+func deviceUploadsACameraCalibrationCapturedAt(machineID, homography, resolution string) error {
+	return uploadCameraCalibration(machineID, homography, nil, resolution)
+}
+
+// uploadCameraCalibration sends a calibration whose homography is given as
+// nine comma-separated values
+func uploadCameraCalibration(machineID, homography string, markers []map[string]interface{}, resolution string) error {
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+
+	values := []float64{}
+	for _, raw := range strings.Split(homography, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid homography value %q: %w", raw, err)
+		}
+		values = append(values, v)
+	}
+
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"homography": values,
+		"capture":    map[string]interface{}{"resolution": resolution},
+	}
+	if markers != nil {
+		body["markers"] = markers
+	}
+	return testContext.SendRequestWithHeaders("PUT", "/api/v1/device/calibration", body, map[string]string{"X-Device-Key": key})
+}
+
+func devicePullsItsCameraCalibration(machineID string) error {
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/device/calibration?machine_id="+machineID, nil, map[string]string{"X-Device-Key": key})
+}
+
+func iRequestTheCameraCalibrationOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/calibration", nil)
+}
+
+func deviceCalibratesItsScale(machineID, zeroGrams string, table *godog.Table) error {
+	return calibrateScale(machineID, zeroGrams, table, nil)
+}
+
+func deviceCalibratedItsScaleDaysAgo(machineID string, days int, zeroGrams string, table *godog.Table) error {
+	at := time.Now().UTC().AddDate(0, 0, -days)
+	return calibrateScale(machineID, zeroGrams, table, &at)
+}
+
+// calibrateScale reports the as-found readings of a scale calibration, one
+// reference_grams | measured_grams row per known weight
+func calibrateScale(machineID, zeroGrams string, table *godog.Table, at *time.Time) error {
+	key := testContext.DeviceKeys[machineID]
+	if key == "" {
+		return fmt.Errorf("no key was issued in this scenario for device %s", machineID)
+	}
+	zero, err := strconv.ParseFloat(zeroGrams, 64)
+	if err != nil {
+		return fmt.Errorf("invalid zero reading %q: %w", zeroGrams, err)
+	}
+
+	readings := []map[string]interface{}{}
+	for _, row := range table.Rows[1:] {
+		readings = append(readings, map[string]interface{}{
+			"reference_grams": parseCellFloat(table, row, "reference_grams"),
+			"measured_grams":  parseCellFloat(table, row, "measured_grams"),
+		})
+	}
+
+	body := map[string]interface{}{
+		"machine_id": machineID,
+		"zero_grams": zero,
+		"readings":   readings,
+	}
+	if at != nil {
+		body["calibrated_at"] = at.Format(time.RFC3339)
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/scale/calibrate", body, map[string]string{"X-Device-Key": key})
+}
+
+func iRequestTheScaleCalibrationsOfDevice(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+	return testContext.SendRequest("GET", "/api/v1/devices/"+deviceID+"/scale/calibrations", nil)
+}
@@ -0,0 +1,20 @@
+package test
+
+import "time"
+
+// Invoicing-specific step definitions
+
+func iGenerateAnInvoiceForCustomerForPeriod(customerID, period string) error {
+	invoice := map[string]interface{}{
+		"customer_id":  customerID,
+		"period":       period,
+		"company_name": "ACME GmbH",
+		"vat_id":       "DE123456789",
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/invoices", invoice)
+}
+
+func iGenerateAnInvoiceForCustomerForTheCurrentMonth(customerID string) error {
+	return iGenerateAnInvoiceForCustomerForPeriod(customerID, time.Now().UTC().Format("2006-01"))
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pricing-specific step definitions
+
+func iCreateAPriceExperimentForSKUWithVariantPrice(code string, priceCents int) error {
+	experiment := map[string]interface{}{
+		"name": "price test " + code,
+		"variants": []map[string]interface{}{
+			{"name": "control"},
+			{"name": "discount", "prices": map[string]int{code: priceCents}},
+		},
+		// A fresh cohort keeps runs from conflicting with earlier experiments
+		"device_ids": []string{uuid.NewString()},
+		"ends_at":    time.Now().Add(7 * 24 * time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/experiments", experiment)
+}
+
+func iCreateAPriceExperimentWithASingleVariantForSKU(code string) error {
+	experiment := map[string]interface{}{
+		"name": "single variant " + code,
+		"variants": []map[string]interface{}{
+			{"name": "discount", "prices": map[string]int{code: 100}},
+		},
+		"ends_at": time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/experiments", experiment)
+}
+
+func aCouponTakesPercentOff(name string, percent int) error {
+	return createCoupon(name, map[string]interface{}{"percent_off": percent})
+}
+
+func aSingleUseCouponTakesPercentOff(name string, percent int) error {
+	return createCoupon(name, map[string]interface{}{"percent_off": percent, "max_redemptions": 1})
+}
+
+func aCouponTakesCentsOffBasketsOfAtLeastCents(name string, amountCents, minCents int) error {
+	return createCoupon(name, map[string]interface{}{
+		"amount_off_cents": amountCents,
+		"min_basket_cents": minCents,
+		"currency":         "USD",
+	})
+}
+
+func iCreateACouponTakingPercentAndCentsOff(name string, percent, amountCents int) error {
+	return createCoupon(name, map[string]interface{}{
+		"percent_off":      percent,
+		"amount_off_cents": amountCents,
+		"currency":         "USD",
+	})
+}
+
+func iCreateACouponThatExpiredYesterday(name string) error {
+	return createCoupon(name, map[string]interface{}{
+		"percent_off": 10,
+		"expires_at":  time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+}
+
+func iDisableTheCoupon(name string) error {
+	couponID, ok := testContext.Coupons[name]
+	if !ok {
+		return fmt.Errorf("coupon %s was not created in this scenario", name)
+	}
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/coupons/%s/disable", couponID), nil)
+}
+
+func iFetchTheCoupon(name string) error {
+	couponID, ok := testContext.Coupons[name]
+	if !ok {
+		return fmt.Errorf("coupon %s was not created in this scenario", name)
+	}
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/coupons/%s", couponID), nil)
+}
+
+// createCoupon defines a coupon under a code of its own, as coupon codes
+// stay taken across runs
+func createCoupon(name string, coupon map[string]interface{}) error {
+	code := fmt.Sprintf("%s-%s", name, uuid.NewString()[:8])
+	coupon["code"] = code
+
+	if err := testContext.SendRequest("POST", "/api/v1/coupons", coupon); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 201 {
+		return nil
+	}
+
+	var created struct {
+		CouponID string `json:"coupon_id"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &created); err != nil {
+		return fmt.Errorf("failed to parse coupon: %w", err)
+	}
+	testContext.Coupons[name] = created.CouponID
+	testContext.CouponCodes[name] = code
+	return nil
+}
+
+// couponCode is the code created for a named coupon, or the name itself for
+// a coupon that was never created
+func couponCode(name string) string {
+	if code, ok := testContext.CouponCodes[name]; ok {
+		return code
+	}
+	return name
+}
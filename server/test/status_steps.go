@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Service status step definitions
+
+func theResponseHeaderShouldBe(name, expected string) error {
+	if actual := testContext.LastResponse.Header.Get(name); actual != expected {
+		return fmt.Errorf("header %s: expected %q, got %q", name, expected, actual)
+	}
+	return nil
+}
+
+func iRequestTheStatusAgainWithTheLastETag() error {
+	etag := testContext.LastResponse.Header.Get("ETag")
+	if etag == "" {
+		return fmt.Errorf("last response has no ETag")
+	}
+	return testContext.SendRequestWithHeaders("GET", "/api/v1/status", nil, map[string]string{
+		"If-None-Match": etag,
+	})
+}
+
+func operatorRaisesIncident(operator, level, component, message string) error {
+	incident := map[string]interface{}{
+		"component": component,
+		"level":     level,
+		"message":   message,
+	}
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/status/incidents", incident, map[string]string{
+		"X-Actor-ID": operator,
+	})
+}
+
+func operatorResolvesIncidentsOn(operator, component string) error {
+	if err := testContext.SendRequest("GET", "/api/v1/status/incidents", nil); err != nil {
+		return err
+	}
+	if testContext.LastResponse.StatusCode != 200 {
+		return fmt.Errorf("failed to list incidents: status %d", testContext.LastResponse.StatusCode)
+	}
+
+	var incidents []struct {
+		ID        string `json:"id"`
+		Component string `json:"component"`
+	}
+	if err := json.Unmarshal(testContext.LastBody, &incidents); err != nil {
+		return fmt.Errorf("failed to parse incidents: %w", err)
+	}
+
+	resolved := 0
+	for _, incident := range incidents {
+		if incident.Component != component {
+			continue
+		}
+		err := testContext.SendRequestWithHeaders("POST", "/api/v1/status/incidents/"+incident.ID+"/resolve", nil, map[string]string{
+			"X-Actor-ID": operator,
+		})
+		if err != nil {
+			return err
+		}
+		resolved++
+	}
+	if resolved == 0 {
+		return fmt.Errorf("no active incident on %s", component)
+	}
+	return nil
+}
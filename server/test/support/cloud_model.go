@@ -0,0 +1,38 @@
+package support
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// CloudModelVersion is the model version the test cloud model reports
+const CloudModelVersion = "test-cloud-model"
+
+// CloudModel stands in for the cloud detection model of the test server: it
+// finds on the images of a device what a scenario says it sees there, and is
+// unavailable on the devices no scenario told it about
+var CloudModel = &cloudModel{detections: make(map[string][]ports.CloudDetection)}
+
+type cloudModel struct {
+	mu         sync.Mutex
+	detections map[string][]ports.CloudDetection
+}
+
+// Sees makes the model find the detections on every image of the device
+func (m *cloudModel) Sees(deviceID string, detections []ports.CloudDetection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.detections[deviceID] = detections
+}
+
+func (m *cloudModel) DetectSession(ctx context.Context, deviceID string, image []byte) (ports.SessionDetectionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	detections, ok := m.detections[deviceID]
+	if !ok {
+		return ports.SessionDetectionResult{}, ports.ErrCloudDetectionUnavailable
+	}
+	return ports.SessionDetectionResult{Detections: detections, ModelVersion: CloudModelVersion}, nil
+}
@@ -0,0 +1,53 @@
+package support
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// Fiscalizer stands in for the fiscalization system of the test server: it
+// numbers the sales of the devices a scenario put in a country requiring
+// fiscal receipts, and leaves the sales of every other device unsigned
+var Fiscalizer = &fiscalizer{devices: make(map[string]bool), issued: make(map[string]*ports.FiscalSignature)}
+
+type fiscalizer struct {
+	mu      sync.Mutex
+	devices map[string]bool
+	issued  map[string]*ports.FiscalSignature // by session, as the port requires
+	counter int64
+}
+
+// Requires makes the sales of the device need a fiscal receipt
+func (f *fiscalizer) Requires(deviceID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.devices[deviceID] = true
+}
+
+func (f *fiscalizer) Fiscalize(ctx context.Context, receipt ports.FiscalReceipt) (*ports.FiscalSignature, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.devices[receipt.DeviceID] {
+		return nil, nil
+	}
+	if sig, ok := f.issued[receipt.SessionID]; ok {
+		return sig, nil
+	}
+
+	f.counter++
+	sig := &ports.FiscalSignature{
+		Country:          "DE",
+		Provider:         "test-tse",
+		DocumentNumber:   fmt.Sprintf("TSE-%d", f.counter),
+		SerialNumber:     "test-serial",
+		Signature:        fmt.Sprintf("sig-%s", receipt.SessionID),
+		SignatureCounter: f.counter,
+		IssuedAt:         time.Now().UTC().Truncate(time.Second),
+	}
+	f.issued[receipt.SessionID] = sig
+	return sig, nil
+}
@@ -0,0 +1,155 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+)
+
+// DeclinedWalletToken is a wallet token the test payment provider declines
+const DeclinedWalletToken = "tok_declined"
+
+// PaymentProvider stands in for the payment provider of the test server. It
+// is unavailable, like a deployment without one, until a scenario makes it
+// available; each test server starts with it unavailable again. Payments
+// happen between the customer's app and the provider, so scenarios settle
+// an intent through it rather than through the server.
+var PaymentProvider = &paymentProvider{}
+
+type paymentProvider struct {
+	mu        sync.Mutex
+	available bool
+	intents   map[string]*ports.PaymentIntent
+	sessions  map[string]string // session ID -> intent ID
+	counter   int
+}
+
+// reset makes the provider unavailable and forgets its intents
+func (p *paymentProvider) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.available = false
+	p.intents = make(map[string]*ports.PaymentIntent)
+	p.sessions = make(map[string]string)
+}
+
+// MakeAvailable lets the test server take payments through the provider
+func (p *paymentProvider) MakeAvailable() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.available = true
+}
+
+// IntentOf returns a copy of the intent opened for the session
+func (p *paymentProvider) IntentOf(sessionID string) (ports.PaymentIntent, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.intents[p.sessions[sessionID]]
+	if !ok {
+		return ports.PaymentIntent{}, false
+	}
+	return *intent, true
+}
+
+// Settle moves the session's intent to the status, for the amount the
+// customer paid; a captured or processing intent was paid by card
+func (p *paymentProvider) Settle(sessionID string, status ports.PaymentIntentStatus, amountCents int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.intents[p.sessions[sessionID]]
+	if !ok {
+		return fmt.Errorf("no payment intent for session %s", sessionID)
+	}
+	intent.Status = status
+	intent.AmountCents = amountCents
+	if status == ports.PaymentIntentSucceeded || status == ports.PaymentIntentProcessing {
+		intent.Card = &ports.CardDetails{Brand: "visa", Last4: "4242"}
+	}
+	return nil
+}
+
+func (p *paymentProvider) Enabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.available
+}
+
+func (p *paymentProvider) CreateIntent(ctx context.Context, sessionID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.available {
+		return nil, ports.ErrPaymentGatewayDisabled
+	}
+
+	p.counter++
+	id := fmt.Sprintf("pi_test_%d", p.counter)
+	intent := &ports.PaymentIntent{
+		ID:           id,
+		ClientSecret: id + "_secret",
+		AmountCents:  amountCents,
+		Currency:     strings.ToUpper(currency),
+		Status:       ports.PaymentIntentRequiresPaymentMethod,
+	}
+	p.intents[id] = intent
+	p.sessions[sessionID] = id
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *paymentProvider) UpdateIntentAmount(ctx context.Context, intentID string, amountCents int64, currency string) (*ports.PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, err := p.intent(intentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.IsCaptured() {
+		return nil, errors.New("a captured payment intent cannot be updated")
+	}
+	intent.AmountCents = amountCents
+	intent.Currency = strings.ToUpper(currency)
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *paymentProvider) GetIntent(ctx context.Context, intentID string) (*ports.PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, err := p.intent(intentID)
+	if err != nil {
+		return nil, err
+	}
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *paymentProvider) ConfirmWithWalletToken(ctx context.Context, intentID, wallet, token string) (*ports.PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, err := p.intent(intentID)
+	if err != nil {
+		return nil, err
+	}
+	if token == DeclinedWalletToken {
+		return nil, errors.New("card declined")
+	}
+	intent.Status = ports.PaymentIntentSucceeded
+	intent.Card = &ports.CardDetails{Wallet: wallet, Brand: "visa", Last4: "4242"}
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *paymentProvider) intent(intentID string) (*ports.PaymentIntent, error) {
+	if !p.available {
+		return nil, ports.ErrPaymentGatewayDisabled
+	}
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, fmt.Errorf("no such payment intent: %s", intentID)
+	}
+	return intent, nil
+}
@@ -0,0 +1,82 @@
+package support
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/transaction/domain"
+	transactioninfra "github.com/vending-machine/server/internal/transaction/infra"
+)
+
+// SessionRaces lets a scenario have another request save a session while the
+// request under test holds a copy of it: the next save of a session it was
+// armed for finds the session saved since it was loaded. Each test server
+// starts with nothing armed.
+var SessionRaces = &sessionRaces{}
+
+type sessionRaces struct {
+	mu    sync.Mutex
+	armed map[string]bool
+}
+
+// reset disarms every session
+func (r *sessionRaces) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.armed = make(map[string]bool)
+}
+
+// RaceNextSave makes the next save of the session lose to another one
+func (r *sessionRaces) RaceNextSave(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.armed[sessionID] = true
+}
+
+func (r *sessionRaces) take(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	armed := r.armed[sessionID]
+	delete(r.armed, sessionID)
+	return armed
+}
+
+// racingSessionRepository is the session repository of the test server; it
+// saves a session armed in SessionRaces once more, as the other request
+// would, right before the save under test
+type racingSessionRepository struct {
+	*transactioninfra.PostgresSessionRepository
+	pool *pgxpool.Pool
+}
+
+func newRacingSessionRepository(pool *pgxpool.Pool) *racingSessionRepository {
+	return &racingSessionRepository{
+		PostgresSessionRepository: transactioninfra.NewPostgresSessionRepository(pool),
+		pool:                      pool,
+	}
+}
+
+func (r *racingSessionRepository) Save(ctx context.Context, s *domain.Session) error {
+	if err := r.race(ctx, s); err != nil {
+		return err
+	}
+	return r.PostgresSessionRepository.Save(ctx, s)
+}
+
+func (r *racingSessionRepository) SaveCompleted(ctx context.Context, s *domain.Session, txn *domain.Transaction) error {
+	if err := r.race(ctx, s); err != nil {
+		return err
+	}
+	return r.PostgresSessionRepository.SaveCompleted(ctx, s, txn)
+}
+
+func (r *racingSessionRepository) race(ctx context.Context, s *domain.Session) error {
+	if !SessionRaces.take(s.ID().String()) {
+		return nil
+	}
+	_, err := postgres.Conn(ctx, r.pool).Exec(ctx, `UPDATE sessions SET version = version + 1 WHERE id = $1`, s.ID().String())
+	return err
+}
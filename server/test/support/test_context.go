@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -28,23 +30,54 @@ type TestContext struct {
 	LastBody     []byte
 
 	// Test data storage
-	CreatedSKUs     map[string]string // code -> id
-	CreatedDevices  map[string]string // machine_id -> id
-	CreatedSessions map[string]string // label -> session_id
+	CreatedSKUs       map[string]string // code -> id
+	CreatedCategories map[string]string // name -> id
+	CreatedDevices    map[string]string // machine_id -> id
+	DeviceKeys        map[string]string // machine_id -> key issued at registration
+	EnrollmentTokens  map[string]string // machine_id -> enrollment token
+	DeviceGroups      map[string]string // name -> device group id
+	CreatedSessions   map[string]string // label -> session_id
+	StreamTokens      map[string]string // session_id -> live stream token
+	OfflineBatch      interface{}       // last offline sync request, for resending
+	SyncCursor        string            // cursor of the last device SKU sync
+	TopicOffset       int64             // offset a scenario reads event topics after
+	TrainingImages    map[string]string // name -> training image id
+	Releases          map[string]string // version -> release id
+	Rollouts          map[string]string // release version -> id of its latest rollout
+	DeviceCommands    map[string]string // command kind -> id of the last one issued
+	RestockSession    string            // id of the last restock session started
+	Coupons           map[string]string // name -> coupon id
+	CouponCodes       map[string]string // name -> code created for it, unique across runs
 }
 
 // NewTestContext creates a new test context
 func NewTestContext() *TestContext {
 	return &TestContext{
-		Client:          &http.Client{},
-		CreatedSKUs:     make(map[string]string),
-		CreatedDevices:  make(map[string]string),
-		CreatedSessions: make(map[string]string),
+		Client:            &http.Client{},
+		CreatedSKUs:       make(map[string]string),
+		CreatedCategories: make(map[string]string),
+		CreatedDevices:    make(map[string]string),
+		DeviceKeys:        make(map[string]string),
+		EnrollmentTokens:  make(map[string]string),
+		DeviceGroups:      make(map[string]string),
+		CreatedSessions:   make(map[string]string),
+		StreamTokens:      make(map[string]string),
+		TrainingImages:    make(map[string]string),
+		Releases:          make(map[string]string),
+		Rollouts:          make(map[string]string),
+		DeviceCommands:    make(map[string]string),
+		Coupons:           make(map[string]string),
+		CouponCodes:       make(map[string]string),
 	}
 }
 
 // SendRequest sends an HTTP request and stores the response
 func (tc *TestContext) SendRequest(method, path string, body interface{}) error {
+	return tc.SendRequestWithHeaders(method, path, body, nil)
+}
+
+// SendRequestWithHeaders sends an HTTP request with extra headers and stores the response
+func (tc *TestContext) SendRequestWithHeaders(method, path string, body interface{}, headers map[string]string) error {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -63,7 +96,58 @@ func (tc *TestContext) SendRequest(method, path string, body interface{}) error
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	return tc.do(req)
+}
+
+// SendFile uploads data as a multipart form file and stores the response
+func (tc *TestContext) SendFile(method, path, field, filename string, data []byte) error {
+	return tc.SendFileWithFields(method, path, field, filename, data, nil)
+}
 
+// SendFileWithFields uploads data as a multipart form file along with plain
+// form fields and stores the response
+func (tc *TestContext) SendFileWithFields(method, path, field, filename string, data []byte, fields map[string]string) error {
+	return tc.SendFileWithHeaders(method, path, field, filename, data, fields, nil)
+}
+
+// SendFileWithHeaders uploads data as a multipart form file along with plain
+// form fields and extra headers and stores the response
+func (tc *TestContext) SendFileWithHeaders(method, path, field, filename string, data []byte, fields, headers map[string]string) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req, err := http.NewRequest(method, tc.Server.URL+path, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	return tc.do(req)
+}
+
+func (tc *TestContext) do(req *http.Request) error {
 	tc.LastRequest = req
 
 	resp, err := tc.Client.Do(req)
@@ -88,20 +172,27 @@ func (tc *TestContext) GetResponseJSON() (map[string]interface{}, error) {
 }
 
 // GetNestedField retrieves a nested field from the response using dot notation
-// e.g., "session.status" returns response["session"]["status"]
+// e.g., "session.status" returns response["session"]["status"]; list
+// responses are indexed from the top, e.g. "0.sku_code"
 func (tc *TestContext) GetNestedField(path string) (interface{}, error) {
-	response, err := tc.GetResponseJSON()
-	if err != nil {
-		return nil, err
+	var current interface{}
+	if err := json.Unmarshal(tc.LastBody, &current); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	parts := strings.Split(path, ".")
-	var current interface{} = response
 
 	for _, part := range parts {
-		if m, ok := current.(map[string]interface{}); ok {
-			current = m[part]
-		} else {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("index %s out of range in path %s", part, path)
+			}
+			current = node[i]
+		default:
 			return nil, fmt.Errorf("field %s not found in path %s", part, path)
 		}
 	}
@@ -129,8 +220,21 @@ func (tc *TestContext) CleanDatabase() error {
 
 	// Clear test data maps
 	tc.CreatedSKUs = make(map[string]string)
+	tc.CreatedCategories = make(map[string]string)
 	tc.CreatedDevices = make(map[string]string)
+	tc.DeviceKeys = make(map[string]string)
+	tc.EnrollmentTokens = make(map[string]string)
+	tc.DeviceGroups = make(map[string]string)
 	tc.CreatedSessions = make(map[string]string)
+	tc.SyncCursor = ""
+	tc.TopicOffset = 0
+	tc.TrainingImages = make(map[string]string)
+	tc.Releases = make(map[string]string)
+	tc.Rollouts = make(map[string]string)
+	tc.DeviceCommands = make(map[string]string)
+	tc.RestockSession = ""
+	tc.Coupons = make(map[string]string)
+	tc.CouponCodes = make(map[string]string)
 
 	return nil
 }
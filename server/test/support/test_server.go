@@ -3,6 +3,9 @@ package support
 import (
 	"context"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -10,67 +13,426 @@ import (
 	catalogapi "github.com/vending-machine/server/internal/catalog/api"
 	catalogapp "github.com/vending-machine/server/internal/catalog/app"
 	cataloginfra "github.com/vending-machine/server/internal/catalog/infra"
+	catalogadapters "github.com/vending-machine/server/internal/catalog/infra/adapters"
 
 	// Device context
 	deviceapi "github.com/vending-machine/server/internal/device/api"
 	deviceapp "github.com/vending-machine/server/internal/device/app"
 	deviceinfra "github.com/vending-machine/server/internal/device/infra"
+	deviceadapters "github.com/vending-machine/server/internal/device/infra/adapters"
+
+	// Invoicing context
+	invoicingapp "github.com/vending-machine/server/internal/invoicing/app"
+	invoicinginfra "github.com/vending-machine/server/internal/invoicing/infra"
+	invoicingadapters "github.com/vending-machine/server/internal/invoicing/infra/adapters"
+
+	// Pricing context
+	pricingapi "github.com/vending-machine/server/internal/pricing/api"
+	pricingapp "github.com/vending-machine/server/internal/pricing/app"
+	pricinginfra "github.com/vending-machine/server/internal/pricing/infra"
+	pricingadapters "github.com/vending-machine/server/internal/pricing/infra/adapters"
 
 	// Transaction context
+	transactionapi "github.com/vending-machine/server/internal/transaction/api"
 	transactionapp "github.com/vending-machine/server/internal/transaction/app"
+	transactiondomain "github.com/vending-machine/server/internal/transaction/domain"
 	transactioninfra "github.com/vending-machine/server/internal/transaction/infra"
 	transactionadapters "github.com/vending-machine/server/internal/transaction/infra/adapters"
 
 	// Platform
+	"github.com/vending-machine/server/internal/platform/cache"
+	"github.com/vending-machine/server/internal/platform/exchangerate"
 	platformhttp "github.com/vending-machine/server/internal/platform/http"
 	"github.com/vending-machine/server/internal/platform/messaging"
+	"github.com/vending-machine/server/internal/platform/objectstore"
+	"github.com/vending-machine/server/internal/platform/postgres"
+	"github.com/vending-machine/server/internal/platform/qrtoken"
+	"github.com/vending-machine/server/internal/platform/region"
+	"github.com/vending-machine/server/internal/platform/status"
+	"github.com/vending-machine/server/internal/platform/webhook"
+
+	// Shared
+	"github.com/vending-machine/server/internal/shared/currency"
+	"github.com/vending-machine/server/internal/shared/policy"
 )
 
+// TaxedCategoryID is the only category the test server charges tax on (10%),
+// so scenarios that do not use it keep untaxed totals
+const TaxedCategoryID = "7a1c0e52-3f0b-4d7e-9c41-5b8e2f6d9a10"
+
+// CatalogWebhookSecret signs the catalog webhook deliveries of the tests
+const CatalogWebhookSecret = "test-catalog-webhook-secret"
+
+// PaymentWebhookSecret signs the payment provider events of the tests
+const PaymentWebhookSecret = "test-payment-webhook-secret"
+
+// FraudRules are the fraud rules of the test server: a detection more than
+// 5kg off its measured weight is blocked and a user with two cancellations
+// within the hour is flagged
+const FraudRules = "weight_deviation=5000:block,repeated_cancellations=2/1h:flag"
+
+// SessionLifetime is how long sessions of the test server last: 30 minutes
+// unless their device sets otherwise, extended to 2 hours at most
+var SessionLifetime = transactiondomain.SessionLifetime{Default: 30 * time.Minute, Max: 2 * time.Hour}
+
+// DetectionReconciliation is how the test server reconciles the device's and
+// the cloud model's detections: boxes overlapping by half are one object
+const DetectionReconciliation = "iou=0.5"
+
+// WalkawayPolicy gives customers of the test server a minute to pay after
+// the device signalled they left
+var WalkawayPolicy = transactionapp.WalkawayPolicy{CancelAfter: time.Minute}
+
+// ExchangeRates are the units of each currency one US dollar buys in tests
+var ExchangeRates = map[string]float64{"EUR": 0.8, "CHF": 0.9}
+
 // StartTestServer creates and starts a test HTTP server with all dependencies wired
 func StartTestServer(pool *pgxpool.Pool) *httptest.Server {
 	// Shared infrastructure
-	eventPublisher := messaging.NewNoOpEventPublisher()
+	eventTopic := messaging.NewPostgresTopic(pool)
+	eventPublisher := messaging.NewInProcessBroker(messaging.NewTopicPublisher(messaging.NewNoOpEventPublisher(), eventTopic, catalogapi.EncodeEvent))
+	qrTokenSigner := qrtoken.NewSigner([]byte("test-secret"), 5*time.Minute)
+	sessionStreamSigner := qrtoken.NewSigner([]byte("test-stream-secret"), SessionLifetime.Max)
+	regionConfig := region.Config{
+		Current:   "eu",
+		Endpoints: map[string]string{"us": "https://us.api.example.com"},
+	}
 
 	// =========================================================================
 	// Catalog Bounded Context
 	// =========================================================================
 	skuRepo := cataloginfra.NewPostgresSKURepository(pool)
-	skuReader := catalogapi.NewSKUReaderAdapter(skuRepo)
-	createSKUHandler := catalogapp.NewCreateSKUHandler(skuRepo, eventPublisher)
-	skuQueryService := catalogapp.NewSKUQueryService(skuRepo)
-	catalogHandler := cataloginfra.NewHTTPHandler(createSKUHandler, skuQueryService)
+	categoryRepo := cataloginfra.NewPostgresCategoryRepository(pool)
+	trainingImageRepo := cataloginfra.NewPostgresTrainingImageRepository(pool)
+	externalLinkRepo := cataloginfra.NewPostgresExternalLinkRepository(pool)
+	skuClassRepo := cataloginfra.NewPostgresSKUClassRepository(pool)
+	skuReader := catalogapi.NewCachedSKUReader(catalogapi.NewSKUReaderAdapter(skuRepo), cache.NewMemoryStore(), time.Minute)
+	catalogPublisher := skuReader.Invalidating(eventPublisher)
+	deviceSyncReader := cataloginfra.NewPostgresDeviceSyncReader(pool)
+	createSKUHandler := catalogapp.NewCreateSKUHandler(skuRepo, categoryRepo, catalogPublisher)
+	updateSKUHandler := catalogapp.NewUpdateSKUHandler(skuRepo, catalogPublisher)
+	skuLifecycleHandler := catalogapp.NewChangeSKULifecycleHandler(skuRepo, catalogPublisher)
+	deleteSKUHandler := catalogapp.NewDeleteSKUHandler(skuRepo, catalogPublisher)
+	restoreSKUHandler := catalogapp.NewRestoreSKUHandler(skuRepo, catalogPublisher)
+	importSKUsHandler := catalogapp.NewImportSKUsHandler(skuRepo, categoryRepo, catalogPublisher)
+	createBundleHandler := catalogapp.NewCreateBundleHandler(skuRepo, catalogPublisher)
+	recordWeightSamplesHandler := catalogapp.NewRecordWeightSamplesHandler(skuRepo, catalogPublisher)
+	skuQueryService := catalogapp.NewSKUQueryService(skuRepo, categoryRepo)
+	createCategoryHandler := catalogapp.NewCreateCategoryHandler(categoryRepo, catalogPublisher)
+	updateCategoryHandler := catalogapp.NewUpdateCategoryHandler(categoryRepo, catalogPublisher)
+	deleteCategoryHandler := catalogapp.NewDeleteCategoryHandler(categoryRepo, catalogPublisher)
+	assignSKUCategoryHandler := catalogapp.NewAssignSKUCategoryHandler(skuRepo, categoryRepo, catalogPublisher)
+	categoryQueryService := catalogapp.NewCategoryQueryService(categoryRepo)
+	objectStore := objectstore.NewFileStore(filepath.Join(os.TempDir(), "vending-test-objects"))
+	addTrainingImageHandler := catalogapp.NewAddTrainingImageHandler(skuRepo, trainingImageRepo, objectStore)
+	deleteTrainingImageHandler := catalogapp.NewDeleteTrainingImageHandler(trainingImageRepo, objectStore)
+	trainingImageQueryService := catalogapp.NewTrainingImageQueryService(skuRepo, trainingImageRepo, objectStore)
+	syncClassesHandler := catalogapp.NewSyncClassesHandler(skuRepo, skuClassRepo, catalogadapters.NewDisabledClassSyncer())
+	assignClassHandler := catalogapp.NewAssignClassHandler(skuRepo, skuClassRepo, catalogPublisher)
+	reassignClassHandler := catalogapp.NewReassignClassHandler(skuRepo, skuClassRepo, catalogPublisher)
+	removeClassHandler := catalogapp.NewRemoveClassHandler(skuClassRepo, catalogPublisher)
+	classQueryService := catalogapp.NewClassQueryService(skuRepo, skuClassRepo)
+	syncExternalProductsHandler := catalogapp.NewSyncExternalProductsHandler(skuRepo, externalLinkRepo, catalogPublisher)
+	catalogWebhookVerifier := webhook.NewVerifier([]byte(CatalogWebhookSecret), 5*time.Minute)
+	catalogHandler := cataloginfra.NewHTTPHandler(
+		createSKUHandler, updateSKUHandler, skuLifecycleHandler, deleteSKUHandler, restoreSKUHandler, importSKUsHandler, createBundleHandler, recordWeightSamplesHandler, skuQueryService,
+		addTrainingImageHandler, deleteTrainingImageHandler, trainingImageQueryService, syncClassesHandler,
+		assignClassHandler, reassignClassHandler, removeClassHandler, classQueryService,
+		syncExternalProductsHandler, catalogWebhookVerifier,
+		createCategoryHandler, updateCategoryHandler, deleteCategoryHandler, assignSKUCategoryHandler, categoryQueryService,
+	)
 
 	// =========================================================================
 	// Device Bounded Context
 	// =========================================================================
 	deviceRepo := deviceinfra.NewPostgresDeviceRepository(pool)
-	deviceReader := deviceapi.NewDeviceReaderAdapter(deviceRepo)
-	registerDeviceHandler := deviceapp.NewRegisterDeviceHandler(deviceRepo, eventPublisher)
-	deviceHandler := deviceinfra.NewHTTPHandler(registerDeviceHandler, skuReader)
+	enrollmentTokenRepo := deviceinfra.NewPostgresEnrollmentTokenRepository(pool)
+	salesHoursRepo := deviceinfra.NewPostgresSalesHoursRepository(pool)
+	stockBatchRepo := deviceinfra.NewPostgresStockBatchRepository(pool)
+	inventoryRepo := deviceinfra.NewPostgresInventoryRepository(pool)
+	assortmentRepo := deviceinfra.NewPostgresAssortmentRepository(pool)
+	deviceGroupRepo := deviceinfra.NewPostgresDeviceGroupRepository(pool)
+	configShadowRepo := deviceinfra.NewPostgresConfigShadowRepository(pool)
+	releaseRepo := deviceinfra.NewPostgresReleaseRepository(pool)
+	rolloutRepo := deviceinfra.NewPostgresRolloutRepository(pool)
+	planogramRepo := deviceinfra.NewPostgresPlanogramRepository(pool)
+	policyResolver := deviceapp.NewPolicyResolver(deviceGroupRepo, planogramRepo)
+	enrollDeviceHandler := deviceapp.NewEnrollDeviceHandler(deviceRepo, enrollmentTokenRepo, eventPublisher, regionConfig.Current)
+	createEnrollmentTokenHandler := deviceapp.NewCreateEnrollmentTokenHandler(deviceRepo, enrollmentTokenRepo, time.Hour)
+	issueStartTokenHandler := deviceapp.NewIssueStartTokenHandler(deviceRepo, qrTokenSigner)
+	stockEstimateRepo := deviceinfra.NewPostgresStockEstimateRepository(pool)
+	submitShelfSnapshotHandler := deviceapp.NewSubmitShelfSnapshotHandler(deviceRepo, stockEstimateRepo, deviceadapters.NewDisabledShelfDetector(), eventPublisher, policyResolver, 0.5, 2)
+	stockQueryService := deviceapp.NewStockQueryService(deviceRepo, stockEstimateRepo, 2)
+	excursionRepo := deviceinfra.NewPostgresExcursionRepository(pool)
+	temperaturePolicy := deviceapp.TemperaturePolicy{MaxCelsius: 8, MaxDuration: 30 * time.Minute}
+	doorPolicy := deviceapp.DoorPolicy{CloseGrace: 2 * time.Minute}
+	clearDeviceHandler := deviceapp.NewClearDeviceHandler(deviceRepo, excursionRepo, eventPublisher)
+	excursionQueryService := deviceapp.NewExcursionQueryService(deviceRepo, excursionRepo)
+	incidentRepo := deviceinfra.NewPostgresIncidentRepository(pool)
+	deviceEventRepo := deviceinfra.NewPostgresDeviceEventRepository(pool)
+	deviceCommandRepo := deviceinfra.NewPostgresDeviceCommandRepository(pool)
+	incidentQueryService := deviceapp.NewIncidentQueryService(deviceRepo, incidentRepo)
+	complianceReportRepo := deviceinfra.NewPostgresComplianceReportRepository(pool)
+	assignPlanogramHandler := deviceapp.NewAssignPlanogramHandler(deviceRepo, planogramRepo, eventPublisher)
+	recordRestockVisitHandler := deviceapp.NewRecordRestockVisitHandler(deviceRepo, policyResolver, complianceReportRepo, deviceadapters.NewDisabledShelfDetector(), eventPublisher, 0.5)
+	planogramQueryService := deviceapp.NewPlanogramQueryService(deviceRepo, policyResolver, complianceReportRepo)
+	setSalesHoursHandler := deviceapp.NewSetSalesHoursHandler(deviceRepo, salesHoursRepo, eventPublisher)
+	salesHoursQueryService := deviceapp.NewSalesHoursQueryService(deviceRepo, salesHoursRepo)
+	markdownPolicy, _ := policy.ParseMarkdownPolicy("2=25,0=50")
+	recordBatchesHandler := deviceapp.NewRecordBatchesHandler(deviceRepo, stockBatchRepo, deviceadapters.NewCatalogAdapter(skuReader), eventPublisher, markdownPolicy)
+	restockInventoryHandler := deviceapp.NewRestockInventoryHandler(deviceRepo, inventoryRepo, deviceadapters.NewCatalogAdapter(skuReader), eventPublisher, 3)
+	inventoryQueryService := deviceapp.NewInventoryQueryService(deviceRepo, inventoryRepo, 3)
+	assignSKUsHandler := deviceapp.NewAssignSKUsHandler(deviceRepo, assortmentRepo, deviceadapters.NewCatalogAdapter(skuReader), eventPublisher)
+	unassignSKUHandler := deviceapp.NewUnassignSKUHandler(deviceRepo, assortmentRepo, eventPublisher)
+	assortmentQueryService := deviceapp.NewAssortmentQueryService(deviceRepo, assortmentRepo)
+	recordHeartbeatHandler := deviceapp.NewRecordHeartbeatHandler(deviceRepo, rolloutRepo, releaseRepo)
+	issueDeviceKeyHandler := deviceapp.NewIssueDeviceKeyHandler(deviceRepo)
+	deviceQueryService := deviceapp.NewDeviceQueryService(deviceRepo, 5*time.Minute)
+	decommissionDeviceHandler := deviceapp.NewDecommissionDeviceHandler(deviceRepo, excursionRepo, incidentRepo, eventPublisher)
+	enterMaintenanceHandler := deviceapp.NewEnterMaintenanceHandler(deviceRepo, eventPublisher)
+	exitMaintenanceHandler := deviceapp.NewExitMaintenanceHandler(deviceRepo, eventPublisher)
+	recordDeviceEventsHandler := deviceapp.NewRecordDeviceEventsHandler(deviceRepo, deviceEventRepo, eventPublisher)
+	deviceEventQueryService := deviceapp.NewDeviceEventQueryService(deviceRepo, deviceEventRepo)
+	issueDeviceCommandHandler := deviceapp.NewIssueDeviceCommandHandler(deviceRepo, deviceCommandRepo, eventPublisher)
+	deviceCommandDelivery := deviceapp.NewDeviceCommandDelivery(deviceRepo, deviceCommandRepo, deviceadapters.NewDeviceCommandFeed(eventPublisher), time.Second)
+	acknowledgeDeviceCommandHandler := deviceapp.NewAcknowledgeDeviceCommandHandler(deviceRepo, deviceCommandRepo, eventPublisher)
+	deviceCommandQueryService := deviceapp.NewDeviceCommandQueryService(deviceRepo, deviceCommandRepo)
+	expectedModelQueryService := deviceapp.NewExpectedModelQueryService(deviceRepo, policyResolver)
+	detectOfflineDevicesHandler := deviceapp.NewDetectOfflineDevicesHandler(deviceRepo, eventPublisher, 5*time.Minute)
+	createDeviceGroupHandler := deviceapp.NewCreateDeviceGroupHandler(deviceGroupRepo)
+	setGroupPolicyHandler := deviceapp.NewSetGroupPolicyHandler(deviceGroupRepo, deviceRepo)
+	assignGroupDevicesHandler := deviceapp.NewAssignGroupDevicesHandler(deviceGroupRepo, deviceRepo)
+	removeGroupDeviceHandler := deviceapp.NewRemoveGroupDeviceHandler(deviceGroupRepo, deviceRepo)
+	setDevicePolicyHandler := deviceapp.NewSetDevicePolicyHandler(deviceRepo, policyResolver)
+	deviceGroupQueryService := deviceapp.NewDeviceGroupQueryService(deviceGroupRepo, deviceRepo, policyResolver)
+	setDesiredConfigHandler := deviceapp.NewSetDesiredConfigHandler(deviceRepo, configShadowRepo, policyResolver, eventPublisher)
+	pullDeviceConfigHandler := deviceapp.NewPullDeviceConfigHandler(deviceRepo, configShadowRepo, policyResolver)
+	reportDeviceConfigHandler := deviceapp.NewReportDeviceConfigHandler(deviceRepo, configShadowRepo, policyResolver)
+	configQueryService := deviceapp.NewConfigQueryService(deviceRepo, configShadowRepo, policyResolver)
+	uploadReleaseHandler := deviceapp.NewUploadReleaseHandler(releaseRepo, objectStore)
+	downloadReleaseHandler := deviceapp.NewDownloadReleaseHandler(deviceRepo, releaseRepo, objectStore)
+	releaseQueryService := deviceapp.NewReleaseQueryService(releaseRepo)
+	startRolloutHandler := deviceapp.NewStartRolloutHandler(releaseRepo, deviceGroupRepo, rolloutRepo, deviceRepo, eventPublisher)
+	advanceRolloutHandler := deviceapp.NewAdvanceRolloutHandler(releaseRepo, rolloutRepo, deviceRepo, eventPublisher)
+	abortRolloutHandler := deviceapp.NewAbortRolloutHandler(releaseRepo, rolloutRepo, deviceRepo, eventPublisher)
+	rolloutQueryService := deviceapp.NewRolloutQueryService(releaseRepo, rolloutRepo, deviceRepo)
+	fleetHealthQueryService := deviceapp.NewFleetHealthQueryService(deviceinfra.NewPostgresFleetHealthReader(pool), 5*time.Minute)
+	deviceQRCodeHandler := deviceapp.NewDeviceQRCodeHandler(deviceRepo, deviceadapters.NewQRLabelRenderer(), "https://shop.example.com/m/{machine_id}")
+
+	// =========================================================================
+	// Pricing Bounded Context
+	// =========================================================================
+	experimentRepo := pricinginfra.NewPostgresExperimentRepository(pool)
+	experimentReader := pricingapi.NewExperimentReaderAdapter(experimentRepo)
+	createExperimentHandler := pricingapp.NewCreateExperimentHandler(experimentRepo, eventPublisher)
+	stopExperimentHandler := pricingapp.NewStopExperimentHandler(experimentRepo, eventPublisher)
+	couponRepo := pricinginfra.NewPostgresCouponRepository(pool)
+	couponReader := pricingapi.NewCouponReaderAdapter(couponRepo)
+	createCouponHandler := pricingapp.NewCreateCouponHandler(couponRepo, eventPublisher)
+	disableCouponHandler := pricingapp.NewDisableCouponHandler(couponRepo, eventPublisher)
 
 	// =========================================================================
 	// Transaction Bounded Context
 	// =========================================================================
-	sessionRepo := transactioninfra.NewPostgresSessionRepository(pool)
+	SessionRaces.reset()
+	sessionRepo := newRacingSessionRepository(pool)
+	transactionRepo := transactioninfra.NewPostgresTransactionRepository(pool)
+	unitOfWork := postgres.NewUnitOfWork(pool)
+	transactionOutbox := messaging.NewOutbox(eventTopic, transactionapi.EncodeEvent)
+	refundRepo := transactioninfra.NewPostgresRefundRepository(pool)
+	disputeRepo := transactioninfra.NewPostgresDisputeRepository(pool)
+	syncLogRepo := transactioninfra.NewPostgresSyncLogRepository(pool)
+	sessionImageRepo := transactioninfra.NewPostgresSessionImageRepository(pool)
+	processedDetectionRepo := transactioninfra.NewPostgresProcessedDetectionRepository(pool)
+	detectionLogRepo := transactioninfra.NewPostgresDetectionLogRepository(pool)
+	sessionNoteRepo := transactioninfra.NewPostgresSessionNoteRepository(pool)
+	reconciliationRepo := transactioninfra.NewPostgresReconciliationRepository(pool)
+	sessionQueryService := transactionapp.NewSessionQueryService(sessionRepo, sessionNoteRepo)
+	transactionQueryService := transactionapp.NewTransactionQueryService(transactionRepo)
+	sessionReader := transactionapi.NewSessionReaderAdapter(sessionQueryService, transactionQueryService)
+	deviceSales := deviceadapters.NewTransactionAdapter(sessionReader)
+	expiryQueryService := deviceapp.NewExpiryQueryService(deviceRepo, stockBatchRepo, salesHoursRepo, deviceSales, markdownPolicy)
+	writeOffBatchHandler := deviceapp.NewWriteOffBatchHandler(deviceRepo, stockBatchRepo, deviceSales, eventPublisher)
+	recordSaleHandler := deviceapp.NewRecordSaleHandler(inventoryRepo, deviceSales, eventPublisher, 3)
+	saleListener := deviceadapters.NewSaleListener(eventPublisher, recordSaleHandler)
+	deviceReader := deviceapi.NewDeviceReaderAdapter(deviceRepo, salesHoursRepo, expiryQueryService, assortmentRepo, policyResolver)
 	deviceAdapter := transactionadapters.NewDeviceAdapter(deviceReader)
 	catalogAdapter := transactionadapters.NewCatalogAdapter(skuReader)
-	startSessionHandler := transactionapp.NewStartSessionHandler(deviceAdapter, sessionRepo, eventPublisher)
-	submitDetectionHandler := transactionapp.NewSubmitDetectionHandler(sessionRepo, catalogAdapter, eventPublisher)
-	confirmSessionHandler := transactionapp.NewConfirmSessionHandler(sessionRepo, eventPublisher)
+	pricingAdapter := transactionadapters.NewPricingAdapter(experimentReader)
+	couponAdapter := transactionadapters.NewCouponAdapter(couponReader)
+	PaymentProvider.reset()
+	paymentGateway := PaymentProvider
+	refundPolicy := transactiondomain.NewRefundPolicy(2000)
+	roundingPolicy, _ := policy.ParseRoundingPolicy("CHF=5")
+	taxPolicy, _ := policy.ParseTaxPolicy("category:"+TaxedCategoryID+"=10", false)
+	taxAssessor := transactionapp.NewTaxAssessor(catalogAdapter, deviceAdapter, taxPolicy)
+	recommender := transactioninfra.NewPostgresCoPurchaseRecommender(pool, 90*24*time.Hour)
+	currencyConverter := exchangerate.NewStaticConverter(currency.Rates{Base: "USD", PerBase: ExchangeRates})
+	startSessionHandler := transactionapp.NewStartSessionHandler(deviceAdapter, sessionRepo, qrTokenSigner, sessionStreamSigner, paymentGateway, pricingAdapter, eventPublisher, SessionLifetime, false)
+	fraudRules, _ := transactiondomain.ParseFraudRules(FraudRules)
+	fraudScreen := transactionapp.NewFraudScreen(sessionRepo, eventPublisher, fraudRules)
+	submitDetectionHandler := transactionapp.NewSubmitDetectionHandler(sessionRepo, sessionImageRepo, processedDetectionRepo, detectionLogRepo, fraudScreen, catalogAdapter, deviceAdapter, paymentGateway, eventPublisher, roundingPolicy, taxAssessor, currencyConverter)
+	detectionReconciler, _ := transactionapp.ParseDetectionReconciler(DetectionReconciliation, 0.5)
+	verifySessionImageHandler := transactionapp.NewVerifySessionImageHandler(sessionRepo, CloudModel, submitDetectionHandler, detectionLogRepo, detectionReconciler)
+	couponChecker := transactionapp.NewCouponChecker(couponAdapter, transactionRepo)
+	confirmSessionHandler := transactionapp.NewConfirmSessionHandler(sessionRepo, transactionRepo, deviceAdapter, paymentGateway, Fiscalizer, taxAssessor, roundingPolicy, unitOfWork, transactionOutbox, eventPublisher, fraudScreen, couponChecker, false, false)
 	cancelSessionHandler := transactionapp.NewCancelSessionHandler(sessionRepo, eventPublisher)
-	sessionQueryService := transactionapp.NewSessionQueryService(sessionRepo)
+	cancelDeviceSessionHandler := transactionapp.NewCancelDeviceSessionHandler(sessionRepo, eventPublisher)
+	decommissionListener := transactionadapters.NewDecommissionListener(eventPublisher, cancelDeviceSessionHandler)
+	offlineListener := transactionadapters.NewOfflineListener(eventPublisher, cancelDeviceSessionHandler)
+	syncOfflineEntriesHandler := transactionapp.NewSyncOfflineEntriesHandler(sessionRepo, syncLogRepo, submitDetectionHandler, cancelSessionHandler)
+	payWithWalletHandler := transactionapp.NewPayWithWalletHandler(sessionRepo, paymentGateway)
+	createPaymentIntentHandler := transactionapp.NewCreatePaymentIntentHandler(sessionRepo, paymentGateway)
+	overrideWeightHandler := transactionapp.NewOverrideWeightHandler(sessionRepo, submitDetectionHandler, eventPublisher)
+	applyCouponHandler := transactionapp.NewApplyCouponHandler(sessionRepo, couponChecker, taxAssessor, paymentGateway, roundingPolicy, eventPublisher)
+	reviewItemHandler := transactionapp.NewReviewItemHandler(sessionRepo, catalogAdapter, submitDetectionHandler, eventPublisher)
+	resumeSessionHandler := transactionapp.NewResumeSessionHandler(deviceAdapter, sessionQueryService, sessionStreamSigner)
+	signalWalkawayHandler := transactionapp.NewSignalWalkawayHandler(sessionRepo, WalkawayPolicy)
+	cancelWalkawaysHandler := transactionapp.NewCancelWalkawaysHandler(sessionRepo, eventPublisher, WalkawayPolicy)
+	archiveSessionsHandler := transactionapp.NewArchiveSessionsHandler(sessionRepo, transactionapp.ArchivePolicy{After: 90 * 24 * time.Hour})
+	extendSessionHandler := transactionapp.NewExtendSessionHandler(sessionRepo, deviceAdapter, SessionLifetime, eventPublisher)
+	processPaymentEventHandler := transactionapp.NewProcessPaymentEventHandler(sessionRepo, confirmSessionHandler, eventPublisher)
+	applePayMerchantHandler := transactionapp.NewValidateApplePayMerchantHandler(transactionadapters.NewDisabledApplePayMerchantValidator())
+	requestRefundHandler := transactionapp.NewRequestRefundHandler(sessionRepo, transactionRepo, refundRepo, refundPolicy, roundingPolicy, unitOfWork, eventPublisher)
+	decideRefundHandler := transactionapp.NewDecideRefundHandler(refundRepo, eventPublisher)
+	refundQueryService := transactionapp.NewRefundQueryService(refundRepo)
+	addSessionNoteHandler := transactionapp.NewAddSessionNoteHandler(sessionRepo, sessionNoteRepo)
+	openDisputeHandler := transactionapp.NewOpenDisputeHandler(sessionRepo, transactionRepo, disputeRepo, eventPublisher)
+	annotateDisputeHandler := transactionapp.NewAnnotateDisputeHandler(disputeRepo)
+	attachDisputeEvidenceHandler := transactionapp.NewAttachDisputeEvidenceHandler(disputeRepo, detectionLogRepo, sessionImageRepo)
+	resolveDisputeHandler := transactionapp.NewResolveDisputeHandler(disputeRepo, eventPublisher)
+	disputeQueryService := transactionapp.NewDisputeQueryService(disputeRepo, sessionImageRepo)
+	recommendationService := transactionapp.NewRecommendationService(sessionRepo, recommender, catalogAdapter)
+	sessionStreamService := transactionapp.NewSessionStreamService(sessionQueryService, sessionStreamSigner, transactionadapters.NewSessionChangeFeed(eventPublisher), time.Second)
+	reconcileSessionsHandler := transactionapp.NewReconcileSessionsHandler(sessionRepo, sessionImageRepo, reconciliationRepo, catalogAdapter, CloudModel, eventPublisher, 0.5)
+	itemLocalizer := transactionapp.NewItemLocalizer(catalogAdapter)
+	detectionLogQueryService := transactionapp.NewDetectionLogQueryService(sessionRepo, detectionLogRepo)
+	reconciliationQueryService := transactionapp.NewReconciliationQueryService(reconciliationRepo)
+	paymentWebhookVerifier := webhook.NewVerifier([]byte(PaymentWebhookSecret), 5*time.Minute)
 	transactionHandler := transactioninfra.NewHTTPHandler(
 		startSessionHandler,
 		submitDetectionHandler,
+		verifySessionImageHandler,
 		confirmSessionHandler,
 		cancelSessionHandler,
+		payWithWalletHandler,
+		createPaymentIntentHandler,
+		applePayMerchantHandler,
+		requestRefundHandler,
+		decideRefundHandler,
 		sessionQueryService,
+		refundQueryService,
+		recommendationService,
+		sessionStreamService,
+		syncOfflineEntriesHandler,
+		reconcileSessionsHandler,
+		reconciliationQueryService,
+		itemLocalizer,
+		detectionLogQueryService,
+		processPaymentEventHandler,
+		paymentWebhookVerifier,
+		overrideWeightHandler,
+		applyCouponHandler,
+		extendSessionHandler,
+		reviewItemHandler,
+		resumeSessionHandler,
+		signalWalkawayHandler,
+		cancelWalkawaysHandler,
+		addSessionNoteHandler,
+		openDisputeHandler,
+		annotateDisputeHandler,
+		attachDisputeEvidenceHandler,
+		resolveDisputeHandler,
+		disputeQueryService,
+		transactionQueryService,
+		archiveSessionsHandler,
 	)
 
+	// =========================================================================
+	// Invoicing Bounded Context
+	// =========================================================================
+	invoiceRepo := invoicinginfra.NewPostgresInvoiceRepository(pool)
+	transactionSource := invoicingadapters.NewTransactionAdapter(sessionReader)
+	invoiceRenderer := invoicingadapters.NewPDFInvoiceRenderer(invoicingadapters.SellerDetails{Name: "Test Operator"})
+	invoiceMailer := invoicingadapters.NewDisabledInvoiceMailer()
+	generateInvoiceHandler := invoicingapp.NewGenerateInvoiceHandler(invoiceRepo, transactionSource, eventPublisher, 1900)
+	sendInvoiceHandler := invoicingapp.NewSendInvoiceHandler(invoiceRepo, invoiceRenderer, invoiceMailer, eventPublisher)
+	invoiceQueryService := invoicingapp.NewInvoiceQueryService(invoiceRepo, invoiceRenderer)
+	invoicingHandler := invoicinginfra.NewHTTPHandler(generateInvoiceHandler, sendInvoiceHandler, invoiceQueryService)
+
+	// Pricing experiment results and coupon redemptions read session outcomes
+	outcomeSource := pricingadapters.NewTransactionAdapter(sessionReader)
+	experimentQueryService := pricingapp.NewExperimentQueryService(experimentRepo, outcomeSource)
+	couponQueryService := pricingapp.NewCouponQueryService(couponRepo, outcomeSource)
+	pricingHandler := pricinginfra.NewHTTPHandler(createExperimentHandler, stopExperimentHandler, experimentQueryService, createCouponHandler, disableCouponHandler, couponQueryService)
+
+	// Device telemetry correlates door readings with session state
+	recordTelemetryHandler := deviceapp.NewRecordTelemetryHandler(deviceRepo, excursionRepo, incidentRepo, deviceSales, eventPublisher, temperaturePolicy, doorPolicy)
+
+	// Restock sessions check the transaction context for a customer session
+	// before keeping customers off the device
+	restockSessionRepo := deviceinfra.NewPostgresRestockSessionRepository(pool)
+	startRestockSessionHandler := deviceapp.NewStartRestockSessionHandler(deviceRepo, restockSessionRepo, deviceSales, eventPublisher)
+	recordRestockUnitsHandler := deviceapp.NewRecordRestockUnitsHandler(deviceRepo, restockSessionRepo, deviceadapters.NewCatalogAdapter(skuReader))
+	completeRestockSessionHandler := deviceapp.NewCompleteRestockSessionHandler(deviceRepo, restockSessionRepo, inventoryRepo, eventPublisher)
+	cancelRestockSessionHandler := deviceapp.NewCancelRestockSessionHandler(deviceRepo, restockSessionRepo, eventPublisher)
+	restockSessionQueryService := deviceapp.NewRestockSessionQueryService(deviceRepo, restockSessionRepo)
+
+	cameraCalibrationRepo := deviceinfra.NewPostgresCameraCalibrationRepository(pool)
+	uploadCameraCalibrationHandler := deviceapp.NewUploadCameraCalibrationHandler(deviceRepo, cameraCalibrationRepo, eventPublisher)
+	cameraCalibrationQueryService := deviceapp.NewCameraCalibrationQueryService(deviceRepo, cameraCalibrationRepo)
+	scaleCalibrationRepo := deviceinfra.NewPostgresScaleCalibrationRepository(pool)
+	recordScaleCalibrationHandler := deviceapp.NewRecordScaleCalibrationHandler(deviceRepo, scaleCalibrationRepo, eventPublisher)
+	scaleCalibrationQueryService := deviceapp.NewScaleCalibrationQueryService(deviceRepo, scaleCalibrationRepo)
+	diagnosticReportRepo := deviceinfra.NewPostgresDiagnosticReportRepository(pool)
+	uploadDiagnosticsHandler := deviceapp.NewUploadDiagnosticsHandler(deviceRepo, deviceCommandRepo, diagnosticReportRepo, eventPublisher)
+	diagnosticsQueryService := deviceapp.NewDiagnosticsQueryService(deviceRepo, deviceCommandRepo, diagnosticReportRepo)
+	setDeviceLabelsHandler := deviceapp.NewSetDeviceLabelsHandler(deviceRepo)
+	deviceHandler := deviceinfra.NewHTTPHandler(
+		enrollDeviceHandler, createEnrollmentTokenHandler, issueStartTokenHandler,
+		submitShelfSnapshotHandler, stockQueryService,
+		recordTelemetryHandler, clearDeviceHandler, excursionQueryService, incidentQueryService,
+		assignPlanogramHandler, recordRestockVisitHandler, planogramQueryService,
+		setSalesHoursHandler, salesHoursQueryService,
+		recordBatchesHandler, writeOffBatchHandler, expiryQueryService,
+		restockInventoryHandler, inventoryQueryService,
+		assignSKUsHandler, unassignSKUHandler, assortmentQueryService,
+		recordHeartbeatHandler, issueDeviceKeyHandler, deviceQueryService, decommissionDeviceHandler,
+		createDeviceGroupHandler, setGroupPolicyHandler, assignGroupDevicesHandler, removeGroupDeviceHandler,
+		setDevicePolicyHandler, deviceGroupQueryService,
+		setDesiredConfigHandler, pullDeviceConfigHandler, reportDeviceConfigHandler, configQueryService,
+		uploadReleaseHandler, downloadReleaseHandler, releaseQueryService,
+		startRolloutHandler, advanceRolloutHandler, abortRolloutHandler, rolloutQueryService, fleetHealthQueryService, deviceQRCodeHandler,
+		enterMaintenanceHandler, exitMaintenanceHandler, recordDeviceEventsHandler, deviceEventQueryService,
+		issueDeviceCommandHandler, deviceCommandDelivery, acknowledgeDeviceCommandHandler, deviceCommandQueryService, expectedModelQueryService, detectOfflineDevicesHandler,
+		startRestockSessionHandler, recordRestockUnitsHandler, completeRestockSessionHandler, cancelRestockSessionHandler, restockSessionQueryService,
+		uploadCameraCalibrationHandler, cameraCalibrationQueryService,
+		recordScaleCalibrationHandler, scaleCalibrationQueryService,
+		uploadDiagnosticsHandler, diagnosticsQueryService,
+		setDeviceLabelsHandler,
+		skuReader, deviceSyncReader,
+	)
+
+	// =========================================================================
+	// Service Status (payments count as configured so incidents on them show)
+	// =========================================================================
+	statusService := status.NewService([]status.Check{
+		{Component: status.ComponentAPI, Configured: true, Probe: pool.Ping},
+		{Component: status.ComponentPayments, Configured: true},
+		{Component: status.ComponentMLVerification, Configured: false},
+	}, status.NewPostgresIncidentStore(pool), 30*time.Second)
+	statusHandler := status.NewHTTPHandler(statusService)
+	eventsHandler := messaging.NewHTTPHandler(eventTopic, catalogapi.EventTopic, transactionapi.EventTopic)
+
 	// =========================================================================
 	// HTTP Router
 	// =========================================================================
-	router := platformhttp.NewRouter(catalogHandler, deviceHandler, transactionHandler)
+	router := platformhttp.NewRouter(catalogHandler, deviceHandler, transactionHandler, invoicingHandler, pricingHandler, statusHandler, eventsHandler, regionConfig)
+
+	// Runs for the lifetime of the test process, like the server's background jobs
+	go saleListener.Run(context.Background())
+	go decommissionListener.Run(context.Background())
+	go offlineListener.Run(context.Background())
 
 	return httptest.NewServer(router.Engine())
 }
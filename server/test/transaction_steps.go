@@ -1,17 +1,36 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cucumber/godog"
+	messages "github.com/cucumber/messages/go/v21"
+
+	"github.com/vending-machine/server/internal/platform/webhook"
+	"github.com/vending-machine/server/internal/transaction/app/ports"
+	"github.com/vending-machine/server/test/support"
 )
 
 // Transaction-specific step definitions
 
 func iStartSessionOnDevice(machineID string) error {
+	return startSessionOnDevice(machineID, "")
+}
+
+// startSessionOnDevice starts a session for the user, or a guest session
+// when userID is empty
+func startSessionOnDevice(machineID, userID string) error {
 	session := map[string]interface{}{
 		"machine_id": machineID,
 	}
+	if userID != "" {
+		session["user_id"] = userID
+	}
 
 	err := testContext.SendRequest("POST", "/api/v1/session/start", session)
 	if err != nil {
@@ -23,6 +42,9 @@ func iStartSessionOnDevice(machineID string) error {
 		response, _ := testContext.GetResponseJSON()
 		if sessionID, ok := response["session_id"].(string); ok {
 			testContext.CreatedSessions["current"] = sessionID
+			if token, ok := response["stream_token"].(string); ok {
+				testContext.StreamTokens[sessionID] = token
+			}
 		}
 	}
 
@@ -30,6 +52,10 @@ func iStartSessionOnDevice(machineID string) error {
 }
 
 func anActiveSessionExistsOnDevice(machineID string) error {
+	return anActiveSessionExistsOnDeviceForUser(machineID, "")
+}
+
+func anActiveSessionExistsOnDeviceForUser(machineID, userID string) error {
 	// Ensure device exists
 	if _, exists := testContext.CreatedDevices[machineID]; !exists {
 		if err := aDeviceExistsWithMachineID(machineID); err != nil {
@@ -38,12 +64,16 @@ func anActiveSessionExistsOnDevice(machineID string) error {
 	}
 
 	// Start session
-	return iStartSessionOnDevice(machineID)
+	return startSessionOnDevice(machineID, userID)
 }
 
 func anActiveSessionWithItemsExistsOnDevice(machineID string) error {
+	return anActiveSessionWithItemsExistsOnDeviceForUser(machineID, "")
+}
+
+func anActiveSessionWithItemsExistsOnDeviceForUser(machineID, userID string) error {
 	// Create active session
-	if err := anActiveSessionExistsOnDevice(machineID); err != nil {
+	if err := anActiveSessionExistsOnDeviceForUser(machineID, userID); err != nil {
 		return err
 	}
 
@@ -80,8 +110,14 @@ func anActiveSessionWithItemsExistsOnDevice(machineID string) error {
 }
 
 func aCompletedSessionExistsOnDevice(machineID string) error {
+	return userCompletedAPurchaseOnDevice("", machineID)
+}
+
+// userCompletedAPurchaseOnDevice buys two apples on the device as the user,
+// or as a guest when userID is empty
+func userCompletedAPurchaseOnDevice(userID, machineID string) error {
 	// Create session with items
-	if err := anActiveSessionWithItemsExistsOnDevice(machineID); err != nil {
+	if err := anActiveSessionWithItemsExistsOnDeviceForUser(machineID, userID); err != nil {
 		return err
 	}
 
@@ -104,11 +140,114 @@ func aCompletedSessionExistsOnDevice(machineID string) error {
 }
 
 func iSubmitDetectionsToSession(table *godog.Table) error {
+	return submitDetections(table, nil, "")
+}
+
+func iSubmitDetectionsWeighingToSession(grams float64, table *godog.Table) error {
+	return submitDetections(table, &grams, "")
+}
+
+func iSubmitDetectionsFromModelToSession(modelVersion string, table *godog.Table) error {
+	return submitDetections(table, nil, modelVersion)
+}
+
+// submitDetections posts the table's detections to the current session, with
+// the scale reading and the model version when they are given
+func submitDetections(table *godog.Table, totalWeight *float64, modelVersion string) error {
+	detection, err := currentSessionDetection(table)
+	if err != nil {
+		return err
+	}
+	if totalWeight != nil {
+		detection["total_weight"] = *totalWeight
+	}
+	if modelVersion != "" {
+		detection["model_version"] = modelVersion
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/detection", detection)
+}
+
+// iSubmitDetectionsWithIdempotencyKeyToSession sends the key as the
+// Idempotency-Key header, or as the detection_id field
+func iSubmitDetectionsWithIdempotencyKeyToSession(field, key string, table *godog.Table) error {
+	detection, err := currentSessionDetection(table)
+	if err != nil {
+		return err
+	}
+	if field == "detection ID" {
+		detection["detection_id"] = key
+		return testContext.SendRequest("POST", "/api/v1/device/detection", detection)
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/device/detection", detection,
+		map[string]string{"Idempotency-Key": key})
+}
+
+// iSubmitFrameWithDetections sends a full snapshot as the given frame of the
+// current session
+func iSubmitFrameWithDetections(sequence int, table *godog.Table) error {
+	detection, err := currentSessionDetection(table)
+	if err != nil {
+		return err
+	}
+	detection["sequence"] = sequence
+	return testContext.SendRequest("POST", "/api/v1/device/detection", detection)
+}
+
+// iSubmitFrameWithChanges sends an incremental frame of the current session;
+// the change column says whether each item was added or removed
+func iSubmitFrameWithChanges(sequence int, table *godog.Table) error {
 	sessionID := testContext.CreatedSessions["current"]
 	if sessionID == "" {
 		return fmt.Errorf("no active session found")
 	}
 
+	var deviceID string
+	for _, id := range testContext.CreatedDevices {
+		deviceID = id
+		break
+	}
+
+	added := []map[string]interface{}{}
+	removed := []map[string]interface{}{}
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue // Skip header
+		}
+		item := map[string]interface{}{
+			"sku":        getCellValue(table, row, "sku"),
+			"confidence": parseCellFloat(table, row, "confidence"),
+		}
+		if box := parseCellBox(table, row, "bbox"); box != nil {
+			item["bbox"] = box
+		}
+		switch change := getCellValue(table, row, "change"); change {
+		case "added":
+			added = append(added, item)
+		case "removed":
+			removed = append(removed, item)
+		default:
+			return fmt.Errorf("unknown change %q, expected added or removed", change)
+		}
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/detection/changes", map[string]interface{}{
+		"device_id":  deviceID,
+		"session_id": sessionID,
+		"sequence":   sequence,
+		"added":      added,
+		"removed":    removed,
+	})
+}
+
+// currentSessionDetection builds a detection of the table's items for the
+// current session
+func currentSessionDetection(table *godog.Table) (map[string]interface{}, error) {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return nil, fmt.Errorf("no active session found")
+	}
+
 	// Find device ID for this session
 	var deviceID string
 	for _, id := range testContext.CreatedDevices {
@@ -126,16 +265,32 @@ func iSubmitDetectionsToSession(table *godog.Table) error {
 			"sku":        getCellValue(table, row, "sku"),
 			"confidence": parseCellFloat(table, row, "confidence"),
 		}
+		if box := parseCellBox(table, row, "bbox"); box != nil {
+			item["bbox"] = box
+		}
 		items = append(items, item)
 	}
 
-	detection := map[string]interface{}{
+	return map[string]interface{}{
 		"device_id":  deviceID,
 		"session_id": sessionID,
 		"items":      items,
-	}
+	}, nil
+}
 
-	return testContext.SendRequest("POST", "/api/v1/device/detection", detection)
+// parseCellBox reads a box written as "x,y,width,height"; nil when the cell
+// is empty
+func parseCellBox(table *godog.Table, row *messages.PickleTableRow, columnName string) []float64 {
+	value := getCellValue(table, row, columnName)
+	if value == "" {
+		return nil
+	}
+	var box []float64
+	for _, part := range strings.Split(value, ",") {
+		v, _ := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		box = append(box, v)
+	}
+	return box
 }
 
 func iSubmitDetectionsToSessionID(sessionID string) error {
@@ -186,6 +341,298 @@ func iCancelSessionWithReason(reason string) error {
 	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/cancel", sessionID), cancel)
 }
 
+// theCurrentSessionShouldBecome polls the session, as it is changed by an
+// event listener rather than by the request before it
+func theCurrentSessionShouldBecome(status string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	var current string
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err := testContext.SendRequest("GET", "/api/v1/session/"+sessionID, nil); err != nil {
+			return err
+		}
+		var view struct {
+			Session struct {
+				Status string `json:"status"`
+			} `json:"session"`
+		}
+		_ = json.Unmarshal(testContext.LastBody, &view)
+		current = view.Session.Status
+		if current == status || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if current != status {
+		return fmt.Errorf("expected session status %s, got %s", status, current)
+	}
+	return nil
+}
+
+func iPayForTheSessionWithWallet(wallet, token string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	payment := map[string]interface{}{
+		"wallet": wallet,
+		"token":  token,
+	}
+
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/pay/wallet", sessionID), payment)
+}
+
+func iPayForTheSessionByCard() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/pay", sessionID), nil)
+}
+
+func iConfirmTheSession() error {
+	return iConfirmSessionWithPaymentRef("")
+}
+
+func thePaymentProviderIsAvailable() error {
+	support.PaymentProvider.MakeAvailable()
+	return nil
+}
+
+// currentIntent is the payment intent the test payment provider opened for
+// the current session
+func currentIntent() (ports.PaymentIntent, error) {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return ports.PaymentIntent{}, fmt.Errorf("no active session found")
+	}
+	intent, ok := support.PaymentProvider.IntentOf(sessionID)
+	if !ok {
+		return ports.PaymentIntent{}, fmt.Errorf("no payment intent was opened for session %s", sessionID)
+	}
+	return intent, nil
+}
+
+func theResponseShouldHandOverTheSessionPaymentIntent() error {
+	intent, err := currentIntent()
+	if err != nil {
+		return err
+	}
+	if err := theResponseFieldShouldBe("payment_intent.id", intent.ID); err != nil {
+		return err
+	}
+	return theResponseFieldShouldBe("payment_intent.client_secret", intent.ClientSecret)
+}
+
+func theSessionPaymentIntentShouldBeForCents(cents int) error {
+	intent, err := currentIntent()
+	if err != nil {
+		return err
+	}
+	if intent.AmountCents != int64(cents) {
+		return fmt.Errorf("payment intent %s is for %d cents, want %d", intent.ID, intent.AmountCents, cents)
+	}
+	return nil
+}
+
+// theCustomerPaysTheSessionPaymentIntentByCard settles the intent for what
+// it asks, as the app does with the card it collected
+func theCustomerPaysTheSessionPaymentIntentByCard() error {
+	intent, err := currentIntent()
+	if err != nil {
+		return err
+	}
+	return support.PaymentProvider.Settle(testContext.CreatedSessions["current"], ports.PaymentIntentSucceeded, intent.AmountCents)
+}
+
+func theCustomerPaysCentsForTheSessionByCard(cents int) error {
+	if _, err := currentIntent(); err != nil {
+		return err
+	}
+	return support.PaymentProvider.Settle(testContext.CreatedSessions["current"], ports.PaymentIntentSucceeded, int64(cents))
+}
+
+// theSessionCardPaymentIs moves the card payment of the session to what the
+// provider made of it
+func theSessionCardPaymentIs(state string) error {
+	intent, err := currentIntent()
+	if err != nil {
+		return err
+	}
+	status := map[string]ports.PaymentIntentStatus{
+		"still processing": ports.PaymentIntentProcessing,
+		"captured":         ports.PaymentIntentSucceeded,
+		"declined":         ports.PaymentIntentRequiresPaymentMethod,
+	}[state]
+	return support.PaymentProvider.Settle(testContext.CreatedSessions["current"], status, intent.AmountCents)
+}
+
+// anotherRequestSavesTheSessionFirst lets the next request on the current
+// session load it and then find it saved by another request in the meantime
+func anotherRequestSavesTheSessionFirst() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+	support.SessionRaces.RaceNextSave(sessionID)
+	return nil
+}
+
+func thePaymentProviderReportsThatTheSessionPayment(outcome string) error {
+	eventType := "payment_intent.succeeded"
+	if outcome == "failed" {
+		eventType = "payment_intent.payment_failed"
+	}
+	return sendPaymentEvent(eventType, support.PaymentWebhookSecret)
+}
+
+func thePaymentProviderSendsAnEventForTheSession(eventType string) error {
+	return sendPaymentEvent(eventType, support.PaymentWebhookSecret)
+}
+
+func thePaymentProviderReportsThatTheSessionPaymentSucceededWithTheWrongSecret() error {
+	return sendPaymentEvent("payment_intent.succeeded", "not-the-webhook-secret")
+}
+
+// sendPaymentEvent delivers a payment provider event about the current
+// session's payment intent, signed the way the provider signs them
+func sendPaymentEvent(eventType, secret string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	// Events are about the intent the provider opened for the session, if any
+	intentID := "pi_test"
+	if intent, ok := support.PaymentProvider.IntentOf(sessionID); ok {
+		intentID = intent.ID
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"id":   "evt_test",
+		"type": eventType,
+		"data": map[string]any{
+			"object": map[string]any{
+				"id":       intentID,
+				"metadata": map[string]string{"session_id": sessionID},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	timestamp, signature := webhook.NewVerifier([]byte(secret), 0).Sign(time.Now(), body)
+
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/webhooks/payments", json.RawMessage(body), map[string]string{
+		"Stripe-Signature": "t=" + timestamp + ",v1=" + strings.TrimPrefix(signature, "sha256="),
+	})
+}
+
+func iRequestAnApplePayMerchantSessionFrom(validationURL string) error {
+	req := map[string]interface{}{
+		"validation_url": validationURL,
+		"domain_name":    "shop.example.com",
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/payments/apple-pay/merchant-session", req)
+}
+
+func iRequestARefundOfCentsForTheSession(amount int) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	refund := map[string]interface{}{
+		"amount_cents": amount,
+		"reason":       "customer complaint",
+	}
+
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/refunds", sessionID), refund)
+}
+
+func supportAgentRequestsARefundOfCentsForTheSession(agent string, amount int) error {
+	return staffMemberWithRolesRequestsARefundOfCentsForTheSession(agent, "support", amount)
+}
+
+// currentRefund is the ID of the refund last requested in the scenario
+var currentRefund string
+
+func staffMemberWithRolesRequestsARefundOfCentsForTheSession(actorID, roles string, amount int) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	refund := map[string]interface{}{
+		"amount_cents": amount,
+		"reason":       "customer complaint",
+	}
+
+	err := testContext.SendRequestWithHeaders("POST", fmt.Sprintf("/api/v1/session/%s/refunds", sessionID), refund, map[string]string{
+		"X-Actor-ID":    actorID,
+		"X-Actor-Roles": roles,
+	})
+	if err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		id, _ := testContext.GetNestedField("refund_id")
+		currentRefund = fmt.Sprint(id)
+	}
+	return nil
+}
+
+func staffMemberWithRolesDecidesTheRefund(actorID, roles, decision string) error {
+	action := "approve"
+	if decision == "rejects" {
+		action = "reject"
+	}
+	return testContext.SendRequestWithHeaders("POST", "/api/v1/refunds/"+currentRefund+"/"+action, map[string]interface{}{"note": "checked the receipt"}, map[string]string{
+		"X-Actor-ID":    actorID,
+		"X-Actor-Roles": roles,
+	})
+}
+
+func thePendingRefundsShouldIncludeTheRefund(not string) error {
+	if err := testContext.SendRequest("GET", "/api/v1/refunds/pending", nil); err != nil {
+		return err
+	}
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	refunds, _ := response["refunds"].([]interface{})
+
+	found := false
+	for _, r := range refunds {
+		if refund, ok := r.(map[string]interface{}); ok && refund["id"] == currentRefund {
+			found = true
+		}
+	}
+	if found != (not == "") {
+		return fmt.Errorf("expected the pending refunds to %sinclude refund %s", not, currentRefund)
+	}
+	return nil
+}
+
+func iRequestRecommendationsForTheSession() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/sessions/%s/recommendations", sessionID), nil)
+}
+
 func theResponseShouldContainItems(count int) error {
 	response, err := testContext.GetResponseJSON()
 	if err != nil {
@@ -239,3 +686,785 @@ func theResponseShouldContainItems() error {
 
 	return nil
 }
+
+func iFetchTheCurrentSession() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/session/%s", sessionID), nil)
+}
+
+func iFetchTheCurrentSessionIn(languages string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequestWithHeaders("GET", fmt.Sprintf("/api/v1/session/%s", sessionID), nil, map[string]string{"Accept-Language": languages})
+}
+
+func iOpenTheLiveStreamOfTheCurrentSession() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	path := fmt.Sprintf("/api/v1/session/%s/stream?token=%s", sessionID, url.QueryEscape(testContext.StreamTokens[sessionID]))
+	return testContext.SendRequest("GET", path, nil)
+}
+
+func iOpenTheLiveStreamOfTheCurrentSessionWithToken(token string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	path := fmt.Sprintf("/api/v1/session/%s/stream?token=%s", sessionID, url.QueryEscape(token))
+	return testContext.SendRequest("GET", path, nil)
+}
+
+func iOpenTheEventStreamOfTheCurrentSession() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return iOpenTheEventStreamOfTheCurrentSessionWithToken(testContext.StreamTokens[sessionID])
+}
+
+func iOpenTheEventStreamOfTheCurrentSessionWithToken(token string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	path := fmt.Sprintf("/api/v1/session/%s/events?token=%s", sessionID, url.QueryEscape(token))
+	return testContext.SendRequest("GET", path, nil)
+}
+
+func theStreamShouldContainEvent(event string) error {
+	body := string(testContext.LastBody)
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "event:") && strings.TrimSpace(strings.TrimPrefix(line, "event:")) == event {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a %q event. Stream: %s", event, body)
+}
+
+func theStreamShouldEndWithEvent(event string) error {
+	body := strings.TrimSpace(string(testContext.LastBody))
+	last := strings.LastIndex(body, "event:")
+	if last < 0 {
+		return fmt.Errorf("no events in stream: %s", body)
+	}
+	if got := strings.TrimSpace(strings.SplitN(body[last+len("event:"):], "\n", 2)[0]); got != event {
+		return fmt.Errorf("expected last event %q, got %q. Stream: %s", event, got, body)
+	}
+	return nil
+}
+
+func deviceSyncsOfflineEntriesForTheCurrentSession(machineID string, table *godog.Table) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	now := time.Now().UTC()
+	var entries []map[string]interface{}
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue // Skip header
+		}
+
+		minutes, err := strconv.Atoi(getCellValue(table, row, "recorded_minutes"))
+		if err != nil {
+			return fmt.Errorf("invalid recorded_minutes: %w", err)
+		}
+		entry := map[string]interface{}{
+			"client_id":   offlineClientID(sessionID, getCellValue(table, row, "client_id")),
+			"type":        getCellValue(table, row, "type"),
+			"session_id":  sessionID,
+			"recorded_at": now.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339),
+		}
+		var items []map[string]interface{}
+		for _, sku := range strings.Split(getCellValue(table, row, "skus"), ",") {
+			if sku = strings.TrimSpace(sku); sku != "" {
+				items = append(items, map[string]interface{}{"sku": sku, "confidence": 0.95})
+			}
+		}
+		if items != nil {
+			entry["items"] = items
+		}
+		entries = append(entries, entry)
+	}
+
+	batch := map[string]interface{}{
+		"device_id": testContext.CreatedDevices[machineID],
+		"entries":   entries,
+	}
+	testContext.OfflineBatch = batch
+
+	return testContext.SendRequest("POST", "/api/v1/device/sync", batch)
+}
+
+// offlineClientID scopes a scenario's client IDs to its session, since the
+// sync log outlives the scenario
+func offlineClientID(sessionID, label string) string {
+	return sessionID + "/" + label
+}
+
+func theDeviceResendsTheLastOfflineBatch() error {
+	if testContext.OfflineBatch == nil {
+		return fmt.Errorf("no offline batch was sent")
+	}
+	return testContext.SendRequest("POST", "/api/v1/device/sync", testContext.OfflineBatch)
+}
+
+func theSyncResultShouldBe(clientID, status string) error {
+	return theSyncResultShouldBeWithError(clientID, status, "")
+}
+
+func theSyncResultShouldBeWithError(clientID, status, errMsg string) error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+
+	results, _ := response["results"].([]interface{})
+	for _, r := range results {
+		result, _ := r.(map[string]interface{})
+		if result["client_id"] != offlineClientID(testContext.CreatedSessions["current"], clientID) {
+			continue
+		}
+		if result["status"] != status {
+			return fmt.Errorf("expected entry %q to be %q, got %v. Body: %s", clientID, status, result["status"], string(testContext.LastBody))
+		}
+		if errMsg != "" && result["error"] != errMsg {
+			return fmt.Errorf("expected entry %q error %q, got %v", clientID, errMsg, result["error"])
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no sync result for entry %q. Body: %s", clientID, string(testContext.LastBody))
+}
+
+func iSendADetectionWithAnImageForTheCurrentSessionOnDevice(machineID string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	detection := map[string]interface{}{
+		"device_id":  testContext.CreatedDevices[machineID],
+		"session_id": sessionID,
+		"items": []map[string]interface{}{
+			{"sku": "APPLE-001", "confidence": 0.95},
+		},
+		"image": []byte("\xff\xd8\xff\xe0 test frame"),
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/detection", detection)
+}
+
+func iUploadTheImageOfTheCurrentSessionForCloudVerification(which string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	var image []byte
+	if which == "the" {
+		image = []byte("\xff\xd8\xff\xe0 test frame")
+	}
+	return uploadSessionImage(sessionID, image)
+}
+
+func iUploadAnImageOfSessionForCloudVerification(sessionID string) error {
+	return uploadSessionImage(sessionID, []byte("\xff\xd8\xff\xe0 test frame"))
+}
+
+func theCloudModelFindsTheFollowingOnDevice(machineID string, table *godog.Table) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found", machineID)
+	}
+
+	detections := []ports.CloudDetection{}
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue // Skip header
+		}
+		detections = append(detections, ports.CloudDetection{
+			SKUCode:    getCellValue(table, row, "sku"),
+			Confidence: parseCellFloat(table, row, "confidence"),
+			BBox:       parseCellBox(table, row, "bbox"),
+		})
+	}
+	support.CloudModel.Sees(deviceID, detections)
+	return nil
+}
+
+func uploadSessionImage(sessionID string, image []byte) error {
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/verify-image", sessionID), map[string]interface{}{
+		"image": image,
+	})
+}
+
+func iListTheDetectionsOfTheCurrentSession() error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("GET", fmt.Sprintf("/api/v1/session/%s/detections", sessionID), nil)
+}
+
+func attendantResolvesTheWeightOfTheCurrentSessionAsBecause(attendant, decision, reason string) error {
+	return sendWeightOverride(attendant, map[string]interface{}{
+		"decision": decision,
+		"reason":   reason,
+	})
+}
+
+func attendantCorrectsTheCurrentSessionToOfBecause(attendant string, quantity int, sku, reason string) error {
+	return sendWeightOverride(attendant, map[string]interface{}{
+		"decision": "corrected",
+		"reason":   reason,
+		"items": []map[string]interface{}{
+			{"sku": sku, "quantity": quantity},
+		},
+	})
+}
+
+func sendWeightOverride(attendant string, override map[string]interface{}) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequestWithHeaders("POST", fmt.Sprintf("/api/v1/session/%s/weight-override", sessionID), override, map[string]string{
+		"X-Actor-ID": attendant,
+	})
+}
+
+func attendantAcceptsTheItemsOfTheCurrentSession(attendant, sku string) error {
+	return sendItemReview(attendant, map[string]interface{}{
+		"sku":      sku,
+		"decision": "accepted",
+	})
+}
+
+func attendantReplacesTheItemsOfTheCurrentSessionWith(attendant, sku, replacement string) error {
+	return sendItemReview(attendant, map[string]interface{}{
+		"sku":             sku,
+		"decision":        "replaced",
+		"replacement_sku": replacement,
+	})
+}
+
+func sendItemReview(attendant string, review map[string]interface{}) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequestWithHeaders("POST", fmt.Sprintf("/api/v1/session/%s/item-review", sessionID), review, map[string]string{
+		"X-Actor-ID": attendant,
+	})
+}
+
+func iListTheSessionsAwaitingReview() error {
+	return testContext.SendRequest("GET", "/api/v1/reviews/pending", nil)
+}
+
+func theReviewQueueShouldListTheCurrentSession(not string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	sessions, ok := response["sessions"].([]interface{})
+	if !ok {
+		return fmt.Errorf("sessions field not found in response")
+	}
+
+	listed := false
+	for _, s := range sessions {
+		if entry, ok := s.(map[string]interface{}); ok && entry["session_id"] == sessionID {
+			listed = true
+		}
+	}
+	if listed && not != "" {
+		return fmt.Errorf("expected session %s not to be awaiting review", sessionID)
+	}
+	if !listed && not == "" {
+		return fmt.Errorf("expected session %s to be awaiting review", sessionID)
+	}
+	return nil
+}
+
+func deviceSignalsForTheCurrentSession(machineID, signal string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/device/signal", map[string]interface{}{
+		"device_id":  testContext.CreatedDevices[machineID],
+		"session_id": sessionID,
+		"signal":     signal,
+	})
+}
+
+func theWalkawaySweepRuns() error {
+	return testContext.SendRequest("POST", "/api/v1/sessions/walkaway-sweep", nil)
+}
+
+func theWalkawaySweepRunsWithAGraceOf(seconds int) error {
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/sessions/walkaway-sweep?cancel_after_seconds=%d", seconds), nil)
+}
+
+func theSessionArchivalRuns() error {
+	return testContext.SendRequest("POST", "/api/v1/sessions/archive-sweep", nil)
+}
+
+func theSessionArchivalRunsForSessionsOlderThan(days int) error {
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/sessions/archive-sweep?after_days=%d", days), nil)
+}
+
+func theWalkawaySweepShouldHaveCancelledTheCurrentSession(not string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	ids, ok := response["session_ids"].([]interface{})
+	if !ok {
+		return fmt.Errorf("session_ids field not found in response")
+	}
+
+	cancelled := false
+	for _, id := range ids {
+		if id == sessionID {
+			cancelled = true
+		}
+	}
+	if cancelled && not != "" {
+		return fmt.Errorf("expected session %s not to be cancelled", sessionID)
+	}
+	if !cancelled && not == "" {
+		return fmt.Errorf("expected session %s to be cancelled", sessionID)
+	}
+	return nil
+}
+
+func iApplyTheCouponToTheSession(name string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/apply-coupon", sessionID), map[string]interface{}{
+		"code": couponCode(name),
+	})
+}
+
+func iExtendTheSession() error {
+	return iExtendTheSessionByMinutes(0)
+}
+
+func iExtendTheSessionByMinutes(minutes int) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	body := map[string]interface{}{}
+	if minutes != 0 {
+		body["minutes"] = minutes
+	}
+	return testContext.SendRequest("POST", fmt.Sprintf("/api/v1/session/%s/extend", sessionID), body)
+}
+
+func theSessionShouldExpireMinutesAfterItStarted(minutes int) error {
+	if err := iFetchTheCurrentSession(); err != nil {
+		return err
+	}
+
+	var started, expires time.Time
+	for field, at := range map[string]*time.Time{"session.created_at": &started, "session.expires_at": &expires} {
+		value, err := testContext.GetNestedField(field)
+		if err != nil {
+			return err
+		}
+		parsed, err := time.Parse(time.RFC3339, fmt.Sprint(value))
+		if err != nil {
+			return fmt.Errorf("invalid %s %v: %w", field, value, err)
+		}
+		*at = parsed
+	}
+
+	if lasts := expires.Sub(started).Round(time.Minute); lasts != time.Duration(minutes)*time.Minute {
+		return fmt.Errorf("expected the session to expire %d minutes after it started, it expires after %s", minutes, lasts)
+	}
+	return nil
+}
+
+func iRunTheSessionReconciliationFor(mode, day string) error {
+	if day == "today" {
+		day = time.Now().UTC().Format("2006-01-02")
+	}
+
+	return testContext.SendRequest("POST", "/api/v1/reconciliation/runs", map[string]interface{}{
+		"day":   day,
+		"rerun": mode == "rerun",
+	})
+}
+
+func iListTheSessionsOfDevice(machineID string) error {
+	return iListTheSessionsOfDeviceAtATime(machineID, 0)
+}
+
+// iListTheSessionsOfDeviceAtATime requests the first page of the device's
+// session history; a limit of 0 uses the server's default
+func iListTheSessionsOfDeviceAtATime(machineID string, limit int) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found in test context", machineID)
+	}
+
+	query := url.Values{"device_id": {deviceID}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return testContext.SendRequest("GET", "/api/v1/sessions?"+query.Encode(), nil)
+}
+
+// iRequestTheNextPageOfSessions repeats the last listing from the
+// next_cursor of its response
+func iRequestTheNextPageOfSessions() error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	cursor, ok := response["next_cursor"].(string)
+	if !ok {
+		return fmt.Errorf("the last page has no next_cursor")
+	}
+
+	query := testContext.LastRequest.URL.Query()
+	query.Set("cursor", cursor)
+	return testContext.SendRequest("GET", testContext.LastRequest.URL.Path+"?"+query.Encode(), nil)
+}
+
+func theSessionHistoryShouldList(count int, statuses string) error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	sessions, ok := response["sessions"].([]interface{})
+	if !ok {
+		return fmt.Errorf("response has no sessions")
+	}
+
+	var got []string
+	for _, s := range sessions {
+		session, _ := s.(map[string]interface{})
+		status, _ := session["status"].(string)
+		got = append(got, status)
+	}
+	if len(got) != count || strings.Join(got, ",") != statuses {
+		return fmt.Errorf("expected %d sessions %q, got %d %q", count, statuses, len(got), strings.Join(got, ","))
+	}
+	return nil
+}
+
+func userStartsASessionOnDevice(userID, machineID string) error {
+	return startSessionOnDevice(machineID, userID)
+}
+
+// userResumesTheActiveSessionOnDevice looks the user's session up as a
+// reconnecting app would, keeping the stream token it is handed for the
+// session
+func userResumesTheActiveSessionOnDevice(userID, machineID string) error {
+	query := url.Values{"machine_id": {machineID}, "user_id": {userID}}
+	if err := testContext.SendRequest("GET", "/api/v1/session/active?"+query.Encode(), nil); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 200 {
+		response, _ := testContext.GetResponseJSON()
+		session, _ := response["session"].(map[string]interface{})
+		sessionID, _ := session["id"].(string)
+		if token, ok := response["stream_token"].(string); ok && sessionID != "" {
+			testContext.StreamTokens[sessionID] = token
+		}
+	}
+	return nil
+}
+
+func theResumedSessionShouldBeTheCurrentSession() error {
+	sessionID, err := testContext.GetNestedField("session.id")
+	if err != nil {
+		return err
+	}
+	if sessionID != testContext.CreatedSessions["current"] {
+		return fmt.Errorf("expected session %s to be resumed, got %v", testContext.CreatedSessions["current"], sessionID)
+	}
+	return nil
+}
+
+func iRequestThePurchaseHistoryOfUser(userID string) error {
+	return testContext.SendRequest("GET", "/api/v1/users/"+url.PathEscape(userID)+"/sessions", nil)
+}
+
+func iRequestThePurchaseHistoryOfUserAtATime(userID string, limit int) error {
+	return testContext.SendRequest("GET", "/api/v1/users/"+url.PathEscape(userID)+"/sessions?limit="+strconv.Itoa(limit), nil)
+}
+
+// confirmedTransaction is the transaction the last confirmation recorded,
+// for telling a replayed confirmation from a new sale, and confirmedFiscal
+// the fiscal receipt it was issued, if any
+var (
+	confirmedTransaction string
+	confirmedFiscal      string
+)
+
+func iRetryTheConfirmationWithPaymentReference(paymentRef string) error {
+	transactionID, err := testContext.GetNestedField("transaction_id")
+	if err != nil {
+		return err
+	}
+	confirmedTransaction = fmt.Sprint(transactionID)
+	confirmedFiscal = ""
+	if fiscal, err := testContext.GetNestedField("fiscal"); err == nil && fiscal != nil {
+		data, _ := json.Marshal(fiscal)
+		confirmedFiscal = string(data)
+	}
+	return iConfirmSessionWithPaymentRef(paymentRef)
+}
+
+func theResponseShouldRepeatTheOriginalFiscalReceipt() error {
+	if confirmedFiscal == "" {
+		return fmt.Errorf("the original confirmation had no fiscal receipt")
+	}
+	fiscal, err := testContext.GetNestedField("fiscal")
+	if err != nil {
+		return err
+	}
+	data, _ := json.Marshal(fiscal)
+	if string(data) != confirmedFiscal {
+		return fmt.Errorf("expected the fiscal receipt %s of the original confirmation, got %s", confirmedFiscal, data)
+	}
+	return nil
+}
+
+func deviceIsInACountryThatRequiresFiscalReceipts(machineID string) error {
+	deviceID, ok := testContext.CreatedDevices[machineID]
+	if !ok {
+		return fmt.Errorf("device %s not found", machineID)
+	}
+	support.Fiscalizer.Requires(deviceID)
+	return nil
+}
+
+func theResponseShouldRepeatTheOriginalConfirmation() error {
+	transactionID, err := testContext.GetNestedField("transaction_id")
+	if err != nil {
+		return err
+	}
+	if fmt.Sprint(transactionID) != confirmedTransaction {
+		return fmt.Errorf("expected transaction %s of the original confirmation, got %v", confirmedTransaction, transactionID)
+	}
+	return nil
+}
+
+// currentDispute is the dispute the last dispute step opened, and
+// disputeOpenedAt when, so listings skip disputes of earlier scenarios
+var (
+	currentDispute  string
+	disputeOpenedAt string
+)
+
+func staffMemberDisputesCentsOfTheSessionBecause(actorID string, amount int, reason string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+
+	dispute := map[string]interface{}{
+		"amount_cents": amount,
+		"reason":       reason,
+		"provider_ref": "CB-" + sessionID[:8],
+	}
+	if err := sendDisputeRequest("POST", fmt.Sprintf("/api/v1/session/%s/disputes", sessionID), dispute, actorID, "support"); err != nil {
+		return err
+	}
+
+	if testContext.LastResponse.StatusCode == 201 {
+		id, _ := testContext.GetNestedField("id")
+		openedAt, _ := testContext.GetNestedField("created_at")
+		currentDispute, disputeOpenedAt = fmt.Sprint(id), fmt.Sprint(openedAt)
+	}
+	return nil
+}
+
+func staffMemberNotesOnTheDispute(actorID, note string) error {
+	return sendDisputeRequest("POST", "/api/v1/disputes/"+currentDispute+"/notes", map[string]interface{}{"note": note}, actorID, "support")
+}
+
+func staffMemberAttachesTheSessionsEvidenceToTheDispute(actorID, kind string) error {
+	return sendDisputeRequest("POST", "/api/v1/disputes/"+currentDispute+"/evidence", map[string]interface{}{"kind": kind}, actorID, "support")
+}
+
+func staffMemberWithRolesResolvesTheDisputeAs(actorID, roles, outcome string) error {
+	resolution := map[string]interface{}{
+		"outcome": outcome,
+		"note":    "provider decision received",
+	}
+	return sendDisputeRequest("POST", "/api/v1/disputes/"+currentDispute+"/resolve", resolution, actorID, roles)
+}
+
+func iFetchTheDisputeImage() error {
+	return testContext.SendRequest("GET", "/api/v1/disputes/"+currentDispute+"/image", nil)
+}
+
+func iListTheDisputesOpenedSinceTheDispute(status string) error {
+	query := url.Values{"status": {status}, "from": {disputeOpenedAt}}
+	return testContext.SendRequest("GET", "/api/v1/disputes?"+query.Encode(), nil)
+}
+
+func iRequestTheDisputeReportSinceTheDispute() error {
+	query := url.Values{"from": {disputeOpenedAt}}
+	return testContext.SendRequest("GET", "/api/v1/disputes/report?"+query.Encode(), nil)
+}
+
+func theDisputeListShouldIncludeTheDispute(not string) error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	disputes, ok := response["disputes"].([]interface{})
+	if !ok {
+		return fmt.Errorf("response has no disputes list")
+	}
+
+	found := false
+	for _, d := range disputes {
+		if dispute, ok := d.(map[string]interface{}); ok && dispute["id"] == currentDispute {
+			found = true
+		}
+	}
+	if found != (not == "") {
+		return fmt.Errorf("expected dispute %s listed: %v, got %v", currentDispute, not == "", found)
+	}
+	return nil
+}
+
+func theDisputeReportShouldCountDisputesOfCents(count int, status string, amount int) error {
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return err
+	}
+	lines, _ := response["lines"].([]interface{})
+
+	gotCount, gotAmount := 0, 0
+	for _, l := range lines {
+		line, ok := l.(map[string]interface{})
+		if !ok || line["status"] != status {
+			continue
+		}
+		c, _ := line["count"].(float64)
+		a, _ := line["amount_cents"].(float64)
+		gotCount += int(c)
+		gotAmount += int(a)
+	}
+	if gotCount != count || gotAmount != amount {
+		return fmt.Errorf("expected %d %s disputes of %d cents, got %d of %d cents", count, status, amount, gotCount, gotAmount)
+	}
+	return nil
+}
+
+func sendDisputeRequest(method, path string, body interface{}, actorID, roles string) error {
+	return testContext.SendRequestWithHeaders(method, path, body, map[string]string{
+		"X-Actor-ID":    actorID,
+		"X-Actor-Roles": roles,
+	})
+}
+
+func supportAgentNotesOnTheSession(agent, note string) error {
+	sessionID := testContext.CreatedSessions["current"]
+	if sessionID == "" {
+		return fmt.Errorf("no active session found")
+	}
+	return supportAgentNotesOnSession(agent, note, sessionID)
+}
+
+func supportAgentNotesOnSession(agent, note, sessionID string) error {
+	headers := map[string]string{}
+	if agent != "" {
+		headers["X-Actor-ID"] = agent
+		headers["X-Actor-Roles"] = "support"
+	}
+	return testContext.SendRequestWithHeaders("POST", fmt.Sprintf("/api/v1/session/%s/notes", sessionID), map[string]interface{}{"note": note}, headers)
+}
+
+func iRequestTheSalesReportGroupedBy(groupBy string) error {
+	return testContext.SendRequest("GET", "/api/v1/reports/sales?group_by="+url.QueryEscape(groupBy), nil)
+}
+
+// salesReportLine finds the report line of the key; devices are named by
+// machine ID and today's line by "today"
+func salesReportLine(key string) (map[string]interface{}, error) {
+	switch {
+	case key == "today":
+		key = time.Now().UTC().Format("2006-01-02")
+	case testContext.CreatedDevices[key] != "":
+		key = testContext.CreatedDevices[key]
+	}
+
+	response, err := testContext.GetResponseJSON()
+	if err != nil {
+		return nil, err
+	}
+	lines, _ := response["lines"].([]interface{})
+	for _, l := range lines {
+		if line, ok := l.(map[string]interface{}); ok && line["key"] == key {
+			return line, nil
+		}
+	}
+	return nil, fmt.Errorf("sales report has no line for %s", key)
+}
+
+func theSalesReportShouldHaveALineFor(key string) error {
+	_, err := salesReportLine(key)
+	return err
+}
+
+func theSalesReportLineForShouldShow(key string, transactions, units, revenue int) error {
+	line, err := salesReportLine(key)
+	if err != nil {
+		return err
+	}
+	got := fmt.Sprintf("%v transactions, %v units, %v cents", line["transactions"], line["units"], line["revenue_cents"])
+	want := fmt.Sprintf("%d transactions, %d units, %d cents", transactions, units, revenue)
+	if got != want {
+		return fmt.Errorf("expected %s for %s, got %s", want, key, got)
+	}
+	return nil
+}
+
+func theSalesReportLineForShouldAveragePerBasket(key string, cents int, units string) error {
+	line, err := salesReportLine(key)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprint(line["average_basket_cents"]) != strconv.Itoa(cents) || fmt.Sprint(line["average_basket_units"]) != units {
+		return fmt.Errorf("expected an average basket of %d cents and %s units for %s, got %v cents and %v units",
+			cents, units, key, line["average_basket_cents"], line["average_basket_units"])
+	}
+	return nil
+}